@@ -0,0 +1,486 @@
+package app
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Repositories "ShopOps/Repositories"
+	Usecases "ShopOps/Usecases"
+
+	memory "github.com/ulule/limiter/v3/drivers/store/memory"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Container holds the application's services, repositories and use cases, wired
+// together once at startup. Everything a service depends on is handed to it here,
+// so tests can build a container with test doubles in place of any of them.
+type Container struct {
+	Config *Infrastructure.Config
+	DB     *mongo.Database
+	Logger *slog.Logger
+	Clock  Infrastructure.Clock
+
+	JWT         Infrastructure.JWTService
+	RateLimiter Infrastructure.RateLimitService
+	Files       Infrastructure.FileStorage
+	Backups     Infrastructure.FileStorage
+	SMSProvider Infrastructure.SMSProvider
+	Exports     Infrastructure.ExportService
+	Receipts    Infrastructure.ReceiptRenderer
+	Labels      Infrastructure.LabelRenderer
+	Jobs        Infrastructure.JobQueue
+	Events      Infrastructure.EventBus
+	Sync        Infrastructure.SyncService
+	Health      *Infrastructure.HealthChecker
+	Cache       *Infrastructure.ResponseCache
+	Search      *Infrastructure.SearchIndex
+	Catalog     *Infrastructure.CatalogCache
+	Telemetry   *Infrastructure.TelemetryBuffer
+	Webhooks    Infrastructure.WebhookSender
+	Telegram    Infrastructure.TelegramBot
+	MobileMoney Infrastructure.MobileMoneyGateways
+	Billing     Infrastructure.BillingGateways
+	Email       Infrastructure.EmailProvider
+	EmailViews  Infrastructure.EmailRenderer
+	Push        Infrastructure.PushSender
+	PrintSignal *Infrastructure.PrintSignal
+	Storefronts Infrastructure.StorefrontConnectors
+	Rates       Infrastructure.ExchangeRateProvider
+
+	Repos    Repos
+	UseCases UseCases
+}
+
+type Repos struct {
+	User              Domain.UserRepository
+	Business          Domain.BusinessRepository
+	Sales             Domain.SaleRepository
+	Expense           Domain.ExpenseRepository
+	RecurringExpense  Domain.RecurringExpenseRepository
+	Inventory         Domain.ProductRepository
+	Report            Domain.ReportRepository
+	Sync              Domain.SyncRepository
+	GiftCard          Domain.GiftCardRepository
+	Loyalty           Domain.LoyaltyRepository
+	ReceiptTemplate   Domain.ReceiptTemplateRepository
+	Customer          Domain.CustomerRepository
+	Supplier          Domain.SupplierRepository
+	PurchaseOrder     Domain.PurchaseOrderRepository
+	Employee          Domain.EmployeeRepository
+	CommissionRule    Domain.CommissionRuleRepository
+	SMS               Domain.SMSRepository
+	Segment           Domain.SegmentRepository
+	CustomerPrice     Domain.CustomerPriceRepository
+	Analytics         Domain.AnalyticsRepository
+	SalesSummary      Domain.SalesSummaryRepository
+	SavedReport       Domain.SavedReportRepository
+	InventorySnapshot Domain.InventorySnapshotRepository
+	StockLedger       Domain.StockLedgerRepository
+	Shrinkage         Domain.ShrinkageRepository
+	Alert             Domain.AlertRepository
+	Job               Domain.JobRepository
+	DomainEvent       Domain.DomainEventRepository
+	StockReservation  Domain.StockReservationRepository
+	Backorder         Domain.BackorderRepository
+	Deposit           Domain.DepositRepository
+	RepairJob         Domain.RepairJobRepository
+	Appointment       Domain.AppointmentRepository
+	Tab               Domain.TabRepository
+	Production        Domain.ProductionRepository
+	Transfer          Domain.StockTransferRepository
+	BI                Domain.BIRepository
+	SupplierPrice     Domain.SupplierPriceRepository
+	Consignment       Domain.ConsignmentRepository
+	FeatureFlag       Domain.FeatureFlagRepository
+	Maintenance       Domain.MaintenanceRepository
+	AuditLog          Domain.AuditLogRepository
+	RequestError      Domain.RequestErrorRepository
+	Backup            Domain.BackupRepository
+	Webhook           Domain.WebhookRepository
+	Telegram          Domain.TelegramRepository
+	MobilePayment     Domain.MobilePaymentRepository
+	Subscription      Domain.SubscriptionRepository
+	Email             Domain.EmailRepository
+	Push              Domain.PushRepository
+	Notification      Domain.NotificationRepository
+	Print             Domain.PrintRepository
+	Scale             Domain.ScaleRepository
+	Storefront        Domain.StorefrontRepository
+	Reconciliation    Domain.ReconciliationRepository
+	Accounting        Domain.AccountingRepository
+	CustomField       Domain.CustomFieldRepository
+	Currency          Domain.CurrencyRepository
+	Archive           Domain.ArchiveRepository
+	Retention         Domain.RetentionRepository
+	Telemetry         Domain.TelemetryRepository
+	Demo              Domain.DemoRepository
+	CatalogSettings   Domain.CatalogSettingsRepository
+	Onboarding        Domain.OnboardingRepository
+	InstalledPack     Domain.InstalledPackRepository
+	ShopSettings      Domain.ShopSettingsRepository
+}
+
+type UseCases struct {
+	User            Usecases.UserUseCase
+	Business        Usecases.BusinessUseCase
+	Analytics       Usecases.AnalyticsUseCase
+	Forecast        Usecases.ForecastUseCase
+	CustomReport    Usecases.CustomReportUseCase
+	Pricing         Usecases.PricingUseCase
+	Segment         Usecases.SegmentUseCase
+	SMS             Usecases.SMSUseCase
+	Sales           Usecases.SalesUseCase
+	Expense         Usecases.ExpenseUseCase
+	Inventory       Usecases.InventoryUseCase
+	Valuation       Usecases.InventoryValuationUseCase
+	Shrinkage       Usecases.ShrinkageUseCase
+	BranchReport    Usecases.BranchReportUseCase
+	Report          Usecases.ReportUseCase
+	Dashboard       Usecases.DashboardUseCase
+	Sync            Usecases.SyncUseCase
+	GiftCard        Usecases.GiftCardUseCase
+	Loyalty         Usecases.LoyaltyUseCase
+	Customer        Usecases.CustomerUseCase
+	Supplier        Usecases.SupplierUseCase
+	Employee        Usecases.EmployeeUseCase
+	Alert           Usecases.AlertUseCase
+	Receipt         Usecases.ReceiptUseCase
+	Job             Usecases.JobUseCase
+	DomainEvent     Usecases.DomainEventUseCase
+	Reservation     Usecases.StockReservationUseCase
+	Backorder       Usecases.BackorderUseCase
+	Deposit         Usecases.DepositUseCase
+	RepairJob       Usecases.RepairJobUseCase
+	Appointment     Usecases.AppointmentUseCase
+	Tab             Usecases.TabUseCase
+	Production      Usecases.ProductionUseCase
+	Transfer        Usecases.StockTransferUseCase
+	BI              Usecases.BIUseCase
+	Consignment     Usecases.ConsignmentUseCase
+	FeatureFlag     Usecases.FeatureFlagUseCase
+	Maintenance     Usecases.MaintenanceUseCase
+	Support         Usecases.SupportUseCase
+	Trash           Usecases.TrashUseCase
+	Search          Usecases.SearchUseCase
+	Webhook         Usecases.WebhookUseCase
+	Telegram        Usecases.TelegramUseCase
+	MobilePayment   Usecases.MobilePaymentUseCase
+	Billing         Usecases.BillingUseCase
+	Email           Usecases.EmailUseCase
+	Push            Usecases.PushUseCase
+	Notification    Usecases.NotificationUseCase
+	Print           Usecases.PrintUseCase
+	Scale           Usecases.ScaleUseCase
+	Storefront      Usecases.StorefrontUseCase
+	Reconciliation  Usecases.ReconciliationUseCase
+	Accounting      Usecases.AccountingUseCase
+	ImportTemplate  Usecases.ImportTemplateUseCase
+	CustomField     Usecases.CustomFieldUseCase
+	Currency        Usecases.CurrencyUseCase
+	Archive         Usecases.ArchiveUseCase
+	Retention       Usecases.RetentionUseCase
+	Demo            Usecases.DemoUseCase
+	CatalogSettings Usecases.CatalogSettingsUseCase
+	Onboarding      Usecases.OnboardingUseCase
+	VerticalPack    Usecases.VerticalPackUseCase
+	ShopSettings    Usecases.ShopSettingsUseCase
+}
+
+// Option replaces part of the container before the use cases are built, e.g. a
+// repository or the SMS provider with a test double
+type Option func(*Container)
+
+// New builds the container from the configuration and database. Options run after
+// the services and repositories are created and before anything that depends on
+// them, so a replacement is used everywhere.
+func New(cfg *Infrastructure.Config, db *mongo.Database, opts ...Option) *Container {
+	c := &Container{
+		Config: cfg,
+		DB:     db,
+		Logger: Infrastructure.Logger,
+		Clock:  Infrastructure.SystemClock(),
+	}
+
+	c.JWT = Infrastructure.NewJWTService(cfg.Auth)
+	c.RateLimiter = Infrastructure.NewRateLimitService(memory.NewStore(), c.Clock, c.Logger)
+	if !cfg.Server.RateLimits {
+		c.RateLimiter = Infrastructure.NewUnlimitedRateLimitService()
+	}
+	c.Files = Infrastructure.NewLocalFileStorage(cfg.Storage)
+	c.Backups = Infrastructure.NewLocalFileStorage(Infrastructure.StorageConfig{UploadDir: cfg.Support.BackupDir, UploadBaseURL: "/backups"})
+	c.SMSProvider = Infrastructure.NewSMSProvider(cfg.SMS)
+	c.Exports = Infrastructure.NewExportService()
+	c.Receipts = Infrastructure.NewReceiptRenderer()
+	c.Labels = Infrastructure.NewLabelRenderer()
+	c.Health = Infrastructure.NewHealthChecker()
+	c.Cache = Infrastructure.NewResponseCache(cfg.Cache.ResponseMaxEntries)
+	c.Search = Infrastructure.NewSearchIndex(cfg.Cache.SearchTTL, cfg.Cache.SearchMaxShops)
+	c.Catalog = Infrastructure.NewCatalogCache(cfg.Cache.CatalogMemoryTTL, cfg.Cache.CatalogMemoryMaxShops)
+	c.Webhooks = Infrastructure.NewWebhookSender(cfg.Webhooks)
+	c.Telegram = Infrastructure.NewTelegramBot(cfg.Telegram)
+	c.MobileMoney = Infrastructure.NewMobileMoneyGateways(cfg.MobileMoney)
+	c.Billing = Infrastructure.NewBillingGateways(cfg.Billing)
+	c.Email = Infrastructure.NewEmailProvider(cfg.Email)
+	c.EmailViews = Infrastructure.NewEmailRenderer()
+	c.Push = Infrastructure.NewPushSender(cfg.Push)
+	c.PrintSignal = Infrastructure.NewPrintSignal()
+	c.Storefronts = Infrastructure.NewStorefrontConnectors()
+	c.Rates = Infrastructure.NewExchangeRateProvider(cfg.ExchangeRates)
+	c.Repos = newRepos(db)
+
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.Jobs == nil {
+		c.Jobs = Infrastructure.NewJobQueue(c.Repos.Job)
+	}
+	if c.Events == nil {
+		c.Events = Infrastructure.NewEventBus(c.Repos.DomainEvent)
+	}
+	c.Telemetry = Infrastructure.NewTelemetryBuffer(c.Repos.Telemetry, cfg.Telemetry.MaxPending)
+	if c.Sync == nil {
+		c.Sync = Infrastructure.NewSyncService(db, Repositories.NewOutbox(db), c.Repos.Sales, c.Repos.Expense, c.Repos.Inventory, c.Repos.Sync)
+	}
+	c.UseCases = c.newUseCases()
+	return c
+}
+
+func newRepos(db *mongo.Database) Repos {
+	return Repos{
+		User:              Repositories.NewUserRepository(db),
+		Business:          Repositories.NewBusinessRepository(db),
+		Sales:             Repositories.NewSalesRepository(db),
+		Expense:           Repositories.NewExpenseRepository(db),
+		RecurringExpense:  Repositories.NewRecurringExpenseRepository(db),
+		Inventory:         Repositories.NewInventoryRepository(db),
+		Report:            Repositories.NewReportRepository(Infrastructure.ReadDB),
+		Sync:              Repositories.NewSyncRepository(db),
+		GiftCard:          Repositories.NewGiftCardRepository(db),
+		Loyalty:           Repositories.NewLoyaltyRepository(db),
+		ReceiptTemplate:   Repositories.NewReceiptTemplateRepository(db),
+		Customer:          Repositories.NewCustomerRepository(db),
+		Supplier:          Repositories.NewSupplierRepository(db),
+		PurchaseOrder:     Repositories.NewPurchaseOrderRepository(db),
+		Employee:          Repositories.NewEmployeeRepository(db),
+		CommissionRule:    Repositories.NewCommissionRuleRepository(db),
+		SMS:               Repositories.NewSMSRepository(db),
+		Segment:           Repositories.NewSegmentRepository(db),
+		CustomerPrice:     Repositories.NewCustomerPriceRepository(db),
+		Analytics:         Repositories.NewAnalyticsRepository(db, Infrastructure.ReadDB),
+		SalesSummary:      Repositories.NewSalesSummaryRepository(db, Infrastructure.ReadDB),
+		SavedReport:       Repositories.NewSavedReportRepository(db),
+		InventorySnapshot: Repositories.NewInventorySnapshotRepository(db),
+		StockLedger:       Repositories.NewStockLedgerRepository(db),
+		Shrinkage:         Repositories.NewShrinkageRepository(db),
+		Alert:             Repositories.NewAlertRepository(db),
+		Job:               Repositories.NewJobRepository(db),
+		DomainEvent:       Repositories.NewDomainEventRepository(db),
+		StockReservation:  Repositories.NewStockReservationRepository(db),
+		Backorder:         Repositories.NewBackorderRepository(db),
+		Deposit:           Repositories.NewDepositRepository(db),
+		RepairJob:         Repositories.NewRepairJobRepository(db),
+		Appointment:       Repositories.NewAppointmentRepository(db),
+		Tab:               Repositories.NewTabRepository(db),
+		Production:        Repositories.NewProductionRepository(db),
+		Transfer:          Repositories.NewStockTransferRepository(db),
+		BI:                Repositories.NewBIRepository(db, Infrastructure.ReadDB),
+		SupplierPrice:     Repositories.NewSupplierPriceRepository(db),
+		Consignment:       Repositories.NewConsignmentRepository(db),
+		FeatureFlag:       Repositories.NewFeatureFlagRepository(db),
+		Maintenance:       Repositories.NewMaintenanceRepository(db),
+		AuditLog:          Repositories.NewAuditLogRepository(db),
+		RequestError:      Repositories.NewRequestErrorRepository(db),
+		Backup:            Repositories.NewBackupRepository(db),
+		Webhook:           Repositories.NewWebhookRepository(db),
+		Telegram:          Repositories.NewTelegramRepository(db),
+		MobilePayment:     Repositories.NewMobilePaymentRepository(db),
+		Subscription:      Repositories.NewSubscriptionRepository(db),
+		Email:             Repositories.NewEmailRepository(db),
+		Push:              Repositories.NewPushRepository(db),
+		Notification:      Repositories.NewNotificationRepository(db),
+		Print:             Repositories.NewPrintRepository(db),
+		Scale:             Repositories.NewScaleRepository(db),
+		Storefront:        Repositories.NewStorefrontRepository(db),
+		Reconciliation:    Repositories.NewReconciliationRepository(db),
+		Accounting:        Repositories.NewAccountingRepository(db),
+		CustomField:       Repositories.NewCustomFieldRepository(db),
+		Currency:          Repositories.NewCurrencyRepository(db),
+		Archive:           Repositories.NewArchiveRepository(db),
+		Retention:         Repositories.NewRetentionRepository(db),
+		Telemetry:         Repositories.NewTelemetryRepository(db),
+		Demo:              Repositories.NewDemoRepository(db),
+		CatalogSettings:   Repositories.NewCatalogSettingsRepository(db),
+		Onboarding:        Repositories.NewOnboardingRepository(db),
+		InstalledPack:     Repositories.NewInstalledPackRepository(db),
+		ShopSettings:      Repositories.NewShopSettingsRepository(db),
+	}
+}
+
+func (c *Container) newUseCases() UseCases {
+	r := c.Repos
+	var uc UseCases
+
+	uc.Billing = Usecases.NewBillingUseCase(r.Subscription, r.Business, r.User, c.Billing, c.Config.Billing, c.Telemetry)
+	uc.Analytics = Usecases.NewAnalyticsUseCase(r.Analytics, r.SalesSummary, r.Business)
+	uc.Business = Usecases.NewBusinessUseCase(r.Business, r.User, uc.Analytics)
+	uc.Forecast = Usecases.NewForecastUseCase(r.Analytics, r.Inventory, r.Business)
+	uc.CustomReport = Usecases.NewCustomReportUseCase(r.SavedReport, r.Analytics, r.Business, uc.Analytics)
+	uc.Pricing = Usecases.NewPricingUseCase(r.CustomerPrice, r.Customer, r.Inventory, r.Business, c.Catalog)
+	uc.Segment = Usecases.NewSegmentUseCase(r.Segment, r.Customer, r.Business)
+	uc.Notification = Usecases.NewNotificationUseCase(r.Notification, r.Business)
+	uc.Email = Usecases.NewEmailUseCase(r.Email, r.Business, r.User, r.Inventory, uc.Notification, c.Email, c.EmailViews, c.Jobs, c.Config.Email)
+	uc.Report = Usecases.NewReportUseCase(r.Report, r.Business, c.Exports, r.Segment, uc.Analytics, r.User, c.Files, c.Jobs, uc.Email, r.CustomField)
+	uc.Dashboard = Usecases.NewDashboardUseCase(r.Business, r.Inventory, uc.Report, uc.Analytics)
+
+	// Sign-ins, account changes and impersonation are pushed to the user's phones
+	uc.Push = Usecases.NewPushUseCase(r.Push, r.Business, uc.Dashboard, uc.Notification, c.Push, c.Jobs)
+	alerters := Usecases.SecurityAlerters{uc.Push, uc.Notification}
+	uc.User = Usecases.NewUserUseCase(r.User, c.JWT, alerters)
+
+	// Sales, stock, sync and backup events go through the outbox to outgoing
+	// webhooks, linked Telegram chats and subscribed phones; failed backups are
+	// also emailed. The event bus relays them in Start.
+	uc.Webhook = Usecases.NewWebhookUseCase(r.Webhook, c.Webhooks, c.Jobs)
+	uc.Telegram = Usecases.NewTelegramUseCase(r.Telegram, r.Business, r.Inventory, uc.Dashboard, c.Telegram, c.Jobs)
+	events := Usecases.NewOutboxPublisher(r.DomainEvent)
+
+	uc.SMS = Usecases.NewSMSUseCase(r.SMS, r.Customer, r.Sales, r.Business, c.SMSProvider, uc.Segment, c.Jobs, c.Config.SMS.MaxPerSecond)
+	uc.Sales = Usecases.NewSalesUseCase(r.Sales, r.Business, r.Inventory, r.GiftCard, r.Loyalty, r.Employee, r.Customer, r.CustomField, r.Currency, r.StockReservation, r.ShopSettings, r.Deposit, uc.SMS, uc.Analytics)
+	uc.MobilePayment = Usecases.NewMobilePaymentUseCase(r.MobilePayment, r.Sales, r.Business, c.MobileMoney, c.Config.MobileMoney.CallbackBaseURL)
+	uc.Expense = Usecases.NewExpenseUseCase(r.Expense, r.RecurringExpense, r.Business, c.Files, uc.Analytics)
+	uc.Inventory = Usecases.NewInventoryUseCase(r.Inventory, r.Business, r.CustomField, r.StockReservation, r.StockLedger, events)
+	uc.Valuation = Usecases.NewInventoryValuationUseCase(r.InventorySnapshot, r.StockLedger, r.Inventory, r.Business)
+	uc.Shrinkage = Usecases.NewShrinkageUseCase(r.Shrinkage, r.Employee, r.Business)
+	uc.BranchReport = Usecases.NewBranchReportUseCase(r.Business, uc.Analytics)
+	uc.Sync = Usecases.NewSyncUseCase(c.Sync, r.Business, r.Sales, r.Expense, r.Inventory, r.Sync, r.CustomField, uc.Analytics, uc.Dashboard, events)
+	uc.GiftCard = Usecases.NewGiftCardUseCase(r.GiftCard, r.Business)
+	uc.Loyalty = Usecases.NewLoyaltyUseCase(r.Loyalty, r.Business)
+	uc.Customer = Usecases.NewCustomerUseCase(r.Customer, r.Business, r.Sales, r.GiftCard, r.Loyalty, r.CustomField)
+	uc.Supplier = Usecases.NewSupplierUseCase(r.Supplier, r.PurchaseOrder, r.Business, r.Inventory, r.CustomField, r.SupplierPrice, uc.Forecast)
+	uc.Employee = Usecases.NewEmployeeUseCase(r.Employee, r.CommissionRule, r.Business, r.Sales, r.Inventory, uc.Email)
+	uc.Alert = Usecases.NewAlertUseCase(r.Alert, r.SalesSummary, r.Employee, r.Business, uc.SMS, uc.Notification)
+	uc.Receipt = Usecases.NewReceiptUseCase(r.ReceiptTemplate, r.Sales, r.Business, r.Inventory, c.Receipts)
+	uc.Print = Usecases.NewPrintUseCase(r.Print, r.Business, r.Inventory, uc.Receipt, uc.Analytics, r.Sales, c.Receipts, c.Labels, c.PrintSignal, c.Telemetry)
+	uc.Scale = Usecases.NewScaleUseCase(r.Scale, r.Inventory, c.Catalog, uc.Sales)
+	uc.CustomField = Usecases.NewCustomFieldUseCase(r.CustomField)
+	uc.CatalogSettings = Usecases.NewCatalogSettingsUseCase(r.CatalogSettings)
+	uc.VerticalPack = Usecases.NewVerticalPackUseCase(r.InstalledPack, uc.CatalogSettings, uc.CustomField, uc.Receipt, uc.CustomReport)
+	uc.Onboarding = Usecases.NewOnboardingUseCase(r.Onboarding, uc.Business, uc.CatalogSettings, uc.VerticalPack, uc.Inventory, uc.Billing)
+	uc.Storefront = Usecases.NewStorefrontUseCase(r.Storefront, r.Inventory, r.StockReservation, uc.Sales, c.Storefronts)
+	uc.Reconciliation = Usecases.NewReconciliationUseCase(r.Reconciliation, r.Sales, r.Expense, r.Business)
+	uc.Accounting = Usecases.NewAccountingUseCase(r.Accounting, r.Sales, r.Expense, r.Supplier, r.Business)
+	uc.ImportTemplate = Usecases.NewImportTemplateUseCase(r.Inventory, r.CustomField)
+	uc.Currency = Usecases.NewCurrencyUseCase(r.Currency, r.Business, r.Sales, c.Rates)
+	uc.ShopSettings = Usecases.NewShopSettingsUseCase(r.ShopSettings, r.Business, uc.CatalogSettings, uc.Scale, uc.Currency, uc.Receipt)
+	uc.Job = Usecases.NewJobUseCase(r.Job)
+	uc.DomainEvent = Usecases.NewDomainEventUseCase(r.DomainEvent)
+	uc.Reservation = Usecases.NewStockReservationUseCase(r.StockReservation, r.Inventory, r.Business)
+	uc.Backorder = Usecases.NewBackorderUseCase(r.Backorder, r.Sales, r.Inventory, r.StockReservation, r.Business, uc.SMS)
+	uc.Deposit = Usecases.NewDepositUseCase(r.Deposit, r.Inventory, r.Sales, r.Business)
+	uc.RepairJob = Usecases.NewRepairJobUseCase(r.RepairJob, r.Inventory, r.StockReservation, r.Employee, r.Business, uc.Sales, uc.SMS)
+	uc.Appointment = Usecases.NewAppointmentUseCase(r.Appointment, r.Inventory, r.Employee, r.Business, r.ShopSettings, uc.Sales, uc.SMS)
+	uc.Tab = Usecases.NewTabUseCase(r.Tab, r.Inventory, r.StockReservation, r.Employee, uc.Sales)
+	uc.Production = Usecases.NewProductionUseCase(r.Production, r.Inventory, r.StockReservation, r.Business)
+	uc.Transfer = Usecases.NewStockTransferUseCase(r.Transfer, r.Inventory, r.StockReservation, r.Business)
+	uc.BI = Usecases.NewBIUseCase(r.BI)
+	uc.Consignment = Usecases.NewConsignmentUseCase(r.Consignment, r.Inventory, r.Supplier, r.Sales, r.Business)
+	uc.FeatureFlag = Usecases.NewFeatureFlagUseCase(r.FeatureFlag)
+	uc.Maintenance = Usecases.NewMaintenanceUseCase(r.Maintenance, uc.Push)
+	uc.Archive = Usecases.NewArchiveUseCase(r.Archive, c.Jobs, c.Config.Archive)
+	uc.Retention = Usecases.NewRetentionUseCase(r.Retention, r.ShopSettings, r.Inventory, r.Customer, r.Supplier, c.Config.Retention)
+	uc.Demo = Usecases.NewDemoUseCase(r.Demo, r.Business, uc.User, uc.Analytics, c.Config.Demo)
+	uc.Trash = Usecases.NewTrashUseCase(r.Inventory, r.Customer, r.Supplier)
+	uc.Search = Usecases.NewSearchUseCase(c.Search, r.Inventory, r.Customer, r.CustomField)
+	uc.Support = Usecases.NewSupportUseCase(r.Business, r.User, r.Employee, r.RequestError, r.Backup, r.AuditLog,
+		uc.FeatureFlag, uc.Maintenance, c.RateLimiter, c.Jobs, c.Backups, c.JWT, events, alerters, c.Config.Support.ImpersonationTTL)
+	return uc
+}
+
+// Start registers the health checks and starts the job queue, event bus and periodic work.
+// Tests that only exercise handlers can skip it.
+func (c *Container) Start() {
+	uc := c.UseCases
+
+	// Queued jobs; one campaign at a time per instance keeps the SMS gateway throttle meaningful
+	c.Jobs.Register(Domain.JobTypeSMSCampaign, 1, uc.SMS.RunCampaignJob)
+	c.Jobs.Register(Domain.JobTypeSMSReceipt, 4, uc.SMS.SendReceiptJob)
+	c.Jobs.Register(Domain.JobTypeShopBackup, 1, uc.Support.RunBackupJob)
+	c.Jobs.Register(Domain.JobTypeWebhookDelivery, 4, uc.Webhook.DeliverJob)
+	c.Jobs.Register(Domain.JobTypeTelegramMessage, 2, uc.Telegram.SendMessageJob)
+	c.Jobs.Register(Domain.JobTypeEmail, 4, uc.Email.SendMessageJob)
+	c.Jobs.Register(Domain.JobTypeReportExport, 1, uc.Report.RunExportEmailJob)
+	c.Jobs.Register(Domain.JobTypePush, 4, uc.Push.SendPushJob)
+	c.Jobs.Register(Domain.JobTypeMaintenanceAnnouncement, 1, uc.Push.SendMaintenanceJob)
+	c.Jobs.Register(Domain.JobTypeArchive, 1, uc.Archive.RunArchiveJob)
+	c.Jobs.Start()
+
+	// Domain events from the outbox; subscriber names are stored with each delivery
+	c.Events.Subscribe("analytics", []Domain.DomainEventType{Domain.EventSaleRecorded}, uc.Analytics.OnSaleRecorded)
+	c.Events.Subscribe("consignment", []Domain.DomainEventType{Domain.EventSaleRecorded, Domain.EventSaleUpdated, Domain.EventSaleVoided}, uc.Consignment.OnSaleChanged)
+	c.Events.Subscribe("stock_alerts", []Domain.DomainEventType{Domain.EventStockAdjusted}, uc.Inventory.OnStockAdjusted)
+	c.Events.Subscribe("deposits", []Domain.DomainEventType{Domain.EventSaleRecorded, Domain.EventSaleUpdated, Domain.EventSaleVoided}, uc.Deposit.OnSaleChanged)
+	c.Events.Subscribe("backorders", []Domain.DomainEventType{Domain.EventSaleRecorded, Domain.EventSaleVoided}, uc.Backorder.OnSaleChanged)
+	c.Events.Subscribe("backorder_allocation", []Domain.DomainEventType{Domain.EventStockAdjusted}, uc.Backorder.OnStockAdjusted)
+	c.Events.Subscribe("backorder_notify", []Domain.DomainEventType{Domain.EventBackorderReady}, uc.Backorder.NotifyReady)
+	c.Events.Subscribe("repair_jobs", []Domain.DomainEventType{Domain.EventSaleVoided}, uc.RepairJob.OnSaleVoided)
+	c.Events.Subscribe("webhooks", Usecases.RelayedEventTypes, Usecases.RelayEvents(uc.Webhook))
+	c.Events.Subscribe("telegram", Usecases.RelayedEventTypes, Usecases.RelayEvents(uc.Telegram))
+	c.Events.Subscribe("email", Usecases.RelayedEventTypes, Usecases.RelayEvents(uc.Email))
+	c.Events.Subscribe("push", Usecases.RelayedEventTypes, Usecases.RelayEvents(uc.Push))
+	c.Events.Subscribe("notifications", Usecases.RelayedEventTypes, Usecases.RelayEvents(uc.Notification))
+	c.Events.Start()
+
+	// Failed shop requests, for the support API
+	Infrastructure.RecordRequestErrors(c.Repos.RequestError)
+
+	// Background jobs
+	Infrastructure.RunPeriodically("recurring_expenses", time.Hour, uc.Expense.GenerateDueRecurringExpenses)
+	Infrastructure.RunPeriodically("customer_segments", time.Hour, uc.Segment.RefreshSegmentCounts)
+	Infrastructure.RunPeriodically("sales_aggregates", 30*time.Second, uc.Analytics.FlushDirty)
+	Infrastructure.RunPeriodically("custom_reports", 15*time.Minute, uc.CustomReport.RunScheduledReports)
+	Infrastructure.RunPeriodically("inventory_snapshots", time.Hour, uc.Valuation.TakeSnapshots)
+	Infrastructure.RunPeriodically("anomaly_alerts", 15*time.Minute, uc.Alert.AnalyzeAll)
+	Infrastructure.RunPeriodically("telegram_summaries", 15*time.Minute, uc.Telegram.SendDailySummaries)
+	Infrastructure.RunPeriodically("mobile_payments", 30*time.Second, uc.MobilePayment.CheckPending)
+	Infrastructure.RunPeriodically("billing_lapses", time.Hour, uc.Billing.ExpireLapsed)
+	Infrastructure.RunPeriodically("low_stock_digests", 15*time.Minute, uc.Email.SendLowStockDigests)
+	Infrastructure.RunPeriodically("push_summaries", 15*time.Minute, uc.Push.SendDailySummaries)
+	Infrastructure.RunPeriodically("print_jobs", time.Minute, uc.Print.FailExpiredJobs)
+	Infrastructure.RunPeriodically("stock_reservations", 5*time.Minute, uc.Reservation.ExpireDue)
+	Infrastructure.RunPeriodically("appointment_reminders", 5*time.Minute, uc.Appointment.SendReminders)
+	Infrastructure.RunPeriodically("storefront_sync", time.Minute, uc.Storefront.SyncDue)
+	Infrastructure.RunPeriodically("journal_posting", time.Minute, uc.Accounting.PostPending)
+	Infrastructure.RunPeriodically("exchange_rates", 15*time.Minute, uc.Currency.FetchDue)
+	Infrastructure.RunPeriodically("sales_archive", 24*time.Hour, uc.Archive.ArchiveDue)
+	Infrastructure.RunPeriodically("data_retention", 24*time.Hour, uc.Retention.ApplyDue)
+	Infrastructure.RunPeriodically("telemetry_flush", c.Config.Telemetry.FlushInterval, c.Telemetry.Flush)
+	Infrastructure.RunPeriodically("demo_purge", time.Hour, uc.Demo.PurgeExpired)
+	Infrastructure.RunPeriodically("read_replicas", 15*time.Second, Infrastructure.CheckReadReplicas)
+
+	// Health checks; the database is the only dependency we cannot serve without
+	c.Health.Register("mongodb", true, func(ctx context.Context) error {
+		return c.DB.Client().Ping(ctx, nil)
+	})
+	c.Health.Register("read_replicas", false, func(ctx context.Context) error {
+		return Infrastructure.CheckReadReplicas()
+	})
+	c.Health.Register("rate_limiter", false, c.RateLimiter.Check)
+	c.Health.Register("file_storage", false, func(ctx context.Context) error {
+		return c.Files.Check()
+	})
+	c.Health.Register("jobs", false, Infrastructure.CheckJobs)
+	c.Health.Register("job_queue", false, c.Jobs.Check)
+	c.Health.Register("event_bus", false, c.Events.Check)
+}
+
+// Stop writes out what is still buffered in memory. Call it once requests and
+// background work have drained.
+func (c *Container) Stop() {
+	if err := c.Telemetry.Flush(); err != nil {
+		c.Logger.Warn("failed to flush telemetry", slog.String("error", err.Error()))
+	}
+}
@@ -0,0 +1,259 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AccountingController struct {
+	accountingUC Usecases.AccountingUseCase
+}
+
+func NewAccountingController(accountingUC Usecases.AccountingUseCase) *AccountingController {
+	return &AccountingController{accountingUC: accountingUC}
+}
+
+// GetAccountingSettings godoc
+// @Summary      Get accounting settings
+// @Description  Whether sales, expenses and supplier bills are posted to the ledger, from when, and why the last posting run stopped if it did. Owners only.
+// @Tags         accounting
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.AccountingSettings
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/settings [get]
+// @Security     BearerAuth
+func (c *AccountingController) GetAccountingSettings(ctx *gin.Context) {
+	settings, err := c.accountingUC.GetSettings(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateAccountingSettings godoc
+// @Summary      Update accounting settings
+// @Description  Turn automatic posting on or off. The first time it is turned on the shop gets the default chart of accounts; documents dated before the start date (today by default) are left to the opening balances. Owners only.
+// @Tags         accounting
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                                  true  "Business ID"
+// @Param        request     body  Domain.UpdateAccountingSettingsRequest  true  "Settings"
+// @Success      200  {object}  Domain.AccountingSettings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/settings [patch]
+// @Security     BearerAuth
+func (c *AccountingController) UpdateAccountingSettings(ctx *gin.Context) {
+	var req Domain.UpdateAccountingSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	settings, err := c.accountingUC.UpdateSettings(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// GetAccounts godoc
+// @Summary      List the chart of accounts
+// @Description  Owners only.
+// @Tags         accounting
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.Account
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/accounts [get]
+// @Security     BearerAuth
+func (c *AccountingController) GetAccounts(ctx *gin.Context) {
+	accounts, err := c.accountingUC.GetAccounts(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+// CreateAccount godoc
+// @Summary      Add an account
+// @Description  Add an account to the chart. Give it a role, e.g. expense:rent or bank, for the posting engine to use it; a role another account holds is refused. Owners only.
+// @Tags         accounting
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.CreateAccountRequest  true  "Account"
+// @Success      201  {object}  Domain.Account
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/accounts [post]
+// @Security     BearerAuth
+func (c *AccountingController) CreateAccount(ctx *gin.Context) {
+	var req Domain.CreateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	account, err := c.accountingUC.CreateAccount(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, account)
+}
+
+// UpdateAccount godoc
+// @Summary      Update an account
+// @Description  Rename, renumber or deactivate an account, or give it a role; the account holding the role before loses it, and later postings go to this one. Owners only.
+// @Tags         accounting
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        accountId   path  string                        true  "Account ID"
+// @Param        request     body  Domain.UpdateAccountRequest  true  "Changes"
+// @Success      200  {object}  Domain.Account
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/accounts/{accountId} [patch]
+// @Security     BearerAuth
+func (c *AccountingController) UpdateAccount(ctx *gin.Context) {
+	var req Domain.UpdateAccountRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	account, err := c.accountingUC.UpdateAccount(ctx.Param("accountId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+// GetJournal godoc
+// @Summary      List journal entries
+// @Description  Journal entries newest first. A changed or voided document keeps its old entry, with a reversal after it. Owners only.
+// @Tags         accounting
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Param        source      query  string  false  "Source: sale, sale_return, expense, payable"
+// @Param        source_id   query  string  false  "Entries for this document"
+// @Param        account_id  query  string  false  "Entries posting to this account"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.JournalEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/journal [get]
+// @Security     BearerAuth
+func (c *AccountingController) GetJournal(ctx *gin.Context) {
+	filters := Domain.JournalFilters{Limit: 50}
+
+	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filters.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+			filters.EndDate = &endDate
+		}
+	}
+
+	if source := ctx.Query("source"); source != "" {
+		s := Domain.JournalSource(source)
+		filters.Source = &s
+	}
+
+	if sourceID := ctx.Query("source_id"); sourceID != "" {
+		filters.SourceID = &sourceID
+	}
+
+	if accountID := ctx.Query("account_id"); accountID != "" {
+		filters.AccountID = &accountID
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	entries, err := c.accountingUC.GetJournal(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// GetTrialBalance godoc
+// @Summary      Get the trial balance
+// @Description  Every account's debit or credit balance at the end of the day given, today by default, with the column totals. Owners only.
+// @Tags         accounting
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        as_of       query  string  false  "Date (YYYY-MM-DD)"
+// @Success      200  {object}  Domain.TrialBalance
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/accounting/trial-balance [get]
+// @Security     BearerAuth
+func (c *AccountingController) GetTrialBalance(ctx *gin.Context) {
+	asOf := time.Now()
+	if asOfStr := ctx.Query("as_of"); asOfStr != "" {
+		date, err := time.Parse("2006-01-02", asOfStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "as_of must be YYYY-MM-DD")
+			return
+		}
+		asOf = date.Add(24*time.Hour - time.Nanosecond)
+	}
+
+	balance, err := c.accountingUC.GetTrialBalance(ctx.Param("businessId"), asOf)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balance)
+}
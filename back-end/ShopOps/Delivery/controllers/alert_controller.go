@@ -0,0 +1,137 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AlertController struct {
+	alertUC Usecases.AlertUseCase
+}
+
+func NewAlertController(alertUC Usecases.AlertUseCase) *AlertController {
+	return &AlertController{alertUC: alertUC}
+}
+
+// GetAlerts godoc
+// @Summary      List alerts
+// @Description  Anomalies found by the background analyzer: sudden revenue drops, refund spikes and tills open without sales during business hours
+// @Tags         alerts
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        type        query  string  false  "Alert type: revenue_drop, refund_spike, idle_till"
+// @Param        status      query  string  false  "Alert status: open, acknowledged"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Alert
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/alerts [get]
+// @Security     BearerAuth
+func (c *AlertController) GetAlerts(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.AlertFilters{}
+
+	if alertType := ctx.Query("type"); alertType != "" {
+		t := Domain.AlertType(alertType)
+		filters.Type = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.AlertStatus(status)
+		filters.Status = &s
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	alerts, err := c.alertUC.GetAlerts(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alerts)
+}
+
+// AnalyzeNow godoc
+// @Summary      Run anomaly checks now
+// @Description  Run the checks the background analyzer runs every 15 minutes and return any alerts newly raised
+// @Tags         alerts
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.Alert
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/alerts/analyze [post]
+// @Security     BearerAuth
+func (c *AlertController) AnalyzeNow(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	alerts, err := c.alertUC.Analyze(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alerts)
+}
+
+// AcknowledgeAlert godoc
+// @Summary      Acknowledge an alert
+// @Description  Mark an alert as seen so it drops out of the open list
+// @Tags         alerts
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        alertId     path  string  true  "Alert ID"
+// @Success      200  {object}  Domain.Alert
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/alerts/{alertId}/acknowledge [post]
+// @Security     BearerAuth
+func (c *AlertController) AcknowledgeAlert(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	alert, err := c.alertUC.Acknowledge(ctx.Request.Context(), ctx.Param("alertId"), businessID, userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, alert)
+}
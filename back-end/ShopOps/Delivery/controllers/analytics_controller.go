@@ -0,0 +1,366 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AnalyticsController struct {
+	analyticsUC Usecases.AnalyticsUseCase
+}
+
+func NewAnalyticsController(analyticsUC Usecases.AnalyticsUseCase) *AnalyticsController {
+	return &AnalyticsController{analyticsUC: analyticsUC}
+}
+
+// GetProfitAndLoss godoc
+// @Summary      Get profit & loss statement
+// @Description  Revenue, cost of goods sold, gross margin, expenses and net profit per period, served from pre-aggregated daily totals
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        start_date   query  string  false  "Start date (YYYY-MM-DD), defaults to 29 days before end_date"
+// @Param        end_date     query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Param        granularity  query  string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly (default daily)"
+// @Param        calendar     query  string  false  "Calendar for buckets and labels: gregorian (default) or ethiopian"
+// @Param        compare      query  string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align        query  string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Success      200  {object}  Domain.PnLReport  "Domain.ReportComparison when compare is set"
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/pnl [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetProfitAndLoss(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	if opts, ok := parseCompareOptions(ctx); ok {
+		comparison, err := c.analyticsUC.CompareProfitAndLoss(
+			businessID,
+			ctx.Query("start_date"),
+			ctx.Query("end_date"),
+			Domain.PeriodType(ctx.Query("granularity")),
+			parseCalendar(ctx),
+			opts,
+		)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
+	report, err := c.analyticsUC.GetProfitAndLoss(
+		businessID,
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+		Domain.PeriodType(ctx.Query("granularity")),
+		parseCalendar(ctx),
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetCategoryMargins godoc
+// @Summary      Get margins by category
+// @Description  Revenue, cost and gross margin for each product category
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Param        compare     query  string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align       query  string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Success      200  {object}  Domain.MarginReport  "Domain.ReportComparison when compare is set"
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/margins/categories [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetCategoryMargins(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	if opts, ok := parseCompareOptions(ctx); ok {
+		comparison, err := c.analyticsUC.CompareCategoryMargins(businessID, ctx.Query("start_date"), ctx.Query("end_date"), opts)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
+	report, err := c.analyticsUC.GetCategoryMargins(businessID, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetProductMargins godoc
+// @Summary      Get margins by product
+// @Description  Revenue, cost and gross margin for each product, highest revenue first
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Param        limit       query  int     false  "Maximum number of products (default 50)"
+// @Param        compare     query  string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align       query  string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Success      200  {object}  Domain.MarginReport  "Domain.ReportComparison when compare is set"
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/margins/products [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetProductMargins(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		limit = l
+	}
+
+	if opts, ok := parseCompareOptions(ctx); ok {
+		comparison, err := c.analyticsUC.CompareProductMargins(businessID, ctx.Query("start_date"), ctx.Query("end_date"), limit, opts)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
+	report, err := c.analyticsUC.GetProductMargins(businessID, ctx.Query("start_date"), ctx.Query("end_date"), limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// RebuildAnalytics godoc
+// @Summary      Rebuild report aggregates
+// @Description  Recompute the P&L and sales summary tables for a date range, e.g. for history recorded before aggregation was enabled
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Success      200  {object}  Domain.AnalyticsRebuildResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/pnl/rebuild [post]
+// @Security     BearerAuth
+func (c *AnalyticsController) RebuildAnalytics(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	result, err := c.analyticsUC.Rebuild(businessID, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetSalesBreakdown godoc
+// @Summary      Get sales breakdown
+// @Description  Sales totals grouped by hour, day, device, category or payment method, served from the summary tables
+// @Tags         sales
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Param        by          query  string  false  "Group by: hour, day, device, category, payment_method (default day)"
+// @Success      200  {object}  Domain.SalesBreakdown
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sales/summary/breakdown [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetSalesBreakdown(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	breakdown, err := c.analyticsUC.GetSalesBreakdown(
+		businessID,
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+		Domain.SalesSummaryDimension(ctx.Query("by")),
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, breakdown)
+}
+
+// GetABCAnalysis godoc
+// @Summary      ABC analysis
+// @Description  Class products by cumulative share of revenue: A up to a_threshold percent, B up to b_threshold percent, C for the rest
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        start_date   query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date     query  string  false  "End date (YYYY-MM-DD)"
+// @Param        category     query  string  false  "Only products in this category"
+// @Param        device_id    query  string  false  "Only sales rung up on this device (till location)"
+// @Param        a_threshold  query  number  false  "Cumulative revenue percent closing class A (default 80)"
+// @Param        b_threshold  query  number  false  "Cumulative revenue percent closing class B (default 95)"
+// @Success      200  {object}  Domain.ABCReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/analytics/abc [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetABCAnalysis(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var thresholds [2]float64
+	for i, name := range []string{"a_threshold", "b_threshold"} {
+		if value := ctx.Query(name); value != "" {
+			threshold, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, name+" must be a number")
+				return
+			}
+			thresholds[i] = threshold
+		}
+	}
+
+	filter := Domain.MarginFilter{
+		Category: ctx.Query("category"),
+		DeviceID: ctx.Query("device_id"),
+	}
+
+	report, err := c.analyticsUC.GetABCAnalysis(businessID, ctx.Query("start_date"), ctx.Query("end_date"), filter, thresholds[0], thresholds[1])
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetTopSellers godoc
+// @Summary      Top sellers
+// @Description  List the best selling products by quantity, revenue or gross profit for a date range
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Param        rank_by     query  string  false  "quantity, revenue or margin (default revenue)"
+// @Param        category    query  string  false  "Only products in this category"
+// @Param        device_id   query  string  false  "Only sales rung up on this device (till location)"
+// @Param        limit       query  int     false  "Number of products (default 10)"
+// @Success      200  {object}  Domain.TopSellersReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/analytics/top-sellers [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetTopSellers(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filter := Domain.MarginFilter{
+		Category: ctx.Query("category"),
+		DeviceID: ctx.Query("device_id"),
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit <= 0 {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		filter.Limit = limit
+	}
+
+	report, err := c.analyticsUC.GetTopSellers(
+		businessID,
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+		Domain.TopSellerRank(ctx.Query("rank_by")),
+		filter,
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetTrafficHeatmap godoc
+// @Summary      Hourly traffic heatmap
+// @Description  Sales count and revenue by day of week and hour of day in the business timezone, with per-day averages, read from the hourly sales summary
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Param        device_id   query  string  false  "Only sales rung up on this device (till location)"
+// @Success      200  {object}  Domain.TrafficHeatmap
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/analytics/heatmap [get]
+// @Security     BearerAuth
+func (c *AnalyticsController) GetTrafficHeatmap(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	heatmap, err := c.analyticsUC.GetTrafficHeatmap(businessID, ctx.Query("start_date"), ctx.Query("end_date"), ctx.Query("device_id"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, heatmap)
+}
@@ -0,0 +1,278 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type AppointmentController struct {
+	appointmentUC Usecases.AppointmentUseCase
+}
+
+func NewAppointmentController(appointmentUC Usecases.AppointmentUseCase) *AppointmentController {
+	return &AppointmentController{appointmentUC: appointmentUC}
+}
+
+// GetSlots godoc
+// @Summary      List free booking slots
+// @Description  Start times on the day, between the shop's booking hours, when the service can be booked, with the staff free at each. A service takes its duration_minutes, or the shop's booking_slot_minutes.
+// @Tags         appointments
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        service_id   query  string  true   "Service product ID"
+// @Param        date         query  string  true   "Day, YYYY-MM-DD in the shop's timezone"
+// @Param        employee_id  query  string  false  "Only this staff member's"
+// @Success      200  {array}   Domain.BookingSlot
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments/slots [get]
+// @Security     BearerAuth
+func (c *AppointmentController) GetSlots(ctx *gin.Context) {
+	serviceID, date := ctx.Query("service_id"), ctx.Query("date")
+	if serviceID == "" || date == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "service_id and date are required")
+		return
+	}
+
+	var employeeID *string
+	if id := ctx.Query("employee_id"); id != "" {
+		employeeID = &id
+	}
+
+	slots, err := c.appointmentUC.GetSlots(ctx.Request.Context(), ctx.Param("businessId"), serviceID, date, employeeID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, slots)
+}
+
+// BookAppointment godoc
+// @Summary      Book an appointment
+// @Description  Books the customer in for a service, with the staff member asked for or whoever is free. The customer is texted a reminder booking_reminder_hours ahead.
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        request     body  Domain.CreateAppointmentRequest  true  "Appointment"
+// @Success      201  {object}  Domain.Appointment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments [post]
+// @Security     BearerAuth
+func (c *AppointmentController) BookAppointment(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateAppointmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	appointment, err := c.appointmentUC.BookAppointment(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, appointment)
+}
+
+// GetAppointments godoc
+// @Summary      List appointments
+// @Description  By start time
+// @Tags         appointments
+// @Produce      json
+// @Param        businessId      path   string  true   "Business ID"
+// @Param        from            query  string  false  "Starting at or after (RFC 3339)"
+// @Param        to              query  string  false  "Starting before (RFC 3339)"
+// @Param        employee_id     query  string  false  "Only this staff member's"
+// @Param        status          query  string  false  "Status: booked, completed, cancelled, no_show"
+// @Param        customer_phone  query  string  false  "Only this customer's"
+// @Param        limit           query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset          query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Appointment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments [get]
+// @Security     BearerAuth
+func (c *AppointmentController) GetAppointments(ctx *gin.Context) {
+	var filters Domain.AppointmentFilters
+	if from, err := time.Parse(time.RFC3339, ctx.Query("from")); err == nil {
+		filters.From = &from
+	}
+	if to, err := time.Parse(time.RFC3339, ctx.Query("to")); err == nil {
+		filters.To = &to
+	}
+	if employeeID := ctx.Query("employee_id"); employeeID != "" {
+		filters.EmployeeID = &employeeID
+	}
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.AppointmentStatus(status)
+		filters.Status = &s
+	}
+	if phone := ctx.Query("customer_phone"); phone != "" {
+		filters.CustomerPhone = &phone
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	appointments, err := c.appointmentUC.GetAppointments(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, appointments)
+}
+
+// GetAppointment godoc
+// @Summary      Get an appointment
+// @Tags         appointments
+// @Produce      json
+// @Param        businessId     path  string  true  "Business ID"
+// @Param        appointmentId  path  string  true  "Appointment ID"
+// @Success      200  {object}  Domain.Appointment
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments/{appointmentId} [get]
+// @Security     BearerAuth
+func (c *AppointmentController) GetAppointment(ctx *gin.Context) {
+	appointment, err := c.appointmentUC.GetAppointment(ctx.Request.Context(), ctx.Param("appointmentId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, appointment)
+}
+
+// RescheduleAppointment godoc
+// @Summary      Reschedule an appointment
+// @Description  Moves a booked appointment, staying with the same staff member unless another is given; the customer is reminded again
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        businessId     path  string                               true  "Business ID"
+// @Param        appointmentId  path  string                               true  "Appointment ID"
+// @Param        request        body  Domain.RescheduleAppointmentRequest  true  "New time"
+// @Success      200  {object}  Domain.Appointment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments/{appointmentId}/reschedule [post]
+// @Security     BearerAuth
+func (c *AppointmentController) RescheduleAppointment(ctx *gin.Context) {
+	var req Domain.RescheduleAppointmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	appointment, err := c.appointmentUC.RescheduleAppointment(ctx.Request.Context(), ctx.Param("appointmentId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, appointment)
+}
+
+// CancelAppointment godoc
+// @Summary      Cancel an appointment
+// @Tags         appointments
+// @Produce      json
+// @Param        businessId     path  string  true  "Business ID"
+// @Param        appointmentId  path  string  true  "Appointment ID"
+// @Success      200  {object}  Domain.Appointment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments/{appointmentId}/cancel [post]
+// @Security     BearerAuth
+func (c *AppointmentController) CancelAppointment(ctx *gin.Context) {
+	appointment, err := c.appointmentUC.CancelAppointment(ctx.Request.Context(), ctx.Param("appointmentId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, appointment)
+}
+
+// MarkNoShow godoc
+// @Summary      Mark an appointment a no-show
+// @Description  The customer did not come; only once the appointment has started
+// @Tags         appointments
+// @Produce      json
+// @Param        businessId     path  string  true  "Business ID"
+// @Param        appointmentId  path  string  true  "Appointment ID"
+// @Success      200  {object}  Domain.Appointment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments/{appointmentId}/no-show [post]
+// @Security     BearerAuth
+func (c *AppointmentController) MarkNoShow(ctx *gin.Context) {
+	appointment, err := c.appointmentUC.MarkNoShow(ctx.Request.Context(), ctx.Param("appointmentId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, appointment)
+}
+
+// CompleteAppointment godoc
+// @Summary      Complete an appointment
+// @Description  Records the sale of the service at the price when booked unless given, credited to the staff member who did it
+// @Tags         appointments
+// @Accept       json
+// @Produce      json
+// @Param        businessId     path  string                             true  "Business ID"
+// @Param        appointmentId  path  string                             true  "Appointment ID"
+// @Param        request        body  Domain.CompleteAppointmentRequest  true  "Payment"
+// @Success      200  {object}  Domain.Appointment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/appointments/{appointmentId}/complete [post]
+// @Security     BearerAuth
+func (c *AppointmentController) CompleteAppointment(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CompleteAppointmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	appointment, err := c.appointmentUC.CompleteAppointment(ctx.Request.Context(), ctx.Param("appointmentId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, appointment)
+}
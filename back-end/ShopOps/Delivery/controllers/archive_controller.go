@@ -0,0 +1,79 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ArchiveController struct {
+	archiveUC Usecases.ArchiveUseCase
+}
+
+func NewArchiveController(archiveUC Usecases.ArchiveUseCase) *ArchiveController {
+	return &ArchiveController{archiveUC: archiveUC}
+}
+
+// GetArchiveRuns godoc
+// @Summary      List archive runs
+// @Description  Runs that moved old sales and stock movements to the archive, newest first. The daily run is only listed when it moved something or failed. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        limit  query  int  false  "Limit results (default 20, max 100)"
+// @Success      200  {array}   Domain.ArchiveRun
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/admin/archive [get]
+// @Security     BearerAuth
+func (c *ArchiveController) GetArchiveRuns(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	runs, err := c.archiveUC.GetRuns(limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
+
+// RunArchive godoc
+// @Summary      Run the archive
+// @Description  Queue a job moving sales and stock movements created before the date to the archive, optionally compacting the live collections afterwards. The date cannot be later than the archive age (ARCHIVE_AFTER_DAYS). Reports keep covering archived days. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.RunArchiveRequest  true  "Archive run"
+// @Success      202  {object}  Domain.Job
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/admin/archive [post]
+// @Security     BearerAuth
+func (c *ArchiveController) RunArchive(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RunArchiveRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.archiveUC.TriggerArchive(ctx.Request.Context(), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, job)
+}
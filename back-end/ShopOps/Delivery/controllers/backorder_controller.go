@@ -0,0 +1,139 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BackorderController struct {
+	backorderUC Usecases.BackorderUseCase
+}
+
+func NewBackorderController(backorderUC Usecases.BackorderUseCase) *BackorderController {
+	return &BackorderController{backorderUC: backorderUC}
+}
+
+// GetBackorders godoc
+// @Summary      List backorders
+// @Description  What sales sold beyond stock, newest first. Stock coming in is set aside for open backorders oldest first; a backorder set aside in full is ready and its customer is texted.
+// @Tags         backorders
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        product_id  query  string  false  "Only this product's"
+// @Param        status      query  string  false  "Status: open, ready, collected, cancelled"
+// @Param        limit       query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Backorder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/backorders [get]
+// @Security     BearerAuth
+func (c *BackorderController) GetBackorders(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var filters Domain.BackorderFilters
+	if productID := ctx.Query("product_id"); productID != "" {
+		filters.ProductID = &productID
+	}
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.BackorderStatus(status)
+		filters.Status = &s
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	backorders, err := c.backorderUC.GetBackorders(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backorders)
+}
+
+// GetBackorder godoc
+// @Summary      Get a backorder
+// @Tags         backorders
+// @Produce      json
+// @Param        businessId   path  string  true  "Business ID"
+// @Param        backorderId  path  string  true  "Backorder ID"
+// @Success      200  {object}  Domain.Backorder
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/backorders/{backorderId} [get]
+// @Security     BearerAuth
+func (c *BackorderController) GetBackorder(ctx *gin.Context) {
+	backorder, err := c.backorderUC.GetBackorder(ctx.Request.Context(), ctx.Param("backorderId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backorder)
+}
+
+// CollectBackorder godoc
+// @Summary      Collect a backorder
+// @Description  The customer picked up their ready backorder
+// @Tags         backorders
+// @Produce      json
+// @Param        businessId   path  string  true  "Business ID"
+// @Param        backorderId  path  string  true  "Backorder ID"
+// @Success      200  {object}  Domain.Backorder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/backorders/{backorderId}/collect [post]
+// @Security     BearerAuth
+func (c *BackorderController) CollectBackorder(ctx *gin.Context) {
+	backorder, err := c.backorderUC.CollectBackorder(ctx.Request.Context(), ctx.Param("backorderId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backorder)
+}
+
+// CancelBackorder godoc
+// @Summary      Cancel a backorder
+// @Description  Give up on an open or ready backorder; what was set aside for it goes back into stock. Voiding the sale cancels its backorder too.
+// @Tags         backorders
+// @Produce      json
+// @Param        businessId   path  string  true  "Business ID"
+// @Param        backorderId  path  string  true  "Backorder ID"
+// @Success      200  {object}  Domain.Backorder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/backorders/{backorderId}/cancel [post]
+// @Security     BearerAuth
+func (c *BackorderController) CancelBackorder(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	backorder, err := c.backorderUC.CancelBackorder(ctx.Request.Context(), ctx.Param("backorderId"), ctx.Param("businessId"), userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backorder)
+}
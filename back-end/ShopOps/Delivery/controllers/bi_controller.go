@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BIController struct {
+	biUC Usecases.BIUseCase
+}
+
+func NewBIController(biUC Usecases.BIUseCase) *BIController {
+	return &BIController{biUC: biUC}
+}
+
+// CreateBIKey godoc
+// @Summary      Create a BI key
+// @Description  Issue a key for a BI tool such as Metabase or Power BI to read the shop's data feed with. The key is only shown in this response. Customers' names and phone numbers are masked in the feed unless include_pii is set. Owners only.
+// @Tags         bi
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                     true  "Business ID"
+// @Param        request     body  Domain.CreateBIKeyRequest  true  "Key name"
+// @Success      201  {object}  Domain.BIKeyTokenResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/bi/keys [post]
+// @Security     BearerAuth
+func (c *BIController) CreateBIKey(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateBIKeyRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	if req.IncludePII {
+		if tenant, ok := Infrastructure.CurrentTenant(ctx); !ok || !tenant.CanViewPII() {
+			Infrastructure.AbortWithError(ctx, Domain.AccessDeniedError("Only the business owner can create a key that reads customers' details"))
+			return
+		}
+	}
+
+	key, err := c.biUC.CreateKey(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, key)
+}
+
+// GetBIKeys godoc
+// @Summary      List BI keys
+// @Description  Get the business's BI keys and when each was last used. Owners only.
+// @Tags         bi
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.BIKey
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/bi/keys [get]
+// @Security     BearerAuth
+func (c *BIController) GetBIKeys(ctx *gin.Context) {
+	keys, err := c.biUC.GetKeys(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, keys)
+}
+
+// DeleteBIKey godoc
+// @Summary      Revoke a BI key
+// @Description  The key stops working at once. Owners only.
+// @Tags         bi
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        keyId       path  string  true  "Key ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/bi/keys/{keyId} [delete]
+// @Security     BearerAuth
+func (c *BIController) DeleteBIKey(ctx *gin.Context) {
+	if err := c.biUC.DeleteKey(ctx.Param("keyId"), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "BI key revoked successfully"})
+}
+
+// GetBIDatasets godoc
+// @Summary      List BI datasets (BI key)
+// @Description  The datasets the feed offers, the field each is ordered by and the fields its rows have. Called with a BI key in place of a user's token.
+// @Tags         bi
+// @Produce      json
+// @Success      200  {array}   Domain.BIDatasetInfo
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/bi/datasets [get]
+// @Security     BearerAuth
+func (c *BIController) GetBIDatasets(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.biUC.GetDatasets())
+}
+
+// GetBIDataset godoc
+// @Summary      Read a BI dataset (BI key)
+// @Description  Rows changed after since, oldest change first, up to as_of. The first page fixes as_of a minute before now; pass next_cursor (also in X-Next-Cursor) for the pages after it, which read the same window, so rows changed during a sync are left to the next one. Start the next sync with since set to this one's as_of. Customers' names and phone numbers are masked unless the key was created with include_pii. Counted against a BI rate limit of its own. Called with a BI key in place of a user's token.
+// @Tags         bi
+// @Produce      json
+// @Param        dataset  path   string  true   "sales, products, customers, expenses or stock_movements"
+// @Param        since    query  string  false  "Rows changed after this time (RFC 3339); every row when empty"
+// @Param        cursor   query  string  false  "next_cursor of the previous page"
+// @Param        limit    query  int     false  "Rows per page (default 500, at most 5000)"
+// @Param        fields   query  string  false  "Comma-separated fields to return; id is always returned"
+// @Success      200  {object}  Domain.BIPage
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      429  {object}  map[string]interface{}
+// @Router       /api/v1/bi/datasets/{dataset} [get]
+// @Security     BearerAuth
+func (c *BIController) GetBIDataset(ctx *gin.Context) {
+	var req Domain.BIPageRequest
+	req.IncludePII = ctx.MustGet("biKey").(*Domain.BIKey).IncludePII
+	if sinceStr := ctx.Query("since"); sinceStr != "" {
+		since, err := time.Parse(time.RFC3339, sinceStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "since must be an RFC 3339 time")
+			return
+		}
+		req.Since = &since
+	}
+	req.Cursor = ctx.Query("cursor")
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		req.Limit = limit
+	}
+	if fields := ctx.Query("fields"); fields != "" {
+		for _, field := range strings.Split(fields, ",") {
+			if field = strings.TrimSpace(field); field != "" {
+				req.Fields = append(req.Fields, field)
+			}
+		}
+	}
+
+	page, err := c.biUC.GetPage(ctx.GetString("businessID"), Domain.BIDataset(ctx.Param("dataset")), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if page.NextCursor != "" {
+		ctx.Header("X-Next-Cursor", page.NextCursor)
+	}
+	ctx.JSON(http.StatusOK, page)
+}
@@ -0,0 +1,145 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BillingController struct {
+	billingUC Usecases.BillingUseCase
+}
+
+func NewBillingController(billingUC Usecases.BillingUseCase) *BillingController {
+	return &BillingController{billingUC: billingUC}
+}
+
+// GetPlans godoc
+// @Summary      List subscription plans
+// @Description  The plans on offer with their prices and limits, and the providers that can take payment. A limit of 0 is unlimited.
+// @Tags         billing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/billing/plans [get]
+// @Security     BearerAuth
+func (c *BillingController) GetPlans(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{
+		"plans":     c.billingUC.GetPlans(),
+		"providers": c.billingUC.Providers(),
+	})
+}
+
+// GetSubscription godoc
+// @Summary      Get the shop's subscription
+// @Description  The shop's plan, where its payments stand and the plan it currently gets, which is free once the trial, paid period or grace period has run out. New shops start on a trial.
+// @Tags         billing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.SubscriptionResponse
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/billing/subscription [get]
+// @Security     BearerAuth
+func (c *BillingController) GetSubscription(ctx *gin.Context) {
+	subscription, err := c.billingUC.GetSubscription(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+// CreateCheckout godoc
+// @Summary      Pay for a plan
+// @Description  Returns the provider's page to pay on. Chapa payments buy a month at a time in birr; Stripe subscriptions renew monthly in dollars until cancelled. The subscription changes once the provider confirms the payment.
+// @Tags         billing
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.CreateCheckoutRequest  true  "Plan and provider"
+// @Success      200  {object}  Domain.CheckoutResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/billing/checkout [post]
+// @Security     BearerAuth
+func (c *BillingController) CreateCheckout(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateCheckoutRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	checkout, err := c.billingUC.CreateCheckout(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, checkout)
+}
+
+// CancelSubscription godoc
+// @Summary      Cancel the shop's subscription
+// @Description  Stops renewals. The shop keeps its plan until the paid period ends, then moves to the free plan.
+// @Tags         billing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.SubscriptionResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/billing/subscription/cancel [post]
+// @Security     BearerAuth
+func (c *BillingController) CancelSubscription(ctx *gin.Context) {
+	subscription, err := c.billingUC.CancelSubscription(ctx.Request.Context(), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+// BillingWebhook receives subscription payments and changes from Chapa and Stripe,
+// authenticated by each provider's signature. Errors other than a bad webhook answer
+// 500 so that the provider tries again.
+func (c *BillingController) BillingWebhook(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	provider := Domain.BillingProvider(ctx.Param("provider"))
+	err = c.billingUC.HandleWebhook(ctx.Request.Context(), provider, body, ctx.Request.Header)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, Infrastructure.ErrBillingBadWebhook) {
+			status = http.StatusBadRequest
+		}
+		Infrastructure.JSONError(ctx, status, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"received": true})
+}
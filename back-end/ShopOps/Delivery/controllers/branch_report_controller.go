@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"net/http"
+	"strings"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type BranchReportController struct {
+	branchReportUC Usecases.BranchReportUseCase
+}
+
+func NewBranchReportController(branchReportUC Usecases.BranchReportUseCase) *BranchReportController {
+	return &BranchReportController{branchReportUC: branchReportUC}
+}
+
+// GetConsolidatedPnL godoc
+// @Summary      Get consolidated profit & loss
+// @Description  P&L added up across the user's shops, with each branch's totals and periods for drilling down
+// @Tags         branches
+// @Produce      json
+// @Param        branches     query  string  false  "Comma separated business IDs, defaults to all the user's open shops"
+// @Param        start_date   query  string  false  "Start date (YYYY-MM-DD), defaults to 29 days before end_date"
+// @Param        end_date     query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Param        granularity  query  string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly (default daily)"
+// @Param        calendar     query  string  false  "Calendar for buckets and labels: gregorian (default) or ethiopian"
+// @Success      200  {object}  Domain.ConsolidatedPnLReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/branches/reports/pnl [get]
+// @Security     BearerAuth
+func (c *BranchReportController) GetConsolidatedPnL(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	report, err := c.branchReportUC.GetConsolidatedPnL(
+		userID.(string),
+		parseBranchIDs(ctx),
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+		Domain.PeriodType(ctx.Query("granularity")),
+		parseCalendar(ctx),
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetConsolidatedCategoryMargins godoc
+// @Summary      Get consolidated margins by category
+// @Description  Category revenue and gross margin added up across the user's shops, with each branch's share of every category
+// @Tags         branches
+// @Produce      json
+// @Param        branches    query  string  false  "Comma separated business IDs, defaults to all the user's open shops"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to 29 days before end_date"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  Domain.ConsolidatedMarginReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/branches/reports/margins/categories [get]
+// @Security     BearerAuth
+func (c *BranchReportController) GetConsolidatedCategoryMargins(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	report, err := c.branchReportUC.GetConsolidatedCategoryMargins(
+		userID.(string),
+		parseBranchIDs(ctx),
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// CompareBranches godoc
+// @Summary      Compare branches
+// @Description  Branches side by side, ranked on one metric against the branch average, optionally with each branch's growth over the prior period
+// @Tags         branches
+// @Produce      json
+// @Param        branches    query  string  false  "Comma separated business IDs, defaults to all the user's open shops"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to 29 days before end_date"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Param        rank_by     query  string  false  "revenue (default), gross_profit, gross_margin, net_profit, net_margin, transactions or average_ticket"
+// @Param        compare     query  string  false  "Add growth over the prior period: previous_period, last_week, last_month, last_year"
+// @Param        align       query  string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Success      200  {object}  Domain.BranchComparison
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/branches/reports/compare [get]
+// @Security     BearerAuth
+func (c *BranchReportController) CompareBranches(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var opts *Domain.CompareOptions
+	if parsed, ok := parseCompareOptions(ctx); ok {
+		opts = &parsed
+	}
+
+	comparison, err := c.branchReportUC.CompareBranches(
+		userID.(string),
+		parseBranchIDs(ctx),
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+		Domain.BranchRankBy(ctx.Query("rank_by")),
+		opts,
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, comparison)
+}
+
+// parseBranchIDs reads the comma separated branches query parameter
+func parseBranchIDs(ctx *gin.Context) []string {
+	var ids []string
+	for _, id := range strings.Split(ctx.Query("branches"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
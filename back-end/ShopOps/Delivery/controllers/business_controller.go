@@ -102,6 +102,7 @@ func (c *BusinessController) GetBusiness(ctx *gin.Context) {
 		return
 	}
 
+	Infrastructure.SetLastModified(ctx, business.UpdatedAt)
 	ctx.JSON(http.StatusOK, business)
 }
 
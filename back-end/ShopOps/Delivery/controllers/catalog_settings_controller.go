@@ -0,0 +1,70 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CatalogSettingsController struct {
+	settingsUC Usecases.CatalogSettingsUseCase
+}
+
+func NewCatalogSettingsController(settingsUC Usecases.CatalogSettingsUseCase) *CatalogSettingsController {
+	return &CatalogSettingsController{settingsUC: settingsUC}
+}
+
+// GetCatalogSettings godoc
+// @Summary      Get catalog settings
+// @Description  The categories, units and tax rates the POS offers when a product is added or sold
+// @Tags         inventory
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.CatalogSettings
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/settings [get]
+// @Security     BearerAuth
+func (c *CatalogSettingsController) GetCatalogSettings(ctx *gin.Context) {
+	settings, err := c.settingsUC.GetSettings(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	Infrastructure.SetLastModified(ctx, settings.UpdatedAt)
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateCatalogSettings godoc
+// @Summary      Update catalog settings
+// @Description  Replace the lists given: categories, units or tax rates. Names must be unique, and at most one tax rate can be the default. Products keep the values they have. Owners only.
+// @Tags         inventory
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        request     body  Domain.UpdateCatalogSettingsRequest  true  "Lists to replace"
+// @Success      200  {object}  Domain.CatalogSettings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/settings [patch]
+// @Security     BearerAuth
+func (c *CatalogSettingsController) UpdateCatalogSettings(ctx *gin.Context) {
+	var req Domain.UpdateCatalogSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	settings, err := c.settingsUC.UpdateSettings(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
@@ -0,0 +1,214 @@
+package controllers
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ConsignmentController struct {
+	consignmentUC Usecases.ConsignmentUseCase
+}
+
+func NewConsignmentController(consignmentUC Usecases.ConsignmentUseCase) *ConsignmentController {
+	return &ConsignmentController{consignmentUC: consignmentUC}
+}
+
+// SetProductConsignment godoc
+// @Summary      Put a product on consignment
+// @Description  The product's stock belongs to the consignor, a supplier, until it sells. Each sale is owed to them on their payables ledger: payout_per_unit for every unit sold, or the sale amount less the shop's commission_percent. Consigned stock is left out of the inventory valuation.
+// @Tags         consignment
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        productId   path  string                               true  "Product ID"
+// @Param        request     body  Domain.SetProductConsignmentRequest  true  "Consignor and terms"
+// @Success      200  {object}  Domain.Product
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/consignment [put]
+// @Security     BearerAuth
+func (c *ConsignmentController) SetProductConsignment(ctx *gin.Context) {
+	var req Domain.SetProductConsignmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	product, err := c.consignmentUC.SetProductConsignment(ctx.Request.Context(), ctx.Param("productId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+// ClearProductConsignment godoc
+// @Summary      Take a product off consignment
+// @Description  The product's stock is the shop's own from now on; sales already made stay owed to the consignor
+// @Tags         consignment
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      200  {object}  Domain.Product
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/consignment [delete]
+// @Security     BearerAuth
+func (c *ConsignmentController) ClearProductConsignment(ctx *gin.Context) {
+	product, err := c.consignmentUC.ClearProductConsignment(ctx.Request.Context(), ctx.Param("productId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+// ReceiveGoods godoc
+// @Summary      Receive consigned goods
+// @Description  Bring goods a consignor leaves with the shop into stock. Every product must be on consignment from them.
+// @Tags         consignment
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                          true  "Business ID"
+// @Param        request     body  Domain.ConsignmentGoodsRequest  true  "Consignor and goods"
+// @Success      201  {array}   Domain.ConsignmentEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/consignments/receive [post]
+// @Security     BearerAuth
+func (c *ConsignmentController) ReceiveGoods(ctx *gin.Context) {
+	c.moveGoods(ctx, c.consignmentUC.ReceiveGoods)
+}
+
+// ReturnGoods godoc
+// @Summary      Return consigned goods
+// @Description  Take unsold goods the consignor collects out of stock
+// @Tags         consignment
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                          true  "Business ID"
+// @Param        request     body  Domain.ConsignmentGoodsRequest  true  "Consignor and goods"
+// @Success      201  {array}   Domain.ConsignmentEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}  "Not enough stock to return"
+// @Router       /api/v1/businesses/{businessId}/consignments/return [post]
+// @Security     BearerAuth
+func (c *ConsignmentController) ReturnGoods(ctx *gin.Context) {
+	c.moveGoods(ctx, c.consignmentUC.ReturnGoods)
+}
+
+func (c *ConsignmentController) moveGoods(ctx *gin.Context, move func(ctx context.Context, businessID, userID string, req Domain.ConsignmentGoodsRequest) ([]Domain.ConsignmentEntry, error)) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ConsignmentGoodsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	entries, err := move(ctx.Request.Context(), businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entries)
+}
+
+// GetEntries godoc
+// @Summary      List the consignment ledger
+// @Description  Goods received from and returned to consignors, and what sales of their goods owe them, newest first
+// @Tags         consignment
+// @Produce      json
+// @Param        businessId    path   string  true   "Business ID"
+// @Param        consignor_id  query  string  false  "Only this consignor's"
+// @Param        product_id    query  string  false  "Only this product's"
+// @Param        type          query  string  false  "Type: received, returned, sold"
+// @Param        limit         query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset        query  int     false  "Offset results"
+// @Success      200  {array}   Domain.ConsignmentEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/consignments [get]
+// @Security     BearerAuth
+func (c *ConsignmentController) GetEntries(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var filters Domain.ConsignmentEntryFilters
+	if consignorID := ctx.Query("consignor_id"); consignorID != "" {
+		filters.ConsignorID = &consignorID
+	}
+	if productID := ctx.Query("product_id"); productID != "" {
+		filters.ProductID = &productID
+	}
+	if entryType := ctx.Query("type"); entryType != "" {
+		t := Domain.ConsignmentEntryType(entryType)
+		filters.Type = &t
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	entries, err := c.consignmentUC.GetEntries(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// GetStatement godoc
+// @Summary      Consignment settlement statement
+// @Description  For settling up with a consignor: what of theirs was received, returned and sold in the period, what the sales owe them, what was paid and what is owed now. Pay them through the supplier payments endpoint.
+// @Tags         consignment
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        supplierId  path   string  true   "Consignor (supplier) ID"
+// @Param        start_date  query  string  false  "First day, YYYY-MM-DD (default 29 days before end_date)"
+// @Param        end_date    query  string  false  "Last day, YYYY-MM-DD (default today)"
+// @Success      200  {object}  Domain.ConsignmentStatement
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId}/consignment-statement [get]
+// @Security     BearerAuth
+func (c *ConsignmentController) GetStatement(ctx *gin.Context) {
+	statement, err := c.consignmentUC.GetStatement(ctx.Request.Context(), ctx.Param("supplierId"), ctx.Param("businessId"), ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statement)
+}
@@ -0,0 +1,193 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CurrencyController struct {
+	currencyUC Usecases.CurrencyUseCase
+}
+
+func NewCurrencyController(currencyUC Usecases.CurrencyUseCase) *CurrencyController {
+	return &CurrencyController{currencyUC: currencyUC}
+}
+
+// GetCurrencySettings godoc
+// @Summary      Get currency settings
+// @Description  The shop's base currency, in which sales are recorded and reported, the foreign currencies it takes, and whether their rates are fetched.
+// @Tags         currencies
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.CurrencySettings
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/currencies/settings [get]
+// @Security     BearerAuth
+func (c *CurrencyController) GetCurrencySettings(ctx *gin.Context) {
+	settings, err := c.currencyUC.GetSettings(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateCurrencySettings godoc
+// @Summary      Update currency settings
+// @Description  Set the foreign currencies the shop takes, e.g. ["USD"], whether their rates are fetched from the market every 6 hours, and how cash in the base currency is rounded, e.g. {"increment": 0.25, "mode": "nearest"}; an increment of 0 turns rounding off. Turning fetching on fetches at once. Owners only.
+// @Tags         currencies
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                                  true  "Business ID"
+// @Param        request     body  Domain.UpdateCurrencySettingsRequest  true  "Settings"
+// @Success      200  {object}  Domain.CurrencySettings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/currencies/settings [patch]
+// @Security     BearerAuth
+func (c *CurrencyController) UpdateCurrencySettings(ctx *gin.Context) {
+	var req Domain.UpdateCurrencySettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	settings, err := c.currencyUC.UpdateSettings(ctx.Request.Context(), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// GetExchangeRates godoc
+// @Summary      List exchange rates in force
+// @Description  For each accepted currency, what one unit is worth in the base currency now. Payments in a currency with no rate are refused.
+// @Tags         currencies
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.ExchangeRate
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/currencies/rates [get]
+// @Security     BearerAuth
+func (c *CurrencyController) GetExchangeRates(ctx *gin.Context) {
+	rates, err := c.currencyUC.GetRates(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+// SetExchangeRate godoc
+// @Summary      Set an exchange rate
+// @Description  Record what one unit of an accepted currency is worth in the base currency, from now on. With fetching on, the next fetch replaces it. Owners only.
+// @Tags         currencies
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                          true  "Business ID"
+// @Param        request     body  Domain.SetExchangeRateRequest  true  "Rate"
+// @Success      201  {object}  Domain.ExchangeRate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/currencies/rates [post]
+// @Security     BearerAuth
+func (c *CurrencyController) SetExchangeRate(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.SetExchangeRateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	rate, err := c.currencyUC.SetRate(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rate)
+}
+
+// GetExchangeRateHistory godoc
+// @Summary      List past exchange rates
+// @Description  The rates a currency has had, newest first; each foreign payment records the one it was converted at.
+// @Tags         currencies
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        currency    path   string  true   "Currency code, e.g. USD"
+// @Param        limit       query  int     false  "Limit results (default 100)"
+// @Success      200  {array}   Domain.ExchangeRate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/currencies/rates/{currency} [get]
+// @Security     BearerAuth
+func (c *CurrencyController) GetExchangeRateHistory(ctx *gin.Context) {
+	limit := 100
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	rates, err := c.currencyUC.GetRateHistory(ctx.Param("businessId"), ctx.Param("currency"), limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rates)
+}
+
+// GetForeignTakings godoc
+// @Summary      Get foreign currency takings
+// @Description  The foreign cash taken by completed sales over the period, by currency: the amount handed over, what it was booked at in the base currency, and the change given back. Sales reports already include it at its base currency value.
+// @Tags         currencies
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        start_date  query  string  true  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  true  "End date (YYYY-MM-DD)"
+// @Success      200  {array}   Domain.ForeignCurrencyTakings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/currencies/takings [get]
+// @Security     BearerAuth
+func (c *CurrencyController) GetForeignTakings(ctx *gin.Context) {
+	startDate, err := time.Parse("2006-01-02", ctx.Query("start_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "start_date is required as YYYY-MM-DD")
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "end_date is required as YYYY-MM-DD")
+		return
+	}
+
+	takings, err := c.currencyUC.GetTakings(ctx.Param("businessId"), startDate, endDate.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, takings)
+}
@@ -0,0 +1,104 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomFieldController struct {
+	customFieldUC Usecases.CustomFieldUseCase
+}
+
+func NewCustomFieldController(customFieldUC Usecases.CustomFieldUseCase) *CustomFieldController {
+	return &CustomFieldController{customFieldUC: customFieldUC}
+}
+
+// GetCustomFields godoc
+// @Summary      List custom fields
+// @Description  The fields the shop has added to its products, customers, suppliers and sales, inactive ones included, by position. Records carry their values under custom_fields.
+// @Tags         custom-fields
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        entity      query  string  false  "Entity: product, customer, supplier, sale"
+// @Success      200  {array}   Domain.CustomFieldDefinition
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/custom-fields [get]
+// @Security     BearerAuth
+func (c *CustomFieldController) GetCustomFields(ctx *gin.Context) {
+	fields, err := c.customFieldUC.GetFields(ctx.Param("businessId"), Domain.CustomFieldEntity(ctx.Query("entity")))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, fields)
+}
+
+// CreateCustomField godoc
+// @Summary      Add a custom field
+// @Description  Add a field to one kind of record, e.g. the IMEI of a phone. Values are checked against its type and rules whenever a record is saved or synced; a required field must be given on new records. Owners only.
+// @Tags         custom-fields
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        request     body  Domain.CreateCustomFieldRequest  true  "Field"
+// @Success      201  {object}  Domain.CustomFieldDefinition
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/custom-fields [post]
+// @Security     BearerAuth
+func (c *CustomFieldController) CreateCustomField(ctx *gin.Context) {
+	var req Domain.CreateCustomFieldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	field, err := c.customFieldUC.CreateField(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, field)
+}
+
+// UpdateCustomField godoc
+// @Summary      Update a custom field
+// @Description  Change a field's label, rules or position, or deactivate it. Its key and type cannot change. Values already on records are kept when a field is deactivated. Owners only.
+// @Tags         custom-fields
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        fieldId     path  string                            true  "Field ID"
+// @Param        request     body  Domain.UpdateCustomFieldRequest  true  "Changes"
+// @Success      200  {object}  Domain.CustomFieldDefinition
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/custom-fields/{fieldId} [patch]
+// @Security     BearerAuth
+func (c *CustomFieldController) UpdateCustomField(ctx *gin.Context) {
+	var req Domain.UpdateCustomFieldRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	field, err := c.customFieldUC.UpdateField(ctx.Request.Context(), ctx.Param("fieldId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, field)
+}
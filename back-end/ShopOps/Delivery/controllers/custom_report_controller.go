@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomReportController struct {
+	customReportUC Usecases.CustomReportUseCase
+}
+
+func NewCustomReportController(customReportUC Usecases.CustomReportUseCase) *CustomReportController {
+	return &CustomReportController{customReportUC: customReportUC}
+}
+
+// CreateReport godoc
+// @Summary      Create custom report
+// @Description  Save a report that groups sales by up to three dimensions (product, category, cashier, payment_method, device, day) and computes measures (revenue, quantity, cogs, gross_profit, margin, transactions). A schedule of daily, weekly or monthly stores a run of the report for the period just finished.
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        request     body  Domain.CreateSavedReportRequest  true  "Report definition"
+// @Success      201  {object}  Domain.SavedReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom [post]
+// @Security     BearerAuth
+func (c *CustomReportController) CreateReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateSavedReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	report, err := c.customReportUC.CreateReport(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, report)
+}
+
+// GetReports godoc
+// @Summary      List custom reports
+// @Description  Get the saved report definitions of a business
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.SavedReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom [get]
+// @Security     BearerAuth
+func (c *CustomReportController) GetReports(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	reports, err := c.customReportUC.GetReports(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reports)
+}
+
+// PreviewReport godoc
+// @Summary      Preview custom report
+// @Description  Run a report definition without saving it
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path   string                         true   "Business ID"
+// @Param        start_date  query  string                         false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string                         false  "End date (YYYY-MM-DD)"
+// @Param        request     body   Domain.CustomReportDefinition  true   "Report definition"
+// @Success      200  {object}  Domain.CustomReportResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom/preview [post]
+// @Security     BearerAuth
+func (c *CustomReportController) PreviewReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var definition Domain.CustomReportDefinition
+	if err := ctx.ShouldBindJSON(&definition); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	result, err := c.customReportUC.PreviewReport(businessID, definition, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetReport godoc
+// @Summary      Get custom report
+// @Description  Get a single saved report definition
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        reportId    path  string  true  "Report ID"
+// @Success      200  {object}  Domain.SavedReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom/{reportId} [get]
+// @Security     BearerAuth
+func (c *CustomReportController) GetReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	reportID := ctx.Param("reportId")
+
+	if businessID == "" || reportID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Report ID are required")
+		return
+	}
+
+	report, err := c.customReportUC.GetReportByID(ctx.Request.Context(), reportID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// UpdateReport godoc
+// @Summary      Update custom report
+// @Description  Rename a saved report, replace its definition or change its schedule
+// @Tags         reports
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        reportId    path  string                           true  "Report ID"
+// @Param        request     body  Domain.UpdateSavedReportRequest  true  "Fields to update"
+// @Success      200  {object}  Domain.SavedReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom/{reportId} [patch]
+// @Security     BearerAuth
+func (c *CustomReportController) UpdateReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	reportID := ctx.Param("reportId")
+
+	if businessID == "" || reportID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Report ID are required")
+		return
+	}
+
+	var req Domain.UpdateSavedReportRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	report, err := c.customReportUC.UpdateReport(ctx.Request.Context(), reportID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// DeleteReport godoc
+// @Summary      Delete custom report
+// @Description  Delete a saved report and its stored runs
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        reportId    path  string  true  "Report ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom/{reportId} [delete]
+// @Security     BearerAuth
+func (c *CustomReportController) DeleteReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	reportID := ctx.Param("reportId")
+
+	if businessID == "" || reportID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Report ID are required")
+		return
+	}
+
+	if err := c.customReportUC.DeleteReport(ctx.Request.Context(), reportID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Report deleted successfully"})
+}
+
+// RunReport godoc
+// @Summary      Run custom report
+// @Description  Run a saved report now, over the given dates or its rolling window (default the last 30 days)
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        reportId    path   string  true   "Report ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Success      200  {object}  Domain.CustomReportResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom/{reportId}/run [get]
+// @Security     BearerAuth
+func (c *CustomReportController) RunReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	reportID := ctx.Param("reportId")
+
+	if businessID == "" || reportID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Report ID are required")
+		return
+	}
+
+	result, err := c.customReportUC.RunReport(ctx.Request.Context(), reportID, businessID, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetReportRuns godoc
+// @Summary      List scheduled runs
+// @Description  Get the stored results of a report's scheduled runs, newest first
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        reportId    path   string  true   "Report ID"
+// @Param        limit       query  int     false  "Runs to return (default 10)"
+// @Success      200  {array}   Domain.SavedReportRun
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/custom/{reportId}/runs [get]
+// @Security     BearerAuth
+func (c *CustomReportController) GetReportRuns(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	reportID := ctx.Param("reportId")
+
+	if businessID == "" || reportID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Report ID are required")
+		return
+	}
+
+	limit := 10
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	runs, err := c.customReportUC.GetReportRuns(ctx.Request.Context(), reportID, businessID, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, runs)
+}
@@ -0,0 +1,367 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type CustomerController struct {
+	customerUC Usecases.CustomerUseCase
+}
+
+func NewCustomerController(customerUC Usecases.CustomerUseCase) *CustomerController {
+	return &CustomerController{customerUC: customerUC}
+}
+
+// CreateCustomer godoc
+// @Summary      Create a customer
+// @Description  Create a customer; the phone number is normalized to international format and must be unique
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.CreateCustomerRequest  true  "Customer details"
+// @Success      201  {object}  Domain.Customer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers [post]
+// @Security     BearerAuth
+func (c *CustomerController) CreateCustomer(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateCustomerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	customer, err := c.customerUC.CreateCustomer(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, customer)
+}
+
+// GetCustomers godoc
+// @Summary      List and search customers
+// @Description  Search customers by name, phone or email. Page with the next_cursor of the previous page; the total is included for the first page, offset pages or with include_total.
+// @Tags         customers
+// @Produce      json
+// @Param        businessId  path    string  true   "Business ID"
+// @Param        search      query   string  false  "Search by name, phone or email"
+// @Param        status      query   string  false  "Status: active, merged, archived (default active)"
+// @Param        tag         query   string  false  "Filter by tag"
+// @Param        tier        query   string  false  "Tier: retail, wholesale, vip"
+// @Param        marketing_opt_in  query  bool  false  "Filter by promotional SMS consent"
+// @Param        sort        query   string  false  "name (default) or -created_at"
+// @Param        cursor      query   string  false  "X-Next-Cursor from the previous page"
+// @Param        limit       query   int     false  "Limit results (default 50, max 500)"
+// @Param        offset      query   int     false  "Offset results (deprecated; page by cursor)"
+// @Param        include_total  query  bool  false  "Send the total across all pages in X-Total-Count"
+// @Success      200  {object}  Domain.CustomerListResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers [get]
+// @Security     BearerAuth
+func (c *CustomerController) GetCustomers(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.CustomerFilters{}
+
+	if search := ctx.Query("search"); search != "" {
+		filters.Search = &search
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		customerStatus := Domain.CustomerStatus(status)
+		filters.Status = &customerStatus
+	}
+
+	if tag := ctx.Query("tag"); tag != "" {
+		filters.Tag = &tag
+	}
+
+	if tier := ctx.Query("tier"); tier != "" {
+		customerTier := Domain.CustomerTier(tier)
+		filters.Tier = &customerTier
+	}
+
+	if optIn := ctx.Query("marketing_opt_in"); optIn != "" {
+		if value, err := strconv.ParseBool(optIn); err == nil {
+			filters.MarketingOptIn = &value
+		}
+	}
+
+	// Pagination
+	page, err := Infrastructure.ParsePage(ctx, Domain.CustomerSorts, "name")
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	if page.Limit == 0 {
+		page.Limit = 50
+	}
+	filters.Limit = page.Limit
+	filters.Sort = page.Sort
+	filters.After = page.After
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	// Older clients page by offset and rely on the total
+	customers, err := c.customerUC.GetCustomers(businessID, filters, page.IncludeTotal || page.After == nil)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	var last Domain.Sortable
+	if n := len(customers.Customers); n > 0 {
+		last = &customers.Customers[n-1]
+	}
+	customers.NextCursor = Infrastructure.SetNextCursor(ctx, page, len(customers.Customers), last)
+
+	ctx.JSON(http.StatusOK, customers)
+}
+
+// GetCustomer godoc
+// @Summary      Get customer details
+// @Description  Get detailed information about a specific customer
+// @Tags         customers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        customerId  path  string  true  "Customer ID"
+// @Success      200  {object}  Domain.Customer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId} [get]
+// @Security     BearerAuth
+func (c *CustomerController) GetCustomer(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	customer, err := c.customerUC.GetCustomerByID(ctx.Request.Context(), customerID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, customer)
+}
+
+// UpdateCustomer godoc
+// @Summary      Update customer
+// @Description  Update customer details. Send the version last read to get a 409 instead of overwriting someone else's changes.
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        customerId  path  string                        true  "Customer ID"
+// @Param        request     body  Domain.UpdateCustomerRequest  true  "Customer updates"
+// @Success      200  {object}  Domain.Customer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId} [patch]
+// @Security     BearerAuth
+func (c *CustomerController) UpdateCustomer(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	var req Domain.UpdateCustomerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	customer, err := c.customerUC.UpdateCustomer(ctx.Request.Context(), customerID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, customer)
+}
+
+// DeleteCustomer godoc
+// @Summary      Delete customer
+// @Description  Move a customer to the trash, where they can be restored for 30 days; sales history is kept
+// @Tags         customers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        customerId  path  string  true  "Customer ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId} [delete]
+// @Security     BearerAuth
+func (c *CustomerController) DeleteCustomer(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	if err := c.customerUC.DeleteCustomer(ctx.Request.Context(), customerID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Customer deleted successfully"})
+}
+
+// GetCustomerSales godoc
+// @Summary      Get customer purchase history
+// @Description  Get sales (including credit sales) recorded against the customer's phone
+// @Tags         customers
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        customerId  path   string  true   "Customer ID"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Sale
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId}/sales [get]
+// @Security     BearerAuth
+func (c *CustomerController) GetCustomerSales(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	offset := 0
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	sales, err := c.customerUC.GetCustomerSales(ctx.Request.Context(), customerID, businessID, limit, offset)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, sales)
+}
+
+// GetDuplicates godoc
+// @Summary      Find duplicate customers
+// @Description  Group customers that share a phone number or have very similar names
+// @Tags         customers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.DuplicateCustomerGroup
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/duplicates [get]
+// @Security     BearerAuth
+func (c *CustomerController) GetDuplicates(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	groups, err := c.customerUC.FindDuplicates(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, groups)
+}
+
+// MergeCustomers godoc
+// @Summary      Merge customers
+// @Description  Merge source customers into this one, reassigning sales, gift cards, store credit and loyalty points
+// @Tags         customers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        customerId  path  string                        true  "Target customer ID"
+// @Param        request     body  Domain.MergeCustomersRequest  true  "Customers to merge"
+// @Success      200  {object}  Domain.MergeCustomersResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId}/merge [post]
+// @Security     BearerAuth
+func (c *CustomerController) MergeCustomers(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.MergeCustomersRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	result, err := c.customerUC.MergeCustomers(ctx.Request.Context(), customerID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
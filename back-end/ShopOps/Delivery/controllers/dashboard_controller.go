@@ -0,0 +1,49 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DashboardController struct {
+	dashboardUC Usecases.DashboardUseCase
+}
+
+func NewDashboardController(dashboardUC Usecases.DashboardUseCase) *DashboardController {
+	return &DashboardController{dashboardUC: dashboardUC}
+}
+
+// GetDashboard godoc
+// @Summary      Get dashboard widgets
+// @Description  Today's sales, cash position, low stock, top products and the week and month overview in one call. Cached per shop for 30 seconds and cleared when a device syncs.
+// @Tags         dashboard
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        refresh     query  bool    false  "Skip the cache"
+// @Success      200  {object}  Domain.DashboardWidgets
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/dashboard [get]
+// @Security     BearerAuth
+func (c *DashboardController) GetDashboard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	refresh, _ := strconv.ParseBool(ctx.Query("refresh"))
+
+	widgets, err := c.dashboardUC.GetDashboard(businessID, refresh)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, widgets)
+}
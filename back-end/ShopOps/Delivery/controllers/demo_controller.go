@@ -0,0 +1,67 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DemoController struct {
+	demoUC Usecases.DemoUseCase
+}
+
+func NewDemoController(demoUC Usecases.DemoUseCase) *DemoController {
+	return &DemoController{demoUC: demoUC}
+}
+
+// TryDemo godoc
+// @Summary      Try the demo
+// @Description  Create a demo shop with generated products, customers and 90 days of sales under a new throwaway account, and sign in to it. The phone and password returned sign in again until the shop expires (DEMO_TTL), when it is deleted with the account. Only available when DEMO_ENABLED is set, and while fewer than DEMO_MAX_SHOPS demo shops are live.
+// @Tags         auth
+// @Produce      json
+// @Success      201  {object}  Domain.DemoSession
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /api/v1/demo [post]
+func (c *DemoController) TryDemo(ctx *gin.Context) {
+	session, err := c.demoUC.TryDemo()
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
+
+// CreateDemoShop godoc
+// @Summary      Create a demo shop
+// @Description  Create a demo shop of the requested size under a new throwaway account, for sales demos and load tests. The same seed generates the same products, customers and sales. The shop is deleted with its account after keep_days, or DEMO_TTL. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.CreateDemoRequest  true  "Demo shop size"
+// @Success      201  {object}  Domain.DemoSession
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/admin/demo-shops [post]
+// @Security     BearerAuth
+func (c *DemoController) CreateDemoShop(ctx *gin.Context) {
+	var req Domain.CreateDemoRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	session, err := c.demoUC.CreateDemoShop(req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, session)
+}
@@ -0,0 +1,238 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DepositController struct {
+	depositUC Usecases.DepositUseCase
+}
+
+func NewDepositController(depositUC Usecases.DepositUseCase) *DepositController {
+	return &DepositController{depositUC: depositUC}
+}
+
+// CreateItem godoc
+// @Summary      Add a returnable container
+// @Description  A crate, bottle or other container whose deposit is charged with the products that go out in it and credited when the empty comes back
+// @Tags         deposits
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        request     body  Domain.CreateDepositItemRequest  true  "Name and deposit"
+// @Success      201  {object}  Domain.DepositItem
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/deposits/items [post]
+// @Security     BearerAuth
+func (c *DepositController) CreateItem(ctx *gin.Context) {
+	var req Domain.CreateDepositItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	item, err := c.depositUC.CreateItem(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, item)
+}
+
+// GetItems godoc
+// @Summary      List returnable containers
+// @Tags         deposits
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.DepositItem
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/deposits/items [get]
+// @Security     BearerAuth
+func (c *DepositController) GetItems(ctx *gin.Context) {
+	items, err := c.depositUC.GetItems(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, items)
+}
+
+// UpdateItem godoc
+// @Summary      Update a returnable container
+// @Description  A new deposit applies to sales from now on. An inactive container is no longer charged but is still taken back.
+// @Tags         deposits
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        itemId      path  string                           true  "Deposit item ID"
+// @Param        request     body  Domain.UpdateDepositItemRequest  true  "Fields to change"
+// @Success      200  {object}  Domain.DepositItem
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/deposits/items/{itemId} [patch]
+// @Security     BearerAuth
+func (c *DepositController) UpdateItem(ctx *gin.Context) {
+	var req Domain.UpdateDepositItemRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	item, err := c.depositUC.UpdateItem(ctx.Param("itemId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, item)
+}
+
+// SetProductDeposits godoc
+// @Summary      Set a product's containers
+// @Description  The containers each unit of the product goes out in, e.g. a crate and 24 bottles. Sales of the product add their deposits as deposit lines on top of the price. An empty list clears them.
+// @Tags         deposits
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        productId   path  string                            true  "Product ID"
+// @Param        request     body  Domain.SetProductDepositsRequest  true  "Containers per unit"
+// @Success      200  {object}  Domain.Product
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/deposits [put]
+// @Security     BearerAuth
+func (c *DepositController) SetProductDeposits(ctx *gin.Context) {
+	var req Domain.SetProductDepositsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	product, err := c.depositUC.SetProductDeposits(ctx.Request.Context(), ctx.Param("productId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+// ReturnDeposits godoc
+// @Summary      Take back empties
+// @Description  Record empties brought back outside a sale and pay their deposit out (cash by default). Empties brought back while buying go on the sale as deposit_returns instead.
+// @Tags         deposits
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.ReturnDepositsRequest  true  "Customer and empties"
+// @Success      201  {object}  Domain.DepositReturn
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/deposits/returns [post]
+// @Security     BearerAuth
+func (c *DepositController) ReturnDeposits(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ReturnDepositsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	result, err := c.depositUC.ReturnDeposits(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, result)
+}
+
+// GetEntries godoc
+// @Summary      List the deposit ledger
+// @Description  Containers charged on sales and empties brought back, newest first
+// @Tags         deposits
+// @Produce      json
+// @Param        businessId      path   string  true   "Business ID"
+// @Param        customer_phone  query  string  false  "Only this customer's"
+// @Param        item_id         query  string  false  "Only this container's"
+// @Param        type            query  string  false  "Type: charged, returned"
+// @Param        limit           query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset          query  int     false  "Offset results"
+// @Success      200  {array}   Domain.DepositEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/deposits [get]
+// @Security     BearerAuth
+func (c *DepositController) GetEntries(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var filters Domain.DepositEntryFilters
+	if phone := ctx.Query("customer_phone"); phone != "" {
+		filters.CustomerPhone = &phone
+	}
+	if itemID := ctx.Query("item_id"); itemID != "" {
+		filters.ItemID = &itemID
+	}
+	if entryType := ctx.Query("type"); entryType != "" {
+		t := Domain.DepositEntryType(entryType)
+		filters.Type = &t
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	entries, err := c.depositUC.GetEntries(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// GetOutstanding godoc
+// @Summary      Deposits outstanding per customer
+// @Description  Each customer's containers still out and the deposit owed back on them, largest first. Walk-in sales without a phone are grouped under an empty customer_phone.
+// @Tags         deposits
+// @Produce      json
+// @Param        businessId      path   string  true   "Business ID"
+// @Param        customer_phone  query  string  false  "Only this customer's"
+// @Success      200  {array}   Domain.DepositBalance
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/deposits/outstanding [get]
+// @Security     BearerAuth
+func (c *DepositController) GetOutstanding(ctx *gin.Context) {
+	balances, err := c.depositUC.GetOutstanding(ctx.Param("businessId"), ctx.Query("customer_phone"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balances)
+}
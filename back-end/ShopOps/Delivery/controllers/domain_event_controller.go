@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type DomainEventController struct {
+	eventUC Usecases.DomainEventUseCase
+}
+
+func NewDomainEventController(eventUC Usecases.DomainEventUseCase) *DomainEventController {
+	return &DomainEventController{eventUC: eventUC}
+}
+
+// GetDomainEvents godoc
+// @Summary      List domain events
+// @Description  Events in the outbox, newest first, each with every subscriber's delivery. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        type         query  string  false  "Event type: sale.recorded, stock.adjusted"
+// @Param        status       query  string  false  "Event status: pending, delivered, dead"
+// @Param        business_id  query  string  false  "Only events of this business"
+// @Param        limit        query  int     false  "Limit results"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.DomainEvent
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/admin/events [get]
+// @Security     BearerAuth
+func (c *DomainEventController) GetDomainEvents(ctx *gin.Context) {
+	filters := Domain.DomainEventFilters{Limit: 50}
+
+	if eventType := ctx.Query("type"); eventType != "" {
+		t := Domain.DomainEventType(eventType)
+		filters.Type = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.DomainEventStatus(status)
+		filters.Status = &s
+	}
+
+	if businessID := ctx.Query("business_id"); businessID != "" {
+		filters.BusinessID = &businessID
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	events, err := c.eventUC.GetEvents(filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// GetDomainEventStats godoc
+// @Summary      Event outbox stats
+// @Description  Event counts by type and status, with the earliest next attempt in each. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   Domain.DomainEventStats
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/admin/events/stats [get]
+// @Security     BearerAuth
+func (c *DomainEventController) GetDomainEventStats(ctx *gin.Context) {
+	stats, err := c.eventUC.GetStats()
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// GetDomainEvent godoc
+// @Summary      Get a domain event
+// @Description  One event with every subscriber's attempts and last error. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        eventId  path  string  true  "Event ID"
+// @Success      200  {object}  Domain.DomainEvent
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/admin/events/{eventId} [get]
+// @Security     BearerAuth
+func (c *DomainEventController) GetDomainEvent(ctx *gin.Context) {
+	event, err := c.eventUC.GetEvent(ctx.Param("eventId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
+
+// RetryDomainEvent godoc
+// @Summary      Retry a dead domain event
+// @Description  Deliver a dead event again to the subscribers that ran out of attempts, with a fresh set. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        eventId  path  string  true  "Event ID"
+// @Success      200  {object}  Domain.DomainEvent
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/admin/events/{eventId}/retry [post]
+// @Security     BearerAuth
+func (c *DomainEventController) RetryDomainEvent(ctx *gin.Context) {
+	event, err := c.eventUC.RetryEvent(ctx.Param("eventId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, event)
+}
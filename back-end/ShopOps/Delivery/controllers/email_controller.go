@@ -0,0 +1,180 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmailController struct {
+	emailUC Usecases.EmailUseCase
+}
+
+func NewEmailController(emailUC Usecases.EmailUseCase) *EmailController {
+	return &EmailController{emailUC: emailUC}
+}
+
+// GetEmailSettings godoc
+// @Summary      Get email settings
+// @Description  The name the shop's emails show, where replies go, who gets its notifications and whether it gets the daily low stock digest. Owners only.
+// @Tags         email
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.EmailSettings
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/email/settings [get]
+// @Security     BearerAuth
+func (c *EmailController) GetEmailSettings(ctx *gin.Context) {
+	settings, err := c.emailUC.GetSettings(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	Infrastructure.SetLastModified(ctx, settings.UpdatedAt)
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateEmailSettings godoc
+// @Summary      Update email settings
+// @Description  Change the sender name, reply-to address, notification recipients (up to 5; the owner when empty) and the low stock digest and its shop-local hour. Owners only.
+// @Tags         email
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID"
+// @Param        request     body  Domain.UpdateEmailSettingsRequest  true  "Settings to change"
+// @Success      200  {object}  Domain.EmailSettings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/email/settings [patch]
+// @Security     BearerAuth
+func (c *EmailController) UpdateEmailSettings(ctx *gin.Context) {
+	var req Domain.UpdateEmailSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	settings, err := c.emailUC.UpdateSettings(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// SendTestEmail godoc
+// @Summary      Send a test email
+// @Description  Queue a test email with the shop's settings, to check it arrives. It shows in the send log. Owners only.
+// @Tags         email
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.SendTestEmailRequest  true  "Address to send to"
+// @Success      202  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/email/test [post]
+// @Security     BearerAuth
+func (c *EmailController) SendTestEmail(ctx *gin.Context) {
+	var req Domain.SendTestEmailRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if err := c.emailUC.SendTest(ctx.Request.Context(), ctx.Param("businessId"), req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, gin.H{"message": "Test email queued"})
+}
+
+// GetEmailMessages godoc
+// @Summary      Email send log
+// @Description  Emails sent, failed, skipped or bounced for the business, newest first. Owners only.
+// @Tags         email
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        template    query  string  false  "Template: export_ready, invite, low_stock_digest, backup_failed, test"
+// @Param        status      query  string  false  "Status: queued, sent, failed, skipped, bounced, complained"
+// @Param        to          query  string  false  "Filter by recipient"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.EmailMessage
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/email/messages [get]
+// @Security     BearerAuth
+func (c *EmailController) GetEmailMessages(ctx *gin.Context) {
+	filters := Domain.EmailMessageFilters{Limit: 50}
+
+	if template := ctx.Query("template"); template != "" {
+		t := Domain.EmailTemplate(template)
+		filters.Template = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.EmailStatus(status)
+		filters.Status = &s
+	}
+
+	if to := ctx.Query("to"); to != "" {
+		filters.To = &to
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	messages, err := c.emailUC.GetMessages(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, messages)
+}
+
+// EmailEvents receives bounces and complaints from the email provider: SendGrid's
+// signed event webhook, or SNS notifications from SES. Errors other than a bad
+// webhook answer 500 so that the provider tries again.
+func (c *EmailController) EmailEvents(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	err = c.emailUC.HandleEvents(ctx.Request.Context(), ctx.Param("provider"), body, ctx.Request.Header)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, Infrastructure.ErrEmailBadWebhook) {
+			status = http.StatusBadRequest
+		}
+		Infrastructure.JSONError(ctx, status, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"received": true})
+}
@@ -0,0 +1,498 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type EmployeeController struct {
+	employeeUC Usecases.EmployeeUseCase
+}
+
+func NewEmployeeController(employeeUC Usecases.EmployeeUseCase) *EmployeeController {
+	return &EmployeeController{employeeUC: employeeUC}
+}
+
+// CreateEmployee godoc
+// @Summary      Add an employee
+// @Description  Add an employee, optionally linked to a login account. Only the owner can grant permissions, such as view_pii to see customers' names and phone numbers in exports.
+// @Tags         employees
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.CreateEmployeeRequest  true  "Employee details"
+// @Success      201  {object}  Domain.Employee
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees [post]
+// @Security     BearerAuth
+func (c *EmployeeController) CreateEmployee(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateEmployeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	if len(req.Permissions) > 0 && !canGrantPermissions(ctx) {
+		return
+	}
+
+	employee, err := c.employeeUC.CreateEmployee(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, employee)
+}
+
+// GetEmployees godoc
+// @Summary      List employees
+// @Description  Get employees of the business
+// @Tags         employees
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        status      query  string  false  "Status: active, inactive"
+// @Success      200  {array}   Domain.Employee
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees [get]
+// @Security     BearerAuth
+func (c *EmployeeController) GetEmployees(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var status *Domain.EmployeeStatus
+	if s := ctx.Query("status"); s != "" {
+		employeeStatus := Domain.EmployeeStatus(s)
+		status = &employeeStatus
+	}
+
+	employees, err := c.employeeUC.GetEmployees(businessID, status)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, employees)
+}
+
+// GetEmployee godoc
+// @Summary      Get employee details
+// @Description  Get a single employee
+// @Tags         employees
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        employeeId  path  string  true  "Employee ID"
+// @Success      200  {object}  Domain.Employee
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees/{employeeId} [get]
+// @Security     BearerAuth
+func (c *EmployeeController) GetEmployee(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	employeeID := ctx.Param("employeeId")
+
+	if businessID == "" || employeeID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Employee ID are required")
+		return
+	}
+
+	employee, err := c.employeeUC.GetEmployeeByID(ctx.Request.Context(), employeeID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, employee)
+}
+
+// UpdateEmployee godoc
+// @Summary      Update employee
+// @Description  Update employee details, login link, status or permissions. Deactivating clocks the employee out. Only the owner can change permissions.
+// @Tags         employees
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        employeeId  path  string                        true  "Employee ID"
+// @Param        request     body  Domain.UpdateEmployeeRequest  true  "Employee updates"
+// @Success      200  {object}  Domain.Employee
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees/{employeeId} [patch]
+// @Security     BearerAuth
+func (c *EmployeeController) UpdateEmployee(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	employeeID := ctx.Param("employeeId")
+
+	if businessID == "" || employeeID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Employee ID are required")
+		return
+	}
+
+	var req Domain.UpdateEmployeeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	if req.Permissions != nil && !canGrantPermissions(ctx) {
+		return
+	}
+
+	employee, err := c.employeeUC.UpdateEmployee(ctx.Request.Context(), employeeID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, employee)
+}
+
+// ClockIn godoc
+// @Summary      Clock in
+// @Description  Start a shift on a device. Anyone else clocked in on the device is clocked out.
+// @Tags         employees
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                 true  "Business ID"
+// @Param        employeeId  path  string                 true  "Employee ID"
+// @Param        request     body  Domain.ClockInRequest  true  "Device details"
+// @Success      201  {object}  Domain.TimeEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees/{employeeId}/clock-in [post]
+// @Security     BearerAuth
+func (c *EmployeeController) ClockIn(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	employeeID := ctx.Param("employeeId")
+
+	if businessID == "" || employeeID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Employee ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ClockInRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	entry, err := c.employeeUC.ClockIn(ctx.Request.Context(), employeeID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// ClockOut godoc
+// @Summary      Clock out
+// @Description  End the employee's current shift
+// @Tags         employees
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                  true   "Business ID"
+// @Param        employeeId  path  string                  true   "Employee ID"
+// @Param        request     body  Domain.ClockOutRequest  false  "Shift notes"
+// @Success      200  {object}  Domain.TimeEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees/{employeeId}/clock-out [post]
+// @Security     BearerAuth
+func (c *EmployeeController) ClockOut(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	employeeID := ctx.Param("employeeId")
+
+	if businessID == "" || employeeID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Employee ID are required")
+		return
+	}
+
+	// Notes are optional
+	var req Domain.ClockOutRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	entry, err := c.employeeUC.ClockOut(ctx.Request.Context(), employeeID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entry)
+}
+
+// GetTimeEntries godoc
+// @Summary      List time entries
+// @Description  Get shifts with filtering and pagination
+// @Tags         employees
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        employee_id  query  string  false  "Employee ID"
+// @Param        device_id    query  string  false  "Device ID"
+// @Param        start_date   query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date     query  string  false  "End date (YYYY-MM-DD)"
+// @Param        open         query  bool    false  "Only shifts still clocked in"
+// @Param        limit        query  int     false  "Limit results"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.TimeEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees/time-entries [get]
+// @Security     BearerAuth
+func (c *EmployeeController) GetTimeEntries(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.TimeEntryFilters{}
+
+	if employeeID := ctx.Query("employee_id"); employeeID != "" {
+		filters.EmployeeID = &employeeID
+	}
+
+	if deviceID := ctx.Query("device_id"); deviceID != "" {
+		filters.DeviceID = &deviceID
+	}
+
+	// Date filters
+	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filters.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			endOfDay := endDate.Add(24*time.Hour - time.Nanosecond)
+			filters.EndDate = &endOfDay
+		}
+	}
+
+	if open, err := strconv.ParseBool(ctx.Query("open")); err == nil {
+		filters.OpenOnly = open
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	entries, err := c.employeeUC.GetTimeEntries(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// GetCommissionReport godoc
+// @Summary      Monthly commission report
+// @Description  Sales, commission and hours worked per employee for a month
+// @Tags         employees
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        month       query  string  false  "Month (YYYY-MM), defaults to the current month"
+// @Success      200  {object}  Domain.CommissionReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/employees/commission-report [get]
+// @Security     BearerAuth
+func (c *EmployeeController) GetCommissionReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	report, err := c.employeeUC.GetCommissionReport(ctx.Request.Context(), businessID, ctx.Query("month"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// CreateCommissionRule godoc
+// @Summary      Create commission rule
+// @Description  Add a percentage or flat-per-sale commission rule, optionally for one employee and/or product category
+// @Tags         employees
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                              true  "Business ID"
+// @Param        request     body  Domain.CreateCommissionRuleRequest  true  "Rule details"
+// @Success      201  {object}  Domain.CommissionRule
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/commission-rules [post]
+// @Security     BearerAuth
+func (c *EmployeeController) CreateCommissionRule(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var req Domain.CreateCommissionRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	rule, err := c.employeeUC.CreateCommissionRule(ctx.Request.Context(), businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, rule)
+}
+
+// GetCommissionRules godoc
+// @Summary      List commission rules
+// @Description  Get all commission rules of the business
+// @Tags         employees
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.CommissionRule
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/commission-rules [get]
+// @Security     BearerAuth
+func (c *EmployeeController) GetCommissionRules(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	rules, err := c.employeeUC.GetCommissionRules(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rules)
+}
+
+// UpdateCommissionRule godoc
+// @Summary      Update commission rule
+// @Description  Change a commission rule's rate, type, category or active flag
+// @Tags         employees
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                              true  "Business ID"
+// @Param        ruleId      path  string                              true  "Commission rule ID"
+// @Param        request     body  Domain.UpdateCommissionRuleRequest  true  "Rule updates"
+// @Success      200  {object}  Domain.CommissionRule
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/commission-rules/{ruleId} [patch]
+// @Security     BearerAuth
+func (c *EmployeeController) UpdateCommissionRule(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	ruleID := ctx.Param("ruleId")
+
+	if businessID == "" || ruleID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Rule ID are required")
+		return
+	}
+
+	var req Domain.UpdateCommissionRuleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	rule, err := c.employeeUC.UpdateCommissionRule(ctx.Request.Context(), ruleID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, rule)
+}
+
+// DeleteCommissionRule godoc
+// @Summary      Delete commission rule
+// @Description  Remove a commission rule
+// @Tags         employees
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        ruleId      path  string  true  "Commission rule ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/commission-rules/{ruleId} [delete]
+// @Security     BearerAuth
+func (c *EmployeeController) DeleteCommissionRule(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	ruleID := ctx.Param("ruleId")
+
+	if businessID == "" || ruleID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Rule ID are required")
+		return
+	}
+
+	if err := c.employeeUC.DeleteCommissionRule(ctx.Request.Context(), ruleID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Commission rule deleted successfully"})
+}
+
+// canGrantPermissions answers 403 unless the caller is the owner, as employees
+// may otherwise edit their own record
+func canGrantPermissions(ctx *gin.Context) bool {
+	if tenant, ok := Infrastructure.CurrentTenant(ctx); ok && tenant.CanGrantPermissions() {
+		return true
+	}
+	Infrastructure.AbortWithError(ctx, Domain.AccessDeniedError("Only the business owner can grant permissions"))
+	return false
+}
@@ -1,7 +1,10 @@
 package controllers
 
 import (
+	"io"
+	"mime"
 	"net/http"
+	"path"
 	"strconv"
 	"time"
 
@@ -162,7 +165,7 @@ func (c *ExpenseController) GetExpense(ctx *gin.Context) {
 		return
 	}
 
-	expense, err := c.expenseUC.GetExpenseByID(expenseID, businessID)
+	expense, err := c.expenseUC.GetExpenseByID(ctx.Request.Context(), expenseID, businessID)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
 		return
@@ -211,7 +214,7 @@ func (c *ExpenseController) UpdateExpense(ctx *gin.Context) {
 		return
 	}
 
-	expense, err := c.expenseUC.UpdateExpense(expenseID, businessID, userID.(string), req)
+	expense, err := c.expenseUC.UpdateExpense(ctx.Request.Context(), expenseID, businessID, userID.(string), req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
@@ -252,7 +255,7 @@ func (c *ExpenseController) VoidExpense(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.expenseUC.VoidExpense(expenseID, businessID, userID.(string)); err != nil {
+	if err := c.expenseUC.VoidExpense(ctx.Request.Context(), expenseID, businessID, userID.(string)); err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
 	}
@@ -304,3 +307,215 @@ func (c *ExpenseController) GetExpenseCategories(ctx *gin.Context) {
 	categories := c.expenseUC.GetExpenseCategories()
 	ctx.JSON(http.StatusOK, categories)
 }
+
+// UploadExpenseReceipt godoc
+// @Summary      Attach receipt photo
+// @Description  Upload a photo or PDF of the expense receipt (jpeg, png, webp or pdf, max 5MB)
+// @Tags         expenses
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        businessId  path      string  true  "Business ID"
+// @Param        expenseId   path      string  true  "Expense ID"
+// @Param        receipt     formData  file    true  "Receipt file"
+// @Success      200  {object}  Domain.Expense
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      413  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/expenses/{expenseId}/receipt [post]
+// @Security     BearerAuth
+func (c *ExpenseController) UploadExpenseReceipt(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	expenseID := ctx.Param("expenseId")
+	if expenseID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Expense ID is required")
+		return
+	}
+
+	// Streamed straight to storage rather than buffered
+	file, err := Infrastructure.FormFileStream(ctx, "receipt", Usecases.MaxReceiptSize)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	expense, err := c.expenseUC.AttachReceipt(ctx.Request.Context(), expenseID, businessID, file.Filename, file.ContentType, file)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, expense)
+}
+
+// GetExpenseReceipt godoc
+// @Summary      Download receipt photo
+// @Description  The receipt photo or PDF uploaded for the expense. Receipts are only served here, to the shop's own staff; receipt_url is where the file is kept, not a link to it.
+// @Tags         expenses
+// @Produce      octet-stream
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        expenseId   path  string  true  "Expense ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/expenses/{expenseId}/receipt [get]
+// @Security     BearerAuth
+func (c *ExpenseController) GetExpenseReceipt(ctx *gin.Context) {
+	file, filename, err := c.expenseUC.OpenReceipt(ctx.Request.Context(), ctx.Param("expenseId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	defer file.Close()
+
+	contentType := mime.TypeByExtension(path.Ext(filename))
+	if contentType == "" {
+		contentType = "application/octet-stream"
+	}
+	ctx.Header("Content-Type", contentType)
+	ctx.Header("Content-Disposition", "inline; filename="+strconv.Quote(filename))
+	ctx.Header("X-Content-Type-Options", "nosniff")
+	ctx.Header("Cache-Control", "private, no-store")
+	ctx.Status(http.StatusOK)
+	_, _ = io.Copy(ctx.Writer, file)
+}
+
+// CreateRecurringExpense godoc
+// @Summary      Create recurring expense
+// @Description  Set up an expense that is recorded automatically every week, month or year (e.g. rent, salaries)
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                                true  "Business ID"
+// @Param        request     body  Domain.CreateRecurringExpenseRequest  true  "Recurring expense details"
+// @Success      201  {object}  Domain.RecurringExpense
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/expenses/recurring [post]
+// @Security     BearerAuth
+func (c *ExpenseController) CreateRecurringExpense(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateRecurringExpenseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	recurring, err := c.expenseUC.CreateRecurringExpense(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, recurring)
+}
+
+// GetRecurringExpenses godoc
+// @Summary      List recurring expenses
+// @Description  Get all recurring expenses with their next run date
+// @Tags         expenses
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.RecurringExpense
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/expenses/recurring [get]
+// @Security     BearerAuth
+func (c *ExpenseController) GetRecurringExpenses(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	recurring, err := c.expenseUC.GetRecurringExpenses(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recurring)
+}
+
+// UpdateRecurringExpense godoc
+// @Summary      Update recurring expense
+// @Description  Change amount, category or end date, or pause/resume a recurring expense
+// @Tags         expenses
+// @Accept       json
+// @Produce      json
+// @Param        businessId   path  string                                true  "Business ID"
+// @Param        recurringId  path  string                                true  "Recurring expense ID"
+// @Param        request      body  Domain.UpdateRecurringExpenseRequest  true  "Recurring expense updates"
+// @Success      200  {object}  Domain.RecurringExpense
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/expenses/recurring/{recurringId} [patch]
+// @Security     BearerAuth
+func (c *ExpenseController) UpdateRecurringExpense(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	recurringID := ctx.Param("recurringId")
+
+	if businessID == "" || recurringID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Recurring expense ID are required")
+		return
+	}
+
+	var req Domain.UpdateRecurringExpenseRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	recurring, err := c.expenseUC.UpdateRecurringExpense(ctx.Request.Context(), recurringID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recurring)
+}
+
+// DeleteRecurringExpense godoc
+// @Summary      Delete recurring expense
+// @Description  Stop a recurring expense; expenses already recorded are kept
+// @Tags         expenses
+// @Produce      json
+// @Param        businessId   path  string  true  "Business ID"
+// @Param        recurringId  path  string  true  "Recurring expense ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/expenses/recurring/{recurringId} [delete]
+// @Security     BearerAuth
+func (c *ExpenseController) DeleteRecurringExpense(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	recurringID := ctx.Param("recurringId")
+
+	if businessID == "" || recurringID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Recurring expense ID are required")
+		return
+	}
+
+	if err := c.expenseUC.DeleteRecurringExpense(ctx.Request.Context(), recurringID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Recurring expense deleted successfully"})
+}
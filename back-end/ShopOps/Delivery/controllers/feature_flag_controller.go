@@ -0,0 +1,170 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type FeatureFlagController struct {
+	flagUC Usecases.FeatureFlagUseCase
+}
+
+func NewFeatureFlagController(flagUC Usecases.FeatureFlagUseCase) *FeatureFlagController {
+	return &FeatureFlagController{flagUC: flagUC}
+}
+
+// GetFlags godoc
+// @Summary      List feature flags
+// @Description  Every feature flag with its rollout settings. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   Domain.FeatureFlag
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/admin/feature-flags [get]
+// @Security     BearerAuth
+func (c *FeatureFlagController) GetFlags(ctx *gin.Context) {
+	flags, err := c.flagUC.GetFlags()
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flags)
+}
+
+// GetFlag godoc
+// @Summary      Get a feature flag
+// @Description  One feature flag with its rollout settings. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        key  path  string  true  "Flag key"
+// @Success      200  {object}  Domain.FeatureFlag
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/admin/feature-flags/{key} [get]
+// @Security     BearerAuth
+func (c *FeatureFlagController) GetFlag(ctx *gin.Context) {
+	flag, err := c.flagUC.GetFlag(ctx.Param("key"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// CreateFlag godoc
+// @Summary      Create a feature flag
+// @Description  Add a flag, optionally switched on for listed shops or a percentage of all shops. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.CreateFeatureFlagRequest  true  "Flag details"
+// @Success      201  {object}  Domain.FeatureFlag
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/admin/feature-flags [post]
+// @Security     BearerAuth
+func (c *FeatureFlagController) CreateFlag(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateFeatureFlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	flag, err := c.flagUC.CreateFlag(userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, flag)
+}
+
+// UpdateFlag godoc
+// @Summary      Update a feature flag
+// @Description  Turn a flag on or off, change its rollout percentage or the shops it is forced on or off for. Takes effect on every instance within 30 seconds. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        key      path  string                           true  "Flag key"
+// @Param        request  body  Domain.UpdateFeatureFlagRequest  true  "Fields to change"
+// @Success      200  {object}  Domain.FeatureFlag
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/admin/feature-flags/{key} [patch]
+// @Security     BearerAuth
+func (c *FeatureFlagController) UpdateFlag(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.UpdateFeatureFlagRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	flag, err := c.flagUC.UpdateFlag(ctx.Param("key"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, flag)
+}
+
+// DeleteFlag godoc
+// @Summary      Delete a feature flag
+// @Description  Remove a flag; code checking it then sees it as off. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        key  path  string  true  "Flag key"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/admin/feature-flags/{key} [delete]
+// @Security     BearerAuth
+func (c *FeatureFlagController) DeleteFlag(ctx *gin.Context) {
+	if err := c.flagUC.DeleteFlag(ctx.Param("key")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Feature flag deleted successfully"})
+}
+
+// GetBusinessFeatures godoc
+// @Summary      Features enabled for a business
+// @Description  Keys of the feature flags that are on for this shop, for the apps to switch behaviour on
+// @Tags         businesses
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/features [get]
+// @Security     BearerAuth
+func (c *FeatureFlagController) GetBusinessFeatures(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"features": c.flagUC.EnabledFlags(ctx.Param("businessId"))})
+}
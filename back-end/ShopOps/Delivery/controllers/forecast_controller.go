@@ -0,0 +1,112 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ForecastController struct {
+	forecastUC Usecases.ForecastUseCase
+}
+
+func NewForecastController(forecastUC Usecases.ForecastUseCase) *ForecastController {
+	return &ForecastController{forecastUC: forecastUC}
+}
+
+// GetForecast godoc
+// @Summary      Forecast product demand
+// @Description  Project next-week demand per product from daily sales history using a moving average with a day-of-week pattern, with reorder quantities
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId    path   string  true   "Business ID"
+// @Param        product_id    query  string  false  "Only forecast this product"
+// @Param        category      query  string  false  "Only forecast products in this category"
+// @Param        history_days  query  int     false  "Days of sales history to use, 14-365 (default 56)"
+// @Param        lead_days     query  int     false  "Supplier lead time in days used for reorder quantities (default 7)"
+// @Success      200  {object}  Domain.ForecastReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/analytics/forecast [get]
+// @Security     BearerAuth
+func (c *ForecastController) GetForecast(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	opts := Domain.ForecastOptions{
+		ProductID: ctx.Query("product_id"),
+		Category:  ctx.Query("category"),
+	}
+
+	if historyStr := ctx.Query("history_days"); historyStr != "" {
+		days, err := strconv.Atoi(historyStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "history_days must be an integer")
+			return
+		}
+		opts.HistoryDays = days
+	}
+
+	if leadStr := ctx.Query("lead_days"); leadStr != "" {
+		days, err := strconv.Atoi(leadStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "lead_days must be an integer")
+			return
+		}
+		opts.LeadDays = days
+	}
+
+	report, err := c.forecastUC.GetForecast(ctx.Request.Context(), businessID, opts)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetReorderSuggestions godoc
+// @Summary      Get reorder suggestions
+// @Description  List products whose stock will not cover forecast demand over the lead time plus minimum stock, soonest to run out first
+// @Tags         analytics
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        lead_days   query  int     false  "Supplier lead time in days (default 7)"
+// @Success      200  {object}  Domain.ForecastReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/analytics/reorder-suggestions [get]
+// @Security     BearerAuth
+func (c *ForecastController) GetReorderSuggestions(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	leadDays := 0
+	if leadStr := ctx.Query("lead_days"); leadStr != "" {
+		days, err := strconv.Atoi(leadStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "lead_days must be an integer")
+			return
+		}
+		leadDays = days
+	}
+
+	report, err := c.forecastUC.GetReorderSuggestions(ctx.Request.Context(), businessID, leadDays)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
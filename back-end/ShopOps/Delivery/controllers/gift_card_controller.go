@@ -0,0 +1,389 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type GiftCardController struct {
+	giftCardUC Usecases.GiftCardUseCase
+}
+
+func NewGiftCardController(giftCardUC Usecases.GiftCardUseCase) *GiftCardController {
+	return &GiftCardController{giftCardUC: giftCardUC}
+}
+
+// IssueGiftCard godoc
+// @Summary      Issue a gift card or store credit
+// @Description  Issue a new gift card or store credit with a unique code and optional expiry
+// @Tags         gift-cards
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                       true  "Business ID"
+// @Param        request     body  Domain.IssueGiftCardRequest  true  "Gift card details"
+// @Success      201  {object}  Domain.GiftCard
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards [post]
+// @Security     BearerAuth
+func (c *GiftCardController) IssueGiftCard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.IssueGiftCardRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	card, err := c.giftCardUC.IssueGiftCard(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, card)
+}
+
+// GetGiftCards godoc
+// @Summary      List gift cards
+// @Description  Get gift cards and store credits with filtering and pagination
+// @Tags         gift-cards
+// @Produce      json
+// @Param        businessId  path    string  true   "Business ID"
+// @Param        status      query   string  false  "Status: active, redeemed, expired, voided"
+// @Param        type        query   string  false  "Type: gift_card, store_credit"
+// @Param        phone       query   string  false  "Customer phone"
+// @Param        limit       query   int     false  "Limit results"
+// @Param        offset      query   int     false  "Offset results"
+// @Success      200  {array}   Domain.GiftCard
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards [get]
+// @Security     BearerAuth
+func (c *GiftCardController) GetGiftCards(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.GiftCardFilters{}
+
+	if status := ctx.Query("status"); status != "" {
+		cardStatus := Domain.GiftCardStatus(status)
+		filters.Status = &cardStatus
+	}
+
+	if cardType := ctx.Query("type"); cardType != "" {
+		t := Domain.GiftCardType(cardType)
+		filters.Type = &t
+	}
+
+	if phone := ctx.Query("phone"); phone != "" {
+		filters.Phone = &phone
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	cards, err := c.giftCardUC.GetGiftCards(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, cards)
+}
+
+// GetGiftCard godoc
+// @Summary      Get gift card details
+// @Description  Get detailed information about a specific gift card
+// @Tags         gift-cards
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        giftCardId  path  string  true  "Gift card ID"
+// @Success      200  {object}  Domain.GiftCard
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/{giftCardId} [get]
+// @Security     BearerAuth
+func (c *GiftCardController) GetGiftCard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	giftCardID := ctx.Param("giftCardId")
+	if giftCardID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Gift card ID is required")
+		return
+	}
+
+	card, err := c.giftCardUC.GetGiftCardByID(ctx.Request.Context(), giftCardID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, card)
+}
+
+// LookupGiftCard godoc
+// @Summary      Look up gift card by code
+// @Description  Get balance and status of a gift card by its code (used at POS)
+// @Tags         gift-cards
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        code        query  string  true  "Gift card code"
+// @Success      200  {object}  Domain.GiftCard
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/lookup [get]
+// @Security     BearerAuth
+func (c *GiftCardController) LookupGiftCard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	code := ctx.Query("code")
+	if code == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Gift card code is required")
+		return
+	}
+
+	card, err := c.giftCardUC.GetGiftCardByCode(businessID, code)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, card)
+}
+
+// RedeemGiftCard godoc
+// @Summary      Redeem gift card balance
+// @Description  Deduct an amount from a gift card balance, optionally linked to a sale
+// @Tags         gift-cards
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.RedeemGiftCardRequest  true  "Redemption details"
+// @Success      200  {object}  Domain.GiftCard
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/redeem [post]
+// @Security     BearerAuth
+func (c *GiftCardController) RedeemGiftCard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RedeemGiftCardRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	card, err := c.giftCardUC.RedeemGiftCard(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, card)
+}
+
+// ReloadGiftCard godoc
+// @Summary      Reload gift card balance
+// @Description  Add balance to an existing gift card or store credit
+// @Tags         gift-cards
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        giftCardId  path  string                        true  "Gift card ID"
+// @Param        request     body  Domain.ReloadGiftCardRequest  true  "Reload details"
+// @Success      200  {object}  Domain.GiftCard
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/{giftCardId}/reload [post]
+// @Security     BearerAuth
+func (c *GiftCardController) ReloadGiftCard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	giftCardID := ctx.Param("giftCardId")
+	if giftCardID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Gift card ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ReloadGiftCardRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	card, err := c.giftCardUC.ReloadGiftCard(ctx.Request.Context(), giftCardID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, card)
+}
+
+// VoidGiftCard godoc
+// @Summary      Void gift card
+// @Description  Void a gift card and write off its remaining balance
+// @Tags         gift-cards
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        giftCardId  path  string  true  "Gift card ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/{giftCardId} [delete]
+// @Security     BearerAuth
+func (c *GiftCardController) VoidGiftCard(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	giftCardID := ctx.Param("giftCardId")
+	if giftCardID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Gift card ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	if err := c.giftCardUC.VoidGiftCard(ctx.Request.Context(), giftCardID, businessID, userID.(string)); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Gift card voided successfully"})
+}
+
+// GetTransactions godoc
+// @Summary      Get gift card transactions
+// @Description  Get the issue/redeem/reload history of a gift card
+// @Tags         gift-cards
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        giftCardId  path   string  true   "Gift card ID"
+// @Param        limit       query  int     false  "Limit results (default 50)"
+// @Success      200  {array}   Domain.GiftCardTransaction
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/{giftCardId}/transactions [get]
+// @Security     BearerAuth
+func (c *GiftCardController) GetTransactions(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	giftCardID := ctx.Param("giftCardId")
+	if giftCardID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Gift card ID is required")
+		return
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	transactions, err := c.giftCardUC.GetTransactions(ctx.Request.Context(), giftCardID, businessID, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transactions)
+}
+
+// GetLiability godoc
+// @Summary      Get gift card liability report
+// @Description  Get outstanding gift card and store credit balances owed to customers
+// @Tags         gift-cards
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.GiftCardLiability
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/gift-cards/liability [get]
+// @Security     BearerAuth
+func (c *GiftCardController) GetLiability(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	liability, err := c.giftCardUC.GetLiability(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, liability)
+}
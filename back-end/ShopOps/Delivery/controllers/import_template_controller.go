@@ -0,0 +1,66 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ImportTemplateController struct {
+	importTemplateUC Usecases.ImportTemplateUseCase
+}
+
+func NewImportTemplateController(importTemplateUC Usecases.ImportTemplateUseCase) *ImportTemplateController {
+	return &ImportTemplateController{importTemplateUC: importTemplateUC}
+}
+
+// GetImportTemplate godoc
+// @Summary      Describe an import file
+// @Description  The columns of the import file for products, customers, suppliers or expenses: which are required, their type, the values allowed, and sample rows. Product categories offered are the shop's own.
+// @Tags         imports
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        entity      path  string  true  "Entity: products, customers, suppliers, expenses"
+// @Success      200  {object}  Domain.ImportTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/import-templates/{entity} [get]
+// @Security     BearerAuth
+func (c *ImportTemplateController) GetImportTemplate(ctx *gin.Context) {
+	template, err := c.importTemplateUC.GetTemplate(ctx.Param("businessId"), Domain.ImportEntity(ctx.Param("entity")))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// DownloadImportTemplate godoc
+// @Summary      Download an import template
+// @Description  A file with the right headers and sample rows to fill in. The XLSX template has dropdowns on columns with a fixed set of values, rejects negative numbers, and explains each column on an Instructions sheet.
+// @Tags         imports
+// @Produce      octet-stream
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        entity      path   string  true   "Entity: products, customers, suppliers, expenses"
+// @Param        format      query  string  false  "Format: xlsx (default), csv"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/import-templates/{entity}/download [get]
+// @Security     BearerAuth
+func (c *ImportTemplateController) DownloadImportTemplate(ctx *gin.Context) {
+	data, contentType, filename, err := c.importTemplateUC.DownloadTemplate(ctx.Param("businessId"),
+		Domain.ImportEntity(ctx.Param("entity")), Domain.TemplateFormat(ctx.Query("format")))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Data(http.StatusOK, contentType, data)
+}
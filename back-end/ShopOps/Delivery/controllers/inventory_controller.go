@@ -3,6 +3,8 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	Domain "ShopOps/Domain"
 	Infrastructure "ShopOps/Infrastructure"
@@ -62,7 +64,7 @@ func (c *InventoryController) CreateProduct(ctx *gin.Context) {
 
 // GetProducts godoc
 // @Summary      List all products
-// @Description  Get products with filtering and search
+// @Description  Get products with filtering and search. When the page is full, X-Next-Cursor holds the cursor for the next one.
 // @Tags         inventory
 // @Produce      json
 // @Param        businessId  path    string  true   "Business ID"
@@ -70,9 +72,14 @@ func (c *InventoryController) CreateProduct(ctx *gin.Context) {
 // @Param        status      query   string  false  "Product status"
 // @Param        low_stock   query   bool    false  "Filter low stock items"
 // @Param        search      query   string  false  "Search in name, SKU, barcode"
-// @Param        limit       query   int     false  "Limit results"
-// @Param        offset      query   int     false  "Offset results"
+// @Param        sort        query   string  false  "name (default), -name, -created_at, -updated_at or stock"
+// @Param        cursor      query   string  false  "X-Next-Cursor from the previous page"
+// @Param        limit       query   int     false  "Limit results (default 50 when paging by cursor, max 500)"
+// @Param        offset      query   int     false  "Offset results (deprecated; page by cursor)"
+// @Param        include_total  query  bool  false  "Send the total across all pages in X-Total-Count"
+// @Param        If-None-Match  header string false "ETag of the copy held; a 304 with no body when unchanged"
 // @Success      200  {array}   Domain.Product
+// @Success      304  "Not modified"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/inventory/products [get]
@@ -112,11 +119,14 @@ func (c *InventoryController) GetProducts(ctx *gin.Context) {
 	}
 
 	// Pagination
-	if limitStr := ctx.Query("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			filters.Limit = limit
-		}
+	page, err := Infrastructure.ParsePage(ctx, Domain.ProductSorts, "name")
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
 	}
+	filters.Limit = page.Limit
+	filters.Sort = page.Sort
+	filters.After = page.After
 
 	if offsetStr := ctx.Query("offset"); offsetStr != "" {
 		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
@@ -130,6 +140,21 @@ func (c *InventoryController) GetProducts(ctx *gin.Context) {
 		return
 	}
 
+	if page.IncludeTotal {
+		total, err := c.inventoryUC.CountProducts(businessID, filters)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+			return
+		}
+		Infrastructure.SetTotalCount(ctx, total)
+	}
+
+	var last Domain.Sortable
+	if n := len(products); n > 0 {
+		last = &products[n-1]
+	}
+	Infrastructure.SetNextCursor(ctx, page, len(products), last)
+
 	ctx.JSON(http.StatusOK, products)
 }
 
@@ -140,7 +165,9 @@ func (c *InventoryController) GetProducts(ctx *gin.Context) {
 // @Produce      json
 // @Param        businessId  path  string  true  "Business ID"
 // @Param        productId   path  string  true  "Product ID"
+// @Param        If-None-Match  header string false "ETag of the copy held; a 304 with no body when unchanged"
 // @Success      200  {object}  Domain.Product
+// @Success      304  "Not modified"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Failure      404  {object}  map[string]interface{}
@@ -159,18 +186,19 @@ func (c *InventoryController) GetProduct(ctx *gin.Context) {
 		return
 	}
 
-	product, err := c.inventoryUC.GetProductByID(productID, businessID)
+	product, err := c.inventoryUC.GetProductByID(ctx.Request.Context(), productID, businessID)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
 		return
 	}
 
+	Infrastructure.SetLastModified(ctx, product.UpdatedAt)
 	ctx.JSON(http.StatusOK, product)
 }
 
 // UpdateProduct godoc
 // @Summary      Update product
-// @Description  Update product information and pricing
+// @Description  Update product information and pricing. Send the version last read to get a 409 instead of overwriting someone else's changes.
 // @Tags         inventory
 // @Accept       json
 // @Produce      json
@@ -181,6 +209,7 @@ func (c *InventoryController) GetProduct(ctx *gin.Context) {
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/inventory/products/{productId} [patch]
 // @Security     BearerAuth
 func (c *InventoryController) UpdateProduct(ctx *gin.Context) {
@@ -208,7 +237,7 @@ func (c *InventoryController) UpdateProduct(ctx *gin.Context) {
 		return
 	}
 
-	product, err := c.inventoryUC.UpdateProduct(productID, businessID, userID.(string), req)
+	product, err := c.inventoryUC.UpdateProduct(ctx.Request.Context(), productID, businessID, userID.(string), req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
@@ -219,7 +248,7 @@ func (c *InventoryController) UpdateProduct(ctx *gin.Context) {
 
 // DeleteProduct godoc
 // @Summary      Delete product
-// @Description  Soft delete a product (mark as discontinued)
+// @Description  Move a product to the trash, where it can be restored for 30 days
 // @Tags         inventory
 // @Produce      json
 // @Param        businessId  path  string  true  "Business ID"
@@ -249,7 +278,7 @@ func (c *InventoryController) DeleteProduct(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.inventoryUC.DeleteProduct(productID, businessID, userID.(string)); err != nil {
+	if err := c.inventoryUC.DeleteProduct(ctx.Request.Context(), productID, businessID, userID.(string)); err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
 	}
@@ -297,7 +326,7 @@ func (c *InventoryController) AdjustStock(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.inventoryUC.AdjustStock(productID, businessID, userID.(string), req); err != nil {
+	if err := c.inventoryUC.AdjustStock(ctx.Request.Context(), productID, businessID, userID.(string), req); err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
 	}
@@ -374,7 +403,7 @@ func (c *InventoryController) GetStockHistory(ctx *gin.Context) {
 		}
 	}
 
-	history, err := c.inventoryUC.GetStockHistory(productID, businessID, limit)
+	history, err := c.inventoryUC.GetStockHistory(ctx.Request.Context(), productID, businessID, limit)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
 		return
@@ -382,3 +411,97 @@ func (c *InventoryController) GetStockHistory(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, history)
 }
+
+// GetStockLedger godoc
+// @Summary      Get the stock ledger
+// @Description  Every change to stock, newest first: receipts, sales, returns, adjustments, losses and transfers, each with what it references and the balance it left. Entries are never changed; corrections are further entries. Pages with the cursor in X-Next-Cursor. On a product's route only its movements are listed.
+// @Tags         inventory
+// @Produce      json
+// @Param        businessId      path   string  true   "Business ID"
+// @Param        productId       path   string  false  "Product ID, on /products/{productId}/ledger"
+// @Param        product_id      query  string  false  "Only this product's, on /ledger"
+// @Param        type            query  string  false  "Comma-separated movement types, e.g. sale,return"
+// @Param        reference_type  query  string  false  "What the movements reference, e.g. sale, purchase_order"
+// @Param        reference_id    query  string  false  "Only the movements of this sale, order, etc."
+// @Param        start_date      query  string  false  "First day, YYYY-MM-DD"
+// @Param        end_date        query  string  false  "Last day, YYYY-MM-DD"
+// @Param        sort            query  string  false  "-created_at (default) or created_at"
+// @Param        cursor          query  string  false  "X-Next-Cursor of the previous page"
+// @Param        limit           query  int     false  "Page size (default 50)"
+// @Success      200  {array}   Domain.StockMovement
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/ledger [get]
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/ledger [get]
+// @Security     BearerAuth
+func (c *InventoryController) GetStockLedger(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var filters Domain.StockLedgerFilters
+	productID := ctx.Param("productId")
+	if productID == "" {
+		productID = ctx.Query("product_id")
+	}
+	if productID != "" {
+		filters.ProductID = &productID
+	}
+	if types := ctx.Query("type"); types != "" {
+		for _, t := range strings.Split(types, ",") {
+			filters.Types = append(filters.Types, Domain.MovementType(strings.TrimSpace(t)))
+		}
+	}
+	if referenceType := ctx.Query("reference_type"); referenceType != "" {
+		filters.ReferenceType = &referenceType
+	}
+	if referenceID := ctx.Query("reference_id"); referenceID != "" {
+		filters.ReferenceID = &referenceID
+	}
+	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+		startDate, err := time.Parse("2006-01-02", startDateStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Invalid start_date, expected YYYY-MM-DD")
+			return
+		}
+		filters.StartDate = &startDate
+	}
+	if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+		endDate, err := time.Parse("2006-01-02", endDateStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Invalid end_date, expected YYYY-MM-DD")
+			return
+		}
+		endDate = endDate.AddDate(0, 0, 1)
+		filters.EndDate = &endDate
+	}
+
+	page, err := Infrastructure.ParsePage(ctx, Domain.StockMovementSorts, "-created_at")
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	if page.Limit == 0 {
+		page.Limit = 50
+	}
+	filters.Limit = page.Limit
+	filters.Sort = page.Sort
+	filters.After = page.After
+
+	movements, err := c.inventoryUC.GetStockLedger(ctx.Request.Context(), businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	var last Domain.Sortable
+	if n := len(movements); n > 0 {
+		last = &movements[n-1]
+	}
+	Infrastructure.SetNextCursor(ctx, page, len(movements), last)
+
+	ctx.JSON(http.StatusOK, movements)
+}
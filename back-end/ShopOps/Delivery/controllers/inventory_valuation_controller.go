@@ -0,0 +1,76 @@
+package controllers
+
+import (
+	"net/http"
+
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type InventoryValuationController struct {
+	valuationUC Usecases.InventoryValuationUseCase
+}
+
+func NewInventoryValuationController(valuationUC Usecases.InventoryValuationUseCase) *InventoryValuationController {
+	return &InventoryValuationController{valuationUC: valuationUC}
+}
+
+// GetValuation godoc
+// @Summary      Get inventory valuation
+// @Description  Stock on hand valued at cost and at selling price as of a date, from the stock ledger's balances at the close of that date and the cost each product last moved at. Today or no date gives the live stock.
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        as_of       query  string  false  "Date (YYYY-MM-DD), defaults to today"
+// @Param        category    query  string  false  "Only value products in this category"
+// @Success      200  {object}  Domain.InventoryValuation
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/inventory/valuation [get]
+// @Security     BearerAuth
+func (c *InventoryValuationController) GetValuation(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	valuation, err := c.valuationUC.GetValuation(businessID, ctx.Query("as_of"), ctx.Query("category"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, valuation)
+}
+
+// GetValuationHistory godoc
+// @Summary      Get inventory value history
+// @Description  Closing stock value for each day with a nightly snapshot
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to 29 days before end_date"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Success      200  {array}   Domain.InventoryValuationPoint
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/inventory/valuation/history [get]
+// @Security     BearerAuth
+func (c *InventoryValuationController) GetValuationHistory(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	points, err := c.valuationUC.GetValuationHistory(businessID, ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, points)
+}
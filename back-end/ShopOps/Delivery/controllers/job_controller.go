@@ -0,0 +1,140 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobController struct {
+	jobUC Usecases.JobUseCase
+}
+
+func NewJobController(jobUC Usecases.JobUseCase) *JobController {
+	return &JobController{jobUC: jobUC}
+}
+
+// GetJobs godoc
+// @Summary      List background jobs
+// @Description  Jobs in the persistent queue, newest first. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        type         query  string  false  "Job type: sms_campaign, sms_receipt"
+// @Param        status       query  string  false  "Job status: queued, running, succeeded, dead"
+// @Param        business_id  query  string  false  "Only jobs for this business"
+// @Param        limit        query  int     false  "Limit results"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Job
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/admin/jobs [get]
+// @Security     BearerAuth
+func (c *JobController) GetJobs(ctx *gin.Context) {
+	filters := Domain.JobFilters{Limit: 50}
+
+	if jobType := ctx.Query("type"); jobType != "" {
+		t := Domain.JobType(jobType)
+		filters.Type = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.JobStatus(status)
+		filters.Status = &s
+	}
+
+	if businessID := ctx.Query("business_id"); businessID != "" {
+		filters.BusinessID = &businessID
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	jobs, err := c.jobUC.GetJobs(filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// GetJobStats godoc
+// @Summary      Job queue stats
+// @Description  Job counts by type and status, with the oldest run time in each. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {array}   Domain.JobStats
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/admin/jobs/stats [get]
+// @Security     BearerAuth
+func (c *JobController) GetJobStats(ctx *gin.Context) {
+	stats, err := c.jobUC.GetStats()
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, stats)
+}
+
+// GetJob godoc
+// @Summary      Get a background job
+// @Description  One job with its attempts and last error. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        jobId  path  string  true  "Job ID"
+// @Success      200  {object}  Domain.Job
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/admin/jobs/{jobId} [get]
+// @Security     BearerAuth
+func (c *JobController) GetJob(ctx *gin.Context) {
+	job, err := c.jobUC.GetJob(ctx.Param("jobId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// RetryJob godoc
+// @Summary      Retry a background job
+// @Description  Run a dead-lettered job again with a fresh set of attempts, or run a job waiting out its backoff now. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Param        jobId  path  string  true  "Job ID"
+// @Success      200  {object}  Domain.Job
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/admin/jobs/{jobId}/retry [post]
+// @Security     BearerAuth
+func (c *JobController) RetryJob(ctx *gin.Context) {
+	job, err := c.jobUC.RetryJob(ctx.Param("jobId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
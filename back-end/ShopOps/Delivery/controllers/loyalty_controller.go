@@ -0,0 +1,293 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type LoyaltyController struct {
+	loyaltyUC Usecases.LoyaltyUseCase
+}
+
+func NewLoyaltyController(loyaltyUC Usecases.LoyaltyUseCase) *LoyaltyController {
+	return &LoyaltyController{loyaltyUC: loyaltyUC}
+}
+
+// GetProgram godoc
+// @Summary      Get loyalty program
+// @Description  Get the business's loyalty earn and redemption settings
+// @Tags         loyalty
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.LoyaltyProgram
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/program [get]
+// @Security     BearerAuth
+func (c *LoyaltyController) GetProgram(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	program, err := c.loyaltyUC.GetProgram(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, program)
+}
+
+// UpdateProgram godoc
+// @Summary      Update loyalty program
+// @Description  Configure earn rate, point value, redemption minimums and bonus rules
+// @Tags         loyalty
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                              true  "Business ID"
+// @Param        request     body  Domain.UpdateLoyaltyProgramRequest  true  "Program settings"
+// @Success      200  {object}  Domain.LoyaltyProgram
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/program [put]
+// @Security     BearerAuth
+func (c *LoyaltyController) UpdateProgram(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.UpdateLoyaltyProgramRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	program, err := c.loyaltyUC.UpdateProgram(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, program)
+}
+
+// LookupBalance godoc
+// @Summary      Look up loyalty balance
+// @Description  Get a customer's points balance and its currency value by phone (used at POS)
+// @Tags         loyalty
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        phone       query  string  true  "Customer phone"
+// @Success      200  {object}  Domain.LoyaltyBalance
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/accounts/lookup [get]
+// @Security     BearerAuth
+func (c *LoyaltyController) LookupBalance(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	phone := ctx.Query("phone")
+	if phone == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Customer phone is required")
+		return
+	}
+
+	balance, err := c.loyaltyUC.GetBalance(businessID, phone)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, balance)
+}
+
+// GetAccounts godoc
+// @Summary      List loyalty accounts
+// @Description  Get loyalty members ordered by points balance
+// @Tags         loyalty
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.LoyaltyAccount
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/accounts [get]
+// @Security     BearerAuth
+func (c *LoyaltyController) GetAccounts(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	offset := 0
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	accounts, err := c.loyaltyUC.GetAccounts(businessID, limit, offset)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, accounts)
+}
+
+// GetTransactions godoc
+// @Summary      Get loyalty account history
+// @Description  Get earn, redeem, adjustment and reversal history for a loyalty account
+// @Tags         loyalty
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        accountId   path   string  true   "Loyalty account ID"
+// @Param        limit       query  int     false  "Limit results"
+// @Success      200  {array}   Domain.LoyaltyTransaction
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/accounts/{accountId}/transactions [get]
+// @Security     BearerAuth
+func (c *LoyaltyController) GetTransactions(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	accountID := ctx.Param("accountId")
+
+	if businessID == "" || accountID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Account ID are required")
+		return
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	transactions, err := c.loyaltyUC.GetTransactions(accountID, businessID, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transactions)
+}
+
+// AdjustPoints godoc
+// @Summary      Adjust loyalty points
+// @Description  Manually add (positive) or remove (negative) points with a note
+// @Tags         loyalty
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID"
+// @Param        accountId   path  string                             true  "Loyalty account ID"
+// @Param        request     body  Domain.AdjustLoyaltyPointsRequest  true  "Adjustment details"
+// @Success      200  {object}  Domain.LoyaltyAccount
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/accounts/{accountId}/adjust [post]
+// @Security     BearerAuth
+func (c *LoyaltyController) AdjustPoints(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	accountID := ctx.Param("accountId")
+
+	if businessID == "" || accountID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Account ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.AdjustLoyaltyPointsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	account, err := c.loyaltyUC.AdjustPoints(accountID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, account)
+}
+
+// GetReport godoc
+// @Summary      Get loyalty report
+// @Description  Get points accrued vs. redeemed for a period and the outstanding points liability
+// @Tags         loyalty
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to 30 days ago"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Success      200  {object}  Domain.LoyaltyReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/loyalty/report [get]
+// @Security     BearerAuth
+func (c *LoyaltyController) GetReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	endDate := time.Now()
+	startDate := endDate.AddDate(0, 0, -30)
+
+	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			startDate = parsed
+		}
+	}
+
+	if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+		if parsed, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			endDate = parsed.Add(24*time.Hour - time.Nanosecond)
+		}
+	}
+
+	report, err := c.loyaltyUC.GetReport(businessID, startDate, endDate)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MaintenanceController struct {
+	maintenanceUC Usecases.MaintenanceUseCase
+}
+
+func NewMaintenanceController(maintenanceUC Usecases.MaintenanceUseCase) *MaintenanceController {
+	return &MaintenanceController{maintenanceUC: maintenanceUC}
+}
+
+// GetStatus godoc
+// @Summary      Maintenance status
+// @Description  Whether the API is in read-only maintenance mode, with the announcement and expected end. Devices poll this to show a banner and hold back writes.
+// @Tags         system
+// @Produce      json
+// @Success      200  {object}  Domain.MaintenanceState
+// @Router       /api/v1/maintenance [get]
+func (c *MaintenanceController) GetStatus(ctx *gin.Context) {
+	state := c.maintenanceUC.CurrentMaintenance()
+	// Who switched it is for admins only
+	state.UpdatedBy = nil
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// GetMaintenance godoc
+// @Summary      Get maintenance mode
+// @Description  The current maintenance state as stored. Admin only.
+// @Tags         admin
+// @Produce      json
+// @Success      200  {object}  Domain.MaintenanceState
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/admin/maintenance [get]
+// @Security     BearerAuth
+func (c *MaintenanceController) GetMaintenance(ctx *gin.Context) {
+	state, err := c.maintenanceUC.GetState()
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// SetMaintenance godoc
+// @Summary      Set maintenance mode
+// @Description  Put the API into read-only mode, or take it out. While on, writes return 503 with Retry-After; reads, sync downloads and reports keep working. Takes effect on every instance within 5 seconds. Admin only.
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.SetMaintenanceRequest  true  "Maintenance settings"
+// @Success      200  {object}  Domain.MaintenanceState
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/admin/maintenance [put]
+// @Security     BearerAuth
+func (c *MaintenanceController) SetMaintenance(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.SetMaintenanceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	state, err := c.maintenanceUC.SetState(userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
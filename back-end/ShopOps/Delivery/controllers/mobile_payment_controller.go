@@ -0,0 +1,183 @@
+package controllers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type MobilePaymentController struct {
+	mobilePaymentUC Usecases.MobilePaymentUseCase
+}
+
+func NewMobilePaymentController(mobilePaymentUC Usecases.MobilePaymentUseCase) *MobilePaymentController {
+	return &MobilePaymentController{mobilePaymentUC: mobilePaymentUC}
+}
+
+// GetMobileMoneyProviders godoc
+// @Summary      List mobile money providers
+// @Description  The wallets customers can pay from: telebirr, cbe_birr or mpesa
+// @Tags         mobile-payments
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/mobile-payments/providers [get]
+// @Security     BearerAuth
+func (c *MobilePaymentController) GetMobileMoneyProviders(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, gin.H{"providers": c.mobilePaymentUC.Providers()})
+}
+
+// InitiateMobilePayment godoc
+// @Summary      Ask a customer to pay a sale by mobile money
+// @Description  Sends the customer's wallet a payment request for the sale's total. Create the sale with await_payment so it stays unpaid until the payment succeeds, then poll the payment until it is no longer pending. The customer has 5 minutes to approve; checkout_url is set for providers that confirm on a web page.
+// @Tags         mobile-payments
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        request     body  Domain.InitiateMobilePaymentRequest  true  "Sale, provider and wallet"
+// @Success      201  {object}  Domain.MobilePayment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/mobile-payments [post]
+// @Security     BearerAuth
+func (c *MobilePaymentController) InitiateMobilePayment(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.InitiateMobilePaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	payment, err := c.mobilePaymentUC.Initiate(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, payment)
+}
+
+// GetMobilePayments godoc
+// @Summary      List a sale's mobile payments
+// @Description  Every payment request made for a sale, newest first
+// @Tags         mobile-payments
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        sale_id     query  string  true  "Sale ID"
+// @Success      200  {array}   Domain.MobilePayment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/mobile-payments [get]
+// @Security     BearerAuth
+func (c *MobilePaymentController) GetMobilePayments(ctx *gin.Context) {
+	saleID := ctx.Query("sale_id")
+	if saleID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "sale_id is required")
+		return
+	}
+
+	payments, err := c.mobilePaymentUC.GetSalePayments(ctx.Request.Context(), saleID, ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payments)
+}
+
+// GetMobilePayment godoc
+// @Summary      Get a mobile payment
+// @Description  Poll this after starting a payment: status moves from pending to succeeded, failed or expired
+// @Tags         mobile-payments
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        paymentId   path  string  true  "Payment ID"
+// @Success      200  {object}  Domain.MobilePayment
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/mobile-payments/{paymentId} [get]
+// @Security     BearerAuth
+func (c *MobilePaymentController) GetMobilePayment(ctx *gin.Context) {
+	payment, err := c.mobilePaymentUC.GetPayment(ctx.Request.Context(), ctx.Param("paymentId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payment)
+}
+
+// ReverseMobilePayment godoc
+// @Summary      Reverse a mobile payment
+// @Description  Sends a succeeded payment, or one held for review, back to the customer. A payment that was applied to its sale is taken off it, leaving the sale unpaid. Owners only.
+// @Tags         mobile-payments
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                              true   "Business ID"
+// @Param        paymentId   path  string                              true   "Payment ID"
+// @Param        request     body  Domain.ReverseMobilePaymentRequest  false  "Reason"
+// @Success      200  {object}  Domain.MobilePayment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      503  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/mobile-payments/{paymentId}/reverse [post]
+// @Security     BearerAuth
+func (c *MobilePaymentController) ReverseMobilePayment(ctx *gin.Context) {
+	var req Domain.ReverseMobilePaymentRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	payment, err := c.mobilePaymentUC.Reverse(ctx.Request.Context(), ctx.Param("paymentId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, payment)
+}
+
+// MobilePaymentCallback receives payment outcomes from the providers, which each
+// authenticate in their own way. Errors other than a bad or unknown callback answer
+// 500 so that the provider tries again.
+func (c *MobilePaymentController) MobilePaymentCallback(ctx *gin.Context) {
+	body, err := io.ReadAll(ctx.Request.Body)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	provider := Domain.MobileMoneyProvider(ctx.Param("provider"))
+	err = c.mobilePaymentUC.HandleCallback(ctx.Request.Context(), provider, body, ctx.Request.Header)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, Infrastructure.ErrMobileMoneyBadCallback) {
+			status = http.StatusBadRequest
+		}
+		Infrastructure.JSONError(ctx, status, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"received": true})
+}
@@ -0,0 +1,185 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type NotificationController struct {
+	notificationUC Usecases.NotificationUseCase
+}
+
+func NewNotificationController(notificationUC Usecases.NotificationUseCase) *NotificationController {
+	return &NotificationController{notificationUC: notificationUC}
+}
+
+// GetNotifications godoc
+// @Summary      List notifications
+// @Description  The signed-in user's notification center, newest first, across all their shops. Notifications are kept for 90 days.
+// @Tags         notifications
+// @Produce      json
+// @Param        business_id  query  string  false  "Only this shop's"
+// @Param        event        query  string  false  "Only this kind (big_sale, daily_summary, sync_failure, backup_failed, low_stock, alert, security)"
+// @Param        unread       query  bool    false  "Only unread"
+// @Param        limit        query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Notification
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/notifications [get]
+// @Security     BearerAuth
+func (c *NotificationController) GetNotifications(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var filters Domain.NotificationFilters
+	if businessID := ctx.Query("business_id"); businessID != "" {
+		filters.BusinessID = &businessID
+	}
+	if event := ctx.Query("event"); event != "" {
+		e := Domain.NotificationEvent(event)
+		filters.Event = &e
+	}
+	filters.Unread = ctx.Query("unread") == "true"
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	notifications, err := c.notificationUC.GetNotifications(userID.(string), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, notifications)
+}
+
+// MarkNotificationsRead godoc
+// @Summary      Mark notifications read
+// @Description  Mark the notifications listed read, or with all set every unread one, of one shop when business_id is given
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.MarkNotificationsReadRequest  true  "Notifications to mark"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/notifications/read [post]
+// @Security     BearerAuth
+func (c *NotificationController) MarkNotificationsRead(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.MarkNotificationsReadRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	marked, err := c.notificationUC.MarkRead(userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"marked": marked})
+}
+
+// GetNotificationBadge godoc
+// @Summary      Unread notification counts
+// @Description  The signed-in user's unread notifications, in all and for each shop, for the app's badges
+// @Tags         notifications
+// @Produce      json
+// @Success      200  {object}  Domain.NotificationBadge
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/notifications/badge [get]
+// @Security     BearerAuth
+func (c *NotificationController) GetNotificationBadge(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	badge, err := c.notificationUC.GetBadge(userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, badge)
+}
+
+// GetNotificationPreferences godoc
+// @Summary      Notification preferences
+// @Description  Every kind of notification with the channels it can be sent by (push, sms, email, in_app), and those the signed-in user gets it by. Security notifications cannot be turned off.
+// @Tags         notifications
+// @Produce      json
+// @Success      200  {object}  Domain.NotificationPreferencesView
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/notifications/preferences [get]
+// @Security     BearerAuth
+func (c *NotificationController) GetNotificationPreferences(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	preferences, err := c.notificationUC.GetPreferences(userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
+
+// UpdateNotificationPreferences godoc
+// @Summary      Update notification preferences
+// @Description  Set the channels for the kinds of notification given; an empty list turns one off. Kinds left out keep their channels. The choice applies in all the user's shops.
+// @Tags         notifications
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.UpdateNotificationPreferencesRequest  true  "Channels by kind of notification"
+// @Success      200  {object}  Domain.NotificationPreferencesView
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/notifications/preferences [patch]
+// @Security     BearerAuth
+func (c *NotificationController) UpdateNotificationPreferences(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.UpdateNotificationPreferencesRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	preferences, err := c.notificationUC.UpdatePreferences(userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, preferences)
+}
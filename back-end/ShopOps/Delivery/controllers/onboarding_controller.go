@@ -0,0 +1,246 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OnboardingController struct {
+	onboardingUC Usecases.OnboardingUseCase
+}
+
+func NewOnboardingController(onboardingUC Usecases.OnboardingUseCase) *OnboardingController {
+	return &OnboardingController{onboardingUC: onboardingUC}
+}
+
+// GetShopTemplates godoc
+// @Summary      List shop templates
+// @Description  Kinds of shop the onboarding wizard offers, each with the categories, units and tax rates it starts the catalog with
+// @Tags         onboarding
+// @Produce      json
+// @Success      200  {array}   Domain.ShopTemplate
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/onboarding/templates [get]
+// @Security     BearerAuth
+func (c *OnboardingController) GetShopTemplates(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.onboardingUC.GetTemplates())
+}
+
+// GetCurrentOnboarding godoc
+// @Summary      Resume onboarding
+// @Description  The user's onboarding still in progress, with the shop it is setting up and the next step, so the wizard picks up where it was left on any device
+// @Tags         onboarding
+// @Produce      json
+// @Success      200  {object}  Domain.OnboardingState
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/onboarding [get]
+// @Security     BearerAuth
+func (c *OnboardingController) GetCurrentOnboarding(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	state, err := c.onboardingUC.GetUnfinished(userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// SaveOnboardingBusiness godoc
+// @Summary      Onboarding: create the shop
+// @Description  The wizard's first step. Creates the shop and starts the onboarding, or, with an onboarding already in progress, changes the shop it created.
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.CreateBusinessRequest  true  "Shop details"
+// @Success      200  {object}  Domain.OnboardingState
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/onboarding/business [post]
+// @Security     BearerAuth
+func (c *OnboardingController) SaveOnboardingBusiness(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateBusinessRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	state, err := c.onboardingUC.SaveBusiness(userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// GetOnboarding godoc
+// @Summary      Get the shop's onboarding
+// @Description  Progress through the onboarding wizard for a shop set up with it
+// @Tags         onboarding
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.OnboardingState
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/onboarding [get]
+// @Security     BearerAuth
+func (c *OnboardingController) GetOnboarding(ctx *gin.Context) {
+	state, err := c.onboardingUC.GetOnboarding(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// ChooseShopTemplate godoc
+// @Summary      Onboarding: choose a template
+// @Description  Adds the template's categories, units and tax rates to the shop's catalog settings, and installs the template's vertical pack with any others listed. Choosing again adds the new template's too, keeping what is there.
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.ChooseTemplateRequest  true  "Template"
+// @Success      200  {object}  Domain.OnboardingState
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/onboarding/template [put]
+// @Security     BearerAuth
+func (c *OnboardingController) ChooseShopTemplate(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ChooseTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	state, err := c.onboardingUC.ChooseTemplate(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// ImportOnboardingProducts godoc
+// @Summary      Onboarding: import products
+// @Description  Adds up to 500 products; each goes in on its own, and those refused are listed with why. The step is done once any product goes in, and can be repeated to add more until the onboarding finishes.
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.ImportProductsRequest  true  "Products"
+// @Success      200  {object}  Domain.ImportProductsResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/onboarding/products [post]
+// @Security     BearerAuth
+func (c *OnboardingController) ImportOnboardingProducts(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ImportProductsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	result, err := c.onboardingUC.ImportProducts(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// RegisterOnboardingDevice godoc
+// @Summary      Onboarding: register the first device
+// @Description  Registers the shop's first till, taking one of the plan's device slots
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.RegisterDeviceRequest  true  "Device"
+// @Success      200  {object}  Domain.OnboardingState
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      402  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/onboarding/device [post]
+// @Security     BearerAuth
+func (c *OnboardingController) RegisterOnboardingDevice(ctx *gin.Context) {
+	var req Domain.RegisterDeviceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	state, err := c.onboardingUC.RegisterDevice(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
+
+// SkipOnboardingStep godoc
+// @Summary      Onboarding: skip a step
+// @Description  Passes over the template, products or device step; a skipped step can still be done until the onboarding finishes
+// @Tags         onboarding
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        request     body  Domain.SkipOnboardingStepRequest  true  "Step"
+// @Success      200  {object}  Domain.OnboardingState
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/onboarding/skip [post]
+// @Security     BearerAuth
+func (c *OnboardingController) SkipOnboardingStep(ctx *gin.Context) {
+	var req Domain.SkipOnboardingStepRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	state, err := c.onboardingUC.SkipStep(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, state)
+}
@@ -0,0 +1,172 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PricingController struct {
+	pricingUC Usecases.PricingUseCase
+}
+
+func NewPricingController(pricingUC Usecases.PricingUseCase) *PricingController {
+	return &PricingController{pricingUC: pricingUC}
+}
+
+// QuotePrice godoc
+// @Summary      Resolve POS price
+// @Description  Resolve the unit price of a product for a customer: negotiated customer price, then the customer's tier price, then the retail selling price
+// @Tags         pricing
+// @Produce      json
+// @Param        businessId      path   string  true   "Business ID"
+// @Param        product_id      query  string  true   "Product ID"
+// @Param        customer_id     query  string  false  "Customer ID"
+// @Param        customer_phone  query  string  false  "Customer phone, used when customer_id is not given"
+// @Param        quantity        query  number  false  "Quantity (default 1)"
+// @Success      200  {object}  Domain.PriceQuote
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/pricing/quote [get]
+// @Security     BearerAuth
+func (c *PricingController) QuotePrice(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	productID := ctx.Query("product_id")
+	if productID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "product_id is required")
+		return
+	}
+
+	quantity := 1.0
+	if quantityStr := ctx.Query("quantity"); quantityStr != "" {
+		q, err := strconv.ParseFloat(quantityStr, 64)
+		if err != nil || q <= 0 {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "quantity must be a positive number")
+			return
+		}
+		quantity = q
+	}
+
+	quote, err := c.pricingUC.QuotePrice(ctx.Request.Context(), businessID, productID, ctx.Query("customer_id"), ctx.Query("customer_phone"), quantity)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, quote)
+}
+
+// GetCustomerPrices godoc
+// @Summary      List negotiated prices
+// @Description  Get the per-product prices negotiated with a customer
+// @Tags         pricing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        customerId  path  string  true  "Customer ID"
+// @Success      200  {array}   Domain.CustomerPrice
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId}/prices [get]
+// @Security     BearerAuth
+func (c *PricingController) GetCustomerPrices(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	prices, err := c.pricingUC.GetCustomerPrices(ctx.Request.Context(), customerID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, prices)
+}
+
+// SetCustomerPrice godoc
+// @Summary      Set negotiated price
+// @Description  Create or replace the price a customer pays for a product
+// @Tags         pricing
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                          true  "Business ID"
+// @Param        customerId  path  string                          true  "Customer ID"
+// @Param        request     body  Domain.SetCustomerPriceRequest  true  "Product and price"
+// @Success      200  {object}  Domain.CustomerPrice
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId}/prices [put]
+// @Security     BearerAuth
+func (c *PricingController) SetCustomerPrice(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+
+	if businessID == "" || customerID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Customer ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.SetCustomerPriceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	price, err := c.pricingUC.SetCustomerPrice(ctx.Request.Context(), customerID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, price)
+}
+
+// DeleteCustomerPrice godoc
+// @Summary      Remove negotiated price
+// @Description  Remove a customer's negotiated price so tier or retail pricing applies again
+// @Tags         pricing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        customerId  path  string  true  "Customer ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId}/prices/{productId} [delete]
+// @Security     BearerAuth
+func (c *PricingController) DeleteCustomerPrice(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	customerID := ctx.Param("customerId")
+	productID := ctx.Param("productId")
+
+	if businessID == "" || customerID == "" || productID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID, Customer ID and Product ID are required")
+		return
+	}
+
+	if err := c.pricingUC.DeleteCustomerPrice(ctx.Request.Context(), customerID, productID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Customer price removed successfully"})
+}
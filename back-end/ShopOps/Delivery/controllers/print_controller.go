@@ -0,0 +1,347 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PrintController struct {
+	printUC Usecases.PrintUseCase
+}
+
+func NewPrintController(printUC Usecases.PrintUseCase) *PrintController {
+	return &PrintController{printUC: printUC}
+}
+
+// RegisterPrintBridge godoc
+// @Summary      Register a print bridge
+// @Description  Register the print agent running next to the shop's printers. The token returned here is not shown again; the bridge sends it as a Bearer token to /api/v1/print-bridge. Owners only.
+// @Tags         printing
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID"
+// @Param        request     body  Domain.RegisterPrintBridgeRequest  true  "Bridge name"
+// @Success      201  {object}  Domain.PrintBridgeTokenResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/bridges [post]
+// @Security     BearerAuth
+func (c *PrintController) RegisterPrintBridge(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RegisterPrintBridgeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	bridge, err := c.printUC.RegisterBridge(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, bridge)
+}
+
+// GetPrintBridges godoc
+// @Summary      List print bridges
+// @Description  Get the business's print bridges, the printers each last reported and whether it is polling. Owners only.
+// @Tags         printing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.PrintBridge
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/bridges [get]
+// @Security     BearerAuth
+func (c *PrintController) GetPrintBridges(ctx *gin.Context) {
+	bridges, err := c.printUC.GetBridges(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bridges)
+}
+
+// RotatePrintBridgeToken godoc
+// @Summary      Rotate a print bridge's token
+// @Description  Issue the bridge a new token; the old one stops working at once. Owners only.
+// @Tags         printing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        bridgeId    path  string  true  "Bridge ID"
+// @Success      200  {object}  Domain.PrintBridgeTokenResponse
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/bridges/{bridgeId}/rotate-token [post]
+// @Security     BearerAuth
+func (c *PrintController) RotatePrintBridgeToken(ctx *gin.Context) {
+	bridge, err := c.printUC.RotateBridgeToken(ctx.Param("bridgeId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bridge)
+}
+
+// DeletePrintBridge godoc
+// @Summary      Remove a print bridge
+// @Description  Remove the bridge and revoke its token; jobs sent to it alone fail. Owners only.
+// @Tags         printing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        bridgeId    path  string  true  "Bridge ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/bridges/{bridgeId} [delete]
+// @Security     BearerAuth
+func (c *PrintController) DeletePrintBridge(ctx *gin.Context) {
+	if err := c.printUC.DeleteBridge(ctx.Param("bridgeId"), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Print bridge removed successfully"})
+}
+
+// CreatePrintJob godoc
+// @Summary      Print a receipt, labels or a Z-report
+// @Description  Queue a document for the shop's print bridges: a sale's receipt (sale_id), barcode labels for a product (product_id, copies) or the Z-report of a business day (date). It is rendered for the printer now and printed by the first bridge to collect it, or only by bridge_id when given.
+// @Tags         printing
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.CreatePrintJobRequest  true  "What to print"
+// @Success      202  {object}  Domain.PrintJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/jobs [post]
+// @Security     BearerAuth
+func (c *PrintController) CreatePrintJob(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreatePrintJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.printUC.CreateJob(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// PrintShelfLabels godoc
+// @Summary      Print shelf labels
+// @Description  Labels with the name, price and barcode of each product listed in product_ids, and of every active product in category or whose price changed since price_changed_since, e.g. to reprint the tags after a price update. They are width_mm by height_mm (50 by 30 by default) and download as a PDF, one label per page or tiled on A4 or Letter sheets, or as ESC/POS. With print set they are queued for the print bridges in ESC/POS instead.
+// @Tags         printing
+// @Accept       json
+// @Produce      application/pdf
+// @Produce      application/octet-stream
+// @Produce      json
+// @Param        businessId  path  string                    true  "Business ID"
+// @Param        request     body  Domain.ShelfLabelRequest  true  "Products and label layout"
+// @Success      200  {file}    file
+// @Success      202  {object}  Domain.PrintJob  "Queued for the print bridges"
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/labels [post]
+// @Security     BearerAuth
+func (c *PrintController) PrintShelfLabels(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ShelfLabelRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if req.Print {
+		job, err := c.printUC.PrintShelfLabels(ctx.Param("businessId"), userID.(string), req)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusAccepted, job)
+		return
+	}
+
+	data, contentType, filename, err := c.printUC.RenderShelfLabels(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// GetPrintJobs godoc
+// @Summary      List print jobs
+// @Description  The business's print jobs, newest first, without their printer data
+// @Tags         printing
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        type        query  string  false  "Type: receipt, barcode, z_report"
+// @Param        status      query  string  false  "Status: queued, claimed, printed, failed"
+// @Param        bridge_id   query  string  false  "Bridge that collected the job"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.PrintJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/jobs [get]
+// @Security     BearerAuth
+func (c *PrintController) GetPrintJobs(ctx *gin.Context) {
+	filters := Domain.PrintJobFilters{Limit: 50}
+
+	if jobType := ctx.Query("type"); jobType != "" {
+		t := Domain.PrintJobType(jobType)
+		filters.Type = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.PrintJobStatus(status)
+		filters.Status = &s
+	}
+
+	if bridgeID := ctx.Query("bridge_id"); bridgeID != "" {
+		filters.BridgeID = &bridgeID
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	jobs, err := c.printUC.GetJobs(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// GetPrintJob godoc
+// @Summary      Get a print job
+// @Description  Follow a print job until a bridge reports it printed or failed
+// @Tags         printing
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        jobId       path  string  true  "Print job ID"
+// @Success      200  {object}  Domain.PrintJob
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/print/jobs/{jobId} [get]
+// @Security     BearerAuth
+func (c *PrintController) GetPrintJob(ctx *gin.Context) {
+	job, err := c.printUC.GetJob(ctx.Param("jobId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// ClaimPrintJobs godoc
+// @Summary      Collect print jobs (print bridge)
+// @Description  Called by a print bridge with its own token. Returns up to max jobs with their ESC/POS data (base64), waiting up to wait seconds for one to be queued when there are none, then an empty list. Each job must be acknowledged within two minutes or it is offered again. The bridge reports the printers it can reach and its version on every call.
+// @Tags         printing
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.PrintBridgeClaimRequest  true  "How long to wait and how many jobs to take"
+// @Success      200  {array}   Domain.PrintJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/print-bridge/jobs/claim [post]
+// @Security     BearerAuth
+func (c *PrintController) ClaimPrintJobs(ctx *gin.Context) {
+	bridge := ctx.MustGet("printBridge").(*Domain.PrintBridge)
+
+	var req Domain.PrintBridgeClaimRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	jobs, err := c.printUC.ClaimJobs(ctx.Request.Context(), bridge, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// AckPrintJob godoc
+// @Summary      Report a print job's outcome (print bridge)
+// @Description  Called by a print bridge with its own token once a collected job is printed or has failed. A failure with retry set is offered again while the job has attempts left.
+// @Tags         printing
+// @Accept       json
+// @Produce      json
+// @Param        jobId    path  string                     true  "Print job ID"
+// @Param        request  body  Domain.PrintJobAckRequest  true  "Outcome"
+// @Success      200  {object}  Domain.PrintJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/print-bridge/jobs/{jobId}/ack [post]
+// @Security     BearerAuth
+func (c *PrintController) AckPrintJob(ctx *gin.Context) {
+	bridge := ctx.MustGet("printBridge").(*Domain.PrintBridge)
+
+	var req Domain.PrintJobAckRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.printUC.AckJob(bridge, ctx.Param("jobId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
@@ -0,0 +1,241 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ProductionController struct {
+	productionUC Usecases.ProductionUseCase
+}
+
+func NewProductionController(productionUC Usecases.ProductionUseCase) *ProductionController {
+	return &ProductionController{productionUC: productionUC}
+}
+
+// SetRecipe godoc
+// @Summary      Set a product's recipe
+// @Description  Replaces the components one batch of the product takes and the units it yields
+// @Tags         production
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                   true  "Business ID"
+// @Param        productId   path  string                   true  "Product ID"
+// @Param        request     body  Domain.SetRecipeRequest  true  "Recipe"
+// @Success      200  {object}  Domain.Recipe
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/recipes/{productId} [put]
+// @Security     BearerAuth
+func (c *ProductionController) SetRecipe(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.SetRecipeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	recipe, err := c.productionUC.SetRecipe(ctx.Request.Context(), ctx.Param("businessId"), ctx.Param("productId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+// GetRecipes godoc
+// @Summary      List recipes
+// @Tags         production
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.Recipe
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/recipes [get]
+// @Security     BearerAuth
+func (c *ProductionController) GetRecipes(ctx *gin.Context) {
+	recipes, err := c.productionUC.GetRecipes(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipes)
+}
+
+// GetRecipe godoc
+// @Summary      Get a product's recipe
+// @Tags         production
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      200  {object}  Domain.Recipe
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/recipes/{productId} [get]
+// @Security     BearerAuth
+func (c *ProductionController) GetRecipe(ctx *gin.Context) {
+	recipe, err := c.productionUC.GetRecipe(ctx.Param("businessId"), ctx.Param("productId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, recipe)
+}
+
+// DeleteRecipe godoc
+// @Summary      Delete a product's recipe
+// @Description  Production orders already made keep what they took
+// @Tags         production
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      204
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/recipes/{productId} [delete]
+// @Security     BearerAuth
+func (c *ProductionController) DeleteRecipe(ctx *gin.Context) {
+	if err := c.productionUC.DeleteRecipe(ctx.Param("businessId"), ctx.Param("productId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// Produce godoc
+// @Summary      Record a production order
+// @Description  Makes units of a product from its recipe: the components are taken out of stock and the units added at what the components cost, averaging the product's cost price over its stock
+// @Tags         production
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        request     body  Domain.CreateProductionOrderRequest  true  "Product and quantity"
+// @Success      201  {object}  Domain.ProductionOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/orders [post]
+// @Security     BearerAuth
+func (c *ProductionController) Produce(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateProductionOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	order, err := c.productionUC.Produce(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
+
+// GetOrders godoc
+// @Summary      List production orders
+// @Description  Newest first
+// @Tags         production
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        product_id  query  string  false  "Only this product's"
+// @Param        from        query  string  false  "Made at or after (RFC 3339)"
+// @Param        to          query  string  false  "Made before (RFC 3339)"
+// @Param        limit       query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.ProductionOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/orders [get]
+// @Security     BearerAuth
+func (c *ProductionController) GetOrders(ctx *gin.Context) {
+	var filters Domain.ProductionOrderFilters
+	if productID := ctx.Query("product_id"); productID != "" {
+		filters.ProductID = &productID
+	}
+	if from, err := time.Parse(time.RFC3339, ctx.Query("from")); err == nil {
+		filters.StartDate = &from
+	}
+	if to, err := time.Parse(time.RFC3339, ctx.Query("to")); err == nil {
+		filters.EndDate = &to
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	orders, err := c.productionUC.GetOrders(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orders)
+}
+
+// GetOrder godoc
+// @Summary      Get a production order
+// @Tags         production
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        orderId     path  string  true  "Production order ID"
+// @Success      200  {object}  Domain.ProductionOrder
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/orders/{orderId} [get]
+// @Security     BearerAuth
+func (c *ProductionController) GetOrder(ctx *gin.Context) {
+	order, err := c.productionUC.GetOrder(ctx.Param("orderId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// GetProductionReport godoc
+// @Summary      Production history report
+// @Description  What was made between the days and at what cost, by product, and the components it took. Defaults to the last 30 days.
+// @Tags         production
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD)"
+// @Success      200  {object}  Domain.ProductionReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/production/report [get]
+// @Security     BearerAuth
+func (c *ProductionController) GetProductionReport(ctx *gin.Context) {
+	report, err := c.productionUC.GetReport(ctx.Param("businessId"), ctx.Query("start_date"), ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
@@ -0,0 +1,252 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type PushController struct {
+	pushUC Usecases.PushUseCase
+}
+
+func NewPushController(pushUC Usecases.PushUseCase) *PushController {
+	return &PushController{pushUC: pushUC}
+}
+
+// RegisterPushDevice godoc
+// @Summary      Register a device for push notifications
+// @Description  Register the phone's FCM (android) or APNs (ios) token for the signed-in user. Call on every app start; a token registered to another user moves to this one. Security alerts are sent to every registered device.
+// @Tags         push
+// @Accept       json
+// @Produce      json
+// @Param        request  body  Domain.RegisterPushDeviceRequest  true  "Device token"
+// @Success      201  {object}  Domain.PushDevice
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/push/devices [post]
+// @Security     BearerAuth
+func (c *PushController) RegisterPushDevice(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RegisterPushDeviceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	device, err := c.pushUC.RegisterDevice(userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, device)
+}
+
+// GetPushDevices godoc
+// @Summary      List push devices
+// @Description  Devices registered for the signed-in user's notifications, most recently seen first
+// @Tags         push
+// @Produce      json
+// @Success      200  {array}   Domain.PushDevice
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/push/devices [get]
+// @Security     BearerAuth
+func (c *PushController) GetPushDevices(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	devices, err := c.pushUC.GetDevices(userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, devices)
+}
+
+// DeletePushDevice godoc
+// @Summary      Unregister a push device
+// @Description  Stop sending notifications to a device, e.g. on sign-out
+// @Tags         push
+// @Param        deviceId  path  string  true  "Device ID"
+// @Success      204
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/push/devices/{deviceId} [delete]
+// @Security     BearerAuth
+func (c *PushController) DeletePushDevice(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	if err := c.pushUC.DeleteDevice(userID.(string), ctx.Param("deviceId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RecordPushReceipt godoc
+// @Summary      Report a push notification received or opened
+// @Description  The app reports the message_id from a notification's data when it is shown (delivered) and when the user taps it (opened)
+// @Tags         push
+// @Accept       json
+// @Param        messageId  path  string                     true  "Message ID"
+// @Param        request    body  Domain.PushReceiptRequest  true  "Receipt"
+// @Success      204
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/push/messages/{messageId}/receipt [post]
+// @Security     BearerAuth
+func (c *PushController) RecordPushReceipt(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.PushReceiptRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if err := c.pushUC.RecordReceipt(userID.(string), ctx.Param("messageId"), req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// GetPushSubscription godoc
+// @Summary      Get a device's notifications for the business
+// @Description  The topics a device of the signed-in user follows for the business (big_sale, daily_summary, sync_failure), the big-sale amount and the summary's shop-local hour
+// @Tags         push
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        deviceId    path  string  true  "Device ID"
+// @Success      200  {object}  Domain.PushSubscription
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/push/subscriptions/{deviceId} [get]
+// @Security     BearerAuth
+func (c *PushController) GetPushSubscription(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	subscription, err := c.pushUC.GetSubscription(userID.(string), ctx.Param("deviceId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+// UpdatePushSubscription godoc
+// @Summary      Choose a device's notifications for the business
+// @Description  Follow topics of the business on a device of the signed-in user; an empty topic list turns its notifications off
+// @Tags         push
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                                true  "Business ID"
+// @Param        deviceId    path  string                                true  "Device ID"
+// @Param        request     body  Domain.UpdatePushSubscriptionRequest  true  "Notifications to change"
+// @Success      200  {object}  Domain.PushSubscription
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/push/subscriptions/{deviceId} [patch]
+// @Security     BearerAuth
+func (c *PushController) UpdatePushSubscription(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.UpdatePushSubscriptionRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	subscription, err := c.pushUC.UpdateSubscription(userID.(string), ctx.Param("deviceId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, subscription)
+}
+
+// GetPushMessages godoc
+// @Summary      Push delivery log
+// @Description  Notifications pushed for the business and their delivery receipts, newest first. Owners only.
+// @Tags         push
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        topic       query  string  false  "Topic: big_sale, daily_summary, sync_failure"
+// @Param        status      query  string  false  "Status: queued, sent, failed, delivered, opened"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.PushMessage
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/push/messages [get]
+// @Security     BearerAuth
+func (c *PushController) GetPushMessages(ctx *gin.Context) {
+	filters := Domain.PushMessageFilters{Limit: 50}
+
+	if topic := ctx.Query("topic"); topic != "" {
+		t := Domain.PushTopic(topic)
+		filters.Topic = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.PushStatus(status)
+		filters.Status = &s
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	messages, err := c.pushUC.GetMessages(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, messages)
+}
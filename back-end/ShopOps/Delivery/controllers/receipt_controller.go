@@ -0,0 +1,223 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReceiptController struct {
+	receiptUC Usecases.ReceiptUseCase
+}
+
+func NewReceiptController(receiptUC Usecases.ReceiptUseCase) *ReceiptController {
+	return &ReceiptController{receiptUC: receiptUC}
+}
+
+// CreateTemplate godoc
+// @Summary      Create receipt template
+// @Description  Create a receipt layout (header, footer, logo, visible fields, paper width) shared by all devices
+// @Tags         receipts
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        request     body  Domain.CreateReceiptTemplateRequest  true  "Template details"
+// @Success      201  {object}  Domain.ReceiptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/receipt-templates [post]
+// @Security     BearerAuth
+func (c *ReceiptController) CreateTemplate(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateReceiptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	template, err := c.receiptUC.CreateTemplate(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, template)
+}
+
+// GetTemplates godoc
+// @Summary      List receipt templates
+// @Description  Get all receipt templates for a business, default first
+// @Tags         receipts
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.ReceiptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/receipt-templates [get]
+// @Security     BearerAuth
+func (c *ReceiptController) GetTemplates(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	templates, err := c.receiptUC.GetTemplates(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, templates)
+}
+
+// GetTemplate godoc
+// @Summary      Get receipt template
+// @Description  Get a specific receipt template
+// @Tags         receipts
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        templateId  path  string  true  "Template ID"
+// @Success      200  {object}  Domain.ReceiptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/receipt-templates/{templateId} [get]
+// @Security     BearerAuth
+func (c *ReceiptController) GetTemplate(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	templateID := ctx.Param("templateId")
+
+	if businessID == "" || templateID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Template ID are required")
+		return
+	}
+
+	template, err := c.receiptUC.GetTemplateByID(ctx.Request.Context(), templateID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// UpdateTemplate godoc
+// @Summary      Update receipt template
+// @Description  Update a receipt template; setting is_default makes it the business default
+// @Tags         receipts
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        templateId  path  string                               true  "Template ID"
+// @Param        request     body  Domain.UpdateReceiptTemplateRequest  true  "Template updates"
+// @Success      200  {object}  Domain.ReceiptTemplate
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/receipt-templates/{templateId} [patch]
+// @Security     BearerAuth
+func (c *ReceiptController) UpdateTemplate(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	templateID := ctx.Param("templateId")
+
+	if businessID == "" || templateID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Template ID are required")
+		return
+	}
+
+	var req Domain.UpdateReceiptTemplateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	template, err := c.receiptUC.UpdateTemplate(ctx.Request.Context(), templateID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, template)
+}
+
+// DeleteTemplate godoc
+// @Summary      Delete receipt template
+// @Description  Delete a receipt template (the default template cannot be deleted)
+// @Tags         receipts
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        templateId  path  string  true  "Template ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/receipt-templates/{templateId} [delete]
+// @Security     BearerAuth
+func (c *ReceiptController) DeleteTemplate(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	templateID := ctx.Param("templateId")
+
+	if businessID == "" || templateID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Template ID are required")
+		return
+	}
+
+	if err := c.receiptUC.DeleteTemplate(ctx.Request.Context(), templateID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Receipt template deleted successfully"})
+}
+
+// RenderReceipt godoc
+// @Summary      Render sale receipt
+// @Description  Render a sale receipt as an ESC/POS byte stream for thermal printers or as printable HTML
+// @Tags         receipts
+// @Produce      html
+// @Produce      octet-stream
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        saleId       path   string  true   "Sale ID"
+// @Param        format       query  string  false  "Output format: html (default), escpos"
+// @Param        template_id  query  string  false  "Template ID, defaults to the business default"
+// @Success      200  {file}    file
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sales/{saleId}/receipt [get]
+// @Security     BearerAuth
+func (c *ReceiptController) RenderReceipt(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	saleID := ctx.Param("saleId")
+
+	if businessID == "" || saleID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Sale ID are required")
+		return
+	}
+
+	format := Domain.ReceiptFormat(ctx.DefaultQuery("format", string(Domain.ReceiptFormatHTML)))
+
+	data, contentType, err := c.receiptUC.RenderReceipt(ctx.Request.Context(), saleID, businessID, ctx.Query("template_id"), format, Infrastructure.RequestLanguage(ctx))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if format == Domain.ReceiptFormatESCPOS {
+		ctx.Header("Content-Disposition", "attachment; filename=receipt_"+saleID+".bin")
+	}
+	ctx.Data(http.StatusOK, contentType, data)
+}
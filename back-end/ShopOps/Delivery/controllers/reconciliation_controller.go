@@ -0,0 +1,341 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ReconciliationController struct {
+	reconciliationUC Usecases.ReconciliationUseCase
+}
+
+func NewReconciliationController(reconciliationUC Usecases.ReconciliationUseCase) *ReconciliationController {
+	return &ReconciliationController{reconciliationUC: reconciliationUC}
+}
+
+// ImportStatement godoc
+// @Summary      Import a bank or wallet statement
+// @Description  Upload a CBE, Awash or Telebirr statement exported as CSV. Lines already imported from an earlier statement are skipped; the new ones are matched to bank, card and mobile sale payments and to expenses of the same amount within 3 days, by transaction reference where the line has it. Owners only.
+// @Tags         reconciliation
+// @Accept       multipart/form-data
+// @Produce      json
+// @Param        businessId  path      string  true  "Business ID"
+// @Param        source      query     string  true  "Source: cbe, awash, telebirr"
+// @Param        statement   formData  file    true  "Statement CSV"
+// @Success      201  {object}  Domain.BankStatement
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      413  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/statements [post]
+// @Security     BearerAuth
+func (c *ReconciliationController) ImportStatement(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	file, err := Infrastructure.FormFileStream(ctx, "statement", Usecases.MaxStatementSize)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	statement, err := c.reconciliationUC.ImportStatement(ctx.Param("businessId"), userID.(string),
+		Domain.StatementSource(ctx.Query("source")), file.Filename, file)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, statement)
+}
+
+// GetStatements godoc
+// @Summary      List imported statements
+// @Description  Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.BankStatement
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/statements [get]
+// @Security     BearerAuth
+func (c *ReconciliationController) GetStatements(ctx *gin.Context) {
+	statements, err := c.reconciliationUC.GetStatements(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statements)
+}
+
+// GetStatement godoc
+// @Summary      Get an imported statement
+// @Description  Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId   path  string  true  "Business ID"
+// @Param        statementId  path  string  true  "Statement ID"
+// @Success      200  {object}  Domain.BankStatement
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/statements/{statementId} [get]
+// @Security     BearerAuth
+func (c *ReconciliationController) GetStatement(ctx *gin.Context) {
+	statement, err := c.reconciliationUC.GetStatement(ctx.Param("statementId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, statement)
+}
+
+// DeleteStatement godoc
+// @Summary      Delete an imported statement
+// @Description  Remove the statement and the lines imported from it, with their matches, e.g. to import it again. Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId   path  string  true  "Business ID"
+// @Param        statementId  path  string  true  "Statement ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/statements/{statementId} [delete]
+// @Security     BearerAuth
+func (c *ReconciliationController) DeleteStatement(ctx *gin.Context) {
+	if err := c.reconciliationUC.DeleteStatement(ctx.Param("statementId"), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Statement deleted successfully"})
+}
+
+// GetStatementLines godoc
+// @Summary      List statement lines
+// @Description  Statement lines oldest first, with the payment or expense each is matched to. Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId    path   string  true   "Business ID"
+// @Param        statement_id  query  string  false  "Lines imported from this statement"
+// @Param        status        query  string  false  "Status: unmatched, matched, ignored"
+// @Param        start_date    query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date      query  string  false  "End date (YYYY-MM-DD)"
+// @Param        limit         query  int     false  "Limit results"
+// @Param        offset        query  int     false  "Offset results"
+// @Success      200  {array}   Domain.StatementLine
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/lines [get]
+// @Security     BearerAuth
+func (c *ReconciliationController) GetStatementLines(ctx *gin.Context) {
+	filters := Domain.StatementLineFilters{Limit: 100}
+
+	if statementID := ctx.Query("statement_id"); statementID != "" {
+		filters.StatementID = &statementID
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.StatementLineStatus(status)
+		filters.Status = &s
+	}
+
+	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filters.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			endDate = endDate.Add(24*time.Hour - time.Nanosecond)
+			filters.EndDate = &endDate
+		}
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	lines, err := c.reconciliationUC.GetLines(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, lines)
+}
+
+// MatchStatementLine godoc
+// @Summary      Match a statement line by hand
+// @Description  Match the line to a sale, or one payment of a split sale, or to an expense, of the same amount. Replaces any match the line had. Owners only.
+// @Tags         reconciliation
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        lineId      path  string                            true  "Statement line ID"
+// @Param        request     body  Domain.MatchStatementLineRequest  true  "Recorded payment"
+// @Success      200  {object}  Domain.StatementLine
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/lines/{lineId}/match [put]
+// @Security     BearerAuth
+func (c *ReconciliationController) MatchStatementLine(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.MatchStatementLineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	line, err := c.reconciliationUC.MatchLine(ctx.Request.Context(), ctx.Param("lineId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, line)
+}
+
+// UnmatchStatementLine godoc
+// @Summary      Unmatch a statement line
+// @Description  Clear the line's match, or take it off the ignored list. Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        lineId      path  string  true  "Statement line ID"
+// @Success      200  {object}  Domain.StatementLine
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/lines/{lineId}/match [delete]
+// @Security     BearerAuth
+func (c *ReconciliationController) UnmatchStatementLine(ctx *gin.Context) {
+	line, err := c.reconciliationUC.UnmatchLine(ctx.Param("lineId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, line)
+}
+
+// IgnoreStatementLine godoc
+// @Summary      Ignore a statement line
+// @Description  Mark a line with nothing to match in the shop, such as a bank fee or a transfer between the owner's accounts, with a note saying why. Owners only.
+// @Tags         reconciliation
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID"
+// @Param        lineId      path  string                             true  "Statement line ID"
+// @Param        request     body  Domain.IgnoreStatementLineRequest  true  "Why"
+// @Success      200  {object}  Domain.StatementLine
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/lines/{lineId}/ignore [post]
+// @Security     BearerAuth
+func (c *ReconciliationController) IgnoreStatementLine(ctx *gin.Context) {
+	var req Domain.IgnoreStatementLineRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	line, err := c.reconciliationUC.IgnoreLine(ctx.Param("lineId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, line)
+}
+
+// AutoMatchStatementLines godoc
+// @Summary      Match unmatched lines again
+// @Description  Run automatic matching over every unmatched line, e.g. after recording payments that were missing. Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/auto-match [post]
+// @Security     BearerAuth
+func (c *ReconciliationController) AutoMatchStatementLines(ctx *gin.Context) {
+	matched, err := c.reconciliationUC.AutoMatch(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"matched": matched})
+}
+
+// GetReconciliationReport godoc
+// @Summary      Get the reconciliation report
+// @Description  For the period: money in and out on the statements, how much was matched, the lines with nothing recorded in the shop, and the bank, card and mobile sale payments that are on no statement. Owners only.
+// @Tags         reconciliation
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        start_date  query  string  true  "Start date (YYYY-MM-DD)"
+// @Param        end_date    query  string  true  "End date (YYYY-MM-DD)"
+// @Success      200  {object}  Domain.ReconciliationReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reconciliation/report [get]
+// @Security     BearerAuth
+func (c *ReconciliationController) GetReconciliationReport(ctx *gin.Context) {
+	startDate, err := time.Parse("2006-01-02", ctx.Query("start_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "start_date is required as YYYY-MM-DD")
+		return
+	}
+
+	endDate, err := time.Parse("2006-01-02", ctx.Query("end_date"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "end_date is required as YYYY-MM-DD")
+		return
+	}
+
+	report, err := c.reconciliationUC.GetReport(ctx.Param("businessId"), startDate, endDate.Add(24*time.Hour-time.Nanosecond))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
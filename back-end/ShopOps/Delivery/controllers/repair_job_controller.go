@@ -0,0 +1,356 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RepairJobController struct {
+	repairUC Usecases.RepairJobUseCase
+}
+
+func NewRepairJobController(repairUC Usecases.RepairJobUseCase) *RepairJobController {
+	return &RepairJobController{repairUC: repairUC}
+}
+
+// CreateJob godoc
+// @Summary      Take in a repair job
+// @Description  Records a device left for repair, with the customer's description of the problem, as received
+// @Tags         repair-jobs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                         true  "Business ID"
+// @Param        request     body  Domain.CreateRepairJobRequest  true  "Customer and device"
+// @Success      201  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs [post]
+// @Security     BearerAuth
+func (c *RepairJobController) CreateJob(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateRepairJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.repairUC.CreateJob(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, job)
+}
+
+// GetJobs godoc
+// @Summary      List repair jobs
+// @Description  Newest first
+// @Tags         repair-jobs
+// @Produce      json
+// @Param        businessId      path   string  true   "Business ID"
+// @Param        status          query  string  false  "Status: received, diagnosing, in_progress, waiting_parts, ready, collected, cancelled"
+// @Param        open            query  bool    false  "Only jobs not yet collected or cancelled, or with false only those"
+// @Param        technician_id   query  string  false  "Only this technician's"
+// @Param        customer_phone  query  string  false  "Only this customer's"
+// @Param        limit           query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset          query  int     false  "Offset results"
+// @Success      200  {array}   Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs [get]
+// @Security     BearerAuth
+func (c *RepairJobController) GetJobs(ctx *gin.Context) {
+	var filters Domain.RepairJobFilters
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.RepairJobStatus(status)
+		filters.Status = &s
+	}
+	if open, err := strconv.ParseBool(ctx.Query("open")); err == nil {
+		filters.Open = &open
+	}
+	if technicianID := ctx.Query("technician_id"); technicianID != "" {
+		filters.TechnicianID = &technicianID
+	}
+	if phone := ctx.Query("customer_phone"); phone != "" {
+		filters.CustomerPhone = &phone
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	jobs, err := c.repairUC.GetJobs(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, jobs)
+}
+
+// GetJob godoc
+// @Summary      Get a repair job
+// @Tags         repair-jobs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        jobId       path  string  true  "Repair job ID"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId} [get]
+// @Security     BearerAuth
+func (c *RepairJobController) GetJob(ctx *gin.Context) {
+	job, err := c.repairUC.GetJob(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// UpdateJob godoc
+// @Summary      Update a repair job
+// @Description  Changes the customer, device, diagnosis, estimate or technician; only the fields given change
+// @Tags         repair-jobs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                         true  "Business ID"
+// @Param        jobId       path  string                         true  "Repair job ID"
+// @Param        request     body  Domain.UpdateRepairJobRequest  true  "Fields to change"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId} [patch]
+// @Security     BearerAuth
+func (c *RepairJobController) UpdateJob(ctx *gin.Context) {
+	var req Domain.UpdateRepairJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.repairUC.UpdateJob(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// SetStatus godoc
+// @Summary      Move a repair job along
+// @Description  A job made ready is texted to the customer. A job is collected only once all its parts and labor are billed; cancelling one puts its parts back into stock, and needs its sales voided first.
+// @Tags         repair-jobs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        jobId       path  string                            true  "Repair job ID"
+// @Param        request     body  Domain.SetRepairJobStatusRequest  true  "New status"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId}/status [post]
+// @Security     BearerAuth
+func (c *RepairJobController) SetStatus(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.SetRepairJobStatusRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.repairUC.SetStatus(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// AddPart godoc
+// @Summary      Fit a part on a repair job
+// @Description  Takes the product out of stock, priced at its selling price unless given
+// @Tags         repair-jobs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                       true  "Business ID"
+// @Param        jobId       path  string                       true  "Repair job ID"
+// @Param        request     body  Domain.AddRepairPartRequest  true  "Part"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId}/parts [post]
+// @Security     BearerAuth
+func (c *RepairJobController) AddPart(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.AddRepairPartRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.repairUC.AddPart(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// RemovePart godoc
+// @Summary      Take a part off a repair job
+// @Description  Puts an unbilled part back into stock
+// @Tags         repair-jobs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        jobId       path  string  true  "Repair job ID"
+// @Param        partId      path  string  true  "Part ID"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId}/parts/{partId} [delete]
+// @Security     BearerAuth
+func (c *RepairJobController) RemovePart(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	job, err := c.repairUC.RemovePart(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), userID.(string), ctx.Param("partId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// AddLabor godoc
+// @Summary      Log labor on a repair job
+// @Description  Priced and described from a service item when service_id is given, otherwise from the request
+// @Tags         repair-jobs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        jobId       path  string                        true  "Repair job ID"
+// @Param        request     body  Domain.AddRepairLaborRequest  true  "Labor"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId}/labor [post]
+// @Security     BearerAuth
+func (c *RepairJobController) AddLabor(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.AddRepairLaborRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.repairUC.AddLabor(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// RemoveLabor godoc
+// @Summary      Remove labor from a repair job
+// @Tags         repair-jobs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        jobId       path  string  true  "Repair job ID"
+// @Param        laborId     path  string  true  "Labor ID"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId}/labor/{laborId} [delete]
+// @Security     BearerAuth
+func (c *RepairJobController) RemoveLabor(ctx *gin.Context) {
+	job, err := c.repairUC.RemoveLabor(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), ctx.Param("laborId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
+
+// BillJob godoc
+// @Summary      Bill a repair job
+// @Description  Records a sale for each part and labor line not yet billed, credited to the job's technician. Parts were taken out of stock when fitted, so their sales move no stock. Voiding one of the sales puts its line back to be billed again.
+// @Tags         repair-jobs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        jobId       path  string                        true  "Repair job ID"
+// @Param        request     body  Domain.BillRepairJobRequest  true  "Payment"
+// @Success      200  {object}  Domain.RepairJob
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/repair-jobs/{jobId}/bill [post]
+// @Security     BearerAuth
+func (c *RepairJobController) BillJob(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.BillRepairJobRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	job, err := c.repairUC.BillJob(ctx.Request.Context(), ctx.Param("jobId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, job)
+}
@@ -53,10 +53,13 @@ func (c *ReportController) GetDashboard(ctx *gin.Context) {
 // @Tags         reports
 // @Produce      json
 // @Param        businessId  path    string  true   "Business ID"
-// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, custom"
+// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly, custom"
 // @Param        start_date  query   string  false  "Start date (YYYY-MM-DD) for custom period"
 // @Param        end_date    query   string  false  "End date (YYYY-MM-DD) for custom period"
-// @Success      200  {object}  Domain.SalesReport
+// @Param        compare     query   string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align       query   string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Param        calendar    query   string  false  "Calendar for fiscal_yearly and dates: gregorian (default) or ethiopian"
+// @Success      200  {object}  Domain.SalesReport  "Domain.ReportComparison when compare is set"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/reports/sales [get]
@@ -71,6 +74,7 @@ func (c *ReportController) GetSalesReport(ctx *gin.Context) {
 	var req Domain.ReportRequest
 	req.BusinessID = businessID
 	req.Type = Domain.ReportTypeSales
+	req.Calendar = parseCalendar(ctx)
 
 	// Parse period
 	if period := ctx.Query("period"); period != "" {
@@ -92,6 +96,16 @@ func (c *ReportController) GetSalesReport(ctx *gin.Context) {
 		}
 	}
 
+	if opts, ok := parseCompareOptions(ctx); ok {
+		comparison, err := c.reportUC.CompareReport(req, opts)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
 	report, err := c.reportUC.GenerateReport(req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
@@ -107,11 +121,14 @@ func (c *ReportController) GetSalesReport(ctx *gin.Context) {
 // @Tags         reports
 // @Produce      json
 // @Param        businessId  path    string  true   "Business ID"
-// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, custom"
+// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly, custom"
 // @Param        start_date  query   string  false  "Start date (YYYY-MM-DD) for custom period"
 // @Param        end_date    query   string  false  "End date (YYYY-MM-DD) for custom period"
 // @Param        category    query   string  false  "Filter by category"
-// @Success      200  {object}  Domain.ExpensesReport
+// @Param        compare     query   string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align       query   string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Param        calendar    query   string  false  "Calendar for fiscal_yearly and dates: gregorian (default) or ethiopian"
+// @Success      200  {object}  Domain.ExpensesReport  "Domain.ReportComparison when compare is set"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/reports/expenses [get]
@@ -126,6 +143,7 @@ func (c *ReportController) GetExpensesReport(ctx *gin.Context) {
 	var req Domain.ReportRequest
 	req.BusinessID = businessID
 	req.Type = Domain.ReportTypeExpenses
+	req.Calendar = parseCalendar(ctx)
 
 	// Parse period
 	if period := ctx.Query("period"); period != "" {
@@ -152,6 +170,16 @@ func (c *ReportController) GetExpensesReport(ctx *gin.Context) {
 		req.Category = &category
 	}
 
+	if opts, ok := parseCompareOptions(ctx); ok {
+		comparison, err := c.reportUC.CompareReport(req, opts)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
 	report, err := c.reportUC.GenerateReport(req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
@@ -167,10 +195,13 @@ func (c *ReportController) GetExpensesReport(ctx *gin.Context) {
 // @Tags         reports
 // @Produce      json
 // @Param        businessId  path    string  true   "Business ID"
-// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, custom"
+// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly, custom"
 // @Param        start_date  query   string  false  "Start date (YYYY-MM-DD) for custom period"
 // @Param        end_date    query   string  false  "End date (YYYY-MM-DD) for custom period"
-// @Success      200  {object}  Domain.ProfitReport
+// @Param        compare     query   string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align       query   string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Param        calendar    query   string  false  "Calendar for fiscal_yearly and dates: gregorian (default) or ethiopian"
+// @Success      200  {object}  Domain.ProfitReport  "Domain.ReportComparison when compare is set"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/reports/profit [get]
@@ -185,6 +216,7 @@ func (c *ReportController) GetProfitReport(ctx *gin.Context) {
 	var req Domain.ReportRequest
 	req.BusinessID = businessID
 	req.Type = Domain.ReportTypeProfit
+	req.Calendar = parseCalendar(ctx)
 
 	// Parse period
 	if period := ctx.Query("period"); period != "" {
@@ -206,6 +238,16 @@ func (c *ReportController) GetProfitReport(ctx *gin.Context) {
 		}
 	}
 
+	if opts, ok := parseCompareOptions(ctx); ok {
+		comparison, err := c.reportUC.CompareReport(req, opts)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
 	report, err := c.reportUC.GenerateReport(req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
@@ -248,24 +290,90 @@ func (c *ReportController) GetInventoryReport(ctx *gin.Context) {
 
 // ExportReport godoc
 // @Summary      Generate CSV export
-// @Description  Export report data to CSV format
+// @Description  Export report data to CSV format. Customers' names and phone numbers are masked unless the caller is the owner or an employee with the view_pii permission.
 // @Tags         reports
 // @Produce      text/csv
 // @Param        businessId  path    string  true   "Business ID"
-// @Param        type        query   string  true   "Report type: sales, expenses, profit, inventory"
-// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, custom"
+// @Param        type        query   string  true   "Report type: sales, expenses, profit, inventory, dead_stock"
+// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly, custom"
 // @Param        start_date  query   string  false  "Start date (YYYY-MM-DD) for custom period"
 // @Param        end_date    query   string  false  "End date (YYYY-MM-DD) for custom period"
+// @Param        days        query   int     false  "Dead stock: days without a sale (default 90)"
+// @Param        sort_by     query   string  false  "Dead stock: capital, days_since_sale, stock, name"
+// @Param        order       query   string  false  "Dead stock: asc or desc"
+// @Param        calendar    query   string  false  "Calendar for fiscal_yearly and dates: gregorian (default) or ethiopian"
 // @Success      200  {string}  string  "CSV file"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/reports/export [get]
 // @Security     BearerAuth
 func (c *ReportController) ExportReport(ctx *gin.Context) {
+	req, ok := parseExportRequest(ctx)
+	if !ok {
+		return
+	}
+
+	// Set format to CSV
+	format := "csv"
+	req.Format = &format
+
+	data, filename, err := c.reportUC.ExportReport(req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.Header("Content-Type", "text/csv")
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Data(http.StatusOK, "text/csv", data)
+}
+
+// EmailExportReport godoc
+// @Summary      Email a CSV export
+// @Description  Export report data to CSV in the background and email the signed-in user a download link when it is ready. Takes the same parameters as the export endpoint, and masks customers' names and phone numbers as it does.
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path    string  true   "Business ID"
+// @Param        type        query   string  true   "Report type: sales, expenses, profit, inventory, dead_stock"
+// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly, custom"
+// @Param        start_date  query   string  false  "Start date (YYYY-MM-DD) for custom period"
+// @Param        end_date    query   string  false  "End date (YYYY-MM-DD) for custom period"
+// @Param        days        query   int     false  "Dead stock: days without a sale (default 90)"
+// @Param        sort_by     query   string  false  "Dead stock: capital, days_since_sale, stock, name"
+// @Param        order       query   string  false  "Dead stock: asc or desc"
+// @Param        calendar    query   string  false  "Calendar for fiscal_yearly and dates: gregorian (default) or ethiopian"
+// @Success      202  {object}  Domain.Job
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/export/email [post]
+// @Security     BearerAuth
+func (c *ReportController) EmailExportReport(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	req, ok := parseExportRequest(ctx)
+	if !ok {
+		return
+	}
+
+	job, err := c.reportUC.QueueExportEmail(ctx.Request.Context(), req, userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// parseExportRequest reads the export query, answering 400 when it cannot
+func parseExportRequest(ctx *gin.Context) (Domain.ReportRequest, bool) {
 	businessID := ctx.Param("businessId")
 	if businessID == "" {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
-		return
+		return Domain.ReportRequest{}, false
 	}
 
 	var req Domain.ReportRequest
@@ -276,7 +384,7 @@ func (c *ReportController) ExportReport(ctx *gin.Context) {
 		req.Type = Domain.ReportType(reportType)
 	} else {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Report type is required")
-		return
+		return Domain.ReportRequest{}, false
 	}
 
 	// Parse period
@@ -299,19 +407,20 @@ func (c *ReportController) ExportReport(ctx *gin.Context) {
 		}
 	}
 
-	// Set format to CSV
-	format := "csv"
-	req.Format = &format
-
-	data, filename, err := c.reportUC.ExportReport(req)
-	if err != nil {
-		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
-		return
+	// Dead stock options
+	if daysStr := ctx.Query("days"); daysStr != "" {
+		if days, err := strconv.Atoi(daysStr); err == nil {
+			req.Days = days
+		}
 	}
-
-	ctx.Header("Content-Type", "text/csv")
-	ctx.Header("Content-Disposition", "attachment; filename="+filename)
-	ctx.Data(http.StatusOK, "text/csv", data)
+	req.SortBy = ctx.Query("sort_by")
+	req.Order = ctx.Query("order")
+	req.Calendar = parseCalendar(ctx)
+	req.Language = Infrastructure.RequestLanguage(ctx)
+	if tenant, ok := Infrastructure.CurrentTenant(ctx); ok {
+		req.IncludePII = tenant.CanViewPII()
+	}
+	return req, true
 }
 
 // GetProfitSummary godoc
@@ -320,10 +429,13 @@ func (c *ReportController) ExportReport(ctx *gin.Context) {
 // @Tags         reports
 // @Produce      json
 // @Param        businessId  path    string  true   "Business ID"
-// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, custom"
+// @Param        period      query   string  false  "Period: daily, weekly, monthly, yearly, fiscal_yearly, custom"
 // @Param        start_date  query   string  false  "Start date (YYYY-MM-DD) for custom period"
 // @Param        end_date    query   string  false  "End date (YYYY-MM-DD) for custom period"
-// @Success      200  {object}  Domain.ProfitReport
+// @Param        compare     query   string  false  "Also return the prior period with deltas: previous_period, last_week, last_month, last_year"
+// @Param        align       query   string  false  "Prior period alignment: weekday (same weekdays, default) or date (same calendar dates)"
+// @Param        calendar    query   string  false  "Calendar for fiscal_yearly and dates: gregorian (default) or ethiopian"
+// @Success      200  {object}  Domain.ProfitReport  "Domain.ReportComparison when compare is set"
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/reports/profit/summary [get]
@@ -350,7 +462,25 @@ func (c *ReportController) GetProfitSummary(ctx *gin.Context) {
 		}
 	}
 
-	report, err := c.reportUC.GetProfitSummary(businessID, period, startDate, endDate)
+	if opts, ok := parseCompareOptions(ctx); ok {
+		req := Domain.ReportRequest{
+			BusinessID: businessID,
+			Type:       Domain.ReportTypeProfit,
+			Period:     period,
+			StartDate:  startDate,
+			EndDate:    endDate,
+			Calendar:   parseCalendar(ctx),
+		}
+		comparison, err := c.reportUC.CompareReport(req, opts)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, comparison)
+		return
+	}
+
+	report, err := c.reportUC.GetProfitSummary(businessID, period, startDate, endDate, parseCalendar(ctx))
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
 		return
@@ -367,6 +497,7 @@ func (c *ReportController) GetProfitSummary(ctx *gin.Context) {
 // @Param        businessId  path    string  true   "Business ID"
 // @Param        period      query   string  false  "Period: daily, weekly, monthly"
 // @Param        weeks       query   int     false  "Number of weeks to analyze (default 12)"
+// @Param        calendar    query   string  false  "Calendar for period labels: gregorian (default) or ethiopian"
 // @Success      200  {array}   Domain.ProfitTrend
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
@@ -387,7 +518,7 @@ func (c *ReportController) GetProfitTrends(ctx *gin.Context) {
 		}
 	}
 
-	trends, err := c.reportUC.GetProfitTrends(businessID, period, weeks)
+	trends, err := c.reportUC.GetProfitTrends(businessID, period, weeks, parseCalendar(ctx))
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
 		return
@@ -395,3 +526,77 @@ func (c *ReportController) GetProfitTrends(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, trends)
 }
+
+// GetDeadStockReport godoc
+// @Summary      Get dead stock and slow movers
+// @Description  List products with stock on hand that have not sold in N days, or sell too slowly to clear, with the capital tied up in each
+// @Tags         reports
+// @Produce      json
+// @Param        businessId       path   string  true   "Business ID"
+// @Param        days             query  int     false  "Products with no sales in this many days are dead stock (default 90)"
+// @Param        slow_cover_days  query  int     false  "Products whose stock lasts longer than this at the current sales rate are slow movers (default 180)"
+// @Param        include_slow     query  bool    false  "Include slow movers (default true)"
+// @Param        sort_by          query  string  false  "Sort: capital, days_since_sale, stock, name (default capital)"
+// @Param        order            query  string  false  "asc or desc (default desc, asc for name)"
+// @Success      200  {object}  Domain.DeadStockReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/dead-stock [get]
+// @Security     BearerAuth
+func (c *ReportController) GetDeadStockReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	opts := Domain.DeadStockOptions{
+		IncludeSlow: ctx.DefaultQuery("include_slow", "true") != "false",
+		SortBy:      ctx.Query("sort_by"),
+		Order:       ctx.Query("order"),
+	}
+
+	if daysStr := ctx.Query("days"); daysStr != "" {
+		days, err := strconv.Atoi(daysStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "days must be an integer")
+			return
+		}
+		opts.Days = days
+	}
+
+	if coverStr := ctx.Query("slow_cover_days"); coverStr != "" {
+		days, err := strconv.Atoi(coverStr)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "slow_cover_days must be an integer")
+			return
+		}
+		opts.SlowCoverDays = days
+	}
+
+	report, err := c.reportUC.GetDeadStockReport(businessID, opts)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// parseCompareOptions reads the optional compare and align query parameters;
+// ok is false when no comparison was asked for
+// parseCalendar reads the calendar query parameter; the use cases validate it
+func parseCalendar(ctx *gin.Context) Domain.Calendar {
+	return Domain.Calendar(ctx.Query("calendar"))
+}
+
+func parseCompareOptions(ctx *gin.Context) (opts Domain.CompareOptions, ok bool) {
+	mode := ctx.Query("compare")
+	if mode == "" {
+		return opts, false
+	}
+	return Domain.CompareOptions{
+		Mode:  Domain.CompareMode(mode),
+		Align: Domain.CompareAlign(ctx.Query("align")),
+	}, true
+}
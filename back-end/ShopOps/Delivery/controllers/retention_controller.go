@@ -0,0 +1,44 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type RetentionController struct {
+	retentionUC Usecases.RetentionUseCase
+}
+
+func NewRetentionController(retentionUC Usecases.RetentionUseCase) *RetentionController {
+	return &RetentionController{retentionUC: retentionUC}
+}
+
+// GetRetentionReports godoc
+// @Summary      List retention reports
+// @Description  What the daily retention run did to the shop, newest first: how many records lost their customer's name and phone number for being older than the shop's retention (the anonymize_after_years setting), by collection: live and archived sales, customers, loyalty accounts, gift cards, SMS logs, appointments and repair jobs; and how many products, customers and suppliers were purged from the trash. Amounts and items on anonymized sales are kept, so reports still add up. Runs that touched nothing are not listed. Owners only.
+// @Tags         retention
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        limit       query  int     false  "Limit results (default 20, max 100)"
+// @Success      200  {array}   Domain.RetentionReport
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      500  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/retention/reports [get]
+// @Security     BearerAuth
+func (c *RetentionController) GetRetentionReports(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	reports, err := c.retentionUC.GetReports(ctx.Param("businessId"), limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reports)
+}
@@ -3,6 +3,7 @@ package controllers
 import (
 	"net/http"
 	"strconv"
+	"strings"
 	"time"
 
 	Domain "ShopOps/Domain"
@@ -53,7 +54,7 @@ func (c *SalesController) CreateSale(ctx *gin.Context) {
 		return
 	}
 
-	sale, err := c.salesUC.CreateSale(businessID, userID.(string), req)
+	sale, err := c.salesUC.CreateSale(ctx.Request.Context(), businessID, userID.(string), req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
@@ -64,7 +65,7 @@ func (c *SalesController) CreateSale(ctx *gin.Context) {
 
 // GetSales godoc
 // @Summary      List all sales
-// @Description  Get sales transactions with filtering and pagination
+// @Description  Get sales transactions with filtering and pagination. When the page is full, X-Next-Cursor holds the cursor for the next one.
 // @Tags         sales
 // @Produce      json
 // @Param        businessId      path      string  true   "Business ID"
@@ -73,9 +74,14 @@ func (c *SalesController) CreateSale(ctx *gin.Context) {
 // @Param        status          query     string  false  "Sale status"
 // @Param        payment_method  query     string  false  "Payment method"
 // @Param        payment_status  query     string  false  "Payment status"
-// @Param        limit           query     int     false  "Limit results"
-// @Param        offset          query     int     false  "Offset results"
-// @Success      200  {array}   Domain.Sale
+// @Param        employee_id     query     string  false  "Employee credited with the sale"
+// @Param        sort        query   string  false  "-created_at (default), created_at or -final_amount"
+// @Param        cursor      query   string  false  "X-Next-Cursor from the previous page"
+// @Param        limit       query   int     false  "Limit results (default 50 when paging by cursor, max 500)"
+// @Param        offset      query   int     false  "Offset results (deprecated; page by cursor)"
+// @Param        include_total  query  bool  false  "Send the total across all pages in X-Total-Count"
+// @Param        expand      query   string  false  "Comma-separated related records to embed: product, customer, employee"
+// @Success      200  {array}   Domain.SaleListItem
 // @Failure      400  {object}  map[string]interface{}
 // @Failure      401  {object}  map[string]interface{}
 // @Router       /api/v1/businesses/{businessId}/sales [get]
@@ -120,12 +126,19 @@ func (c *SalesController) GetSales(ctx *gin.Context) {
 		filters.PaymentStatus = &ps
 	}
 
+	if employeeID := ctx.Query("employee_id"); employeeID != "" {
+		filters.EmployeeID = &employeeID
+	}
+
 	// Pagination
-	if limitStr := ctx.Query("limit"); limitStr != "" {
-		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
-			filters.Limit = limit
-		}
+	page, err := Infrastructure.ParsePage(ctx, Domain.SaleSorts, "-created_at")
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
 	}
+	filters.Limit = page.Limit
+	filters.Sort = page.Sort
+	filters.After = page.After
 
 	if offsetStr := ctx.Query("offset"); offsetStr != "" {
 		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
@@ -139,6 +152,35 @@ func (c *SalesController) GetSales(ctx *gin.Context) {
 		return
 	}
 
+	if page.IncludeTotal {
+		total, err := c.salesUC.CountSales(businessID, filters)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+			return
+		}
+		Infrastructure.SetTotalCount(ctx, total)
+	}
+
+	var last Domain.Sortable
+	if n := len(sales); n > 0 {
+		last = &sales[n-1]
+	}
+	Infrastructure.SetNextCursor(ctx, page, len(sales), last)
+
+	if expand := ctx.Query("expand"); expand != "" {
+		names := strings.Split(expand, ",")
+		for i := range names {
+			names[i] = strings.TrimSpace(names[i])
+		}
+		items, err := c.salesUC.ExpandSales(businessID, sales, names)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+			return
+		}
+		ctx.JSON(http.StatusOK, items)
+		return
+	}
+
 	ctx.JSON(http.StatusOK, sales)
 }
 
@@ -168,7 +210,7 @@ func (c *SalesController) GetSale(ctx *gin.Context) {
 		return
 	}
 
-	sale, err := c.salesUC.GetSaleByID(saleID, businessID)
+	sale, err := c.salesUC.GetSaleByID(ctx.Request.Context(), saleID, businessID)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
 		return
@@ -217,7 +259,7 @@ func (c *SalesController) UpdateSale(ctx *gin.Context) {
 		return
 	}
 
-	sale, err := c.salesUC.UpdateSale(saleID, businessID, userID.(string), req)
+	sale, err := c.salesUC.UpdateSale(ctx.Request.Context(), saleID, businessID, userID.(string), req)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
@@ -258,7 +300,7 @@ func (c *SalesController) VoidSale(ctx *gin.Context) {
 		return
 	}
 
-	if err := c.salesUC.VoidSale(saleID, businessID, userID.(string)); err != nil {
+	if err := c.salesUC.VoidSale(ctx.Request.Context(), saleID, businessID, userID.(string)); err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
 	}
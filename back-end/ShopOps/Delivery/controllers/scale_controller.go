@@ -0,0 +1,173 @@
+package controllers
+
+import (
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ScaleController struct {
+	scaleUC Usecases.ScaleUseCase
+}
+
+func NewScaleController(scaleUC Usecases.ScaleUseCase) *ScaleController {
+	return &ScaleController{scaleUC: scaleUC}
+}
+
+// GetScaleSettings godoc
+// @Summary      Get scale settings
+// @Description  How the shop's label-printing scales lay out the barcodes of weighed goods, and the PLU file format they load
+// @Tags         scales
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.ScaleSettings
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/scale/settings [get]
+// @Security     BearerAuth
+func (c *ScaleController) GetScaleSettings(ctx *gin.Context) {
+	settings, err := c.scaleUC.GetSettings(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	Infrastructure.SetLastModified(ctx, settings.UpdatedAt)
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateScaleSettings godoc
+// @Summary      Update scale settings
+// @Description  Turn scale barcodes on and set their layout to match the scales: prefixes, digits of the PLU, an optional check digit over the value, and whether the value is a price or a weight. The layout must add up to 13 digits. Owners only.
+// @Tags         scales
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID"
+// @Param        request     body  Domain.UpdateScaleSettingsRequest  true  "Settings to change"
+// @Success      200  {object}  Domain.ScaleSettings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/scale/settings [patch]
+// @Security     BearerAuth
+func (c *ScaleController) UpdateScaleSettings(ctx *gin.Context) {
+	var req Domain.UpdateScaleSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	settings, err := c.scaleUC.UpdateSettings(ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// ExportPLUs godoc
+// @Summary      Download the PLU file for the scales
+// @Description  The shop's active products with a PLU, in the format set in the scale settings, to load into the scales' software
+// @Tags         scales
+// @Produce      text/csv
+// @Produce      text/plain
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/scale/plu-export [get]
+// @Security     BearerAuth
+func (c *ScaleController) ExportPLUs(ctx *gin.Context) {
+	data, contentType, filename, err := c.scaleUC.ExportPLUs(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename="+filename)
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// ScanBarcode godoc
+// @Summary      Look up a scanned barcode
+// @Description  Find the product for a barcode read at the POS. A barcode printed by the shop's scales gives the product by its PLU with the weight and amount on the label; any other barcode is one unit of the product with that barcode.
+// @Tags         inventory
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        barcode     query  string  true  "Barcode as scanned"
+// @Success      200  {object}  Domain.ScanResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/scan [get]
+// @Security     BearerAuth
+func (c *ScaleController) ScanBarcode(ctx *gin.Context) {
+	result, err := c.scaleUC.Scan(ctx.Param("businessId"), ctx.Query("barcode"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetQuickSaleLine godoc
+// @Summary      Price a scanned barcode
+// @Description  Resolve a barcode into a priced sale line. Barcodes in one of the shop's schemes carry the weight or price: a weight is charged at the product's selling price, and a price is charged as printed, with the quantity worked out from it. Any other barcode is one unit of its product.
+// @Tags         sales
+// @Produce      json
+// @Param        businessId  path   string  true  "Business ID"
+// @Param        barcode     query  string  true  "Barcode as scanned"
+// @Success      200  {object}  Domain.QuickSaleLine
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sales/quick-line [get]
+// @Security     BearerAuth
+func (c *ScaleController) GetQuickSaleLine(ctx *gin.Context) {
+	line, err := c.scaleUC.QuickSaleLine(ctx.Param("businessId"), ctx.Query("barcode"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, line)
+}
+
+// CreateQuickSale godoc
+// @Summary      Sell a scanned barcode
+// @Description  Record a sale of the line the barcode resolves to, as GET /sales/quick-line prices it
+// @Tags         sales
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                   true  "Business ID"
+// @Param        request     body  Domain.QuickSaleRequest  true  "Barcode and payment"
+// @Success      201  {object}  Domain.Sale
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sales/quick [post]
+// @Security     BearerAuth
+func (c *ScaleController) CreateQuickSale(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.QuickSaleRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	sale, err := c.scaleUC.QuickSale(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, sale)
+}
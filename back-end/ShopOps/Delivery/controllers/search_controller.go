@@ -0,0 +1,74 @@
+package controllers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+const maxSearchLimit = 50
+
+type SearchController struct {
+	searchUC Usecases.SearchUseCase
+}
+
+func NewSearchController(searchUC Usecases.SearchUseCase) *SearchController {
+	return &SearchController{searchUC: searchUC}
+}
+
+// Search godoc
+// @Summary      Search products and customers
+// @Description  As-you-type search over active products (name, SKU, barcode) and customers (name, phone, email). Matches word prefixes, parts of words and misspellings, and Amharic and Latin spellings of the same name find each other.
+// @Tags         search
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        q           query  string  true   "Search text"
+// @Param        types       query  string  false  "Comma-separated kinds to search: product, customer (default both)"
+// @Param        limit       query  int     false  "Maximum number of results (default 20, max 50)"
+// @Success      200  {array}   Domain.SearchResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/search [get]
+// @Security     BearerAuth
+func (c *SearchController) Search(ctx *gin.Context) {
+	var kinds []Domain.SearchKind
+	if types := ctx.Query("types"); types != "" {
+		for _, kind := range strings.Split(types, ",") {
+			switch Domain.SearchKind(strings.TrimSpace(kind)) {
+			case Domain.SearchKindProduct, Domain.SearchKindCustomer:
+				kinds = append(kinds, Domain.SearchKind(strings.TrimSpace(kind)))
+			default:
+				Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, fmt.Sprintf("invalid type %q; use product or customer", kind))
+				return
+			}
+		}
+	}
+
+	limit := 20
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		l, err := strconv.Atoi(limitStr)
+		if err != nil || l <= 0 {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "limit must be a positive integer")
+			return
+		}
+		limit = l
+	}
+	if limit > maxSearchLimit {
+		limit = maxSearchLimit
+	}
+
+	results, err := c.searchUC.Search(ctx.Param("businessId"), ctx.Query("q"), kinds, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, results)
+}
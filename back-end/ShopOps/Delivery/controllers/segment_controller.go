@@ -0,0 +1,276 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SegmentController struct {
+	segmentUC Usecases.SegmentUseCase
+}
+
+func NewSegmentController(segmentUC Usecases.SegmentUseCase) *SegmentController {
+	return &SegmentController{segmentUC: segmentUC}
+}
+
+// CreateSegment godoc
+// @Summary      Create customer segment
+// @Description  Save a customer segment defined by filter rules on purchase count, spend, recency and tags. All rules must match.
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                       true  "Business ID"
+// @Param        request     body  Domain.CreateSegmentRequest  true  "Segment definition"
+// @Success      201  {object}  Domain.CustomerSegment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments [post]
+// @Security     BearerAuth
+func (c *SegmentController) CreateSegment(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateSegmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	segment, err := c.segmentUC.CreateSegment(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, segment)
+}
+
+// GetSegments godoc
+// @Summary      List customer segments
+// @Description  Get saved segments with their last computed member counts
+// @Tags         segments
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.CustomerSegment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments [get]
+// @Security     BearerAuth
+func (c *SegmentController) GetSegments(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	segments, err := c.segmentUC.GetSegments(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, segments)
+}
+
+// PreviewSegment godoc
+// @Summary      Preview segment rules
+// @Description  Evaluate rules without saving them and return the matching customers
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path   string                        true   "Business ID"
+// @Param        limit       query  int                           false  "Customers to return (default 20)"
+// @Param        request     body   Domain.PreviewSegmentRequest  true   "Segment rules"
+// @Success      200  {object}  Domain.SegmentMembersResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments/preview [post]
+// @Security     BearerAuth
+func (c *SegmentController) PreviewSegment(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var req Domain.PreviewSegmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	limit := 20
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	result, err := c.segmentUC.PreviewSegment(businessID, req, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
+
+// GetSegment godoc
+// @Summary      Get customer segment
+// @Description  Get a single segment definition
+// @Tags         segments
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        segmentId   path  string  true  "Segment ID"
+// @Success      200  {object}  Domain.CustomerSegment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments/{segmentId} [get]
+// @Security     BearerAuth
+func (c *SegmentController) GetSegment(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	segmentID := ctx.Param("segmentId")
+
+	if businessID == "" || segmentID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Segment ID are required")
+		return
+	}
+
+	segment, err := c.segmentUC.GetSegmentByID(ctx.Request.Context(), segmentID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, segment)
+}
+
+// UpdateSegment godoc
+// @Summary      Update customer segment
+// @Description  Rename a segment, change its rules or its dashboard visibility
+// @Tags         segments
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                       true  "Business ID"
+// @Param        segmentId   path  string                       true  "Segment ID"
+// @Param        request     body  Domain.UpdateSegmentRequest  true  "Fields to update"
+// @Success      200  {object}  Domain.CustomerSegment
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments/{segmentId} [patch]
+// @Security     BearerAuth
+func (c *SegmentController) UpdateSegment(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	segmentID := ctx.Param("segmentId")
+
+	if businessID == "" || segmentID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Segment ID are required")
+		return
+	}
+
+	var req Domain.UpdateSegmentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	segment, err := c.segmentUC.UpdateSegment(ctx.Request.Context(), segmentID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, segment)
+}
+
+// DeleteSegment godoc
+// @Summary      Delete customer segment
+// @Description  Delete a saved segment
+// @Tags         segments
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        segmentId   path  string  true  "Segment ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments/{segmentId} [delete]
+// @Security     BearerAuth
+func (c *SegmentController) DeleteSegment(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	segmentID := ctx.Param("segmentId")
+
+	if businessID == "" || segmentID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Segment ID are required")
+		return
+	}
+
+	if err := c.segmentUC.DeleteSegment(ctx.Request.Context(), segmentID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Segment deleted successfully"})
+}
+
+// GetSegmentCustomers godoc
+// @Summary      List segment members
+// @Description  Evaluate the segment now and return the matching customers
+// @Tags         segments
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        segmentId   path   string  true   "Segment ID"
+// @Param        limit       query  int     false  "Limit results"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {object}  Domain.SegmentMembersResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/segments/{segmentId}/customers [get]
+// @Security     BearerAuth
+func (c *SegmentController) GetSegmentCustomers(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	segmentID := ctx.Param("segmentId")
+
+	if businessID == "" || segmentID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Segment ID are required")
+		return
+	}
+
+	limit := 50
+	offset := 0
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if o, err := strconv.Atoi(offsetStr); err == nil && o >= 0 {
+			offset = o
+		}
+	}
+
+	result, err := c.segmentUC.GetSegmentMembers(ctx.Request.Context(), segmentID, businessID, limit, offset)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
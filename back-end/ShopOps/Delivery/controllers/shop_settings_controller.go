@@ -0,0 +1,138 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShopSettingsController struct {
+	settingsUC Usecases.ShopSettingsUseCase
+}
+
+func NewShopSettingsController(settingsUC Usecases.ShopSettingsUseCase) *ShopSettingsController {
+	return &ShopSettingsController{settingsUC: settingsUC}
+}
+
+// GetSettingDefinitions godoc
+// @Summary      List shop settings
+// @Description  Every shop setting with its type, group, default and allowed values, for the apps' settings screens
+// @Tags         settings
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.SettingDefinition
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/settings/definitions [get]
+// @Security     BearerAuth
+func (c *ShopSettingsController) GetSettingDefinitions(ctx *gin.Context) {
+	ctx.JSON(http.StatusOK, c.settingsUC.GetDefinitions())
+}
+
+// GetShopSettings godoc
+// @Summary      Get shop settings
+// @Description  How the shop's tills behave: discounts, receipts, locking and syncing. A shop that never saved them gets the defaults.
+// @Tags         settings
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.ShopSettings
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/settings [get]
+// @Security     BearerAuth
+func (c *ShopSettingsController) GetShopSettings(ctx *gin.Context) {
+	settings, err := c.settingsUC.GetSettings(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	Infrastructure.SetLastModified(ctx, settings.UpdatedAt)
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// UpdateShopSettings godoc
+// @Summary      Update shop settings
+// @Description  Change the settings given; each that changes is recorded in the history with who changed it. Owners only.
+// @Tags         settings
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        request     body  Domain.UpdateShopSettingsRequest  true  "Settings to change"
+// @Success      200  {object}  Domain.ShopSettings
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/settings [patch]
+// @Security     BearerAuth
+func (c *ShopSettingsController) UpdateShopSettings(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.UpdateShopSettingsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	settings, err := c.settingsUC.UpdateSettings(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, settings)
+}
+
+// GetSettingHistory godoc
+// @Summary      Shop settings history
+// @Description  Changes to the shop settings, newest first, each with the value before and after, who made it and when
+// @Tags         settings
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        key         query  string  false  "Only this setting"
+// @Param        limit       query  int     false  "Changes to return (default 50, at most 500)"
+// @Success      200  {array}   Domain.SettingChange
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/settings/history [get]
+// @Security     BearerAuth
+func (c *ShopSettingsController) GetSettingHistory(ctx *gin.Context) {
+	limit, _ := strconv.Atoi(ctx.Query("limit"))
+
+	changes, err := c.settingsUC.GetHistory(ctx.Param("businessId"), ctx.Query("key"), limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
+
+// GetSettingsBundle godoc
+// @Summary      Settings for a till
+// @Description  Everything a till needs in one download: the shop's currency, timezone and language, shop settings, catalog settings, scale layout, accepted currencies and default receipt template. Send back the ETag in If-None-Match to get a 304 until any of it changes.
+// @Tags         settings
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.SettingsBundle
+// @Success      304  "Not modified"
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/settings/bundle [get]
+// @Security     BearerAuth
+func (c *ShopSettingsController) GetSettingsBundle(ctx *gin.Context) {
+	bundle, err := c.settingsUC.GetBundle(ctx.Request.Context(), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, bundle)
+}
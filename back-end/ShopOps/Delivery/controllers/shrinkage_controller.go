@@ -0,0 +1,179 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type ShrinkageController struct {
+	shrinkageUC Usecases.ShrinkageUseCase
+}
+
+func NewShrinkageController(shrinkageUC Usecases.ShrinkageUseCase) *ShrinkageController {
+	return &ShrinkageController{shrinkageUC: shrinkageUC}
+}
+
+// RecordNoSale godoc
+// @Summary      Record a no-sale drawer open
+// @Description  Log the cash drawer being opened without a sale. The employee defaults to whoever is clocked in on the device, then to the employee linked to the user.
+// @Tags         drawer
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        request     body  Domain.RecordDrawerEventRequest  true  "Drawer open details"
+// @Success      201  {object}  Domain.DrawerEvent
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/drawer/events [post]
+// @Security     BearerAuth
+func (c *ShrinkageController) RecordNoSale(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RecordDrawerEventRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	event, err := c.shrinkageUC.RecordNoSale(ctx.Request.Context(), businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, event)
+}
+
+// GetDrawerEvents godoc
+// @Summary      List drawer events
+// @Description  Get no-sale drawer opens with filtering and pagination
+// @Tags         drawer
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        employee_id  query  string  false  "Employee ID"
+// @Param        device_id    query  string  false  "Device ID"
+// @Param        start_date   query  string  false  "Start date (YYYY-MM-DD)"
+// @Param        end_date     query  string  false  "End date (YYYY-MM-DD)"
+// @Param        limit        query  int     false  "Limit results"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.DrawerEvent
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/drawer/events [get]
+// @Security     BearerAuth
+func (c *ShrinkageController) GetDrawerEvents(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.DrawerEventFilters{}
+
+	if employeeID := ctx.Query("employee_id"); employeeID != "" {
+		filters.EmployeeID = &employeeID
+	}
+
+	if deviceID := ctx.Query("device_id"); deviceID != "" {
+		filters.DeviceID = &deviceID
+	}
+
+	// Date filters
+	if startDateStr := ctx.Query("start_date"); startDateStr != "" {
+		if startDate, err := time.Parse("2006-01-02", startDateStr); err == nil {
+			filters.StartDate = &startDate
+		}
+	}
+
+	if endDateStr := ctx.Query("end_date"); endDateStr != "" {
+		if endDate, err := time.Parse("2006-01-02", endDateStr); err == nil {
+			endOfDay := endDate.Add(24*time.Hour - time.Nanosecond)
+			filters.EndDate = &endOfDay
+		}
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	events, err := c.shrinkageUC.GetDrawerEvents(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, events)
+}
+
+// GetShrinkageReport godoc
+// @Summary      Get shrinkage report
+// @Description  Voids, no-sale drawer opens and stock losses (damage, theft, negative adjustments) per employee or device, with a weekly trend and lines flagged where a rate is threshold times the average of the others
+// @Tags         reports
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        start_date  query  string  false  "Start date (YYYY-MM-DD), defaults to 29 days before end_date"
+// @Param        end_date    query  string  false  "End date (YYYY-MM-DD), defaults to today"
+// @Param        group_by    query  string  false  "employee (default) or device"
+// @Param        threshold   query  number  false  "Ratio to the average that counts as an anomaly, defaults to 3"
+// @Success      200  {object}  Domain.ShrinkageReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/reports/shrinkage [get]
+// @Security     BearerAuth
+func (c *ShrinkageController) GetShrinkageReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var threshold float64
+	if thresholdStr := ctx.Query("threshold"); thresholdStr != "" {
+		parsed, err := strconv.ParseFloat(thresholdStr, 64)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "Invalid threshold")
+			return
+		}
+		threshold = parsed
+	}
+
+	report, err := c.shrinkageUC.GetShrinkageReport(
+		businessID,
+		ctx.Query("start_date"),
+		ctx.Query("end_date"),
+		Domain.ShrinkageGroupBy(ctx.Query("group_by")),
+		threshold,
+	)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
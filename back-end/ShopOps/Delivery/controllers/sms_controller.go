@@ -0,0 +1,320 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SMSController struct {
+	smsUC Usecases.SMSUseCase
+}
+
+func NewSMSController(smsUC Usecases.SMSUseCase) *SMSController {
+	return &SMSController{smsUC: smsUC}
+}
+
+// SendSaleReceipt godoc
+// @Summary      Text a sale receipt
+// @Description  Send the receipt of a sale to the customer's phone by SMS. Blacklisted numbers are skipped.
+// @Tags         sms
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        saleId      path  string  true  "Sale ID"
+// @Success      200  {object}  Domain.SMSMessage
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sales/{saleId}/sms-receipt [post]
+// @Security     BearerAuth
+func (c *SMSController) SendSaleReceipt(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	saleID := ctx.Param("saleId")
+
+	if businessID == "" || saleID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Sale ID are required")
+		return
+	}
+
+	message, err := c.smsUC.SendSaleReceipt(ctx.Request.Context(), saleID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, message)
+}
+
+// GetSMSMessages godoc
+// @Summary      SMS delivery log
+// @Description  List sent, failed and skipped SMS messages
+// @Tags         sms
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        type         query  string  false  "Type: receipt, promotional"
+// @Param        status       query  string  false  "Status: sent, failed, skipped"
+// @Param        phone        query  string  false  "Filter by phone (E.164)"
+// @Param        campaign_id  query  string  false  "Filter by campaign"
+// @Param        limit        query  int     false  "Limit results"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.SMSMessage
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/messages [get]
+// @Security     BearerAuth
+func (c *SMSController) GetSMSMessages(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.SMSMessageFilters{Limit: 50}
+
+	if smsType := ctx.Query("type"); smsType != "" {
+		t := Domain.SMSType(smsType)
+		filters.Type = &t
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.SMSStatus(status)
+		filters.Status = &s
+	}
+
+	if phone := ctx.Query("phone"); phone != "" {
+		filters.Phone = &phone
+	}
+
+	if campaignID := ctx.Query("campaign_id"); campaignID != "" {
+		filters.CampaignID = &campaignID
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	messages, err := c.smsUC.GetMessages(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, messages)
+}
+
+// SendBulkSMS godoc
+// @Summary      Start a promotional SMS campaign
+// @Description  Text opted-in customers, optionally limited to a tag, a customer segment or a phone list. Sending is throttled and runs in the background; poll the campaign for progress.
+// @Tags         sms
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                 true  "Business ID"
+// @Param        request     body  Domain.BulkSMSRequest  true  "Campaign message and audience"
+// @Success      202  {object}  Domain.SMSCampaign
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      429  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/campaigns [post]
+// @Security     BearerAuth
+func (c *SMSController) SendBulkSMS(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.BulkSMSRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	campaign, err := c.smsUC.SendBulk(ctx.Request.Context(), businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, campaign)
+}
+
+// GetSMSCampaigns godoc
+// @Summary      List SMS campaigns
+// @Description  Get promotional SMS campaigns, newest first
+// @Tags         sms
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        limit       query  int     false  "Limit results"
+// @Success      200  {array}   Domain.SMSCampaign
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/campaigns [get]
+// @Security     BearerAuth
+func (c *SMSController) GetSMSCampaigns(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	campaigns, err := c.smsUC.GetCampaigns(businessID, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, campaigns)
+}
+
+// GetSMSCampaign godoc
+// @Summary      Get SMS campaign
+// @Description  Get a campaign with its send progress
+// @Tags         sms
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        campaignId  path  string  true  "Campaign ID"
+// @Success      200  {object}  Domain.SMSCampaign
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/campaigns/{campaignId} [get]
+// @Security     BearerAuth
+func (c *SMSController) GetSMSCampaign(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	campaignID := ctx.Param("campaignId")
+
+	if businessID == "" || campaignID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Campaign ID are required")
+		return
+	}
+
+	campaign, err := c.smsUC.GetCampaignByID(campaignID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, campaign)
+}
+
+// GetSMSBlacklist godoc
+// @Summary      List blacklisted numbers
+// @Description  Numbers that never receive SMS from the business
+// @Tags         sms
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.SMSBlacklistEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/blacklist [get]
+// @Security     BearerAuth
+func (c *SMSController) GetSMSBlacklist(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	entries, err := c.smsUC.GetBlacklist(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// AddToSMSBlacklist godoc
+// @Summary      Blacklist a number
+// @Description  Stop all SMS to a number and opt the matching customer out of promotions
+// @Tags         sms
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                         true  "Business ID"
+// @Param        request     body  Domain.AddSMSBlacklistRequest  true  "Number to blacklist"
+// @Success      201  {object}  Domain.SMSBlacklistEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/blacklist [post]
+// @Security     BearerAuth
+func (c *SMSController) AddToSMSBlacklist(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.AddSMSBlacklistRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	entry, err := c.smsUC.AddToBlacklist(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// RemoveFromSMSBlacklist godoc
+// @Summary      Remove a number from the blacklist
+// @Description  Allow SMS to a number again. Promotional consent is not restored automatically.
+// @Tags         sms
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        phone       path  string  true  "Phone number"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/sms/blacklist/{phone} [delete]
+// @Security     BearerAuth
+func (c *SMSController) RemoveFromSMSBlacklist(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	phone := ctx.Param("phone")
+
+	if businessID == "" || phone == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and phone are required")
+		return
+	}
+
+	if err := c.smsUC.RemoveFromBlacklist(businessID, phone); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Number removed from blacklist"})
+}
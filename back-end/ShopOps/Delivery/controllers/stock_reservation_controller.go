@@ -0,0 +1,218 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockReservationController struct {
+	reservationUC Usecases.StockReservationUseCase
+}
+
+func NewStockReservationController(reservationUC Usecases.StockReservationUseCase) *StockReservationController {
+	return &StockReservationController{reservationUC: reservationUC}
+}
+
+// CreateReservation godoc
+// @Summary      Reserve stock for an order
+// @Description  Hold stock for an accepted online or phone order so the POS cannot sell it. The hold lapses after ttl_minutes (24 hours by default); ring the order up with its reservation_id to sell the held stock.
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                                true  "Business ID"
+// @Param        request     body  Domain.CreateStockReservationRequest  true  "Product, quantity and order"
+// @Success      201  {object}  Domain.StockReservation
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}  "Not enough stock available"
+// @Router       /api/v1/businesses/{businessId}/inventory/reservations [post]
+// @Security     BearerAuth
+func (c *StockReservationController) CreateReservation(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateStockReservationRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	reservation, err := c.reservationUC.CreateReservation(ctx.Request.Context(), businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, reservation)
+}
+
+// GetReservations godoc
+// @Summary      List stock reservations
+// @Description  The shop's reservations, newest first
+// @Tags         reservations
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        product_id  query  string  false  "Only this product's"
+// @Param        status      query  string  false  "Status: active, fulfilled, released, expired"
+// @Param        limit       query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.StockReservation
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/reservations [get]
+// @Security     BearerAuth
+func (c *StockReservationController) GetReservations(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var filters Domain.StockReservationFilters
+	if productID := ctx.Query("product_id"); productID != "" {
+		filters.ProductID = &productID
+	}
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.ReservationStatus(status)
+		filters.Status = &s
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	reservations, err := c.reservationUC.GetReservations(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservations)
+}
+
+// GetReservation godoc
+// @Summary      Get a stock reservation
+// @Tags         reservations
+// @Produce      json
+// @Param        businessId     path  string  true  "Business ID"
+// @Param        reservationId  path  string  true  "Reservation ID"
+// @Success      200  {object}  Domain.StockReservation
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/reservations/{reservationId} [get]
+// @Security     BearerAuth
+func (c *StockReservationController) GetReservation(ctx *gin.Context) {
+	reservation, err := c.reservationUC.GetReservation(ctx.Request.Context(), ctx.Param("reservationId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// ReleaseReservation godoc
+// @Summary      Release a stock reservation
+// @Description  The order was cancelled: give its held stock back to the shop
+// @Tags         reservations
+// @Accept       json
+// @Produce      json
+// @Param        businessId     path  string                                 true   "Business ID"
+// @Param        reservationId  path  string                                 true   "Reservation ID"
+// @Param        request        body  Domain.ReleaseStockReservationRequest  false  "Why"
+// @Success      200  {object}  Domain.StockReservation
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/reservations/{reservationId}/release [post]
+// @Security     BearerAuth
+func (c *StockReservationController) ReleaseReservation(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ReleaseStockReservationRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	reservation, err := c.reservationUC.ReleaseReservation(ctx.Request.Context(), ctx.Param("reservationId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, reservation)
+}
+
+// GetProductAvailability godoc
+// @Summary      Product availability
+// @Description  The product's stock, what is held for orders and what is left to sell
+// @Tags         reservations
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      200  {object}  Domain.ProductAvailability
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/availability [get]
+// @Security     BearerAuth
+func (c *StockReservationController) GetProductAvailability(ctx *gin.Context) {
+	availability, err := c.reservationUC.GetAvailability(ctx.Request.Context(), ctx.Param("productId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, availability)
+}
+
+// GetBranchAvailability godoc
+// @Summary      Availability across branches
+// @Description  What each of the signed-in user's shops has available of the product with the SKU, for choosing which branch takes an order
+// @Tags         reservations
+// @Produce      json
+// @Param        sku  query  string  true  "Product SKU"
+// @Success      200  {array}   Domain.BranchAvailability
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/branches/availability [get]
+// @Security     BearerAuth
+func (c *StockReservationController) GetBranchAvailability(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	branches, err := c.reservationUC.GetBranchAvailability(userID.(string), ctx.Query("sku"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, branches)
+}
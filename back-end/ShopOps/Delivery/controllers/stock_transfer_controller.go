@@ -0,0 +1,306 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StockTransferController struct {
+	transferUC Usecases.StockTransferUseCase
+}
+
+func NewStockTransferController(transferUC Usecases.StockTransferUseCase) *StockTransferController {
+	return &StockTransferController{transferUC: transferUC}
+}
+
+// RequestTransfer godoc
+// @Summary      Request stock from another branch
+// @Description  Asks another of the owner's branches for stock of this branch's products. The other branch's products are matched by SKU when it approves.
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID of the requesting branch"
+// @Param        request     body  Domain.CreateStockTransferRequest  true  "Supplying branch and products"
+// @Success      201  {object}  Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers [post]
+// @Security     BearerAuth
+func (c *StockTransferController) RequestTransfer(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateStockTransferRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	transfer, err := c.transferUC.RequestTransfer(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, transfer)
+}
+
+// GetTransfers godoc
+// @Summary      List stock transfers
+// @Description  Transfers the branch asked for or was asked for, newest first
+// @Tags         transfers
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        direction   query  string  false  "incoming (asked for by this branch) or outgoing (asked of it)"
+// @Param        status      query  string  false  "Status: requested, approved, in_transit, received, rejected, cancelled"
+// @Param        limit       query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset      query  int     false  "Offset results"
+// @Success      200  {array}   Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers [get]
+// @Security     BearerAuth
+func (c *StockTransferController) GetTransfers(ctx *gin.Context) {
+	var filters Domain.StockTransferFilters
+	if direction := ctx.Query("direction"); direction != "" {
+		d := Domain.StockTransferDirection(direction)
+		filters.Direction = &d
+	}
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.StockTransferStatus(status)
+		filters.Status = &s
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	transfers, err := c.transferUC.GetTransfers(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfers)
+}
+
+// GetTransfer godoc
+// @Summary      Get a stock transfer
+// @Tags         transfers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        transferId  path  string  true  "Transfer ID"
+// @Success      200  {object}  Domain.StockTransfer
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers/{transferId} [get]
+// @Security     BearerAuth
+func (c *StockTransferController) GetTransfer(ctx *gin.Context) {
+	transfer, err := c.transferUC.GetTransfer(ctx.Request.Context(), ctx.Param("transferId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// ApproveTransfer godoc
+// @Summary      Approve a stock transfer
+// @Description  The supplying branch approves what it can spare of each line, all of it unless given; lines are matched to its products by SKU
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                              true  "Business ID of the supplying branch"
+// @Param        transferId  path  string                              true  "Transfer ID"
+// @Param        request     body  Domain.ApproveStockTransferRequest  false  "Quantities approved"
+// @Success      200  {object}  Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers/{transferId}/approve [post]
+// @Security     BearerAuth
+func (c *StockTransferController) ApproveTransfer(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ApproveStockTransferRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	transfer, err := c.transferUC.ApproveTransfer(ctx.Request.Context(), ctx.Param("transferId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// RejectTransfer godoc
+// @Summary      Reject a stock transfer
+// @Description  The supplying branch turns down a transfer it has not shipped
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true   "Business ID of the supplying branch"
+// @Param        transferId  path  string                            true   "Transfer ID"
+// @Param        request     body  Domain.CloseStockTransferRequest  false  "Reason"
+// @Success      200  {object}  Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers/{transferId}/reject [post]
+// @Security     BearerAuth
+func (c *StockTransferController) RejectTransfer(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CloseStockTransferRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	transfer, err := c.transferUC.RejectTransfer(ctx.Request.Context(), ctx.Param("transferId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// ShipTransfer godoc
+// @Summary      Ship a stock transfer
+// @Description  The supplying branch takes the approved stock out of its stock; it is in transit until the requesting branch receives it
+// @Tags         transfers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID of the supplying branch"
+// @Param        transferId  path  string  true  "Transfer ID"
+// @Success      200  {object}  Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers/{transferId}/ship [post]
+// @Security     BearerAuth
+func (c *StockTransferController) ShipTransfer(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	transfer, err := c.transferUC.ShipTransfer(ctx.Request.Context(), ctx.Param("transferId"), ctx.Param("businessId"), userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// ReceiveTransfer godoc
+// @Summary      Receive a stock transfer
+// @Description  The requesting branch confirms what arrived of each line, all of it unless given, with a note on any difference. What arrived is added to its stock at the supplying branch's cost.
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                              true   "Business ID of the requesting branch"
+// @Param        transferId  path  string                              true   "Transfer ID"
+// @Param        request     body  Domain.ReceiveStockTransferRequest  false  "Quantities received"
+// @Success      200  {object}  Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers/{transferId}/receive [post]
+// @Security     BearerAuth
+func (c *StockTransferController) ReceiveTransfer(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ReceiveStockTransferRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	transfer, err := c.transferUC.ReceiveTransfer(ctx.Request.Context(), ctx.Param("transferId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
+
+// CancelTransfer godoc
+// @Summary      Cancel a stock transfer
+// @Description  The requesting branch withdraws a request not yet shipped
+// @Tags         transfers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true   "Business ID of the requesting branch"
+// @Param        transferId  path  string                            true   "Transfer ID"
+// @Param        request     body  Domain.CloseStockTransferRequest  false  "Reason"
+// @Success      200  {object}  Domain.StockTransfer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/transfers/{transferId}/cancel [post]
+// @Security     BearerAuth
+func (c *StockTransferController) CancelTransfer(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CloseStockTransferRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	transfer, err := c.transferUC.CancelTransfer(ctx.Request.Context(), ctx.Param("transferId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, transfer)
+}
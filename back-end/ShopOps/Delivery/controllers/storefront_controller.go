@@ -0,0 +1,342 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type StorefrontController struct {
+	storefrontUC Usecases.StorefrontUseCase
+}
+
+func NewStorefrontController(storefrontUC Usecases.StorefrontUseCase) *StorefrontController {
+	return &StorefrontController{storefrontUC: storefrontUC}
+}
+
+// ConnectStorefront godoc
+// @Summary      Connect an online store
+// @Description  Link a WooCommerce store (consumer key and secret) or a Shopify store (admin API access token). The credentials are checked before saving. Paid online orders are pulled in as sales and the store's stock kept in line with the shop's every 10 minutes; with push_catalog, products with a SKU the store lacks are listed and names and prices kept current. Owners only.
+// @Tags         storefronts
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                           true  "Business ID"
+// @Param        request     body  Domain.ConnectStorefrontRequest  true  "Store and credentials"
+// @Success      201  {object}  Domain.StorefrontConnection
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts [post]
+// @Security     BearerAuth
+func (c *StorefrontController) ConnectStorefront(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ConnectStorefrontRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	connection, err := c.storefrontUC.Connect(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, connection)
+}
+
+// GetStorefronts godoc
+// @Summary      List connected online stores
+// @Description  Get the business's store connections with how their last sync went. Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.StorefrontConnection
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts [get]
+// @Security     BearerAuth
+func (c *StorefrontController) GetStorefronts(ctx *gin.Context) {
+	connections, err := c.storefrontUC.GetConnections(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, connections)
+}
+
+// GetStorefront godoc
+// @Summary      Get an online store connection
+// @Description  Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId    path  string  true  "Business ID"
+// @Param        connectionId  path  string  true  "Connection ID"
+// @Success      200  {object}  Domain.StorefrontConnection
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId} [get]
+// @Security     BearerAuth
+func (c *StorefrontController) GetStorefront(ctx *gin.Context) {
+	connection, err := c.storefrontUC.GetConnection(ctx.Param("connectionId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, connection)
+}
+
+// UpdateStorefront godoc
+// @Summary      Update an online store connection
+// @Description  Change what is synced, the stock buffer or the credentials, or pause the connection with active false. New credentials are checked before saving. Owners only.
+// @Tags         storefronts
+// @Accept       json
+// @Produce      json
+// @Param        businessId    path  string                          true  "Business ID"
+// @Param        connectionId  path  string                          true  "Connection ID"
+// @Param        request       body  Domain.UpdateStorefrontRequest  true  "Fields to change"
+// @Success      200  {object}  Domain.StorefrontConnection
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId} [patch]
+// @Security     BearerAuth
+func (c *StorefrontController) UpdateStorefront(ctx *gin.Context) {
+	var req Domain.UpdateStorefrontRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	connection, err := c.storefrontUC.UpdateConnection(ctx.Param("connectionId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, connection)
+}
+
+// DeleteStorefront godoc
+// @Summary      Disconnect an online store
+// @Description  Stop syncing the store and forget its product mappings. Orders already pulled stay. Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId    path  string  true  "Business ID"
+// @Param        connectionId  path  string  true  "Connection ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId} [delete]
+// @Security     BearerAuth
+func (c *StorefrontController) DeleteStorefront(ctx *gin.Context) {
+	if err := c.storefrontUC.DeleteConnection(ctx.Param("connectionId"), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Store disconnected successfully"})
+}
+
+// SyncStorefront godoc
+// @Summary      Sync an online store now
+// @Description  Have the store synced within a minute instead of at its next turn. Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId    path  string  true  "Business ID"
+// @Param        connectionId  path  string  true  "Connection ID"
+// @Success      202  {object}  Domain.StorefrontConnection
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId}/sync [post]
+// @Security     BearerAuth
+func (c *StorefrontController) SyncStorefront(ctx *gin.Context) {
+	connection, err := c.storefrontUC.SyncNow(ctx.Param("connectionId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, connection)
+}
+
+// GetStorefrontMappings godoc
+// @Summary      List product mappings
+// @Description  Which store listing each product is tied to, and the stock and price the store was last sent. Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId    path  string  true  "Business ID"
+// @Param        connectionId  path  string  true  "Connection ID"
+// @Success      200  {array}   Domain.StorefrontMapping
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId}/mappings [get]
+// @Security     BearerAuth
+func (c *StorefrontController) GetStorefrontMappings(ctx *gin.Context) {
+	mappings, err := c.storefrontUC.GetMappings(ctx.Param("connectionId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mappings)
+}
+
+// SetStorefrontMapping godoc
+// @Summary      Map a product to a store listing
+// @Description  Tie a product to a listing the store already has, for listings whose SKU does not match. Replaces the product's mapping; the stock is sent on the next sync. Owners only.
+// @Tags         storefronts
+// @Accept       json
+// @Produce      json
+// @Param        businessId    path  string                              true  "Business ID"
+// @Param        connectionId  path  string                              true  "Connection ID"
+// @Param        request       body  Domain.SetStorefrontMappingRequest  true  "Product and listing"
+// @Success      200  {object}  Domain.StorefrontMapping
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId}/mappings [put]
+// @Security     BearerAuth
+func (c *StorefrontController) SetStorefrontMapping(ctx *gin.Context) {
+	var req Domain.SetStorefrontMappingRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	mapping, err := c.storefrontUC.SetMapping(ctx.Request.Context(), ctx.Param("connectionId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, mapping)
+}
+
+// DeleteStorefrontMapping godoc
+// @Summary      Unmap a product
+// @Description  Stop syncing the product with its listing. The listing stays on the store. Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId    path  string  true  "Business ID"
+// @Param        connectionId  path  string  true  "Connection ID"
+// @Param        productId     path  string  true  "Product ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId}/mappings/{productId} [delete]
+// @Security     BearerAuth
+func (c *StorefrontController) DeleteStorefrontMapping(ctx *gin.Context) {
+	if err := c.storefrontUC.DeleteMapping(ctx.Param("connectionId"), ctx.Param("businessId"), ctx.Param("productId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Mapping removed successfully"})
+}
+
+// GetStorefrontOrders godoc
+// @Summary      List pulled online orders
+// @Description  Orders pulled from the store, newest first. Orders in conflict have lines that could not be recorded as sales, each with the reason. Owners only.
+// @Tags         storefronts
+// @Produce      json
+// @Param        businessId    path   string  true   "Business ID"
+// @Param        connectionId  path   string  true   "Connection ID"
+// @Param        status        query  string  false  "Status: importing, imported, conflict, dismissed"
+// @Param        limit         query  int     false  "Limit results"
+// @Param        offset        query  int     false  "Offset results"
+// @Success      200  {array}   Domain.StorefrontOrder
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId}/orders [get]
+// @Security     BearerAuth
+func (c *StorefrontController) GetStorefrontOrders(ctx *gin.Context) {
+	filters := Domain.StorefrontOrderFilters{Limit: 50}
+
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.StorefrontOrderStatus(status)
+		filters.Status = &s
+	}
+
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	orders, err := c.storefrontUC.GetOrders(ctx.Param("connectionId"), ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orders)
+}
+
+// ResolveStorefrontOrder godoc
+// @Summary      Resolve an online order in conflict
+// @Description  retry records the waiting lines as sales again, after restocking or mapping their products; dismiss leaves them unrecorded, for orders refunded or cancelled on the store. Owners only.
+// @Tags         storefronts
+// @Accept       json
+// @Produce      json
+// @Param        businessId    path  string                                true  "Business ID"
+// @Param        connectionId  path  string                                true  "Connection ID"
+// @Param        orderId       path  string                                true  "Order ID"
+// @Param        request       body  Domain.ResolveStorefrontOrderRequest  true  "retry or dismiss"
+// @Success      200  {object}  Domain.StorefrontOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/storefronts/{connectionId}/orders/{orderId}/resolve [post]
+// @Security     BearerAuth
+func (c *StorefrontController) ResolveStorefrontOrder(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.ResolveStorefrontOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	order, err := c.storefrontUC.ResolveOrder(ctx.Param("connectionId"), ctx.Param("orderId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
@@ -0,0 +1,646 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SupplierController struct {
+	supplierUC Usecases.SupplierUseCase
+}
+
+func NewSupplierController(supplierUC Usecases.SupplierUseCase) *SupplierController {
+	return &SupplierController{supplierUC: supplierUC}
+}
+
+// CreateSupplier godoc
+// @Summary      Create a supplier
+// @Description  Create a supplier with contact details and payment terms
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        request     body  Domain.CreateSupplierRequest  true  "Supplier details"
+// @Success      201  {object}  Domain.Supplier
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers [post]
+// @Security     BearerAuth
+func (c *SupplierController) CreateSupplier(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateSupplierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	supplier, err := c.supplierUC.CreateSupplier(businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, supplier)
+}
+
+// GetSuppliers godoc
+// @Summary      List suppliers
+// @Description  Get suppliers with their outstanding balances
+// @Tags         suppliers
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        status      query  string  false  "Status: active, inactive"
+// @Param        search      query  string  false  "Search by name, contact or phone"
+// @Success      200  {array}   Domain.Supplier
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetSuppliers(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	var status *Domain.SupplierStatus
+	if s := ctx.Query("status"); s != "" {
+		supplierStatus := Domain.SupplierStatus(s)
+		status = &supplierStatus
+	}
+
+	var search *string
+	if s := ctx.Query("search"); s != "" {
+		search = &s
+	}
+
+	suppliers, err := c.supplierUC.GetSuppliers(businessID, status, search)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, suppliers)
+}
+
+// GetSupplier godoc
+// @Summary      Get supplier details
+// @Description  Get a supplier and its current balance
+// @Tags         suppliers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        supplierId  path  string  true  "Supplier ID"
+// @Success      200  {object}  Domain.Supplier
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId} [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetSupplier(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	supplierID := ctx.Param("supplierId")
+
+	if businessID == "" || supplierID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Supplier ID are required")
+		return
+	}
+
+	supplier, err := c.supplierUC.GetSupplierByID(ctx.Request.Context(), supplierID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, supplier)
+}
+
+// UpdateSupplier godoc
+// @Summary      Update supplier
+// @Description  Update supplier details, payment terms or status. Send the version last read to get a 409 instead of overwriting someone else's changes.
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                        true  "Business ID"
+// @Param        supplierId  path  string                        true  "Supplier ID"
+// @Param        request     body  Domain.UpdateSupplierRequest  true  "Supplier updates"
+// @Success      200  {object}  Domain.Supplier
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId} [patch]
+// @Security     BearerAuth
+func (c *SupplierController) UpdateSupplier(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	supplierID := ctx.Param("supplierId")
+
+	if businessID == "" || supplierID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Supplier ID are required")
+		return
+	}
+
+	var req Domain.UpdateSupplierRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	supplier, err := c.supplierUC.UpdateSupplier(ctx.Request.Context(), supplierID, businessID, req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, supplier)
+}
+
+// DeleteSupplier godoc
+// @Summary      Delete supplier
+// @Description  Move a supplier with no outstanding balance to the trash, where it can be restored for 30 days
+// @Tags         suppliers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        supplierId  path  string  true  "Supplier ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId} [delete]
+// @Security     BearerAuth
+func (c *SupplierController) DeleteSupplier(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	supplierID := ctx.Param("supplierId")
+
+	if businessID == "" || supplierID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Supplier ID are required")
+		return
+	}
+
+	if err := c.supplierUC.DeleteSupplier(ctx.Request.Context(), supplierID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Supplier deleted successfully"})
+}
+
+// RecordInvoice godoc
+// @Summary      Record supplier invoice
+// @Description  Record an invoice received from a supplier, increasing the amount owed
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        supplierId  path  string                               true  "Supplier ID"
+// @Param        request     body  Domain.RecordSupplierInvoiceRequest  true  "Invoice details"
+// @Success      201  {object}  Domain.PayableEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId}/invoices [post]
+// @Security     BearerAuth
+func (c *SupplierController) RecordInvoice(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	supplierID := ctx.Param("supplierId")
+
+	if businessID == "" || supplierID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Supplier ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RecordSupplierInvoiceRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	entry, err := c.supplierUC.RecordInvoice(ctx.Request.Context(), supplierID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// RecordPayment godoc
+// @Summary      Record supplier payment
+// @Description  Record a payment made to a supplier, reducing the amount owed
+// @Tags         suppliers
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                               true  "Business ID"
+// @Param        supplierId  path  string                               true  "Supplier ID"
+// @Param        request     body  Domain.RecordSupplierPaymentRequest  true  "Payment details"
+// @Success      201  {object}  Domain.PayableEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId}/payments [post]
+// @Security     BearerAuth
+func (c *SupplierController) RecordPayment(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	supplierID := ctx.Param("supplierId")
+
+	if businessID == "" || supplierID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Supplier ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.RecordSupplierPaymentRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	entry, err := c.supplierUC.RecordPayment(ctx.Request.Context(), supplierID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, entry)
+}
+
+// GetLedger godoc
+// @Summary      Get supplier payables ledger
+// @Description  Get invoices, payments and credits for a supplier with running balance
+// @Tags         suppliers
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        supplierId  path   string  true   "Supplier ID"
+// @Param        limit       query  int     false  "Limit results"
+// @Success      200  {array}   Domain.PayableEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId}/ledger [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetLedger(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	supplierID := ctx.Param("supplierId")
+
+	if businessID == "" || supplierID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Supplier ID are required")
+		return
+	}
+
+	limit := 100
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	entries, err := c.supplierUC.GetLedger(ctx.Request.Context(), supplierID, businessID, limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, entries)
+}
+
+// GetAgingReport godoc
+// @Summary      Get payables aging report
+// @Description  Get outstanding supplier balances bucketed by days past due (current, 1-30, 31-60, 61-90, 90+)
+// @Tags         suppliers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.PayablesAgingReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/aging [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetAgingReport(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	report, err := c.supplierUC.GetAgingReport(businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// GetProductSupplierPrices godoc
+// @Summary      Compare supplier prices for a product
+// @Description  What each supplier last charged for the product and how long they take to deliver, cheapest first. Prices are recorded as purchase orders are received.
+// @Tags         suppliers
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      200  {object}  Domain.ProductSupplierPrices
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/supplier-prices [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetProductSupplierPrices(ctx *gin.Context) {
+	prices, err := c.supplierUC.GetProductSupplierPrices(ctx.Request.Context(), ctx.Param("productId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, prices)
+}
+
+// GetPurchaseSuggestions godoc
+// @Summary      Get purchase suggestions
+// @Description  Recommend a supplier for each product from their last prices, preferring a faster one when the cheapest cannot deliver before stock falls to the minimum, with the quantity to order and the last day to order it. Without product_ids, lists the products to order within the next 14 days.
+// @Tags         purchase-orders
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        product_ids  query  string  false  "Comma separated product IDs to suggest for"
+// @Param        supplier_id  query  string  false  "Only suggest buying from this supplier"
+// @Success      200  {object}  Domain.PurchaseSuggestionReport
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders/suggestions [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetPurchaseSuggestions(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	opts := Domain.PurchaseSuggestionOptions{SupplierID: ctx.Query("supplier_id")}
+	for _, id := range strings.Split(ctx.Query("product_ids"), ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			opts.ProductIDs = append(opts.ProductIDs, id)
+		}
+	}
+
+	report, err := c.supplierUC.GetPurchaseSuggestions(ctx.Request.Context(), businessID, opts)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, report)
+}
+
+// CreatePurchaseOrder godoc
+// @Summary      Create purchase order
+// @Description  Create a purchase order for a supplier, as draft or submitted
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                             true  "Business ID"
+// @Param        request     body  Domain.CreatePurchaseOrderRequest  true  "Purchase order details"
+// @Success      201  {object}  Domain.PurchaseOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders [post]
+// @Security     BearerAuth
+func (c *SupplierController) CreatePurchaseOrder(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreatePurchaseOrderRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	order, err := c.supplierUC.CreatePurchaseOrder(ctx.Request.Context(), businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, order)
+}
+
+// GetPurchaseOrders godoc
+// @Summary      List purchase orders
+// @Description  Get purchase orders with filtering and pagination
+// @Tags         purchase-orders
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        supplier_id  query  string  false  "Supplier ID"
+// @Param        status       query  string  false  "Status: draft, ordered, received, cancelled"
+// @Param        limit        query  int     false  "Limit results"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.PurchaseOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetPurchaseOrders(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	if businessID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID is required")
+		return
+	}
+
+	filters := Domain.PurchaseOrderFilters{}
+
+	if supplierID := ctx.Query("supplier_id"); supplierID != "" {
+		filters.SupplierID = &supplierID
+	}
+
+	if status := ctx.Query("status"); status != "" {
+		orderStatus := Domain.PurchaseOrderStatus(status)
+		filters.Status = &orderStatus
+	}
+
+	// Pagination
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if limit, err := strconv.Atoi(limitStr); err == nil && limit > 0 {
+			filters.Limit = limit
+		}
+	}
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	orders, err := c.supplierUC.GetPurchaseOrders(businessID, filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, orders)
+}
+
+// GetPurchaseOrder godoc
+// @Summary      Get purchase order
+// @Description  Get a purchase order with its items
+// @Tags         purchase-orders
+// @Produce      json
+// @Param        businessId       path  string  true  "Business ID"
+// @Param        purchaseOrderId  path  string  true  "Purchase order ID"
+// @Success      200  {object}  Domain.PurchaseOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders/{purchaseOrderId} [get]
+// @Security     BearerAuth
+func (c *SupplierController) GetPurchaseOrder(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	orderID := ctx.Param("purchaseOrderId")
+
+	if businessID == "" || orderID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Purchase order ID are required")
+		return
+	}
+
+	order, err := c.supplierUC.GetPurchaseOrderByID(ctx.Request.Context(), orderID, businessID)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// SubmitPurchaseOrder godoc
+// @Summary      Submit purchase order
+// @Description  Mark a draft purchase order as ordered
+// @Tags         purchase-orders
+// @Produce      json
+// @Param        businessId       path  string  true  "Business ID"
+// @Param        purchaseOrderId  path  string  true  "Purchase order ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders/{purchaseOrderId}/submit [post]
+// @Security     BearerAuth
+func (c *SupplierController) SubmitPurchaseOrder(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	orderID := ctx.Param("purchaseOrderId")
+
+	if businessID == "" || orderID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Purchase order ID are required")
+		return
+	}
+
+	if err := c.supplierUC.SubmitPurchaseOrder(ctx.Request.Context(), orderID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Purchase order submitted successfully"})
+}
+
+// ReceivePurchaseOrder godoc
+// @Summary      Receive purchase order
+// @Description  Receive goods into stock and record the supplier invoice for the order total
+// @Tags         purchase-orders
+// @Accept       json
+// @Produce      json
+// @Param        businessId       path  string                              true  "Business ID"
+// @Param        purchaseOrderId  path  string                              true  "Purchase order ID"
+// @Param        request          body  Domain.ReceivePurchaseOrderRequest  false "Invoice details"
+// @Success      200  {object}  Domain.PurchaseOrder
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders/{purchaseOrderId}/receive [post]
+// @Security     BearerAuth
+func (c *SupplierController) ReceivePurchaseOrder(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	orderID := ctx.Param("purchaseOrderId")
+
+	if businessID == "" || orderID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Purchase order ID are required")
+		return
+	}
+
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	// Invoice details are optional
+	var req Domain.ReceivePurchaseOrderRequest
+	if ctx.Request.ContentLength > 0 {
+		if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+	}
+
+	order, err := c.supplierUC.ReceivePurchaseOrder(ctx.Request.Context(), orderID, businessID, userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, order)
+}
+
+// CancelPurchaseOrder godoc
+// @Summary      Cancel purchase order
+// @Description  Cancel a purchase order that has not been received
+// @Tags         purchase-orders
+// @Produce      json
+// @Param        businessId       path  string  true  "Business ID"
+// @Param        purchaseOrderId  path  string  true  "Purchase order ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/purchase-orders/{purchaseOrderId} [delete]
+// @Security     BearerAuth
+func (c *SupplierController) CancelPurchaseOrder(ctx *gin.Context) {
+	businessID := ctx.Param("businessId")
+	orderID := ctx.Param("purchaseOrderId")
+
+	if businessID == "" || orderID == "" {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "Business ID and Purchase order ID are required")
+		return
+	}
+
+	if err := c.supplierUC.CancelPurchaseOrder(ctx.Request.Context(), orderID, businessID); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Purchase order cancelled successfully"})
+}
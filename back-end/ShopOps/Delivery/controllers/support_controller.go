@@ -0,0 +1,294 @@
+package controllers
+
+import (
+	"io"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type SupportController struct {
+	supportUC Usecases.SupportUseCase
+}
+
+func NewSupportController(supportUC Usecases.SupportUseCase) *SupportController {
+	return &SupportController{supportUC: supportUC}
+}
+
+// SearchShops godoc
+// @Summary      Find shops
+// @Description  Shops matching an ID, name, phone or email, or owned by the user with that phone. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        q  query  string  true  "Business ID, name, phone or email"
+// @Success      200  {array}   Domain.Business
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /internal/v1/shops [get]
+// @Security     SupportKey
+func (c *SupportController) SearchShops(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.search")
+	Infrastructure.AuditDetail(ctx, "query", ctx.Query("q"))
+
+	shops, err := c.supportUC.SearchShops(ctx.Query("q"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, shops)
+}
+
+// GetShop godoc
+// @Summary      Look up a shop
+// @Description  The shop with its owner, account status, enabled features, last backup and recent error count. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.ShopOverview
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId} [get]
+// @Security     SupportKey
+func (c *SupportController) GetShop(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.view")
+
+	shop, err := c.supportUC.GetShop(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, shop)
+}
+
+// ResetRateLimits godoc
+// @Summary      Reset a shop's rate limits
+// @Description  Clear the request counts of the shop and its users on the instance handling the request. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId}/rate-limits/reset [post]
+// @Security     SupportKey
+func (c *SupportController) ResetRateLimits(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.rate_limits.reset")
+
+	if err := c.supportUC.ResetRateLimits(ctx.Request.Context(), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, gin.H{"message": "Rate limits reset successfully"})
+}
+
+// TriggerBackup godoc
+// @Summary      Back up a shop
+// @Description  Queue a full export of the shop's data; it appears under the shop's backups once the job finishes. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      202  {object}  Domain.Job
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId}/backups [post]
+// @Security     SupportKey
+func (c *SupportController) TriggerBackup(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.backup.trigger")
+
+	job, err := c.supportUC.TriggerBackup(ctx.Request.Context(), ctx.Param("businessId"), Infrastructure.SupportActor(ctx))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	Infrastructure.AuditDetail(ctx, "job_id", job.ID.Hex())
+	ctx.JSON(http.StatusAccepted, job)
+}
+
+// GetBackups godoc
+// @Summary      List a shop's backups
+// @Description  Backups taken of the shop, newest first. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.ShopBackup
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId}/backups [get]
+// @Security     SupportKey
+func (c *SupportController) GetBackups(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.backup.list")
+
+	backups, err := c.supportUC.GetBackups(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, backups)
+}
+
+// DownloadBackup godoc
+// @Summary      Download a shop backup
+// @Description  The backup as JSON lines, one {"collection", "document"} object per line. Support API.
+// @Tags         support
+// @Produce      application/x-ndjson
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        backupId    path  string  true  "Backup ID"
+// @Success      200  {file}    file
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId}/backups/{backupId}/download [get]
+// @Security     SupportKey
+func (c *SupportController) DownloadBackup(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.backup.download")
+	Infrastructure.AuditDetail(ctx, "backup_id", ctx.Param("backupId"))
+
+	backup, file, err := c.supportUC.OpenBackup(ctx.Param("businessId"), ctx.Param("backupId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+	defer file.Close()
+
+	ctx.Header("Content-Disposition", "attachment; filename="+strconv.Quote(path.Base(backup.URL)))
+	ctx.Header("Content-Type", "application/x-ndjson")
+	ctx.Status(http.StatusOK)
+	_, _ = io.Copy(ctx.Writer, file)
+}
+
+// Impersonate godoc
+// @Summary      Impersonate a shop's owner
+// @Description  A short-lived access token for the shop's owner that can only read, for seeing what they see. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.ImpersonationResponse
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId}/impersonate [post]
+// @Security     SupportKey
+func (c *SupportController) Impersonate(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.impersonate")
+
+	session, err := c.supportUC.Impersonate(ctx.Param("businessId"), Infrastructure.SupportActor(ctx))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	Infrastructure.AuditDetail(ctx, "user_id", session.UserID)
+	Infrastructure.AuditDetail(ctx, "expires_at", session.ExpiresAt.UTC().Format(time.RFC3339))
+	ctx.JSON(http.StatusOK, session)
+}
+
+// GetRecentErrors godoc
+// @Summary      A shop's recent errors
+// @Description  Failed requests made against the shop in the last 14 days, newest first. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        limit       query  int     false  "Limit results (default 50)"
+// @Success      200  {array}   Domain.RequestError
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /internal/v1/shops/{businessId}/errors [get]
+// @Security     SupportKey
+func (c *SupportController) GetRecentErrors(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "shop.errors.view")
+
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 && l <= 500 {
+			limit = l
+		}
+	}
+
+	requestErrors, err := c.supportUC.GetRecentErrors(ctx.Param("businessId"), limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, requestErrors)
+}
+
+// GetAuditLog godoc
+// @Summary      Support audit log
+// @Description  Calls made through the support API, newest first. When the page is full, X-Next-Cursor holds the cursor for the next one. Support API.
+// @Tags         support
+// @Produce      json
+// @Param        actor        query  string  false  "Only calls made with this support key"
+// @Param        business_id  query  string  false  "Only calls about this business"
+// @Param        cursor       query  string  false  "X-Next-Cursor from the previous page"
+// @Param        limit        query  int     false  "Limit results (default 50, max 500)"
+// @Param        offset       query  int     false  "Offset results (deprecated; page by cursor)"
+// @Param        include_total  query  bool  false  "Send the total across all pages in X-Total-Count"
+// @Success      200  {array}   Domain.AuditEntry
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /internal/v1/audit-log [get]
+// @Security     SupportKey
+func (c *SupportController) GetAuditLog(ctx *gin.Context) {
+	Infrastructure.AuditAction(ctx, "audit_log.view")
+
+	filters := Domain.AuditFilters{}
+
+	if actor := ctx.Query("actor"); actor != "" {
+		filters.Actor = &actor
+	}
+
+	if businessID := ctx.Query("business_id"); businessID != "" {
+		filters.BusinessID = &businessID
+	}
+
+	// Pagination
+	page, err := Infrastructure.ParsePage(ctx, Domain.AuditSorts, "-created_at")
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+	if page.Limit == 0 {
+		page.Limit = 50
+	}
+	filters.Limit = page.Limit
+	filters.After = page.After
+
+	if offsetStr := ctx.Query("offset"); offsetStr != "" {
+		if offset, err := strconv.Atoi(offsetStr); err == nil && offset >= 0 {
+			filters.Offset = offset
+		}
+	}
+
+	entries, err := c.supportUC.GetAuditLog(filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if page.IncludeTotal {
+		total, err := c.supportUC.CountAuditLog(filters)
+		if err != nil {
+			Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+			return
+		}
+		Infrastructure.SetTotalCount(ctx, total)
+	}
+
+	var last Domain.Sortable
+	if n := len(entries); n > 0 {
+		last = &entries[n-1]
+	}
+	Infrastructure.SetNextCursor(ctx, page, len(entries), last)
+
+	ctx.JSON(http.StatusOK, entries)
+}
@@ -54,7 +54,7 @@ func (c *SyncController) ProcessBatch(ctx *gin.Context) {
 	// Set business ID from URL parameter
 	batch.BusinessID = businessID
 
-	response, err := c.syncUC.ProcessBatch(batch)
+	response, err := c.syncUC.ProcessBatch(ctx.Request.Context(), batch)
 	if err != nil {
 		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
 		return
@@ -0,0 +1,403 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TabController struct {
+	tabUC Usecases.TabUseCase
+}
+
+func NewTabController(tabUC Usecases.TabUseCase) *TabController {
+	return &TabController{tabUC: tabUC}
+}
+
+// OpenTab godoc
+// @Summary      Open a tab
+// @Description  Starts a running bill for a table, with the first items ordered if any. The waiter defaults to whoever is clocked in on the device.
+// @Tags         tabs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                 true  "Business ID"
+// @Param        request     body  Domain.OpenTabRequest  true  "Tab"
+// @Success      201  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs [post]
+// @Security     BearerAuth
+func (c *TabController) OpenTab(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.OpenTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	tab, err := c.tabUC.OpenTab(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, tab)
+}
+
+// GetTabs godoc
+// @Summary      List tabs
+// @Description  Newest first
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId   path   string  true   "Business ID"
+// @Param        status       query  string  false  "Status: open, closed, cancelled"
+// @Param        table        query  string  false  "Only this table's"
+// @Param        employee_id  query  string  false  "Only this waiter's"
+// @Param        limit        query  int     false  "Limit results (default 50, at most 200)"
+// @Param        offset       query  int     false  "Offset results"
+// @Success      200  {array}   Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs [get]
+// @Security     BearerAuth
+func (c *TabController) GetTabs(ctx *gin.Context) {
+	var filters Domain.TabFilters
+	if status := ctx.Query("status"); status != "" {
+		s := Domain.TabStatus(status)
+		filters.Status = &s
+	}
+	if table := ctx.Query("table"); table != "" {
+		filters.Table = &table
+	}
+	if employeeID := ctx.Query("employee_id"); employeeID != "" {
+		filters.EmployeeID = &employeeID
+	}
+	if limit, err := strconv.Atoi(ctx.Query("limit")); err == nil && limit > 0 {
+		filters.Limit = limit
+	}
+	if offset, err := strconv.Atoi(ctx.Query("offset")); err == nil && offset >= 0 {
+		filters.Offset = offset
+	}
+
+	tabs, err := c.tabUC.GetTabs(ctx.Param("businessId"), filters)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tabs)
+}
+
+// GetTabChanges godoc
+// @Summary      Follow changes to tabs
+// @Description  Tabs changed since a revision, closed and cancelled ones included, with the revision to ask from next. With wait the request is held until there is a change or the wait runs out, so waiter devices see each other's orders within a second or so. Without a revision, the open tabs to start from.
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        revision    query  int     false  "The revision last seen"
+// @Param        wait        query  int     false  "Seconds to wait for a change, at most 30"
+// @Success      200  {object}  Domain.TabChanges
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/changes [get]
+// @Security     BearerAuth
+func (c *TabController) GetTabChanges(ctx *gin.Context) {
+	var revision *int64
+	if r := ctx.Query("revision"); r != "" {
+		parsed, err := strconv.ParseInt(r, 10, 64)
+		if err != nil || parsed < 0 {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "revision must be a whole number")
+			return
+		}
+		revision = &parsed
+	}
+
+	var wait time.Duration
+	if seconds, err := strconv.Atoi(ctx.Query("wait")); err == nil && seconds > 0 {
+		wait = time.Duration(seconds) * time.Second
+	}
+
+	changes, err := c.tabUC.GetChanges(ctx.Request.Context(), ctx.Param("businessId"), revision, wait)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, changes)
+}
+
+// GetTab godoc
+// @Summary      Get a tab
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        tabId       path  string  true  "Tab ID"
+// @Success      200  {object}  Domain.Tab
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId} [get]
+// @Security     BearerAuth
+func (c *TabController) GetTab(ctx *gin.Context) {
+	tab, err := c.tabUC.GetTab(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// UpdateTab godoc
+// @Summary      Update a tab
+// @Description  Move the party to another table, or change the guests, waiter or notes
+// @Tags         tabs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                   true  "Business ID"
+// @Param        tabId       path  string                   true  "Tab ID"
+// @Param        request     body  Domain.UpdateTabRequest  true  "Changes"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId} [patch]
+// @Security     BearerAuth
+func (c *TabController) UpdateTab(ctx *gin.Context) {
+	var req Domain.UpdateTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	tab, err := c.tabUC.UpdateTab(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// AddTabItems godoc
+// @Summary      Add items to a tab
+// @Description  Orders taken from any device; adding items drops a split. Not once payments are taken.
+// @Tags         tabs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                     true  "Business ID"
+// @Param        tabId       path  string                     true  "Tab ID"
+// @Param        request     body  Domain.AddTabItemsRequest  true  "Items"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/items [post]
+// @Security     BearerAuth
+func (c *TabController) AddTabItems(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.AddTabItemsRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	tab, err := c.tabUC.AddItems(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// RemoveTabItem godoc
+// @Summary      Remove an item from a tab
+// @Description  Removing an item drops a split. Not once payments are taken.
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        tabId       path  string  true  "Tab ID"
+// @Param        itemId      path  string  true  "Item ID"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/items/{itemId} [delete]
+// @Security     BearerAuth
+func (c *TabController) RemoveTabItem(ctx *gin.Context) {
+	tab, err := c.tabUC.RemoveItem(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("itemId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// SplitTab godoc
+// @Summary      Split a tab
+// @Description  Evenly between a number of guests, or into bills of the items listed, with items left out on a bill of their own. Neither undoes the split. Not once payments are taken.
+// @Tags         tabs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                  true  "Business ID"
+// @Param        tabId       path  string                  true  "Tab ID"
+// @Param        request     body  Domain.SplitTabRequest  true  "Split"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/split [post]
+// @Security     BearerAuth
+func (c *TabController) SplitTab(ctx *gin.Context) {
+	var req Domain.SplitTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	tab, err := c.tabUC.SplitTab(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// PayTab godoc
+// @Summary      Take payment on a tab
+// @Description  One or more payments towards the tab or one of its bills; a single payment without an amount pays what is left. Once the whole tab is paid it closes, recording a sale for each item credited to the waiter.
+// @Tags         tabs
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                true  "Business ID"
+// @Param        tabId       path  string                true  "Tab ID"
+// @Param        request     body  Domain.PayTabRequest  true  "Payments"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/payments [post]
+// @Security     BearerAuth
+func (c *TabController) PayTab(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.PayTabRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	tab, err := c.tabUC.PayTab(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// RemoveTabPayment godoc
+// @Summary      Take back a payment on a tab
+// @Description  For a payment taken in error, before the tab's sales are recorded
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        tabId       path  string  true  "Tab ID"
+// @Param        paymentId   path  string  true  "Payment ID"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/payments/{paymentId} [delete]
+// @Security     BearerAuth
+func (c *TabController) RemoveTabPayment(ctx *gin.Context) {
+	tab, err := c.tabUC.RemovePayment(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("paymentId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// CloseTab godoc
+// @Summary      Close a paid tab
+// @Description  Records the sales left of a fully paid tab whose closing was cut short
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        tabId       path  string  true  "Tab ID"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/close [post]
+// @Security     BearerAuth
+func (c *TabController) CloseTab(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	tab, err := c.tabUC.CloseTab(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"), userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
+
+// CancelTab godoc
+// @Summary      Cancel a tab
+// @Description  Closes a tab with nothing paid on it, without any sales
+// @Tags         tabs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        tabId       path  string  true  "Tab ID"
+// @Success      200  {object}  Domain.Tab
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/tabs/{tabId}/cancel [post]
+// @Security     BearerAuth
+func (c *TabController) CancelTab(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	tab, err := c.tabUC.CancelTab(ctx.Request.Context(), ctx.Param("tabId"), ctx.Param("businessId"), userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, tab)
+}
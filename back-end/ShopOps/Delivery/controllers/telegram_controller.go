@@ -0,0 +1,147 @@
+package controllers
+
+import (
+	"crypto/subtle"
+	"log/slog"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TelegramController struct {
+	telegramUC    Usecases.TelegramUseCase
+	webhookSecret string
+}
+
+func NewTelegramController(telegramUC Usecases.TelegramUseCase, webhookSecret string) *TelegramController {
+	return &TelegramController{telegramUC: telegramUC, webhookSecret: webhookSecret}
+}
+
+// CreateTelegramLinkCode godoc
+// @Summary      Get a Telegram link code
+// @Description  A one-time code, valid for 10 minutes, that links a Telegram chat to the business when sent to the bot as /link CODE. bot_url opens the bot with the code filled in. Owners only.
+// @Tags         telegram
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      201  {object}  Domain.TelegramLinkCodeResponse
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/telegram/link-code [post]
+// @Security     BearerAuth
+func (c *TelegramController) CreateTelegramLinkCode(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	code, err := c.telegramUC.CreateLinkCode(ctx.Param("businessId"), userID.(string))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, code)
+}
+
+// GetTelegramLinks godoc
+// @Summary      List linked Telegram chats
+// @Description  Telegram chats linked to the business and what each is sent. Owners only.
+// @Tags         telegram
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.TelegramLink
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/telegram/links [get]
+// @Security     BearerAuth
+func (c *TelegramController) GetTelegramLinks(ctx *gin.Context) {
+	links, err := c.telegramUC.GetLinks(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, links)
+}
+
+// UpdateTelegramLink godoc
+// @Summary      Update Telegram notifications
+// @Description  Choose what a linked chat is sent: the daily summary and its shop-local hour, low-stock alerts, and sales at or above a big-sale amount (0 for none). Owners only.
+// @Tags         telegram
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                            true  "Business ID"
+// @Param        linkId      path  string                            true  "Link ID"
+// @Param        request     body  Domain.UpdateTelegramLinkRequest  true  "Notifications to change"
+// @Success      200  {object}  Domain.TelegramLink
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/telegram/links/{linkId} [patch]
+// @Security     BearerAuth
+func (c *TelegramController) UpdateTelegramLink(ctx *gin.Context) {
+	var req Domain.UpdateTelegramLinkRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	link, err := c.telegramUC.UpdateLink(ctx.Param("linkId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, link)
+}
+
+// DeleteTelegramLink godoc
+// @Summary      Unlink a Telegram chat
+// @Description  Stop sending the business's notifications to a chat. Owners only.
+// @Tags         telegram
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        linkId      path  string  true  "Link ID"
+// @Success      204
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/telegram/links/{linkId} [delete]
+// @Security     BearerAuth
+func (c *TelegramController) DeleteTelegramLink(ctx *gin.Context) {
+	if err := c.telegramUC.DeleteLink(ctx.Param("linkId"), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// TelegramWebhook receives bot updates from Telegram, which authenticates with the
+// secret token given to setWebhook. It answers 200 even when handling fails, since
+// Telegram would otherwise resend the update and the user would get repeated replies.
+func (c *TelegramController) TelegramWebhook(ctx *gin.Context) {
+	token := ctx.GetHeader("X-Telegram-Bot-Api-Secret-Token")
+	if c.webhookSecret == "" || subtle.ConstantTimeCompare([]byte(token), []byte(c.webhookSecret)) != 1 {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "invalid secret token")
+		return
+	}
+
+	var update Domain.TelegramUpdate
+	if err := ctx.ShouldBindJSON(&update); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	if err := c.telegramUC.HandleUpdate(ctx.Request.Context(), update); err != nil {
+		Infrastructure.LoggerFrom(ctx.Request.Context()).Warn("failed to handle telegram update",
+			slog.Int64("update_id", update.UpdateID), slog.String("error", err.Error()))
+	}
+
+	ctx.Status(http.StatusOK)
+}
@@ -0,0 +1,109 @@
+package controllers
+
+import (
+	"net/http"
+
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type TrashController struct {
+	trashUC Usecases.TrashUseCase
+}
+
+func NewTrashController(trashUC Usecases.TrashUseCase) *TrashController {
+	return &TrashController{trashUC: trashUC}
+}
+
+// GetTrash godoc
+// @Summary      Get trash
+// @Description  Deleted products, customers and suppliers that can still be restored. Items are purged 30 days after deletion.
+// @Tags         trash
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {object}  Domain.Trash
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/trash [get]
+// @Security     BearerAuth
+func (c *TrashController) GetTrash(ctx *gin.Context) {
+	trash, err := c.trashUC.GetTrash(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, trash)
+}
+
+// RestoreProduct godoc
+// @Summary      Restore product
+// @Description  Take a deleted product out of the trash
+// @Tags         trash
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        productId   path  string  true  "Product ID"
+// @Success      200  {object}  Domain.Product
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/inventory/products/{productId}/restore [post]
+// @Security     BearerAuth
+func (c *TrashController) RestoreProduct(ctx *gin.Context) {
+	product, err := c.trashUC.RestoreProduct(ctx.Request.Context(), ctx.Param("productId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, product)
+}
+
+// RestoreCustomer godoc
+// @Summary      Restore customer
+// @Description  Take a deleted customer out of the trash. Fails if another customer now has their phone number.
+// @Tags         trash
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        customerId  path  string  true  "Customer ID"
+// @Success      200  {object}  Domain.Customer
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Failure      409  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/customers/{customerId}/restore [post]
+// @Security     BearerAuth
+func (c *TrashController) RestoreCustomer(ctx *gin.Context) {
+	customer, err := c.trashUC.RestoreCustomer(ctx.Request.Context(), ctx.Param("customerId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, customer)
+}
+
+// RestoreSupplier godoc
+// @Summary      Restore supplier
+// @Description  Take a deleted supplier out of the trash
+// @Tags         trash
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        supplierId  path  string  true  "Supplier ID"
+// @Success      200  {object}  Domain.Supplier
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/suppliers/{supplierId}/restore [post]
+// @Security     BearerAuth
+func (c *TrashController) RestoreSupplier(ctx *gin.Context) {
+	supplier, err := c.trashUC.RestoreSupplier(ctx.Request.Context(), ctx.Param("supplierId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, supplier)
+}
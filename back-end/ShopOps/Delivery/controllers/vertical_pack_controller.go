@@ -0,0 +1,90 @@
+package controllers
+
+import (
+	"net/http"
+
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type VerticalPackController struct {
+	packUC Usecases.VerticalPackUseCase
+}
+
+func NewVerticalPackController(packUC Usecases.VerticalPackUseCase) *VerticalPackController {
+	return &VerticalPackController{packUC: packUC}
+}
+
+// GetPacks godoc
+// @Summary      List vertical packs
+// @Description  Packs of custom fields, units, tax rates, receipt templates and reports for kinds of shop, with the version the shop has installed and whether a newer one is out
+// @Tags         packs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.PackStatus
+// @Failure      401  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/packs [get]
+// @Security     BearerAuth
+func (c *VerticalPackController) GetPacks(ctx *gin.Context) {
+	packs, err := c.packUC.GetPacks(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, packs)
+}
+
+// GetPack godoc
+// @Summary      Get a vertical pack
+// @Description  Everything the pack adds to a shop
+// @Tags         packs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        code        path  string  true  "Pack code"
+// @Success      200  {object}  Domain.VerticalPack
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/packs/{code} [get]
+// @Security     BearerAuth
+func (c *VerticalPackController) GetPack(ctx *gin.Context) {
+	pack, err := c.packUC.GetPack(ctx.Param("code"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, pack)
+}
+
+// InstallPack godoc
+// @Summary      Install or update a vertical pack
+// @Description  Adds the pack's categories, units and tax rates to the catalog settings and creates its custom fields, receipt templates and reports. Installing again brings them up to the pack's latest version, except those the shop changed or deleted, which are left as they are; nothing is removed. Owners only.
+// @Tags         packs
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        code        path  string  true  "Pack code"
+// @Success      200  {object}  Domain.PackInstallResult
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/packs/{code}/install [post]
+// @Security     BearerAuth
+func (c *VerticalPackController) InstallPack(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	result, err := c.packUC.InstallPack(ctx.Request.Context(), ctx.Param("businessId"), userID.(string), ctx.Param("code"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, result)
+}
@@ -0,0 +1,231 @@
+package controllers
+
+import (
+	"net/http"
+	"strconv"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	"github.com/gin-gonic/gin"
+)
+
+type WebhookController struct {
+	webhookUC Usecases.WebhookUseCase
+}
+
+func NewWebhookController(webhookUC Usecases.WebhookUseCase) *WebhookController {
+	return &WebhookController{webhookUC: webhookUC}
+}
+
+// CreateWebhook godoc
+// @Summary      Register a webhook
+// @Description  Have events POSTed to a URL. Each delivery is signed with the secret returned here, which is not shown again: the X-ShopOps-Signature header is t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">. Events: sale.created, stock.low, backup.completed. Owners only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                       true  "Business ID"
+// @Param        request     body  Domain.CreateWebhookRequest  true  "URL and events"
+// @Success      201  {object}  Domain.WebhookSecretResponse
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks [post]
+// @Security     BearerAuth
+func (c *WebhookController) CreateWebhook(ctx *gin.Context) {
+	userID, exists := ctx.Get("userID")
+	if !exists {
+		Infrastructure.JSONError(ctx, http.StatusUnauthorized, nil, "User not authenticated")
+		return
+	}
+
+	var req Domain.CreateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	webhook, err := c.webhookUC.CreateWebhook(ctx.Param("businessId"), userID.(string), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, webhook)
+}
+
+// GetWebhooks godoc
+// @Summary      List webhooks
+// @Description  Get the business's webhooks, without their secrets. Owners only.
+// @Tags         webhooks
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Success      200  {array}   Domain.Webhook
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks [get]
+// @Security     BearerAuth
+func (c *WebhookController) GetWebhooks(ctx *gin.Context) {
+	webhooks, err := c.webhookUC.GetWebhooks(ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusInternalServerError, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhooks)
+}
+
+// GetWebhook godoc
+// @Summary      Get webhook
+// @Description  Get a webhook, without its secret. Owners only.
+// @Tags         webhooks
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        webhookId   path  string  true  "Webhook ID"
+// @Success      200  {object}  Domain.Webhook
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks/{webhookId} [get]
+// @Security     BearerAuth
+func (c *WebhookController) GetWebhook(ctx *gin.Context) {
+	webhook, err := c.webhookUC.GetWebhookByID(ctx.Request.Context(), ctx.Param("webhookId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusNotFound, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhook)
+}
+
+// UpdateWebhook godoc
+// @Summary      Update webhook
+// @Description  Change a webhook's URL, events or description, or pause it by setting active to false. Deliveries queued while paused are dropped. Owners only.
+// @Tags         webhooks
+// @Accept       json
+// @Produce      json
+// @Param        businessId  path  string                       true  "Business ID"
+// @Param        webhookId   path  string                       true  "Webhook ID"
+// @Param        request     body  Domain.UpdateWebhookRequest  true  "Fields to change"
+// @Success      200  {object}  Domain.Webhook
+// @Failure      400  {object}  map[string]interface{}
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks/{webhookId} [patch]
+// @Security     BearerAuth
+func (c *WebhookController) UpdateWebhook(ctx *gin.Context) {
+	var req Domain.UpdateWebhookRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	webhook, err := c.webhookUC.UpdateWebhook(ctx.Request.Context(), ctx.Param("webhookId"), ctx.Param("businessId"), req)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhook)
+}
+
+// DeleteWebhook godoc
+// @Summary      Delete webhook
+// @Description  Stop sending events to a webhook. Its delivery log is kept until it expires. Owners only.
+// @Tags         webhooks
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        webhookId   path  string  true  "Webhook ID"
+// @Success      204
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks/{webhookId} [delete]
+// @Security     BearerAuth
+func (c *WebhookController) DeleteWebhook(ctx *gin.Context) {
+	if err := c.webhookUC.DeleteWebhook(ctx.Request.Context(), ctx.Param("webhookId"), ctx.Param("businessId")); err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.Status(http.StatusNoContent)
+}
+
+// RotateWebhookSecret godoc
+// @Summary      Rotate webhook secret
+// @Description  Replace a webhook's signing secret, returning the new one. Deliveries sent from now on, including retries, use it. Owners only.
+// @Tags         webhooks
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        webhookId   path  string  true  "Webhook ID"
+// @Success      200  {object}  Domain.WebhookSecretResponse
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks/{webhookId}/rotate-secret [post]
+// @Security     BearerAuth
+func (c *WebhookController) RotateWebhookSecret(ctx *gin.Context) {
+	webhook, err := c.webhookUC.RotateSecret(ctx.Request.Context(), ctx.Param("webhookId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, webhook)
+}
+
+// GetWebhookDeliveries godoc
+// @Summary      List webhook deliveries
+// @Description  The delivery log of a webhook, newest first, with every attempt's response. Kept for 30 days. Owners only.
+// @Tags         webhooks
+// @Produce      json
+// @Param        businessId  path   string  true   "Business ID"
+// @Param        webhookId   path   string  true   "Webhook ID"
+// @Param        limit       query  int     false  "Limit results (default 50)"
+// @Success      200  {array}   Domain.WebhookDelivery
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks/{webhookId}/deliveries [get]
+// @Security     BearerAuth
+func (c *WebhookController) GetWebhookDeliveries(ctx *gin.Context) {
+	limit := 50
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	deliveries, err := c.webhookUC.GetDeliveries(ctx.Request.Context(), ctx.Param("webhookId"), ctx.Param("businessId"), limit)
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, deliveries)
+}
+
+// ReplayWebhookDelivery godoc
+// @Summary      Replay webhook delivery
+// @Description  Send a past delivery's event again, e.g. after fixing the receiving system. The replay is a new delivery with its own ID and log. Owners only.
+// @Tags         webhooks
+// @Produce      json
+// @Param        businessId  path  string  true  "Business ID"
+// @Param        webhookId   path  string  true  "Webhook ID"
+// @Param        deliveryId  path  string  true  "Delivery ID"
+// @Success      202  {object}  Domain.WebhookDelivery
+// @Failure      401  {object}  map[string]interface{}
+// @Failure      403  {object}  map[string]interface{}
+// @Failure      404  {object}  map[string]interface{}
+// @Router       /api/v1/businesses/{businessId}/webhooks/{webhookId}/deliveries/{deliveryId}/replay [post]
+// @Security     BearerAuth
+func (c *WebhookController) ReplayWebhookDelivery(ctx *gin.Context) {
+	delivery, err := c.webhookUC.ReplayDelivery(ctx.Request.Context(), ctx.Param("webhookId"), ctx.Param("deliveryId"), ctx.Param("businessId"))
+	if err != nil {
+		Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+		return
+	}
+
+	ctx.JSON(http.StatusAccepted, delivery)
+}
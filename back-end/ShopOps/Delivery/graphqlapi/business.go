@@ -0,0 +1,177 @@
+package graphqlapi
+
+import (
+	"context"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type services struct {
+	business  Usecases.BusinessUseCase
+	sales     Usecases.SalesUseCase
+	inventory Usecases.InventoryUseCase
+	customers Usecases.CustomerUseCase
+	reports   Usecases.ReportUseCase
+	tenants   *Infrastructure.TenantResolver
+}
+
+type queryResolver struct {
+	svc *services
+}
+
+func (r *queryResolver) Businesses(ctx context.Context) ([]*businessResolver, error) {
+	principal, _ := Infrastructure.PrincipalFromContext(ctx)
+
+	businesses, err := r.svc.business.GetUserBusinesses(principal.UserID)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resolvers := make([]*businessResolver, 0, len(businesses))
+	for i := range businesses {
+		shop, err := r.shop(ctx, &businesses[i])
+		if err != nil {
+			return nil, err
+		}
+		resolvers = append(resolvers, shop)
+	}
+	return resolvers, nil
+}
+
+func (r *queryResolver) Business(ctx context.Context, args struct{ ID graphql.ID }) (*businessResolver, error) {
+	business, err := r.svc.business.GetBusinessByID(string(args.ID))
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusNotFound, err)
+	}
+	return r.shop(ctx, business)
+}
+
+// shop checks the caller belongs to the business, as the tenancy middleware does,
+// and starts the loaders its fields share
+func (r *queryResolver) shop(ctx context.Context, business *Domain.Business) (*businessResolver, error) {
+	principal, _ := Infrastructure.PrincipalFromContext(ctx)
+
+	tenant, err := r.svc.tenants.Resolve(business.ID.Hex(), principal.UserID, principal.Role)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+
+	shop := &businessResolver{svc: r.svc, business: business, tenant: tenant}
+	businessID := business.ID.Hex()
+	shop.products = Infrastructure.NewLoader(func(ids []string) (map[string]*Domain.Product, error) {
+		return r.svc.inventory.GetProductsByIDs(businessID, ids)
+	})
+	shop.customers = Infrastructure.NewLoader(func(phones []string) (map[string]*Domain.Customer, error) {
+		return r.svc.customers.GetCustomersByPhones(businessID, phones)
+	})
+	return shop, nil
+}
+
+type businessResolver struct {
+	svc      *services
+	business *Domain.Business
+	tenant   *Infrastructure.Tenant
+
+	products  *Infrastructure.Loader[string, *Domain.Product]
+	customers *Infrastructure.Loader[string, *Domain.Customer] // By phone
+}
+
+// scoped is ctx acting on the shop, so lookups made with it find its records only
+func (b *businessResolver) scoped(ctx context.Context) context.Context {
+	return Infrastructure.WithTenant(ctx, b.tenant)
+}
+
+// ownersOnly guards cost and profit figures, which employees do not see
+func (b *businessResolver) ownersOnly(ctx context.Context) error {
+	if b.tenant.Role == Infrastructure.TenantRoleOwner || b.tenant.Role == Infrastructure.TenantRoleAdmin {
+		return nil
+	}
+	return Infrastructure.GraphQLError(ctx, http.StatusForbidden, Domain.AccessDeniedError("Only the business owner can see this"))
+}
+
+func (b *businessResolver) ID() graphql.ID {
+	return graphql.ID(b.business.ID.Hex())
+}
+
+func (b *businessResolver) Name() string {
+	return b.business.Name
+}
+
+func (b *businessResolver) Description() *string {
+	return optional(b.business.Description)
+}
+
+func (b *businessResolver) BusinessType() string {
+	return b.business.BusinessType
+}
+
+func (b *businessResolver) Currency() string {
+	return b.business.Currency
+}
+
+func (b *businessResolver) Timezone() *string {
+	return optional(b.business.Timezone)
+}
+
+func (b *businessResolver) City() *string {
+	return optional(b.business.City)
+}
+
+func (b *businessResolver) Country() *string {
+	return optional(b.business.Country)
+}
+
+func (b *businessResolver) Status() string {
+	return string(b.business.Status)
+}
+
+func (b *businessResolver) Role() string {
+	return string(b.tenant.Role)
+}
+
+func (b *businessResolver) Reports() *reportsResolver {
+	return &reportsResolver{shop: b}
+}
+
+// pageInfo is the connection's paging, shared by products, sales and customers
+type pageInfo struct {
+	endCursor string
+}
+
+func (p *pageInfo) EndCursor() *string {
+	return optional(p.endCursor)
+}
+
+func (p *pageInfo) HasNextPage() bool {
+	return p.endCursor != ""
+}
+
+// page reads a connection's first, after and sort arguments as
+// Infrastructure.ParsePage reads the query
+func page(sorts Domain.ListSorts, defaultSort string, first *int32, after, sort *string) (*Domain.Page, error) {
+	key := defaultSort
+	if sort != nil && *sort != "" {
+		key = *sort
+	}
+	limit := defaultPageSize
+	if first != nil && *first > 0 {
+		limit = int(*first)
+	}
+	cursor := ""
+	if after != nil {
+		cursor = *after
+	}
+	return Infrastructure.NewPage(sorts, key, cursor, limit, false)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
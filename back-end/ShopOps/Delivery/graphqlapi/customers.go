@@ -0,0 +1,157 @@
+package graphqlapi
+
+import (
+	"context"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type customersArgs struct {
+	First  *int32
+	After  *string
+	Sort   *string
+	Search *string
+	Tag    *string
+	Tier   *string
+}
+
+func (b *businessResolver) Customers(ctx context.Context, args customersArgs) (*customerConnection, error) {
+	p, err := page(Domain.CustomerSorts, "name", args.First, args.After, args.Sort)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusBadRequest, err)
+	}
+
+	filters := Domain.CustomerFilters{
+		Search: args.Search,
+		Tag:    args.Tag,
+		Limit:  p.Limit,
+		Sort:   p.Sort,
+		After:  p.After,
+	}
+	if args.Tier != nil {
+		tier := Domain.CustomerTier(*args.Tier)
+		filters.Tier = &tier
+	}
+
+	list, err := b.svc.customers.GetCustomers(b.business.ID.Hex(), filters, false)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+
+	connection := &customerConnection{shop: b, filters: filters}
+	var last Domain.Sortable
+	for i := range list.Customers {
+		connection.nodes = append(connection.nodes, &customerResolver{shop: b, customer: &list.Customers[i]})
+		last = &list.Customers[i]
+	}
+	connection.pageInfo.endCursor = Infrastructure.NextCursor(p, len(list.Customers), last)
+	return connection, nil
+}
+
+func (b *businessResolver) Customer(ctx context.Context, args struct{ ID graphql.ID }) (*customerResolver, error) {
+	customer, err := b.svc.customers.GetCustomerByID(b.scoped(ctx), string(args.ID), b.business.ID.Hex())
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusNotFound, err)
+	}
+	return &customerResolver{shop: b, customer: customer}, nil
+}
+
+type customerConnection struct {
+	shop     *businessResolver
+	filters  Domain.CustomerFilters
+	nodes    []*customerResolver
+	pageInfo pageInfo
+}
+
+func (c *customerConnection) Nodes() []*customerResolver {
+	return c.nodes
+}
+
+func (c *customerConnection) PageInfo() *pageInfo {
+	return &c.pageInfo
+}
+
+func (c *customerConnection) TotalCount(ctx context.Context) (int32, error) {
+	total, err := c.shop.svc.customers.CountCustomers(c.shop.business.ID.Hex(), c.filters)
+	if err != nil {
+		return 0, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	return int32(total), nil
+}
+
+type customerResolver struct {
+	shop     *businessResolver
+	customer *Domain.Customer
+}
+
+func (r *customerResolver) ID() graphql.ID {
+	return graphql.ID(r.customer.ID.Hex())
+}
+
+func (r *customerResolver) Name() string {
+	return r.customer.Name
+}
+
+func (r *customerResolver) Phone() *string {
+	return optional(r.customer.Phone)
+}
+
+func (r *customerResolver) Email() *string {
+	return optional(r.customer.Email)
+}
+
+func (r *customerResolver) Address() *string {
+	return optional(r.customer.Address)
+}
+
+func (r *customerResolver) Notes() *string {
+	return optional(r.customer.Notes)
+}
+
+func (r *customerResolver) Tags() []string {
+	if r.customer.Tags == nil {
+		return []string{}
+	}
+	return r.customer.Tags
+}
+
+func (r *customerResolver) Tier() string {
+	if r.customer.Tier == "" {
+		return string(Domain.CustomerTierRetail)
+	}
+	return string(r.customer.Tier)
+}
+
+func (r *customerResolver) Status() string {
+	return string(r.customer.Status)
+}
+
+func (r *customerResolver) MarketingOptIn() bool {
+	return r.customer.MarketingOptIn
+}
+
+func (r *customerResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.customer.CreatedAt}
+}
+
+func (r *customerResolver) Sales(ctx context.Context, args struct{ First *int32 }) ([]*saleResolver, error) {
+	limit := defaultPageSize
+	if args.First != nil && *args.First > 0 {
+		limit = min(int(*args.First), maxPageSize)
+	}
+
+	sales, err := r.shop.svc.customers.GetCustomerSales(r.shop.scoped(ctx), r.customer.ID.Hex(), r.shop.business.ID.Hex(), limit, 0)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resolvers := make([]*saleResolver, len(sales))
+	for i := range sales {
+		resolvers[i] = &saleResolver{shop: r.shop, sale: &sales[i]}
+	}
+	return resolvers, nil
+}
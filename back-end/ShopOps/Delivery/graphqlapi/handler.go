@@ -0,0 +1,119 @@
+// Package graphqlapi serves the web dashboard's GraphQL endpoint. A dashboard page
+// asks for a shop's products, sales, customers and reports in one query; lookups
+// the query repeats, such as the product of every sale on a page, are batched by
+// per-request loaders, and cost and profit fields are checked against the
+// caller's place in the shop one by one.
+package graphqlapi
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"net/http"
+
+	app "ShopOps/Delivery/app"
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"github.com/gin-gonic/gin"
+	graphql "github.com/graph-gophers/graphql-go"
+	gqlerrors "github.com/graph-gophers/graphql-go/errors"
+)
+
+//go:embed schema.graphql
+var schemaSDL string
+
+// Limits on what one query may ask for, so a query cannot walk the whole shop
+const (
+	maxQueryDepth   = 8
+	maxQueryLength  = 16 << 10
+	maxParallelism  = 16
+	defaultPageSize = 50
+	maxPageSize     = 500
+)
+
+type graphQLRequest struct {
+	Query         string                 `json:"query" form:"query"`
+	OperationName string                 `json:"operationName" form:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewHandler serves queries by POST, or by GET with the query in the URL so
+// read-only sessions such as support impersonation can use it too
+func NewHandler(container *app.Container) gin.HandlerFunc {
+	uc := container.UseCases
+	root := &queryResolver{svc: &services{
+		business:  uc.Business,
+		sales:     uc.Sales,
+		inventory: uc.Inventory,
+		customers: uc.Customer,
+		reports:   uc.Report,
+		tenants:   Infrastructure.NewTenantResolver(container.Repos.Business, container.Repos.Employee),
+	}}
+
+	schema := graphql.MustParseSchema(schemaSDL, root,
+		graphql.UseStringDescriptions(),
+		graphql.MaxDepth(maxQueryDepth),
+		graphql.MaxQueryLength(maxQueryLength),
+		graphql.MaxParallelism(maxParallelism),
+		graphql.PanicHandler(panicHandler{}),
+		graphql.Logger(noPanicLog{}),
+	)
+
+	return func(ctx *gin.Context) {
+		var req graphQLRequest
+		if ctx.Request.Method == http.MethodGet {
+			req.Query = ctx.Query("query")
+			req.OperationName = ctx.Query("operationName")
+			if variables := ctx.Query("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+					Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "variables must be a JSON object")
+					return
+				}
+			}
+		} else if err := ctx.ShouldBindJSON(&req); err != nil {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, err, "")
+			return
+		}
+		if req.Query == "" {
+			Infrastructure.JSONError(ctx, http.StatusBadRequest, nil, "query is required")
+			return
+		}
+
+		principal := &Infrastructure.Principal{
+			UserID:       ctx.GetString("userID"),
+			Phone:        ctx.GetString("phone"),
+			Role:         ctx.GetString("role"),
+			Impersonator: ctx.GetString("impersonator"),
+		}
+		queryCtx := Infrastructure.WithPrincipal(ctx.Request.Context(), principal)
+
+		// Errors of single fields come back beside the data with a 200, as GraphQL
+		// clients expect
+		ctx.JSON(http.StatusOK, schema.Exec(queryCtx, req.Query, req.OperationName, req.Variables))
+	}
+}
+
+// panicHandler reports a resolver's panic as the REST recovery middleware does, and
+// gives the caller the error ID to quote
+type panicHandler struct{}
+
+func (panicHandler) MakePanicError(ctx context.Context, value interface{}) *gqlerrors.QueryError {
+	userID := ""
+	if principal, ok := Infrastructure.PrincipalFromContext(ctx); ok {
+		userID = principal.UserID
+	}
+	errorID := Infrastructure.ReportPanic(ctx, value, map[string]string{"api": "graphql"}, userID)
+
+	err := gqlerrors.Errorf("Internal server error (error ID %s)", errorID)
+	err.Extensions = map[string]interface{}{
+		"code":        Domain.ErrCodeInternal,
+		"message_key": Domain.ErrCodeInternal.MessageKey(),
+	}
+	return err
+}
+
+// noPanicLog leaves panics to panicHandler, which reports them with the user
+type noPanicLog struct{}
+
+func (noPanicLog) LogPanic(context.Context, interface{}) {}
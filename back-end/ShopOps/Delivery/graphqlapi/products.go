@@ -0,0 +1,202 @@
+package graphqlapi
+
+import (
+	"context"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type productsArgs struct {
+	First    *int32
+	After    *string
+	Sort     *string
+	Category *string
+	Status   *string
+	LowStock *bool
+	Search   *string
+}
+
+func (b *businessResolver) Products(ctx context.Context, args productsArgs) (*productConnection, error) {
+	p, err := page(Domain.ProductSorts, "name", args.First, args.After, args.Sort)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusBadRequest, err)
+	}
+
+	filters := Domain.ProductFilters{
+		Category: args.Category,
+		LowStock: args.LowStock,
+		Search:   args.Search,
+		Limit:    p.Limit,
+		Sort:     p.Sort,
+		After:    p.After,
+	}
+	if args.Status != nil {
+		status := Domain.ProductStatus(*args.Status)
+		filters.Status = &status
+	}
+
+	products, err := b.svc.inventory.GetProducts(b.business.ID.Hex(), filters)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+
+	connection := &productConnection{shop: b, filters: filters}
+	var last Domain.Sortable
+	for i := range products {
+		connection.nodes = append(connection.nodes, &productResolver{shop: b, product: &products[i]})
+		last = &products[i]
+	}
+	connection.pageInfo.endCursor = Infrastructure.NextCursor(p, len(products), last)
+	return connection, nil
+}
+
+func (b *businessResolver) Product(ctx context.Context, args struct{ ID graphql.ID }) (*productResolver, error) {
+	product, err := b.svc.inventory.GetProductByID(b.scoped(ctx), string(args.ID), b.business.ID.Hex())
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusNotFound, err)
+	}
+	return &productResolver{shop: b, product: product}, nil
+}
+
+type productConnection struct {
+	shop     *businessResolver
+	filters  Domain.ProductFilters
+	nodes    []*productResolver
+	pageInfo pageInfo
+}
+
+func (c *productConnection) Nodes() []*productResolver {
+	return c.nodes
+}
+
+func (c *productConnection) PageInfo() *pageInfo {
+	return &c.pageInfo
+}
+
+func (c *productConnection) TotalCount(ctx context.Context) (int32, error) {
+	total, err := c.shop.svc.inventory.CountProducts(c.shop.business.ID.Hex(), c.filters)
+	if err != nil {
+		return 0, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	return int32(total), nil
+}
+
+// loadProduct resolves a product ID held by another type, e.g. a sale's, through
+// the shop's loader
+func (b *businessResolver) loadProduct(ctx context.Context, id string) (*productResolver, error) {
+	if id == "" {
+		return nil, nil
+	}
+	product, err := b.products.Load(id)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	if product == nil {
+		return nil, nil
+	}
+	return &productResolver{shop: b, product: product}, nil
+}
+
+type productResolver struct {
+	shop    *businessResolver
+	product *Domain.Product
+}
+
+func (r *productResolver) ID() graphql.ID {
+	return graphql.ID(r.product.ID.Hex())
+}
+
+func (r *productResolver) Name() string {
+	return r.product.Name
+}
+
+func (r *productResolver) Description() *string {
+	return optional(r.product.Description)
+}
+
+func (r *productResolver) Sku() *string {
+	return optional(r.product.SKU)
+}
+
+func (r *productResolver) Barcode() *string {
+	return optional(r.product.Barcode)
+}
+
+func (r *productResolver) Category() *string {
+	return optional(r.product.Category)
+}
+
+func (r *productResolver) Unit() *string {
+	return optional(r.product.Unit)
+}
+
+func (r *productResolver) CostPrice(ctx context.Context) (*float64, error) {
+	if err := r.shop.ownersOnly(ctx); err != nil {
+		return nil, err
+	}
+	cost := r.product.CostPrice.Float64()
+	return &cost, nil
+}
+
+func (r *productResolver) SellingPrice() float64 {
+	return r.product.SellingPrice.Float64()
+}
+
+// Margin is the share of the selling price left after cost
+func (r *productResolver) Margin(ctx context.Context) (*float64, error) {
+	if err := r.shop.ownersOnly(ctx); err != nil {
+		return nil, err
+	}
+	if r.product.SellingPrice <= 0 {
+		return nil, nil
+	}
+	margin := (r.product.SellingPrice - r.product.CostPrice).Float64() / r.product.SellingPrice.Float64() * 100
+	return &margin, nil
+}
+
+func (r *productResolver) Stock() float64 {
+	return r.product.Stock
+}
+
+func (r *productResolver) MinStock() *float64 {
+	return optionalAmount(r.product.MinStock)
+}
+
+func (r *productResolver) MaxStock() *float64 {
+	return optionalAmount(r.product.MaxStock)
+}
+
+func (r *productResolver) ImageUrl() *string {
+	return optional(r.product.ImageURL)
+}
+
+func (r *productResolver) Status() string {
+	return string(r.product.Status)
+}
+
+func (r *productResolver) Version() int32 {
+	return int32(r.product.Version)
+}
+
+func (r *productResolver) Deleted() bool {
+	return r.product.DeletedAt != nil
+}
+
+func (r *productResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.product.CreatedAt}
+}
+
+func (r *productResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.product.UpdatedAt}
+}
+
+func optionalAmount(amount float64) *float64 {
+	if amount == 0 {
+		return nil
+	}
+	return &amount
+}
@@ -0,0 +1,344 @@
+package graphqlapi
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type reportsResolver struct {
+	shop *businessResolver
+}
+
+type reportArgs struct {
+	Period   *string
+	From     *graphql.Time
+	To       *graphql.Time
+	Calendar *string
+}
+
+// request builds the report request the REST report endpoints build from their query
+func (r *reportsResolver) request(reportType Domain.ReportType, args reportArgs) Domain.ReportRequest {
+	req := Domain.ReportRequest{
+		BusinessID: r.shop.business.ID.Hex(),
+		Type:       reportType,
+		Period:     Domain.PeriodTypeMonthly,
+	}
+	if args.Period != nil && *args.Period != "" {
+		req.Period = Domain.PeriodType(*args.Period)
+	}
+	if args.From != nil {
+		req.StartDate = &args.From.Time
+	}
+	if args.To != nil {
+		req.EndDate = &args.To.Time
+	}
+	if args.Calendar != nil {
+		req.Calendar = Domain.Calendar(*args.Calendar)
+	}
+	return req
+}
+
+func (r *reportsResolver) Dashboard(ctx context.Context) (*dashboardResolver, error) {
+	data, err := r.shop.svc.reports.GetDashboardData(r.shop.business.ID.Hex())
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	return &dashboardResolver{shop: r.shop, data: data}, nil
+}
+
+func (r *reportsResolver) Sales(ctx context.Context, args reportArgs) (*salesReportResolver, error) {
+	result, err := r.shop.svc.reports.GenerateReport(r.request(Domain.ReportTypeSales, args))
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusBadRequest, err)
+	}
+	report, ok := result.(*Domain.SalesReport)
+	if !ok {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, fmt.Errorf("unexpected sales report %T", result))
+	}
+	return &salesReportResolver{shop: r.shop, report: report}, nil
+}
+
+func (r *reportsResolver) Profit(ctx context.Context, args reportArgs) (*profitReportResolver, error) {
+	if err := r.shop.ownersOnly(ctx); err != nil {
+		return nil, err
+	}
+
+	result, err := r.shop.svc.reports.GenerateReport(r.request(Domain.ReportTypeProfit, args))
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusBadRequest, err)
+	}
+	report, ok := result.(*Domain.ProfitReport)
+	if !ok {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, fmt.Errorf("unexpected profit report %T", result))
+	}
+	return &profitReportResolver{report: report}, nil
+}
+
+func (r *reportsResolver) Inventory(ctx context.Context) (*inventoryReportResolver, error) {
+	result, err := r.shop.svc.reports.GenerateReport(Domain.ReportRequest{
+		BusinessID: r.shop.business.ID.Hex(),
+		Type:       Domain.ReportTypeInventory,
+	})
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	report, ok := result.(*Domain.InventoryReport)
+	if !ok {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, fmt.Errorf("unexpected inventory report %T", result))
+	}
+	return &inventoryReportResolver{shop: r.shop, report: report}, nil
+}
+
+type dashboardResolver struct {
+	shop *businessResolver
+	data *Domain.DashboardData
+}
+
+func (r *dashboardResolver) TodaySales() float64 {
+	return r.data.TodaySales.Float64()
+}
+
+func (r *dashboardResolver) TodayExpenses() float64 {
+	return r.data.TodayExpenses.Float64()
+}
+
+func (r *dashboardResolver) TodayProfit(ctx context.Context) (*float64, error) {
+	return r.profit(ctx, r.data.TodayProfit.Float64())
+}
+
+func (r *dashboardResolver) WeekSales() float64 {
+	return r.data.WeekSales.Float64()
+}
+
+func (r *dashboardResolver) WeekExpenses() float64 {
+	return r.data.WeekExpenses.Float64()
+}
+
+func (r *dashboardResolver) WeekProfit(ctx context.Context) (*float64, error) {
+	return r.profit(ctx, r.data.WeekProfit.Float64())
+}
+
+func (r *dashboardResolver) MonthSales() float64 {
+	return r.data.MonthSales.Float64()
+}
+
+func (r *dashboardResolver) MonthExpenses() float64 {
+	return r.data.MonthExpenses.Float64()
+}
+
+func (r *dashboardResolver) MonthProfit(ctx context.Context) (*float64, error) {
+	return r.profit(ctx, r.data.MonthProfit.Float64())
+}
+
+func (r *dashboardResolver) LowStockCount() int32 {
+	return int32(r.data.LowStockCount)
+}
+
+func (r *dashboardResolver) PendingPayments() float64 {
+	return r.data.PendingPayments.Float64()
+}
+
+func (r *dashboardResolver) profit(ctx context.Context, profit float64) (*float64, error) {
+	if err := r.shop.ownersOnly(ctx); err != nil {
+		return nil, err
+	}
+	return &profit, nil
+}
+
+type salesReportResolver struct {
+	shop   *businessResolver
+	report *Domain.SalesReport
+}
+
+func (r *salesReportResolver) Period() string {
+	return r.report.Period
+}
+
+func (r *salesReportResolver) TotalSales() float64 {
+	return r.report.TotalSales
+}
+
+func (r *salesReportResolver) TotalAmount() float64 {
+	return r.report.TotalAmount.Float64()
+}
+
+func (r *salesReportResolver) TotalTransactions() int32 {
+	return int32(r.report.TotalTransactions)
+}
+
+func (r *salesReportResolver) AverageSale() float64 {
+	return r.report.AverageSale.Float64()
+}
+
+func (r *salesReportResolver) TopProducts() []*topProductResolver {
+	products := make([]*topProductResolver, len(r.report.TopProducts))
+	for i := range r.report.TopProducts {
+		products[i] = &topProductResolver{shop: r.shop, top: &r.report.TopProducts[i]}
+	}
+	return products
+}
+
+func (r *salesReportResolver) DailyBreakdown() []*dailySalesResolver {
+	days := make([]*dailySalesResolver, len(r.report.DailyBreakdown))
+	for i := range r.report.DailyBreakdown {
+		days[i] = &dailySalesResolver{day: &r.report.DailyBreakdown[i]}
+	}
+	return days
+}
+
+type topProductResolver struct {
+	shop *businessResolver
+	top  *Domain.TopProduct
+}
+
+func (r *topProductResolver) Product(ctx context.Context) (*productResolver, error) {
+	return r.shop.loadProduct(ctx, r.top.ProductID)
+}
+
+func (r *topProductResolver) ProductName() string {
+	return r.top.ProductName
+}
+
+func (r *topProductResolver) Quantity() float64 {
+	return r.top.Quantity
+}
+
+func (r *topProductResolver) TotalAmount() float64 {
+	return r.top.TotalAmount.Float64()
+}
+
+type dailySalesResolver struct {
+	day *Domain.DailySales
+}
+
+func (r *dailySalesResolver) Date() string {
+	return r.day.Date
+}
+
+func (r *dailySalesResolver) Sales() float64 {
+	return r.day.Sales
+}
+
+func (r *dailySalesResolver) Amount() float64 {
+	return r.day.Amount.Float64()
+}
+
+func (r *dailySalesResolver) Transactions() int32 {
+	return int32(r.day.Transactions)
+}
+
+type profitReportResolver struct {
+	report *Domain.ProfitReport
+}
+
+func (r *profitReportResolver) Period() string {
+	return r.report.Period
+}
+
+func (r *profitReportResolver) TotalSales() float64 {
+	return r.report.TotalSales.Float64()
+}
+
+func (r *profitReportResolver) TotalExpenses() float64 {
+	return r.report.TotalExpenses.Float64()
+}
+
+func (r *profitReportResolver) GrossProfit() float64 {
+	return r.report.GrossProfit.Float64()
+}
+
+func (r *profitReportResolver) NetProfit() float64 {
+	return r.report.NetProfit.Float64()
+}
+
+func (r *profitReportResolver) ProfitMargin() float64 {
+	return r.report.ProfitMargin
+}
+
+func (r *profitReportResolver) ExpenseBreakdown() []*categoryExpenseResolver {
+	categories := make([]*categoryExpenseResolver, len(r.report.ExpenseBreakdown))
+	for i := range r.report.ExpenseBreakdown {
+		categories[i] = &categoryExpenseResolver{expense: &r.report.ExpenseBreakdown[i]}
+	}
+	return categories
+}
+
+type categoryExpenseResolver struct {
+	expense *Domain.CategoryExpense
+}
+
+func (r *categoryExpenseResolver) Category() string {
+	return string(r.expense.Category)
+}
+
+func (r *categoryExpenseResolver) Amount() float64 {
+	return r.expense.TotalAmount.Float64()
+}
+
+func (r *categoryExpenseResolver) Percentage() float64 {
+	return r.expense.Percentage
+}
+
+func (r *categoryExpenseResolver) Count() int32 {
+	return int32(r.expense.Count)
+}
+
+type inventoryReportResolver struct {
+	shop   *businessResolver
+	report *Domain.InventoryReport
+}
+
+func (r *inventoryReportResolver) TotalProducts() int32 {
+	return int32(r.report.TotalProducts)
+}
+
+func (r *inventoryReportResolver) TotalStock() float64 {
+	return r.report.TotalStock
+}
+
+func (r *inventoryReportResolver) TotalValue(ctx context.Context) (*float64, error) {
+	if err := r.shop.ownersOnly(ctx); err != nil {
+		return nil, err
+	}
+	value := r.report.TotalValue.Float64()
+	return &value, nil
+}
+
+func (r *inventoryReportResolver) LowStockItems() []*lowStockItemResolver {
+	items := make([]*lowStockItemResolver, len(r.report.LowStockItems))
+	for i := range r.report.LowStockItems {
+		items[i] = &lowStockItemResolver{shop: r.shop, item: &r.report.LowStockItems[i]}
+	}
+	return items
+}
+
+type lowStockItemResolver struct {
+	shop *businessResolver
+	item *Domain.LowStockItem
+}
+
+func (r *lowStockItemResolver) Product(ctx context.Context) (*productResolver, error) {
+	return r.shop.loadProduct(ctx, r.item.ProductID)
+}
+
+func (r *lowStockItemResolver) ProductName() string {
+	return r.item.ProductName
+}
+
+func (r *lowStockItemResolver) Current() float64 {
+	return r.item.Current
+}
+
+func (r *lowStockItemResolver) Minimum() float64 {
+	return r.item.Minimum
+}
+
+func (r *lowStockItemResolver) Difference() float64 {
+	return r.item.Difference
+}
@@ -0,0 +1,239 @@
+package graphqlapi
+
+import (
+	"context"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	graphql "github.com/graph-gophers/graphql-go"
+)
+
+type salesArgs struct {
+	First         *int32
+	After         *string
+	Sort          *string
+	From          *graphql.Time
+	To            *graphql.Time
+	Status        *string
+	PaymentMethod *string
+	PaymentStatus *string
+	EmployeeID    *graphql.ID
+}
+
+func (b *businessResolver) Sales(ctx context.Context, args salesArgs) (*saleConnection, error) {
+	p, err := page(Domain.SaleSorts, "-created_at", args.First, args.After, args.Sort)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusBadRequest, err)
+	}
+
+	filters := Domain.SaleFilters{
+		Limit: p.Limit,
+		Sort:  p.Sort,
+		After: p.After,
+	}
+	if args.From != nil {
+		filters.StartDate = &args.From.Time
+	}
+	if args.To != nil {
+		filters.EndDate = &args.To.Time
+	}
+	if args.Status != nil {
+		status := Domain.SaleStatus(*args.Status)
+		filters.Status = &status
+	}
+	if args.PaymentMethod != nil {
+		method := Domain.PaymentMethod(*args.PaymentMethod)
+		filters.PaymentMethod = &method
+	}
+	if args.PaymentStatus != nil {
+		status := Domain.PaymentStatus(*args.PaymentStatus)
+		filters.PaymentStatus = &status
+	}
+	if args.EmployeeID != nil {
+		employeeID := string(*args.EmployeeID)
+		filters.EmployeeID = &employeeID
+	}
+
+	sales, err := b.svc.sales.GetSales(b.business.ID.Hex(), filters)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+
+	connection := &saleConnection{shop: b, filters: filters}
+	var last Domain.Sortable
+	for i := range sales {
+		connection.nodes = append(connection.nodes, &saleResolver{shop: b, sale: &sales[i]})
+		last = &sales[i]
+	}
+	connection.pageInfo.endCursor = Infrastructure.NextCursor(p, len(sales), last)
+	return connection, nil
+}
+
+func (b *businessResolver) Sale(ctx context.Context, args struct{ ID graphql.ID }) (*saleResolver, error) {
+	sale, err := b.svc.sales.GetSaleByID(b.scoped(ctx), string(args.ID), b.business.ID.Hex())
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusNotFound, err)
+	}
+	return &saleResolver{shop: b, sale: sale}, nil
+}
+
+type saleConnection struct {
+	shop     *businessResolver
+	filters  Domain.SaleFilters
+	nodes    []*saleResolver
+	pageInfo pageInfo
+}
+
+func (c *saleConnection) Nodes() []*saleResolver {
+	return c.nodes
+}
+
+func (c *saleConnection) PageInfo() *pageInfo {
+	return &c.pageInfo
+}
+
+func (c *saleConnection) TotalCount(ctx context.Context) (int32, error) {
+	total, err := c.shop.svc.sales.CountSales(c.shop.business.ID.Hex(), c.filters)
+	if err != nil {
+		return 0, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	return int32(total), nil
+}
+
+type saleResolver struct {
+	shop *businessResolver
+	sale *Domain.Sale
+}
+
+func (r *saleResolver) ID() graphql.ID {
+	return graphql.ID(r.sale.ID.Hex())
+}
+
+func (r *saleResolver) LocalId() *string {
+	return optional(r.sale.LocalID)
+}
+
+func (r *saleResolver) Product(ctx context.Context) (*productResolver, error) {
+	if r.sale.ProductID == nil {
+		return nil, nil
+	}
+	return r.shop.loadProduct(ctx, r.sale.ProductID.Hex())
+}
+
+func (r *saleResolver) Customer(ctx context.Context) (*customerResolver, error) {
+	if r.sale.CustomerPhone == "" {
+		return nil, nil
+	}
+	customer, err := r.shop.customers.Load(r.sale.CustomerPhone)
+	if err != nil {
+		return nil, Infrastructure.GraphQLError(ctx, http.StatusInternalServerError, err)
+	}
+	if customer == nil {
+		return nil, nil
+	}
+	return &customerResolver{shop: r.shop, customer: customer}, nil
+}
+
+func (r *saleResolver) CustomerName() *string {
+	return optional(r.sale.CustomerName)
+}
+
+func (r *saleResolver) CustomerPhone() *string {
+	return optional(r.sale.CustomerPhone)
+}
+
+func (r *saleResolver) Quantity() float64 {
+	return r.sale.Quantity
+}
+
+func (r *saleResolver) UnitPrice() float64 {
+	return r.sale.UnitPrice.Float64()
+}
+
+func (r *saleResolver) TotalAmount() float64 {
+	return r.sale.TotalAmount.Float64()
+}
+
+func (r *saleResolver) Discount() float64 {
+	return r.sale.Discount.Float64()
+}
+
+func (r *saleResolver) Tax() float64 {
+	return r.sale.Tax.Float64()
+}
+
+func (r *saleResolver) FinalAmount() float64 {
+	return r.sale.FinalAmount.Float64()
+}
+
+func (r *saleResolver) UnitCost(ctx context.Context) (*float64, error) {
+	if err := r.shop.ownersOnly(ctx); err != nil {
+		return nil, err
+	}
+	return optionalAmount(r.sale.UnitCost.Float64()), nil
+}
+
+func (r *saleResolver) PaymentMethod() string {
+	return string(r.sale.PaymentMethod)
+}
+
+func (r *saleResolver) PaymentStatus() string {
+	return string(r.sale.PaymentStatus)
+}
+
+func (r *saleResolver) Payments() []*salePaymentResolver {
+	payments := make([]*salePaymentResolver, len(r.sale.Payments))
+	for i := range r.sale.Payments {
+		payments[i] = &salePaymentResolver{payment: &r.sale.Payments[i]}
+	}
+	return payments
+}
+
+func (r *saleResolver) Notes() *string {
+	return optional(r.sale.Notes)
+}
+
+func (r *saleResolver) EmployeeId() *graphql.ID {
+	if r.sale.EmployeeID == nil {
+		return nil
+	}
+	id := graphql.ID(r.sale.EmployeeID.Hex())
+	return &id
+}
+
+func (r *saleResolver) DeviceId() *string {
+	return optional(r.sale.DeviceID)
+}
+
+func (r *saleResolver) Status() string {
+	return string(r.sale.Status)
+}
+
+func (r *saleResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.sale.CreatedAt}
+}
+
+func (r *saleResolver) VoidedAt() *graphql.Time {
+	if r.sale.VoidedAt == nil {
+		return nil
+	}
+	return &graphql.Time{Time: *r.sale.VoidedAt}
+}
+
+type salePaymentResolver struct {
+	payment *Domain.SalePayment
+}
+
+func (r *salePaymentResolver) Method() string {
+	return string(r.payment.Method)
+}
+
+func (r *salePaymentResolver) Amount() float64 {
+	return r.payment.Amount.Float64()
+}
+
+func (r *salePaymentResolver) Reference() *string {
+	return optional(r.payment.Reference)
+}
@@ -0,0 +1,38 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+)
+
+type businessServer struct {
+	pb.UnimplementedBusinessServiceServer
+	businessUC Usecases.BusinessUseCase
+}
+
+func (s *businessServer) ListBusinesses(ctx context.Context, req *pb.ListBusinessesRequest) (*pb.ListBusinessesResponse, error) {
+	principal, _ := Infrastructure.PrincipalFromContext(ctx)
+
+	businesses, err := s.businessUC.GetUserBusinesses(principal.UserID)
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resp := &pb.ListBusinessesResponse{Businesses: make([]*pb.Business, len(businesses))}
+	for i := range businesses {
+		resp.Businesses[i] = toBusiness(&businesses[i])
+	}
+	return resp, nil
+}
+
+func (s *businessServer) GetBusiness(ctx context.Context, req *pb.GetBusinessRequest) (*pb.Business, error) {
+	business, err := s.businessUC.GetBusinessByID(req.GetBusinessId())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusNotFound, err)
+	}
+	return toBusiness(business), nil
+}
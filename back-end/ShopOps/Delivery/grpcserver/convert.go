@@ -0,0 +1,174 @@
+package grpcserver
+
+import (
+	"time"
+
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Domain models become protobuf messages here; IDs are hex strings and unset
+// optional IDs and times are left empty
+
+func toBusiness(b *Domain.Business) *pb.Business {
+	return &pb.Business{
+		Id:           b.ID.Hex(),
+		OwnerId:      b.UserID.Hex(),
+		Name:         b.Name,
+		Description:  b.Description,
+		BusinessType: b.BusinessType,
+		Currency:     b.Currency,
+		Timezone:     b.Timezone,
+		Address:      b.Address,
+		City:         b.City,
+		Country:      b.Country,
+		Phone:        b.Phone,
+		Email:        b.Email,
+		Status:       string(b.Status),
+		CreatedAt:    timestamp(b.CreatedAt),
+		UpdatedAt:    timestamp(b.UpdatedAt),
+	}
+}
+
+func toSale(s *Domain.Sale) *pb.Sale {
+	sale := &pb.Sale{
+		Id:            s.ID.Hex(),
+		BusinessId:    s.BusinessID.Hex(),
+		LocalId:       s.LocalID,
+		ProductId:     optionalID(s.ProductID),
+		CustomerName:  s.CustomerName,
+		CustomerPhone: s.CustomerPhone,
+		Quantity:      s.Quantity,
+		UnitPrice:     s.UnitPrice.Float64(),
+		TotalAmount:   s.TotalAmount.Float64(),
+		Discount:      s.Discount.Float64(),
+		Tax:           s.Tax.Float64(),
+		FinalAmount:   s.FinalAmount.Float64(),
+		PaymentMethod: string(s.PaymentMethod),
+		PaymentStatus: string(s.PaymentStatus),
+		Notes:         s.Notes,
+		EmployeeId:    optionalID(s.EmployeeID),
+		DeviceId:      s.DeviceID,
+		Status:        string(s.Status),
+		CreatedBy:     s.CreatedBy.Hex(),
+		CreatedAt:     timestamp(s.CreatedAt),
+		UpdatedAt:     timestamp(s.UpdatedAt),
+		UnitCost:      s.UnitCost.Float64(),
+	}
+	if s.VoidedAt != nil {
+		sale.VoidedAt = timestamp(*s.VoidedAt)
+	}
+	for _, payment := range s.Payments {
+		sale.Payments = append(sale.Payments, &pb.SalePayment{
+			Method:    string(payment.Method),
+			Amount:    payment.Amount.Float64(),
+			Reference: payment.Reference,
+		})
+	}
+	return sale
+}
+
+func toProduct(p *Domain.Product) *pb.Product {
+	return &pb.Product{
+		Id:           p.ID.Hex(),
+		BusinessId:   p.BusinessID.Hex(),
+		Name:         p.Name,
+		Description:  p.Description,
+		Sku:          p.SKU,
+		Barcode:      p.Barcode,
+		Category:     p.Category,
+		Unit:         p.Unit,
+		CostPrice:    p.CostPrice.Float64(),
+		SellingPrice: p.SellingPrice.Float64(),
+		Stock:        p.Stock,
+		MinStock:     p.MinStock,
+		MaxStock:     p.MaxStock,
+		ImageUrl:     p.ImageURL,
+		Status:       string(p.Status),
+		Version:      p.Version,
+		CreatedAt:    timestamp(p.CreatedAt),
+		UpdatedAt:    timestamp(p.UpdatedAt),
+	}
+}
+
+func toExpense(e *Domain.Expense) *pb.Expense {
+	return &pb.Expense{
+		Id:          e.ID.Hex(),
+		BusinessId:  e.BusinessID.Hex(),
+		LocalId:     e.LocalID,
+		Category:    string(e.Category),
+		Amount:      e.Amount.Float64(),
+		Description: e.Description,
+		ReceiptUrl:  e.ReceiptURL,
+		RecurringId: optionalID(e.RecurringID),
+		Date:        timestamp(e.Date),
+		Status:      string(e.Status),
+		CreatedAt:   timestamp(e.CreatedAt),
+		UpdatedAt:   timestamp(e.UpdatedAt),
+	}
+}
+
+func toSyncResults(results []Domain.SyncResult) []*pb.SyncResult {
+	out := make([]*pb.SyncResult, len(results))
+	for i, result := range results {
+		out[i] = &pb.SyncResult{
+			LocalId:   result.LocalID,
+			ServerId:  result.ServerID,
+			Success:   result.Success,
+			Error:     result.Error,
+			Timestamp: timestamp(result.Timestamp),
+		}
+	}
+	return out
+}
+
+// fromSyncItem reads a sync item as the REST API decodes it from JSON, so the usecase
+// sees the same map of fields either way
+func fromSyncItem(item *pb.SyncItem) Domain.SyncItem {
+	var data interface{}
+	if item.GetData() != nil {
+		data = item.GetData().AsMap()
+	}
+	return Domain.SyncItem{
+		ID:         item.GetId(),
+		LocalID:    item.GetLocalId(),
+		Operation:  Domain.SyncOperation(item.GetOperation()),
+		EntityType: item.GetEntityType(),
+		Data:       data,
+		CreatedAt:  fromTimestamp(item.GetCreatedAt()),
+		UpdatedAt:  fromTimestamp(item.GetUpdatedAt()),
+	}
+}
+
+func timestamp(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+	return timestamppb.New(t)
+}
+
+func fromTimestamp(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+// optionalTime is nil for an unset timestamp, as filters expect
+func optionalTime(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+	t := ts.AsTime()
+	return &t
+}
+
+func optionalID(id *primitive.ObjectID) string {
+	if id == nil {
+		return ""
+	}
+	return id.Hex()
+}
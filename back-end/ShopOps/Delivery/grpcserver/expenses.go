@@ -0,0 +1,52 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+)
+
+// Expenses have no cursor sorts yet, so they page by offset as the REST list does
+const maxExpensePageSize = 500
+
+type expenseServer struct {
+	pb.UnimplementedExpenseServiceServer
+	expenseUC Usecases.ExpenseUseCase
+}
+
+func (s *expenseServer) ListExpenses(ctx context.Context, req *pb.ListExpensesRequest) (*pb.ListExpensesResponse, error) {
+	filters := Domain.ExpenseFilters{
+		StartDate: optionalTime(req.GetStartDate()),
+		EndDate:   optionalTime(req.GetEndDate()),
+		Limit:     defaultPageSize,
+	}
+	if category := req.GetCategory(); category != "" {
+		expenseCategory := Domain.ExpenseCategory(category)
+		filters.Category = &expenseCategory
+	}
+	if status := req.GetStatus(); status != "" {
+		expenseStatus := Domain.ExpenseStatus(status)
+		filters.Status = &expenseStatus
+	}
+	if size := int(req.GetPageSize()); size > 0 {
+		filters.Limit = min(size, maxExpensePageSize)
+	}
+	if offset := int(req.GetOffset()); offset > 0 {
+		filters.Offset = offset
+	}
+
+	expenses, err := s.expenseUC.GetExpenses(req.GetBusinessId(), filters)
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resp := &pb.ListExpensesResponse{Expenses: make([]*pb.Expense, len(expenses))}
+	for i := range expenses {
+		resp.Expenses[i] = toExpense(&expenses[i])
+	}
+	return resp, nil
+}
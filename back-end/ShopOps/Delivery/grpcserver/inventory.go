@@ -0,0 +1,73 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+)
+
+type inventoryServer struct {
+	pb.UnimplementedInventoryServiceServer
+	inventoryUC Usecases.InventoryUseCase
+}
+
+func (s *inventoryServer) ListProducts(ctx context.Context, req *pb.ListProductsRequest) (*pb.ListProductsResponse, error) {
+	filters := Domain.ProductFilters{}
+	if category := req.GetCategory(); category != "" {
+		filters.Category = &category
+	}
+	if status := req.GetStatus(); status != "" {
+		productStatus := Domain.ProductStatus(status)
+		filters.Status = &productStatus
+	}
+	if req.GetLowStock() {
+		lowStock := true
+		filters.LowStock = &lowStock
+	}
+	if search := req.GetSearch(); search != "" {
+		filters.Search = &search
+	}
+
+	p, err := page(Domain.ProductSorts, req.GetSort(), "name", req.GetPageToken(), req.GetPageSize(), req.GetIncludeTotal())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusBadRequest, err)
+	}
+	filters.Limit = p.Limit
+	filters.Sort = p.Sort
+	filters.After = p.After
+
+	products, err := s.inventoryUC.GetProducts(req.GetBusinessId(), filters)
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resp := &pb.ListProductsResponse{Products: make([]*pb.Product, len(products))}
+	for i := range products {
+		resp.Products[i] = toProduct(&products[i])
+	}
+	if p.IncludeTotal {
+		if resp.TotalCount, err = s.inventoryUC.CountProducts(req.GetBusinessId(), filters); err != nil {
+			return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+		}
+	}
+
+	var last Domain.Sortable
+	if n := len(products); n > 0 {
+		last = &products[n-1]
+	}
+	resp.NextPageToken = Infrastructure.NextCursor(p, len(products), last)
+
+	return resp, nil
+}
+
+func (s *inventoryServer) GetProduct(ctx context.Context, req *pb.GetProductRequest) (*pb.Product, error) {
+	product, err := s.inventoryUC.GetProductByID(ctx, req.GetProductId(), req.GetBusinessId())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusNotFound, err)
+	}
+	return toProduct(product), nil
+}
@@ -0,0 +1,77 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+)
+
+type salesServer struct {
+	pb.UnimplementedSalesServiceServer
+	salesUC Usecases.SalesUseCase
+}
+
+func (s *salesServer) ListSales(ctx context.Context, req *pb.ListSalesRequest) (*pb.ListSalesResponse, error) {
+	filters := Domain.SaleFilters{
+		StartDate: optionalTime(req.GetStartDate()),
+		EndDate:   optionalTime(req.GetEndDate()),
+	}
+	if status := req.GetStatus(); status != "" {
+		saleStatus := Domain.SaleStatus(status)
+		filters.Status = &saleStatus
+	}
+	if paymentMethod := req.GetPaymentMethod(); paymentMethod != "" {
+		pm := Domain.PaymentMethod(paymentMethod)
+		filters.PaymentMethod = &pm
+	}
+	if paymentStatus := req.GetPaymentStatus(); paymentStatus != "" {
+		ps := Domain.PaymentStatus(paymentStatus)
+		filters.PaymentStatus = &ps
+	}
+	if employeeID := req.GetEmployeeId(); employeeID != "" {
+		filters.EmployeeID = &employeeID
+	}
+
+	p, err := page(Domain.SaleSorts, req.GetSort(), "-created_at", req.GetPageToken(), req.GetPageSize(), req.GetIncludeTotal())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusBadRequest, err)
+	}
+	filters.Limit = p.Limit
+	filters.Sort = p.Sort
+	filters.After = p.After
+
+	sales, err := s.salesUC.GetSales(req.GetBusinessId(), filters)
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resp := &pb.ListSalesResponse{Sales: make([]*pb.Sale, len(sales))}
+	for i := range sales {
+		resp.Sales[i] = toSale(&sales[i])
+	}
+	if p.IncludeTotal {
+		if resp.TotalCount, err = s.salesUC.CountSales(req.GetBusinessId(), filters); err != nil {
+			return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+		}
+	}
+
+	var last Domain.Sortable
+	if n := len(sales); n > 0 {
+		last = &sales[n-1]
+	}
+	resp.NextPageToken = Infrastructure.NextCursor(p, len(sales), last)
+
+	return resp, nil
+}
+
+func (s *salesServer) GetSale(ctx context.Context, req *pb.GetSaleRequest) (*pb.Sale, error) {
+	sale, err := s.salesUC.GetSaleByID(ctx, req.GetSaleId(), req.GetBusinessId())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusNotFound, err)
+	}
+	return toSale(sale), nil
+}
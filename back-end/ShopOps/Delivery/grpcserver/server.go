@@ -0,0 +1,61 @@
+// Package grpcserver serves the internal gRPC API, a low-latency alternative to REST
+// for services that run beside ShopOps. It calls the same usecases as the gin
+// controllers, behind interceptors that share the REST API's authentication, shop
+// access checks and rate limits.
+package grpcserver
+
+import (
+	app "ShopOps/Delivery/app"
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"google.golang.org/grpc"
+)
+
+//go:generate protoc -I proto --go_out=. --go_opt=module=ShopOps/Delivery/grpcserver --go-grpc_out=. --go-grpc_opt=module=ShopOps/Delivery/grpcserver shopops/v1/shopops.proto
+
+// Lists are paged as the REST API pages them by cursor
+const defaultPageSize = 50
+
+// NewServer registers the services on a gRPC server with the shared interceptors
+func NewServer(container *app.Container) *grpc.Server {
+	uc := container.UseCases
+
+	server := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		Infrastructure.GRPCLogging(),
+		Infrastructure.GRPCAuth(container.JWT),
+		// As POST /sync/batch and GET /sync/status
+		Infrastructure.GRPCRateLimit(container.RateLimiter, map[string]Infrastructure.RateLimit{
+			pb.SyncService_ProcessBatch_FullMethodName:  Infrastructure.RateLimitRestore,
+			pb.SyncService_GetSyncStatus_FullMethodName: Infrastructure.RateLimitSync,
+		}),
+		Infrastructure.GRPCTenancy(Infrastructure.NewTenantResolver(container.Repos.Business, container.Repos.Employee)),
+		Infrastructure.GRPCRecovery(),
+	))
+
+	pb.RegisterBusinessServiceServer(server, &businessServer{businessUC: uc.Business})
+	pb.RegisterSalesServiceServer(server, &salesServer{salesUC: uc.Sales})
+	pb.RegisterInventoryServiceServer(server, &inventoryServer{inventoryUC: uc.Inventory})
+	pb.RegisterExpenseServiceServer(server, &expenseServer{expenseUC: uc.Expense})
+	pb.RegisterSyncServiceServer(server, &syncServer{
+		syncUC:  uc.Sync,
+		cache:   container.Cache,
+		search:  container.Search,
+		catalog: container.Catalog,
+	})
+
+	return server
+}
+
+// page reads a list request's paging as Infrastructure.ParsePage reads the query
+func page(sorts Domain.ListSorts, key, defaultSort, token string, size int32, includeTotal bool) (*Domain.Page, error) {
+	if key == "" {
+		key = defaultSort
+	}
+	limit := int(size)
+	if limit <= 0 {
+		limit = defaultPageSize
+	}
+	return Infrastructure.NewPage(sorts, key, token, limit, includeTotal)
+}
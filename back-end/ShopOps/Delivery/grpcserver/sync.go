@@ -0,0 +1,75 @@
+package grpcserver
+
+import (
+	"context"
+	"net/http"
+
+	pb "ShopOps/Delivery/grpcserver/pb"
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	Usecases "ShopOps/Usecases"
+)
+
+type syncServer struct {
+	pb.UnimplementedSyncServiceServer
+	syncUC  Usecases.SyncUseCase
+	cache   *Infrastructure.ResponseCache
+	search  *Infrastructure.SearchIndex
+	catalog *Infrastructure.CatalogCache
+}
+
+func (s *syncServer) ProcessBatch(ctx context.Context, req *pb.ProcessBatchRequest) (*pb.ProcessBatchResponse, error) {
+	batch := Domain.SyncBatch{
+		BusinessID: req.GetBusinessId(),
+		DeviceID:   req.GetDeviceId(),
+		Timestamp:  fromTimestamp(req.GetTimestamp()),
+		Items:      make([]Domain.SyncItem, len(req.GetItems())),
+	}
+	for i, item := range req.GetItems() {
+		batch.Items[i] = fromSyncItem(item)
+	}
+
+	response, err := s.syncUC.ProcessBatch(ctx, batch)
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusBadRequest, err)
+	}
+
+	// The batch may have touched anything, as the REST sync routes assume
+	s.cache.Invalidate(batch.BusinessID, Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock,
+		Infrastructure.CacheTagSales, Infrastructure.CacheTagExpenses)
+	s.search.Invalidate(batch.BusinessID)
+	s.catalog.Invalidate(batch.BusinessID)
+
+	return &pb.ProcessBatchResponse{
+		Success:    toSyncResults(response.Success),
+		Failed:     toSyncResults(response.Failed),
+		ServerTime: timestamp(response.ServerTime),
+	}, nil
+}
+
+func (s *syncServer) GetSyncStatus(ctx context.Context, req *pb.GetSyncStatusRequest) (*pb.SyncStatus, error) {
+	status, err := s.syncUC.GetSyncStatus(req.GetBusinessId())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+	}
+
+	return &pb.SyncStatus{
+		LastSync:    timestamp(status.LastSync),
+		Pending:     int32(status.Pending),
+		SyncedToday: int32(status.SyncedToday),
+		Total:       int32(status.Total),
+	}, nil
+}
+
+func (s *syncServer) GetLastSync(ctx context.Context, req *pb.GetLastSyncRequest) (*pb.GetLastSyncResponse, error) {
+	lastSync, err := s.syncUC.GetLastSync(req.GetBusinessId(), req.GetDeviceId())
+	if err != nil {
+		return nil, Infrastructure.GRPCError(ctx, http.StatusInternalServerError, err)
+	}
+
+	resp := &pb.GetLastSyncResponse{}
+	if lastSync != nil {
+		resp.LastSync = timestamp(*lastSync)
+	}
+	return resp, nil
+}
@@ -0,0 +1,147 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Result is one scenario's load run or one benchmark. Latencies are in milliseconds.
+type Result struct {
+	Name       string `json:"name"`
+	Operations int    `json:"operations"`
+
+	// Load runs
+	Errors     int     `json:"errors,omitempty"`
+	Skipped    int     `json:"skipped,omitempty"` // Starts dropped with every worker busy
+	Throughput float64 `json:"throughput,omitempty"`
+	P50        float64 `json:"p50_ms,omitempty"`
+	P95        float64 `json:"p95_ms,omitempty"`
+	P99        float64 `json:"p99_ms,omitempty"`
+	FirstError string  `json:"first_error,omitempty"`
+
+	// Benchmarks
+	NsPerOp     int64 `json:"ns_per_op,omitempty"`
+	BytesPerOp  int64 `json:"bytes_per_op,omitempty"`
+	AllocsPerOp int64 `json:"allocs_per_op,omitempty"`
+}
+
+func (r Result) String() string {
+	if r.NsPerOp > 0 {
+		return fmt.Sprintf("%-18s %10d ops %14d ns/op %12d B/op %10d allocs/op", r.Name, r.Operations, r.NsPerOp, r.BytesPerOp, r.AllocsPerOp)
+	}
+	line := fmt.Sprintf("%-18s %6d ops %4d errors %4d skipped %8.1f/s  p50 %8.1fms  p95 %8.1fms  p99 %8.1fms",
+		r.Name, r.Operations, r.Errors, r.Skipped, r.Throughput, r.P50, r.P95, r.P99)
+	if r.FirstError != "" {
+		line += "\n  first error: " + r.FirstError
+	}
+	return line
+}
+
+// Baseline is the results a release is held to, committed alongside it or kept by CI
+type Baseline struct {
+	RecordedAt time.Time         `json:"recorded_at"`
+	Results    map[string]Result `json:"results"`
+}
+
+type baselineFlags struct {
+	path      *string
+	save      *bool
+	tolerance *float64
+}
+
+func addBaselineFlags(flags *flag.FlagSet, path string) baselineFlags {
+	return baselineFlags{
+		path:      flags.String("baseline", path, "Baseline file to compare with or save to"),
+		save:      flags.Bool("save", false, "Save the results as the baseline instead of comparing"),
+		tolerance: flags.Float64("tolerance", 0.2, "How much worse than the baseline a result may be, as a fraction"),
+	}
+}
+
+// apply saves the results as the baseline, or compares them with it and fails if
+// any regressed. Without a baseline file yet, the results are only reported.
+func (f baselineFlags) apply(results map[string]Result) error {
+	if *f.save {
+		data, err := json.MarshalIndent(Baseline{RecordedAt: time.Now().UTC(), Results: results}, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(*f.path, data, 0o644); err != nil {
+			return err
+		}
+		log.Printf("Saved the baseline to %s", *f.path)
+		return nil
+	}
+
+	data, err := os.ReadFile(*f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		log.Printf("No baseline at %s to compare with; -save records one", *f.path)
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", *f.path, err)
+	}
+
+	regressions := compare(baseline, results, *f.tolerance)
+	if len(regressions) > 0 {
+		return fmt.Errorf("regressed against the baseline of %s:\n  %s",
+			baseline.RecordedAt.Format(time.DateOnly), strings.Join(regressions, "\n  "))
+	}
+	log.Printf("Within %.0f%% of the baseline of %s", *f.tolerance*100, baseline.RecordedAt.Format(time.DateOnly))
+	return nil
+}
+
+// compare lists how each result is worse than its baseline beyond the tolerance:
+// slower at the 95th percentile or per operation, more allocation, or failing or
+// falling behind more often
+func compare(baseline Baseline, results map[string]Result, tolerance float64) []string {
+	var regressions []string
+	worse := func(name, measure string, was, now float64) {
+		if was > 0 && now > was*(1+tolerance) {
+			regressions = append(regressions, fmt.Sprintf("%s %s: %.1f, was %.1f (+%.0f%%)", name, measure, now, was, (now/was-1)*100))
+		}
+	}
+
+	names := make([]string, 0, len(results))
+	for name := range results {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		now, was := results[name], baseline.Results[name]
+		if was.Name == "" {
+			continue
+		}
+		worse(name, "p95 ms", was.P95, now.P95)
+		worse(name, "ns/op", float64(was.NsPerOp), float64(now.NsPerOp))
+		worse(name, "B/op", float64(was.BytesPerOp), float64(now.BytesPerOp))
+		worse(name, "allocs/op", float64(was.AllocsPerOp), float64(now.AllocsPerOp))
+
+		if now.Operations > 0 && now.Errors > 0 && was.Errors == 0 {
+			regressions = append(regressions, fmt.Sprintf("%s: %d errors, none before; first: %s", name, now.Errors, now.FirstError))
+		}
+		if rate, wasRate := skipRate(now), skipRate(was); rate > wasRate+tolerance/4 {
+			regressions = append(regressions, fmt.Sprintf("%s: %.0f%% of starts skipped, was %.0f%%", name, rate*100, wasRate*100))
+		}
+	}
+	return regressions
+}
+
+// skipRate is the share of starts dropped for want of a free worker
+func skipRate(r Result) float64 {
+	if r.Operations+r.Skipped == 0 {
+		return 0
+	}
+	return float64(r.Skipped) / float64(r.Operations+r.Skipped)
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"sort"
+	"testing"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+// benchmark is the in-process part of a path, without the network or database, so
+// a regression in the server's own code shows up apart from noise in the load run
+type benchmark struct {
+	name string
+	fn   func(b *testing.B)
+}
+
+// Sizes of the data the benchmarks work on, those of a large shop
+const (
+	benchSyncItems    = 500
+	benchCatalogSize  = 20000
+	benchExportedRows = 50000
+)
+
+var benchmarks = map[string]benchmark{
+	// Decoding the largest sync upload the server takes
+	"sync_decode": {name: "sync_decode", fn: func(b *testing.B) {
+		gen := newGenerator(1)
+		body, err := json.Marshal(Domain.SyncBatch{
+			BusinessID: "000000000000000000000000",
+			DeviceID:   "loadtest",
+			Timestamp:  time.Now(),
+			Items:      gen.sales(benchSyncItems, []string{"000000000000000000000001"}),
+		})
+		if err != nil {
+			b.Fatal(err)
+		}
+
+		b.SetBytes(int64(len(body)))
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			var batch Domain.SyncBatch
+			if err := json.Unmarshal(body, &batch); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}},
+
+	// Loading a shop's catalog into memory after an invalidation, then a scan
+	"catalog_load": {name: "catalog_load", fn: func(b *testing.B) {
+		products := newGenerator(1).catalog(benchCatalogSize)
+		barcode := products[len(products)/2].Barcode
+		load := func(string) ([]Domain.Product, error) { return products, nil }
+		cache := Infrastructure.NewCatalogCache(time.Hour, 1)
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			cache.Invalidate("loadtest")
+			product, err := cache.ProductByBarcode("loadtest", barcode, load)
+			if err != nil || product == nil {
+				b.Fatal("product not found", err)
+			}
+		}
+	}},
+
+	// Writing a year of a busy shop's sales as CSV
+	"export_csv": {name: "export_csv", fn: func(b *testing.B) {
+		sales := newGenerator(1).saleRecords(benchExportedRows)
+		exports := Infrastructure.NewExportService()
+
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			if _, err := exports.ExportToCSV(sales, Domain.ReportTypeSales, Domain.CalendarGregorian, Domain.LanguageEnglish, time.UTC, nil); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}},
+}
+
+func benchmarkNames() []string {
+	names := make([]string, 0, len(benchmarks))
+	for name := range benchmarks {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func runBenchmark(bm benchmark) Result {
+	result := testing.Benchmark(bm.fn)
+	return Result{
+		Name:        bm.name,
+		Operations:  result.N,
+		NsPerOp:     result.NsPerOp(),
+		BytesPerOp:  result.AllocedBytesPerOp(),
+		AllocsPerOp: result.AllocsPerOp(),
+	}
+}
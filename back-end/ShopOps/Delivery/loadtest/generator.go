@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// generator makes synthetic but plausible shop data, the same for the same seed, so
+// two runs against the same baseline upload and export comparable records
+type generator struct {
+	rand *rand.Rand
+	next int // For local IDs unique across batches
+}
+
+var (
+	productCategories = []string{"Beverages", "Grains", "Dairy", "Household", "Snacks", "Spices", "Stationery", "Cosmetics"}
+	productUnits      = []string{"pcs", "kg", "l", "pack"}
+	paymentMethods    = []Domain.PaymentMethod{Domain.PaymentMethodCash, Domain.PaymentMethodCash, Domain.PaymentMethodMobile, Domain.PaymentMethodCard}
+)
+
+func newGenerator(seed int64) *generator {
+	return &generator{rand: rand.New(rand.NewSource(seed))}
+}
+
+func (g *generator) localID(prefix string) string {
+	g.next++
+	return fmt.Sprintf("loadtest-%s-%d-%d", prefix, g.rand.Int63(), g.next)
+}
+
+// price is a shelf price from 5 to about 2000 birr, in steps of 25 cents
+func (g *generator) price() Domain.Money {
+	return Domain.Money(500 + g.rand.Int63n(8000)*25)
+}
+
+// products are sync items creating n catalog products
+func (g *generator) products(n int) []Domain.SyncItem {
+	now := time.Now()
+	items := make([]Domain.SyncItem, n)
+	for i, product := range g.catalog(n) {
+		items[i] = Domain.SyncItem{
+			LocalID:    g.localID("product"),
+			Operation:  Domain.SyncOperationCreate,
+			EntityType: "product",
+			Data: map[string]interface{}{
+				"name":          product.Name,
+				"sku":           product.SKU,
+				"barcode":       product.Barcode,
+				"category":      product.Category,
+				"unit":          product.Unit,
+				"cost_price":    product.CostPrice.Float64(),
+				"selling_price": product.SellingPrice.Float64(),
+				"stock":         product.Stock,
+				"min_stock":     product.MinStock,
+				"status":        string(product.Status),
+				"version":       1,
+			},
+			CreatedAt: now,
+			UpdatedAt: now,
+		}
+	}
+	return items
+}
+
+// sales are sync items creating n sales of the given products, by the product's
+// server ID
+func (g *generator) sales(n int, productIDs []string) []Domain.SyncItem {
+	now := time.Now()
+	items := make([]Domain.SyncItem, n)
+	for i, sale := range g.saleRecords(n) {
+		data := map[string]interface{}{
+			"quantity":       sale.Quantity,
+			"unit_price":     sale.UnitPrice.Float64(),
+			"total_amount":   sale.TotalAmount.Float64(),
+			"final_amount":   sale.FinalAmount.Float64(),
+			"payment_method": string(sale.PaymentMethod),
+			"payment_status": string(sale.PaymentStatus),
+			"status":         string(sale.Status),
+		}
+		if len(productIDs) > 0 {
+			data["product_id"] = productIDs[g.rand.Intn(len(productIDs))]
+		}
+		items[i] = Domain.SyncItem{
+			LocalID:    g.localID("sale"),
+			Operation:  Domain.SyncOperationCreate,
+			EntityType: "sale",
+			Data:       data,
+			CreatedAt:  now,
+			UpdatedAt:  now,
+		}
+	}
+	return items
+}
+
+// catalog is n products as the server stores them, for the in-process benchmarks
+func (g *generator) catalog(n int) []Domain.Product {
+	products := make([]Domain.Product, n)
+	for i := range products {
+		selling := g.price()
+		products[i] = Domain.Product{
+			ID:           primitive.NewObjectID(),
+			Name:         fmt.Sprintf("Product %d", i+1),
+			SKU:          fmt.Sprintf("SKU-%06d", i+1),
+			Barcode:      fmt.Sprintf("2%012d", g.rand.Int63n(1e12)),
+			Category:     productCategories[g.rand.Intn(len(productCategories))],
+			Unit:         productUnits[g.rand.Intn(len(productUnits))],
+			CostPrice:    selling * 3 / 4,
+			SellingPrice: selling,
+			Stock:        float64(g.rand.Intn(500)),
+			MinStock:     float64(5 + g.rand.Intn(20)),
+			Status:       Domain.ProductStatusActive,
+			Version:      1,
+		}
+	}
+	return products
+}
+
+// saleRecords is n sales over the last year as the server stores them, for the
+// in-process benchmarks
+func (g *generator) saleRecords(n int) []Domain.Sale {
+	now := time.Now()
+	sales := make([]Domain.Sale, n)
+	for i := range sales {
+		quantity := float64(1 + g.rand.Intn(5))
+		price := g.price()
+		total := price.Times(quantity)
+		sales[i] = Domain.Sale{
+			ID:            primitive.NewObjectID(),
+			Quantity:      quantity,
+			UnitPrice:     price,
+			TotalAmount:   total,
+			FinalAmount:   total,
+			PaymentMethod: paymentMethods[g.rand.Intn(len(paymentMethods))],
+			PaymentStatus: Domain.PaymentStatusPaid,
+			Status:        Domain.SaleStatusCompleted,
+			CreatedAt:     now.Add(-time.Duration(g.rand.Int63n(int64(365 * 24 * time.Hour)))),
+		}
+	}
+	return sales
+}
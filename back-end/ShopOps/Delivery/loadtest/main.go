@@ -0,0 +1,168 @@
+// Command loadtest drives the paths that slow down first as shops grow: sync uploads,
+// catalog downloads and report exports. It seeds a server with a synthetic shop,
+// puts load on the paths over HTTP, benchmarks the same code in process, and
+// compares each result with a saved baseline, failing when one regressed.
+//
+//	go run ./Delivery/loadtest seed -url http://localhost:8080 -products 5000 -sales 50000
+//	go run ./Delivery/loadtest seed -demo -admin-token $TOKEN -products 5000 -sales 180000 -days 365
+//	go run ./Delivery/loadtest run -rate 20 -duration 30s -baseline loadtest-baseline.json
+//	go run ./Delivery/loadtest bench -baseline bench-baseline.json
+//
+// The server under load must be started with RATE_LIMITS=false, or the limits turn
+// most requests away. run and bench take -save to record their results as the new
+// baseline instead of comparing.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"syscall"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+func main() {
+	if len(os.Args) < 2 {
+		usage()
+	}
+
+	// Importing the server's packages routes the standard logger through its JSON
+	// logger; plain lines read better at a terminal
+	log.SetOutput(os.Stderr)
+	log.SetFlags(log.Ltime)
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	var err error
+	switch os.Args[1] {
+	case "seed":
+		err = seedCommand(ctx, os.Args[2:])
+	case "run":
+		err = runCommand(ctx, os.Args[2:])
+	case "bench":
+		err = benchCommand(os.Args[2:])
+	default:
+		usage()
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+func usage() {
+	fmt.Fprintln(os.Stderr, "usage: loadtest seed|run|bench [flags]; loadtest <command> -h for flags")
+	os.Exit(2)
+}
+
+func seedCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("seed", flag.ExitOnError)
+	url := flags.String("url", "http://localhost:8080", "Server to seed")
+	state := flags.String("state", "loadtest-shop.json", "File to write the seeded shop's details to")
+	products := flags.Int("products", 5000, "Products in the catalog")
+	sales := flags.Int("sales", 50000, "Sales to upload")
+	seed := flags.Int64("seed", 1, "Random seed; the same seed generates the same data")
+	demo := flags.Bool("demo", false, "Have the server generate a demo shop with sales history instead of uploading one")
+	adminToken := flags.String("admin-token", os.Getenv("LOADTEST_ADMIN_TOKEN"), "Admin's token, for -demo")
+	days := flags.Int("days", 90, "Days of sales history, for -demo; -sales is then spread over them")
+	flags.Parse(args)
+
+	if *demo {
+		if *adminToken == "" {
+			return fmt.Errorf("-demo needs -admin-token or LOADTEST_ADMIN_TOKEN")
+		}
+		if *days <= 0 {
+			return fmt.Errorf("-days must be positive")
+		}
+		req := Domain.CreateDemoRequest{Products: *products, Days: *days, SalesPerDay: max(1, *sales / *days), KeepDays: 30, Seed: *seed}
+		shop, err := seedDemoShop(ctx, *url, *adminToken, req)
+		if err != nil {
+			return err
+		}
+		if err := shop.save(*state); err != nil {
+			return err
+		}
+		log.Printf("Seeded demo business %s with %d products and about %d sales over %d days; details in %s",
+			shop.BusinessID, *products, req.SalesPerDay*req.Days, req.Days, *state)
+		return nil
+	}
+
+	shop, err := seedShop(ctx, *url, newGenerator(*seed), *products, *sales)
+	if err != nil {
+		return err
+	}
+	if err := shop.save(*state); err != nil {
+		return err
+	}
+	log.Printf("Seeded business %s with %d products and %d sales; details in %s", shop.BusinessID, *products, *sales, *state)
+	return nil
+}
+
+func runCommand(ctx context.Context, args []string) error {
+	flags := flag.NewFlagSet("run", flag.ExitOnError)
+	state := flags.String("state", "loadtest-shop.json", "Shop written by seed")
+	only := flags.String("scenarios", strings.Join(scenarioNames(), ","), "Scenarios to run, comma separated")
+	rate := flags.Int("rate", 10, "Operations started per second in each scenario")
+	duration := flags.Duration("duration", 30*time.Second, "How long each scenario runs")
+	workers := flags.Int("workers", 50, "Most operations in flight at once; starts beyond it are counted as skipped")
+	batch := flags.Int("batch", 100, "Sales per sync upload")
+	seed := flags.Int64("seed", 2, "Random seed for the uploaded sales")
+	baselineFlags := addBaselineFlags(flags, "loadtest-baseline.json")
+	flags.Parse(args)
+	if *rate <= 0 || *workers <= 0 || *batch <= 0 {
+		return fmt.Errorf("-rate, -workers and -batch must be positive")
+	}
+
+	shop, err := loadShop(*state)
+	if err != nil {
+		return err
+	}
+	client, err := shop.login(ctx)
+	if err != nil {
+		return err
+	}
+
+	env := &scenarioEnv{client: client, shop: shop, gen: newGenerator(*seed), batch: *batch}
+	results := make(map[string]Result)
+	for _, name := range strings.Split(*only, ",") {
+		scenario, ok := scenarios[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown scenario %q; have %s", name, strings.Join(scenarioNames(), ", "))
+		}
+		log.Printf("Running %s at %d/s for %s", scenario.name, *rate, *duration)
+		result := attack(ctx, scenario, env, *rate, *duration, *workers)
+		log.Print(result)
+		results[scenario.name] = result
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+	}
+
+	return baselineFlags.apply(results)
+}
+
+func benchCommand(args []string) error {
+	flags := flag.NewFlagSet("bench", flag.ExitOnError)
+	only := flags.String("benchmarks", strings.Join(benchmarkNames(), ","), "Benchmarks to run, comma separated")
+	baselineFlags := addBaselineFlags(flags, "bench-baseline.json")
+	flags.Parse(args)
+
+	results := make(map[string]Result)
+	for _, name := range strings.Split(*only, ",") {
+		benchmark, ok := benchmarks[strings.TrimSpace(name)]
+		if !ok {
+			return fmt.Errorf("unknown benchmark %q; have %s", name, strings.Join(benchmarkNames(), ", "))
+		}
+		result := runBenchmark(benchmark)
+		log.Print(result)
+		results[benchmark.name] = result
+	}
+
+	return baselineFlags.apply(results)
+}
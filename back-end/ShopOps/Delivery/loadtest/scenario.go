@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"sync"
+	"time"
+)
+
+// scenario is one operation a device or owner performs, timed from start to finish
+type scenario struct {
+	name string
+	run  func(ctx context.Context, env *scenarioEnv) error
+}
+
+type scenarioEnv struct {
+	client *client
+	shop   *shop
+	batch  int
+
+	mu  sync.Mutex // The generator is not safe for concurrent use
+	gen *generator
+}
+
+var scenarios = map[string]scenario{
+	// A till uploading a batch of sales made offline
+	"sync_upload": {name: "sync_upload", run: func(ctx context.Context, env *scenarioEnv) error {
+		env.mu.Lock()
+		items := env.gen.sales(env.batch, env.shop.ProductIDs)
+		env.mu.Unlock()
+
+		response, err := env.shop.sync(ctx, env.client, items)
+		if err != nil {
+			return err
+		}
+		if len(response.Failed) > 0 {
+			return fmt.Errorf("%d of the batch failed to sync", len(response.Failed))
+		}
+		return nil
+	}},
+
+	// A till downloading the whole catalog, a page at a time, as after a reinstall
+	"catalog_download": {name: "catalog_download", run: func(ctx context.Context, env *scenarioEnv) error {
+		path := "/api/v1/businesses/" + env.shop.BusinessID + "/inventory/products?limit=500"
+		cursor := ""
+		for {
+			page := path
+			if cursor != "" {
+				page += "&cursor=" + url.QueryEscape(cursor)
+			}
+			header, err := env.client.do(ctx, "GET", page, nil, nil)
+			if err != nil {
+				return err
+			}
+			if cursor = header.Get("X-Next-Cursor"); cursor == "" {
+				return nil
+			}
+		}
+	}},
+
+	// The owner exporting the year's sales
+	"export": {name: "export", run: func(ctx context.Context, env *scenarioEnv) error {
+		_, err := env.client.do(ctx, "GET", "/api/v1/businesses/"+env.shop.BusinessID+"/reports/export?type=sales&period=yearly", nil, nil)
+		return err
+	}},
+}
+
+func scenarioNames() []string {
+	names := make([]string, 0, len(scenarios))
+	for name := range scenarios {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// attack starts the scenario rate times a second for the duration, whether or not
+// earlier runs have finished, as independent devices would, up to workers at once.
+// A start with every worker busy is skipped and counted, as the server is then
+// slower than the rate asked of it.
+func attack(ctx context.Context, s scenario, env *scenarioEnv, rate int, duration time.Duration, workers int) Result {
+	ctx, cancel := context.WithTimeout(ctx, duration)
+	defer cancel()
+
+	var (
+		mu        sync.Mutex
+		latencies []time.Duration
+		errors    int
+		firstErr  error
+		wg        sync.WaitGroup
+	)
+	slots := make(chan struct{}, workers)
+	skipped := 0
+
+	start := time.Now()
+	ticker := time.NewTicker(time.Second / time.Duration(rate))
+	defer ticker.Stop()
+loop:
+	for {
+		select {
+		case <-ctx.Done():
+			break loop
+		case <-ticker.C:
+		}
+
+		select {
+		case slots <- struct{}{}:
+		default:
+			skipped++
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer func() { <-slots; wg.Done() }()
+
+			// Runs in flight when the time is up finish rather than count as errors
+			began := time.Now()
+			err := s.run(context.WithoutCancel(ctx), env)
+			took := time.Since(began)
+
+			mu.Lock()
+			defer mu.Unlock()
+			latencies = append(latencies, took)
+			if err != nil {
+				errors++
+				if firstErr == nil {
+					firstErr = err
+				}
+			}
+		}()
+	}
+	wg.Wait()
+	elapsed := time.Since(start)
+
+	result := Result{
+		Name:       s.name,
+		Operations: len(latencies),
+		Errors:     errors,
+		Skipped:    skipped,
+		Throughput: float64(len(latencies)-errors) / elapsed.Seconds(),
+	}
+	if len(latencies) > 0 {
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+		result.P50 = percentile(latencies, 0.50)
+		result.P95 = percentile(latencies, 0.95)
+		result.P99 = percentile(latencies, 0.99)
+	}
+	if firstErr != nil {
+		result.FirstError = firstErr.Error()
+	}
+	return result
+}
+
+// percentile of latencies sorted ascending, in milliseconds
+func percentile(sorted []time.Duration, p float64) float64 {
+	i := int(float64(len(sorted)-1) * p)
+	return float64(sorted[i].Microseconds()) / 1000
+}
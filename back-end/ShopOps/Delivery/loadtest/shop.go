@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// shop is the seeded account, saved by seed for run to sign in with
+type shop struct {
+	URL        string   `json:"url"`
+	Phone      string   `json:"phone"`
+	Password   string   `json:"password"`
+	BusinessID string   `json:"business_id"`
+	DeviceID   string   `json:"device_id"`
+	ProductIDs []string `json:"product_ids"` // A sample, for uploaded sales to refer to
+}
+
+// Records per seeding upload, the most the server writes in one go
+const seedBatchSize = 500
+
+// Product IDs kept in the state file
+const maxSavedProductIDs = 1000
+
+// seedShop registers a user with a shop and fills it through the sync API, as a
+// till coming online with a backlog would. The server stamps synced records with
+// the time they arrive, so every seeded sale falls on the day it was seeded.
+func seedShop(ctx context.Context, url string, gen *generator, products, sales int) (*shop, error) {
+	s := &shop{
+		URL:      url,
+		Phone:    fmt.Sprintf("+2519%08d", gen.rand.Intn(1e8)),
+		Password: fmt.Sprintf("loadtest-%d", gen.rand.Int63()),
+		DeviceID: gen.localID("device"),
+	}
+
+	c := newClient(url)
+	register := Domain.RegisterRequest{Name: "Load Test", Phone: s.Phone, Password: s.Password}
+	if _, err := c.do(ctx, "POST", "/api/v1/auth/register", register, nil); err != nil {
+		return nil, fmt.Errorf("failed to register: %w", err)
+	}
+	c, err := s.login(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var business Domain.Business
+	create := Domain.CreateBusinessRequest{Name: "Load Test Shop", BusinessType: "retail", Currency: "ETB"}
+	if _, err := c.do(ctx, "POST", "/api/v1/businesses", create, &business); err != nil {
+		return nil, fmt.Errorf("failed to create business: %w", err)
+	}
+	s.BusinessID = business.ID.Hex()
+
+	productIDs, err := s.upload(ctx, c, gen.products(products))
+	if err != nil {
+		return nil, err
+	}
+	for i := 0; i < len(productIDs) && len(s.ProductIDs) < maxSavedProductIDs; i += max(1, len(productIDs)/maxSavedProductIDs) {
+		s.ProductIDs = append(s.ProductIDs, productIDs[i])
+	}
+	log.Printf("Uploaded %d products", len(productIDs))
+
+	for uploaded := 0; uploaded < sales; uploaded += seedBatchSize {
+		if _, err := s.upload(ctx, c, gen.sales(min(seedBatchSize, sales-uploaded), s.ProductIDs)); err != nil {
+			return nil, err
+		}
+	}
+	log.Printf("Uploaded %d sales", sales)
+
+	return s, nil
+}
+
+// seedDemoShop has the server generate a demo shop, which unlike an uploaded one
+// has sales spread over past days, so exports and reports cover real history. It
+// takes an admin's token and keeps the first page of products for uploaded sales.
+func seedDemoShop(ctx context.Context, url, adminToken string, req Domain.CreateDemoRequest) (*shop, error) {
+	admin := newClient(url)
+	admin.token = adminToken
+	var session Domain.DemoSession
+	if _, err := admin.do(ctx, "POST", "/api/v1/admin/demo-shops", req, &session); err != nil {
+		return nil, fmt.Errorf("failed to create demo shop: %w", err)
+	}
+
+	s := &shop{
+		URL:        url,
+		Phone:      session.Phone,
+		Password:   session.Password,
+		BusinessID: session.Business.ID.Hex(),
+		DeviceID:   fmt.Sprintf("loadtest-device-%d", req.Seed),
+	}
+	c := newClient(url)
+	c.token = session.Token
+
+	var products []Domain.Product
+	path := fmt.Sprintf("/api/v1/businesses/%s/inventory/products?limit=%d", s.BusinessID, min(maxSavedProductIDs, 500))
+	if _, err := c.do(ctx, "GET", path, nil, &products); err != nil {
+		return nil, fmt.Errorf("failed to list demo products: %w", err)
+	}
+	for _, product := range products {
+		s.ProductIDs = append(s.ProductIDs, product.ID.Hex())
+	}
+	return s, nil
+}
+
+// upload syncs the items in batches and returns the server IDs of those created
+func (s *shop) upload(ctx context.Context, c *client, items []Domain.SyncItem) ([]string, error) {
+	var ids []string
+	for start := 0; start < len(items); start += seedBatchSize {
+		response, err := s.sync(ctx, c, items[start:min(start+seedBatchSize, len(items))])
+		if err != nil {
+			return nil, err
+		}
+		if len(response.Failed) > 0 {
+			return nil, fmt.Errorf("%d of the batch failed to sync, the first: %s", len(response.Failed), response.Failed[0].Error)
+		}
+		for _, result := range response.Success {
+			if result.ServerID != "" {
+				ids = append(ids, result.ServerID)
+			}
+		}
+	}
+	return ids, nil
+}
+
+func (s *shop) sync(ctx context.Context, c *client, items []Domain.SyncItem) (*Domain.SyncResponse, error) {
+	batch := Domain.SyncBatch{BusinessID: s.BusinessID, DeviceID: s.DeviceID, Timestamp: time.Now(), Items: items}
+	var response Domain.SyncResponse
+	if _, err := c.do(ctx, "POST", "/api/v1/businesses/"+s.BusinessID+"/sync/batch", batch, &response); err != nil {
+		return nil, err
+	}
+	return &response, nil
+}
+
+func (s *shop) login(ctx context.Context) (*client, error) {
+	c := newClient(s.URL)
+	var login Domain.LoginResponse
+	if _, err := c.do(ctx, "POST", "/api/v1/auth/login", Domain.LoginRequest{Phone: s.Phone, Password: s.Password}, &login); err != nil {
+		return nil, fmt.Errorf("failed to sign in: %w", err)
+	}
+	c.token = login.Token
+	return c, nil
+}
+
+func (s *shop) save(path string) error {
+	data, err := json.MarshalIndent(s, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0o600)
+}
+
+func loadShop(path string) (*shop, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read the seeded shop, run seed first: %w", err)
+	}
+	var s shop
+	if err := json.Unmarshal(data, &s); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	return &s, nil
+}
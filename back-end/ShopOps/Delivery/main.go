@@ -1,17 +1,37 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log"
+	"net"
+	"net/http"
 	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+	// Shop timezones must resolve in containers without a zoneinfo database
+	_ "time/tzdata"
 
+	app "ShopOps/Delivery/app"
+	grpcserver "ShopOps/Delivery/grpcserver"
 	routers "ShopOps/Delivery/routers"
 	Infrastructure "ShopOps/Infrastructure"
 	_ "ShopOps/docs"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
 )
 
+//go:generate go run github.com/swaggo/swag/cmd/swag@v1.16.6 init --dir .. --generalInfo Delivery/main.go --output ../docs
+
 // @title           ShopOps Backend API
 // @version         1.0
 // @description     Backend system for shop operations management.
+// @description     The public API lives under /api/v1 and its OpenAPI 3 spec is served at /openapi.json.
+// @description     Version 1 only gains endpoints and optional fields; breaking changes will ship as /api/v2.
 // @termsOfService  http://swagger.io/terms/
 // @contact.name   API Support
 // @contact.email  support@shopops.com
@@ -21,25 +41,202 @@ import (
 // @securityDefinitions.apikey  BearerAuth
 // @in                          header
 // @name                        Authorization
+// @securityDefinitions.apikey  SupportKey
+// @in                          header
+// @name                        Authorization
 func main() {
+	cfg, err := Infrastructure.LoadConfig()
+	if err != nil {
+		log.Fatal(err)
+	}
+	Infrastructure.InitLogging(cfg.Logging)
+	gin.SetMode(cfg.Server.Mode)
+
 	// Initialize MongoDB
-	if err := Infrastructure.InitMongo(); err != nil {
+	if err := Infrastructure.InitMongo(cfg.Mongo); err != nil {
 		log.Fatalf("Failed to connect to database: %v", err)
 	}
 	defer Infrastructure.CloseMongo()
 
-	// Get port from environment
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	// shopops migrate up|down [n]|status
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := migrate(cfg.Mongo, os.Args[2:]); err != nil {
+			Infrastructure.CloseMongo()
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+	if err := migrateOnBoot(cfg.Mongo); err != nil {
+		log.Fatalf("Migration failed: %v", err)
+	}
+	if err := Infrastructure.InitReadReplicas(); err != nil {
+		log.Fatalf("Failed to connect to read replicas: %v", err)
 	}
+	defer Infrastructure.CloseReadReplicas()
 
-	// Setup router using GetDB()
-	router := routers.SetupRouter(Infrastructure.GetDB())
+	Infrastructure.InitTracing(cfg.Tracing)
+	Infrastructure.InitErrorReporting(cfg.ErrorReporting, cfg.Server.Mode)
+
+	// Wire the application's services, then serve them
+	container := app.New(cfg, Infrastructure.GetDB())
+	router := routers.SetupRouter(container)
+	container.Start()
+	server := Infrastructure.NewHTTPServer(cfg.Server, router)
 
 	// Start server
-	log.Printf("ShopOps Server starting on port %s", port)
-	if err := router.Run(":" + port); err != nil {
+	serverErr := make(chan error, 2)
+	go func() {
+		log.Printf("ShopOps Server starting on port %d", cfg.Server.Port)
+		if err := Infrastructure.ListenAndServe(server, cfg.Server); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			serverErr <- err
+		}
+	}()
+
+	// The internal gRPC API listens on its own port, when one is configured
+	var grpcServer *grpc.Server
+	if cfg.Server.GRPCPort > 0 {
+		listener, err := net.Listen("tcp", ":"+strconv.Itoa(cfg.Server.GRPCPort))
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		grpcServer = grpcserver.NewServer(container)
+		go func() {
+			log.Printf("ShopOps gRPC server starting on port %d", cfg.Server.GRPCPort)
+			if err := grpcServer.Serve(listener); err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+
+	stop, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	select {
+	case err := <-serverErr:
 		log.Fatalf("Failed to start server: %v", err)
+	case <-stop.Done():
+	}
+
+	shutdown(server, grpcServer, container, cfg.Server)
+}
+
+// shutdown fails readiness and tells background work to checkpoint, waits for load
+// balancers to notice, then drains in-flight requests and background work within
+// SHUTDOWN_TIMEOUT
+func shutdown(server *http.Server, grpcServer *grpc.Server, container *app.Container, cfg Infrastructure.ServerConfig) {
+	timeout := cfg.ShutdownTimeout
+	drainDelay := cfg.ShutdownDrainDelay
+	log.Printf("Shutting down: waiting %s for traffic to drain, deadline %s", drainDelay, timeout)
+
+	Infrastructure.BeginShutdown()
+	time.Sleep(drainDelay)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if err := server.Shutdown(ctx); err != nil {
+		log.Printf("Failed to drain HTTP requests: %v", err)
+	}
+	if grpcServer != nil {
+		stopped := make(chan struct{})
+		go func() {
+			grpcServer.GracefulStop()
+			close(stopped)
+		}()
+		select {
+		case <-stopped:
+		case <-ctx.Done():
+			log.Printf("Failed to drain gRPC calls: %v", ctx.Err())
+			grpcServer.Stop()
+		}
+	}
+	if err := Infrastructure.WaitForBackground(ctx); err != nil {
+		log.Printf("Failed to drain background work: %v", err)
+	}
+	container.Stop()
+	Infrastructure.ShutdownTracing(ctx)
+	Infrastructure.FlushErrorReports(ctx)
+
+	log.Printf("ShopOps Server stopped")
+}
+
+// migrate runs the migrate subcommand against the configured database
+func migrate(cfg Infrastructure.MongoConfig, args []string) error {
+	migrator, err := Infrastructure.NewMigrator(Infrastructure.GetDB())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MigrateTimeout)
+	defer cancel()
+
+	command := "status"
+	if len(args) > 0 {
+		command = args[0]
+	}
+
+	switch command {
+	case "up":
+		ran, err := migrator.Up(ctx)
+		for _, m := range ran {
+			log.Printf("Applied %04d_%s", m.Version, m.Name)
+		}
+		if err == nil && len(ran) == 0 {
+			log.Printf("Database is up to date")
+		}
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		rolledBack, err := migrator.Down(ctx, steps)
+		for _, m := range rolledBack {
+			log.Printf("Rolled back %04d_%s", m.Version, m.Name)
+		}
+		return err
+	case "status":
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			applied := "pending"
+			if status.AppliedAt != nil {
+				applied = "applied " + status.AppliedAt.Format(time.RFC3339)
+			}
+			fmt.Printf("%04d_%s\t%s\n", status.Version, status.Name, applied)
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q (want up, down [n] or status)", command)
+	}
+}
+
+// migrateOnBoot applies pending migrations when MIGRATE_ON_BOOT is set, and otherwise
+// warns that the schema is behind the binary
+func migrateOnBoot(cfg Infrastructure.MongoConfig) error {
+	migrator, err := Infrastructure.NewMigrator(Infrastructure.GetDB())
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.MigrateTimeout)
+	defer cancel()
+
+	if !cfg.MigrateOnBoot {
+		pending, err := migrator.Pending(ctx)
+		if err != nil {
+			log.Printf("Warning: failed to check migrations: %v", err)
+		} else if pending > 0 {
+			log.Printf("Warning: %d migration(s) pending; run `shopops migrate up` or set MIGRATE_ON_BOOT=true", pending)
+		}
+		return nil
+	}
+
+	ran, err := migrator.Up(ctx)
+	for _, m := range ran {
+		log.Printf("Applied migration %04d_%s", m.Version, m.Name)
 	}
+	return err
 }
@@ -1,27 +1,45 @@
 package routers
 
 import (
+	"strings"
+
+	app "ShopOps/Delivery/app"
 	controllers "ShopOps/Delivery/controllers"
+	graphqlapi "ShopOps/Delivery/graphqlapi"
+	Domain "ShopOps/Domain"
 	Infrastructure "ShopOps/Infrastructure"
-	Repositories "ShopOps/Repositories"
-	Usecases "ShopOps/Usecases"
 
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
 
 	"github.com/gin-gonic/gin"
-	"go.mongodb.org/mongo-driver/mongo"
 )
 
-func SetupRouter(db *mongo.Database) *gin.Engine {
-	router := gin.Default()
+func SetupRouter(container *app.Container) *gin.Engine {
+	cfg := container.Config
+
+	// gin's own request logger is replaced by the structured one
+	router := gin.New()
+	Infrastructure.RegisterValidators()
+	router.Use(Infrastructure.TracingMiddleware(), Infrastructure.RequestLogger(), Infrastructure.MetricsMiddleware(), Infrastructure.Recovery())
+
+	// Prometheus scrape endpoint, behind basic auth when credentials are configured
+	if cfg.Metrics.Username != "" && cfg.Metrics.Password != "" {
+		router.GET("/metrics", gin.BasicAuth(gin.Accounts{cfg.Metrics.Username: cfg.Metrics.Password}), Infrastructure.MetricsHandler())
+	} else {
+		router.GET("/metrics", Infrastructure.MetricsHandler())
+	}
 	router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
+	router.GET("/openapi.json", Infrastructure.OpenAPIHandler())
+
+	router.Use(Infrastructure.LanguageMiddleware())
 
 	// CORS middleware
 	router.Use(func(c *gin.Context) {
 		c.Writer.Header().Set("Access-Control-Allow-Origin", "*")
 		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
+		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With, X-Request-ID, X-Device-ID, traceparent")
+		c.Writer.Header().Set("Access-Control-Expose-Headers", "X-Request-ID, traceparent, X-Feature-Flags, X-Maintenance-Mode, Retry-After, X-Next-Cursor, X-Total-Count")
 		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE, PATCH")
 
 		if c.Request.Method == "OPTIONS" {
@@ -32,50 +50,162 @@ func SetupRouter(db *mongo.Database) *gin.Engine {
 		c.Next()
 	})
 
-	// Initialize services
-	jwtService := Infrastructure.NewJWTService()
-	authMiddleware := Infrastructure.AuthMiddleware(jwtService)
+	authMiddleware := Infrastructure.AuthMiddleware(container.JWT)
+	rateLimitService := container.RateLimiter
+
+	// Health probes are registered before rate limiting so frequent polling is never rejected
+	healthChecker := container.Health
+	router.GET("/healthz", healthChecker.Liveness())
+	router.GET("/readyz", healthChecker.Readiness())
+
+	// BI tools' data feed, authenticated by the shop's BI keys and counted against a
+	// limit of its own rather than the general one
+	biController := controllers.NewBIController(container.UseCases.BI)
+	biRoutes := router.Group("/api/v1/bi")
+	biRoutes.Use(Infrastructure.BIKeyAuth(container.UseCases.BI), rateLimitService.LimitBI())
+	{
+		biRoutes.GET("/datasets", biController.GetBIDatasets)
+		biRoutes.GET("/datasets/:dataset", biController.GetBIDataset)
+	}
 
-	// Initialize rate limit service
-	rateLimitService := Infrastructure.NewRateLimitService()
-	
 	// Apply general rate limiting to all requests
 	router.Use(rateLimitService.LimitGeneral())
 
-	// Initialize repositories
-	userRepo := Repositories.NewUserRepository(db)
-	businessRepo := Repositories.NewBusinessRepository(db)
-	salesRepo := Repositories.NewSalesRepository(db)
-	expenseRepo := Repositories.NewExpenseRepository(db)
-	inventoryRepo := Repositories.NewInventoryRepository(db)
-	reportRepo := Repositories.NewReportRepository(db)
-	syncRepo := Repositories.NewSyncRepository(db)
-
-	// Initialize sync service
-	syncService := Infrastructure.NewSyncService(db, salesRepo, expenseRepo, inventoryRepo, syncRepo)
-
-	// Initialize use cases
-	userUC := Usecases.NewUserUseCase(userRepo, jwtService)
-	businessUC := Usecases.NewBusinessUseCase(businessRepo, userRepo)
-	salesUC := Usecases.NewSalesUseCase(salesRepo, businessRepo, inventoryRepo)
-	expenseUC := Usecases.NewExpenseUseCase(expenseRepo, businessRepo)
-	inventoryUC := Usecases.NewInventoryUseCase(inventoryRepo, businessRepo)
-	reportUC := Usecases.NewReportUseCase(reportRepo, businessRepo, Infrastructure.NewExportService())
-	syncUC := Usecases.NewSyncUseCase(syncService, businessRepo, salesRepo, expenseRepo, inventoryRepo, syncRepo)
+	// Request body size limits: small for the API, larger for sync batches and uploads
+	router.Use(Infrastructure.BodyLimitMiddleware(Infrastructure.BodyLimits{
+		Default: cfg.Server.BodyLimitDefault,
+		Routes: map[string]int64{
+			"/api/v1/businesses/:businessId/sync/batch":                  cfg.Server.BodyLimitSync,
+			"/api/v1/businesses/:businessId/expenses/:expenseId/receipt": cfg.Server.BodyLimitUpload,
+		},
+	}))
+
+	uc := container.UseCases
+
+	// Response caching for expensive reads; writes drop what they change
+	responseCache := container.Cache
+	catalogCache := responseCache.Cached(cfg.Cache.CatalogTTL,
+		Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock)
+	reportCache := responseCache.Cached(cfg.Cache.ReportsTTL,
+		Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock, Infrastructure.CacheTagSales, Infrastructure.CacheTagExpenses)
+	invalidateAll := responseCache.Invalidates(Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock,
+		Infrastructure.CacheTagSales, Infrastructure.CacheTagExpenses)
+	// ETags on the catalog and settings, so apps revalidate them rather than download them
+	conditional := Infrastructure.ConditionalGET()
 
 	// Initialize controllers
-	userController := controllers.NewUserController(userUC)
-	businessController := controllers.NewBusinessController(businessUC)
-	salesController := controllers.NewSalesController(salesUC)
-	expenseController := controllers.NewExpenseController(expenseUC)
-	inventoryController := controllers.NewInventoryController(inventoryUC)
-	reportController := controllers.NewReportController(reportUC)
-	syncController := controllers.NewSyncController(syncUC)
+	userController := controllers.NewUserController(uc.User)
+	businessController := controllers.NewBusinessController(uc.Business)
+	salesController := controllers.NewSalesController(uc.Sales)
+	expenseController := controllers.NewExpenseController(uc.Expense)
+	inventoryController := controllers.NewInventoryController(uc.Inventory)
+	reservationController := controllers.NewStockReservationController(uc.Reservation)
+	backorderController := controllers.NewBackorderController(uc.Backorder)
+	consignmentController := controllers.NewConsignmentController(uc.Consignment)
+	depositController := controllers.NewDepositController(uc.Deposit)
+	repairJobController := controllers.NewRepairJobController(uc.RepairJob)
+	appointmentController := controllers.NewAppointmentController(uc.Appointment)
+	tabController := controllers.NewTabController(uc.Tab)
+	productionController := controllers.NewProductionController(uc.Production)
+	transferController := controllers.NewStockTransferController(uc.Transfer)
+	reportController := controllers.NewReportController(uc.Report)
+	syncController := controllers.NewSyncController(uc.Sync)
+	giftCardController := controllers.NewGiftCardController(uc.GiftCard)
+	loyaltyController := controllers.NewLoyaltyController(uc.Loyalty)
+	receiptController := controllers.NewReceiptController(uc.Receipt)
+	customerController := controllers.NewCustomerController(uc.Customer)
+	supplierController := controllers.NewSupplierController(uc.Supplier)
+	employeeController := controllers.NewEmployeeController(uc.Employee)
+	smsController := controllers.NewSMSController(uc.SMS)
+	segmentController := controllers.NewSegmentController(uc.Segment)
+	pricingController := controllers.NewPricingController(uc.Pricing)
+	analyticsController := controllers.NewAnalyticsController(uc.Analytics)
+	forecastController := controllers.NewForecastController(uc.Forecast)
+	customReportController := controllers.NewCustomReportController(uc.CustomReport)
+	valuationController := controllers.NewInventoryValuationController(uc.Valuation)
+	shrinkageController := controllers.NewShrinkageController(uc.Shrinkage)
+	alertController := controllers.NewAlertController(uc.Alert)
+	branchReportController := controllers.NewBranchReportController(uc.BranchReport)
+	dashboardController := controllers.NewDashboardController(uc.Dashboard)
+	jobController := controllers.NewJobController(uc.Job)
+	domainEventController := controllers.NewDomainEventController(uc.DomainEvent)
+	featureFlagController := controllers.NewFeatureFlagController(uc.FeatureFlag)
+	maintenanceController := controllers.NewMaintenanceController(uc.Maintenance)
+	archiveController := controllers.NewArchiveController(uc.Archive)
+	demoController := controllers.NewDemoController(uc.Demo)
+	onboardingController := controllers.NewOnboardingController(uc.Onboarding)
+	catalogSettingsController := controllers.NewCatalogSettingsController(uc.CatalogSettings)
+	packController := controllers.NewVerticalPackController(uc.VerticalPack)
+	shopSettingsController := controllers.NewShopSettingsController(uc.ShopSettings)
+	supportController := controllers.NewSupportController(uc.Support)
+	trashController := controllers.NewTrashController(uc.Trash)
+	retentionController := controllers.NewRetentionController(uc.Retention)
+	searchController := controllers.NewSearchController(uc.Search)
+	webhookController := controllers.NewWebhookController(uc.Webhook)
+	telegramController := controllers.NewTelegramController(uc.Telegram, cfg.Telegram.WebhookSecret)
+	mobilePaymentController := controllers.NewMobilePaymentController(uc.MobilePayment)
+	billingController := controllers.NewBillingController(uc.Billing)
+	emailController := controllers.NewEmailController(uc.Email)
+	pushController := controllers.NewPushController(uc.Push)
+	notificationController := controllers.NewNotificationController(uc.Notification)
+	printController := controllers.NewPrintController(uc.Print)
+	scaleController := controllers.NewScaleController(uc.Scale)
+	storefrontController := controllers.NewStorefrontController(uc.Storefront)
+	reconciliationController := controllers.NewReconciliationController(uc.Reconciliation)
+	accountingController := controllers.NewAccountingController(uc.Accounting)
+	importTemplateController := controllers.NewImportTemplateController(uc.ImportTemplate)
+	customFieldController := controllers.NewCustomFieldController(uc.CustomField)
+	currencyController := controllers.NewCurrencyController(uc.Currency)
+
+	// Read-only maintenance mode refuses writes on every route registered below
+	router.Use(Infrastructure.MaintenanceMiddleware(uc.Maintenance))
+
+	// Stored files, only through expiring signed links such as those in export emails;
+	// receipt photos are served to the shop's staff from the expense
+	router.GET(strings.TrimRight(cfg.Storage.UploadBaseURL, "/")+"/*filepath", Infrastructure.ServeSignedFiles(container.Files))
 
 	// Public routes
 	router.POST("/api/v1/auth/register", userController.Register)
 	router.POST("/api/v1/auth/login", userController.Login)
 	router.POST("/api/v1/auth/refresh", userController.RefreshToken)
+	router.POST("/api/v1/demo", demoController.TryDemo)
+	router.GET("/api/v1/maintenance", maintenanceController.GetStatus)
+
+	// Telegram bot updates, authenticated by the bot's webhook secret
+	router.POST("/api/v1/integrations/telegram/webhook", telegramController.TelegramWebhook)
+
+	// Mobile money outcomes, checked by each provider's own signature or a status query
+	router.POST("/api/v1/integrations/payments/:provider/callback", mobilePaymentController.MobilePaymentCallback)
+
+	// Subscription payments and changes, checked by each provider's signature
+	router.POST("/api/v1/integrations/billing/:provider/webhook", billingController.BillingWebhook)
+
+	// Email bounces and complaints, checked by SendGrid's or SNS's signature
+	router.POST("/api/v1/integrations/email/:provider/events", emailController.EmailEvents)
+
+	// Print bridges collecting the shop's print jobs, authenticated by their own tokens
+	printBridgeRoutes := router.Group("/api/v1/print-bridge")
+	printBridgeRoutes.Use(Infrastructure.PrintBridgeAuth(uc.Print))
+	{
+		printBridgeRoutes.POST("/jobs/claim", printController.ClaimPrintJobs)
+		printBridgeRoutes.POST("/jobs/:jobId/ack", printController.AckPrintJob)
+	}
+
+	// Internal support API, authenticated with support keys rather than user accounts
+	// and audit-logged
+	supportRoutes := router.Group("/internal/v1")
+	supportRoutes.Use(Infrastructure.SupportAudit(container.Repos.AuditLog), Infrastructure.SupportAuth(cfg.Support.APIKeys))
+	{
+		supportRoutes.GET("/shops", supportController.SearchShops)
+		supportRoutes.GET("/shops/:businessId", supportController.GetShop)
+		supportRoutes.POST("/shops/:businessId/rate-limits/reset", supportController.ResetRateLimits)
+		supportRoutes.POST("/shops/:businessId/backups", supportController.TriggerBackup)
+		supportRoutes.GET("/shops/:businessId/backups", supportController.GetBackups)
+		supportRoutes.GET("/shops/:businessId/backups/:backupId/download", supportController.DownloadBackup)
+		supportRoutes.POST("/shops/:businessId/impersonate", supportController.Impersonate)
+		supportRoutes.GET("/shops/:businessId/errors", supportController.GetRecentErrors)
+		supportRoutes.GET("/audit-log", supportController.GetAuditLog)
+	}
 
 	// Protected routes (require authentication)
 	protected := router.Group("/api/v1")
@@ -85,79 +215,718 @@ func SetupRouter(db *mongo.Database) *gin.Engine {
 		protected.GET("/users/me", userController.GetCurrentUser)
 		protected.PATCH("/users/me", userController.UpdateUser)
 
+		// The web dashboard's read API: a page's products, sales, customers and reports in one query
+		graphqlHandler := graphqlapi.NewHandler(container)
+		protected.GET("/graphql", graphqlHandler)
+		protected.POST("/graphql", graphqlHandler)
+
+		// The user's phones registered for push notifications
+		pushDeviceRoutes := protected.Group("/push")
+		{
+			pushDeviceRoutes.POST("/devices", pushController.RegisterPushDevice)
+			pushDeviceRoutes.GET("/devices", pushController.GetPushDevices)
+			pushDeviceRoutes.DELETE("/devices/:deviceId", pushController.DeletePushDevice)
+			pushDeviceRoutes.POST("/messages/:messageId/receipt", pushController.RecordPushReceipt)
+		}
+
+		// The user's notification center and the channels they get each kind on
+		notificationRoutes := protected.Group("/notifications")
+		{
+			notificationRoutes.GET("", notificationController.GetNotifications)
+			notificationRoutes.GET("/badge", notificationController.GetNotificationBadge)
+			notificationRoutes.POST("/read", notificationController.MarkNotificationsRead)
+			notificationRoutes.GET("/preferences", notificationController.GetNotificationPreferences)
+			notificationRoutes.PATCH("/preferences", notificationController.UpdateNotificationPreferences)
+		}
+
+		// Platform administration
+		adminRoutes := protected.Group("/admin")
+		adminRoutes.Use(Infrastructure.AdminOnlyMiddleware())
+		{
+			adminRoutes.GET("/config", Infrastructure.ConfigHandler(cfg))
+			adminRoutes.GET("/jobs", jobController.GetJobs)
+			adminRoutes.GET("/jobs/stats", jobController.GetJobStats)
+			adminRoutes.GET("/jobs/:jobId", jobController.GetJob)
+			adminRoutes.POST("/jobs/:jobId/retry", jobController.RetryJob)
+			adminRoutes.GET("/events", domainEventController.GetDomainEvents)
+			adminRoutes.GET("/events/stats", domainEventController.GetDomainEventStats)
+			adminRoutes.GET("/events/:eventId", domainEventController.GetDomainEvent)
+			adminRoutes.POST("/events/:eventId/retry", domainEventController.RetryDomainEvent)
+			adminRoutes.GET("/feature-flags", featureFlagController.GetFlags)
+			adminRoutes.POST("/feature-flags", featureFlagController.CreateFlag)
+			adminRoutes.GET("/feature-flags/:key", featureFlagController.GetFlag)
+			adminRoutes.PATCH("/feature-flags/:key", featureFlagController.UpdateFlag)
+			adminRoutes.DELETE("/feature-flags/:key", featureFlagController.DeleteFlag)
+			adminRoutes.GET("/maintenance", maintenanceController.GetMaintenance)
+			adminRoutes.PUT("/maintenance", maintenanceController.SetMaintenance)
+			adminRoutes.GET("/archive", archiveController.GetArchiveRuns)
+			adminRoutes.POST("/archive", archiveController.RunArchive)
+			adminRoutes.POST("/demo-shops", demoController.CreateDemoShop)
+		}
+
+		// The wizard setting up a new shop; the steps after the first are on the shop
+		onboardingRoutes := protected.Group("/onboarding")
+		{
+			onboardingRoutes.GET("", onboardingController.GetCurrentOnboarding)
+			onboardingRoutes.GET("/templates", onboardingController.GetShopTemplates)
+			onboardingRoutes.POST("/business", onboardingController.SaveOnboardingBusiness)
+		}
+
 		// Business routes
 		businessRoutes := protected.Group("/businesses")
+		businessRoutes.Use(invalidateAll)
 		{
 			businessRoutes.POST("", businessController.CreateBusiness)
 			businessRoutes.GET("", businessController.GetBusinesses)
-			businessRoutes.GET("/:businessId", businessController.GetBusiness)
-			businessRoutes.PATCH("/:businessId", businessController.UpdateBusiness)
 		}
 
+		// Consolidated reports across the user's shops
+		branchRoutes := protected.Group("/branches/reports")
+		{
+			branchRoutes.GET("/pnl", branchReportController.GetConsolidatedPnL)
+			branchRoutes.GET("/margins/categories", branchReportController.GetConsolidatedCategoryMargins)
+			branchRoutes.GET("/compare", branchReportController.CompareBranches)
+		}
+
+		// A product's availability in each of the user's shops, matched by SKU
+		protected.GET("/branches/availability", reservationController.GetBranchAvailability)
+
 		// Business-specific routes (require business ID in path)
 		businessSpecific := protected.Group("/businesses/:businessId")
-		businessSpecific.Use(Infrastructure.BusinessMiddleware())
+		businessSpecific.Use(Infrastructure.TenancyMiddleware(container.Repos.Business, container.Repos.Employee), Infrastructure.FeatureFlagsMiddleware(uc.FeatureFlag), Infrastructure.PlanMiddleware(uc.Billing))
 		{
+			// The shop itself, to its members only
+			businessSpecific.GET("", conditional, businessController.GetBusiness)
+			businessSpecific.PATCH("", invalidateAll, businessController.UpdateBusiness)
+
+			// Feature flags on for the shop
+			businessSpecific.GET("/features", featureFlagController.GetBusinessFeatures)
+
+			// Every home screen widget in one call
+			businessSpecific.GET("/dashboard", dashboardController.GetDashboard)
+
+			// Onboarding steps after the shop is created; owners only
+			shopOnboardingRoutes := businessSpecific.Group("/onboarding")
+			shopOnboardingRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				shopOnboardingRoutes.GET("", onboardingController.GetOnboarding)
+				shopOnboardingRoutes.PUT("/template", onboardingController.ChooseShopTemplate)
+				shopOnboardingRoutes.POST("/products",
+					responseCache.Invalidates(Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock), container.Search.Invalidates(), container.Catalog.Invalidates(),
+					onboardingController.ImportOnboardingProducts)
+				shopOnboardingRoutes.POST("/device", onboardingController.RegisterOnboardingDevice)
+				shopOnboardingRoutes.POST("/skip", onboardingController.SkipOnboardingStep)
+			}
+
+			// How the tills behave, its history, and everything a till needs in one download
+			settingsRoutes := businessSpecific.Group("/settings")
+			{
+				settingsRoutes.GET("", conditional, shopSettingsController.GetShopSettings)
+				settingsRoutes.PATCH("",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					shopSettingsController.UpdateShopSettings)
+				settingsRoutes.GET("/definitions", shopSettingsController.GetSettingDefinitions)
+				settingsRoutes.GET("/history", shopSettingsController.GetSettingHistory)
+				settingsRoutes.GET("/bundle", conditional, shopSettingsController.GetSettingsBundle)
+			}
+
+			// Vertical packs for kinds of shop; owners install them
+			packRoutes := businessSpecific.Group("/packs")
+			{
+				packRoutes.GET("", packController.GetPacks)
+				packRoutes.GET("/:code", packController.GetPack)
+				packRoutes.POST("/:code/install",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					packController.InstallPack)
+			}
+
+			// Deleted products, customers and suppliers, kept for 30 days
+			businessSpecific.GET("/trash", trashController.GetTrash)
+
+			// What the daily retention run anonymized and purged; owners only
+			businessSpecific.GET("/retention/reports",
+				Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+				retentionController.GetRetentionReports)
+
+			// As-you-type product and customer search for the POS
+			businessSpecific.GET("/search", searchController.Search)
+
 			// Sales routes
 			salesRoutes := businessSpecific.Group("/sales")
+			salesRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagSales, Infrastructure.CacheTagStock))
 			{
 				salesRoutes.POST("", salesController.CreateSale)
 				salesRoutes.GET("", salesController.GetSales)
+				salesRoutes.GET("/quick-line", scaleController.GetQuickSaleLine)
+				salesRoutes.POST("/quick", scaleController.CreateQuickSale)
 				salesRoutes.GET("/summary", salesController.GetSalesSummary)
+				salesRoutes.GET("/summary/breakdown", analyticsController.GetSalesBreakdown)
 				salesRoutes.GET("/stats", salesController.GetSalesStats)
 				salesRoutes.GET("/:saleId", salesController.GetSale)
 				salesRoutes.PATCH("/:saleId", salesController.UpdateSale)
 				salesRoutes.DELETE("/:saleId", salesController.VoidSale)
+				salesRoutes.GET("/:saleId/receipt", receiptController.RenderReceipt)
+				salesRoutes.POST("/:saleId/sms-receipt", smsController.SendSaleReceipt)
+			}
+
+			// Mobile money payments for sales
+			mobilePaymentRoutes := businessSpecific.Group("/mobile-payments")
+			{
+				mobilePaymentRoutes.GET("/providers", mobilePaymentController.GetMobileMoneyProviders)
+				mobilePaymentRoutes.POST("", mobilePaymentController.InitiateMobilePayment)
+				mobilePaymentRoutes.GET("", mobilePaymentController.GetMobilePayments)
+				mobilePaymentRoutes.GET("/:paymentId", mobilePaymentController.GetMobilePayment)
+				mobilePaymentRoutes.POST("/:paymentId/reverse",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					mobilePaymentController.ReverseMobilePayment)
 			}
 
 			// Expense routes
 			expenseRoutes := businessSpecific.Group("/expenses")
+			expenseRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagExpenses))
 			{
 				expenseRoutes.POST("", expenseController.CreateExpense)
 				expenseRoutes.GET("", expenseController.GetExpenses)
 				expenseRoutes.GET("/summary", expenseController.GetExpenseSummary)
 				expenseRoutes.GET("/categories", expenseController.GetExpenseCategories)
+				expenseRoutes.POST("/recurring", expenseController.CreateRecurringExpense)
+				expenseRoutes.GET("/recurring", expenseController.GetRecurringExpenses)
+				expenseRoutes.PATCH("/recurring/:recurringId", expenseController.UpdateRecurringExpense)
+				expenseRoutes.DELETE("/recurring/:recurringId", expenseController.DeleteRecurringExpense)
 				expenseRoutes.GET("/:expenseId", expenseController.GetExpense)
 				expenseRoutes.PATCH("/:expenseId", expenseController.UpdateExpense)
 				expenseRoutes.DELETE("/:expenseId", expenseController.VoidExpense)
+				expenseRoutes.POST("/:expenseId/receipt", expenseController.UploadExpenseReceipt)
+				expenseRoutes.GET("/:expenseId/receipt", expenseController.GetExpenseReceipt)
 			}
 
 			// Inventory routes
 			inventoryRoutes := businessSpecific.Group("/inventory")
 			{
 				productsRoutes := inventoryRoutes.Group("/products")
+				productsRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock), container.Search.Invalidates(), container.Catalog.Invalidates())
 				{
 					productsRoutes.POST("", inventoryController.CreateProduct)
-					productsRoutes.GET("", inventoryController.GetProducts)
-					productsRoutes.GET("/low-stock", inventoryController.GetLowStock)
-					productsRoutes.GET("/:productId", inventoryController.GetProduct)
+					productsRoutes.GET("", conditional, catalogCache, inventoryController.GetProducts)
+					productsRoutes.GET("/low-stock", conditional, catalogCache, inventoryController.GetLowStock)
+					productsRoutes.GET("/:productId", conditional, catalogCache, inventoryController.GetProduct)
 					productsRoutes.PATCH("/:productId", inventoryController.UpdateProduct)
 					productsRoutes.DELETE("/:productId", inventoryController.DeleteProduct)
 					productsRoutes.POST("/:productId/adjust", inventoryController.AdjustStock)
 					productsRoutes.GET("/:productId/history", inventoryController.GetStockHistory)
+					productsRoutes.GET("/:productId/ledger", inventoryController.GetStockLedger)
+					productsRoutes.GET("/:productId/availability", reservationController.GetProductAvailability)
+					productsRoutes.GET("/:productId/supplier-prices", supplierController.GetProductSupplierPrices)
+					productsRoutes.PUT("/:productId/consignment", consignmentController.SetProductConsignment)
+					productsRoutes.DELETE("/:productId/consignment", consignmentController.ClearProductConsignment)
+					productsRoutes.PUT("/:productId/deposits", depositController.SetProductDeposits)
+					productsRoutes.POST("/:productId/restore", trashController.RestoreProduct)
+				}
+
+				// Stock held for accepted online and phone orders
+				reservationRoutes := inventoryRoutes.Group("/reservations")
+				reservationRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagStock), container.Catalog.Invalidates())
+				{
+					reservationRoutes.POST("", reservationController.CreateReservation)
+					reservationRoutes.GET("", reservationController.GetReservations)
+					reservationRoutes.GET("/:reservationId", reservationController.GetReservation)
+					reservationRoutes.POST("/:reservationId/release", reservationController.ReleaseReservation)
 				}
+
+				// Sold beyond stock, waiting for deliveries
+				backorderRoutes := inventoryRoutes.Group("/backorders")
+				backorderRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagStock), container.Catalog.Invalidates())
+				{
+					backorderRoutes.GET("", backorderController.GetBackorders)
+					backorderRoutes.GET("/:backorderId", backorderController.GetBackorder)
+					backorderRoutes.POST("/:backorderId/collect", backorderController.CollectBackorder)
+					backorderRoutes.POST("/:backorderId/cancel", backorderController.CancelBackorder)
+				}
+
+				// Product, quantity and amount for a barcode read at the POS
+				inventoryRoutes.GET("/scan", scaleController.ScanBarcode)
+				inventoryRoutes.GET("/ledger", inventoryController.GetStockLedger)
+
+				// Categories, units and tax rates offered when adding products
+				inventoryRoutes.GET("/settings", conditional, catalogSettingsController.GetCatalogSettings)
+				inventoryRoutes.PATCH("/settings",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					catalogSettingsController.UpdateCatalogSettings)
 			}
 
 			// Report routes
 			reportRoutes := businessSpecific.Group("/reports")
+			reportRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagSales, Infrastructure.CacheTagExpenses))
 			{
-				reportRoutes.GET("/dashboard", reportController.GetDashboard)
-				reportRoutes.GET("/sales", reportController.GetSalesReport)
-				reportRoutes.GET("/expenses", reportController.GetExpensesReport)
-				reportRoutes.GET("/profit", reportController.GetProfitReport)
-				reportRoutes.GET("/inventory", reportController.GetInventoryReport)
+				reportRoutes.GET("/dashboard", reportCache, reportController.GetDashboard)
+				reportRoutes.GET("/sales", reportCache, reportController.GetSalesReport)
+				reportRoutes.GET("/expenses", reportCache, reportController.GetExpensesReport)
+				reportRoutes.GET("/profit", reportCache, reportController.GetProfitReport)
+				reportRoutes.GET("/inventory", reportCache, reportController.GetInventoryReport)
+				reportRoutes.GET("/inventory/valuation", reportCache, valuationController.GetValuation)
+				reportRoutes.GET("/inventory/valuation/history", reportCache, valuationController.GetValuationHistory)
+				reportRoutes.GET("/dead-stock", reportCache, reportController.GetDeadStockReport)
+				reportRoutes.GET("/shrinkage", reportCache, shrinkageController.GetShrinkageReport)
 				
 				// Export endpoint - 10 requests per hour rate limit (ADDED)
 				reportRoutes.GET("/export", 
 					rateLimitService.LimitExports(), 
+					Infrastructure.RequirePlanQuota(uc.Billing, Domain.QuotaExports),
 					reportController.ExportReport)
+				reportRoutes.POST("/export/email",
+					rateLimitService.LimitExports(),
+					Infrastructure.RequirePlanQuota(uc.Billing, Domain.QuotaExports),
+					reportController.EmailExportReport)
 				
-				reportRoutes.GET("/profit/summary", reportController.GetProfitSummary)
-				reportRoutes.GET("/profit/trends", reportController.GetProfitTrends)
+				reportRoutes.GET("/profit/summary", reportCache, reportController.GetProfitSummary)
+				reportRoutes.GET("/profit/trends", reportCache, reportController.GetProfitTrends)
+
+				// P&L and margins from pre-aggregated daily totals
+				reportRoutes.GET("/pnl", reportCache, analyticsController.GetProfitAndLoss)
+				reportRoutes.POST("/pnl/rebuild", analyticsController.RebuildAnalytics)
+				reportRoutes.GET("/margins/categories", reportCache, analyticsController.GetCategoryMargins)
+				reportRoutes.GET("/margins/products", reportCache, analyticsController.GetProductMargins)
+
+				// Saved custom reports
+				reportRoutes.POST("/custom", customReportController.CreateReport)
+				reportRoutes.GET("/custom", customReportController.GetReports)
+				reportRoutes.POST("/custom/preview", customReportController.PreviewReport)
+				reportRoutes.GET("/custom/:reportId", customReportController.GetReport)
+				reportRoutes.PATCH("/custom/:reportId", customReportController.UpdateReport)
+				reportRoutes.DELETE("/custom/:reportId", customReportController.DeleteReport)
+				reportRoutes.GET("/custom/:reportId/run", customReportController.RunReport)
+				reportRoutes.GET("/custom/:reportId/runs", customReportController.GetReportRuns)
+			}
+
+			// Analytics routes
+			analyticsRoutes := businessSpecific.Group("/analytics")
+			{
+				analyticsRoutes.GET("/forecast", forecastController.GetForecast)
+				analyticsRoutes.GET("/abc", analyticsController.GetABCAnalysis)
+				analyticsRoutes.GET("/top-sellers", analyticsController.GetTopSellers)
+				analyticsRoutes.GET("/heatmap", analyticsController.GetTrafficHeatmap)
+				analyticsRoutes.GET("/reorder-suggestions", forecastController.GetReorderSuggestions)
+			}
+
+			// Customer routes
+			customerRoutes := businessSpecific.Group("/customers")
+			customerRoutes.Use(container.Search.Invalidates())
+			{
+				customerRoutes.POST("", customerController.CreateCustomer)
+				customerRoutes.GET("", customerController.GetCustomers)
+				customerRoutes.GET("/duplicates", customerController.GetDuplicates)
+				customerRoutes.GET("/:customerId", customerController.GetCustomer)
+				customerRoutes.PATCH("/:customerId", customerController.UpdateCustomer)
+				customerRoutes.DELETE("/:customerId", customerController.DeleteCustomer)
+				customerRoutes.POST("/:customerId/restore", trashController.RestoreCustomer)
+				customerRoutes.GET("/:customerId/sales", customerController.GetCustomerSales)
+				customerRoutes.POST("/:customerId/merge", customerController.MergeCustomers)
+				customerRoutes.GET("/:customerId/prices", pricingController.GetCustomerPrices)
+				customerRoutes.PUT("/:customerId/prices", pricingController.SetCustomerPrice)
+				customerRoutes.DELETE("/:customerId/prices/:productId", pricingController.DeleteCustomerPrice)
+			}
+
+			// POS pricing
+			businessSpecific.GET("/pricing/quote", pricingController.QuotePrice)
+
+			// Customer segment routes
+			segmentRoutes := businessSpecific.Group("/segments")
+			{
+				segmentRoutes.POST("", segmentController.CreateSegment)
+				segmentRoutes.GET("", segmentController.GetSegments)
+				segmentRoutes.POST("/preview", segmentController.PreviewSegment)
+				segmentRoutes.GET("/:segmentId", segmentController.GetSegment)
+				segmentRoutes.PATCH("/:segmentId", segmentController.UpdateSegment)
+				segmentRoutes.DELETE("/:segmentId", segmentController.DeleteSegment)
+				segmentRoutes.GET("/:segmentId/customers", segmentController.GetSegmentCustomers)
+			}
+
+			// SMS routes
+			smsRoutes := businessSpecific.Group("/sms")
+			{
+				smsRoutes.POST("/campaigns", rateLimitService.LimitBulkSMS(), smsController.SendBulkSMS)
+				smsRoutes.GET("/campaigns", smsController.GetSMSCampaigns)
+				smsRoutes.GET("/campaigns/:campaignId", smsController.GetSMSCampaign)
+				smsRoutes.GET("/messages", smsController.GetSMSMessages)
+				smsRoutes.GET("/blacklist", smsController.GetSMSBlacklist)
+				smsRoutes.POST("/blacklist", smsController.AddToSMSBlacklist)
+				smsRoutes.DELETE("/blacklist/:phone", smsController.RemoveFromSMSBlacklist)
+			}
+
+			// Webhook routes; they hand out signing secrets, so owners only
+			webhookRoutes := businessSpecific.Group("/webhooks")
+			webhookRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				webhookRoutes.POST("", webhookController.CreateWebhook)
+				webhookRoutes.GET("", webhookController.GetWebhooks)
+				webhookRoutes.GET("/:webhookId", webhookController.GetWebhook)
+				webhookRoutes.PATCH("/:webhookId", webhookController.UpdateWebhook)
+				webhookRoutes.DELETE("/:webhookId", webhookController.DeleteWebhook)
+				webhookRoutes.POST("/:webhookId/rotate-secret", webhookController.RotateWebhookSecret)
+				webhookRoutes.GET("/:webhookId/deliveries", webhookController.GetWebhookDeliveries)
+				webhookRoutes.POST("/:webhookId/deliveries/:deliveryId/replay", webhookController.ReplayWebhookDelivery)
+			}
+
+			// The shop's own subscription to ShopOps
+			billingRoutes := businessSpecific.Group("/billing")
+			billingRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				billingRoutes.GET("/plans", billingController.GetPlans)
+				billingRoutes.GET("/subscription", billingController.GetSubscription)
+				billingRoutes.POST("/checkout", billingController.CreateCheckout)
+				billingRoutes.POST("/subscription/cancel", billingController.CancelSubscription)
+			}
+
+			// Telegram bot links; owners only, as linked chats see the shop's takings
+			telegramRoutes := businessSpecific.Group("/telegram")
+			telegramRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				telegramRoutes.POST("/link-code", telegramController.CreateTelegramLinkCode)
+				telegramRoutes.GET("/links", telegramController.GetTelegramLinks)
+				telegramRoutes.PATCH("/links/:linkId", telegramController.UpdateTelegramLink)
+				telegramRoutes.DELETE("/links/:linkId", telegramController.DeleteTelegramLink)
+			}
+
+			// Email sender, recipients and send log; owners only
+			emailRoutes := businessSpecific.Group("/email")
+			emailRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				emailRoutes.GET("/settings", conditional, emailController.GetEmailSettings)
+				emailRoutes.PATCH("/settings", emailController.UpdateEmailSettings)
+				emailRoutes.POST("/test", emailController.SendTestEmail)
+				emailRoutes.GET("/messages", emailController.GetEmailMessages)
+			}
+
+			// Push notifications a device follows for the shop; the delivery log is for owners
+			pushRoutes := businessSpecific.Group("/push")
+			{
+				pushRoutes.GET("/subscriptions/:deviceId", pushController.GetPushSubscription)
+				pushRoutes.PATCH("/subscriptions/:deviceId", pushController.UpdatePushSubscription)
+				pushRoutes.GET("/messages",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					pushController.GetPushMessages)
+			}
+
+			// Supplier and payables routes
+			supplierRoutes := businessSpecific.Group("/suppliers")
+			{
+				supplierRoutes.POST("", supplierController.CreateSupplier)
+				supplierRoutes.GET("", supplierController.GetSuppliers)
+				supplierRoutes.GET("/aging", supplierController.GetAgingReport)
+				supplierRoutes.GET("/:supplierId", supplierController.GetSupplier)
+				supplierRoutes.PATCH("/:supplierId", supplierController.UpdateSupplier)
+				supplierRoutes.DELETE("/:supplierId", supplierController.DeleteSupplier)
+				supplierRoutes.POST("/:supplierId/restore", trashController.RestoreSupplier)
+				supplierRoutes.GET("/:supplierId/ledger", supplierController.GetLedger)
+				supplierRoutes.POST("/:supplierId/invoices", supplierController.RecordInvoice)
+				supplierRoutes.POST("/:supplierId/payments", supplierController.RecordPayment)
+				supplierRoutes.GET("/:supplierId/consignment-statement", consignmentController.GetStatement)
+			}
+
+			// Goods held for consignors, and what their sales owe them
+			consignmentRoutes := businessSpecific.Group("/consignments")
+			consignmentRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock))
+			{
+				consignmentRoutes.GET("", consignmentController.GetEntries)
+				consignmentRoutes.POST("/receive", consignmentController.ReceiveGoods)
+				consignmentRoutes.POST("/return", consignmentController.ReturnGoods)
+			}
+
+			// Returnable containers, the deposits charged on them and empties brought back
+			depositRoutes := businessSpecific.Group("/deposits")
+			{
+				depositRoutes.GET("", depositController.GetEntries)
+				depositRoutes.GET("/outstanding", depositController.GetOutstanding)
+				depositRoutes.POST("/returns", depositController.ReturnDeposits)
+				depositRoutes.GET("/items", depositController.GetItems)
+				depositRoutes.POST("/items", depositController.CreateItem)
+				depositRoutes.PATCH("/items/:itemId", depositController.UpdateItem)
+			}
+
+			// Devices in for repair, the parts fitted and labor done on them, and their billing
+			repairJobRoutes := businessSpecific.Group("/repair-jobs")
+			repairJobRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagSales, Infrastructure.CacheTagStock))
+			{
+				repairJobRoutes.POST("", repairJobController.CreateJob)
+				repairJobRoutes.GET("", repairJobController.GetJobs)
+				repairJobRoutes.GET("/:jobId", repairJobController.GetJob)
+				repairJobRoutes.PATCH("/:jobId", repairJobController.UpdateJob)
+				repairJobRoutes.POST("/:jobId/status", repairJobController.SetStatus)
+				repairJobRoutes.POST("/:jobId/parts", repairJobController.AddPart)
+				repairJobRoutes.DELETE("/:jobId/parts/:partId", repairJobController.RemovePart)
+				repairJobRoutes.POST("/:jobId/labor", repairJobController.AddLabor)
+				repairJobRoutes.DELETE("/:jobId/labor/:laborId", repairJobController.RemoveLabor)
+				repairJobRoutes.POST("/:jobId/bill", repairJobController.BillJob)
+			}
+
+			// Customers booked in for services, and the sales of those done
+			appointmentRoutes := businessSpecific.Group("/appointments")
+			appointmentRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagSales))
+			{
+				appointmentRoutes.GET("/slots", appointmentController.GetSlots)
+				appointmentRoutes.POST("", appointmentController.BookAppointment)
+				appointmentRoutes.GET("", appointmentController.GetAppointments)
+				appointmentRoutes.GET("/:appointmentId", appointmentController.GetAppointment)
+				appointmentRoutes.POST("/:appointmentId/reschedule", appointmentController.RescheduleAppointment)
+				appointmentRoutes.POST("/:appointmentId/cancel", appointmentController.CancelAppointment)
+				appointmentRoutes.POST("/:appointmentId/no-show", appointmentController.MarkNoShow)
+				appointmentRoutes.POST("/:appointmentId/complete", appointmentController.CompleteAppointment)
+			}
+
+			// Running bills for tables, shared between waiter devices
+			tabRoutes := businessSpecific.Group("/tabs")
+			tabRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagSales, Infrastructure.CacheTagStock))
+			{
+				tabRoutes.POST("", tabController.OpenTab)
+				tabRoutes.GET("", tabController.GetTabs)
+				tabRoutes.GET("/changes", tabController.GetTabChanges)
+				tabRoutes.GET("/:tabId", tabController.GetTab)
+				tabRoutes.PATCH("/:tabId", tabController.UpdateTab)
+				tabRoutes.POST("/:tabId/items", tabController.AddTabItems)
+				tabRoutes.DELETE("/:tabId/items/:itemId", tabController.RemoveTabItem)
+				tabRoutes.POST("/:tabId/split", tabController.SplitTab)
+				tabRoutes.POST("/:tabId/payments", tabController.PayTab)
+				tabRoutes.DELETE("/:tabId/payments/:paymentId", tabController.RemoveTabPayment)
+				tabRoutes.POST("/:tabId/close", tabController.CloseTab)
+				tabRoutes.POST("/:tabId/cancel", tabController.CancelTab)
+			}
+
+			// Recipes of the goods the shop makes, and the production orders making them
+			productionRoutes := businessSpecific.Group("/production")
+			productionRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock))
+			{
+				productionRoutes.GET("/recipes", productionController.GetRecipes)
+				productionRoutes.GET("/recipes/:productId", productionController.GetRecipe)
+				productionRoutes.PUT("/recipes/:productId", productionController.SetRecipe)
+				productionRoutes.DELETE("/recipes/:productId", productionController.DeleteRecipe)
+				productionRoutes.POST("/orders", productionController.Produce)
+				productionRoutes.GET("/orders", productionController.GetOrders)
+				productionRoutes.GET("/orders/:orderId", productionController.GetOrder)
+				productionRoutes.GET("/report", productionController.GetProductionReport)
+			}
+
+			// Stock asked of and shipped between the owner's branches
+			transferRoutes := businessSpecific.Group("/transfers")
+			transferRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagStock))
+			{
+				transferRoutes.POST("", transferController.RequestTransfer)
+				transferRoutes.GET("", transferController.GetTransfers)
+				transferRoutes.GET("/:transferId", transferController.GetTransfer)
+				transferRoutes.POST("/:transferId/approve", transferController.ApproveTransfer)
+				transferRoutes.POST("/:transferId/reject", transferController.RejectTransfer)
+				transferRoutes.POST("/:transferId/ship", transferController.ShipTransfer)
+				transferRoutes.POST("/:transferId/receive", transferController.ReceiveTransfer)
+				transferRoutes.POST("/:transferId/cancel", transferController.CancelTransfer)
+			}
+
+			// Keys BI tools read the shop's data feed with; owners only
+			biKeyRoutes := businessSpecific.Group("/bi/keys")
+			biKeyRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				biKeyRoutes.POST("", biController.CreateBIKey)
+				biKeyRoutes.GET("", biController.GetBIKeys)
+				biKeyRoutes.DELETE("/:keyId", biController.DeleteBIKey)
+			}
+
+			// Purchase order routes
+			purchaseOrderRoutes := businessSpecific.Group("/purchase-orders")
+			purchaseOrderRoutes.Use(responseCache.Invalidates(Infrastructure.CacheTagCatalog, Infrastructure.CacheTagStock))
+			{
+				purchaseOrderRoutes.POST("", supplierController.CreatePurchaseOrder)
+				purchaseOrderRoutes.GET("", supplierController.GetPurchaseOrders)
+				purchaseOrderRoutes.GET("/suggestions", supplierController.GetPurchaseSuggestions)
+				purchaseOrderRoutes.GET("/:purchaseOrderId", supplierController.GetPurchaseOrder)
+				purchaseOrderRoutes.POST("/:purchaseOrderId/submit", supplierController.SubmitPurchaseOrder)
+				purchaseOrderRoutes.POST("/:purchaseOrderId/receive", supplierController.ReceivePurchaseOrder)
+				purchaseOrderRoutes.DELETE("/:purchaseOrderId", supplierController.CancelPurchaseOrder)
+			}
+
+			// Employee, time tracking and commission routes
+			employeeRoutes := businessSpecific.Group("/employees")
+			{
+				employeeRoutes.POST("", employeeController.CreateEmployee)
+				employeeRoutes.GET("", employeeController.GetEmployees)
+				employeeRoutes.GET("/time-entries", employeeController.GetTimeEntries)
+				employeeRoutes.GET("/commission-report", employeeController.GetCommissionReport)
+				employeeRoutes.GET("/:employeeId", employeeController.GetEmployee)
+				employeeRoutes.PATCH("/:employeeId", employeeController.UpdateEmployee)
+				employeeRoutes.POST("/:employeeId/clock-in", employeeController.ClockIn)
+				employeeRoutes.POST("/:employeeId/clock-out", employeeController.ClockOut)
+			}
+
+			// Cash drawer opens without a sale
+			drawerRoutes := businessSpecific.Group("/drawer")
+			{
+				drawerRoutes.POST("/events", shrinkageController.RecordNoSale)
+				drawerRoutes.GET("/events", shrinkageController.GetDrawerEvents)
+			}
+
+			// Anomaly alerts raised by the background analyzer
+			alertRoutes := businessSpecific.Group("/alerts")
+			{
+				alertRoutes.GET("", alertController.GetAlerts)
+				alertRoutes.POST("/analyze", alertController.AnalyzeNow)
+				alertRoutes.POST("/:alertId/acknowledge", alertController.AcknowledgeAlert)
+			}
+
+			commissionRoutes := businessSpecific.Group("/commission-rules")
+			{
+				commissionRoutes.POST("", employeeController.CreateCommissionRule)
+				commissionRoutes.GET("", employeeController.GetCommissionRules)
+				commissionRoutes.PATCH("/:ruleId", employeeController.UpdateCommissionRule)
+				commissionRoutes.DELETE("/:ruleId", employeeController.DeleteCommissionRule)
+			}
+
+			// Gift card and store credit routes
+			giftCardRoutes := businessSpecific.Group("/gift-cards")
+			{
+				giftCardRoutes.POST("", giftCardController.IssueGiftCard)
+				giftCardRoutes.GET("", giftCardController.GetGiftCards)
+				giftCardRoutes.GET("/lookup", giftCardController.LookupGiftCard)
+				giftCardRoutes.GET("/liability", giftCardController.GetLiability)
+				giftCardRoutes.POST("/redeem", giftCardController.RedeemGiftCard)
+				giftCardRoutes.GET("/:giftCardId", giftCardController.GetGiftCard)
+				giftCardRoutes.POST("/:giftCardId/reload", giftCardController.ReloadGiftCard)
+				giftCardRoutes.DELETE("/:giftCardId", giftCardController.VoidGiftCard)
+				giftCardRoutes.GET("/:giftCardId/transactions", giftCardController.GetTransactions)
+			}
+
+			// Receipt template routes
+			receiptRoutes := businessSpecific.Group("/receipt-templates")
+			{
+				receiptRoutes.POST("", receiptController.CreateTemplate)
+				receiptRoutes.GET("", receiptController.GetTemplates)
+				receiptRoutes.GET("/:templateId", receiptController.GetTemplate)
+				receiptRoutes.PATCH("/:templateId", receiptController.UpdateTemplate)
+				receiptRoutes.DELETE("/:templateId", receiptController.DeleteTemplate)
+			}
+
+			// Printing through the shop's print bridges; registering bridges hands out
+			// their tokens, so owners only
+			printRoutes := businessSpecific.Group("/print")
+			{
+				printRoutes.POST("/jobs", printController.CreatePrintJob)
+				printRoutes.GET("/jobs", printController.GetPrintJobs)
+				printRoutes.GET("/jobs/:jobId", printController.GetPrintJob)
+				printRoutes.POST("/labels", printController.PrintShelfLabels)
+
+				printBridgeAdmin := printRoutes.Group("/bridges")
+				printBridgeAdmin.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+				{
+					printBridgeAdmin.POST("", printController.RegisterPrintBridge)
+					printBridgeAdmin.GET("", printController.GetPrintBridges)
+					printBridgeAdmin.POST("/:bridgeId/rotate-token", printController.RotatePrintBridgeToken)
+					printBridgeAdmin.DELETE("/:bridgeId", printController.DeletePrintBridge)
+				}
+			}
+
+			// Label-printing scales: the barcode layout they print and the PLU file
+			// they load; only owners change the layout
+			scaleRoutes := businessSpecific.Group("/scale")
+			{
+				scaleRoutes.GET("/settings", conditional, scaleController.GetScaleSettings)
+				scaleRoutes.PATCH("/settings",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					scaleController.UpdateScaleSettings)
+				scaleRoutes.GET("/plu-export", scaleController.ExportPLUs)
+			}
+
+			// Spreadsheet templates for bulk imports
+			importTemplateRoutes := businessSpecific.Group("/import-templates")
+			{
+				importTemplateRoutes.GET("/:entity", importTemplateController.GetImportTemplate)
+				importTemplateRoutes.GET("/:entity/download", importTemplateController.DownloadImportTemplate)
+			}
+
+			// Shop-defined fields on products, customers, suppliers and sales; owners define them
+			customFieldRoutes := businessSpecific.Group("/custom-fields")
+			{
+				customFieldRoutes.GET("", customFieldController.GetCustomFields)
+				customFieldRoutes.POST("",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					customFieldController.CreateCustomField)
+				customFieldRoutes.PATCH("/:fieldId",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					customFieldController.UpdateCustomField)
+			}
+
+			// Foreign currencies the shop takes and their rates; owners set them
+			currencyRoutes := businessSpecific.Group("/currencies")
+			{
+				currencyRoutes.GET("/settings", conditional, currencyController.GetCurrencySettings)
+				currencyRoutes.PATCH("/settings",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					currencyController.UpdateCurrencySettings)
+				currencyRoutes.GET("/rates", currencyController.GetExchangeRates)
+				currencyRoutes.POST("/rates",
+					Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin),
+					currencyController.SetExchangeRate)
+				currencyRoutes.GET("/rates/:currency", currencyController.GetExchangeRateHistory)
+				currencyRoutes.GET("/takings", currencyController.GetForeignTakings)
+			}
+
+			// Online stores (WooCommerce, Shopify): catalog and stock pushed out,
+			// orders pulled in as sales; owners only, as they hold the store's keys
+			storefrontRoutes := businessSpecific.Group("/storefronts")
+			storefrontRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				storefrontRoutes.POST("", storefrontController.ConnectStorefront)
+				storefrontRoutes.GET("", storefrontController.GetStorefronts)
+				storefrontRoutes.GET("/:connectionId", storefrontController.GetStorefront)
+				storefrontRoutes.PATCH("/:connectionId", storefrontController.UpdateStorefront)
+				storefrontRoutes.DELETE("/:connectionId", storefrontController.DeleteStorefront)
+				storefrontRoutes.POST("/:connectionId/sync", storefrontController.SyncStorefront)
+				storefrontRoutes.GET("/:connectionId/mappings", storefrontController.GetStorefrontMappings)
+				storefrontRoutes.PUT("/:connectionId/mappings", storefrontController.SetStorefrontMapping)
+				storefrontRoutes.DELETE("/:connectionId/mappings/:productId", storefrontController.DeleteStorefrontMapping)
+				storefrontRoutes.GET("/:connectionId/orders", storefrontController.GetStorefrontOrders)
+				storefrontRoutes.POST("/:connectionId/orders/:orderId/resolve", storefrontController.ResolveStorefrontOrder)
+			}
+
+			// Bank and wallet statements matched against recorded payments; owners only
+			reconciliationRoutes := businessSpecific.Group("/reconciliation")
+			reconciliationRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				reconciliationRoutes.POST("/statements", reconciliationController.ImportStatement)
+				reconciliationRoutes.GET("/statements", reconciliationController.GetStatements)
+				reconciliationRoutes.GET("/statements/:statementId", reconciliationController.GetStatement)
+				reconciliationRoutes.DELETE("/statements/:statementId", reconciliationController.DeleteStatement)
+				reconciliationRoutes.GET("/lines", reconciliationController.GetStatementLines)
+				reconciliationRoutes.PUT("/lines/:lineId/match", reconciliationController.MatchStatementLine)
+				reconciliationRoutes.DELETE("/lines/:lineId/match", reconciliationController.UnmatchStatementLine)
+				reconciliationRoutes.POST("/lines/:lineId/ignore", reconciliationController.IgnoreStatementLine)
+				reconciliationRoutes.POST("/auto-match", reconciliationController.AutoMatchStatementLines)
+				reconciliationRoutes.GET("/report", reconciliationController.GetReconciliationReport)
+			}
+
+			// Chart of accounts, journal and trial balance; owners only
+			accountingRoutes := businessSpecific.Group("/accounting")
+			accountingRoutes.Use(Infrastructure.RequireTenantRole(Infrastructure.TenantRoleOwner, Infrastructure.TenantRoleAdmin))
+			{
+				accountingRoutes.GET("/settings", conditional, accountingController.GetAccountingSettings)
+				accountingRoutes.PATCH("/settings", accountingController.UpdateAccountingSettings)
+				accountingRoutes.GET("/accounts", accountingController.GetAccounts)
+				accountingRoutes.POST("/accounts", accountingController.CreateAccount)
+				accountingRoutes.PATCH("/accounts/:accountId", accountingController.UpdateAccount)
+				accountingRoutes.GET("/journal", accountingController.GetJournal)
+				accountingRoutes.GET("/trial-balance", accountingController.GetTrialBalance)
+			}
+
+			// Loyalty routes
+			loyaltyRoutes := businessSpecific.Group("/loyalty")
+			{
+				loyaltyRoutes.GET("/program", loyaltyController.GetProgram)
+				loyaltyRoutes.PUT("/program", loyaltyController.UpdateProgram)
+				loyaltyRoutes.GET("/report", loyaltyController.GetReport)
+				loyaltyRoutes.GET("/accounts", loyaltyController.GetAccounts)
+				loyaltyRoutes.GET("/accounts/lookup", loyaltyController.LookupBalance)
+				loyaltyRoutes.GET("/accounts/:accountId/transactions", loyaltyController.GetTransactions)
+				loyaltyRoutes.POST("/accounts/:accountId/adjust", loyaltyController.AdjustPoints)
 			}
 
 			// Sync routes
 			syncRoutes := businessSpecific.Group("/sync")
+			syncRoutes.Use(invalidateAll, container.Search.Invalidates(), container.Catalog.Invalidates())
 			{
 				// Batch endpoint - 1 restore per hour per device (ADDED)
 				syncRoutes.POST("/batch", 
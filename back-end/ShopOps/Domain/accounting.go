@@ -0,0 +1,270 @@
+package Domain
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountType string
+
+const (
+	AccountTypeAsset     AccountType = "asset"
+	AccountTypeLiability AccountType = "liability"
+	AccountTypeEquity    AccountType = "equity"
+	AccountTypeIncome    AccountType = "income"
+	AccountTypeExpense   AccountType = "expense"
+)
+
+func (t AccountType) IsValid() bool {
+	switch t {
+	case AccountTypeAsset, AccountTypeLiability, AccountTypeEquity, AccountTypeIncome, AccountTypeExpense:
+		return true
+	}
+	return false
+}
+
+// DebitNormal is whether the account's balance grows with debits
+func (t AccountType) DebitNormal() bool {
+	return t == AccountTypeAsset || t == AccountTypeExpense
+}
+
+// AccountRole is what the posting engine uses an account for. Each role is held
+// by at most one account of the shop; moving a role to another account sends
+// later postings there.
+type AccountRole string
+
+const (
+	AccountRoleCash          AccountRole = "cash"
+	AccountRoleBank          AccountRole = "bank"
+	AccountRoleMobileMoney   AccountRole = "mobile_money"
+	AccountRoleCard          AccountRole = "card" // Card takings not yet settled by the bank
+	AccountRoleOtherReceipts AccountRole = "other_receipts"
+	AccountRoleReceivable    AccountRole = "receivable" // Credit sales and payments still pending
+	AccountRoleInventory     AccountRole = "inventory"
+	AccountRoleGiftCards     AccountRole = "gift_cards" // Gift card balances owed to holders
+	AccountRoleLoyalty       AccountRole = "loyalty"    // Points redeemed against sales
+	AccountRoleDeposits      AccountRole = "deposits"   // Container deposits owed back to customers
+	AccountRolePayable       AccountRole = "payable"
+	AccountRoleTaxPayable    AccountRole = "tax_payable"
+	AccountRoleRevenue       AccountRole = "revenue"
+	AccountRoleReturns       AccountRole = "returns"
+	AccountRoleCOGS          AccountRole = "cogs"
+	AccountRoleCashRounding  AccountRole = "cash_rounding" // What rounding cash at the till gained or lost
+)
+
+func (r AccountRole) IsValid() bool {
+	switch r {
+	case AccountRoleCash, AccountRoleBank, AccountRoleMobileMoney, AccountRoleCard, AccountRoleOtherReceipts,
+		AccountRoleReceivable, AccountRoleInventory, AccountRoleGiftCards, AccountRoleLoyalty, AccountRoleDeposits, AccountRolePayable,
+		AccountRoleTaxPayable, AccountRoleRevenue, AccountRoleReturns, AccountRoleCOGS, AccountRoleCashRounding:
+		return true
+	}
+	category, ok := strings.CutPrefix(string(r), "expense:")
+	if !ok {
+		return false
+	}
+	switch ExpenseCategory(category) {
+	case ExpenseCategoryRent, ExpenseCategoryUtilities, ExpenseCategoryStockPurchase, ExpenseCategoryTransport,
+		ExpenseCategorySalaries, ExpenseCategoryMarketing, ExpenseCategoryMaintenance, ExpenseCategoryOther:
+		return true
+	}
+	return false
+}
+
+// ExpenseAccountRole is the role of the account an expense category posts to
+func ExpenseAccountRole(category ExpenseCategory) AccountRole {
+	return AccountRole("expense:" + string(category))
+}
+
+// PaymentAccountRole is the role of the account money paid by method lands in
+func PaymentAccountRole(method PaymentMethod) AccountRole {
+	switch method {
+	case PaymentMethodCash:
+		return AccountRoleCash
+	case PaymentMethodBank:
+		return AccountRoleBank
+	case PaymentMethodMobile:
+		return AccountRoleMobileMoney
+	case PaymentMethodCard:
+		return AccountRoleCard
+	case PaymentMethodCredit:
+		return AccountRoleReceivable
+	case PaymentMethodGiftCard:
+		return AccountRoleGiftCards
+	case PaymentMethodLoyalty:
+		return AccountRoleLoyalty
+	}
+	return AccountRoleOtherReceipts
+}
+
+// Account is one account of a shop's chart of accounts
+type Account struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Code       string             `bson:"code" json:"code"`
+	Name       string             `bson:"name" json:"name"`
+	Type       AccountType        `bson:"type" json:"type"`
+	Role       AccountRole        `bson:"role,omitempty" json:"role,omitempty"`
+	Active     bool               `bson:"active" json:"active"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// DefaultChartOfAccounts is the chart a shop starts with when it turns accounting on
+var DefaultChartOfAccounts = []Account{
+	{Code: "1000", Name: "Cash on hand", Type: AccountTypeAsset, Role: AccountRoleCash},
+	{Code: "1010", Name: "Bank", Type: AccountTypeAsset, Role: AccountRoleBank},
+	{Code: "1020", Name: "Mobile money", Type: AccountTypeAsset, Role: AccountRoleMobileMoney},
+	{Code: "1030", Name: "Card clearing", Type: AccountTypeAsset, Role: AccountRoleCard},
+	{Code: "1090", Name: "Other receipts", Type: AccountTypeAsset, Role: AccountRoleOtherReceipts},
+	{Code: "1100", Name: "Accounts receivable", Type: AccountTypeAsset, Role: AccountRoleReceivable},
+	{Code: "1200", Name: "Inventory", Type: AccountTypeAsset, Role: AccountRoleInventory},
+	{Code: "2000", Name: "Accounts payable", Type: AccountTypeLiability, Role: AccountRolePayable},
+	{Code: "2100", Name: "VAT payable", Type: AccountTypeLiability, Role: AccountRoleTaxPayable},
+	{Code: "2200", Name: "Gift cards outstanding", Type: AccountTypeLiability, Role: AccountRoleGiftCards},
+	{Code: "2300", Name: "Loyalty points outstanding", Type: AccountTypeLiability, Role: AccountRoleLoyalty},
+	{Code: "2400", Name: "Container deposits held", Type: AccountTypeLiability, Role: AccountRoleDeposits},
+	{Code: "3000", Name: "Owner's equity", Type: AccountTypeEquity},
+	{Code: "4000", Name: "Sales", Type: AccountTypeIncome, Role: AccountRoleRevenue},
+	{Code: "4100", Name: "Sales returns", Type: AccountTypeIncome, Role: AccountRoleReturns},
+	{Code: "4900", Name: "Cash rounding", Type: AccountTypeIncome, Role: AccountRoleCashRounding},
+	{Code: "5000", Name: "Cost of goods sold", Type: AccountTypeExpense, Role: AccountRoleCOGS},
+	{Code: "6000", Name: "Rent", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryRent)},
+	{Code: "6010", Name: "Utilities", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryUtilities)},
+	{Code: "6020", Name: "Stock purchases", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryStockPurchase)},
+	{Code: "6030", Name: "Transport", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryTransport)},
+	{Code: "6040", Name: "Salaries", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategorySalaries)},
+	{Code: "6050", Name: "Marketing", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryMarketing)},
+	{Code: "6060", Name: "Maintenance", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryMaintenance)},
+	{Code: "6900", Name: "Other expenses", Type: AccountTypeExpense, Role: ExpenseAccountRole(ExpenseCategoryOther)},
+}
+
+// AccountingSettings turns automatic posting on for a shop
+type AccountingSettings struct {
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Enabled    bool               `bson:"enabled" json:"enabled"`
+	// Sales, expenses and supplier bills dated before this are not posted; the
+	// opening balances carry them
+	StartDate time.Time `bson:"start_date" json:"start_date"`
+	// Changes up to this time have been posted
+	PostedThrough *time.Time `bson:"posted_through,omitempty" json:"posted_through,omitempty"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"` // Why the last posting run stopped
+	UpdatedAt     time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// AccountingPostingOverlap is how far back each posting run looks past the last
+// one, for changes saved while it ran; reposting an unchanged document does nothing
+const AccountingPostingOverlap = 2 * time.Minute
+
+// JournalSource is the kind of document a journal entry was posted for
+type JournalSource string
+
+const (
+	JournalSourceSale       JournalSource = "sale"
+	JournalSourceSaleReturn JournalSource = "sale_return"
+	JournalSourceExpense    JournalSource = "expense"
+	JournalSourcePayable    JournalSource = "payable" // Supplier invoice, payment or credit note
+)
+
+// JournalEntry is a balanced posting to the ledger. Its lines are never changed:
+// when their document changes or is voided, the entry is reversed by another
+// and, if there is still something to post, a new one is posted.
+type JournalEntry struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Date        time.Time           `bson:"date" json:"date"`
+	Description string              `bson:"description" json:"description"`
+	Source      JournalSource       `bson:"source" json:"source"`
+	SourceID    primitive.ObjectID  `bson:"source_id" json:"source_id"`
+	Lines       []JournalLine       `bson:"lines" json:"lines"`
+	Open        bool                `bson:"open" json:"open"`                             // The document's current posting
+	Reverses    *primitive.ObjectID `bson:"reverses,omitempty" json:"reverses,omitempty"` // Set on reversals
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type JournalLine struct {
+	AccountID   primitive.ObjectID `bson:"account_id" json:"account_id"`
+	AccountCode string             `bson:"account_code" json:"account_code"`
+	Debit       Money              `bson:"debit" json:"debit"`
+	Credit      Money              `bson:"credit" json:"credit"`
+}
+
+type CreateAccountRequest struct {
+	Code string      `json:"code" validate:"required,max=20"`
+	Name string      `json:"name" validate:"required,max=100"`
+	Type AccountType `json:"type" validate:"required,oneof=asset liability equity income expense"`
+	Role AccountRole `json:"role,omitempty"`
+}
+
+type UpdateAccountRequest struct {
+	Code   *string      `json:"code,omitempty" validate:"omitempty,min=1,max=20"`
+	Name   *string      `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Role   *AccountRole `json:"role,omitempty"` // Empty to take the account's role away
+	Active *bool        `json:"active,omitempty"`
+}
+
+type UpdateAccountingSettingsRequest struct {
+	Enabled   *bool      `json:"enabled,omitempty"`
+	StartDate *time.Time `json:"start_date,omitempty"`
+}
+
+type JournalFilters struct {
+	StartDate *time.Time
+	EndDate   *time.Time
+	Source    *JournalSource
+	SourceID  *string
+	AccountID *string
+	Limit     int
+	Offset    int
+}
+
+// TrialBalance lists every account's debit or credit balance as of a date; the
+// two columns agree when the ledger is in balance
+type TrialBalance struct {
+	AsOf        time.Time         `json:"as_of"`
+	Rows        []TrialBalanceRow `json:"rows"`
+	TotalDebit  Money             `json:"total_debit"`
+	TotalCredit Money             `json:"total_credit"`
+	Balanced    bool              `json:"balanced"`
+}
+
+type TrialBalanceRow struct {
+	AccountID primitive.ObjectID `json:"account_id"`
+	Code      string             `json:"code"`
+	Name      string             `json:"name"`
+	Type      AccountType        `json:"type"`
+	Debit     Money              `json:"debit"`
+	Credit    Money              `json:"credit"`
+}
+
+// AccountTotals is what was posted to an account
+type AccountTotals struct {
+	AccountID primitive.ObjectID `bson:"_id"`
+	Debit     Money              `bson:"debit"`
+	Credit    Money              `bson:"credit"`
+}
+
+type AccountingRepository interface {
+	FindSettings(businessID string) (*AccountingSettings, error)
+	SaveSettings(settings *AccountingSettings) error
+	FindEnabledSettings() ([]AccountingSettings, error)
+
+	CreateAccounts(accounts []Account) error
+	FindAccounts(businessID string) ([]Account, error)
+	FindAccountByID(id string) (*Account, error)
+	// UpdateAccount saves the account, taking its role from any other account of the shop
+	UpdateAccount(account *Account) error
+
+	// FindOpenEntries returns the current postings of the documents, by document ID
+	FindOpenEntries(businessID primitive.ObjectID, source JournalSource, sourceIDs []primitive.ObjectID) (map[primitive.ObjectID]JournalEntry, error)
+	// CreateEntry posts the entry; false when the document already has a current
+	// posting or, for a reversal, the entry was reversed already
+	CreateEntry(entry *JournalEntry) (bool, error)
+	// CloseEntry marks a reversed entry as no longer the document's current posting
+	CloseEntry(id primitive.ObjectID) error
+	FindEntries(businessID string, filters JournalFilters) ([]JournalEntry, error)
+	// GetAccountTotals sums what was posted to each account up to asOf
+	GetAccountTotals(businessID string, asOf time.Time) ([]AccountTotals, error)
+}
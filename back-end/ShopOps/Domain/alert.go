@@ -0,0 +1,83 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Alert is an anomaly the background analyzer found in a shop's trading. Each
+// alert is raised once per key, so a condition that persists is not repeated.
+type Alert struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID     primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Type           AlertType           `bson:"type" json:"type"`
+	Severity       AlertSeverity       `bson:"severity" json:"severity"`
+	Key            string              `bson:"key" json:"key"` // Type, day and device; unique per business
+	DeviceID       string              `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	Message        string              `bson:"message" json:"message"`
+	Value          float64             `bson:"value" json:"value"`       // What was observed
+	Expected       float64             `bson:"expected" json:"expected"` // Baseline it was judged against
+	Status         AlertStatus         `bson:"status" json:"status"`
+	AcknowledgedBy *primitive.ObjectID `bson:"acknowledged_by,omitempty" json:"acknowledged_by,omitempty"`
+	AcknowledgedAt *time.Time          `bson:"acknowledged_at,omitempty" json:"acknowledged_at,omitempty"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type AlertType string
+
+const (
+	AlertTypeRevenueDrop AlertType = "revenue_drop" // Sales so far today well below the usual for this time
+	AlertTypeRefundSpike AlertType = "refund_spike" // Many more refunds today than usual
+	AlertTypeIdleTill    AlertType = "idle_till"    // Someone is clocked in on a till that has not sold for hours
+)
+
+func (t AlertType) IsValid() bool {
+	switch t {
+	case AlertTypeRevenueDrop, AlertTypeRefundSpike, AlertTypeIdleTill:
+		return true
+	}
+	return false
+}
+
+type AlertSeverity string
+
+const (
+	AlertSeverityWarning  AlertSeverity = "warning"
+	AlertSeverityCritical AlertSeverity = "critical"
+)
+
+type AlertStatus string
+
+const (
+	AlertStatusOpen         AlertStatus = "open"
+	AlertStatusAcknowledged AlertStatus = "acknowledged"
+)
+
+type AlertFilters struct {
+	Type   *AlertType
+	Status *AlertStatus
+	Limit  int
+	Offset int
+}
+
+// RefundActivity is the sales refunded within a window
+type RefundActivity struct {
+	Refunds int     `bson:"refunds" json:"refunds"`
+	Amount  float64 `bson:"amount" json:"amount"`
+}
+
+type AlertRepository interface {
+	// Raise stores the alert unless one with the same key exists, reporting whether it was new
+	Raise(alert *Alert) (bool, error)
+	// FindInBusiness returns the alert when it was raised for the shop
+	FindInBusiness(ctx context.Context, id string) (*Alert, error)
+	Find(businessID string, filters AlertFilters) ([]Alert, error)
+	Acknowledge(id string, userID primitive.ObjectID, at time.Time) error
+
+	// RefundActivity counts sales marked refunded between start and end
+	RefundActivity(businessID string, start, end time.Time) (*RefundActivity, error)
+	// LastSaleAt is when the device last rang up a sale, nil if never
+	LastSaleAt(businessID, deviceID string) (*time.Time, error)
+}
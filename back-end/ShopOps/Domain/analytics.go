@@ -0,0 +1,169 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Pre-aggregated P&L rows are keyed by business day. Day is midnight UTC of the
+// calendar date in the business timezone, so ranges compare as plain dates.
+
+// PnLDailyProduct is one product's completed sales on one business day
+type PnLDailyProduct struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID   primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Day          time.Time           `bson:"day" json:"day"`
+	ProductID    *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"` // Nil for sales without a catalog product
+	DeviceID     string              `bson:"device_id,omitempty" json:"device_id,omitempty"`   // Till the sales were rung up on
+	ProductName  string              `bson:"product_name,omitempty" json:"product_name,omitempty"`
+	Category     string              `bson:"category,omitempty" json:"category,omitempty"`
+	Quantity     float64             `bson:"quantity" json:"quantity"`
+	Revenue      Money               `bson:"revenue" json:"revenue"`
+	COGS         Money               `bson:"cogs" json:"cogs"`
+	Transactions int                 `bson:"transactions" json:"transactions"`
+	UpdatedAt    time.Time           `bson:"updated_at" json:"updated_at"`
+
+	// Further grouping for custom reports
+	EmployeeID    *primitive.ObjectID `bson:"employee_id,omitempty" json:"employee_id,omitempty"` // Cashier credited with the sales
+	PaymentMethod PaymentMethod       `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+}
+
+// PnLDailyExpense is one expense category's total on one business day
+type PnLDailyExpense struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Day        time.Time          `bson:"day" json:"day"`
+	Category   ExpenseCategory    `bson:"category" json:"category"`
+	Amount     Money              `bson:"amount" json:"amount"`
+	Count      int                `bson:"count" json:"count"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// PnLDayTotal is the whole business on one day
+type PnLDayTotal struct {
+	Day      time.Time `bson:"_id" json:"day"`
+	Revenue  Money     `bson:"revenue" json:"revenue"`
+	COGS     Money     `bson:"cogs" json:"cogs"`
+	Expenses Money     `bson:"expenses" json:"expenses"`
+}
+
+type PnLLine struct {
+	Period      string  `json:"period"`
+	Revenue     Money   `json:"revenue"`
+	COGS        Money   `json:"cogs"`
+	GrossProfit Money   `json:"gross_profit"`
+	GrossMargin float64 `json:"gross_margin"` // Percent of revenue
+	Expenses    Money   `json:"expenses"`
+	NetProfit   Money   `json:"net_profit"`
+	NetMargin   float64 `json:"net_margin"` // Percent of revenue
+}
+
+type PnLReport struct {
+	StartDate   string     `json:"start_date"`
+	EndDate     string     `json:"end_date"`
+	Granularity PeriodType `json:"granularity"`
+	Totals      PnLLine    `json:"totals"`
+	Periods     []PnLLine  `json:"periods"`
+
+	// Calendar the periods are bucketed and labelled in
+	Calendar Calendar `json:"calendar"`
+}
+
+// MarginLine is revenue and gross margin for a category or a product
+type MarginLine struct {
+	ProductID    string  `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	Name         string  `bson:"name" json:"name"`
+	Category     string  `bson:"category,omitempty" json:"category,omitempty"`
+	Quantity     float64 `bson:"quantity" json:"quantity"`
+	Revenue      Money   `bson:"revenue" json:"revenue"`
+	COGS         Money   `bson:"cogs" json:"cogs"`
+	GrossProfit  Money   `bson:"-" json:"gross_profit"`
+	GrossMargin  float64 `bson:"-" json:"gross_margin"`
+	RevenueShare float64 `bson:"-" json:"revenue_share"` // Percent of revenue in the range
+}
+
+// MarginFilter narrows product margin queries
+type MarginFilter struct {
+	Category string
+	DeviceID string
+	Limit    int
+}
+
+type MarginReport struct {
+	StartDate string       `json:"start_date"`
+	EndDate   string       `json:"end_date"`
+	Lines     []MarginLine `json:"lines"`
+}
+
+// ProductDayQuantity is the quantity of one product sold on one business day
+type ProductDayQuantity struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Day       time.Time          `bson:"day" json:"day"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+}
+
+type ABCClass string
+
+const (
+	ABCClassA ABCClass = "A"
+	ABCClassB ABCClass = "B"
+	ABCClassC ABCClass = "C"
+)
+
+type ABCLine struct {
+	MarginLine
+	Class           ABCClass `json:"class"`
+	CumulativeShare float64  `json:"cumulative_share"` // Percent of revenue up to and including this product
+}
+
+type ABCClassSummary struct {
+	Class        ABCClass `json:"class"`
+	Products     int      `json:"products"`
+	Revenue      Money    `json:"revenue"`
+	RevenueShare float64  `json:"revenue_share"`
+}
+
+// ABCReport ranks products by revenue: A products make up the first AThreshold percent
+// of revenue, B products the next share up to BThreshold, and C products the rest
+type ABCReport struct {
+	StartDate  string            `json:"start_date"`
+	EndDate    string            `json:"end_date"`
+	AThreshold float64           `json:"a_threshold"`
+	BThreshold float64           `json:"b_threshold"`
+	Classes    []ABCClassSummary `json:"classes"`
+	Lines      []ABCLine         `json:"lines"`
+}
+
+type TopSellerRank string
+
+const (
+	TopSellerRankQuantity TopSellerRank = "quantity"
+	TopSellerRankRevenue  TopSellerRank = "revenue"
+	TopSellerRankMargin   TopSellerRank = "margin" // Gross profit
+)
+
+type TopSellersReport struct {
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	RankBy    TopSellerRank `json:"rank_by"`
+	Lines     []MarginLine  `json:"lines"`
+}
+
+type AnalyticsRebuildResult struct {
+	StartDate   string `json:"start_date"`
+	EndDate     string `json:"end_date"`
+	DaysRebuilt int    `json:"days_rebuilt"`
+}
+
+type AnalyticsRepository interface {
+	// RebuildDay replaces the rows for day with fresh aggregates of sales and expenses in [start, end)
+	RebuildDay(businessID string, day, start, end time.Time) error
+
+	DailyTotals(businessID string, fromDay, toDay time.Time) ([]PnLDayTotal, error)
+	CategoryMargins(businessID string, fromDay, toDay time.Time) ([]MarginLine, error)
+	ProductMargins(businessID string, fromDay, toDay time.Time, filter MarginFilter) ([]MarginLine, error)
+	ProductDailyQuantities(businessID string, fromDay, toDay time.Time) ([]ProductDayQuantity, error)
+	// Pivot groups the product rows between the days by the given dimensions
+	Pivot(businessID string, fromDay, toDay time.Time, dimensions []ReportDimension, filter CustomReportFilter) ([]ReportPivotRow, error)
+}
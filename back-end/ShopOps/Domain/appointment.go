@@ -0,0 +1,108 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Appointment is a customer booked in for a service, with one of the shop's staff
+// when it has any. Slots are offered between the shop's booking hours; the
+// customer is texted a reminder ahead of it, and completing it records the sale.
+type Appointment struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID     primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	ServiceID      primitive.ObjectID  `bson:"service_id" json:"service_id"`
+	ServiceName    string              `bson:"service_name" json:"service_name"`
+	EmployeeID     *primitive.ObjectID `bson:"employee_id" json:"employee_id,omitempty"` // Nil in a shop without staff, where every appointment shares the one chair
+	CustomerName   string              `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	CustomerPhone  string              `bson:"customer_phone" json:"customer_phone" pii:"phone"`
+	StartsAt       time.Time           `bson:"starts_at" json:"starts_at"`
+	EndsAt         time.Time           `bson:"ends_at" json:"ends_at"`
+	Price          Money               `bson:"price" json:"price"` // The service's price when booked
+	Notes          string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	Status         AppointmentStatus   `bson:"status" json:"status"`
+	SaleID         *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"` // Recorded when completed
+	ReminderSentAt *time.Time          `bson:"reminder_sent_at,omitempty" json:"reminder_sent_at,omitempty"`
+	CreatedBy      primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+	ClosedAt       *time.Time          `bson:"closed_at,omitempty" json:"closed_at,omitempty"` // When it was completed, cancelled or marked a no-show
+}
+
+// Overlaps reports whether the appointment takes up any of from to to
+func (a *Appointment) Overlaps(from, to time.Time) bool {
+	return a.StartsAt.Before(to) && a.EndsAt.After(from)
+}
+
+type AppointmentStatus string
+
+const (
+	AppointmentBooked    AppointmentStatus = "booked"
+	AppointmentCompleted AppointmentStatus = "completed"
+	AppointmentCancelled AppointmentStatus = "cancelled"
+	AppointmentNoShow    AppointmentStatus = "no_show"
+)
+
+type CreateAppointmentRequest struct {
+	ServiceID     string    `json:"service_id" validate:"required"`
+	EmployeeID    *string   `json:"employee_id,omitempty"` // Whoever is free when left out
+	CustomerName  string    `json:"customer_name,omitempty" validate:"max=100"`
+	CustomerPhone string    `json:"customer_phone" validate:"required,phone"`
+	StartsAt      time.Time `json:"starts_at" validate:"required"`
+	Notes         string    `json:"notes,omitempty" validate:"max=500"`
+}
+
+// RescheduleAppointmentRequest moves a booked appointment, to another of the
+// staff when EmployeeID is given
+type RescheduleAppointmentRequest struct {
+	StartsAt   time.Time `json:"starts_at" validate:"required"`
+	EmployeeID *string   `json:"employee_id,omitempty"`
+}
+
+// CompleteAppointmentRequest records the sale of the service done
+type CompleteAppointmentRequest struct {
+	PaymentMethod PaymentMethod `json:"payment_method" validate:"required"`
+	UnitPrice     *Money        `json:"unit_price,omitempty" validate:"omitempty,gt=0,money"` // Defaults to the price when booked
+	Discount      Money         `json:"discount,omitempty" validate:"omitempty,money"`
+}
+
+type AppointmentFilters struct {
+	From          *time.Time // Starting at or after
+	To            *time.Time // Starting before
+	EmployeeID    *string
+	Status        *AppointmentStatus
+	CustomerPhone *string
+	Limit         int
+	Offset        int
+}
+
+// BookingSlot is a start time free for a service, with the staff free then
+type BookingSlot struct {
+	StartsAt    time.Time `json:"starts_at"`
+	EndsAt      time.Time `json:"ends_at"`
+	EmployeeIDs []string  `json:"employee_ids,omitempty"`
+}
+
+type AppointmentRepository interface {
+	// Create books the appointment; a conflict when the staff member already has
+	// one starting then
+	Create(appointment *Appointment) error
+	// FindInBusiness returns the appointment when it is with the shop
+	FindInBusiness(ctx context.Context, id string) (*Appointment, error)
+	// FindByBusiness lists appointments by start time
+	FindByBusiness(businessID string, filters AppointmentFilters) ([]Appointment, error)
+	// FindBooked returns the shop's booked appointments taking up any of from to to
+	FindBooked(businessID string, from, to time.Time) ([]Appointment, error)
+	// Reschedule moves the appointment if it is still booked, for a fresh
+	// reminder; false when it is not
+	Reschedule(appointment *Appointment) (bool, error)
+	// SetStatus moves the appointment to status if it is in from; false when it is not
+	SetStatus(id primitive.ObjectID, from, status AppointmentStatus, at time.Time) (bool, error)
+	SetSale(id, saleID primitive.ObjectID) error
+	// FindDueReminders returns booked appointments starting between now and
+	// before that the customer has not been reminded of
+	FindDueReminders(now, before time.Time) ([]Appointment, error)
+	MarkReminded(id primitive.ObjectID, at time.Time) error
+}
@@ -0,0 +1,47 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Sales and stock movements older than the archive age move out of the live
+// collections into ArchiveCollection(name), so the queries and indexes the shops use
+// every day stay small. Reports over old periods read both.
+var ArchivedCollections = []string{"sales", "stock_movements"}
+
+func ArchiveCollection(collection string) string {
+	return collection + "_archive"
+}
+
+// ArchiveRun is one pass of moving old records to the archive
+type ArchiveRun struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	Before      time.Time          `bson:"before" json:"before"`                                 // Records created before this were moved
+	Moved       map[string]int64   `bson:"moved" json:"moved"`                                   // By collection
+	Compacted   bool               `bson:"compacted" json:"compacted"`                           // Whether the live collections were compacted after
+	RequestedBy string             `bson:"requested_by,omitempty" json:"requested_by,omitempty"` // Empty for the daily run
+	Error       string             `bson:"error,omitempty" json:"error,omitempty"`
+	StartedAt   time.Time          `bson:"started_at" json:"started_at"`
+	FinishedAt  time.Time          `bson:"finished_at" json:"finished_at"`
+}
+
+type RunArchiveRequest struct {
+	// Move records created before this date, e.g. "2023-01-01"; the configured
+	// archive age when empty
+	Before string `json:"before,omitempty"`
+	// Compact the live collections afterwards to give the freed space back
+	Compact bool `json:"compact,omitempty"`
+}
+
+type ArchiveRepository interface {
+	// Move moves up to limit of collection's records created before before into its
+	// archive, oldest first, and returns how many it moved
+	Move(collection string, before time.Time, limit int) (int64, error)
+	// Compact rewrites the collection and its indexes to release the space left by
+	// moved records
+	Compact(collection string) error
+	SaveRun(run *ArchiveRun) error
+	FindRuns(limit int) ([]ArchiveRun, error)
+}
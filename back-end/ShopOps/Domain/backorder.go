@@ -0,0 +1,67 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Backorder is what a sale sold beyond the stock on hand, in a shop that allows
+// backorders. Stock coming in is set aside for the shop's backorders oldest
+// first, and the customer is told when theirs is all there to collect.
+type Backorder struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID `bson:"business_id" json:"business_id"`
+	SaleID        primitive.ObjectID `bson:"sale_id" json:"sale_id"`
+	ProductID     primitive.ObjectID `bson:"product_id" json:"product_id"`
+	CustomerName  string             `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	CustomerPhone string             `bson:"customer_phone,omitempty" json:"customer_phone,omitempty" pii:"phone"`
+	Quantity      float64            `bson:"quantity" json:"quantity"`
+	Allocated     float64            `bson:"allocated" json:"allocated"` // Taken out of stock for the customer so far
+	Status        BackorderStatus    `bson:"status" json:"status"`
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	ReadyAt       *time.Time         `bson:"ready_at,omitempty" json:"ready_at,omitempty"`
+	NotifiedAt    *time.Time         `bson:"notified_at,omitempty" json:"notified_at,omitempty"` // When the customer was texted that it is ready
+	CollectedAt   *time.Time         `bson:"collected_at,omitempty" json:"collected_at,omitempty"`
+	CancelledAt   *time.Time         `bson:"cancelled_at,omitempty" json:"cancelled_at,omitempty"`
+}
+
+// Outstanding is what is still to come in for the backorder
+func (b *Backorder) Outstanding() float64 {
+	return b.Quantity - b.Allocated
+}
+
+type BackorderStatus string
+
+const (
+	BackorderOpen      BackorderStatus = "open"  // Waiting for stock
+	BackorderReady     BackorderStatus = "ready" // All set aside, waiting for the customer
+	BackorderCollected BackorderStatus = "collected"
+	BackorderCancelled BackorderStatus = "cancelled" // What was set aside went back into stock
+)
+
+type BackorderFilters struct {
+	Status    *BackorderStatus
+	ProductID *string
+	Limit     int
+	Offset    int
+}
+
+type BackorderRepository interface {
+	Create(backorder *Backorder) error
+	// FindInBusiness returns the backorder when the shop owes it
+	FindInBusiness(ctx context.Context, id string) (*Backorder, error)
+	FindBySale(saleID primitive.ObjectID) (*Backorder, error)
+	FindByBusiness(businessID string, filters BackorderFilters) ([]Backorder, error)
+	// FindOpen returns the product's open backorders, oldest first
+	FindOpen(productID primitive.ObjectID) ([]Backorder, error)
+	// Allocate records quantity more set aside for the backorder, if it is still
+	// open with what backorder.Allocated says; false when it has moved on. A
+	// backorder allocated in full becomes ready, with a backorder.ready event.
+	Allocate(backorder *Backorder, quantity float64, at time.Time) (bool, error)
+	// SetStatus moves the backorder to status if it is in one of from; false when
+	// it is not
+	SetStatus(id primitive.ObjectID, from []BackorderStatus, status BackorderStatus, at time.Time) (bool, error)
+	MarkNotified(id primitive.ObjectID, at time.Time) error
+}
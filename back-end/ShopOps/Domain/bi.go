@@ -0,0 +1,150 @@
+package Domain
+
+import (
+	"encoding/base64"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BIKey lets a BI tool such as Metabase or Power BI read the shop's data feed. The
+// tools keep a static key rather than logging in, so a key reads the feed and
+// nothing else, and is counted against its own rate limit rather than the shop's.
+type BIKey struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Name       string             `bson:"name" json:"name"`
+	TokenHash  string             `bson:"token_hash" json:"-"`            // SHA-256 of the key; the key is shown once
+	IncludePII bool               `bson:"include_pii" json:"include_pii"` // Customers' names and phone numbers unmasked
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastUsedAt *time.Time         `bson:"last_used_at,omitempty" json:"last_used_at,omitempty"`
+}
+
+type CreateBIKeyRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+	// Send customers' names and phone numbers unmasked; only for callers who may
+	// see them
+	IncludePII bool `json:"include_pii,omitempty"`
+}
+
+// BIKeyTokenResponse carries a key's token, the only time it is shown
+type BIKeyTokenResponse struct {
+	BIKey
+	Token string `json:"token"`
+}
+
+// BIDataset is a collection the feed offers
+type BIDataset string
+
+const (
+	BIDatasetSales          BIDataset = "sales"
+	BIDatasetProducts       BIDataset = "products"
+	BIDatasetCustomers      BIDataset = "customers"
+	BIDatasetExpenses       BIDataset = "expenses"
+	BIDatasetStockMovements BIDataset = "stock_movements"
+)
+
+// BIDatasetInfo describes a dataset for the tool setting up its tables
+type BIDatasetInfo struct {
+	Name        BIDataset `json:"name"`
+	ChangedAt   string    `json:"changed_at"` // The field the feed is ordered and windowed by
+	Description string    `json:"description"`
+	Fields      []string  `json:"fields"`
+}
+
+const (
+	// The feed ends this long before now, so rows still being written, or not yet
+	// copied to the read replica, fall in the next window rather than being missed
+	BISnapshotLag = time.Minute
+
+	BIDefaultPageLimit = 500
+	BIMaxPageLimit     = 5000
+)
+
+// BIPageRequest is a page of a dataset as the tool asked for it. The first page
+// fixes the window; the pages after it only pass the cursor.
+type BIPageRequest struct {
+	Since      *time.Time
+	Cursor     string
+	Limit      int
+	Fields     []string // Every field when empty
+	IncludePII bool     // Customers' names and phone numbers unmasked
+}
+
+// BIQuery asks for one page of a dataset's rows changed in (Since, AsOf], oldest
+// change first. A sync keeps AsOf for every page, so rows changed while it reads
+// are left to the next sync, which starts from this one's AsOf.
+type BIQuery struct {
+	Since *time.Time // Nil for every row
+	AsOf  time.Time
+	After *BICursor
+	Limit int
+}
+
+// BICursor is where the next page of a sync starts: the window it reads and the
+// last row returned
+type BICursor struct {
+	Dataset   BIDataset          `bson:"d"`
+	Since     *time.Time         `bson:"s,omitempty"`
+	AsOf      time.Time          `bson:"a"`
+	ChangedAt time.Time          `bson:"c"`
+	ID        primitive.ObjectID `bson:"id"`
+}
+
+// Encode makes the cursor opaque for the client
+func (c BICursor) Encode() string {
+	data, err := bson.MarshalExtJSON(c, true, false)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeBICursor reads a cursor made by Encode for the dataset
+func DecodeBICursor(cursor string, dataset BIDataset) (*BICursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, NewAppError(ErrCodeInvalidArgument, "invalid cursor")
+	}
+
+	var decoded BICursor
+	if err := bson.UnmarshalExtJSON(data, true, &decoded); err != nil {
+		return nil, NewAppError(ErrCodeInvalidArgument, "invalid cursor")
+	}
+	if decoded.Dataset != dataset {
+		return nil, NewAppError(ErrCodeInvalidArgument, "cursor was made for dataset "+string(decoded.Dataset))
+	}
+
+	return &decoded, nil
+}
+
+// BIRecord is a row as read, with what orders it in the feed
+type BIRecord struct {
+	ID        primitive.ObjectID
+	ChangedAt time.Time
+	Data      interface{} // The Sale, Product, Customer, Expense or StockMovement
+}
+
+// BIPage is one page of a dataset. Rows are the records as the rest of the API
+// returns them, cut down to the fields asked for.
+type BIPage struct {
+	Dataset    BIDataset                `json:"dataset"`
+	Since      *time.Time               `json:"since,omitempty"`
+	AsOf       time.Time                `json:"as_of"` // Pass as since on the next sync
+	Rows       []map[string]interface{} `json:"rows"`
+	NextCursor string                   `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+type BIRepository interface {
+	CreateKey(key *BIKey) error
+	FindKeyByID(id string) (*BIKey, error)
+	FindKeyByTokenHash(hash string) (*BIKey, error)
+	FindKeysByBusiness(businessID string) ([]BIKey, error)
+	DeleteKey(id string) error
+	TouchKey(id primitive.ObjectID, at time.Time) error
+	// FindRecords reads a page of the dataset from the reporting database, archived
+	// sales and stock movements included
+	FindRecords(dataset BIDataset, businessID string, query BIQuery) ([]BIRecord, error)
+}
@@ -0,0 +1,166 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PlanCode names a subscription plan
+type PlanCode string
+
+const (
+	PlanFree  PlanCode = "free"
+	PlanBasic PlanCode = "basic"
+	PlanPro   PlanCode = "pro"
+)
+
+// Plan is what a shop pays for and what it gets. Limits of 0 are unlimited.
+type Plan struct {
+	Code          PlanCode `json:"code"`
+	Name          string   `json:"name"`
+	PriceETB      float64  `json:"price_etb"` // Per month, charged through Chapa
+	PriceUSD      float64  `json:"price_usd"` // Per month, charged through Stripe
+	MaxDevices    int      `json:"max_devices"`
+	ExportsPerDay int      `json:"exports_per_day"`
+}
+
+// Plans are the plans on offer. A shop without a paid or trial plan is on free.
+var Plans = map[PlanCode]Plan{
+	PlanFree:  {Code: PlanFree, Name: "Free", MaxDevices: 1, ExportsPerDay: 1},
+	PlanBasic: {Code: PlanBasic, Name: "Basic", PriceETB: 499, PriceUSD: 9, MaxDevices: 3, ExportsPerDay: 10},
+	PlanPro:   {Code: PlanPro, Name: "Pro", PriceETB: 1499, PriceUSD: 29},
+}
+
+// TrialPlan is the plan new shops try before paying
+const TrialPlan = PlanPro
+
+// PlanQuota is a daily allowance a plan limits
+type PlanQuota string
+
+const (
+	QuotaExports PlanQuota = "exports"
+)
+
+// Limit is the plan's daily allowance for the quota; 0 for unlimited
+func (p Plan) Limit(quota PlanQuota) int {
+	switch quota {
+	case QuotaExports:
+		return p.ExportsPerDay
+	default:
+		return 0
+	}
+}
+
+// BillingProvider collects subscription payments
+type BillingProvider string
+
+const (
+	BillingProviderChapa  BillingProvider = "chapa"
+	BillingProviderStripe BillingProvider = "stripe"
+)
+
+// Subscription is a shop's plan and where its payments stand. Chapa payments each buy
+// a month; Stripe renews on its own and reports each invoice.
+type Subscription struct {
+	ID                     primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID             primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Plan                   PlanCode           `bson:"plan" json:"plan"`
+	Status                 SubscriptionStatus `bson:"status" json:"status"`
+	Provider               BillingProvider    `bson:"provider,omitempty" json:"provider,omitempty"`
+	ProviderCustomerID     string             `bson:"provider_customer_id,omitempty" json:"-"`
+	ProviderSubscriptionID string             `bson:"provider_subscription_id,omitempty" json:"-"`
+	TrialEndsAt            *time.Time         `bson:"trial_ends_at,omitempty" json:"trial_ends_at,omitempty"`
+	CurrentPeriodEnd       *time.Time         `bson:"current_period_end,omitempty" json:"current_period_end,omitempty"`
+	GraceEndsAt            *time.Time         `bson:"grace_ends_at,omitempty" json:"grace_ends_at,omitempty"` // Set while past due
+	CancelAtPeriodEnd      bool               `bson:"cancel_at_period_end" json:"cancel_at_period_end"`
+	CanceledAt             *time.Time         `bson:"canceled_at,omitempty" json:"canceled_at,omitempty"`
+	CreatedAt              time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt              time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type SubscriptionStatus string
+
+const (
+	SubscriptionTrialing SubscriptionStatus = "trialing"
+	SubscriptionActive   SubscriptionStatus = "active"
+	// Payment is late; the plan keeps working until GraceEndsAt
+	SubscriptionPastDue  SubscriptionStatus = "past_due"
+	SubscriptionCanceled SubscriptionStatus = "canceled"
+	// The trial or grace period ran out; the shop is on the free plan
+	SubscriptionExpired SubscriptionStatus = "expired"
+)
+
+// EffectivePlan is the plan the shop gets at the time given, which falls back to free
+// once the trial, paid period or grace period is over
+func (s *Subscription) EffectivePlan(now time.Time) PlanCode {
+	if s == nil {
+		return PlanFree
+	}
+	switch s.Status {
+	case SubscriptionTrialing:
+		if s.TrialEndsAt != nil && now.Before(*s.TrialEndsAt) {
+			return s.Plan
+		}
+	case SubscriptionActive, SubscriptionCanceled:
+		if s.CurrentPeriodEnd != nil && now.Before(*s.CurrentPeriodEnd) {
+			return s.Plan
+		}
+	case SubscriptionPastDue:
+		if s.GraceEndsAt != nil && now.Before(*s.GraceEndsAt) {
+			return s.Plan
+		}
+	}
+	return PlanFree
+}
+
+// SubscriptionResponse is a shop's subscription with the plan it currently gets
+type SubscriptionResponse struct {
+	Subscription
+	EffectivePlan Plan `json:"effective_plan"`
+	Devices       int  `json:"devices"` // Devices used in the last 30 days
+}
+
+type CreateCheckoutRequest struct {
+	Plan      PlanCode        `json:"plan" validate:"required,oneof=basic pro"`
+	Provider  BillingProvider `json:"provider" validate:"required,oneof=chapa stripe"`
+	ReturnURL string          `json:"return_url" validate:"required,url"` // Where the owner lands after paying
+}
+
+type CheckoutResponse struct {
+	CheckoutURL string `json:"checkout_url"`
+}
+
+// BillingDevice is a till seen using a shop, counted against the plan's device limit
+type BillingDevice struct {
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	DeviceID   string             `bson:"device_id" json:"device_id"`
+	FirstSeen  time.Time          `bson:"first_seen" json:"first_seen"`
+	LastSeen   time.Time          `bson:"last_seen" json:"last_seen"`
+}
+
+// BillingDeviceWindow is how recently a device must have been used to take a slot
+const BillingDeviceWindow = 30 * 24 * time.Hour
+
+type SubscriptionRepository interface {
+	FindByBusiness(businessID string) (*Subscription, error)
+	FindByProviderSubscription(provider BillingProvider, subscriptionID string) (*Subscription, error)
+	// Create inserts the shop's subscription unless it has one, and returns the stored one
+	Create(subscription *Subscription) (*Subscription, error)
+	Update(subscription *Subscription) error
+	// FindLapsing returns subscriptions whose trial, period or grace ended before now
+	FindLapsing(now time.Time, limit int) ([]Subscription, error)
+
+	// RecordEvent stores a provider event ID, reporting false if it was seen before
+	RecordEvent(provider BillingProvider, eventID string) (bool, error)
+	// ForgetEvent removes a recorded event that could not be applied, so a retry is
+	ForgetEvent(provider BillingProvider, eventID string) error
+
+	// TouchDevice records the use of a device seen since the time given, reporting
+	// false if there is none, so a device that has not taken a slot can be checked first
+	TouchDevice(businessID, deviceID string, since, now time.Time) (bool, error)
+	AddDevice(businessID, deviceID string, now time.Time) error
+	CountDevices(businessID string, since time.Time) (int, error)
+	// UseQuota adds one to the shop's use of the quota on day and returns the new count
+	UseQuota(businessID string, quota PlanQuota, day string) (int, error)
+}
@@ -0,0 +1,103 @@
+package Domain
+
+// Branches are the businesses (shops) owned by one user. Consolidated reports add
+// their figures up; branches must share a currency to be consolidated.
+
+// BranchPnL is one branch's share of a consolidated P&L, for drilling down
+type BranchPnL struct {
+	BusinessID   string    `json:"business_id"`
+	Name         string    `json:"name"`
+	City         string    `json:"city,omitempty"`
+	Totals       PnLLine   `json:"totals"`
+	RevenueShare float64   `json:"revenue_share"` // Percent of consolidated revenue
+	Periods      []PnLLine `json:"periods"`
+}
+
+type ConsolidatedPnLReport struct {
+	StartDate   string      `json:"start_date"`
+	EndDate     string      `json:"end_date"`
+	Granularity PeriodType  `json:"granularity"`
+	Calendar    Calendar    `json:"calendar"`
+	Currency    string      `json:"currency"`
+	Totals      PnLLine     `json:"totals"`
+	Periods     []PnLLine   `json:"periods"`
+	Branches    []BranchPnL `json:"branches"`
+}
+
+// BranchMarginLine is one branch's figures for a consolidated category
+type BranchMarginLine struct {
+	BusinessID   string  `json:"business_id"`
+	Name         string  `json:"name"`
+	Quantity     float64 `json:"quantity"`
+	Revenue      Money   `json:"revenue"`
+	COGS         Money   `json:"cogs"`
+	GrossProfit  Money   `json:"gross_profit"`
+	GrossMargin  float64 `json:"gross_margin"`
+	RevenueShare float64 `json:"revenue_share"` // Percent of the category's revenue
+}
+
+type ConsolidatedMarginLine struct {
+	MarginLine
+	Branches []BranchMarginLine `json:"branches"`
+}
+
+type ConsolidatedMarginReport struct {
+	StartDate string                   `json:"start_date"`
+	EndDate   string                   `json:"end_date"`
+	Currency  string                   `json:"currency"`
+	Lines     []ConsolidatedMarginLine `json:"lines"`
+}
+
+type BranchRankBy string
+
+const (
+	BranchRankRevenue       BranchRankBy = "revenue"
+	BranchRankGrossProfit   BranchRankBy = "gross_profit"
+	BranchRankGrossMargin   BranchRankBy = "gross_margin"
+	BranchRankNetProfit     BranchRankBy = "net_profit"
+	BranchRankNetMargin     BranchRankBy = "net_margin"
+	BranchRankTransactions  BranchRankBy = "transactions"
+	BranchRankAverageTicket BranchRankBy = "average_ticket"
+)
+
+func (r BranchRankBy) IsValid() bool {
+	switch r {
+	case BranchRankRevenue, BranchRankGrossProfit, BranchRankGrossMargin, BranchRankNetProfit,
+		BranchRankNetMargin, BranchRankTransactions, BranchRankAverageTicket:
+		return true
+	}
+	return false
+}
+
+type BranchComparisonLine struct {
+	BusinessID    string  `json:"business_id"`
+	Name          string  `json:"name"`
+	City          string  `json:"city,omitempty"`
+	Rank          int     `json:"rank"`
+	Revenue       Money   `json:"revenue"`
+	GrossProfit   Money   `json:"gross_profit"`
+	GrossMargin   float64 `json:"gross_margin"`
+	Expenses      Money   `json:"expenses"`
+	NetProfit     Money   `json:"net_profit"`
+	NetMargin     float64 `json:"net_margin"`
+	Transactions  int     `json:"transactions"`
+	AverageTicket Money   `json:"average_ticket"`
+	RevenueShare  float64 `json:"revenue_share"`
+	VsAverage     float64 `json:"vs_average"` // Ranked metric as a percent of the branch average
+
+	// Set when comparing with a prior period
+	Growth map[string]MetricDelta `json:"growth,omitempty"`
+}
+
+// BranchComparison ranks branches side by side on one metric
+type BranchComparison struct {
+	StartDate     string                 `json:"start_date"`
+	EndDate       string                 `json:"end_date"`
+	Currency      string                 `json:"currency"`
+	RankBy        BranchRankBy           `json:"rank_by"`
+	Compare       CompareMode            `json:"compare,omitempty"`
+	PreviousStart string                 `json:"previous_start,omitempty"`
+	PreviousEnd   string                 `json:"previous_end,omitempty"`
+	Branches      []BranchComparisonLine `json:"branches"`
+	Average       BranchComparisonLine   `json:"average"`
+}
@@ -7,23 +7,32 @@ import (
 )
 
 type Business struct {
-	ID           primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	UserID       primitive.ObjectID `bson:"user_id" json:"user_id"`
-	Name         string             `bson:"name" json:"name" validate:"required"`
-	Description  string             `bson:"description,omitempty" json:"description,omitempty"`
-	BusinessType string             `bson:"business_type" json:"business_type" validate:"required"`
-	Currency     string             `bson:"currency" json:"currency" validate:"required"`
-	Timezone     string             `bson:"timezone" json:"timezone"`
-	Address      string             `bson:"address,omitempty" json:"address,omitempty"`
-	City         string             `bson:"city,omitempty" json:"city,omitempty"`
-	Country      string             `bson:"country,omitempty" json:"country,omitempty"`
-	Phone        string             `bson:"phone,omitempty" json:"phone,omitempty"`
-	Email        string             `bson:"email,omitempty" json:"email,omitempty"`
-	Status       BusinessStatus     `bson:"status" json:"status"`
-	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID        primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Name          string             `bson:"name" json:"name" validate:"required"`
+	Description   string             `bson:"description,omitempty" json:"description,omitempty"`
+	BusinessType  string             `bson:"business_type" json:"business_type" validate:"required"`
+	Currency      string             `bson:"currency" json:"currency" validate:"required"`
+	Timezone      string             `bson:"timezone" json:"timezone"`                     // IANA name; reports split days in it
+	DayCutoffHour int                `bson:"day_cutoff_hour" json:"day_cutoff_hour"`       // Hour business days start, so late sales count toward the day before; midnight when 0
+	Language      Language           `bson:"language,omitempty" json:"language,omitempty"` // Default for the shop's staff and customers; English when empty
+	Address       string             `bson:"address,omitempty" json:"address,omitempty"`
+	City          string             `bson:"city,omitempty" json:"city,omitempty"`
+	Country       string             `bson:"country,omitempty" json:"country,omitempty"`
+	Phone         string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Email         string             `bson:"email,omitempty" json:"email,omitempty"`
+	Status        BusinessStatus     `bson:"status" json:"status"`
+	DemoExpiresAt *time.Time         `bson:"demo_expires_at,omitempty" json:"demo_expires_at,omitempty"` // Set on demo shops, which are deleted with their account after it
+	CreatedAt     time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
 }
 
+// DefaultTimezone is used for shops created without one
+const DefaultTimezone = "Africa/Addis_Ababa"
+
+// MaxDayCutoffHour is the latest a business day may start
+const MaxDayCutoffHour = 12
+
 type BusinessStatus string
 
 const (
@@ -33,29 +42,33 @@ const (
 )
 
 type CreateBusinessRequest struct {
-	Name         string `json:"name" validate:"required"`
-	Description  string `json:"description,omitempty"`
-	BusinessType string `json:"business_type" validate:"required"`
-	Currency     string `json:"currency" validate:"required"`
-	Timezone     string `json:"timezone,omitempty"`
-	Address      string `json:"address,omitempty"`
-	City         string `json:"city,omitempty"`
-	Country      string `json:"country,omitempty"`
-	Phone        string `json:"phone,omitempty"`
-	Email        string `json:"email,omitempty"`
+	Name          string `json:"name" validate:"required"`
+	Description   string `json:"description,omitempty"`
+	BusinessType  string `json:"business_type" validate:"required"`
+	Currency      string `json:"currency" validate:"required"`
+	Timezone      string `json:"timezone,omitempty"`
+	DayCutoffHour *int   `json:"day_cutoff_hour,omitempty" validate:"omitempty,min=0,max=12"`
+	Language      string `json:"language,omitempty" validate:"omitempty,oneof=en am om"`
+	Address       string `json:"address,omitempty"`
+	City          string `json:"city,omitempty"`
+	Country       string `json:"country,omitempty"`
+	Phone         string `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email         string `json:"email,omitempty" validate:"omitempty,email"`
 }
 
 type UpdateBusinessRequest struct {
-	Name         string `json:"name,omitempty"`
-	Description  string `json:"description,omitempty"`
-	BusinessType string `json:"business_type,omitempty"`
-	Currency     string `json:"currency,omitempty"`
-	Timezone     string `json:"timezone,omitempty"`
-	Address      string `json:"address,omitempty"`
-	City         string `json:"city,omitempty"`
-	Country      string `json:"country,omitempty"`
-	Phone        string `json:"phone,omitempty"`
-	Email        string `json:"email,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Description   string `json:"description,omitempty"`
+	BusinessType  string `json:"business_type,omitempty"`
+	Currency      string `json:"currency,omitempty"`
+	Timezone      string `json:"timezone,omitempty"`
+	DayCutoffHour *int   `json:"day_cutoff_hour,omitempty" validate:"omitempty,min=0,max=12"`
+	Language      string `json:"language,omitempty" validate:"omitempty,oneof=en am om"`
+	Address       string `json:"address,omitempty"`
+	City          string `json:"city,omitempty"`
+	Country       string `json:"country,omitempty"`
+	Phone         string `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email         string `json:"email,omitempty" validate:"omitempty,email"`
 }
 
 type BusinessRepository interface {
@@ -66,4 +79,8 @@ type BusinessRepository interface {
 	UpdateStatus(id string, status BusinessStatus) error
 	Delete(id string) error
 	FindByPhone(phone string) (*Business, error)
+	// FindActive returns every active business, for background jobs
+	FindActive() ([]Business, error)
+	// Search matches businesses by name, phone or email, for support lookups
+	Search(query string, limit int) ([]Business, error)
 }
@@ -0,0 +1,134 @@
+package Domain
+
+import (
+	"fmt"
+	"time"
+)
+
+// Calendar is the calendar reports bucket and label dates in. Dates are always
+// stored Gregorian; only reporting changes.
+type Calendar string
+
+const (
+	CalendarGregorian Calendar = "gregorian"
+	CalendarEthiopian Calendar = "ethiopian"
+)
+
+func (c Calendar) IsValid() bool {
+	return c == CalendarGregorian || c == CalendarEthiopian
+}
+
+// EthiopianDate is a date in the Ethiopian calendar: twelve 30-day months and
+// Pagume, a 13th month of 5 days (6 in the year before a Gregorian leap year)
+type EthiopianDate struct {
+	Year  int `json:"year"`
+	Month int `json:"month"` // 1 = Meskerem ... 13 = Pagume
+	Day   int `json:"day"`
+}
+
+var EthiopianMonthNames = [13]string{
+	"Meskerem", "Tikimt", "Hidar", "Tahsas", "Tir", "Yekatit", "Megabit",
+	"Miyazya", "Ginbot", "Sene", "Hamle", "Nehase", "Pagume",
+}
+
+// The Ethiopian fiscal year starts on Hamle 1 (early July)
+const ethiopianFiscalYearStartMonth = 11
+
+// Julian day numbers of the Ethiopian epoch (Amete Mihret) and of 1970-01-01
+const (
+	ethiopianEpochJDN = 1723856
+	unixEpochJDN      = 2440588
+)
+
+// ToEthiopian converts the calendar date of t, read in t's location
+func ToEthiopian(t time.Time) EthiopianDate {
+	jdn := gregorianJDN(t) - ethiopianEpochJDN
+	r := jdn % 1461
+	n := r%365 + 365*(r/1460)
+
+	return EthiopianDate{
+		Year:  4*(jdn/1461) + r/365 - r/1460,
+		Month: n/30 + 1,
+		Day:   n%30 + 1,
+	}
+}
+
+// FromEthiopian returns midnight UTC of the Gregorian date matching the Ethiopian date
+func FromEthiopian(year, month, day int) time.Time {
+	jdn := ethiopianEpochJDN + 365 + 365*(year-1) + year/4 + 30*month + day - 31
+	return time.Unix(int64(jdn-unixEpochJDN)*86400, 0).UTC()
+}
+
+// ParseEthiopian reads an Ethiopian date written YYYY-MM-DD
+func ParseEthiopian(value string) (time.Time, error) {
+	var year, month, day int
+	if _, err := fmt.Sscanf(value, "%4d-%2d-%2d", &year, &month, &day); err != nil {
+		return time.Time{}, fmt.Errorf("invalid Ethiopian date %q, expected YYYY-MM-DD", value)
+	}
+
+	maxDay := 30
+	if month == 13 {
+		maxDay = 5
+		if year%4 == 3 {
+			maxDay = 6
+		}
+	}
+	if month < 1 || month > 13 || day < 1 || day > maxDay {
+		return time.Time{}, fmt.Errorf("invalid Ethiopian date %q", value)
+	}
+
+	return FromEthiopian(year, month, day), nil
+}
+
+func (d EthiopianDate) String() string {
+	return fmt.Sprintf("%04d-%02d-%02d", d.Year, d.Month, d.Day)
+}
+
+func (d EthiopianDate) MonthName() string {
+	return EthiopianMonthNames[d.Month-1]
+}
+
+// FormatDate writes the date of t as YYYY-MM-DD in the calendar
+func FormatDate(t time.Time, calendar Calendar) string {
+	if calendar == CalendarEthiopian {
+		return ToEthiopian(t).String()
+	}
+	return t.Format("2006-01-02")
+}
+
+// ParseDate reads a YYYY-MM-DD date written in the calendar, as midnight UTC
+func ParseDate(value string, calendar Calendar) (time.Time, error) {
+	if calendar == CalendarEthiopian {
+		return ParseEthiopian(value)
+	}
+	return time.Parse("2006-01-02", value)
+}
+
+// MonthStart is the first day of the calendar month containing the date of t, as midnight UTC
+func MonthStart(t time.Time, calendar Calendar) time.Time {
+	if calendar == CalendarEthiopian {
+		d := ToEthiopian(t)
+		return FromEthiopian(d.Year, d.Month, 1)
+	}
+	return time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, time.UTC)
+}
+
+// FiscalYear is the fiscal year containing the date of t and its first day. Ethiopian
+// fiscal years run Hamle 1 to Sene 30 and are named by the year they end in; Gregorian
+// fiscal years are calendar years.
+func FiscalYear(t time.Time, calendar Calendar) (int, time.Time) {
+	if calendar == CalendarEthiopian {
+		d := ToEthiopian(t)
+		year := d.Year
+		if d.Month >= ethiopianFiscalYearStartMonth {
+			year++
+		}
+		return year, FromEthiopian(year-1, ethiopianFiscalYearStartMonth, 1)
+	}
+	return t.Year(), time.Date(t.Year(), 1, 1, 0, 0, 0, 0, time.UTC)
+}
+
+func gregorianJDN(t time.Time) int {
+	day := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+	return int(day.Unix()/86400) + unixEpochJDN
+}
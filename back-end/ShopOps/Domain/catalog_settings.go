@@ -0,0 +1,90 @@
+package Domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CatalogSettings are the choices the POS offers when a product is added: its
+// categories, units and tax rates. Products keep their own values as text, so
+// changing the lists does not touch products already in the catalog.
+type CatalogSettings struct {
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Categories []string           `bson:"categories" json:"categories"`
+	Units      []string           `bson:"units" json:"units"`
+	TaxRates   []TaxRate          `bson:"tax_rates" json:"tax_rates"`
+	Template   string             `bson:"template,omitempty" json:"template,omitempty"` // Shop template last applied
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// TaxRate is a rate the POS can apply to a sale; the default one is applied unless
+// the cashier picks another
+type TaxRate struct {
+	Code    string  `bson:"code" json:"code" validate:"required,max=20"`
+	Name    string  `bson:"name" json:"name" validate:"required,max=60"`
+	Percent float64 `bson:"percent" json:"percent" validate:"gte=0,lte=100"`
+	Default bool    `bson:"default,omitempty" json:"default,omitempty"`
+}
+
+// DefaultCatalogSettings is what a shop without settings gets: pieces and kilograms,
+// and Ethiopian VAT
+var DefaultCatalogSettings = CatalogSettings{
+	Categories: []string{},
+	Units:      []string{"pcs", "kg"},
+	TaxRates:   []TaxRate{{Code: "VAT", Name: "VAT 15%", Percent: 15, Default: true}},
+}
+
+// Validate checks the lists have no blanks or repeats and at most one default rate
+func (s *CatalogSettings) Validate() error {
+	if err := uniqueNames("categories", s.Categories); err != nil {
+		return err
+	}
+	if err := uniqueNames("units", s.Units); err != nil {
+		return err
+	}
+	codes := make([]string, len(s.TaxRates))
+	defaults := 0
+	for i, rate := range s.TaxRates {
+		codes[i] = rate.Code
+		if rate.Default {
+			defaults++
+		}
+	}
+	if err := uniqueNames("tax rate codes", codes); err != nil {
+		return err
+	}
+	if defaults > 1 {
+		return ValidationError("only one tax rate can be the default")
+	}
+	return nil
+}
+
+func uniqueNames(what string, names []string) error {
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if key == "" {
+			return ValidationError(fmt.Sprintf("%s cannot be blank", what))
+		}
+		if seen[key] {
+			return ValidationError(fmt.Sprintf("%s has %q more than once", what, name))
+		}
+		seen[key] = true
+	}
+	return nil
+}
+
+// UpdateCatalogSettingsRequest replaces the lists given and leaves the rest
+type UpdateCatalogSettingsRequest struct {
+	Categories *[]string  `json:"categories,omitempty" validate:"omitempty,max=200,dive,max=60"`
+	Units      *[]string  `json:"units,omitempty" validate:"omitempty,max=50,dive,max=20"`
+	TaxRates   *[]TaxRate `json:"tax_rates,omitempty" validate:"omitempty,max=20,dive"`
+}
+
+type CatalogSettingsRepository interface {
+	FindSettings(businessID string) (*CatalogSettings, error)
+	SaveSettings(settings *CatalogSettings) error
+}
@@ -0,0 +1,61 @@
+package Domain
+
+// CompareMode picks the prior period a report is compared against
+type CompareMode string
+
+const (
+	// The period of the same length just before the current one
+	CompareModePreviousPeriod CompareMode = "previous_period"
+	CompareModeLastWeek       CompareMode = "last_week"
+	CompareModeLastMonth      CompareMode = "last_month"
+	CompareModeLastYear       CompareMode = "last_year"
+)
+
+func (m CompareMode) IsValid() bool {
+	switch m {
+	case CompareModePreviousPeriod, CompareModeLastWeek, CompareModeLastMonth, CompareModeLastYear:
+		return true
+	}
+	return false
+}
+
+// CompareAlign decides how the prior period lines up with the current one
+type CompareAlign string
+
+const (
+	// Shift by whole weeks so each day is compared with the same weekday
+	// (last month is 4 weeks back, last year 52 weeks back)
+	CompareAlignWeekday CompareAlign = "weekday"
+	// Shift by calendar dates (last month is the same dates one month back)
+	CompareAlignDate CompareAlign = "date"
+)
+
+func (a CompareAlign) IsValid() bool {
+	return a == CompareAlignWeekday || a == CompareAlignDate
+}
+
+type CompareOptions struct {
+	Mode  CompareMode
+	Align CompareAlign
+}
+
+// MetricDelta is how one headline metric moved between the two periods
+type MetricDelta struct {
+	Current       float64  `json:"current"`
+	Previous      float64  `json:"previous"`
+	Change        float64  `json:"change"`
+	ChangePercent *float64 `json:"change_percent"` // Nil when the previous value is zero
+}
+
+// ReportComparison wraps a report with the same report for the prior period
+type ReportComparison struct {
+	Compare       CompareMode            `json:"compare"`
+	Align         CompareAlign           `json:"align"`
+	CurrentStart  string                 `json:"current_start"`
+	CurrentEnd    string                 `json:"current_end"`
+	PreviousStart string                 `json:"previous_start"`
+	PreviousEnd   string                 `json:"previous_end"`
+	Current       interface{}            `json:"current"`
+	Previous      interface{}            `json:"previous"`
+	Deltas        map[string]MetricDelta `json:"deltas"`
+}
@@ -0,0 +1,117 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ProductConsignment marks a product as held on consignment: its stock belongs to
+// the consignor, a supplier, until it sells, and each sale is owed to them on their
+// payables ledger. The consignor is owed PayoutPerUnit for each unit sold, or the
+// sale amount less the shop's CommissionPercent when no payout is set.
+type ProductConsignment struct {
+	ConsignorID       primitive.ObjectID `bson:"consignor_id" json:"consignor_id"`
+	PayoutPerUnit     Money              `bson:"payout_per_unit,omitempty" json:"payout_per_unit,omitempty"`
+	CommissionPercent float64            `bson:"commission_percent,omitempty" json:"commission_percent,omitempty"`
+}
+
+// Payout is what the consignor is owed for a sale of quantity for amount
+func (c *ProductConsignment) Payout(quantity float64, amount Money) Money {
+	if c.PayoutPerUnit > 0 {
+		return c.PayoutPerUnit.Times(quantity)
+	}
+	return amount.Times(1 - c.CommissionPercent/100)
+}
+
+type SetProductConsignmentRequest struct {
+	ConsignorID       string  `json:"consignor_id" validate:"required"`
+	PayoutPerUnit     Money   `json:"payout_per_unit,omitempty" validate:"omitempty,gt=0,money"`
+	CommissionPercent float64 `json:"commission_percent,omitempty" validate:"omitempty,gt=0,lt=100"`
+}
+
+// ConsignmentEntry is one line of the consignment ledger: goods a consignor left
+// with the shop or took back, or what a sale of their goods owes them. A sale's
+// entries add up to what it currently owes, so a change or void of the sale is
+// recorded as a further entry with the difference.
+type ConsignmentEntry struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID   `bson:"business_id" json:"business_id"`
+	ConsignorID primitive.ObjectID   `bson:"consignor_id" json:"consignor_id"`
+	ProductID   primitive.ObjectID   `bson:"product_id" json:"product_id"`
+	Type        ConsignmentEntryType `bson:"type" json:"type"`
+	Quantity    float64              `bson:"quantity" json:"quantity"` // Negative on a sale entry that takes units back
+	SaleID      *primitive.ObjectID  `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	SaleAmount  Money                `bson:"sale_amount,omitempty" json:"sale_amount,omitempty"`
+	Payout      Money                `bson:"payout,omitempty" json:"payout,omitempty"` // Owed to the consignor; negative when a sale was reduced or voided
+	Reference   string               `bson:"reference,omitempty" json:"reference,omitempty"`
+	Notes       string               `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy   *primitive.ObjectID  `bson:"created_by,omitempty" json:"created_by,omitempty"` // Nil on sale entries, which the event bus records
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+}
+
+type ConsignmentEntryType string
+
+const (
+	ConsignmentReceived ConsignmentEntryType = "received" // Goods left with the shop
+	ConsignmentReturned ConsignmentEntryType = "returned" // Unsold goods taken back
+	ConsignmentSold     ConsignmentEntryType = "sold"
+)
+
+// ConsignmentGoodsRequest records goods a consignor brings in or takes back
+type ConsignmentGoodsRequest struct {
+	ConsignorID string                 `json:"consignor_id" validate:"required"`
+	Items       []ConsignmentGoodsItem `json:"items" validate:"required,min=1,dive"`
+	Reference   string                 `json:"reference,omitempty" validate:"max=100"`
+	Notes       string                 `json:"notes,omitempty" validate:"max=500"`
+}
+
+type ConsignmentGoodsItem struct {
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+type ConsignmentEntryFilters struct {
+	ConsignorID *string
+	ProductID   *string
+	Type        *ConsignmentEntryType
+	StartDate   *time.Time
+	EndDate     *time.Time
+	Limit       int
+	Offset      int
+}
+
+// ConsignmentStatement settles a period with a consignor: what of theirs came in,
+// went back and sold, what the sales owe them and what was paid
+type ConsignmentStatement struct {
+	ConsignorID   string                     `json:"consignor_id"`
+	ConsignorName string                     `json:"consignor_name"`
+	StartDate     string                     `json:"start_date"`
+	EndDate       string                     `json:"end_date"`
+	Lines         []ConsignmentStatementLine `json:"lines"`
+	Received      float64                    `json:"received"`
+	Returned      float64                    `json:"returned"`
+	Sold          float64                    `json:"sold"`
+	SalesAmount   Money                      `json:"sales_amount"`
+	Payout        Money                      `json:"payout"`  // Owed for the period's sales
+	Paid          Money                      `json:"paid"`    // Payments and credits to the consignor in the period
+	Balance       Money                      `json:"balance"` // Owed to the consignor now, across all periods
+}
+
+type ConsignmentStatementLine struct {
+	ProductID   string  `json:"product_id"`
+	Name        string  `json:"name"`
+	SKU         string  `json:"sku,omitempty"`
+	Received    float64 `json:"received"`
+	Returned    float64 `json:"returned"`
+	Sold        float64 `json:"sold"`
+	SalesAmount Money   `json:"sales_amount"`
+	Payout      Money   `json:"payout"`
+	OnHand      float64 `json:"on_hand"` // Stock now, not at the end of the period
+}
+
+type ConsignmentRepository interface {
+	Create(entry *ConsignmentEntry) error
+	FindByBusiness(businessID string, filters ConsignmentEntryFilters) ([]ConsignmentEntry, error)
+	FindBySale(saleID primitive.ObjectID) ([]ConsignmentEntry, error)
+}
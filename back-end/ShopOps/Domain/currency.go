@@ -0,0 +1,162 @@
+package Domain
+
+import (
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var currencyCodePattern = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// NormalizeCurrency upper-cases an ISO 4217 code; false when it is not one
+func NormalizeCurrency(code string) (string, bool) {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return code, currencyCodePattern.MatchString(code)
+}
+
+// CurrencySettings are the foreign currencies a shop takes besides its base
+// currency, Business.Currency, in which everything is recorded and reported
+type CurrencySettings struct {
+	BusinessID   primitive.ObjectID `bson:"business_id" json:"business_id"`
+	BaseCurrency string             `bson:"-" json:"base_currency"` // Filled in from the business
+	Accepted     []string           `bson:"accepted" json:"accepted"`
+	// Fetch market rates every few hours; a rate set by hand is used until the next fetch
+	AutoFetch     bool       `bson:"auto_fetch" json:"auto_fetch"`
+	LastFetchedAt *time.Time `bson:"last_fetched_at,omitempty" json:"last_fetched_at,omitempty"`
+	LastError     string     `bson:"last_error,omitempty" json:"last_error,omitempty"` // Why the last fetch failed
+	// How cash in the base currency is rounded at the till; exact when nil
+	CashRounding *CashRounding `bson:"cash_rounding,omitempty" json:"cash_rounding,omitempty"`
+	UpdatedAt    time.Time     `bson:"updated_at" json:"updated_at"`
+}
+
+// Accepts is whether the shop takes payments in currency
+func (s *CurrencySettings) Accepts(currency string) bool {
+	if s == nil {
+		return false
+	}
+	for _, accepted := range s.Accepted {
+		if accepted == currency {
+			return true
+		}
+	}
+	return false
+}
+
+type UpdateCurrencySettingsRequest struct {
+	Accepted  []string `json:"accepted,omitempty" validate:"max=10"`
+	AutoFetch *bool    `json:"auto_fetch,omitempty"`
+	// An increment of 0 turns rounding off
+	CashRounding *CashRounding `json:"cash_rounding,omitempty"`
+}
+
+type CashRoundingMode string
+
+const (
+	CashRoundingNearest CashRoundingMode = "nearest"
+	CashRoundingUp      CashRoundingMode = "up"
+	CashRoundingDown    CashRoundingMode = "down"
+)
+
+func (m CashRoundingMode) IsValid() bool {
+	switch m {
+	case CashRoundingNearest, CashRoundingUp, CashRoundingDown:
+		return true
+	}
+	return false
+}
+
+// CashRounding rounds what is due in cash to an amount the till can make up in
+// notes and coins, e.g. to the nearest 0.25 or 1 birr. What it adds or takes off
+// is kept on the sale apart from its total, so tax stays on the price of the goods.
+type CashRounding struct {
+	Increment float64          `bson:"increment" json:"increment" validate:"gte=0,lte=100"`
+	Mode      CashRoundingMode `bson:"mode" json:"mode"`
+}
+
+// Apply rounds amount to a multiple of the increment
+func (r *CashRounding) Apply(amount Money) Money {
+	if r == nil {
+		return amount
+	}
+	increment := NewMoney(r.Increment)
+	if increment <= 0 {
+		return amount
+	}
+
+	// Floored, so the remainder is never negative
+	steps, rest := amount/increment, amount%increment
+	if rest < 0 {
+		steps--
+		rest += increment
+	}
+	switch r.Mode {
+	case CashRoundingUp:
+		if rest > 0 {
+			steps++
+		}
+	case CashRoundingDown:
+	default:
+		if 2*rest > increment || (2*rest == increment && amount >= 0) {
+			steps++
+		}
+	}
+	return steps * increment
+}
+
+type ExchangeRateSource string
+
+const (
+	ExchangeRateManual  ExchangeRateSource = "manual"
+	ExchangeRateFetched ExchangeRateSource = "fetched"
+)
+
+// ExchangeRate is what one unit of a foreign currency is worth in the shop's
+// base currency from CreatedAt on. Rates are never changed, so each payment can
+// be traced to the rate it was converted at.
+type ExchangeRate struct {
+	ID           primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID   primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	BaseCurrency string              `bson:"base_currency" json:"base_currency"`
+	Currency     string              `bson:"currency" json:"currency"`
+	Rate         float64             `bson:"rate" json:"rate"`
+	Source       ExchangeRateSource  `bson:"source" json:"source"`
+	CreatedBy    *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"`
+	CreatedAt    time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type SetExchangeRateRequest struct {
+	Currency string  `json:"currency" validate:"required"`
+	Rate     float64 `json:"rate" validate:"required,gt=0"` // Base currency per unit of Currency
+}
+
+// ForeignCurrencyTakings is what was taken in one foreign currency over a period,
+// for counting the till: ForeignAmount is the cash in that currency and Amount
+// what it was booked at in the base currency, net of Change given back
+type ForeignCurrencyTakings struct {
+	Currency      string `bson:"_id" json:"currency"`
+	ForeignAmount Money  `bson:"foreign_amount" json:"foreign_amount"`
+	Amount        Money  `bson:"amount" json:"amount"`
+	Change        Money  `bson:"change" json:"change"`
+	Payments      int    `bson:"payments" json:"payments"`
+}
+
+// ExchangeRateHours is how often rates are fetched for shops with AutoFetch
+const ExchangeRateHours = 6
+
+// ExchangeRateDecimals is how precisely rates are kept
+const ExchangeRateDecimals = 6
+
+type CurrencyRepository interface {
+	FindSettings(businessID string) (*CurrencySettings, error)
+	SaveSettings(settings *CurrencySettings) error
+	// FindAutoFetchSettings returns the settings of shops whose rates are fetched
+	FindAutoFetchSettings() ([]CurrencySettings, error)
+
+	CreateRate(rate *ExchangeRate) error
+	// FindLatestRate returns the rate in force for currency, nil when none was ever set
+	FindLatestRate(businessID, currency string) (*ExchangeRate, error)
+	// FindRates returns the shop's rates for currency, newest first
+	FindRates(businessID, currency string, limit int) ([]ExchangeRate, error)
+}
@@ -0,0 +1,316 @@
+package Domain
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CustomFieldEntity is a kind of record a shop can add its own fields to
+type CustomFieldEntity string
+
+const (
+	CustomFieldEntityProduct  CustomFieldEntity = "product"
+	CustomFieldEntityCustomer CustomFieldEntity = "customer"
+	CustomFieldEntitySupplier CustomFieldEntity = "supplier"
+	CustomFieldEntitySale     CustomFieldEntity = "sale"
+)
+
+func (e CustomFieldEntity) IsValid() bool {
+	switch e {
+	case CustomFieldEntityProduct, CustomFieldEntityCustomer, CustomFieldEntitySupplier, CustomFieldEntitySale:
+		return true
+	}
+	return false
+}
+
+type CustomFieldType string
+
+const (
+	CustomFieldText    CustomFieldType = "text"
+	CustomFieldNumber  CustomFieldType = "number"
+	CustomFieldBoolean CustomFieldType = "boolean"
+	CustomFieldDate    CustomFieldType = "date" // Stored as YYYY-MM-DD
+	CustomFieldSelect  CustomFieldType = "select"
+)
+
+func (t CustomFieldType) IsValid() bool {
+	switch t {
+	case CustomFieldText, CustomFieldNumber, CustomFieldBoolean, CustomFieldDate, CustomFieldSelect:
+		return true
+	}
+	return false
+}
+
+// CustomFields are a record's values for its shop's custom fields, by key. They
+// are kept in the record's own document, so reading a record needs no join.
+type CustomFields map[string]interface{}
+
+// MaxCustomFields is how many fields a shop can define per entity
+const MaxCustomFields = 30
+
+const maxCustomTextLength = 500
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,39}$`)
+
+// CustomFieldDefinition is a field a shop adds to one kind of record, e.g. the
+// IMEI of a phone or a sale's prescription number
+type CustomFieldDefinition struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Entity     CustomFieldEntity  `bson:"entity" json:"entity"`
+	Key        string             `bson:"key" json:"key"` // Name in custom_fields; fixed once created
+	Label      string             `bson:"label" json:"label"`
+	Type       CustomFieldType    `bson:"type" json:"type"` // Fixed once created
+	Required   bool               `bson:"required" json:"required"`
+	Options    []string           `bson:"options,omitempty" json:"options,omitempty"` // Values of a select field
+	MaxLength  int                `bson:"max_length,omitempty" json:"max_length,omitempty"`
+	Min        *float64           `bson:"min,omitempty" json:"min,omitempty"`
+	Max        *float64           `bson:"max,omitempty" json:"max,omitempty"`
+	Pattern    string             `bson:"pattern,omitempty" json:"pattern,omitempty"` // Regular expression text values must match
+	Searchable bool               `bson:"searchable" json:"searchable"`               // Found by the global search
+	Position   int                `bson:"position" json:"position"`
+	Active     bool               `bson:"active" json:"active"` // Inactive fields are kept on records but no longer checked or shown
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type CreateCustomFieldRequest struct {
+	Entity     CustomFieldEntity `json:"entity" validate:"required"`
+	Key        string            `json:"key" validate:"required"`
+	Label      string            `json:"label" validate:"required,max=100"`
+	Type       CustomFieldType   `json:"type" validate:"required"`
+	Required   bool              `json:"required,omitempty"`
+	Options    []string          `json:"options,omitempty" validate:"max=100,dive,required,max=100"`
+	MaxLength  int               `json:"max_length,omitempty" validate:"gte=0"`
+	Min        *float64          `json:"min,omitempty"`
+	Max        *float64          `json:"max,omitempty"`
+	Pattern    string            `json:"pattern,omitempty" validate:"max=200"`
+	Searchable bool              `json:"searchable,omitempty"`
+	Position   int               `json:"position,omitempty"`
+}
+
+type UpdateCustomFieldRequest struct {
+	Label      *string  `json:"label,omitempty" validate:"omitempty,min=1,max=100"`
+	Required   *bool    `json:"required,omitempty"`
+	Options    []string `json:"options,omitempty" validate:"max=100,dive,required,max=100"`
+	MaxLength  *int     `json:"max_length,omitempty" validate:"omitempty,gte=0"`
+	Min        *float64 `json:"min,omitempty"`
+	Max        *float64 `json:"max,omitempty"`
+	Pattern    *string  `json:"pattern,omitempty" validate:"omitempty,max=200"`
+	Searchable *bool    `json:"searchable,omitempty"`
+	Position   *int     `json:"position,omitempty"`
+	Active     *bool    `json:"active,omitempty"`
+}
+
+// Validate checks the definition itself is usable
+func (d CustomFieldDefinition) Validate() error {
+	if !d.Entity.IsValid() {
+		return ValidationError("entity must be product, customer, supplier or sale")
+	}
+	if !customFieldKeyPattern.MatchString(d.Key) {
+		return ValidationError("key must start with a letter and have only lowercase letters, digits and _, up to 40")
+	}
+	if !d.Type.IsValid() {
+		return ValidationError("type must be text, number, boolean, date or select")
+	}
+	if d.Type == CustomFieldSelect && len(d.Options) == 0 {
+		return ValidationError("a select field needs options")
+	}
+	if d.Type != CustomFieldSelect && len(d.Options) > 0 {
+		return ValidationError("only select fields have options")
+	}
+	if d.Min != nil && d.Max != nil && *d.Min > *d.Max {
+		return ValidationError("min must not be above max")
+	}
+	if d.Pattern != "" {
+		if _, err := regexp.Compile(d.Pattern); err != nil {
+			return ValidationError("pattern is not a valid regular expression")
+		}
+	}
+	return nil
+}
+
+// Check returns value in the form the field stores it: numbers as float64,
+// dates as YYYY-MM-DD, text trimmed
+func (d CustomFieldDefinition) Check(value interface{}) (interface{}, error) {
+	switch d.Type {
+	case CustomFieldNumber:
+		var n float64
+		switch v := value.(type) {
+		case float64:
+			n = v
+		case int:
+			n = float64(v)
+		case int32:
+			n = float64(v)
+		case int64:
+			n = float64(v)
+		case string:
+			parsed, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("must be a number")
+			}
+			n = parsed
+		default:
+			return nil, fmt.Errorf("must be a number")
+		}
+		if math.IsNaN(n) || math.IsInf(n, 0) {
+			return nil, fmt.Errorf("must be a number")
+		}
+		if d.Min != nil && n < *d.Min {
+			return nil, fmt.Errorf("must be at least %g", *d.Min)
+		}
+		if d.Max != nil && n > *d.Max {
+			return nil, fmt.Errorf("must be at most %g", *d.Max)
+		}
+		return n, nil
+
+	case CustomFieldBoolean:
+		switch v := value.(type) {
+		case bool:
+			return v, nil
+		case string:
+			switch strings.ToLower(strings.TrimSpace(v)) {
+			case "true", "yes", "1":
+				return true, nil
+			case "false", "no", "0":
+				return false, nil
+			}
+		}
+		return nil, fmt.Errorf("must be true or false")
+
+	case CustomFieldDate:
+		s, ok := value.(string)
+		if !ok {
+			if t, isTime := value.(time.Time); isTime {
+				return t.Format("2006-01-02"), nil
+			}
+			return nil, fmt.Errorf("must be a date as YYYY-MM-DD")
+		}
+		t, err := time.Parse("2006-01-02", strings.TrimSpace(s))
+		if err != nil {
+			return nil, fmt.Errorf("must be a date as YYYY-MM-DD")
+		}
+		return t.Format("2006-01-02"), nil
+	}
+
+	s, ok := value.(string)
+	if !ok {
+		return nil, fmt.Errorf("must be text")
+	}
+	s = strings.TrimSpace(s)
+
+	if d.Type == CustomFieldSelect {
+		for _, option := range d.Options {
+			if strings.EqualFold(option, s) {
+				return option, nil
+			}
+		}
+		return nil, fmt.Errorf("must be one of %s", strings.Join(d.Options, ", "))
+	}
+
+	maxLength := d.MaxLength
+	if maxLength <= 0 || maxLength > maxCustomTextLength {
+		maxLength = maxCustomTextLength
+	}
+	if len([]rune(s)) > maxLength {
+		return nil, fmt.Errorf("must be at most %d characters", maxLength)
+	}
+	if d.Pattern != "" {
+		if re, err := regexp.Compile(d.Pattern); err == nil && !re.MatchString(s) {
+			return nil, fmt.Errorf("is not in the expected format")
+		}
+	}
+	return s, nil
+}
+
+// ValidateCustomFields checks values against the shop's active fields and
+// returns them as stored. On an update, existing holds the record's current
+// values: values are merged over them and a null value clears a field. Values
+// of fields since deactivated are kept as they were. Required fields are only
+// insisted on with requireAll, so records from before a field was added can
+// still be edited.
+func ValidateCustomFields(defs []CustomFieldDefinition, values, existing CustomFields, requireAll bool) (CustomFields, error) {
+	byKey := make(map[string]CustomFieldDefinition, len(defs))
+	for _, def := range defs {
+		if def.Active {
+			byKey[def.Key] = def
+		}
+	}
+
+	result := CustomFields{}
+	for key, value := range existing {
+		result[key] = value
+	}
+
+	var details []ErrorDetail
+	for key, value := range values {
+		def, ok := byKey[key]
+		if !ok {
+			details = append(details, ErrorDetail{Field: "custom_fields." + key, Code: "unknown", Message: key + " is not a custom field of this shop"})
+			continue
+		}
+		if value == nil || value == "" {
+			delete(result, key)
+			continue
+		}
+		checked, err := def.Check(value)
+		if err != nil {
+			details = append(details, ErrorDetail{Field: "custom_fields." + key, Code: "invalid", Message: def.Label + " " + err.Error()})
+			continue
+		}
+		result[key] = checked
+	}
+
+	for _, def := range defs {
+		if !requireAll || !def.Active || !def.Required {
+			continue
+		}
+		if _, ok := result[def.Key]; !ok {
+			details = append(details, ErrorDetail{Field: "custom_fields." + def.Key, Code: "required", Message: def.Label + " is required"})
+		}
+	}
+
+	if len(details) > 0 {
+		return nil, ValidationError(details[0].Message, details...)
+	}
+	if len(result) == 0 {
+		return nil, nil
+	}
+	return result, nil
+}
+
+// FormatCustomField writes a stored value the way exports and search show it
+func FormatCustomField(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case bool:
+		if v {
+			return "yes"
+		}
+		return "no"
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return fmt.Sprint(value)
+}
+
+type CustomFieldRepository interface {
+	Create(def *CustomFieldDefinition) error
+	// FindInBusiness returns the definition when the shop added it
+	FindInBusiness(ctx context.Context, id string) (*CustomFieldDefinition, error)
+	// FindByBusinessID lists the shop's fields by position; all entities when entity is empty
+	FindByBusinessID(businessID string, entity CustomFieldEntity, activeOnly bool) ([]CustomFieldDefinition, error)
+	Count(businessID string, entity CustomFieldEntity) (int64, error)
+	Update(def *CustomFieldDefinition) error
+}
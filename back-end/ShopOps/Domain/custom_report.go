@@ -0,0 +1,209 @@
+package Domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SavedReport is a user-defined report over the daily sales aggregates: the sales are
+// grouped by the chosen dimensions and each group gets the chosen measures
+type SavedReport struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID     `bson:"business_id" json:"business_id"`
+	Name        string                 `bson:"name" json:"name"`
+	Description string                 `bson:"description,omitempty" json:"description,omitempty"`
+	Definition  CustomReportDefinition `bson:"definition" json:"definition"`
+	Schedule    ReportSchedule         `bson:"schedule" json:"schedule"`
+	NextRunAt   *time.Time             `bson:"next_run_at,omitempty" json:"next_run_at,omitempty"` // Nil when not scheduled
+	LastRunAt   *time.Time             `bson:"last_run_at,omitempty" json:"last_run_at,omitempty"`
+	CreatedBy   primitive.ObjectID     `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+// CustomReportDefinition is what a custom report computes, e.g.
+//
+//	{"dimensions": ["category", "payment_method"], "measures": ["revenue", "margin"], "sort_by": "revenue"}
+type CustomReportDefinition struct {
+	Dimensions []ReportDimension  `bson:"dimensions" json:"dimensions"`
+	Measures   []ReportMeasure    `bson:"measures" json:"measures" validate:"required,min=1"`
+	Filter     CustomReportFilter `bson:"filter" json:"filter"`
+	// Rolling window in days when no dates are given. 0 means the schedule's own
+	// period (the previous day, week or calendar month), or 30 days on demand.
+	RangeDays int           `bson:"range_days,omitempty" json:"range_days,omitempty"`
+	SortBy    ReportMeasure `bson:"sort_by,omitempty" json:"sort_by,omitempty"` // Defaults to the first measure
+	Order     string        `bson:"order,omitempty" json:"order,omitempty"`     // asc or desc (default)
+	Limit     int           `bson:"limit,omitempty" json:"limit,omitempty"`     // Rows to keep, default 100
+}
+
+type CustomReportFilter struct {
+	ProductID     string        `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	Category      string        `bson:"category,omitempty" json:"category,omitempty"`
+	EmployeeID    string        `bson:"employee_id,omitempty" json:"employee_id,omitempty"` // Cashier
+	PaymentMethod PaymentMethod `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+	DeviceID      string        `bson:"device_id,omitempty" json:"device_id,omitempty"`
+}
+
+type ReportDimension string
+
+const (
+	ReportDimensionProduct       ReportDimension = "product"
+	ReportDimensionCategory      ReportDimension = "category"
+	ReportDimensionCashier       ReportDimension = "cashier" // Employee credited with the sale
+	ReportDimensionPaymentMethod ReportDimension = "payment_method"
+	ReportDimensionDevice        ReportDimension = "device"
+	ReportDimensionDay           ReportDimension = "day"
+)
+
+type ReportMeasure string
+
+const (
+	ReportMeasureRevenue      ReportMeasure = "revenue"
+	ReportMeasureQuantity     ReportMeasure = "quantity"
+	ReportMeasureCOGS         ReportMeasure = "cogs"
+	ReportMeasureGrossProfit  ReportMeasure = "gross_profit"
+	ReportMeasureMargin       ReportMeasure = "margin" // Gross profit as a percent of revenue
+	ReportMeasureTransactions ReportMeasure = "transactions"
+)
+
+type ReportSchedule string
+
+const (
+	ReportScheduleNone    ReportSchedule = "none"
+	ReportScheduleDaily   ReportSchedule = "daily"
+	ReportScheduleWeekly  ReportSchedule = "weekly"  // Mondays
+	ReportScheduleMonthly ReportSchedule = "monthly" // The 1st
+)
+
+func (s ReportSchedule) IsValid() bool {
+	switch s {
+	case ReportScheduleNone, ReportScheduleDaily, ReportScheduleWeekly, ReportScheduleMonthly:
+		return true
+	}
+	return false
+}
+
+const (
+	MaxCustomReportDimensions = 3
+	MaxCustomReportRangeDays  = 366
+	MaxCustomReportRows       = 1000
+)
+
+func (d CustomReportDefinition) Validate() error {
+	if len(d.Dimensions) > MaxCustomReportDimensions {
+		return fmt.Errorf("a report can group by at most %d dimensions", MaxCustomReportDimensions)
+	}
+	seen := make(map[ReportDimension]bool)
+	for _, dim := range d.Dimensions {
+		switch dim {
+		case ReportDimensionProduct, ReportDimensionCategory, ReportDimensionCashier,
+			ReportDimensionPaymentMethod, ReportDimensionDevice, ReportDimensionDay:
+		default:
+			return fmt.Errorf("unknown report dimension: %s", dim)
+		}
+		if seen[dim] {
+			return fmt.Errorf("dimension %s is listed twice", dim)
+		}
+		seen[dim] = true
+	}
+
+	if len(d.Measures) == 0 {
+		return fmt.Errorf("a report needs at least one measure")
+	}
+	measures := make(map[ReportMeasure]bool)
+	for _, measure := range d.Measures {
+		switch measure {
+		case ReportMeasureRevenue, ReportMeasureQuantity, ReportMeasureCOGS,
+			ReportMeasureGrossProfit, ReportMeasureMargin, ReportMeasureTransactions:
+		default:
+			return fmt.Errorf("unknown report measure: %s", measure)
+		}
+		measures[measure] = true
+	}
+
+	if d.SortBy != "" && !measures[d.SortBy] {
+		return fmt.Errorf("sort_by must be one of the report's measures")
+	}
+	if d.Order != "" && d.Order != "asc" && d.Order != "desc" {
+		return fmt.Errorf("order must be asc or desc")
+	}
+	if d.RangeDays < 0 || d.RangeDays > MaxCustomReportRangeDays {
+		return fmt.Errorf("range_days must be between 0 and %d", MaxCustomReportRangeDays)
+	}
+	if d.Limit < 0 || d.Limit > MaxCustomReportRows {
+		return fmt.Errorf("limit must be between 0 and %d", MaxCustomReportRows)
+	}
+
+	return nil
+}
+
+// ReportPivotRow is one group of the daily product aggregates. Keys holds the group's
+// value for each dimension and Labels a readable name where the key is an ID.
+type ReportPivotRow struct {
+	Keys         map[ReportDimension]string
+	Labels       map[ReportDimension]string
+	Quantity     float64
+	Revenue      Money
+	COGS         Money
+	Transactions int
+}
+
+type CustomReportRow struct {
+	Dimensions map[ReportDimension]string `bson:"dimensions" json:"dimensions"`
+	Labels     map[ReportDimension]string `bson:"labels,omitempty" json:"labels,omitempty"`
+	Measures   map[ReportMeasure]float64  `bson:"measures" json:"measures"`
+}
+
+type CustomReportResult struct {
+	ReportID   string                    `bson:"report_id,omitempty" json:"report_id,omitempty"` // Empty for previews
+	Name       string                    `bson:"name,omitempty" json:"name,omitempty"`
+	StartDate  string                    `bson:"start_date" json:"start_date"`
+	EndDate    string                    `bson:"end_date" json:"end_date"`
+	Dimensions []ReportDimension         `bson:"dimensions" json:"dimensions"`
+	Measures   []ReportMeasure           `bson:"measures" json:"measures"`
+	Rows       []CustomReportRow         `bson:"rows" json:"rows"`
+	TotalRows  int                       `bson:"total_rows" json:"total_rows"` // Before the row limit
+	Totals     map[ReportMeasure]float64 `bson:"totals" json:"totals"`         // Over all rows, not just the kept ones
+	ComputedAt time.Time                 `bson:"computed_at" json:"computed_at"`
+}
+
+// SavedReportRun is the stored result of a scheduled run
+type SavedReportRun struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	ReportID   primitive.ObjectID `bson:"saved_report_id" json:"saved_report_id"`
+	Result     CustomReportResult `bson:"result" json:"result"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type CreateSavedReportRequest struct {
+	Name        string                 `json:"name" validate:"required"`
+	Description string                 `json:"description,omitempty"`
+	Definition  CustomReportDefinition `json:"definition" validate:"required"`
+	Schedule    ReportSchedule         `json:"schedule,omitempty"` // Default none
+}
+
+type UpdateSavedReportRequest struct {
+	Name        *string                 `json:"name,omitempty"`
+	Description *string                 `json:"description,omitempty"`
+	Definition  *CustomReportDefinition `json:"definition,omitempty"`
+	Schedule    *ReportSchedule         `json:"schedule,omitempty"`
+}
+
+type SavedReportRepository interface {
+	Create(report *SavedReport) error
+	// FindInBusiness returns the report when the shop saved it
+	FindInBusiness(ctx context.Context, id string) (*SavedReport, error)
+	FindByBusinessID(businessID string) ([]SavedReport, error)
+	// FindDue returns the scheduled reports whose next run is at or before now
+	FindDue(now time.Time) ([]SavedReport, error)
+	Update(report *SavedReport) error
+	UpdateRunTimes(id primitive.ObjectID, lastRunAt time.Time, nextRunAt *time.Time) error
+	Delete(id string) error
+
+	CreateRun(run *SavedReportRun) error
+	FindRuns(reportID string, limit int) ([]SavedReportRun, error)
+}
@@ -0,0 +1,175 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Customer struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Name       string              `bson:"name" json:"name" pii:"name"`
+	Phone      string              `bson:"phone,omitempty" json:"phone,omitempty" pii:"phone"` // Stored in E.164 form
+	Email      string              `bson:"email,omitempty" json:"email,omitempty" pii:"email"`
+	Address    string              `bson:"address,omitempty" json:"address,omitempty"`
+	Notes      string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	Tags       []string            `bson:"tags,omitempty" json:"tags,omitempty"`
+	Tier       CustomerTier        `bson:"tier,omitempty" json:"tier,omitempty"` // Empty means retail
+	Status     CustomerStatus      `bson:"status" json:"status"`
+	MergedInto *primitive.ObjectID `bson:"merged_into,omitempty" json:"merged_into,omitempty"`
+	CreatedBy  primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+	DeletedAt  *time.Time          `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"` // Set while in the trash
+	Version    int64               `bson:"version" json:"version"`                           // Incremented by every update
+
+	// Promotional SMS consent; receipts are transactional and don't need it
+	MarketingOptIn bool       `bson:"marketing_opt_in" json:"marketing_opt_in"`
+	OptInUpdatedAt *time.Time `bson:"opt_in_updated_at,omitempty" json:"opt_in_updated_at,omitempty"`
+	OptInSource    string     `bson:"opt_in_source,omitempty" json:"opt_in_source,omitempty"` // staff, blacklist
+
+	CustomFields CustomFields `bson:"custom_fields,omitempty" json:"custom_fields,omitempty"`
+}
+
+type CustomerStatus string
+
+const (
+	CustomerStatusActive   CustomerStatus = "active"
+	CustomerStatusMerged   CustomerStatus = "merged"
+	CustomerStatusArchived CustomerStatus = "archived"
+)
+
+// CustomerTier selects which product price list applies to the customer
+type CustomerTier string
+
+const (
+	CustomerTierRetail    CustomerTier = "retail"
+	CustomerTierWholesale CustomerTier = "wholesale"
+	CustomerTierVIP       CustomerTier = "vip"
+)
+
+func (t CustomerTier) IsValid() bool {
+	switch t {
+	case CustomerTierRetail, CustomerTierWholesale, CustomerTierVIP:
+		return true
+	}
+	return false
+}
+
+// PricingTier returns the customer's tier, defaulting to retail
+func (c *Customer) PricingTier() CustomerTier {
+	if c.Tier == "" {
+		return CustomerTierRetail
+	}
+	return c.Tier
+}
+
+type CreateCustomerRequest struct {
+	Name    string   `json:"name" validate:"required"`
+	Phone   string   `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email   string   `json:"email,omitempty" validate:"omitempty,email"`
+	Address string   `json:"address,omitempty"`
+	Notes   string   `json:"notes,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+
+	Tier           CustomerTier `json:"tier,omitempty"`
+	MarketingOptIn bool         `json:"marketing_opt_in,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty"`
+}
+
+type UpdateCustomerRequest struct {
+	Name    *string  `json:"name,omitempty"`
+	Phone   *string  `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email   *string  `json:"email,omitempty" validate:"omitempty,email"`
+	Address *string  `json:"address,omitempty"`
+	Notes   *string  `json:"notes,omitempty"`
+	Tags    []string `json:"tags,omitempty"`
+
+	Tier           *CustomerTier `json:"tier,omitempty"`
+	MarketingOptIn *bool         `json:"marketing_opt_in,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty"` // Only the fields given change; a null clears one
+
+	Version *int64 `json:"version,omitempty"` // The version the client last read; a newer one on the server is a 409
+}
+
+type MergeCustomersRequest struct {
+	SourceIDs []string `json:"source_ids" validate:"required,min=1"` // Customers folded into the target
+}
+
+type MergeCustomersResult struct {
+	Customer          *Customer `json:"customer"`
+	MergedCount       int       `json:"merged_count"`
+	SalesReassigned   int64     `json:"sales_reassigned"`
+	CreditsReassigned int64     `json:"credits_reassigned"` // Gift cards and store credit
+	PointsTransferred float64   `json:"points_transferred"`
+}
+
+// DuplicateCustomerGroup is a set of customers that probably refer to the same person
+type DuplicateCustomerGroup struct {
+	Reason    string     `json:"reason"` // same_phone or similar_name
+	Customers []Customer `json:"customers"`
+}
+
+type CustomerRepository interface {
+	Create(customer *Customer) error
+	// FindInBusiness returns the shop's customer, merged or in the trash included
+	FindInBusiness(ctx context.Context, id string) (*Customer, error)
+	FindByPhone(businessID, phone string) (*Customer, error)
+	// FindByPhones returns the shop's active customers with any of phones
+	FindByPhones(businessID string, phones []string) ([]Customer, error)
+	FindByBusinessID(businessID string, filters CustomerFilters) ([]Customer, error)
+	Count(businessID string, filters CustomerFilters) (int64, error)
+	// Update saves the customer if they are still at customer.Version, and increments it
+	Update(customer *Customer) error
+	MarkMerged(id string, targetID primitive.ObjectID) error
+	// Delete archives the customer and moves them to the trash
+	Delete(id string) error
+	FindDeleted(businessID string) ([]Customer, error)
+	Restore(id string) error
+	PurgeDeleted(before time.Time, limit int) (map[string]int64, error)
+}
+
+type CustomerFilters struct {
+	Search *string // Matches name, phone or email
+	Status *CustomerStatus
+	Tag    *string
+	Tier   *CustomerTier
+	Limit  int
+
+	MarketingOptIn *bool
+	Offset         int // Deprecated: page with After instead
+
+	Sort  ListSort // By name when empty
+	After *PageCursor
+}
+
+// CustomerSorts are the orders the customer list can be paged in
+var CustomerSorts = ListSorts{
+	"name":        {Field: "name"},
+	"-created_at": {Field: "created_at", Desc: true},
+}
+
+func (c *Customer) SortValue(field string) interface{} {
+	switch field {
+	case "created_at":
+		return c.CreatedAt
+	default:
+		return c.Name
+	}
+}
+
+func (c *Customer) SortID() primitive.ObjectID {
+	return c.ID
+}
+
+type CustomerListResponse struct {
+	Customers  []Customer `json:"customers"`
+	Total      *int64     `json:"total,omitempty"` // Counted for offset pages, or when include_total is set
+	Limit      int        `json:"limit"`
+	Offset     int        `json:"offset"`
+	NextCursor string     `json:"next_cursor,omitempty"`
+}
@@ -0,0 +1,39 @@
+package Domain
+
+import "time"
+
+// DashboardWidgets is everything the app's home screen shows, in one response
+type DashboardWidgets struct {
+	GeneratedAt  time.Time             `json:"generated_at"`
+	Cached       bool                  `json:"cached"` // Served from the short-lived server cache
+	Currency     string                `json:"currency"`
+	Today        DashboardToday        `json:"today"`
+	CashPosition DashboardCashPosition `json:"cash_position"`
+	LowStock     DashboardLowStock     `json:"low_stock"`
+	TopProducts  []MarginLine          `json:"top_products"` // Today's best sellers by revenue
+	Overview     DashboardData         `json:"overview"`     // Week and month totals, segments
+}
+
+type DashboardToday struct {
+	Date         string `json:"date"` // Business day, YYYY-MM-DD
+	Sales        Money  `json:"sales"`
+	Transactions int    `json:"transactions"`
+	AverageSale  Money  `json:"average_sale"`
+	Expenses     Money  `json:"expenses"`
+	Profit       Money  `json:"profit"`
+}
+
+// DashboardCashPosition is the cash taken today less today's expenses
+type DashboardCashPosition struct {
+	CashSales       Money                `json:"cash_sales"`
+	OtherSales      Money                `json:"other_sales"`   // Card, mobile money, credit and other tenders
+	CashRounding    Money                `json:"cash_rounding"` // Cash taken beyond the sales by rounding at the till
+	Expenses        Money                `json:"expenses"`
+	NetCash         Money                `json:"net_cash"`
+	ByPaymentMethod []SalesBreakdownLine `json:"by_payment_method"`
+}
+
+type DashboardLowStock struct {
+	Count int            `json:"count"`
+	Items []LowStockItem `json:"items"` // Furthest below minimum first
+}
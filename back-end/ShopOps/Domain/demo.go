@@ -0,0 +1,43 @@
+package Domain
+
+import "time"
+
+// A demo shop is a shop filled with generated products, customers and sales, so the
+// app can be tried, shown to a prospect or put under load without setting one up.
+// Each belongs to a throwaway account and is deleted with it once it expires.
+
+// CreateDemoRequest sizes a demo shop; the app's "try demo" mode always gets the
+// defaults
+type CreateDemoRequest struct {
+	Products    int   `json:"products,omitempty" validate:"omitempty,min=1,max=5000"`      // 120 when 0
+	Customers   int   `json:"customers,omitempty" validate:"omitempty,min=1,max=5000"`     // 60 when 0
+	Days        int   `json:"days,omitempty" validate:"omitempty,min=1,max=365"`           // Days of sales history, 90 when 0
+	SalesPerDay int   `json:"sales_per_day,omitempty" validate:"omitempty,min=1,max=2000"` // On an average weekday, 80 when 0
+	KeepDays    int   `json:"keep_days,omitempty" validate:"omitempty,min=1,max=365"`      // Before it is deleted; DEMO_TTL when 0
+	Seed        int64 `json:"seed,omitempty"`                                              // The same seed generates the same shop; random when 0
+}
+
+// DemoSession is a signed-in demo account. The credentials let the app sign in
+// again once the token lapses, for as long as the demo lasts.
+type DemoSession struct {
+	Token     string    `json:"token"`
+	Phone     string    `json:"phone"`
+	Password  string    `json:"password"`
+	User      User      `json:"user"`
+	Business  Business  `json:"business"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+type DemoRepository interface {
+	// InsertProducts, InsertCustomers and InsertSales write generated records in bulk,
+	// setting their IDs
+	InsertProducts(products []Product) error
+	InsertCustomers(customers []Customer) error
+	InsertSales(sales []Sale) error
+	// CountLive counts demo shops that have not expired
+	CountLive(now time.Time) (int64, error)
+	// FindExpired returns demo shops that expired before now
+	FindExpired(now time.Time, limit int) ([]Business, error)
+	// DeleteShop removes a demo shop, everything recorded for it and its account
+	DeleteShop(business Business) error
+}
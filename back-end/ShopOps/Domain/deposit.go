@@ -0,0 +1,156 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DepositItem is a returnable container, such as a crate or a bottle. Its deposit
+// is charged with each product that goes out in it and credited back when the
+// empty is returned.
+type DepositItem struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Name       string             `bson:"name" json:"name"`
+	Amount     Money              `bson:"amount" json:"amount"` // Deposit per container
+	Active     bool               `bson:"active" json:"active"` // Inactive containers are no longer charged, but still taken back
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type CreateDepositItemRequest struct {
+	Name   string `json:"name" validate:"required,max=100"`
+	Amount Money  `json:"amount" validate:"required,gt=0,money"`
+}
+
+type UpdateDepositItemRequest struct {
+	Name   *string `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	Amount *Money  `json:"amount,omitempty" validate:"omitempty,gt=0,money"`
+	Active *bool   `json:"active,omitempty"`
+}
+
+// ProductDeposit is a container that goes out with each unit of a product, e.g.
+// a crate and 24 bottles with a crate of beer
+type ProductDeposit struct {
+	ItemID   primitive.ObjectID `bson:"item_id" json:"item_id"`
+	Quantity float64            `bson:"quantity" json:"quantity"` // Containers per unit sold
+}
+
+// SetProductDepositsRequest replaces the containers a product goes out in; none
+// clears them
+type SetProductDepositsRequest struct {
+	Deposits []ProductDepositInput `json:"deposits" validate:"omitempty,max=10,dive"`
+}
+
+type ProductDepositInput struct {
+	ItemID   string  `json:"item_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// SaleDeposit is a deposit line on a sale: containers going out with the goods,
+// or, with a negative quantity, empties brought back and credited against it
+type SaleDeposit struct {
+	ItemID   primitive.ObjectID `bson:"item_id" json:"item_id"`
+	Name     string             `bson:"name" json:"name"`
+	Quantity float64            `bson:"quantity" json:"quantity"`
+	Amount   Money              `bson:"amount" json:"amount"` // Per container
+	Total    Money              `bson:"total" json:"total"`
+}
+
+// DepositReturnItem is a number of empties of one kind brought back
+type DepositReturnItem struct {
+	ItemID   string  `json:"item_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// DepositEntry is one line of the deposit ledger. A customer's entries add up to
+// the containers they still have out and the deposit owed back on them; a sale's
+// add up to its deposit lines, so a change or void of the sale is recorded as a
+// further entry with the difference.
+type DepositEntry struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	ItemID        primitive.ObjectID  `bson:"item_id" json:"item_id"`
+	Type          DepositEntryType    `bson:"type" json:"type"`
+	Quantity      float64             `bson:"quantity" json:"quantity"` // Containers out; negative when they came back
+	Amount        Money               `bson:"amount" json:"amount"`     // Deposit owed back on them, negative likewise
+	CustomerPhone string              `bson:"customer_phone,omitempty" json:"customer_phone,omitempty" pii:"phone"`
+	CustomerName  string              `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	SaleID        *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	RefundMethod  PaymentMethod       `bson:"refund_method,omitempty" json:"refund_method,omitempty"` // How a return outside a sale was paid out
+	Notes         string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy     *primitive.ObjectID `bson:"created_by,omitempty" json:"created_by,omitempty"` // Nil on sale entries, which the event bus records
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type DepositEntryType string
+
+const (
+	DepositCharged  DepositEntryType = "charged"  // Containers sold with goods
+	DepositReturned DepositEntryType = "returned" // Empties brought back
+)
+
+// ReturnDepositsRequest records empties brought back outside a sale, with their
+// deposit paid out
+type ReturnDepositsRequest struct {
+	CustomerPhone string              `json:"customer_phone,omitempty" validate:"omitempty,phone"`
+	CustomerName  string              `json:"customer_name,omitempty" validate:"max=100"`
+	Items         []DepositReturnItem `json:"items" validate:"required,min=1,dive"`
+	RefundMethod  PaymentMethod       `json:"refund_method,omitempty"` // Defaults to cash
+	Notes         string              `json:"notes,omitempty" validate:"max=500"`
+}
+
+type DepositReturn struct {
+	Entries []DepositEntry `json:"entries"`
+	Refund  Money          `json:"refund"`
+}
+
+type DepositEntryFilters struct {
+	CustomerPhone *string
+	ItemID        *string
+	Type          *DepositEntryType
+	StartDate     *time.Time
+	EndDate       *time.Time
+	Limit         int
+	Offset        int
+}
+
+// DepositHolding is what one customer has out of one kind of container
+type DepositHolding struct {
+	CustomerPhone string             `bson:"customer_phone" json:"customer_phone" pii:"phone"`
+	CustomerName  string             `bson:"customer_name" json:"customer_name" pii:"name"`
+	ItemID        primitive.ObjectID `bson:"item_id" json:"item_id"`
+	Quantity      float64            `bson:"quantity" json:"quantity"`
+	Amount        Money              `bson:"amount" json:"amount"`
+}
+
+// DepositBalance is a customer's containers still out and the deposit owed back
+// on them. Walk-in sales without a phone share a balance with no phone.
+type DepositBalance struct {
+	CustomerPhone string               `json:"customer_phone" pii:"phone"`
+	CustomerName  string               `json:"customer_name,omitempty" pii:"name"`
+	Items         []DepositBalanceItem `json:"items"`
+	Amount        Money                `json:"amount"`
+}
+
+type DepositBalanceItem struct {
+	ItemID   string  `json:"item_id"`
+	Name     string  `json:"name"`
+	Quantity float64 `json:"quantity"`
+	Amount   Money   `json:"amount"`
+}
+
+type DepositRepository interface {
+	CreateItem(item *DepositItem) error
+	FindItemByID(id string) (*DepositItem, error)
+	FindItems(businessID string) ([]DepositItem, error)
+	UpdateItem(item *DepositItem) error
+
+	CreateEntries(entries []DepositEntry) error
+	FindEntries(businessID string, filters DepositEntryFilters) ([]DepositEntry, error)
+	FindEntriesBySale(saleID primitive.ObjectID) ([]DepositEntry, error)
+	// Holdings sums the entries by customer and container, leaving out those that
+	// balance out; only the customer's when phone is given
+	Holdings(businessID string, phone *string) ([]DepositHolding, error)
+}
@@ -0,0 +1,123 @@
+package Domain
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DomainEvent is a change to a shop, written to the outbox in the same transaction
+// as the change itself, so an event is recorded if and only if the change is. The
+// event bus hands each one to every subscriber of its type, tracking each
+// subscriber's delivery on its own: a failing subscriber is retried without the
+// others seeing the event twice. Delivery is at least once, so handlers must
+// tolerate seeing an event again.
+type DomainEvent struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Type          DomainEventType    `bson:"type" json:"type"`
+	AggregateID   string             `bson:"aggregate_id" json:"aggregate_id"` // The sale, product or other record the event is about
+	Payload       bson.Raw           `bson:"payload" json:"-"`
+	Status        DomainEventStatus  `bson:"status" json:"status"`
+	Deliveries    []EventDelivery    `bson:"deliveries,omitempty" json:"deliveries"` // Filled in with the subscribers when first dispatched
+	NextAttemptAt time.Time          `bson:"next_attempt_at" json:"next_attempt_at"`
+	LockedBy      string             `bson:"locked_by,omitempty" json:"locked_by,omitempty"`
+	LockedUntil   *time.Time         `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	OccurredAt    time.Time          `bson:"occurred_at" json:"occurred_at"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+	FinishedAt    *time.Time         `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+type DomainEventType string
+
+const (
+	EventSaleRecorded    DomainEventType = "sale.recorded"    // Payload: the Sale
+	EventSaleUpdated     DomainEventType = "sale.updated"     // Payload: the Sale as updated
+	EventSaleVoided      DomainEventType = "sale.voided"      // Payload: the Sale as voided
+	EventStockAdjusted   DomainEventType = "stock.adjusted"   // Payload: the StockMovement
+	EventStockLow        DomainEventType = "stock.low"        // Payload: a StockLowEvent
+	EventBackorderReady  DomainEventType = "backorder.ready"  // Payload: the Backorder
+	EventBackupCompleted DomainEventType = "backup.completed" // Payload: the ShopBackup
+	EventBackupFailed    DomainEventType = "backup.failed"    // Payload: a BackupFailedEvent
+	EventSyncFailed      DomainEventType = "sync.failed"      // Payload: a SyncFailedEvent
+)
+
+type DomainEventStatus string
+
+const (
+	DomainEventPending   DomainEventStatus = "pending"   // Some subscriber has yet to take it
+	DomainEventDelivered DomainEventStatus = "delivered" // Every subscriber took it
+	DomainEventDead      DomainEventStatus = "dead"      // A subscriber ran out of attempts; only an admin retry tries it again
+)
+
+// EventDelivery is one subscriber's progress with an event
+type EventDelivery struct {
+	Subscriber    string            `bson:"subscriber" json:"subscriber"`
+	Status        DomainEventStatus `bson:"status" json:"status"`
+	Attempts      int               `bson:"attempts" json:"attempts"`
+	NextAttemptAt time.Time         `bson:"next_attempt_at" json:"next_attempt_at"`
+	LastError     string            `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	DeliveredAt   *time.Time        `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+}
+
+// NewDomainEvent builds an event for the outbox with its payload encoded
+func NewDomainEvent(businessID primitive.ObjectID, eventType DomainEventType, aggregateID string, payload interface{}) (*DomainEvent, error) {
+	raw, err := bson.Marshal(payload)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode %s event: %w", eventType, err)
+	}
+	now := time.Now()
+	return &DomainEvent{
+		BusinessID:    businessID,
+		Type:          eventType,
+		AggregateID:   aggregateID,
+		Payload:       raw,
+		Status:        DomainEventPending,
+		NextAttemptAt: now,
+		OccurredAt:    now,
+		UpdatedAt:     now,
+	}, nil
+}
+
+// Decode reads the payload into v, which should be the type the event type carries
+func (e *DomainEvent) Decode(v interface{}) error {
+	if err := bson.Unmarshal(e.Payload, v); err != nil {
+		return fmt.Errorf("failed to decode %s event %s: %w", e.Type, e.ID.Hex(), err)
+	}
+	return nil
+}
+
+type DomainEventFilters struct {
+	Type       *DomainEventType
+	Status     *DomainEventStatus
+	BusinessID *string
+	Limit      int
+	Offset     int
+}
+
+// DomainEventStats counts the events of one type in one status
+type DomainEventStats struct {
+	Type          DomainEventType   `bson:"type" json:"type"`
+	Status        DomainEventStatus `bson:"status" json:"status"`
+	Count         int               `bson:"count" json:"count"`
+	OldestAttempt time.Time         `bson:"oldest_attempt" json:"oldest_attempt"`
+}
+
+// DomainEventRepository is the outbox. Events are written into it by the
+// repositories making the changes they describe, or appended on their own by
+// work that retries until the append succeeds.
+type DomainEventRepository interface {
+	Append(event *DomainEvent) error
+	FindByID(id string) (*DomainEvent, error)
+	Find(filters DomainEventFilters) ([]DomainEvent, error)
+	// Claim locks the next pending event due an attempt for workerID. Events whose
+	// lease has lapsed are due again. Returns nil when none are due.
+	Claim(workerID string, lease time.Duration) (*DomainEvent, error)
+	// Finish saves the deliveries' progress and releases the lock, if workerID still holds it
+	Finish(event *DomainEvent, workerID string) error
+	// Retry gives a dead event's failed deliveries a fresh set of attempts, due now
+	Retry(id string) (*DomainEvent, error)
+	Stats() ([]DomainEventStats, error)
+}
@@ -0,0 +1,115 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailTemplate names one of the messages the system sends
+type EmailTemplate string
+
+const (
+	EmailTemplateExportReady    EmailTemplate = "export_ready"     // A report export emailed in the background is ready
+	EmailTemplateInvite         EmailTemplate = "invite"           // Someone was added to a shop's staff
+	EmailTemplateLowStockDigest EmailTemplate = "low_stock_digest" // Daily list of products below minimum stock
+	EmailTemplateBackupFailed   EmailTemplate = "backup_failed"
+	EmailTemplateTest           EmailTemplate = "test" // Sent from the settings page to check delivery
+)
+
+// EmailMessage is the send log for every email the system sends or skips
+type EmailMessage struct {
+	ID                primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID        *primitive.ObjectID `bson:"business_id,omitempty" json:"business_id,omitempty"`
+	Template          EmailTemplate       `bson:"template" json:"template"`
+	To                string              `bson:"to" json:"to"`
+	FromName          string              `bson:"from_name,omitempty" json:"from_name,omitempty"`
+	ReplyTo           string              `bson:"reply_to,omitempty" json:"reply_to,omitempty"`
+	Subject           string              `bson:"subject" json:"subject"`
+	Text              string              `bson:"text" json:"-"`
+	HTML              string              `bson:"html" json:"-"`
+	Status            EmailStatus         `bson:"status" json:"status"`
+	Provider          string              `bson:"provider,omitempty" json:"provider,omitempty"`
+	ProviderMessageID string              `bson:"provider_message_id,omitempty" json:"provider_message_id,omitempty"`
+	Error             string              `bson:"error,omitempty" json:"error,omitempty"` // Failure, skip or bounce reason
+	CreatedAt         time.Time           `bson:"created_at" json:"created_at"`
+	SentAt            *time.Time          `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	BouncedAt         *time.Time          `bson:"bounced_at,omitempty" json:"bounced_at,omitempty"`
+}
+
+type EmailStatus string
+
+const (
+	EmailStatusQueued     EmailStatus = "queued"
+	EmailStatusSent       EmailStatus = "sent"
+	EmailStatusFailed     EmailStatus = "failed"
+	EmailStatusSkipped    EmailStatus = "skipped"    // The address bounced or complained before
+	EmailStatusBounced    EmailStatus = "bounced"    // Accepted by the provider, then refused by the recipient's server
+	EmailStatusComplained EmailStatus = "complained" // The recipient marked it as spam
+)
+
+// EmailSettings is how a shop's emails are sent and which it gets. Mail always
+// goes out from the server's own address, so it passes SPF and DKIM; shops choose
+// the name it shows and where replies go.
+type EmailSettings struct {
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	FromName   string             `bson:"from_name,omitempty" json:"from_name,omitempty"` // Defaults to the shop's name
+	ReplyTo    string             `bson:"reply_to,omitempty" json:"reply_to,omitempty"`   // Defaults to the shop's email
+	// Who gets the shop's notifications; the owner's email when empty
+	Recipients     []string  `bson:"recipients,omitempty" json:"recipients,omitempty"`
+	LowStockDigest bool      `bson:"low_stock_digest" json:"low_stock_digest"`
+	DigestHour     int       `bson:"digest_hour" json:"digest_hour"` // Local hour the digest goes out
+	LastDigestDate string    `bson:"last_digest_date,omitempty" json:"-"`
+	UpdatedAt      time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// DefaultEmailSettings are a shop's settings until it saves its own
+var DefaultEmailSettings = EmailSettings{
+	DigestHour: 8,
+}
+
+type UpdateEmailSettingsRequest struct {
+	FromName       *string   `json:"from_name,omitempty" validate:"omitempty,max=60"`
+	ReplyTo        *string   `json:"reply_to,omitempty" validate:"omitempty,email"`
+	Recipients     *[]string `json:"recipients,omitempty" validate:"omitempty,max=5,dive,email"`
+	LowStockDigest *bool     `json:"low_stock_digest,omitempty"`
+	DigestHour     *int      `json:"digest_hour,omitempty" validate:"omitempty,min=0,max=23"`
+}
+
+type SendTestEmailRequest struct {
+	To string `json:"to" validate:"required,email"`
+}
+
+// EmailSuppression is an address mail must not go to, because it bounced for good
+// or its owner reported spam
+type EmailSuppression struct {
+	Email     string      `bson:"_id" json:"email"`
+	Reason    EmailStatus `bson:"reason" json:"reason"`
+	Detail    string      `bson:"detail,omitempty" json:"detail,omitempty"`
+	CreatedAt time.Time   `bson:"created_at" json:"created_at"`
+}
+
+type EmailMessageFilters struct {
+	Template *EmailTemplate
+	Status   *EmailStatus
+	To       *string
+	Limit    int
+	Offset   int
+}
+
+type EmailRepository interface {
+	LogMessage(message *EmailMessage) error
+	FindMessageByID(id string) (*EmailMessage, error)
+	FindMessages(businessID string, filters EmailMessageFilters) ([]EmailMessage, error)
+	UpdateMessage(message *EmailMessage) error
+	// MarkBounced records a bounce or complaint against the message the provider sent
+	MarkBounced(provider, providerMessageID string, status EmailStatus, reason string) error
+
+	FindSettings(businessID string) (*EmailSettings, error)
+	SaveSettings(settings *EmailSettings) error
+	FindDigestSettings() ([]EmailSettings, error)
+	SetLastDigestDate(businessID, date string) error
+
+	Suppress(entry *EmailSuppression) error
+	IsSuppressed(email string) (bool, error)
+}
@@ -0,0 +1,223 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Employee struct {
+	ID          primitive.ObjectID   `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID   `bson:"business_id" json:"business_id"`
+	UserID      *primitive.ObjectID  `bson:"user_id,omitempty" json:"user_id,omitempty"` // Login account, if the employee has one
+	Name        string               `bson:"name" json:"name"`
+	Phone       string               `bson:"phone,omitempty" json:"phone,omitempty"`
+	Email       string               `bson:"email,omitempty" json:"email,omitempty"`       // Where the staff invite was sent
+	Position    string               `bson:"position,omitempty" json:"position,omitempty"` // e.g. cashier, sales
+	Status      EmployeeStatus       `bson:"status" json:"status"`
+	Permissions []EmployeePermission `bson:"permissions,omitempty" json:"permissions,omitempty"` // Granted by the owner
+	CreatedBy   primitive.ObjectID   `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time            `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time            `bson:"updated_at" json:"updated_at"`
+}
+
+type EmployeeStatus string
+
+const (
+	EmployeeStatusActive   EmployeeStatus = "active"
+	EmployeeStatusInactive EmployeeStatus = "inactive"
+)
+
+// EmployeePermission lets an employee do what only the owner can otherwise
+type EmployeePermission string
+
+const (
+	// Customers' names and phone numbers in exports, rather than masked
+	PermissionViewPII EmployeePermission = "view_pii"
+)
+
+func (p EmployeePermission) IsValid() bool {
+	switch p {
+	case PermissionViewPII:
+		return true
+	}
+	return false
+}
+
+// HasPermission reports whether the employee was granted permission
+func (e *Employee) HasPermission(permission EmployeePermission) bool {
+	for _, granted := range e.Permissions {
+		if granted == permission {
+			return true
+		}
+	}
+	return false
+}
+
+type CreateEmployeeRequest struct {
+	Name        string               `json:"name" validate:"required"`
+	Phone       string               `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email       string               `json:"email,omitempty" validate:"omitempty,email"` // Sent an invite to the shop
+	Position    string               `json:"position,omitempty"`
+	UserID      *string              `json:"user_id,omitempty"`
+	Permissions []EmployeePermission `json:"permissions,omitempty"` // Owners only
+}
+
+type UpdateEmployeeRequest struct {
+	Name        *string               `json:"name,omitempty"`
+	Phone       *string               `json:"phone,omitempty" validate:"omitempty,phone"`
+	Position    *string               `json:"position,omitempty"`
+	UserID      *string               `json:"user_id,omitempty"`
+	Status      *EmployeeStatus       `json:"status,omitempty"`
+	Permissions *[]EmployeePermission `json:"permissions,omitempty"` // Replaces the employee's permissions; owners only
+}
+
+// TimeEntry is one shift, from clock-in to clock-out on a device
+type TimeEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	EmployeeID primitive.ObjectID `bson:"employee_id" json:"employee_id"`
+	DeviceID   string             `bson:"device_id" json:"device_id"`
+	ClockInAt  time.Time          `bson:"clock_in_at" json:"clock_in_at"`
+	ClockOutAt *time.Time         `bson:"clock_out_at,omitempty" json:"clock_out_at,omitempty"`
+	Minutes    int                `bson:"minutes" json:"minutes"` // Set on clock-out
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type ClockInRequest struct {
+	DeviceID string `json:"device_id" validate:"required"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+type ClockOutRequest struct {
+	Notes string `json:"notes,omitempty"`
+}
+
+type TimeEntryFilters struct {
+	EmployeeID *string
+	DeviceID   *string
+	StartDate  *time.Time
+	EndDate    *time.Time
+	OpenOnly   bool
+	Limit      int
+	Offset     int
+}
+
+// CommissionRule decides how much an employee earns on a sale. A rule can be
+// limited to one employee and/or one product category; the most specific
+// active rule wins.
+type CommissionRule struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Name       string              `bson:"name" json:"name"`
+	EmployeeID *primitive.ObjectID `bson:"employee_id,omitempty" json:"employee_id,omitempty"`
+	Category   string              `bson:"category,omitempty" json:"category,omitempty"`
+	Type       CommissionType      `bson:"type" json:"type"`
+	Rate       float64             `bson:"rate" json:"rate"` // Percent of sale amount, or flat amount per sale
+	Active     bool                `bson:"active" json:"active"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type CommissionType string
+
+const (
+	CommissionTypePercentage CommissionType = "percentage"
+	CommissionTypeFlat       CommissionType = "flat"
+)
+
+// Specificity ranks how narrowly a rule applies; higher wins
+func (r CommissionRule) Specificity() int {
+	score := 0
+	if r.EmployeeID != nil {
+		score += 2
+	}
+	if r.Category != "" {
+		score++
+	}
+	return score
+}
+
+// Matches reports whether the rule applies to a sale by employeeID in category
+func (r CommissionRule) Matches(employeeID primitive.ObjectID, category string) bool {
+	if !r.Active {
+		return false
+	}
+	if r.EmployeeID != nil && *r.EmployeeID != employeeID {
+		return false
+	}
+	if r.Category != "" && r.Category != category {
+		return false
+	}
+	return true
+}
+
+// Commission returns the commission earned on a sale of amount
+func (r CommissionRule) Commission(amount float64) float64 {
+	if r.Type == CommissionTypeFlat {
+		return r.Rate
+	}
+	return amount * r.Rate / 100
+}
+
+type CreateCommissionRuleRequest struct {
+	Name       string         `json:"name" validate:"required"`
+	EmployeeID *string        `json:"employee_id,omitempty"`
+	Category   string         `json:"category,omitempty"`
+	Type       CommissionType `json:"type" validate:"required"`
+	Rate       float64        `json:"rate" validate:"gte=0"`
+}
+
+type UpdateCommissionRuleRequest struct {
+	Name     *string         `json:"name,omitempty"`
+	Category *string         `json:"category,omitempty"`
+	Type     *CommissionType `json:"type,omitempty"`
+	Rate     *float64        `json:"rate,omitempty"`
+	Active   *bool           `json:"active,omitempty"`
+}
+
+type CommissionReport struct {
+	Month           string               `json:"month"` // YYYY-MM
+	StartDate       time.Time            `json:"start_date"`
+	EndDate         time.Time            `json:"end_date"`
+	TotalSales      float64              `json:"total_sales"`
+	TotalCommission float64              `json:"total_commission"`
+	Employees       []EmployeeCommission `json:"employees"`
+}
+
+type EmployeeCommission struct {
+	EmployeeID   primitive.ObjectID `json:"employee_id"`
+	EmployeeName string             `json:"employee_name"`
+	SalesCount   int                `json:"sales_count"`
+	SalesTotal   float64            `json:"sales_total"`
+	Commission   float64            `json:"commission"`
+	HoursWorked  float64            `json:"hours_worked"`
+}
+
+type EmployeeRepository interface {
+	Create(employee *Employee) error
+	// FindInBusiness returns the employee when they work for the shop, removed ones included
+	FindInBusiness(ctx context.Context, id string) (*Employee, error)
+	FindByBusinessID(businessID string, status *EmployeeStatus) ([]Employee, error)
+	FindByUserID(businessID, userID string) (*Employee, error)
+	Update(employee *Employee) error
+
+	CreateTimeEntry(entry *TimeEntry) error
+	FindOpenTimeEntry(employeeID string) (*TimeEntry, error)
+	FindOpenTimeEntryByDevice(businessID, deviceID string) (*TimeEntry, error)
+	CloseTimeEntry(entry *TimeEntry) error
+	FindTimeEntries(businessID string, filters TimeEntryFilters) ([]TimeEntry, error)
+}
+
+type CommissionRuleRepository interface {
+	Create(rule *CommissionRule) error
+	// FindInBusiness returns the rule when the shop set it
+	FindInBusiness(ctx context.Context, id string) (*CommissionRule, error)
+	FindByBusinessID(businessID string) ([]CommissionRule, error)
+	Update(rule *CommissionRule) error
+	Delete(id string) error
+}
@@ -0,0 +1,88 @@
+package Domain
+
+// ErrorCode is the machine-readable reason a request failed. Clients branch on the
+// code rather than the message, and look up translated text under its message key.
+type ErrorCode string
+
+const (
+	ErrCodeBadRequest       ErrorCode = "bad_request"       // The request breaks a business rule
+	ErrCodeInvalidArgument  ErrorCode = "invalid_argument"  // Malformed body, ID or parameter
+	ErrCodeValidationFailed ErrorCode = "validation_failed" // Field-level problems, listed in details
+	ErrCodeUnauthorized     ErrorCode = "unauthorized"
+	ErrCodeAccessDenied     ErrorCode = "access_denied"
+	ErrCodeNotFound         ErrorCode = "not_found"
+	ErrCodeConflict         ErrorCode = "conflict"
+	ErrCodePaymentRequired  ErrorCode = "payment_required" // The shop's plan does not cover this; upgrade to continue
+	ErrCodePayloadTooLarge  ErrorCode = "payload_too_large"
+	ErrCodeRateLimited      ErrorCode = "rate_limited"
+	ErrCodeUnavailable      ErrorCode = "unavailable"
+	ErrCodeMaintenance      ErrorCode = "maintenance" // Read-only maintenance; retry writes after Retry-After
+	ErrCodeInternal         ErrorCode = "internal_error"
+)
+
+// MessageKey is the localization key for the code's generic message
+func (c ErrorCode) MessageKey() string {
+	return "errors." + string(c)
+}
+
+// ErrorDetail describes one problem with one field of the request
+type ErrorDetail struct {
+	Field   string `json:"field,omitempty"`
+	Code    string `json:"code"`
+	Param   string `json:"param,omitempty"` // The rule's limit or choices, such as 5 for max=5
+	Message string `json:"message"`
+}
+
+// AppError is an error with a code the API can map to a status and clients can act on.
+// Usecases return it for failures the caller should tell apart; other errors are
+// classified by the response layer.
+type AppError struct {
+	Code    ErrorCode
+	Message string
+	Key     string // Localization key for this specific message; the code's key when empty
+	Details []ErrorDetail
+	Err     error // Underlying cause, logged but not sent to clients
+}
+
+func (e *AppError) Error() string {
+	if e.Err != nil && e.Message == "" {
+		return e.Err.Error()
+	}
+	return e.Message
+}
+
+func (e *AppError) Unwrap() error {
+	return e.Err
+}
+
+func (e *AppError) MessageKey() string {
+	if e.Key != "" {
+		return e.Key
+	}
+	return e.Code.MessageKey()
+}
+
+func NewAppError(code ErrorCode, message string, details ...ErrorDetail) *AppError {
+	return &AppError{Code: code, Message: message, Details: details}
+}
+
+func NotFoundError(message string) *AppError {
+	return &AppError{Code: ErrCodeNotFound, Message: message}
+}
+
+func AccessDeniedError(message string) *AppError {
+	return &AppError{Code: ErrCodeAccessDenied, Message: message}
+}
+
+// StaleVersionError rejects an update to a record that changed after the client read it
+func StaleVersionError(entity string) *AppError {
+	return &AppError{
+		Code:    ErrCodeConflict,
+		Message: entity + " was changed by someone else; reload it and try again",
+		Key:     "errors.stale_version",
+	}
+}
+
+func ValidationError(message string, details ...ErrorDetail) *AppError {
+	return &AppError{Code: ErrCodeValidationFailed, Message: message, Details: details}
+}
@@ -1,26 +1,28 @@
 package Domain
 
 import (
+	"context"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type Expense struct {
-	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
-	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
-	LocalID     string             `bson:"local_id,omitempty" json:"local_id,omitempty"` // For offline sync
-	Category    ExpenseCategory    `bson:"category" json:"category" validate:"required"`
-	Amount      float64            `bson:"amount" json:"amount" validate:"required,gt=0"`
-	Description string             `bson:"description,omitempty" json:"description,omitempty"`
-	ReceiptURL  string             `bson:"receipt_url,omitempty" json:"receipt_url,omitempty"`
-	Date        time.Time          `bson:"date" json:"date"`
-	Status      ExpenseStatus      `bson:"status" json:"status"`
-	Synced      bool               `bson:"synced" json:"synced"`
-	SyncedAt    *time.Time         `bson:"synced_at,omitempty" json:"synced_at,omitempty"`
-	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
-	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
-	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	LocalID     string              `bson:"local_id,omitempty" json:"local_id,omitempty"` // For offline sync
+	Category    ExpenseCategory     `bson:"category" json:"category" validate:"required"`
+	Amount      Money               `bson:"amount" json:"amount" validate:"required,gt=0"`
+	Description string              `bson:"description,omitempty" json:"description,omitempty"`
+	ReceiptURL  string              `bson:"receipt_url,omitempty" json:"receipt_url,omitempty"`
+	RecurringID *primitive.ObjectID `bson:"recurring_id,omitempty" json:"recurring_id,omitempty"` // Set when generated from a recurring expense
+	Date        time.Time           `bson:"date" json:"date"`
+	Status      ExpenseStatus       `bson:"status" json:"status"`
+	Synced      bool                `bson:"synced" json:"synced"`
+	SyncedAt    *time.Time          `bson:"synced_at,omitempty" json:"synced_at,omitempty"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
 }
 
 type ExpenseCategory string
@@ -46,8 +48,9 @@ const (
 
 type CreateExpenseRequest struct {
 	Category    ExpenseCategory `json:"category" validate:"required"`
-	Amount      float64         `json:"amount" validate:"required,gt=0"`
+	Amount      Money           `json:"amount" validate:"required,gt=0,money"`
 	Description string          `json:"description,omitempty"`
+	ReceiptURL  string          `json:"receipt_url,omitempty"`
 	Date        time.Time       `json:"date"`
 	LocalID     string          `json:"local_id,omitempty"` // For offline sync
 }
@@ -61,14 +64,15 @@ type ExpenseSummary struct {
 
 type ExpenseRepository interface {
 	Create(expense *Expense) error
-	FindByID(id string) (*Expense, error)
+	// FindInBusiness returns the expense when the shop recorded it
+	FindInBusiness(ctx context.Context, id string) (*Expense, error)
 	FindByBusinessID(businessID string, filters ExpenseFilters) ([]Expense, error)
 	FindByLocalID(businessID, localID string) (*Expense, error)
 	Update(expense *Expense) error
 	UpdateStatus(id string, status ExpenseStatus) error
 	Delete(id string) error
 	GetSummaryByCategory(businessID string, startDate, endDate time.Time) ([]ExpenseSummary, error)
-	GetTotal(businessID string, startDate, endDate time.Time) (float64, error)
+	GetTotal(businessID string, startDate, endDate time.Time) (Money, error)
 }
 
 type ExpenseFilters struct {
@@ -76,6 +80,93 @@ type ExpenseFilters struct {
 	EndDate   *time.Time
 	Category  *ExpenseCategory
 	Status    *ExpenseStatus
-	Limit     int
-	Offset    int
+	// Changed at or after this time, including voids
+	UpdatedSince *time.Time
+	Limit        int
+	Offset       int
+}
+
+// RecurringExpense is a template that generates an expense every period,
+// e.g. monthly rent or salaries
+type RecurringExpense struct {
+	ID          primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID     `bson:"business_id" json:"business_id"`
+	Category    ExpenseCategory        `bson:"category" json:"category"`
+	Amount      Money                  `bson:"amount" json:"amount"`
+	Description string                 `bson:"description,omitempty" json:"description,omitempty"`
+	Frequency   RecurrenceFrequency    `bson:"frequency" json:"frequency"`
+	StartDate   time.Time              `bson:"start_date" json:"start_date"`
+	EndDate     *time.Time             `bson:"end_date,omitempty" json:"end_date,omitempty"`
+	NextRunAt   time.Time              `bson:"next_run_at" json:"next_run_at"`
+	Occurrences int                    `bson:"occurrences" json:"occurrences"` // Expenses generated so far
+	Status      RecurringExpenseStatus `bson:"status" json:"status"`
+	CreatedBy   primitive.ObjectID     `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+type RecurrenceFrequency string
+
+const (
+	RecurrenceWeekly  RecurrenceFrequency = "weekly"
+	RecurrenceMonthly RecurrenceFrequency = "monthly"
+	RecurrenceYearly  RecurrenceFrequency = "yearly"
+)
+
+// Occurrence returns the date of the n-th occurrence (0-based) after start.
+// Monthly and yearly dates are clamped to the end of shorter months.
+func (f RecurrenceFrequency) Occurrence(start time.Time, n int) time.Time {
+	switch f {
+	case RecurrenceWeekly:
+		return start.AddDate(0, 0, 7*n)
+	case RecurrenceYearly:
+		return addMonthsClamped(start, 12*n)
+	default:
+		return addMonthsClamped(start, n)
+	}
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	firstOfMonth := time.Date(t.Year(), t.Month()+time.Month(months), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	lastDay := firstOfMonth.AddDate(0, 1, -1).Day()
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return firstOfMonth.AddDate(0, 0, day-1)
+}
+
+type RecurringExpenseStatus string
+
+const (
+	RecurringExpenseStatusActive RecurringExpenseStatus = "active"
+	RecurringExpenseStatusPaused RecurringExpenseStatus = "paused"
+	RecurringExpenseStatusEnded  RecurringExpenseStatus = "ended"
+)
+
+type CreateRecurringExpenseRequest struct {
+	Category    ExpenseCategory     `json:"category" validate:"required"`
+	Amount      Money               `json:"amount" validate:"required,gt=0,money"`
+	Description string              `json:"description,omitempty"`
+	Frequency   RecurrenceFrequency `json:"frequency" validate:"required"`
+	StartDate   time.Time           `json:"start_date"`
+	EndDate     *time.Time          `json:"end_date,omitempty"`
+}
+
+type UpdateRecurringExpenseRequest struct {
+	Category    *ExpenseCategory        `json:"category,omitempty"`
+	Amount      *Money                  `json:"amount,omitempty" validate:"omitempty,gt=0,money"`
+	Description *string                 `json:"description,omitempty"`
+	EndDate     *time.Time              `json:"end_date,omitempty"`
+	Status      *RecurringExpenseStatus `json:"status,omitempty"` // active or paused
+}
+
+type RecurringExpenseRepository interface {
+	Create(recurring *RecurringExpense) error
+	// FindInBusiness returns the schedule when the shop set it up
+	FindInBusiness(ctx context.Context, id string) (*RecurringExpense, error)
+	FindByBusinessID(businessID string) ([]RecurringExpense, error)
+	FindDue(asOf time.Time, limit int) ([]RecurringExpense, error)
+	Update(recurring *RecurringExpense) error
+	Delete(id string) error
 }
@@ -0,0 +1,52 @@
+package Domain
+
+import "time"
+
+type ForecastMethod string
+
+const (
+	// Moving average scaled by a day-of-week index
+	ForecastMethodSeasonal ForecastMethod = "seasonal"
+	// Plain moving average, used while there is too little history for weekday patterns
+	ForecastMethodMovingAverage ForecastMethod = "moving_average"
+	// No sales in the history window
+	ForecastMethodNoHistory ForecastMethod = "no_history"
+)
+
+type ForecastDay struct {
+	Date     string  `json:"date"`
+	Quantity float64 `json:"quantity"`
+}
+
+type ProductForecast struct {
+	ProductID      string         `json:"product_id"`
+	Name           string         `json:"name"`
+	Category       string         `json:"category,omitempty"`
+	Method         ForecastMethod `json:"method"`
+	HistoryDays    int            `json:"history_days"` // Days of history actually used
+	AverageDaily   float64        `json:"average_daily"`
+	NextWeekDemand float64        `json:"next_week_demand"`
+	Daily          []ForecastDay  `json:"daily"`
+
+	CurrentStock    float64  `json:"current_stock"`
+	MinStock        float64  `json:"min_stock,omitempty"`
+	DaysOfCover     *float64 `json:"days_of_cover,omitempty"` // Nil when nothing is expected to sell
+	LeadDemand      float64  `json:"lead_demand"`             // Expected sales over the lead time
+	ReorderQuantity float64  `json:"reorder_quantity"`
+	NeedsReorder    bool     `json:"needs_reorder"`
+}
+
+type ForecastReport struct {
+	GeneratedAt time.Time         `json:"generated_at"`
+	StartDate   string            `json:"start_date"` // First forecast day
+	HistoryDays int               `json:"history_days"`
+	LeadDays    int               `json:"lead_days"`
+	Products    []ProductForecast `json:"products"`
+}
+
+type ForecastOptions struct {
+	ProductID   string
+	Category    string
+	HistoryDays int
+	LeadDays    int
+}
@@ -0,0 +1,132 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type GiftCard struct {
+	ID             primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID     primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Code           string             `bson:"code" json:"code"`
+	Type           GiftCardType       `bson:"type" json:"type"`
+	InitialBalance Money              `bson:"initial_balance" json:"initial_balance"`
+	Balance        Money              `bson:"balance" json:"balance"`
+	CustomerName   string             `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	CustomerPhone  string             `bson:"customer_phone,omitempty" json:"customer_phone,omitempty" pii:"phone"`
+	ExpiresAt      *time.Time         `bson:"expires_at,omitempty" json:"expires_at,omitempty"`
+	Status         GiftCardStatus     `bson:"status" json:"status"`
+	Notes          string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy      primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt      time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type GiftCardType string
+
+const (
+	GiftCardTypeGiftCard    GiftCardType = "gift_card"
+	GiftCardTypeStoreCredit GiftCardType = "store_credit"
+)
+
+type GiftCardStatus string
+
+const (
+	GiftCardStatusActive   GiftCardStatus = "active"
+	GiftCardStatusRedeemed GiftCardStatus = "redeemed"
+	GiftCardStatusExpired  GiftCardStatus = "expired"
+	GiftCardStatusVoided   GiftCardStatus = "voided"
+)
+
+// IsExpired reports whether the card has passed its expiry date at the given time
+func (g *GiftCard) IsExpired(at time.Time) bool {
+	return g.ExpiresAt != nil && at.After(*g.ExpiresAt)
+}
+
+type GiftCardTransaction struct {
+	ID           primitive.ObjectID      `bson:"_id,omitempty" json:"id"`
+	BusinessID   primitive.ObjectID      `bson:"business_id" json:"business_id"`
+	GiftCardID   primitive.ObjectID      `bson:"gift_card_id" json:"gift_card_id"`
+	Type         GiftCardTransactionType `bson:"type" json:"type"`
+	Amount       Money                   `bson:"amount" json:"amount"`
+	BalanceAfter Money                   `bson:"balance_after" json:"balance_after"`
+	SaleID       *primitive.ObjectID     `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	Note         string                  `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedBy    primitive.ObjectID      `bson:"created_by" json:"created_by"`
+	CreatedAt    time.Time               `bson:"created_at" json:"created_at"`
+}
+
+type GiftCardTransactionType string
+
+const (
+	GiftCardTransactionIssue  GiftCardTransactionType = "issue"
+	GiftCardTransactionRedeem GiftCardTransactionType = "redeem"
+	GiftCardTransactionReload GiftCardTransactionType = "reload"
+	GiftCardTransactionRefund GiftCardTransactionType = "refund" // Tender given back when its sale is undone
+	GiftCardTransactionExpire GiftCardTransactionType = "expire"
+	GiftCardTransactionVoid   GiftCardTransactionType = "void"
+)
+
+type IssueGiftCardRequest struct {
+	Type          GiftCardType `json:"type,omitempty"` // defaults to gift_card
+	Amount        Money        `json:"amount" validate:"required,gt=0,money"`
+	CustomerName  string       `json:"customer_name,omitempty"`
+	CustomerPhone string       `json:"customer_phone,omitempty" validate:"omitempty,phone"`
+	ExpiryDays    *int         `json:"expiry_days,omitempty"` // 0 means never expires
+	Notes         string       `json:"notes,omitempty"`
+}
+
+type RedeemGiftCardRequest struct {
+	Code   string  `json:"code" validate:"required"`
+	Amount Money   `json:"amount" validate:"required,gt=0,money"`
+	SaleID *string `json:"sale_id,omitempty"`
+}
+
+type ReloadGiftCardRequest struct {
+	Amount Money  `json:"amount" validate:"required,gt=0,money"`
+	Note   string `json:"note,omitempty"`
+}
+
+type GiftCardLiability struct {
+	TotalOutstanding Money                   `json:"total_outstanding"`
+	ActiveCards      int                     `json:"active_cards"`
+	ExpiringSoon     Money                   `json:"expiring_soon"` // balance expiring within 30 days
+	ByType           []GiftCardLiabilityType `json:"by_type"`
+}
+
+type GiftCardLiabilityType struct {
+	Type        GiftCardType `json:"type" bson:"_id"`
+	Outstanding Money        `json:"outstanding" bson:"outstanding"`
+	Count       int          `json:"count" bson:"count"`
+}
+
+type GiftCardRepository interface {
+	Create(card *GiftCard) error
+	// FindInBusiness returns the gift card when the shop issued it
+	FindInBusiness(ctx context.Context, id string) (*GiftCard, error)
+	FindByCode(businessID, code string) (*GiftCard, error)
+	FindByBusinessID(businessID string, filters GiftCardFilters) ([]GiftCard, error)
+	Redeem(id string, amount Money, saleID *string, userID string) (*GiftCard, error)
+	Reload(id string, amount Money, note string, userID string) (*GiftCard, error)
+	// Refund gives back tender taken for a sale that was undone, whatever has
+	// happened to the card since, except when it was voided
+	Refund(id string, amount Money, saleID *string, note string, userID string) (*GiftCard, error)
+	UpdateStatus(id string, status GiftCardStatus, txType GiftCardTransactionType, userID string) error
+	ExpireOverdue(businessID string, now time.Time) (int, error)
+	GetTransactions(giftCardID string, limit int) ([]GiftCardTransaction, error)
+	GetLiability(businessID string, now time.Time) (*GiftCardLiability, error)
+	ReassignCustomer(businessID, fromPhone, toPhone, toName string) (int64, error)
+}
+
+type GiftCardFilters struct {
+	Status *GiftCardStatus
+	Type   *GiftCardType
+	Phone  *string
+	Limit  int
+	Offset int
+}
+
+// Default number of days a newly issued gift card stays valid
+const DefaultGiftCardExpiryDays = 365
@@ -0,0 +1,137 @@
+package Domain
+
+// ImportEntity is a kind of record a shop can bring in from a spreadsheet
+type ImportEntity string
+
+const (
+	ImportEntityProducts  ImportEntity = "products"
+	ImportEntityCustomers ImportEntity = "customers"
+	ImportEntitySuppliers ImportEntity = "suppliers"
+	ImportEntityExpenses  ImportEntity = "expenses"
+)
+
+func (e ImportEntity) IsValid() bool {
+	switch e {
+	case ImportEntityProducts, ImportEntityCustomers, ImportEntitySuppliers, ImportEntityExpenses:
+		return true
+	}
+	return false
+}
+
+type TemplateFormat string
+
+const (
+	TemplateFormatCSV  TemplateFormat = "csv"
+	TemplateFormatXLSX TemplateFormat = "xlsx"
+)
+
+func (f TemplateFormat) IsValid() bool {
+	return f == TemplateFormatCSV || f == TemplateFormatXLSX
+}
+
+type ImportColumnType string
+
+const (
+	ImportColumnText    ImportColumnType = "text"
+	ImportColumnNumber  ImportColumnType = "number"  // Zero or more
+	ImportColumnInteger ImportColumnType = "integer" // Zero or more
+	ImportColumnDate    ImportColumnType = "date"    // YYYY-MM-DD
+)
+
+// ImportColumn is one column of an import file. Options, when set, are the
+// only values the column takes; XLSX templates offer them as a dropdown.
+type ImportColumn struct {
+	Header   string           `json:"header"`
+	Type     ImportColumnType `json:"type"`
+	Required bool             `json:"required"`
+	Options  []string         `json:"options,omitempty"`
+	Open     bool             `json:"open,omitempty"` // Options are suggestions; other values are taken too
+	Note     string           `json:"note,omitempty"`
+}
+
+// ImportTemplate is the layout of an import file with sample rows to copy
+type ImportTemplate struct {
+	Entity  ImportEntity   `json:"entity"`
+	Columns []ImportColumn `json:"columns"`
+	Samples [][]string     `json:"samples"` // One value per column
+}
+
+var yesNo = []string{"yes", "no"}
+
+// ImportTemplates are the columns every shop's templates start with; the
+// options of open-ended columns, such as product categories, are the shop's own
+var ImportTemplates = map[ImportEntity]ImportTemplate{
+	ImportEntityProducts: {
+		Entity: ImportEntityProducts,
+		Columns: []ImportColumn{
+			{Header: "name", Type: ImportColumnText, Required: true},
+			{Header: "sku", Type: ImportColumnText, Note: "Unique within the shop"},
+			{Header: "barcode", Type: ImportColumnText},
+			{Header: "category", Type: ImportColumnText, Open: true, Note: "One of the shop's categories, or a new one"},
+			{Header: "unit", Type: ImportColumnText, Note: "e.g. pcs, kg, litre"},
+			{Header: "cost_price", Type: ImportColumnNumber, Required: true},
+			{Header: "selling_price", Type: ImportColumnNumber, Required: true},
+			{Header: "stock", Type: ImportColumnNumber},
+			{Header: "min_stock", Type: ImportColumnNumber, Note: "Low stock alert level"},
+			{Header: "max_stock", Type: ImportColumnNumber},
+			{Header: "plu", Type: ImportColumnText, Note: "Scale item number, up to 6 digits"},
+			{Header: "weighed", Type: ImportColumnText, Options: yesNo, Note: "yes when sold by the kg"},
+			{Header: "description", Type: ImportColumnText},
+		},
+		Samples: [][]string{
+			{"Coca-Cola 500ml", "COKE-500", "5449000000996", "Drinks", "pcs", "18", "25", "48", "12", "", "", "no", ""},
+			{"Teff (white)", "TEFF-W", "", "Grains", "kg", "95", "120", "50", "10", "", "101", "yes", "Magna teff"},
+		},
+	},
+	ImportEntityCustomers: {
+		Entity: ImportEntityCustomers,
+		Columns: []ImportColumn{
+			{Header: "name", Type: ImportColumnText, Required: true},
+			{Header: "phone", Type: ImportColumnText, Note: "e.g. 0911234567 or +251911234567"},
+			{Header: "email", Type: ImportColumnText},
+			{Header: "address", Type: ImportColumnText},
+			{Header: "tier", Type: ImportColumnText, Options: []string{string(CustomerTierRetail), string(CustomerTierWholesale), string(CustomerTierVIP)}},
+			{Header: "tags", Type: ImportColumnText, Note: "Separated by ;"},
+			{Header: "marketing_opt_in", Type: ImportColumnText, Options: yesNo, Note: "yes only if the customer agreed to promotional SMS"},
+			{Header: "notes", Type: ImportColumnText},
+		},
+		Samples: [][]string{
+			{"Abebe Kebede", "0911234567", "abebe@example.com", "Bole, Addis Ababa", "retail", "regular", "yes", ""},
+			{"Hana Mini Market", "0922345678", "", "Piassa", "wholesale", "reseller;credit", "no", "Pays monthly"},
+		},
+	},
+	ImportEntitySuppliers: {
+		Entity: ImportEntitySuppliers,
+		Columns: []ImportColumn{
+			{Header: "name", Type: ImportColumnText, Required: true},
+			{Header: "contact_name", Type: ImportColumnText},
+			{Header: "phone", Type: ImportColumnText},
+			{Header: "email", Type: ImportColumnText},
+			{Header: "address", Type: ImportColumnText},
+			{Header: "tin", Type: ImportColumnText, Note: "Tax identification number"},
+			{Header: "payment_terms_days", Type: ImportColumnInteger, Note: "Days until an invoice is due"},
+			{Header: "notes", Type: ImportColumnText},
+		},
+		Samples: [][]string{
+			{"East Africa Bottling", "Sales desk", "0116612345", "orders@example.com", "Addis Ababa", "0001234567", "30", ""},
+			{"Merkato Grain Traders", "Tesfaye", "0933456789", "", "Merkato", "", "0", "Cash on delivery"},
+		},
+	},
+	ImportEntityExpenses: {
+		Entity: ImportEntityExpenses,
+		Columns: []ImportColumn{
+			{Header: "date", Type: ImportColumnDate, Required: true, Note: "YYYY-MM-DD"},
+			{Header: "category", Type: ImportColumnText, Required: true, Options: []string{
+				string(ExpenseCategoryRent), string(ExpenseCategoryUtilities), string(ExpenseCategoryStockPurchase),
+				string(ExpenseCategoryTransport), string(ExpenseCategorySalaries), string(ExpenseCategoryMarketing),
+				string(ExpenseCategoryMaintenance), string(ExpenseCategoryOther),
+			}},
+			{Header: "amount", Type: ImportColumnNumber, Required: true},
+			{Header: "description", Type: ImportColumnText},
+		},
+		Samples: [][]string{
+			{"2026-01-01", "rent", "15000", "January shop rent"},
+			{"2026-01-05", "utilities", "850.50", "Electricity"},
+		},
+	},
+}
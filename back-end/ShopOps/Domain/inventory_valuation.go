@@ -0,0 +1,79 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// InventorySnapshot is the stock on hand and its value at the close of one business day.
+// Day is the business day key (midnight UTC of the local date), like the P&L rows.
+type InventorySnapshot struct {
+	ID            primitive.ObjectID      `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID      `bson:"business_id" json:"business_id"`
+	Day           time.Time               `bson:"day" json:"day"`
+	TakenAt       time.Time               `bson:"taken_at" json:"taken_at"`
+	ProductCount  int                     `bson:"product_count" json:"product_count"`
+	TotalQuantity float64                 `bson:"total_quantity" json:"total_quantity"`
+	TotalValue    Money                   `bson:"total_value" json:"total_value"`   // At cost
+	RetailValue   Money                   `bson:"retail_value" json:"retail_value"` // At selling price
+	Lines         []InventorySnapshotLine `bson:"lines,omitempty" json:"lines,omitempty"`
+
+	// Consigned stock is the consignors', so it is left out of the lines and totals
+	ConsignedQuantity    float64 `bson:"consigned_quantity,omitempty" json:"consigned_quantity,omitempty"`
+	ConsignedRetailValue Money   `bson:"consigned_retail_value,omitempty" json:"consigned_retail_value,omitempty"`
+}
+
+// InventorySnapshotLine is one product with stock on hand. Products with no stock are left out.
+type InventorySnapshotLine struct {
+	ProductID   primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Name        string             `bson:"name" json:"name"`
+	Category    string             `bson:"category,omitempty" json:"category,omitempty"`
+	Quantity    float64            `bson:"quantity" json:"quantity"`
+	UnitCost    Money              `bson:"unit_cost" json:"unit_cost"`
+	UnitPrice   Money              `bson:"unit_price" json:"unit_price"`
+	Value       Money              `bson:"value" json:"value"`
+	RetailValue Money              `bson:"retail_value" json:"retail_value"`
+}
+
+type CategoryValuation struct {
+	Category    string  `json:"category"`
+	Quantity    float64 `json:"quantity"`
+	Value       Money   `json:"value"`
+	RetailValue Money   `json:"retail_value"`
+}
+
+// InventoryValuation is the stock value as of a date: the stock ledger's balances at
+// the close of that date, or the live stock when the date is today
+type InventoryValuation struct {
+	AsOf          string                  `json:"as_of"`
+	Live          bool                    `json:"live"`
+	TakenAt       time.Time               `json:"taken_at"`
+	ProductCount  int                     `json:"product_count"`
+	TotalQuantity float64                 `json:"total_quantity"`
+	TotalValue    Money                   `json:"total_value"`
+	RetailValue   Money                   `json:"retail_value"`
+	Categories    []CategoryValuation     `json:"categories"`
+	Lines         []InventorySnapshotLine `json:"lines"`
+
+	// Stock held on consignment, in every category; not counted in the totals
+	ConsignedQuantity    float64 `json:"consigned_quantity,omitempty"`
+	ConsignedRetailValue Money   `json:"consigned_retail_value,omitempty"`
+}
+
+// InventoryValuationPoint is one day of the stock value history
+type InventoryValuationPoint struct {
+	Day           string  `json:"day"`
+	ProductCount  int     `json:"product_count"`
+	TotalQuantity float64 `json:"total_quantity"`
+	TotalValue    Money   `json:"total_value"`
+	RetailValue   Money   `json:"retail_value"`
+}
+
+type InventorySnapshotRepository interface {
+	// Save stores the snapshot, replacing any earlier one for the same day
+	Save(snapshot *InventorySnapshot) error
+	Exists(businessID string, day time.Time) (bool, error)
+	// FindTotals returns the snapshots between the days without their lines, oldest first
+	FindTotals(businessID string, fromDay, toDay time.Time) ([]InventorySnapshot, error)
+}
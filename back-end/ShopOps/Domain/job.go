@@ -0,0 +1,83 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Job is one unit of background work in the persistent queue. Workers claim jobs
+// under a lease, so a job whose worker dies is picked up again once the lease lapses.
+type Job struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Type        JobType             `bson:"type" json:"type"`
+	BusinessID  *primitive.ObjectID `bson:"business_id,omitempty" json:"business_id,omitempty"`
+	Payload     map[string]string   `bson:"payload,omitempty" json:"payload,omitempty"`
+	Status      JobStatus           `bson:"status" json:"status"`
+	Attempts    int                 `bson:"attempts" json:"attempts"`
+	MaxAttempts int                 `bson:"max_attempts" json:"max_attempts"`
+	RunAt       time.Time           `bson:"run_at" json:"run_at"` // Not claimed before this; pushed back between retries
+	LastError   string              `bson:"last_error,omitempty" json:"last_error,omitempty"`
+	LockedBy    string              `bson:"locked_by,omitempty" json:"locked_by,omitempty"`
+	LockedUntil *time.Time          `bson:"locked_until,omitempty" json:"locked_until,omitempty"`
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+	FinishedAt  *time.Time          `bson:"finished_at,omitempty" json:"finished_at,omitempty"`
+}
+
+type JobType string
+
+const (
+	JobTypeSMSCampaign     JobType = "sms_campaign"
+	JobTypeSMSReceipt      JobType = "sms_receipt"
+	JobTypeShopBackup      JobType = "shop_backup"
+	JobTypeWebhookDelivery JobType = "webhook_delivery"
+	JobTypeTelegramMessage JobType = "telegram_message"
+	JobTypeEmail           JobType = "email"
+	JobTypeReportExport    JobType = "report_export" // A report export emailed when ready
+	JobTypePush            JobType = "push_notification"
+	JobTypeArchive         JobType = "archive" // Moving old sales and stock movements to the archive
+
+	JobTypeMaintenanceAnnouncement JobType = "maintenance_announcement" // Pushing a maintenance window to every device
+)
+
+type JobStatus string
+
+const (
+	JobStatusQueued    JobStatus = "queued" // Waiting for its first run or its next retry
+	JobStatusRunning   JobStatus = "running"
+	JobStatusSucceeded JobStatus = "succeeded"
+	JobStatusDead      JobStatus = "dead" // Out of attempts; only an admin retry runs it again
+)
+
+type JobFilters struct {
+	Type       *JobType
+	Status     *JobStatus
+	BusinessID *string
+	Limit      int
+	Offset     int
+}
+
+// JobStats counts the jobs of one type in one status
+type JobStats struct {
+	Type        JobType   `bson:"type" json:"type"`
+	Status      JobStatus `bson:"status" json:"status"`
+	Count       int       `bson:"count" json:"count"`
+	OldestRunAt time.Time `bson:"oldest_run_at" json:"oldest_run_at"`
+}
+
+type JobRepository interface {
+	Create(job *Job) error
+	FindByID(id string) (*Job, error)
+	Find(filters JobFilters) ([]Job, error)
+	// Claim locks the next due job of jobType for workerID, counting an attempt.
+	// Running jobs whose lease has lapsed are due again. Returns nil when none are due.
+	Claim(jobType JobType, workerID string, lease time.Duration) (*Job, error)
+	// Heartbeat extends the lease while workerID still holds it
+	Heartbeat(id, workerID string, lease time.Duration) error
+	// Finish saves the outcome of a run and releases the lock, if workerID still holds it
+	Finish(job *Job, workerID string) error
+	// Retry queues a dead or waiting job to run now with a fresh set of attempts
+	Retry(id string) (*Job, error)
+	Stats() ([]JobStats, error)
+}
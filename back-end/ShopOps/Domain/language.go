@@ -0,0 +1,48 @@
+package Domain
+
+import "strings"
+
+// Language is a language server-generated text can be written in: error messages,
+// notifications, receipt labels and export headers. Data the shop typed, such as
+// product names, is never translated.
+type Language string
+
+const (
+	LanguageEnglish Language = "en"
+	LanguageAmharic Language = "am"
+	LanguageOromo   Language = "om" // Afaan Oromo
+)
+
+// Languages are the supported languages, in the order a tie is broken
+var Languages = []Language{LanguageEnglish, LanguageAmharic, LanguageOromo}
+
+// DefaultLanguage is used when neither the request nor the shop picks one
+const DefaultLanguage = LanguageEnglish
+
+func (l Language) IsValid() bool {
+	for _, language := range Languages {
+		if l == language {
+			return true
+		}
+	}
+	return false
+}
+
+// ParseLanguage reads a language tag such as "am", "am-ET" or "om_ET"; false when
+// the language is not supported
+func ParseLanguage(tag string) (Language, bool) {
+	tag = strings.ToLower(strings.TrimSpace(tag))
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	language := Language(tag)
+	return language, language.IsValid()
+}
+
+// LanguageOr is l, or fallback when l is not set or not supported
+func LanguageOr(l, fallback Language) Language {
+	if l.IsValid() {
+		return l
+	}
+	return fallback
+}
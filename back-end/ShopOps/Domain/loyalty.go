@@ -0,0 +1,124 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// LoyaltyProgram holds a business's earn and redemption settings
+type LoyaltyProgram struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID      primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Enabled         bool               `bson:"enabled" json:"enabled"`
+	PointsPerUnit   float64            `bson:"points_per_unit" json:"points_per_unit"`     // Points earned per 1 unit of currency spent
+	PointValue      float64            `bson:"point_value" json:"point_value"`             // Currency value of 1 point when redeemed
+	MinPurchase     Money              `bson:"min_purchase,omitempty" json:"min_purchase"` // Sales below this amount earn nothing
+	MinRedeemPoints float64            `bson:"min_redeem_points,omitempty" json:"min_redeem_points"`
+	Rules           []LoyaltyRule      `bson:"rules,omitempty" json:"rules,omitempty"`
+	UpdatedBy       primitive.ObjectID `bson:"updated_by" json:"updated_by"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// LoyaltyRule boosts earning for matching purchases. A rule with neither
+// category nor product applies to every sale.
+type LoyaltyRule struct {
+	Name        string  `bson:"name" json:"name"`
+	Category    string  `bson:"category,omitempty" json:"category,omitempty"`
+	ProductID   string  `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	Multiplier  float64 `bson:"multiplier,omitempty" json:"multiplier,omitempty"`     // Applied to base points
+	BonusPoints float64 `bson:"bonus_points,omitempty" json:"bonus_points,omitempty"` // Flat points added
+	MinAmount   Money   `bson:"min_amount,omitempty" json:"min_amount,omitempty"`     // Sale amount needed for the rule to apply
+	Active      bool    `bson:"active" json:"active"`
+}
+
+type LoyaltyAccount struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID       primitive.ObjectID `bson:"business_id" json:"business_id"`
+	CustomerPhone    string             `bson:"customer_phone" json:"customer_phone" pii:"phone"`
+	CustomerName     string             `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	Points           float64            `bson:"points" json:"points"`
+	LifetimeEarned   float64            `bson:"lifetime_earned" json:"lifetime_earned"`
+	LifetimeRedeemed float64            `bson:"lifetime_redeemed" json:"lifetime_redeemed"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type LoyaltyTransaction struct {
+	ID           primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	BusinessID   primitive.ObjectID     `bson:"business_id" json:"business_id"`
+	AccountID    primitive.ObjectID     `bson:"account_id" json:"account_id"`
+	Type         LoyaltyTransactionType `bson:"type" json:"type"`
+	Points       float64                `bson:"points" json:"points"`
+	Value        Money                  `bson:"value" json:"value"` // Currency value at the time of the transaction
+	BalanceAfter float64                `bson:"balance_after" json:"balance_after"`
+	SaleID       *primitive.ObjectID    `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	Note         string                 `bson:"note,omitempty" json:"note,omitempty"`
+	CreatedBy    primitive.ObjectID     `bson:"created_by" json:"created_by"`
+	CreatedAt    time.Time              `bson:"created_at" json:"created_at"`
+}
+
+type LoyaltyTransactionType string
+
+const (
+	LoyaltyTransactionEarn    LoyaltyTransactionType = "earn"
+	LoyaltyTransactionRedeem  LoyaltyTransactionType = "redeem"
+	LoyaltyTransactionAdjust  LoyaltyTransactionType = "adjust"
+	LoyaltyTransactionReverse LoyaltyTransactionType = "reverse"
+)
+
+type UpdateLoyaltyProgramRequest struct {
+	Enabled         *bool         `json:"enabled,omitempty"`
+	PointsPerUnit   *float64      `json:"points_per_unit,omitempty"`
+	PointValue      *float64      `json:"point_value,omitempty"`
+	MinPurchase     *Money        `json:"min_purchase,omitempty"`
+	MinRedeemPoints *float64      `json:"min_redeem_points,omitempty"`
+	Rules           []LoyaltyRule `json:"rules,omitempty"`
+}
+
+type AdjustLoyaltyPointsRequest struct {
+	Points float64 `json:"points" validate:"required"` // Positive to add, negative to deduct
+	Note   string  `json:"note" validate:"required"`
+}
+
+type LoyaltyBalance struct {
+	CustomerPhone string  `json:"customer_phone" pii:"phone"`
+	CustomerName  string  `json:"customer_name,omitempty" pii:"name"`
+	Points        float64 `json:"points"`
+	Value         Money   `json:"value"` // Points expressed in currency
+}
+
+type LoyaltyReport struct {
+	Period            string  `json:"period"`
+	PointsAccrued     float64 `json:"points_accrued"`
+	PointsRedeemed    float64 `json:"points_redeemed"`
+	PointsAdjusted    float64 `json:"points_adjusted"`
+	PointsReversed    float64 `json:"points_reversed"`
+	AccruedValue      Money   `json:"accrued_value"`
+	RedeemedValue     Money   `json:"redeemed_value"`
+	OutstandingPoints float64 `json:"outstanding_points"`
+	OutstandingValue  Money   `json:"outstanding_value"` // Current liability at today's point value
+	ActiveMembers     int     `json:"active_members"`
+	RedemptionRate    float64 `json:"redemption_rate"` // Redeemed as % of accrued in the period
+}
+
+type LoyaltyActivity struct {
+	Type   LoyaltyTransactionType `bson:"_id"`
+	Points float64                `bson:"points"`
+	Value  Money                  `bson:"value"`
+}
+
+type LoyaltyRepository interface {
+	GetProgram(businessID string) (*LoyaltyProgram, error)
+	SaveProgram(program *LoyaltyProgram) error
+	FindAccountByID(id string) (*LoyaltyAccount, error)
+	FindAccountByPhone(businessID, phone string) (*LoyaltyAccount, error)
+	FindAccounts(businessID string, limit, offset int) ([]LoyaltyAccount, error)
+	AddPoints(businessID, phone, name string, points float64, value Money, txType LoyaltyTransactionType, saleID *string, userID, note string) (*LoyaltyAccount, error)
+	DeductPoints(accountID string, points float64, value Money, txType LoyaltyTransactionType, saleID *string, userID, note string) (*LoyaltyAccount, error)
+	GetTransactions(accountID string, limit int) ([]LoyaltyTransaction, error)
+	GetSaleTransactions(saleID string) ([]LoyaltyTransaction, error)
+	GetActivity(businessID string, startDate, endDate time.Time) ([]LoyaltyActivity, error)
+	GetOutstanding(businessID string) (float64, int, error)
+}
@@ -0,0 +1,32 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaintenanceState switches the API into read-only mode, e.g. while a migration
+// runs. Reads, sync downloads and reports keep working; writes are refused with a
+// 503 telling clients when to retry.
+type MaintenanceState struct {
+	Enabled   bool                `bson:"enabled" json:"enabled"`
+	Message   string              `bson:"message,omitempty" json:"message,omitempty"`
+	StartedAt *time.Time          `bson:"started_at,omitempty" json:"started_at,omitempty"`
+	EndsAt    *time.Time          `bson:"ends_at,omitempty" json:"ends_at,omitempty"` // Expected end; clients retry writes after it
+	UpdatedBy *primitive.ObjectID `bson:"updated_by,omitempty" json:"updated_by,omitempty"`
+	UpdatedAt time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type SetMaintenanceRequest struct {
+	Enabled bool   `json:"enabled"`
+	Message string `json:"message,omitempty" validate:"max=500"`
+	// How long the maintenance is expected to last, for Retry-After; 0 leaves it open
+	ExpectedMinutes int `json:"expected_minutes,omitempty" validate:"gte=0,lte=1440"`
+}
+
+type MaintenanceRepository interface {
+	// Get returns the current state, disabled if it was never set
+	Get() (*MaintenanceState, error)
+	Save(state *MaintenanceState) error
+}
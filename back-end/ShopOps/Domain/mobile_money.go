@@ -0,0 +1,82 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MobileMoneyProvider is a mobile wallet a sale can be paid from
+type MobileMoneyProvider string
+
+const (
+	MobileMoneyTelebirr MobileMoneyProvider = "telebirr"
+	MobileMoneyCBEBirr  MobileMoneyProvider = "cbe_birr"
+	MobileMoneyMPesa    MobileMoneyProvider = "mpesa"
+)
+
+// MobilePayment is a request for a customer to pay a sale from their wallet. It is
+// pending until the provider reports the outcome, by callback or when asked.
+type MobilePayment struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"` // Also the merchant reference sent to the provider
+	BusinessID    primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	SaleID        primitive.ObjectID  `bson:"sale_id" json:"sale_id"`
+	Provider      MobileMoneyProvider `bson:"provider" json:"provider"`
+	Phone         string              `bson:"phone" json:"phone" pii:"phone"`
+	Amount        Money               `bson:"amount" json:"amount"`
+	Currency      string              `bson:"currency" json:"currency"`
+	Status        MobilePaymentStatus `bson:"status" json:"status"`
+	ProviderRef   string              `bson:"provider_ref,omitempty" json:"provider_ref,omitempty"`     // The provider's ID for the payment request
+	TransactionID string              `bson:"transaction_id,omitempty" json:"transaction_id,omitempty"` // The provider's receipt for money that moved
+	CheckoutURL   string              `bson:"checkout_url,omitempty" json:"checkout_url,omitempty"`     // For providers that confirm on a web page rather than a push
+	FailureReason string              `bson:"failure_reason,omitempty" json:"failure_reason,omitempty"` // Why it failed or needs review
+	ExpiresAt     time.Time           `bson:"expires_at" json:"expires_at"`                             // Still pending after this, it is given up on
+	CompletedAt   *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+	ReversedAt    *time.Time          `bson:"reversed_at,omitempty" json:"reversed_at,omitempty"`
+	CreatedBy     primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type MobilePaymentStatus string
+
+const (
+	MobilePaymentPending   MobilePaymentStatus = "pending"
+	MobilePaymentSucceeded MobilePaymentStatus = "succeeded"
+	MobilePaymentFailed    MobilePaymentStatus = "failed"
+	MobilePaymentExpired   MobilePaymentStatus = "expired"
+	MobilePaymentReversed  MobilePaymentStatus = "reversed"
+	// Money arrived that could not be applied to the sale nor sent back automatically,
+	// such as a second payment for a sale already paid; someone has to look at it
+	MobilePaymentNeedsReview MobilePaymentStatus = "needs_review"
+)
+
+// MobilePaymentTimeout is how long a customer has to approve a payment
+const MobilePaymentTimeout = 5 * time.Minute
+
+type InitiateMobilePaymentRequest struct {
+	SaleID   string              `json:"sale_id" validate:"required"`
+	Provider MobileMoneyProvider `json:"provider" validate:"required,oneof=telebirr cbe_birr mpesa"`
+	Phone    string              `json:"phone" validate:"required,phone"` // The wallet to charge
+}
+
+type ReverseMobilePaymentRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=200"`
+}
+
+type MobilePaymentRepository interface {
+	Create(payment *MobilePayment) error
+	// FindByID finds the payment whatever its shop, for the provider's callbacks, which name none
+	FindByID(id string) (*MobilePayment, error)
+	// FindInBusiness returns the payment when it was taken for the shop
+	FindInBusiness(ctx context.Context, id string) (*MobilePayment, error)
+	FindByProviderRef(provider MobileMoneyProvider, providerRef string) (*MobilePayment, error)
+	FindBySale(saleID string) ([]MobilePayment, error)
+	// FindStale returns pending payments not updated since before, oldest first
+	FindStale(before time.Time, limit int) ([]MobilePayment, error)
+	// Transition saves the payment only if its stored status is still from, so a
+	// callback and a status check racing each other apply the outcome once
+	Transition(payment *MobilePayment, from MobilePaymentStatus) (bool, error)
+	SetProviderRef(id primitive.ObjectID, providerRef, checkoutURL string) error
+}
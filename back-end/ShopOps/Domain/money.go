@@ -0,0 +1,184 @@
+package Domain
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/x/bsonx/bsoncore"
+)
+
+// Money is an amount in whole cents, so sums and differences are exact. It is
+// written to JSON as a number with two decimals, as amounts always were, and read
+// from a number or a decimal string; in MongoDB it is a Decimal128. Amounts with
+// more than two decimals, as older clients send from float arithmetic, are
+// rounded to the nearest cent, halves away from zero.
+type Money int64
+
+// NewMoney rounds a float amount to the nearest cent. The float is read as the
+// shortest decimal that converts back to it, so 1.005 is 1.01 rather than the
+// 1.00 its binary value would round to.
+func NewMoney(amount float64) Money {
+	if math.IsNaN(amount) || math.IsInf(amount, 0) {
+		return 0
+	}
+	m, _ := ParseMoney(strconv.FormatFloat(amount, 'f', -1, 64))
+	return m
+}
+
+// ParseMoney reads a decimal amount such as "12.5", "-3" or "1e3"; fractions such
+// as "1/3" are refused even though big.Rat would take them
+func ParseMoney(s string) (Money, error) {
+	s = strings.TrimSpace(s)
+	amount, ok := new(big.Rat).SetString(s)
+	if !ok || s == "" || strings.Contains(s, "/") {
+		return 0, fmt.Errorf("%q is not an amount", s)
+	}
+	m, ok := roundCents(amount.Mul(amount, big.NewRat(100, 1)))
+	if !ok {
+		return 0, fmt.Errorf("%q is too large an amount", s)
+	}
+	return m, nil
+}
+
+// roundCents rounds a number of cents to a whole one, halves away from zero
+func roundCents(cents *big.Rat) (Money, bool) {
+	q, r := new(big.Int).QuoRem(cents.Num(), cents.Denom(), new(big.Int))
+	// |2r| >= denominator moves one cent outwards
+	if new(big.Int).Abs(r.Mul(r, big.NewInt(2))).Cmp(cents.Denom()) >= 0 {
+		q.Add(q, big.NewInt(int64(cents.Num().Sign())))
+	}
+	if !q.IsInt64() {
+		return 0, false
+	}
+	return Money(q.Int64()), true
+}
+
+// MoneyFromValue reads an amount decoded from JSON or BSON into an interface{}:
+// a number of any kind, a decimal string or a Decimal128
+func MoneyFromValue(v interface{}) (Money, bool) {
+	switch value := v.(type) {
+	case Money:
+		return value, true
+	case float64:
+		return NewMoney(value), true
+	case float32:
+		return NewMoney(float64(value)), true
+	case int:
+		return Money(value) * 100, true
+	case int32:
+		return Money(value) * 100, true
+	case int64:
+		return Money(value) * 100, true
+	case json.Number:
+		m, err := ParseMoney(string(value))
+		return m, err == nil
+	case string:
+		m, err := ParseMoney(value)
+		return m, err == nil
+	case primitive.Decimal128:
+		m, err := ParseMoney(value.String())
+		return m, err == nil
+	}
+	return 0, false
+}
+
+// Float64 is the amount in whole units, for ratios and display
+func (m Money) Float64() float64 {
+	return float64(m) / 100
+}
+
+// Times is the amount for quantity units at m each, to the nearest cent, e.g.
+// 0.375 kg at 12.30 is 4.61
+func (m Money) Times(quantity float64) Money {
+	q, ok := new(big.Rat).SetString(strconv.FormatFloat(quantity, 'f', -1, 64))
+	if !ok {
+		return 0
+	}
+	product, _ := roundCents(q.Mul(q, new(big.Rat).SetInt64(int64(m))))
+	return product
+}
+
+// String is the amount with two decimals, e.g. 12.50 or -0.05
+func (m Money) String() string {
+	sign := ""
+	cents := int64(m)
+	if cents < 0 {
+		sign = "-"
+		cents = -cents
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, cents/100, cents%100)
+}
+
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+func (m *Money) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if bytes.Equal(data, []byte("null")) {
+		return nil
+	}
+
+	text := string(data)
+	if len(data) > 0 && data[0] == '"' {
+		unquoted, err := strconv.Unquote(text)
+		if err != nil {
+			return fmt.Errorf("invalid amount %s", text)
+		}
+		text = unquoted
+	}
+
+	parsed, err := ParseMoney(text)
+	if err != nil {
+		return err
+	}
+	*m = parsed
+	return nil
+}
+
+func (m Money) MarshalBSONValue() (bsontype.Type, []byte, error) {
+	d, err := primitive.ParseDecimal128(m.String())
+	if err != nil {
+		return 0, nil, err
+	}
+	high, low := d.GetBytes()
+	return bsontype.Decimal128, bsoncore.AppendDecimal128(nil, primitive.NewDecimal128(high, low)), nil
+}
+
+// UnmarshalBSONValue reads Decimal128 amounts and, from documents written before
+// amounts were decimals or by older sync clients, doubles and integers
+func (m *Money) UnmarshalBSONValue(t bsontype.Type, data []byte) error {
+	value := bsoncore.Value{Type: t, Data: data}
+	switch t {
+	case bsontype.Decimal128:
+		parsed, err := ParseMoney(value.Decimal128().String())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case bsontype.Double:
+		*m = NewMoney(value.Double())
+	case bsontype.Int32:
+		*m = Money(value.Int32()) * 100
+	case bsontype.Int64:
+		*m = Money(value.Int64()) * 100
+	case bsontype.String:
+		parsed, err := ParseMoney(value.StringValue())
+		if err != nil {
+			return err
+		}
+		*m = parsed
+	case bsontype.Null, bsontype.Undefined:
+		*m = 0
+	default:
+		return fmt.Errorf("cannot decode %s into an amount", t)
+	}
+	return nil
+}
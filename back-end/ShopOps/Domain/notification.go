@@ -0,0 +1,192 @@
+package Domain
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// NotificationEvent is a kind of thing users are told about
+type NotificationEvent string
+
+const (
+	NotificationBigSale      NotificationEvent = "big_sale"
+	NotificationDailySummary NotificationEvent = "daily_summary"
+	NotificationSyncFailure  NotificationEvent = "sync_failure"
+	NotificationBackupFailed NotificationEvent = "backup_failed"
+	NotificationLowStock     NotificationEvent = "low_stock"
+	NotificationAlert        NotificationEvent = "alert" // Anomalies found in the shop's trading
+	NotificationSecurity     NotificationEvent = "security"
+)
+
+// NotificationChannel is a way a notification reaches a user
+type NotificationChannel string
+
+const (
+	ChannelPush  NotificationChannel = "push"
+	ChannelSMS   NotificationChannel = "sms"
+	ChannelEmail NotificationChannel = "email"
+	ChannelInApp NotificationChannel = "in_app"
+)
+
+// NotificationEventInfo is an event with the channels it can go out on and the
+// ones it goes out on unless the user says otherwise
+type NotificationEventInfo struct {
+	Event    NotificationEvent     `json:"event"`
+	Channels []NotificationChannel `json:"channels"`
+	Defaults []NotificationChannel `json:"defaults"`
+	Required bool                  `json:"required"` // Always sent on every channel; cannot be turned off
+}
+
+// NotificationEvents lists every event users can be notified of
+var NotificationEvents = []NotificationEventInfo{
+	{Event: NotificationBigSale, Channels: []NotificationChannel{ChannelPush}, Defaults: []NotificationChannel{ChannelPush}},
+	{Event: NotificationDailySummary, Channels: []NotificationChannel{ChannelPush}, Defaults: []NotificationChannel{ChannelPush}},
+	{Event: NotificationSyncFailure, Channels: []NotificationChannel{ChannelPush, ChannelInApp}, Defaults: []NotificationChannel{ChannelPush, ChannelInApp}},
+	{Event: NotificationBackupFailed, Channels: []NotificationChannel{ChannelEmail, ChannelInApp}, Defaults: []NotificationChannel{ChannelEmail, ChannelInApp}},
+	{Event: NotificationLowStock, Channels: []NotificationChannel{ChannelEmail, ChannelInApp}, Defaults: []NotificationChannel{ChannelEmail, ChannelInApp}},
+	{Event: NotificationAlert, Channels: []NotificationChannel{ChannelSMS, ChannelInApp}, Defaults: []NotificationChannel{ChannelSMS, ChannelInApp}},
+	{Event: NotificationSecurity, Channels: []NotificationChannel{ChannelPush, ChannelInApp}, Defaults: []NotificationChannel{ChannelPush, ChannelInApp}, Required: true},
+}
+
+// FindNotificationEvent returns the event's info, or nil
+func FindNotificationEvent(event NotificationEvent) *NotificationEventInfo {
+	for i := range NotificationEvents {
+		if NotificationEvents[i].Event == event {
+			return &NotificationEvents[i]
+		}
+	}
+	return nil
+}
+
+func hasChannel(channels []NotificationChannel, channel NotificationChannel) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+// NotificationPreferences are the channels a user wants each event on, across all
+// their shops. Events the user never set go out on their defaults.
+type NotificationPreferences struct {
+	UserID    primitive.ObjectID                          `bson:"user_id" json:"user_id"`
+	Channels  map[NotificationEvent][]NotificationChannel `bson:"channels" json:"channels"`
+	UpdatedAt time.Time                                   `bson:"updated_at" json:"updated_at"`
+}
+
+// Allows reports whether the event goes to the user on the channel
+func (p *NotificationPreferences) Allows(event NotificationEvent, channel NotificationChannel) bool {
+	info := FindNotificationEvent(event)
+	if info == nil || !hasChannel(info.Channels, channel) {
+		return false
+	}
+	if info.Required {
+		return true
+	}
+	if p != nil {
+		if channels, ok := p.Channels[event]; ok {
+			return hasChannel(channels, channel)
+		}
+	}
+	return hasChannel(info.Defaults, channel)
+}
+
+// Resolved fills in the defaults, so every event is listed with its channels
+func (p *NotificationPreferences) Resolved() map[NotificationEvent][]NotificationChannel {
+	resolved := make(map[NotificationEvent][]NotificationChannel, len(NotificationEvents))
+	for _, info := range NotificationEvents {
+		channels := []NotificationChannel{}
+		for _, channel := range info.Channels {
+			if p.Allows(info.Event, channel) {
+				channels = append(channels, channel)
+			}
+		}
+		resolved[info.Event] = channels
+	}
+	return resolved
+}
+
+// UpdateNotificationPreferencesRequest sets the channels of the events given; an
+// empty list turns an event off
+type UpdateNotificationPreferencesRequest struct {
+	Channels map[NotificationEvent][]NotificationChannel `json:"channels" validate:"required"`
+}
+
+// Validate checks each event is known, can be changed, and uses only its channels
+func (r UpdateNotificationPreferencesRequest) Validate() error {
+	for event, channels := range r.Channels {
+		info := FindNotificationEvent(event)
+		if info == nil {
+			return ValidationError(fmt.Sprintf("unknown notification event %q", event))
+		}
+		if info.Required {
+			return ValidationError(fmt.Sprintf("%s notifications cannot be turned off", event))
+		}
+		for _, channel := range channels {
+			if !hasChannel(info.Channels, channel) {
+				return ValidationError(fmt.Sprintf("%s notifications are not sent by %s", event, channel))
+			}
+		}
+	}
+	return nil
+}
+
+// NotificationPreferencesView is what the user sees: every event, the channels it
+// can go out on and those it does
+type NotificationPreferencesView struct {
+	Events   []NotificationEventInfo                     `json:"events"`
+	Channels map[NotificationEvent][]NotificationChannel `json:"channels"`
+}
+
+// Notification is an entry in a user's in-app notification center. Entries are
+// kept for 90 days.
+type Notification struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	BusinessID *primitive.ObjectID `bson:"business_id,omitempty" json:"business_id,omitempty"` // Nil for account notifications
+	Event      NotificationEvent   `bson:"event" json:"event"`
+	Title      string              `bson:"title" json:"title"`
+	Body       string              `bson:"body" json:"body"`
+	Data       map[string]string   `bson:"data,omitempty" json:"data,omitempty"` // For the app to open the right screen
+	ReadAt     *time.Time          `bson:"read_at,omitempty" json:"read_at,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type NotificationFilters struct {
+	BusinessID *string
+	Event      *NotificationEvent
+	Unread     bool
+	Limit      int
+	Offset     int
+}
+
+// MarkNotificationsReadRequest marks the notifications listed read, or with all
+// set every unread one, of one shop when business_id is given
+type MarkNotificationsReadRequest struct {
+	IDs        []string `json:"ids,omitempty" validate:"max=500"`
+	All        bool     `json:"all,omitempty"`
+	BusinessID string   `json:"business_id,omitempty"`
+}
+
+// NotificationBadge is the user's unread count, in all and per shop
+type NotificationBadge struct {
+	Unread int            `json:"unread"`
+	Shops  map[string]int `json:"shops"` // By business ID; account notifications are only in the total
+}
+
+type NotificationRepository interface {
+	Create(notification *Notification) error
+	Find(userID string, filters NotificationFilters) ([]Notification, error)
+	// MarkRead marks the user's notifications read: those with the IDs, or when ids
+	// is nil all unread, of the shop when businessID is set. It returns how many.
+	MarkRead(userID string, ids []primitive.ObjectID, businessID string) (int64, error)
+	// CountUnread counts the user's unread notifications by shop; the empty key
+	// holds account notifications
+	CountUnread(userID string) (map[string]int, error)
+
+	FindPreferences(userID string) (*NotificationPreferences, error)
+	SavePreferences(preferences *NotificationPreferences) error
+}
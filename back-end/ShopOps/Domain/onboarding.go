@@ -0,0 +1,195 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Onboarding is a new owner's progress through setting up a shop: creating it,
+// choosing a template for its catalog, importing products and registering the
+// first till. It is saved after every step, so the wizard resumes where it was
+// left, on any device, until it is finished.
+type Onboarding struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID           primitive.ObjectID `bson:"user_id" json:"user_id"`
+	BusinessID       primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Template         string             `bson:"template,omitempty" json:"template,omitempty"`
+	Packs            []string           `bson:"packs,omitempty" json:"packs,omitempty"` // Vertical packs installed during onboarding
+	Done             []OnboardingStep   `bson:"done" json:"done"`                       // Steps completed or skipped, in the order they were
+	Skipped          []OnboardingStep   `bson:"skipped" json:"skipped"`                 // Steps passed over, which can still be done later
+	NextStep         OnboardingStep     `bson:"next_step" json:"next_step"`
+	ProductsImported int                `bson:"products_imported" json:"products_imported"`
+	DeviceID         string             `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+	CompletedAt      *time.Time         `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+type OnboardingStep string
+
+const (
+	OnboardingStepBusiness OnboardingStep = "business"
+	OnboardingStepTemplate OnboardingStep = "template"
+	OnboardingStepProducts OnboardingStep = "products"
+	OnboardingStepDevice   OnboardingStep = "device"
+	OnboardingStepFinished OnboardingStep = "finished" // Not a step; NextStep once every step is done
+)
+
+// OnboardingSteps are the steps in the order the wizard shows them
+var OnboardingSteps = []OnboardingStep{OnboardingStepBusiness, OnboardingStepTemplate, OnboardingStepProducts, OnboardingStepDevice}
+
+// Skippable reports whether the shop works without the step: it can start with an
+// empty catalog, and a till registers itself when first used
+func (s OnboardingStep) Skippable() bool {
+	return s == OnboardingStepTemplate || s == OnboardingStepProducts || s == OnboardingStepDevice
+}
+
+// IsDone reports whether the step was completed or skipped
+func (o *Onboarding) IsDone(step OnboardingStep) bool {
+	for _, done := range o.Done {
+		if done == step {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkDone records the step as completed, taking it off the skipped steps if it
+// was skipped before, and moves on to the first step not yet done
+func (o *Onboarding) MarkDone(step OnboardingStep, skipped bool) {
+	if !o.IsDone(step) {
+		o.Done = append(o.Done, step)
+	}
+	remaining := o.Skipped[:0]
+	for _, s := range o.Skipped {
+		if s != step {
+			remaining = append(remaining, s)
+		}
+	}
+	o.Skipped = remaining
+	if skipped {
+		o.Skipped = append(o.Skipped, step)
+	}
+
+	o.NextStep = OnboardingStepFinished
+	for _, s := range OnboardingSteps {
+		if !o.IsDone(s) {
+			o.NextStep = s
+			break
+		}
+	}
+}
+
+// ShopTemplate starts a kind of shop off with the categories, units and tax rates
+// it usually needs, and the vertical pack that goes with it, if any
+type ShopTemplate struct {
+	Code       string    `json:"code"`
+	Name       string    `json:"name"`
+	Categories []string  `json:"categories"`
+	Units      []string  `json:"units"`
+	TaxRates   []TaxRate `json:"tax_rates"`
+	Pack       string    `json:"pack,omitempty"`
+}
+
+// Ethiopian rates: VAT for registered shops, turnover tax for smaller ones, and
+// exempt goods such as medicines
+var (
+	taxVAT       = TaxRate{Code: "VAT", Name: "VAT 15%", Percent: 15}
+	taxTOT       = TaxRate{Code: "TOT", Name: "Turnover tax 2%", Percent: 2}
+	taxVATExempt = TaxRate{Code: "EXEMPT", Name: "VAT exempt", Percent: 0}
+)
+
+func defaultTax(rate TaxRate) TaxRate {
+	rate.Default = true
+	return rate
+}
+
+// ShopTemplates are the templates offered during onboarding
+var ShopTemplates = []ShopTemplate{
+	{
+		Code: "pharmacy",
+		Name: "Pharmacy",
+		Categories: []string{"Prescription Medicines", "Over-the-Counter Medicines", "Vitamins & Supplements",
+			"Baby Care", "Personal Care", "Medical Supplies", "First Aid"},
+		Units: []string{"tablet", "strip", "box", "bottle", "tube", "vial", "sachet", "pcs"},
+		// Medicines are exempt from VAT; cosmetics and supplies are not
+		TaxRates: []TaxRate{defaultTax(taxVATExempt), taxVAT},
+		Pack:     "pharmacy",
+	},
+	{
+		Code: "boutique",
+		Name: "Boutique",
+		Categories: []string{"Women's Clothing", "Men's Clothing", "Children's Clothing", "Traditional Wear",
+			"Shoes", "Bags", "Accessories"},
+		Units:    []string{"pcs", "pair", "set", "m"},
+		TaxRates: []TaxRate{defaultTax(taxVAT), taxTOT},
+	},
+	{
+		Code: "minimart",
+		Name: "Mini-market",
+		Categories: []string{"Beverages", "Grains & Flour", "Dairy & Eggs", "Bakery", "Snacks", "Cooking Oil",
+			"Spices", "Household", "Personal Care"},
+		Units:    []string{"pcs", "kg", "g", "l", "pack", "crate", "dozen"},
+		TaxRates: []TaxRate{defaultTax(taxVAT), taxTOT},
+	},
+}
+
+// FindShopTemplate returns the template with the code, or nil
+func FindShopTemplate(code string) *ShopTemplate {
+	for i := range ShopTemplates {
+		if ShopTemplates[i].Code == code {
+			return &ShopTemplates[i]
+		}
+	}
+	return nil
+}
+
+// ChooseTemplateRequest applies a template to the shop's catalog settings and
+// installs the template's pack along with any others listed
+type ChooseTemplateRequest struct {
+	Template string   `json:"template" validate:"required"`
+	Packs    []string `json:"packs,omitempty" validate:"max=5"`
+}
+
+// ImportProductsRequest adds the shop's first products; the step can be repeated
+// to add more before the wizard moves on
+type ImportProductsRequest struct {
+	Products []CreateProductRequest `json:"products" validate:"required,min=1,max=500,dive"`
+}
+
+type ImportProductsResult struct {
+	Imported   int                  `json:"imported"`
+	Failed     []ImportProductError `json:"failed"`
+	Onboarding Onboarding           `json:"onboarding"`
+}
+
+type ImportProductError struct {
+	Row   int    `json:"row"` // Zero-based index in the request
+	Name  string `json:"name"`
+	Error string `json:"error"`
+}
+
+// RegisterDeviceRequest registers the shop's first till, which takes one of the
+// plan's device slots
+type RegisterDeviceRequest struct {
+	DeviceID string `json:"device_id" validate:"required,max=100"`
+}
+
+type SkipOnboardingStepRequest struct {
+	Step OnboardingStep `json:"step" validate:"required,oneof=template products device"`
+}
+
+// OnboardingState is the wizard's progress with the shop it is setting up
+type OnboardingState struct {
+	Onboarding Onboarding `json:"onboarding"`
+	Business   *Business  `json:"business"`
+}
+
+type OnboardingRepository interface {
+	Create(onboarding *Onboarding) error
+	Update(onboarding *Onboarding) error
+	FindByBusiness(businessID string) (*Onboarding, error)
+	// FindUnfinished returns the user's latest onboarding not yet finished
+	FindUnfinished(userID string) (*Onboarding, error)
+}
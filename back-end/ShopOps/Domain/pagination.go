@@ -0,0 +1,82 @@
+package Domain
+
+import (
+	"encoding/base64"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// List endpoints page with an opaque cursor rather than an offset: skipping tens of
+// thousands of sales to reach a page gets slower the further in it is, and rows
+// shift between pages while the shop keeps selling. A cursor holds the sort value
+// and ID of the last item returned, and the next page starts after it.
+
+// ListSort is one order a list can be paged in. Ties are broken by _id, in the same
+// direction, so every item has a single place in the order.
+type ListSort struct {
+	Field string // Document field, e.g. created_at
+	Desc  bool
+}
+
+// ListSorts are the orders a list endpoint offers, keyed by the sort parameter, such
+// as "name" or "-created_at"
+type ListSorts map[string]ListSort
+
+// Page is how a list was asked to be paged
+type Page struct {
+	Key          string // Sort key the client asked for
+	Sort         ListSort
+	After        *PageCursor // Nil for the first page
+	Limit        int
+	IncludeTotal bool
+}
+
+// PageCursor is the last item of the previous page
+type PageCursor struct {
+	Value interface{}
+	ID    primitive.ObjectID
+}
+
+// Sortable items report their value for a sort field, so the next cursor can be
+// made from the last item of a page
+type Sortable interface {
+	SortValue(field string) interface{}
+	SortID() primitive.ObjectID
+}
+
+type encodedCursor struct {
+	Sort  string             `bson:"s"`
+	Value interface{}        `bson:"v"`
+	ID    primitive.ObjectID `bson:"id"`
+}
+
+// EncodeCursor makes the cursor for the page after item, in the sort with the given key
+func EncodeCursor(key string, sort ListSort, item Sortable) string {
+	// Extended JSON keeps the value's BSON type, so a date still compares as a date
+	data, err := bson.MarshalExtJSON(encodedCursor{Sort: key, Value: item.SortValue(sort.Field), ID: item.SortID()}, true, false)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// DecodeCursor reads a cursor made by EncodeCursor. A cursor made for a different
+// sort cannot be continued in this one.
+func DecodeCursor(cursor, key string) (*PageCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, NewAppError(ErrCodeInvalidArgument, "invalid cursor")
+	}
+
+	var decoded encodedCursor
+	if err := bson.UnmarshalExtJSON(data, true, &decoded); err != nil {
+		return nil, NewAppError(ErrCodeInvalidArgument, "invalid cursor")
+	}
+	if decoded.Sort != key {
+		return nil, NewAppError(ErrCodeInvalidArgument, fmt.Sprintf("cursor was made for sort %q, not %q", decoded.Sort, key))
+	}
+
+	return &PageCursor{Value: decoded.Value, ID: decoded.ID}, nil
+}
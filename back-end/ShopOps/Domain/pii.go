@@ -0,0 +1,153 @@
+package Domain
+
+import (
+	"reflect"
+	"strings"
+	"unicode/utf8"
+)
+
+// Customers' names and phone numbers are tagged on the models with pii:"name",
+// pii:"phone" or pii:"email". Logs and error reports always get them masked;
+// exports and the BI feed get them masked unless the caller was given
+// PermissionViewPII, or is the shop's owner.
+
+// PIIKind is what a tagged field holds, and so how it is masked
+type PIIKind string
+
+const (
+	PIIName  PIIKind = "name"
+	PIIPhone PIIKind = "phone"
+	PIIEmail PIIKind = "email"
+)
+
+// piiLogKeys are the log attribute keys that carry PII by name, for values logged
+// on their own rather than inside a tagged model
+var piiLogKeys = map[string]PIIKind{
+	"phone":          PIIPhone,
+	"customer_phone": PIIPhone,
+	"customer_name":  PIIName,
+	"email":          PIIEmail,
+}
+
+// PIIKindOfKey reports whether a log attribute key holds PII
+func PIIKindOfKey(key string) (PIIKind, bool) {
+	kind, ok := piiLogKeys[key]
+	return kind, ok
+}
+
+// MaskPII masks s as kind: a name keeps its first letter, a phone number its last
+// two digits and an email address its first letter and domain, so a row can still
+// be told apart from its neighbours
+func MaskPII(kind PIIKind, s string) string {
+	if s == "" {
+		return s
+	}
+	switch kind {
+	case PIIPhone:
+		if len(s) <= 2 {
+			return "***"
+		}
+		return "***" + s[len(s)-2:]
+	case PIIEmail:
+		local, domain, ok := strings.Cut(s, "@")
+		if !ok || local == "" {
+			return "***"
+		}
+		first, _ := utf8.DecodeRuneInString(local)
+		return string(first) + "***@" + domain
+	default:
+		first, _ := utf8.DecodeRuneInString(s)
+		return string(first) + "***"
+	}
+}
+
+// Redacted returns a copy of v with every tagged field masked, at any depth:
+// structs, pointers, slices and maps of them. v itself is not changed, and the
+// copy has v's type, so a []Sale stays a []Sale.
+func Redacted(v interface{}) interface{} {
+	if v == nil {
+		return nil
+	}
+	value := reflect.ValueOf(v)
+	if !hasPII(value.Type(), map[reflect.Type]bool{}) {
+		return v
+	}
+	return redactValue(value).Interface()
+}
+
+// HasPII reports whether v's type has tagged fields anywhere in it
+func HasPII(v interface{}) bool {
+	return v != nil && hasPII(reflect.TypeOf(v), map[reflect.Type]bool{})
+}
+
+func hasPII(t reflect.Type, seen map[reflect.Type]bool) bool {
+	if seen[t] {
+		return false
+	}
+	seen[t] = true
+
+	switch t.Kind() {
+	case reflect.Pointer, reflect.Slice, reflect.Array:
+		return hasPII(t.Elem(), seen)
+	case reflect.Map:
+		return hasPII(t.Elem(), seen)
+	case reflect.Struct:
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if field.Tag.Get("pii") != "" || hasPII(field.Type, seen) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+func redactValue(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.New(v.Type().Elem())
+		copied.Elem().Set(redactValue(v.Elem()))
+		return copied
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			copied.Index(i).Set(redactValue(v.Index(i)))
+		}
+		return copied
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		copied := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			copied.SetMapIndex(iter.Key(), redactValue(iter.Value()))
+		}
+		return copied
+	case reflect.Struct:
+		copied := reflect.New(v.Type()).Elem()
+		copied.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			field := v.Type().Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			if kind := field.Tag.Get("pii"); kind != "" && field.Type.Kind() == reflect.String {
+				copied.Field(i).SetString(MaskPII(PIIKind(kind), v.Field(i).String()))
+				continue
+			}
+			copied.Field(i).Set(redactValue(v.Field(i)))
+		}
+		return copied
+	}
+	return v
+}
@@ -0,0 +1,54 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CustomerPrice is a unit price negotiated with one customer for one product
+type CustomerPrice struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	CustomerID primitive.ObjectID `bson:"customer_id" json:"customer_id"`
+	ProductID  primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Price      Money              `bson:"price" json:"price"`
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type SetCustomerPriceRequest struct {
+	ProductID string `json:"product_id" validate:"required"`
+	Price     Money  `json:"price" validate:"required,gt=0,money"`
+	Notes     string `json:"notes,omitempty"`
+}
+
+// PriceSource tells the POS which rule produced a quoted price
+type PriceSource string
+
+const (
+	PriceSourceCustomer PriceSource = "customer" // Negotiated customer price
+	PriceSourceTier     PriceSource = "tier"     // Product price for the customer's tier
+	PriceSourceRetail   PriceSource = "retail"   // Product selling price
+)
+
+type PriceQuote struct {
+	ProductID    string       `json:"product_id"`
+	ProductName  string       `json:"product_name"`
+	CustomerID   string       `json:"customer_id,omitempty"`
+	CustomerTier CustomerTier `json:"customer_tier"`
+	Quantity     float64      `json:"quantity"`
+	RetailPrice  Money        `json:"retail_price"`
+	UnitPrice    Money        `json:"unit_price"`
+	Total        Money        `json:"total"`
+	Source       PriceSource  `json:"source"`
+}
+
+type CustomerPriceRepository interface {
+	Upsert(price *CustomerPrice) error
+	Find(customerID, productID string) (*CustomerPrice, error)
+	FindByCustomerID(customerID string) ([]CustomerPrice, error)
+	Delete(customerID, productID string) error
+}
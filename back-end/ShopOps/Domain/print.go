@@ -0,0 +1,231 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrintBridge is the small agent a shop runs on a PC or box next to its network or
+// USB receipt printers. It collects the shop's print jobs from the server and
+// sends them to the printers, so phones and browsers can print without drivers.
+type PrintBridge struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Name       string             `bson:"name" json:"name"`
+	TokenHash  string             `bson:"token_hash" json:"-"`                          // SHA-256 of the bridge's token; the token is shown once
+	Printers   []string           `bson:"printers,omitempty" json:"printers,omitempty"` // Names the bridge reported, for jobs to target
+	Version    string             `bson:"version,omitempty" json:"version,omitempty"`
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastSeenAt *time.Time         `bson:"last_seen_at,omitempty" json:"last_seen_at,omitempty"`
+	Online     bool               `bson:"-" json:"online"` // Polled within PrintBridgeOnlineWindow
+}
+
+type PrintJobType string
+
+const (
+	PrintJobReceipt PrintJobType = "receipt"  // A sale's receipt
+	PrintJobBarcode PrintJobType = "barcode"  // Shelf or product labels with the product's barcode
+	PrintJobZReport PrintJobType = "z_report" // End-of-day takings by payment method
+)
+
+func (t PrintJobType) IsValid() bool {
+	switch t {
+	case PrintJobReceipt, PrintJobBarcode, PrintJobZReport:
+		return true
+	}
+	return false
+}
+
+type PrintJobStatus string
+
+const (
+	PrintJobQueued  PrintJobStatus = "queued"
+	PrintJobClaimed PrintJobStatus = "claimed" // Handed to a bridge, waiting for its ack
+	PrintJobPrinted PrintJobStatus = "printed"
+	PrintJobFailed  PrintJobStatus = "failed" // The bridge gave up, or no bridge printed it in time
+)
+
+const (
+	// A claimed job not acknowledged within the lease goes back to the queue, in
+	// case the bridge died holding it
+	PrintJobLease = 2 * time.Minute
+	// Jobs are offered this many times before they fail
+	PrintJobMaxAttempts = 5
+	// A receipt printed hours late is no use at the till; queued jobs fail after this
+	PrintJobTTL = 6 * time.Hour
+	// Bridges long-poll; one seen within this window counts as online
+	PrintBridgeOnlineWindow = 2 * time.Minute
+)
+
+// PrintJob is one document for a shop's printers, rendered to ESC/POS when it is
+// queued so the bridge only copies bytes to the printer
+type PrintJob struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Type       PrintJobType        `bson:"type" json:"type"`
+	BridgeID   *primitive.ObjectID `bson:"bridge_id,omitempty" json:"bridge_id,omitempty"` // Only this bridge may print it; any of the shop's when empty
+	Printer    string              `bson:"printer,omitempty" json:"printer,omitempty"`     // Printer name on the bridge; its default when empty
+	Payload    []byte              `bson:"payload" json:"payload,omitempty"`               // ESC/POS bytes, base64 in JSON; sent to bridges only
+	SaleID     *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	ProductID  *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	ReportDate string              `bson:"report_date,omitempty" json:"report_date,omitempty"` // Z-reports: business day, YYYY-MM-DD
+	Status     PrintJobStatus      `bson:"status" json:"status"`
+	Attempts   int                 `bson:"attempts" json:"attempts"`
+	ClaimedBy  *primitive.ObjectID `bson:"claimed_by,omitempty" json:"claimed_by,omitempty"`
+	LeaseUntil *time.Time          `bson:"lease_until,omitempty" json:"-"`
+	Error      string              `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedBy  primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	ExpiresAt  time.Time           `bson:"expires_at" json:"expires_at"`
+	PrintedAt  *time.Time          `bson:"printed_at,omitempty" json:"printed_at,omitempty"`
+}
+
+// ZReportData is one business day's takings, as printed at closing
+type ZReportData struct {
+	Template *ReceiptTemplate
+	Business *Business
+	Date     string
+	Payments []SalesBreakdownLine // By payment method
+	Devices  []SalesBreakdownLine // By till
+	// Foreign cash in the till, already counted in Payments at its base currency value
+	Currencies   []ForeignCurrencyTakings
+	CashRounding Money // Cash taken beyond the sales total, negative when less
+	Printed      time.Time
+	Language     Language // Labels; English when empty
+}
+
+// BarcodeLabelData is the labels for one product
+type BarcodeLabelData struct {
+	Template *ReceiptTemplate
+	Business *Business
+	Product  *Product
+	Copies   int
+}
+
+type ShelfLabelFormat string
+
+const (
+	ShelfLabelPDF    ShelfLabelFormat = "pdf"    // For label sheets on an office printer, or a label printer with a driver
+	ShelfLabelESCPOS ShelfLabelFormat = "escpos" // For a receipt or label printer, cut between labels
+)
+
+// LabelSheet is the paper PDF labels are laid out on
+type LabelSheet string
+
+const (
+	LabelSheetRoll   LabelSheet = ""       // One label per page, the size of the label
+	LabelSheetA4     LabelSheet = "a4"     // Tiled on A4 sheets
+	LabelSheetLetter LabelSheet = "letter" // Tiled on US Letter sheets
+)
+
+const (
+	DefaultShelfLabelWidthMM  = 50.0
+	DefaultShelfLabelHeightMM = 30.0
+	// Labels in one batch, copies included
+	MaxShelfLabels = 2000
+)
+
+// ShelfLabelRequest picks products to print shelf labels for: the ones listed,
+// and every active product in category or whose price changed since a time
+type ShelfLabelRequest struct {
+	ProductIDs        []string         `json:"product_ids,omitempty" validate:"max=500"`
+	Category          string           `json:"category,omitempty" validate:"max=100"`
+	PriceChangedSince *time.Time       `json:"price_changed_since,omitempty"`
+	Copies            int              `json:"copies,omitempty" validate:"omitempty,min=1,max=100"`    // Of each product's label
+	Format            ShelfLabelFormat `json:"format,omitempty" validate:"omitempty,oneof=pdf escpos"` // PDF when empty
+	WidthMM           float64          `json:"width_mm,omitempty" validate:"omitempty,min=20,max=120"`
+	HeightMM          float64          `json:"height_mm,omitempty" validate:"omitempty,min=12,max=100"`
+	Sheet             LabelSheet       `json:"sheet,omitempty" validate:"omitempty,oneof=a4 letter"` // PDF only
+	Border            bool             `json:"border,omitempty"`                                     // PDF only: outline each label to cut along
+
+	// Queue the labels in ESC/POS for the print bridges instead of downloading them
+	Print    bool   `json:"print,omitempty"`
+	BridgeID string `json:"bridge_id,omitempty"`
+	Printer  string `json:"printer,omitempty" validate:"max=100"`
+}
+
+// ShelfLabel is what one label shows
+type ShelfLabel struct {
+	Name  string
+	Price string // With the currency and unit, e.g. "ETB 120.00 / kg"
+	Code  string // Barcode, or SKU when there is none; no bars when empty
+}
+
+// ShelfLabelData is a batch of labels of one size
+type ShelfLabelData struct {
+	Labels   []ShelfLabel
+	WidthMM  float64
+	HeightMM float64
+	Sheet    LabelSheet
+	Border   bool
+}
+
+type RegisterPrintBridgeRequest struct {
+	Name string `json:"name" validate:"required,max=100"`
+}
+
+// PrintBridgeTokenResponse carries a bridge's token, the only time it is shown
+type PrintBridgeTokenResponse struct {
+	PrintBridge
+	Token string `json:"token"`
+}
+
+type CreatePrintJobRequest struct {
+	Type       PrintJobType `json:"type" validate:"required,oneof=receipt barcode z_report"`
+	BridgeID   string       `json:"bridge_id,omitempty"`
+	Printer    string       `json:"printer,omitempty" validate:"max=100"`
+	SaleID     string       `json:"sale_id,omitempty"`     // Receipts
+	TemplateID string       `json:"template_id,omitempty"` // Receipt template; the business default when empty
+	ProductID  string       `json:"product_id,omitempty"`  // Barcode labels
+	Copies     int          `json:"copies,omitempty" validate:"omitempty,min=1,max=100"`
+	Date       string       `json:"date,omitempty"` // Z-reports: YYYY-MM-DD, today when empty
+}
+
+// PrintBridgeClaimRequest is a bridge asking for jobs, waiting up to Wait seconds
+// for one to be queued. It reports the printers it can reach on every call.
+type PrintBridgeClaimRequest struct {
+	Wait     int      `json:"wait,omitempty" validate:"omitempty,min=0,max=60"`
+	Max      int      `json:"max,omitempty" validate:"omitempty,min=1,max=20"`
+	Printers []string `json:"printers,omitempty" validate:"max=20,dive,max=100"`
+	Version  string   `json:"version,omitempty" validate:"max=40"`
+}
+
+// PrintJobAckRequest is a bridge reporting what became of a job. A failed job with
+// Retry set goes back to the queue while it has attempts left, e.g. when the
+// printer was out of paper.
+type PrintJobAckRequest struct {
+	Status PrintJobStatus `json:"status" validate:"required,oneof=printed failed"`
+	Error  string         `json:"error,omitempty" validate:"max=500"`
+	Retry  bool           `json:"retry,omitempty"`
+}
+
+type PrintJobFilters struct {
+	Type     *PrintJobType
+	Status   *PrintJobStatus
+	BridgeID *string
+	Limit    int
+	Offset   int
+}
+
+type PrintRepository interface {
+	CreateBridge(bridge *PrintBridge) error
+	FindBridgeByID(id string) (*PrintBridge, error)
+	FindBridgeByTokenHash(hash string) (*PrintBridge, error)
+	FindBridgesByBusiness(businessID string) ([]PrintBridge, error)
+	SetBridgeToken(id primitive.ObjectID, hash string) error
+	// DeleteBridge removes the bridge; jobs queued for it alone fail
+	DeleteBridge(id string) error
+
+	CreateJob(job *PrintJob) error
+	FindJobByID(id string) (*PrintJob, error)
+	FindJobs(businessID string, filters PrintJobFilters) ([]PrintJob, error)
+	// ClaimJob hands the oldest job the bridge may print to it, including jobs
+	// whose lease ran out, or returns nil when there is none
+	ClaimJob(bridge *PrintBridge, now time.Time) (*PrintJob, error)
+	// AckJob settles a job the bridge holds; false when it no longer holds it
+	AckJob(id string, bridgeID primitive.ObjectID, status PrintJobStatus, errMsg string, at time.Time) (bool, error)
+	// FailExpired fails jobs past their expiry or out of attempts
+	FailExpired(now time.Time) (int64, error)
+}
@@ -1,6 +1,7 @@
 package Domain
 
 import (
+	"context"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -15,9 +16,10 @@ type Product struct {
 	Barcode      string             `bson:"barcode,omitempty" json:"barcode,omitempty"`
 	Category     string             `bson:"category,omitempty" json:"category,omitempty"`
 	Unit         string             `bson:"unit,omitempty" json:"unit,omitempty"`
-	CostPrice    float64            `bson:"cost_price" json:"cost_price" validate:"required,gt=0"`
-	SellingPrice float64            `bson:"selling_price" json:"selling_price" validate:"required,gt=0"`
+	CostPrice    Money              `bson:"cost_price" json:"cost_price" validate:"required,gt=0"`
+	SellingPrice Money              `bson:"selling_price" json:"selling_price" validate:"required,gt=0"`
 	Stock        float64            `bson:"stock" json:"stock" validate:"gte=0"`
+	Reserved     float64            `bson:"-" json:"reserved,omitempty"` // Held for orders; Stock less this is what the POS may sell
 	MinStock     float64            `bson:"min_stock,omitempty" json:"min_stock,omitempty"`
 	MaxStock     float64            `bson:"max_stock,omitempty" json:"max_stock,omitempty"`
 	ImageURL     string             `bson:"image_url,omitempty" json:"image_url,omitempty"`
@@ -25,6 +27,32 @@ type Product struct {
 	CreatedBy    primitive.ObjectID `bson:"created_by" json:"created_by"`
 	CreatedAt    time.Time          `bson:"created_at" json:"created_at"`
 	UpdatedAt    time.Time          `bson:"updated_at" json:"updated_at"`
+	DeletedAt    *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"` // Set while in the trash
+	Version      int64              `bson:"version" json:"version"`                           // Incremented by every update
+
+	// Unit prices for wholesale and VIP customers; retail always pays SellingPrice
+	TierPrices map[CustomerTier]Money `bson:"tier_prices,omitempty" json:"tier_prices,omitempty"`
+
+	// PLU is the item number the shop's scales print in their barcodes. Weighed
+	// products are sold by the kg at SellingPrice.
+	PLU     string `bson:"plu,omitempty" json:"plu,omitempty"`
+	Weighed bool   `bson:"weighed,omitempty" json:"weighed,omitempty"`
+
+	// A service, such as a repair or an hour of labor, is sold without stock.
+	// Appointments for it take DurationMinutes, or the shop's booking slot.
+	Service         bool `bson:"service,omitempty" json:"service,omitempty"`
+	DurationMinutes int  `bson:"duration_minutes,omitempty" json:"duration_minutes,omitempty"`
+
+	// When SellingPrice last changed, so the shelf labels printed before can be redone
+	PriceChangedAt *time.Time `bson:"price_changed_at,omitempty" json:"price_changed_at,omitempty"`
+
+	// Set when the stock belongs to a consignor rather than the shop
+	Consignment *ProductConsignment `bson:"consignment,omitempty" json:"consignment,omitempty"`
+
+	// Returnable containers each unit goes out in, charged as deposits on sales
+	Deposits []ProductDeposit `bson:"deposits,omitempty" json:"deposits,omitempty"`
+
+	CustomFields CustomFields `bson:"custom_fields,omitempty" json:"custom_fields,omitempty"`
 }
 
 type ProductStatus string
@@ -35,14 +63,19 @@ const (
 	ProductStatusDiscontinued ProductStatus = "discontinued"
 )
 
+// StockMovement is one entry of the stock ledger, written with every change to a
+// product's stock. Entries are never edited or removed; a mistake is put right by
+// a further movement.
 type StockMovement struct {
 	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
 	BusinessID    primitive.ObjectID  `bson:"business_id" json:"business_id"`
 	ProductID     primitive.ObjectID  `bson:"product_id" json:"product_id"`
 	Type          MovementType        `bson:"type" json:"type"`
 	Quantity      float64             `bson:"quantity" json:"quantity"`
+	Change        float64             `bson:"change" json:"change"` // Signed: New less Previous
 	Previous      float64             `bson:"previous" json:"previous"`
-	New           float64             `bson:"new" json:"new"`
+	New           float64             `bson:"new" json:"new"`                                 // The balance after the movement
+	UnitCost      Money               `bson:"unit_cost,omitempty" json:"unit_cost,omitempty"` // The product's cost price when it moved
 	Reason        string              `bson:"reason" json:"reason"`
 	ReferenceID   *primitive.ObjectID `bson:"reference_id,omitempty" json:"reference_id,omitempty"`
 	ReferenceType string              `bson:"reference_type,omitempty" json:"reference_type,omitempty"`
@@ -59,6 +92,24 @@ const (
 	MovementTypeDamage   MovementType = "damage"
 	MovementTypeTheft    MovementType = "theft"
 	MovementTypeReturn   MovementType = "return"
+
+	// Consigned goods a consignor brings in or takes back
+	MovementTypeConsignmentIn     MovementType = "consignment_in"
+	MovementTypeConsignmentReturn MovementType = "consignment_return"
+
+	// Stock set aside for a customer's backorder
+	MovementTypeBackorder MovementType = "backorder"
+
+	// Parts fitted on a repair job
+	MovementTypeRepair MovementType = "repair"
+
+	// Components taken by a production order, and the units it made
+	MovementTypeProductionUse MovementType = "production_use"
+	MovementTypeProduction    MovementType = "production"
+
+	// Stock shipped to another branch, and stock received from one
+	MovementTypeTransferOut MovementType = "transfer_out"
+	MovementTypeTransferIn  MovementType = "transfer_in"
 )
 
 type CreateProductRequest struct {
@@ -68,11 +119,26 @@ type CreateProductRequest struct {
 	Barcode      string  `json:"barcode,omitempty"`
 	Category     string  `json:"category,omitempty"`
 	Unit         string  `json:"unit,omitempty"`
-	CostPrice    float64 `json:"cost_price" validate:"required,gt=0"`
-	SellingPrice float64 `json:"selling_price" validate:"required,gt=0"`
+	CostPrice    Money   `json:"cost_price" validate:"required,gt=0,money"`
+	SellingPrice Money   `json:"selling_price" validate:"required,gt=0,money"`
 	Stock        float64 `json:"stock" validate:"gte=0"`
 	MinStock     float64 `json:"min_stock,omitempty"`
 	MaxStock     float64 `json:"max_stock,omitempty"`
+
+	TierPrices map[CustomerTier]Money `json:"tier_prices,omitempty"`
+
+	PLU     string `json:"plu,omitempty" validate:"omitempty,numeric,max=6"`
+	Weighed *bool  `json:"weighed,omitempty"`
+
+	// Services keep no stock, so Stock, MinStock and MaxStock are ignored for them
+	Service         *bool `json:"service,omitempty"`
+	DurationMinutes *int  `json:"duration_minutes,omitempty" validate:"omitempty,min=0,max=1440"`
+
+	// On update, only the fields given change and a null clears one
+	CustomFields CustomFields `json:"custom_fields,omitempty"`
+
+	// On update, the version the client last read; a newer one on the server is a 409
+	Version *int64 `json:"version,omitempty"`
 }
 
 type AdjustStockRequest struct {
@@ -83,12 +149,32 @@ type AdjustStockRequest struct {
 
 type ProductRepository interface {
 	Create(product *Product) error
-	FindByID(id string) (*Product, error)
+	// FindInBusiness returns the product when it is the shop's, in the trash or not
+	FindInBusiness(ctx context.Context, id string) (*Product, error)
+	// FindByIDs returns the shop's products among ids, in the trash or not, in no
+	// particular order; unknown IDs are left out
+	FindByIDs(businessID string, ids []string) ([]Product, error)
 	FindByBusinessID(businessID string, filters ProductFilters) ([]Product, error)
+	Count(businessID string, filters ProductFilters) (int64, error)
+	// Update saves the product if it is still at product.Version, and increments it
 	Update(product *Product) error
+	// Delete moves the product to the trash
 	Delete(id string) error
+	FindDeleted(businessID string) ([]Product, error)
+	Restore(id string) error
+	PurgeDeleted(before time.Time, limit int) (map[string]int64, error)
+	// SetConsignment puts the product on the consignment terms, or takes it off
+	// consignment when nil
+	SetConsignment(id string, consignment *ProductConsignment) error
+	// SetDeposits replaces the containers the product goes out in
+	SetDeposits(id string, deposits []ProductDeposit) error
 	AdjustStock(productID string, quantity float64, movementType MovementType, reason string, referenceID *string, referenceType string, userID string) error
+	// AddStockAtCost adds stock bought or made at unitCost, moving the product's
+	// cost price to the average of the stock it then holds
+	AddStockAtCost(productID string, quantity float64, unitCost Money, movementType MovementType, reason string, referenceID *string, referenceType string, userID string) error
 	GetLowStock(businessID string, threshold float64) ([]Product, error)
+	// FindCategories lists the categories the shop's products are in, by name
+	FindCategories(businessID string) ([]string, error)
 	GetStockHistory(productID string, limit int) ([]StockMovement, error)
 }
 
@@ -97,6 +183,41 @@ type ProductFilters struct {
 	Status   *ProductStatus
 	LowStock *bool
 	Search   *string
-	Limit    int
-	Offset   int
+	Barcode  *string // Exact match, as scanned
+	SKU      *string // Exact match
+	PLU      *string
+	HasPLU   *bool
+	// Only products whose price changed at or after this time
+	PriceChangedSince *time.Time
+	Limit             int
+	Offset            int // Deprecated: page with After instead
+
+	Sort  ListSort // By name when empty
+	After *PageCursor
+}
+
+// ProductSorts are the orders the product list can be paged in
+var ProductSorts = ListSorts{
+	"name":        {Field: "name"},
+	"-name":       {Field: "name", Desc: true},
+	"-created_at": {Field: "created_at", Desc: true},
+	"-updated_at": {Field: "updated_at", Desc: true},
+	"stock":       {Field: "stock"},
+}
+
+func (p *Product) SortValue(field string) interface{} {
+	switch field {
+	case "created_at":
+		return p.CreatedAt
+	case "updated_at":
+		return p.UpdatedAt
+	case "stock":
+		return p.Stock
+	default:
+		return p.Name
+	}
+}
+
+func (p *Product) SortID() primitive.ObjectID {
+	return p.ID
 }
@@ -0,0 +1,125 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Recipe is the bill of materials of a product the shop makes itself, such as a
+// bakery's bread: the components one batch takes and the units it yields. A
+// product has at most one recipe.
+type Recipe struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	ProductID  primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Name       string             `bson:"name" json:"name"`   // The product's, when the recipe was saved
+	Yield      float64            `bson:"yield" json:"yield"` // Units of the product one batch makes
+	Components []RecipeComponent  `bson:"components" json:"components"`
+	Notes      string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// RecipeComponent is a product one batch takes, e.g. 25 kg of flour
+type RecipeComponent struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Name      string             `bson:"name" json:"name"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+}
+
+// ProductionOrder records units of a product made from its recipe. Making them
+// takes the components out of stock and adds the units at what the components
+// cost, so the product's cost price becomes the average of the stock it holds.
+type ProductionOrder struct {
+	ID         primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID    `bson:"business_id" json:"business_id"`
+	Number     string                `bson:"number" json:"number"` // PR-00001
+	ProductID  primitive.ObjectID    `bson:"product_id" json:"product_id"`
+	Name       string                `bson:"name" json:"name"`
+	Quantity   float64               `bson:"quantity" json:"quantity"`
+	Components []ProductionComponent `bson:"components" json:"components"`
+	TotalCost  Money                 `bson:"total_cost" json:"total_cost"`
+	UnitCost   Money                 `bson:"unit_cost" json:"unit_cost"` // TotalCost over Quantity
+	Notes      string                `bson:"notes,omitempty" json:"notes,omitempty"`
+	ProducedBy primitive.ObjectID    `bson:"produced_by" json:"produced_by"`
+	CreatedAt  time.Time             `bson:"created_at" json:"created_at"`
+}
+
+// ProductionComponent is what the order took of one component, at the
+// component's cost price at the time
+type ProductionComponent struct {
+	ProductID primitive.ObjectID `bson:"product_id" json:"product_id"`
+	Name      string             `bson:"name" json:"name"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+	UnitCost  Money              `bson:"unit_cost" json:"unit_cost"`
+	Cost      Money              `bson:"cost" json:"cost"`
+}
+
+// SetRecipeRequest replaces the product's recipe
+type SetRecipeRequest struct {
+	Yield      float64                `json:"yield" validate:"required,gt=0"`
+	Components []RecipeComponentInput `json:"components" validate:"required,min=1,max=50,dive"`
+	Notes      string                 `json:"notes,omitempty" validate:"max=500"`
+}
+
+type RecipeComponentInput struct {
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// CreateProductionOrderRequest makes units of a product from its recipe, taking
+// Quantity over the recipe's yield batches' worth of each component
+type CreateProductionOrderRequest struct {
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+	Notes     string  `json:"notes,omitempty" validate:"max=500"`
+}
+
+type ProductionOrderFilters struct {
+	ProductID *string
+	StartDate *time.Time
+	EndDate   *time.Time // Exclusive
+	Limit     int        // None when zero
+	Offset    int
+}
+
+// ProductionReport is what was made between two days and what it took
+type ProductionReport struct {
+	StartDate  string                      `json:"start_date"`
+	EndDate    string                      `json:"end_date"`
+	Orders     int                         `json:"orders"`
+	TotalCost  Money                       `json:"total_cost"`
+	Products   []ProductionReportProduct   `json:"products"`   // Most costly first
+	Components []ProductionReportComponent `json:"components"` // Most costly first
+}
+
+type ProductionReportProduct struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Orders    int     `json:"orders"`
+	Quantity  float64 `json:"quantity"`
+	TotalCost Money   `json:"total_cost"`
+	UnitCost  Money   `json:"unit_cost"` // Average over the period
+}
+
+type ProductionReportComponent struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Quantity  float64 `json:"quantity"`
+	Cost      Money   `json:"cost"`
+}
+
+type ProductionRepository interface {
+	// SaveRecipe creates or replaces the recipe of recipe.ProductID
+	SaveRecipe(recipe *Recipe) error
+	FindRecipe(businessID, productID string) (*Recipe, error)
+	FindRecipes(businessID string) ([]Recipe, error)
+	DeleteRecipe(businessID, productID string) error
+
+	CreateOrder(order *ProductionOrder) error
+	FindOrderByID(id string) (*ProductionOrder, error)
+	FindOrders(businessID string, filters ProductionOrderFilters) ([]ProductionOrder, error)
+	CountOrders(businessID string) (int64, error)
+}
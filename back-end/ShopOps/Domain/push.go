@@ -0,0 +1,167 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PushPlatform is the push service a device's token belongs to
+type PushPlatform string
+
+const (
+	PushPlatformAndroid PushPlatform = "android" // Firebase Cloud Messaging
+	PushPlatformIOS     PushPlatform = "ios"     // Apple Push Notification service
+)
+
+// PushTopic is a kind of notification a device can follow for a shop
+type PushTopic string
+
+const (
+	PushTopicBigSale      PushTopic = "big_sale"
+	PushTopicDailySummary PushTopic = "daily_summary"
+	PushTopicSyncFailure  PushTopic = "sync_failure" // A device's sync batch had items rejected
+	PushTopicSecurity     PushTopic = "security"     // Sign-ins and account changes; always on, and not per shop
+	PushTopicMaintenance  PushTopic = "maintenance"  // Read-only maintenance starting, changing or ending; sent to every device
+)
+
+func (t PushTopic) IsValid() bool {
+	switch t {
+	case PushTopicBigSale, PushTopicDailySummary, PushTopicSyncFailure:
+		return true
+	}
+	return false
+}
+
+// PushDevice is a phone a user signed in on and registered for notifications
+type PushDevice struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"user_id"`
+	Platform   PushPlatform       `bson:"platform" json:"platform"`
+	Token      string             `bson:"token" json:"-"`
+	Name       string             `bson:"name,omitempty" json:"name,omitempty"` // e.g. the phone model
+	AppVersion string             `bson:"app_version,omitempty" json:"app_version,omitempty"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+	LastSeenAt time.Time          `bson:"last_seen_at" json:"last_seen_at"`
+}
+
+// PushSubscription is what one device is sent about one shop. Notifications are
+// fanned out to every device subscribed to the shop's topic.
+type PushSubscription struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	DeviceID    primitive.ObjectID `bson:"device_id" json:"device_id"`
+	UserID      primitive.ObjectID `bson:"user_id" json:"user_id"`
+	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Topics      []PushTopic        `bson:"topics" json:"topics"`
+	BigSale     float64            `bson:"big_sale" json:"big_sale"`         // Sales of at least this amount are pushed
+	SummaryHour int                `bson:"summary_hour" json:"summary_hour"` // Shop-local hour the summary goes out
+	// Business day of the last daily summary sent, YYYY-MM-DD
+	LastSummaryDate string    `bson:"last_summary_date,omitempty" json:"last_summary_date,omitempty"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// DefaultPushSubscription is what a device follows for a shop until changed
+var DefaultPushSubscription = PushSubscription{
+	Topics:      []PushTopic{PushTopicBigSale, PushTopicDailySummary, PushTopicSyncFailure},
+	BigSale:     10000,
+	SummaryHour: 21,
+}
+
+// HasTopic reports whether the subscription follows topic
+func (s *PushSubscription) HasTopic(topic PushTopic) bool {
+	for _, t := range s.Topics {
+		if t == topic {
+			return true
+		}
+	}
+	return false
+}
+
+// PushMessage is the delivery log of one notification to one device. The app
+// reports back when it is shown and when it is opened.
+type PushMessage struct {
+	ID                primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID        *primitive.ObjectID `bson:"business_id,omitempty" json:"business_id,omitempty"`
+	UserID            primitive.ObjectID  `bson:"user_id" json:"user_id"`
+	DeviceID          primitive.ObjectID  `bson:"device_id" json:"device_id"`
+	Topic             PushTopic           `bson:"topic" json:"topic"`
+	Title             string              `bson:"title" json:"title"`
+	Body              string              `bson:"body" json:"body"`
+	Data              map[string]string   `bson:"data,omitempty" json:"data,omitempty"` // For the app to open the right screen
+	Status            PushStatus          `bson:"status" json:"status"`
+	ProviderMessageID string              `bson:"provider_message_id,omitempty" json:"provider_message_id,omitempty"`
+	Error             string              `bson:"error,omitempty" json:"error,omitempty"`
+	CreatedAt         time.Time           `bson:"created_at" json:"created_at"`
+	SentAt            *time.Time          `bson:"sent_at,omitempty" json:"sent_at,omitempty"`
+	DeliveredAt       *time.Time          `bson:"delivered_at,omitempty" json:"delivered_at,omitempty"`
+	OpenedAt          *time.Time          `bson:"opened_at,omitempty" json:"opened_at,omitempty"`
+}
+
+type PushStatus string
+
+const (
+	PushStatusQueued    PushStatus = "queued"
+	PushStatusSent      PushStatus = "sent" // Accepted by FCM or APNs
+	PushStatusFailed    PushStatus = "failed"
+	PushStatusDelivered PushStatus = "delivered" // The app received it
+	PushStatusOpened    PushStatus = "opened"    // The user tapped it
+)
+
+// SecurityAlert is an account event pushed to all of a user's devices
+type SecurityAlert struct {
+	Title string
+	Body  string
+}
+
+type RegisterPushDeviceRequest struct {
+	Platform   PushPlatform `json:"platform" validate:"required,oneof=android ios"`
+	Token      string       `json:"token" validate:"required,max=4096"`
+	Name       string       `json:"name,omitempty" validate:"omitempty,max=100"`
+	AppVersion string       `json:"app_version,omitempty" validate:"omitempty,max=40"`
+}
+
+type UpdatePushSubscriptionRequest struct {
+	Topics      *[]PushTopic `json:"topics,omitempty"`
+	BigSale     *float64     `json:"big_sale,omitempty" validate:"omitempty,min=0"`
+	SummaryHour *int         `json:"summary_hour,omitempty" validate:"omitempty,min=0,max=23"`
+}
+
+// PushReceiptRequest is the app reporting a notification shown or opened
+type PushReceiptRequest struct {
+	Status PushStatus `json:"status" validate:"required,oneof=delivered opened"`
+}
+
+type PushMessageFilters struct {
+	Topic  *PushTopic
+	Status *PushStatus
+	Limit  int
+	Offset int
+}
+
+type PushRepository interface {
+	// SaveDevice registers the token for the user, taking it over from any other
+	// user it was registered to, since the phone now belongs to whoever signed in last
+	SaveDevice(device *PushDevice) error
+	FindDeviceByID(id string) (*PushDevice, error)
+	FindDevicesByUser(userID string) ([]PushDevice, error)
+	FindDevicesByIDs(ids []primitive.ObjectID) ([]PushDevice, error)
+	// FindDevicesAfter pages through every device in ID order, starting after
+	// afterID; from the first when it is zero
+	FindDevicesAfter(afterID primitive.ObjectID, limit int) ([]PushDevice, error)
+	// DeleteDevice removes the device and its subscriptions
+	DeleteDevice(id string) error
+
+	FindSubscription(deviceID, businessID string) (*PushSubscription, error)
+	SaveSubscription(subscription *PushSubscription) error
+	// FindSubscribed returns the shop's subscriptions following topic
+	FindSubscribed(businessID string, topic PushTopic) ([]PushSubscription, error)
+	FindSummarySubscriptions() ([]PushSubscription, error)
+	SetLastSummaryDate(id primitive.ObjectID, date string) error
+
+	LogMessage(message *PushMessage) error
+	FindMessageByID(id string) (*PushMessage, error)
+	FindMessages(businessID string, filters PushMessageFilters) ([]PushMessage, error)
+	UpdateMessage(message *PushMessage) error
+	// MarkReceipt moves the message on to delivered or opened, never back
+	MarkReceipt(id string, status PushStatus) error
+}
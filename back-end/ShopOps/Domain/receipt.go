@@ -0,0 +1,118 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ReceiptTemplate controls how receipts look on every device of a business
+type ReceiptTemplate struct {
+	ID              primitive.ObjectID     `bson:"_id,omitempty" json:"id"`
+	BusinessID      primitive.ObjectID     `bson:"business_id" json:"business_id"`
+	Name            string                 `bson:"name" json:"name"`
+	IsDefault       bool                   `bson:"is_default" json:"is_default"`
+	Header          []string               `bson:"header,omitempty" json:"header,omitempty"` // Extra lines printed under the business name
+	Footer          []string               `bson:"footer,omitempty" json:"footer,omitempty"`
+	LogoURL         string                 `bson:"logo_url,omitempty" json:"logo_url,omitempty"` // Used by HTML receipts
+	PrintStoredLogo bool                   `bson:"print_stored_logo" json:"print_stored_logo"`   // ESC/POS: print the logo saved in printer memory
+	PaperWidth      ReceiptPaperWidth      `bson:"paper_width" json:"paper_width"`
+	Fields          ReceiptFieldVisibility `bson:"fields" json:"fields"`
+	CreatedBy       primitive.ObjectID     `bson:"created_by" json:"created_by"`
+	CreatedAt       time.Time              `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time              `bson:"updated_at" json:"updated_at"`
+}
+
+// ReceiptPaperWidth is the roll width in millimetres
+type ReceiptPaperWidth int
+
+const (
+	ReceiptPaperWidth58 ReceiptPaperWidth = 58
+	ReceiptPaperWidth80 ReceiptPaperWidth = 80
+)
+
+// Columns returns the number of characters per line in the printer's default font
+func (w ReceiptPaperWidth) Columns() int {
+	if w == ReceiptPaperWidth58 {
+		return 32
+	}
+	return 48
+}
+
+type ReceiptFieldVisibility struct {
+	BusinessName     bool `bson:"business_name" json:"business_name"`
+	Address          bool `bson:"address" json:"address"`
+	Phone            bool `bson:"phone" json:"phone"`
+	SaleID           bool `bson:"sale_id" json:"sale_id"`
+	Date             bool `bson:"date" json:"date"`
+	Customer         bool `bson:"customer" json:"customer"`
+	Discount         bool `bson:"discount" json:"discount"`
+	Tax              bool `bson:"tax" json:"tax"`
+	PaymentBreakdown bool `bson:"payment_breakdown" json:"payment_breakdown"`
+	Notes            bool `bson:"notes" json:"notes"`
+}
+
+// DefaultReceiptFields shows everything except internal notes
+func DefaultReceiptFields() ReceiptFieldVisibility {
+	return ReceiptFieldVisibility{
+		BusinessName:     true,
+		Address:          true,
+		Phone:            true,
+		SaleID:           true,
+		Date:             true,
+		Customer:         true,
+		Discount:         true,
+		Tax:              true,
+		PaymentBreakdown: true,
+	}
+}
+
+type ReceiptFormat string
+
+const (
+	ReceiptFormatESCPOS ReceiptFormat = "escpos"
+	ReceiptFormatHTML   ReceiptFormat = "html"
+)
+
+// ReceiptData is everything a renderer needs to print one sale
+type ReceiptData struct {
+	Template    *ReceiptTemplate
+	Business    *Business
+	Sale        *Sale
+	ProductName string
+	Language    Language // Labels; English when empty
+}
+
+type CreateReceiptTemplateRequest struct {
+	Name            string                  `json:"name" validate:"required"`
+	IsDefault       bool                    `json:"is_default"`
+	Header          []string                `json:"header,omitempty"`
+	Footer          []string                `json:"footer,omitempty"`
+	LogoURL         string                  `json:"logo_url,omitempty"`
+	PrintStoredLogo bool                    `json:"print_stored_logo"`
+	PaperWidth      ReceiptPaperWidth       `json:"paper_width,omitempty"` // 58 or 80, defaults to 80
+	Fields          *ReceiptFieldVisibility `json:"fields,omitempty"`
+}
+
+type UpdateReceiptTemplateRequest struct {
+	Name            *string                 `json:"name,omitempty"`
+	IsDefault       *bool                   `json:"is_default,omitempty"`
+	Header          []string                `json:"header,omitempty"`
+	Footer          []string                `json:"footer,omitempty"`
+	LogoURL         *string                 `json:"logo_url,omitempty"`
+	PrintStoredLogo *bool                   `json:"print_stored_logo,omitempty"`
+	PaperWidth      *ReceiptPaperWidth      `json:"paper_width,omitempty"`
+	Fields          *ReceiptFieldVisibility `json:"fields,omitempty"`
+}
+
+type ReceiptTemplateRepository interface {
+	Create(template *ReceiptTemplate) error
+	// FindInBusiness returns the template when it is one of the shop's
+	FindInBusiness(ctx context.Context, id string) (*ReceiptTemplate, error)
+	FindByBusinessID(businessID string) ([]ReceiptTemplate, error)
+	FindDefault(businessID string) (*ReceiptTemplate, error)
+	Update(template *ReceiptTemplate) error
+	Delete(id string) error
+	ClearDefault(businessID string) error
+}
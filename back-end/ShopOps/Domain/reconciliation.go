@@ -0,0 +1,189 @@
+package Domain
+
+import (
+	"strconv"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StatementSource is the bank or wallet a statement was exported from, which
+// decides how its CSV is read
+type StatementSource string
+
+const (
+	StatementCBE      StatementSource = "cbe"   // Commercial Bank of Ethiopia
+	StatementAwash    StatementSource = "awash" // Awash Bank
+	StatementTelebirr StatementSource = "telebirr"
+)
+
+func (s StatementSource) IsValid() bool {
+	return s == StatementCBE || s == StatementAwash || s == StatementTelebirr
+}
+
+// Methods are the sale payment methods whose money lands in the source's account
+func (s StatementSource) Methods() []PaymentMethod {
+	if s == StatementTelebirr {
+		return []PaymentMethod{PaymentMethodMobile}
+	}
+	return []PaymentMethod{PaymentMethodBank, PaymentMethodCard}
+}
+
+// BankStatement is an imported statement file. Its lines are kept on their own,
+// so a line on two overlapping statements is imported once.
+type BankStatement struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Source     StatementSource    `bson:"source" json:"source"`
+	FileName   string             `bson:"file_name" json:"file_name"`
+	// Dates of the first and last lines
+	PeriodStart time.Time          `bson:"period_start" json:"period_start"`
+	PeriodEnd   time.Time          `bson:"period_end" json:"period_end"`
+	LineCount   int                `bson:"line_count" json:"line_count"`
+	Duplicates  int                `bson:"duplicates" json:"duplicates"` // Lines already imported from an earlier statement
+	Matched     int                `bson:"matched" json:"matched"`       // Matched when imported
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+// StatementLine is one transaction on a statement, matched to the payment or
+// expense it is the money for
+type StatementLine struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
+	StatementID primitive.ObjectID `bson:"statement_id" json:"statement_id"`
+	Source      StatementSource    `bson:"source" json:"source"`
+	Date        time.Time          `bson:"date" json:"date"`
+	Reference   string             `bson:"reference,omitempty" json:"reference,omitempty"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Amount      Money              `bson:"amount" json:"amount"` // Money in is positive, money out negative
+	Balance     *Money             `bson:"balance,omitempty" json:"balance,omitempty"`
+	// Identifies the line across statements, so re-imports skip it
+	Fingerprint string              `bson:"fingerprint" json:"-"`
+	Status      StatementLineStatus `bson:"status" json:"status"`
+	Match       *StatementMatch     `bson:"match,omitempty" json:"match,omitempty"`
+	Note        string              `bson:"note,omitempty" json:"note,omitempty"` // Why it was ignored
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type StatementLineStatus string
+
+const (
+	StatementLineUnmatched StatementLineStatus = "unmatched"
+	StatementLineMatched   StatementLineStatus = "matched"
+	StatementLineIgnored   StatementLineStatus = "ignored" // Bank fees, transfers between own accounts and the like
+)
+
+// StatementMatch is the recorded payment a line was matched to
+type StatementMatch struct {
+	Type      ReconciliationEntryType `bson:"type" json:"type"`
+	ID        primitive.ObjectID      `bson:"id" json:"id"`           // Sale or expense
+	Payment   int                     `bson:"payment" json:"payment"` // Index of the split tender payment, -1 for the whole sale
+	Key       string                  `bson:"key" json:"-"`           // Unique per recorded payment, so it is matched once
+	Method    MatchMethod             `bson:"method" json:"method"`
+	MatchedBy *primitive.ObjectID     `bson:"matched_by,omitempty" json:"matched_by,omitempty"` // Set for manual matches
+	MatchedAt time.Time               `bson:"matched_at" json:"matched_at"`
+}
+
+type ReconciliationEntryType string
+
+const (
+	ReconciliationSale    ReconciliationEntryType = "sale"
+	ReconciliationExpense ReconciliationEntryType = "expense"
+)
+
+type MatchMethod string
+
+const (
+	MatchByReference  MatchMethod = "reference"   // The payment's transaction reference is on the line
+	MatchByAmountDate MatchMethod = "amount_date" // Same amount, closest date
+	MatchManual       MatchMethod = "manual"
+)
+
+// ReconciliationMatchWindow is how far a line's date may be from the payment's;
+// banks post card and transfer payments a day or two late
+const ReconciliationMatchWindow = 3 * 24 * time.Hour
+
+// ReconciliationEntry is a payment recorded in the shop that should show on a
+// statement: a bank, card or mobile sale payment, or an expense
+type ReconciliationEntry struct {
+	Type      ReconciliationEntryType `json:"type"`
+	ID        primitive.ObjectID      `json:"id"`
+	Payment   int                     `json:"payment"`
+	Date      time.Time               `json:"date"`
+	Amount    Money                   `json:"amount"` // Negative for expenses
+	Method    PaymentMethod           `json:"method,omitempty"`
+	Reference string                  `json:"reference,omitempty"`
+	Label     string                  `json:"label,omitempty"` // Customer or expense description
+}
+
+// Key identifies the entry among matches
+func (e *ReconciliationEntry) Key() string {
+	if e.Type == ReconciliationExpense {
+		return "expense:" + e.ID.Hex()
+	}
+	return "sale:" + e.ID.Hex() + ":" + strconv.Itoa(e.Payment)
+}
+
+// ReconciliationReport sets a period's statement lines against the payments
+// recorded for it
+type ReconciliationReport struct {
+	StartDate time.Time `json:"start_date"`
+	EndDate   time.Time `json:"end_date"`
+
+	Lines        int   `json:"lines"`
+	MatchedLines int   `json:"matched_lines"`
+	IgnoredLines int   `json:"ignored_lines"`
+	MoneyIn      Money `json:"money_in"`
+	MoneyOut     Money `json:"money_out"`
+	MatchedIn    Money `json:"matched_in"`
+	MatchedOut   Money `json:"matched_out"`
+	UnmatchedIn  Money `json:"unmatched_in"` // On the statements but not recorded
+	UnmatchedOut Money `json:"unmatched_out"`
+	RecordedIn   Money `json:"recorded_in"`   // Bank, card and mobile sale payments
+	UnrecordedIn Money `json:"unrecorded_in"` // Recorded but on no statement
+	Reconciled   bool  `json:"reconciled"`    // Nothing left unmatched on either side
+
+	UnmatchedLines []StatementLine       `json:"unmatched_lines"`
+	Unrecorded     []ReconciliationEntry `json:"unrecorded"`
+}
+
+type MatchStatementLineRequest struct {
+	Type    ReconciliationEntryType `json:"type" validate:"required,oneof=sale expense"`
+	ID      string                  `json:"id" validate:"required"`
+	Payment *int                    `json:"payment,omitempty"` // Split tender payment of the sale; the whole sale when left out
+}
+
+type IgnoreStatementLineRequest struct {
+	Note string `json:"note" validate:"required,max=200"`
+}
+
+type StatementLineFilters struct {
+	StatementID *string
+	Status      *StatementLineStatus
+	StartDate   *time.Time
+	EndDate     *time.Time
+	Limit       int
+	Offset      int
+}
+
+type ReconciliationRepository interface {
+	CreateStatement(statement *BankStatement) error
+	FindStatementByID(id string) (*BankStatement, error)
+	FindStatements(businessID string) ([]BankStatement, error)
+	UpdateStatement(statement *BankStatement) error
+	// DeleteStatement removes the statement and the lines first imported from it
+	DeleteStatement(id string) error
+
+	// CreateLines saves the lines not already imported for the business and
+	// returns them
+	CreateLines(lines []StatementLine) ([]StatementLine, error)
+	FindLineByID(id string) (*StatementLine, error)
+	FindLines(businessID string, filters StatementLineFilters) ([]StatementLine, error)
+	// FindMatchKeys returns the keys of the recorded payments matched to a line
+	FindMatchKeys(businessID string) (map[string]bool, error)
+	// SetMatch saves the line's status and match; false when another line took
+	// the recorded payment first
+	SetMatch(line *StatementLine) (bool, error)
+}
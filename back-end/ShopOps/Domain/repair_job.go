@@ -0,0 +1,213 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RepairJob is a device left with the shop for repair. Parts fitted come out of
+// stock as they are added and labor is logged against the job; both are billed
+// through ordinary sales, one per line, before the customer collects the device.
+type RepairJob struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Number        string              `bson:"number" json:"number"` // RJ-00001, printed on the intake slip
+	CustomerName  string              `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	CustomerPhone string              `bson:"customer_phone,omitempty" json:"customer_phone,omitempty" pii:"phone"`
+	Device        RepairDevice        `bson:"device" json:"device"`
+	Problem       string              `bson:"problem" json:"problem"`                         // As the customer described it
+	Diagnosis     string              `bson:"diagnosis,omitempty" json:"diagnosis,omitempty"` // What the technician found
+	Estimate      Money               `bson:"estimate,omitempty" json:"estimate,omitempty"`   // Quoted at intake
+	Status        RepairJobStatus     `bson:"status" json:"status"`
+	TechnicianID  *primitive.ObjectID `bson:"technician_id,omitempty" json:"technician_id,omitempty"`
+	Parts         []RepairPart        `bson:"parts" json:"parts"`
+	Labor         []RepairLabor       `bson:"labor" json:"labor"`
+	History       []RepairJobEvent    `bson:"history" json:"history"`
+	CreatedBy     primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+	CompletedAt   *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"` // When it was last made ready
+	NotifiedAt    *time.Time          `bson:"notified_at,omitempty" json:"notified_at,omitempty"`   // When the customer was texted that it is ready
+	CollectedAt   *time.Time          `bson:"collected_at,omitempty" json:"collected_at,omitempty"`
+	Version       int64               `bson:"version" json:"version"` // Incremented by every update
+}
+
+// Total is what the job's parts and labor come to
+func (j *RepairJob) Total() Money {
+	var total Money
+	for _, part := range j.Parts {
+		total += part.UnitPrice.Times(part.Quantity)
+	}
+	for _, labor := range j.Labor {
+		total += labor.UnitPrice.Times(labor.Quantity)
+	}
+	return total
+}
+
+// Unbilled reports whether any part or labor is still to be billed
+func (j *RepairJob) Unbilled() bool {
+	for _, part := range j.Parts {
+		if part.SaleID == nil {
+			return true
+		}
+	}
+	for _, labor := range j.Labor {
+		if labor.SaleID == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Billed reports whether any part or labor has been billed
+func (j *RepairJob) Billed() bool {
+	for _, part := range j.Parts {
+		if part.SaleID != nil {
+			return true
+		}
+	}
+	for _, labor := range j.Labor {
+		if labor.SaleID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// Closed reports whether the job is over, so it can no longer change
+func (j *RepairJob) Closed() bool {
+	return j.Status == RepairJobCollected || j.Status == RepairJobCancelled
+}
+
+type RepairDevice struct {
+	Type        string `bson:"type,omitempty" json:"type,omitempty" validate:"max=50"` // Phone, tablet, laptop...
+	Brand       string `bson:"brand,omitempty" json:"brand,omitempty" validate:"max=50"`
+	Model       string `bson:"model,omitempty" json:"model,omitempty" validate:"max=100"`
+	Serial      string `bson:"serial,omitempty" json:"serial,omitempty" validate:"max=100"` // Serial number or IMEI
+	Condition   string `bson:"condition,omitempty" json:"condition,omitempty" validate:"max=500"`
+	Accessories string `bson:"accessories,omitempty" json:"accessories,omitempty" validate:"max=200"` // Left with the device, such as a charger or case
+}
+
+type RepairJobStatus string
+
+const (
+	RepairJobReceived     RepairJobStatus = "received"
+	RepairJobDiagnosing   RepairJobStatus = "diagnosing"
+	RepairJobInProgress   RepairJobStatus = "in_progress"
+	RepairJobWaitingParts RepairJobStatus = "waiting_parts"
+	RepairJobReady        RepairJobStatus = "ready" // Done, waiting for the customer
+	RepairJobCollected    RepairJobStatus = "collected"
+	RepairJobCancelled    RepairJobStatus = "cancelled" // Unbilled parts went back into stock
+)
+
+func (s RepairJobStatus) IsValid() bool {
+	switch s {
+	case RepairJobReceived, RepairJobDiagnosing, RepairJobInProgress, RepairJobWaitingParts,
+		RepairJobReady, RepairJobCollected, RepairJobCancelled:
+		return true
+	}
+	return false
+}
+
+// RepairPart is a product fitted on the job, taken out of stock when added
+type RepairPart struct {
+	ID        primitive.ObjectID  `bson:"id" json:"id"`
+	ProductID primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	Name      string              `bson:"name" json:"name"`
+	Quantity  float64             `bson:"quantity" json:"quantity"`
+	UnitPrice Money               `bson:"unit_price" json:"unit_price"`
+	SaleID    *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"` // Set once billed
+	AddedBy   primitive.ObjectID  `bson:"added_by" json:"added_by"`
+	AddedAt   time.Time           `bson:"added_at" json:"added_at"`
+}
+
+// RepairLabor is work done on the job, optionally priced from a service item
+type RepairLabor struct {
+	ID          primitive.ObjectID  `bson:"id" json:"id"`
+	ServiceID   *primitive.ObjectID `bson:"service_id,omitempty" json:"service_id,omitempty"`
+	Description string              `bson:"description" json:"description"`
+	Quantity    float64             `bson:"quantity" json:"quantity"` // Hours, or times the service was done
+	UnitPrice   Money               `bson:"unit_price" json:"unit_price"`
+	SaleID      *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"` // Set once billed
+	AddedBy     primitive.ObjectID  `bson:"added_by" json:"added_by"`
+	AddedAt     time.Time           `bson:"added_at" json:"added_at"`
+}
+
+// RepairJobEvent is a status the job moved to
+type RepairJobEvent struct {
+	Status RepairJobStatus    `bson:"status" json:"status"`
+	Note   string             `bson:"note,omitempty" json:"note,omitempty"`
+	By     primitive.ObjectID `bson:"by" json:"by"`
+	At     time.Time          `bson:"at" json:"at"`
+}
+
+type CreateRepairJobRequest struct {
+	CustomerName  string       `json:"customer_name,omitempty" validate:"max=100"`
+	CustomerPhone string       `json:"customer_phone" validate:"required,phone"`
+	Device        RepairDevice `json:"device"`
+	Problem       string       `json:"problem" validate:"required,max=1000"`
+	Estimate      Money        `json:"estimate,omitempty" validate:"omitempty,gte=0,money"`
+	TechnicianID  *string      `json:"technician_id,omitempty"`
+}
+
+// UpdateRepairJobRequest changes the job's details; only the fields given change
+type UpdateRepairJobRequest struct {
+	CustomerName  *string       `json:"customer_name,omitempty" validate:"omitempty,max=100"`
+	CustomerPhone *string       `json:"customer_phone,omitempty" validate:"omitempty,phone"`
+	Device        *RepairDevice `json:"device,omitempty"`
+	Problem       *string       `json:"problem,omitempty" validate:"omitempty,min=1,max=1000"`
+	Diagnosis     *string       `json:"diagnosis,omitempty" validate:"omitempty,max=2000"`
+	Estimate      *Money        `json:"estimate,omitempty" validate:"omitempty,gte=0,money"`
+	TechnicianID  *string       `json:"technician_id,omitempty"` // Empty unassigns
+
+	// The version the client last read; a newer one on the server is a 409
+	Version *int64 `json:"version,omitempty"`
+}
+
+type SetRepairJobStatusRequest struct {
+	Status RepairJobStatus `json:"status" validate:"required"`
+	Note   string          `json:"note,omitempty" validate:"max=500"`
+}
+
+type AddRepairPartRequest struct {
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+	UnitPrice *Money  `json:"unit_price,omitempty" validate:"omitempty,gt=0,money"` // Defaults to the product's selling price
+}
+
+// AddRepairLaborRequest logs work on the job, priced from ServiceID when given
+type AddRepairLaborRequest struct {
+	ServiceID   *string `json:"service_id,omitempty"`
+	Description string  `json:"description,omitempty" validate:"max=200"` // Defaults to the service's name
+	Quantity    float64 `json:"quantity,omitempty" validate:"gte=0"`      // Defaults to 1
+	UnitPrice   *Money  `json:"unit_price,omitempty" validate:"omitempty,gt=0,money"`
+}
+
+// BillRepairJobRequest bills the job's unbilled parts and labor
+type BillRepairJobRequest struct {
+	PaymentMethod PaymentMethod `json:"payment_method" validate:"required"`
+}
+
+type RepairJobFilters struct {
+	Status        *RepairJobStatus
+	TechnicianID  *string
+	CustomerPhone *string
+	Open          *bool // Neither collected nor cancelled
+	Limit         int
+	Offset        int
+}
+
+type RepairJobRepository interface {
+	Create(job *RepairJob) error
+	// FindInBusiness returns the job when the shop took it in
+	FindInBusiness(ctx context.Context, id string) (*RepairJob, error)
+	// FindBySale returns the job with a part or labor billed on the sale
+	FindBySale(saleID primitive.ObjectID) (*RepairJob, error)
+	FindByBusiness(businessID string, filters RepairJobFilters) ([]RepairJob, error)
+	CountByBusinessID(businessID string) (int64, error)
+	// Update saves the job if it is still at job.Version, and increments it
+	Update(job *RepairJob) error
+	MarkNotified(id primitive.ObjectID, at time.Time) error
+}
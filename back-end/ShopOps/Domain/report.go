@@ -9,6 +9,7 @@ const (
 	ReportTypeExpenses  ReportType = "expenses"
 	ReportTypeProfit    ReportType = "profit"
 	ReportTypeInventory ReportType = "inventory"
+	ReportTypeDeadStock ReportType = "dead_stock"
 )
 
 type PeriodType string
@@ -19,6 +20,9 @@ const (
 	PeriodTypeMonthly PeriodType = "monthly"
 	PeriodTypeYearly  PeriodType = "yearly"
 	PeriodTypeCustom  PeriodType = "custom"
+
+	// Fiscal year to date as a report period, or fiscal years as a P&L granularity
+	PeriodTypeFiscalYearly PeriodType = "fiscal_yearly"
 )
 
 type ReportRequest struct {
@@ -29,14 +33,27 @@ type ReportRequest struct {
 	EndDate    *time.Time `json:"end_date,omitempty"`
 	Category   *string    `json:"category,omitempty"`
 	Format     *string    `json:"format,omitempty"` // json, csv
+
+	// Dead stock options
+	Days   int    `json:"days,omitempty"`
+	SortBy string `json:"sort_by,omitempty"`
+	Order  string `json:"order,omitempty"`
+
+	// Calendar for fiscal year boundaries and dates in exports; defaults to Gregorian
+	Calendar Calendar `json:"calendar,omitempty"`
+	// Language of CSV export headers; English when empty
+	Language Language `json:"-"`
+	// Leave customers' names and phone numbers unmasked in exports, for callers
+	// who may see them
+	IncludePII bool `json:"-"`
 }
 
 type SalesReport struct {
 	Period            string       `json:"period"`
 	TotalSales        float64      `json:"total_sales"`
-	TotalAmount       float64      `json:"total_amount"`
+	TotalAmount       Money        `json:"total_amount"`
 	TotalTransactions int          `json:"total_transactions"`
-	AverageSale       float64      `json:"average_sale"`
+	AverageSale       Money        `json:"average_sale"`
 	TopProducts       []TopProduct `json:"top_products,omitempty"`
 	DailyBreakdown    []DailySales `json:"daily_breakdown,omitempty"`
 }
@@ -45,57 +62,58 @@ type TopProduct struct {
 	ProductID   string  `json:"product_id"`
 	ProductName string  `json:"product_name"`
 	Quantity    float64 `json:"quantity"`
-	TotalAmount float64 `json:"total_amount"`
+	TotalAmount Money   `json:"total_amount"`
 }
 
 type DailySales struct {
 	Date         string  `json:"date"`
 	Sales        float64 `json:"sales"`
-	Amount       float64 `json:"amount"`
+	Amount       Money   `json:"amount"`
 	Transactions int     `json:"transactions"`
 }
 
 type ExpensesReport struct {
 	Period            string            `json:"period"`
-	TotalExpenses     float64           `json:"total_expenses"`
+	TotalExpenses     Money             `json:"total_expenses"`
 	CategoryBreakdown []CategoryExpense `json:"category_breakdown"`
 	DailyExpenses     []DailyExpense    `json:"daily_expenses,omitempty"`
 }
 
 type CategoryExpense struct {
 	Category    ExpenseCategory `json:"category"`
-	TotalAmount float64         `json:"total_amount"`
+	TotalAmount Money           `json:"total_amount"`
 	Count       int             `json:"count"`
 	Percentage  float64         `json:"percentage"`
 }
 
 type DailyExpense struct {
-	Date   string  `json:"date"`
-	Amount float64 `json:"amount"`
-	Count  int     `json:"count"`
+	Date   string `json:"date"`
+	Amount Money  `json:"amount"`
+	Count  int    `json:"count"`
 }
 
 type ProfitReport struct {
-	Period        string        `json:"period"`
-	TotalSales    float64       `json:"total_sales"`
-	TotalExpenses float64       `json:"total_expenses"`
-	GrossProfit   float64       `json:"gross_profit"`
-	NetProfit     float64       `json:"net_profit"`
-	ProfitMargin  float64       `json:"profit_margin"`
-	Trends        []ProfitTrend `json:"trends,omitempty"`
+	Period           string            `json:"period"`
+	TotalSales       Money             `json:"total_sales"`
+	TotalExpenses    Money             `json:"total_expenses"`
+	GrossProfit      Money             `json:"gross_profit"`
+	NetProfit        Money             `json:"net_profit"`
+	ProfitMargin     float64           `json:"profit_margin"`
+	ExpenseBreakdown []CategoryExpense `json:"expense_breakdown,omitempty"`
+	Trends           []ProfitTrend     `json:"trends,omitempty"`
 }
 
 type ProfitTrend struct {
-	Period   string  `json:"period"`
-	Sales    float64 `json:"sales"`
-	Expenses float64 `json:"expenses"`
-	Profit   float64 `json:"profit"`
+	Period   string `json:"period"`
+	Sales    Money  `json:"sales"`
+	Expenses Money  `json:"expenses"`
+	Profit   Money  `json:"profit"`
 }
 
 type InventoryReport struct {
 	TotalProducts int             `json:"total_products"`
 	TotalStock    float64         `json:"total_stock"`
-	TotalValue    float64         `json:"total_value"`
+	TotalValue    Money           `json:"total_value"`
 	LowStockItems []LowStockItem  `json:"low_stock_items"`
 	StockMovement []StockMovement `json:"stock_movement,omitempty"`
 }
@@ -108,18 +126,66 @@ type LowStockItem struct {
 	Difference  float64 `json:"difference"`
 }
 
+type DeadStockStatus string
+
+const (
+	DeadStockStatusDead DeadStockStatus = "dead" // No sales in the window
+	DeadStockStatusSlow DeadStockStatus = "slow" // Sells, but too slowly to clear the stock
+)
+
+// DeadStockItem is a product with stock on hand and its recent sales
+type DeadStockItem struct {
+	ProductID     string          `bson:"_id" json:"product_id"`
+	ProductName   string          `bson:"name" json:"product_name"`
+	SKU           string          `bson:"sku,omitempty" json:"sku,omitempty"`
+	Category      string          `bson:"category,omitempty" json:"category,omitempty"`
+	Stock         float64         `bson:"stock" json:"stock"`
+	CostPrice     Money           `bson:"cost_price" json:"cost_price"`
+	SellingPrice  Money           `bson:"selling_price" json:"selling_price"`
+	LastSoldAt    *time.Time      `bson:"last_sold_at,omitempty" json:"last_sold_at,omitempty"`
+	SoldInWindow  float64         `bson:"sold_in_window" json:"sold_in_window"`
+	CreatedAt     time.Time       `bson:"created_at" json:"-"`
+	Status        DeadStockStatus `bson:"-" json:"status"`
+	DaysSinceSale *int            `bson:"-" json:"days_since_sale,omitempty"` // Nil when never sold
+	DaysOfCover   *float64        `bson:"-" json:"days_of_cover,omitempty"`   // At the window's sales rate
+	TiedUpCapital Money           `bson:"-" json:"tied_up_capital"`           // Stock at cost
+	RetailValue   Money           `bson:"-" json:"retail_value"`              // Stock at selling price
+}
+
+type DeadStockReport struct {
+	Days               int             `json:"days"`
+	SlowCoverDays      int             `json:"slow_cover_days"`
+	SortBy             string          `json:"sort_by"`
+	DeadCount          int             `json:"dead_count"`
+	SlowCount          int             `json:"slow_count"`
+	DeadCapital        Money           `json:"dead_capital"`
+	SlowCapital        Money           `json:"slow_capital"`
+	TotalTiedUpCapital Money           `json:"total_tied_up_capital"`
+	Items              []DeadStockItem `json:"items"`
+}
+
+type DeadStockOptions struct {
+	Days          int // Products with no sales in this many days are dead stock
+	SlowCoverDays int // Products whose stock lasts longer than this at the current rate are slow movers
+	IncludeSlow   bool
+	SortBy        string // capital, days_since_sale, stock, name
+	Order         string // asc or desc
+}
+
 type DashboardData struct {
-	TodaySales      float64 `json:"today_sales"`
-	TodayExpenses   float64 `json:"today_expenses"`
-	TodayProfit     float64 `json:"today_profit"`
-	WeekSales       float64 `json:"week_sales"`
-	WeekExpenses    float64 `json:"week_expenses"`
-	WeekProfit      float64 `json:"week_profit"`
-	MonthSales      float64 `json:"month_sales"`
-	MonthExpenses   float64 `json:"month_expenses"`
-	MonthProfit     float64 `json:"month_profit"`
-	LowStockCount   int     `json:"low_stock_count"`
-	PendingPayments float64 `json:"pending_payments"`
+	TodaySales      Money `json:"today_sales"`
+	TodayExpenses   Money `json:"today_expenses"`
+	TodayProfit     Money `json:"today_profit"`
+	WeekSales       Money `json:"week_sales"`
+	WeekExpenses    Money `json:"week_expenses"`
+	WeekProfit      Money `json:"week_profit"`
+	MonthSales      Money `json:"month_sales"`
+	MonthExpenses   Money `json:"month_expenses"`
+	MonthProfit     Money `json:"month_profit"`
+	LowStockCount   int   `json:"low_stock_count"`
+	PendingPayments Money `json:"pending_payments"`
+
+	Segments []SegmentCount `json:"segments,omitempty"` // Segments flagged show_on_dashboard
 }
 
 type ReportRepository interface {
@@ -127,6 +193,9 @@ type ReportRepository interface {
 	GenerateExpensesReport(businessID string, startDate, endDate time.Time) (*ExpensesReport, error)
 	GenerateProfitReport(businessID string, startDate, endDate time.Time) (*ProfitReport, error)
 	GenerateInventoryReport(businessID string) (*InventoryReport, error)
-	GetDashboardData(businessID string) (*DashboardData, error)
+	// Totals for the day starting at today and the 7 and 30 days before it
+	GetDashboardData(businessID string, today time.Time) (*DashboardData, error)
+	// GetStockActivity lists active products with stock on hand, their last sale and the quantity sold since since
+	GetStockActivity(businessID string, since time.Time) ([]DeadStockItem, error)
 	ExportCSV(report interface{}, reportType ReportType) ([]byte, error)
 }
@@ -0,0 +1,57 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Customers' names and phone numbers are kept for as long as each shop's
+// AnonymizeAfterYears setting allows. Past it the daily retention run removes them
+// from the shop's sales, archived sales included, and from the records that name
+// the customer, leaving the amounts, items and dates reports add up. The same run
+// purges what has been in a shop's trash for TrashRetention.
+
+// AnonymizedCollection is where the run removes customers' details: Fields go from
+// documents whose AgedBy date is past the shop's retention
+type AnonymizedCollection struct {
+	Name        string
+	AgedBy      string
+	Fields      []string
+	SoldToPhone string // Set on records kept while the customer still buys: one whose phone here is on a sale since is left alone
+}
+
+// AnonymizedCollections are everywhere a customer's details are kept. Records still
+// in use are aged by when they last changed, so only those left alone for the whole
+// retention lose them.
+var AnonymizedCollections = []AnonymizedCollection{
+	{Name: "sales", AgedBy: "created_at", Fields: []string{"customer_name", "customer_phone"}},
+	{Name: ArchiveCollection("sales"), AgedBy: "created_at", Fields: []string{"customer_name", "customer_phone"}},
+	{Name: "customers", AgedBy: "updated_at", Fields: []string{"name", "phone", "email", "address"}, SoldToPhone: "phone"},
+	{Name: "loyalty_accounts", AgedBy: "updated_at", Fields: []string{"customer_name", "customer_phone"}},
+	{Name: "gift_cards", AgedBy: "updated_at", Fields: []string{"customer_name", "customer_phone"}},
+	{Name: "sms_messages", AgedBy: "created_at", Fields: []string{"phone"}},
+	{Name: "appointments", AgedBy: "ends_at", Fields: []string{"customer_name", "customer_phone"}},
+	{Name: "repair_jobs", AgedBy: "updated_at", Fields: []string{"customer_name", "customer_phone"}},
+}
+
+// RetentionReport is what one retention run did to one shop. Shops the run left
+// alone get none.
+type RetentionReport struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID       primitive.ObjectID `bson:"business_id" json:"business_id"`
+	AnonymizedBefore *time.Time         `bson:"anonymized_before,omitempty" json:"anonymized_before,omitempty"` // What aged past this lost its customer's details; nil while the shop keeps them
+	TrashBefore      time.Time          `bson:"trash_before" json:"trash_before"`                               // What was deleted before this was purged
+	Anonymized       map[string]int64   `bson:"anonymized" json:"anonymized"`                                   // By collection
+	Purged           map[string]int64   `bson:"purged" json:"purged"`                                           // Products, customers and suppliers
+	RunAt            time.Time          `bson:"run_at" json:"run_at"`
+}
+
+type RetentionRepository interface {
+	// Anonymize removes collection's Fields from up to limit of the business's
+	// documents aged past before, and returns how many it changed
+	Anonymize(businessID primitive.ObjectID, collection AnonymizedCollection, before time.Time, limit int) (int64, error)
+	SaveReports(reports []RetentionReport) error
+	// FindReports returns the business's reports, newest first
+	FindReports(businessID string, limit int) ([]RetentionReport, error)
+}
@@ -1,6 +1,7 @@
 package Domain
 
 import (
+	"context"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
@@ -11,23 +12,51 @@ type Sale struct {
 	BusinessID    primitive.ObjectID  `bson:"business_id" json:"business_id"`
 	LocalID       string              `bson:"local_id,omitempty" json:"local_id,omitempty"` // For offline sync
 	ProductID     *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"`
-	CustomerName  string              `bson:"customer_name,omitempty" json:"customer_name,omitempty"`
-	CustomerPhone string              `bson:"customer_phone,omitempty" json:"customer_phone,omitempty"`
+	CustomerName  string              `bson:"customer_name,omitempty" json:"customer_name,omitempty" pii:"name"`
+	CustomerPhone string              `bson:"customer_phone,omitempty" json:"customer_phone,omitempty" pii:"phone"`
 	Quantity      float64             `bson:"quantity" json:"quantity" validate:"required,gt=0"`
-	UnitPrice     float64             `bson:"unit_price" json:"unit_price" validate:"required,gt=0"`
-	TotalAmount   float64             `bson:"total_amount" json:"total_amount"`
-	Discount      float64             `bson:"discount,omitempty" json:"discount,omitempty"`
-	Tax           float64             `bson:"tax,omitempty" json:"tax,omitempty"`
-	FinalAmount   float64             `bson:"final_amount" json:"final_amount"`
+	UnitPrice     Money               `bson:"unit_price" json:"unit_price" validate:"required,gt=0"`
+	TotalAmount   Money               `bson:"total_amount" json:"total_amount"`
+	Discount      Money               `bson:"discount,omitempty" json:"discount,omitempty"`
+	Tax           Money               `bson:"tax,omitempty" json:"tax,omitempty"`
+	FinalAmount   Money               `bson:"final_amount" json:"final_amount"`
 	PaymentMethod PaymentMethod       `bson:"payment_method" json:"payment_method"`
 	PaymentStatus PaymentStatus       `bson:"payment_status" json:"payment_status"`
+	Payments      []SalePayment       `bson:"payments,omitempty" json:"payments,omitempty"`           // Split tender breakdown
+	CashRounding  Money               `bson:"cash_rounding,omitempty" json:"cash_rounding,omitempty"` // Cash taken beyond FinalAmount, negative when less; tax is on FinalAmount alone
 	Notes         string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	EmployeeID    *primitive.ObjectID `bson:"employee_id,omitempty" json:"employee_id,omitempty"` // Employee credited with the sale
+	DeviceID      string              `bson:"device_id,omitempty" json:"device_id,omitempty"`
 	Status        SaleStatus          `bson:"status" json:"status"`
 	Synced        bool                `bson:"synced" json:"synced"`
 	SyncedAt      *time.Time          `bson:"synced_at,omitempty" json:"synced_at,omitempty"`
 	CreatedBy     primitive.ObjectID  `bson:"created_by" json:"created_by"`
 	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
 	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+
+	// Product cost at the time of sale, so margins do not move when cost prices change later
+	UnitCost Money `bson:"unit_cost,omitempty" json:"unit_cost,omitempty"`
+
+	// Units sold beyond the stock on hand, owed to the customer as a Backorder
+	Backordered float64 `bson:"backordered,omitempty" json:"backordered,omitempty"`
+
+	// Set when the goods had already left stock through another record, such as
+	// parts fitted on a repair job, so the sale moves no stock of its own
+	StockTaken bool `bson:"stock_taken,omitempty" json:"stock_taken,omitempty"`
+
+	// Set once the retention run removed the customer's name and phone number
+	AnonymizedAt *time.Time `bson:"anonymized_at,omitempty" json:"anonymized_at,omitempty"`
+
+	// Container deposits charged with the goods, less empties brought back. Taken
+	// on top of FinalAmount and owed back on return, so not revenue.
+	Deposits      []SaleDeposit `bson:"deposits,omitempty" json:"deposits,omitempty"`
+	DepositAmount Money         `bson:"deposit_amount,omitempty" json:"deposit_amount,omitempty"`
+
+	// Who voided the sale and when, for shrinkage reporting
+	VoidedBy *primitive.ObjectID `bson:"voided_by,omitempty" json:"voided_by,omitempty"`
+	VoidedAt *time.Time          `bson:"voided_at,omitempty" json:"voided_at,omitempty"`
+
+	CustomFields CustomFields `bson:"custom_fields,omitempty" json:"custom_fields,omitempty"`
 }
 
 type SaleStatus string
@@ -41,14 +70,32 @@ const (
 type PaymentMethod string
 
 const (
-	PaymentMethodCash   PaymentMethod = "cash"
-	PaymentMethodCard   PaymentMethod = "card"
-	PaymentMethodMobile PaymentMethod = "mobile"
-	PaymentMethodBank   PaymentMethod = "bank"
-	PaymentMethodCredit PaymentMethod = "credit"
-	PaymentMethodOther  PaymentMethod = "other"
+	PaymentMethodCash     PaymentMethod = "cash"
+	PaymentMethodCard     PaymentMethod = "card"
+	PaymentMethodMobile   PaymentMethod = "mobile"
+	PaymentMethodBank     PaymentMethod = "bank"
+	PaymentMethodCredit   PaymentMethod = "credit"
+	PaymentMethodOther    PaymentMethod = "other"
+	PaymentMethodGiftCard PaymentMethod = "gift_card"
+	PaymentMethodLoyalty  PaymentMethod = "loyalty_points"
+	PaymentMethodSplit    PaymentMethod = "split"
 )
 
+// SalePayment is one tender applied to a sale when it is paid with several methods.
+// Amount is always in the shop's base currency; a payment in a foreign currency
+// keeps what was handed over and the rate it was converted at.
+type SalePayment struct {
+	Method    PaymentMethod `bson:"method" json:"method" validate:"required"`
+	Amount    Money         `bson:"amount" json:"amount" validate:"omitempty,gt=0,money"` // Worked out from ForeignAmount when that is given
+	Reference string        `bson:"reference,omitempty" json:"reference,omitempty"`       // Gift card code, transaction ref, etc.
+
+	Currency      string  `bson:"currency,omitempty" json:"currency,omitempty"`
+	ForeignAmount Money   `bson:"foreign_amount,omitempty" json:"foreign_amount,omitempty" validate:"omitempty,gt=0,money"`
+	ExchangeRate  float64 `bson:"exchange_rate,omitempty" json:"exchange_rate,omitempty"` // Set by the server
+	// Change handed back in the base currency when the foreign amount was more than was due
+	Change Money `bson:"change,omitempty" json:"change,omitempty"`
+}
+
 type PaymentStatus string
 
 const (
@@ -60,22 +107,38 @@ const (
 type CreateSaleRequest struct {
 	ProductID     *string       `json:"product_id,omitempty"`
 	CustomerName  string        `json:"customer_name,omitempty"`
-	CustomerPhone string        `json:"customer_phone,omitempty"`
+	CustomerPhone string        `json:"customer_phone,omitempty" validate:"omitempty,phone"`
 	Quantity      float64       `json:"quantity" validate:"required,gt=0"`
-	UnitPrice     float64       `json:"unit_price" validate:"required,gt=0"`
-	Discount      float64       `json:"discount,omitempty"`
-	Tax           float64       `json:"tax,omitempty"`
+	UnitPrice     Money         `json:"unit_price" validate:"required,gt=0,money"`
+	Discount      Money         `json:"discount,omitempty" validate:"omitempty,money"`
+	Tax           Money         `json:"tax,omitempty" validate:"omitempty,money"`
 	PaymentMethod PaymentMethod `json:"payment_method" validate:"required"`
+	Payments      []SalePayment `json:"payments,omitempty" validate:"omitempty,dive"` // Optional split tender, must add up to the final amount
 	Notes         string        `json:"notes,omitempty"`
-	LocalID       string        `json:"local_id,omitempty"` // For offline sync
+	LocalID       string        `json:"local_id,omitempty"`    // For offline sync
+	EmployeeID    *string       `json:"employee_id,omitempty"` // Defaults to whoever is clocked in on the device
+	DeviceID      string        `json:"device_id,omitempty"`
+	// The order's stock reservation, which the sale fulfils; its stock is the
+	// sale's to take. The product defaults to the reservation's.
+	ReservationID *string `json:"reservation_id,omitempty"`
+	// Empties the customer brought back, credited against what is due
+	DepositReturns []DepositReturnItem `json:"deposit_returns,omitempty" validate:"omitempty,max=20,dive"`
+	// Set by modules billing goods they took out of stock themselves; never from clients
+	StockTaken bool `json:"-"`
+
+	SendReceiptSMS bool `json:"send_receipt_sms,omitempty"` // Text the receipt to CustomerPhone
+	// Record the sale as pending until a mobile money payment for it succeeds
+	AwaitPayment bool `json:"await_payment,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty"`
 }
 
 type SaleSummary struct {
 	Date             time.Time `json:"date"`
 	TotalSales       float64   `json:"total_sales"`
-	TotalAmount      float64   `json:"total_amount"`
-	TotalDiscount    float64   `json:"total_discount"`
-	TotalTax         float64   `json:"total_tax"`
+	TotalAmount      Money     `json:"total_amount"`
+	TotalDiscount    Money     `json:"total_discount"`
+	TotalTax         Money     `json:"total_tax"`
 	TransactionCount int       `json:"transaction_count"`
 }
 
@@ -89,15 +152,23 @@ type SaleStats struct {
 
 type SaleRepository interface {
 	Create(sale *Sale) error
-	FindByID(id string) (*Sale, error)
+	// FindInBusiness returns the sale when the shop made it
+	FindInBusiness(ctx context.Context, id string) (*Sale, error)
 	FindByBusinessID(businessID string, filters SaleFilters) ([]Sale, error)
+	Count(businessID string, filters SaleFilters) (int64, error)
 	FindByLocalID(businessID, localID string) (*Sale, error)
 	Update(sale *Sale) error
 	UpdateStatus(id string, status SaleStatus) error
+	// SetPayment records how the sale stands with the customer's payment
+	SetPayment(id primitive.ObjectID, status PaymentStatus, payments []SalePayment) error
+	Void(id string, voidedBy primitive.ObjectID, voidedAt time.Time) error
 	Delete(id string) error
 	GetSummary(businessID string, startDate, endDate time.Time) (*SaleSummary, error)
 	GetStats(businessID string, period string) (*SaleStats, error)
 	GetDailySales(businessID string, date time.Time) ([]Sale, error)
+	ReassignCustomer(businessID, fromPhone, toPhone, toName string) (int64, error)
+	// GetForeignTakings totals the foreign currency taken by completed sales, by currency
+	GetForeignTakings(businessID string, startDate, endDate time.Time) ([]ForeignCurrencyTakings, error)
 }
 
 type SaleFilters struct {
@@ -106,6 +177,67 @@ type SaleFilters struct {
 	Status        *SaleStatus
 	PaymentMethod *PaymentMethod
 	PaymentStatus *PaymentStatus
+	CustomerPhone *string
+	EmployeeID    *string
+	UpdatedSince  *time.Time // Changed at or after this time, including voids and payments
 	Limit         int
-	Offset        int
+	Offset        int // Deprecated: page with After instead
+
+	Sort  ListSort // Newest first when empty
+	After *PageCursor
+}
+
+// SaleSorts are the orders the sales list can be paged in
+var SaleSorts = ListSorts{
+	"-created_at":   {Field: "created_at", Desc: true},
+	"created_at":    {Field: "created_at"},
+	"-final_amount": {Field: "final_amount", Desc: true},
+}
+
+func (s *Sale) SortValue(field string) interface{} {
+	switch field {
+	case "final_amount":
+		return s.FinalAmount
+	default:
+		return s.CreatedAt
+	}
+}
+
+func (s *Sale) SortID() primitive.ObjectID {
+	return s.ID
+}
+
+// SaleExpansions are the related records the sales list can embed with expand,
+// each loaded for the whole page at once rather than per sale
+var SaleExpansions = map[string]bool{"product": true, "customer": true, "employee": true}
+
+// SaleListItem is a sale with the related records asked for, left out when the sale
+// has none or it no longer exists
+type SaleListItem struct {
+	Sale
+	Product  *SaleProduct  `json:"product,omitempty"`
+	Customer *SaleCustomer `json:"customer,omitempty"`
+	Employee *SaleEmployee `json:"employee,omitempty"`
+}
+
+type SaleProduct struct {
+	ID       primitive.ObjectID `json:"id"`
+	Name     string             `json:"name"`
+	SKU      string             `json:"sku,omitempty"`
+	Barcode  string             `json:"barcode,omitempty"`
+	Category string             `json:"category,omitempty"`
+	Unit     string             `json:"unit,omitempty"`
+}
+
+type SaleCustomer struct {
+	ID    primitive.ObjectID `json:"id"`
+	Name  string             `json:"name" pii:"name"`
+	Phone string             `json:"phone,omitempty" pii:"phone"`
+	Tier  CustomerTier       `json:"tier,omitempty"`
+}
+
+type SaleEmployee struct {
+	ID       primitive.ObjectID `json:"id"`
+	Name     string             `json:"name"`
+	Position string             `json:"position,omitempty"`
 }
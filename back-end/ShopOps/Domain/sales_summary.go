@@ -0,0 +1,92 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SalesSummaryRow is the completed sales for one combination of device, category and
+// payment method within a period. Hourly rows (sales_summary_hourly) start on the UTC
+// hour; daily rows (sales_summary_daily) use the business day key, like the P&L rows.
+type SalesSummaryRow struct {
+	ID            primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Day           time.Time          `bson:"day" json:"day"`
+	PeriodStart   time.Time          `bson:"period_start" json:"period_start"`
+	DeviceID      string             `bson:"device_id" json:"device_id"`
+	Category      string             `bson:"category" json:"category"`
+	PaymentMethod PaymentMethod      `bson:"payment_method" json:"payment_method"`
+	Quantity      float64            `bson:"quantity" json:"quantity"`
+	Amount        Money              `bson:"amount" json:"amount"`
+	Discount      Money              `bson:"discount" json:"discount"`
+	Tax           Money              `bson:"tax" json:"tax"`
+	CashRounding  Money              `bson:"cash_rounding,omitempty" json:"cash_rounding,omitempty"` // Cash taken beyond Amount
+	Transactions  int                `bson:"transactions" json:"transactions"`
+	UpdatedAt     time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type SalesSummaryDimension string
+
+const (
+	SalesSummaryByHour          SalesSummaryDimension = "hour"
+	SalesSummaryByDay           SalesSummaryDimension = "day"
+	SalesSummaryByDevice        SalesSummaryDimension = "device"
+	SalesSummaryByCategory      SalesSummaryDimension = "category"
+	SalesSummaryByPaymentMethod SalesSummaryDimension = "payment_method"
+)
+
+func (d SalesSummaryDimension) IsValid() bool {
+	switch d {
+	case SalesSummaryByHour, SalesSummaryByDay, SalesSummaryByDevice, SalesSummaryByCategory, SalesSummaryByPaymentMethod:
+		return true
+	}
+	return false
+}
+
+type SalesBreakdownLine struct {
+	Key          string  `bson:"_id" json:"key"`
+	Quantity     float64 `bson:"quantity" json:"quantity"`
+	Amount       Money   `bson:"amount" json:"amount"`
+	CashRounding Money   `bson:"cash_rounding" json:"cash_rounding,omitempty"` // Daily breakdowns only
+	Transactions int     `bson:"transactions" json:"transactions"`
+}
+
+type SalesBreakdown struct {
+	StartDate string                `json:"start_date"`
+	EndDate   string                `json:"end_date"`
+	By        SalesSummaryDimension `json:"by"`
+	Lines     []SalesBreakdownLine  `json:"lines"`
+}
+
+// HeatmapCell is the sales in one hour of one weekday, in the business timezone
+type HeatmapCell struct {
+	DayOfWeek           int     `bson:"day_of_week" json:"day_of_week"` // 0 = Sunday
+	Day                 string  `bson:"-" json:"day"`
+	Hour                int     `bson:"hour" json:"hour"`
+	Transactions        int     `bson:"transactions" json:"transactions"`
+	Revenue             Money   `bson:"revenue" json:"revenue"`
+	AverageTransactions float64 `bson:"-" json:"average_transactions"` // Per occurrence of the weekday in the range
+	AverageRevenue      Money   `bson:"-" json:"average_revenue"`
+}
+
+type TrafficHeatmap struct {
+	StartDate string        `json:"start_date"`
+	EndDate   string        `json:"end_date"`
+	Timezone  string        `json:"timezone"`
+	DeviceID  string        `json:"device_id,omitempty"`
+	Peak      *HeatmapCell  `json:"peak,omitempty"` // Busiest cell by transactions
+	Cells     []HeatmapCell `json:"cells"`          // All 168 cells, Sunday 00:00 first
+}
+
+type SalesSummaryRepository interface {
+	// RebuildDay replaces the hourly and daily rows for day with fresh aggregates of sales in [start, end)
+	RebuildDay(businessID string, day, start, end time.Time) error
+
+	// Summary totals the hourly rows between start and end
+	Summary(businessID string, start, end time.Time) (*SaleSummary, error)
+	HourlyBreakdown(businessID string, start, end time.Time) ([]SalesBreakdownLine, error)
+	DailyBreakdown(businessID string, fromDay, toDay time.Time, by SalesSummaryDimension) ([]SalesBreakdownLine, error)
+	// Heatmap buckets the hourly rows between start and end by weekday and hour in timezone
+	Heatmap(businessID string, start, end time.Time, timezone, deviceID string) ([]HeatmapCell, error)
+}
@@ -0,0 +1,359 @@
+package Domain
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ScaleSettings is how a shop's label-printing scales encode weighed goods. The
+// scale prints an EAN-13 barcode laid out as prefix, the product's PLU, an
+// optional check digit over the value, the price or weight, and the EAN check
+// digit; the POS reads the product and amount back out of it.
+type ScaleSettings struct {
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Enabled    bool               `bson:"enabled" json:"enabled"`
+	// Barcodes starting with one of these came from a scale. 20-29 are set aside
+	// by GS1 for in-store use.
+	Prefixes        []string          `bson:"prefixes" json:"prefixes"`
+	PrefixDigits    int               `bson:"prefix_digits" json:"prefix_digits"`
+	ItemDigits      int               `bson:"item_digits" json:"item_digits"` // Digits of the PLU
+	ValueCheckDigit bool              `bson:"value_check_digit" json:"value_check_digit"`
+	ValueDigits     int               `bson:"value_digits" json:"value_digits"`
+	ValueType       ScaleValueType    `bson:"value_type" json:"value_type"`
+	ValueDecimals   int               `bson:"value_decimals" json:"value_decimals"` // e.g. 2 for a price in cents, 3 for grams
+	ExportFormat    ScaleExportFormat `bson:"export_format" json:"export_format"`
+	NameLength      int               `bson:"name_length" json:"name_length"` // Characters the scale's label has room for
+	// Further layouts read besides the scales', such as those printed by another
+	// make of scale or by a supplier's packing line; tried in order after it
+	Schemes   []BarcodeScheme `bson:"schemes,omitempty" json:"schemes"`
+	UpdatedAt time.Time       `bson:"updated_at" json:"updated_at"`
+}
+
+// BarcodeScheme is one layout of price-embedded barcode: prefix, item code, an
+// optional check digit over the value, the price or weight, and the check digit.
+// Length 13 is an EAN-13; 12 is a UPC-A, as printed on goods from North America.
+type BarcodeScheme struct {
+	Name            string         `bson:"name" json:"name" validate:"required,max=50"`
+	Length          int            `bson:"length" json:"length"`
+	Prefixes        []string       `bson:"prefixes" json:"prefixes" validate:"required,min=1,max=20"`
+	PrefixDigits    int            `bson:"prefix_digits" json:"prefix_digits"`
+	ItemDigits      int            `bson:"item_digits" json:"item_digits"` // Digits of the PLU
+	ValueCheckDigit bool           `bson:"value_check_digit" json:"value_check_digit"`
+	ValueDigits     int            `bson:"value_digits" json:"value_digits"`
+	ValueType       ScaleValueType `bson:"value_type" json:"value_type"`
+	ValueDecimals   int            `bson:"value_decimals" json:"value_decimals"`
+}
+
+// MaxBarcodeSchemes caps the further layouts a shop reads
+const MaxBarcodeSchemes = 10
+
+// ScaleValueType is what the number after the PLU is
+type ScaleValueType string
+
+const (
+	ScaleValuePrice  ScaleValueType = "price"  // Price to pay; the weight is worked out from the unit price
+	ScaleValueWeight ScaleValueType = "weight" // Weight in kg; the price is worked out at the POS
+)
+
+func (t ScaleValueType) IsValid() bool {
+	return t == ScaleValuePrice || t == ScaleValueWeight
+}
+
+// ScaleExportFormat is the layout of the PLU file loaded into the scales
+type ScaleExportFormat string
+
+const (
+	ScaleExportCSV ScaleExportFormat = "csv" // Comma-separated with a header, for the scale maker's PC software
+	ScaleExportTXT ScaleExportFormat = "txt" // Tab-separated without a header, for scales loaded from a USB stick
+)
+
+func (f ScaleExportFormat) IsValid() bool {
+	return f == ScaleExportCSV || f == ScaleExportTXT
+}
+
+// DefaultScaleSettings is the common 2 + 5 + 5 + 1 price-embedded layout
+var DefaultScaleSettings = ScaleSettings{
+	Prefixes:      []string{"20", "21", "22", "23", "24", "25", "26", "27", "28", "29"},
+	PrefixDigits:  2,
+	ItemDigits:    5,
+	ValueDigits:   5,
+	ValueType:     ScaleValuePrice,
+	ValueDecimals: 2,
+	ExportFormat:  ScaleExportCSV,
+	NameLength:    24,
+}
+
+// Validate checks each layout adds up to its barcode's length and no prefix is
+// read by two of them
+func (s *ScaleSettings) Validate() error {
+	if !s.ExportFormat.IsValid() {
+		return ValidationError("export_format must be csv or txt")
+	}
+	if s.NameLength < 8 || s.NameLength > 100 {
+		return ValidationError("name_length must be between 8 and 100")
+	}
+	if len(s.Schemes) > MaxBarcodeSchemes {
+		return ValidationError(fmt.Sprintf("at most %d further barcode schemes", MaxBarcodeSchemes))
+	}
+
+	seen := make(map[string]string)
+	for i, scheme := range s.schemes() {
+		if i > 0 && scheme.Name == "" {
+			return ValidationError("every further barcode scheme needs a name")
+		}
+		if i > 0 && scheme.Length != 12 && scheme.Length != 13 {
+			return ValidationError(fmt.Sprintf("scheme %s: length must be 12 (UPC-A) or 13 (EAN-13)", scheme.Name))
+		}
+		if err := scheme.validate(); err != nil {
+			if i == 0 {
+				return err
+			}
+			return ValidationError(fmt.Sprintf("scheme %s: %s", scheme.Name, err.Error()))
+		}
+		for _, p := range scheme.Prefixes {
+			key := fmt.Sprintf("%d:%s", scheme.Length, p)
+			if other, ok := seen[key]; ok {
+				return ValidationError(fmt.Sprintf("prefix %s is read by both %s and %s", p, other, scheme.label()))
+			}
+			seen[key] = scheme.label()
+		}
+	}
+	return nil
+}
+
+func (s BarcodeScheme) validate() error {
+	if s.PrefixDigits < 1 || s.PrefixDigits > 3 {
+		return ValidationError("prefix_digits must be between 1 and 3")
+	}
+	if s.ItemDigits < 4 || s.ItemDigits > 6 {
+		return ValidationError("item_digits must be between 4 and 6")
+	}
+	if s.ValueDigits < 4 || s.ValueDigits > 6 {
+		return ValidationError("value_digits must be between 4 and 6")
+	}
+	check := 0
+	if s.ValueCheckDigit {
+		check = 1
+	}
+	if total := s.PrefixDigits + s.ItemDigits + check + s.ValueDigits + 1; total != s.Length {
+		kind := "EAN-13"
+		if s.Length == 12 {
+			kind = "UPC-A"
+		}
+		return ValidationError(fmt.Sprintf("the layout is %d digits; %s barcodes are %d", total, kind, s.Length))
+	}
+	if s.ValueDecimals < 0 || s.ValueDecimals > 3 {
+		return ValidationError("value_decimals must be between 0 and 3")
+	}
+	if !s.ValueType.IsValid() {
+		return ValidationError("value_type must be price or weight")
+	}
+	if len(s.Prefixes) == 0 {
+		return ValidationError("at least one prefix is required")
+	}
+	for _, p := range s.Prefixes {
+		if len(p) != s.PrefixDigits || !isDigits(p) {
+			return ValidationError(fmt.Sprintf("prefix %q must be %d digits", p, s.PrefixDigits))
+		}
+	}
+	return nil
+}
+
+// label names the scheme in messages; the scales' own layout has no name
+func (s BarcodeScheme) label() string {
+	if s.Name == "" {
+		return "the scale layout"
+	}
+	return "scheme " + s.Name
+}
+
+// schemes are the layouts read, the scales' own first
+func (s *ScaleSettings) schemes() []BarcodeScheme {
+	scale := BarcodeScheme{
+		Length:          13,
+		Prefixes:        s.Prefixes,
+		PrefixDigits:    s.PrefixDigits,
+		ItemDigits:      s.ItemDigits,
+		ValueCheckDigit: s.ValueCheckDigit,
+		ValueDigits:     s.ValueDigits,
+		ValueType:       s.ValueType,
+		ValueDecimals:   s.ValueDecimals,
+	}
+	return append([]BarcodeScheme{scale}, s.Schemes...)
+}
+
+// Parse reads a price-embedded barcode by the first of the shop's layouts whose
+// length and prefix it has. ok is false for barcodes none of them read, which are
+// looked up as ordinary product barcodes. The check digit over the value, when
+// the layout has one, is skipped; the barcode's own check digit already guards
+// the read.
+func (s *ScaleSettings) Parse(barcode string) (result *ScaleBarcode, ok bool, err error) {
+	if !s.Enabled || !isDigits(barcode) {
+		return nil, false, nil
+	}
+
+	for _, scheme := range s.schemes() {
+		if result, ok, err := scheme.parse(barcode); ok {
+			return result, ok, err
+		}
+	}
+	return nil, false, nil
+}
+
+func (s BarcodeScheme) parse(barcode string) (*ScaleBarcode, bool, error) {
+	if len(barcode) != s.Length {
+		return nil, false, nil
+	}
+
+	matched := false
+	for _, p := range s.Prefixes {
+		if strings.HasPrefix(barcode, p) {
+			matched = true
+			break
+		}
+	}
+	if !matched {
+		return nil, false, nil
+	}
+
+	// A UPC-A is an EAN-13 with the leading zero left off
+	last := len(barcode) - 1
+	if EAN13CheckDigit(strings.Repeat("0", 13-len(barcode))+barcode[:last]) != barcode[last] {
+		return nil, true, ValidationError("scale barcode check digit does not match; scan it again")
+	}
+
+	pos := s.PrefixDigits
+	plu := NormalizePLU(barcode[pos : pos+s.ItemDigits])
+	pos += s.ItemDigits
+	if s.ValueCheckDigit {
+		pos++
+	}
+	raw, _ := strconv.Atoi(barcode[pos : pos+s.ValueDigits])
+
+	return &ScaleBarcode{
+		PLU:      plu,
+		Value:    float64(raw) / math.Pow10(s.ValueDecimals),
+		RawValue: int64(raw),
+		Decimals: s.ValueDecimals,
+		Weight:   s.ValueType == ScaleValueWeight,
+		Scheme:   s.Name,
+	}, true, nil
+}
+
+// NormalizePLU drops leading zeros, so "00123" from a barcode finds the product
+// saved with PLU "123"
+func NormalizePLU(plu string) string {
+	if trimmed := strings.TrimLeft(plu, "0"); trimmed != "" {
+		return trimmed
+	}
+	return plu
+}
+
+// EAN13CheckDigit is the check digit for the first 12 digits of an EAN-13
+func EAN13CheckDigit(digits string) byte {
+	sum := 0
+	for i := 0; i < 12 && i < len(digits); i++ {
+		d := int(digits[i] - '0')
+		if i%2 == 1 {
+			d *= 3
+		}
+		sum += d
+	}
+	return byte('0' + (10-sum%10)%10)
+}
+
+func isDigits(s string) bool {
+	for _, r := range s {
+		if r < '0' || r > '9' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+type UpdateScaleSettingsRequest struct {
+	Enabled         *bool              `json:"enabled,omitempty"`
+	Prefixes        *[]string          `json:"prefixes,omitempty" validate:"omitempty,min=1,max=20"`
+	PrefixDigits    *int               `json:"prefix_digits,omitempty"`
+	ItemDigits      *int               `json:"item_digits,omitempty"`
+	ValueCheckDigit *bool              `json:"value_check_digit,omitempty"`
+	ValueDigits     *int               `json:"value_digits,omitempty"`
+	ValueType       *ScaleValueType    `json:"value_type,omitempty"`
+	ValueDecimals   *int               `json:"value_decimals,omitempty"`
+	ExportFormat    *ScaleExportFormat `json:"export_format,omitempty"`
+	NameLength      *int               `json:"name_length,omitempty"`
+	Schemes         *[]BarcodeScheme   `json:"schemes,omitempty" validate:"omitempty,max=10,dive"`
+}
+
+// ScaleBarcode is what a scale barcode says, before the PLU is looked up
+type ScaleBarcode struct {
+	PLU      string
+	Value    float64
+	RawValue int64 // Value as printed, before the decimal point is put in
+	Decimals int
+	Weight   bool   // Value is a weight rather than a price
+	Scheme   string // The layout that read it; empty for the scales' own
+}
+
+// Price is the value as an amount, for a barcode with the price in it
+func (b *ScaleBarcode) Price() Money {
+	cents := b.RawValue
+	for d := b.Decimals; d < 2; d++ {
+		cents *= 10
+	}
+	for d := b.Decimals; d > 2; d-- {
+		cents = (cents + 5) / 10
+	}
+	return Money(cents)
+}
+
+// ScanResult is the product a scanned barcode is for. Scale barcodes carry the
+// quantity and amount as well; other barcodes are one unit at the product's price.
+type ScanResult struct {
+	Barcode   string   `json:"barcode"`
+	Product   *Product `json:"product"`
+	FromScale bool     `json:"from_scale"`
+	Quantity  float64  `json:"quantity"` // kg for weighed goods
+	UnitPrice float64  `json:"unit_price"`
+	Amount    float64  `json:"amount"`
+}
+
+// QuickSaleLine is a scanned barcode priced as a sale line, in the fields of a
+// sale, so the POS can ring it up without weighing or keying anything. A barcode
+// with the price in it is charged what the label says: the quantity is worked
+// out at today's price, rounded up to the gram, and the cent or so that adds
+// comes off as a discount.
+type QuickSaleLine struct {
+	Barcode   string  `json:"barcode"`
+	Scheme    string  `json:"scheme,omitempty"` // The barcode scheme that read it; empty for the scales' own
+	FromScale bool    `json:"from_scale"`       // Price-embedded, rather than a product's own barcode
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	Unit      string  `json:"unit,omitempty"`
+	Quantity  float64 `json:"quantity"` // kg for weighed goods
+	UnitPrice Money   `json:"unit_price"`
+	Discount  Money   `json:"discount,omitempty"`
+	Amount    Money   `json:"amount"` // What the line comes to
+}
+
+// QuickSaleRequest rings up a scanned barcode as a sale on its own
+type QuickSaleRequest struct {
+	Barcode       string        `json:"barcode" validate:"required,max=64"`
+	PaymentMethod PaymentMethod `json:"payment_method" validate:"required"`
+	Payments      []SalePayment `json:"payments,omitempty" validate:"omitempty,dive"`
+	CustomerName  string        `json:"customer_name,omitempty"`
+	CustomerPhone string        `json:"customer_phone,omitempty" validate:"omitempty,phone"`
+	LocalID       string        `json:"local_id,omitempty"` // For offline sync
+	EmployeeID    *string       `json:"employee_id,omitempty"`
+	DeviceID      string        `json:"device_id,omitempty"`
+}
+
+type ScaleRepository interface {
+	FindSettings(businessID string) (*ScaleSettings, error)
+	SaveSettings(settings *ScaleSettings) error
+}
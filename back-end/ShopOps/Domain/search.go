@@ -0,0 +1,27 @@
+package Domain
+
+// SearchKind is what a search result refers to
+type SearchKind string
+
+const (
+	SearchKindProduct  SearchKind = "product"
+	SearchKindCustomer SearchKind = "customer"
+)
+
+// SearchDocument is one record as the search index sees it
+type SearchDocument struct {
+	Kind     SearchKind
+	ID       string
+	Title    string   // Product or customer name
+	Subtitle string   // Shown under the title: SKU or phone
+	Terms    []string // Everything else the record can be found by, such as SKU, barcode or phone
+}
+
+// SearchResult is a record matching a search, best matches first
+type SearchResult struct {
+	Kind     SearchKind `json:"kind"`
+	ID       string     `json:"id"`
+	Title    string     `json:"title"`
+	Subtitle string     `json:"subtitle,omitempty"`
+	Score    float64    `json:"score"` // 1 for a prefix match of every word, lower for fuzzier ones
+}
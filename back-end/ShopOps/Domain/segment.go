@@ -0,0 +1,168 @@
+package Domain
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CustomerSegment is a saved customer filter; a customer belongs to it when every rule matches
+type CustomerSegment struct {
+	ID              primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID      primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Name            string             `bson:"name" json:"name"`
+	Description     string             `bson:"description,omitempty" json:"description,omitempty"`
+	Rules           []SegmentRule      `bson:"rules" json:"rules"`
+	ShowOnDashboard bool               `bson:"show_on_dashboard" json:"show_on_dashboard"`
+	MemberCount     int                `bson:"member_count" json:"member_count"` // As of EvaluatedAt
+	EvaluatedAt     *time.Time         `bson:"evaluated_at,omitempty" json:"evaluated_at,omitempty"`
+	CreatedBy       primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt       time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SegmentRule is one condition of the segment DSL, e.g.
+//
+//	{"field": "purchase_count", "op": "gt", "value": 5, "window_days": 90}
+//	{"field": "days_since_last_purchase", "op": "gte", "value": 60}
+//	{"field": "tag", "op": "eq", "tag": "wholesale"}
+type SegmentRule struct {
+	Field      SegmentField    `bson:"field" json:"field"`
+	Op         SegmentOperator `bson:"op" json:"op"`
+	Value      float64         `bson:"value,omitempty" json:"value,omitempty"`
+	Tag        string          `bson:"tag,omitempty" json:"tag,omitempty"`
+	WindowDays int             `bson:"window_days,omitempty" json:"window_days,omitempty"` // purchase_count and total_spent only; 0 means all time
+}
+
+type SegmentField string
+
+const (
+	SegmentFieldPurchaseCount         SegmentField = "purchase_count"
+	SegmentFieldTotalSpent            SegmentField = "total_spent"
+	SegmentFieldDaysSinceLastPurchase SegmentField = "days_since_last_purchase" // Customers who never bought count as infinitely long ago
+	SegmentFieldTag                   SegmentField = "tag"
+)
+
+type SegmentOperator string
+
+const (
+	SegmentOpEq  SegmentOperator = "eq"
+	SegmentOpNe  SegmentOperator = "ne"
+	SegmentOpGt  SegmentOperator = "gt"
+	SegmentOpGte SegmentOperator = "gte"
+	SegmentOpLt  SegmentOperator = "lt"
+	SegmentOpLte SegmentOperator = "lte"
+)
+
+func (r SegmentRule) Validate() error {
+	switch r.Field {
+	case SegmentFieldTag:
+		if r.Op != SegmentOpEq && r.Op != SegmentOpNe {
+			return fmt.Errorf("tag rules support only eq and ne")
+		}
+		if r.Tag == "" {
+			return fmt.Errorf("tag rules need a tag")
+		}
+		return nil
+	case SegmentFieldPurchaseCount, SegmentFieldTotalSpent:
+		if r.WindowDays < 0 {
+			return fmt.Errorf("window_days cannot be negative")
+		}
+	case SegmentFieldDaysSinceLastPurchase:
+		if r.WindowDays != 0 {
+			return fmt.Errorf("window_days does not apply to %s", r.Field)
+		}
+	default:
+		return fmt.Errorf("unknown segment field: %s", r.Field)
+	}
+
+	switch r.Op {
+	case SegmentOpEq, SegmentOpNe, SegmentOpGt, SegmentOpGte, SegmentOpLt, SegmentOpLte:
+	default:
+		return fmt.Errorf("unknown segment operator: %s", r.Op)
+	}
+
+	if r.Value < 0 {
+		return fmt.Errorf("%s value cannot be negative", r.Field)
+	}
+
+	return nil
+}
+
+// Compare applies the rule's operator to a numeric customer metric
+func (r SegmentRule) Compare(metric float64) bool {
+	switch r.Op {
+	case SegmentOpEq:
+		return metric == r.Value
+	case SegmentOpNe:
+		return metric != r.Value
+	case SegmentOpGt:
+		return metric > r.Value
+	case SegmentOpGte:
+		return metric >= r.Value
+	case SegmentOpLt:
+		return metric < r.Value
+	case SegmentOpLte:
+		return metric <= r.Value
+	}
+	return false
+}
+
+type CreateSegmentRequest struct {
+	Name            string        `json:"name" validate:"required"`
+	Description     string        `json:"description,omitempty"`
+	Rules           []SegmentRule `json:"rules" validate:"required,min=1"`
+	ShowOnDashboard bool          `json:"show_on_dashboard,omitempty"`
+}
+
+type UpdateSegmentRequest struct {
+	Name            *string       `json:"name,omitempty"`
+	Description     *string       `json:"description,omitempty"`
+	Rules           []SegmentRule `json:"rules,omitempty"`
+	ShowOnDashboard *bool         `json:"show_on_dashboard,omitempty"`
+}
+
+type PreviewSegmentRequest struct {
+	Rules []SegmentRule `json:"rules" validate:"required,min=1"`
+}
+
+type SegmentMembersResponse struct {
+	Segment   *CustomerSegment `json:"segment,omitempty"` // Empty for previews
+	Customers []Customer       `json:"customers"`
+	Total     int              `json:"total"`
+	Limit     int              `json:"limit"`
+	Offset    int              `json:"offset"`
+}
+
+// SegmentCount is the dashboard view of a segment
+type SegmentCount struct {
+	SegmentID   string     `json:"segment_id"`
+	Name        string     `json:"name"`
+	MemberCount int        `json:"member_count"`
+	EvaluatedAt *time.Time `json:"evaluated_at,omitempty"`
+}
+
+// CustomerPurchaseStats aggregates completed sales for one customer phone
+type CustomerPurchaseStats struct {
+	Phone          string    `bson:"_id" json:"phone" pii:"phone"`
+	Purchases      int       `bson:"purchases" json:"purchases"`
+	TotalSpent     float64   `bson:"total_spent" json:"total_spent"`
+	LastPurchaseAt time.Time `bson:"last_purchase_at" json:"last_purchase_at"`
+}
+
+type SegmentRepository interface {
+	Create(segment *CustomerSegment) error
+	// FindInBusiness returns the segment when the shop defined it
+	FindInBusiness(ctx context.Context, id string) (*CustomerSegment, error)
+	FindByBusinessID(businessID string) ([]CustomerSegment, error)
+	FindAll() ([]CustomerSegment, error)
+	FindForDashboard(businessID string) ([]CustomerSegment, error)
+	Update(segment *CustomerSegment) error
+	UpdateMemberCount(id primitive.ObjectID, count int, evaluatedAt time.Time) error
+	Delete(id string) error
+
+	// PurchaseStats groups completed sales since the given time (all time when nil) by customer phone
+	PurchaseStats(businessID string, since *time.Time) ([]CustomerPurchaseStats, error)
+}
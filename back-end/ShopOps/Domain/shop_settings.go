@@ -0,0 +1,275 @@
+package Domain
+
+import (
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShopSettings are how the shop's tills behave at the counter: what a cashier may
+// do, how receipts come out and how devices lock and sync. The apps apply them;
+// every change is recorded, setting by setting, with who made it.
+type ShopSettings struct {
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+
+	// Sales
+	AllowNegativeStock       bool          `bson:"allow_negative_stock" json:"allow_negative_stock"` // Sell items the till shows as out of stock
+	AllowBackorders          bool          `bson:"allow_backorders" json:"allow_backorders"`         // Sell beyond the stock on hand, owing the rest to the customer
+	MaxDiscountPercent       float64       `bson:"max_discount_percent" json:"max_discount_percent"` // Largest discount a cashier can give without an owner
+	RequireCustomerForCredit bool          `bson:"require_customer_for_credit" json:"require_customer_for_credit"`
+	DefaultPaymentMethod     PaymentMethod `bson:"default_payment_method" json:"default_payment_method"`
+
+	// Receipts
+	AutoPrintReceipt bool `bson:"auto_print_receipt" json:"auto_print_receipt"`
+	ReceiptCopies    int  `bson:"receipt_copies" json:"receipt_copies"`
+	OfferSMSReceipt  bool `bson:"offer_sms_receipt" json:"offer_sms_receipt"`
+
+	// Devices
+	LockAfterMinutes    int `bson:"lock_after_minutes" json:"lock_after_minutes"` // Idle time before the till asks for a PIN; 0 never locks
+	SyncIntervalMinutes int `bson:"sync_interval_minutes" json:"sync_interval_minutes"`
+	OfflineSalesLimit   int `bson:"offline_sales_limit" json:"offline_sales_limit"` // Sales a till keeps before it must sync; 0 for no limit
+
+	// Inventory
+	DefaultMinStock float64 `bson:"default_min_stock" json:"default_min_stock"` // Low-stock level the apps fill in for new products
+
+	// Bookings, in the shop's timezone
+	BookingOpensHour     int `bson:"booking_opens_hour" json:"booking_opens_hour"`         // First hour appointments can start
+	BookingClosesHour    int `bson:"booking_closes_hour" json:"booking_closes_hour"`       // Hour appointments must be over by
+	BookingSlotMinutes   int `bson:"booking_slot_minutes" json:"booking_slot_minutes"`     // Step between the start times offered, and the length of a service without its own
+	BookingReminderHours int `bson:"booking_reminder_hours" json:"booking_reminder_hours"` // How long before an appointment the customer is texted; 0 never
+
+	// Privacy
+	AnonymizeAfterYears int `bson:"anonymize_after_years" json:"anonymize_after_years"` // Years customers' details are kept before the retention run removes them; 0 keeps them for good
+
+	UpdatedAt time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// MaxBookingReminderHours is the furthest ahead of an appointment its reminder can go
+const MaxBookingReminderHours = 72
+
+// DefaultShopSettings are a shop's settings until it saves its own
+var DefaultShopSettings = ShopSettings{
+	MaxDiscountPercent:       100,
+	RequireCustomerForCredit: true,
+	DefaultPaymentMethod:     PaymentMethodCash,
+	AutoPrintReceipt:         true,
+	ReceiptCopies:            1,
+	LockAfterMinutes:         5,
+	SyncIntervalMinutes:      5,
+	OfflineSalesLimit:        500,
+	DefaultMinStock:          5,
+	BookingOpensHour:         9,
+	BookingClosesHour:        18,
+	BookingSlotMinutes:       30,
+	BookingReminderHours:     24,
+}
+
+type SettingType string
+
+const (
+	SettingBoolean SettingType = "boolean"
+	SettingInteger SettingType = "integer"
+	SettingNumber  SettingType = "number"
+	SettingChoice  SettingType = "choice"
+)
+
+// SettingDefinition describes one setting, for validation and for the apps'
+// settings screens
+type SettingDefinition struct {
+	Key         string      `json:"key"`
+	Group       string      `json:"group"`
+	Type        SettingType `json:"type"`
+	Description string      `json:"description"`
+	Default     interface{} `json:"default"`
+	Min         *float64    `json:"min,omitempty"`
+	Max         *float64    `json:"max,omitempty"`
+	Options     []string    `json:"options,omitempty"` // Values of a choice
+}
+
+func settingRange(min, max float64) (*float64, *float64) {
+	return &min, &max
+}
+
+// ShopSettingDefinitions lists every setting in the order the apps show them
+var ShopSettingDefinitions = func() []SettingDefinition {
+	defs := []SettingDefinition{
+		{Key: "allow_negative_stock", Group: "sales", Type: SettingBoolean, Description: "Sell items the till shows as out of stock"},
+		{Key: "allow_backorders", Group: "sales", Type: SettingBoolean, Description: "Sell more than is in stock and set the rest aside for the customer as it comes in"},
+		{Key: "max_discount_percent", Group: "sales", Type: SettingNumber, Description: "Largest discount a cashier can give without an owner"},
+		{Key: "require_customer_for_credit", Group: "sales", Type: SettingBoolean, Description: "A sale on credit must name the customer"},
+		{Key: "default_payment_method", Group: "sales", Type: SettingChoice, Description: "Payment method the till starts a sale with",
+			Options: []string{string(PaymentMethodCash), string(PaymentMethodCard), string(PaymentMethodMobile), string(PaymentMethodBank)}},
+		{Key: "auto_print_receipt", Group: "receipts", Type: SettingBoolean, Description: "Print the receipt as soon as a sale is paid"},
+		{Key: "receipt_copies", Group: "receipts", Type: SettingInteger, Description: "Copies printed of each receipt"},
+		{Key: "offer_sms_receipt", Group: "receipts", Type: SettingBoolean, Description: "Offer to text the receipt to the customer"},
+		{Key: "lock_after_minutes", Group: "devices", Type: SettingInteger, Description: "Idle minutes before the till asks for a PIN; 0 never locks"},
+		{Key: "sync_interval_minutes", Group: "devices", Type: SettingInteger, Description: "Minutes between syncs while online"},
+		{Key: "offline_sales_limit", Group: "devices", Type: SettingInteger, Description: "Sales a till keeps offline before it must sync; 0 for no limit"},
+		{Key: "default_min_stock", Group: "inventory", Type: SettingNumber, Description: "Low-stock level the apps fill in for new products"},
+		{Key: "booking_opens_hour", Group: "bookings", Type: SettingInteger, Description: "First hour appointments can start"},
+		{Key: "booking_closes_hour", Group: "bookings", Type: SettingInteger, Description: "Hour appointments must be over by"},
+		{Key: "booking_slot_minutes", Group: "bookings", Type: SettingInteger, Description: "Minutes between the start times offered for appointments"},
+		{Key: "booking_reminder_hours", Group: "bookings", Type: SettingInteger, Description: "Hours before an appointment the customer is texted a reminder; 0 never"},
+		{Key: "anonymize_after_years", Group: "privacy", Type: SettingInteger, Description: "Years customers' names and phone numbers are kept on sales and records no longer in use; 0 keeps them for good"},
+	}
+	ranges := map[string][2]float64{
+		"max_discount_percent":   {0, 100},
+		"receipt_copies":         {1, 3},
+		"lock_after_minutes":     {0, 120},
+		"sync_interval_minutes":  {1, 60},
+		"offline_sales_limit":    {0, 10000},
+		"default_min_stock":      {0, 100000},
+		"booking_opens_hour":     {0, 23},
+		"booking_closes_hour":    {1, 24},
+		"booking_slot_minutes":   {5, 240},
+		"booking_reminder_hours": {0, MaxBookingReminderHours},
+		"anonymize_after_years":  {0, 50},
+	}
+	defaults := DefaultShopSettings.Values()
+	for i := range defs {
+		defs[i].Default = defaults[defs[i].Key]
+		if r, ok := ranges[defs[i].Key]; ok {
+			defs[i].Min, defs[i].Max = settingRange(r[0], r[1])
+		}
+	}
+	return defs
+}()
+
+// Values returns the settings by key
+func (s *ShopSettings) Values() map[string]interface{} {
+	return map[string]interface{}{
+		"allow_negative_stock":        s.AllowNegativeStock,
+		"allow_backorders":            s.AllowBackorders,
+		"max_discount_percent":        s.MaxDiscountPercent,
+		"require_customer_for_credit": s.RequireCustomerForCredit,
+		"default_payment_method":      string(s.DefaultPaymentMethod),
+		"auto_print_receipt":          s.AutoPrintReceipt,
+		"receipt_copies":              s.ReceiptCopies,
+		"offer_sms_receipt":           s.OfferSMSReceipt,
+		"lock_after_minutes":          s.LockAfterMinutes,
+		"sync_interval_minutes":       s.SyncIntervalMinutes,
+		"offline_sales_limit":         s.OfflineSalesLimit,
+		"default_min_stock":           s.DefaultMinStock,
+		"booking_opens_hour":          s.BookingOpensHour,
+		"booking_closes_hour":         s.BookingClosesHour,
+		"booking_slot_minutes":        s.BookingSlotMinutes,
+		"booking_reminder_hours":      s.BookingReminderHours,
+		"anonymize_after_years":       s.AnonymizeAfterYears,
+	}
+}
+
+// Validate checks every setting against its definition
+func (s *ShopSettings) Validate() error {
+	values := s.Values()
+	for _, def := range ShopSettingDefinitions {
+		switch value := values[def.Key].(type) {
+		case int:
+			if err := def.checkRange(float64(value)); err != nil {
+				return err
+			}
+		case float64:
+			if err := def.checkRange(value); err != nil {
+				return err
+			}
+		case string:
+			if !def.allows(value) {
+				return ValidationError(fmt.Sprintf("%s must be one of %v", def.Key, def.Options))
+			}
+		}
+	}
+	if s.BookingOpensHour >= s.BookingClosesHour {
+		return ValidationError("booking_opens_hour must be before booking_closes_hour")
+	}
+	return nil
+}
+
+func (d SettingDefinition) checkRange(value float64) error {
+	if (d.Min != nil && value < *d.Min) || (d.Max != nil && value > *d.Max) {
+		return ValidationError(fmt.Sprintf("%s must be between %g and %g", d.Key, *d.Min, *d.Max))
+	}
+	return nil
+}
+
+func (d SettingDefinition) allows(value string) bool {
+	for _, option := range d.Options {
+		if option == value {
+			return true
+		}
+	}
+	return false
+}
+
+// Changes lists the settings that differ from before, in definition order
+func (s *ShopSettings) Changes(before *ShopSettings) []SettingChange {
+	old, current := before.Values(), s.Values()
+	var changes []SettingChange
+	for _, def := range ShopSettingDefinitions {
+		if old[def.Key] != current[def.Key] {
+			changes = append(changes, SettingChange{
+				BusinessID: s.BusinessID,
+				Key:        def.Key,
+				OldValue:   old[def.Key],
+				NewValue:   current[def.Key],
+			})
+		}
+	}
+	return changes
+}
+
+// UpdateShopSettingsRequest changes the settings given and leaves the rest
+type UpdateShopSettingsRequest struct {
+	AllowNegativeStock       *bool          `json:"allow_negative_stock,omitempty"`
+	AllowBackorders          *bool          `json:"allow_backorders,omitempty"`
+	MaxDiscountPercent       *float64       `json:"max_discount_percent,omitempty"`
+	RequireCustomerForCredit *bool          `json:"require_customer_for_credit,omitempty"`
+	DefaultPaymentMethod     *PaymentMethod `json:"default_payment_method,omitempty"`
+	AutoPrintReceipt         *bool          `json:"auto_print_receipt,omitempty"`
+	ReceiptCopies            *int           `json:"receipt_copies,omitempty"`
+	OfferSMSReceipt          *bool          `json:"offer_sms_receipt,omitempty"`
+	LockAfterMinutes         *int           `json:"lock_after_minutes,omitempty"`
+	SyncIntervalMinutes      *int           `json:"sync_interval_minutes,omitempty"`
+	OfflineSalesLimit        *int           `json:"offline_sales_limit,omitempty"`
+	DefaultMinStock          *float64       `json:"default_min_stock,omitempty"`
+	BookingOpensHour         *int           `json:"booking_opens_hour,omitempty"`
+	BookingClosesHour        *int           `json:"booking_closes_hour,omitempty"`
+	BookingSlotMinutes       *int           `json:"booking_slot_minutes,omitempty"`
+	BookingReminderHours     *int           `json:"booking_reminder_hours,omitempty"`
+	AnonymizeAfterYears      *int           `json:"anonymize_after_years,omitempty"`
+}
+
+// SettingChange is one setting changed, with the value before and after
+type SettingChange struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Key        string             `bson:"key" json:"key"`
+	OldValue   interface{}        `bson:"old_value" json:"old_value"`
+	NewValue   interface{}        `bson:"new_value" json:"new_value"`
+	ChangedBy  primitive.ObjectID `bson:"changed_by" json:"changed_by"`
+	ChangedAt  time.Time          `bson:"changed_at" json:"changed_at"`
+}
+
+// SettingsBundle is every setting a till needs, in one download. Devices send back
+// the ETag it came with and get a 304 until something in it changes.
+type SettingsBundle struct {
+	BusinessID    primitive.ObjectID `json:"business_id"`
+	Currency      string             `json:"currency"`
+	Timezone      string             `json:"timezone"`
+	DayCutoffHour int                `json:"day_cutoff_hour"`
+	Language      Language           `json:"language,omitempty"`
+	Shop          ShopSettings       `json:"shop"`
+	Catalog       CatalogSettings    `json:"catalog"`
+	Scale         ScaleSettings      `json:"scale"`
+	Currencies    CurrencySettings   `json:"currencies"`
+	Receipt       ReceiptTemplate    `json:"receipt"` // The default template
+}
+
+type ShopSettingsRepository interface {
+	FindSettings(businessID string) (*ShopSettings, error)
+	SaveSettings(settings *ShopSettings) error
+	CreateChanges(changes []SettingChange) error
+	// FindChanges returns the newest changes first, of one setting when key is set
+	FindChanges(businessID, key string, limit int) ([]SettingChange, error)
+	// FindAnonymizing returns the settings of the shops with AnonymizeAfterYears set
+	FindAnonymizing() ([]ShopSettings, error)
+}
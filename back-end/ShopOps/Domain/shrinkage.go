@@ -0,0 +1,120 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// DrawerEvent is a cash drawer opened without a sale being rung up
+type DrawerEvent struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Type       DrawerEventType     `bson:"type" json:"type"`
+	EmployeeID *primitive.ObjectID `bson:"employee_id,omitempty" json:"employee_id,omitempty"`
+	DeviceID   string              `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	Reason     string              `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy  primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type DrawerEventType string
+
+const (
+	DrawerEventNoSale DrawerEventType = "no_sale"
+)
+
+type RecordDrawerEventRequest struct {
+	EmployeeID *string    `json:"employee_id,omitempty"` // Defaults to the employee linked to the user
+	DeviceID   string     `json:"device_id,omitempty"`
+	Reason     string     `json:"reason,omitempty"`
+	OpenedAt   *time.Time `json:"opened_at,omitempty"` // When recorded offline; defaults to now
+}
+
+type DrawerEventFilters struct {
+	StartDate  *time.Time
+	EndDate    *time.Time
+	EmployeeID *string
+	DeviceID   *string
+	Limit      int
+	Offset     int
+}
+
+type ShrinkageGroupBy string
+
+const (
+	ShrinkageByEmployee ShrinkageGroupBy = "employee"
+	ShrinkageByDevice   ShrinkageGroupBy = "device"
+)
+
+// ShrinkageActivity is one actor's loss signals on one business day. Key is an employee
+// ID, or the user ID when no employee is known, or a device ID when grouping by device.
+type ShrinkageActivity struct {
+	Key          string  `bson:"key" json:"key"`
+	Day          string  `bson:"day" json:"day"` // YYYY-MM-DD in the business timezone
+	Sales        int     `bson:"sales" json:"sales"`
+	SalesAmount  float64 `bson:"sales_amount" json:"sales_amount"`
+	Voids        int     `bson:"voids" json:"voids"`
+	VoidAmount   float64 `bson:"void_amount" json:"void_amount"`
+	NoSales      int     `bson:"no_sales" json:"no_sales"`
+	LossQuantity float64 `bson:"loss_quantity" json:"loss_quantity"` // Damage, theft and stock counted short
+	LossValue    float64 `bson:"loss_value" json:"loss_value"`       // At cost
+}
+
+type ShrinkageLine struct {
+	Key          string   `json:"key"`
+	Name         string   `json:"name"`
+	Sales        int      `json:"sales"`
+	SalesAmount  float64  `json:"sales_amount"`
+	Voids        int      `json:"voids"`
+	VoidAmount   float64  `json:"void_amount"`
+	VoidRate     float64  `json:"void_rate"`    // Percent of rung-up sales that were voided
+	NoSales      int      `json:"no_sales"`     // Drawer opens without a sale
+	NoSaleRate   float64  `json:"no_sale_rate"` // Per 100 sales
+	LossQuantity float64  `json:"loss_quantity"`
+	LossValue    float64  `json:"loss_value"`
+	Anomalies    []string `json:"anomalies,omitempty"` // Metrics flagged for this line
+}
+
+// ShrinkageAnomaly is a metric well above the average of the other lines
+type ShrinkageAnomaly struct {
+	Key     string  `json:"key"`
+	Name    string  `json:"name"`
+	Metric  string  `json:"metric"` // void_rate, no_sale_rate or loss_value
+	Value   float64 `json:"value"`
+	Average float64 `json:"average"`
+	Ratio   float64 `json:"ratio"` // Value / average
+}
+
+type ShrinkageTrendPoint struct {
+	Period     string  `json:"period"` // Week starting Monday, YYYY-MM-DD
+	Sales      int     `json:"sales"`
+	Voids      int     `json:"voids"`
+	VoidAmount float64 `json:"void_amount"`
+	VoidRate   float64 `json:"void_rate"`
+	NoSales    int     `json:"no_sales"`
+	LossValue  float64 `json:"loss_value"`
+}
+
+type ShrinkageReport struct {
+	StartDate string                `json:"start_date"`
+	EndDate   string                `json:"end_date"`
+	GroupBy   ShrinkageGroupBy      `json:"group_by"`
+	Threshold float64               `json:"threshold"` // Ratio to the average that counts as an anomaly
+	Totals    ShrinkageLine         `json:"totals"`
+	Lines     []ShrinkageLine       `json:"lines"`
+	Anomalies []ShrinkageAnomaly    `json:"anomalies"`
+	Trend     []ShrinkageTrendPoint `json:"trend"`
+}
+
+type ShrinkageRepository interface {
+	CreateDrawerEvent(event *DrawerEvent) error
+	FindDrawerEvents(businessID string, filters DrawerEventFilters) ([]DrawerEvent, error)
+
+	// Daily activity between start and end, bucketed by business day in timezone,
+	// each starting cutoffHour hours after midnight
+	SaleActivity(businessID string, start, end time.Time, timezone string, cutoffHour int, by ShrinkageGroupBy) ([]ShrinkageActivity, error)
+	DrawerActivity(businessID string, start, end time.Time, timezone string, cutoffHour int, by ShrinkageGroupBy) ([]ShrinkageActivity, error)
+	// StockLossActivity is keyed by the user who recorded the movement; movements have no device
+	StockLossActivity(businessID string, start, end time.Time, timezone string, cutoffHour int) ([]ShrinkageActivity, error)
+}
@@ -0,0 +1,112 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SMSMessage is the delivery log for every SMS the system sends or skips
+type SMSMessage struct {
+	ID                primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID        primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	CampaignID        *primitive.ObjectID `bson:"campaign_id,omitempty" json:"campaign_id,omitempty"`
+	SaleID            *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	Phone             string              `bson:"phone" json:"phone" pii:"phone"`
+	Body              string              `bson:"body" json:"body"`
+	Type              SMSType             `bson:"type" json:"type"`
+	Status            SMSStatus           `bson:"status" json:"status"`
+	Provider          string              `bson:"provider,omitempty" json:"provider,omitempty"`
+	ProviderMessageID string              `bson:"provider_message_id,omitempty" json:"provider_message_id,omitempty"`
+	Error             string              `bson:"error,omitempty" json:"error,omitempty"` // Failure or skip reason
+	CreatedAt         time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type SMSType string
+
+const (
+	SMSTypeReceipt     SMSType = "receipt"
+	SMSTypePromotional SMSType = "promotional"
+	SMSTypeAlert       SMSType = "alert"  // Sent to the business's own phone
+	SMSTypeNotice      SMSType = "notice" // News of a customer's order, e.g. a backorder ready to collect
+)
+
+type SMSStatus string
+
+const (
+	SMSStatusSent    SMSStatus = "sent"
+	SMSStatusFailed  SMSStatus = "failed"
+	SMSStatusSkipped SMSStatus = "skipped" // Blacklisted, not opted in or no phone
+)
+
+// SMSCampaign is one bulk promotional send
+type SMSCampaign struct {
+	ID          primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Message     string              `bson:"message" json:"message"`
+	Tag         string              `bson:"tag,omitempty" json:"tag,omitempty"` // Customer tag the audience was limited to
+	SegmentID   *primitive.ObjectID `bson:"segment_id,omitempty" json:"segment_id,omitempty"`
+	Status      SMSCampaignStatus   `bson:"status" json:"status"`
+	Total       int                 `bson:"total" json:"total"`
+	Sent        int                 `bson:"sent" json:"sent"`
+	Failed      int                 `bson:"failed" json:"failed"`
+	Skipped     int                 `bson:"skipped" json:"skipped"`
+	CreatedBy   primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	Recipients  []string            `bson:"recipients" json:"-"` // Audience fixed at send time, so a resumed campaign texts the same numbers
+	CreatedAt   time.Time           `bson:"created_at" json:"created_at"`
+	CompletedAt *time.Time          `bson:"completed_at,omitempty" json:"completed_at,omitempty"`
+}
+
+type SMSCampaignStatus string
+
+const (
+	SMSCampaignStatusSending   SMSCampaignStatus = "sending"
+	SMSCampaignStatusCompleted SMSCampaignStatus = "completed"
+)
+
+type BulkSMSRequest struct {
+	Message   string   `json:"message" validate:"required"`
+	Tag       *string  `json:"tag,omitempty"`                                    // Only customers with this tag
+	SegmentID *string  `json:"segment_id,omitempty"`                             // Only members of this customer segment
+	Phones    []string `json:"phones,omitempty" validate:"omitempty,dive,phone"` // Explicit audience; still limited to opted-in customers
+}
+
+// SMSBlacklistEntry is a number that must never receive SMS from the business
+type SMSBlacklistEntry struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Phone      string             `bson:"phone" json:"phone" pii:"phone"`
+	Reason     string             `bson:"reason,omitempty" json:"reason,omitempty"`
+	CreatedBy  primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type AddSMSBlacklistRequest struct {
+	Phone  string `json:"phone" validate:"required,phone"`
+	Reason string `json:"reason,omitempty"`
+}
+
+type SMSMessageFilters struct {
+	Type       *SMSType
+	Status     *SMSStatus
+	Phone      *string
+	CampaignID *string
+	Limit      int
+	Offset     int
+}
+
+type SMSRepository interface {
+	LogMessage(message *SMSMessage) error
+	FindMessages(businessID string, filters SMSMessageFilters) ([]SMSMessage, error)
+
+	CreateCampaign(campaign *SMSCampaign) error
+	FindCampaignByID(id string) (*SMSCampaign, error)
+	FindCampaigns(businessID string, limit int) ([]SMSCampaign, error)
+	FindActiveCampaign(businessID string) (*SMSCampaign, error)
+	UpdateCampaign(campaign *SMSCampaign) error
+
+	AddToBlacklist(entry *SMSBlacklistEntry) error
+	RemoveFromBlacklist(businessID, phone string) error
+	IsBlacklisted(businessID, phone string) (bool, error)
+	GetBlacklist(businessID string) ([]SMSBlacklistEntry, error)
+}
@@ -0,0 +1,51 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockMovementSorts are the orders the stock ledger can be paged in
+var StockMovementSorts = ListSorts{
+	"-created_at": {Field: "created_at", Desc: true},
+	"created_at":  {Field: "created_at"},
+}
+
+func (m *StockMovement) SortValue(field string) interface{} {
+	return m.CreatedAt
+}
+
+func (m *StockMovement) SortID() primitive.ObjectID {
+	return m.ID
+}
+
+type StockLedgerFilters struct {
+	ProductID     *string
+	Types         []MovementType
+	ReferenceType *string // e.g. sale, purchase_order
+	ReferenceID   *string
+	StartDate     *time.Time
+	EndDate       *time.Time // Exclusive
+	Limit         int
+
+	Sort  ListSort // Newest first when empty
+	After *PageCursor
+}
+
+// StockBalance is a product's stock as the ledger had it at a point in time
+type StockBalance struct {
+	ProductID primitive.ObjectID `bson:"_id" json:"product_id"`
+	Quantity  float64            `bson:"quantity" json:"quantity"`
+	UnitCost  Money              `bson:"unit_cost,omitempty" json:"unit_cost,omitempty"` // As of its last movement; zero on movements recorded before costs were
+	At        time.Time          `bson:"at" json:"at"`                                   // Its last movement
+}
+
+// StockLedgerRepository reads the stock ledger, which the product repository
+// writes as it changes stock. It has no way to change an entry.
+type StockLedgerRepository interface {
+	// FindByBusiness pages through the shop's movements, including archived ones
+	FindByBusiness(businessID string, filters StockLedgerFilters) ([]StockMovement, error)
+	// BalancesAsOf returns the stock of each product with stock just before at
+	BalancesAsOf(businessID string, at time.Time) ([]StockBalance, error)
+}
@@ -0,0 +1,115 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockReservation holds a product's stock for an accepted online or phone order
+// until the order is sold, cancelled or the hold lapses. Active holds that have not
+// lapsed come off the stock available to sell, so the POS cannot sell the last
+// unit out from under the order.
+type StockReservation struct {
+	ID            primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	ProductID     primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	Quantity      float64             `bson:"quantity" json:"quantity"`
+	Channel       ReservationChannel  `bson:"channel" json:"channel"`
+	Reference     string              `bson:"reference,omitempty" json:"reference,omitempty"` // The order's number, as the customer knows it
+	CustomerName  string              `bson:"customer_name,omitempty" json:"customer_name,omitempty"`
+	CustomerPhone string              `bson:"customer_phone,omitempty" json:"customer_phone,omitempty"`
+	Note          string              `bson:"note,omitempty" json:"note,omitempty"`
+	Status        ReservationStatus   `bson:"status" json:"status"`
+	ExpiresAt     time.Time           `bson:"expires_at" json:"expires_at"`
+	SaleID        *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"` // The sale that fulfilled it
+	ClosedBy      *primitive.ObjectID `bson:"closed_by,omitempty" json:"closed_by,omitempty"`
+	ClosedAt      *time.Time          `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+	CloseReason   string              `bson:"close_reason,omitempty" json:"close_reason,omitempty"`
+	CreatedBy     primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt     time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type ReservationChannel string
+
+const (
+	ReservationChannelOnline ReservationChannel = "online"
+	ReservationChannelPhone  ReservationChannel = "phone"
+)
+
+type ReservationStatus string
+
+const (
+	ReservationActive    ReservationStatus = "active"
+	ReservationFulfilled ReservationStatus = "fulfilled" // Sold at the POS
+	ReservationReleased  ReservationStatus = "released"  // The order was cancelled
+	ReservationExpired   ReservationStatus = "expired"   // Lapsed before it was sold
+)
+
+const (
+	DefaultReservationTTL = 24 * time.Hour
+	MaxReservationTTL     = 14 * 24 * time.Hour
+)
+
+// Holds reports whether the reservation still holds stock at now
+func (r *StockReservation) Holds(now time.Time) bool {
+	return r.Status == ReservationActive && r.ExpiresAt.After(now)
+}
+
+type CreateStockReservationRequest struct {
+	ProductID     string             `json:"product_id" validate:"required"`
+	Quantity      float64            `json:"quantity" validate:"required,gt=0"`
+	Channel       ReservationChannel `json:"channel" validate:"required,oneof=online phone"`
+	Reference     string             `json:"reference,omitempty" validate:"max=100"`
+	CustomerName  string             `json:"customer_name,omitempty" validate:"max=100"`
+	CustomerPhone string             `json:"customer_phone,omitempty" validate:"omitempty,phone"`
+	Note          string             `json:"note,omitempty" validate:"max=500"`
+	TTLMinutes    int                `json:"ttl_minutes,omitempty" validate:"omitempty,min=5,max=20160"` // 24 hours when left out, at most 14 days
+}
+
+type ReleaseStockReservationRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=200"`
+}
+
+type StockReservationFilters struct {
+	ProductID *string
+	Status    *ReservationStatus
+	Limit     int
+	Offset    int
+}
+
+// ProductAvailability is a product's stock less what is held for orders
+type ProductAvailability struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	SKU       string  `json:"sku,omitempty"`
+	Stock     float64 `json:"stock"`
+	Reserved  float64 `json:"reserved"`
+	Available float64 `json:"available"`
+}
+
+// BranchAvailability is one branch's availability of a product, matched across
+// branches by SKU, for choosing which branch an order is taken from
+type BranchAvailability struct {
+	BusinessID string `json:"business_id"`
+	Name       string `json:"name"`
+	City       string `json:"city,omitempty"`
+	ProductAvailability
+}
+
+type StockReservationRepository interface {
+	Create(reservation *StockReservation) error
+	// FindInBusiness returns the reservation when it holds the shop's stock
+	FindInBusiness(ctx context.Context, id string) (*StockReservation, error)
+	FindByBusiness(businessID string, filters StockReservationFilters) ([]StockReservation, error)
+	// ReservedQuantities adds up the holding reservations of each of the shop's
+	// products, or of those in productIDs when given, leaving out the reservation except
+	ReservedQuantities(businessID string, productIDs []primitive.ObjectID, except *primitive.ObjectID) (map[primitive.ObjectID]float64, error)
+	// Close saves the reservation's new status if it is still active; false when
+	// something else closed it first
+	Close(reservation *StockReservation) (bool, error)
+	// ExpireDue marks active reservations that have lapsed by now expired
+	ExpireDue(now time.Time) (int64, error)
+}
@@ -0,0 +1,124 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StockTransfer moves stock between two branches of one owner. The branch short
+// of stock requests it; the other approves what it can spare and ships it, taking
+// it out of its stock, and it is in transit until the requesting branch confirms
+// what arrived, which is added to its stock at the cost it left at. Products are
+// matched between the branches by SKU.
+type StockTransfer struct {
+	ID             primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Number         string              `bson:"number" json:"number"`                     // TR-00001, counted by the requesting branch
+	FromBusinessID primitive.ObjectID  `bson:"from_business_id" json:"from_business_id"` // The branch supplying the stock
+	ToBusinessID   primitive.ObjectID  `bson:"to_business_id" json:"to_business_id"`     // The branch that asked for it
+	Status         StockTransferStatus `bson:"status" json:"status"`
+	Lines          []StockTransferLine `bson:"lines" json:"lines"`
+	Notes          string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	Reason         string              `bson:"reason,omitempty" json:"reason,omitempty"` // Why it was rejected or cancelled
+	RequestedBy    primitive.ObjectID  `bson:"requested_by" json:"requested_by"`
+	ApprovedBy     *primitive.ObjectID `bson:"approved_by,omitempty" json:"approved_by,omitempty"`
+	ApprovedAt     *time.Time          `bson:"approved_at,omitempty" json:"approved_at,omitempty"`
+	ShippedBy      *primitive.ObjectID `bson:"shipped_by,omitempty" json:"shipped_by,omitempty"`
+	ShippedAt      *time.Time          `bson:"shipped_at,omitempty" json:"shipped_at,omitempty"`
+	ReceivedBy     *primitive.ObjectID `bson:"received_by,omitempty" json:"received_by,omitempty"`
+	ReceivedAt     *time.Time          `bson:"received_at,omitempty" json:"received_at,omitempty"`
+	ClosedBy       *primitive.ObjectID `bson:"closed_by,omitempty" json:"closed_by,omitempty"` // Who rejected or cancelled it
+	CreatedAt      time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt      time.Time           `bson:"updated_at" json:"updated_at"`
+	Version        int64               `bson:"version" json:"version"` // Incremented by every update
+}
+
+type StockTransferStatus string
+
+const (
+	StockTransferRequested StockTransferStatus = "requested"
+	StockTransferApproved  StockTransferStatus = "approved"
+	StockTransferInTransit StockTransferStatus = "in_transit" // Out of the supplying branch's stock, not yet in the other's
+	StockTransferReceived  StockTransferStatus = "received"
+	StockTransferRejected  StockTransferStatus = "rejected"
+	StockTransferCancelled StockTransferStatus = "cancelled"
+)
+
+// StockTransferLine is one product asked for, and how much of it was approved,
+// shipped and received
+type StockTransferLine struct {
+	ID            primitive.ObjectID  `bson:"_id" json:"id"`
+	ProductID     primitive.ObjectID  `bson:"product_id" json:"product_id"`                               // The requesting branch's
+	FromProductID *primitive.ObjectID `bson:"from_product_id,omitempty" json:"from_product_id,omitempty"` // The supplying branch's, matched on approval
+	SKU           string              `bson:"sku" json:"sku"`
+	Name          string              `bson:"name" json:"name"`
+	Requested     float64             `bson:"requested" json:"requested"`
+	Approved      float64             `bson:"approved" json:"approved"`
+	Shipped       float64             `bson:"shipped" json:"shipped"`
+	Received      float64             `bson:"received" json:"received"`
+	Discrepancy   float64             `bson:"discrepancy" json:"discrepancy"`                 // Received less shipped: negative when some went missing
+	UnitCost      Money               `bson:"unit_cost,omitempty" json:"unit_cost,omitempty"` // The supplying branch's cost price when shipped
+	Note          string              `bson:"note,omitempty" json:"note,omitempty"`           // On receipt, e.g. two crates broken
+}
+
+// CreateStockTransferRequest asks another of the owner's branches for stock
+type CreateStockTransferRequest struct {
+	FromBusinessID string                     `json:"from_business_id" validate:"required"`
+	Lines          []StockTransferRequestLine `json:"lines" validate:"required,min=1,max=100,dive"`
+	Notes          string                     `json:"notes,omitempty" validate:"max=500"`
+}
+
+type StockTransferRequestLine struct {
+	ProductID string  `json:"product_id" validate:"required"` // The requesting branch's
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+}
+
+// ApproveStockTransferRequest approves what the supplying branch can spare of
+// each line; lines left out are approved in full, and 0 approves none of a line
+type ApproveStockTransferRequest struct {
+	Lines []StockTransferLineQuantity `json:"lines,omitempty" validate:"omitempty,max=100,dive"`
+}
+
+// ReceiveStockTransferRequest confirms what arrived of each line; lines left out
+// arrived as shipped
+type ReceiveStockTransferRequest struct {
+	Lines []StockTransferLineQuantity `json:"lines,omitempty" validate:"omitempty,max=100,dive"`
+}
+
+type StockTransferLineQuantity struct {
+	LineID   string  `json:"line_id" validate:"required"`
+	Quantity float64 `json:"quantity" validate:"gte=0"`
+	Note     string  `json:"note,omitempty" validate:"max=200"`
+}
+
+// CloseStockTransferRequest rejects or cancels a transfer not yet shipped
+type CloseStockTransferRequest struct {
+	Reason string `json:"reason,omitempty" validate:"max=500"`
+}
+
+type StockTransferDirection string
+
+const (
+	StockTransferIncoming StockTransferDirection = "incoming" // Asked for by the branch
+	StockTransferOutgoing StockTransferDirection = "outgoing" // Asked of the branch
+)
+
+type StockTransferFilters struct {
+	Direction *StockTransferDirection
+	Status    *StockTransferStatus
+	Limit     int
+	Offset    int
+}
+
+type StockTransferRepository interface {
+	Create(transfer *StockTransfer) error
+	// FindInBusiness returns the transfer when the shop is either branch
+	FindInBusiness(ctx context.Context, id string) (*StockTransfer, error)
+	// FindByBusiness returns the transfers the branch asked for or was asked for
+	FindByBusiness(businessID string, filters StockTransferFilters) ([]StockTransfer, error)
+	// CountRequested counts the transfers the branch has asked for
+	CountRequested(businessID string) (int64, error)
+	// Update saves the transfer if it is still at transfer.Version, and increments it
+	Update(transfer *StockTransfer) error
+}
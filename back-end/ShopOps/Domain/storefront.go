@@ -0,0 +1,209 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// StorefrontConnection links a shop to its online store. On every sync paid
+// online orders are pulled in as sales, then the store's stock and, if asked,
+// its catalog are brought in line with the shop's.
+type StorefrontConnection struct {
+	ID         primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Platform   StorefrontPlatform `bson:"platform" json:"platform"`
+	Name       string             `bson:"name" json:"name"`
+	// https://shop.example.com for WooCommerce, example.myshopify.com for Shopify
+	StoreURL  string `bson:"store_url" json:"store_url"`
+	APIKey    string `bson:"api_key,omitempty" json:"-"` // WooCommerce consumer key
+	APISecret string `bson:"api_secret" json:"-"`        // WooCommerce consumer secret or Shopify admin API access token
+	// Shopify location whose stock is set; the first active one when connected
+	LocationID string `bson:"location_id,omitempty" json:"location_id,omitempty"`
+
+	PushCatalog bool `bson:"push_catalog" json:"push_catalog"` // List products with a SKU the store does not have yet, and keep names and prices current
+	PushStock   bool `bson:"push_stock" json:"push_stock"`
+	PullOrders  bool `bson:"pull_orders" json:"pull_orders"`
+	// Units of each product held back from the store, so sales in the shop
+	// between syncs do not sell online what is no longer there
+	StockBuffer float64 `bson:"stock_buffer" json:"stock_buffer"`
+	Active      bool    `bson:"active" json:"active"`
+
+	NextSyncAt  time.Time           `bson:"next_sync_at" json:"next_sync_at"`
+	OrdersSince time.Time           `bson:"orders_since" json:"orders_since"` // Orders changed after this are pulled on the next sync
+	LastSyncAt  *time.Time          `bson:"last_sync_at,omitempty" json:"last_sync_at,omitempty"`
+	LastResult  StorefrontSyncStats `bson:"last_result" json:"last_result"`
+	LastError   string              `bson:"last_error,omitempty" json:"last_error,omitempty"`
+
+	CreatedBy primitive.ObjectID `bson:"created_by" json:"created_by"` // Online orders are recorded as sales by this user
+	CreatedAt time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type StorefrontPlatform string
+
+const (
+	StorefrontWooCommerce StorefrontPlatform = "woocommerce"
+	StorefrontShopify     StorefrontPlatform = "shopify"
+)
+
+func (p StorefrontPlatform) IsValid() bool {
+	return p == StorefrontWooCommerce || p == StorefrontShopify
+}
+
+const (
+	// StorefrontSyncInterval is how often each store is synced
+	StorefrontSyncInterval = 10 * time.Minute
+	// StorefrontOrderOverlap is how far back each pull reaches past the last one,
+	// for orders saved while it ran and clocks that disagree; repeats are skipped
+	StorefrontOrderOverlap = 5 * time.Minute
+)
+
+// StorefrontSyncStats is what a sync did
+type StorefrontSyncStats struct {
+	OrdersImported  int `bson:"orders_imported" json:"orders_imported"`
+	OrderConflicts  int `bson:"order_conflicts" json:"order_conflicts"` // Orders with lines that could not be recorded
+	ProductsListed  int `bson:"products_listed" json:"products_listed"`
+	ProductsMapped  int `bson:"products_mapped" json:"products_mapped"` // Found on the store by SKU
+	ProductsUpdated int `bson:"products_updated" json:"products_updated"`
+	StockUpdates    int `bson:"stock_updates" json:"stock_updates"`
+	Errors          int `bson:"errors" json:"errors"`
+}
+
+// StorefrontMapping ties a product to its listing on a connected store
+type StorefrontMapping struct {
+	ID                primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID        primitive.ObjectID `bson:"business_id" json:"business_id"`
+	ConnectionID      primitive.ObjectID `bson:"connection_id" json:"connection_id"`
+	ProductID         primitive.ObjectID `bson:"product_id" json:"product_id"`
+	RemoteProductID   string             `bson:"remote_product_id" json:"remote_product_id"`
+	RemoteVariantID   string             `bson:"remote_variant_id,omitempty" json:"remote_variant_id,omitempty"`     // Shopify only
+	RemoteInventoryID string             `bson:"remote_inventory_id,omitempty" json:"remote_inventory_id,omitempty"` // Shopify only
+	// What the store was last told; -1 before the stock is first set
+	LastPushedStock int        `bson:"last_pushed_stock" json:"last_pushed_stock"`
+	LastPushedPrice float64    `bson:"last_pushed_price" json:"last_pushed_price"`
+	LastPushedName  string     `bson:"last_pushed_name,omitempty" json:"last_pushed_name,omitempty"`
+	LastPushedAt    *time.Time `bson:"last_pushed_at,omitempty" json:"last_pushed_at,omitempty"`
+	CreatedAt       time.Time  `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time  `bson:"updated_at" json:"updated_at"`
+}
+
+// StorefrontOrder is an online order pulled from a store. Each line is recorded
+// as a sale of its product; lines that cannot be, because the product is not
+// mapped or was sold out in the shop first, are held for the owner to resolve.
+type StorefrontOrder struct {
+	ID            primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	BusinessID    primitive.ObjectID    `bson:"business_id" json:"business_id"`
+	ConnectionID  primitive.ObjectID    `bson:"connection_id" json:"connection_id"`
+	RemoteID      string                `bson:"remote_id" json:"remote_id"`
+	Number        string                `bson:"number" json:"number"` // As the store shows it, e.g. #1001
+	Status        StorefrontOrderStatus `bson:"status" json:"status"`
+	CustomerName  string                `bson:"customer_name,omitempty" json:"customer_name,omitempty"`
+	CustomerPhone string                `bson:"customer_phone,omitempty" json:"customer_phone,omitempty"`
+	PaymentMethod PaymentMethod         `bson:"payment_method" json:"payment_method"`
+	Currency      string                `bson:"currency" json:"currency"`
+	Total         float64               `bson:"total" json:"total"`
+	Lines         []StorefrontOrderLine `bson:"lines" json:"lines"`
+	OrderedAt     time.Time             `bson:"ordered_at" json:"ordered_at"`
+	ResolvedBy    *primitive.ObjectID   `bson:"resolved_by,omitempty" json:"resolved_by,omitempty"`
+	ResolvedAt    *time.Time            `bson:"resolved_at,omitempty" json:"resolved_at,omitempty"`
+	CreatedAt     time.Time             `bson:"created_at" json:"created_at"`
+	UpdatedAt     time.Time             `bson:"updated_at" json:"updated_at"`
+}
+
+type StorefrontOrderLine struct {
+	RemoteLineID    string              `bson:"remote_line_id" json:"remote_line_id"`
+	RemoteProductID string              `bson:"remote_product_id" json:"remote_product_id"`
+	RemoteVariantID string              `bson:"remote_variant_id,omitempty" json:"remote_variant_id,omitempty"`
+	SKU             string              `bson:"sku,omitempty" json:"sku,omitempty"`
+	Name            string              `bson:"name" json:"name"`
+	Quantity        float64             `bson:"quantity" json:"quantity"`
+	UnitPrice       float64             `bson:"unit_price" json:"unit_price"` // After the store's discounts
+	ProductID       *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	SaleID          *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"`
+	Conflict        string              `bson:"conflict,omitempty" json:"conflict,omitempty"` // Why no sale was recorded
+}
+
+type StorefrontOrderStatus string
+
+const (
+	StorefrontOrderImporting StorefrontOrderStatus = "importing"
+	StorefrontOrderImported  StorefrontOrderStatus = "imported"
+	StorefrontOrderConflict  StorefrontOrderStatus = "conflict"  // Some lines are waiting on the owner
+	StorefrontOrderDismissed StorefrontOrderStatus = "dismissed" // The owner settled the waiting lines on the store, e.g. refunded them
+)
+
+// StorefrontResolution is what the owner does with an order's waiting lines
+type StorefrontResolution string
+
+const (
+	StorefrontResolutionRetry   StorefrontResolution = "retry" // After restocking or mapping the products
+	StorefrontResolutionDismiss StorefrontResolution = "dismiss"
+)
+
+type ConnectStorefrontRequest struct {
+	Platform    StorefrontPlatform `json:"platform" validate:"required"`
+	Name        string             `json:"name" validate:"required,max=100"`
+	StoreURL    string             `json:"store_url" validate:"required,max=300"`
+	APIKey      string             `json:"api_key,omitempty" validate:"max=200"`
+	APISecret   string             `json:"api_secret" validate:"required,max=200"`
+	LocationID  string             `json:"location_id,omitempty" validate:"omitempty,numeric"`
+	PushCatalog bool               `json:"push_catalog,omitempty"`
+	PushStock   *bool              `json:"push_stock,omitempty"`  // On when left out
+	PullOrders  *bool              `json:"pull_orders,omitempty"` // On when left out
+	StockBuffer float64            `json:"stock_buffer,omitempty" validate:"min=0"`
+}
+
+type UpdateStorefrontRequest struct {
+	Name        *string  `json:"name,omitempty" validate:"omitempty,min=1,max=100"`
+	APIKey      *string  `json:"api_key,omitempty" validate:"omitempty,max=200"`
+	APISecret   *string  `json:"api_secret,omitempty" validate:"omitempty,min=1,max=200"`
+	LocationID  *string  `json:"location_id,omitempty" validate:"omitempty,numeric"`
+	PushCatalog *bool    `json:"push_catalog,omitempty"`
+	PushStock   *bool    `json:"push_stock,omitempty"`
+	PullOrders  *bool    `json:"pull_orders,omitempty"`
+	StockBuffer *float64 `json:"stock_buffer,omitempty" validate:"omitempty,min=0"`
+	Active      *bool    `json:"active,omitempty"`
+}
+
+type SetStorefrontMappingRequest struct {
+	ProductID       string `json:"product_id" validate:"required"`
+	RemoteProductID string `json:"remote_product_id" validate:"required"`
+	RemoteVariantID string `json:"remote_variant_id,omitempty"` // Required for Shopify
+}
+
+type ResolveStorefrontOrderRequest struct {
+	Action StorefrontResolution `json:"action" validate:"required,oneof=retry dismiss"`
+}
+
+type StorefrontOrderFilters struct {
+	Status *StorefrontOrderStatus
+	Limit  int
+	Offset int
+}
+
+type StorefrontRepository interface {
+	CreateConnection(connection *StorefrontConnection) error
+	FindConnectionByID(id string) (*StorefrontConnection, error)
+	FindConnections(businessID string) ([]StorefrontConnection, error)
+	UpdateConnection(connection *StorefrontConnection) error
+	// DeleteConnection removes the connection and its mappings; its orders stay
+	DeleteConnection(id string) error
+	// ClaimDueConnection takes the next active connection due a sync and moves its
+	// next sync on, so other instances leave it alone; nil when none is due
+	ClaimDueConnection(now time.Time) (*StorefrontConnection, error)
+	ScheduleSync(id primitive.ObjectID, at time.Time) error
+	RecordSync(id primitive.ObjectID, ordersSince, at time.Time, result StorefrontSyncStats, lastError string) error
+
+	FindMappings(connectionID primitive.ObjectID) ([]StorefrontMapping, error)
+	FindMappingByRemote(connectionID primitive.ObjectID, remoteProductID, remoteVariantID string) (*StorefrontMapping, error)
+	// SaveMapping creates or replaces the product's mapping on the connection
+	SaveMapping(mapping *StorefrontMapping) error
+	DeleteMapping(connectionID primitive.ObjectID, productID string) error
+
+	// CreateOrder records a pulled order; false when it was pulled before
+	CreateOrder(order *StorefrontOrder) (bool, error)
+	FindOrderByID(id string) (*StorefrontOrder, error)
+	FindOrders(connectionID primitive.ObjectID, filters StorefrontOrderFilters) ([]StorefrontOrder, error)
+	UpdateOrder(order *StorefrontOrder) error
+}
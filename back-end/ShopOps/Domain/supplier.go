@@ -0,0 +1,247 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type Supplier struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID       primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Name             string             `bson:"name" json:"name"`
+	ContactName      string             `bson:"contact_name,omitempty" json:"contact_name,omitempty"`
+	Phone            string             `bson:"phone,omitempty" json:"phone,omitempty"`
+	Email            string             `bson:"email,omitempty" json:"email,omitempty"`
+	Address          string             `bson:"address,omitempty" json:"address,omitempty"`
+	TIN              string             `bson:"tin,omitempty" json:"tin,omitempty"`                       // Tax identification number
+	PaymentTermsDays int                `bson:"payment_terms_days" json:"payment_terms_days"`             // Days until an invoice is due
+	LeadTimeDays     int                `bson:"lead_time_days,omitempty" json:"lead_time_days,omitempty"` // Quoted days from order to delivery
+	Balance          Money              `bson:"balance" json:"balance"`                                   // Amount currently owed to the supplier
+	Notes            string             `bson:"notes,omitempty" json:"notes,omitempty"`
+	Status           SupplierStatus     `bson:"status" json:"status"`
+	CreatedBy        primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt        time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+	DeletedAt        *time.Time         `bson:"deleted_at,omitempty" json:"deleted_at,omitempty"` // Set while in the trash
+	Version          int64              `bson:"version" json:"version"`                           // Incremented by every update
+
+	CustomFields CustomFields `bson:"custom_fields,omitempty" json:"custom_fields,omitempty"`
+}
+
+type SupplierStatus string
+
+const (
+	SupplierStatusActive   SupplierStatus = "active"
+	SupplierStatusInactive SupplierStatus = "inactive"
+)
+
+type CreateSupplierRequest struct {
+	Name             string `json:"name" validate:"required"`
+	ContactName      string `json:"contact_name,omitempty"`
+	Phone            string `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email            string `json:"email,omitempty" validate:"omitempty,email"`
+	Address          string `json:"address,omitempty"`
+	TIN              string `json:"tin,omitempty" validate:"omitempty,tin"`
+	PaymentTermsDays int    `json:"payment_terms_days,omitempty"`
+	LeadTimeDays     int    `json:"lead_time_days,omitempty" validate:"omitempty,min=0,max=365"`
+	Notes            string `json:"notes,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty"`
+}
+
+type UpdateSupplierRequest struct {
+	Name             *string         `json:"name,omitempty"`
+	ContactName      *string         `json:"contact_name,omitempty"`
+	Phone            *string         `json:"phone,omitempty" validate:"omitempty,phone"`
+	Email            *string         `json:"email,omitempty" validate:"omitempty,email"`
+	Address          *string         `json:"address,omitempty"`
+	TIN              *string         `json:"tin,omitempty" validate:"omitempty,tin"`
+	PaymentTermsDays *int            `json:"payment_terms_days,omitempty"`
+	LeadTimeDays     *int            `json:"lead_time_days,omitempty" validate:"omitempty,min=0,max=365"`
+	Notes            *string         `json:"notes,omitempty"`
+	Status           *SupplierStatus `json:"status,omitempty"`
+
+	CustomFields CustomFields `json:"custom_fields,omitempty"` // Only the fields given change; a null clears one
+
+	Version *int64 `json:"version,omitempty"` // The version the client last read; a newer one on the server is a 409
+}
+
+type PurchaseOrder struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	SupplierID primitive.ObjectID  `bson:"supplier_id" json:"supplier_id"`
+	Number     string              `bson:"number" json:"number"`
+	Items      []PurchaseOrderItem `bson:"items" json:"items"`
+	Total      Money               `bson:"total" json:"total"`
+	Status     PurchaseOrderStatus `bson:"status" json:"status"`
+	ExpectedAt *time.Time          `bson:"expected_at,omitempty" json:"expected_at,omitempty"`
+	OrderedAt  *time.Time          `bson:"ordered_at,omitempty" json:"ordered_at,omitempty"` // When it was sent to the supplier
+	ReceivedAt *time.Time          `bson:"received_at,omitempty" json:"received_at,omitempty"`
+	Notes      string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy  primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+}
+
+type PurchaseOrderItem struct {
+	ProductID   *primitive.ObjectID `bson:"product_id,omitempty" json:"product_id,omitempty"`
+	Description string              `bson:"description" json:"description"`
+	Quantity    float64             `bson:"quantity" json:"quantity"`
+	UnitCost    Money               `bson:"unit_cost" json:"unit_cost"`
+	Total       Money               `bson:"total" json:"total"`
+}
+
+type PurchaseOrderStatus string
+
+const (
+	PurchaseOrderStatusDraft     PurchaseOrderStatus = "draft"
+	PurchaseOrderStatusOrdered   PurchaseOrderStatus = "ordered"
+	PurchaseOrderStatusReceived  PurchaseOrderStatus = "received"
+	PurchaseOrderStatusCancelled PurchaseOrderStatus = "cancelled"
+)
+
+type CreatePurchaseOrderRequest struct {
+	SupplierID string                     `json:"supplier_id" validate:"required"`
+	Items      []PurchaseOrderItemRequest `json:"items" validate:"required,min=1,dive"`
+	ExpectedAt *time.Time                 `json:"expected_at,omitempty"`
+	Notes      string                     `json:"notes,omitempty"`
+	Submit     bool                       `json:"submit"` // Create as ordered instead of draft
+}
+
+type PurchaseOrderItemRequest struct {
+	ProductID   *string `json:"product_id,omitempty"`
+	Description string  `json:"description,omitempty"`
+	Quantity    float64 `json:"quantity" validate:"required,gt=0"`
+	UnitCost    Money   `json:"unit_cost" validate:"gte=0,money"`
+}
+
+// ReceivePurchaseOrderRequest records goods arriving and the supplier's invoice for them
+type ReceivePurchaseOrderRequest struct {
+	InvoiceNumber string     `json:"invoice_number,omitempty"`
+	InvoiceDate   *time.Time `json:"invoice_date,omitempty"`
+	DueDate       *time.Time `json:"due_date,omitempty"` // Defaults to invoice date + supplier payment terms
+}
+
+type PurchaseOrderFilters struct {
+	SupplierID *string
+	Status     *PurchaseOrderStatus
+	Limit      int
+	Offset     int
+}
+
+// PayableEntry is one line in a supplier's payables ledger
+type PayableEntry struct {
+	ID              primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID      primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	SupplierID      primitive.ObjectID  `bson:"supplier_id" json:"supplier_id"`
+	Type            PayableEntryType    `bson:"type" json:"type"`
+	Amount          Money               `bson:"amount" json:"amount"` // Always positive; type decides the direction
+	Reference       string              `bson:"reference,omitempty" json:"reference,omitempty"`
+	PurchaseOrderID *primitive.ObjectID `bson:"purchase_order_id,omitempty" json:"purchase_order_id,omitempty"`
+	Date            time.Time           `bson:"date" json:"date"`
+	DueDate         *time.Time          `bson:"due_date,omitempty" json:"due_date,omitempty"`
+	PaymentMethod   PaymentMethod       `bson:"payment_method,omitempty" json:"payment_method,omitempty"`
+	BalanceAfter    Money               `bson:"balance_after" json:"balance_after"`
+	Notes           string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	CreatedBy       primitive.ObjectID  `bson:"created_by" json:"created_by"`
+	CreatedAt       time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type PayableEntryType string
+
+const (
+	PayableEntryInvoice PayableEntryType = "invoice"
+	PayableEntryPayment PayableEntryType = "payment"
+	PayableEntryCredit  PayableEntryType = "credit" // Credit note / returned goods
+)
+
+type RecordSupplierInvoiceRequest struct {
+	Amount          Money      `json:"amount" validate:"required,gt=0,money"`
+	InvoiceNumber   string     `json:"invoice_number,omitempty"`
+	InvoiceDate     *time.Time `json:"invoice_date,omitempty"`
+	DueDate         *time.Time `json:"due_date,omitempty"`
+	PurchaseOrderID *string    `json:"purchase_order_id,omitempty"`
+	Notes           string     `json:"notes,omitempty"`
+}
+
+type RecordSupplierPaymentRequest struct {
+	Amount        Money         `json:"amount" validate:"required,gt=0,money"`
+	PaymentMethod PaymentMethod `json:"payment_method" validate:"required"`
+	Reference     string        `json:"reference,omitempty"`
+	Date          *time.Time    `json:"date,omitempty"`
+	Notes         string        `json:"notes,omitempty"`
+}
+
+type PayablesAgingReport struct {
+	AsOf      time.Time             `json:"as_of"`
+	Totals    AgingBuckets          `json:"totals"`
+	Suppliers []SupplierAgingDetail `json:"suppliers"`
+}
+
+type SupplierAgingDetail struct {
+	SupplierID   primitive.ObjectID `json:"supplier_id"`
+	SupplierName string             `json:"supplier_name"`
+	Buckets      AgingBuckets       `json:"buckets"`
+}
+
+// AgingBuckets splits outstanding amounts by days past due
+type AgingBuckets struct {
+	Current     Money `json:"current"`
+	Days1To30   Money `json:"days_1_30"`
+	Days31To60  Money `json:"days_31_60"`
+	Days61To90  Money `json:"days_61_90"`
+	Over90      Money `json:"over_90"`
+	Outstanding Money `json:"outstanding"`
+}
+
+// Add places an amount into the right bucket for how many days it is overdue
+func (b *AgingBuckets) Add(amount Money, daysOverdue int) {
+	switch {
+	case daysOverdue <= 0:
+		b.Current += amount
+	case daysOverdue <= 30:
+		b.Days1To30 += amount
+	case daysOverdue <= 60:
+		b.Days31To60 += amount
+	case daysOverdue <= 90:
+		b.Days61To90 += amount
+	default:
+		b.Over90 += amount
+	}
+	b.Outstanding += amount
+}
+
+type SupplierRepository interface {
+	Create(supplier *Supplier) error
+	// FindInBusiness returns the supplier when it is one of the shop's
+	FindInBusiness(ctx context.Context, id string) (*Supplier, error)
+	FindByBusinessID(businessID string, status *SupplierStatus, search *string) ([]Supplier, error)
+	// Update saves the supplier if it is still at supplier.Version, and increments it
+	Update(supplier *Supplier) error
+	// Delete moves the supplier to the trash
+	Delete(id string) error
+	FindDeleted(businessID string) ([]Supplier, error)
+	Restore(id string) error
+	PurgeDeleted(before time.Time, limit int) (map[string]int64, error)
+	AddPayableEntry(entry *PayableEntry) error
+	GetPayableEntries(supplierID string, limit int) ([]PayableEntry, error)
+	GetBusinessPayableEntries(businessID string) ([]PayableEntry, error)
+}
+
+type PurchaseOrderRepository interface {
+	Create(order *PurchaseOrder) error
+	// FindInBusiness returns the order when the shop placed it
+	FindInBusiness(ctx context.Context, id string) (*PurchaseOrder, error)
+	FindByBusinessID(businessID string, filters PurchaseOrderFilters) ([]PurchaseOrder, error)
+	UpdateStatus(id string, status PurchaseOrderStatus, receivedAt *time.Time) error
+	CountByBusinessID(businessID string) (int64, error)
+}
+
+// Default payment terms for new suppliers when none are given
+const DefaultSupplierPaymentTermsDays = 30
+
+// DefaultSupplierLeadTimeDays is assumed for a supplier with no quoted lead time
+// and no deliveries to learn one from
+const DefaultSupplierLeadTimeDays = 7
@@ -0,0 +1,121 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SupplierPrice is what the shop last paid a supplier for a product, and how long
+// that supplier has taken to deliver it. Receiving a purchase order updates it.
+type SupplierPrice struct {
+	ID               primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID       primitive.ObjectID `bson:"business_id" json:"business_id"`
+	SupplierID       primitive.ObjectID `bson:"supplier_id" json:"supplier_id"`
+	ProductID        primitive.ObjectID `bson:"product_id" json:"product_id"`
+	LastUnitCost     Money              `bson:"last_unit_cost" json:"last_unit_cost"`
+	PreviousUnitCost *Money             `bson:"previous_unit_cost,omitempty" json:"previous_unit_cost,omitempty"` // The cost before the last purchase
+	LastPurchasedAt  time.Time          `bson:"last_purchased_at" json:"last_purchased_at"`
+	LastOrderID      primitive.ObjectID `bson:"last_order_id" json:"last_order_id"`
+	LastOrderNumber  string             `bson:"last_order_number" json:"last_order_number"`
+	Purchases        int                `bson:"purchases" json:"purchases"`
+	LeadTimeDays     *float64           `bson:"lead_time_days,omitempty" json:"lead_time_days,omitempty"` // Average days from order to delivery
+	LeadTimeOrders   int                `bson:"lead_time_orders,omitempty" json:"lead_time_orders,omitempty"`
+	UpdatedAt        time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// SupplierPurchase is one received purchase order line, as recorded against the
+// supplier's price for the product
+type SupplierPurchase struct {
+	BusinessID   primitive.ObjectID
+	SupplierID   primitive.ObjectID
+	ProductID    primitive.ObjectID
+	UnitCost     Money
+	OrderID      primitive.ObjectID
+	OrderNumber  string
+	PurchasedAt  time.Time
+	LeadTimeDays *float64 // Nil when the order's dates do not give one
+}
+
+type LeadTimeSource string
+
+const (
+	LeadTimeFromHistory  LeadTimeSource = "history"  // Averaged over past deliveries
+	LeadTimeFromSupplier LeadTimeSource = "supplier" // The supplier's quoted lead time
+	LeadTimeDefault      LeadTimeSource = "default"
+)
+
+// StaleSupplierPriceDays is how old a last price can be before suggestions stop
+// trusting it over a fresher one
+const StaleSupplierPriceDays = 180
+
+// SupplierQuote is one supplier's last price and lead time for a product
+type SupplierQuote struct {
+	SupplierID       string         `json:"supplier_id"`
+	SupplierName     string         `json:"supplier_name"`
+	LastUnitCost     Money          `json:"last_unit_cost"`
+	PreviousUnitCost *Money         `json:"previous_unit_cost,omitempty"`
+	LastPurchasedAt  time.Time      `json:"last_purchased_at"`
+	LastOrderNumber  string         `json:"last_order_number"`
+	Purchases        int            `json:"purchases"`
+	LeadTimeDays     float64        `json:"lead_time_days"`
+	LeadTimeSource   LeadTimeSource `json:"lead_time_source"`
+	Stale            bool           `json:"stale,omitempty"` // Last bought over StaleSupplierPriceDays ago
+}
+
+// ProductSupplierPrices compares what each supplier last charged for a product,
+// cheapest first
+type ProductSupplierPrices struct {
+	ProductID string          `json:"product_id"`
+	Name      string          `json:"name"`
+	SKU       string          `json:"sku,omitempty"`
+	CostPrice Money           `json:"cost_price"`
+	Quotes    []SupplierQuote `json:"quotes"`
+}
+
+type SuggestionReason string
+
+const (
+	SuggestionCheapest       SuggestionReason = "cheapest"
+	SuggestionOnlySupplier   SuggestionReason = "only_supplier"
+	SuggestionFasterDelivery SuggestionReason = "faster_delivery" // The cheapest could not deliver before stock runs down
+	SuggestionMostRecent     SuggestionReason = "most_recent"     // Every price is stale
+)
+
+// PurchaseSuggestion recommends a supplier to buy a product from, how much, and
+// by when to order so it arrives before stock falls below the minimum
+type PurchaseSuggestion struct {
+	ProductID    string   `json:"product_id"`
+	Name         string   `json:"name"`
+	SKU          string   `json:"sku,omitempty"`
+	Stock        float64  `json:"stock"`
+	MinStock     float64  `json:"min_stock,omitempty"`
+	AverageDaily float64  `json:"average_daily"`
+	DaysOfCover  *float64 `json:"days_of_cover,omitempty"` // Nil when nothing is expected to sell
+
+	Recommended       *SupplierQuote   `json:"recommended,omitempty"` // Nil when no supplier has sold it to the shop
+	Reason            SuggestionReason `json:"reason,omitempty"`
+	Quotes            []SupplierQuote  `json:"quotes"`
+	SuggestedQuantity float64          `json:"suggested_quantity"`
+	ReorderBy         string           `json:"reorder_by,omitempty"` // Last day to order, in the shop's time zone; empty when nothing is selling
+	OrderNow          bool             `json:"order_now"`
+	EstimatedCost     Money            `json:"estimated_cost"`
+}
+
+type PurchaseSuggestionReport struct {
+	GeneratedAt time.Time            `json:"generated_at"`
+	Suggestions []PurchaseSuggestion `json:"suggestions"`
+}
+
+type PurchaseSuggestionOptions struct {
+	ProductIDs []string // All products with supplier prices that need ordering soon when empty
+	SupplierID string   // Only suggest buying from this supplier
+}
+
+type SupplierPriceRepository interface {
+	// RecordPurchase makes the purchase the supplier's last price for the product
+	// and folds its lead time into their average
+	RecordPurchase(purchase SupplierPurchase) error
+	FindByProduct(businessID, productID string) ([]SupplierPrice, error)
+	FindByBusiness(businessID string) ([]SupplierPrice, error)
+}
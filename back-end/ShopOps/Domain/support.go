@@ -0,0 +1,120 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// ShopOverview is what the support team sees when they look up a shop
+type ShopOverview struct {
+	Business     Business     `json:"business"`
+	Owner        *UserSummary `json:"owner,omitempty"`
+	Features     []string     `json:"features"`
+	RecentErrors int          `json:"recent_errors"` // Failed requests in the last 24 hours
+	LastBackup   *ShopBackup  `json:"last_backup,omitempty"`
+	Maintenance  bool         `json:"maintenance"`
+}
+
+// UserSummary is a user without their credentials
+type UserSummary struct {
+	ID        primitive.ObjectID `json:"id"`
+	Name      string             `json:"name"`
+	Phone     string             `json:"phone"`
+	Email     string             `json:"email,omitempty"`
+	Role      UserRole           `json:"role"`
+	Status    UserStatus         `json:"status"`
+	CreatedAt time.Time          `json:"created_at"`
+}
+
+// AuditEntry records one call to the support API: who did what to which shop
+type AuditEntry struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	Actor      string              `bson:"actor" json:"actor"` // Name of the support API key
+	Action     string              `bson:"action" json:"action"`
+	BusinessID *primitive.ObjectID `bson:"business_id,omitempty" json:"business_id,omitempty"`
+	Method     string              `bson:"method" json:"method"`
+	Path       string              `bson:"path" json:"path"`
+	Status     int                 `bson:"status" json:"status"`
+	RequestID  string              `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	ClientIP   string              `bson:"client_ip" json:"client_ip"`
+	Details    map[string]string   `bson:"details,omitempty" json:"details,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type AuditFilters struct {
+	Actor      *string
+	BusinessID *string
+	Limit      int
+	Offset     int // Deprecated: page with After instead
+
+	After *PageCursor // Always newest first
+}
+
+// AuditSorts are the orders the audit log can be paged in
+var AuditSorts = ListSorts{
+	"-created_at": {Field: "created_at", Desc: true},
+}
+
+func (e *AuditEntry) SortValue(field string) interface{} {
+	return e.CreatedAt
+}
+
+func (e *AuditEntry) SortID() primitive.ObjectID {
+	return e.ID
+}
+
+type AuditLogRepository interface {
+	Create(entry *AuditEntry) error
+	Find(filters AuditFilters) ([]AuditEntry, error)
+	Count(filters AuditFilters) (int64, error)
+}
+
+// RequestError is a failed API request, kept for a while so support can see what a
+// shop ran into
+type RequestError struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	UserID     *primitive.ObjectID `bson:"user_id,omitempty" json:"user_id,omitempty"`
+	RequestID  string              `bson:"request_id,omitempty" json:"request_id,omitempty"`
+	Method     string              `bson:"method" json:"method"`
+	Route      string              `bson:"route" json:"route"`
+	Status     int                 `bson:"status" json:"status"`
+	Code       ErrorCode           `bson:"code,omitempty" json:"code,omitempty"`
+	Message    string              `bson:"message,omitempty" json:"message,omitempty"`
+	DeviceID   string              `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+}
+
+type RequestErrorRepository interface {
+	Create(requestError *RequestError) error
+	FindByBusiness(businessID string, limit int) ([]RequestError, error)
+	CountByBusinessSince(businessID string, since time.Time) (int, error)
+}
+
+// ShopBackup is a JSON export of one shop's data, taken on request by support
+type ShopBackup struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
+	JobID       primitive.ObjectID `bson:"job_id" json:"job_id"`
+	URL         string             `bson:"url" json:"url"`
+	Documents   map[string]int     `bson:"documents" json:"documents"` // Count per collection
+	RequestedBy string             `bson:"requested_by" json:"requested_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+}
+
+type BackupRepository interface {
+	Create(backup *ShopBackup) error
+	FindByBusiness(businessID string) ([]ShopBackup, error)
+	// Export writes every document belonging to the business to write, one
+	// collection at a time, and returns how many it wrote from each
+	Export(businessID string, write func(collection string, document []byte) error) (map[string]int, error)
+}
+
+// ImpersonationResponse is a short-lived, read-only token for acting as a shop's owner
+type ImpersonationResponse struct {
+	AccessToken string    `json:"access_token"`
+	ExpiresAt   time.Time `json:"expires_at"`
+	UserID      string    `json:"user_id"`
+	ReadOnly    bool      `json:"read_only"`
+}
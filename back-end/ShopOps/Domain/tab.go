@@ -0,0 +1,225 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Tab is a table's running bill in a café or restaurant. Waiters add items to it
+// from any device as they are ordered; it can be split by items or evenly and is
+// paid off in one or more payments. Once it is fully paid it closes, recording an
+// ordinary sale for each item.
+//
+// Every change to a shop's tabs takes the next revision of its tabs, so devices
+// keep up by asking for the tabs changed since the last revision they saw.
+type Tab struct {
+	ID         primitive.ObjectID  `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID  `bson:"business_id" json:"business_id"`
+	Table      string              `bson:"table" json:"table"` // Table number or name, as the shop labels them
+	Guests     int                 `bson:"guests,omitempty" json:"guests,omitempty"`
+	EmployeeID *primitive.ObjectID `bson:"employee_id,omitempty" json:"employee_id,omitempty"` // The waiter, credited with the sales
+	Status     TabStatus           `bson:"status" json:"status"`
+	Items      []TabItem           `bson:"items" json:"items"`
+	Bills      []TabBill           `bson:"bills" json:"bills"` // How the tab is split, if it is
+	Payments   []TabPayment        `bson:"payments" json:"payments"`
+	Notes      string              `bson:"notes,omitempty" json:"notes,omitempty"`
+	OpenedBy   primitive.ObjectID  `bson:"opened_by" json:"opened_by"`
+	CreatedAt  time.Time           `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time           `bson:"updated_at" json:"updated_at"`
+	ClosedBy   *primitive.ObjectID `bson:"closed_by,omitempty" json:"closed_by,omitempty"`
+	ClosedAt   *time.Time          `bson:"closed_at,omitempty" json:"closed_at,omitempty"`
+	Revision   int64               `bson:"revision" json:"revision"` // The shop's tab revision at the last change
+}
+
+type TabStatus string
+
+const (
+	TabOpen      TabStatus = "open"
+	TabClosed    TabStatus = "closed" // Paid, with a sale recorded for every item
+	TabCancelled TabStatus = "cancelled"
+)
+
+// TabItem is one order taken on the tab
+type TabItem struct {
+	ID        primitive.ObjectID  `bson:"_id" json:"id"`
+	ProductID primitive.ObjectID  `bson:"product_id" json:"product_id"`
+	Name      string              `bson:"name" json:"name"`
+	Quantity  float64             `bson:"quantity" json:"quantity"`
+	UnitPrice Money               `bson:"unit_price" json:"unit_price"`
+	Notes     string              `bson:"notes,omitempty" json:"notes,omitempty"` // For the kitchen: no onions, well done
+	DeviceID  string              `bson:"device_id,omitempty" json:"device_id,omitempty"`
+	AddedBy   primitive.ObjectID  `bson:"added_by" json:"added_by"`
+	AddedAt   time.Time           `bson:"added_at" json:"added_at"`
+	SaleID    *primitive.ObjectID `bson:"sale_id,omitempty" json:"sale_id,omitempty"` // Recorded when the tab closes
+}
+
+// TabBill is one guest's share of a split tab: the items listed, or an even share
+// of the whole tab when none are
+type TabBill struct {
+	ID      primitive.ObjectID   `bson:"_id" json:"id"`
+	ItemIDs []primitive.ObjectID `bson:"item_ids,omitempty" json:"item_ids,omitempty"`
+	Amount  Money                `bson:"amount" json:"amount"`
+}
+
+// TabPayment is money taken towards the tab, or towards one of its bills
+type TabPayment struct {
+	ID        primitive.ObjectID  `bson:"_id" json:"id"`
+	BillID    *primitive.ObjectID `bson:"bill_id,omitempty" json:"bill_id,omitempty"`
+	Method    PaymentMethod       `bson:"method" json:"method"`
+	Amount    Money               `bson:"amount" json:"amount"`
+	Reference string              `bson:"reference,omitempty" json:"reference,omitempty"` // Card slip, transaction ref
+	TakenBy   primitive.ObjectID  `bson:"taken_by" json:"taken_by"`
+	TakenAt   time.Time           `bson:"taken_at" json:"taken_at"`
+}
+
+// Total is what the tab's items come to
+func (t *Tab) Total() Money {
+	var total Money
+	for _, item := range t.Items {
+		total += item.UnitPrice.Times(item.Quantity)
+	}
+	return total
+}
+
+// Paid is what has been taken towards the tab
+func (t *Tab) Paid() Money {
+	var paid Money
+	for _, payment := range t.Payments {
+		paid += payment.Amount
+	}
+	return paid
+}
+
+// Due is what is left to pay on the whole tab
+func (t *Tab) Due() Money {
+	return t.Total() - t.Paid()
+}
+
+// BillDue is what is left to pay on the bill
+func (t *Tab) BillDue(bill *TabBill) Money {
+	due := bill.Amount
+	for _, payment := range t.Payments {
+		if payment.BillID != nil && *payment.BillID == bill.ID {
+			due -= payment.Amount
+		}
+	}
+	return due
+}
+
+// Bill finds the bill with the ID, or nil
+func (t *Tab) Bill(id primitive.ObjectID) *TabBill {
+	for i := range t.Bills {
+		if t.Bills[i].ID == id {
+			return &t.Bills[i]
+		}
+	}
+	return nil
+}
+
+// Billed reports whether any item has had its sale recorded
+func (t *Tab) Billed() bool {
+	for _, item := range t.Items {
+		if item.SaleID != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// OpenTabRequest opens a tab for a table, with the first items ordered if any
+type OpenTabRequest struct {
+	Table      string           `json:"table" validate:"required,max=50"`
+	Guests     int              `json:"guests,omitempty" validate:"omitempty,min=1,max=500"`
+	EmployeeID *string          `json:"employee_id,omitempty"` // The waiter; defaults to whoever is clocked in on the device
+	Notes      string           `json:"notes,omitempty" validate:"max=500"`
+	Items      []TabItemRequest `json:"items,omitempty" validate:"omitempty,max=100,dive"`
+	DeviceID   string           `json:"device_id,omitempty"`
+}
+
+type UpdateTabRequest struct {
+	Table      *string `json:"table,omitempty" validate:"omitempty,min=1,max=50"` // Moving the party to another table
+	Guests     *int    `json:"guests,omitempty" validate:"omitempty,min=1,max=500"`
+	EmployeeID *string `json:"employee_id,omitempty"` // Empty to clear
+	Notes      *string `json:"notes,omitempty" validate:"omitempty,max=500"`
+}
+
+type TabItemRequest struct {
+	ProductID string  `json:"product_id" validate:"required"`
+	Quantity  float64 `json:"quantity" validate:"required,gt=0"`
+	UnitPrice *Money  `json:"unit_price,omitempty" validate:"omitempty,gt=0,money"` // Defaults to the product's selling price
+	Notes     string  `json:"notes,omitempty" validate:"max=200"`
+}
+
+type AddTabItemsRequest struct {
+	Items    []TabItemRequest `json:"items" validate:"required,min=1,max=100,dive"`
+	DeviceID string           `json:"device_id,omitempty"`
+}
+
+// SplitTabRequest splits the tab evenly between a number of guests, or into bills
+// of the items listed, with any items left out on a bill of their own. A split is
+// dropped when items are added or removed; once payments are taken it is fixed.
+type SplitTabRequest struct {
+	Evenly int        `json:"evenly,omitempty" validate:"omitempty,min=2,max=50"`
+	Items  [][]string `json:"items,omitempty" validate:"omitempty,max=50"`
+}
+
+// PayTabRequest takes payments towards the tab, or towards one of its bills.
+// A single payment without an amount pays what is left. The tab closes once it
+// is fully paid.
+type PayTabRequest struct {
+	BillID   *string             `json:"bill_id,omitempty"`
+	Payments []TabPaymentRequest `json:"payments" validate:"required,min=1,max=10,dive"`
+}
+
+type TabPaymentRequest struct {
+	Method    PaymentMethod `json:"method" validate:"required"`
+	Amount    *Money        `json:"amount,omitempty" validate:"omitempty,gt=0,money"`
+	Reference string        `json:"reference,omitempty" validate:"max=100"`
+}
+
+type TabFilters struct {
+	Status     *TabStatus
+	Table      *string
+	EmployeeID *string
+	Limit      int
+	Offset     int
+}
+
+// TabChanges are the shop's tabs changed since a revision, closed and cancelled
+// ones included so devices drop them. Revision is the one to ask from next.
+type TabChanges struct {
+	Tabs     []Tab `json:"tabs"`
+	Revision int64 `json:"revision"`
+}
+
+// TabRepository saves tabs. Each change takes the shop's next tab revision,
+// which is committed along with it where the database has transactions, so
+// changes are seen in revision order.
+type TabRepository interface {
+	Create(tab *Tab) error
+	// FindInBusiness returns the tab when it is open or was closed at the shop
+	FindInBusiness(ctx context.Context, id string) (*Tab, error)
+	FindByBusiness(businessID string, filters TabFilters) ([]Tab, error)
+	// FindChanged returns the shop's tabs changed since revision, oldest change first
+	FindChanged(businessID string, revision int64, limit int) ([]Tab, error)
+	// Revision is the shop's latest tab revision
+	Revision(businessID string) (int64, error)
+	// Update saves the table, guests, waiter and notes of an open tab
+	Update(tab *Tab) (bool, error)
+	// AddItems adds to an open tab with no payments, dropping any split
+	AddItems(tab *Tab, items []TabItem) (bool, error)
+	// RemoveItem removes from an open tab with no payments, dropping any split
+	RemoveItem(tab *Tab, itemID primitive.ObjectID) (bool, error)
+	// SetBills splits an open tab if it is still at the revision it was read at
+	SetBills(tab *Tab, bills []TabBill) (bool, error)
+	// AddPayments adds to an open tab if it is still at the revision it was read at
+	AddPayments(tab *Tab, payments []TabPayment) (bool, error)
+	// RemovePayment removes from an open tab whose items have no sales yet
+	RemovePayment(tab *Tab, paymentID primitive.ObjectID) (bool, error)
+	// SetSales records the sales of the tab's items, by item
+	SetSales(tab *Tab, sales map[primitive.ObjectID]primitive.ObjectID) error
+	// Close moves an open tab to status
+	Close(tab *Tab, status TabStatus, closedBy primitive.ObjectID, at time.Time) (bool, error)
+}
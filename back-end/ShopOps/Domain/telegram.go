@@ -0,0 +1,99 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TelegramLink connects a Telegram chat to a shop. The owner links a chat by sending
+// the bot a one-time code from the app; the chat then gets the shop's notifications
+// and can ask the bot about it.
+type TelegramLink struct {
+	ID         primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID    `bson:"business_id" json:"business_id"`
+	ChatID     int64                 `bson:"chat_id" json:"chat_id"`
+	ChatName   string                `bson:"chat_name,omitempty" json:"chat_name,omitempty"` // Telegram username or title, to tell links apart
+	LinkedBy   primitive.ObjectID    `bson:"linked_by" json:"linked_by"`
+	Notify     TelegramNotifications `bson:"notify" json:"notify"`
+	// Business day of the last daily summary sent, YYYY-MM-DD
+	LastSummaryDate string    `bson:"last_summary_date,omitempty" json:"last_summary_date,omitempty"`
+	CreatedAt       time.Time `bson:"created_at" json:"created_at"`
+	UpdatedAt       time.Time `bson:"updated_at" json:"updated_at"`
+}
+
+// TelegramNotifications is what a linked chat is sent
+type TelegramNotifications struct {
+	DailySummary bool    `bson:"daily_summary" json:"daily_summary"`
+	SummaryHour  int     `bson:"summary_hour" json:"summary_hour"` // Shop-local hour the summary goes out
+	LowStock     bool    `bson:"low_stock" json:"low_stock"`
+	BigSale      float64 `bson:"big_sale" json:"big_sale"` // Sales of at least this amount are reported; 0 for none
+}
+
+// DefaultTelegramNotifications are set on a newly linked chat
+var DefaultTelegramNotifications = TelegramNotifications{
+	DailySummary: true,
+	SummaryHour:  21,
+	LowStock:     true,
+}
+
+// TelegramLinkCode is a one-time code an owner sends the bot to link a chat
+type TelegramLinkCode struct {
+	Code       string             `bson:"_id" json:"code"`
+	BusinessID primitive.ObjectID `bson:"business_id" json:"business_id"`
+	UserID     primitive.ObjectID `bson:"user_id" json:"-"`
+	ExpiresAt  time.Time          `bson:"expires_at" json:"expires_at"`
+}
+
+// TelegramLinkCodeTTL is how long a link code can be used
+const TelegramLinkCodeTTL = 10 * time.Minute
+
+type TelegramLinkCodeResponse struct {
+	Code      string    `json:"code"`
+	ExpiresAt time.Time `json:"expires_at"`
+	BotURL    string    `json:"bot_url,omitempty"` // Opens the bot with the code filled in
+}
+
+type UpdateTelegramLinkRequest struct {
+	DailySummary *bool    `json:"daily_summary,omitempty"`
+	SummaryHour  *int     `json:"summary_hour,omitempty" validate:"omitempty,min=0,max=23"`
+	LowStock     *bool    `json:"low_stock,omitempty"`
+	BigSale      *float64 `json:"big_sale,omitempty" validate:"omitempty,min=0"`
+}
+
+// TelegramUpdate is the part of a Telegram Bot API update the bot reads
+type TelegramUpdate struct {
+	UpdateID int64            `json:"update_id"`
+	Message  *TelegramMessage `json:"message,omitempty"`
+}
+
+type TelegramMessage struct {
+	MessageID int64        `json:"message_id"`
+	Chat      TelegramChat `json:"chat"`
+	Text      string       `json:"text"`
+}
+
+type TelegramChat struct {
+	ID        int64  `json:"id"`
+	Type      string `json:"type"` // private, group, supergroup or channel
+	Title     string `json:"title,omitempty"`
+	Username  string `json:"username,omitempty"`
+	FirstName string `json:"first_name,omitempty"`
+}
+
+type TelegramRepository interface {
+	CreateCode(code *TelegramLinkCode) error
+	// ConsumeCode removes and returns the code if it has not expired; nil otherwise
+	ConsumeCode(code string) (*TelegramLinkCode, error)
+
+	// SaveLink links the chat to the shop, or refreshes an existing link
+	SaveLink(link *TelegramLink) error
+	FindLinkByID(id string) (*TelegramLink, error)
+	FindLinksByBusiness(businessID string) ([]TelegramLink, error)
+	FindLinksByChat(chatID int64) ([]TelegramLink, error)
+	// FindSummaryLinks returns every link with the daily summary on
+	FindSummaryLinks() ([]TelegramLink, error)
+	UpdateLink(link *TelegramLink) error
+	DeleteLink(id string) error
+	DeleteLinksByChat(chatID int64) error
+}
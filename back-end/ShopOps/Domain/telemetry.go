@@ -0,0 +1,54 @@
+package Domain
+
+import "time"
+
+// Telemetry is the high-rate, low-value bookkeeping devices cause just by being used:
+// when a print bridge last polled and what it reported, when a till was last used.
+// It is buffered in memory and written in batches, so a burst of polls costs one
+// write, and a write lost to a crash only leaves a timestamp a little behind.
+type TelemetryKind string
+
+const (
+	TelemetryPrintBridgeSeen   TelemetryKind = "print_bridge_seen"   // SubjectID is the bridge's ID
+	TelemetryBillingDeviceSeen TelemetryKind = "billing_device_seen" // SubjectID is the device's ID
+)
+
+type TelemetryWrite struct {
+	Kind       TelemetryKind
+	BusinessID string
+	SubjectID  string
+	At         time.Time
+	// Fields to set alongside the time, by their stored name; for a print bridge,
+	// printers and version
+	Fields map[string]interface{}
+}
+
+// Key identifies what the write is about; writes with the same key are merged
+func (w TelemetryWrite) Key() string {
+	return string(w.Kind) + ":" + w.BusinessID + ":" + w.SubjectID
+}
+
+// Merge folds a later write about the same subject into w, keeping the latest time
+// and the latest value of each field
+func (w *TelemetryWrite) Merge(later TelemetryWrite) {
+	if later.At.After(w.At) {
+		w.At = later.At
+	}
+	if len(later.Fields) == 0 {
+		return
+	}
+	fields := make(map[string]interface{}, len(w.Fields)+len(later.Fields))
+	for name, value := range w.Fields {
+		fields[name] = value
+	}
+	for name, value := range later.Fields {
+		fields[name] = value
+	}
+	w.Fields = fields
+}
+
+type TelemetryRepository interface {
+	// WriteBatch applies the writes with one unordered bulk write per collection; a
+	// write whose subject no longer exists is skipped
+	WriteBatch(writes []TelemetryWrite) error
+}
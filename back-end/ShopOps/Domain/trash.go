@@ -0,0 +1,15 @@
+package Domain
+
+import "time"
+
+// Deleted products, customers and suppliers stay in the shop's trash, where they can
+// be restored, for TrashRetention before they are purged for good
+const TrashRetention = 30 * 24 * time.Hour
+
+// Trash is everything a shop has deleted and can still restore, most recently
+// deleted first
+type Trash struct {
+	Products  []Product  `json:"products"`
+	Customers []Customer `json:"customers"`
+	Suppliers []Supplier `json:"suppliers"`
+}
@@ -37,7 +37,7 @@ const (
 type RegisterRequest struct {
 	Name     string `json:"name" validate:"required"`
 	Email    string `json:"email" validate:"omitempty,email"`
-	Phone    string `json:"phone" validate:"required"`
+	Phone    string `json:"phone" validate:"required,phone"`
 	Password string `json:"password" validate:"required,min=6"`
 }
 
@@ -54,7 +54,7 @@ type LoginResponse struct {
 type UpdateUserRequest struct {
 	Name  string `json:"name" validate:"omitempty"`
 	Email string `json:"email" validate:"omitempty,email"`
-	Phone string `json:"phone" validate:"omitempty"`
+	Phone string `json:"phone" validate:"omitempty,phone"`
 }
 
 type UserRepository interface {
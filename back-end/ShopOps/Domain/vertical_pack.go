@@ -0,0 +1,222 @@
+package Domain
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// VerticalPack bundles what a kind of shop needs beyond the basics: catalog
+// categories, units and tax rates, custom fields, a receipt template and saved
+// reports. A pack can be installed at onboarding or any time later, and installed
+// again when a new version comes out. Reinstalling only changes what the pack
+// itself wrote and the shop has not changed since; nothing is ever deleted.
+type VerticalPack struct {
+	Code        string    `json:"code"`
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Version     int       `json:"version"` // Raised whenever the contents change
+	Categories  []string  `json:"categories"`
+	Units       []string  `json:"units"`
+	TaxRates    []TaxRate `json:"tax_rates"`
+
+	CustomFields     []CreateCustomFieldRequest     `json:"custom_fields"`
+	ReceiptTemplates []CreateReceiptTemplateRequest `json:"receipt_templates"`
+	ReportPresets    []CreateSavedReportRequest     `json:"report_presets"`
+}
+
+// PackItemKind is a kind of record a pack creates
+type PackItemKind string
+
+const (
+	PackItemCustomField     PackItemKind = "custom_field"
+	PackItemReceiptTemplate PackItemKind = "receipt_template"
+	PackItemReportPreset    PackItemKind = "report_preset"
+)
+
+// InstalledPack is a pack installed in a shop and the records it wrote, so the
+// next install can tell the records the shop changed from those it left alone
+type InstalledPack struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
+	Code        string             `bson:"code" json:"code"`
+	Version     int                `bson:"version" json:"version"`
+	Items       []PackItem         `bson:"items" json:"items"`
+	InstalledBy primitive.ObjectID `bson:"installed_by" json:"installed_by"` // Who last installed or updated it
+	InstalledAt time.Time          `bson:"installed_at" json:"installed_at"` // First install
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+// PackItem is a record the pack wrote. Key names it within the pack: a custom
+// field's entity and key, or a template's or report's name.
+type PackItem struct {
+	Kind     PackItemKind       `bson:"kind" json:"kind"`
+	Key      string             `bson:"key" json:"key"`
+	RecordID primitive.ObjectID `bson:"record_id" json:"record_id"`
+	SpecHash string             `bson:"spec_hash" json:"-"` // Of the pack's version of the item
+	Written  string             `bson:"written" json:"-"`   // Of the record as the pack last left it
+}
+
+// Item returns the pack's item of the kind and key, or nil
+func (p *InstalledPack) Item(kind PackItemKind, key string) *PackItem {
+	for i := range p.Items {
+		if p.Items[i].Kind == kind && p.Items[i].Key == key {
+			return &p.Items[i]
+		}
+	}
+	return nil
+}
+
+// PackOutcome is what an install did with one of the pack's items
+type PackOutcome string
+
+const (
+	PackOutcomeCreated   PackOutcome = "created"
+	PackOutcomeUpdated   PackOutcome = "updated"
+	PackOutcomeUnchanged PackOutcome = "unchanged"
+	PackOutcomeKept      PackOutcome = "kept"    // The shop changed it, or had its own by that name; left as it is
+	PackOutcomeSkipped   PackOutcome = "skipped" // The shop deleted it; not brought back
+	PackOutcomeFailed    PackOutcome = "failed"
+)
+
+type PackItemResult struct {
+	Kind    PackItemKind `json:"kind"`
+	Key     string       `json:"key"`
+	Outcome PackOutcome  `json:"outcome"`
+	Note    string       `json:"note,omitempty"`
+}
+
+type PackInstallResult struct {
+	Code            string           `json:"code"`
+	Version         int              `json:"version"`
+	PreviousVersion int              `json:"previous_version,omitempty"` // 0 on a first install
+	Items           []PackItemResult `json:"items"`
+}
+
+// PackStatus is a pack as offered to a shop
+type PackStatus struct {
+	Code             string `json:"code"`
+	Name             string `json:"name"`
+	Description      string `json:"description"`
+	Version          int    `json:"version"`
+	InstalledVersion int    `json:"installed_version,omitempty"` // 0 when not installed
+	UpdateAvailable  bool   `json:"update_available"`
+}
+
+type InstalledPackRepository interface {
+	Find(businessID, code string) (*InstalledPack, error)
+	FindByBusiness(businessID string) ([]InstalledPack, error)
+	// Save inserts the shop's record of the pack or replaces it
+	Save(pack *InstalledPack) error
+}
+
+func packBound(v float64) *float64 {
+	return &v
+}
+
+// VerticalPacks are the packs a shop can install
+var VerticalPacks = []VerticalPack{pharmacyPack, butcheryPack, electronicsPack}
+
+// FindVerticalPack returns the pack with the code, or nil
+func FindVerticalPack(code string) *VerticalPack {
+	for i := range VerticalPacks {
+		if VerticalPacks[i].Code == code {
+			return &VerticalPacks[i]
+		}
+	}
+	return nil
+}
+
+var pharmacyPack = VerticalPack{
+	Code:        "pharmacy",
+	Name:        "Pharmacy",
+	Description: "Batch numbers, expiry dates and prescriptions on medicines, VAT-exempt medicines, and reports on margins by category and fast movers",
+	Version:     1,
+	Categories: []string{"Prescription Medicines", "Over-the-Counter Medicines", "Vitamins & Supplements",
+		"Baby Care", "Personal Care", "Medical Supplies", "First Aid"},
+	Units:    []string{"tablet", "strip", "box", "bottle", "tube", "vial", "sachet"},
+	TaxRates: []TaxRate{defaultTax(taxVATExempt), taxVAT},
+	CustomFields: []CreateCustomFieldRequest{
+		{Entity: CustomFieldEntityProduct, Key: "batch_number", Label: "Batch number", Type: CustomFieldText, MaxLength: 40, Searchable: true, Position: 1},
+		{Entity: CustomFieldEntityProduct, Key: "expiry_date", Label: "Expiry date", Type: CustomFieldDate, Position: 2},
+		{Entity: CustomFieldEntityProduct, Key: "prescription_only", Label: "Prescription only", Type: CustomFieldBoolean, Position: 3},
+		{Entity: CustomFieldEntityProduct, Key: "dosage_form", Label: "Dosage form", Type: CustomFieldSelect, Position: 4,
+			Options: []string{"Tablet", "Capsule", "Syrup", "Injection", "Cream", "Drops", "Inhaler"}},
+		{Entity: CustomFieldEntitySale, Key: "prescription_number", Label: "Prescription number", Type: CustomFieldText, MaxLength: 40, Searchable: true, Position: 1},
+	},
+	ReceiptTemplates: []CreateReceiptTemplateRequest{
+		{Name: "Pharmacy receipt", PaperWidth: ReceiptPaperWidth80,
+			Footer: []string{"Take medicines only as directed.", "Keep out of reach of children.", "Medicines sold are not returnable."}},
+	},
+	ReportPresets: []CreateSavedReportRequest{
+		{Name: "Margins by category", Description: "Revenue, gross profit and margin for each category over the last month",
+			Definition: CustomReportDefinition{Dimensions: []ReportDimension{ReportDimensionCategory},
+				Measures: []ReportMeasure{ReportMeasureRevenue, ReportMeasureGrossProfit, ReportMeasureMargin}},
+			Schedule: ReportScheduleMonthly},
+		{Name: "Fast movers", Description: "The products selling the most units over the last 30 days, to reorder in time",
+			Definition: CustomReportDefinition{Dimensions: []ReportDimension{ReportDimensionProduct},
+				Measures: []ReportMeasure{ReportMeasureQuantity, ReportMeasureRevenue}, RangeDays: 30, Limit: 50},
+			Schedule: ReportScheduleWeekly},
+	},
+}
+
+var butcheryPack = VerticalPack{
+	Code:        "butchery",
+	Name:        "Butchery",
+	Description: "Meat sold by weight with the animal and cut recorded, a narrow receipt for counter printers, and a daily report of weight and takings by product",
+	Version:     1,
+	Categories:  []string{"Beef", "Goat", "Lamb", "Chicken", "Fish", "Offal", "Minced Meat"},
+	Units:       []string{"kg", "g", "pcs"},
+	TaxRates:    []TaxRate{defaultTax(taxVAT), taxTOT},
+	CustomFields: []CreateCustomFieldRequest{
+		{Entity: CustomFieldEntityProduct, Key: "animal", Label: "Animal", Type: CustomFieldSelect, Position: 1,
+			Options: []string{"Beef", "Goat", "Lamb", "Chicken", "Fish"}},
+		{Entity: CustomFieldEntityProduct, Key: "cut", Label: "Cut", Type: CustomFieldSelect, Position: 2,
+			Options: []string{"Tenderloin (Filet)", "Sirloin", "Ribs", "Shoulder", "Leg", "Brisket", "Mince", "Bone-in", "Boneless"}},
+		{Entity: CustomFieldEntityProduct, Key: "slaughter_date", Label: "Slaughter date", Type: CustomFieldDate, Position: 3},
+		{Entity: CustomFieldEntitySale, Key: "preparation", Label: "Preparation", Type: CustomFieldSelect, Position: 1,
+			Options: []string{"Whole", "Sliced", "Cubed", "Minced", "Tibs cut", "Kitfo"}},
+	},
+	ReceiptTemplates: []CreateReceiptTemplateRequest{
+		{Name: "Butchery receipt", PaperWidth: ReceiptPaperWidth58,
+			Footer: []string{"Keep refrigerated.", "Weights are as shown on the scale."}},
+	},
+	ReportPresets: []CreateSavedReportRequest{
+		{Name: "Daily weight and takings", Description: "Kilograms sold and revenue for each product, each day",
+			Definition: CustomReportDefinition{Dimensions: []ReportDimension{ReportDimensionProduct},
+				Measures: []ReportMeasure{ReportMeasureQuantity, ReportMeasureRevenue, ReportMeasureGrossProfit}},
+			Schedule: ReportScheduleDaily},
+	},
+}
+
+var electronicsPack = VerticalPack{
+	Code:        "electronics",
+	Name:        "Electronics",
+	Description: "Serial numbers, brands and warranties on products and sales, warranty terms on receipts, and reports on profit by product and by payment method",
+	Version:     1,
+	Categories: []string{"Phones", "Phone Accessories", "Computers", "Computer Accessories", "TV & Audio",
+		"Home Appliances", "Cables & Chargers", "Batteries"},
+	Units:    []string{"pcs", "set", "box", "m"},
+	TaxRates: []TaxRate{defaultTax(taxVAT)},
+	CustomFields: []CreateCustomFieldRequest{
+		{Entity: CustomFieldEntityProduct, Key: "brand", Label: "Brand", Type: CustomFieldText, MaxLength: 60, Searchable: true, Position: 1},
+		{Entity: CustomFieldEntityProduct, Key: "model", Label: "Model", Type: CustomFieldText, MaxLength: 60, Searchable: true, Position: 2},
+		{Entity: CustomFieldEntityProduct, Key: "warranty_months", Label: "Warranty (months)", Type: CustomFieldNumber, Position: 3,
+			Min: packBound(0), Max: packBound(60)},
+		{Entity: CustomFieldEntitySale, Key: "serial_number", Label: "Serial number / IMEI", Type: CustomFieldText, MaxLength: 40, Searchable: true, Position: 1},
+	},
+	ReceiptTemplates: []CreateReceiptTemplateRequest{
+		{Name: "Electronics receipt", PaperWidth: ReceiptPaperWidth80,
+			Footer: []string{"Keep this receipt for warranty claims.", "Warranty does not cover physical or water damage."}},
+	},
+	ReportPresets: []CreateSavedReportRequest{
+		{Name: "Profit by product", Description: "Units, revenue and gross profit for each product over the last month",
+			Definition: CustomReportDefinition{Dimensions: []ReportDimension{ReportDimensionProduct},
+				Measures: []ReportMeasure{ReportMeasureQuantity, ReportMeasureRevenue, ReportMeasureGrossProfit}, SortBy: ReportMeasureGrossProfit},
+			Schedule: ReportScheduleMonthly},
+		{Name: "Takings by payment method", Description: "Revenue and transactions for each payment method, each week",
+			Definition: CustomReportDefinition{Dimensions: []ReportDimension{ReportDimensionPaymentMethod},
+				Measures: []ReportMeasure{ReportMeasureRevenue, ReportMeasureTransactions}},
+			Schedule: ReportScheduleWeekly},
+	},
+}
@@ -0,0 +1,143 @@
+package Domain
+
+import (
+	"context"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Webhook is a URL a shop has registered to be told about events, so its own
+// systems (an ERP, an accounting package) can follow along without polling
+type Webhook struct {
+	ID          primitive.ObjectID `bson:"_id,omitempty" json:"id"`
+	BusinessID  primitive.ObjectID `bson:"business_id" json:"business_id"`
+	URL         string             `bson:"url" json:"url"`
+	Events      []WebhookEvent     `bson:"events" json:"events"`
+	Description string             `bson:"description,omitempty" json:"description,omitempty"`
+	Secret      string             `bson:"secret" json:"-"` // Signs every delivery; shown once, when created or rotated
+	Active      bool               `bson:"active" json:"active"`
+	CreatedBy   primitive.ObjectID `bson:"created_by" json:"created_by"`
+	CreatedAt   time.Time          `bson:"created_at" json:"created_at"`
+	UpdatedAt   time.Time          `bson:"updated_at" json:"updated_at"`
+}
+
+type WebhookEvent string
+
+const (
+	WebhookEventSaleCreated     WebhookEvent = "sale.created"
+	WebhookEventStockLow        WebhookEvent = "stock.low" // A sale or adjustment took a product below its minimum stock
+	WebhookEventBackupCompleted WebhookEvent = "backup.completed"
+	WebhookEventBackupFailed    WebhookEvent = "backup.failed" // A backup ran out of attempts
+	WebhookEventSyncFailed      WebhookEvent = "sync.failed"   // A device's sync batch had items rejected
+)
+
+func (e WebhookEvent) IsValid() bool {
+	switch e {
+	case WebhookEventSaleCreated, WebhookEventStockLow, WebhookEventBackupCompleted, WebhookEventBackupFailed,
+		WebhookEventSyncFailed:
+		return true
+	}
+	return false
+}
+
+// WebhookDelivery is the log of one event sent to one webhook, across its attempts
+type WebhookDelivery struct {
+	ID         primitive.ObjectID    `bson:"_id,omitempty" json:"id"`
+	BusinessID primitive.ObjectID    `bson:"business_id" json:"business_id"`
+	WebhookID  primitive.ObjectID    `bson:"webhook_id" json:"webhook_id"`
+	Event      WebhookEvent          `bson:"event" json:"event"`
+	Payload    string                `bson:"payload" json:"payload"`                         // Request body, exactly as signed
+	ReplayOf   *primitive.ObjectID   `bson:"replay_of,omitempty" json:"replay_of,omitempty"` // Delivery this one resends
+	Status     WebhookDeliveryStatus `bson:"status" json:"status"`
+	Attempts   []WebhookAttempt      `bson:"attempts" json:"attempts"`
+	CreatedAt  time.Time             `bson:"created_at" json:"created_at"`
+	UpdatedAt  time.Time             `bson:"updated_at" json:"updated_at"`
+}
+
+type WebhookDeliveryStatus string
+
+const (
+	WebhookDeliveryPending   WebhookDeliveryStatus = "pending" // Queued, or waiting to retry
+	WebhookDeliverySucceeded WebhookDeliveryStatus = "succeeded"
+	WebhookDeliveryFailed    WebhookDeliveryStatus = "failed" // Out of attempts; can be replayed
+)
+
+// WebhookAttempt is one POST of a delivery
+type WebhookAttempt struct {
+	At           time.Time `bson:"at" json:"at"`
+	StatusCode   int       `bson:"status_code,omitempty" json:"status_code,omitempty"`
+	ResponseBody string    `bson:"response_body,omitempty" json:"response_body,omitempty"` // First KB of the receiver's reply
+	Error        string    `bson:"error,omitempty" json:"error,omitempty"`
+	DurationMs   int64     `bson:"duration_ms" json:"duration_ms"`
+}
+
+// StockLowEvent is the data of a stock.low event
+type StockLowEvent struct {
+	ProductID string  `json:"product_id"`
+	Name      string  `json:"name"`
+	SKU       string  `json:"sku,omitempty"`
+	Stock     float64 `json:"stock"`
+	MinStock  float64 `json:"min_stock"`
+}
+
+// BackupFailedEvent is the data of a backup.failed event
+type BackupFailedEvent struct {
+	JobID    string `json:"job_id"`
+	Error    string `json:"error"`
+	Attempts int    `json:"attempts"`
+}
+
+// SyncFailedEvent is the data of a sync.failed event
+type SyncFailedEvent struct {
+	DeviceID string   `json:"device_id"`
+	Failed   int      `json:"failed"`
+	Total    int      `json:"total"`
+	Errors   []string `json:"errors"` // The first few, for a hint of what went wrong
+}
+
+// WebhookEnvelope is the body of every delivery
+type WebhookEnvelope struct {
+	ID         string       `json:"id"`                 // Delivery ID; replays get a new one, so receivers can de-duplicate on the event's own data
+	EventID    string       `json:"event_id,omitempty"` // The same on every delivery of one event, including the rare duplicate; replays keep it
+	Event      WebhookEvent `json:"event"`
+	BusinessID string       `json:"business_id"`
+	CreatedAt  time.Time    `json:"created_at"`
+	Data       interface{}  `json:"data"`
+}
+
+type CreateWebhookRequest struct {
+	URL         string         `json:"url" validate:"required,url,max=2048"`
+	Events      []WebhookEvent `json:"events" validate:"required,min=1"`
+	Description string         `json:"description,omitempty" validate:"max=200"`
+}
+
+type UpdateWebhookRequest struct {
+	URL         *string        `json:"url,omitempty" validate:"omitempty,url,max=2048"`
+	Events      []WebhookEvent `json:"events,omitempty"`
+	Description *string        `json:"description,omitempty" validate:"omitempty,max=200"`
+	Active      *bool          `json:"active,omitempty"`
+}
+
+// WebhookSecretResponse carries a webhook's signing secret, the only time it is shown
+type WebhookSecretResponse struct {
+	Webhook
+	Secret string `json:"secret"`
+}
+
+type WebhookRepository interface {
+	Create(webhook *Webhook) error
+	// FindInBusiness returns the webhook when the shop registered it
+	FindInBusiness(ctx context.Context, id string) (*Webhook, error)
+	FindByBusinessID(businessID string) ([]Webhook, error)
+	// FindSubscribed returns the shop's active webhooks for event
+	FindSubscribed(businessID string, event WebhookEvent) ([]Webhook, error)
+	Update(webhook *Webhook) error
+	Delete(id string) error
+
+	CreateDelivery(delivery *WebhookDelivery) error
+	FindDeliveryByID(id string) (*WebhookDelivery, error)
+	FindDeliveries(webhookID string, limit int) ([]WebhookDelivery, error)
+	// RecordAttempt appends an attempt to the delivery's log and sets its status
+	RecordAttempt(id string, attempt WebhookAttempt, status WebhookDeliveryStatus) error
+}
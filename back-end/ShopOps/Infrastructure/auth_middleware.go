@@ -1,7 +1,7 @@
 package Infrastructure
 
 import (
-	"net/http"
+	"context"
 	"strings"
 
 	Domain "ShopOps/Domain"
@@ -13,90 +13,122 @@ func AuthMiddleware(jwtService JWTService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Authorization header is required"})
-			c.Abort()
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Authorization header is required"))
 			return
 		}
 
-		tokenString := strings.TrimPrefix(authHeader, "Bearer ")
-		if tokenString == authHeader {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Bearer token is required"})
-			c.Abort()
+		principal, appErr := Authenticate(jwtService, authHeader)
+		if appErr != nil {
+			AbortWithError(c, appErr)
 			return
 		}
 
-		token, err := jwtService.ValidateToken(tokenString)
-		if err != nil || !token.Valid {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid or expired token"})
-			c.Abort()
-			return
+		// Support staff acting as the user may look but not change anything
+		if principal.Impersonator != "" {
+			if !isReadOnlyMethod(c.Request.Method) {
+				AbortWithError(c, Domain.AccessDeniedError("Impersonation sessions are read-only"))
+				return
+			}
+			c.Set("impersonator", principal.Impersonator)
 		}
 
-		userID, err := jwtService.ExtractUserID(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to extract user ID from token"})
-			c.Abort()
-			return
-		}
+		c.Set("userID", principal.UserID)
+		c.Set("phone", principal.Phone)
+		c.Set("role", principal.Role)
+		c.Next()
+	}
+}
 
-		phone, err := jwtService.ExtractPhone(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to extract phone from token"})
-			c.Abort()
-			return
-		}
+// Principal is the user a request was authenticated as
+type Principal struct {
+	UserID       string
+	Phone        string
+	Role         string
+	Impersonator string // Support staff acting as the user, if any
+}
 
-		role, err := jwtService.ExtractRole(token)
-		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": "Failed to extract role from token"})
-			c.Abort()
-			return
-		}
+type principalKey struct{}
 
-		c.Set("userID", userID)
-		c.Set("phone", phone)
-		c.Set("role", role)
-		c.Next()
-	}
+// WithPrincipal returns ctx acting as principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
 }
 
-func BusinessMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		businessID := c.Param("businessId")
-		if businessID == "" {
-			businessID = c.Query("business_id")
-		}
+// PrincipalFromContext is the user a gRPC call or GraphQL query was authenticated as
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok
+}
 
-		if businessID == "" {
-			c.JSON(http.StatusBadRequest, gin.H{"error": "Business ID is required"})
-			c.Abort()
-			return
-		}
+// Authenticate checks the "Bearer <token>" value of an Authorization header, for the
+// REST middleware and the gRPC interceptor alike
+func Authenticate(jwtService JWTService, authHeader string) (*Principal, *Domain.AppError) {
+	tokenString := strings.TrimPrefix(authHeader, "Bearer ")
+	if tokenString == authHeader {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Bearer token is required")
+	}
 
-		c.Set("businessID", businessID)
-		c.Next()
+	token, err := jwtService.ValidateToken(tokenString)
+	if err != nil || !token.Valid {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Invalid or expired token")
 	}
+
+	userID, err := jwtService.ExtractUserID(token)
+	if err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Failed to extract user ID from token")
+	}
+
+	phone, err := jwtService.ExtractPhone(token)
+	if err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Failed to extract phone from token")
+	}
+
+	role, err := jwtService.ExtractRole(token)
+	if err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Failed to extract role from token")
+	}
+
+	return &Principal{
+		UserID:       userID,
+		Phone:        phone,
+		Role:         role,
+		Impersonator: jwtService.ExtractImpersonator(token),
+	}, nil
 }
 
 func OwnerOnlyMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		role, exists := c.Get("role")
 		if !exists {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Role not found in context"})
-			c.Abort()
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeAccessDenied, "Role not found in context"))
 			return
 		}
 
 		roleStr, ok := role.(string)
 		if !ok {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Invalid role type"})
-			c.Abort()
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeAccessDenied, "Invalid role type"))
 			return
 		}
 
 		if roleStr != string(Domain.RoleBusinessOwner) && roleStr != string(Domain.RoleAdmin) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Only business owners can perform this action"})
-			c.Abort()
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeAccessDenied, "Only business owners can perform this action"))
+			return
+		}
+
+		c.Next()
+	}
+}
+
+func AdminOnlyMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		role, exists := c.Get("role")
+		if !exists {
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeAccessDenied, "Role not found in context"))
+			return
+		}
+
+		if roleStr, ok := role.(string); !ok || roleStr != string(Domain.RoleAdmin) {
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeAccessDenied, "Only administrators can perform this action"))
 			return
 		}
 
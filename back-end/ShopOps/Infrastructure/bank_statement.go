@@ -0,0 +1,253 @@
+package Infrastructure
+
+import (
+	"crypto/sha1"
+	"encoding/csv"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// statementFormat is how one bank or wallet lays out its CSV export. Exports
+// start with a few lines about the account before the table, so the header row
+// is found by its column names; each column may go by any of a few names, as
+// the layout changes between internet banking versions.
+type statementFormat struct {
+	date        []string
+	reference   []string
+	description []string
+	credit      []string // Money in, or a signed amount when debit is empty
+	debit       []string
+	balance     []string
+	status      []string // Lines whose status is not one of completed are skipped
+	completed   []string
+	dateLayouts []string
+}
+
+var statementFormats = map[Domain.StatementSource]statementFormat{
+	Domain.StatementCBE: {
+		date:        []string{"posting date", "transaction date", "date", "value date"},
+		reference:   []string{"reference", "ref no", "ref. no", "reference no", "ftnumber"},
+		description: []string{"narrative", "description", "particulars", "details"},
+		credit:      []string{"credit", "credit amount", "cr"},
+		debit:       []string{"debit", "debit amount", "dr"},
+		balance:     []string{"balance", "running balance"},
+		dateLayouts: []string{"02/01/2006", "02-Jan-2006", "02-Jan-06", "02 Jan 2006", "2006-01-02", "02/01/2006 15:04:05"},
+	},
+	Domain.StatementAwash: {
+		date:        []string{"transaction date", "trans date", "date", "value date"},
+		reference:   []string{"reference no", "reference number", "reference", "ref no", "transaction id"},
+		description: []string{"description", "narration", "remark", "particulars"},
+		credit:      []string{"deposit", "deposits", "credit"},
+		debit:       []string{"withdrawal", "withdrawals", "debit"},
+		balance:     []string{"balance", "available balance"},
+		dateLayouts: []string{"02/01/2006", "2006-01-02", "02-Jan-2006", "02-Jan-06", "02/01/2006 15:04"},
+	},
+	Domain.StatementTelebirr: {
+		date:        []string{"completion time", "transaction time", "date", "time"},
+		reference:   []string{"receipt no.", "receipt no", "transaction no", "transaction id"},
+		description: []string{"details", "transaction type", "description", "opposite party"},
+		credit:      []string{"paid in", "credit", "amount"},
+		debit:       []string{"withdrawn", "paid out", "debit"},
+		balance:     []string{"balance", "balance after"},
+		status:      []string{"transaction status", "status"},
+		completed:   []string{"completed", "success", "successful"},
+		dateLayouts: []string{"2006-01-02 15:04:05", "02/01/2006 15:04:05", "02/01/2006 15:04", "2006/01/02 15:04:05", "2006-01-02", "02/01/2006"},
+	},
+}
+
+// statementHeaderRows is how far into the file the header row is looked for
+const statementHeaderRows = 40
+
+// ParseStatement reads a statement export into its lines, dated in loc. Rows
+// that are not transactions, such as opening and closing balances and totals,
+// are skipped.
+func ParseStatement(source Domain.StatementSource, r io.Reader, loc *time.Location) ([]Domain.StatementLine, error) {
+	format, ok := statementFormats[source]
+	if !ok {
+		return nil, Domain.ValidationError("source must be cbe, awash or telebirr")
+	}
+
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	var columns map[string]int
+	for row := 0; columns == nil; row++ {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) || row >= statementHeaderRows {
+			return nil, Domain.ValidationError(fmt.Sprintf("no %s statement table found; export the statement as CSV", source))
+		}
+		if err != nil {
+			return nil, Domain.ValidationError(fmt.Sprintf("could not read the statement: %v", err))
+		}
+		columns = format.header(record)
+	}
+
+	var lines []Domain.StatementLine
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, Domain.ValidationError(fmt.Sprintf("could not read the statement: %v", err))
+		}
+
+		line, ok := format.line(record, columns, loc)
+		if !ok {
+			continue
+		}
+		line.Source = source
+		line.Fingerprint = statementFingerprint(line)
+		lines = append(lines, line)
+	}
+
+	if len(lines) == 0 {
+		return nil, Domain.ValidationError("the statement has no transactions")
+	}
+	return lines, nil
+}
+
+// header maps the format's columns to their place in the row, or returns nil
+// when the row is not the header
+func (f statementFormat) header(record []string) map[string]int {
+	names := make(map[string]int, len(record))
+	for i, cell := range record {
+		names[strings.ToLower(strings.TrimSpace(strings.TrimPrefix(cell, "\ufeff")))] = i
+	}
+
+	columns := map[string]int{}
+	for key, aliases := range map[string][]string{
+		"date": f.date, "reference": f.reference, "description": f.description,
+		"credit": f.credit, "debit": f.debit, "balance": f.balance, "status": f.status,
+	} {
+		for _, alias := range aliases {
+			if i, ok := names[alias]; ok {
+				columns[key] = i
+				break
+			}
+		}
+	}
+
+	if _, ok := columns["date"]; !ok {
+		return nil
+	}
+	if _, ok := columns["credit"]; !ok {
+		return nil
+	}
+	return columns
+}
+
+func (f statementFormat) line(record []string, columns map[string]int, loc *time.Location) (Domain.StatementLine, bool) {
+	cell := func(key string) string {
+		if i, ok := columns[key]; ok && i < len(record) {
+			return strings.TrimSpace(record[i])
+		}
+		return ""
+	}
+
+	date, ok := parseStatementDate(cell("date"), f.dateLayouts, loc)
+	if !ok {
+		return Domain.StatementLine{}, false
+	}
+
+	if len(f.completed) > 0 && cell("status") != "" {
+		status := strings.ToLower(cell("status"))
+		done := false
+		for _, c := range f.completed {
+			done = done || status == c
+		}
+		if !done {
+			return Domain.StatementLine{}, false
+		}
+	}
+
+	credit, creditOK := parseStatementAmount(cell("credit"))
+	debit, debitOK := parseStatementAmount(cell("debit"))
+	if !creditOK && !debitOK {
+		return Domain.StatementLine{}, false
+	}
+	// Debits are shown positive in their own column, or negative in a single one
+	if debit < 0 {
+		debit = -debit
+	}
+	amount := credit - debit
+	if amount == 0 {
+		return Domain.StatementLine{}, false
+	}
+
+	line := Domain.StatementLine{
+		Date:        date,
+		Reference:   cell("reference"),
+		Description: cell("description"),
+		Amount:      amount,
+	}
+	if balance, ok := parseStatementAmount(cell("balance")); ok {
+		line.Balance = &balance
+	}
+	return line, true
+}
+
+func parseStatementDate(value string, layouts []string, loc *time.Location) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, value, loc); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseStatementAmount reads amounts written as 1,250.00, ETB 1250, (1,250.00)
+// or -1250; false for blanks and dashes
+func parseStatementAmount(value string) (Domain.Money, bool) {
+	value = strings.TrimSpace(value)
+	for _, unit := range []string{"ETB", "Birr", "birr", "Br"} {
+		value = strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(value, unit), unit))
+	}
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+	value = strings.ReplaceAll(strings.ReplaceAll(value, ",", ""), " ", "")
+	if value == "" || value == "-" {
+		return 0, false
+	}
+
+	amount, err := Domain.ParseMoney(value)
+	if err != nil {
+		return 0, false
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, true
+}
+
+// statementFingerprint identifies a line by what the bank printed, so the same
+// line exported on two statements is known as one
+func statementFingerprint(line Domain.StatementLine) string {
+	balance := ""
+	if line.Balance != nil {
+		balance = line.Balance.String()
+	}
+	sum := sha1.Sum([]byte(strings.Join([]string{
+		string(line.Source),
+		line.Date.Format(time.RFC3339),
+		line.Reference,
+		strings.ToLower(line.Description),
+		line.Amount.String(),
+		balance,
+	}, "|")))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,67 @@
+package Infrastructure
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// biTokenPrefix marks BI keys so they are easy to tell from user tokens in a BI
+// tool's connection settings
+const biTokenPrefix = "bi_"
+
+// NewBIToken generates a BI key's token and the hash that is stored
+func NewBIToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate BI key: %w", err)
+	}
+	token = biTokenPrefix + hex.EncodeToString(buf)
+	return token, HashBIToken(token), nil
+}
+
+// HashBIToken is how a key is looked up; only the hash is kept
+func HashBIToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// BIKeyAuthenticator finds the key a token belongs to
+type BIKeyAuthenticator interface {
+	// AuthenticateBIKey returns the key, or nil for an unknown token
+	AuthenticateBIKey(token string) (*Domain.BIKey, error)
+}
+
+// BIKeyAuth accepts requests bearing a BI key in place of a user's token, and sets
+// the key and its business for the handlers
+func BIKeyAuth(auth BIKeyAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(token, biTokenPrefix) {
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeUnauthorized, "A BI key is required"))
+			return
+		}
+
+		key, err := auth.AuthenticateBIKey(token)
+		if err != nil {
+			JSONError(c, http.StatusInternalServerError, err, "")
+			c.Abort()
+			return
+		}
+		if key == nil {
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Invalid BI key"))
+			return
+		}
+
+		c.Set("biKey", key)
+		c.Set("businessID", key.BusinessID.Hex())
+		c.Next()
+	}
+}
@@ -0,0 +1,436 @@
+package Infrastructure
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// BillingGateway takes shops' subscription payments through one provider
+type BillingGateway interface {
+	Provider() Domain.BillingProvider
+	// Checkout starts paying for the plan and returns the page the owner pays on
+	Checkout(ctx context.Context, checkout BillingCheckout) (string, error)
+	// ParseWebhook checks a webhook's signature and reads what it reports. Events that
+	// do not concern subscriptions give a nil event.
+	ParseWebhook(ctx context.Context, body []byte, header http.Header) (*BillingEvent, error)
+	// Cancel stops renewals; the shop keeps the plan until the paid period ends
+	Cancel(ctx context.Context, subscription *Domain.Subscription) error
+}
+
+// BillingCheckout is a plan an owner is about to pay for
+type BillingCheckout struct {
+	BusinessID   string
+	BusinessName string
+	Email        string
+	Plan         Domain.Plan
+	ReturnURL    string
+	CustomerID   string // The provider's customer from an earlier subscription, if any
+}
+
+type BillingEventType string
+
+const (
+	BillingEventPaid          BillingEventType = "paid" // A period of the plan was paid for
+	BillingEventPaymentFailed BillingEventType = "payment_failed"
+	BillingEventUpdated       BillingEventType = "updated" // Plan or renewal changed at the provider
+	BillingEventCanceled      BillingEventType = "canceled"
+)
+
+// BillingEvent is a change in a subscription reported by a provider
+type BillingEvent struct {
+	ID             string // The provider's event ID, so repeats are applied once
+	Type           BillingEventType
+	BusinessID     string // Empty when the provider only names the subscription
+	Plan           Domain.PlanCode
+	CustomerID     string
+	SubscriptionID string
+	// When the paid period ends; nil when the provider does not say, in which case a
+	// payment buys a month
+	PeriodEnd         *time.Time
+	CancelAtPeriodEnd bool
+}
+
+// ErrBillingBadWebhook means a webhook failed its signature check or could not be read
+var ErrBillingBadWebhook = errors.New("invalid billing webhook")
+
+// BillingGateways are the providers shops can pay with
+type BillingGateways map[Domain.BillingProvider]BillingGateway
+
+// NewBillingGateways sets up each provider whose keys are configured
+func NewBillingGateways(cfg BillingConfig) BillingGateways {
+	client := &http.Client{Timeout: 30 * time.Second}
+	gateways := make(BillingGateways)
+	if cfg.ChapaSecretKey != "" {
+		gateways[Domain.BillingProviderChapa] = &chapaGateway{
+			client:        client,
+			baseURL:       strings.TrimRight(cfg.ChapaBaseURL, "/"),
+			secretKey:     cfg.ChapaSecretKey,
+			webhookSecret: cfg.ChapaWebhookSecret,
+		}
+	}
+	if cfg.StripeSecretKey != "" {
+		gateways[Domain.BillingProviderStripe] = &stripeGateway{
+			client:        client,
+			baseURL:       strings.TrimRight(cfg.StripeBaseURL, "/"),
+			secretKey:     cfg.StripeSecretKey,
+			webhookSecret: cfg.StripeWebhookSecret,
+			prices: map[Domain.PlanCode]string{
+				Domain.PlanBasic: cfg.StripePriceBasic,
+				Domain.PlanPro:   cfg.StripePricePro,
+			},
+		}
+	}
+	return gateways
+}
+
+// chapaGateway takes one-off payments in birr, each buying a month of the plan.
+// Chapa has no renewals, so owners pay again before the month runs out.
+type chapaGateway struct {
+	client        *http.Client
+	baseURL       string
+	secretKey     string
+	webhookSecret string
+}
+
+func (g *chapaGateway) Provider() Domain.BillingProvider { return Domain.BillingProviderChapa }
+
+// Chapa transaction references carry the shop and plan, as sub-<business>-<plan>-<random>
+const chapaRefPrefix = "sub-"
+
+func (g *chapaGateway) Checkout(ctx context.Context, checkout BillingCheckout) (string, error) {
+	nonce := make([]byte, 4)
+	_, _ = rand.Read(nonce)
+	txRef := fmt.Sprintf("%s%s-%s-%s", chapaRefPrefix, checkout.BusinessID, checkout.Plan.Code, hex.EncodeToString(nonce))
+
+	var reply struct {
+		Status  string          `json:"status"`
+		Message json.RawMessage `json:"message"`
+		Data    struct {
+			CheckoutURL string `json:"checkout_url"`
+		} `json:"data"`
+	}
+	err := postMobileMoney(ctx, g.client, http.MethodPost, g.baseURL+"/v1/transaction/initialize", g.header(), map[string]interface{}{
+		"amount":     formatMobileMoneyAmount(Domain.NewMoney(checkout.Plan.PriceETB)),
+		"currency":   "ETB",
+		"email":      checkout.Email,
+		"first_name": checkout.BusinessName,
+		"tx_ref":     txRef,
+		"return_url": checkout.ReturnURL,
+		"customization": map[string]string{
+			"title":       "ShopOps",
+			"description": checkout.Plan.Name + " plan, one month",
+		},
+	}, &reply)
+	if err != nil {
+		return "", fmt.Errorf("chapa: %w", err)
+	}
+	if reply.Status != "success" || reply.Data.CheckoutURL == "" {
+		return "", fmt.Errorf("chapa: %s", string(reply.Message))
+	}
+	return reply.Data.CheckoutURL, nil
+}
+
+func (g *chapaGateway) ParseWebhook(ctx context.Context, body []byte, header http.Header) (*BillingEvent, error) {
+	mac := hmac.New(sha256.New, []byte(g.webhookSecret))
+	mac.Write(body)
+	if !hmac.Equal([]byte(header.Get("X-Chapa-Signature")), []byte(hex.EncodeToString(mac.Sum(nil)))) {
+		return nil, fmt.Errorf("%w: bad signature", ErrBillingBadWebhook)
+	}
+
+	var webhook struct {
+		Event  string `json:"event"`
+		TxRef  string `json:"tx_ref"`
+		Status string `json:"status"`
+	}
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBillingBadWebhook, err)
+	}
+	if webhook.Event != "charge.success" || !strings.HasPrefix(webhook.TxRef, chapaRefPrefix) {
+		return nil, nil
+	}
+
+	parts := strings.Split(strings.TrimPrefix(webhook.TxRef, chapaRefPrefix), "-")
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("%w: unexpected tx_ref %q", ErrBillingBadWebhook, webhook.TxRef)
+	}
+	plan, ok := Domain.Plans[Domain.PlanCode(parts[1])]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown plan in tx_ref %q", ErrBillingBadWebhook, webhook.TxRef)
+	}
+
+	// The webhook says it was paid; Chapa's own record says how much
+	var verify struct {
+		Status string `json:"status"`
+		Data   struct {
+			Status   string      `json:"status"`
+			Amount   json.Number `json:"amount"`
+			Currency string      `json:"currency"`
+		} `json:"data"`
+	}
+	err := postMobileMoney(ctx, g.client, http.MethodGet, g.baseURL+"/v1/transaction/verify/"+url.PathEscape(webhook.TxRef), g.header(), nil, &verify)
+	if err != nil {
+		return nil, fmt.Errorf("chapa verify: %w", err)
+	}
+	amount, _ := verify.Data.Amount.Float64()
+	if verify.Data.Status != "success" || verify.Data.Currency != "ETB" || amount < plan.PriceETB {
+		return nil, fmt.Errorf("chapa verify: %s is %s for %s %s, not %.2f ETB",
+			webhook.TxRef, verify.Data.Status, verify.Data.Amount, verify.Data.Currency, plan.PriceETB)
+	}
+
+	return &BillingEvent{
+		ID:         webhook.TxRef,
+		Type:       BillingEventPaid,
+		BusinessID: parts[0],
+		Plan:       plan.Code,
+	}, nil
+}
+
+func (g *chapaGateway) Cancel(ctx context.Context, subscription *Domain.Subscription) error {
+	return nil
+}
+
+func (g *chapaGateway) header() http.Header {
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+g.secretKey)
+	return header
+}
+
+// stripeGateway sells monthly subscriptions in dollars through Stripe Checkout.
+// Stripe renews them and reports each invoice by webhook.
+type stripeGateway struct {
+	client        *http.Client
+	baseURL       string
+	secretKey     string
+	webhookSecret string
+	prices        map[Domain.PlanCode]string
+}
+
+func (g *stripeGateway) Provider() Domain.BillingProvider { return Domain.BillingProviderStripe }
+
+// Webhooks signed longer ago than this are refused, so captured ones cannot be replayed
+const stripeWebhookTolerance = 5 * time.Minute
+
+func (g *stripeGateway) Checkout(ctx context.Context, checkout BillingCheckout) (string, error) {
+	form := url.Values{}
+	form.Set("mode", "subscription")
+	form.Set("line_items[0][price]", g.prices[checkout.Plan.Code])
+	form.Set("line_items[0][quantity]", "1")
+	form.Set("success_url", checkout.ReturnURL)
+	form.Set("cancel_url", checkout.ReturnURL)
+	form.Set("client_reference_id", checkout.BusinessID)
+	form.Set("subscription_data[metadata][business_id]", checkout.BusinessID)
+	form.Set("subscription_data[metadata][plan]", string(checkout.Plan.Code))
+	if checkout.CustomerID != "" {
+		form.Set("customer", checkout.CustomerID)
+	} else if checkout.Email != "" {
+		form.Set("customer_email", checkout.Email)
+	}
+
+	var session struct {
+		URL string `json:"url"`
+	}
+	if err := g.post(ctx, "/v1/checkout/sessions", form, &session); err != nil {
+		return "", err
+	}
+	return session.URL, nil
+}
+
+func (g *stripeGateway) ParseWebhook(ctx context.Context, body []byte, header http.Header) (*BillingEvent, error) {
+	if err := g.verify(body, header.Get("Stripe-Signature")); err != nil {
+		return nil, err
+	}
+
+	var event struct {
+		ID   string `json:"id"`
+		Type string `json:"type"`
+		Data struct {
+			Object json.RawMessage `json:"object"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &event); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrBillingBadWebhook, err)
+	}
+
+	switch event.Type {
+	case "invoice.paid", "invoice.payment_failed":
+		var invoice struct {
+			Customer            string `json:"customer"`
+			Subscription        string `json:"subscription"`
+			SubscriptionDetails struct {
+				Metadata map[string]string `json:"metadata"`
+			} `json:"subscription_details"`
+			Lines struct {
+				Data []struct {
+					Period struct {
+						End int64 `json:"end"`
+					} `json:"period"`
+					Price struct {
+						ID string `json:"id"`
+					} `json:"price"`
+				} `json:"data"`
+			} `json:"lines"`
+		}
+		if err := json.Unmarshal(event.Data.Object, &invoice); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBillingBadWebhook, err)
+		}
+		if invoice.Subscription == "" {
+			return nil, nil
+		}
+
+		result := &BillingEvent{
+			ID:             event.ID,
+			Type:           BillingEventPaymentFailed,
+			BusinessID:     invoice.SubscriptionDetails.Metadata["business_id"],
+			CustomerID:     invoice.Customer,
+			SubscriptionID: invoice.Subscription,
+		}
+		if event.Type == "invoice.paid" {
+			result.Type = BillingEventPaid
+			for _, line := range invoice.Lines.Data {
+				if plan := g.planFor(line.Price.ID); plan != "" {
+					result.Plan = plan
+					end := time.Unix(line.Period.End, 0)
+					result.PeriodEnd = &end
+				}
+			}
+		}
+		return result, nil
+
+	case "customer.subscription.updated", "customer.subscription.deleted":
+		var subscription struct {
+			ID                string            `json:"id"`
+			Customer          string            `json:"customer"`
+			CancelAtPeriodEnd bool              `json:"cancel_at_period_end"`
+			CurrentPeriodEnd  int64             `json:"current_period_end"`
+			Metadata          map[string]string `json:"metadata"`
+			Items             struct {
+				Data []struct {
+					Price struct {
+						ID string `json:"id"`
+					} `json:"price"`
+				} `json:"data"`
+			} `json:"items"`
+		}
+		if err := json.Unmarshal(event.Data.Object, &subscription); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrBillingBadWebhook, err)
+		}
+
+		result := &BillingEvent{
+			ID:                event.ID,
+			Type:              BillingEventUpdated,
+			BusinessID:        subscription.Metadata["business_id"],
+			CustomerID:        subscription.Customer,
+			SubscriptionID:    subscription.ID,
+			CancelAtPeriodEnd: subscription.CancelAtPeriodEnd,
+		}
+		if event.Type == "customer.subscription.deleted" {
+			result.Type = BillingEventCanceled
+		}
+		if subscription.CurrentPeriodEnd > 0 {
+			end := time.Unix(subscription.CurrentPeriodEnd, 0)
+			result.PeriodEnd = &end
+		}
+		for _, item := range subscription.Items.Data {
+			if plan := g.planFor(item.Price.ID); plan != "" {
+				result.Plan = plan
+			}
+		}
+		return result, nil
+	}
+
+	return nil, nil
+}
+
+func (g *stripeGateway) Cancel(ctx context.Context, subscription *Domain.Subscription) error {
+	form := url.Values{}
+	form.Set("cancel_at_period_end", "true")
+	var reply struct{}
+	return g.post(ctx, "/v1/subscriptions/"+url.PathEscape(subscription.ProviderSubscriptionID), form, &reply)
+}
+
+func (g *stripeGateway) planFor(priceID string) Domain.PlanCode {
+	for plan, id := range g.prices {
+		if id == priceID {
+			return plan
+		}
+	}
+	return ""
+}
+
+// verify checks the Stripe-Signature header, t=<unix seconds>,v1=<hex HMAC-SHA256 of "<t>.<body>">
+func (g *stripeGateway) verify(body []byte, signature string) error {
+	var timestamp string
+	var signatures []string
+	for _, part := range strings.Split(signature, ",") {
+		key, value, _ := strings.Cut(part, "=")
+		switch key {
+		case "t":
+			timestamp = value
+		case "v1":
+			signatures = append(signatures, value)
+		}
+	}
+
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: no timestamp", ErrBillingBadWebhook)
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > stripeWebhookTolerance || age < -stripeWebhookTolerance {
+		return fmt.Errorf("%w: signed too long ago", ErrBillingBadWebhook)
+	}
+
+	mac := hmac.New(sha256.New, []byte(g.webhookSecret))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(body)
+	expected := hex.EncodeToString(mac.Sum(nil))
+	for _, candidate := range signatures {
+		if hmac.Equal([]byte(candidate), []byte(expected)) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: bad signature", ErrBillingBadWebhook)
+}
+
+func (g *stripeGateway) post(ctx context.Context, path string, form url.Values, out interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, g.baseURL+path, strings.NewReader(form.Encode()))
+	if err != nil {
+		return err
+	}
+	req.SetBasicAuth(g.secretKey, "")
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := g.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("stripe request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("stripe: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		var problem struct {
+			Error struct {
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		_ = json.Unmarshal(data, &problem)
+		return fmt.Errorf("stripe returned status %d: %s", resp.StatusCode, problem.Error.Message)
+	}
+	return json.Unmarshal(data, out)
+}
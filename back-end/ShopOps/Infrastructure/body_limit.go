@@ -0,0 +1,121 @@
+package Infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimits caps request body sizes. Routes maps a route pattern, as registered, to
+// its own limit; every other route gets Default. Zero means no limit.
+type BodyLimits struct {
+	Default int64
+	Routes  map[string]int64
+}
+
+// BodyLimitMiddleware rejects a body over the route's limit with a 413, up front when
+// the client declares its length and otherwise as soon as the handler reads past it.
+// Register it before the routes it covers.
+func BodyLimitMiddleware(limits BodyLimits) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		limit := limits.Default
+		if routeLimit, ok := limits.Routes[c.FullPath()]; ok {
+			limit = routeLimit
+		}
+		if limit <= 0 || c.Request.Body == nil || c.Request.Body == http.NoBody {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > limit {
+			// Don't read the rest of an upload we are refusing
+			c.Header("Connection", "close")
+			AbortWithError(c, PayloadTooLargeError(limit))
+			return
+		}
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, limit)
+		c.Next()
+	}
+}
+
+// PayloadTooLargeError is the 413 for a body or file over limit bytes
+func PayloadTooLargeError(limit int64) *Domain.AppError {
+	return Domain.NewAppError(Domain.ErrCodePayloadTooLarge,
+		fmt.Sprintf("Request is larger than the %s limit", formatBytes(limit)))
+}
+
+func formatBytes(n int64) string {
+	switch {
+	case n >= 1<<20 && n%(1<<20) == 0:
+		return fmt.Sprintf("%dMB", n>>20)
+	case n >= 1<<10 && n%(1<<10) == 0:
+		return fmt.Sprintf("%dKB", n>>10)
+	default:
+		return fmt.Sprintf("%d bytes", n)
+	}
+}
+
+// UploadedFile is a file field streamed from a multipart request
+type UploadedFile struct {
+	io.Reader
+	Filename    string
+	ContentType string
+}
+
+// FormFileStream returns the named file field of a multipart request without
+// buffering it, unlike FormFile, which holds up to 32MB in memory and spills the
+// rest to disk before the handler sees a byte. Reading more than maxSize bytes fails
+// with a 413 error. Fields after the file are not read.
+func FormFileStream(c *gin.Context, field string, maxSize int64) (*UploadedFile, error) {
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "Request must be multipart/form-data")
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("%s file is required", field))
+		}
+		if err != nil {
+			return nil, err
+		}
+		if part.FormName() != field || part.FileName() == "" {
+			continue
+		}
+		return &UploadedFile{
+			Reader:      &fileSizeLimiter{part: part, remaining: maxSize, limit: maxSize},
+			Filename:    part.FileName(),
+			ContentType: part.Header.Get("Content-Type"),
+		}, nil
+	}
+}
+
+// fileSizeLimiter fails once a file part grows past its limit
+type fileSizeLimiter struct {
+	part      *multipart.Part
+	remaining int64
+	limit     int64
+}
+
+func (l *fileSizeLimiter) Read(p []byte) (int, error) {
+	if l.remaining < 0 {
+		return 0, PayloadTooLargeError(l.limit)
+	}
+	// Read one byte past the limit to tell a file of exactly the limit from a bigger one
+	if int64(len(p)) > l.remaining+1 {
+		p = p[:l.remaining+1]
+	}
+	n, err := l.part.Read(p)
+	l.remaining -= int64(n)
+	if l.remaining < 0 {
+		return n + int(l.remaining), PayloadTooLargeError(l.limit)
+	}
+	return n, err
+}
@@ -0,0 +1,194 @@
+package Infrastructure
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CatalogCache holds shops' products in memory for the POS hot paths, barcode scans
+// and price quotes, which otherwise read the database on every item rung up. A
+// shop's catalog is loaded on its first lookup from the loader the caller passes,
+// and kept until it reaches its TTL or is invalidated: by a write to the shop's
+// products through the API, or by a sync batch from one of its devices, which may
+// carry product changes made offline. Beyond maxShops the least recently used shop
+// is dropped.
+//
+// Prices are never stale beyond an invalidation; stock is, as sales do not
+// invalidate the catalog, so the stock of a cached product is only a guide.
+//
+// Like SearchIndex it is per process, so on a multi-instance deployment another
+// instance can serve products up to its TTL old.
+type CatalogCache struct {
+	ttl      time.Duration
+	maxShops int
+
+	mu          sync.Mutex
+	shops       map[string]*shopCatalog
+	generations map[string]uint64 // Shop to its invalidation count
+}
+
+// CatalogLoader returns every product in a shop's catalog, in the order lookups
+// should prefer them when two share a barcode or PLU
+type CatalogLoader func(businessID string) ([]Domain.Product, error)
+
+type shopCatalog struct {
+	byID      map[string]*Domain.Product
+	byBarcode map[string]*Domain.Product
+	byPLU     map[string]*Domain.Product
+	loadedAt  time.Time
+	usedAt    time.Time
+}
+
+var CatalogCacheLoads = NewCounterVec("shopops_catalog_cache_loads_total",
+	"Shop catalogs loaded into memory, by reason (cold, expired, invalidated)", "reason")
+
+func NewCatalogCache(ttl time.Duration, maxShops int) *CatalogCache {
+	return &CatalogCache{
+		ttl:         ttl,
+		maxShops:    maxShops,
+		shops:       make(map[string]*shopCatalog),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Product returns a copy of the shop's product with the ID, nil when the catalog
+// has none
+func (cc *CatalogCache) Product(businessID, productID string, load CatalogLoader) (*Domain.Product, error) {
+	catalog, err := cc.shop(businessID, load)
+	if err != nil {
+		return nil, err
+	}
+	return copyProduct(catalog.byID[productID]), nil
+}
+
+// ProductByBarcode returns a copy of the shop's product with the barcode, nil when
+// the catalog has none
+func (cc *CatalogCache) ProductByBarcode(businessID, barcode string, load CatalogLoader) (*Domain.Product, error) {
+	catalog, err := cc.shop(businessID, load)
+	if err != nil {
+		return nil, err
+	}
+	return copyProduct(catalog.byBarcode[barcode]), nil
+}
+
+// ProductByPLU returns a copy of the shop's product with the scale PLU, nil when the
+// catalog has none
+func (cc *CatalogCache) ProductByPLU(businessID, plu string, load CatalogLoader) (*Domain.Product, error) {
+	catalog, err := cc.shop(businessID, load)
+	if err != nil {
+		return nil, err
+	}
+	return copyProduct(catalog.byPLU[plu]), nil
+}
+
+// Invalidates drops the shop's catalog once a write on the route succeeds. Reads on
+// the route pass through untouched, so it can be applied to a whole route group.
+func (cc *CatalogCache) Invalidates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		cc.Invalidate(c.Param("businessId"))
+	}
+}
+
+// Invalidate drops the shop's catalog, for writes made outside a request
+func (cc *CatalogCache) Invalidate(businessID string) {
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+
+	cc.generations[businessID]++
+	delete(cc.shops, businessID)
+}
+
+func (cc *CatalogCache) shop(businessID string, load CatalogLoader) (*shopCatalog, error) {
+	cc.mu.Lock()
+	catalog, ok := cc.shops[businessID]
+	generation := cc.generations[businessID]
+	reason := "cold"
+	if ok && time.Since(catalog.loadedAt) < cc.ttl {
+		catalog.usedAt = time.Now()
+		cc.mu.Unlock()
+		return catalog, nil
+	}
+	if ok {
+		reason = "expired"
+	} else if generation > 0 {
+		reason = "invalidated"
+	}
+	cc.mu.Unlock()
+
+	products, err := load(businessID)
+	if err != nil {
+		return nil, err
+	}
+	catalog = buildShopCatalog(products)
+	CatalogCacheLoads.Inc(reason)
+
+	cc.mu.Lock()
+	defer cc.mu.Unlock()
+	// A catalog loaded across an invalidation may be missing the write; use it for
+	// this lookup but leave it to be loaded again
+	if cc.generations[businessID] == generation {
+		if _, exists := cc.shops[businessID]; !exists && len(cc.shops) >= cc.maxShops {
+			cc.evictLocked()
+		}
+		cc.shops[businessID] = catalog
+	}
+	return catalog, nil
+}
+
+// evictLocked drops the least recently used shop
+func (cc *CatalogCache) evictLocked() {
+	var oldest string
+	var oldestAt time.Time
+	for businessID, catalog := range cc.shops {
+		if oldest == "" || catalog.usedAt.Before(oldestAt) {
+			oldest, oldestAt = businessID, catalog.usedAt
+		}
+	}
+	delete(cc.shops, oldest)
+}
+
+func buildShopCatalog(products []Domain.Product) *shopCatalog {
+	now := time.Now()
+	catalog := &shopCatalog{
+		byID:      make(map[string]*Domain.Product, len(products)),
+		byBarcode: make(map[string]*Domain.Product),
+		byPLU:     make(map[string]*Domain.Product),
+		loadedAt:  now,
+		usedAt:    now,
+	}
+
+	for i := range products {
+		product := &products[i]
+		catalog.byID[product.ID.Hex()] = product
+		if _, taken := catalog.byBarcode[product.Barcode]; product.Barcode != "" && !taken {
+			catalog.byBarcode[product.Barcode] = product
+		}
+		if _, taken := catalog.byPLU[product.PLU]; product.PLU != "" && !taken {
+			catalog.byPLU[product.PLU] = product
+		}
+	}
+
+	return catalog
+}
+
+// copyProduct keeps callers from changing the cached product
+func copyProduct(product *Domain.Product) *Domain.Product {
+	if product == nil {
+		return nil
+	}
+	copied := *product
+	return &copied
+}
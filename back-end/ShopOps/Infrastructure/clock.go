@@ -0,0 +1,20 @@
+package Infrastructure
+
+import "time"
+
+// Clock tells the time, so services that depend on it can be given a fixed or
+// advancing clock in tests
+type Clock interface {
+	Now() time.Time
+}
+
+type systemClock struct{}
+
+// SystemClock is the real wall clock
+func SystemClock() Clock {
+	return systemClock{}
+}
+
+func (systemClock) Now() time.Time {
+	return time.Now()
+}
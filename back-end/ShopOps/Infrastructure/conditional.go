@@ -0,0 +1,116 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ConditionalGET lets clients revalidate what they already hold, so an app that
+// reloads the catalog on every start only downloads it when it changed. Successful
+// GET responses carry an ETag hashed from the body, and a Last-Modified when the
+// handler set one with SetLastModified. A request whose If-None-Match matches the
+// ETag, or without one whose If-Modified-Since is no older than Last-Modified, gets
+// a 304 with no body.
+//
+// The handler still runs, so this saves the transfer rather than the work; register
+// it ahead of a ResponseCache to save both on a hit.
+func ConditionalGET() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.Request.Method != http.MethodGet && c.Request.Method != http.MethodHead {
+			c.Next()
+			return
+		}
+
+		writer := &bufferingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Next()
+		c.Writer = writer.ResponseWriter
+
+		if writer.Status() != http.StatusOK {
+			writer.flush()
+			return
+		}
+
+		header := writer.Header()
+		etag := fmt.Sprintf(`"%x"`, sha256.Sum256(writer.body.Bytes()))
+		header.Set("ETag", etag)
+		if header.Get("Cache-Control") == "" {
+			// Per user, and always worth asking whether it changed
+			header.Set("Cache-Control", "private, no-cache")
+		}
+
+		if !notModified(c.Request, etag, header.Get("Last-Modified")) {
+			writer.flush()
+			return
+		}
+		header.Del("Content-Type")
+		header.Del("Content-Length")
+		writer.ResponseWriter.WriteHeader(http.StatusNotModified)
+		writer.ResponseWriter.WriteHeaderNow()
+	}
+}
+
+// SetLastModified sets when the response's data last changed, for If-Modified-Since.
+// Only set it where that time covers everything in the response: a list's newest
+// record says nothing of records deleted since.
+func SetLastModified(ctx *gin.Context, modified time.Time) {
+	if modified.IsZero() {
+		return
+	}
+	ctx.Header("Last-Modified", modified.UTC().Format(http.TimeFormat))
+}
+
+// notModified follows RFC 9110: If-None-Match when sent, otherwise If-Modified-Since
+func notModified(r *http.Request, etag, lastModified string) bool {
+	if match := r.Header.Get("If-None-Match"); match != "" {
+		for _, candidate := range strings.Split(match, ",") {
+			candidate = strings.TrimSpace(candidate)
+			// Weak comparison: the body is the same either way
+			if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+				return true
+			}
+		}
+		return false
+	}
+
+	since := r.Header.Get("If-Modified-Since")
+	if since == "" || lastModified == "" {
+		return false
+	}
+	sinceTime, err := http.ParseTime(since)
+	if err != nil {
+		return false
+	}
+	modified, err := http.ParseTime(lastModified)
+	return err == nil && !modified.After(sinceTime)
+}
+
+// bufferingWriter holds the body back until the status it goes out with is known
+type bufferingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *bufferingWriter) Write(data []byte) (int, error) {
+	return w.body.Write(data)
+}
+
+func (w *bufferingWriter) WriteString(s string) (int, error) {
+	return w.body.WriteString(s)
+}
+
+func (w *bufferingWriter) Written() bool {
+	return w.body.Len() > 0 || w.ResponseWriter.Written()
+}
+
+func (w *bufferingWriter) flush() {
+	if w.body.Len() > 0 {
+		w.ResponseWriter.Write(w.body.Bytes())
+	}
+}
@@ -0,0 +1,745 @@
+package Infrastructure
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Config is every setting the server reads, loaded once at startup and handed to the
+// parts that need it. Each field names its environment variable and default in its
+// tags. Values come from, in increasing priority: the defaults, the JSON file named
+// by CONFIG_FILE (nested like the JSON tags, e.g. {"mongo": {"max_pool_size": 200}}),
+// and the environment, including .env.
+type Config struct {
+	Server         ServerConfig         `json:"server"`
+	Mongo          MongoConfig          `json:"mongo"`
+	Auth           AuthConfig           `json:"auth"`
+	Metrics        MetricsConfig        `json:"metrics"`
+	Logging        LoggingConfig        `json:"logging"`
+	Tracing        TracingConfig        `json:"tracing"`
+	ErrorReporting ErrorReportingConfig `json:"error_reporting"`
+	Storage        StorageConfig        `json:"storage"`
+	SMS            SMSConfig            `json:"sms"`
+	Cache          CacheConfig          `json:"cache"`
+	Support        SupportConfig        `json:"support"`
+	Webhooks       WebhookConfig        `json:"webhooks"`
+	Telegram       TelegramConfig       `json:"telegram"`
+	MobileMoney    MobileMoneyConfig    `json:"mobile_money"`
+	Billing        BillingConfig        `json:"billing"`
+	Email          EmailConfig          `json:"email"`
+	Push           PushConfig           `json:"push"`
+	ExchangeRates  ExchangeRateConfig   `json:"exchange_rates"`
+	Archive        ArchiveConfig        `json:"archive"`
+	Retention      RetentionConfig      `json:"retention"`
+	Telemetry      TelemetryConfig      `json:"telemetry"`
+	Demo           DemoConfig           `json:"demo"`
+}
+
+type ServerConfig struct {
+	Port               int           `json:"port" env:"PORT" default:"8080"`
+	GRPCPort           int           `json:"grpc_port" env:"GRPC_PORT" default:"0"` // Internal gRPC API; 0 leaves it off
+	Mode               string        `json:"mode" env:"GIN_MODE" default:"debug"`   // debug, release or test
+	ShutdownTimeout    time.Duration `json:"shutdown_timeout" env:"SHUTDOWN_TIMEOUT" default:"30s"`
+	ShutdownDrainDelay time.Duration `json:"shutdown_drain_delay" env:"SHUTDOWN_DRAIN_DELAY" default:"5s"`
+	BodyLimitDefault   int64         `json:"body_limit_default" env:"BODY_LIMIT_DEFAULT" default:"1048576"`
+	BodyLimitSync      int64         `json:"body_limit_sync" env:"BODY_LIMIT_SYNC" default:"20971520"`
+	BodyLimitUpload    int64         `json:"body_limit_upload" env:"BODY_LIMIT_UPLOAD" default:"6291456"`
+	RateLimits         bool          `json:"rate_limits" env:"RATE_LIMITS" default:"true"` // Off only for load tests, which would otherwise be throttled
+
+	// Connection limits, so slow or idle clients cannot hold connections open. The
+	// read and write timeouts cover a whole request, so they must allow a sync batch
+	// or an export over a slow mobile link.
+	ReadHeaderTimeout time.Duration `json:"read_header_timeout" env:"READ_HEADER_TIMEOUT" default:"10s"`
+	ReadTimeout       time.Duration `json:"read_timeout" env:"READ_TIMEOUT" default:"2m"`
+	WriteTimeout      time.Duration `json:"write_timeout" env:"WRITE_TIMEOUT" default:"2m"`
+	IdleTimeout       time.Duration `json:"idle_timeout" env:"IDLE_TIMEOUT" default:"2m"` // Keep-alive connections between requests
+	MaxHeaderBytes    int           `json:"max_header_bytes" env:"MAX_HEADER_BYTES" default:"65536"`
+
+	// HTTP/2 is served over TLS when a certificate is given, and otherwise in the
+	// clear to clients that ask for it, such as a load balancer in front
+	HTTP2           bool   `json:"http2" env:"HTTP2" default:"true"`
+	HTTP2MaxStreams int    `json:"http2_max_streams" env:"HTTP2_MAX_STREAMS" default:"250"` // Concurrent requests per connection
+	TLSCertFile     string `json:"tls_cert_file" env:"TLS_CERT_FILE"`
+	TLSKeyFile      string `json:"tls_key_file" env:"TLS_KEY_FILE"`
+}
+
+type MongoConfig struct {
+	URL                string        `json:"url" env:"MONGODB_URL" secret:"uri"`
+	Database           string        `json:"database" env:"MONGO_DB" default:"Shopops_DB"`
+	ReadURLs           []string      `json:"read_urls" env:"MONGODB_READ_URLS" secret:"uri"`
+	MaxPoolSize        uint64        `json:"max_pool_size" env:"MONGO_MAX_POOL_SIZE" default:"100"`
+	MinPoolSize        uint64        `json:"min_pool_size" env:"MONGO_MIN_POOL_SIZE" default:"0"`
+	MaxConnIdleTime    time.Duration `json:"max_conn_idle_time" env:"MONGO_MAX_CONN_IDLE_TIME" default:"5m"`
+	MaxConnecting      uint64        `json:"max_connecting" env:"MONGO_MAX_CONNECTING" default:"2"`
+	SlowQueryThreshold time.Duration `json:"slow_query_threshold" env:"MONGO_SLOW_QUERY_THRESHOLD" default:"500ms"`
+	MigrateOnBoot      bool          `json:"migrate_on_boot" env:"MIGRATE_ON_BOOT" default:"false"`
+	MigrateTimeout     time.Duration `json:"migrate_timeout" env:"MIGRATE_TIMEOUT" default:"10m"`
+}
+
+type AuthConfig struct {
+	JWTSecret        string `json:"jwt_secret" env:"JWT_SECRET" default:"shopops-secret-key-change-in-production" secret:"true"`
+	JWTRefreshSecret string `json:"jwt_refresh_secret" env:"JWT_REFRESH_SECRET" default:"shopops-refresh-secret-key-change-in-production" secret:"true"`
+}
+
+type MetricsConfig struct {
+	Username string `json:"username" env:"METRICS_USERNAME"`
+	Password string `json:"password" env:"METRICS_PASSWORD" secret:"true"`
+}
+
+type LoggingConfig struct {
+	Level string `json:"level" env:"LOG_LEVEL" default:"info"` // debug, info, warn or error
+}
+
+type TracingConfig struct {
+	Endpoint    string  `json:"endpoint" env:"OTEL_EXPORTER_OTLP_ENDPOINT"` // Tracing is off when empty
+	SampleRatio float64 `json:"sample_ratio" env:"OTEL_TRACES_SAMPLER_ARG" default:"1"`
+	ServiceName string  `json:"service_name" env:"OTEL_SERVICE_NAME" default:"shopops"`
+}
+
+type ErrorReportingConfig struct {
+	DSN         string `json:"dsn" env:"SENTRY_DSN" secret:"uri"`    // Reporting is off when empty
+	Environment string `json:"environment" env:"SENTRY_ENVIRONMENT"` // Defaults to the server mode
+	Release     string `json:"release" env:"SENTRY_RELEASE"`
+}
+
+type StorageConfig struct {
+	UploadDir     string `json:"upload_dir" env:"UPLOAD_DIR" default:"uploads"`
+	UploadBaseURL string `json:"upload_base_url" env:"UPLOAD_BASE_URL" default:"/uploads"`
+	// Signs the expiring links to stored files handed out in emails
+	SigningKey string `json:"signing_key" env:"UPLOAD_SIGNING_KEY" default:"shopops-upload-signing-key-change-in-production" secret:"true"`
+}
+
+type SMSConfig struct {
+	Provider                 string `json:"provider" env:"SMS_PROVIDER" default:"log"` // log, afromessage, geezsms or africastalking
+	MaxPerSecond             int    `json:"max_per_second" env:"SMS_MAX_PER_SECOND" default:"5"`
+	AfroMessageToken         string `json:"afromessage_token" env:"AFROMESSAGE_TOKEN" secret:"true"`
+	AfroMessageIdentifierID  string `json:"afromessage_identifier_id" env:"AFROMESSAGE_IDENTIFIER_ID"`
+	AfroMessageSender        string `json:"afromessage_sender" env:"AFROMESSAGE_SENDER"`
+	GeezSMSToken             string `json:"geezsms_token" env:"GEEZSMS_TOKEN" secret:"true"`
+	GeezSMSShortcodeID       string `json:"geezsms_shortcode_id" env:"GEEZSMS_SHORTCODE_ID"`
+	AfricasTalkingUsername   string `json:"africastalking_username" env:"AT_USERNAME"`
+	AfricasTalkingAPIKey     string `json:"africastalking_api_key" env:"AT_API_KEY" secret:"true"`
+	AfricasTalkingSenderName string `json:"africastalking_sender" env:"AT_SENDER"`
+}
+
+// EmailConfig is how the server sends mail. Everything goes out from FromAddress,
+// which must be on a domain the provider is set up to send for. Bounces come back to
+// /api/v1/integrations/email/{provider}/events: SendGrid's signed event webhook, or
+// an SNS topic subscribed to SES notifications.
+type EmailConfig struct {
+	Provider    string `json:"provider" env:"EMAIL_PROVIDER" default:"log"` // log, smtp, ses or sendgrid
+	FromAddress string `json:"from_address" env:"EMAIL_FROM_ADDRESS"`
+	FromName    string `json:"from_name" env:"EMAIL_FROM_NAME" default:"ShopOps"`
+	// The web app, for links in emails
+	AppBaseURL string `json:"app_base_url" env:"EMAIL_APP_BASE_URL"`
+
+	SMTPHost     string `json:"smtp_host" env:"SMTP_HOST"`
+	SMTPPort     int    `json:"smtp_port" env:"SMTP_PORT" default:"587"`
+	SMTPUsername string `json:"smtp_username" env:"SMTP_USERNAME"`
+	SMTPPassword string `json:"smtp_password" env:"SMTP_PASSWORD" secret:"true"`
+
+	SESRegion          string `json:"ses_region" env:"SES_REGION"`
+	SESAccessKeyID     string `json:"ses_access_key_id" env:"SES_ACCESS_KEY_ID"`
+	SESSecretAccessKey string `json:"ses_secret_access_key" env:"SES_SECRET_ACCESS_KEY" secret:"true"`
+
+	SendGridAPIKey string `json:"sendgrid_api_key" env:"SENDGRID_API_KEY" secret:"true"`
+	// The event webhook's verification key, base64 as SendGrid shows it
+	SendGridWebhookKey string `json:"sendgrid_webhook_key" env:"SENDGRID_WEBHOOK_KEY"`
+}
+
+type CacheConfig struct {
+	CatalogTTL         time.Duration `json:"catalog_ttl" env:"CACHE_TTL_CATALOG" default:"5m"`
+	ReportsTTL         time.Duration `json:"reports_ttl" env:"CACHE_TTL_REPORTS" default:"2m"`
+	ResponseMaxEntries int           `json:"response_max_entries" env:"RESPONSE_CACHE_MAX_ENTRIES" default:"10000"`
+	// Shops' search indexes held in memory; writes through the API rebuild them sooner
+	SearchTTL      time.Duration `json:"search_ttl" env:"SEARCH_INDEX_TTL" default:"1m"`
+	SearchMaxShops int           `json:"search_max_shops" env:"SEARCH_INDEX_MAX_SHOPS" default:"1000"`
+	// Shops' products held in memory for barcode scans and price quotes; product
+	// writes and sync batches reload them sooner
+	CatalogMemoryTTL      time.Duration `json:"catalog_memory_ttl" env:"CATALOG_CACHE_TTL" default:"10m"`
+	CatalogMemoryMaxShops int           `json:"catalog_memory_max_shops" env:"CATALOG_CACHE_MAX_SHOPS" default:"1000"`
+}
+
+type SupportConfig struct {
+	// Keys for the internal support API, as name:key pairs; the name is recorded in
+	// the audit log. The API is off when there are none.
+	APIKeys          []string      `json:"api_keys" env:"SUPPORT_API_KEYS" secret:"true"`
+	ImpersonationTTL time.Duration `json:"impersonation_ttl" env:"SUPPORT_IMPERSONATION_TTL" default:"15m"`
+	// Shop backups hold everything a shop has, so unlike uploads they are never served
+	BackupDir string `json:"backup_dir" env:"BACKUP_DIR" default:"backups"`
+}
+
+// ArchiveConfig moves sales and stock movements out of the live collections once
+// they are old, daily and on an admin's request
+type ArchiveConfig struct {
+	AfterDays int `json:"after_days" env:"ARCHIVE_AFTER_DAYS" default:"1095"` // 0 leaves the daily run off
+	BatchSize int `json:"batch_size" env:"ARCHIVE_BATCH_SIZE" default:"1000"`
+}
+
+// RetentionConfig is how the daily retention run works through what is due; how
+// long customers' details are kept is each shop's own setting
+type RetentionConfig struct {
+	BatchSize int `json:"batch_size" env:"RETENTION_BATCH_SIZE" default:"1000"`
+}
+
+// TelemetryConfig buffers device last-seen writes before they reach the database
+type TelemetryConfig struct {
+	FlushInterval time.Duration `json:"flush_interval" env:"TELEMETRY_FLUSH_INTERVAL" default:"10s"`
+	MaxPending    int           `json:"max_pending" env:"TELEMETRY_MAX_PENDING" default:"50000"` // Devices and bridges held between flushes
+}
+
+// DemoConfig limits the generated demo shops. Admins can create them either way;
+// Enabled opens the app's public "try demo" mode.
+type DemoConfig struct {
+	Enabled  bool          `json:"enabled" env:"DEMO_ENABLED" default:"false"`
+	TTL      time.Duration `json:"ttl" env:"DEMO_TTL" default:"72h"`             // How long a demo shop lasts unless sized otherwise
+	MaxShops int           `json:"max_shops" env:"DEMO_MAX_SHOPS" default:"200"` // Live demo shops before "try demo" turns people away
+}
+
+type WebhookConfig struct {
+	Timeout time.Duration `json:"timeout" env:"WEBHOOK_TIMEOUT" default:"10s"`
+	// Lets webhooks reach loopback and private addresses, for local development only
+	AllowPrivateTargets bool `json:"allow_private_targets" env:"WEBHOOK_ALLOW_PRIVATE_TARGETS" default:"false"`
+}
+
+// TelegramConfig is the owners' notification bot. Without a token messages go to the
+// server log. Telegram must be pointed at /api/v1/integrations/telegram/webhook with
+// setWebhook, passing WebhookSecret as its secret_token.
+type TelegramConfig struct {
+	BotToken      string `json:"bot_token" env:"TELEGRAM_BOT_TOKEN" secret:"true"`
+	BotUsername   string `json:"bot_username" env:"TELEGRAM_BOT_USERNAME"`
+	WebhookSecret string `json:"webhook_secret" env:"TELEGRAM_WEBHOOK_SECRET" secret:"true"`
+	APIURL        string `json:"api_url" env:"TELEGRAM_API_URL" default:"https://api.telegram.org"`
+}
+
+// PushConfig is how notifications reach owners' phones. Android devices are reached
+// through FCM with a service account of the Firebase project, iOS devices through
+// APNs with a token signing key. Without credentials for a platform its
+// notifications go to the server log. Keys are PEM, with line breaks written as \n
+// when set in the environment.
+type PushConfig struct {
+	FCMProjectID   string `json:"fcm_project_id" env:"FCM_PROJECT_ID"`
+	FCMClientEmail string `json:"fcm_client_email" env:"FCM_CLIENT_EMAIL"` // The service account's
+	FCMPrivateKey  string `json:"fcm_private_key" env:"FCM_PRIVATE_KEY" secret:"true"`
+
+	APNsKeyID  string `json:"apns_key_id" env:"APNS_KEY_ID"`
+	APNsTeamID string `json:"apns_team_id" env:"APNS_TEAM_ID"`
+	APNsKey    string `json:"apns_key" env:"APNS_KEY" secret:"true"` // The .p8 file's contents
+	APNsTopic  string `json:"apns_topic" env:"APNS_TOPIC"`           // The app's bundle ID
+	// Sends to the development APNs environment, for builds run from Xcode
+	APNsSandbox bool `json:"apns_sandbox" env:"APNS_SANDBOX" default:"false"`
+}
+
+// MobileMoneyConfig holds the merchant accounts sales can be paid to from customers'
+// wallets. A provider is offered once its credentials are set. Providers report
+// outcomes to CallbackBaseURL + /api/v1/integrations/payments/{provider}/callback.
+// RSA keys are PEM, with line breaks written as \n when set in the environment.
+type MobileMoneyConfig struct {
+	CallbackBaseURL string `json:"callback_base_url" env:"MOBILE_MONEY_CALLBACK_URL"` // e.g. https://api.example.com
+	// Offers every provider as a simulator that approves each payment, for development only
+	Sandbox bool `json:"sandbox" env:"MOBILE_MONEY_SANDBOX" default:"false"`
+
+	TelebirrBaseURL       string `json:"telebirr_base_url" env:"TELEBIRR_BASE_URL"`         // .../apiaccess/payment/gateway
+	TelebirrCheckoutURL   string `json:"telebirr_checkout_url" env:"TELEBIRR_CHECKOUT_URL"` // .../payment/web/paygate
+	TelebirrFabricAppID   string `json:"telebirr_fabric_app_id" env:"TELEBIRR_FABRIC_APP_ID"`
+	TelebirrAppSecret     string `json:"telebirr_app_secret" env:"TELEBIRR_APP_SECRET" secret:"true"`
+	TelebirrMerchantAppID string `json:"telebirr_merchant_app_id" env:"TELEBIRR_MERCHANT_APP_ID"`
+	TelebirrShortCode     string `json:"telebirr_short_code" env:"TELEBIRR_SHORT_CODE"`
+	TelebirrPrivateKey    string `json:"telebirr_private_key" env:"TELEBIRR_PRIVATE_KEY" secret:"true"` // Signs requests
+	TelebirrPublicKey     string `json:"telebirr_public_key" env:"TELEBIRR_PUBLIC_KEY"`                 // Telebirr's, to check callbacks
+
+	CBEBirrBaseURL    string `json:"cbe_birr_base_url" env:"CBE_BIRR_BASE_URL"`
+	CBEBirrMerchantID string `json:"cbe_birr_merchant_id" env:"CBE_BIRR_MERCHANT_ID"`
+	CBEBirrAPIKey     string `json:"cbe_birr_api_key" env:"CBE_BIRR_API_KEY" secret:"true"`
+
+	MPesaBaseURL            string `json:"mpesa_base_url" env:"MPESA_BASE_URL"` // e.g. https://apisandbox.safaricom.et
+	MPesaConsumerKey        string `json:"mpesa_consumer_key" env:"MPESA_CONSUMER_KEY"`
+	MPesaConsumerSecret     string `json:"mpesa_consumer_secret" env:"MPESA_CONSUMER_SECRET" secret:"true"`
+	MPesaShortCode          string `json:"mpesa_short_code" env:"MPESA_SHORT_CODE"`
+	MPesaPasskey            string `json:"mpesa_passkey" env:"MPESA_PASSKEY" secret:"true"`
+	MPesaInitiator          string `json:"mpesa_initiator" env:"MPESA_INITIATOR"` // API operator allowed to reverse payments
+	MPesaSecurityCredential string `json:"mpesa_security_credential" env:"MPESA_SECURITY_CREDENTIAL" secret:"true"`
+}
+
+func (c MobileMoneyConfig) telebirrEnabled() bool { return c.TelebirrFabricAppID != "" }
+func (c MobileMoneyConfig) cbeBirrEnabled() bool  { return c.CBEBirrMerchantID != "" }
+func (c MobileMoneyConfig) mpesaEnabled() bool    { return c.MPesaConsumerKey != "" }
+
+// BillingConfig is the shops' own subscription to ShopOps. Plan limits are only
+// enforced when Enabled is set, so shops are not cut off before billing is launched.
+// Chapa and Stripe must be pointed at /api/v1/integrations/billing/{provider}/webhook.
+type BillingConfig struct {
+	Enabled     bool          `json:"enabled" env:"BILLING_ENABLED" default:"false"`
+	TrialPeriod time.Duration `json:"trial_period" env:"BILLING_TRIAL_PERIOD" default:"336h"`
+	// How long a shop keeps its plan after a payment is missed
+	GracePeriod time.Duration `json:"grace_period" env:"BILLING_GRACE_PERIOD" default:"168h"`
+
+	ChapaSecretKey     string `json:"chapa_secret_key" env:"CHAPA_SECRET_KEY" secret:"true"`
+	ChapaWebhookSecret string `json:"chapa_webhook_secret" env:"CHAPA_WEBHOOK_SECRET" secret:"true"`
+	ChapaBaseURL       string `json:"chapa_base_url" env:"CHAPA_BASE_URL" default:"https://api.chapa.co"`
+
+	StripeSecretKey     string `json:"stripe_secret_key" env:"STRIPE_SECRET_KEY" secret:"true"`
+	StripeWebhookSecret string `json:"stripe_webhook_secret" env:"STRIPE_WEBHOOK_SECRET" secret:"true"`
+	StripePriceBasic    string `json:"stripe_price_basic" env:"STRIPE_PRICE_BASIC"` // Monthly price IDs
+	StripePricePro      string `json:"stripe_price_pro" env:"STRIPE_PRICE_PRO"`
+	StripeBaseURL       string `json:"stripe_base_url" env:"STRIPE_BASE_URL" default:"https://api.stripe.com"`
+}
+
+// ExchangeRateConfig is where rates are fetched for shops that do not set their own
+type ExchangeRateConfig struct {
+	APIURL  string        `json:"api_url" env:"EXCHANGE_RATE_API_URL" default:"https://open.er-api.com/v6/latest"` // Takes the base currency as the last path segment
+	Timeout time.Duration `json:"timeout" env:"EXCHANGE_RATE_TIMEOUT" default:"15s"`
+}
+
+// LoadConfig reads and validates the configuration. The error lists every problem
+// found, by the environment variable that sets it.
+func LoadConfig() (*Config, error) {
+	_ = LoadEnv()
+
+	cfg := &Config{}
+	var problems []string
+	setFields(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, path []string) (string, bool) {
+		return field.Tag.Get("default"), field.Tag.Get("default") != ""
+	}, &problems)
+
+	if file := os.Getenv("CONFIG_FILE"); file != "" {
+		values, err := readConfigFile(file)
+		if err != nil {
+			return nil, err
+		}
+		setFields(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, path []string) (string, bool) {
+			return lookupConfigFile(values, path)
+		}, &problems)
+	}
+
+	setFields(reflect.ValueOf(cfg).Elem(), func(field reflect.StructField, path []string) (string, bool) {
+		value := os.Getenv(field.Tag.Get("env"))
+		return value, value != ""
+	}, &problems)
+
+	problems = append(problems, cfg.validate()...)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("invalid configuration:\n  - %s", strings.Join(problems, "\n  - "))
+	}
+	return cfg, nil
+}
+
+func (cfg *Config) validate() []string {
+	var problems []string
+	add := func(format string, args ...interface{}) {
+		problems = append(problems, fmt.Sprintf(format, args...))
+	}
+
+	if cfg.Server.Port <= 0 || cfg.Server.Port > 65535 {
+		add("PORT must be between 1 and 65535, got %d", cfg.Server.Port)
+	}
+	if cfg.Server.GRPCPort < 0 || cfg.Server.GRPCPort > 65535 || (cfg.Server.GRPCPort != 0 && cfg.Server.GRPCPort == cfg.Server.Port) {
+		add("GRPC_PORT must be 0 or a port between 1 and 65535 other than PORT, got %d", cfg.Server.GRPCPort)
+	}
+	if !oneOf(cfg.Server.Mode, gin.DebugMode, gin.ReleaseMode, gin.TestMode) {
+		add("GIN_MODE must be debug, release or test, got %q", cfg.Server.Mode)
+	}
+	if cfg.Mongo.URL == "" {
+		add("MONGODB_URL is required, e.g. mongodb://localhost:27017")
+	} else if !strings.HasPrefix(cfg.Mongo.URL, "mongodb://") && !strings.HasPrefix(cfg.Mongo.URL, "mongodb+srv://") {
+		add("MONGODB_URL must start with mongodb:// or mongodb+srv://")
+	}
+	if cfg.Mongo.MaxPoolSize > 0 && cfg.Mongo.MinPoolSize > cfg.Mongo.MaxPoolSize {
+		add("MONGO_MIN_POOL_SIZE (%d) cannot exceed MONGO_MAX_POOL_SIZE (%d)", cfg.Mongo.MinPoolSize, cfg.Mongo.MaxPoolSize)
+	}
+	if cfg.Server.Mode == gin.ReleaseMode {
+		defaults := reflect.TypeOf(AuthConfig{})
+		if field, _ := defaults.FieldByName("JWTSecret"); cfg.Auth.JWTSecret == field.Tag.Get("default") {
+			add("JWT_SECRET must be set in release mode")
+		}
+		if field, _ := defaults.FieldByName("JWTRefreshSecret"); cfg.Auth.JWTRefreshSecret == field.Tag.Get("default") {
+			add("JWT_REFRESH_SECRET must be set in release mode")
+		}
+		if field, _ := reflect.TypeOf(StorageConfig{}).FieldByName("SigningKey"); cfg.Storage.SigningKey == field.Tag.Get("default") {
+			add("UPLOAD_SIGNING_KEY must be set in release mode")
+		}
+	}
+	if len(cfg.Auth.JWTSecret) < 16 || len(cfg.Auth.JWTRefreshSecret) < 16 {
+		add("JWT_SECRET and JWT_REFRESH_SECRET must be at least 16 characters")
+	}
+	if len(cfg.Storage.SigningKey) < 16 {
+		add("UPLOAD_SIGNING_KEY must be at least 16 characters")
+	}
+	if (cfg.Metrics.Username == "") != (cfg.Metrics.Password == "") {
+		add("METRICS_USERNAME and METRICS_PASSWORD must be set together")
+	}
+	if !oneOf(strings.ToLower(cfg.Logging.Level), "debug", "info", "warn", "error") {
+		add("LOG_LEVEL must be debug, info, warn or error, got %q", cfg.Logging.Level)
+	}
+	if cfg.Tracing.SampleRatio < 0 || cfg.Tracing.SampleRatio > 1 {
+		add("OTEL_TRACES_SAMPLER_ARG must be between 0 and 1, got %g", cfg.Tracing.SampleRatio)
+	}
+	if cfg.SMS.MaxPerSecond <= 0 {
+		add("SMS_MAX_PER_SECOND must be positive, got %d", cfg.SMS.MaxPerSecond)
+	}
+
+	names := make(map[string]bool)
+	for _, entry := range cfg.Support.APIKeys {
+		name, key, ok := strings.Cut(entry, ":")
+		switch {
+		case !ok || name == "":
+			add("SUPPORT_API_KEYS entries must be name:key pairs")
+		case len(key) < 32:
+			add("SUPPORT_API_KEYS key for %q must be at least 32 characters", name)
+		case names[name]:
+			add("SUPPORT_API_KEYS has more than one key named %q", name)
+		}
+		names[name] = true
+	}
+	if cfg.Support.BackupDir == cfg.Storage.UploadDir {
+		add("BACKUP_DIR must differ from UPLOAD_DIR")
+	}
+	if cfg.Support.ImpersonationTTL <= 0 || cfg.Support.ImpersonationTTL > time.Hour {
+		add("SUPPORT_IMPERSONATION_TTL must be between 1s and 1h, got %s", cfg.Support.ImpersonationTTL)
+	}
+	if cfg.Webhooks.Timeout <= 0 || cfg.Webhooks.Timeout > time.Minute {
+		add("WEBHOOK_TIMEOUT must be between 1s and 1m, got %s", cfg.Webhooks.Timeout)
+	}
+	if cfg.Webhooks.AllowPrivateTargets && cfg.Server.Mode == gin.ReleaseMode {
+		add("WEBHOOK_ALLOW_PRIVATE_TARGETS must not be set in release mode")
+	}
+	if cfg.Telegram.BotToken != "" && len(cfg.Telegram.WebhookSecret) < 16 {
+		add("TELEGRAM_WEBHOOK_SECRET must be at least 16 characters when TELEGRAM_BOT_TOKEN is set")
+	}
+
+	push := cfg.Push
+	if (push.FCMProjectID != "" || push.FCMClientEmail != "" || push.FCMPrivateKey != "") &&
+		(push.FCMProjectID == "" || push.FCMClientEmail == "" || push.FCMPrivateKey == "") {
+		add("FCM needs all of FCM_PROJECT_ID, FCM_CLIENT_EMAIL and FCM_PRIVATE_KEY")
+	}
+	if (push.APNsKeyID != "" || push.APNsTeamID != "" || push.APNsKey != "") &&
+		(push.APNsKeyID == "" || push.APNsTeamID == "" || push.APNsKey == "" || push.APNsTopic == "") {
+		add("APNs needs all of APNS_KEY_ID, APNS_TEAM_ID, APNS_KEY and APNS_TOPIC")
+	}
+
+	billing := cfg.Billing
+	if billing.TrialPeriod < 0 || billing.GracePeriod < 0 {
+		add("BILLING_TRIAL_PERIOD and BILLING_GRACE_PERIOD cannot be negative")
+	}
+	if billing.ChapaSecretKey != "" && billing.ChapaWebhookSecret == "" {
+		add("CHAPA_SECRET_KEY needs CHAPA_WEBHOOK_SECRET, to check Chapa's webhooks")
+	}
+	if billing.StripeSecretKey != "" && (billing.StripeWebhookSecret == "" || billing.StripePriceBasic == "" || billing.StripePricePro == "") {
+		add("STRIPE_SECRET_KEY needs STRIPE_WEBHOOK_SECRET, STRIPE_PRICE_BASIC and STRIPE_PRICE_PRO")
+	}
+
+	mm := cfg.MobileMoney
+	if mm.Sandbox && cfg.Server.Mode == gin.ReleaseMode {
+		add("MOBILE_MONEY_SANDBOX must not be set in release mode")
+	}
+	if mm.telebirrEnabled() || mm.cbeBirrEnabled() || mm.mpesaEnabled() {
+		if mm.CallbackBaseURL == "" {
+			add("MOBILE_MONEY_CALLBACK_URL is required when a mobile money provider is set up")
+		} else if cfg.Server.Mode == gin.ReleaseMode && !strings.HasPrefix(mm.CallbackBaseURL, "https://") {
+			add("MOBILE_MONEY_CALLBACK_URL must be https in release mode")
+		}
+	}
+	if mm.telebirrEnabled() && (mm.TelebirrBaseURL == "" || mm.TelebirrCheckoutURL == "" || mm.TelebirrAppSecret == "" ||
+		mm.TelebirrMerchantAppID == "" || mm.TelebirrShortCode == "" || mm.TelebirrPrivateKey == "" || mm.TelebirrPublicKey == "") {
+		add("TELEBIRR_FABRIC_APP_ID needs TELEBIRR_BASE_URL, TELEBIRR_CHECKOUT_URL, TELEBIRR_APP_SECRET, TELEBIRR_MERCHANT_APP_ID, TELEBIRR_SHORT_CODE, TELEBIRR_PRIVATE_KEY and TELEBIRR_PUBLIC_KEY")
+	}
+	if mm.cbeBirrEnabled() && (mm.CBEBirrBaseURL == "" || mm.CBEBirrAPIKey == "") {
+		add("CBE_BIRR_MERCHANT_ID needs CBE_BIRR_BASE_URL and CBE_BIRR_API_KEY")
+	}
+	if mm.mpesaEnabled() && (mm.MPesaBaseURL == "" || mm.MPesaConsumerSecret == "" || mm.MPesaShortCode == "" || mm.MPesaPasskey == "") {
+		add("MPESA_CONSUMER_KEY needs MPESA_BASE_URL, MPESA_CONSUMER_SECRET, MPESA_SHORT_CODE and MPESA_PASSKEY")
+	}
+
+	sms := cfg.SMS
+	switch strings.ToLower(sms.Provider) {
+	case "log":
+	case "afromessage":
+		if sms.AfroMessageToken == "" || sms.AfroMessageIdentifierID == "" {
+			add("SMS_PROVIDER=afromessage needs AFROMESSAGE_TOKEN and AFROMESSAGE_IDENTIFIER_ID")
+		}
+	case "geezsms":
+		if sms.GeezSMSToken == "" {
+			add("SMS_PROVIDER=geezsms needs GEEZSMS_TOKEN")
+		}
+	case "africastalking":
+		if sms.AfricasTalkingUsername == "" || sms.AfricasTalkingAPIKey == "" {
+			add("SMS_PROVIDER=africastalking needs AT_USERNAME and AT_API_KEY")
+		}
+	default:
+		add("SMS_PROVIDER must be log, afromessage, geezsms or africastalking, got %q", sms.Provider)
+	}
+
+	email := cfg.Email
+	switch strings.ToLower(email.Provider) {
+	case "log":
+	case "smtp":
+		if email.SMTPHost == "" {
+			add("EMAIL_PROVIDER=smtp needs SMTP_HOST")
+		}
+	case "ses":
+		if email.SESRegion == "" || email.SESAccessKeyID == "" || email.SESSecretAccessKey == "" {
+			add("EMAIL_PROVIDER=ses needs SES_REGION, SES_ACCESS_KEY_ID and SES_SECRET_ACCESS_KEY")
+		}
+	case "sendgrid":
+		if email.SendGridAPIKey == "" {
+			add("EMAIL_PROVIDER=sendgrid needs SENDGRID_API_KEY")
+		}
+	default:
+		add("EMAIL_PROVIDER must be log, smtp, ses or sendgrid, got %q", email.Provider)
+	}
+	if strings.ToLower(email.Provider) != "log" && email.FromAddress == "" {
+		add("EMAIL_PROVIDER=%s needs EMAIL_FROM_ADDRESS", email.Provider)
+	}
+
+	for name, timeout := range map[string]time.Duration{
+		"READ_HEADER_TIMEOUT": cfg.Server.ReadHeaderTimeout,
+		"READ_TIMEOUT":        cfg.Server.ReadTimeout,
+		"WRITE_TIMEOUT":       cfg.Server.WriteTimeout,
+		"IDLE_TIMEOUT":        cfg.Server.IdleTimeout,
+	} {
+		if timeout <= 0 {
+			add("%s must be positive, got %s", name, timeout)
+		}
+	}
+	if cfg.Server.ReadHeaderTimeout > cfg.Server.ReadTimeout {
+		add("READ_HEADER_TIMEOUT (%s) cannot exceed READ_TIMEOUT (%s)", cfg.Server.ReadHeaderTimeout, cfg.Server.ReadTimeout)
+	}
+	if cfg.Server.MaxHeaderBytes < 4096 {
+		add("MAX_HEADER_BYTES must be at least 4096, got %d", cfg.Server.MaxHeaderBytes)
+	}
+	if cfg.Server.HTTP2 && cfg.Server.HTTP2MaxStreams <= 0 {
+		add("HTTP2_MAX_STREAMS must be positive, got %d", cfg.Server.HTTP2MaxStreams)
+	}
+	if cfg.Archive.AfterDays < 0 {
+		add("ARCHIVE_AFTER_DAYS cannot be negative")
+	}
+	if cfg.Archive.BatchSize <= 0 {
+		add("ARCHIVE_BATCH_SIZE must be positive, got %d", cfg.Archive.BatchSize)
+	}
+	if cfg.Retention.BatchSize <= 0 {
+		add("RETENTION_BATCH_SIZE must be positive, got %d", cfg.Retention.BatchSize)
+	}
+	if cfg.Telemetry.FlushInterval <= 0 {
+		add("TELEMETRY_FLUSH_INTERVAL must be positive")
+	}
+	if cfg.Telemetry.MaxPending <= 0 {
+		add("TELEMETRY_MAX_PENDING must be positive, got %d", cfg.Telemetry.MaxPending)
+	}
+	if cfg.Demo.TTL <= 0 {
+		add("DEMO_TTL must be positive")
+	}
+	if cfg.Demo.MaxShops <= 0 {
+		add("DEMO_MAX_SHOPS must be positive, got %d", cfg.Demo.MaxShops)
+	}
+	if !cfg.Server.RateLimits && cfg.Server.Mode == "release" {
+		add("RATE_LIMITS cannot be turned off in release mode")
+	}
+	if (cfg.Server.TLSCertFile == "") != (cfg.Server.TLSKeyFile == "") {
+		add("TLS_CERT_FILE and TLS_KEY_FILE must be set together")
+	}
+
+	for name, limit := range map[string]int64{
+		"BODY_LIMIT_DEFAULT": cfg.Server.BodyLimitDefault,
+		"BODY_LIMIT_SYNC":    cfg.Server.BodyLimitSync,
+		"BODY_LIMIT_UPLOAD":  cfg.Server.BodyLimitUpload,
+	} {
+		if limit < 0 {
+			add("%s cannot be negative", name)
+		}
+	}
+	sort.Strings(problems)
+	return problems
+}
+
+// Redacted returns the configuration keyed like the config file, with secrets masked
+// and credentials removed from connection strings
+func (cfg *Config) Redacted() map[string]interface{} {
+	return redactedStruct(reflect.ValueOf(*cfg))
+}
+
+// ConfigHandler serves the redacted configuration, for checking what an instance is
+// actually running with
+func ConfigHandler(cfg *Config) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, cfg.Redacted())
+	}
+}
+
+func redactedStruct(v reflect.Value) map[string]interface{} {
+	out := make(map[string]interface{}, v.NumField())
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		value := v.Field(i)
+
+		switch {
+		case field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeOf(time.Duration(0)):
+			out[name] = redactedStruct(value)
+		case field.Tag.Get("secret") == "uri":
+			if value.Kind() == reflect.Slice {
+				uris := make([]string, value.Len())
+				for j := range uris {
+					uris[j] = redactURI(value.Index(j).String())
+				}
+				out[name] = uris
+			} else if value.String() != "" {
+				out[name] = redactURI(value.String())
+			} else {
+				out[name] = ""
+			}
+		case field.Tag.Get("secret") == "true" && value.Kind() == reflect.Slice:
+			// name:secret pairs keep their names
+			entries := make([]string, value.Len())
+			for j := range entries {
+				keyName, _, _ := strings.Cut(value.Index(j).String(), ":")
+				entries[j] = keyName + ":[redacted]"
+			}
+			out[name] = entries
+		case field.Tag.Get("secret") == "true":
+			if value.String() != "" {
+				out[name] = "[redacted]"
+			} else {
+				out[name] = ""
+			}
+		case field.Type == reflect.TypeOf(time.Duration(0)):
+			out[name] = time.Duration(value.Int()).String()
+		default:
+			out[name] = value.Interface()
+		}
+	}
+	return out
+}
+
+// setFields walks the config struct and sets every leaf the lookup has a value for
+func setFields(v reflect.Value, lookup func(field reflect.StructField, path []string) (string, bool), problems *[]string, path ...string) {
+	for i := 0; i < v.NumField(); i++ {
+		field := v.Type().Field(i)
+		name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+		fieldPath := append(append([]string(nil), path...), name)
+
+		if field.Type.Kind() == reflect.Struct {
+			setFields(v.Field(i), lookup, problems, fieldPath...)
+			continue
+		}
+		raw, ok := lookup(field, fieldPath)
+		if !ok {
+			continue
+		}
+		if err := setField(v.Field(i), raw); err != nil {
+			*problems = append(*problems, fmt.Sprintf("%s: %v", field.Tag.Get("env"), err))
+		}
+	}
+}
+
+func setField(field reflect.Value, raw string) error {
+	raw = strings.TrimSpace(raw)
+	switch {
+	case field.Type() == reflect.TypeOf(time.Duration(0)):
+		d, err := time.ParseDuration(raw)
+		if err != nil || d < 0 {
+			return fmt.Errorf("%q is not a duration such as 30s or 5m", raw)
+		}
+		field.SetInt(int64(d))
+	case field.Kind() == reflect.String:
+		field.SetString(raw)
+	case field.Kind() == reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return fmt.Errorf("%q is not true or false", raw)
+		}
+		field.SetBool(b)
+	case field.Kind() == reflect.Int || field.Kind() == reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a whole number", raw)
+		}
+		field.SetInt(n)
+	case field.Kind() == reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a non-negative whole number", raw)
+		}
+		field.SetUint(n)
+	case field.Kind() == reflect.Float64:
+		f, err := strconv.ParseFloat(raw, 64)
+		if err != nil {
+			return fmt.Errorf("%q is not a number", raw)
+		}
+		field.SetFloat(f)
+	case field.Kind() == reflect.Slice && field.Type().Elem().Kind() == reflect.String:
+		var items []string
+		for _, item := range strings.Split(raw, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				items = append(items, item)
+			}
+		}
+		field.Set(reflect.ValueOf(items))
+	default:
+		return fmt.Errorf("unsupported config field type %s", field.Type())
+	}
+	return nil
+}
+
+func readConfigFile(path string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONFIG_FILE: %w", err)
+	}
+	var values map[string]interface{}
+	if err := json.Unmarshal(data, &values); err != nil {
+		return nil, fmt.Errorf("failed to parse CONFIG_FILE %s: %w", path, err)
+	}
+	return values, nil
+}
+
+// lookupConfigFile finds a leaf in the parsed file, as the string the environment
+// would hold. Lists are joined with commas.
+func lookupConfigFile(values map[string]interface{}, path []string) (string, bool) {
+	var current interface{} = values
+	for _, key := range path {
+		section, ok := current.(map[string]interface{})
+		if !ok {
+			return "", false
+		}
+		if current, ok = section[key]; !ok {
+			return "", false
+		}
+	}
+
+	switch value := current.(type) {
+	case nil:
+		return "", false
+	case string:
+		return value, true
+	case float64:
+		return strconv.FormatFloat(value, 'f', -1, 64), true
+	case bool:
+		return strconv.FormatBool(value), true
+	case []interface{}:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+		return strings.Join(items, ","), true
+	default:
+		return "", false
+	}
+}
+
+func oneOf(value string, options ...string) bool {
+	for _, option := range options {
+		if value == option {
+			return true
+		}
+	}
+	return false
+}
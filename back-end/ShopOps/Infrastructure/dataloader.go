@@ -0,0 +1,103 @@
+package Infrastructure
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// A GraphQL query for fifty sales and each sale's product would look the product up
+// fifty times. The resolvers run concurrently, so a loader holds their lookups for
+// a moment and fetches all the keys asked for in that time in one query.
+
+const (
+	loaderWait     = 2 * time.Millisecond
+	loaderMaxBatch = 200
+)
+
+// Loader batches and caches lookups by key. Make one per request, so a result is
+// never served to another caller or kept past the request.
+type Loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	results map[K]*loaderResult[V]
+	batch   *loaderBatch[K, V]
+}
+
+type loaderResult[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+type loaderBatch[K comparable, V any] struct {
+	keys    []K
+	results []*loaderResult[V]
+}
+
+// NewLoader makes a loader over fetch, which returns the values for the keys it
+// finds. Keys it leaves out load as V's zero value.
+func NewLoader[K comparable, V any](fetch func(keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{fetch: fetch, results: map[K]*loaderResult[V]{}}
+}
+
+// Load returns the value for key, waiting for the batch it joins
+func (l *Loader[K, V]) Load(key K) (V, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loaderResult[V]{done: make(chan struct{})}
+		l.results[key] = result
+
+		if l.batch == nil {
+			batch := &loaderBatch[K, V]{}
+			l.batch = batch
+			time.AfterFunc(loaderWait, func() { l.dispatch(batch) })
+		}
+		batch := l.batch
+		batch.keys = append(batch.keys, key)
+		batch.results = append(batch.results, result)
+		if len(batch.keys) >= loaderMaxBatch {
+			l.batch = nil
+			go l.run(batch)
+		}
+	}
+	l.mu.Unlock()
+
+	<-result.done
+	return result.value, result.err
+}
+
+// dispatch runs batch when its wait is up, unless it filled up and ran already
+func (l *Loader[K, V]) dispatch(batch *loaderBatch[K, V]) {
+	l.mu.Lock()
+	if l.batch != batch {
+		l.mu.Unlock()
+		return
+	}
+	l.batch = nil
+	l.mu.Unlock()
+
+	l.run(batch)
+}
+
+func (l *Loader[K, V]) run(batch *loaderBatch[K, V]) {
+	var values map[K]V
+	var err error
+	func() {
+		// Every caller in the batch is waiting, so a panic must still answer them
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("loader failed: %v", r)
+			}
+		}()
+		values, err = l.fetch(batch.keys)
+	}()
+
+	for i, key := range batch.keys {
+		result := batch.results[i]
+		result.value, result.err = values[key], err
+		close(result.done)
+	}
+}
@@ -3,8 +3,17 @@ package Infrastructure
 import (
 	"context"
 	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
 	"time"
 
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsoncodec"
+	"go.mongodb.org/mongo-driver/bson/bsonrw"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 	"go.mongodb.org/mongo-driver/mongo/readpref"
@@ -13,33 +22,145 @@ import (
 var client *mongo.Client
 var db *mongo.Database
 
-func InitMongo() error {
-	_ = LoadEnv()
-	uri := GetEnv("MONGODB_URL", "")
-	if uri == "" {
+// The settings InitMongo connected with, reused for the read replicas
+var mongoConfig MongoConfig
+
+func InitMongo(cfg MongoConfig) error {
+	if cfg.URL == "" {
 		return errors.New("MONGODB_URL not set")
 	}
-	dbName := GetEnv("MONGO_DB", "Shopops_DB")
+	slowQueryThreshold = cfg.SlowQueryThreshold
+	mongoConfig = cfg
 
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	var err error
-	client, err = mongo.Connect(ctx, options.Client().ApplyURI(uri).SetServerSelectionTimeout(30*time.Second))
+	clientOpts := applyPoolOptions(options.Client().
+		ApplyURI(cfg.URL).
+		SetServerSelectionTimeout(30*time.Second).
+		SetRegistry(decimalRegistry()).
+		SetMonitor(commandMonitor()).
+		SetPoolMonitor(poolMonitor()), cfg)
+	client, err = mongo.Connect(ctx, clientOpts)
 	if err != nil {
 		return err
 	}
 	if err = client.Ping(ctx, readpref.Primary()); err != nil {
 		return err
 	}
-	db = client.Database(dbName)
+	db = client.Database(cfg.Database)
 	return nil
 }
 
+// decimalRegistry is the driver's default registry, except that Decimal128 values
+// decode into float fields. Amounts are stored and reported as Money, but a pipeline
+// that mixes a decimal into a ratio or a quantity still hands back a decimal.
+func decimalRegistry() *bsoncodec.Registry {
+	registry := bson.NewRegistry()
+	floats := bsoncodec.DefaultValueDecoders{}
+	decode := bsoncodec.ValueDecoderFunc(func(dc bsoncodec.DecodeContext, vr bsonrw.ValueReader, val reflect.Value) error {
+		if vr.Type() != bsontype.Decimal128 {
+			return floats.FloatDecodeValue(dc, vr, val)
+		}
+		if !val.CanSet() {
+			return bsoncodec.ValueDecoderError{Name: "FloatDecodeValue", Kinds: []reflect.Kind{reflect.Float32, reflect.Float64}, Received: val}
+		}
+		d, err := vr.ReadDecimal128()
+		if err != nil {
+			return err
+		}
+		f, err := strconv.ParseFloat(d.String(), 64)
+		if err != nil {
+			return fmt.Errorf("cannot decode decimal %s into a float: %w", d, err)
+		}
+		val.SetFloat(f)
+		return nil
+	})
+	registry.RegisterKindDecoder(reflect.Float32, decode)
+	registry.RegisterKindDecoder(reflect.Float64, decode)
+	return registry
+}
+
+// commandMonitor times every command the driver sends, for the DB latency metric,
+// logs slow ones and traces commands issued within a traced operation
+func commandMonitor() *event.CommandMonitor {
+	var spans sync.Map   // Driver request ID to its span
+	var queries sync.Map // Driver request ID to the command, for the slow query log
+
+	return &event.CommandMonitor{
+		Started: func(ctx context.Context, e *event.CommandStartedEvent) {
+			if query := startedQuery(e); query != nil {
+				queries.Store(e.RequestID, query)
+			}
+			if SpanFrom(ctx) == nil {
+				return
+			}
+			_, span := StartSpan(ctx, "mongodb."+e.CommandName, SpanKindClient)
+			span.SetAttribute("db.system", "mongodb")
+			span.SetAttribute("db.name", e.DatabaseName)
+			span.SetAttribute("db.operation", e.CommandName)
+			if collection, ok := e.Command.Lookup(e.CommandName).StringValueOK(); ok {
+				span.SetAttribute("db.collection", collection)
+			}
+			spans.Store(e.RequestID, span)
+		},
+		Succeeded: func(ctx context.Context, e *event.CommandSucceededEvent) {
+			DBCommandDuration.Observe(e.Duration.Seconds(), e.CommandName, "success")
+			if query, ok := queries.LoadAndDelete(e.RequestID); ok {
+				logSlowQuery(ctx, query.(*queryStart), e.Duration, "")
+			}
+			if span, ok := spans.LoadAndDelete(e.RequestID); ok {
+				span.(*Span).End()
+			}
+		},
+		Failed: func(ctx context.Context, e *event.CommandFailedEvent) {
+			DBCommandDuration.Observe(e.Duration.Seconds(), e.CommandName, "failure")
+			if query, ok := queries.LoadAndDelete(e.RequestID); ok {
+				logSlowQuery(ctx, query.(*queryStart), e.Duration, e.Failure)
+			}
+			if span, ok := spans.LoadAndDelete(e.RequestID); ok {
+				span.(*Span).RecordError(errors.New(e.Failure))
+				span.(*Span).End()
+			}
+		},
+	}
+}
+
 func GetDB() *mongo.Database {
 	return db
 }
 
+var (
+	transactionsMu sync.Mutex
+	transactions   = make(map[*mongo.Client]bool)
+)
+
+// SupportsTransactions reports whether the database's deployment is a replica set
+// or sharded cluster, asking each server once; a standalone server has no
+// transactions. When the server cannot be asked none are assumed for this call
+// only, and it is asked again the next time.
+func SupportsTransactions(ctx context.Context, database *mongo.Database) bool {
+	transactionsMu.Lock()
+	defer transactionsMu.Unlock()
+
+	if supported, ok := transactions[database.Client()]; ok {
+		return supported
+	}
+
+	var hello struct {
+		SetName string `bson:"setName"`
+		Msg     string `bson:"msg"`
+	}
+	if err := database.RunCommand(ctx, bson.D{{Key: "hello", Value: 1}}).Decode(&hello); err != nil {
+		LoggerFrom(ctx).Warn("could not tell whether mongodb supports transactions; writing without", "error", err)
+		return false
+	}
+	supported := hello.SetName != "" || hello.Msg == "isdbgrid"
+	transactions[database.Client()] = supported
+	return supported
+}
+
 func CloseMongo() {
 	if client == nil {
 		return
@@ -0,0 +1,88 @@
+package Infrastructure
+
+import (
+	"log/slog"
+	"sync/atomic"
+
+	"go.mongodb.org/mongo-driver/event"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// The driver keeps one connection pool per server. At month-end a large shop's
+// reports and exports can hold every connection, and POS writes then queue until
+// they time out, so the pool is sized from the environment and its saturation is
+// exported (see MongoConfig):
+//
+//	MONGO_MAX_POOL_SIZE       connections per server, in use or idle (default 100)
+//	MONGO_MIN_POOL_SIZE       idle connections kept open per server (default 0)
+//	MONGO_MAX_CONN_IDLE_TIME  idle connections older than this are closed (default 5m)
+//	MONGO_MAX_CONNECTING      connections dialled at once per server (default 2)
+//
+// MongoDB connections have no maximum lifetime; an idle connection is closed after
+// MONGO_MAX_CONN_IDLE_TIME and one in use lives until it errors.
+
+var (
+	poolOpen   atomic.Int64
+	poolInUse  atomic.Int64
+	poolMaxPer atomic.Int64
+
+	DBPoolCheckoutWait = NewHistogramVec("shopops_db_pool_checkout_wait_seconds",
+		"Time spent waiting for a pooled MongoDB connection", DefaultBuckets, "outcome")
+	DBPoolCheckoutFailures = NewCounterVec("shopops_db_pool_checkout_failures_total",
+		"Connection checkouts that failed, by reason; timeout means the pool was exhausted", "reason")
+	DBPoolCleared = NewCounterVec("shopops_db_pool_cleared_total",
+		"Times a server's connection pool was cleared after an error")
+)
+
+func init() {
+	register(gaugeFunc{"shopops_db_pool_open_connections", "Open MongoDB connections, in use or idle", func() float64 {
+		return float64(poolOpen.Load())
+	}})
+	register(gaugeFunc{"shopops_db_pool_in_use_connections", "MongoDB connections checked out by an operation", func() float64 {
+		return float64(poolInUse.Load())
+	}})
+	register(gaugeFunc{"shopops_db_pool_max_connections", "Maximum MongoDB connections per server", func() float64 {
+		return float64(poolMaxPer.Load())
+	}})
+}
+
+// applyPoolOptions sizes the client's connection pools from the configuration
+func applyPoolOptions(opts *options.ClientOptions, cfg MongoConfig) *options.ClientOptions {
+	poolMaxPer.Store(int64(cfg.MaxPoolSize))
+
+	return opts.
+		SetMaxPoolSize(cfg.MaxPoolSize).
+		SetMinPoolSize(cfg.MinPoolSize).
+		SetMaxConnIdleTime(cfg.MaxConnIdleTime).
+		SetMaxConnecting(cfg.MaxConnecting)
+}
+
+// poolMonitor tracks connection counts and checkout waits for the pool metrics
+func poolMonitor() *event.PoolMonitor {
+	return &event.PoolMonitor{
+		Event: func(e *event.PoolEvent) {
+			switch e.Type {
+			case event.ConnectionCreated:
+				poolOpen.Add(1)
+			case event.ConnectionClosed:
+				poolOpen.Add(-1)
+			case event.GetSucceeded:
+				poolInUse.Add(1)
+				DBPoolCheckoutWait.Observe(e.Duration.Seconds(), "success")
+			case event.ConnectionReturned:
+				poolInUse.Add(-1)
+			case event.GetFailed:
+				DBPoolCheckoutWait.Observe(e.Duration.Seconds(), "failure")
+				DBPoolCheckoutFailures.Inc(e.Reason)
+				if e.Reason == event.ReasonTimedOut {
+					Logger.Warn("mongodb connection pool exhausted",
+						slog.String("address", e.Address),
+						slog.Int64("in_use", poolInUse.Load()),
+						slog.Int64("max_pool_size", poolMaxPer.Load()))
+				}
+			case event.PoolCleared:
+				DBPoolCleared.Inc()
+			}
+		},
+	}
+}
@@ -0,0 +1,706 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// Email is one rendered message ready to send
+type Email struct {
+	From     string
+	FromName string
+	ReplyTo  string
+	To       string
+	Subject  string
+	Text     string
+	HTML     string
+}
+
+// EmailEvent is a bounce or complaint reported by the provider after a send
+type EmailEvent struct {
+	ProviderMessageID string
+	Email             string
+	Status            Domain.EmailStatus // Bounced or complained
+	// Permanent bounces and complaints stop further mail to the address; a full
+	// mailbox or a blocked connection does not
+	Permanent bool
+	Reason    string
+}
+
+// EmailProvider sends mail and reads the delivery events it reports back
+type EmailProvider interface {
+	Name() string
+	// Send delivers the message and returns the provider's message ID
+	Send(ctx context.Context, email Email) (string, error)
+	// ParseEvents checks a delivery event webhook and reads the bounces and
+	// complaints in it. Providers without webhooks refuse every call.
+	ParseEvents(ctx context.Context, body []byte, header http.Header) ([]EmailEvent, error)
+}
+
+// ErrEmailBadWebhook means an event webhook failed its signature check or could not be read
+var ErrEmailBadWebhook = errors.New("invalid email event webhook")
+
+// NewEmailProvider picks the provider from EMAIL_PROVIDER, which needs:
+//   - smtp:        SMTP_HOST, and SMTP_USERNAME and SMTP_PASSWORD if the server asks
+//   - ses:         SES_REGION, SES_ACCESS_KEY_ID, SES_SECRET_ACCESS_KEY
+//   - sendgrid:    SENDGRID_API_KEY, and SENDGRID_WEBHOOK_KEY for bounces
+//   - log (default): writes messages to the server log, for development
+func NewEmailProvider(cfg EmailConfig) EmailProvider {
+	client := &http.Client{Timeout: 15 * time.Second}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "smtp":
+		return &smtpEmailProvider{
+			host:     cfg.SMTPHost,
+			port:     cfg.SMTPPort,
+			username: cfg.SMTPUsername,
+			password: cfg.SMTPPassword,
+		}
+	case "ses":
+		return &sesEmailProvider{
+			client:    client,
+			region:    cfg.SESRegion,
+			accessKey: cfg.SESAccessKeyID,
+			secretKey: cfg.SESSecretAccessKey,
+		}
+	case "sendgrid":
+		return &sendGridEmailProvider{
+			client:     client,
+			apiKey:     cfg.SendGridAPIKey,
+			webhookKey: cfg.SendGridWebhookKey,
+		}
+	default:
+		return &logEmailProvider{}
+	}
+}
+
+type logEmailProvider struct{}
+
+func (p *logEmailProvider) Name() string { return "log" }
+
+func (p *logEmailProvider) Send(ctx context.Context, email Email) (string, error) {
+	log.Printf("Email to=%s subject=%q\n%s", email.To, email.Subject, email.Text)
+	return fmt.Sprintf("log-%d", time.Now().UnixNano()), nil
+}
+
+func (p *logEmailProvider) ParseEvents(ctx context.Context, body []byte, header http.Header) ([]EmailEvent, error) {
+	return nil, fmt.Errorf("%w: the log provider has no events", ErrEmailBadWebhook)
+}
+
+// smtpEmailProvider sends through any SMTP server, using STARTTLS when offered or
+// TLS from the start on port 465. Bounces come back as mail, which is not read.
+type smtpEmailProvider struct {
+	host     string
+	port     int
+	username string
+	password string
+}
+
+func (p *smtpEmailProvider) Name() string { return "smtp" }
+
+func (p *smtpEmailProvider) Send(ctx context.Context, email Email) (string, error) {
+	messageID, message, err := buildMIMEMessage(email)
+	if err != nil {
+		return "", err
+	}
+
+	addr := net.JoinHostPort(p.host, strconv.Itoa(p.port))
+	var auth smtp.Auth
+	if p.username != "" {
+		auth = smtp.PlainAuth("", p.username, p.password, p.host)
+	}
+
+	if p.port != 465 {
+		if err := smtp.SendMail(addr, auth, email.From, []string{email.To}, message); err != nil {
+			return "", fmt.Errorf("smtp send failed: %w", err)
+		}
+		return messageID, nil
+	}
+
+	dialer := &net.Dialer{Timeout: 15 * time.Second}
+	conn, err := tls.DialWithDialer(dialer, "tcp", addr, &tls.Config{ServerName: p.host})
+	if err != nil {
+		return "", fmt.Errorf("smtp connect failed: %w", err)
+	}
+	client, err := smtp.NewClient(conn, p.host)
+	if err != nil {
+		conn.Close()
+		return "", fmt.Errorf("smtp connect failed: %w", err)
+	}
+	defer client.Close()
+
+	if auth != nil {
+		if err := client.Auth(auth); err != nil {
+			return "", fmt.Errorf("smtp auth failed: %w", err)
+		}
+	}
+	if err := client.Mail(email.From); err != nil {
+		return "", fmt.Errorf("smtp send failed: %w", err)
+	}
+	if err := client.Rcpt(email.To); err != nil {
+		return "", fmt.Errorf("smtp send failed: %w", err)
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return "", fmt.Errorf("smtp send failed: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		return "", fmt.Errorf("smtp send failed: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("smtp send failed: %w", err)
+	}
+	return messageID, client.Quit()
+}
+
+func (p *smtpEmailProvider) ParseEvents(ctx context.Context, body []byte, header http.Header) ([]EmailEvent, error) {
+	return nil, fmt.Errorf("%w: smtp has no events", ErrEmailBadWebhook)
+}
+
+// buildMIMEMessage writes a multipart/alternative message with text and HTML
+// parts, returning its Message-ID
+func buildMIMEMessage(email Email) (string, []byte, error) {
+	nonce := make([]byte, 12)
+	_, _ = rand.Read(nonce)
+	domain := "shopops"
+	if _, host, ok := strings.Cut(email.From, "@"); ok {
+		domain = host
+	}
+	messageID := hex.EncodeToString(nonce) + "@" + domain
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	headers := []struct{ key, value string }{
+		{"From", (&mail.Address{Name: email.FromName, Address: email.From}).String()},
+		{"To", (&mail.Address{Address: email.To}).String()},
+		{"Subject", mime.QEncoding.Encode("utf-8", email.Subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", "<" + messageID + ">"},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", "multipart/alternative; boundary=" + writer.Boundary()},
+	}
+	if email.ReplyTo != "" {
+		headers = append(headers, struct{ key, value string }{"Reply-To", (&mail.Address{Address: email.ReplyTo}).String()})
+	}
+	for _, header := range headers {
+		fmt.Fprintf(&buf, "%s: %s\r\n", header.key, header.value)
+	}
+	buf.WriteString("\r\n")
+
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", email.Text},
+		{"text/html; charset=utf-8", email.HTML},
+	} {
+		if part.body == "" {
+			continue
+		}
+		w, err := writer.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return "", nil, err
+		}
+		qp := quotedprintable.NewWriter(w)
+		if _, err := qp.Write([]byte(part.body)); err != nil {
+			return "", nil, err
+		}
+		if err := qp.Close(); err != nil {
+			return "", nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return "", nil, err
+	}
+
+	return messageID, buf.Bytes(), nil
+}
+
+// sesEmailProvider sends through Amazon SES. Bounces and complaints arrive from an
+// SNS topic the SES identity publishes to.
+type sesEmailProvider struct {
+	client    *http.Client
+	region    string
+	accessKey string
+	secretKey string
+
+	certs sync.Map // SNS signing certificate URL to *x509.Certificate
+}
+
+func (p *sesEmailProvider) Name() string { return "ses" }
+
+func (p *sesEmailProvider) Send(ctx context.Context, email Email) (string, error) {
+	content := map[string]interface{}{
+		"Subject": map[string]string{"Data": email.Subject, "Charset": "UTF-8"},
+		"Body": map[string]interface{}{
+			"Text": map[string]string{"Data": email.Text, "Charset": "UTF-8"},
+			"Html": map[string]string{"Data": email.HTML, "Charset": "UTF-8"},
+		},
+	}
+	payload := map[string]interface{}{
+		"FromEmailAddress": (&mail.Address{Name: email.FromName, Address: email.From}).String(),
+		"Destination":      map[string][]string{"ToAddresses": {email.To}},
+		"Content":          map[string]interface{}{"Simple": content},
+	}
+	if email.ReplyTo != "" {
+		payload["ReplyToAddresses"] = []string{email.ReplyTo}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := fmt.Sprintf("https://email.%s.amazonaws.com/v2/email/outbound-emails", p.region)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	p.sign(req, body, time.Now().UTC())
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("ses request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("failed to read ses response: %w", err)
+	}
+	if resp.StatusCode >= 300 {
+		return "", fmt.Errorf("ses returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+
+	var result struct {
+		MessageID string `json:"MessageId"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return "", fmt.Errorf("failed to decode ses response: %w", err)
+	}
+	return result.MessageID, nil
+}
+
+// sign adds AWS Signature Version 4 headers for the SES API
+func (p *sesEmailProvider) sign(req *http.Request, body []byte, now time.Time) {
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+
+	payloadHash := sha256.Sum256(body)
+	signedHeaders := "content-type;host;x-amz-date"
+	canonical := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		req.URL.RawQuery,
+		"content-type:" + req.Header.Get("Content-Type"),
+		"host:" + req.URL.Host,
+		"x-amz-date:" + amzDate,
+		"",
+		signedHeaders,
+		hex.EncodeToString(payloadHash[:]),
+	}, "\n")
+
+	scope := date + "/" + p.region + "/ses/aws4_request"
+	canonicalHash := sha256.Sum256([]byte(canonical))
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalHash[:])
+
+	key := []byte("AWS4" + p.secretKey)
+	for _, part := range []string{date, p.region, "ses", "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		p.accessKey, scope, signedHeaders, signature))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// snsCertURL only accepts signing certificates served by SNS itself
+var snsCertURL = regexp.MustCompile(`^https://sns\.[a-z0-9-]+\.amazonaws\.com(\.cn)?/`)
+
+func (p *sesEmailProvider) ParseEvents(ctx context.Context, body []byte, header http.Header) ([]EmailEvent, error) {
+	var envelope struct {
+		Type             string `json:"Type"`
+		MessageID        string `json:"MessageId"`
+		Token            string `json:"Token"`
+		TopicArn         string `json:"TopicArn"`
+		Subject          string `json:"Subject"`
+		Message          string `json:"Message"`
+		SubscribeURL     string `json:"SubscribeURL"`
+		Timestamp        string `json:"Timestamp"`
+		SignatureVersion string `json:"SignatureVersion"`
+		Signature        string `json:"Signature"`
+		SigningCertURL   string `json:"SigningCertURL"`
+	}
+	if err := json.Unmarshal(body, &envelope); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEmailBadWebhook, err)
+	}
+
+	// SNS signs its fields in a fixed order, each as "name\nvalue\n"
+	var signed strings.Builder
+	field := func(name, value string) {
+		signed.WriteString(name + "\n" + value + "\n")
+	}
+	field("Message", envelope.Message)
+	field("MessageId", envelope.MessageID)
+	if envelope.Type == "Notification" {
+		if envelope.Subject != "" {
+			field("Subject", envelope.Subject)
+		}
+	} else {
+		field("SubscribeURL", envelope.SubscribeURL)
+	}
+	field("Timestamp", envelope.Timestamp)
+	if envelope.Type != "Notification" {
+		field("Token", envelope.Token)
+	}
+	field("TopicArn", envelope.TopicArn)
+	field("Type", envelope.Type)
+
+	if err := p.verifySNS(ctx, envelope.SigningCertURL, envelope.SignatureVersion, envelope.Signature, signed.String()); err != nil {
+		return nil, err
+	}
+
+	switch envelope.Type {
+	case "SubscriptionConfirmation":
+		if !snsCertURL.MatchString(envelope.SubscribeURL) {
+			return nil, fmt.Errorf("%w: unexpected subscribe URL", ErrEmailBadWebhook)
+		}
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, envelope.SubscribeURL, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := p.client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("failed to confirm sns subscription: %w", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			return nil, fmt.Errorf("sns subscription confirmation returned %d", resp.StatusCode)
+		}
+		return nil, nil
+	case "Notification":
+	default:
+		return nil, nil
+	}
+
+	var notification struct {
+		NotificationType string `json:"notificationType"`
+		EventType        string `json:"eventType"`
+		Mail             struct {
+			MessageID string `json:"messageId"`
+		} `json:"mail"`
+		Bounce struct {
+			BounceType        string `json:"bounceType"`
+			BounceSubType     string `json:"bounceSubType"`
+			BouncedRecipients []struct {
+				EmailAddress   string `json:"emailAddress"`
+				DiagnosticCode string `json:"diagnosticCode"`
+			} `json:"bouncedRecipients"`
+		} `json:"bounce"`
+		Complaint struct {
+			ComplainedRecipients []struct {
+				EmailAddress string `json:"emailAddress"`
+			} `json:"complainedRecipients"`
+			ComplaintFeedbackType string `json:"complaintFeedbackType"`
+		} `json:"complaint"`
+	}
+	if err := json.Unmarshal([]byte(envelope.Message), &notification); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEmailBadWebhook, err)
+	}
+
+	kind := notification.NotificationType
+	if kind == "" {
+		kind = notification.EventType // Configuration set event destinations
+	}
+
+	var events []EmailEvent
+	switch kind {
+	case "Bounce":
+		for _, recipient := range notification.Bounce.BouncedRecipients {
+			reason := recipient.DiagnosticCode
+			if reason == "" {
+				reason = notification.Bounce.BounceType + "/" + notification.Bounce.BounceSubType
+			}
+			events = append(events, EmailEvent{
+				ProviderMessageID: notification.Mail.MessageID,
+				Email:             recipient.EmailAddress,
+				Status:            Domain.EmailStatusBounced,
+				Permanent:         notification.Bounce.BounceType == "Permanent",
+				Reason:            reason,
+			})
+		}
+	case "Complaint":
+		for _, recipient := range notification.Complaint.ComplainedRecipients {
+			events = append(events, EmailEvent{
+				ProviderMessageID: notification.Mail.MessageID,
+				Email:             recipient.EmailAddress,
+				Status:            Domain.EmailStatusComplained,
+				Permanent:         true,
+				Reason:            notification.Complaint.ComplaintFeedbackType,
+			})
+		}
+	}
+	return events, nil
+}
+
+func (p *sesEmailProvider) verifySNS(ctx context.Context, certURL, version, signature, signed string) error {
+	if !snsCertURL.MatchString(certURL) {
+		return fmt.Errorf("%w: unexpected signing certificate URL", ErrEmailBadWebhook)
+	}
+	sig, err := base64.StdEncoding.DecodeString(signature)
+	if err != nil {
+		return fmt.Errorf("%w: bad signature encoding", ErrEmailBadWebhook)
+	}
+
+	cert, err := p.snsCertificate(ctx, certURL)
+	if err != nil {
+		return err
+	}
+	key, ok := cert.PublicKey.(*rsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: unexpected signing key", ErrEmailBadWebhook)
+	}
+
+	var hash crypto.Hash
+	var digest []byte
+	switch version {
+	case "1":
+		sum := sha1.Sum([]byte(signed))
+		hash, digest = crypto.SHA1, sum[:]
+	case "2":
+		sum := sha256.Sum256([]byte(signed))
+		hash, digest = crypto.SHA256, sum[:]
+	default:
+		return fmt.Errorf("%w: unknown signature version %q", ErrEmailBadWebhook, version)
+	}
+	if err := rsa.VerifyPKCS1v15(key, hash, digest, sig); err != nil {
+		return fmt.Errorf("%w: bad signature", ErrEmailBadWebhook)
+	}
+	return nil
+}
+
+func (p *sesEmailProvider) snsCertificate(ctx context.Context, certURL string) (*x509.Certificate, error) {
+	if cached, ok := p.certs.Load(certURL); ok {
+		return cached.(*x509.Certificate), nil
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, certURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sns certificate: %w", err)
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch sns certificate: %w", err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("sns certificate is not PEM")
+	}
+	cert, err := x509.ParseCertificate(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse sns certificate: %w", err)
+	}
+	if now := time.Now(); now.Before(cert.NotBefore) || now.After(cert.NotAfter) {
+		return nil, fmt.Errorf("sns certificate has expired")
+	}
+
+	p.certs.Store(certURL, cert)
+	return cert, nil
+}
+
+// sendGridEmailProvider sends through SendGrid's v3 API, with bounces from its
+// signed event webhook
+type sendGridEmailProvider struct {
+	client     *http.Client
+	apiKey     string
+	webhookKey string
+}
+
+func (p *sendGridEmailProvider) Name() string { return "sendgrid" }
+
+// Event webhooks signed longer ago than this are refused, so captured ones cannot be replayed
+const sendGridWebhookTolerance = 10 * time.Minute
+
+func (p *sendGridEmailProvider) Send(ctx context.Context, email Email) (string, error) {
+	type address struct {
+		Email string `json:"email"`
+		Name  string `json:"name,omitempty"`
+	}
+	type content struct {
+		Type  string `json:"type"`
+		Value string `json:"value"`
+	}
+	payload := map[string]interface{}{
+		"personalizations": []map[string]interface{}{{"to": []address{{Email: email.To}}}},
+		"from":             address{Email: email.From, Name: email.FromName},
+		"subject":          email.Subject,
+		"content": []content{
+			{Type: "text/plain", Value: email.Text},
+			{Type: "text/html", Value: email.HTML},
+		},
+	}
+	if email.ReplyTo != "" {
+		payload["reply_to"] = address{Email: email.ReplyTo}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "https://api.sendgrid.com/v3/mail/send", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+p.apiKey)
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("sendgrid request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+		return "", fmt.Errorf("sendgrid returned %d: %s", resp.StatusCode, strings.TrimSpace(string(data)))
+	}
+	return resp.Header.Get("X-Message-Id"), nil
+}
+
+func (p *sendGridEmailProvider) ParseEvents(ctx context.Context, body []byte, header http.Header) ([]EmailEvent, error) {
+	if err := p.verify(body, header); err != nil {
+		return nil, err
+	}
+
+	var webhook []struct {
+		Email       string `json:"email"`
+		Event       string `json:"event"`
+		Type        string `json:"type"` // For bounces: bounce, or blocked when the receiving server refused for now
+		Reason      string `json:"reason"`
+		SGMessageID string `json:"sg_message_id"`
+	}
+	if err := json.Unmarshal(body, &webhook); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrEmailBadWebhook, err)
+	}
+
+	var events []EmailEvent
+	for _, event := range webhook {
+		// sg_message_id is the X-Message-Id from the send with a suffix per recipient
+		messageID, _, _ := strings.Cut(event.SGMessageID, ".")
+		switch event.Event {
+		case "bounce":
+			events = append(events, EmailEvent{
+				ProviderMessageID: messageID,
+				Email:             event.Email,
+				Status:            Domain.EmailStatusBounced,
+				Permanent:         event.Type != "blocked",
+				Reason:            event.Reason,
+			})
+		case "spamreport":
+			events = append(events, EmailEvent{
+				ProviderMessageID: messageID,
+				Email:             event.Email,
+				Status:            Domain.EmailStatusComplained,
+				Permanent:         true,
+				Reason:            "marked as spam",
+			})
+		}
+	}
+	return events, nil
+}
+
+// verify checks the ECDSA signature SendGrid puts over the timestamp and body
+func (p *sendGridEmailProvider) verify(body []byte, header http.Header) error {
+	if p.webhookKey == "" {
+		return fmt.Errorf("%w: SENDGRID_WEBHOOK_KEY is not set", ErrEmailBadWebhook)
+	}
+	der, err := base64.StdEncoding.DecodeString(p.webhookKey)
+	if err != nil {
+		return fmt.Errorf("%w: bad verification key", ErrEmailBadWebhook)
+	}
+	parsed, err := x509.ParsePKIXPublicKey(der)
+	if err != nil {
+		return fmt.Errorf("%w: bad verification key", ErrEmailBadWebhook)
+	}
+	key, ok := parsed.(*ecdsa.PublicKey)
+	if !ok {
+		return fmt.Errorf("%w: verification key is not ECDSA", ErrEmailBadWebhook)
+	}
+
+	timestamp := header.Get("X-Twilio-Email-Event-Webhook-Timestamp")
+	seconds, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil {
+		return fmt.Errorf("%w: no timestamp", ErrEmailBadWebhook)
+	}
+	if age := time.Since(time.Unix(seconds, 0)); age > sendGridWebhookTolerance || age < -sendGridWebhookTolerance {
+		return fmt.Errorf("%w: signed too long ago", ErrEmailBadWebhook)
+	}
+
+	signature, err := base64.StdEncoding.DecodeString(header.Get("X-Twilio-Email-Event-Webhook-Signature"))
+	if err != nil {
+		return fmt.Errorf("%w: bad signature encoding", ErrEmailBadWebhook)
+	}
+	digest := sha256.Sum256(append([]byte(timestamp), body...))
+	if !ecdsa.VerifyASN1(key, digest[:], signature) {
+		return fmt.Errorf("%w: bad signature", ErrEmailBadWebhook)
+	}
+	return nil
+}
+
+// EmailLink joins the web app's address and a path for links in emails
+func EmailLink(baseURL, path string) string {
+	if baseURL == "" {
+		return ""
+	}
+	if u, err := url.Parse(path); err == nil && u.IsAbs() {
+		return path
+	}
+	return strings.TrimRight(baseURL, "/") + "/" + strings.TrimLeft(path, "/")
+}
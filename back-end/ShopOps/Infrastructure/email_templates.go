@@ -0,0 +1,183 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+
+	Domain "ShopOps/Domain"
+)
+
+// EmailRenderer turns a template and its data into a subject, text and HTML body
+type EmailRenderer interface {
+	Render(name Domain.EmailTemplate, data interface{}) (subject, text, html string, err error)
+}
+
+// Data for each template. ShopName is set on all of them.
+
+type EmailExportReadyData struct {
+	ShopName   string
+	ReportType string
+	Period     string
+	URL        string
+}
+
+type EmailInviteData struct {
+	ShopName    string
+	InviterName string
+	Name        string
+	Position    string
+	URL         string
+}
+
+type EmailLowStockDigestData struct {
+	ShopName string
+	Items    []Domain.LowStockItem
+	More     int // Products below minimum beyond those listed
+	URL      string
+}
+
+type EmailBackupFailedData struct {
+	ShopName string
+	Error    string
+	Attempts int
+}
+
+type EmailTestData struct {
+	ShopName string
+}
+
+type emailTemplate struct {
+	subject *texttemplate.Template
+	text    *texttemplate.Template
+	html    *htmltemplate.Template
+}
+
+type emailRenderer struct {
+	templates map[Domain.EmailTemplate]emailTemplate
+}
+
+func NewEmailRenderer() EmailRenderer {
+	funcs := map[string]interface{}{
+		"qty": func(value float64) string {
+			return strings.TrimSuffix(strings.TrimRight(fmt.Sprintf("%.3f", value), "0"), ".")
+		},
+	}
+
+	renderer := &emailRenderer{templates: make(map[Domain.EmailTemplate]emailTemplate)}
+	for name, source := range emailTemplateSources {
+		renderer.templates[name] = emailTemplate{
+			subject: texttemplate.Must(texttemplate.New(string(name)).Funcs(funcs).Parse(source.subject)),
+			text:    texttemplate.Must(texttemplate.New(string(name)).Funcs(funcs).Parse(source.text)),
+			html: htmltemplate.Must(htmltemplate.New(string(name)).Funcs(funcs).
+				Parse(emailHTMLLayout + `{{define "content"}}` + source.html + `{{end}}`)),
+		}
+	}
+	return renderer
+}
+
+func (r *emailRenderer) Render(name Domain.EmailTemplate, data interface{}) (string, string, string, error) {
+	tpl, ok := r.templates[name]
+	if !ok {
+		return "", "", "", fmt.Errorf("unknown email template %q", name)
+	}
+
+	var subject, text, html bytes.Buffer
+	if err := tpl.subject.Execute(&subject, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s subject: %w", name, err)
+	}
+	if err := tpl.text.Execute(&text, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s text: %w", name, err)
+	}
+	if err := tpl.html.Execute(&html, data); err != nil {
+		return "", "", "", fmt.Errorf("failed to render %s html: %w", name, err)
+	}
+
+	return strings.TrimSpace(subject.String()), strings.TrimSpace(text.String()) + "\n", html.String(), nil
+}
+
+var emailTemplateSources = map[Domain.EmailTemplate]struct{ subject, text, html string }{
+	Domain.EmailTemplateExportReady: {
+		subject: `Your {{.ReportType}} export for {{.ShopName}} is ready`,
+		text: `Your {{.ReportType}} report{{if .Period}} for {{.Period}}{{end}} has been exported.
+
+Download it here: {{.URL}}
+
+The link works for 7 days, and anyone with it can download the file, so keep this email to yourself.`,
+		html: `<p>Your <strong>{{.ReportType}}</strong> report{{if .Period}} for {{.Period}}{{end}} has been exported.</p>
+<p><a class="button" href="{{.URL}}">Download export</a></p>
+<p class="muted">The link works for 7 days, and anyone with it can download the file, so keep this email to yourself.</p>`,
+	},
+	Domain.EmailTemplateInvite: {
+		subject: `{{if .InviterName}}{{.InviterName}} added you{{else}}You were added{{end}} to {{.ShopName}} on ShopOps`,
+		text: `Hello{{if .Name}} {{.Name}}{{end}},
+
+{{if .InviterName}}{{.InviterName}} added you{{else}}You were added{{end}} to the staff of {{.ShopName}}{{if .Position}} as {{.Position}}{{end}}.
+{{if .URL}}
+Sign in or create your account with this email address: {{.URL}}{{end}}`,
+		html: `<p>Hello{{if .Name}} {{.Name}}{{end}},</p>
+<p>{{if .InviterName}}{{.InviterName}} added you{{else}}You were added{{end}} to the staff of <strong>{{.ShopName}}</strong>{{if .Position}} as {{.Position}}{{end}}.</p>
+{{if .URL}}<p><a class="button" href="{{.URL}}">Open ShopOps</a></p>
+<p class="muted">Sign in or create your account with this email address.</p>{{end}}`,
+	},
+	Domain.EmailTemplateLowStockDigest: {
+		subject: `{{len .Items}}{{if .More}}+{{end}} products are running low at {{.ShopName}}`,
+		text: `These products are below their minimum stock:
+{{range .Items}}
+- {{.ProductName}}: {{qty .Current}} left, minimum {{qty .Minimum}}{{end}}
+{{if .More}}
+...and {{.More}} more.{{end}}
+{{if .URL}}
+See them all: {{.URL}}{{end}}`,
+		html: `<p>These products are below their minimum stock:</p>
+<table>
+<tr><th>Product</th><th class="num">In stock</th><th class="num">Minimum</th></tr>
+{{range .Items}}<tr><td>{{.ProductName}}</td><td class="num">{{qty .Current}}</td><td class="num">{{qty .Minimum}}</td></tr>
+{{end}}</table>
+{{if .More}}<p>...and {{.More}} more.</p>{{end}}
+{{if .URL}}<p><a class="button" href="{{.URL}}">View inventory</a></p>{{end}}`,
+	},
+	Domain.EmailTemplateBackupFailed: {
+		subject: `Backup of {{.ShopName}} failed`,
+		text: `The backup of {{.ShopName}} failed after {{.Attempts}} attempts.
+
+Error: {{.Error}}
+
+Your data is safe; only this copy of it could not be made. Our support team can run the backup again.`,
+		html: `<p>The backup of <strong>{{.ShopName}}</strong> failed after {{.Attempts}} attempts.</p>
+<p class="muted">Error: {{.Error}}</p>
+<p>Your data is safe; only this copy of it could not be made. Our support team can run the backup again.</p>`,
+	},
+	Domain.EmailTemplateTest: {
+		subject: `Test email from {{.ShopName}}`,
+		text:    `Emails from {{.ShopName}} on ShopOps are reaching this address.`,
+		html:    `<p>Emails from <strong>{{.ShopName}}</strong> on ShopOps are reaching this address.</p>`,
+	},
+}
+
+const emailHTMLLayout = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<style>
+  body { margin: 0; padding: 24px; background: #f4f5f7; font-family: Helvetica, Arial, sans-serif; font-size: 15px; color: #222; }
+  .card { max-width: 560px; margin: 0 auto; padding: 24px; background: #fff; border-radius: 6px; }
+  .shop { font-size: 18px; font-weight: bold; margin-bottom: 16px; }
+  .button { display: inline-block; padding: 10px 18px; background: #1a73e8; color: #fff; text-decoration: none; border-radius: 4px; }
+  .muted { color: #777; font-size: 13px; }
+  table { width: 100%; border-collapse: collapse; }
+  th, td { padding: 6px 4px; border-bottom: 1px solid #eee; text-align: left; }
+  .num { text-align: right; }
+</style>
+</head>
+<body>
+<div class="card">
+<div class="shop">{{.ShopName}}</div>
+{{template "content" .}}
+</div>
+<p class="muted" style="text-align: center">Sent by ShopOps</p>
+</body>
+</html>`
@@ -0,0 +1,267 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"regexp"
+	"runtime"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// Panics are reported to a Sentry-compatible error tracker when SENTRY_DSN is set,
+// using the store endpoint so the SDK does not need to be vendored. Events carry
+// IDs for the shop, user, request and trace but never phone numbers, emails, IPs,
+// query strings or bodies, and messages are scrubbed of anything that looks like PII.
+
+// ErrorEvent is one reported panic
+type ErrorEvent struct {
+	ID      string
+	Message string
+	Frames  []StackFrame
+	Tags    map[string]string
+	UserID  string
+}
+
+type StackFrame struct {
+	Function string `json:"function"`
+	File     string `json:"filename"`
+	Line     int    `json:"lineno"`
+	InApp    bool   `json:"in_app"`
+}
+
+type errorReporter struct {
+	endpoint    string
+	auth        string
+	environment string
+	release     string
+	client      *http.Client
+	queue       chan ErrorEvent
+	flush       chan chan struct{}
+}
+
+var defaultReporter *errorReporter
+
+// InitErrorReporting starts the reporter when SENTRY_DSN is set. Call it once at
+// startup; environment is the server mode, used when SENTRY_ENVIRONMENT is not set.
+func InitErrorReporting(cfg ErrorReportingConfig, environment string) {
+	if cfg.DSN == "" {
+		return
+	}
+	if cfg.Environment != "" {
+		environment = cfg.Environment
+	}
+	reporter, err := newErrorReporter(cfg.DSN, environment, cfg.Release)
+	if err != nil {
+		Logger.Warn("error reporting disabled", slog.String("error", err.Error()))
+		return
+	}
+	defaultReporter = reporter
+	Logger.Info("error reporting enabled", slog.String("endpoint", reporter.endpoint))
+}
+
+// The DSN has the form https://<key>@<host>/<project>
+func newErrorReporter(dsn, environment, release string) (*errorReporter, error) {
+	u, err := url.Parse(dsn)
+	if err != nil || u.User == nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid SENTRY_DSN")
+	}
+	project := strings.Trim(u.Path, "/")
+	if project == "" {
+		return nil, fmt.Errorf("SENTRY_DSN has no project")
+	}
+
+	r := &errorReporter{
+		endpoint:    fmt.Sprintf("%s://%s/api/%s/store/", u.Scheme, u.Host, project),
+		auth:        fmt.Sprintf("Sentry sentry_version=7, sentry_client=shopops/1.0, sentry_key=%s", u.User.Username()),
+		environment: environment,
+		release:     release,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan ErrorEvent, 256),
+		flush:       make(chan chan struct{}),
+	}
+	go r.send()
+	return r, nil
+}
+
+// ReportPanic logs a recovered panic with its stack and sends it to the error tracker.
+// It returns the event ID to show the user so support can find the report. Call it
+// from the deferred function that recovered.
+func ReportPanic(ctx context.Context, recovered interface{}, tags map[string]string, userID string) string {
+	event := ErrorEvent{
+		ID:      NewRequestID(),
+		Message: redactPanic(recovered),
+		Frames:  callerFrames(4),
+		Tags:    tags,
+		UserID:  userID,
+	}
+	if span := SpanFrom(ctx); span != nil {
+		if event.Tags == nil {
+			event.Tags = make(map[string]string)
+		}
+		event.Tags["trace_id"] = span.TraceID()
+	}
+
+	stack := make([]string, 0, len(event.Frames))
+	for _, frame := range event.Frames {
+		stack = append(stack, fmt.Sprintf("%s\n\t%s:%d", frame.Function, frame.File, frame.Line))
+	}
+	LoggerFrom(ctx).Error("panic recovered",
+		slog.String("error_id", event.ID),
+		slog.String("panic", event.Message),
+		slog.String("stack", strings.Join(stack, "\n")),
+	)
+
+	if defaultReporter != nil {
+		select {
+		case defaultReporter.queue <- event:
+		default:
+			// A panic storm must not pile up goroutines; the log still has every event
+		}
+	}
+	return event.ID
+}
+
+// FlushErrorReports sends the events still queued, waiting until ctx is done at most
+func FlushErrorReports(ctx context.Context) {
+	if defaultReporter == nil {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case defaultReporter.flush <- done:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+// callerFrames walks the stack of the panicking goroutine, skipping the runtime's
+// panic machinery and the recovery code itself
+func callerFrames(skip int) []StackFrame {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(skip, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var out []StackFrame
+	for {
+		frame, more := frames.Next()
+		if !strings.HasPrefix(frame.Function, "runtime.") {
+			out = append(out, StackFrame{
+				Function: frame.Function,
+				File:     frame.File,
+				Line:     frame.Line,
+				InApp:    strings.HasPrefix(frame.Function, "ShopOps/"),
+			})
+		}
+		if !more {
+			break
+		}
+	}
+	return out
+}
+
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`)
+	phonePattern = regexp.MustCompile(`\+?\d[\d\s-]{6,}\d`)
+)
+
+// RedactPII masks email addresses and phone-like digit runs
+func RedactPII(s string) string {
+	s = emailPattern.ReplaceAllString(s, "[email]")
+	return phonePattern.ReplaceAllString(s, "[phone]")
+}
+
+// redactPanic describes a panic value with the PII fields of any model in it
+// masked and the rest scrubbed
+func redactPanic(recovered interface{}) string {
+	return RedactPII(fmt.Sprint(Domain.Redacted(recovered)))
+}
+
+func (r *errorReporter) send() {
+	for {
+		select {
+		case event := <-r.queue:
+			r.post(event)
+		case done := <-r.flush:
+			for drained := false; !drained; {
+				select {
+				case event := <-r.queue:
+					r.post(event)
+				default:
+					drained = true
+				}
+			}
+			close(done)
+		}
+	}
+}
+
+func (r *errorReporter) post(event ErrorEvent) {
+	// Sentry lists frames oldest first
+	frames := make([]StackFrame, len(event.Frames))
+	for i, frame := range event.Frames {
+		frames[len(frames)-1-i] = frame
+	}
+
+	payload := map[string]interface{}{
+		"event_id":    event.ID,
+		"timestamp":   time.Now().UTC().Format(time.RFC3339),
+		"level":       "error",
+		"platform":    "go",
+		"logger":      "panic",
+		"server_name": "shopops",
+		"environment": r.environment,
+		"tags":        event.Tags,
+		"exception": map[string]interface{}{
+			"values": []interface{}{
+				map[string]interface{}{
+					"type":       "panic",
+					"value":      event.Message,
+					"stacktrace": map[string]interface{}{"frames": frames},
+				},
+			},
+		},
+	}
+	if r.release != "" {
+		payload["release"] = r.release
+	}
+	if event.UserID != "" {
+		payload["user"] = map[string]string{"id": event.UserID}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		Logger.Warn("failed to encode error report", slog.String("error", err.Error()))
+		return
+	}
+
+	req, err := http.NewRequest(http.MethodPost, r.endpoint, bytes.NewReader(body))
+	if err != nil {
+		Logger.Warn("failed to build error report", slog.String("error", err.Error()))
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-Sentry-Auth", r.auth)
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		Logger.Warn("failed to send error report", slog.String("error_id", event.ID), slog.String("error", err.Error()))
+		return
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		Logger.Warn("error tracker rejected report", slog.String("error_id", event.ID), slog.String("status", resp.Status))
+	}
+}
@@ -0,0 +1,248 @@
+package Infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// EventHandler reacts to one domain event. Returning an error retries the event
+// later, for this subscriber alone. The same event may be handled more than once.
+type EventHandler func(ctx context.Context, event *Domain.DomainEvent) error
+
+// EventBus dispatches the domain events recorded in the outbox to the modules
+// subscribed to them. Every instance dispatches; events are claimed under a lease,
+// so each is worked on by one instance at a time and picked up again if it dies.
+type EventBus interface {
+	// Subscribe registers handler under name for the event types. The name keeps
+	// track of the subscriber's deliveries, so it must not change once in use.
+	// Call before Start.
+	Subscribe(name string, types []Domain.DomainEventType, handler EventHandler)
+	Start()
+	// Check fails when events have waited too long, meaning dispatchers are stuck or down
+	Check(ctx context.Context) error
+}
+
+const (
+	eventLease          = 2 * time.Minute
+	eventPollInterval   = time.Second
+	eventDispatchers    = 2
+	eventMaxAttempts    = 8
+	eventBacklogTimeout = 15 * time.Minute
+)
+
+type eventSubscription struct {
+	name    string
+	types   []Domain.DomainEventType
+	handler EventHandler
+}
+
+type eventBus struct {
+	repo     Domain.DomainEventRepository
+	workerID string
+
+	mu            sync.Mutex
+	subscriptions []eventSubscription
+}
+
+func NewEventBus(repo Domain.DomainEventRepository) EventBus {
+	host, _ := os.Hostname()
+	return &eventBus{
+		repo:     repo,
+		workerID: fmt.Sprintf("%s-%d-%s", host, os.Getpid(), NewRequestID()[:8]),
+	}
+}
+
+func (b *eventBus) Subscribe(name string, types []Domain.DomainEventType, handler EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subscriptions = append(b.subscriptions, eventSubscription{name: name, types: types, handler: handler})
+}
+
+func (b *eventBus) Start() {
+	for i := 0; i < eventDispatchers; i++ {
+		go b.dispatch(fmt.Sprintf("%s/events/%d", b.workerID, i))
+	}
+}
+
+// dispatch claims and delivers events until shutdown begins
+func (b *eventBus) dispatch(workerID string) {
+	for !IsShuttingDown() {
+		event, err := b.repo.Claim(workerID, eventLease)
+		if err != nil {
+			Logger.Warn("failed to claim event", slog.String("error", err.Error()))
+		}
+		if event == nil {
+			select {
+			case <-time.After(eventPollInterval):
+			case <-ShuttingDown():
+			}
+			continue
+		}
+		b.deliver(event, workerID)
+	}
+}
+
+// subscribers lists those subscribed to the event type
+func (b *eventBus) subscribers(eventType Domain.DomainEventType) []eventSubscription {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	var subscribers []eventSubscription
+	for _, subscription := range b.subscriptions {
+		for _, t := range subscription.types {
+			if t == eventType {
+				subscribers = append(subscribers, subscription)
+				break
+			}
+		}
+	}
+	return subscribers
+}
+
+func (b *eventBus) handler(name string) EventHandler {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	for _, subscription := range b.subscriptions {
+		if subscription.name == name {
+			return subscription.handler
+		}
+	}
+	return nil
+}
+
+// deliver runs the event's due deliveries and saves how each went
+func (b *eventBus) deliver(event *Domain.DomainEvent, workerID string) {
+	defer trackWork("events")()
+
+	logger := Logger.With(
+		slog.String("event_id", event.ID.Hex()),
+		slog.String("event_type", string(event.Type)),
+		slog.String("business_id", event.BusinessID.Hex()),
+	)
+	ctx, cancel := withShutdown(WithLogger(context.Background(), logger))
+	defer cancel()
+	// Subscribers look up the event's shop's records only
+	ctx = WithBusiness(ctx, event.BusinessID.Hex())
+
+	now := time.Now()
+	if event.Deliveries == nil {
+		event.Deliveries = []Domain.EventDelivery{}
+		for _, subscription := range b.subscribers(event.Type) {
+			event.Deliveries = append(event.Deliveries, Domain.EventDelivery{
+				Subscriber:    subscription.name,
+				Status:        Domain.DomainEventPending,
+				NextAttemptAt: now,
+			})
+		}
+	}
+
+	for i := range event.Deliveries {
+		delivery := &event.Deliveries[i]
+		if delivery.Status != Domain.DomainEventPending || delivery.NextAttemptAt.After(now) {
+			continue
+		}
+
+		var err error
+		if handler := b.handler(delivery.Subscriber); handler != nil {
+			err = runEventHandler(ctx, handler, event, delivery.Subscriber)
+		} else {
+			// Subscribed on another version of the app, mid-deploy
+			err = fmt.Errorf("no subscriber %s on this instance", delivery.Subscriber)
+		}
+
+		outcome := "delivered"
+		finished := time.Now()
+		switch {
+		case err == nil:
+			delivery.Status = Domain.DomainEventDelivered
+			delivery.LastError = ""
+			delivery.DeliveredAt = &finished
+		case ctx.Err() != nil && IsShuttingDown():
+			// Stopped for a deploy: try again without spending an attempt
+			outcome = "released"
+		default:
+			delivery.Attempts++
+			delivery.LastError = err.Error()
+			if delivery.Attempts >= eventMaxAttempts {
+				outcome = "dead"
+				delivery.Status = Domain.DomainEventDead
+			} else {
+				outcome = "retry"
+				delivery.NextAttemptAt = finished.Add(jobBackoff(delivery.Attempts))
+			}
+			level := slog.LevelWarn
+			if outcome == "dead" {
+				level = slog.LevelError
+			}
+			logger.Log(ctx, level, "event delivery failed", slog.String("subscriber", delivery.Subscriber),
+				slog.String("outcome", outcome), slog.Int("attempt", delivery.Attempts), slog.String("error", err.Error()))
+		}
+		EventDeliveries.Inc(delivery.Subscriber, outcome)
+	}
+
+	settleEvent(event)
+	if err := b.repo.Finish(event, workerID); err != nil {
+		logger.Error("failed to save event deliveries", slog.String("error", err.Error()))
+	}
+}
+
+// settleEvent sets the event's status and next attempt from its deliveries
+func settleEvent(event *Domain.DomainEvent) {
+	event.Status = Domain.DomainEventDelivered
+	var next time.Time
+	for _, delivery := range event.Deliveries {
+		switch delivery.Status {
+		case Domain.DomainEventPending:
+			event.Status = Domain.DomainEventPending
+			if next.IsZero() || delivery.NextAttemptAt.Before(next) {
+				next = delivery.NextAttemptAt
+			}
+		case Domain.DomainEventDead:
+			if event.Status != Domain.DomainEventPending {
+				event.Status = Domain.DomainEventDead
+			}
+		}
+	}
+
+	if event.Status == Domain.DomainEventPending {
+		event.NextAttemptAt = next
+		event.FinishedAt = nil
+		return
+	}
+	now := time.Now()
+	event.FinishedAt = &now
+}
+
+func runEventHandler(ctx context.Context, handler EventHandler, event *Domain.DomainEvent, subscriber string) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			errorID := ReportPanic(ctx, r, map[string]string{"event_type": string(event.Type), "event_id": event.ID.Hex(), "subscriber": subscriber}, "")
+			err = fmt.Errorf("panic, error ID %s", errorID)
+		}
+	}()
+	return handler(ctx, event)
+}
+
+func (b *eventBus) Check(ctx context.Context) error {
+	stats, err := b.repo.Stats()
+	if err != nil {
+		return err
+	}
+
+	var stuck []string
+	for _, stat := range stats {
+		if stat.Status == Domain.DomainEventPending && time.Since(stat.OldestAttempt) > eventBacklogTimeout {
+			stuck = append(stuck, string(stat.Type))
+		}
+	}
+	if len(stuck) > 0 {
+		return fmt.Errorf("events waiting over %s: %s", eventBacklogTimeout, strings.Join(stuck, ", "))
+	}
+	return nil
+}
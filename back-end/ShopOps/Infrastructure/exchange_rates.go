@@ -0,0 +1,63 @@
+package Infrastructure
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// ExchangeRateProvider fetches market exchange rates
+type ExchangeRateProvider interface {
+	// FetchRates returns how many units of each currency one unit of base buys
+	FetchRates(ctx context.Context, base string) (map[string]float64, error)
+}
+
+func NewExchangeRateProvider(cfg ExchangeRateConfig) ExchangeRateProvider {
+	return &httpExchangeRateProvider{
+		client: &http.Client{Timeout: cfg.Timeout},
+		apiURL: strings.TrimRight(cfg.APIURL, "/"),
+	}
+}
+
+// httpExchangeRateProvider reads the open.er-api.com format, which other free
+// rate APIs share: {"result": "success", "rates": {"USD": 0.0069, ...}}
+type httpExchangeRateProvider struct {
+	client *http.Client
+	apiURL string
+}
+
+func (p *httpExchangeRateProvider) FetchRates(ctx context.Context, base string) (map[string]float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, p.apiURL+"/"+strings.ToUpper(base), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("exchange rate request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("exchange rate API returned %s", resp.Status)
+	}
+
+	var body struct {
+		Result    string             `json:"result"`
+		ErrorType string             `json:"error-type"`
+		Rates     map[string]float64 `json:"rates"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+	if body.Result != "" && body.Result != "success" {
+		return nil, fmt.Errorf("exchange rate API error: %s", body.ErrorType)
+	}
+	if len(body.Rates) == 0 {
+		return nil, fmt.Errorf("exchange rate API returned no rates for %s", base)
+	}
+
+	return body.Rates, nil
+}
@@ -11,7 +11,10 @@ import (
 )
 
 type ExportService interface {
-	ExportToCSV(data interface{}, reportType Domain.ReportType) ([]byte, error)
+	// ExportToCSV writes dates in calendar; Gregorian when empty, and headers in
+	// language. Sales and inventory get a column for each of fields, the shop's
+	// custom fields.
+	ExportToCSV(data interface{}, reportType Domain.ReportType, calendar Domain.Calendar, language Domain.Language, loc *time.Location, fields []Domain.CustomFieldDefinition) ([]byte, error)
 	ExportToJSON(data interface{}) ([]byte, error)
 }
 
@@ -21,18 +24,25 @@ func NewExportService() ExportService {
 	return &exportService{}
 }
 
-func (s *exportService) ExportToCSV(data interface{}, reportType Domain.ReportType) ([]byte, error) {
+func (s *exportService) ExportToCSV(data interface{}, reportType Domain.ReportType, calendar Domain.Calendar, language Domain.Language, loc *time.Location, fields []Domain.CustomFieldDefinition) ([]byte, error) {
 	var records [][]string
+	headers := func(keys ...string) []string {
+		row := make([]string, len(keys))
+		for i, key := range keys {
+			row[i] = Translate(language, "export."+key)
+		}
+		return row
+	}
 
 	switch reportType {
 	case Domain.ReportTypeSales:
 		if sales, ok := data.([]Domain.Sale); ok {
 			// Add header
-			records = append(records, []string{
-				"ID", "Date", "Customer", "Phone", "Product", "Quantity",
-				"Unit Price", "Total", "Discount", "Tax", "Final Amount",
-				"Payment Method", "Payment Status", "Notes",
-			})
+			records = append(records, append(headers(
+				"id", "date", "customer", "phone", "product", "quantity",
+				"unit_price", "total", "discount", "tax", "final_amount",
+				"payment_method", "payment_status", "notes",
+			), customFieldHeaders(fields, Domain.CustomFieldEntitySale)...))
 
 			// Add data rows
 			for _, sale := range sales {
@@ -42,39 +52,39 @@ func (s *exportService) ExportToCSV(data interface{}, reportType Domain.ReportTy
 					productName = "Product"
 				}
 
-				records = append(records, []string{
+				records = append(records, append([]string{
 					sale.ID.Hex(),
-					sale.CreatedAt.Format("2006-01-02 15:04:05"),
+					Domain.FormatDate(sale.CreatedAt.In(loc), calendar) + sale.CreatedAt.In(loc).Format(" 15:04:05"),
 					sale.CustomerName,
 					sale.CustomerPhone,
 					productName,
 					fmt.Sprintf("%.2f", sale.Quantity),
-					fmt.Sprintf("%.2f", sale.UnitPrice),
-					fmt.Sprintf("%.2f", sale.TotalAmount),
-					fmt.Sprintf("%.2f", sale.Discount),
-					fmt.Sprintf("%.2f", sale.Tax),
-					fmt.Sprintf("%.2f", sale.FinalAmount),
+					sale.UnitPrice.String(),
+					sale.TotalAmount.String(),
+					sale.Discount.String(),
+					sale.Tax.String(),
+					sale.FinalAmount.String(),
 					string(sale.PaymentMethod),
 					string(sale.PaymentStatus),
 					sale.Notes,
-				})
+				}, customFieldCells(fields, Domain.CustomFieldEntitySale, sale.CustomFields)...))
 			}
 		}
 
 	case Domain.ReportTypeExpenses:
 		if expenses, ok := data.([]Domain.Expense); ok {
 			// Add header
-			records = append(records, []string{
-				"ID", "Date", "Category", "Amount", "Description",
-			})
+			records = append(records, headers(
+				"id", "date", "category", "amount", "description",
+			))
 
 			// Add data rows
 			for _, expense := range expenses {
 				records = append(records, []string{
 					expense.ID.Hex(),
-					expense.Date.Format("2006-01-02"),
+					Domain.FormatDate(expense.Date.In(loc), calendar),
 					string(expense.Category),
-					fmt.Sprintf("%.2f", expense.Amount),
+					expense.Amount.String(),
 					expense.Description,
 				})
 			}
@@ -83,27 +93,66 @@ func (s *exportService) ExportToCSV(data interface{}, reportType Domain.ReportTy
 	case Domain.ReportTypeInventory:
 		if products, ok := data.([]Domain.Product); ok {
 			// Add header
-			records = append(records, []string{
-				"ID", "Name", "SKU", "Barcode", "Category", "Unit",
-				"Cost Price", "Selling Price", "Stock", "Min Stock", "Max Stock",
-				"Status",
-			})
+			records = append(records, append(headers(
+				"id", "name", "sku", "barcode", "category", "unit",
+				"cost_price", "selling_price", "stock", "min_stock", "max_stock",
+				"status",
+			), customFieldHeaders(fields, Domain.CustomFieldEntityProduct)...))
 
 			// Add data rows
 			for _, product := range products {
-				records = append(records, []string{
+				records = append(records, append([]string{
 					product.ID.Hex(),
 					product.Name,
 					product.SKU,
 					product.Barcode,
 					product.Category,
 					product.Unit,
-					fmt.Sprintf("%.2f", product.CostPrice),
-					fmt.Sprintf("%.2f", product.SellingPrice),
+					product.CostPrice.String(),
+					product.SellingPrice.String(),
 					fmt.Sprintf("%.2f", product.Stock),
 					fmt.Sprintf("%.2f", product.MinStock),
 					fmt.Sprintf("%.2f", product.MaxStock),
 					string(product.Status),
+				}, customFieldCells(fields, Domain.CustomFieldEntityProduct, product.CustomFields)...))
+			}
+		}
+
+	case Domain.ReportTypeDeadStock:
+		if report, ok := data.(*Domain.DeadStockReport); ok {
+			records = append(records, headers(
+				"product_id", "name", "sku", "category", "status", "stock",
+				"cost_price", "selling_price", "tied_up", "retail_value",
+				"last_sold", "days_since", "sold_in_window", "days_of_cover",
+			))
+
+			for _, item := range report.Items {
+				lastSold, daysSince, cover := Translate(language, "export.never"), "", ""
+				if item.LastSoldAt != nil {
+					lastSold = Domain.FormatDate(item.LastSoldAt.In(loc), calendar)
+				}
+				if item.DaysSinceSale != nil {
+					daysSince = fmt.Sprintf("%d", *item.DaysSinceSale)
+				}
+				if item.DaysOfCover != nil {
+					cover = fmt.Sprintf("%.0f", *item.DaysOfCover)
+				}
+
+				records = append(records, []string{
+					item.ProductID,
+					item.ProductName,
+					item.SKU,
+					item.Category,
+					string(item.Status),
+					fmt.Sprintf("%.2f", item.Stock),
+					item.CostPrice.String(),
+					item.SellingPrice.String(),
+					item.TiedUpCapital.String(),
+					item.RetailValue.String(),
+					lastSold,
+					daysSince,
+					fmt.Sprintf("%.2f", item.SoldInWindow),
+					cover,
 				})
 			}
 		}
@@ -125,6 +174,27 @@ func (s *exportService) ExportToCSV(data interface{}, reportType Domain.ReportTy
 	return []byte(buf.String()), nil
 }
 
+// customFieldHeaders are the labels of the custom fields of entity, in order
+func customFieldHeaders(fields []Domain.CustomFieldDefinition, entity Domain.CustomFieldEntity) []string {
+	var headers []string
+	for _, field := range fields {
+		if field.Entity == entity {
+			headers = append(headers, field.Label)
+		}
+	}
+	return headers
+}
+
+func customFieldCells(fields []Domain.CustomFieldDefinition, entity Domain.CustomFieldEntity, values Domain.CustomFields) []string {
+	var cells []string
+	for _, field := range fields {
+		if field.Entity == entity {
+			cells = append(cells, Domain.FormatCustomField(values[field.Key]))
+		}
+	}
+	return cells
+}
+
 func (s *exportService) ExportToJSON(data interface{}) ([]byte, error) {
 	return json.MarshalIndent(data, "", "  ")
 }
@@ -0,0 +1,65 @@
+package Infrastructure
+
+import (
+	"context"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// FlagEvaluator lists the feature flags that are on for a shop
+type FlagEvaluator interface {
+	EnabledFlags(businessID string) []string
+}
+
+type featureFlagsKey struct{}
+
+// FeatureFlagsMiddleware evaluates the shop's feature flags once per request and
+// makes them available to handlers through FeatureEnabled and to usecases through
+// FlagEnabled. The enabled keys are also sent in X-Feature-Flags, so the apps can
+// switch behaviour, such as the sync protocol, without an extra call.
+func FeatureFlagsMiddleware(flags FlagEvaluator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		enabled := flags.EnabledFlags(c.GetString("businessID"))
+
+		set := make(map[string]bool, len(enabled))
+		for _, key := range enabled {
+			set[key] = true
+		}
+		c.Set("featureFlags", set)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), featureFlagsKey{}, set))
+		c.Header("X-Feature-Flags", strings.Join(enabled, ","))
+
+		c.Next()
+	}
+}
+
+// FeatureEnabled reports whether the flag is on for the request's shop
+func FeatureEnabled(c *gin.Context, key string) bool {
+	if set, ok := c.Get("featureFlags"); ok {
+		return set.(map[string]bool)[key]
+	}
+	return false
+}
+
+// FlagEnabled reports whether the flag is on in a request context
+func FlagEnabled(ctx context.Context, key string) bool {
+	if ctx == nil {
+		return false
+	}
+	set, _ := ctx.Value(featureFlagsKey{}).(map[string]bool)
+	return set[key]
+}
+
+// RequireFeature hides a route from shops the flag is off for
+func RequireFeature(key string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !FeatureEnabled(c, key) {
+			AbortWithError(c, Domain.NotFoundError("Not found"))
+			return
+		}
+		c.Next()
+	}
+}
@@ -0,0 +1,153 @@
+package Infrastructure
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// FileStorage stores uploaded files (receipt photos etc.) and returns the URL they
+// are kept under. Files are not served from it as they are: handlers that check
+// who is asking read them with Open, and links for those who cannot sign in, such
+// as in an email, are made with SignURL.
+type FileStorage interface {
+	Save(folder, filename string, r io.Reader) (string, error)
+	Delete(url string) error
+	// Open reads back a file Save stored
+	Open(url string) (io.ReadCloser, error)
+	// SignURL returns a link to the file that works until ttl has passed
+	SignURL(url string, ttl time.Duration) string
+	// OpenSigned opens the file a link made by SignURL points to, if it is still
+	// valid
+	OpenSigned(link string) (io.ReadCloser, error)
+	// Check verifies files can be written, for the health probes
+	Check() error
+}
+
+type localFileStorage struct {
+	baseDir    string
+	baseURL    string
+	signingKey []byte
+}
+
+// NewLocalFileStorage stores files on disk under UPLOAD_DIR, with URLs under
+// UPLOAD_BASE_URL; the router serves signed links to them there
+func NewLocalFileStorage(cfg StorageConfig) FileStorage {
+	return &localFileStorage{
+		baseDir:    cfg.UploadDir,
+		baseURL:    strings.TrimRight(cfg.UploadBaseURL, "/"),
+		signingKey: []byte(cfg.SigningKey),
+	}
+}
+
+func (s *localFileStorage) Save(folder, filename string, r io.Reader) (string, error) {
+	folder = filepath.Clean("/" + folder)
+	filename = filepath.Base(filename)
+	if filename == "." || filename == "/" {
+		return "", fmt.Errorf("invalid file name")
+	}
+
+	dir := filepath.Join(s.baseDir, folder)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	path := filepath.Join(dir, filename)
+	f, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create file: %w", err)
+	}
+	defer f.Close()
+
+	// r may be a streamed upload that fails partway, e.g. over its size limit
+	if _, err := io.Copy(f, r); err != nil {
+		_ = os.Remove(path)
+		return "", fmt.Errorf("failed to write file: %w", err)
+	}
+
+	return s.baseURL + filepath.ToSlash(filepath.Join(folder, filename)), nil
+}
+
+func (s *localFileStorage) Check() error {
+	if err := os.MkdirAll(s.baseDir, 0o755); err != nil {
+		return fmt.Errorf("failed to create upload directory: %w", err)
+	}
+
+	probe, err := os.CreateTemp(s.baseDir, ".healthcheck-*")
+	if err != nil {
+		return fmt.Errorf("upload directory is not writable: %w", err)
+	}
+	name := probe.Name()
+	probe.Close()
+	return os.Remove(name)
+}
+
+func (s *localFileStorage) Open(url string) (io.ReadCloser, error) {
+	if !strings.HasPrefix(url, s.baseURL+"/") {
+		return nil, fmt.Errorf("file %s is not in this storage", url)
+	}
+
+	rel := filepath.Clean("/" + strings.TrimPrefix(url, s.baseURL))
+	f, err := os.Open(filepath.Join(s.baseDir, rel))
+	if err != nil {
+		return nil, fmt.Errorf("failed to open file: %w", err)
+	}
+	return f, nil
+}
+
+func (s *localFileStorage) Delete(url string) error {
+	if !strings.HasPrefix(url, s.baseURL+"/") {
+		// Not one of ours (e.g. uploaded by the client elsewhere)
+		return nil
+	}
+
+	rel := filepath.Clean("/" + strings.TrimPrefix(url, s.baseURL))
+	if err := os.Remove(filepath.Join(s.baseDir, rel)); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to delete file: %w", err)
+	}
+
+	return nil
+}
+
+func (s *localFileStorage) SignURL(fileURL string, ttl time.Duration) string {
+	expires := strconv.FormatInt(time.Now().Add(ttl).Unix(), 10)
+	return fileURL + "?expires=" + expires + "&signature=" + s.signature(fileURL, expires)
+}
+
+func (s *localFileStorage) OpenSigned(link string) (io.ReadCloser, error) {
+	if len(s.signingKey) == 0 {
+		return nil, fmt.Errorf("this storage does not serve signed links")
+	}
+
+	parsed, err := url.Parse(link)
+	if err != nil {
+		return nil, fmt.Errorf("invalid link: %w", err)
+	}
+	expires := parsed.Query().Get("expires")
+	signature := parsed.Query().Get("signature")
+
+	if !hmac.Equal([]byte(signature), []byte(s.signature(parsed.Path, expires))) {
+		return nil, fmt.Errorf("invalid link signature")
+	}
+	unix, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || time.Now().Unix() > unix {
+		return nil, fmt.Errorf("link has expired")
+	}
+
+	return s.Open(parsed.Path)
+}
+
+// signature binds the file's URL to the link's expiry
+func (s *localFileStorage) signature(fileURL, expires string) string {
+	mac := hmac.New(sha256.New, s.signingKey)
+	mac.Write([]byte(fileURL + "\n" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
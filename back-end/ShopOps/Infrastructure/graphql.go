@@ -0,0 +1,46 @@
+package Infrastructure
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	Domain "ShopOps/Domain"
+)
+
+// GraphQLError is JSONError for GraphQL resolvers: it logs the error and returns one
+// whose code, message key and field details are sent in the error's extensions, so
+// clients can branch on them as REST clients do. The field it failed on is null.
+func GraphQLError(ctx context.Context, httpStatus int, err error) error {
+	var appErr *Domain.AppError
+	if errors.As(err, &appErr) {
+		appErr = &Domain.AppError{Code: appErr.Code, Message: err.Error(), Key: appErr.Key, Details: appErr.Details}
+	} else {
+		appErr = &Domain.AppError{Code: classifyError(httpStatus, err, err.Error()), Message: err.Error()}
+	}
+
+	LoggerFrom(ctx).Error("graphql field failed",
+		slog.String("code", string(appErr.Code)),
+		slog.String("error", appErr.Message),
+	)
+	return &graphQLError{appErr}
+}
+
+type graphQLError struct {
+	appErr *Domain.AppError
+}
+
+func (e *graphQLError) Error() string {
+	return e.appErr.Message
+}
+
+func (e *graphQLError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{
+		"code":        e.appErr.Code,
+		"message_key": e.appErr.MessageKey(),
+	}
+	if len(e.appErr.Details) > 0 {
+		extensions["details"] = e.appErr.Details
+	}
+	return extensions
+}
@@ -0,0 +1,270 @@
+package Infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/protoadapt"
+)
+
+// The gRPC server shares the REST API's authentication, tenancy and rate limits. Its
+// interceptors mirror the gin middleware: logging, then authentication, rate
+// limiting and the shop the call acts on. Recovery runs last, so a panic is
+// reported with the user and shop.
+
+var GRPCRequestDuration = NewHistogramVec("shopops_grpc_request_duration_seconds",
+	"gRPC call latency by method and status code", DefaultBuckets, "method", "code")
+
+// GRPCLogging assigns each call a request ID, keeps a logger with it on the context
+// and logs the call once it completes
+func GRPCLogging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		start := time.Now()
+
+		requestID := firstMetadata(ctx, strings.ToLower(RequestIDHeader))
+		if !validRequestID(requestID) {
+			requestID = NewRequestID()
+		}
+		_ = grpc.SetHeader(ctx, metadata.Pairs(strings.ToLower(RequestIDHeader), requestID))
+		ctx = WithLogger(ctx, Logger.With(slog.String("request_id", requestID)))
+
+		resp, err := handler(ctx, req)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		switch code {
+		case codes.OK:
+		case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+			level = slog.LevelError
+		default:
+			level = slog.LevelWarn
+		}
+		attrs := []slog.Attr{
+			slog.String("method", info.FullMethod),
+			slog.String("code", code.String()),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+		}
+		LoggerFrom(ctx).LogAttrs(ctx, level, "grpc request", attrs...)
+		GRPCRequestDuration.ObserveSince(start, info.FullMethod, code.String())
+
+		return resp, err
+	}
+}
+
+// GRPCRecovery turns a panic in a handler into an Internal error with an error ID the
+// caller can quote, and reports it with the user and shop it happened on
+func GRPCRecovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp interface{}, err error) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			tags := map[string]string{"method": info.FullMethod}
+			userID := ""
+			if principal, ok := PrincipalFromContext(ctx); ok {
+				userID = principal.UserID
+			}
+			if tenant, ok := TenantFromContext(ctx); ok {
+				tags["business_id"] = tenant.BusinessID.Hex()
+			}
+
+			errorID := ReportPanic(ctx, r, tags, userID)
+			resp, err = nil, status.Errorf(codes.Internal, "Internal server error (error ID %s)", errorID)
+		}()
+
+		return handler(ctx, req)
+	}
+}
+
+// GRPCAuth authenticates calls by the bearer token in their authorization metadata.
+// As over REST, impersonation sessions may only call Get and List methods.
+func GRPCAuth(jwtService JWTService) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		authHeader := firstMetadata(ctx, "authorization")
+		if authHeader == "" {
+			return nil, GRPCError(ctx, http.StatusUnauthorized, Domain.NewAppError(Domain.ErrCodeUnauthorized, "authorization metadata is required"))
+		}
+
+		principal, appErr := Authenticate(jwtService, authHeader)
+		if appErr != nil {
+			return nil, GRPCError(ctx, http.StatusUnauthorized, appErr)
+		}
+		if principal.Impersonator != "" && !isReadOnlyRPC(info.FullMethod) {
+			return nil, GRPCError(ctx, http.StatusForbidden, Domain.AccessDeniedError("Impersonation sessions are read-only"))
+		}
+
+		ctx = WithPrincipal(ctx, principal)
+		ctx = WithLogger(ctx, LoggerFrom(ctx).With(slog.String("user_id", principal.UserID)))
+		return handler(ctx, req)
+	}
+}
+
+func isReadOnlyRPC(fullMethod string) bool {
+	method := fullMethod[strings.LastIndex(fullMethod, "/")+1:]
+	return strings.HasPrefix(method, "Get") || strings.HasPrefix(method, "List")
+}
+
+// GRPCRateLimit counts every call against the user's general limit, and the methods
+// in extra against a further limit, e.g. sync calls against the sync limit. The
+// further limits use the keys the REST routes do, so a user or device has one
+// budget across both APIs; the restore limit is counted per device_id.
+func GRPCRateLimit(rateLimiter RateLimitService, extra map[string]RateLimit) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		principal, ok := PrincipalFromContext(ctx)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		limits := []RateLimit{RateLimitGeneral}
+		if limit, ok := extra[info.FullMethod]; ok {
+			limits = append(limits, limit)
+		}
+
+		// The headers report the last limit counted, the call's most specific
+		md := metadata.MD{}
+		for _, limit := range limits {
+			key := "user:" + principal.UserID
+			if limit == RateLimitRestore {
+				device, _ := req.(interface{ GetDeviceId() string })
+				if device == nil || device.GetDeviceId() == "" {
+					return nil, GRPCError(ctx, http.StatusBadRequest, Domain.NewAppError(Domain.ErrCodeInvalidArgument,
+						"device_id is required for restore operations"))
+				}
+				key = "device:" + device.GetDeviceId()
+			}
+
+			result, err := rateLimiter.Allow(ctx, limit, key)
+			if err != nil {
+				LoggerFrom(ctx).Warn("rate limiter unavailable", slog.String("error", err.Error()))
+				continue
+			}
+
+			md.Set("x-ratelimit-limit", strconv.FormatInt(result.Limit, 10))
+			md.Set("x-ratelimit-remaining", strconv.FormatInt(result.Remaining, 10))
+			md.Set("x-ratelimit-reset", strconv.FormatInt(result.Reset, 10))
+			if result.Reached {
+				md.Set("retry-after", strconv.FormatInt(result.Reset, 10))
+				_ = grpc.SetHeader(ctx, md)
+				return nil, GRPCError(ctx, http.StatusTooManyRequests, Domain.NewAppError(Domain.ErrCodeRateLimited,
+					fmt.Sprintf("Rate limit exceeded. Maximum %d %s requests; retry in %d seconds.", result.Limit, limit, result.Reset)))
+			}
+		}
+		if len(md) > 0 {
+			_ = grpc.SetHeader(ctx, md)
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+// GRPCTenancy resolves the shop of calls whose request has a business_id, and rejects
+// callers who do not belong to it. Handlers read it with TenantFromContext.
+func GRPCTenancy(resolver *TenantResolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		scoped, ok := req.(interface{ GetBusinessId() string })
+		if !ok {
+			return handler(ctx, req)
+		}
+		principal, ok := PrincipalFromContext(ctx)
+		if !ok {
+			return nil, GRPCError(ctx, http.StatusUnauthorized, Domain.NewAppError(Domain.ErrCodeUnauthorized, "User not authenticated"))
+		}
+
+		tenant, err := resolver.Resolve(scoped.GetBusinessId(), principal.UserID, principal.Role)
+		if err != nil {
+			return nil, GRPCError(ctx, http.StatusInternalServerError, err)
+		}
+
+		ctx = WithTenant(ctx, tenant)
+		ctx = WithLogger(ctx, LoggerFrom(ctx).With(slog.String("business_id", tenant.BusinessID.Hex())))
+		return handler(ctx, req)
+	}
+}
+
+// GRPCError is JSONError for gRPC: it logs the error and returns it as a status. An
+// *Domain.AppError in err's chain sets the code; other errors are classified from
+// the given HTTP status and their message. The error code and message key travel in
+// an ErrorInfo detail, so clients can branch on them as REST clients do.
+func GRPCError(ctx context.Context, httpStatus int, err error) error {
+	var appErr *Domain.AppError
+	if errors.As(err, &appErr) {
+		appErr = &Domain.AppError{Code: appErr.Code, Message: err.Error(), Key: appErr.Key, Details: appErr.Details}
+	} else {
+		appErr = &Domain.AppError{Code: classifyError(httpStatus, err, err.Error()), Message: err.Error()}
+	}
+
+	LoggerFrom(ctx).Error("grpc request failed",
+		slog.String("code", string(appErr.Code)),
+		slog.String("error", appErr.Message),
+	)
+
+	st := status.New(grpcCode(appErr.Code), appErr.Message)
+	info := &errdetails.ErrorInfo{
+		Reason:   string(appErr.Code),
+		Domain:   "shopops",
+		Metadata: map[string]string{"message_key": appErr.MessageKey()},
+	}
+	var violations []*errdetails.BadRequest_FieldViolation
+	for _, detail := range appErr.Details {
+		violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: detail.Field, Description: detail.Message})
+	}
+
+	details := []protoadapt.MessageV1{info}
+	if len(violations) > 0 {
+		details = append(details, &errdetails.BadRequest{FieldViolations: violations})
+	}
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st.Err()
+}
+
+// grpcCode maps an error code to its gRPC status code, as HTTPStatus does for REST
+func grpcCode(code Domain.ErrorCode) codes.Code {
+	switch code {
+	case Domain.ErrCodeInvalidArgument, Domain.ErrCodeValidationFailed:
+		return codes.InvalidArgument
+	case Domain.ErrCodeBadRequest, Domain.ErrCodePaymentRequired:
+		return codes.FailedPrecondition
+	case Domain.ErrCodeUnauthorized:
+		return codes.Unauthenticated
+	case Domain.ErrCodeAccessDenied:
+		return codes.PermissionDenied
+	case Domain.ErrCodeNotFound:
+		return codes.NotFound
+	case Domain.ErrCodeConflict:
+		return codes.Aborted
+	case Domain.ErrCodePayloadTooLarge, Domain.ErrCodeRateLimited:
+		return codes.ResourceExhausted
+	case Domain.ErrCodeUnavailable, Domain.ErrCodeMaintenance:
+		return codes.Unavailable
+	default:
+		return codes.Internal
+	}
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ""
+	}
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
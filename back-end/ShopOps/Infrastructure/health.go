@@ -0,0 +1,159 @@
+package Infrastructure
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+type HealthStatus string
+
+const (
+	HealthStatusOK       HealthStatus = "ok"
+	HealthStatusDegraded HealthStatus = "degraded" // A non-critical dependency is failing
+	HealthStatusDown     HealthStatus = "down"
+)
+
+type DependencyHealth struct {
+	Name      string       `json:"name"`
+	Status    HealthStatus `json:"status"`
+	Critical  bool         `json:"critical"` // Failing takes the instance out of rotation
+	LatencyMS int64        `json:"latency_ms"`
+	Error     string       `json:"error,omitempty"`
+}
+
+type HealthReport struct {
+	Status       HealthStatus       `json:"status"`
+	StartedAt    time.Time          `json:"started_at"`
+	Uptime       string             `json:"uptime"`
+	CheckedAt    time.Time          `json:"checked_at"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	ShuttingDown bool               `json:"shutting_down,omitempty"`
+}
+
+type healthCheck struct {
+	name     string
+	critical bool
+	check    func(ctx context.Context) error
+}
+
+// HealthChecker runs dependency checks for the liveness and readiness probes
+type HealthChecker struct {
+	mu        sync.RWMutex
+	checks    []healthCheck
+	startedAt time.Time
+}
+
+// Each check gets this long before it counts as failed
+const healthCheckTimeout = 3 * time.Second
+
+func NewHealthChecker() *HealthChecker {
+	return &HealthChecker{startedAt: time.Now()}
+}
+
+// Register adds a dependency check. Critical dependencies fail readiness; the others
+// only mark the instance degraded.
+func (h *HealthChecker) Register(name string, critical bool, check func(ctx context.Context) error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.checks = append(h.checks, healthCheck{name: name, critical: critical, check: check})
+}
+
+// Check runs every registered check concurrently
+func (h *HealthChecker) Check(ctx context.Context) HealthReport {
+	h.mu.RLock()
+	checks := append([]healthCheck(nil), h.checks...)
+	h.mu.RUnlock()
+
+	now := time.Now()
+	report := HealthReport{
+		Status:       HealthStatusOK,
+		StartedAt:    h.startedAt,
+		Uptime:       now.Sub(h.startedAt).Round(time.Second).String(),
+		CheckedAt:    now,
+		Dependencies: make([]DependencyHealth, len(checks)),
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check healthCheck) {
+			defer wg.Done()
+			report.Dependencies[i] = runHealthCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	for _, dep := range report.Dependencies {
+		if dep.Status == HealthStatusOK {
+			continue
+		}
+		if dep.Critical {
+			report.Status = HealthStatusDown
+		} else if report.Status == HealthStatusOK {
+			report.Status = HealthStatusDegraded
+		}
+	}
+
+	return report
+}
+
+func runHealthCheck(ctx context.Context, check healthCheck) (dep DependencyHealth) {
+	dep = DependencyHealth{Name: check.name, Status: HealthStatusOK, Critical: check.critical}
+
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+
+	start := time.Now()
+	defer func() {
+		dep.LatencyMS = time.Since(start).Milliseconds()
+		if r := recover(); r != nil {
+			dep.Status = HealthStatusDown
+			dep.Error = "check panicked"
+		}
+	}()
+
+	done := make(chan error, 1)
+	go func() { done <- check.check(ctx) }()
+
+	select {
+	case err := <-done:
+		if err != nil {
+			dep.Status = HealthStatusDown
+			dep.Error = err.Error()
+		}
+	case <-ctx.Done():
+		dep.Status = HealthStatusDown
+		dep.Error = "timed out"
+	}
+	return dep
+}
+
+// Liveness reports every dependency for the status page but only fails when the
+// process cannot serve at all, so a database outage does not restart every pod
+func (h *HealthChecker) Liveness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.JSON(http.StatusOK, h.Check(c.Request.Context()))
+	}
+}
+
+// Readiness fails while a critical dependency is down or the instance is shutting down,
+// so load balancers stop sending it traffic before the server closes
+func (h *HealthChecker) Readiness() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		report := h.Check(c.Request.Context())
+		if IsShuttingDown() {
+			report.Status = HealthStatusDown
+			report.ShuttingDown = true
+		}
+
+		status := http.StatusOK
+		if report.Status == HealthStatusDown {
+			status = http.StatusServiceUnavailable
+		}
+		c.JSON(status, report)
+	}
+}
@@ -0,0 +1,44 @@
+package Infrastructure
+
+import (
+	"net/http"
+	"strconv"
+)
+
+// NewHTTPServer configures the API server with the timeouts and header limit from
+// cfg, and the protocols it speaks: HTTP/1.1 always, HTTP/2 unless turned off
+func NewHTTPServer(cfg ServerConfig, handler http.Handler) *http.Server {
+	server := &http.Server{
+		Addr:              ":" + strconv.Itoa(cfg.Port),
+		Handler:           handler,
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+	}
+
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	if cfg.HTTP2 {
+		if cfg.TLSCertFile != "" {
+			protocols.SetHTTP2(true)
+		} else {
+			// Without TLS there is no negotiation, so only clients that start with
+			// HTTP/2 get it
+			protocols.SetUnencryptedHTTP2(true)
+		}
+		server.HTTP2 = &http.HTTP2Config{MaxConcurrentStreams: cfg.HTTP2MaxStreams}
+	}
+	server.Protocols = protocols
+
+	return server
+}
+
+// ListenAndServe serves over TLS when a certificate is configured
+func ListenAndServe(server *http.Server, cfg ServerConfig) error {
+	if cfg.TLSCertFile != "" {
+		return server.ListenAndServeTLS(cfg.TLSCertFile, cfg.TLSKeyFile)
+	}
+	return server.ListenAndServe()
+}
@@ -0,0 +1,532 @@
+package Infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Server-generated text is looked up in the catalog below by key and written in
+// the request's language: the first supported one in Accept-Language, else the
+// shop's own, else English. English messages built in code stay as they are, as
+// they carry more detail than the generic keys; other languages replace them
+// with the translation of their key.
+
+type languageKey struct{}
+
+// translation is a message in each supported language; fmt verbs are filled from
+// the arguments to Translate. An empty translation falls back to English.
+type translation struct {
+	en, am, om string
+}
+
+func (t translation) in(language Domain.Language) string {
+	switch language {
+	case Domain.LanguageAmharic:
+		return t.am
+	case Domain.LanguageOromo:
+		return t.om
+	}
+	return t.en
+}
+
+// LanguageMiddleware picks the request's language from Accept-Language. Routes
+// under a shop fall back to the shop's language in TenancyMiddleware when the
+// header names no supported language.
+func LanguageMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		language, ok := NegotiateLanguage(c.GetHeader("Accept-Language"))
+		c.Set("languageRequested", ok)
+		setRequestLanguage(c, language)
+		c.Header("Vary", "Accept-Language")
+		c.Next()
+	}
+}
+
+func setRequestLanguage(c *gin.Context, language Domain.Language) {
+	c.Set("language", language)
+	c.Header("Content-Language", string(language))
+	c.Request = c.Request.WithContext(WithLanguage(c.Request.Context(), language))
+}
+
+// useShopLanguage switches the request to the shop's language unless the client
+// asked for one
+func useShopLanguage(c *gin.Context, language Domain.Language) {
+	if c.GetBool("languageRequested") || !language.IsValid() {
+		return
+	}
+	setRequestLanguage(c, language)
+}
+
+// RequestLanguage is the language the response is written in
+func RequestLanguage(c *gin.Context) Domain.Language {
+	if language, ok := c.Get("language"); ok {
+		return language.(Domain.Language)
+	}
+	return Domain.DefaultLanguage
+}
+
+// WithLanguage returns ctx writing text in language
+func WithLanguage(ctx context.Context, language Domain.Language) context.Context {
+	return context.WithValue(ctx, languageKey{}, language)
+}
+
+// LanguageFromContext is the language text for a request context is written in
+func LanguageFromContext(ctx context.Context) Domain.Language {
+	if ctx != nil {
+		if language, ok := ctx.Value(languageKey{}).(Domain.Language); ok {
+			return language
+		}
+	}
+	return Domain.DefaultLanguage
+}
+
+// NegotiateLanguage picks the supported language the client prefers from an
+// Accept-Language header such as "am-ET,am;q=0.9,en;q=0.5". It returns the
+// default language and false when none is acceptable.
+func NegotiateLanguage(header string) (Domain.Language, bool) {
+	type candidate struct {
+		language Domain.Language
+		quality  float64
+	}
+
+	var candidates []candidate
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		language, ok := Domain.ParseLanguage(tag)
+		if !ok {
+			continue
+		}
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			parsed, err := strconv.ParseFloat(q, 64)
+			if err != nil {
+				continue
+			}
+			quality = parsed
+		}
+		if quality <= 0 {
+			continue
+		}
+		candidates = append(candidates, candidate{language: language, quality: quality})
+	}
+	if len(candidates) == 0 {
+		return Domain.DefaultLanguage, false
+	}
+
+	// Stable, so ties go to the language listed first
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+	return candidates[0].language, true
+}
+
+// Translate writes the message for key in language, filling in args. Keys missing
+// from the catalog come back as they are, so a gap shows rather than hides.
+func Translate(language Domain.Language, key string, args ...interface{}) string {
+	message, ok := lookupMessage(language, key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return message
+	}
+	return fmt.Sprintf(message, args...)
+}
+
+// lookupMessage finds key in language, falling back to English
+func lookupMessage(language Domain.Language, key string) (string, bool) {
+	t, ok := messages[key]
+	if !ok {
+		return "", false
+	}
+	if message := t.in(language); message != "" {
+		return message, true
+	}
+	return t.en, t.en != ""
+}
+
+// localizeError rewrites an error's message and details in language. English is
+// left alone; other languages fall back to the English message when the catalog
+// has nothing for the error.
+func localizeError(language Domain.Language, appErr *Domain.AppError) (string, []Domain.ErrorDetail) {
+	message := appErr.Error()
+	if language == Domain.LanguageEnglish || !language.IsValid() {
+		return message, appErr.Details
+	}
+
+	details := make([]Domain.ErrorDetail, len(appErr.Details))
+	copy(details, appErr.Details)
+	for i := range details {
+		if text, ok := lookupMessage(language, "validation."+details[i].Code); ok {
+			details[i].Message = fmt.Sprintf(text, details[i].Field, strings.ReplaceAll(details[i].Param, " ", ", "))
+		}
+	}
+
+	if appErr.Code == Domain.ErrCodeValidationFailed && len(details) > 0 && appErr.Key == "" {
+		message = details[0].Message
+		if len(details) > 1 {
+			message = Translate(language, "validation.and_more", message, len(details)-1)
+		}
+	} else if text, ok := lookupMessage(language, appErr.MessageKey()); ok {
+		message = text
+	}
+	return message, details
+}
+
+// printerLanguage is the language receipts are printed in. Receipt printers only
+// encode ASCII, so Amharic receipts are printed with English labels.
+func printerLanguage(language Domain.Language) Domain.Language {
+	if language == Domain.LanguageAmharic {
+		return Domain.LanguageEnglish
+	}
+	return Domain.LanguageOr(language, Domain.DefaultLanguage)
+}
+
+var messages = map[string]translation{
+	// Errors, by code
+	"errors.bad_request": {
+		en: "The request could not be completed",
+		am: "ጥያቄው ሊፈጸም አልቻለም",
+		om: "Gaaffiin raawwatamuu hin dandeenye",
+	},
+	"errors.invalid_argument": {
+		en: "The request has invalid data",
+		am: "ጥያቄው የተሳሳተ መረጃ ይዟል",
+		om: "Gaaffiin odeeffannoo dogoggoraa qaba",
+	},
+	"errors.validation_failed": {
+		en: "Some fields are not valid",
+		am: "አንዳንድ መስኮች ትክክል አይደሉም",
+		om: "Dirreewwan tokko tokko sirrii miti",
+	},
+	"errors.unauthorized": {
+		en: "Authentication failed",
+		am: "ማረጋገጫው አልተሳካም",
+		om: "Mirkaneessi hin milkoofne",
+	},
+	"errors.access_denied": {
+		en: "You are not allowed to do this",
+		am: "ይህን ለማድረግ ፈቃድ የለዎትም",
+		om: "Kana gochuuf hayyama hin qabdan",
+	},
+	"errors.not_found": {
+		en: "What you asked for was not found",
+		am: "የጠየቁት አልተገኘም",
+		om: "Wanti gaafattan hin argamne",
+	},
+	"errors.conflict": {
+		en: "This clashes with existing data",
+		am: "ይህ ካለ መረጃ ጋር ይጋጫል",
+		om: "Kun odeeffannoo jiru waliin wal-dhaba",
+	},
+	"errors.payment_required": {
+		en: "Your shop's plan does not include this; upgrade to continue",
+		am: "ይህ በሱቅዎ ዕቅድ ውስጥ አልተካተተም፤ ለመቀጠል ዕቅድዎን ያሳድጉ",
+		om: "Kun karoora suuqii keessanii keessa hin jiru; itti fufuuf karoora keessan guddisaa",
+	},
+	"errors.payload_too_large": {
+		en: "What was sent is too large",
+		am: "የተላከው መረጃ በጣም ትልቅ ነው",
+		om: "Odeeffannoon ergame baay'ee guddaa dha",
+	},
+	"errors.rate_limited": {
+		en: "Too many requests; try again shortly",
+		am: "በጣም ብዙ ጥያቄዎች ተልከዋል፤ ትንሽ ቆይተው እንደገና ይሞክሩ",
+		om: "Gaaffiiwwan baay'ee ergamaniiru; yeroo muraasaan booda irra deebi'aa yaalaa",
+	},
+	"errors.unavailable": {
+		en: "The service is unavailable for now; try again",
+		am: "አገልግሎቱ ለጊዜው አይገኝም፤ እንደገና ይሞክሩ",
+		om: "Tajaajilli yeroof hin jiru; irra deebi'aa yaalaa",
+	},
+	"errors.maintenance": {
+		en: "The system is under maintenance; save changes again shortly",
+		am: "ሥርዓቱ በጥገና ላይ ነው፤ ለውጦችን ትንሽ ቆይተው ያስቀምጡ",
+		om: "Sirni suphaa irra jira; jijjiirama yeroo muraasaan booda olkaa'aa",
+	},
+	"errors.internal_error": {
+		en: "Something went wrong",
+		am: "ያልተጠበቀ ስህተት ተፈጥሯል",
+		om: "Dogoggorri hin eegamne uumameera",
+	},
+	"errors.stale_version": {
+		en: "This was changed by someone else; reload it and try again",
+		am: "ይህ በሌላ ሰው ተቀይሯል፤ እንደገና ጭነው ይሞክሩ",
+		om: "Kun nama biraatiin jijjiirameera; irra deebi'aa fe'aatii yaalaa",
+	},
+
+	// Field validation, by rule; the field and the rule's parameter fill the verbs
+	"validation.required": {
+		en: "%[1]s is required",
+		am: "%[1]s ያስፈልጋል",
+		om: "%[1]s ni barbaachisa",
+	},
+	"validation.gt": {
+		en: "%[1]s must be greater than %[2]s",
+		am: "%[1]s ከ%[2]s በላይ መሆን አለበት",
+		om: "%[1]s %[2]s caaluu qaba",
+	},
+	"validation.gte": {
+		en: "%[1]s must be at least %[2]s",
+		am: "%[1]s ቢያንስ %[2]s መሆን አለበት",
+		om: "%[1]s yoo xiqqaate %[2]s ta'uu qaba",
+	},
+	"validation.lt": {
+		en: "%[1]s must be less than %[2]s",
+		am: "%[1]s ከ%[2]s በታች መሆን አለበት",
+		om: "%[1]s %[2]s gadi ta'uu qaba",
+	},
+	"validation.lte": {
+		en: "%[1]s must be at most %[2]s",
+		am: "%[1]s ቢበዛ %[2]s መሆን አለበት",
+		om: "%[1]s yoo baay'ate %[2]s ta'uu qaba",
+	},
+	"validation.min": {
+		en: "%[1]s must be at least %[2]s",
+		am: "%[1]s ቢያንስ %[2]s መሆን አለበት",
+		om: "%[1]s yoo xiqqaate %[2]s ta'uu qaba",
+	},
+	"validation.max": {
+		en: "%[1]s must be at most %[2]s",
+		am: "%[1]s ቢበዛ %[2]s መሆን አለበት",
+		om: "%[1]s yoo baay'ate %[2]s ta'uu qaba",
+	},
+	"validation.oneof": {
+		en: "%[1]s must be one of: %[2]s",
+		am: "%[1]s ከሚከተሉት አንዱ መሆን አለበት፦ %[2]s",
+		om: "%[1]s kanneen armaan gadii keessaa tokko ta'uu qaba: %[2]s",
+	},
+	"validation.email": {
+		en: "%[1]s must be a valid email address",
+		am: "%[1]s ትክክለኛ የኢሜይል አድራሻ መሆን አለበት",
+		om: "%[1]s teessoo imeelii sirrii ta'uu qaba",
+	},
+	"validation.phone": {
+		en: "%[1]s must be a valid phone number",
+		am: "%[1]s ትክክለኛ የስልክ ቁጥር መሆን አለበት",
+		om: "%[1]s lakkoofsa bilbilaa sirrii ta'uu qaba",
+	},
+	"validation.tin": {
+		en: "%[1]s must be a 10 digit TIN",
+		am: "%[1]s ባለ 10 አሃዝ የግብር ከፋይ መለያ ቁጥር መሆን አለበት",
+		om: "%[1]s TIN dijiitii 10 ta'uu qaba",
+	},
+	"validation.money": {
+		en: "%[1]s must be a non-negative amount with at most two decimal places",
+		am: "%[1]s ከዜሮ ያላነሰ እና ከሁለት በላይ የአስርዮሽ ቦታ የሌለው መጠን መሆን አለበት",
+		om: "%[1]s maallaqa zeeroo gadi hin taane kan lakkoofsa tuqaa booda lama hin caalle ta'uu qaba",
+	},
+	"validation.and_more": {
+		en: "%s (and %d more)",
+		am: "%s (እና ሌሎች %d)",
+		om: "%s (fi kan biroo %d)",
+	},
+
+	// Receipts and Z reports
+	"receipt.tel":          {en: "Tel", am: "ስልክ", om: "Bilbila"},
+	"receipt.receipt":      {en: "Receipt", am: "ደረሰኝ", om: "Nagahee"},
+	"receipt.date":         {en: "Date", am: "ቀን", om: "Guyyaa"},
+	"receipt.customer":     {en: "Customer", am: "ደንበኛ", om: "Maamila"},
+	"receipt.item":         {en: "Item", am: "ዕቃ", om: "Meeshaa"},
+	"receipt.discount":     {en: "Discount", am: "ቅናሽ", om: "Hir'isa"},
+	"receipt.tax":          {en: "Tax", am: "ግብር", om: "Gibira"},
+	"receipt.total":        {en: "TOTAL", am: "ጠቅላላ", om: "WALIIGALA"},
+	"receipt.rounding":     {en: "Rounding", am: "ማጠጋጋት", om: "Geggeessa"},
+	"receipt.cash_due":     {en: "Cash due", am: "የሚከፈል ጥሬ ገንዘብ", om: "Maallaqa kaffalamu"},
+	"receipt.deposit":      {en: "Deposit", am: "ማስያዣ", om: "Qabsiisa"},
+	"receipt.amount_due":   {en: "Amount due", am: "የሚከፈል መጠን", om: "Kaffaltii barbaachisu"},
+	"receipt.change":       {en: "Change", am: "መልስ", om: "Deebii"},
+	"receipt.paid":         {en: "Paid", am: "የተከፈለ", om: "Kaffalame"},
+	"zreport.title":        {en: "Z REPORT", am: "የቀን ማጠቃለያ", om: "GABAASA Z"},
+	"zreport.payments":     {en: "Payments", am: "ክፍያዎች", om: "Kaffaltiiwwan"},
+	"zreport.tills":        {en: "Tills", am: "ካዝናዎች", om: "Qarshii kuusaawwan"},
+	"zreport.unknown":      {en: "Unknown", am: "ያልታወቀ", om: "Kan hin beekamne"},
+	"zreport.no_sales":     {en: "No sales", am: "ሽያጭ የለም", om: "Gurgurtaan hin jiru"},
+	"zreport.transactions": {en: "Transactions", am: "ግብይቶች", om: "Daldala"},
+	"zreport.foreign_cash": {en: "Foreign cash", am: "የውጭ ምንዛሪ", om: "Maallaqa alaa"},
+	"zreport.booked":       {en: "Booked", am: "የተመዘገበ", om: "Galmeeffame"},
+	"zreport.change_given": {en: "Change given", am: "የተሰጠ መልስ", om: "Deebii kenname"},
+	"zreport.rounding":     {en: "Cash rounding", am: "የጥሬ ገንዘብ ማጠጋጋት", om: "Geggeessa maallaqa callaa"},
+	"zreport.printed":      {en: "Printed", am: "የታተመ", om: "Maxxanfame"},
+
+	// Payment methods; without an entry the method's name is shown
+	"payment.cash":           {am: "ጥሬ ገንዘብ", om: "Maallaqa callaa"},
+	"payment.card":           {am: "ካርድ", om: "Kaardii"},
+	"payment.mobile":         {am: "ሞባይል", om: "Moobaayilii"},
+	"payment.bank":           {am: "ባንክ", om: "Baankii"},
+	"payment.credit":         {am: "ዱቤ", om: "Liqii"},
+	"payment.other":          {am: "ሌላ", om: "Kan biraa"},
+	"payment.gift_card":      {am: "የስጦታ ካርድ", om: "Kaardii kennaa"},
+	"payment.loyalty_points": {am: "የታማኝነት ነጥቦች", om: "Qabxii amanamummaa"},
+	"payment.split":          {am: "የተከፋፈለ", om: "Qoodame"},
+
+	// CSV export headers
+	"export.id":             {en: "ID", am: "መለያ", om: "Eenyummaa"},
+	"export.date":           {en: "Date", am: "ቀን", om: "Guyyaa"},
+	"export.customer":       {en: "Customer", am: "ደንበኛ", om: "Maamila"},
+	"export.phone":          {en: "Phone", am: "ስልክ", om: "Bilbila"},
+	"export.product":        {en: "Product", am: "ምርት", om: "Oomisha"},
+	"export.quantity":       {en: "Quantity", am: "ብዛት", om: "Baay'ina"},
+	"export.unit_price":     {en: "Unit Price", am: "የአንዱ ዋጋ", om: "Gatii tokkoo"},
+	"export.total":          {en: "Total", am: "ድምር", om: "Waliigala"},
+	"export.discount":       {en: "Discount", am: "ቅናሽ", om: "Hir'isa"},
+	"export.tax":            {en: "Tax", am: "ግብር", om: "Gibira"},
+	"export.final_amount":   {en: "Final Amount", am: "የመጨረሻ መጠን", om: "Hanga dhumaa"},
+	"export.payment_method": {en: "Payment Method", am: "የክፍያ ዘዴ", om: "Mala kaffaltii"},
+	"export.payment_status": {en: "Payment Status", am: "የክፍያ ሁኔታ", om: "Haala kaffaltii"},
+	"export.notes":          {en: "Notes", am: "ማስታወሻ", om: "Yaadannoo"},
+	"export.category":       {en: "Category", am: "ምድብ", om: "Ramaddii"},
+	"export.amount":         {en: "Amount", am: "መጠን", om: "Hanga"},
+	"export.description":    {en: "Description", am: "መግለጫ", om: "Ibsa"},
+	"export.name":           {en: "Name", am: "ስም", om: "Maqaa"},
+	"export.sku":            {en: "SKU", am: "SKU", om: "SKU"},
+	"export.barcode":        {en: "Barcode", am: "ባርኮድ", om: "Baarkoodii"},
+	"export.unit":           {en: "Unit", am: "መለኪያ", om: "Safartuu"},
+	"export.cost_price":     {en: "Cost Price", am: "የግዢ ዋጋ", om: "Gatii bittaa"},
+	"export.selling_price":  {en: "Selling Price", am: "የሽያጭ ዋጋ", om: "Gatii gurgurtaa"},
+	"export.stock":          {en: "Stock", am: "ክምችት", om: "Kuusaa"},
+	"export.min_stock":      {en: "Min Stock", am: "ዝቅተኛ ክምችት", om: "Kuusaa xiqqaa"},
+	"export.max_stock":      {en: "Max Stock", am: "ከፍተኛ ክምችት", om: "Kuusaa guddaa"},
+	"export.status":         {en: "Status", am: "ሁኔታ", om: "Haala"},
+	"export.product_id":     {en: "Product ID", am: "የምርት መለያ", om: "Eenyummaa oomishaa"},
+	"export.tied_up":        {en: "Tied-up Capital", am: "የታሰረ ካፒታል", om: "Kaappitaala hidhame"},
+	"export.retail_value":   {en: "Retail Value", am: "የችርቻሮ ዋጋ", om: "Gatii gurgurtaa xixiqqaa"},
+	"export.last_sold":      {en: "Last Sold", am: "መጨረሻ የተሸጠበት", om: "Yeroo dhumaa gurgurame"},
+	"export.days_since":     {en: "Days Since Sale", am: "ከሽያጭ በኋላ ያሉ ቀናት", om: "Guyyoota gurgurtaa booda"},
+	"export.sold_in_window": {en: "Sold In Window", am: "በጊዜው የተሸጠ", om: "Yeroo sanatti kan gurgurame"},
+	"export.days_of_cover":  {en: "Days Of Cover", am: "የሚበቃባቸው ቀናት", om: "Guyyoota ga'u"},
+	"export.never":          {en: "never", am: "በጭራሽ", om: "gonkumaa"},
+
+	// Notifications
+	"sms.receipt": {
+		en: "%s: Thank you for your purchase. Receipt %s, total %s %.2f, %s.",
+		am: "%s፦ ስለገዙ እናመሰግናለን። ደረሰኝ %s፣ ድምር %s %.2f፣ %s።",
+		om: "%s: Waan bitattaniif galatoomaa. Nagahee %s, waliigala %s %.2f, %s.",
+	},
+	"sms.backorder_ready": {
+		en: "%s: Good news, your order of %g x %s is ready for pickup. Ref %s.",
+		am: "%s፦ ያዘዙት %g x %s ለመውሰድ ዝግጁ ነው። መለያ %s።",
+		om: "%s: Ajajni keessan %g x %s fudhachuuf qophaa'eera. Lakk. %s.",
+	},
+	"sms.appointment_reminder": {
+		en: "%s: Reminder of your %s appointment on %s. Reply or call us to change it.",
+		am: "%s፦ የ%s ቀጠሮዎ %s ላይ መሆኑን እናስታውሳለን። ለመቀየር ይደውሉልን።",
+		om: "%s: Beellama %s keessan %s irratti akka ta'e isin yaadachiisna. Jijjiiruuf nu bilbilaa.",
+	},
+	"sms.repair_ready": {
+		en: "%s: Your %s is repaired and ready for pickup. Job %s, amount due %s.",
+		am: "%s፦ %s ተጠግኖ ለመውሰድ ዝግጁ ነው። ስራ %s፣ የሚከፈል %s።",
+		om: "%s: %s keessan suphamee fudhachuuf qophaa'eera. Hojii %s, kaffaltii %s.",
+	},
+	"push.big_sale": {
+		en: "Big sale at %s",
+		am: "በ%s ትልቅ ሽያጭ",
+		om: "Gurgurtaa guddaa %s irratti",
+	},
+	"push.big_sale_customer": {
+		en: "%s to %s",
+		am: "%s ለ%s",
+		om: "%s, %s",
+	},
+	"push.sync_problem": {
+		en: "Sync problem at %s",
+		am: "በ%s የማመሳሰል ችግር",
+		om: "Rakkoo walsimsiisuu %s irratti",
+	},
+	"push.sync_problem_body": {
+		en: "%d of %d records from a device could not be saved",
+		am: "ከአንድ መሣሪያ ከመጡ %[2]d መዝገቦች %[1]d ሊቀመጡ አልቻሉም",
+		om: "Galmeewwan meeshaa tokko irraa dhufan %[2]d keessaa %[1]d olkaa'amuu hin dandeenye",
+	},
+	"push.daily_summary": {
+		en: "%s today",
+		am: "%s ዛሬ",
+		om: "%s har'a",
+	},
+	"push.daily_summary_body": {
+		en: "Sales %s (%d), expenses %s, profit %s",
+		am: "ሽያጭ %s (%d)፣ ወጪ %s፣ ትርፍ %s",
+		om: "Gurgurtaa %s (%d), baasii %s, bu'aa %s",
+	},
+	"push.maintenance": {
+		en: "ShopOps is under maintenance",
+		am: "ShopOps በጥገና ላይ ነው",
+		om: "ShopOps suphaa irra jira",
+	},
+	"push.maintenance_until": {
+		en: "You can still look things up, but changes are paused until about %s",
+		am: "መረጃ ማየት ይችላሉ፤ ለውጦች ግን እስከ %s አካባቢ ቆመዋል",
+		om: "Odeeffannoo ilaaluu ni dandeessu, jijjiiramni garuu hanga %s tti dhaabbateera",
+	},
+	"push.maintenance_open": {
+		en: "You can still look things up, but changes are paused for now",
+		am: "መረጃ ማየት ይችላሉ፤ ለውጦች ግን ለጊዜው ቆመዋል",
+		om: "Odeeffannoo ilaaluu ni dandeessu, jijjiiramni garuu yeroof dhaabbateera",
+	},
+	"push.maintenance_over": {
+		en: "ShopOps maintenance is over",
+		am: "የShopOps ጥገና አልቋል",
+		om: "Suphaan ShopOps xumurameera",
+	},
+	"push.maintenance_over_body": {
+		en: "Changes are back on; your devices will sync what they held back",
+		am: "ለውጦች ተመልሰዋል፤ መሣሪያዎችዎ ያቆዩትን ያመሳስላሉ",
+		om: "Jijjiiramni deebi'eera; meeshaaleen keessan kan qabatan ni walsimsiisu",
+	},
+	"notify.backup_failed": {
+		en: "Backup failed for %s",
+		am: "የ%s ምትኬ አልተሳካም",
+		om: "Kuusaan deeggarsaa %s hin milkoofne",
+	},
+	"notify.backup_failed_body": {
+		en: "The backup gave up after %d attempts: %s",
+		am: "ምትኬው ከ%d ሙከራዎች በኋላ ቆሟል፦ %s",
+		om: "Kuusaan deeggarsaa yaalii %d booda dhaabbateera: %s",
+	},
+	"notify.low_stock": {
+		en: "Low stock at %s",
+		am: "በ%s ዝቅተኛ ክምችት",
+		om: "Kuusaa gadi aanaa %s irratti",
+	},
+	"notify.low_stock_body": {
+		en: "%s is down to %g (minimum %g)",
+		am: "%s ወደ %g ወርዷል (ዝቅተኛ %g)",
+		om: "%s gara %g gadi bu'eera (xiqqaate %g)",
+	},
+	"notify.alert": {
+		en: "Alert at %s",
+		am: "በ%s ማስጠንቀቂያ",
+		om: "Akeekkachiisa %s irratti",
+	},
+	"alert.revenue_drop": {
+		en: "Sales so far today are %.0f%% below a usual %s by %s (%s %.2f against %.2f).",
+		am: "የዛሬ ሽያጭ እስከ %[3]s ድረስ ከተለመደው %[2]s በ%.0[1]f%% ያነሰ ነው (%[4]s %.2[5]f ከ%.2[6]f ጋር ሲነጻጸር)።",
+		om: "Gurgurtaan har'aa hanga %[3]s tti %[2]s baramaa irra %.0[1]f%% gadi (%[4]s %.2[5]f fi %.2[6]f).",
+	},
+	"alert.refund_spike": {
+		en: "%d sales refunded today (%s %.2f), against a usual %.1f a day.",
+		am: "ዛሬ %d ሽያጮች ተመላሽ ተደርገዋል (%s %.2f)፤ በቀን የተለመደው %.1f ነው።",
+		om: "Har'a gurgurtaan %d deebi'eera (%s %.2f); guyyaatti kan baramaa %.1f dha.",
+	},
+	"alert.idle_till": {
+		en: "Till %s is open with no sales since %s (%.1f hours).",
+		am: "ካዝና %s ክፍት ነው፤ ከ%s ጀምሮ ሽያጭ የለም (%.1f ሰዓት)።",
+		om: "Qarshii kuusaan %s banaa dha; %s irraa eegalee gurgurtaan hin jiru (sa'aatii %.1f).",
+	},
+
+	// Weekdays, Sunday first as in time.Weekday
+	"weekday.0": {en: "Sunday", am: "እሑድ", om: "Dilbata"},
+	"weekday.1": {en: "Monday", am: "ሰኞ", om: "Wiixata"},
+	"weekday.2": {en: "Tuesday", am: "ማክሰኞ", om: "Kibxata"},
+	"weekday.3": {en: "Wednesday", am: "ረቡዕ", om: "Roobii"},
+	"weekday.4": {en: "Thursday", am: "ሐሙስ", om: "Kamiisa"},
+	"weekday.5": {en: "Friday", am: "ዓርብ", om: "Jimaata"},
+	"weekday.6": {en: "Saturday", am: "ቅዳሜ", om: "Sanbata"},
+}
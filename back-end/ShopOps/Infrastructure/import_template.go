@@ -0,0 +1,272 @@
+package Infrastructure
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/csv"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+
+	Domain "ShopOps/Domain"
+)
+
+const XLSXContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+
+// templateRows is how far down an XLSX template's dropdowns and number checks reach
+const templateRows = 1000
+
+// WriteTemplateCSV writes the template's header and sample rows
+func WriteTemplateCSV(template Domain.ImportTemplate) ([]byte, error) {
+	var buf bytes.Buffer
+	// Byte order mark, so Excel opens Amharic text as UTF-8
+	buf.WriteString("\ufeff")
+
+	writer := csv.NewWriter(&buf)
+	header := make([]string, len(template.Columns))
+	for i, col := range template.Columns {
+		header[i] = col.Header
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write template header: %w", err)
+	}
+	for _, sample := range template.Samples {
+		if err := writer.Write(sample); err != nil {
+			return nil, fmt.Errorf("failed to write template row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to flush template: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// WriteTemplateXLSX writes the template as a workbook: the import sheet with
+// dropdowns on columns with options and checks on number columns, an
+// instructions sheet describing each column, and a hidden sheet holding the
+// dropdown values
+func WriteTemplateXLSX(template Domain.ImportTemplate) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+
+	files := []struct {
+		name string
+		body string
+	}{
+		{"[Content_Types].xml", xlsxContentTypes},
+		{"_rels/.rels", xlsxRootRels},
+		{"xl/workbook.xml", fmt.Sprintf(xlsxWorkbook, xmlEscape(string(template.Entity)))},
+		{"xl/_rels/workbook.xml.rels", xlsxWorkbookRels},
+		{"xl/styles.xml", xlsxStyles},
+		{"xl/worksheets/sheet1.xml", templateSheet(template)},
+		{"xl/worksheets/sheet2.xml", instructionsSheet(template)},
+		{"xl/worksheets/sheet3.xml", listsSheet(template)},
+	}
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+		if _, err := w.Write([]byte(xml.Header + f.body)); err != nil {
+			return nil, fmt.Errorf("failed to write %s: %w", f.name, err)
+		}
+	}
+
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to write workbook: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func templateSheet(template Domain.ImportTemplate) string {
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	// Header row stays in view while scrolling
+	b.WriteString(`<sheetViews><sheetView workbookViewId="0"><pane ySplit="1" topLeftCell="A2" activePane="bottomLeft" state="frozen"/></sheetView></sheetViews>`)
+	b.WriteString(`<cols>`)
+	for i, col := range template.Columns {
+		width := len(col.Header) + 4
+		if width < 12 {
+			width = 12
+		}
+		fmt.Fprintf(&b, `<col min="%d" max="%d" width="%d" customWidth="1"/>`, i+1, i+1, width)
+	}
+	b.WriteString(`</cols><sheetData>`)
+
+	header := make([]string, len(template.Columns))
+	for i, col := range template.Columns {
+		header[i] = col.Header
+	}
+	writeRow(&b, 1, header, nil, 1)
+	for i, sample := range template.Samples {
+		writeRow(&b, i+2, sample, template.Columns, 0)
+	}
+	b.WriteString(`</sheetData>`)
+
+	var validations []string
+	listColumn := 0
+	for i, col := range template.Columns {
+		ref := fmt.Sprintf("%s2:%s%d", columnName(i), columnName(i), templateRows+1)
+		switch {
+		case len(col.Options) > 0:
+			errorStyle := "stop"
+			if col.Open {
+				errorStyle = "warning"
+			}
+			validations = append(validations, fmt.Sprintf(
+				`<dataValidation type="list" errorStyle="%s" allowBlank="1" showErrorMessage="1" errorTitle="%s" error="Pick one of the listed values" sqref="%s"><formula1>Lists!$%s$1:$%s$%d</formula1></dataValidation>`,
+				errorStyle, xmlEscape(col.Header), ref, columnName(listColumn), columnName(listColumn), len(col.Options)))
+			listColumn++
+		case col.Type == Domain.ImportColumnNumber:
+			validations = append(validations, fmt.Sprintf(
+				`<dataValidation type="decimal" operator="greaterThanOrEqual" allowBlank="1" showErrorMessage="1" errorTitle="%s" error="Enter a number, 0 or more" sqref="%s"><formula1>0</formula1></dataValidation>`,
+				xmlEscape(col.Header), ref))
+		case col.Type == Domain.ImportColumnInteger:
+			validations = append(validations, fmt.Sprintf(
+				`<dataValidation type="whole" operator="greaterThanOrEqual" allowBlank="1" showErrorMessage="1" errorTitle="%s" error="Enter a whole number, 0 or more" sqref="%s"><formula1>0</formula1></dataValidation>`,
+				xmlEscape(col.Header), ref))
+		}
+	}
+	if len(validations) > 0 {
+		fmt.Fprintf(&b, `<dataValidations count="%d">%s</dataValidations>`, len(validations), strings.Join(validations, ""))
+	}
+
+	b.WriteString(`</worksheet>`)
+	return b.String()
+}
+
+func instructionsSheet(template Domain.ImportTemplate) string {
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">`)
+	b.WriteString(`<cols><col min="1" max="1" width="22" customWidth="1"/><col min="2" max="3" width="10" customWidth="1"/><col min="4" max="5" width="50" customWidth="1"/></cols><sheetData>`)
+	writeRow(&b, 1, []string{"Column", "Required", "Type", "Allowed values", "Notes"}, nil, 1)
+	for i, col := range template.Columns {
+		required := "no"
+		if col.Required {
+			required = "yes"
+		}
+		allowed := strings.Join(col.Options, ", ")
+		if col.Open && allowed != "" {
+			allowed += ", or another value"
+		}
+		writeRow(&b, i+2, []string{col.Header, required, string(col.Type), allowed, col.Note}, nil, 0)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// listsSheet puts each column's options down a column of their own, for the
+// dropdowns to refer to; a list written into the validation itself is capped
+// at 255 characters
+func listsSheet(template Domain.ImportTemplate) string {
+	var lists [][]string
+	longest := 0
+	for _, col := range template.Columns {
+		if len(col.Options) > 0 {
+			lists = append(lists, col.Options)
+			if len(col.Options) > longest {
+				longest = len(col.Options)
+			}
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString(`<worksheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main"><sheetData>`)
+	for r := 0; r < longest; r++ {
+		fmt.Fprintf(&b, `<row r="%d">`, r+1)
+		for c, list := range lists {
+			if r < len(list) {
+				writeCell(&b, fmt.Sprintf("%s%d", columnName(c), r+1), list[r], false, 0)
+			}
+		}
+		b.WriteString(`</row>`)
+	}
+	b.WriteString(`</sheetData></worksheet>`)
+	return b.String()
+}
+
+// writeRow writes values as text, or as numbers in the number columns of cols
+func writeRow(b *strings.Builder, r int, values []string, cols []Domain.ImportColumn, style int) {
+	fmt.Fprintf(b, `<row r="%d">`, r)
+	for i, value := range values {
+		if value == "" {
+			continue
+		}
+		numeric := false
+		if i < len(cols) && (cols[i].Type == Domain.ImportColumnNumber || cols[i].Type == Domain.ImportColumnInteger) {
+			_, err := strconv.ParseFloat(value, 64)
+			numeric = err == nil
+		}
+		writeCell(b, fmt.Sprintf("%s%d", columnName(i), r), value, numeric, style)
+	}
+	b.WriteString(`</row>`)
+}
+
+func writeCell(b *strings.Builder, ref, value string, numeric bool, style int) {
+	styleAttr := ""
+	if style > 0 {
+		styleAttr = fmt.Sprintf(` s="%d"`, style)
+	}
+	if numeric {
+		fmt.Fprintf(b, `<c r="%s"%s><v>%s</v></c>`, ref, styleAttr, value)
+		return
+	}
+	fmt.Fprintf(b, `<c r="%s"%s t="inlineStr"><is><t xml:space="preserve">%s</t></is></c>`, ref, styleAttr, xmlEscape(value))
+}
+
+// columnName is the spreadsheet letter of the 0-based column i: A, B, ... Z, AA
+func columnName(i int) string {
+	name := ""
+	for i++; i > 0; i = (i - 1) / 26 {
+		name = string(rune('A'+(i-1)%26)) + name
+	}
+	return name
+}
+
+func xmlEscape(s string) string {
+	var b strings.Builder
+	xml.EscapeText(&b, []byte(s))
+	return b.String()
+}
+
+const xlsxContentTypes = `<Types xmlns="http://schemas.openxmlformats.org/package/2006/content-types">` +
+	`<Default Extension="rels" ContentType="application/vnd.openxmlformats-package.relationships+xml"/>` +
+	`<Default Extension="xml" ContentType="application/xml"/>` +
+	`<Override PartName="/xl/workbook.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.sheet.main+xml"/>` +
+	`<Override PartName="/xl/styles.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.styles+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet1.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet2.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`<Override PartName="/xl/worksheets/sheet3.xml" ContentType="application/vnd.openxmlformats-officedocument.spreadsheetml.worksheet+xml"/>` +
+	`</Types>`
+
+const xlsxRootRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/officeDocument" Target="xl/workbook.xml"/>` +
+	`</Relationships>`
+
+const xlsxWorkbook = `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships">` +
+	`<sheets>` +
+	`<sheet name="%s" sheetId="1" r:id="rId1"/>` +
+	`<sheet name="Instructions" sheetId="2" r:id="rId2"/>` +
+	`<sheet name="Lists" sheetId="3" state="hidden" r:id="rId3"/>` +
+	`</sheets></workbook>`
+
+const xlsxWorkbookRels = `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships">` +
+	`<Relationship Id="rId1" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet1.xml"/>` +
+	`<Relationship Id="rId2" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet2.xml"/>` +
+	`<Relationship Id="rId3" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/worksheet" Target="worksheets/sheet3.xml"/>` +
+	`<Relationship Id="rId4" Type="http://schemas.openxmlformats.org/officeDocument/2006/relationships/styles" Target="styles.xml"/>` +
+	`</Relationships>`
+
+// Style 1 is the bold header
+const xlsxStyles = `<styleSheet xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main">` +
+	`<fonts count="2"><font><sz val="11"/><name val="Calibri"/></font><font><b/><sz val="11"/><name val="Calibri"/></font></fonts>` +
+	`<fills count="2"><fill><patternFill patternType="none"/></fill><fill><patternFill patternType="gray125"/></fill></fills>` +
+	`<borders count="1"><border><left/><right/><top/><bottom/><diagonal/></border></borders>` +
+	`<cellStyleXfs count="1"><xf numFmtId="0" fontId="0" fillId="0" borderId="0"/></cellStyleXfs>` +
+	`<cellXfs count="2"><xf numFmtId="0" fontId="0" fillId="0" borderId="0" xfId="0"/><xf numFmtId="0" fontId="1" fillId="0" borderId="0" xfId="0" applyFont="1"/></cellXfs>` +
+	`</styleSheet>`
@@ -0,0 +1,267 @@
+package Infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// JobHandler runs one job. Its context is cancelled when shutdown begins; a handler
+// that stops early should save its progress and return the context's error, and the
+// job goes back on the queue without using up an attempt.
+type JobHandler func(ctx context.Context, job *Domain.Job) error
+
+// JobQueue runs work persisted in the jobs collection, so it survives restarts and
+// is shared by every instance. Failed jobs are retried with exponential backoff and
+// dead-lettered once out of attempts.
+type JobQueue interface {
+	// Register sets the handler for jobType and how many of its jobs this instance
+	// runs at once. Call before Start.
+	Register(jobType Domain.JobType, concurrency int, handler JobHandler)
+	Start()
+	Enqueue(ctx context.Context, jobType Domain.JobType, businessID string, payload map[string]string) (*Domain.Job, error)
+	// Check fails when due jobs have waited too long, meaning workers are stuck or down
+	Check(ctx context.Context) error
+}
+
+const (
+	jobLease          = 2 * time.Minute // Renewed by heartbeat while the handler runs
+	jobPollInterval   = 2 * time.Second
+	jobMaxAttempts    = 5
+	jobBackoffBase    = 30 * time.Second
+	jobBackoffMax     = time.Hour
+	jobBacklogTimeout = 15 * time.Minute
+)
+
+type jobWorker struct {
+	concurrency int
+	handler     JobHandler
+	wake        chan struct{}
+}
+
+type jobQueue struct {
+	repo     Domain.JobRepository
+	workerID string
+
+	mu      sync.Mutex
+	workers map[Domain.JobType]*jobWorker
+}
+
+func NewJobQueue(repo Domain.JobRepository) JobQueue {
+	host, _ := os.Hostname()
+	return &jobQueue{
+		repo:     repo,
+		workerID: fmt.Sprintf("%s-%d-%s", host, os.Getpid(), NewRequestID()[:8]),
+		workers:  make(map[Domain.JobType]*jobWorker),
+	}
+}
+
+func (q *jobQueue) Register(jobType Domain.JobType, concurrency int, handler JobHandler) {
+	if concurrency < 1 {
+		concurrency = 1
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.workers[jobType] = &jobWorker{concurrency: concurrency, handler: handler, wake: make(chan struct{}, 1)}
+}
+
+func (q *jobQueue) Start() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for jobType, worker := range q.workers {
+		for i := 0; i < worker.concurrency; i++ {
+			go q.work(jobType, worker, fmt.Sprintf("%s/%s/%d", q.workerID, jobType, i))
+		}
+	}
+}
+
+func (q *jobQueue) Enqueue(ctx context.Context, jobType Domain.JobType, businessID string, payload map[string]string) (*Domain.Job, error) {
+	job := &Domain.Job{
+		Type:        jobType,
+		Payload:     payload,
+		Status:      Domain.JobStatusQueued,
+		MaxAttempts: jobMaxAttempts,
+	}
+	if businessID != "" {
+		objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		job.BusinessID = &objBusinessID
+	}
+
+	if err := q.repo.Create(job); err != nil {
+		return nil, err
+	}
+	LoggerFrom(ctx).Info("job queued", slog.String("job_id", job.ID.Hex()), slog.String("job_type", string(jobType)))
+
+	// Start it here without waiting for the next poll; other instances pick it up on theirs
+	q.mu.Lock()
+	worker := q.workers[jobType]
+	q.mu.Unlock()
+	if worker != nil {
+		select {
+		case worker.wake <- struct{}{}:
+		default:
+		}
+	}
+
+	return job, nil
+}
+
+// work claims and runs jobs of one type until shutdown begins
+func (q *jobQueue) work(jobType Domain.JobType, worker *jobWorker, workerID string) {
+	for !IsShuttingDown() {
+		job, err := q.repo.Claim(jobType, workerID, jobLease)
+		if err != nil {
+			Logger.Warn("failed to claim job", slog.String("job_type", string(jobType)), slog.String("error", err.Error()))
+		}
+		if job == nil {
+			select {
+			case <-worker.wake:
+			case <-time.After(jobPollInterval):
+			case <-ShuttingDown():
+			}
+			continue
+		}
+		q.run(job, worker.handler, workerID)
+	}
+}
+
+func (q *jobQueue) run(job *Domain.Job, handler JobHandler, workerID string) {
+	defer trackWork("queue:" + string(job.Type))()
+
+	start := time.Now()
+	logger := Logger.With(
+		slog.String("job_id", job.ID.Hex()),
+		slog.String("job_type", string(job.Type)),
+		slog.Int("attempt", job.Attempts),
+	)
+	ctx, cancel := withShutdown(WithLogger(context.Background(), logger))
+	defer cancel()
+	// A shop's job looks up that shop's records only
+	if job.BusinessID != nil {
+		ctx = WithBusiness(ctx, job.BusinessID.Hex())
+	}
+	ctx, span := StartSpan(ctx, "queue."+string(job.Type), SpanKindInternal)
+	defer span.End()
+	span.SetAttribute("job.id", job.ID.Hex())
+	span.SetAttribute("job.attempt", job.Attempts)
+
+	stopHeartbeat := q.heartbeat(job.ID.Hex(), workerID, logger)
+	err := runJobHandler(ctx, handler, job)
+	stopHeartbeat()
+
+	outcome := "success"
+	now := time.Now()
+	switch {
+	case err == nil:
+		job.Status = Domain.JobStatusSucceeded
+		job.LastError = ""
+		job.FinishedAt = &now
+	case ctx.Err() != nil && IsShuttingDown():
+		// Stopped for a deploy, not a failure: put it back without spending an attempt
+		outcome = "released"
+		job.Status = Domain.JobStatusQueued
+		job.Attempts--
+		job.RunAt = now
+	case job.Attempts >= job.MaxAttempts:
+		outcome = "dead"
+		job.Status = Domain.JobStatusDead
+		job.LastError = err.Error()
+		job.FinishedAt = &now
+	default:
+		outcome = "retry"
+		job.Status = Domain.JobStatusQueued
+		job.LastError = err.Error()
+		job.RunAt = now.Add(jobBackoff(job.Attempts))
+	}
+
+	JobDuration.ObserveSince(start, "queue:"+string(job.Type), outcome)
+	QueuedJobs.Inc(string(job.Type), outcome)
+	if err != nil {
+		span.RecordError(err)
+		level := slog.LevelWarn
+		if outcome == "dead" {
+			level = slog.LevelError
+		}
+		logger.Log(ctx, level, "job failed", slog.String("outcome", outcome), slog.String("error", err.Error()),
+			slog.Time("next_run_at", job.RunAt), slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+	} else {
+		logger.Info("job finished", slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+	}
+
+	if err := q.repo.Finish(job, workerID); err != nil {
+		logger.Error("failed to save job outcome", slog.String("error", err.Error()))
+	}
+}
+
+func runJobHandler(ctx context.Context, handler JobHandler, job *Domain.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			errorID := ReportPanic(ctx, r, map[string]string{"job_type": string(job.Type), "job_id": job.ID.Hex()}, "")
+			err = fmt.Errorf("panic, error ID %s", errorID)
+		}
+	}()
+	return handler(ctx, job)
+}
+
+// heartbeat keeps the job's lease alive until the returned func is called
+func (q *jobQueue) heartbeat(id, workerID string, logger *slog.Logger) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(jobLease / 3)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if err := q.repo.Heartbeat(id, workerID, jobLease); err != nil {
+					logger.Warn("failed to extend job lease", slog.String("error", err.Error()))
+				}
+			case <-done:
+				return
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// jobBackoff doubles the wait after each failed attempt, with jitter so jobs that
+// failed together do not all retry together
+func jobBackoff(attempts int) time.Duration {
+	backoff := jobBackoffMax
+	if attempts < 8 {
+		backoff = jobBackoffBase << (attempts - 1)
+		if backoff > jobBackoffMax {
+			backoff = jobBackoffMax
+		}
+	}
+	return backoff + time.Duration(rand.Int63n(int64(backoff/5)+1))
+}
+
+func (q *jobQueue) Check(ctx context.Context) error {
+	stats, err := q.repo.Stats()
+	if err != nil {
+		return err
+	}
+
+	var stuck []string
+	for _, stat := range stats {
+		if stat.Status == Domain.JobStatusQueued && time.Since(stat.OldestRunAt) > jobBacklogTimeout {
+			stuck = append(stuck, string(stat.Type))
+		}
+	}
+	if len(stuck) > 0 {
+		return fmt.Errorf("jobs waiting over %s: %s", jobBacklogTimeout, strings.Join(stuck, ", "))
+	}
+	return nil
+}
@@ -3,7 +3,6 @@ package Infrastructure
 import (
 	"errors"
 	"fmt"
-	"os"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -19,6 +18,12 @@ type JWTService interface {
 	ExtractPhone(token *jwt.Token) (string, error)
 	ExtractRole(token *jwt.Token) (string, error)
 	GenerateRefreshToken(userID string) (string, error)
+	// GenerateImpersonationToken signs a read-only access token for the user on
+	// behalf of a support team member, valid for ttl
+	GenerateImpersonationToken(userID, phone, role, impersonator string, ttl time.Duration) (string, time.Time, error)
+	// ExtractImpersonator returns who the token was issued to on the user's behalf,
+	// or "" for the user's own tokens
+	ExtractImpersonator(token *jwt.Token) string
 }
 
 type jwtService struct {
@@ -26,20 +31,10 @@ type jwtService struct {
 	refreshSecret string
 }
 
-func NewJWTService() JWTService {
-	secret := os.Getenv("JWT_SECRET")
-	if secret == "" {
-		secret = "shopops-secret-key-change-in-production"
-	}
-
-	refreshSecret := os.Getenv("JWT_REFRESH_SECRET")
-	if refreshSecret == "" {
-		refreshSecret = "shopops-refresh-secret-key-change-in-production"
-	}
-
+func NewJWTService(cfg AuthConfig) JWTService {
 	return &jwtService{
-		secretKey:     secret,
-		refreshSecret: refreshSecret,
+		secretKey:     cfg.JWTSecret,
+		refreshSecret: cfg.JWTRefreshSecret,
 	}
 }
 
@@ -54,9 +49,10 @@ func (s *jwtService) CheckPasswordHash(password, hash string) bool {
 }
 
 type Claims struct {
-	UserID string `json:"user_id"`
-	Phone  string `json:"phone"`
-	Role   string `json:"role"`
+	UserID       string `json:"user_id"`
+	Phone        string `json:"phone"`
+	Role         string `json:"role"`
+	Impersonator string `json:"impersonator,omitempty"`
 	jwt.RegisteredClaims
 }
 
@@ -78,6 +74,26 @@ func (s *jwtService) GenerateToken(userID, phone, role string) (string, error) {
 	return token.SignedString([]byte(s.secretKey))
 }
 
+func (s *jwtService) GenerateImpersonationToken(userID, phone, role, impersonator string, ttl time.Duration) (string, time.Time, error) {
+	expirationTime := time.Now().Add(ttl)
+
+	claims := &Claims{
+		UserID:       userID,
+		Phone:        phone,
+		Role:         role,
+		Impersonator: impersonator,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(expirationTime),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+			Issuer:    "shopops-api",
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	signed, err := token.SignedString([]byte(s.secretKey))
+	return signed, expirationTime, err
+}
+
 func (s *jwtService) GenerateRefreshToken(userID string) (string, error) {
 	expirationTime := time.Now().Add(7 * 24 * time.Hour)
 
@@ -151,3 +167,13 @@ func (s *jwtService) ExtractRole(token *jwt.Token) (string, error) {
 
 	return role, nil
 }
+
+func (s *jwtService) ExtractImpersonator(token *jwt.Token) string {
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return ""
+	}
+
+	impersonator, _ := claims["impersonator"].(string)
+	return impersonator
+}
@@ -0,0 +1,450 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"compress/zlib"
+	"fmt"
+	"math"
+	"strings"
+
+	Domain "ShopOps/Domain"
+)
+
+// LabelRenderer lays out batches of shelf labels: a product's name, price and
+// barcode on each
+type LabelRenderer interface {
+	// RenderPDF draws the labels one per page, or tiled on sheets
+	RenderPDF(data Domain.ShelfLabelData) ([]byte, error)
+	// RenderESCPOS prints the labels on a receipt or label printer, cutting
+	// between them
+	RenderESCPOS(data Domain.ShelfLabelData) ([]byte, error)
+}
+
+type labelRenderer struct{}
+
+func NewLabelRenderer() LabelRenderer {
+	return &labelRenderer{}
+}
+
+const (
+	ptPerMM = 72 / 25.4
+	// Thermal printers print 8 dots per mm and font A is 12 dots wide
+	escposDotsPerMM  = 8
+	escposCharDots   = 12
+	labelSheetMargin = 8 * ptPerMM
+	labelSheetGap    = 2 * ptPerMM
+	// Bars wider than this look odd on a small label and gain nothing at the scanner
+	maxBarcodeModule = 0.5 * ptPerMM
+	barcodeQuietZone = 10 // Modules of white either side
+)
+
+func labelSize(data Domain.ShelfLabelData) (float64, float64) {
+	width, height := data.WidthMM, data.HeightMM
+	if width <= 0 {
+		width = Domain.DefaultShelfLabelWidthMM
+	}
+	if height <= 0 {
+		height = Domain.DefaultShelfLabelHeightMM
+	}
+	return width, height
+}
+
+func (r *labelRenderer) RenderPDF(data Domain.ShelfLabelData) ([]byte, error) {
+	if len(data.Labels) == 0 {
+		return nil, fmt.Errorf("no labels to print")
+	}
+	widthMM, heightMM := labelSize(data)
+	w, h := widthMM*ptPerMM, heightMM*ptPerMM
+
+	pageW, pageH := w, h
+	switch data.Sheet {
+	case Domain.LabelSheetA4:
+		pageW, pageH = 595.28, 841.89
+	case Domain.LabelSheetLetter:
+		pageW, pageH = 612, 792
+	}
+
+	// On a sheet, labels run left to right then down from the top left margin
+	cols, rows, originX, originY := 1, 1, 0.0, 0.0
+	if data.Sheet != Domain.LabelSheetRoll {
+		cols = int((pageW - 2*labelSheetMargin + labelSheetGap) / (w + labelSheetGap))
+		rows = int((pageH - 2*labelSheetMargin + labelSheetGap) / (h + labelSheetGap))
+		if cols < 1 || rows < 1 {
+			return nil, fmt.Errorf("a %gx%g mm label does not fit on the sheet", widthMM, heightMM)
+		}
+		originX, originY = labelSheetMargin, pageH-labelSheetMargin-h
+	}
+	perPage := cols * rows
+
+	var pages [][]byte
+	for start := 0; start < len(data.Labels); start += perPage {
+		var page bytes.Buffer
+		for i, label := range data.Labels[start:min(start+perPage, len(data.Labels))] {
+			x := originX + float64(i%cols)*(w+labelSheetGap)
+			y := originY - float64(i/cols)*(h+labelSheetGap)
+			if err := drawPDFLabel(&page, label, x, y, w, h, data.Border); err != nil {
+				return nil, err
+			}
+		}
+		pages = append(pages, page.Bytes())
+	}
+
+	return writePDF(pages, pageW, pageH)
+}
+
+// drawPDFLabel draws label in the box with its bottom left corner at x, y: the
+// name at the top, the price below it in bold and the barcode filling the rest
+func drawPDFLabel(page *bytes.Buffer, label Domain.ShelfLabel, x, y, w, h float64, border bool) error {
+	if border {
+		fmt.Fprintf(page, "0.3 w %.2f %.2f %.2f %.2f re S\n", x, y, w, h)
+	}
+
+	pad := math.Min(2*ptPerMM, h*0.06)
+	innerW := w - 2*pad
+	top := y + h - pad
+
+	nameSize := clamp(h*0.11, 6, 12)
+	maxLines := 1
+	if h >= 25*ptPerMM {
+		maxLines = 2
+	}
+	for _, line := range wrapPDFText(label.Name, nameSize, innerW, maxLines) {
+		top -= nameSize
+		drawPDFText(page, "F1", nameSize, line, x+pad, top, innerW)
+		top -= nameSize * 0.15
+	}
+
+	priceSize := clamp(h*0.22, 8, 32)
+	if width := pdfTextWidth(label.Price, priceSize, true); width > innerW {
+		priceSize *= innerW / width
+	}
+	top -= priceSize * 0.85
+	drawPDFText(page, "F2", priceSize, label.Price, x+pad, top, innerW)
+	top -= priceSize*0.25 + pad/2
+
+	if label.Code == "" {
+		return nil
+	}
+	bars, text, err := barcodeModules(label.Code)
+	if err != nil {
+		return err
+	}
+	digitSize := clamp(h*0.08, 5, 8)
+	bottom := y + pad + digitSize*1.1
+	// Too little room left for bars a scanner can read; the price matters more
+	if top-bottom < 4*ptPerMM {
+		return nil
+	}
+
+	modules := len(bars) + 2*barcodeQuietZone
+	module := math.Min(innerW/float64(modules), maxBarcodeModule)
+	barX := x + (w-module*float64(len(bars)))/2
+	for i := 0; i < len(bars); {
+		if !bars[i] {
+			i++
+			continue
+		}
+		run := i
+		for run < len(bars) && bars[run] {
+			run++
+		}
+		fmt.Fprintf(page, "%.3f %.2f %.3f %.2f re f\n", barX+float64(i)*module, bottom, float64(run-i)*module, top-bottom)
+		i = run
+	}
+	drawPDFText(page, "F1", digitSize, text, x+pad, y+pad, innerW)
+	return nil
+}
+
+// drawPDFText writes text centred in the width starting at x, on the baseline y
+func drawPDFText(page *bytes.Buffer, font string, size float64, text string, x, y, width float64) {
+	offset := (width - pdfTextWidth(text, size, font == "F2")) / 2
+	fmt.Fprintf(page, "BT /%s %.2f Tf %.2f %.2f Td (%s) Tj ET\n", font, size, x+math.Max(offset, 0), y, pdfString(text))
+}
+
+// wrapPDFText breaks text into at most maxLines lines that fit the width, ending
+// the last with "..." when it is cut short
+func wrapPDFText(text string, size, width float64, maxLines int) []string {
+	words := strings.Fields(text)
+	var lines []string
+	line := ""
+	for i, word := range words {
+		candidate := strings.TrimSpace(line + " " + word)
+		if line == "" || pdfTextWidth(candidate, size, false) <= width {
+			line = candidate
+			continue
+		}
+		if len(lines) == maxLines-1 {
+			line = strings.Join(append([]string{line}, words[i:]...), " ")
+			break
+		}
+		lines = append(lines, line)
+		line = word
+	}
+	if line == "" {
+		return lines
+	}
+
+	if pdfTextWidth(line, size, false) > width {
+		runes := []rune(line)
+		for len(runes) > 0 && pdfTextWidth(string(runes)+"...", size, false) > width {
+			runes = runes[:len(runes)-1]
+		}
+		line = strings.TrimSpace(string(runes)) + "..."
+	}
+	return append(lines, line)
+}
+
+// helveticaWidths are the standard Helvetica advance widths of ' ' to '~', in
+// thousandths of the font size
+var helveticaWidths = [...]int{
+	278, 278, 355, 556, 556, 889, 667, 191, 333, 333, 389, 584, 278, 333, 278, 278,
+	556, 556, 556, 556, 556, 556, 556, 556, 556, 556, 278, 278, 584, 584, 584, 556,
+	1015, 667, 667, 722, 722, 667, 611, 778, 722, 278, 500, 667, 556, 833, 722, 778,
+	667, 778, 722, 667, 611, 722, 667, 944, 667, 667, 611, 278, 278, 278, 469, 556,
+	333, 556, 556, 500, 556, 556, 278, 556, 556, 222, 222, 500, 222, 833, 556, 556,
+	556, 556, 333, 500, 278, 556, 500, 722, 500, 500, 500, 334, 260, 334, 584,
+}
+
+func pdfTextWidth(text string, size float64, bold bool) float64 {
+	total := 0
+	for _, r := range text {
+		if r >= ' ' && r <= '~' {
+			total += helveticaWidths[r-' ']
+		} else {
+			total += 556
+		}
+	}
+	width := float64(total) * size / 1000
+	if bold {
+		// Helvetica-Bold's digits match; its letters run a little wider
+		width *= 1.05
+	}
+	return width
+}
+
+// pdfString escapes text for a PDF literal string in WinAnsiEncoding, which
+// covers Latin-1; other characters print as '?'
+func pdfString(text string) string {
+	var sb strings.Builder
+	for _, r := range text {
+		switch {
+		case r == '(' || r == ')' || r == '\\':
+			sb.WriteByte('\\')
+			sb.WriteRune(r)
+		case r >= ' ' && r <= '~':
+			sb.WriteRune(r)
+		case r >= 0xA0 && r <= 0xFF:
+			fmt.Fprintf(&sb, "\\%03o", r)
+		default:
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// writePDF assembles a PDF of pages of the same size from their content streams,
+// with Helvetica as F1 and Helvetica-Bold as F2
+func writePDF(pages [][]byte, width, height float64) ([]byte, error) {
+	var buf bytes.Buffer
+	var offsets []int
+	object := func(body string) {
+		offsets = append(offsets, buf.Len())
+		fmt.Fprintf(&buf, "%d 0 obj\n%s\nendobj\n", len(offsets), body)
+	}
+
+	buf.WriteString("%PDF-1.4\n%\xE2\xE3\xCF\xD3\n")
+
+	// Objects 1 to 4 are fixed; page i is object 5+2i and its content 6+2i
+	kids := make([]string, len(pages))
+	for i := range pages {
+		kids[i] = fmt.Sprintf("%d 0 R", 5+2*i)
+	}
+	object("<< /Type /Catalog /Pages 2 0 R >>")
+	object(fmt.Sprintf("<< /Type /Pages /Kids [%s] /Count %d >>", strings.Join(kids, " "), len(pages)))
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica /Encoding /WinAnsiEncoding >>")
+	object("<< /Type /Font /Subtype /Type1 /BaseFont /Helvetica-Bold /Encoding /WinAnsiEncoding >>")
+
+	for i, content := range pages {
+		object(fmt.Sprintf("<< /Type /Page /Parent 2 0 R /MediaBox [0 0 %.2f %.2f] /Resources << /Font << /F1 3 0 R /F2 4 0 R >> >> /Contents %d 0 R >>", width, height, 6+2*i))
+
+		var stream bytes.Buffer
+		zw := zlib.NewWriter(&stream)
+		if _, err := zw.Write(content); err != nil {
+			return nil, fmt.Errorf("failed to compress label page: %w", err)
+		}
+		if err := zw.Close(); err != nil {
+			return nil, fmt.Errorf("failed to compress label page: %w", err)
+		}
+		object(fmt.Sprintf("<< /Length %d /Filter /FlateDecode >>\nstream\n%s\nendstream", stream.Len(), stream.Bytes()))
+	}
+
+	xref := buf.Len()
+	fmt.Fprintf(&buf, "xref\n0 %d\n0000000000 65535 f \n", len(offsets)+1)
+	for _, offset := range offsets {
+		fmt.Fprintf(&buf, "%010d 00000 n \n", offset)
+	}
+	fmt.Fprintf(&buf, "trailer\n<< /Size %d /Root 1 0 R >>\nstartxref\n%d\n%%%%EOF\n", len(offsets)+1, xref)
+
+	return buf.Bytes(), nil
+}
+
+func (r *labelRenderer) RenderESCPOS(data Domain.ShelfLabelData) ([]byte, error) {
+	if len(data.Labels) == 0 {
+		return nil, fmt.Errorf("no labels to print")
+	}
+	widthMM, heightMM := labelSize(data)
+	cols := int(widthMM * escposDotsPerMM / escposCharDots)
+	// The bars take about half the label, within what GS h allows
+	barHeight := byte(clamp(heightMM*escposDotsPerMM*0.45, 24, 255))
+
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	for _, label := range data.Labels {
+		buf.Write(escAlignCenter)
+		buf.Write(escBoldOn)
+		name := escposText(label.Name)
+		if len(name) > cols {
+			name = name[:cols]
+		}
+		buf.WriteString(name)
+		buf.WriteByte('\n')
+		buf.Write(escBoldOff)
+		price := escposText(label.Price)
+		// Double width needs twice the columns
+		if len(price)*2 <= cols {
+			buf.Write(escDoubleSize)
+		}
+		buf.WriteString(price)
+		buf.WriteByte('\n')
+		buf.Write(escNormalSize)
+
+		if label.Code != "" {
+			barcode, err := escposBarcode(label.Code)
+			if err != nil {
+				return nil, err
+			}
+			bars, _, err := barcodeModules(label.Code)
+			if err != nil {
+				return nil, err
+			}
+			module := byte(clamp(math.Floor(widthMM*escposDotsPerMM/float64(len(bars)+2*barcodeQuietZone)), 2, 6))
+			buf.Write([]byte{0x1D, 0x68, barHeight, 0x1D, 0x77, module})
+			buf.Write(escBarcodeHRI)
+			buf.Write(barcode)
+		}
+		buf.Write(escFeedAndCut)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func clamp(v, lo, hi float64) float64 {
+	return math.Max(lo, math.Min(hi, v))
+}
+
+// barcodeModules encodes code as a printer would in escposBarcode, EAN-13 for 12
+// or 13 digits and Code 128 otherwise, as a run of modules that are true for bar.
+// It also returns the text to print beneath, EAN-13 with its check digit.
+func barcodeModules(code string) ([]bool, string, error) {
+	for _, r := range code {
+		if r < 0x20 || r >= 0x7F {
+			return nil, "", fmt.Errorf("barcode %q has characters a printer cannot encode", code)
+		}
+	}
+	if (len(code) == 12 || len(code) == 13) && strings.Trim(code, "0123456789") == "" {
+		return ean13Modules(code)
+	}
+	return code128Modules(code), code, nil
+}
+
+var (
+	eanL = [10]string{"0001101", "0011001", "0010011", "0111101", "0100011", "0110001", "0101111", "0111011", "0110111", "0001011"}
+	eanG = [10]string{"0100111", "0110011", "0011011", "0100001", "0011101", "0111001", "0000101", "0010001", "0001001", "0010111"}
+	eanR = [10]string{"1110010", "1100110", "1101100", "1000010", "1011100", "1001110", "1010000", "1000100", "1001000", "1110100"}
+	// eanParity is which of the left digits use the G codes, by the first digit
+	eanParity = [10]string{"LLLLLL", "LLGLGG", "LLGGLG", "LLGGGL", "LGLLGG", "LGGLLG", "LGGGLL", "LGLGLG", "LGLGGL", "LGGLGL"}
+)
+
+func ean13Modules(code string) ([]bool, string, error) {
+	if len(code) == 12 {
+		sum := 0
+		for i, r := range code {
+			digit := int(r - '0')
+			if i%2 == 1 {
+				digit *= 3
+			}
+			sum += digit
+		}
+		code += string(rune('0' + (10-sum%10)%10))
+	}
+
+	pattern := "101"
+	parity := eanParity[code[0]-'0']
+	for i := 1; i <= 6; i++ {
+		if parity[i-1] == 'G' {
+			pattern += eanG[code[i]-'0']
+		} else {
+			pattern += eanL[code[i]-'0']
+		}
+	}
+	pattern += "01010"
+	for i := 7; i <= 12; i++ {
+		pattern += eanR[code[i]-'0']
+	}
+	pattern += "101"
+
+	bars := make([]bool, len(pattern))
+	for i := range pattern {
+		bars[i] = pattern[i] == '1'
+	}
+	return bars, code, nil
+}
+
+// code128Patterns are the bar and space widths of each Code 128 symbol value;
+// 103 to 105 start code sets A to C and 106 is the stop
+var code128Patterns = [...]string{
+	"212222", "222122", "222221", "121223", "121322", "131222", "122213", "122312", "132212", "221213",
+	"221312", "231212", "112232", "122132", "122231", "113222", "123122", "123221", "223211", "221132",
+	"221231", "213212", "223112", "312131", "311222", "321122", "321221", "312212", "322112", "322211",
+	"212123", "212321", "232121", "111323", "131123", "131321", "112313", "132113", "132311", "211313",
+	"231113", "231311", "112133", "112331", "132131", "113123", "113321", "133121", "313121", "211331",
+	"231131", "213113", "213311", "213131", "311123", "311321", "331121", "312113", "312311", "332111",
+	"314111", "221411", "431111", "111224", "111422", "121124", "121421", "141122", "141221", "112214",
+	"112412", "122114", "122411", "142112", "142211", "241211", "221114", "413111", "241112", "134111",
+	"111242", "121142", "121241", "114212", "124112", "124211", "411212", "421112", "421211", "212141",
+	"214121", "412121", "111143", "111341", "131141", "114113", "114311", "411113", "411311", "113141",
+	"114131", "311141", "411131", "211412", "211214", "211232", "2331112",
+}
+
+// code128Modules encodes all-digit codes of even length in code set C, two digits
+// to a symbol, and anything else in code set B
+func code128Modules(code string) []bool {
+	var values []int
+	if len(code) >= 4 && len(code)%2 == 0 && strings.Trim(code, "0123456789") == "" {
+		values = append(values, 105)
+		for i := 0; i < len(code); i += 2 {
+			values = append(values, int(code[i]-'0')*10+int(code[i+1]-'0'))
+		}
+	} else {
+		values = append(values, 104)
+		for i := 0; i < len(code); i++ {
+			values = append(values, int(code[i])-32)
+		}
+	}
+
+	checksum := values[0]
+	for i, value := range values[1:] {
+		checksum += (i + 1) * value
+	}
+	values = append(values, checksum%103, 106)
+
+	var bars []bool
+	for _, value := range values {
+		for i, width := range code128Patterns[value] {
+			for j := 0; j < int(width-'0'); j++ {
+				bars = append(bars, i%2 == 0)
+			}
+		}
+	}
+	return bars
+}
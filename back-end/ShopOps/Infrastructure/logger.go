@@ -0,0 +1,213 @@
+package Infrastructure
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log"
+	"log/slog"
+	"os"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the correlation ID in both directions; clients may send
+// their own so a request can be traced from the device through the server
+const RequestIDHeader = "X-Request-ID"
+
+const maxRequestIDLength = 64
+
+type loggerKey struct{}
+
+// Logger is the central structured logger. Everything is written as JSON lines to
+// stdout; the standard log package is routed through it too. It logs at info until
+// InitLogging applies the configured level.
+var Logger = newLogger("info")
+
+// InitLogging sets the level of the central logger from LOG_LEVEL. Call it once at
+// startup, before anything else logs.
+func InitLogging(cfg LoggingConfig) {
+	Logger = newLogger(cfg.Level)
+}
+
+func newLogger(levelName string) *slog.Logger {
+	level := slog.LevelInfo
+	switch strings.ToLower(levelName) {
+	case "debug":
+		level = slog.LevelDebug
+	case "warn":
+		level = slog.LevelWarn
+	case "error":
+		level = slog.LevelError
+	}
+
+	logger := slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{Level: level, ReplaceAttr: redactLogAttr}))
+	slog.SetDefault(logger)
+	log.SetFlags(0)
+	return logger
+}
+
+// redactLogAttr keeps customers' names and phone numbers out of the logs: models
+// logged whole have their tagged fields masked, values logged under a PII key are
+// masked, and error messages are scrubbed of anything that looks like a phone
+// number or email address
+func redactLogAttr(groups []string, a slog.Attr) slog.Attr {
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if kind, ok := Domain.PIIKindOfKey(a.Key); ok {
+			return slog.String(a.Key, Domain.MaskPII(kind, a.Value.String()))
+		}
+		if a.Key == "error" {
+			return slog.String(a.Key, RedactPII(a.Value.String()))
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactPII(err.Error()))
+		}
+		if Domain.HasPII(a.Value.Any()) {
+			return slog.Any(a.Key, Domain.Redacted(a.Value.Any()))
+		}
+	}
+	return a
+}
+
+// WithLogger returns a copy of ctx carrying logger
+func WithLogger(ctx context.Context, logger *slog.Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// LoggerFrom returns the logger carried by ctx, with its request or job fields, or the central logger
+func LoggerFrom(ctx context.Context) *slog.Logger {
+	if ctx != nil {
+		if logger, ok := ctx.Value(loggerKey{}).(*slog.Logger); ok {
+			return logger
+		}
+	}
+	return Logger
+}
+
+// NewRequestID returns a random 16 byte hex ID
+func NewRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return fmt.Sprintf("%x", time.Now().UnixNano())
+	}
+	return hex.EncodeToString(b)
+}
+
+// RequestLogger assigns each request an ID, keeps a logger with the request's shop,
+// user and device on the request context, and logs the request once it completes
+func RequestLogger() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		requestID := c.GetHeader(RequestIDHeader)
+		if !validRequestID(requestID) {
+			requestID = NewRequestID()
+		}
+		c.Set("requestID", requestID)
+		c.Header(RequestIDHeader, requestID)
+
+		attrs := []any{slog.String("request_id", requestID)}
+		if span := SpanFrom(c.Request.Context()); span != nil {
+			attrs = append(attrs, slog.String("trace_id", span.TraceID()))
+		}
+		if businessID := c.Param("businessId"); businessID != "" {
+			attrs = append(attrs, slog.String("business_id", businessID))
+		}
+		if deviceID := requestDeviceID(c); deviceID != "" {
+			attrs = append(attrs, slog.String("device_id", deviceID))
+		}
+		c.Request = c.Request.WithContext(WithLogger(c.Request.Context(), Logger.With(attrs...)))
+
+		c.Next()
+
+		// The user is only known once the auth middleware has run
+		logger := RequestLog(c)
+		status := c.Writer.Status()
+		recordRequestError(c, status)
+		level := slog.LevelInfo
+		if status >= 500 {
+			level = slog.LevelError
+		} else if status >= 400 {
+			level = slog.LevelWarn
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = c.Request.URL.Path
+		}
+
+		logger.LogAttrs(c.Request.Context(), level, "request",
+			slog.String("method", c.Request.Method),
+			slog.String("route", route),
+			slog.String("path", c.Request.URL.Path),
+			slog.Int("status", status),
+			slog.Int64("duration_ms", time.Since(start).Milliseconds()),
+			slog.Int("bytes", c.Writer.Size()),
+			slog.String("client_ip", c.ClientIP()),
+		)
+	}
+}
+
+// RequestLog is the logger for the request, with the user attached once authenticated
+func RequestLog(c *gin.Context) *slog.Logger {
+	logger := LoggerFrom(c.Request.Context())
+	if userID, exists := c.Get("userID"); exists {
+		logger = logger.With(slog.Any("user_id", userID))
+	}
+	return logger
+}
+
+// Go runs task in a goroutine that outlives the request but keeps its log fields,
+// so work started by a request can be traced back to it. Errors and panics are logged.
+// The task's context is cancelled when shutdown begins; long tasks should watch it,
+// save their progress and return, as shutdown waits for them up to its deadline.
+func Go(ctx context.Context, task string, fn func(ctx context.Context) error) {
+	logger := LoggerFrom(ctx).With(slog.String("task", task))
+	ctx, cancel := withShutdown(WithLogger(context.WithoutCancel(ctx), logger))
+	done := trackWork("task:" + task)
+
+	go func() {
+		defer done()
+		defer cancel()
+
+		ctx, span := StartSpan(ctx, "task."+task, SpanKindInternal)
+		defer span.End()
+		defer func() {
+			if r := recover(); r != nil {
+				errorID := ReportPanic(ctx, r, map[string]string{"task": task}, "")
+				span.RecordError(fmt.Errorf("panic, error ID %s", errorID))
+			}
+		}()
+		if err := fn(ctx); err != nil {
+			logger.Error("background task failed", slog.String("error", err.Error()))
+			span.RecordError(err)
+		}
+	}()
+}
+
+func requestDeviceID(c *gin.Context) string {
+	if deviceID := c.Query("device_id"); deviceID != "" {
+		return deviceID
+	}
+	return c.GetHeader("X-Device-ID")
+}
+
+// validRequestID accepts client IDs that are short and safe to echo into logs and headers
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9' || r == '-' || r == '_' || r == '.') {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,82 @@
+package Infrastructure
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// MaintenanceSource reports whether the API is in read-only maintenance mode
+type MaintenanceSource interface {
+	CurrentMaintenance() Domain.MaintenanceState
+}
+
+// Clients are told to retry writes after this long when no end time was given
+const defaultMaintenanceRetryAfter = 5 * time.Minute
+
+// Routes that keep accepting writes during maintenance: signing in and refreshing a
+// token, so staff can still read, the admin API that turns maintenance off again and
+// the support API. Registering is a write like any other and waits.
+// The GraphQL API has no mutations, so its POSTs are reads
+var maintenanceExemptPaths = map[string]bool{"/api/v1/auth/login": true, "/api/v1/auth/refresh": true}
+var maintenanceExemptPrefixes = []string{"/api/v1/admin/", "/internal/", "/api/v1/graphql"}
+
+// MaintenanceMiddleware refuses writes with a 503 and Retry-After while maintenance
+// mode is on. Reads, including sync downloads and reports, carry on, and every
+// response says the API is read-only so apps can show a banner and queue writes.
+func MaintenanceMiddleware(source MaintenanceSource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		state := source.CurrentMaintenance()
+		if !state.Enabled {
+			c.Next()
+			return
+		}
+
+		c.Header("X-Maintenance-Mode", "read-only")
+		if isReadOnlyMethod(c.Request.Method) || isMaintenanceExempt(c.Request.URL.Path) {
+			c.Next()
+			return
+		}
+
+		c.Header("Retry-After", strconv.Itoa(maintenanceRetryAfter(state)))
+		message := state.Message
+		if message == "" {
+			message = "ShopOps is under maintenance; changes are paused and can be retried shortly"
+		}
+		AbortWithError(c, Domain.NewAppError(Domain.ErrCodeMaintenance, message))
+	}
+}
+
+func isReadOnlyMethod(method string) bool {
+	return method == http.MethodGet || method == http.MethodHead || method == http.MethodOptions
+}
+
+func isMaintenanceExempt(path string) bool {
+	if maintenanceExemptPaths[path] {
+		return true
+	}
+	for _, prefix := range maintenanceExemptPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// maintenanceRetryAfter is the seconds until the expected end, at least a minute
+func maintenanceRetryAfter(state Domain.MaintenanceState) int {
+	wait := defaultMaintenanceRetryAfter
+	if state.EndsAt != nil {
+		wait = time.Until(*state.EndsAt)
+	}
+	if wait < time.Minute {
+		wait = time.Minute
+	}
+	return int(math.Ceil(wait.Seconds()))
+}
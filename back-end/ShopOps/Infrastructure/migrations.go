@@ -0,0 +1,289 @@
+package Infrastructure
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Schema changes (indexes, collection options, backfills) ship inside the binary as
+// numbered migrations. Each is a pair of files, NNNN_name.up.json and
+// NNNN_name.down.json, holding a JSON array of database commands in MongoDB extended
+// JSON; they run in order with RunCommand. Applied versions are recorded in
+// schema_migrations, and a lock document there keeps two instances from migrating
+// at once.
+
+//go:embed migrations/*.json
+var migrationFiles embed.FS
+
+const (
+	migrationsCollection = "schema_migrations"
+	migrationLockID      = "lock"
+	// A lock older than this is left over from a crashed run and may be taken over
+	migrationLockTTL = 10 * time.Minute
+)
+
+// Migration is one embedded schema change
+type Migration struct {
+	Version int
+	Name    string
+	up      string
+	down    string
+}
+
+// MigrationStatus reports whether a migration has been applied, and when
+type MigrationStatus struct {
+	Version   int
+	Name      string
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int       `bson:"_id"`
+	Name      string    `bson:"name"`
+	AppliedAt time.Time `bson:"applied_at"`
+}
+
+// Migrator applies and rolls back the embedded migrations
+type Migrator struct {
+	db         *mongo.Database
+	migrations []Migration
+	owner      string
+}
+
+func NewMigrator(db *mongo.Database) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles)
+	if err != nil {
+		return nil, err
+	}
+	host, _ := os.Hostname()
+	return &Migrator{
+		db:         db,
+		migrations: migrations,
+		owner:      fmt.Sprintf("%s-%d", host, os.Getpid()),
+	}, nil
+}
+
+func loadMigrations(files fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(files, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		base, direction, ok := strings.Cut(strings.TrimSuffix(fileName, ".json"), ".")
+		if !ok || (direction != "up" && direction != "down") {
+			return nil, fmt.Errorf("invalid migration file name %q", fileName)
+		}
+		versionText, name, ok := strings.Cut(base, "_")
+		version, err := strconv.Atoi(versionText)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %q", fileName)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration %d has two names: %s and %s", version, m.Name, name)
+		}
+		filePath := path.Join("migrations", fileName)
+		if direction == "up" {
+			m.up = filePath
+		} else {
+			m.down = filePath
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.up == "" {
+			return nil, fmt.Errorf("migration %04d_%s has no up file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// Up applies every pending migration in order, returning the ones it applied
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var ran []Migration
+	for _, migration := range m.migrations {
+		if _, done := applied[migration.Version]; done {
+			continue
+		}
+		if err := m.run(ctx, migration.up); err != nil {
+			return ran, fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		record := appliedMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+		if _, err := m.db.Collection(migrationsCollection).InsertOne(ctx, record); err != nil {
+			return ran, fmt.Errorf("failed to record migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		ran = append(ran, migration)
+	}
+	return ran, nil
+}
+
+// Down rolls back the latest steps applied migrations, newest first
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	unlock, err := m.lock(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer unlock()
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rolledBack []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(rolledBack) < steps; i-- {
+		migration := m.migrations[i]
+		if _, done := applied[migration.Version]; !done {
+			continue
+		}
+		if migration.down == "" {
+			return rolledBack, fmt.Errorf("migration %04d_%s cannot be rolled back", migration.Version, migration.Name)
+		}
+		if err := m.run(ctx, migration.down); err != nil {
+			return rolledBack, fmt.Errorf("failed to roll back migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		if _, err := m.db.Collection(migrationsCollection).DeleteOne(ctx, bson.M{"_id": migration.Version}); err != nil {
+			return rolledBack, fmt.Errorf("failed to unrecord migration %04d_%s: %w", migration.Version, migration.Name, err)
+		}
+		rolledBack = append(rolledBack, migration)
+	}
+	return rolledBack, nil
+}
+
+// Status lists every embedded migration and when it was applied, if it has been
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Version: migration.Version, Name: migration.Name}
+		if record, done := applied[migration.Version]; done {
+			appliedAt := record.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// Pending counts migrations that have not been applied
+func (m *Migrator) Pending(ctx context.Context) (int, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	pending := 0
+	for _, migration := range m.migrations {
+		if _, done := applied[migration.Version]; !done {
+			pending++
+		}
+	}
+	return pending, nil
+}
+
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	cursor, err := m.db.Collection(migrationsCollection).Find(ctx, bson.M{"_id": bson.M{"$type": "number"}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read applied migrations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []appliedMigration
+	if err := cursor.All(ctx, &records); err != nil {
+		return nil, fmt.Errorf("failed to decode applied migrations: %w", err)
+	}
+	applied := make(map[int]appliedMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// run executes each command in an embedded migration file in order
+func (m *Migrator) run(ctx context.Context, filePath string) error {
+	data, err := migrationFiles.ReadFile(filePath)
+	if err != nil {
+		return err
+	}
+
+	// Extended JSON only decodes into a document, so wrap the array
+	var file struct {
+		Commands []bson.D `bson:"commands"`
+	}
+	wrapped := append(append([]byte(`{"commands":`), data...), '}')
+	if err := bson.UnmarshalExtJSON(wrapped, false, &file); err != nil {
+		return fmt.Errorf("failed to parse %s: %w", filePath, err)
+	}
+
+	for i, command := range file.Commands {
+		if err := m.db.RunCommand(ctx, command).Err(); err != nil {
+			return fmt.Errorf("command %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+// lock takes the migration lock, failing if another instance holds a live one
+func (m *Migrator) lock(ctx context.Context) (func(), error) {
+	collection := m.db.Collection(migrationsCollection)
+	now := time.Now()
+
+	_, err := collection.InsertOne(ctx, bson.M{"_id": migrationLockID, "owner": m.owner, "locked_at": now})
+	if mongo.IsDuplicateKeyError(err) {
+		// Take over a lock abandoned by a crashed run
+		result, updateErr := collection.UpdateOne(ctx,
+			bson.M{"_id": migrationLockID, "locked_at": bson.M{"$lt": now.Add(-migrationLockTTL)}},
+			bson.M{"$set": bson.M{"owner": m.owner, "locked_at": now}},
+		)
+		if updateErr != nil {
+			return nil, fmt.Errorf("failed to take migration lock: %w", updateErr)
+		}
+		if result.ModifiedCount == 0 {
+			return nil, errors.New("migrations are already running in another instance")
+		}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to take migration lock: %w", err)
+	}
+
+	return func() {
+		releaseCtx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		_, _ = collection.DeleteOne(releaseCtx, bson.M{"_id": migrationLockID, "owner": m.owner})
+	}, nil
+}
@@ -0,0 +1,370 @@
+package Infrastructure
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+	"github.com/swaggo/swag"
+)
+
+// The public API is everything under /api/v1 apart from platform administration.
+// The support API and integration callbacks are left out of the published spec.
+const publicAPIPrefix = "/api/v1/"
+
+var privateAPIPrefixes = []string{"/api/v1/admin", "/api/v1/integrations/"}
+
+// OpenAPIHandler serves the public API as an OpenAPI 3 document. It is converted
+// from the Swagger 2 spec swag generates from the handlers' annotations (go generate
+// in Delivery), so the models always match the code the server was built from.
+func OpenAPIHandler() gin.HandlerFunc {
+	var (
+		once sync.Once
+		spec map[string]interface{}
+		err  error
+	)
+
+	return func(c *gin.Context) {
+		once.Do(func() {
+			spec, err = buildOpenAPI()
+		})
+		if err != nil {
+			JSONError(c, http.StatusInternalServerError, err, "API spec unavailable")
+			return
+		}
+
+		// Point integrators at the host that served the spec rather than the one
+		// the docs were generated on
+		doc := make(map[string]interface{}, len(spec)+1)
+		for key, value := range spec {
+			doc[key] = value
+		}
+		doc["servers"] = []interface{}{map[string]interface{}{"url": requestBaseURL(c)}}
+
+		c.Header("Cache-Control", "public, max-age=300")
+		c.JSON(http.StatusOK, doc)
+	}
+}
+
+func requestBaseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil {
+		scheme = "https"
+	}
+	if proto := c.GetHeader("X-Forwarded-Proto"); proto != "" {
+		scheme = strings.TrimSpace(strings.Split(proto, ",")[0])
+	}
+	return scheme + "://" + c.Request.Host
+}
+
+func buildOpenAPI() (map[string]interface{}, error) {
+	raw, err := swag.ReadDoc()
+	if err != nil {
+		return nil, err
+	}
+
+	var swagger map[string]interface{}
+	if err := json.Unmarshal([]byte(raw), &swagger); err != nil {
+		return nil, err
+	}
+	return convertSwagger(swagger), nil
+}
+
+// convertSwagger turns a Swagger 2 document into OpenAPI 3.0, keeping only the
+// public paths and the models and security schemes they use
+func convertSwagger(swagger map[string]interface{}) map[string]interface{} {
+	defaultConsumes := stringList(swagger["consumes"], "application/json")
+	defaultProduces := stringList(swagger["produces"], "application/json")
+
+	paths := map[string]interface{}{}
+	tags := map[string]bool{}
+	schemes := map[string]bool{}
+
+	for path, item := range asMap(swagger["paths"]) {
+		if !isPublicPath(path) {
+			continue
+		}
+
+		operations := map[string]interface{}{}
+		for method, value := range asMap(item) {
+			op := asMap(value)
+			if op == nil {
+				continue
+			}
+			operations[method] = convertOperation(op, defaultConsumes, defaultProduces)
+
+			for _, tag := range stringList(op["tags"]) {
+				tags[tag] = true
+			}
+			for _, requirement := range asList(op["security"]) {
+				for name := range asMap(requirement) {
+					schemes[name] = true
+				}
+			}
+		}
+		paths[path] = operations
+	}
+
+	// Only the models reachable from the public paths are published
+	definitions := asMap(swagger["definitions"])
+	schemas := map[string]interface{}{}
+	pending := collectRefs(paths, nil)
+	for len(pending) > 0 {
+		name := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if _, done := schemas[name]; done {
+			continue
+		}
+		definition, ok := definitions[name]
+		if !ok {
+			continue
+		}
+		schemas[name] = definition
+		pending = collectRefs(definition, pending)
+	}
+
+	securitySchemes := map[string]interface{}{}
+	for name, value := range asMap(swagger["securityDefinitions"]) {
+		if schemes[name] {
+			securitySchemes[name] = convertSecurityScheme(asMap(value))
+		}
+	}
+
+	tagList := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagList = append(tagList, tag)
+	}
+	sort.Strings(tagList)
+	tagObjects := make([]interface{}, len(tagList))
+	for i, tag := range tagList {
+		tagObjects[i] = map[string]interface{}{"name": tag}
+	}
+
+	doc := map[string]interface{}{
+		"openapi": "3.0.3",
+		"info":    swagger["info"],
+		"paths":   paths,
+		"tags":    tagObjects,
+		"components": map[string]interface{}{
+			"schemas":         schemas,
+			"securitySchemes": securitySchemes,
+		},
+	}
+	return rewriteRefs(doc).(map[string]interface{})
+}
+
+func isPublicPath(path string) bool {
+	if !strings.HasPrefix(path, publicAPIPrefix) {
+		return false
+	}
+	for _, prefix := range privateAPIPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return false
+		}
+	}
+	return true
+}
+
+func convertOperation(op map[string]interface{}, defaultConsumes, defaultProduces []string) map[string]interface{} {
+	out := map[string]interface{}{}
+	for _, key := range []string{"summary", "description", "tags", "operationId", "security", "deprecated"} {
+		if value, ok := op[key]; ok {
+			out[key] = value
+		}
+	}
+
+	consumes := stringList(op["consumes"], defaultConsumes...)
+	produces := stringList(op["produces"], defaultProduces...)
+
+	var parameters []interface{}
+	form := map[string]interface{}{}
+	var formRequired []interface{}
+	hasFile := false
+
+	for _, value := range asList(op["parameters"]) {
+		param := asMap(value)
+		if param == nil {
+			continue
+		}
+
+		switch param["in"] {
+		case "body":
+			content := map[string]interface{}{}
+			for _, mediaType := range consumes {
+				content[mediaType] = map[string]interface{}{"schema": param["schema"]}
+			}
+			body := map[string]interface{}{"content": content}
+			if description, ok := param["description"]; ok {
+				body["description"] = description
+			}
+			if required, ok := param["required"]; ok {
+				body["required"] = required
+			}
+			out["requestBody"] = body
+		case "formData":
+			name, _ := param["name"].(string)
+			if param["type"] == "file" {
+				hasFile = true
+			}
+			form[name] = parameterSchema(param)
+			if required, _ := param["required"].(bool); required {
+				formRequired = append(formRequired, name)
+			}
+		default:
+			converted := map[string]interface{}{"schema": parameterSchema(param)}
+			for _, key := range []string{"name", "in", "description", "required"} {
+				if value, ok := param[key]; ok {
+					converted[key] = value
+				}
+			}
+			parameters = append(parameters, converted)
+		}
+	}
+
+	if len(parameters) > 0 {
+		out["parameters"] = parameters
+	}
+	if len(form) > 0 {
+		schema := map[string]interface{}{"type": "object", "properties": form}
+		if len(formRequired) > 0 {
+			schema["required"] = formRequired
+		}
+		mediaType := "application/x-www-form-urlencoded"
+		if hasFile {
+			mediaType = "multipart/form-data"
+		}
+		out["requestBody"] = map[string]interface{}{
+			"content":  map[string]interface{}{mediaType: map[string]interface{}{"schema": schema}},
+			"required": len(formRequired) > 0,
+		}
+	}
+
+	responses := map[string]interface{}{}
+	for status, value := range asMap(op["responses"]) {
+		response := asMap(value)
+		converted := map[string]interface{}{"description": response["description"]}
+		if converted["description"] == nil || converted["description"] == "" {
+			code, _ := strconv.Atoi(status)
+			converted["description"] = http.StatusText(code)
+		}
+		if schema, ok := response["schema"]; ok {
+			content := map[string]interface{}{}
+			for _, mediaType := range produces {
+				content[mediaType] = map[string]interface{}{"schema": schema}
+			}
+			converted["content"] = content
+		}
+		if headers := asMap(response["headers"]); len(headers) > 0 {
+			convertedHeaders := map[string]interface{}{}
+			for name, header := range headers {
+				h := asMap(header)
+				convertedHeaders[name] = map[string]interface{}{
+					"description": h["description"],
+					"schema":      parameterSchema(h),
+				}
+			}
+			converted["headers"] = convertedHeaders
+		}
+		responses[status] = converted
+	}
+	out["responses"] = responses
+
+	return out
+}
+
+// parameterSchema moves a Swagger 2 parameter's type information into a schema
+func parameterSchema(param map[string]interface{}) map[string]interface{} {
+	if param["type"] == "file" {
+		return map[string]interface{}{"type": "string", "format": "binary"}
+	}
+
+	schema := map[string]interface{}{}
+	for _, key := range []string{"type", "format", "items", "enum", "default", "minimum", "maximum", "minLength", "maxLength", "pattern", "example"} {
+		if value, ok := param[key]; ok {
+			schema[key] = value
+		}
+	}
+	return schema
+}
+
+func convertSecurityScheme(scheme map[string]interface{}) map[string]interface{} {
+	switch scheme["type"] {
+	case "basic":
+		return map[string]interface{}{"type": "http", "scheme": "basic"}
+	case "apiKey":
+		// Our API keys are bearer tokens in the Authorization header
+		if name, _ := scheme["name"].(string); strings.EqualFold(name, "Authorization") {
+			return map[string]interface{}{"type": "http", "scheme": "bearer", "bearerFormat": "JWT"}
+		}
+		return map[string]interface{}{"type": "apiKey", "name": scheme["name"], "in": scheme["in"]}
+	}
+	return scheme
+}
+
+// collectRefs appends the names of the definitions value refers to
+func collectRefs(value interface{}, names []string) []string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				names = append(names, strings.TrimPrefix(ref, "#/definitions/"))
+				continue
+			}
+			names = collectRefs(child, names)
+		}
+	case []interface{}:
+		for _, child := range v {
+			names = collectRefs(child, names)
+		}
+	}
+	return names
+}
+
+func rewriteRefs(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, child := range v {
+			if ref, ok := child.(string); ok && key == "$ref" {
+				out[key] = strings.Replace(ref, "#/definitions/", "#/components/schemas/", 1)
+				continue
+			}
+			out[key] = rewriteRefs(child)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, child := range v {
+			out[i] = rewriteRefs(child)
+		}
+		return out
+	}
+	return value
+}
+
+func asMap(value interface{}) map[string]interface{} {
+	m, _ := value.(map[string]interface{})
+	return m
+}
+
+func asList(value interface{}) []interface{} {
+	l, _ := value.([]interface{})
+	return l
+}
+
+func stringList(value interface{}, fallback ...string) []string {
+	var out []string
+	for _, item := range asList(value) {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	if len(out) == 0 {
+		return fallback
+	}
+	return out
+}
@@ -0,0 +1,94 @@
+package Infrastructure
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	defaultPageLimit = 50
+	maxPageLimit     = 500
+)
+
+// ParsePage reads the sort, cursor, limit and include_total query parameters of a
+// list endpoint. Paging by cursor always has a limit; lists asked for without one
+// keep returning everything, as they did before cursors.
+func ParsePage(ctx *gin.Context, sorts Domain.ListSorts, defaultSort string) (*Domain.Page, error) {
+	limit := 0
+	if limitStr := ctx.Query("limit"); limitStr != "" {
+		if l, err := strconv.Atoi(limitStr); err == nil && l > 0 {
+			limit = l
+		}
+	}
+
+	return NewPage(sorts, ctx.DefaultQuery("sort", defaultSort), ctx.Query("cursor"), limit, ctx.Query("include_total") == "true")
+}
+
+// NewPage checks a page asked for outside a gin request, e.g. over gRPC. A page after
+// a cursor is limited to the default size when no limit is given.
+func NewPage(sorts Domain.ListSorts, key, cursor string, limit int, includeTotal bool) (*Domain.Page, error) {
+	page := &Domain.Page{
+		Key:          key,
+		Limit:        limit,
+		IncludeTotal: includeTotal,
+	}
+
+	listSort, ok := sorts[page.Key]
+	if !ok {
+		keys := make([]string, 0, len(sorts))
+		for key := range sorts {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument,
+			fmt.Sprintf("invalid sort %q; use one of %s", page.Key, strings.Join(keys, ", ")))
+	}
+	page.Sort = listSort
+
+	if cursor != "" {
+		after, err := Domain.DecodeCursor(cursor, page.Key)
+		if err != nil {
+			return nil, err
+		}
+		page.After = after
+		if page.Limit <= 0 {
+			page.Limit = defaultPageLimit
+		}
+	}
+
+	if page.Limit > maxPageLimit {
+		page.Limit = maxPageLimit
+	}
+
+	return page, nil
+}
+
+// NextCursor is the cursor for the page after this one. A page shorter than its
+// limit is the last, and gets none.
+func NextCursor(page *Domain.Page, count int, last Domain.Sortable) string {
+	if page.Limit == 0 || count < page.Limit || last == nil {
+		return ""
+	}
+	return Domain.EncodeCursor(page.Key, page.Sort, last)
+}
+
+// SetNextCursor sends the cursor for the page after this one in X-Next-Cursor, and
+// returns it. A page shorter than its limit is the last, and gets none.
+func SetNextCursor(ctx *gin.Context, page *Domain.Page, count int, last Domain.Sortable) string {
+	cursor := NextCursor(page, count, last)
+	if cursor != "" {
+		ctx.Header("X-Next-Cursor", cursor)
+	}
+	return cursor
+}
+
+// SetTotalCount sends the number of items across all pages in X-Total-Count
+func SetTotalCount(ctx *gin.Context, total int64) {
+	ctx.Header("X-Total-Count", strconv.FormatInt(total, 10))
+}
@@ -0,0 +1,65 @@
+package Infrastructure
+
+import (
+	"errors"
+	"log/slog"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// PlanEnforcer holds shops to the limits of the plan they pay for
+type PlanEnforcer interface {
+	// CheckDevice records the device's use of the shop, refusing it if the plan's
+	// devices are all taken
+	CheckDevice(businessID, deviceID string) error
+	// UseQuota counts one use of a daily allowance, refusing it once the plan's is spent
+	UseQuota(businessID string, quota Domain.PlanQuota) error
+}
+
+// PlanMiddleware refuses requests from devices beyond the shop's plan with a 402.
+// Requests without a device ID, such as from the web dashboard, are not counted,
+// and the billing routes are left open so an owner over the limit can upgrade.
+// If the limits cannot be checked the request goes through, so that a billing
+// outage does not stop shops from selling.
+func PlanMiddleware(enforcer PlanEnforcer) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		deviceID := requestDeviceID(c)
+		if deviceID == "" || strings.Contains(c.FullPath(), "/billing/") {
+			c.Next()
+			return
+		}
+
+		if err := enforcer.CheckDevice(c.GetString("businessID"), deviceID); err != nil {
+			if abortForPlan(c, err) {
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// RequirePlanQuota counts the request against one of the plan's daily allowances
+func RequirePlanQuota(enforcer PlanEnforcer, quota Domain.PlanQuota) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if err := enforcer.UseQuota(c.GetString("businessID"), quota); err != nil {
+			if abortForPlan(c, err) {
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// abortForPlan sends the plan's refusal, or logs a failed check and lets the request on
+func abortForPlan(c *gin.Context, err error) bool {
+	var appErr *Domain.AppError
+	if errors.As(err, &appErr) {
+		AbortWithError(c, appErr)
+		return true
+	}
+	LoggerFrom(c.Request.Context()).Warn("failed to check plan limits", slog.String("error", err.Error()))
+	return false
+}
@@ -0,0 +1,105 @@
+package Infrastructure
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// printBridgeTokenPrefix marks print bridge tokens so they are easy to tell from
+// user tokens in a bridge's config file
+const printBridgeTokenPrefix = "pb_"
+
+// NewPrintBridgeToken generates a bridge's token and the hash that is stored
+func NewPrintBridgeToken() (token, hash string, err error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", fmt.Errorf("failed to generate print bridge token: %w", err)
+	}
+	token = printBridgeTokenPrefix + hex.EncodeToString(buf)
+	return token, HashPrintBridgeToken(token), nil
+}
+
+// HashPrintBridgeToken is how a token is looked up; only the hash is kept, so a
+// database leak does not let anyone collect a shop's print jobs
+func HashPrintBridgeToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// PrintBridgeAuthenticator finds the bridge a token belongs to
+type PrintBridgeAuthenticator interface {
+	// AuthenticateBridge returns the bridge, or nil for an unknown token
+	AuthenticateBridge(token string) (*Domain.PrintBridge, error)
+}
+
+// PrintBridgeAuth accepts requests bearing a print bridge's token in place of a
+// user's, and sets the bridge and its business for the handlers
+func PrintBridgeAuth(auth PrintBridgeAuthenticator) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+		if !ok || !strings.HasPrefix(token, printBridgeTokenPrefix) {
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeUnauthorized, "A print bridge token is required"))
+			return
+		}
+
+		bridge, err := auth.AuthenticateBridge(token)
+		if err != nil {
+			JSONError(c, http.StatusInternalServerError, err, "")
+			c.Abort()
+			return
+		}
+		if bridge == nil {
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeUnauthorized, "Invalid print bridge token"))
+			return
+		}
+
+		c.Set("printBridge", bridge)
+		c.Set("businessID", bridge.BusinessID.Hex())
+		c.Next()
+	}
+}
+
+// PrintSignal wakes bridges long-polling this instance when a job is queued for
+// their shop. Bridges polling another instance find the job on their next check.
+type PrintSignal struct {
+	mu      sync.Mutex
+	waiters map[string]chan struct{}
+}
+
+func NewPrintSignal() *PrintSignal {
+	return &PrintSignal{waiters: make(map[string]chan struct{})}
+}
+
+// Wait returns a channel closed by the next Notify for the business. Take it
+// before looking for jobs, so a job queued in between is not missed.
+func (s *PrintSignal) Wait(businessID string) <-chan struct{} {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	ch, ok := s.waiters[businessID]
+	if !ok {
+		ch = make(chan struct{})
+		s.waiters[businessID] = ch
+	}
+	return ch
+}
+
+// Notify wakes every bridge of the business waiting on this instance
+func (s *PrintSignal) Notify(businessID string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if ch, ok := s.waiters[businessID]; ok {
+		close(ch)
+		delete(s.waiters, businessID)
+	}
+}
@@ -0,0 +1,343 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// PushNotification is one alert for one device
+type PushNotification struct {
+	Title string
+	Body  string
+	Data  map[string]string // Delivered to the app alongside the alert
+}
+
+// PushSender delivers notifications to phones through FCM and APNs
+type PushSender interface {
+	// Send delivers the notification to the device token and returns the push
+	// service's message ID
+	Send(ctx context.Context, platform Domain.PushPlatform, token string, notification PushNotification) (string, error)
+}
+
+// ErrPushTokenGone means the push service no longer knows the device token, because
+// the app was uninstalled or the token was replaced. Retrying will not help.
+var ErrPushTokenGone = errors.New("push token is no longer registered")
+
+// NewPushSender sends Android notifications through FCM when FCM_* is set and iOS
+// notifications through APNs when APNS_* is set. A platform without credentials
+// has its notifications written to the server log, for development.
+func NewPushSender(cfg PushConfig) PushSender {
+	client := &http.Client{Timeout: 15 * time.Second}
+	sender := &pushSender{}
+
+	if cfg.FCMProjectID != "" {
+		key, err := parseRSAPrivateKey(cfg.FCMPrivateKey)
+		if err != nil {
+			log.Printf("Warning: FCM disabled, bad FCM_PRIVATE_KEY: %v", err)
+		} else {
+			sender.fcm = &fcmSender{client: client, projectID: cfg.FCMProjectID, clientEmail: cfg.FCMClientEmail, key: key}
+		}
+	}
+
+	if cfg.APNsKeyID != "" {
+		key, err := parseECPrivateKey(cfg.APNsKey)
+		if err != nil {
+			log.Printf("Warning: APNs disabled, bad APNS_KEY: %v", err)
+		} else {
+			host := "https://api.push.apple.com"
+			if cfg.APNsSandbox {
+				host = "https://api.sandbox.push.apple.com"
+			}
+			sender.apns = &apnsSender{client: client, host: host, keyID: cfg.APNsKeyID, teamID: cfg.APNsTeamID, topic: cfg.APNsTopic, key: key}
+		}
+	}
+
+	return sender
+}
+
+type pushSender struct {
+	fcm  *fcmSender
+	apns *apnsSender
+}
+
+func (s *pushSender) Send(ctx context.Context, platform Domain.PushPlatform, token string, notification PushNotification) (string, error) {
+	switch {
+	case platform == Domain.PushPlatformAndroid && s.fcm != nil:
+		return s.fcm.send(ctx, token, notification)
+	case platform == Domain.PushPlatformIOS && s.apns != nil:
+		return s.apns.send(ctx, token, notification)
+	default:
+		log.Printf("Push to=%s platform=%s title=%q body=%q", shortToken(token), platform, notification.Title, notification.Body)
+		return fmt.Sprintf("log-%d", time.Now().UnixNano()), nil
+	}
+}
+
+// fcmSender uses the FCM HTTP v1 API, authenticated with an OAuth token obtained
+// by signing a JWT with the service account key
+type fcmSender struct {
+	client      *http.Client
+	projectID   string
+	clientEmail string
+	key         *rsa.PrivateKey
+
+	mu          sync.Mutex
+	accessToken string
+	expiresAt   time.Time
+}
+
+const (
+	fcmTokenURL = "https://oauth2.googleapis.com/token"
+	fcmScope    = "https://www.googleapis.com/auth/firebase.messaging"
+)
+
+func (s *fcmSender) send(ctx context.Context, token string, notification PushNotification) (string, error) {
+	accessToken, err := s.token(ctx)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(map[string]interface{}{
+		"message": map[string]interface{}{
+			"token":        token,
+			"notification": map[string]string{"title": notification.Title, "body": notification.Body},
+			"data":         notification.Data,
+			"android":      map[string]string{"priority": "high"},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	endpoint := fmt.Sprintf("https://fcm.googleapis.com/v1/projects/%s/messages:send", url.PathEscape(s.projectID))
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		Name  string `json:"name"`
+		Error struct {
+			Status  string `json:"status"`
+			Message string `json:"message"`
+			Details []struct {
+				ErrorCode string `json:"errorCode"`
+			} `json:"details"`
+		} `json:"error"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("fcm returned status %d", resp.StatusCode)
+	}
+	if resp.StatusCode != http.StatusOK {
+		for _, detail := range result.Error.Details {
+			if detail.ErrorCode == "UNREGISTERED" {
+				return "", fmt.Errorf("%w: %s", ErrPushTokenGone, result.Error.Message)
+			}
+		}
+		if resp.StatusCode == http.StatusUnauthorized {
+			s.mu.Lock()
+			s.accessToken = ""
+			s.mu.Unlock()
+		}
+		return "", fmt.Errorf("fcm: %s: %s", result.Error.Status, result.Error.Message)
+	}
+	return result.Name, nil
+}
+
+// token returns a cached access token, fetching a new one shortly before it expires
+func (s *fcmSender) token(ctx context.Context) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.accessToken != "" && time.Now().Before(s.expiresAt) {
+		return s.accessToken, nil
+	}
+
+	now := time.Now()
+	assertion, err := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"iss":   s.clientEmail,
+		"scope": fcmScope,
+		"aud":   fcmTokenURL,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	}).SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign fcm token request: %w", err)
+	}
+
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, fcmTokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("fcm token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+		Error       string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil || result.AccessToken == "" {
+		return "", fmt.Errorf("fcm token request returned status %d: %s", resp.StatusCode, result.Error)
+	}
+
+	s.accessToken = result.AccessToken
+	s.expiresAt = now.Add(time.Duration(result.ExpiresIn)*time.Second - 5*time.Minute)
+	return s.accessToken, nil
+}
+
+// apnsSender uses APNs over HTTP/2 with token-based authentication. Apple rejects
+// tokens older than an hour and throttles ones renewed too often, so each is reused
+// for 40 minutes.
+type apnsSender struct {
+	client *http.Client
+	host   string
+	keyID  string
+	teamID string
+	topic  string
+	key    *ecdsa.PrivateKey
+
+	mu       sync.Mutex
+	jwt      string
+	issuedAt time.Time
+}
+
+func (s *apnsSender) send(ctx context.Context, token string, notification PushNotification) (string, error) {
+	authToken, err := s.token()
+	if err != nil {
+		return "", err
+	}
+
+	payload := map[string]interface{}{
+		"aps": map[string]interface{}{
+			"alert": map[string]string{"title": notification.Title, "body": notification.Body},
+			"sound": "default",
+		},
+	}
+	for key, value := range notification.Data {
+		if key != "aps" {
+			payload[key] = value
+		}
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.host+"/3/device/"+url.PathEscape(token), bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "bearer "+authToken)
+	req.Header.Set("apns-topic", s.topic)
+	req.Header.Set("apns-push-type", "alert")
+	req.Header.Set("apns-priority", "10")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("apns request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return resp.Header.Get("apns-id"), nil
+	}
+
+	var result struct {
+		Reason string `json:"reason"`
+	}
+	_ = json.NewDecoder(resp.Body).Decode(&result)
+	if resp.StatusCode == http.StatusGone || result.Reason == "BadDeviceToken" || result.Reason == "Unregistered" {
+		return "", fmt.Errorf("%w: %s", ErrPushTokenGone, result.Reason)
+	}
+	if result.Reason == "ExpiredProviderToken" {
+		s.mu.Lock()
+		s.jwt = ""
+		s.mu.Unlock()
+	}
+	return "", fmt.Errorf("apns returned status %d: %s", resp.StatusCode, result.Reason)
+}
+
+func (s *apnsSender) token() (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.jwt != "" && time.Since(s.issuedAt) < 40*time.Minute {
+		return s.jwt, nil
+	}
+
+	now := time.Now()
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, jwt.MapClaims{
+		"iss": s.teamID,
+		"iat": now.Unix(),
+	})
+	token.Header["kid"] = s.keyID
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign apns token: %w", err)
+	}
+
+	s.jwt, s.issuedAt = signed, now
+	return signed, nil
+}
+
+func parseECPrivateKey(value string) (*ecdsa.PrivateKey, error) {
+	block, _ := pem.Decode([]byte(strings.ReplaceAll(value, `\n`, "\n")))
+	if block == nil {
+		return nil, errors.New("not a PEM key")
+	}
+	if key, err := x509.ParseECPrivateKey(block.Bytes); err == nil {
+		return key, nil
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+	key, ok := parsed.(*ecdsa.PrivateKey)
+	if !ok {
+		return nil, errors.New("not an EC key")
+	}
+	return key, nil
+}
+
+// shortToken is enough of a device token to tell devices apart in logs
+func shortToken(token string) string {
+	if len(token) <= 12 {
+		return token
+	}
+	return token[:12] + "..."
+}
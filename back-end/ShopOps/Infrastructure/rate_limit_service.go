@@ -1,12 +1,15 @@
 package Infrastructure
 
 import (
+	stdcontext "context"
 	"fmt"
+	"log/slog"
 	"time"
 
+	Domain "ShopOps/Domain"
+
 	"github.com/gin-gonic/gin"
 	"github.com/ulule/limiter/v3"
-	memory "github.com/ulule/limiter/v3/drivers/store/memory"
 )
 
 type RateLimitService interface {
@@ -14,29 +17,62 @@ type RateLimitService interface {
 	LimitExports() gin.HandlerFunc
 	LimitSync() gin.HandlerFunc
 	LimitRestore() gin.HandlerFunc
+	LimitBulkSMS() gin.HandlerFunc
+	// LimitBI counts BI tools' feed reads per business, apart from the shop's own
+	// requests, so a nightly sync cannot lock the staff out of the app
+	LimitBI() gin.HandlerFunc
+	// Allow counts a request against a limit outside gin, e.g. a gRPC call. The
+	// client key is built as the middlewares build it, so a user shares one budget
+	// across REST and gRPC.
+	Allow(ctx stdcontext.Context, limit RateLimit, clientKey string) (limiter.Context, error)
+	// ResetBusiness clears the counts for the business and its users, so support can
+	// unblock a shop. Counts are per instance with the in-memory store.
+	ResetBusiness(ctx stdcontext.Context, businessID string, userIDs []string) error
+	// Check verifies the limiter store answers, for the health probes
+	Check(ctx stdcontext.Context) error
 }
 
+// RateLimit names a limit for Allow
+type RateLimit string
+
+const (
+	RateLimitGeneral RateLimit = "general" // 100 requests per minute
+	RateLimitExport  RateLimit = "export"  // 10 requests per hour
+	RateLimitSync    RateLimit = "sync"    // 60 requests per minute
+	RateLimitRestore RateLimit = "restore" // 1 request per hour; the client key is the device's
+)
+
 type rateLimitService struct {
 	generalLimiter  *limiter.Limiter
 	exportLimiter   *limiter.Limiter
 	syncLimiter     *limiter.Limiter
 	restoreLimiter  *limiter.Limiter
+	bulkSMSLimiter  *limiter.Limiter
+	biLimiter       *limiter.Limiter
+	clock           Clock
+	logger          *slog.Logger
 }
 
-func NewRateLimitService() RateLimitService {
-	store := memory.NewStore()
-	
+// NewRateLimitService counts requests in store, which is shared by every limit.
+// When the store fails requests are let through and the error is logged.
+func NewRateLimitService(store limiter.Store, clock Clock, logger *slog.Logger) RateLimitService {
 	// Using formatted rate strings
 	generalRate, _ := limiter.NewRateFromFormatted("100-M")  // 100 requests per minute
 	exportRate, _ := limiter.NewRateFromFormatted("10-H")    // 10 requests per hour
 	syncRate, _ := limiter.NewRateFromFormatted("60-M")      // 60 requests per minute
 	restoreRate, _ := limiter.NewRateFromFormatted("1-H")    // 1 request per hour
+	bulkSMSRate, _ := limiter.NewRateFromFormatted("5-H")    // 5 campaigns per hour
+	biRate, _ := limiter.NewRateFromFormatted("120-M")       // 120 feed pages per minute
 	
 	return &rateLimitService{
 		generalLimiter:  limiter.New(store, generalRate),
 		exportLimiter:   limiter.New(store, exportRate),
 		syncLimiter:     limiter.New(store, syncRate),
 		restoreLimiter:  limiter.New(store, restoreRate),
+		bulkSMSLimiter:  limiter.New(store, bulkSMSRate),
+		biLimiter:       limiter.New(store, biRate),
+		clock:           clock,
+		logger:          logger,
 	}
 }
 
@@ -73,6 +109,7 @@ func (s *rateLimitService) LimitGeneral() gin.HandlerFunc {
 		key := s.getClientKey(c)
 		context, err := s.generalLimiter.Get(c, key)
 		if err != nil {
+			s.logger.Warn("rate limiter unavailable", slog.String("error", err.Error()))
 			c.Next()
 			return
 		}
@@ -80,12 +117,16 @@ func (s *rateLimitService) LimitGeneral() gin.HandlerFunc {
 		s.setRateLimitHeaders(c, context)
 		
 		if context.Reached {
+			RateLimitRejections.Inc("general")
 			// FIX: context.Reset is int64 (seconds), not time.Duration
 			retryAfterSeconds := context.Reset
-			resetTime := time.Now().Add(time.Duration(context.Reset) * time.Second)
+			resetTime := s.clock.Now().Add(time.Duration(context.Reset) * time.Second)
 			
 			c.JSON(429, gin.H{
 				"error":       "Rate limit exceeded. Maximum 100 requests per minute.",
+				"code":        Domain.ErrCodeRateLimited,
+				"message_key": Domain.ErrCodeRateLimited.MessageKey(),
+				"request_id":  c.GetString("requestID"),
 				"retry_after": retryAfterSeconds,
 				"limit":       100,
 				"remaining":   0,
@@ -105,6 +146,7 @@ func (s *rateLimitService) LimitExports() gin.HandlerFunc {
 		key := s.getClientKey(c) + ":export"
 		context, err := s.exportLimiter.Get(c, key)
 		if err != nil {
+			s.logger.Warn("rate limiter unavailable", slog.String("error", err.Error()))
 			c.Next()
 			return
 		}
@@ -112,12 +154,16 @@ func (s *rateLimitService) LimitExports() gin.HandlerFunc {
 		s.setRateLimitHeaders(c, context)
 		
 		if context.Reached {
+			RateLimitRejections.Inc("export")
 			// FIX: context.Reset is int64 (seconds)
 			retryAfterSeconds := context.Reset
-			resetTime := time.Now().Add(time.Duration(context.Reset) * time.Second)
+			resetTime := s.clock.Now().Add(time.Duration(context.Reset) * time.Second)
 			
 			c.JSON(429, gin.H{
 				"error":       "Export rate limit exceeded. Maximum 10 exports per hour.",
+				"code":        Domain.ErrCodeRateLimited,
+				"message_key": Domain.ErrCodeRateLimited.MessageKey(),
+				"request_id":  c.GetString("requestID"),
 				"retry_after": retryAfterSeconds,
 				"limit":       10,
 				"remaining":   0,
@@ -137,6 +183,7 @@ func (s *rateLimitService) LimitSync() gin.HandlerFunc {
 		key := s.getClientKey(c) + ":sync"
 		context, err := s.syncLimiter.Get(c, key)
 		if err != nil {
+			s.logger.Warn("rate limiter unavailable", slog.String("error", err.Error()))
 			c.Next()
 			return
 		}
@@ -144,12 +191,16 @@ func (s *rateLimitService) LimitSync() gin.HandlerFunc {
 		s.setRateLimitHeaders(c, context)
 		
 		if context.Reached {
+			RateLimitRejections.Inc("sync")
 			// FIX: context.Reset is int64 (seconds)
 			retryAfterSeconds := context.Reset
-			resetTime := time.Now().Add(time.Duration(context.Reset) * time.Second)
+			resetTime := s.clock.Now().Add(time.Duration(context.Reset) * time.Second)
 			
 			c.JSON(429, gin.H{
 				"error":       "Sync rate limit exceeded. Maximum 60 sync requests per minute.",
+				"code":        Domain.ErrCodeRateLimited,
+				"message_key": Domain.ErrCodeRateLimited.MessageKey(),
+				"request_id":  c.GetString("requestID"),
 				"retry_after": retryAfterSeconds,
 				"limit":       60,
 				"remaining":   0,
@@ -168,16 +219,15 @@ func (s *rateLimitService) LimitRestore() gin.HandlerFunc {
 	return func(c *gin.Context) {
 		deviceID := s.getDeviceID(c)
 		if deviceID == "" {
-			c.JSON(400, gin.H{
-				"error": "Device ID is required for restore operations. Provide via query param 'device_id' or header 'X-Device-ID'",
-			})
-			c.Abort()
+			AbortWithError(c, Domain.NewAppError(Domain.ErrCodeInvalidArgument,
+				"Device ID is required for restore operations. Provide via query param 'device_id' or header 'X-Device-ID'"))
 			return
 		}
 		
 		key := "device:" + deviceID + ":restore"
 		context, err := s.restoreLimiter.Get(c, key)
 		if err != nil {
+			s.logger.Warn("rate limiter unavailable", slog.String("error", err.Error()))
 			c.Next()
 			return
 		}
@@ -185,12 +235,16 @@ func (s *rateLimitService) LimitRestore() gin.HandlerFunc {
 		s.setRateLimitHeaders(c, context)
 		
 		if context.Reached {
+			RateLimitRejections.Inc("restore")
 			// FIX: context.Reset is int64 (seconds)
 			retryAfterSeconds := context.Reset
-			resetTime := time.Now().Add(time.Duration(context.Reset) * time.Second)
+			resetTime := s.clock.Now().Add(time.Duration(context.Reset) * time.Second)
 			
 			c.JSON(429, gin.H{
 				"error":       "Device restore rate limit exceeded. Maximum 1 restore per hour per device.",
+				"code":        Domain.ErrCodeRateLimited,
+				"message_key": Domain.ErrCodeRateLimited.MessageKey(),
+				"request_id":  c.GetString("requestID"),
 				"retry_after": retryAfterSeconds,
 				"limit":       1,
 				"remaining":   0,
@@ -205,6 +259,137 @@ func (s *rateLimitService) LimitRestore() gin.HandlerFunc {
 	}
 }
 
+// LimitBulkSMS - 5 bulk SMS campaigns per hour per business
+func (s *rateLimitService) LimitBulkSMS() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "business:" + c.Param("businessId") + ":bulk_sms"
+		context, err := s.bulkSMSLimiter.Get(c, key)
+		if err != nil {
+			s.logger.Warn("rate limiter unavailable", slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+		
+		s.setRateLimitHeaders(c, context)
+		
+		if context.Reached {
+			RateLimitRejections.Inc("bulk_sms")
+			retryAfterSeconds := context.Reset
+			resetTime := s.clock.Now().Add(time.Duration(context.Reset) * time.Second)
+			
+			c.JSON(429, gin.H{
+				"error":       "Bulk SMS rate limit exceeded. Maximum 5 campaigns per hour.",
+				"code":        Domain.ErrCodeRateLimited,
+				"message_key": Domain.ErrCodeRateLimited.MessageKey(),
+				"request_id":  c.GetString("requestID"),
+				"retry_after": retryAfterSeconds,
+				"limit":       5,
+				"remaining":   0,
+				"reset_at":    resetTime.Format(time.RFC3339),
+			})
+			c.Abort()
+			return
+		}
+		
+		c.Next()
+	}
+}
+
+// LimitBI - 120 feed pages per minute per business, for BI keys
+func (s *rateLimitService) LimitBI() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		key := "business:" + c.GetString("businessID") + ":bi"
+		context, err := s.biLimiter.Get(c, key)
+		if err != nil {
+			s.logger.Warn("rate limiter unavailable", slog.String("error", err.Error()))
+			c.Next()
+			return
+		}
+		
+		s.setRateLimitHeaders(c, context)
+		
+		if context.Reached {
+			RateLimitRejections.Inc("bi")
+			retryAfterSeconds := context.Reset
+			resetTime := s.clock.Now().Add(time.Duration(context.Reset) * time.Second)
+			
+			c.JSON(429, gin.H{
+				"error":       "BI rate limit exceeded. Maximum 120 requests per minute.",
+				"code":        Domain.ErrCodeRateLimited,
+				"message_key": Domain.ErrCodeRateLimited.MessageKey(),
+				"request_id":  c.GetString("requestID"),
+				"retry_after": retryAfterSeconds,
+				"limit":       120,
+				"remaining":   0,
+				"reset_at":    resetTime.Format(time.RFC3339),
+			})
+			c.Abort()
+			return
+		}
+		
+		c.Next()
+	}
+}
+
+func (s *rateLimitService) Allow(ctx stdcontext.Context, limit RateLimit, clientKey string) (limiter.Context, error) {
+	var context limiter.Context
+	var err error
+	switch limit {
+	case RateLimitGeneral:
+		context, err = s.generalLimiter.Get(ctx, clientKey)
+	case RateLimitExport:
+		context, err = s.exportLimiter.Get(ctx, clientKey+":export")
+	case RateLimitSync:
+		context, err = s.syncLimiter.Get(ctx, clientKey+":sync")
+	case RateLimitRestore:
+		context, err = s.restoreLimiter.Get(ctx, clientKey+":restore")
+	default:
+		return limiter.Context{}, fmt.Errorf("unknown rate limit %q", limit)
+	}
+	if err != nil {
+		return context, err
+	}
+
+	if context.Reached {
+		RateLimitRejections.Inc(string(limit))
+	}
+	return context, nil
+}
+
+func (s *rateLimitService) ResetBusiness(ctx stdcontext.Context, businessID string, userIDs []string) error {
+	reset := func(l *limiter.Limiter, key string) error {
+		if _, err := l.Reset(ctx, key); err != nil {
+			return fmt.Errorf("failed to reset rate limit %s: %w", key, err)
+		}
+		return nil
+	}
+
+	if err := reset(s.bulkSMSLimiter, "business:"+businessID+":bulk_sms"); err != nil {
+		return err
+	}
+	if err := reset(s.biLimiter, "business:"+businessID+":bi"); err != nil {
+		return err
+	}
+	for _, userID := range userIDs {
+		key := "user:" + userID
+		if err := reset(s.generalLimiter, key); err != nil {
+			return err
+		}
+		if err := reset(s.exportLimiter, key+":export"); err != nil {
+			return err
+		}
+		if err := reset(s.syncLimiter, key+":sync"); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *rateLimitService) Check(ctx stdcontext.Context) error {
+	_, err := s.generalLimiter.Peek(ctx, "healthcheck")
+	return err
+}
+
 // Set rate limit headers for response
 func (s *rateLimitService) setRateLimitHeaders(c *gin.Context, context limiter.Context) {
 	c.Header("X-RateLimit-Limit", fmt.Sprintf("%d", context.Limit))
@@ -214,4 +399,34 @@ func (s *rateLimitService) setRateLimitHeaders(c *gin.Context, context limiter.C
 	if context.Reached {
 		c.Header("Retry-After", fmt.Sprintf("%d", context.Reset))
 	}
-}
\ No newline at end of file
+}
+// unlimitedRateLimitService lets every request through, for servers under a load
+// test started with RATE_LIMITS=false
+type unlimitedRateLimitService struct{}
+
+func NewUnlimitedRateLimitService() RateLimitService {
+	return unlimitedRateLimitService{}
+}
+
+func passThrough(c *gin.Context) {
+	c.Next()
+}
+
+func (unlimitedRateLimitService) LimitGeneral() gin.HandlerFunc { return passThrough }
+func (unlimitedRateLimitService) LimitExports() gin.HandlerFunc { return passThrough }
+func (unlimitedRateLimitService) LimitSync() gin.HandlerFunc    { return passThrough }
+func (unlimitedRateLimitService) LimitRestore() gin.HandlerFunc { return passThrough }
+func (unlimitedRateLimitService) LimitBulkSMS() gin.HandlerFunc { return passThrough }
+func (unlimitedRateLimitService) LimitBI() gin.HandlerFunc      { return passThrough }
+
+func (unlimitedRateLimitService) Allow(ctx stdcontext.Context, limit RateLimit, clientKey string) (limiter.Context, error) {
+	return limiter.Context{}, nil
+}
+
+func (unlimitedRateLimitService) ResetBusiness(ctx stdcontext.Context, businessID string, userIDs []string) error {
+	return nil
+}
+
+func (unlimitedRateLimitService) Check(ctx stdcontext.Context) error {
+	return nil
+}
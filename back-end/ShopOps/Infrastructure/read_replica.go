@@ -0,0 +1,166 @@
+package Infrastructure
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+	"go.mongodb.org/mongo-driver/mongo/readpref"
+)
+
+// Reports, exports and dashboards scan far more data than the POS writes, so their
+// reads go to a read replica when one is configured. MONGODB_READ_URLS lists replica
+// DSNs in order of preference; reads use the first healthy one and fall back to the
+// primary cluster when none are. Without replicas of their own, reporting reads use
+// the primary cluster's secondaries when it has any.
+
+type readReplica struct {
+	url     string // Redacted, for logs
+	db      *mongo.Database
+	healthy bool
+}
+
+var (
+	replicasMu   sync.RWMutex
+	readReplicas []*readReplica
+	primaryReads *mongo.Database
+)
+
+// Replica pings slower than this count as failures
+const replicaPingTimeout = 3 * time.Second
+
+var ReplicaReads = NewCounterVec("shopops_db_replica_reads_total",
+	"Reporting reads by the database they were routed to", "target")
+
+// InitReadReplicas connects to the replicas in MONGODB_READ_URLS. Call it after
+// InitMongo. An unreachable replica does not fail startup; it is skipped until it
+// answers a ping.
+func InitReadReplicas() error {
+	if db == nil {
+		return errors.New("mongodb is not initialized")
+	}
+	dbName := mongoConfig.Database
+	primaryReads = client.Database(dbName, options.Database().SetReadPreference(readpref.SecondaryPreferred()))
+
+	var replicas []*readReplica
+	for _, uri := range mongoConfig.ReadURLs {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		replicaClient, err := mongo.Connect(ctx, applyPoolOptions(options.Client().
+			ApplyURI(uri).
+			SetServerSelectionTimeout(replicaPingTimeout).
+			SetReadPreference(readpref.SecondaryPreferred()).
+			SetRegistry(decimalRegistry()).
+			SetMonitor(commandMonitor()), mongoConfig))
+		cancel()
+		if err != nil {
+			return fmt.Errorf("failed to configure read replica %s: %w", redactURI(uri), err)
+		}
+		replicas = append(replicas, &readReplica{url: redactURI(uri), db: replicaClient.Database(dbName)})
+	}
+
+	replicasMu.Lock()
+	readReplicas = replicas
+	replicasMu.Unlock()
+
+	if len(replicas) > 0 {
+		_ = CheckReadReplicas()
+	}
+	return nil
+}
+
+// ReadDB returns the database reporting queries should read from: the first healthy
+// replica, or the primary cluster if there is none
+func ReadDB() *mongo.Database {
+	replicasMu.RLock()
+	defer replicasMu.RUnlock()
+
+	for _, replica := range readReplicas {
+		if replica.healthy {
+			ReplicaReads.Inc("replica")
+			return replica.db
+		}
+	}
+	ReplicaReads.Inc("primary")
+	if primaryReads == nil {
+		return db
+	}
+	return primaryReads
+}
+
+// CheckReadReplicas pings every replica and updates which ones take reads. It fails
+// when replicas are configured but none are reachable, so reads are on the primary.
+func CheckReadReplicas() error {
+	replicasMu.RLock()
+	replicas := append([]*readReplica(nil), readReplicas...)
+	replicasMu.RUnlock()
+	if len(replicas) == 0 {
+		return nil
+	}
+
+	healthy := make([]bool, len(replicas))
+	var wg sync.WaitGroup
+	for i, replica := range replicas {
+		wg.Add(1)
+		go func(i int, replica *readReplica) {
+			defer wg.Done()
+			ctx, cancel := context.WithTimeout(context.Background(), replicaPingTimeout)
+			defer cancel()
+			healthy[i] = replica.db.Client().Ping(ctx, readpref.Nearest()) == nil
+		}(i, replica)
+	}
+	wg.Wait()
+
+	available := 0
+	replicasMu.Lock()
+	for i, replica := range replicas {
+		if replica.healthy != healthy[i] {
+			if healthy[i] {
+				Logger.Info("read replica is back; routing reads to it", slog.String("replica", replica.url))
+			} else {
+				Logger.Warn("read replica is unreachable; skipping it", slog.String("replica", replica.url))
+			}
+		}
+		replica.healthy = healthy[i]
+		if healthy[i] {
+			available++
+		}
+	}
+	replicasMu.Unlock()
+
+	if available == 0 {
+		return errors.New("no read replica reachable; reporting reads are on the primary")
+	}
+	return nil
+}
+
+// CloseReadReplicas disconnects from the replicas
+func CloseReadReplicas() {
+	replicasMu.Lock()
+	replicas := readReplicas
+	readReplicas = nil
+	replicasMu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	for _, replica := range replicas {
+		_ = replica.db.Client().Disconnect(ctx)
+	}
+}
+
+// redactURI drops credentials from a connection string
+func redactURI(uri string) string {
+	scheme, rest, ok := strings.Cut(uri, "://")
+	if !ok {
+		return "invalid uri"
+	}
+	if at := strings.LastIndex(strings.SplitN(rest, "/", 2)[0], "@"); at >= 0 {
+		rest = rest[at+1:]
+	}
+	return scheme + "://" + rest
+}
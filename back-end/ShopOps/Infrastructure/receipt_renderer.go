@@ -0,0 +1,503 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+	"strings"
+
+	Domain "ShopOps/Domain"
+)
+
+type ReceiptRenderer interface {
+	RenderESCPOS(data Domain.ReceiptData) ([]byte, error)
+	RenderHTML(data Domain.ReceiptData) ([]byte, error)
+	// RenderZReport prints a day's takings in ESC/POS
+	RenderZReport(data Domain.ZReportData) ([]byte, error)
+	// RenderBarcodeLabels prints a product's name, price and barcode in ESC/POS,
+	// once per copy, cutting between labels
+	RenderBarcodeLabels(data Domain.BarcodeLabelData) ([]byte, error)
+}
+
+type receiptRenderer struct {
+	htmlTemplate *template.Template
+}
+
+func NewReceiptRenderer() ReceiptRenderer {
+	return &receiptRenderer{
+		htmlTemplate: template.Must(template.New("receipt").Parse(receiptHTML)),
+	}
+}
+
+// ESC/POS command bytes
+var (
+	escInit        = []byte{0x1B, 0x40}
+	escAlignLeft   = []byte{0x1B, 0x61, 0x00}
+	escAlignCenter = []byte{0x1B, 0x61, 0x01}
+	escBoldOn      = []byte{0x1B, 0x45, 0x01}
+	escBoldOff     = []byte{0x1B, 0x45, 0x00}
+	escDoubleSize  = []byte{0x1D, 0x21, 0x11}
+	escNormalSize  = []byte{0x1D, 0x21, 0x00}
+	escStoredLogo  = []byte{0x1C, 0x70, 0x01, 0x00} // FS p: print NV logo #1
+	escFeedAndCut  = []byte{0x1D, 0x56, 0x42, 0x03} // GS V B: feed 3 lines, partial cut
+)
+
+// ESC/POS barcode settings for labels
+var (
+	escBarcodeHRI  = []byte{0x1D, 0x48, 0x02}                   // GS H: digits below the bars
+	escBarcodeSize = []byte{0x1D, 0x68, 0x50, 0x1D, 0x77, 0x02} // GS h: 80 dots high, GS w: module width 2
+)
+
+func (r *receiptRenderer) RenderESCPOS(data Domain.ReceiptData) ([]byte, error) {
+	if data.Template == nil || data.Sale == nil {
+		return nil, fmt.Errorf("template and sale are required")
+	}
+
+	tpl := data.Template
+	sale := data.Sale
+	cols := tpl.PaperWidth.Columns()
+	language := printerLanguage(data.Language)
+	label := func(key string) string {
+		return Translate(language, key)
+	}
+
+	var buf bytes.Buffer
+	line := func(text string) {
+		buf.WriteString(escposText(text))
+		buf.WriteByte('\n')
+	}
+	row := func(label string, amount Domain.Money) {
+		line(padColumns(label, amount.String(), cols))
+	}
+	divider := func() {
+		line(strings.Repeat("-", cols))
+	}
+
+	buf.Write(escInit)
+	buf.Write(escAlignCenter)
+
+	if tpl.PrintStoredLogo {
+		buf.Write(escStoredLogo)
+		buf.WriteByte('\n')
+	}
+
+	if tpl.Fields.BusinessName && data.Business != nil {
+		buf.Write(escDoubleSize)
+		buf.Write(escBoldOn)
+		line(data.Business.Name)
+		buf.Write(escBoldOff)
+		buf.Write(escNormalSize)
+	}
+	if tpl.Fields.Address && data.Business != nil && data.Business.Address != "" {
+		line(joinNonEmpty(", ", data.Business.Address, data.Business.City))
+	}
+	if tpl.Fields.Phone && data.Business != nil && data.Business.Phone != "" {
+		line(label("receipt.tel") + ": " + data.Business.Phone)
+	}
+	for _, h := range tpl.Header {
+		line(h)
+	}
+
+	buf.Write(escAlignLeft)
+	divider()
+
+	if tpl.Fields.SaleID {
+		line(label("receipt.receipt") + ": " + sale.ID.Hex())
+	}
+	if tpl.Fields.Date {
+		line(label("receipt.date") + ": " + sale.CreatedAt.Format("2006-01-02 15:04"))
+	}
+	if tpl.Fields.Customer && sale.CustomerName != "" {
+		line(label("receipt.customer") + ": " + sale.CustomerName)
+	}
+
+	divider()
+
+	item := data.ProductName
+	if item == "" {
+		item = label("receipt.item")
+	}
+	line(item)
+	line(padColumns(fmt.Sprintf("  %.2f x %s", sale.Quantity, sale.UnitPrice), sale.TotalAmount.String(), cols))
+
+	divider()
+
+	if tpl.Fields.Discount && sale.Discount > 0 {
+		row(label("receipt.discount"), -sale.Discount)
+	}
+	if tpl.Fields.Tax && sale.Tax > 0 {
+		row(label("receipt.tax"), sale.Tax)
+	}
+
+	buf.Write(escBoldOn)
+	row(label("receipt.total"), sale.FinalAmount)
+	buf.Write(escBoldOff)
+	for _, d := range sale.Deposits {
+		row(fmt.Sprintf("%s %g x %s", label("receipt.deposit"), d.Quantity, d.Name), d.Total)
+	}
+	if sale.CashRounding != 0 {
+		row(label("receipt.rounding"), sale.CashRounding)
+		row(label("receipt.cash_due"), sale.FinalAmount+sale.DepositAmount+sale.CashRounding)
+	} else if sale.DepositAmount != 0 {
+		row(label("receipt.amount_due"), sale.FinalAmount+sale.DepositAmount)
+	}
+
+	if tpl.Fields.PaymentBreakdown {
+		if len(sale.Payments) > 0 {
+			for _, p := range sale.Payments {
+				row(paymentLabel(language, p.Method), p.Amount)
+				if p.Currency != "" {
+					line(fmt.Sprintf("  %s %s @ %g", p.Currency, p.ForeignAmount, p.ExchangeRate))
+				}
+				if p.Change > 0 {
+					row(label("receipt.change"), p.Change)
+				}
+			}
+		} else {
+			row(paymentLabel(language, sale.PaymentMethod), sale.FinalAmount+sale.DepositAmount+sale.CashRounding)
+		}
+	}
+
+	if tpl.Fields.Notes && sale.Notes != "" {
+		divider()
+		line(sale.Notes)
+	}
+
+	if len(tpl.Footer) > 0 {
+		divider()
+		buf.Write(escAlignCenter)
+		for _, f := range tpl.Footer {
+			line(f)
+		}
+	}
+
+	buf.Write(escFeedAndCut)
+
+	return buf.Bytes(), nil
+}
+
+func (r *receiptRenderer) RenderZReport(data Domain.ZReportData) ([]byte, error) {
+	if data.Template == nil {
+		return nil, fmt.Errorf("template is required")
+	}
+
+	cols := data.Template.PaperWidth.Columns()
+	language := printerLanguage(data.Language)
+	label := func(key string) string {
+		return Translate(language, key)
+	}
+
+	var buf bytes.Buffer
+	line := func(text string) {
+		buf.WriteString(escposText(text))
+		buf.WriteByte('\n')
+	}
+	divider := func() {
+		line(strings.Repeat("-", cols))
+	}
+	section := func(title string, lines []Domain.SalesBreakdownLine, label func(string) string) (Domain.Money, int) {
+		var amount Domain.Money
+		var transactions int
+		buf.Write(escBoldOn)
+		line(title)
+		buf.Write(escBoldOff)
+		for _, l := range lines {
+			line(padColumns(fmt.Sprintf("%s (%d)", label(l.Key), l.Transactions), l.Amount.String(), cols))
+			amount += l.Amount
+			transactions += l.Transactions
+		}
+		if len(lines) == 0 {
+			line(label("zreport.no_sales"))
+		}
+		return amount, transactions
+	}
+
+	buf.Write(escInit)
+	buf.Write(escAlignCenter)
+	if data.Business != nil {
+		buf.Write(escBoldOn)
+		line(data.Business.Name)
+		buf.Write(escBoldOff)
+	}
+	buf.Write(escDoubleSize)
+	line(label("zreport.title"))
+	buf.Write(escNormalSize)
+	line(data.Date)
+
+	buf.Write(escAlignLeft)
+	divider()
+	total, transactions := section(label("zreport.payments"), data.Payments, func(key string) string {
+		return paymentLabel(language, Domain.PaymentMethod(key))
+	})
+	divider()
+	if len(data.Devices) > 1 {
+		section(label("zreport.tills"), data.Devices, func(key string) string {
+			if key == "" {
+				return label("zreport.unknown")
+			}
+			return key
+		})
+		divider()
+	}
+
+	line(padColumns(label("zreport.transactions"), fmt.Sprintf("%d", transactions), cols))
+	buf.Write(escBoldOn)
+	line(padColumns(label("receipt.total"), total.String(), cols))
+	buf.Write(escBoldOff)
+	if data.CashRounding != 0 {
+		line(padColumns(label("zreport.rounding"), data.CashRounding.String(), cols))
+	}
+	divider()
+
+	if len(data.Currencies) > 0 {
+		buf.Write(escBoldOn)
+		line(label("zreport.foreign_cash"))
+		buf.Write(escBoldOff)
+		for _, c := range data.Currencies {
+			line(padColumns(fmt.Sprintf("%s (%d)", c.Currency, c.Payments), c.ForeignAmount.String(), cols))
+			line(padColumns("  "+label("zreport.booked"), c.Amount.String(), cols))
+			if c.Change > 0 {
+				line(padColumns("  "+label("zreport.change_given"), c.Change.String(), cols))
+			}
+		}
+		divider()
+	}
+	line(label("zreport.printed") + ": " + data.Printed.Format("2006-01-02 15:04"))
+
+	buf.Write(escFeedAndCut)
+
+	return buf.Bytes(), nil
+}
+
+func (r *receiptRenderer) RenderBarcodeLabels(data Domain.BarcodeLabelData) ([]byte, error) {
+	if data.Template == nil || data.Product == nil {
+		return nil, fmt.Errorf("template and product are required")
+	}
+
+	code := data.Product.Barcode
+	if code == "" {
+		code = data.Product.SKU
+	}
+	barcode, err := escposBarcode(code)
+	if err != nil {
+		return nil, err
+	}
+
+	cols := data.Template.PaperWidth.Columns()
+	currency := ""
+	if data.Business != nil {
+		currency = data.Business.Currency + " "
+	}
+	copies := max(data.Copies, 1)
+
+	var buf bytes.Buffer
+	buf.Write(escInit)
+	for i := 0; i < copies; i++ {
+		buf.Write(escAlignCenter)
+		buf.Write(escBoldOn)
+		name := escposText(data.Product.Name)
+		if len(name) > cols {
+			name = name[:cols]
+		}
+		buf.WriteString(name)
+		buf.WriteByte('\n')
+		buf.Write(escBoldOff)
+		buf.Write(escDoubleSize)
+		buf.WriteString(escposText(currency + data.Product.SellingPrice.String()))
+		buf.WriteByte('\n')
+		buf.Write(escNormalSize)
+		buf.Write(escBarcodeSize)
+		buf.Write(escBarcodeHRI)
+		buf.Write(barcode)
+		buf.Write(escFeedAndCut)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// escposBarcode is the GS k command printing code: EAN-13 for 12 or 13 digits, as
+// on most packaged goods, and Code 128 for anything else
+func escposBarcode(code string) ([]byte, error) {
+	if code == "" {
+		return nil, fmt.Errorf("product has no barcode or SKU to print")
+	}
+	for _, r := range code {
+		if r < 0x20 || r >= 0x7F {
+			return nil, fmt.Errorf("barcode %q has characters a printer cannot encode", code)
+		}
+	}
+
+	if (len(code) == 12 || len(code) == 13) && strings.Trim(code, "0123456789") == "" {
+		out := []byte{0x1D, 0x6B, 0x43, byte(len(code))} // GS k 67: EAN-13
+		return append(out, code...), nil
+	}
+
+	// GS k 73: Code 128, starting in code set B; a literal { is escaped as {{
+	data := []byte("{B")
+	for i := 0; i < len(code); i++ {
+		data = append(data, code[i])
+		if code[i] == '{' {
+			data = append(data, '{')
+		}
+	}
+	if len(data) > 255 {
+		return nil, fmt.Errorf("barcode %q is too long", code)
+	}
+	out := []byte{0x1D, 0x6B, 0x49, byte(len(data))}
+	return append(out, data...), nil
+}
+
+func (r *receiptRenderer) RenderHTML(data Domain.ReceiptData) ([]byte, error) {
+	if data.Template == nil || data.Sale == nil {
+		return nil, fmt.Errorf("template and sale are required")
+	}
+
+	business := data.Business
+	if business == nil {
+		business = &Domain.Business{}
+	}
+
+	language := Domain.LanguageOr(data.Language, Domain.DefaultLanguage)
+	item := data.ProductName
+	if item == "" {
+		item = Translate(language, "receipt.item")
+	}
+
+	payments := data.Sale.Payments
+	if len(payments) == 0 {
+		payments = []Domain.SalePayment{{Method: data.Sale.PaymentMethod, Amount: data.Sale.FinalAmount + data.Sale.DepositAmount + data.Sale.CashRounding}}
+	}
+
+	view := map[string]interface{}{
+		"T":        data.Template,
+		"Business": business,
+		"Address":  joinNonEmpty(", ", business.Address, business.City),
+		"Sale":     data.Sale,
+		"SaleID":   data.Sale.ID.Hex(),
+		"Date":     data.Sale.CreatedAt.Format("2006-01-02 15:04"),
+		"Item":     item,
+		"Payments": payments,
+		"CashDue":  data.Sale.FinalAmount + data.Sale.DepositAmount + data.Sale.CashRounding,
+		"Width":    int(data.Template.PaperWidth),
+		"Lang":     string(language),
+		"L":        receiptLabels(language),
+		"PaymentLabel": func(method Domain.PaymentMethod) string {
+			return paymentLabel(language, method)
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := r.htmlTemplate.Execute(&buf, view); err != nil {
+		return nil, fmt.Errorf("failed to render receipt: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// padColumns right-aligns value on a line of the given width, truncating the label if needed
+func padColumns(label, value string, cols int) string {
+	space := cols - len(value) - 1
+	if space < 1 {
+		return value
+	}
+	if len(label) > space {
+		label = label[:space]
+	}
+	return label + strings.Repeat(" ", cols-len(label)-len(value)) + value
+}
+
+// escposText keeps printable ASCII only; most receipt printers ship with a
+// single-byte code page and print garbage for anything else
+func escposText(s string) string {
+	var sb strings.Builder
+	for _, r := range s {
+		if r >= 0x20 && r < 0x7F {
+			sb.WriteRune(r)
+		} else {
+			sb.WriteByte('?')
+		}
+	}
+	return sb.String()
+}
+
+// receiptLabels are the HTML receipt's labels in language, by the last part of
+// their key
+func receiptLabels(language Domain.Language) map[string]string {
+	labels := make(map[string]string)
+	for _, name := range []string{"tel", "receipt", "date", "customer", "discount", "tax", "total", "rounding", "cash_due", "deposit", "amount_due", "change"} {
+		labels[name] = Translate(language, "receipt."+name)
+	}
+	return labels
+}
+
+// paymentLabel names a payment method in language, or by its code when the
+// catalog has no name for it
+func paymentLabel(language Domain.Language, method Domain.PaymentMethod) string {
+	if label, ok := lookupMessage(language, "payment."+string(method)); ok {
+		return label
+	}
+	label := strings.ReplaceAll(string(method), "_", " ")
+	if label == "" {
+		return Translate(language, "receipt.paid")
+	}
+	return strings.ToUpper(label[:1]) + label[1:]
+}
+
+func joinNonEmpty(sep string, parts ...string) string {
+	var out []string
+	for _, p := range parts {
+		if p != "" {
+			out = append(out, p)
+		}
+	}
+	return strings.Join(out, sep)
+}
+
+const receiptHTML = `<!DOCTYPE html>
+<html lang="{{.Lang}}">
+<head>
+<meta charset="utf-8">
+<title>{{.L.receipt}} {{.SaleID}}</title>
+<style>
+  @page { size: {{.Width}}mm auto; margin: 0; }
+  body { width: {{.Width}}mm; margin: 0 auto; padding: 4mm; box-sizing: border-box; font-family: monospace; font-size: 12px; }
+  .center { text-align: center; }
+  .name { font-size: 18px; font-weight: bold; }
+  .logo { max-width: 60%; }
+  hr { border: 0; border-top: 1px dashed #000; }
+  table { width: 100%; border-collapse: collapse; }
+  td.amount { text-align: right; }
+  tr.total td { font-weight: bold; }
+</style>
+</head>
+<body>
+<div class="center">
+  {{if .T.LogoURL}}<img class="logo" src="{{.T.LogoURL}}" alt="">{{end}}
+  {{if .T.Fields.BusinessName}}<div class="name">{{.Business.Name}}</div>{{end}}
+  {{if and .T.Fields.Address .Address}}<div>{{.Address}}</div>{{end}}
+  {{if and .T.Fields.Phone .Business.Phone}}<div>{{.L.tel}}: {{.Business.Phone}}</div>{{end}}
+  {{range .T.Header}}<div>{{.}}</div>{{end}}
+</div>
+<hr>
+{{if .T.Fields.SaleID}}<div>{{.L.receipt}}: {{.SaleID}}</div>{{end}}
+{{if .T.Fields.Date}}<div>{{.L.date}}: {{.Date}}</div>{{end}}
+{{if and .T.Fields.Customer .Sale.CustomerName}}<div>{{.L.customer}}: {{.Sale.CustomerName}}</div>{{end}}
+<hr>
+<table>
+  <tr><td colspan="2">{{.Item}}</td></tr>
+  <tr><td>&nbsp;&nbsp;{{printf "%.2f" .Sale.Quantity}} x {{.Sale.UnitPrice}}</td><td class="amount">{{.Sale.TotalAmount}}</td></tr>
+</table>
+<hr>
+<table>
+  {{if and .T.Fields.Discount (gt .Sale.Discount 0)}}<tr><td>{{.L.discount}}</td><td class="amount">-{{.Sale.Discount}}</td></tr>{{end}}
+  {{if and .T.Fields.Tax (gt .Sale.Tax 0)}}<tr><td>{{.L.tax}}</td><td class="amount">{{.Sale.Tax}}</td></tr>{{end}}
+  <tr class="total"><td>{{.L.total}}</td><td class="amount">{{.Sale.FinalAmount}}</td></tr>
+  {{range .Sale.Deposits}}<tr><td>{{$.L.deposit}} {{.Quantity}} x {{.Name}}</td><td class="amount">{{.Total}}</td></tr>{{end}}
+  {{if .Sale.CashRounding}}<tr><td>{{.L.rounding}}</td><td class="amount">{{.Sale.CashRounding}}</td></tr><tr><td>{{.L.cash_due}}</td><td class="amount">{{.CashDue}}</td></tr>{{else if .Sale.DepositAmount}}<tr><td>{{.L.amount_due}}</td><td class="amount">{{.CashDue}}</td></tr>{{end}}
+  {{if .T.Fields.PaymentBreakdown}}{{range .Payments}}<tr><td>{{call $.PaymentLabel .Method}}</td><td class="amount">{{.Amount}}</td></tr>{{if .Currency}}<tr><td>&nbsp;&nbsp;{{.Currency}} {{.ForeignAmount}} @ {{.ExchangeRate}}</td><td></td></tr>{{end}}{{if .Change}}<tr><td>{{$.L.change}}</td><td class="amount">{{.Change}}</td></tr>{{end}}{{end}}{{end}}
+</table>
+{{if and .T.Fields.Notes .Sale.Notes}}<hr><div>{{.Sale.Notes}}</div>{{end}}
+{{if .T.Footer}}<hr><div class="center">{{range .T.Footer}}<div>{{.}}</div>{{end}}</div>{{end}}
+</body>
+</html>
+`
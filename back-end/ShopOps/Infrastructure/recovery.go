@@ -0,0 +1,63 @@
+package Infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"syscall"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Recovery turns a panic in a handler into a 500 with an error ID the user can quote
+// to support, and reports it with the shop, user and request it happened on
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			r := recover()
+			if r == nil {
+				return
+			}
+
+			// The client went away mid-response; there is nobody to answer and nothing to fix
+			if err, ok := r.(error); ok && (errors.Is(err, syscall.EPIPE) || errors.Is(err, syscall.ECONNRESET)) {
+				c.Abort()
+				return
+			}
+
+			route := c.FullPath()
+			if route == "" {
+				route = "unmatched"
+			}
+			tags := map[string]string{
+				"method": c.Request.Method,
+				"route":  route,
+			}
+			if requestID := c.GetString("requestID"); requestID != "" {
+				tags["request_id"] = requestID
+			}
+			if businessID := c.Param("businessId"); businessID != "" {
+				tags["business_id"] = businessID
+			}
+			userID := ""
+			if id, exists := c.Get("userID"); exists {
+				userID = fmt.Sprint(id)
+			}
+
+			errorID := ReportPanic(c.Request.Context(), r, tags, userID)
+			SpanFrom(c.Request.Context()).RecordError(fmt.Errorf("panic: %s", redactPanic(r)))
+
+			if c.Writer.Written() {
+				c.Abort()
+				return
+			}
+			body := errorBody(c, Domain.NewAppError(Domain.ErrCodeInternal, "Internal server error"))
+			body.ErrorID = errorID
+			c.AbortWithStatusJSON(http.StatusInternalServerError, body)
+		}()
+
+		c.Next()
+	}
+}
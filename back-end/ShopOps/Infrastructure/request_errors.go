@@ -0,0 +1,82 @@
+package Infrastructure
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Failed requests made against a shop are kept for the support team, so they can
+// see what the shop ran into without asking for screenshots. They are written in
+// the background and dropped rather than slowing requests when the writer is behind.
+
+const requestErrorQueueSize = 1024
+
+var requestErrorQueue chan *Domain.RequestError
+
+var RequestErrorsDropped = NewCounterVec("shopops_request_errors_dropped_total",
+	"Failed requests not recorded for support because the writer was behind")
+
+// RecordRequestErrors starts writing failed shop requests to repo. Call it once at
+// startup; until then nothing is recorded.
+func RecordRequestErrors(repo Domain.RequestErrorRepository) {
+	queue := make(chan *Domain.RequestError, requestErrorQueueSize)
+	go func() {
+		for requestError := range queue {
+			if err := repo.Create(requestError); err != nil {
+				Logger.Warn("failed to record request error", slog.String("error", err.Error()))
+			}
+		}
+	}()
+	requestErrorQueue = queue
+}
+
+// recordRequestError queues the request for support if it failed and belongs to a
+// shop. Expected failures, such as expired sessions, unknown routes and rate limits,
+// are not worth keeping.
+func recordRequestError(c *gin.Context, status int) {
+	if requestErrorQueue == nil || status < 400 {
+		return
+	}
+	switch status {
+	case http.StatusUnauthorized, http.StatusNotFound, http.StatusTooManyRequests:
+		return
+	}
+	businessID, err := primitive.ObjectIDFromHex(c.Param("businessId"))
+	if err != nil {
+		return
+	}
+
+	route := c.FullPath()
+	if route == "" {
+		route = c.Request.URL.Path
+	}
+	requestError := &Domain.RequestError{
+		BusinessID: businessID,
+		RequestID:  c.GetString("requestID"),
+		Method:     c.Request.Method,
+		Route:      route,
+		Status:     status,
+		Code:       Domain.ErrorCode(c.GetString("errorCode")),
+		Message:    RedactPII(c.GetString("errorMessage")),
+		DeviceID:   requestDeviceID(c),
+		CreatedAt:  time.Now(),
+	}
+	if id, exists := c.Get("userID"); exists {
+		if userID, err := primitive.ObjectIDFromHex(fmt.Sprint(id)); err == nil {
+			requestError.UserID = &userID
+		}
+	}
+
+	select {
+	case requestErrorQueue <- requestError:
+	default:
+		RequestErrorsDropped.Inc()
+	}
+}
@@ -1,20 +1,151 @@
 package Infrastructure
 
 import (
-	"log"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	Domain "ShopOps/Domain"
 
 	"github.com/gin-gonic/gin"
 )
 
-// JSONError logs the error and sends a JSON error response with given status.
-// If err is non-nil the error's message is used; otherwise msg is used.
+// ErrorResponse is the body of every error response. Error stays the plain message
+// older clients already show, in the request's language; Code is what clients
+// should branch on.
+type ErrorResponse struct {
+	Error      string               `json:"error"`
+	Code       Domain.ErrorCode     `json:"code"`
+	MessageKey string               `json:"message_key"`
+	Details    []Domain.ErrorDetail `json:"details,omitempty"`
+	RequestID  string               `json:"request_id,omitempty"`
+	ErrorID    string               `json:"error_id,omitempty"` // Set for crashes; quote it to support
+}
+
+// HTTPStatus maps an error code to its response status
+func HTTPStatus(code Domain.ErrorCode) int {
+	switch code {
+	case Domain.ErrCodeInvalidArgument, Domain.ErrCodeValidationFailed, Domain.ErrCodeBadRequest:
+		return http.StatusBadRequest
+	case Domain.ErrCodeUnauthorized:
+		return http.StatusUnauthorized
+	case Domain.ErrCodeAccessDenied:
+		return http.StatusForbidden
+	case Domain.ErrCodeNotFound:
+		return http.StatusNotFound
+	case Domain.ErrCodeConflict:
+		return http.StatusConflict
+	case Domain.ErrCodePaymentRequired:
+		return http.StatusPaymentRequired
+	case Domain.ErrCodePayloadTooLarge:
+		return http.StatusRequestEntityTooLarge
+	case Domain.ErrCodeRateLimited:
+		return http.StatusTooManyRequests
+	case Domain.ErrCodeUnavailable, Domain.ErrCodeMaintenance:
+		return http.StatusServiceUnavailable
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+// JSONError logs the error and sends it in the standard error body. An *Domain.AppError
+// anywhere in err's chain sets the code and status, binding failures list the invalid
+// fields and a body over its size limit is a 413; other errors keep the given status
+// and are classified from it and their message. If err is nil, msg is used.
 func JSONError(ctx *gin.Context, status int, err error, msg string) {
-	if err != nil {
-		log.Printf("ERROR status=%d method=%s path=%s err=%v", status, ctx.Request.Method, ctx.Request.URL.Path, err)
-		ctx.JSON(status, gin.H{"error": err.Error()})
-		return
+	var appErr *Domain.AppError
+	var maxBytesErr *http.MaxBytesError
+	if validationErr, ok := validationAppError(err); ok {
+		status = http.StatusBadRequest
+		appErr = validationErr
+	} else if errors.As(err, &maxBytesErr) {
+		status = http.StatusRequestEntityTooLarge
+		appErr = PayloadTooLargeError(maxBytesErr.Limit)
+	} else if err != nil && errors.As(err, &appErr) {
+		status = HTTPStatus(appErr.Code)
+		// Keep the context the usecase wrapped around it
+		appErr = &Domain.AppError{Code: appErr.Code, Message: err.Error(), Key: appErr.Key, Details: appErr.Details}
+	} else {
+		if err != nil {
+			msg = err.Error()
+		}
+		appErr = &Domain.AppError{Code: classifyError(status, err, msg), Message: msg}
+	}
+
+	logger := RequestLog(ctx).With(
+		slog.Int("status", status),
+		slog.String("method", ctx.Request.Method),
+		slog.String("path", ctx.Request.URL.Path),
+		slog.String("code", string(appErr.Code)),
+	)
+	logger.Error("request failed", slog.String("error", appErr.Message))
+
+	ctx.JSON(status, errorBody(ctx, appErr))
+}
+
+// AbortWithError sends appErr in the standard error body and stops the handler chain.
+// Middleware uses it to reject a request.
+func AbortWithError(ctx *gin.Context, appErr *Domain.AppError) {
+	ctx.AbortWithStatusJSON(HTTPStatus(appErr.Code), errorBody(ctx, appErr))
+}
+
+func errorBody(ctx *gin.Context, appErr *Domain.AppError) ErrorResponse {
+	// Kept for the request log and the support team's view of failed requests
+	ctx.Set("errorCode", string(appErr.Code))
+	ctx.Set("errorMessage", appErr.Error())
+
+	message, details := localizeError(RequestLanguage(ctx), appErr)
+	return ErrorResponse{
+		Error:      message,
+		Code:       appErr.Code,
+		MessageKey: appErr.MessageKey(),
+		Details:    details,
+		RequestID:  ctx.GetString("requestID"),
+	}
+}
+
+// classifyError picks a code for errors that are not AppErrors, following the
+// conventions usecases use in their messages
+func classifyError(status int, err error, msg string) Domain.ErrorCode {
+	switch {
+	case status == http.StatusUnauthorized:
+		return Domain.ErrCodeUnauthorized
+	case status == http.StatusForbidden:
+		return Domain.ErrCodeAccessDenied
+	case status == http.StatusNotFound:
+		return Domain.ErrCodeNotFound
+	case status == http.StatusConflict:
+		return Domain.ErrCodeConflict
+	case status == http.StatusPaymentRequired:
+		return Domain.ErrCodePaymentRequired
+	case status == http.StatusRequestEntityTooLarge:
+		return Domain.ErrCodePayloadTooLarge
+	case status == http.StatusTooManyRequests:
+		return Domain.ErrCodeRateLimited
+	case status == http.StatusServiceUnavailable:
+		return Domain.ErrCodeUnavailable
+	case status >= 500:
+		return Domain.ErrCodeInternal
+	}
+
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) || errors.As(err, &typeErr) {
+		return Domain.ErrCodeInvalidArgument
+	}
+
+	lower := strings.ToLower(msg)
+	switch {
+	case strings.Contains(lower, "not found"):
+		return Domain.ErrCodeNotFound
+	case strings.HasPrefix(lower, "access denied"):
+		return Domain.ErrCodeAccessDenied
+	case strings.HasPrefix(lower, "invalid "), strings.Contains(lower, " is required"):
+		return Domain.ErrCodeInvalidArgument
+	case strings.Contains(lower, "already exists"):
+		return Domain.ErrCodeConflict
 	}
-	log.Printf("ERROR status=%d method=%s path=%s message=%s", status, ctx.Request.Method, ctx.Request.URL.Path, msg)
-	ctx.JSON(status, gin.H{"error": msg})
-	return
+	return Domain.ErrCodeBadRequest
 }
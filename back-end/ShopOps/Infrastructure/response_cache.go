@@ -0,0 +1,261 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ResponseCache keeps the bodies of expensive GET responses, such as the catalog and
+// reports, in memory. Each cached route declares the tags its data comes from, and
+// write routes declare the tags they change; a successful write drops every cached
+// response with those tags for that shop. Entries are per user, since access to a
+// shop is checked by the handler the cache skips.
+//
+// Like TTLCache it is per process, so on a multi-instance deployment another
+// instance can serve a response up to its TTL old.
+type ResponseCache struct {
+	maxEntries int
+
+	mu          sync.Mutex
+	entries     map[string]*cachedResponse
+	tagged      map[string]map[string]struct{} // Shop and tag to the keys cached under it
+	generations map[string]uint64              // Shop and tag to its invalidation count
+}
+
+type cachedResponse struct {
+	status    int
+	header    http.Header
+	body      []byte
+	tags      []string // Shop-scoped
+	expiresAt time.Time
+}
+
+// Tags for the data cached responses are built from
+const (
+	CacheTagCatalog  = "catalog" // Products and their prices
+	CacheTagStock    = "stock"
+	CacheTagSales    = "sales"
+	CacheTagExpenses = "expenses"
+)
+
+// Response headers replayed on a hit
+var cachedHeaders = []string{"Content-Type", "Content-Disposition", "Content-Language", "X-Next-Cursor", "X-Total-Count"}
+
+var ResponseCacheRequests = NewCounterVec("shopops_response_cache_requests_total",
+	"Cacheable requests by route and result (hit, miss, bypass)", "route", "result")
+
+func NewResponseCache(maxEntries int) *ResponseCache {
+	return &ResponseCache{
+		maxEntries:  maxEntries,
+		entries:     make(map[string]*cachedResponse),
+		tagged:      make(map[string]map[string]struct{}),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Cached serves the route from the cache for ttl after a successful response. A
+// zero ttl turns caching off for the route. Requests sent with Cache-Control:
+// no-cache skip the cached copy and refresh it.
+func (rc *ResponseCache) Cached(ttl time.Duration, tags ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if ttl <= 0 || c.Request.Method != http.MethodGet {
+			c.Next()
+			return
+		}
+
+		route := c.FullPath()
+		key := rc.key(c)
+		scoped := scopeTags(c.Param("businessId"), tags)
+
+		if strings.Contains(c.GetHeader("Cache-Control"), "no-cache") {
+			ResponseCacheRequests.Inc(route, "bypass")
+		} else if cached, ok := rc.get(key); ok {
+			ResponseCacheRequests.Inc(route, "hit")
+			for name, values := range cached.header {
+				c.Writer.Header()[name] = values
+			}
+			c.Header("X-Cache", "HIT")
+			c.Data(cached.status, cached.header.Get("Content-Type"), cached.body)
+			c.Abort()
+			return
+		} else {
+			ResponseCacheRequests.Inc(route, "miss")
+		}
+
+		generations := rc.generationsOf(scoped)
+		writer := &cachingWriter{ResponseWriter: c.Writer}
+		c.Writer = writer
+		c.Header("X-Cache", "MISS")
+		c.Next()
+
+		if writer.Status() != http.StatusOK || c.IsAborted() || writer.Header().Get("Set-Cookie") != "" {
+			return
+		}
+		header := http.Header{}
+		for _, name := range cachedHeaders {
+			if value := writer.Header().Get(name); value != "" {
+				header.Set(name, value)
+			}
+		}
+		rc.set(key, &cachedResponse{
+			status:    writer.Status(),
+			header:    header,
+			body:      writer.body.Bytes(),
+			tags:      scoped,
+			expiresAt: time.Now().Add(ttl),
+		}, generations)
+	}
+}
+
+// Invalidates drops the shop's cached responses with any of tags once a write on the
+// route succeeds. Reads on the route pass through untouched, so it can be applied to
+// a whole route group.
+func (rc *ResponseCache) Invalidates(tags ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		rc.Invalidate(c.Param("businessId"), tags...)
+	}
+}
+
+// Invalidate drops the shop's cached responses with any of tags, for writes made
+// outside a request
+func (rc *ResponseCache) Invalidate(businessID string, tags ...string) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for _, tag := range scopeTags(businessID, tags) {
+		rc.generations[tag]++
+		for key := range rc.tagged[tag] {
+			rc.deleteLocked(key)
+		}
+		delete(rc.tagged, tag)
+	}
+}
+
+func (rc *ResponseCache) key(c *gin.Context) string {
+	userID, _ := c.Get("userID")
+	// Encode sorts the parameters, so their order in the URL does not matter
+	return fmt.Sprintf("%v|%s|%s?%s", userID, RequestLanguage(c), c.Request.URL.Path, c.Request.URL.Query().Encode())
+}
+
+func (rc *ResponseCache) get(key string) (*cachedResponse, bool) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	cached, ok := rc.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if time.Now().After(cached.expiresAt) {
+		rc.deleteLocked(key)
+		return nil, false
+	}
+	return cached, true
+}
+
+func (rc *ResponseCache) generationsOf(tags []string) []uint64 {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	generations := make([]uint64, len(tags))
+	for i, tag := range tags {
+		generations[i] = rc.generations[tag]
+	}
+	return generations
+}
+
+// set stores the response unless one of its tags was invalidated while it was being
+// built, in which case it may already be stale
+func (rc *ResponseCache) set(key string, cached *cachedResponse, generations []uint64) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	for i, tag := range cached.tags {
+		if rc.generations[tag] != generations[i] {
+			return
+		}
+	}
+
+	if _, exists := rc.entries[key]; !exists && len(rc.entries) >= rc.maxEntries {
+		rc.evictLocked()
+	}
+	rc.deleteLocked(key)
+	rc.entries[key] = cached
+	for _, tag := range cached.tags {
+		if rc.tagged[tag] == nil {
+			rc.tagged[tag] = make(map[string]struct{})
+		}
+		rc.tagged[tag][key] = struct{}{}
+	}
+}
+
+// evictLocked makes room by dropping expired entries, or the one closest to expiring
+// if none have
+func (rc *ResponseCache) evictLocked() {
+	now := time.Now()
+	oldestKey := ""
+	var oldest time.Time
+	for key, cached := range rc.entries {
+		if now.After(cached.expiresAt) {
+			rc.deleteLocked(key)
+			continue
+		}
+		if oldestKey == "" || cached.expiresAt.Before(oldest) {
+			oldestKey, oldest = key, cached.expiresAt
+		}
+	}
+	if len(rc.entries) >= rc.maxEntries && oldestKey != "" {
+		rc.deleteLocked(oldestKey)
+	}
+}
+
+func (rc *ResponseCache) deleteLocked(key string) {
+	cached, ok := rc.entries[key]
+	if !ok {
+		return
+	}
+	delete(rc.entries, key)
+	for _, tag := range cached.tags {
+		delete(rc.tagged[tag], key)
+		if len(rc.tagged[tag]) == 0 {
+			delete(rc.tagged, tag)
+		}
+	}
+}
+
+func scopeTags(businessID string, tags []string) []string {
+	scoped := make([]string, len(tags))
+	for i, tag := range tags {
+		scoped[i] = businessID + "|" + tag
+	}
+	return scoped
+}
+
+// cachingWriter copies the response body as it is written
+type cachingWriter struct {
+	gin.ResponseWriter
+	body bytes.Buffer
+}
+
+func (w *cachingWriter) Write(data []byte) (int, error) {
+	w.body.Write(data)
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *cachingWriter) WriteString(s string) (int, error) {
+	w.body.WriteString(s)
+	return w.ResponseWriter.WriteString(s)
+}
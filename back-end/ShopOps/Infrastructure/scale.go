@@ -0,0 +1,77 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	Domain "ShopOps/Domain"
+)
+
+// WritePLUFile lays out the shop's PLU products for loading into its scales:
+// PLU, name cut to the label, unit price, and whether the item is sold by weight
+func WritePLUFile(settings Domain.ScaleSettings, products []Domain.Product) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+	if settings.ExportFormat == Domain.ScaleExportTXT {
+		writer.Comma = '\t'
+	} else {
+		if err := writer.Write([]string{"PLU", "Name", "Unit Price", "Sold By", "Barcode"}); err != nil {
+			return nil, fmt.Errorf("failed to write PLU header: %w", err)
+		}
+	}
+
+	for _, p := range products {
+		soldBy := "unit"
+		if p.Weighed {
+			soldBy = "kg"
+		}
+
+		record := []string{
+			leftPad(p.PLU, settings.ItemDigits),
+			labelName(p.Name, settings.NameLength),
+			p.SellingPrice.String(),
+			soldBy,
+			p.Barcode,
+		}
+		if err := writer.Write(record); err != nil {
+			return nil, fmt.Errorf("failed to write PLU record: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to flush PLU file: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// leftPad zero-pads a PLU to the width the scale prints it at, which is also
+// how the POS reads it back
+func leftPad(plu string, width int) string {
+	if len(plu) >= width {
+		return plu
+	}
+	return strings.Repeat("0", width-len(plu)) + plu
+}
+
+// labelName fits a name on the scale's label; scales print plain ASCII only
+func labelName(name string, length int) string {
+	var b strings.Builder
+	for _, r := range name {
+		if b.Len() >= length {
+			break
+		}
+		switch {
+		case r == '\t' || r == '\n' || r == '\r':
+			b.WriteByte(' ')
+		case r < 0x80:
+			b.WriteRune(r)
+		default:
+			b.WriteByte('?')
+		}
+	}
+	return strings.TrimSpace(b.String())
+}
@@ -0,0 +1,144 @@
+package Infrastructure
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JobStatus is the outcome of a periodic job's latest run
+type JobStatus struct {
+	Name      string        `json:"name"`
+	Interval  time.Duration `json:"interval"`
+	LastStart time.Time     `json:"last_start"`
+	LastEnd   time.Time     `json:"last_end"`
+	LastError string        `json:"last_error,omitempty"`
+	Running   bool          `json:"running"`
+}
+
+var (
+	jobsMu sync.Mutex
+	jobs   = make(map[string]*JobStatus)
+)
+
+// RunPeriodically runs job once immediately and then every interval in a
+// background goroutine. Errors are logged and the job keeps running. Each run
+// is logged under its own run ID. No new runs start once shutdown begins, and
+// shutdown waits for a run in progress.
+func RunPeriodically(name string, interval time.Duration, job func() error) {
+	jobsMu.Lock()
+	jobs[name] = &JobStatus{Name: name, Interval: interval}
+	jobsMu.Unlock()
+
+	go func() {
+		run := func() {
+			defer trackWork("job:" + name)()
+
+			start := time.Now()
+			recordJobStart(name, start)
+			var runErr error
+			defer func() { recordJobEnd(name, runErr) }()
+
+			ctx, span := StartSpan(context.Background(), "job."+name, SpanKindInternal)
+			defer span.End()
+
+			logger := Logger.With(slog.String("job", name), slog.String("run_id", NewRequestID()))
+			if span != nil {
+				logger = logger.With(slog.String("trace_id", span.TraceID()))
+			}
+			defer func() {
+				if r := recover(); r != nil {
+					errorID := ReportPanic(WithLogger(ctx, logger), r, map[string]string{"job": name}, "")
+					JobDuration.ObserveSince(start, name, "panic")
+					runErr = fmt.Errorf("panic, error ID %s", errorID)
+					span.RecordError(runErr)
+				}
+			}()
+			if err := job(); err != nil {
+				runErr = err
+				logger.Error("job failed", slog.String("error", err.Error()), slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+				JobDuration.ObserveSince(start, name, "failure")
+				span.RecordError(err)
+				return
+			}
+			JobDuration.ObserveSince(start, name, "success")
+			logger.Debug("job finished", slog.Int64("duration_ms", time.Since(start).Milliseconds()))
+		}
+
+		if IsShuttingDown() {
+			return
+		}
+		run()
+
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				if IsShuttingDown() {
+					return
+				}
+				run()
+			case <-ShuttingDown():
+				return
+			}
+		}
+	}()
+}
+
+func recordJobStart(name string, at time.Time) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	status := jobs[name]
+	status.LastStart = at
+	status.Running = true
+}
+
+func recordJobEnd(name string, err error) {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+	status := jobs[name]
+	status.LastEnd = time.Now()
+	status.Running = false
+	status.LastError = ""
+	if err != nil {
+		status.LastError = err.Error()
+	}
+}
+
+// JobStatuses lists every periodic job by name
+func JobStatuses() []JobStatus {
+	jobsMu.Lock()
+	defer jobsMu.Unlock()
+
+	statuses := make([]JobStatus, 0, len(jobs))
+	for _, status := range jobs {
+		statuses = append(statuses, *status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// CheckJobs fails when a periodic job has not completed a run for two intervals,
+// which means it is stuck or its goroutine died
+func CheckJobs(ctx context.Context) error {
+	now := time.Now()
+	var stalled []string
+	for _, status := range JobStatuses() {
+		last := status.LastEnd
+		if last.IsZero() {
+			last = status.LastStart
+		}
+		if !last.IsZero() && now.Sub(last) > 2*status.Interval+time.Minute {
+			stalled = append(stalled, status.Name)
+		}
+	}
+	if len(stalled) > 0 {
+		return fmt.Errorf("jobs stalled: %s", strings.Join(stalled, ", "))
+	}
+	return nil
+}
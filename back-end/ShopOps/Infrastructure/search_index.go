@@ -0,0 +1,293 @@
+package Infrastructure
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SearchIndex answers as-you-type searches over a shop's products and customers from
+// memory. Each shop's index is built on its first search from the loader the caller
+// passes, kept until a write to the shop's products or customers invalidates it or
+// it reaches its TTL, and dropped least recently used beyond maxShops.
+//
+// Like ResponseCache it is per process, so on a multi-instance deployment another
+// instance can find records up to its TTL old.
+type SearchIndex struct {
+	ttl      time.Duration
+	maxShops int
+
+	mu          sync.Mutex
+	shops       map[string]*shopIndex
+	generations map[string]uint64 // Shop to its invalidation count
+}
+
+// SearchLoader returns every document in a shop's index
+type SearchLoader func(businessID string) ([]Domain.SearchDocument, error)
+
+type shopIndex struct {
+	docs     []indexedDocument
+	postings map[string][]int32 // Trigram to the documents with a word containing it
+	builtAt  time.Time
+	usedAt   time.Time
+}
+
+type indexedDocument struct {
+	doc   Domain.SearchDocument
+	words []string
+}
+
+// Matches scoring below this are dropped; a single typo in a short word scores about 0.4
+const minSearchScore = 0.3
+
+var SearchIndexBuilds = NewCounterVec("shopops_search_index_builds_total",
+	"Shop search indexes built, by reason (cold, expired, invalidated)", "reason")
+
+func NewSearchIndex(ttl time.Duration, maxShops int) *SearchIndex {
+	return &SearchIndex{
+		ttl:         ttl,
+		maxShops:    maxShops,
+		shops:       make(map[string]*shopIndex),
+		generations: make(map[string]uint64),
+	}
+}
+
+// Search returns the shop's documents of the given kinds, or of any kind when none
+// are given, that match every word of query, best first
+func (si *SearchIndex) Search(businessID, query string, kinds []Domain.SearchKind, limit int, load SearchLoader) ([]Domain.SearchResult, error) {
+	words := NormalizeSearchText(query)
+	if len(words) == 0 {
+		return []Domain.SearchResult{}, nil
+	}
+
+	index, err := si.shop(businessID, load)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[Domain.SearchKind]bool, len(kinds))
+	for _, kind := range kinds {
+		wanted[kind] = true
+	}
+
+	results := []Domain.SearchResult{}
+	for _, i := range index.candidates(words) {
+		doc := index.docs[i]
+		if len(wanted) > 0 && !wanted[doc.doc.Kind] {
+			continue
+		}
+		score := matchScore(words, doc.words)
+		if score < minSearchScore {
+			continue
+		}
+		results = append(results, Domain.SearchResult{
+			Kind:     doc.doc.Kind,
+			ID:       doc.doc.ID,
+			Title:    doc.doc.Title,
+			Subtitle: doc.doc.Subtitle,
+			Score:    score,
+		})
+	}
+
+	sort.SliceStable(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Title < results[b].Title
+	})
+	if limit > 0 && len(results) > limit {
+		results = results[:limit]
+	}
+	return results, nil
+}
+
+// Invalidates drops the shop's index once a write on the route succeeds. Reads on the
+// route pass through untouched, so it can be applied to a whole route group.
+func (si *SearchIndex) Invalidates() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			return
+		}
+		if c.Writer.Status() >= http.StatusBadRequest {
+			return
+		}
+		si.Invalidate(c.Param("businessId"))
+	}
+}
+
+// Invalidate drops the shop's index, for writes made outside a request
+func (si *SearchIndex) Invalidate(businessID string) {
+	si.mu.Lock()
+	defer si.mu.Unlock()
+
+	si.generations[businessID]++
+	delete(si.shops, businessID)
+}
+
+func (si *SearchIndex) shop(businessID string, load SearchLoader) (*shopIndex, error) {
+	si.mu.Lock()
+	index, ok := si.shops[businessID]
+	generation := si.generations[businessID]
+	reason := "cold"
+	if ok && time.Since(index.builtAt) < si.ttl {
+		index.usedAt = time.Now()
+		si.mu.Unlock()
+		return index, nil
+	}
+	if ok {
+		reason = "expired"
+	} else if generation > 0 {
+		reason = "invalidated"
+	}
+	si.mu.Unlock()
+
+	docs, err := load(businessID)
+	if err != nil {
+		return nil, err
+	}
+	index = buildShopIndex(docs)
+	SearchIndexBuilds.Inc(reason)
+
+	si.mu.Lock()
+	defer si.mu.Unlock()
+	// An index built across an invalidation may be missing the write; use it for this
+	// search but leave it to be rebuilt
+	if si.generations[businessID] == generation {
+		if _, exists := si.shops[businessID]; !exists && len(si.shops) >= si.maxShops {
+			si.evictLocked()
+		}
+		si.shops[businessID] = index
+	}
+	return index, nil
+}
+
+// evictLocked drops the least recently searched shop
+func (si *SearchIndex) evictLocked() {
+	var oldest string
+	var oldestAt time.Time
+	for businessID, index := range si.shops {
+		if oldest == "" || index.usedAt.Before(oldestAt) {
+			oldest, oldestAt = businessID, index.usedAt
+		}
+	}
+	delete(si.shops, oldest)
+}
+
+func buildShopIndex(docs []Domain.SearchDocument) *shopIndex {
+	now := time.Now()
+	index := &shopIndex{
+		docs:     make([]indexedDocument, 0, len(docs)),
+		postings: make(map[string][]int32),
+		builtAt:  now,
+		usedAt:   now,
+	}
+
+	for _, doc := range docs {
+		words := NormalizeSearchText(doc.Title + " " + strings.Join(doc.Terms, " "))
+		if len(words) == 0 {
+			continue
+		}
+		i := int32(len(index.docs))
+		index.docs = append(index.docs, indexedDocument{doc: doc, words: words})
+
+		seen := make(map[string]bool)
+		for _, word := range words {
+			for _, gram := range trigrams(word) {
+				if !seen[gram] {
+					seen[gram] = true
+					index.postings[gram] = append(index.postings[gram], i)
+				}
+			}
+		}
+	}
+
+	return index
+}
+
+// candidates are the documents sharing a trigram with every query word; only they
+// can score above zero for all of them
+func (index *shopIndex) candidates(words []string) []int32 {
+	var result map[int32]bool
+	for _, word := range words {
+		matched := make(map[int32]bool)
+		for _, gram := range trigrams(word) {
+			for _, i := range index.postings[gram] {
+				if result == nil || result[i] {
+					matched[i] = true
+				}
+			}
+		}
+		result = matched
+		if len(result) == 0 {
+			return nil
+		}
+	}
+
+	ids := make([]int32, 0, len(result))
+	for i := range result {
+		ids = append(ids, i)
+	}
+	return ids
+}
+
+// matchScore is the average over query words of how well each matches its best word
+// in the document: 1 for a prefix, 0.8 inside a word, and trigram similarity,
+// scaled down, for a misspelling. A query word matching nothing scores the document 0.
+func matchScore(query, words []string) float64 {
+	total := 0.0
+	for _, q := range query {
+		best := 0.0
+		for _, word := range words {
+			var score float64
+			switch {
+			case strings.HasPrefix(word, q):
+				score = 1
+			case strings.Contains(word, q):
+				score = 0.8
+			default:
+				score = 0.7 * trigramSimilarity(q, word)
+			}
+			if score > best {
+				best = score
+			}
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return total / float64(len(query))
+}
+
+// trigramSimilarity is the share of the two words' trigrams they have in common
+func trigramSimilarity(a, b string) float64 {
+	gramsA := make(map[string]bool)
+	for _, gram := range trigrams(a) {
+		gramsA[gram] = true
+	}
+	shared, union := 0, len(gramsA)
+	seen := make(map[string]bool)
+	for _, gram := range trigrams(b) {
+		if seen[gram] {
+			continue
+		}
+		seen[gram] = true
+		if gramsA[gram] {
+			shared++
+		} else {
+			union++
+		}
+	}
+	if union == 0 {
+		return 0
+	}
+	return float64(shared) / float64(union)
+}
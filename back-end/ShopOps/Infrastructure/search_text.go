@@ -0,0 +1,91 @@
+package Infrastructure
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Shops name products in Amharic, in Latin letters, or in a mix of the two, and
+// staff search in whichever keyboard they have open. Search text is therefore
+// folded to one Latin spelling before indexing: Ethiopic syllables are
+// transliterated, letters the two scripts spell differently (ቀ as q or k, ጸ as ts or
+// tz) are merged, and doubled letters are collapsed, since Amharic does not write
+// gemination but Latin spellings often do.
+
+// ethiopicConsonants maps the first syllable of each Ethiopic row, whose eight
+// syllables share a consonant, to that consonant in Latin. Labialized rows (ቈ, ኰ,
+// ጐ) are spelled as the consonant followed by w.
+var ethiopicConsonants = map[rune]string{
+	'ሀ': "h", 'ለ': "l", 'ሐ': "h", 'መ': "m", 'ሠ': "s", 'ረ': "r", 'ሰ': "s", 'ሸ': "sh",
+	'ቀ': "k", 'ቈ': "kw", 'ቐ': "k", 'በ': "b", 'ቨ': "v", 'ተ': "t", 'ቸ': "ch",
+	'ኀ': "h", 'ኈ': "hw", 'ነ': "n", 'ኘ': "ny", 'አ': "", 'ከ': "k", 'ኰ': "kw", 'ኸ': "h",
+	'ወ': "w", 'ዐ': "", 'ዘ': "z", 'ዠ': "zh", 'የ': "y", 'ደ': "d", 'ጀ': "j", 'ገ': "g",
+	'ጐ': "gw", 'ጠ': "t", 'ጨ': "ch", 'ጰ': "p", 'ጸ': "ts", 'ፀ': "ts", 'ፈ': "f", 'ፐ': "p",
+}
+
+// ethiopicVowels are the vowels of the eight orders of a row. The sixth order is
+// usually silent, and the eighth adds w before its vowel.
+var ethiopicVowels = [8]string{"e", "u", "i", "a", "e", "", "o", "wa"}
+
+// latinFolds merge spellings Latin writers use for the same sound. ch is listed
+// first so that it is kept rather than read as c.
+var latinFolds = strings.NewReplacer("ch", "ch", "c", "k", "q", "k", "tz", "ts", "ph", "f", "ou", "u")
+
+// NormalizeSearchText folds s to the lowercase Latin words it is indexed and
+// searched by. Punctuation separates words; digits are kept, so phone numbers and
+// SKUs can be found by any part.
+func NormalizeSearchText(s string) []string {
+	var b strings.Builder
+	for _, r := range s {
+		if r >= 0x1200 && r <= 0x135A {
+			base := r - (r-0x1200)%8
+			if consonant, ok := ethiopicConsonants[base]; ok {
+				vowel := ethiopicVowels[(r-0x1200)%8]
+				if consonant == "" && vowel == "e" {
+					vowel = "a" // አበበ is written Abebe, not Ebebe
+				}
+				b.WriteString(consonant)
+				b.WriteString(vowel)
+				continue
+			}
+		}
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			b.WriteRune(unicode.ToLower(r))
+		case r == '\'':
+			// Apostrophes mark glottal stops in Latin spellings (ma'ed); drop them
+		default:
+			b.WriteByte(' ')
+		}
+	}
+
+	words := strings.Fields(latinFolds.Replace(b.String()))
+	for i, word := range words {
+		words[i] = collapseDoubled(word)
+	}
+	return words
+}
+
+func collapseDoubled(word string) string {
+	var b strings.Builder
+	var last rune
+	for i, r := range word {
+		if i > 0 && r == last && unicode.IsLetter(r) {
+			continue
+		}
+		b.WriteRune(r)
+		last = r
+	}
+	return b.String()
+}
+
+// trigrams are the three-letter windows of word, padded so that the start of a word
+// has windows of its own and a short prefix still matches
+func trigrams(word string) []string {
+	padded := []rune("  " + word + " ")
+	grams := make([]string, 0, len(padded)-2)
+	for i := 0; i+3 <= len(padded); i++ {
+		grams = append(grams, string(padded[i:i+3]))
+	}
+	return grams
+}
@@ -0,0 +1,79 @@
+package Infrastructure
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Shutdown is coordinated in two steps. BeginShutdown fails readiness and cancels the
+// context background work runs under, telling it to checkpoint and return; the HTTP
+// server is then drained, and WaitForBackground gives the work a deadline to finish.
+
+var (
+	shutdownCtx, cancelShutdown = context.WithCancel(context.Background())
+
+	backgroundWork sync.WaitGroup
+	runningMu      sync.Mutex
+	running        = make(map[string]int) // In-flight background work by name, for the shutdown log
+)
+
+// BeginShutdown signals every background task and job to wrap up
+func BeginShutdown() {
+	cancelShutdown()
+}
+
+// ShuttingDown is closed once shutdown has begun
+func ShuttingDown() <-chan struct{} {
+	return shutdownCtx.Done()
+}
+
+func IsShuttingDown() bool {
+	return shutdownCtx.Err() != nil
+}
+
+// trackWork registers in-flight background work; call the returned func when it ends
+func trackWork(name string) func() {
+	backgroundWork.Add(1)
+	runningMu.Lock()
+	running[name]++
+	runningMu.Unlock()
+
+	return func() {
+		runningMu.Lock()
+		if running[name]--; running[name] <= 0 {
+			delete(running, name)
+		}
+		runningMu.Unlock()
+		backgroundWork.Done()
+	}
+}
+
+// WaitForBackground waits for tracked background work to return, or for ctx to end,
+// in which case it reports what was still running
+func WaitForBackground(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		backgroundWork.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		runningMu.Lock()
+		defer runningMu.Unlock()
+		return fmt.Errorf("background work still running at deadline: %v", running)
+	}
+}
+
+// withShutdown returns a copy of ctx that is also cancelled when shutdown begins
+func withShutdown(ctx context.Context) (context.Context, context.CancelFunc) {
+	ctx, cancel := context.WithCancel(ctx)
+	stop := context.AfterFunc(shutdownCtx, cancel)
+	return ctx, func() {
+		stop()
+		cancel()
+	}
+}
@@ -0,0 +1,37 @@
+package Infrastructure
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"path"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ServeSignedFiles serves stored files from links made by FileStorage.SignURL and
+// nothing else, so a file's URL alone no longer fetches it
+func ServeSignedFiles(files FileStorage) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		file, err := files.OpenSigned(c.Request.URL.RequestURI())
+		if err != nil {
+			AbortWithError(c, Domain.AccessDeniedError("This link is invalid or has expired"))
+			return
+		}
+		defer file.Close()
+
+		name := path.Base(c.Request.URL.Path)
+		contentType := mime.TypeByExtension(path.Ext(name))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+		c.Header("Content-Type", contentType)
+		c.Header("Content-Disposition", "attachment; filename="+name)
+		c.Header("X-Content-Type-Options", "nosniff")
+		c.Header("Cache-Control", "private, no-store")
+		c.Status(http.StatusOK)
+		_, _ = io.Copy(c.Writer, file)
+	}
+}
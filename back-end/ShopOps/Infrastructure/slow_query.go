@@ -0,0 +1,171 @@
+package Infrastructure
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"log/slog"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/bsontype"
+	"go.mongodb.org/mongo-driver/event"
+)
+
+// Commands slower than MONGO_SLOW_QUERY_THRESHOLD (default 500ms, 0 disables) are
+// logged with a fingerprint: the command's shape with every value replaced by ?, so
+// the same query for different shops and dates groups under one ID in the logs.
+
+// Set by InitMongo once the environment is loaded
+var slowQueryThreshold time.Duration
+
+var SlowQueries = NewCounterVec("shopops_db_slow_queries_total",
+	"MongoDB commands slower than the slow query threshold", "command", "collection")
+
+// Longest shape written to the log; pipelines past this are cut
+const maxQueryShapeLength = 1024
+
+// Driver bookkeeping fields that say nothing about the query
+var ignoredCommandFields = map[string]bool{
+	"lsid": true, "$clusterTime": true, "$db": true, "txnNumber": true, "autocommit": true,
+	"startTransaction": true, "$readPreference": true, "readConcern": true, "writeConcern": true,
+	"cursor": true, "batchSize": true, "maxTimeMS": true, "comment": true, "ordered": true,
+}
+
+// Handshakes and heartbeats are never slow queries
+var unloggedCommands = map[string]bool{
+	"hello": true, "isMaster": true, "ismaster": true, "ping": true, "getMore": true,
+	"endSessions": true, "saslStart": true, "saslContinue": true, "killCursors": true,
+}
+
+type queryStart struct {
+	command    string
+	collection string
+	database   string
+	shape      string
+}
+
+// startedQuery captures what a slow query log needs while the command is still at hand
+func startedQuery(e *event.CommandStartedEvent) *queryStart {
+	if slowQueryThreshold <= 0 || unloggedCommands[e.CommandName] {
+		return nil
+	}
+	collection, _ := e.Command.Lookup(e.CommandName).StringValueOK()
+	return &queryStart{
+		command:    e.CommandName,
+		collection: collection,
+		database:   e.DatabaseName,
+		shape:      queryShape(e.Command),
+	}
+}
+
+// logSlowQuery logs the command if it took longer than the threshold
+func logSlowQuery(ctx context.Context, query *queryStart, duration time.Duration, failure string) {
+	if query == nil || duration < slowQueryThreshold {
+		return
+	}
+	SlowQueries.Inc(query.command, query.collection)
+
+	attrs := []any{
+		slog.String("command", query.command),
+		slog.String("collection", query.collection),
+		slog.String("database", query.database),
+		slog.Int64("duration_ms", duration.Milliseconds()),
+		slog.String("fingerprint", queryFingerprint(query.shape)),
+		slog.String("shape", query.shape),
+	}
+	if failure != "" {
+		attrs = append(attrs, slog.String("error", failure))
+	}
+	LoggerFrom(ctx).Warn("slow query", attrs...)
+}
+
+func queryFingerprint(shape string) string {
+	sum := sha1.Sum([]byte(shape))
+	return hex.EncodeToString(sum[:8])
+}
+
+// queryShape renders a command with values masked
+func queryShape(command bson.Raw) string {
+	var b strings.Builder
+	writeDocumentShape(&b, command, true)
+	shape := b.String()
+	if len(shape) > maxQueryShapeLength {
+		shape = shape[:maxQueryShapeLength] + "…"
+	}
+	return shape
+}
+
+func writeDocumentShape(b *strings.Builder, doc bson.Raw, topLevel bool) {
+	elements, err := doc.Elements()
+	if err != nil {
+		b.WriteString("?")
+		return
+	}
+
+	b.WriteString("{")
+	written := 0
+	for i, element := range elements {
+		key := element.Key()
+		if topLevel && ignoredCommandFields[key] {
+			continue
+		}
+		if written > 0 {
+			b.WriteString(",")
+		}
+		written++
+		b.WriteString(key)
+		b.WriteString(":")
+		switch {
+		case topLevel && i == 0:
+			// The command name's value is the collection, which the fingerprint keeps
+			b.WriteString(element.Value().String())
+		case topLevel && (key == "documents" || key == "updates" || key == "deletes"):
+			// Writes repeat one shape per document; the first stands for all
+			writeArrayShape(b, element.Value(), true)
+		default:
+			writeValueShape(b, element.Value())
+		}
+	}
+	b.WriteString("}")
+}
+
+func writeValueShape(b *strings.Builder, value bson.RawValue) {
+	switch value.Type {
+	case bsontype.EmbeddedDocument:
+		writeDocumentShape(b, value.Document(), false)
+	case bsontype.Array:
+		writeArrayShape(b, value, false)
+	default:
+		b.WriteString("?")
+	}
+}
+
+// writeArrayShape keeps the shape of each document, as pipeline stages differ, but
+// collapses lists of values such as an $in to one placeholder
+func writeArrayShape(b *strings.Builder, value bson.RawValue, firstOnly bool) {
+	values, err := value.Array().Values()
+	if err != nil {
+		b.WriteString("[?]")
+		return
+	}
+
+	b.WriteString("[")
+	written := 0
+	for _, item := range values {
+		if item.Type != bsontype.EmbeddedDocument && item.Type != bsontype.Array {
+			b.WriteString("?")
+			break
+		}
+		if written > 0 {
+			b.WriteString(",")
+		}
+		writeValueShape(b, item)
+		written++
+		if firstOnly {
+			break
+		}
+	}
+	b.WriteString("]")
+}
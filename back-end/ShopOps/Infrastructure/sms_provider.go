@@ -0,0 +1,225 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// SMSProvider sends a single text message and returns the provider's message ID
+type SMSProvider interface {
+	Name() string
+	Send(to, message string) (string, error)
+}
+
+// NewSMSProvider picks the gateway from SMS_PROVIDER, which needs:
+//   - afromessage:    AFROMESSAGE_TOKEN, AFROMESSAGE_IDENTIFIER_ID, AFROMESSAGE_SENDER
+//   - geezsms:        GEEZSMS_TOKEN, GEEZSMS_SHORTCODE_ID
+//   - africastalking: AT_USERNAME, AT_API_KEY, AT_SENDER
+//   - log (default):  writes messages to the server log, for development
+func NewSMSProvider(cfg SMSConfig) SMSProvider {
+	client := &http.Client{Timeout: 15 * time.Second}
+
+	switch strings.ToLower(cfg.Provider) {
+	case "afromessage":
+		return &afroMessageProvider{
+			client:       client,
+			token:        cfg.AfroMessageToken,
+			identifierID: cfg.AfroMessageIdentifierID,
+			sender:       cfg.AfroMessageSender,
+		}
+	case "geezsms":
+		return &geezSMSProvider{
+			client:      client,
+			token:       cfg.GeezSMSToken,
+			shortcodeID: cfg.GeezSMSShortcodeID,
+		}
+	case "africastalking":
+		return &africasTalkingProvider{
+			client:   client,
+			username: cfg.AfricasTalkingUsername,
+			apiKey:   cfg.AfricasTalkingAPIKey,
+			sender:   cfg.AfricasTalkingSenderName,
+		}
+	default:
+		return &logSMSProvider{}
+	}
+}
+
+type logSMSProvider struct{}
+
+func (p *logSMSProvider) Name() string { return "log" }
+
+func (p *logSMSProvider) Send(to, message string) (string, error) {
+	log.Printf("SMS to=%s message=%q", to, message)
+	return fmt.Sprintf("log-%d", time.Now().UnixNano()), nil
+}
+
+// afroMessageProvider sends through AfroMessage (Ethiopia)
+type afroMessageProvider struct {
+	client       *http.Client
+	token        string
+	identifierID string
+	sender       string
+}
+
+func (p *afroMessageProvider) Name() string { return "afromessage" }
+
+func (p *afroMessageProvider) Send(to, message string) (string, error) {
+	params := url.Values{}
+	params.Set("from", p.identifierID)
+	params.Set("sender", p.sender)
+	params.Set("to", to)
+	params.Set("message", message)
+
+	req, err := http.NewRequest(http.MethodGet, "https://api.afromessage.com/api/send?"+params.Encode(), nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Authorization", "Bearer "+p.token)
+
+	var result struct {
+		Acknowledge string `json:"acknowledge"`
+		Response    struct {
+			MessageID string   `json:"message_id"`
+			Errors    []string `json:"errors"`
+		} `json:"response"`
+	}
+	if err := doSMSRequest(p.client, req, &result); err != nil {
+		return "", err
+	}
+
+	if result.Acknowledge != "success" {
+		return "", fmt.Errorf("afromessage rejected message: %s", strings.Join(result.Response.Errors, "; "))
+	}
+
+	return result.Response.MessageID, nil
+}
+
+// geezSMSProvider sends through GeezSMS (Ethiopia)
+type geezSMSProvider struct {
+	client      *http.Client
+	token       string
+	shortcodeID string
+}
+
+func (p *geezSMSProvider) Name() string { return "geezsms" }
+
+func (p *geezSMSProvider) Send(to, message string) (string, error) {
+	payload := map[string]string{
+		"token": p.token,
+		"phone": to,
+		"msg":   message,
+	}
+	if p.shortcodeID != "" {
+		payload["shortcode_id"] = p.shortcodeID
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return "", err
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.geezsms.com/api/v1/sms/send", bytes.NewReader(body))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	var result struct {
+		Error bool   `json:"error"`
+		Msg   string `json:"msg"`
+		Data  struct {
+			APILogID string `json:"api_log_id"`
+		} `json:"data"`
+	}
+	if err := doSMSRequest(p.client, req, &result); err != nil {
+		return "", err
+	}
+
+	if result.Error {
+		return "", fmt.Errorf("geezsms rejected message: %s", result.Msg)
+	}
+
+	return result.Data.APILogID, nil
+}
+
+// africasTalkingProvider sends through Africa's Talking (Kenya, Uganda, Tanzania, Rwanda ...)
+type africasTalkingProvider struct {
+	client   *http.Client
+	username string
+	apiKey   string
+	sender   string
+}
+
+func (p *africasTalkingProvider) Name() string { return "africastalking" }
+
+func (p *africasTalkingProvider) Send(to, message string) (string, error) {
+	form := url.Values{}
+	form.Set("username", p.username)
+	form.Set("to", to)
+	form.Set("message", message)
+	if p.sender != "" {
+		form.Set("from", p.sender)
+	}
+
+	req, err := http.NewRequest(http.MethodPost, "https://api.africastalking.com/version1/messaging", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("apiKey", p.apiKey)
+
+	var result struct {
+		SMSMessageData struct {
+			Message    string `json:"Message"`
+			Recipients []struct {
+				MessageID string `json:"messageId"`
+				Status    string `json:"status"`
+			} `json:"Recipients"`
+		} `json:"SMSMessageData"`
+	}
+	if err := doSMSRequest(p.client, req, &result); err != nil {
+		return "", err
+	}
+
+	recipients := result.SMSMessageData.Recipients
+	if len(recipients) == 0 {
+		return "", fmt.Errorf("africastalking rejected message: %s", result.SMSMessageData.Message)
+	}
+	if recipients[0].Status != "Success" {
+		return "", fmt.Errorf("africastalking rejected message: %s", recipients[0].Status)
+	}
+
+	return recipients[0].MessageID, nil
+}
+
+func doSMSRequest(client *http.Client, req *http.Request, out interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("sms gateway request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("failed to read sms gateway response: %w", err)
+	}
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("sms gateway returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.Unmarshal(body, out); err != nil {
+		return fmt.Errorf("failed to decode sms gateway response: %w", err)
+	}
+
+	return nil
+}
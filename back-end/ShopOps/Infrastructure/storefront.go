@@ -0,0 +1,637 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// StorefrontConnector talks to one e-commerce platform on behalf of a shop's
+// connection. Adding a platform means implementing this and listing it in
+// NewStorefrontConnectors.
+type StorefrontConnector interface {
+	Platform() Domain.StorefrontPlatform
+	// NormalizeStoreURL checks a store address and puts it in the form the
+	// connector calls
+	NormalizeStoreURL(raw string) (string, error)
+	// Verify checks the credentials work, and fills in anything the connection
+	// needs from the store, such as Shopify's location
+	Verify(ctx context.Context, conn *Domain.StorefrontConnection) error
+	// FindBySKU finds the store's listing with the SKU; nil when there is none
+	FindBySKU(ctx context.Context, conn *Domain.StorefrontConnection, sku string) (*StorefrontListing, error)
+	// GetListing looks up a listing by its IDs on the store; nil when there is none
+	GetListing(ctx context.Context, conn *Domain.StorefrontConnection, productID, variantID string) (*StorefrontListing, error)
+	CreateListing(ctx context.Context, conn *Domain.StorefrontConnection, item StorefrontItem) (*StorefrontListing, error)
+	UpdateListing(ctx context.Context, conn *Domain.StorefrontConnection, listing StorefrontListing, item StorefrontItem) error
+	SetStock(ctx context.Context, conn *Domain.StorefrontConnection, listing StorefrontListing, quantity int) error
+	// ListOrders returns the paid, uncancelled orders changed since the time
+	ListOrders(ctx context.Context, conn *Domain.StorefrontConnection, since time.Time) ([]Domain.StorefrontOrder, error)
+}
+
+// StorefrontItem is a product as the store lists it
+type StorefrontItem struct {
+	Name        string
+	Description string
+	SKU         string
+	Barcode     string
+	Price       float64
+	Stock       int
+}
+
+// StorefrontListing is where a product is on the store
+type StorefrontListing struct {
+	ProductID   string
+	VariantID   string // Shopify only
+	InventoryID string // Shopify only
+}
+
+// StorefrontConnectors are the platforms shops can connect, by name
+type StorefrontConnectors map[Domain.StorefrontPlatform]StorefrontConnector
+
+func NewStorefrontConnectors() StorefrontConnectors {
+	client := &http.Client{Timeout: 30 * time.Second}
+	return StorefrontConnectors{
+		Domain.StorefrontWooCommerce: &wooCommerceConnector{client: client},
+		Domain.StorefrontShopify:     &shopifyConnector{client: client},
+	}
+}
+
+// StorefrontSyncs counts store syncs by platform and outcome
+var StorefrontSyncs = NewCounterVec("shopops_storefront_syncs_total",
+	"Online store syncs by platform and outcome.", "platform", "status")
+
+// storefrontMaxPages stops a runaway listing of orders; the rest come next sync
+const storefrontMaxPages = 20
+
+// storefrontRequest sends a JSON request, retrying a few times when the store
+// asks to slow down, and decodes the JSON reply into out
+func storefrontRequest(ctx context.Context, client *http.Client, method, url string, auth func(*http.Request), body, out interface{}) (http.Header, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			return nil, err
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		req, err := http.NewRequestWithContext(ctx, method, url, bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Accept", "application/json")
+		if body != nil {
+			req.Header.Set("Content-Type", "application/json")
+		}
+		auth(req)
+
+		resp, err := client.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("store request failed: %w", err)
+		}
+
+		if resp.StatusCode == http.StatusTooManyRequests && attempt < 3 {
+			resp.Body.Close()
+			wait, _ := strconv.ParseFloat(resp.Header.Get("Retry-After"), 64)
+			if wait <= 0 || wait > 10 {
+				wait = 2
+			}
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(time.Duration(wait * float64(time.Second))):
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			var problem struct {
+				Message string          `json:"message"`
+				Errors  json.RawMessage `json:"errors"`
+			}
+			_ = json.NewDecoder(resp.Body).Decode(&problem)
+			message := problem.Message
+			if message == "" && len(problem.Errors) > 0 {
+				message = string(problem.Errors)
+			}
+			return nil, fmt.Errorf("store returned status %d: %s", resp.StatusCode, message)
+		}
+		if out != nil {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return nil, fmt.Errorf("unreadable store response: %w", err)
+			}
+		}
+		return resp.Header, nil
+	}
+}
+
+func formatStorefrontPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', 2, 64)
+}
+
+// storefrontPaymentMethod tells cash on delivery from paid-online orders
+func storefrontPaymentMethod(gateway string) Domain.PaymentMethod {
+	gateway = strings.ToLower(gateway)
+	if gateway == "cod" || strings.Contains(gateway, "cash") {
+		return Domain.PaymentMethodCash
+	}
+	return Domain.PaymentMethodCard
+}
+
+// wooCommerceConnector uses the WooCommerce REST API (wc/v3) with a consumer key
+// and secret over HTTPS
+type wooCommerceConnector struct {
+	client *http.Client
+}
+
+func (c *wooCommerceConnector) Platform() Domain.StorefrontPlatform {
+	return Domain.StorefrontWooCommerce
+}
+
+func (c *wooCommerceConnector) NormalizeStoreURL(raw string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(raw))
+	if err != nil || u.Host == "" {
+		return "", Domain.ValidationError("store_url must be the store's address, e.g. https://shop.example.com")
+	}
+	if u.Scheme != "https" {
+		return "", Domain.ValidationError("store_url must use https; WooCommerce keys are sent with every request")
+	}
+	return "https://" + u.Host + strings.TrimRight(u.Path, "/"), nil
+}
+
+func (c *wooCommerceConnector) send(ctx context.Context, conn *Domain.StorefrontConnection, method, path string, body, out interface{}) error {
+	auth := func(req *http.Request) { req.SetBasicAuth(conn.APIKey, conn.APISecret) }
+	_, err := storefrontRequest(ctx, c.client, method, conn.StoreURL+"/wp-json/wc/v3"+path, auth, body, out)
+	if err != nil {
+		return fmt.Errorf("woocommerce: %w", err)
+	}
+	return nil
+}
+
+type wooProduct struct {
+	ID int64 `json:"id"`
+}
+
+func (p wooProduct) listing() *StorefrontListing {
+	return &StorefrontListing{ProductID: strconv.FormatInt(p.ID, 10)}
+}
+
+func (c *wooCommerceConnector) Verify(ctx context.Context, conn *Domain.StorefrontConnection) error {
+	if conn.APIKey == "" {
+		return Domain.ValidationError("api_key is required for WooCommerce: the REST API consumer key")
+	}
+	var products []wooProduct
+	return c.send(ctx, conn, http.MethodGet, "/products?per_page=1", nil, &products)
+}
+
+func (c *wooCommerceConnector) FindBySKU(ctx context.Context, conn *Domain.StorefrontConnection, sku string) (*StorefrontListing, error) {
+	var products []wooProduct
+	if err := c.send(ctx, conn, http.MethodGet, "/products?sku="+url.QueryEscape(sku), nil, &products); err != nil {
+		return nil, err
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return products[0].listing(), nil
+}
+
+func (c *wooCommerceConnector) GetListing(ctx context.Context, conn *Domain.StorefrontConnection, productID, variantID string) (*StorefrontListing, error) {
+	var product wooProduct
+	if err := c.send(ctx, conn, http.MethodGet, "/products/"+url.PathEscape(productID), nil, &product); err != nil {
+		return nil, err
+	}
+	if product.ID == 0 {
+		return nil, nil
+	}
+	return product.listing(), nil
+}
+
+func (c *wooCommerceConnector) CreateListing(ctx context.Context, conn *Domain.StorefrontConnection, item StorefrontItem) (*StorefrontListing, error) {
+	var product wooProduct
+	err := c.send(ctx, conn, http.MethodPost, "/products", map[string]interface{}{
+		"name":           item.Name,
+		"type":           "simple",
+		"status":         "publish",
+		"description":    item.Description,
+		"sku":            item.SKU,
+		"regular_price":  formatStorefrontPrice(item.Price),
+		"manage_stock":   true,
+		"stock_quantity": item.Stock,
+	}, &product)
+	if err != nil {
+		return nil, err
+	}
+	return product.listing(), nil
+}
+
+func (c *wooCommerceConnector) UpdateListing(ctx context.Context, conn *Domain.StorefrontConnection, listing StorefrontListing, item StorefrontItem) error {
+	var product wooProduct
+	return c.send(ctx, conn, http.MethodPut, "/products/"+url.PathEscape(listing.ProductID), map[string]interface{}{
+		"name":          item.Name,
+		"regular_price": formatStorefrontPrice(item.Price),
+	}, &product)
+}
+
+func (c *wooCommerceConnector) SetStock(ctx context.Context, conn *Domain.StorefrontConnection, listing StorefrontListing, quantity int) error {
+	var product wooProduct
+	return c.send(ctx, conn, http.MethodPut, "/products/"+url.PathEscape(listing.ProductID), map[string]interface{}{
+		"manage_stock":   true,
+		"stock_quantity": quantity,
+	}, &product)
+}
+
+type wooOrder struct {
+	ID             int64  `json:"id"`
+	Number         string `json:"number"`
+	Currency       string `json:"currency"`
+	Total          string `json:"total"`
+	DateCreatedGMT string `json:"date_created_gmt"`
+	PaymentMethod  string `json:"payment_method"`
+	Billing        struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Phone     string `json:"phone"`
+	} `json:"billing"`
+	LineItems []struct {
+		ID          int64   `json:"id"`
+		ProductID   int64   `json:"product_id"`
+		VariationID int64   `json:"variation_id"`
+		SKU         string  `json:"sku"`
+		Name        string  `json:"name"`
+		Quantity    float64 `json:"quantity"`
+		Total       string  `json:"total"` // After discounts, before tax
+	} `json:"line_items"`
+}
+
+func (c *wooCommerceConnector) ListOrders(ctx context.Context, conn *Domain.StorefrontConnection, since time.Time) ([]Domain.StorefrontOrder, error) {
+	var orders []Domain.StorefrontOrder
+	for page := 1; page <= storefrontMaxPages; page++ {
+		query := url.Values{
+			"status":         {"processing,completed"},
+			"modified_after": {since.UTC().Format("2006-01-02T15:04:05")},
+			"dates_are_gmt":  {"true"},
+			"orderby":        {"modified"},
+			"order":          {"asc"},
+			"per_page":       {"100"},
+			"page":           {strconv.Itoa(page)},
+		}
+
+		var batch []wooOrder
+		if err := c.send(ctx, conn, http.MethodGet, "/orders?"+query.Encode(), nil, &batch); err != nil {
+			return nil, err
+		}
+
+		for _, o := range batch {
+			total, _ := strconv.ParseFloat(o.Total, 64)
+			orderedAt, _ := time.Parse("2006-01-02T15:04:05", o.DateCreatedGMT)
+			order := Domain.StorefrontOrder{
+				RemoteID:      strconv.FormatInt(o.ID, 10),
+				Number:        "#" + o.Number,
+				CustomerName:  strings.TrimSpace(o.Billing.FirstName + " " + o.Billing.LastName),
+				CustomerPhone: o.Billing.Phone,
+				PaymentMethod: storefrontPaymentMethod(o.PaymentMethod),
+				Currency:      o.Currency,
+				Total:         total,
+				OrderedAt:     orderedAt,
+			}
+			for _, item := range o.LineItems {
+				lineTotal, _ := strconv.ParseFloat(item.Total, 64)
+				line := Domain.StorefrontOrderLine{
+					RemoteLineID:    strconv.FormatInt(item.ID, 10),
+					RemoteProductID: strconv.FormatInt(item.ProductID, 10),
+					SKU:             item.SKU,
+					Name:            item.Name,
+					Quantity:        item.Quantity,
+				}
+				// Variations are mapped as products of their own
+				if item.VariationID != 0 {
+					line.RemoteProductID = strconv.FormatInt(item.VariationID, 10)
+				}
+				if item.Quantity > 0 {
+					line.UnitPrice = lineTotal / item.Quantity
+				}
+				order.Lines = append(order.Lines, line)
+			}
+			orders = append(orders, order)
+		}
+
+		if len(batch) < 100 {
+			break
+		}
+	}
+	return orders, nil
+}
+
+// shopifyConnector uses the Shopify Admin REST API with a custom app's access
+// token, and its GraphQL API to find variants by SKU
+type shopifyConnector struct {
+	client *http.Client
+}
+
+const shopifyAPIVersion = "2024-07"
+
+var shopifyNextLink = regexp.MustCompile(`<([^>]+)>;\s*rel="next"`)
+
+func (c *shopifyConnector) Platform() Domain.StorefrontPlatform {
+	return Domain.StorefrontShopify
+}
+
+func (c *shopifyConnector) NormalizeStoreURL(raw string) (string, error) {
+	host := strings.TrimSpace(raw)
+	if u, err := url.Parse(host); err == nil && u.Host != "" {
+		host = u.Host
+	}
+	host = strings.ToLower(strings.TrimRight(host, "/"))
+	if !strings.HasSuffix(host, ".myshopify.com") || strings.ContainsAny(host, "/?#@") {
+		return "", Domain.ValidationError("store_url must be the store's myshopify.com address, e.g. example.myshopify.com")
+	}
+	return host, nil
+}
+
+func (c *shopifyConnector) send(ctx context.Context, conn *Domain.StorefrontConnection, method, path string, body, out interface{}) (http.Header, error) {
+	target := path
+	if !strings.HasPrefix(path, "https://") {
+		target = "https://" + conn.StoreURL + "/admin/api/" + shopifyAPIVersion + path
+	}
+	auth := func(req *http.Request) { req.Header.Set("X-Shopify-Access-Token", conn.APISecret) }
+	header, err := storefrontRequest(ctx, c.client, method, target, auth, body, out)
+	if err != nil {
+		return nil, fmt.Errorf("shopify: %w", err)
+	}
+	return header, nil
+}
+
+func (c *shopifyConnector) Verify(ctx context.Context, conn *Domain.StorefrontConnection) error {
+	var shop struct {
+		Shop struct {
+			ID int64 `json:"id"`
+		} `json:"shop"`
+	}
+	if _, err := c.send(ctx, conn, http.MethodGet, "/shop.json", nil, &shop); err != nil {
+		return err
+	}
+	if conn.LocationID != "" {
+		return nil
+	}
+
+	var locations struct {
+		Locations []struct {
+			ID     int64 `json:"id"`
+			Active bool  `json:"active"`
+		} `json:"locations"`
+	}
+	if _, err := c.send(ctx, conn, http.MethodGet, "/locations.json", nil, &locations); err != nil {
+		return err
+	}
+	for _, location := range locations.Locations {
+		if location.Active {
+			conn.LocationID = strconv.FormatInt(location.ID, 10)
+			return nil
+		}
+	}
+	return Domain.ValidationError("the Shopify store has no active location to keep stock at")
+}
+
+func (c *shopifyConnector) FindBySKU(ctx context.Context, conn *Domain.StorefrontConnection, sku string) (*StorefrontListing, error) {
+	var reply struct {
+		Data struct {
+			ProductVariants struct {
+				Nodes []struct {
+					LegacyResourceID string `json:"legacyResourceId"`
+					SKU              string `json:"sku"`
+					Product          struct {
+						LegacyResourceID string `json:"legacyResourceId"`
+					} `json:"product"`
+					InventoryItem struct {
+						LegacyResourceID string `json:"legacyResourceId"`
+					} `json:"inventoryItem"`
+				} `json:"nodes"`
+			} `json:"productVariants"`
+		} `json:"data"`
+	}
+	query := `query($q: String!) { productVariants(first: 5, query: $q) { nodes { legacyResourceId sku product { legacyResourceId } inventoryItem { legacyResourceId } } } }`
+	_, err := c.send(ctx, conn, http.MethodPost, "/graphql.json", map[string]interface{}{
+		"query":     query,
+		"variables": map[string]string{"q": "sku:" + strconv.Quote(sku)},
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+
+	// The search matches loosely; only an exact SKU is the same product
+	for _, v := range reply.Data.ProductVariants.Nodes {
+		if v.SKU == sku {
+			return &StorefrontListing{
+				ProductID:   v.Product.LegacyResourceID,
+				VariantID:   v.LegacyResourceID,
+				InventoryID: v.InventoryItem.LegacyResourceID,
+			}, nil
+		}
+	}
+	return nil, nil
+}
+
+type shopifyVariant struct {
+	ID              int64 `json:"id"`
+	ProductID       int64 `json:"product_id"`
+	InventoryItemID int64 `json:"inventory_item_id"`
+}
+
+func (v shopifyVariant) listing() *StorefrontListing {
+	return &StorefrontListing{
+		ProductID:   strconv.FormatInt(v.ProductID, 10),
+		VariantID:   strconv.FormatInt(v.ID, 10),
+		InventoryID: strconv.FormatInt(v.InventoryItemID, 10),
+	}
+}
+
+func (c *shopifyConnector) GetListing(ctx context.Context, conn *Domain.StorefrontConnection, productID, variantID string) (*StorefrontListing, error) {
+	if variantID == "" {
+		return nil, Domain.ValidationError("remote_variant_id is required for Shopify")
+	}
+
+	var reply struct {
+		Variant shopifyVariant `json:"variant"`
+	}
+	if _, err := c.send(ctx, conn, http.MethodGet, "/variants/"+url.PathEscape(variantID)+".json", nil, &reply); err != nil {
+		return nil, err
+	}
+	if reply.Variant.ID == 0 || strconv.FormatInt(reply.Variant.ProductID, 10) != productID {
+		return nil, nil
+	}
+	return reply.Variant.listing(), nil
+}
+
+func (c *shopifyConnector) CreateListing(ctx context.Context, conn *Domain.StorefrontConnection, item StorefrontItem) (*StorefrontListing, error) {
+	var reply struct {
+		Product struct {
+			Variants []shopifyVariant `json:"variants"`
+		} `json:"product"`
+	}
+	_, err := c.send(ctx, conn, http.MethodPost, "/products.json", map[string]interface{}{
+		"product": map[string]interface{}{
+			"title":     item.Name,
+			"body_html": item.Description,
+			"status":    "active",
+			"variants": []map[string]interface{}{{
+				"sku":                  item.SKU,
+				"barcode":              item.Barcode,
+				"price":                formatStorefrontPrice(item.Price),
+				"inventory_management": "shopify",
+			}},
+		},
+	}, &reply)
+	if err != nil {
+		return nil, err
+	}
+	if len(reply.Product.Variants) == 0 {
+		return nil, fmt.Errorf("shopify: product created without a variant")
+	}
+
+	listing := reply.Product.Variants[0].listing()
+	if err := c.SetStock(ctx, conn, *listing, item.Stock); err != nil {
+		return listing, err
+	}
+	return listing, nil
+}
+
+func (c *shopifyConnector) UpdateListing(ctx context.Context, conn *Domain.StorefrontConnection, listing StorefrontListing, item StorefrontItem) error {
+	_, err := c.send(ctx, conn, http.MethodPut, "/products/"+url.PathEscape(listing.ProductID)+".json", map[string]interface{}{
+		"product": map[string]interface{}{"id": json.Number(listing.ProductID), "title": item.Name},
+	}, nil)
+	if err != nil {
+		return err
+	}
+
+	_, err = c.send(ctx, conn, http.MethodPut, "/variants/"+url.PathEscape(listing.VariantID)+".json", map[string]interface{}{
+		"variant": map[string]interface{}{"id": json.Number(listing.VariantID), "price": formatStorefrontPrice(item.Price)},
+	}, nil)
+	return err
+}
+
+func (c *shopifyConnector) SetStock(ctx context.Context, conn *Domain.StorefrontConnection, listing StorefrontListing, quantity int) error {
+	if conn.LocationID == "" || listing.InventoryID == "" {
+		return fmt.Errorf("shopify: no location or inventory item to set stock at")
+	}
+	_, err := c.send(ctx, conn, http.MethodPost, "/inventory_levels/set.json", map[string]interface{}{
+		"location_id":       json.Number(conn.LocationID),
+		"inventory_item_id": json.Number(listing.InventoryID),
+		"available":         quantity,
+	}, nil)
+	return err
+}
+
+type shopifyOrder struct {
+	ID                  int64     `json:"id"`
+	Name                string    `json:"name"`
+	Currency            string    `json:"currency"`
+	TotalPrice          string    `json:"total_price"`
+	CreatedAt           time.Time `json:"created_at"`
+	CancelledAt         *string   `json:"cancelled_at"`
+	PaymentGatewayNames []string  `json:"payment_gateway_names"`
+	Customer            *struct {
+		FirstName string `json:"first_name"`
+		LastName  string `json:"last_name"`
+		Phone     string `json:"phone"`
+	} `json:"customer"`
+	BillingAddress *struct {
+		Name  string `json:"name"`
+		Phone string `json:"phone"`
+	} `json:"billing_address"`
+	LineItems []struct {
+		ID            int64   `json:"id"`
+		ProductID     int64   `json:"product_id"`
+		VariantID     int64   `json:"variant_id"`
+		SKU           string  `json:"sku"`
+		Name          string  `json:"name"`
+		Quantity      float64 `json:"quantity"`
+		Price         string  `json:"price"`
+		TotalDiscount string  `json:"total_discount"`
+	} `json:"line_items"`
+}
+
+func (c *shopifyConnector) ListOrders(ctx context.Context, conn *Domain.StorefrontConnection, since time.Time) ([]Domain.StorefrontOrder, error) {
+	query := url.Values{
+		"status":           {"any"},
+		"financial_status": {"paid"},
+		"updated_at_min":   {since.UTC().Format(time.RFC3339)},
+		"limit":            {"250"},
+	}
+	next := "/orders.json?" + query.Encode()
+
+	var orders []Domain.StorefrontOrder
+	for page := 0; next != "" && page < storefrontMaxPages; page++ {
+		var reply struct {
+			Orders []shopifyOrder `json:"orders"`
+		}
+		header, err := c.send(ctx, conn, http.MethodGet, next, nil, &reply)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, o := range reply.Orders {
+			if o.CancelledAt != nil {
+				continue
+			}
+			total, _ := strconv.ParseFloat(o.TotalPrice, 64)
+			order := Domain.StorefrontOrder{
+				RemoteID:  strconv.FormatInt(o.ID, 10),
+				Number:    o.Name,
+				Currency:  o.Currency,
+				Total:     total,
+				OrderedAt: o.CreatedAt,
+			}
+			if len(o.PaymentGatewayNames) > 0 {
+				order.PaymentMethod = storefrontPaymentMethod(o.PaymentGatewayNames[0])
+			} else {
+				order.PaymentMethod = Domain.PaymentMethodCard
+			}
+			if o.Customer != nil {
+				order.CustomerName = strings.TrimSpace(o.Customer.FirstName + " " + o.Customer.LastName)
+				order.CustomerPhone = o.Customer.Phone
+			}
+			if o.BillingAddress != nil {
+				if order.CustomerName == "" {
+					order.CustomerName = o.BillingAddress.Name
+				}
+				if order.CustomerPhone == "" {
+					order.CustomerPhone = o.BillingAddress.Phone
+				}
+			}
+			for _, item := range o.LineItems {
+				price, _ := strconv.ParseFloat(item.Price, 64)
+				discount, _ := strconv.ParseFloat(item.TotalDiscount, 64)
+				line := Domain.StorefrontOrderLine{
+					RemoteLineID:    strconv.FormatInt(item.ID, 10),
+					RemoteProductID: strconv.FormatInt(item.ProductID, 10),
+					RemoteVariantID: strconv.FormatInt(item.VariantID, 10),
+					SKU:             item.SKU,
+					Name:            item.Name,
+					Quantity:        item.Quantity,
+					UnitPrice:       price,
+				}
+				if item.Quantity > 0 {
+					line.UnitPrice = price - discount/item.Quantity
+				}
+				order.Lines = append(order.Lines, line)
+			}
+			orders = append(orders, order)
+		}
+
+		next = ""
+		if m := shopifyNextLink.FindStringSubmatch(header.Get("Link")); m != nil {
+			next = m[1]
+		}
+	}
+	return orders, nil
+}
@@ -2,6 +2,7 @@ package Infrastructure
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
@@ -9,23 +10,50 @@ import (
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type SyncService interface {
-	ProcessBatch(batch Domain.SyncBatch) (*Domain.SyncResponse, error)
+	ProcessBatch(ctx context.Context, batch Domain.SyncBatch) (*Domain.SyncResponse, error)
 	GetSyncStatus(businessID string) (*Domain.SyncStatus, error)
 }
 
+// EventOutbox writes domain events with the change they describe, in one
+// transaction where the deployment has them
+type EventOutbox interface {
+	Write(ctx context.Context, change func(ctx context.Context) ([]*Domain.DomainEvent, error)) error
+	Append(ctx context.Context, events []*Domain.DomainEvent) error
+}
+
 type syncService struct {
 	db          *mongo.Database
+	outbox      EventOutbox
 	salesRepo   Domain.SaleRepository
 	expenseRepo Domain.ExpenseRepository
 	productRepo Domain.ProductRepository
 	syncRepo    Domain.SyncRepository
 }
 
+// Synced items are written in bulk, up to syncChunkSize per collection at a time,
+// rather than one round trip each: a device catching up after days offline can
+// send thousands. A chunk never holds two writes to the same record, so items
+// still apply in the order the device made them.
+const syncChunkSize = 500
+
+// syncWrite is one item's write, waiting in a chunk
+type syncWrite struct {
+	index     int // Of the item in the batch
+	key       string
+	operation Domain.SyncOperation
+	model     mongo.WriteModel
+	id        primitive.ObjectID // Of the record written
+	serverID  primitive.ObjectID
+	versioned bool // An update filtered on the version the device last saw
+}
+
 func NewSyncService(
 	db *mongo.Database,
+	outbox EventOutbox,
 	salesRepo Domain.SaleRepository,
 	expenseRepo Domain.ExpenseRepository,
 	productRepo Domain.ProductRepository,
@@ -33,6 +61,7 @@ func NewSyncService(
 ) SyncService {
 	return &syncService{
 		db:          db,
+		outbox:      outbox,
 		salesRepo:   salesRepo,
 		expenseRepo: expenseRepo,
 		productRepo: productRepo,
@@ -40,7 +69,7 @@ func NewSyncService(
 	}
 }
 
-func (s *syncService) ProcessBatch(batch Domain.SyncBatch) (*Domain.SyncResponse, error) {
+func (s *syncService) ProcessBatch(ctx context.Context, batch Domain.SyncBatch) (*Domain.SyncResponse, error) {
 	response := &Domain.SyncResponse{
 		Success:    []Domain.SyncResult{},
 		Failed:     []Domain.SyncResult{},
@@ -52,213 +81,512 @@ func (s *syncService) ProcessBatch(batch Domain.SyncBatch) (*Domain.SyncResponse
 		return nil, fmt.Errorf("invalid business ID: %w", err)
 	}
 
-	for _, item := range batch.Items {
-		result := Domain.SyncResult{
-			LocalID:   item.LocalID,
-			Timestamp: time.Now(),
+	ctx, span := StartSpan(ctx, "sync.batch", SpanKindInternal)
+	defer span.End()
+	span.SetAttribute("sync.items", len(batch.Items))
+
+	// What the device already uploaded, one query per entity type
+	existing, err := s.findExisting(ctx, businessObjID, batch.Items)
+	if err != nil {
+		return nil, fmt.Errorf("check failed: %w", err)
+	}
+
+	results := make([]Domain.SyncResult, len(batch.Items))
+	chunks := make(map[string][]syncWrite)
+	flush := func(entityType string) {
+		writes := chunks[entityType]
+		if len(writes) == 0 {
+			return
 		}
+		s.writeChunk(ctx, entityType, writes, results)
+		for _, w := range writes {
+			// A create that did not go in can be tried again later in the batch
+			if w.operation == Domain.SyncOperationCreate && !results[w.index].Success {
+				delete(existing, w.key)
+			}
+		}
+		chunks[entityType] = nil
+	}
 
-		// Check if item already exists
-		existing, err := s.findExistingItem(businessObjID, item.EntityType, item.LocalID)
+	for i, item := range batch.Items {
+		results[i] = Domain.SyncResult{LocalID: item.LocalID, Timestamp: time.Now()}
+		key := syncKey(item.EntityType, item.LocalID)
+
+		// Settle earlier writes to the same record first, so this one sees them
+		writes := chunks[item.EntityType]
+		if len(writes) >= syncChunkSize || chunkHas(writes, key) {
+			flush(item.EntityType)
+		}
+
+		write, err := s.planItem(batch, item, existing)
 		if err != nil {
-			result.Success = false
-			result.Error = fmt.Sprintf("check failed: %v", err)
-			response.Failed = append(response.Failed, result)
+			results[i].Error = err.Error()
 			continue
 		}
-
-		// Process based on operation
-		switch item.Operation {
-		case Domain.SyncOperationCreate:
-			if existing != nil {
-				// Already exists, skip
-				result.Success = true
-				result.ServerID = existing.(primitive.ObjectID).Hex()
-				response.Success = append(response.Success, result)
-				continue
+		if write == nil {
+			// Nothing to write: created before, or already deleted
+			results[i].Success = true
+			if id, ok := existing[key]; ok {
+				results[i].ServerID = id.Hex()
 			}
+			continue
+		}
 
-			serverID, err := s.createItem(batch.BusinessID, item.EntityType, item.Data)
-			if err != nil {
-				result.Success = false
-				result.Error = fmt.Sprintf("create failed: %v", err)
-				response.Failed = append(response.Failed, result)
-			} else {
-				result.Success = true
-				result.ServerID = serverID
-				response.Success = append(response.Success, result)
-			}
+		write.index = i
+		write.key = key
+		chunks[item.EntityType] = append(chunks[item.EntityType], *write)
+	}
+	for entityType := range chunks {
+		flush(entityType)
+	}
 
-		case Domain.SyncOperationUpdate:
-			if existing == nil {
-				result.Success = false
-				result.Error = "item not found for update"
-				response.Failed = append(response.Failed, result)
-				continue
-			}
+	for _, result := range results {
+		if result.Success {
+			response.Success = append(response.Success, result)
+		} else {
+			response.Failed = append(response.Failed, result)
+		}
+	}
+	span.SetAttribute("sync.failed", len(response.Failed))
 
-			err := s.updateItem(existing.(primitive.ObjectID).Hex(), item.EntityType, item.Data)
-			if err != nil {
-				result.Success = false
-				result.Error = fmt.Sprintf("update failed: %v", err)
-				response.Failed = append(response.Failed, result)
-			} else {
-				result.Success = true
-				result.ServerID = existing.(primitive.ObjectID).Hex()
-				response.Success = append(response.Success, result)
-			}
+	// Log sync result
+	result := *response
+	Go(ctx, "sync_log", func(ctx context.Context) error {
+		return s.syncRepo.LogSync(batch.BusinessID, batch.DeviceID, batch.Items, result)
+	})
 
-		case Domain.SyncOperationDelete:
-			if existing == nil {
-				// Already deleted, consider success
-				result.Success = true
-				response.Success = append(response.Success, result)
-				continue
-			}
+	return response, nil
+}
+
+// planItem works out the write an item needs, nil when it needs none. Records
+// created here are added to existing, under the ID they will be written with.
+func (s *syncService) planItem(batch Domain.SyncBatch, item Domain.SyncItem, existing map[string]primitive.ObjectID) (*syncWrite, error) {
+	key := syncKey(item.EntityType, item.LocalID)
+	id, found := existing[key]
 
-			err := s.deleteItem(existing.(primitive.ObjectID).Hex(), item.EntityType)
-			if err != nil {
-				result.Success = false
-				result.Error = fmt.Sprintf("delete failed: %v", err)
-				response.Failed = append(response.Failed, result)
-			} else {
-				result.Success = true
-				response.Success = append(response.Success, result)
+	switch item.Operation {
+	case Domain.SyncOperationCreate:
+		if found {
+			return nil, nil
+		}
+		doc, err := createDoc(batch.BusinessID, batch.DeviceID, item.EntityType, item.Data)
+		if err != nil {
+			return nil, fmt.Errorf("create failed: %v", err)
+		}
+		id = primitive.NewObjectID()
+		doc["_id"] = id
+		existing[key] = id
+		return &syncWrite{operation: item.Operation, model: mongo.NewInsertOneModel().SetDocument(doc), id: id, serverID: id}, nil
+
+	case Domain.SyncOperationUpdate:
+		if !found {
+			return nil, errors.New("item not found for update")
+		}
+		doc, version, err := updateDoc(item.EntityType, item.Data)
+		if err != nil {
+			return nil, fmt.Errorf("update failed: %v", err)
+		}
+		if !syncVersioned[item.EntityType] {
+			model := mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(bson.M{"$set": doc})
+			return &syncWrite{operation: item.Operation, model: model, id: id, serverID: id}, nil
+		}
+
+		// Like an update through the API, it applies only while the record is at
+		// the version the device last saw, and moves the version on
+		filter := bson.M{"_id": id}
+		if version != nil {
+			filter["version"] = *version
+			if *version == 0 {
+				// Documents from before versioning have no version field
+				filter["version"] = bson.M{"$in": bson.A{0, nil}}
 			}
 		}
+		model := mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(bson.M{"$set": doc, "$inc": bson.M{"version": 1}})
+		return &syncWrite{operation: item.Operation, model: model, id: id, serverID: id, versioned: true}, nil
+
+	case Domain.SyncOperationDelete:
+		if !found {
+			// Already deleted, consider success
+			return nil, nil
+		}
+		model := mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(deleteUpdate(item.EntityType))
+		return &syncWrite{operation: item.Operation, model: model, id: id}, nil
 	}
 
-	// Log sync result
-	go s.syncRepo.LogSync(batch.BusinessID, batch.DeviceID, batch.Items, *response)
+	return nil, fmt.Errorf("unknown operation: %s", item.Operation)
+}
 
-	return response, nil
+// findExisting maps the batch's records already on the server, by syncKey, to their IDs
+func (s *syncService) findExisting(ctx context.Context, businessID primitive.ObjectID, items []Domain.SyncItem) (map[string]primitive.ObjectID, error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 10*time.Second)
+	defer cancel()
+
+	localIDs := make(map[string][]string)
+	for _, item := range items {
+		localIDs[item.EntityType] = append(localIDs[item.EntityType], item.LocalID)
+	}
+
+	existing := make(map[string]primitive.ObjectID, len(items))
+	for entityType, ids := range localIDs {
+		collection := s.db.Collection(fmt.Sprintf("%ss", entityType))
+		cursor, err := collection.Find(ctx,
+			bson.M{"business_id": businessID, "local_id": bson.M{"$in": ids}},
+			options.Find().SetProjection(bson.M{"_id": 1, "local_id": 1}),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		var found []struct {
+			ID      primitive.ObjectID `bson:"_id"`
+			LocalID string             `bson:"local_id"`
+		}
+		if err := cursor.All(ctx, &found); err != nil {
+			return nil, err
+		}
+		for _, f := range found {
+			existing[syncKey(entityType, f.LocalID)] = f.ID
+		}
+	}
+
+	return existing, nil
 }
 
-func (s *syncService) findExistingItem(businessID primitive.ObjectID, entityType, localID string) (interface{}, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+// writeChunk writes a chunk of one entity type and records each item's result.
+// On a replica set the chunk is written in one transaction through the outbox,
+// with the events of its writes. A record the database turns down aborts the
+// transaction, so the chunk is then written again unordered, which lets the rest
+// go in and says which writes failed, and their events are recorded after.
+func (s *syncService) writeChunk(ctx context.Context, entityType string, writes []syncWrite, results []Domain.SyncResult) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), 30*time.Second)
 	defer cancel()
 
+	ctx, span := StartSpan(ctx, "sync.write", SpanKindClient)
+	defer span.End()
+	span.SetAttribute("sync.entity_type", entityType)
+	span.SetAttribute("sync.writes", len(writes))
+
 	collection := s.db.Collection(fmt.Sprintf("%ss", entityType))
 
-	filter := bson.M{
-		"business_id": businessID,
-		"local_id":    localID,
+	var failed map[int]string
+	written := SupportsTransactions(ctx, s.db) && s.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		var err error
+		if failed, err = applyWrites(ctx, collection, entityType, writes, true); err != nil {
+			return nil, err
+		}
+		return syncEvents(ctx, collection, entityType, writes, failed)
+	}) == nil
+	if !written {
+		failed, _ = applyWrites(ctx, collection, entityType, writes, false)
+
+		events, err := syncEvents(ctx, collection, entityType, writes, failed)
+		if err == nil {
+			err = s.outbox.Append(ctx, events)
+		}
+		if err != nil {
+			LoggerFrom(ctx).Warn("failed to record synced events", "entity_type", entityType, "error", err)
+		}
+	}
+
+	for i, w := range writes {
+		result := &results[w.index]
+		if msg, ok := failed[i]; ok {
+			result.Error = fmt.Sprintf("%s failed: %s", w.operation, msg)
+			continue
+		}
+		result.Success = true
+		if !w.serverID.IsZero() {
+			result.ServerID = w.serverID.Hex()
+		}
+	}
+	if len(failed) > 0 {
+		span.RecordError(fmt.Errorf("%d of %d writes failed", len(failed), len(writes)))
+	}
+}
+
+// applyWrites writes a chunk and maps the writes that failed to why. Ordered, as
+// in a transaction, the first write the database turns down stops it and is
+// returned instead. Versioned updates go one at a time: one made on a version
+// since changed matches nothing, which a bulk write does not say per write.
+func applyWrites(ctx context.Context, collection *mongo.Collection, entityType string, writes []syncWrite, ordered bool) (map[int]string, error) {
+	var models []mongo.WriteModel
+	var indexes []int // Of each model's write
+	for i, w := range writes {
+		if !w.versioned {
+			models = append(models, w.model)
+			indexes = append(indexes, i)
+		}
+	}
+
+	failed := make(map[int]string)
+	if len(models) > 0 {
+		_, err := collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(ordered))
+		if err != nil && ordered {
+			return nil, err
+		}
+		for j, msg := range bulkWriteFailures(err, len(models)) {
+			failed[indexes[j]] = msg
+		}
+	}
+
+	for i, w := range writes {
+		if !w.versioned {
+			continue
+		}
+		model := w.model.(*mongo.UpdateOneModel)
+		result, err := collection.UpdateOne(ctx, model.Filter, model.Update)
+		switch {
+		case err != nil && ordered:
+			return nil, err
+		case err != nil:
+			failed[i] = err.Error()
+		case result.MatchedCount == 0:
+			failed[i] = Domain.StaleVersionError(entityType).Error()
+		}
+	}
+
+	return failed, nil
+}
+
+// syncEventTypes are the events of each synced write to a sale, the same the API's
+// writes record
+var syncEventTypes = map[Domain.SyncOperation]Domain.DomainEventType{
+	Domain.SyncOperationCreate: Domain.EventSaleRecorded,
+	Domain.SyncOperationUpdate: Domain.EventSaleUpdated,
+	Domain.SyncOperationDelete: Domain.EventSaleVoided,
+}
+
+// syncEvents are the events of a chunk's writes that went in, with the sales read
+// back as written. Only sales have events.
+func syncEvents(ctx context.Context, collection *mongo.Collection, entityType string, writes []syncWrite, failed map[int]string) ([]*Domain.DomainEvent, error) {
+	if entityType != "sale" {
+		return nil, nil
+	}
+
+	var ids []primitive.ObjectID
+	for i, w := range writes {
+		if _, ok := failed[i]; !ok {
+			ids = append(ids, w.id)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
 	}
 
-	var result bson.M
-	err := collection.FindOne(ctx, filter).Decode(&result)
+	cursor, err := collection.Find(ctx, bson.M{"_id": bson.M{"$in": ids}})
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
+		return nil, fmt.Errorf("failed to read synced sales: %w", err)
+	}
+	var sales []Domain.Sale
+	if err := cursor.All(ctx, &sales); err != nil {
+		return nil, fmt.Errorf("failed to read synced sales: %w", err)
+	}
+	written := make(map[primitive.ObjectID]*Domain.Sale, len(sales))
+	for i := range sales {
+		written[sales[i].ID] = &sales[i]
+	}
+
+	// In the order the device made them
+	var events []*Domain.DomainEvent
+	for i, w := range writes {
+		sale, ok := written[w.id]
+		if _, bad := failed[i]; bad || !ok {
+			continue
 		}
-		return nil, err
+		event, err := Domain.NewDomainEvent(sale.BusinessID, syncEventTypes[w.operation], sale.ID.Hex(), sale)
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, event)
 	}
+	return events, nil
+}
 
-	return result["_id"], nil
+// bulkWriteFailures maps the index of each write that failed to why. When the
+// error is not about particular writes, all of them are taken to have failed.
+func bulkWriteFailures(err error, n int) map[int]string {
+	if err == nil {
+		return nil
+	}
+
+	failed := make(map[int]string)
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil {
+		for _, writeErr := range bulkErr.WriteErrors {
+			failed[writeErr.Index] = writeErr.Message
+		}
+		return failed
+	}
+
+	for i := 0; i < n; i++ {
+		failed[i] = err.Error()
+	}
+	return failed
 }
 
-func (s *syncService) createItem(businessID, entityType string, data interface{}) (string, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
+func syncKey(entityType, localID string) string {
+	return entityType + "/" + localID
+}
 
-	collection := s.db.Collection(fmt.Sprintf("%ss", entityType))
+func chunkHas(writes []syncWrite, key string) bool {
+	for _, w := range writes {
+		if w.key == key {
+			return true
+		}
+	}
+	return false
+}
 
+// createDoc is the document a synced create inserts
+func createDoc(businessID, deviceID, entityType string, data interface{}) (bson.M, error) {
 	// Convert data to BSON
 	bsonData, err := bson.Marshal(data)
 	if err != nil {
-		return "", err
+		return nil, err
 	}
 
 	var doc bson.M
 	if err := bson.Unmarshal(bsonData, &doc); err != nil {
-		return "", err
+		return nil, err
+	}
+	if err := normalizeSyncMoney(entityType, doc); err != nil {
+		return nil, err
 	}
 
 	// Add business ID and timestamps
 	businessObjID, _ := primitive.ObjectIDFromHex(businessID)
+	now := time.Now()
 	doc["business_id"] = businessObjID
 	doc["synced"] = true
-	doc["synced_at"] = time.Now()
-	doc["created_at"] = time.Now()
-	doc["updated_at"] = time.Now()
-
-	result, err := collection.InsertOne(ctx, doc)
-	if err != nil {
-		return "", err
+	doc["synced_at"] = now
+	doc["created_at"] = now
+	doc["updated_at"] = now
+
+	// Sales made offline are credited to whoever was clocked in on the device
+	if entityType == "sale" {
+		if _, ok := doc["device_id"]; !ok && deviceID != "" {
+			doc["device_id"] = deviceID
+		}
 	}
 
-	return result.InsertedID.(primitive.ObjectID).Hex(), nil
+	return doc, nil
 }
 
-func (s *syncService) updateItem(id, entityType string, data interface{}) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	collection := s.db.Collection(fmt.Sprintf("%ss", entityType))
-
-	objID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
-	}
+// syncVersioned are the synced entities that carry a version
+var syncVersioned = map[string]bool{"product": true}
 
+// updateDoc is the $set of a synced update, and the version the device made it
+// on if it sent one
+func updateDoc(entityType string, data interface{}) (bson.M, *int64, error) {
 	// Convert data to BSON
 	bsonData, err := bson.Marshal(data)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	var updateDoc bson.M
-	if err := bson.Unmarshal(bsonData, &updateDoc); err != nil {
-		return err
+	var doc bson.M
+	if err := bson.Unmarshal(bsonData, &doc); err != nil {
+		return nil, nil, err
 	}
+	if err := normalizeSyncMoney(entityType, doc); err != nil {
+		return nil, nil, err
+	}
+	// The client cannot move a record to another ID or shop, set its version or
+	// its stock, which only moves with stock movements
+	var version *int64
+	if v, ok := doc["version"]; ok && v != nil {
+		n, ok := syncInt(v)
+		if !ok {
+			return nil, nil, fmt.Errorf("version is not a number")
+		}
+		version = &n
+	}
+	delete(doc, "_id")
+	delete(doc, "business_id")
+	delete(doc, "version")
+	delete(doc, "stock")
 
 	// Add update timestamp
-	updateDoc["updated_at"] = time.Now()
-	updateDoc["synced"] = true
-	updateDoc["synced_at"] = time.Now()
-
-	filter := bson.M{"_id": objID}
-	update := bson.M{"$set": updateDoc}
+	now := time.Now()
+	doc["updated_at"] = now
+	doc["synced"] = true
+	doc["synced_at"] = now
 
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
+	return doc, version, nil
 }
 
-func (s *syncService) deleteItem(id, entityType string) error {
-	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
-	defer cancel()
-
-	collection := s.db.Collection(fmt.Sprintf("%ss", entityType))
-
-	objID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return err
+// syncInt is a whole number sent by a client, whichever BSON type it came as
+func syncInt(value interface{}) (int64, bool) {
+	switch v := value.(type) {
+	case int32:
+		return int64(v), true
+	case int64:
+		return v, true
+	case float64:
+		return int64(v), v == float64(int64(v))
 	}
+	return 0, false
+}
 
-	filter := bson.M{"_id": objID}
-
-	// Soft delete by updating status based on entity type
-	var status string
+// deleteUpdate soft deletes a synced record by its status
+func deleteUpdate(entityType string) bson.M {
+	status := "deleted"
 	switch entityType {
-	case "sale":
+	case "sale", "expense":
 		status = "voided"
-	case "expense":
-		status = "voided"
-	case "product":
-		status = "deleted"
-	default:
-		status = "deleted"
 	}
 
-	update := bson.M{"$set": bson.M{
+	now := time.Now()
+	return bson.M{"$set": bson.M{
 		"status":     status,
 		"synced":     true,
-		"synced_at":  time.Now(),
-		"updated_at": time.Now(),
+		"synced_at":  now,
+		"updated_at": now,
 	}}
+}
+
+// syncMoneyFields are the amounts on each synced entity. Clients send them as
+// float numbers, or on newer versions as decimal strings; both are stored as
+// decimals, like amounts saved through the API.
+var syncMoneyFields = map[string][]string{
+	"sale":    {"unit_price", "total_amount", "discount", "tax", "final_amount", "cash_rounding", "unit_cost"},
+	"expense": {"amount"},
+	"product": {"cost_price", "selling_price"},
+}
+
+var syncPaymentMoneyFields = []string{"amount", "foreign_amount", "change"}
+
+func normalizeSyncMoney(entityType string, doc bson.M) error {
+	if err := normalizeMoneyFields(doc, syncMoneyFields[entityType]); err != nil {
+		return err
+	}
+	if entityType != "sale" {
+		return nil
+	}
+	payments, _ := doc["payments"].(bson.A)
+	for _, payment := range payments {
+		if p, ok := payment.(bson.M); ok {
+			if err := normalizeMoneyFields(p, syncPaymentMoneyFields); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-	_, err = collection.UpdateOne(ctx, filter, update)
-	return err
+func normalizeMoneyFields(doc bson.M, keys []string) error {
+	for _, key := range keys {
+		value, ok := doc[key]
+		if !ok || value == nil {
+			continue
+		}
+		amount, ok := Domain.MoneyFromValue(value)
+		if !ok {
+			return fmt.Errorf("%s is not an amount", key)
+		}
+		doc[key] = amount
+	}
+	return nil
 }
 
 func (s *syncService) GetSyncStatus(businessID string) (*Domain.SyncStatus, error) {
@@ -0,0 +1,93 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// TelegramBot sends messages as the shop owners' Telegram bot
+type TelegramBot interface {
+	// Username is the bot's @name without the @, for links that open it
+	Username() string
+	SendMessage(ctx context.Context, chatID int64, text string) error
+}
+
+// ErrTelegramChatGone means the bot can no longer write to the chat, because it was
+// blocked, removed from the group or the chat was deleted. Retrying will not help.
+var ErrTelegramChatGone = errors.New("telegram chat is no longer reachable")
+
+// NewTelegramBot talks to the Bot API when TELEGRAM_BOT_TOKEN is set, and otherwise
+// writes messages to the server log, for development
+func NewTelegramBot(cfg TelegramConfig) TelegramBot {
+	if cfg.BotToken == "" {
+		return &logTelegramBot{username: cfg.BotUsername}
+	}
+	return &telegramBot{
+		client:   &http.Client{Timeout: 15 * time.Second},
+		baseURL:  cfg.APIURL + "/bot" + cfg.BotToken,
+		username: cfg.BotUsername,
+	}
+}
+
+type logTelegramBot struct {
+	username string
+}
+
+func (b *logTelegramBot) Username() string { return b.username }
+
+func (b *logTelegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	log.Printf("Telegram to=%d message=%q", chatID, text)
+	return nil
+}
+
+type telegramBot struct {
+	client   *http.Client
+	baseURL  string
+	username string
+}
+
+func (b *telegramBot) Username() string { return b.username }
+
+func (b *telegramBot) SendMessage(ctx context.Context, chatID int64, text string) error {
+	body, err := json.Marshal(map[string]interface{}{
+		"chat_id":                  chatID,
+		"text":                     text,
+		"disable_web_page_preview": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, b.baseURL+"/sendMessage", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := b.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("telegram request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	var result struct {
+		OK          bool   `json:"ok"`
+		Description string `json:"description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("telegram returned status %d", resp.StatusCode)
+	}
+	if !result.OK {
+		if resp.StatusCode == http.StatusForbidden {
+			return fmt.Errorf("%w: %s", ErrTelegramChatGone, result.Description)
+		}
+		return fmt.Errorf("telegram: %s", result.Description)
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package Infrastructure
+
+import (
+	"fmt"
+	"sync"
+
+	Domain "ShopOps/Domain"
+)
+
+// TelemetryBuffer holds telemetry writes in memory until the next flush, merging
+// writes about the same subject, so a bridge polling every few seconds or a till
+// ringing up a queue of customers costs the primary one write per flush interval.
+//
+// It trades durability for write volume: writes still buffered when the process
+// dies are lost, a batch the database refuses is dropped rather than retried, as
+// the next poll reports the same again, and beyond maxPending subjects new ones are
+// dropped until the next flush. Nothing that must not be lost belongs here.
+type TelemetryBuffer struct {
+	repo       Domain.TelemetryRepository
+	maxPending int
+
+	mu      sync.Mutex
+	pending map[string]*Domain.TelemetryWrite
+}
+
+var TelemetryWrites = NewCounterVec("shopops_telemetry_writes_total",
+	"Telemetry writes by outcome (buffered, merged, dropped, written, failed)", "outcome")
+
+func NewTelemetryBuffer(repo Domain.TelemetryRepository, maxPending int) *TelemetryBuffer {
+	return &TelemetryBuffer{
+		repo:       repo,
+		maxPending: maxPending,
+		pending:    make(map[string]*Domain.TelemetryWrite),
+	}
+}
+
+// Record queues the write for the next flush
+func (b *TelemetryBuffer) Record(write Domain.TelemetryWrite) {
+	key := write.Key()
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if queued, ok := b.pending[key]; ok {
+		queued.Merge(write)
+		TelemetryWrites.Inc("merged")
+		return
+	}
+	if len(b.pending) >= b.maxPending {
+		TelemetryWrites.Inc("dropped")
+		return
+	}
+	b.pending[key] = &write
+	TelemetryWrites.Inc("buffered")
+}
+
+// Flush writes everything buffered so far in one batch. It runs periodically and
+// once more at shutdown, after requests have drained.
+func (b *TelemetryBuffer) Flush() error {
+	b.mu.Lock()
+	pending := b.pending
+	b.pending = make(map[string]*Domain.TelemetryWrite, len(pending))
+	b.mu.Unlock()
+
+	if len(pending) == 0 {
+		return nil
+	}
+
+	writes := make([]Domain.TelemetryWrite, 0, len(pending))
+	for _, write := range pending {
+		writes = append(writes, *write)
+	}
+
+	if err := b.repo.WriteBatch(writes); err != nil {
+		TelemetryWrites.Add(float64(len(writes)), "failed")
+		return fmt.Errorf("dropped %d telemetry writes: %w", len(writes), err)
+	}
+	TelemetryWrites.Add(float64(len(writes)), "written")
+	return nil
+}
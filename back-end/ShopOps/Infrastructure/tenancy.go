@@ -0,0 +1,220 @@
+package Infrastructure
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"slices"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// Every route under /businesses/:businessId acts on one shop, the tenant. The
+// tenancy middleware checks the caller belongs to that shop before any handler
+// runs, so a handler cannot serve another shop's data by trusting the path.
+
+// TenantRole is how the caller belongs to the shop
+type TenantRole string
+
+const (
+	TenantRoleOwner    TenantRole = "owner"
+	TenantRoleEmployee TenantRole = "employee"
+	TenantRoleAdmin    TenantRole = "admin" // Platform administrators may act on any shop
+)
+
+// Tenant is the shop a request acts on and the caller's place in it
+type Tenant struct {
+	BusinessID  primitive.ObjectID
+	UserID      string
+	Role        TenantRole
+	EmployeeID  *primitive.ObjectID         // Set for employees
+	Permissions []Domain.EmployeePermission // What the owner granted the employee
+	Language    Domain.Language             // The shop's language, for requests that do not ask for one
+}
+
+// CanGrantPermissions reports whether the caller may change what employees are
+// permitted: the owner, or an administrator
+func (t *Tenant) CanGrantPermissions() bool {
+	return t.Role == TenantRoleOwner || t.Role == TenantRoleAdmin
+}
+
+// CanViewPII reports whether the caller sees customers' names and phone numbers
+// in exports: the owner, and employees granted Domain.PermissionViewPII.
+// Administrators acting on a shop for support see them masked.
+func (t *Tenant) CanViewPII() bool {
+	if t.Role == TenantRoleOwner {
+		return true
+	}
+	return t.Role == TenantRoleEmployee && slices.Contains(t.Permissions, Domain.PermissionViewPII)
+}
+
+type tenantKey struct{}
+
+type businessKey struct{}
+
+// Memberships change rarely, and removed employees losing access a minute late is
+// fine, so lookups are cached rather than read from the database on every request.
+const tenantCacheTTL = time.Minute
+
+// TenancyMiddleware resolves the request's shop from the businessId path parameter
+// and the authenticated user, and rejects callers who are neither its owner, one
+// of its active employees nor an administrator. The tenant is available to
+// handlers through CurrentTenant and to usecases through TenantFromContext, and
+// scopes repository lookups made with the request context through BusinessFromContext.
+func TenancyMiddleware(businessRepo Domain.BusinessRepository, employeeRepo Domain.EmployeeRepository) gin.HandlerFunc {
+	resolver := NewTenantResolver(businessRepo, employeeRepo)
+
+	return func(c *gin.Context) {
+		businessID := c.Param("businessId")
+		tenant, err := resolver.Resolve(businessID, c.GetString("userID"), c.GetString("role"))
+		if err != nil {
+			var appErr *Domain.AppError
+			if errors.As(err, &appErr) {
+				AbortWithError(c, appErr)
+				return
+			}
+			JSONError(c, http.StatusInternalServerError, err, "")
+			c.Abort()
+			return
+		}
+
+		c.Set("businessID", businessID)
+		c.Set("tenant", tenant)
+		c.Request = c.Request.WithContext(WithTenant(c.Request.Context(), tenant))
+		useShopLanguage(c, tenant.Language)
+
+		c.Next()
+	}
+}
+
+// TenantResolver finds how a user belongs to a shop, caching the answer for
+// tenantCacheTTL. The REST middleware and the gRPC interceptor each keep one.
+type TenantResolver struct {
+	businessRepo Domain.BusinessRepository
+	employeeRepo Domain.EmployeeRepository
+	cache        *TTLCache
+}
+
+func NewTenantResolver(businessRepo Domain.BusinessRepository, employeeRepo Domain.EmployeeRepository) *TenantResolver {
+	return &TenantResolver{businessRepo: businessRepo, employeeRepo: employeeRepo, cache: NewTTLCache(tenantCacheTTL)}
+}
+
+// Resolve returns the caller's tenancy of the shop, or an access denied error when
+// they are neither its owner, one of its active employees nor an administrator
+func (r *TenantResolver) Resolve(businessID, userID, role string) (*Tenant, error) {
+	if _, err := primitive.ObjectIDFromHex(businessID); err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "Invalid business ID")
+	}
+
+	key := userID + ":" + businessID
+	if cached, _, ok := r.cache.Get(key); ok {
+		return cached.(*Tenant), nil
+	}
+
+	tenant, err := resolveTenant(r.businessRepo, r.employeeRepo, businessID, userID, role)
+	if err != nil {
+		return nil, err
+	}
+	if tenant == nil {
+		return nil, Domain.AccessDeniedError("You do not have access to this business")
+	}
+	r.cache.Set(key, tenant)
+	return tenant, nil
+}
+
+// resolveTenant returns nil without an error when the user does not belong to the shop
+func resolveTenant(businessRepo Domain.BusinessRepository, employeeRepo Domain.EmployeeRepository, businessID, userID, role string) (*Tenant, error) {
+	business, err := businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("Business not found")
+	}
+
+	tenant := &Tenant{BusinessID: business.ID, UserID: userID, Language: business.Language}
+	switch {
+	case business.UserID.Hex() == userID:
+		tenant.Role = TenantRoleOwner
+	case role == string(Domain.RoleAdmin):
+		tenant.Role = TenantRoleAdmin
+	default:
+		employee, err := employeeRepo.FindByUserID(businessID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if employee == nil || employee.Status != Domain.EmployeeStatusActive {
+			return nil, nil
+		}
+		tenant.Role = TenantRoleEmployee
+		tenant.EmployeeID = &employee.ID
+		tenant.Permissions = employee.Permissions
+	}
+
+	return tenant, nil
+}
+
+// RequireTenantRole limits a route to callers with one of roles in the shop. It must
+// run after TenancyMiddleware.
+func RequireTenantRole(roles ...TenantRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		tenant, ok := CurrentTenant(c)
+		if ok {
+			for _, role := range roles {
+				if tenant.Role == role {
+					c.Next()
+					return
+				}
+			}
+		}
+		AbortWithError(c, Domain.AccessDeniedError("Only the business owner can do this"))
+	}
+}
+
+// CurrentTenant is the shop the request acts on, set by TenancyMiddleware
+func CurrentTenant(c *gin.Context) (*Tenant, bool) {
+	if tenant, ok := c.Get("tenant"); ok {
+		return tenant.(*Tenant), true
+	}
+	return nil, false
+}
+
+// WithTenant returns ctx acting on tenant's shop
+func WithTenant(ctx context.Context, tenant *Tenant) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// WithBusiness returns ctx acting on the shop, for usecases and jobs that have its
+// ID but no caller to resolve
+func WithBusiness(ctx context.Context, businessID string) context.Context {
+	return context.WithValue(ctx, businessKey{}, businessID)
+}
+
+// BusinessFromContext is the shop ctx acts on: the one set by WithBusiness, else
+// its tenant's; false when it has neither, or the ID is invalid
+func BusinessFromContext(ctx context.Context) (primitive.ObjectID, bool) {
+	if ctx == nil {
+		return primitive.NilObjectID, false
+	}
+	if businessID, ok := ctx.Value(businessKey{}).(string); ok {
+		objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+		return objBusinessID, err == nil
+	}
+	if tenant, ok := TenantFromContext(ctx); ok {
+		return tenant.BusinessID, true
+	}
+	return primitive.NilObjectID, false
+}
+
+// TenantFromContext is the shop a request context acts on
+func TenantFromContext(ctx context.Context) (*Tenant, bool) {
+	if ctx == nil {
+		return nil, false
+	}
+	tenant, ok := ctx.Value(tenantKey{}).(*Tenant)
+	return tenant, ok
+}
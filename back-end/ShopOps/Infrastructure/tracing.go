@@ -0,0 +1,430 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Tracing follows the OpenTelemetry model: spans share a trace ID, propagate
+// across services in the W3C traceparent header, and are exported as OTLP/HTTP
+// JSON to OTEL_EXPORTER_OTLP_ENDPOINT. With no endpoint configured spans are not
+// recorded at all. The exporter is written against the wire format so the SDK
+// does not need to be vendored.
+
+type SpanKind int
+
+const (
+	SpanKindInternal SpanKind = 1
+	SpanKindServer   SpanKind = 2
+	SpanKindClient   SpanKind = 3
+)
+
+const traceparentHeader = "traceparent"
+
+type spanKey struct{}
+
+// Span is one timed operation within a trace. A nil *Span is valid and does nothing,
+// so callers never need to check whether tracing is enabled.
+type Span struct {
+	traceID  [16]byte
+	spanID   [8]byte
+	parentID [8]byte
+	name     string
+	kind     SpanKind
+	start    time.Time
+	end      time.Time
+
+	mu         sync.Mutex
+	attributes map[string]interface{}
+	err        string
+	ended      bool
+}
+
+type tracer struct {
+	endpoint    string
+	serviceName string
+	ratio       float64
+	client      *http.Client
+	queue       chan *Span
+	flush       chan chan struct{}
+}
+
+var defaultTracer *tracer
+
+// InitTracing starts the span exporter when OTEL_EXPORTER_OTLP_ENDPOINT is set. Call it
+// once at startup.
+func InitTracing(cfg TracingConfig) {
+	defaultTracer = newTracer(cfg)
+	if defaultTracer != nil {
+		Logger.Info("tracing enabled", slog.String("endpoint", defaultTracer.endpoint), slog.Float64("sample_ratio", defaultTracer.ratio))
+	}
+}
+
+func newTracer(cfg TracingConfig) *tracer {
+	endpoint := strings.TrimRight(cfg.Endpoint, "/")
+	if endpoint == "" {
+		return nil
+	}
+
+	t := &tracer{
+		endpoint:    endpoint + "/v1/traces",
+		serviceName: cfg.ServiceName,
+		ratio:       cfg.SampleRatio,
+		client:      &http.Client{Timeout: 10 * time.Second},
+		queue:       make(chan *Span, 4096),
+		flush:       make(chan chan struct{}),
+	}
+	go t.export()
+	return t
+}
+
+// StartSpan starts a span as a child of the span in ctx, or a new trace when there is none.
+// The returned context carries the new span; always End it.
+func StartSpan(ctx context.Context, name string, kind SpanKind) (context.Context, *Span) {
+	if defaultTracer == nil {
+		return ctx, nil
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	span := &Span{name: name, kind: kind, start: time.Now(), attributes: make(map[string]interface{})}
+	if parent := SpanFrom(ctx); parent != nil {
+		span.traceID = parent.traceID
+		span.parentID = parent.spanID
+	} else {
+		if !defaultTracer.sample() {
+			return ctx, nil
+		}
+		_, _ = rand.Read(span.traceID[:])
+	}
+	_, _ = rand.Read(span.spanID[:])
+
+	return context.WithValue(ctx, spanKey{}, span), span
+}
+
+// SpanFrom returns the span carried by ctx, or nil
+func SpanFrom(ctx context.Context) *Span {
+	if ctx == nil {
+		return nil
+	}
+	span, _ := ctx.Value(spanKey{}).(*Span)
+	return span
+}
+
+func (s *Span) SetAttribute(key string, value interface{}) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.attributes[key] = value
+}
+
+// RecordError marks the span failed; nil errors are ignored
+func (s *Span) RecordError(err error) {
+	if s == nil || err == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.err = err.Error()
+}
+
+func (s *Span) End() {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	if s.ended {
+		s.mu.Unlock()
+		return
+	}
+	s.ended = true
+	s.end = time.Now()
+	s.mu.Unlock()
+
+	select {
+	case defaultTracer.queue <- s:
+	default:
+		// Exporting must never slow requests down; drop when the collector falls behind
+	}
+}
+
+func (s *Span) TraceID() string {
+	if s == nil {
+		return ""
+	}
+	return hex.EncodeToString(s.traceID[:])
+}
+
+// Traceparent is the W3C header value that continues this trace in another service
+func (s *Span) Traceparent() string {
+	if s == nil {
+		return ""
+	}
+	return fmt.Sprintf("00-%x-%x-01", s.traceID, s.spanID)
+}
+
+// TracingMiddleware starts a server span for each request, continuing the caller's
+// trace when a traceparent header is sent
+func TracingMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if defaultTracer == nil {
+			c.Next()
+			return
+		}
+
+		ctx := c.Request.Context()
+		if parent, ok := parseTraceparent(c.GetHeader(traceparentHeader)); ok {
+			ctx = context.WithValue(ctx, spanKey{}, parent)
+		}
+
+		route := c.FullPath()
+		if route == "" {
+			route = "unmatched"
+		}
+		ctx, span := StartSpan(ctx, c.Request.Method+" "+route, SpanKindServer)
+		if span == nil {
+			c.Next()
+			return
+		}
+		c.Request = c.Request.WithContext(ctx)
+		c.Header(traceparentHeader, span.Traceparent())
+
+		c.Next()
+
+		status := c.Writer.Status()
+		span.SetAttribute("http.request.method", c.Request.Method)
+		span.SetAttribute("http.route", route)
+		span.SetAttribute("http.response.status_code", status)
+		if businessID := c.Param("businessId"); businessID != "" {
+			span.SetAttribute("shopops.business_id", businessID)
+		}
+		if status >= 500 {
+			span.RecordError(fmt.Errorf("%s", http.StatusText(status)))
+		}
+		span.End()
+	}
+}
+
+// parseTraceparent reads a version 00 traceparent into a remote parent span
+func parseTraceparent(value string) (*Span, bool) {
+	parts := strings.Split(value, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 {
+		return nil, false
+	}
+
+	parent := &Span{ended: true}
+	if _, err := hex.Decode(parent.traceID[:], []byte(parts[1])); err != nil {
+		return nil, false
+	}
+	if _, err := hex.Decode(parent.spanID[:], []byte(parts[2])); err != nil {
+		return nil, false
+	}
+	if parent.traceID == [16]byte{} || parent.spanID == [8]byte{} {
+		return nil, false
+	}
+	return parent, true
+}
+
+// ShutdownTracing exports the spans still queued, waiting until ctx is done at most
+func ShutdownTracing(ctx context.Context) {
+	if defaultTracer == nil {
+		return
+	}
+	done := make(chan struct{})
+	select {
+	case defaultTracer.flush <- done:
+	case <-ctx.Done():
+		return
+	}
+	select {
+	case <-done:
+	case <-ctx.Done():
+	}
+}
+
+func (t *tracer) sample() bool {
+	if t.ratio >= 1 {
+		return true
+	}
+	var b [8]byte
+	_, _ = rand.Read(b[:])
+	n := uint64(0)
+	for _, v := range b {
+		n = n<<8 | uint64(v)
+	}
+	return float64(n)/float64(^uint64(0)) < t.ratio
+}
+
+const (
+	traceBatchSize     = 512
+	traceFlushInterval = 5 * time.Second
+)
+
+func (t *tracer) export() {
+	ticker := time.NewTicker(traceFlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]*Span, 0, traceBatchSize)
+	send := func() {
+		if len(batch) == 0 {
+			return
+		}
+		if err := t.post(batch); err != nil {
+			Logger.Warn("failed to export spans", slog.Int("spans", len(batch)), slog.String("error", err.Error()))
+		}
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case span := <-t.queue:
+			batch = append(batch, span)
+			if len(batch) >= traceBatchSize {
+				send()
+			}
+		case <-ticker.C:
+			send()
+		case done := <-t.flush:
+			for drained := false; !drained; {
+				select {
+				case span := <-t.queue:
+					batch = append(batch, span)
+				default:
+					drained = true
+				}
+			}
+			send()
+			close(done)
+		}
+	}
+}
+
+// OTLP/JSON payload, see opentelemetry-proto trace/v1
+type otlpValue struct {
+	StringValue *string  `json:"stringValue,omitempty"`
+	IntValue    *string  `json:"intValue,omitempty"`
+	DoubleValue *float64 `json:"doubleValue,omitempty"`
+	BoolValue   *bool    `json:"boolValue,omitempty"`
+}
+
+type otlpAttribute struct {
+	Key   string    `json:"key"`
+	Value otlpValue `json:"value"`
+}
+
+type otlpStatus struct {
+	Code    int    `json:"code"`
+	Message string `json:"message,omitempty"`
+}
+
+type otlpSpan struct {
+	TraceID           string          `json:"traceId"`
+	SpanID            string          `json:"spanId"`
+	ParentSpanID      string          `json:"parentSpanId,omitempty"`
+	Name              string          `json:"name"`
+	Kind              SpanKind        `json:"kind"`
+	StartTimeUnixNano string          `json:"startTimeUnixNano"`
+	EndTimeUnixNano   string          `json:"endTimeUnixNano"`
+	Attributes        []otlpAttribute `json:"attributes,omitempty"`
+	Status            otlpStatus      `json:"status"`
+}
+
+func (t *tracer) post(spans []*Span) error {
+	converted := make([]otlpSpan, 0, len(spans))
+	for _, span := range spans {
+		converted = append(converted, span.toOTLP())
+	}
+
+	serviceName := t.serviceName
+	payload := map[string]interface{}{
+		"resourceSpans": []interface{}{
+			map[string]interface{}{
+				"resource": map[string]interface{}{
+					"attributes": []otlpAttribute{{Key: "service.name", Value: otlpValue{StringValue: &serviceName}}},
+				},
+				"scopeSpans": []interface{}{
+					map[string]interface{}{
+						"scope": map[string]interface{}{"name": "ShopOps"},
+						"spans": converted,
+					},
+				},
+			},
+		},
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode spans: %w", err)
+	}
+
+	resp, err := t.client.Post(t.endpoint, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post spans: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("collector returned %s", resp.Status)
+	}
+	return nil
+}
+
+func (s *Span) toOTLP() otlpSpan {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	out := otlpSpan{
+		TraceID:           hex.EncodeToString(s.traceID[:]),
+		SpanID:            hex.EncodeToString(s.spanID[:]),
+		Name:              s.name,
+		Kind:              s.kind,
+		StartTimeUnixNano: strconv.FormatInt(s.start.UnixNano(), 10),
+		EndTimeUnixNano:   strconv.FormatInt(s.end.UnixNano(), 10),
+		Status:            otlpStatus{Code: 1},
+	}
+	if s.parentID != [8]byte{} {
+		out.ParentSpanID = hex.EncodeToString(s.parentID[:])
+	}
+	if s.err != "" {
+		out.Status = otlpStatus{Code: 2, Message: s.err}
+	}
+
+	for key, value := range s.attributes {
+		attr := otlpAttribute{Key: key}
+		switch v := value.(type) {
+		case string:
+			attr.Value.StringValue = &v
+		case int:
+			n := strconv.Itoa(v)
+			attr.Value.IntValue = &n
+		case int64:
+			n := strconv.FormatInt(v, 10)
+			attr.Value.IntValue = &n
+		case float64:
+			attr.Value.DoubleValue = &v
+		case bool:
+			attr.Value.BoolValue = &v
+		default:
+			text := fmt.Sprint(v)
+			attr.Value.StringValue = &text
+		}
+		out.Attributes = append(out.Attributes, attr)
+	}
+
+	return out
+}
@@ -0,0 +1,189 @@
+package Infrastructure
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"strings"
+	"sync"
+
+	Domain "ShopOps/Domain"
+
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+)
+
+// Request structs declare their rules in `validate` tags. Gin's binder is switched
+// over to that tag, so every handler that binds a body enforces them, and Validate
+// applies the same rules to values built elsewhere. Failures become a 400
+// validation_failed error listing each field, named as the client sent it.
+
+var (
+	validatorOnce sync.Once
+	validate      *validator.Validate
+)
+
+var (
+	phonePunctuation = strings.NewReplacer(" ", "", "-", "", "(", "", ")", "", ".", "")
+	phoneFormat      = regexp.MustCompile(`^\+?\d{7,15}$`)
+	tinFormat        = regexp.MustCompile(`^\d{10}$`)
+)
+
+// Largest amount accepted anywhere; anything bigger is a typo or an overflow attempt
+const maxMoneyAmount = 1e12
+
+// RegisterValidators configures request validation. Call it once at startup, before
+// requests are served.
+func RegisterValidators() {
+	validatorEngine()
+}
+
+func validatorEngine() *validator.Validate {
+	validatorOnce.Do(func() {
+		engine, ok := binding.Validator.Engine().(*validator.Validate)
+		if !ok {
+			engine = validator.New()
+		}
+		engine.SetTagName("validate")
+
+		// Report fields by their JSON names
+		engine.RegisterTagNameFunc(func(field reflect.StructField) string {
+			name := strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+			if name == "-" {
+				return ""
+			}
+			if name == "" {
+				return field.Name
+			}
+			return name
+		})
+
+		_ = engine.RegisterValidation("phone", validatePhone)
+		_ = engine.RegisterValidation("tin", validateTIN)
+		_ = engine.RegisterValidation("money", validateMoney)
+		validate = engine
+	})
+	return validate
+}
+
+// Validate checks v against its `validate` tags, returning a validation_failed
+// *Domain.AppError listing every failing field
+func Validate(v interface{}) error {
+	if err := validatorEngine().Struct(v); err != nil {
+		if appErr, ok := validationAppError(err); ok {
+			return appErr
+		}
+		return err
+	}
+	return nil
+}
+
+// validatePhone accepts international or local numbers, with the spaces, dashes and
+// brackets people type; normalizing to E.164 happens in the usecases
+func validatePhone(fl validator.FieldLevel) bool {
+	return phoneFormat.MatchString(phonePunctuation.Replace(strings.TrimSpace(fl.Field().String())))
+}
+
+// validateTIN accepts Ethiopian taxpayer identification numbers, which are ten digits
+func validateTIN(fl validator.FieldLevel) bool {
+	return tinFormat.MatchString(phonePunctuation.Replace(strings.TrimSpace(fl.Field().String())))
+}
+
+// validateMoney accepts non-negative amounts with at most two decimal places
+func validateMoney(fl validator.FieldLevel) bool {
+	var amount float64
+	if m, ok := fl.Field().Interface().(Domain.Money); ok {
+		// Already whole cents; only the range is left to check
+		return m >= 0 && m.Float64() <= maxMoneyAmount
+	}
+	switch fl.Field().Kind() {
+	case reflect.Float32, reflect.Float64:
+		amount = fl.Field().Float()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		amount = float64(fl.Field().Int())
+	default:
+		return false
+	}
+	if math.IsNaN(amount) || math.IsInf(amount, 0) || amount < 0 || amount > maxMoneyAmount {
+		return false
+	}
+	cents := amount * 100
+	return math.Abs(cents-math.Round(cents)) < 1e-6
+}
+
+func validationAppError(err error) (*Domain.AppError, bool) {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return nil, false
+	}
+
+	details := make([]Domain.ErrorDetail, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		details = append(details, Domain.ErrorDetail{
+			Field:   fieldPath(fieldErr),
+			Code:    fieldErr.Tag(),
+			Param:   fieldErr.Param(),
+			Message: fieldMessage(fieldErr),
+		})
+	}
+
+	message := "Validation failed"
+	if len(details) > 0 {
+		message = details[0].Message
+		if len(details) > 1 {
+			message = fmt.Sprintf("%s (and %d more)", message, len(details)-1)
+		}
+	}
+	return Domain.ValidationError(message, details...), true
+}
+
+// fieldPath is the field's JSON path without the struct name, such as items[0].quantity
+func fieldPath(fieldErr validator.FieldError) string {
+	namespace := fieldErr.Namespace()
+	if i := strings.Index(namespace, "."); i >= 0 {
+		return namespace[i+1:]
+	}
+	return namespace
+}
+
+func fieldMessage(fieldErr validator.FieldError) string {
+	field := fieldPath(fieldErr)
+	param := fieldErr.Param()
+
+	switch fieldErr.Tag() {
+	case "required":
+		return field + " is required"
+	case "gt":
+		return fmt.Sprintf("%s must be greater than %s", field, param)
+	case "gte":
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "lt":
+		return fmt.Sprintf("%s must be less than %s", field, param)
+	case "lte":
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	case "min":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at least %s characters", field, param)
+		}
+		return fmt.Sprintf("%s must be at least %s", field, param)
+	case "max":
+		if fieldErr.Kind() == reflect.String {
+			return fmt.Sprintf("%s must be at most %s characters", field, param)
+		}
+		return fmt.Sprintf("%s must be at most %s", field, param)
+	case "oneof":
+		return fmt.Sprintf("%s must be one of: %s", field, strings.ReplaceAll(param, " ", ", "))
+	case "email":
+		return field + " must be a valid email address"
+	case "phone":
+		return field + " must be a valid phone number"
+	case "tin":
+		return field + " must be a 10 digit TIN"
+	case "money":
+		return field + " must be a non-negative amount with at most two decimal places"
+	default:
+		return fmt.Sprintf("%s is invalid (%s)", field, fieldErr.Tag())
+	}
+}
@@ -0,0 +1,126 @@
+package Infrastructure
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// Webhook deliveries are POSTed as JSON with these headers. Receivers verify a
+// delivery by computing HMAC-SHA256 with their secret over "<timestamp>.<body>" and
+// comparing it with the v1 value of the signature header; checking the timestamp is
+// recent stops an old delivery being replayed at them.
+const (
+	WebhookHeaderEvent     = "X-ShopOps-Event"
+	WebhookHeaderDelivery  = "X-ShopOps-Delivery"
+	WebhookHeaderSignature = "X-ShopOps-Signature" // t=<unix seconds>,v1=<hex HMAC>
+)
+
+const webhookResponseLimit = 1024
+
+var WebhookDeliveries = NewCounterVec("shopops_webhook_deliveries_total",
+	"Webhook delivery attempts by event and outcome", "event", "outcome")
+
+// ErrWebhookTargetForbidden is returned for URLs resolving to loopback, private or
+// link-local addresses, which a shop could otherwise use to reach our own network
+var ErrWebhookTargetForbidden = errors.New("webhook URL resolves to a private address")
+
+// WebhookResponse is what the receiver answered
+type WebhookResponse struct {
+	StatusCode int
+	Body       string // Truncated to the first KB
+}
+
+// WebhookSender POSTs signed webhook deliveries
+type WebhookSender interface {
+	Send(ctx context.Context, url, secret, event, deliveryID string, body []byte) (*WebhookResponse, error)
+}
+
+type webhookSender struct {
+	client *http.Client
+}
+
+func NewWebhookSender(cfg WebhookConfig) WebhookSender {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateTargets {
+		// Checked on the resolved address at connect time, so DNS cannot be changed
+		// between validating the URL and sending to it
+		dialer.Control = func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			ip := net.ParseIP(host)
+			if ip == nil || ip.IsLoopback() || ip.IsPrivate() || ip.IsLinkLocalUnicast() ||
+				ip.IsLinkLocalMulticast() || ip.IsUnspecified() || ip.IsMulticast() {
+				return ErrWebhookTargetForbidden
+			}
+			return nil
+		}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &webhookSender{
+		client: &http.Client{
+			Timeout:   cfg.Timeout,
+			Transport: transport,
+			// A redirect would send the delivery somewhere the shop did not register
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		},
+	}
+}
+
+func (s *webhookSender) Send(ctx context.Context, url, secret, event, deliveryID string, body []byte) (*WebhookResponse, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook request: %w", err)
+	}
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "ShopOps-Webhooks/1.0")
+	req.Header.Set(WebhookHeaderEvent, event)
+	req.Header.Set(WebhookHeaderDelivery, deliveryID)
+	req.Header.Set(WebhookHeaderSignature, "t="+timestamp+",v1="+SignWebhook(secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	reply, _ := io.ReadAll(io.LimitReader(resp.Body, webhookResponseLimit))
+	return &WebhookResponse{StatusCode: resp.StatusCode, Body: string(reply)}, nil
+}
+
+// SignWebhook is the v1 signature of a delivery body sent at timestamp
+func SignWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// NewWebhookSecret generates a signing secret for a webhook
+func NewWebhookSecret() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+	return "whsec_" + hex.EncodeToString(buf), nil
+}
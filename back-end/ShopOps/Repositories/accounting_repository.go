@@ -0,0 +1,354 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AccountingRepository struct {
+	settingsCollection *mongo.Collection
+	accountsCollection *mongo.Collection
+	entriesCollection  *mongo.Collection
+}
+
+func NewAccountingRepository(db *mongo.Database) Domain.AccountingRepository {
+	return &AccountingRepository{
+		settingsCollection: db.Collection("accounting_settings"),
+		accountsCollection: db.Collection("accounts"),
+		entriesCollection:  db.Collection("journal_entries"),
+	}
+}
+
+func (r *AccountingRepository) FindSettings(businessID string) (*Domain.AccountingSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var settings Domain.AccountingSettings
+	err = r.settingsCollection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find accounting settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *AccountingRepository) SaveSettings(settings *Domain.AccountingSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	_, err := r.settingsCollection.UpdateOne(ctx,
+		bson.M{"business_id": settings.BusinessID},
+		bson.M{"$set": settings},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save accounting settings: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountingRepository) FindEnabledSettings() ([]Domain.AccountingSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.settingsCollection.Find(ctx, bson.M{"enabled": true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounting settings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var settings []Domain.AccountingSettings
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode accounting settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *AccountingRepository) CreateAccounts(accounts []Domain.Account) error {
+	if len(accounts) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	docs := make([]interface{}, len(accounts))
+	for i := range accounts {
+		accounts[i].ID = primitive.NewObjectID()
+		accounts[i].CreatedAt = now
+		accounts[i].UpdatedAt = now
+		docs[i] = accounts[i]
+	}
+
+	if _, err := r.accountsCollection.InsertMany(ctx, docs); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return Domain.NewAppError(Domain.ErrCodeConflict, "an account with this code or role already exists")
+		}
+		return fmt.Errorf("failed to create accounts: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountingRepository) FindAccounts(businessID string) ([]Domain.Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.accountsCollection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"code": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find accounts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var accounts []Domain.Account
+	if err := cursor.All(ctx, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to decode accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+func (r *AccountingRepository) FindAccountByID(id string) (*Domain.Account, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid account ID: %w", err)
+	}
+
+	var account Domain.Account
+	err = r.accountsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find account: %w", err)
+	}
+
+	return &account, nil
+}
+
+func (r *AccountingRepository) UpdateAccount(account *Domain.Account) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	account.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"code":       account.Code,
+			"name":       account.Name,
+			"active":     account.Active,
+			"updated_at": account.UpdatedAt,
+		},
+	}
+	if account.Role != "" {
+		_, err := r.accountsCollection.UpdateMany(ctx,
+			bson.M{"business_id": account.BusinessID, "role": account.Role, "_id": bson.M{"$ne": account.ID}},
+			bson.M{"$unset": bson.M{"role": ""}, "$set": bson.M{"updated_at": account.UpdatedAt}},
+		)
+		if err != nil {
+			return fmt.Errorf("failed to move account role: %w", err)
+		}
+		update["$set"].(bson.M)["role"] = account.Role
+	} else {
+		update["$unset"] = bson.M{"role": ""}
+	}
+
+	if _, err := r.accountsCollection.UpdateOne(ctx, bson.M{"_id": account.ID}, update); err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return Domain.NewAppError(Domain.ErrCodeConflict, "another account has this code")
+		}
+		return fmt.Errorf("failed to update account: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountingRepository) FindOpenEntries(businessID primitive.ObjectID, source Domain.JournalSource, sourceIDs []primitive.ObjectID) (map[primitive.ObjectID]Domain.JournalEntry, error) {
+	entries := make(map[primitive.ObjectID]Domain.JournalEntry, len(sourceIDs))
+	if len(sourceIDs) == 0 {
+		return entries, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.entriesCollection.Find(ctx, bson.M{
+		"business_id": businessID,
+		"source":      source,
+		"source_id":   bson.M{"$in": sourceIDs},
+		"open":        true,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find journal entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	for cursor.Next(ctx) {
+		var entry Domain.JournalEntry
+		if err := cursor.Decode(&entry); err != nil {
+			return nil, fmt.Errorf("failed to decode journal entry: %w", err)
+		}
+		entries[entry.SourceID] = entry
+	}
+	if err := cursor.Err(); err != nil {
+		return nil, fmt.Errorf("failed to find journal entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *AccountingRepository) CreateEntry(entry *Domain.JournalEntry) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry.CreatedAt = time.Now()
+
+	result, err := r.entriesCollection.InsertOne(ctx, entry)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create journal entry: %w", err)
+	}
+
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return true, nil
+}
+
+func (r *AccountingRepository) CloseEntry(id primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.entriesCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"open": false}}); err != nil {
+		return fmt.Errorf("failed to close journal entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AccountingRepository) FindEntries(businessID string, filters Domain.JournalFilters) ([]Domain.JournalEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.StartDate != nil || filters.EndDate != nil {
+		dateQuery := bson.M{}
+		if filters.StartDate != nil {
+			dateQuery["$gte"] = *filters.StartDate
+		}
+		if filters.EndDate != nil {
+			dateQuery["$lte"] = *filters.EndDate
+		}
+		query["date"] = dateQuery
+	}
+
+	if filters.Source != nil {
+		query["source"] = *filters.Source
+	}
+
+	if filters.SourceID != nil {
+		objSourceID, err := primitive.ObjectIDFromHex(*filters.SourceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid source ID: %w", err)
+		}
+		query["source_id"] = objSourceID
+	}
+
+	if filters.AccountID != nil {
+		objAccountID, err := primitive.ObjectIDFromHex(*filters.AccountID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid account ID: %w", err)
+		}
+		query["lines.account_id"] = objAccountID
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "_id", Value: -1}})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.entriesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find journal entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.JournalEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode journal entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *AccountingRepository) GetAccountTotals(businessID string, asOf time.Time) ([]Domain.AccountTotals, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"business_id": objBusinessID, "date": bson.M{"$lte": asOf}}}},
+		{{Key: "$unwind", Value: "$lines"}},
+		{{Key: "$group", Value: bson.M{
+			"_id":    "$lines.account_id",
+			"debit":  bson.M{"$sum": "$lines.debit"},
+			"credit": bson.M{"$sum": "$lines.credit"},
+		}}},
+	}
+
+	cursor, err := r.entriesCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to total journal entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var totals []Domain.AccountTotals
+	if err := cursor.All(ctx, &totals); err != nil {
+		return nil, fmt.Errorf("failed to decode account totals: %w", err)
+	}
+
+	return totals, nil
+}
@@ -0,0 +1,209 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AlertRepository struct {
+	collection *mongo.Collection
+	sales      *mongo.Collection
+}
+
+func NewAlertRepository(db *mongo.Database) Domain.AlertRepository {
+	return &AlertRepository{
+		collection: db.Collection("alerts"),
+		sales:      db.Collection("sales"),
+	}
+}
+
+func (r *AlertRepository) Raise(alert *Domain.Alert) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+	alert.Status = Domain.AlertStatusOpen
+
+	// Upsert on the key so a condition seen on every run is only raised once
+	filter := bson.M{"business_id": alert.BusinessID, "key": alert.Key}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"type":       alert.Type,
+			"severity":   alert.Severity,
+			"device_id":  alert.DeviceID,
+			"message":    alert.Message,
+			"value":      alert.Value,
+			"expected":   alert.Expected,
+			"status":     alert.Status,
+			"created_at": alert.CreatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return false, fmt.Errorf("failed to raise alert: %w", err)
+	}
+	if result.UpsertedID == nil {
+		return false, nil
+	}
+
+	alert.ID = result.UpsertedID.(primitive.ObjectID)
+	return true, nil
+}
+
+func (r *AlertRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Alert, error) {
+	var alert Domain.Alert
+	found, err := findByIDInBusiness(ctx, r.collection, id, &alert)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+func (r *AlertRepository) Find(businessID string, filters Domain.AlertFilters) ([]Domain.Alert, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find alerts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var alerts []Domain.Alert
+	if err := cursor.All(ctx, &alerts); err != nil {
+		return nil, fmt.Errorf("failed to decode alerts: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func (r *AlertRepository) Acknowledge(id string, userID primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid alert ID: %w", err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":          Domain.AlertStatusAcknowledged,
+			"acknowledged_by": userID,
+			"acknowledged_at": at,
+		},
+	}
+
+	_, err = r.collection.UpdateByID(ctx, objID, update)
+	if err != nil {
+		return fmt.Errorf("failed to acknowledge alert: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AlertRepository) RefundActivity(businessID string, start, end time.Time) (*Domain.RefundActivity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	// Sales carry no refund timestamp; updated_at is set when the status changes
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"status":      Domain.SaleStatusRefunded,
+				"updated_at":  bson.M{"$gte": start, "$lt": end},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":     nil,
+				"refunds": bson.M{"$sum": 1},
+				"amount":  bson.M{"$sum": "$final_amount"},
+			},
+		},
+	}
+
+	cursor, err := r.sales.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate refund activity: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []Domain.RefundActivity
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode refund activity: %w", err)
+	}
+
+	if len(results) == 0 {
+		return &Domain.RefundActivity{}, nil
+	}
+
+	return &results[0], nil
+}
+
+func (r *AlertRepository) LastSaleAt(businessID, deviceID string) (*time.Time, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID, "device_id": deviceID}
+	opts := options.FindOne().
+		SetSort(bson.M{"created_at": -1}).
+		SetProjection(bson.M{"created_at": 1})
+
+	var sale Domain.Sale
+	err = r.sales.FindOne(ctx, query, opts).Decode(&sale)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find last sale: %w", err)
+	}
+
+	return &sale.CreatedAt, nil
+}
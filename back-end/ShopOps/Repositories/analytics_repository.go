@@ -0,0 +1,545 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AnalyticsRepository struct {
+	productDays *mongo.Collection
+	expenseDays *mongo.Collection
+	sales       *mongo.Collection
+	expenses    *mongo.Collection
+	reads       func() *mongo.Database // Report queries; rebuilds read and write the primary
+}
+
+func NewAnalyticsRepository(db *mongo.Database, reads func() *mongo.Database) Domain.AnalyticsRepository {
+	return &AnalyticsRepository{
+		productDays: db.Collection("pnl_daily_products"),
+		expenseDays: db.Collection("pnl_daily_expenses"),
+		sales:       db.Collection("sales"),
+		expenses:    db.Collection("expenses"),
+		reads:       reads,
+	}
+}
+
+func (r *AnalyticsRepository) RebuildDay(businessID string, day, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	now := time.Now()
+
+	// Cost is the unit cost captured at sale time, falling back to the current product cost
+	unitCost := bson.M{
+		"$cond": bson.A{
+			bson.M{"$gt": bson.A{"$unit_cost", 0}},
+			"$unit_cost",
+			bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$product.cost_price", 0}}, 0}},
+		},
+	}
+
+	salesPipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"status":      Domain.SaleStatusCompleted,
+				"created_at":  bson.M{"$gte": start, "$lt": end},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "product_id",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"product_id":     "$product_id",
+					"device_id":      bson.M{"$ifNull": bson.A{"$device_id", ""}},
+					"employee_id":    "$employee_id",
+					"payment_method": "$payment_method",
+				},
+				"product_name": bson.M{"$first": bson.M{"$arrayElemAt": bson.A{"$product.name", 0}}},
+				"category":     bson.M{"$first": bson.M{"$arrayElemAt": bson.A{"$product.category", 0}}},
+				"quantity":     bson.M{"$sum": "$quantity"},
+				"revenue":      bson.M{"$sum": "$final_amount"},
+				"cogs":         bson.M{"$sum": bson.M{"$multiply": bson.A{"$quantity", unitCost}}},
+				"transactions": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	// Days past the archive age are rebuilt from the archive
+	cursor, err := r.sales.Aggregate(ctx, withArchive("sales", salesPipeline))
+	if err != nil {
+		return fmt.Errorf("failed to aggregate sales for day: %w", err)
+	}
+
+	var productRows []interface{}
+	for cursor.Next(ctx) {
+		var result struct {
+			Key struct {
+				ProductID     *primitive.ObjectID  `bson:"product_id"`
+				DeviceID      string               `bson:"device_id"`
+				EmployeeID    *primitive.ObjectID  `bson:"employee_id"`
+				PaymentMethod Domain.PaymentMethod `bson:"payment_method"`
+			} `bson:"_id"`
+			ProductName  string       `bson:"product_name"`
+			Category     string       `bson:"category"`
+			Quantity     float64      `bson:"quantity"`
+			Revenue      Domain.Money `bson:"revenue"`
+			COGS         Domain.Money `bson:"cogs"`
+			Transactions int          `bson:"transactions"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			cursor.Close(ctx)
+			return fmt.Errorf("failed to decode sales for day: %w", err)
+		}
+
+		productRows = append(productRows, Domain.PnLDailyProduct{
+			BusinessID:   objBusinessID,
+			Day:          day,
+			ProductID:    result.Key.ProductID,
+			DeviceID:     result.Key.DeviceID,
+			ProductName:  result.ProductName,
+			Category:     result.Category,
+			Quantity:     result.Quantity,
+			Revenue:      result.Revenue,
+			COGS:         result.COGS,
+			Transactions: result.Transactions,
+			UpdatedAt:    now,
+
+			EmployeeID:    result.Key.EmployeeID,
+			PaymentMethod: result.Key.PaymentMethod,
+		})
+	}
+	cursor.Close(ctx)
+
+	expensesPipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"status":      Domain.ExpenseStatusActive,
+				"date":        bson.M{"$gte": start, "$lt": end},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":    "$category",
+				"amount": bson.M{"$sum": "$amount"},
+				"count":  bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err = r.expenses.Aggregate(ctx, expensesPipeline)
+	if err != nil {
+		return fmt.Errorf("failed to aggregate expenses for day: %w", err)
+	}
+
+	var expenseRows []interface{}
+	for cursor.Next(ctx) {
+		var result struct {
+			Category Domain.ExpenseCategory `bson:"_id"`
+			Amount   Domain.Money           `bson:"amount"`
+			Count    int                    `bson:"count"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			cursor.Close(ctx)
+			return fmt.Errorf("failed to decode expenses for day: %w", err)
+		}
+
+		expenseRows = append(expenseRows, Domain.PnLDailyExpense{
+			BusinessID: objBusinessID,
+			Day:        day,
+			Category:   result.Category,
+			Amount:     result.Amount,
+			Count:      result.Count,
+			UpdatedAt:  now,
+		})
+	}
+	cursor.Close(ctx)
+
+	// Replace the day's rows wholesale so rebuilding is idempotent
+	dayFilter := bson.M{"business_id": objBusinessID, "day": day}
+
+	if _, err := r.productDays.DeleteMany(ctx, dayFilter); err != nil {
+		return fmt.Errorf("failed to clear product aggregates: %w", err)
+	}
+	if len(productRows) > 0 {
+		if _, err := r.productDays.InsertMany(ctx, productRows); err != nil {
+			return fmt.Errorf("failed to store product aggregates: %w", err)
+		}
+	}
+
+	if _, err := r.expenseDays.DeleteMany(ctx, dayFilter); err != nil {
+		return fmt.Errorf("failed to clear expense aggregates: %w", err)
+	}
+	if len(expenseRows) > 0 {
+		if _, err := r.expenseDays.InsertMany(ctx, expenseRows); err != nil {
+			return fmt.Errorf("failed to store expense aggregates: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *AnalyticsRepository) DailyTotals(businessID string, fromDay, toDay time.Time) ([]Domain.PnLDayTotal, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	totals := make(map[time.Time]*Domain.PnLDayTotal)
+	dayTotal := func(day time.Time) *Domain.PnLDayTotal {
+		if totals[day] == nil {
+			totals[day] = &Domain.PnLDayTotal{Day: day}
+		}
+		return totals[day]
+	}
+
+	cursor, err := r.reads().Collection("pnl_daily_products").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":     "$day",
+				"revenue": bson.M{"$sum": "$revenue"},
+				"cogs":    bson.M{"$sum": "$cogs"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate daily revenue: %w", err)
+	}
+
+	var salesDays []Domain.PnLDayTotal
+	if err := cursor.All(ctx, &salesDays); err != nil {
+		return nil, fmt.Errorf("failed to decode daily revenue: %w", err)
+	}
+	for _, d := range salesDays {
+		total := dayTotal(d.Day)
+		total.Revenue = d.Revenue
+		total.COGS = d.COGS
+	}
+
+	cursor, err = r.reads().Collection("pnl_daily_expenses").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":      "$day",
+				"expenses": bson.M{"$sum": "$amount"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate daily expenses: %w", err)
+	}
+
+	var expenseDays []Domain.PnLDayTotal
+	if err := cursor.All(ctx, &expenseDays); err != nil {
+		return nil, fmt.Errorf("failed to decode daily expenses: %w", err)
+	}
+	for _, d := range expenseDays {
+		dayTotal(d.Day).Expenses = d.Expenses
+	}
+
+	result := make([]Domain.PnLDayTotal, 0, len(totals))
+	for _, total := range totals {
+		result = append(result, *total)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Day.Before(result[j].Day) })
+
+	return result, nil
+}
+
+func (r *AnalyticsRepository) CategoryMargins(businessID string, fromDay, toDay time.Time) ([]Domain.MarginLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.reads().Collection("pnl_daily_products").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":      bson.M{"$ifNull": bson.A{"$category", ""}},
+				"quantity": bson.M{"$sum": "$quantity"},
+				"revenue":  bson.M{"$sum": "$revenue"},
+				"cogs":     bson.M{"$sum": "$cogs"},
+			},
+		},
+		{"$sort": bson.M{"revenue": -1}},
+		{"$project": bson.M{"name": "$_id", "category": "$_id", "quantity": 1, "revenue": 1, "cogs": 1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate category margins: %w", err)
+	}
+
+	var lines []Domain.MarginLine
+	if err := cursor.All(ctx, &lines); err != nil {
+		return nil, fmt.Errorf("failed to decode category margins: %w", err)
+	}
+
+	return lines, nil
+}
+
+func (r *AnalyticsRepository) ProductMargins(businessID string, fromDay, toDay time.Time, filter Domain.MarginFilter) ([]Domain.MarginLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+	if filter.Category != "" {
+		match["category"] = filter.Category
+	}
+	if filter.DeviceID != "" {
+		match["device_id"] = filter.DeviceID
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":      "$product_id",
+				"name":     bson.M{"$last": "$product_name"},
+				"category": bson.M{"$last": "$category"},
+				"quantity": bson.M{"$sum": "$quantity"},
+				"revenue":  bson.M{"$sum": "$revenue"},
+				"cogs":     bson.M{"$sum": "$cogs"},
+			},
+		},
+		{"$sort": bson.M{"revenue": -1}},
+	}
+	if filter.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": filter.Limit})
+	}
+
+	cursor, err := r.reads().Collection("pnl_daily_products").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate product margins: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var lines []Domain.MarginLine
+	for cursor.Next(ctx) {
+		var result struct {
+			ProductID         *primitive.ObjectID `bson:"_id"`
+			Domain.MarginLine `bson:",inline"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode product margins: %w", err)
+		}
+		if result.ProductID != nil {
+			result.MarginLine.ProductID = result.ProductID.Hex()
+		}
+		lines = append(lines, result.MarginLine)
+	}
+
+	return lines, nil
+}
+
+func (r *AnalyticsRepository) ProductDailyQuantities(businessID string, fromDay, toDay time.Time) ([]Domain.ProductDayQuantity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+	match["product_id"] = bson.M{"$ne": nil}
+
+	opts := options.Find().
+		SetProjection(bson.M{"product_id": 1, "day": 1, "quantity": 1}).
+		SetSort(bson.M{"day": 1})
+
+	cursor, err := r.reads().Collection("pnl_daily_products").Find(ctx, match, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find daily quantities: %w", err)
+	}
+
+	var quantities []Domain.ProductDayQuantity
+	if err := cursor.All(ctx, &quantities); err != nil {
+		return nil, fmt.Errorf("failed to decode daily quantities: %w", err)
+	}
+
+	return quantities, nil
+}
+
+// pivotFields are the product row fields behind each report dimension
+var pivotFields = map[Domain.ReportDimension]interface{}{
+	Domain.ReportDimensionProduct:       "$product_id",
+	Domain.ReportDimensionCategory:      bson.M{"$ifNull": bson.A{"$category", ""}},
+	Domain.ReportDimensionCashier:       "$employee_id",
+	Domain.ReportDimensionPaymentMethod: bson.M{"$ifNull": bson.A{"$payment_method", ""}},
+	Domain.ReportDimensionDevice:        bson.M{"$ifNull": bson.A{"$device_id", ""}},
+	Domain.ReportDimensionDay:           "$day",
+}
+
+func (r *AnalyticsRepository) Pivot(businessID string, fromDay, toDay time.Time, dimensions []Domain.ReportDimension, filter Domain.CustomReportFilter) ([]Domain.ReportPivotRow, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+	if filter.ProductID != "" {
+		productID, err := primitive.ObjectIDFromHex(filter.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %w", err)
+		}
+		match["product_id"] = productID
+	}
+	if filter.EmployeeID != "" {
+		employeeID, err := primitive.ObjectIDFromHex(filter.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID: %w", err)
+		}
+		match["employee_id"] = employeeID
+	}
+	if filter.Category != "" {
+		match["category"] = filter.Category
+	}
+	if filter.PaymentMethod != "" {
+		match["payment_method"] = filter.PaymentMethod
+	}
+	if filter.DeviceID != "" {
+		match["device_id"] = filter.DeviceID
+	}
+
+	key := bson.M{}
+	for _, dim := range dimensions {
+		field, ok := pivotFields[dim]
+		if !ok {
+			return nil, fmt.Errorf("unknown report dimension: %s", dim)
+		}
+		key[string(dim)] = field
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":          key,
+				"product_name": bson.M{"$last": "$product_name"},
+				"quantity":     bson.M{"$sum": "$quantity"},
+				"revenue":      bson.M{"$sum": "$revenue"},
+				"cogs":         bson.M{"$sum": "$cogs"},
+				"transactions": bson.M{"$sum": "$transactions"},
+			},
+		},
+	}
+
+	cashier := false
+	for _, dim := range dimensions {
+		cashier = cashier || dim == Domain.ReportDimensionCashier
+	}
+	if cashier {
+		pipeline = append(pipeline, bson.M{
+			"$lookup": bson.M{
+				"from":         "employees",
+				"localField":   "_id.cashier",
+				"foreignField": "_id",
+				"as":           "cashier",
+			},
+		})
+	}
+
+	cursor, err := r.reads().Collection("pnl_daily_products").Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate report: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []Domain.ReportPivotRow
+	for cursor.Next(ctx) {
+		var result struct {
+			Key         bson.M       `bson:"_id"`
+			ProductName string       `bson:"product_name"`
+			Quantity    float64      `bson:"quantity"`
+			Revenue     Domain.Money `bson:"revenue"`
+			COGS        Domain.Money `bson:"cogs"`
+			Cashier     []struct {
+				Name string `bson:"name"`
+			} `bson:"cashier"`
+			Transactions int `bson:"transactions"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode report: %w", err)
+		}
+
+		row := Domain.ReportPivotRow{
+			Keys:         make(map[Domain.ReportDimension]string, len(dimensions)),
+			Labels:       make(map[Domain.ReportDimension]string),
+			Quantity:     result.Quantity,
+			Revenue:      result.Revenue,
+			COGS:         result.COGS,
+			Transactions: result.Transactions,
+		}
+		for _, dim := range dimensions {
+			row.Keys[dim] = pivotKey(result.Key[string(dim)])
+		}
+		if _, ok := row.Keys[Domain.ReportDimensionProduct]; ok && result.ProductName != "" {
+			row.Labels[Domain.ReportDimensionProduct] = result.ProductName
+		}
+		if len(result.Cashier) > 0 {
+			row.Labels[Domain.ReportDimensionCashier] = result.Cashier[0].Name
+		}
+
+		rows = append(rows, row)
+	}
+
+	return rows, nil
+}
+
+// pivotKey renders a grouped value as the string used in report rows; IDs are hex
+// and days are dates. Missing values, like sales without a cashier, are empty.
+func pivotKey(value interface{}) string {
+	switch v := value.(type) {
+	case primitive.ObjectID:
+		return v.Hex()
+	case primitive.DateTime:
+		return v.Time().UTC().Format("2006-01-02")
+	case string:
+		return v
+	case nil:
+		return ""
+	}
+	return fmt.Sprint(value)
+}
+
+func dayRangeMatch(businessID string, fromDay, toDay time.Time) (bson.M, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return bson.M{
+		"business_id": objBusinessID,
+		"day":         bson.M{"$gte": fromDay, "$lte": toDay},
+	}, nil
+}
@@ -0,0 +1,247 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AppointmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAppointmentRepository(db *mongo.Database) Domain.AppointmentRepository {
+	return &AppointmentRepository{
+		collection: db.Collection("appointments"),
+	}
+}
+
+func (r *AppointmentRepository) Create(appointment *Domain.Appointment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	appointment.CreatedAt = time.Now()
+	appointment.UpdatedAt = appointment.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, appointment)
+	if err != nil {
+		// Two bookings for the same start raced each other
+		if mongo.IsDuplicateKeyError(err) {
+			return Domain.NewAppError(Domain.ErrCodeConflict, "that time was just booked; choose another")
+		}
+		return fmt.Errorf("failed to create appointment: %w", err)
+	}
+
+	appointment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *AppointmentRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Appointment, error) {
+	var appointment Domain.Appointment
+	found, err := findByIDInBusiness(ctx, r.collection, id, &appointment)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &appointment, nil
+}
+
+func (r *AppointmentRepository) FindByBusiness(businessID string, filters Domain.AppointmentFilters) ([]Domain.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.From != nil || filters.To != nil {
+		dateQuery := bson.M{}
+		if filters.From != nil {
+			dateQuery["$gte"] = *filters.From
+		}
+		if filters.To != nil {
+			dateQuery["$lt"] = *filters.To
+		}
+		query["starts_at"] = dateQuery
+	}
+
+	if filters.EmployeeID != nil {
+		objEmployeeID, err := primitive.ObjectIDFromHex(*filters.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID: %w", err)
+		}
+		query["employee_id"] = objEmployeeID
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.CustomerPhone != nil {
+		query["customer_phone"] = *filters.CustomerPhone
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "starts_at", Value: 1}, {Key: "_id", Value: 1}})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find appointments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []Domain.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, fmt.Errorf("failed to decode appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+func (r *AppointmentRepository) FindBooked(businessID string, from, to time.Time) ([]Domain.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.AppointmentBooked,
+		"starts_at":   bson.M{"$lt": to},
+		"ends_at":     bson.M{"$gt": from},
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.M{"starts_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find booked appointments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []Domain.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, fmt.Errorf("failed to decode appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+func (r *AppointmentRepository) Reschedule(appointment *Domain.Appointment) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	appointment.UpdatedAt = time.Now()
+	appointment.ReminderSentAt = nil
+
+	filter := bson.M{"_id": appointment.ID, "status": Domain.AppointmentBooked}
+	update := bson.M{
+		"$set": bson.M{
+			"employee_id": appointment.EmployeeID,
+			"starts_at":   appointment.StartsAt,
+			"ends_at":     appointment.EndsAt,
+			"updated_at":  appointment.UpdatedAt,
+		},
+		"$unset": bson.M{"reminder_sent_at": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, Domain.NewAppError(Domain.ErrCodeConflict, "that time was just booked; choose another")
+		}
+		return false, fmt.Errorf("failed to reschedule appointment: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *AppointmentRepository) SetStatus(id primitive.ObjectID, from, status Domain.AppointmentStatus, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	set := bson.M{"status": status, "updated_at": at}
+	unset := bson.M{}
+	if status == Domain.AppointmentBooked {
+		unset["closed_at"] = ""
+	} else {
+		set["closed_at"] = at
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": from}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, Domain.NewAppError(Domain.ErrCodeConflict, "that time has been booked since")
+		}
+		return false, fmt.Errorf("failed to update appointment: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *AppointmentRepository) SetSale(id, saleID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"sale_id": saleID}}); err != nil {
+		return fmt.Errorf("failed to set appointment sale: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AppointmentRepository) FindDueReminders(now, before time.Time) ([]Domain.Appointment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"status":           Domain.AppointmentBooked,
+		"starts_at":        bson.M{"$gt": now, "$lte": before},
+		"reminder_sent_at": bson.M{"$exists": false},
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.M{"starts_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find appointments due a reminder: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var appointments []Domain.Appointment
+	if err := cursor.All(ctx, &appointments); err != nil {
+		return nil, fmt.Errorf("failed to decode appointments: %w", err)
+	}
+
+	return appointments, nil
+}
+
+func (r *AppointmentRepository) MarkReminded(id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"reminder_sent_at": at}}); err != nil {
+		return fmt.Errorf("failed to mark appointment reminded: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,138 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ArchiveRepository struct {
+	db   *mongo.Database
+	runs *mongo.Collection
+}
+
+func NewArchiveRepository(db *mongo.Database) Domain.ArchiveRepository {
+	return &ArchiveRepository{
+		db:   db,
+		runs: db.Collection("archive_runs"),
+	}
+}
+
+// Move copies the records into the archive before deleting them, so a run cut short
+// leaves a batch in both places rather than in neither; the next run copies it again
+// over the records already there and finishes the delete.
+func (r *ArchiveRepository) Move(collection string, before time.Time, limit int) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	live := r.db.Collection(collection)
+	opts := options.Find().SetSort(bson.M{"created_at": 1}).SetLimit(int64(limit))
+	cursor, err := live.Find(ctx, bson.M{"created_at": bson.M{"$lt": before}}, opts)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find %s to archive: %w", collection, err)
+	}
+
+	var records []bson.Raw
+	if err := cursor.All(ctx, &records); err != nil {
+		return 0, fmt.Errorf("failed to decode %s to archive: %w", collection, err)
+	}
+	if len(records) == 0 {
+		return 0, nil
+	}
+
+	documents := make([]interface{}, len(records))
+	ids := make(bson.A, len(records))
+	for i, record := range records {
+		documents[i] = record
+		ids[i] = record.Lookup("_id")
+	}
+
+	archive := r.db.Collection(Domain.ArchiveCollection(collection))
+	_, err = archive.InsertMany(ctx, documents, options.InsertMany().SetOrdered(false))
+	if err != nil && !onlyDuplicateKeys(err) {
+		return 0, fmt.Errorf("failed to archive %s: %w", collection, err)
+	}
+
+	result, err := live.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to delete archived %s: %w", collection, err)
+	}
+
+	return result.DeletedCount, nil
+}
+
+func (r *ArchiveRepository) Compact(collection string) error {
+	// Compacting blocks little on recent servers but can take a while on large collections
+	ctx, cancel := context.WithTimeout(context.Background(), time.Hour)
+	defer cancel()
+
+	if err := r.db.RunCommand(ctx, bson.D{{Key: "compact", Value: collection}}).Err(); err != nil {
+		return fmt.Errorf("failed to compact %s: %w", collection, err)
+	}
+	return nil
+}
+
+func (r *ArchiveRepository) SaveRun(run *Domain.ArchiveRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.runs.InsertOne(ctx, run)
+	if err != nil {
+		return fmt.Errorf("failed to save archive run: %w", err)
+	}
+
+	run.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ArchiveRepository) FindRuns(limit int) ([]Domain.ArchiveRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"started_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.runs.Find(ctx, bson.M{}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find archive runs: %w", err)
+	}
+
+	var runs []Domain.ArchiveRun
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, fmt.Errorf("failed to decode archive runs: %w", err)
+	}
+
+	return runs, nil
+}
+
+// onlyDuplicateKeys is whether every write failed for a record already there
+func onlyDuplicateKeys(err error) bool {
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return false
+	}
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return false
+		}
+	}
+	return true
+}
+
+// withArchive has a pipeline that starts with a $match read the collection's archive
+// as well, for reports that can reach back past the archive age
+func withArchive(collection string, pipeline []bson.M) []bson.M {
+	union := bson.M{"$unionWith": bson.M{
+		"coll":     Domain.ArchiveCollection(collection),
+		"pipeline": []bson.M{pipeline[0]},
+	}}
+
+	combined := make([]bson.M, 0, len(pipeline)+1)
+	combined = append(combined, pipeline[0], union)
+	return append(combined, pipeline[1:]...)
+}
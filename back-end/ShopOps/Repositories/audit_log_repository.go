@@ -0,0 +1,107 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type AuditLogRepository struct {
+	collection *mongo.Collection
+}
+
+func NewAuditLogRepository(db *mongo.Database) Domain.AuditLogRepository {
+	return &AuditLogRepository{
+		collection: db.Collection("admin_audit_log"),
+	}
+}
+
+func (r *AuditLogRepository) Create(entry *Domain.AuditEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to create audit entry: %w", err)
+	}
+
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *AuditLogRepository) Find(filters Domain.AuditFilters) ([]Domain.AuditEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query, err := r.buildQuery(filters)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find()
+	applyPage(query, opts, Domain.ListSort{}, Domain.AuditSorts["-created_at"], filters.After)
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find audit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.AuditEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode audit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *AuditLogRepository) Count(filters Domain.AuditFilters) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query, err := r.buildQuery(filters)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count audit entries: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *AuditLogRepository) buildQuery(filters Domain.AuditFilters) (bson.M, error) {
+	query := bson.M{}
+
+	if filters.Actor != nil {
+		query["actor"] = *filters.Actor
+	}
+
+	if filters.BusinessID != nil {
+		objBusinessID, err := primitive.ObjectIDFromHex(*filters.BusinessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		query["business_id"] = objBusinessID
+	}
+
+	return query, nil
+}
@@ -0,0 +1,215 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type BackorderRepository struct {
+	collection *mongo.Collection
+	outbox     *outbox
+}
+
+func NewBackorderRepository(db *mongo.Database) Domain.BackorderRepository {
+	return &BackorderRepository{
+		collection: db.Collection("backorders"),
+		outbox:     newOutbox(db),
+	}
+}
+
+func (r *BackorderRepository) Create(backorder *Domain.Backorder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backorder.CreatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, backorder)
+	if err != nil {
+		return fmt.Errorf("failed to create backorder: %w", err)
+	}
+
+	backorder.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *BackorderRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Backorder, error) {
+	var backorder Domain.Backorder
+	found, err := findByIDInBusiness(ctx, r.collection, id, &backorder)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &backorder, nil
+}
+
+func (r *BackorderRepository) FindBySale(saleID primitive.ObjectID) (*Domain.Backorder, error) {
+	return r.findOne(bson.M{"sale_id": saleID})
+}
+
+func (r *BackorderRepository) findOne(query bson.M) (*Domain.Backorder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var backorder Domain.Backorder
+	err := r.collection.FindOne(ctx, query).Decode(&backorder)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find backorder: %w", err)
+	}
+
+	return &backorder, nil
+}
+
+func (r *BackorderRepository) FindByBusiness(businessID string, filters Domain.BackorderFilters) ([]Domain.Backorder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.ProductID != nil {
+		objProductID, err := primitive.ObjectIDFromHex(*filters.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %w", err)
+		}
+		query["product_id"] = objProductID
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find backorders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var backorders []Domain.Backorder
+	if err := cursor.All(ctx, &backorders); err != nil {
+		return nil, fmt.Errorf("failed to decode backorders: %w", err)
+	}
+
+	return backorders, nil
+}
+
+func (r *BackorderRepository) FindOpen(productID primitive.ObjectID) ([]Domain.Backorder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{"product_id": productID, "status": Domain.BackorderOpen}
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.D{{Key: "created_at", Value: 1}, {Key: "_id", Value: 1}}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find open backorders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var backorders []Domain.Backorder
+	if err := cursor.All(ctx, &backorders); err != nil {
+		return nil, fmt.Errorf("failed to decode backorders: %w", err)
+	}
+
+	return backorders, nil
+}
+
+func (r *BackorderRepository) Allocate(backorder *Domain.Backorder, quantity float64, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	allocated := backorder.Allocated + quantity
+	set := bson.M{"allocated": allocated}
+	ready := allocated >= backorder.Quantity
+	if ready {
+		set["status"] = Domain.BackorderReady
+		set["ready_at"] = at
+	}
+
+	updated := false
+	// Becoming ready and its backorder.ready event go in together
+	err := r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		filter := bson.M{"_id": backorder.ID, "status": Domain.BackorderOpen, "allocated": backorder.Allocated}
+		result, err := r.collection.UpdateOne(ctx, filter, bson.M{"$set": set})
+		if err != nil {
+			return nil, fmt.Errorf("failed to allocate backorder: %w", err)
+		}
+		updated = result.MatchedCount > 0
+		if !updated || !ready {
+			return nil, nil
+		}
+
+		readied := *backorder
+		readied.Allocated = allocated
+		readied.Status = Domain.BackorderReady
+		readied.ReadyAt = &at
+		event, err := Domain.NewDomainEvent(backorder.BusinessID, Domain.EventBackorderReady, backorder.ID.Hex(), readied)
+		if err != nil {
+			return nil, err
+		}
+		return []*Domain.DomainEvent{event}, nil
+	})
+	if err != nil {
+		return false, err
+	}
+
+	if updated {
+		backorder.Allocated = allocated
+		if ready {
+			backorder.Status = Domain.BackorderReady
+			backorder.ReadyAt = &at
+		}
+	}
+	return updated, nil
+}
+
+func (r *BackorderRepository) SetStatus(id primitive.ObjectID, from []Domain.BackorderStatus, status Domain.BackorderStatus, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	set := bson.M{"status": status}
+	switch status {
+	case Domain.BackorderCollected:
+		set["collected_at"] = at
+	case Domain.BackorderCancelled:
+		set["cancelled_at"] = at
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": id, "status": bson.M{"$in": from}}, bson.M{"$set": set})
+	if err != nil {
+		return false, fmt.Errorf("failed to update backorder: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *BackorderRepository) MarkNotified(id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"notified_at": at}}); err != nil {
+		return fmt.Errorf("failed to mark backorder notified: %w", err)
+	}
+
+	return nil
+}
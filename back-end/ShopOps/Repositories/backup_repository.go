@@ -0,0 +1,130 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// backupCollections hold a shop's own records, keyed by business_id. Summaries and
+// other data rebuilt from these are left out.
+var backupCollections = []string{
+	"products", "stock_movements", "sales", "expenses", "recurring_expenses",
+	"customers", "customer_prices", "customer_segments", "gift_cards", "gift_card_transactions",
+	"loyalty_programs", "loyalty_accounts", "loyalty_transactions",
+	"suppliers", "purchase_orders", "supplier_payables",
+	"employees", "commission_rules", "time_entries", "drawer_events",
+	"receipt_templates", "saved_reports", "sms_campaigns", "sms_messages", "alerts",
+	"sales_archive", "stock_movements_archive",
+}
+
+type BackupRepository struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+	outbox     *outbox
+}
+
+func NewBackupRepository(db *mongo.Database) Domain.BackupRepository {
+	return &BackupRepository{
+		db:         db,
+		collection: db.Collection("shop_backups"),
+		outbox:     newOutbox(db),
+	}
+}
+
+func (r *BackupRepository) Create(backup *Domain.ShopBackup) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	backup.CreatedAt = time.Now()
+	if backup.ID.IsZero() {
+		backup.ID = primitive.NewObjectID()
+	}
+
+	// backup.completed goes in with the backup, so the shop always hears of it
+	return r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		if _, err := r.collection.InsertOne(ctx, backup); err != nil {
+			return nil, fmt.Errorf("failed to create backup: %w", err)
+		}
+		event, err := Domain.NewDomainEvent(backup.BusinessID, Domain.EventBackupCompleted, backup.ID.Hex(), backup)
+		if err != nil {
+			return nil, err
+		}
+		return []*Domain.DomainEvent{event}, nil
+	})
+}
+
+func (r *BackupRepository) FindByBusiness(businessID string) ([]Domain.ShopBackup, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	cursor, err := r.collection.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find backups: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var backups []Domain.ShopBackup
+	if err := cursor.All(ctx, &backups); err != nil {
+		return nil, fmt.Errorf("failed to decode backups: %w", err)
+	}
+
+	return backups, nil
+}
+
+func (r *BackupRepository) Export(businessID string, write func(collection string, document []byte) error) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	counts := make(map[string]int)
+	exportOne := func(collection string, filter bson.M) error {
+		cursor, err := r.db.Collection(collection).Find(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("failed to read %s: %w", collection, err)
+		}
+		defer cursor.Close(ctx)
+
+		for cursor.Next(ctx) {
+			document, err := bson.MarshalExtJSON(cursor.Current, true, false)
+			if err != nil {
+				return fmt.Errorf("failed to encode %s document: %w", collection, err)
+			}
+			if err := write(collection, document); err != nil {
+				return err
+			}
+			counts[collection]++
+		}
+		if err := cursor.Err(); err != nil {
+			return fmt.Errorf("failed to read %s: %w", collection, err)
+		}
+		return nil
+	}
+
+	if err := exportOne("businesses", bson.M{"_id": objBusinessID}); err != nil {
+		return nil, err
+	}
+	for _, collection := range backupCollections {
+		if err := exportOne(collection, bson.M{"business_id": objBusinessID}); err != nil {
+			return nil, err
+		}
+	}
+
+	return counts, nil
+}
@@ -0,0 +1,210 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// BIRepository keeps the shops' BI keys in the primary database and reads the feed
+// from the database reads returns, normally a read replica
+type BIRepository struct {
+	keysCollection *mongo.Collection
+	reads          func() *mongo.Database
+}
+
+func NewBIRepository(db *mongo.Database, reads func() *mongo.Database) Domain.BIRepository {
+	return &BIRepository{
+		keysCollection: db.Collection("bi_keys"),
+		reads:          reads,
+	}
+}
+
+func (r *BIRepository) CreateKey(key *Domain.BIKey) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	key.CreatedAt = time.Now()
+
+	result, err := r.keysCollection.InsertOne(ctx, key)
+	if err != nil {
+		return fmt.Errorf("failed to create BI key: %w", err)
+	}
+
+	key.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *BIRepository) FindKeyByID(id string) (*Domain.BIKey, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid BI key ID: %w", err)
+	}
+	return r.findKey(bson.M{"_id": objID})
+}
+
+func (r *BIRepository) FindKeyByTokenHash(hash string) (*Domain.BIKey, error) {
+	return r.findKey(bson.M{"token_hash": hash})
+}
+
+func (r *BIRepository) findKey(query bson.M) (*Domain.BIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var key Domain.BIKey
+	err := r.keysCollection.FindOne(ctx, query).Decode(&key)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find BI key: %w", err)
+	}
+
+	return &key, nil
+}
+
+func (r *BIRepository) FindKeysByBusiness(businessID string) ([]Domain.BIKey, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.keysCollection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find BI keys: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var keys []Domain.BIKey
+	if err := cursor.All(ctx, &keys); err != nil {
+		return nil, fmt.Errorf("failed to decode BI keys: %w", err)
+	}
+
+	return keys, nil
+}
+
+func (r *BIRepository) DeleteKey(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid BI key ID: %w", err)
+	}
+
+	if _, err := r.keysCollection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete BI key: %w", err)
+	}
+
+	return nil
+}
+
+func (r *BIRepository) TouchKey(id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.keysCollection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_used_at": at}}); err != nil {
+		return fmt.Errorf("failed to update BI key: %w", err)
+	}
+
+	return nil
+}
+
+// biChangedFields is the field each dataset's feed is ordered by; stock movements
+// are never changed once written
+var biChangedFields = map[Domain.BIDataset]string{
+	Domain.BIDatasetSales:          "updated_at",
+	Domain.BIDatasetProducts:       "updated_at",
+	Domain.BIDatasetCustomers:      "updated_at",
+	Domain.BIDatasetExpenses:       "updated_at",
+	Domain.BIDatasetStockMovements: "created_at",
+}
+
+func (r *BIRepository) FindRecords(dataset Domain.BIDataset, businessID string, query Domain.BIQuery) ([]Domain.BIRecord, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	field, ok := biChangedFields[dataset]
+	if !ok {
+		return nil, fmt.Errorf("unknown BI dataset %q", dataset)
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	window := bson.M{"$lte": query.AsOf}
+	if query.Since != nil {
+		window["$gt"] = *query.Since
+	}
+	match := bson.M{"business_id": objBusinessID, field: window}
+	if query.After != nil {
+		match["$or"] = bson.A{
+			bson.M{field: bson.M{"$gt": query.After.ChangedAt}},
+			bson.M{field: query.After.ChangedAt, "_id": bson.M{"$gt": query.After.ID}},
+		}
+	}
+
+	pipeline := []bson.M{{"$match": match}}
+	collection := string(dataset)
+	for _, archived := range Domain.ArchivedCollections {
+		if archived == collection {
+			pipeline = withArchive(collection, pipeline)
+		}
+	}
+	pipeline = append(pipeline,
+		bson.M{"$sort": bson.D{{Key: field, Value: 1}, {Key: "_id", Value: 1}}},
+		bson.M{"$limit": query.Limit},
+	)
+
+	cursor, err := r.reads().Collection(collection).Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", dataset, err)
+	}
+	defer cursor.Close(ctx)
+
+	var records []Domain.BIRecord
+	switch dataset {
+	case Domain.BIDatasetSales:
+		records, err = decodeBIRecords(ctx, cursor, func(s Domain.Sale) (primitive.ObjectID, time.Time) { return s.ID, s.UpdatedAt })
+	case Domain.BIDatasetProducts:
+		records, err = decodeBIRecords(ctx, cursor, func(p Domain.Product) (primitive.ObjectID, time.Time) { return p.ID, p.UpdatedAt })
+	case Domain.BIDatasetCustomers:
+		records, err = decodeBIRecords(ctx, cursor, func(c Domain.Customer) (primitive.ObjectID, time.Time) { return c.ID, c.UpdatedAt })
+	case Domain.BIDatasetExpenses:
+		records, err = decodeBIRecords(ctx, cursor, func(e Domain.Expense) (primitive.ObjectID, time.Time) { return e.ID, e.UpdatedAt })
+	case Domain.BIDatasetStockMovements:
+		records, err = decodeBIRecords(ctx, cursor, func(m Domain.StockMovement) (primitive.ObjectID, time.Time) { return m.ID, m.CreatedAt })
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", dataset, err)
+	}
+
+	return records, nil
+}
+
+// decodeBIRecords decodes the rows as the rest of the API reads them, so fields
+// kept out of responses stay out of the feed
+func decodeBIRecords[T any](ctx context.Context, cursor *mongo.Cursor, key func(T) (primitive.ObjectID, time.Time)) ([]Domain.BIRecord, error) {
+	var rows []T
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, err
+	}
+
+	records := make([]Domain.BIRecord, len(rows))
+	for i, row := range rows {
+		id, changedAt := key(row)
+		records[i] = Domain.BIRecord{ID: id, ChangedAt: changedAt, Data: row}
+	}
+	return records, nil
+}
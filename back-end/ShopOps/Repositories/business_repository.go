@@ -3,12 +3,14 @@ package Repositories
 import (
 	"context"
 	"fmt"
+	"regexp"
 	"time"
 
 	Domain "ShopOps/Domain"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type BusinessRepository struct {
@@ -28,7 +30,6 @@ func (r *BusinessRepository) Create(business *Domain.Business) error {
 	business.CreatedAt = time.Now()
 	business.UpdatedAt = time.Now()
 	business.Status = Domain.BusinessStatusActive
-	business.Timezone = "UTC"
 
 	result, err := r.collection.InsertOne(ctx, business)
 	if err != nil {
@@ -83,6 +84,50 @@ func (r *BusinessRepository) FindByUserID(userID string) ([]Domain.Business, err
 	return businesses, nil
 }
 
+func (r *BusinessRepository) FindActive() ([]Domain.Business, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"status": Domain.BusinessStatusActive})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find businesses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var businesses []Domain.Business
+	if err := cursor.All(ctx, &businesses); err != nil {
+		return nil, fmt.Errorf("failed to decode businesses: %w", err)
+	}
+
+	return businesses, nil
+}
+
+func (r *BusinessRepository) Search(query string, limit int) ([]Domain.Business, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pattern := primitive.Regex{Pattern: regexp.QuoteMeta(query), Options: "i"}
+	filter := bson.M{"$or": []bson.M{
+		{"name": pattern},
+		{"phone": pattern},
+		{"email": pattern},
+	}}
+	opts := options.Find().SetSort(bson.D{{Key: "name", Value: 1}}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search businesses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var businesses []Domain.Business
+	if err := cursor.All(ctx, &businesses); err != nil {
+		return nil, fmt.Errorf("failed to decode businesses: %w", err)
+	}
+
+	return businesses, nil
+}
+
 func (r *BusinessRepository) Update(business *Domain.Business) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -91,17 +136,19 @@ func (r *BusinessRepository) Update(business *Domain.Business) error {
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":          business.Name,
-			"description":   business.Description,
-			"business_type": business.BusinessType,
-			"currency":      business.Currency,
-			"timezone":      business.Timezone,
-			"address":       business.Address,
-			"city":          business.City,
-			"country":       business.Country,
-			"phone":         business.Phone,
-			"email":         business.Email,
-			"updated_at":    business.UpdatedAt,
+			"name":            business.Name,
+			"description":     business.Description,
+			"business_type":   business.BusinessType,
+			"currency":        business.Currency,
+			"timezone":        business.Timezone,
+			"day_cutoff_hour": business.DayCutoffHour,
+			"language":        business.Language,
+			"address":         business.Address,
+			"city":            business.City,
+			"country":         business.Country,
+			"phone":           business.Phone,
+			"email":           business.Email,
+			"updated_at":      business.UpdatedAt,
 		},
 	}
 
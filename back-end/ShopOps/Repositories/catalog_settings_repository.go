@@ -0,0 +1,67 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CatalogSettingsRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCatalogSettingsRepository(db *mongo.Database) Domain.CatalogSettingsRepository {
+	return &CatalogSettingsRepository{
+		collection: db.Collection("catalog_settings"),
+	}
+}
+
+func (r *CatalogSettingsRepository) FindSettings(businessID string) (*Domain.CatalogSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var settings Domain.CatalogSettings
+	err = r.collection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find catalog settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *CatalogSettingsRepository) SaveSettings(settings *Domain.CatalogSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"categories": settings.Categories,
+			"units":      settings.Units,
+			"tax_rates":  settings.TaxRates,
+			"template":   settings.Template,
+			"updated_at": settings.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"business_id": settings.BusinessID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save catalog settings: %w", err)
+	}
+	return nil
+}
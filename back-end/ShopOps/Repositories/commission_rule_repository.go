@@ -0,0 +1,114 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CommissionRuleRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCommissionRuleRepository(db *mongo.Database) Domain.CommissionRuleRepository {
+	return &CommissionRuleRepository{
+		collection: db.Collection("commission_rules"),
+	}
+}
+
+func (r *CommissionRuleRepository) Create(rule *Domain.CommissionRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rule.Active = true
+	rule.CreatedAt = time.Now()
+	rule.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, rule)
+	if err != nil {
+		return fmt.Errorf("failed to create commission rule: %w", err)
+	}
+
+	rule.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *CommissionRuleRepository) FindInBusiness(ctx context.Context, id string) (*Domain.CommissionRule, error) {
+	var rule Domain.CommissionRule
+	found, err := findByIDInBusiness(ctx, r.collection, id, &rule)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *CommissionRuleRepository) FindByBusinessID(businessID string) ([]Domain.CommissionRule, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commission rules: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rules []Domain.CommissionRule
+	if err := cursor.All(ctx, &rules); err != nil {
+		return nil, fmt.Errorf("failed to decode commission rules: %w", err)
+	}
+
+	return rules, nil
+}
+
+func (r *CommissionRuleRepository) Update(rule *Domain.CommissionRule) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rule.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       rule.Name,
+			"category":   rule.Category,
+			"type":       rule.Type,
+			"rate":       rule.Rate,
+			"active":     rule.Active,
+			"updated_at": rule.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, rule.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update commission rule: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CommissionRuleRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid commission rule ID: %w", err)
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete commission rule: %w", err)
+	}
+
+	return nil
+}
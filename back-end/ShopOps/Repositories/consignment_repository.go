@@ -0,0 +1,124 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ConsignmentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewConsignmentRepository(db *mongo.Database) Domain.ConsignmentRepository {
+	return &ConsignmentRepository{
+		collection: db.Collection("consignment_entries"),
+	}
+}
+
+func (r *ConsignmentRepository) Create(entry *Domain.ConsignmentEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	result, err := r.collection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to create consignment entry: %w", err)
+	}
+
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ConsignmentRepository) FindByBusiness(businessID string, filters Domain.ConsignmentEntryFilters) ([]Domain.ConsignmentEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.ConsignorID != nil {
+		objConsignorID, err := primitive.ObjectIDFromHex(*filters.ConsignorID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid consignor ID: %w", err)
+		}
+		query["consignor_id"] = objConsignorID
+	}
+
+	if filters.ProductID != nil {
+		objProductID, err := primitive.ObjectIDFromHex(*filters.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %w", err)
+		}
+		query["product_id"] = objProductID
+	}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.StartDate != nil || filters.EndDate != nil {
+		dateQuery := bson.M{}
+		if filters.StartDate != nil {
+			dateQuery["$gte"] = *filters.StartDate
+		}
+		if filters.EndDate != nil {
+			dateQuery["$lt"] = *filters.EndDate
+		}
+		query["created_at"] = dateQuery
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consignment entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.ConsignmentEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode consignment entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *ConsignmentRepository) FindBySale(saleID primitive.ObjectID) ([]Domain.ConsignmentEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{"sale_id": saleID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consignment entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.ConsignmentEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode consignment entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,138 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CurrencyRepository struct {
+	settingsCollection *mongo.Collection
+	ratesCollection    *mongo.Collection
+}
+
+func NewCurrencyRepository(db *mongo.Database) Domain.CurrencyRepository {
+	return &CurrencyRepository{
+		settingsCollection: db.Collection("currency_settings"),
+		ratesCollection:    db.Collection("exchange_rates"),
+	}
+}
+
+func (r *CurrencyRepository) FindSettings(businessID string) (*Domain.CurrencySettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var settings Domain.CurrencySettings
+	err = r.settingsCollection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find currency settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *CurrencyRepository) SaveSettings(settings *Domain.CurrencySettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	update := bson.M{"$set": settings}
+	if settings.CashRounding == nil {
+		update["$unset"] = bson.M{"cash_rounding": ""}
+	}
+
+	_, err := r.settingsCollection.UpdateOne(ctx,
+		bson.M{"business_id": settings.BusinessID},
+		update,
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save currency settings: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CurrencyRepository) FindAutoFetchSettings() ([]Domain.CurrencySettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.settingsCollection.Find(ctx, bson.M{"auto_fetch": true, "accepted.0": bson.M{"$exists": true}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find currency settings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var settings []Domain.CurrencySettings
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode currency settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *CurrencyRepository) CreateRate(rate *Domain.ExchangeRate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	rate.CreatedAt = time.Now()
+
+	result, err := r.ratesCollection.InsertOne(ctx, rate)
+	if err != nil {
+		return fmt.Errorf("failed to save exchange rate: %w", err)
+	}
+
+	rate.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *CurrencyRepository) FindLatestRate(businessID, currency string) (*Domain.ExchangeRate, error) {
+	rates, err := r.FindRates(businessID, currency, 1)
+	if err != nil || len(rates) == 0 {
+		return nil, err
+	}
+	return &rates[0], nil
+}
+
+func (r *CurrencyRepository) FindRates(businessID, currency string, limit int) ([]Domain.ExchangeRate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.ratesCollection.Find(ctx, bson.M{"business_id": objBusinessID, "currency": currency}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find exchange rates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rates []Domain.ExchangeRate
+	if err := cursor.All(ctx, &rates); err != nil {
+		return nil, fmt.Errorf("failed to decode exchange rates: %w", err)
+	}
+
+	return rates, nil
+}
@@ -0,0 +1,129 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CustomFieldRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCustomFieldRepository(db *mongo.Database) Domain.CustomFieldRepository {
+	return &CustomFieldRepository{
+		collection: db.Collection("custom_field_definitions"),
+	}
+}
+
+func (r *CustomFieldRepository) Create(def *Domain.CustomFieldDefinition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	def.CreatedAt = time.Now()
+	def.UpdatedAt = def.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, def)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("the %s already has a field with key %s", def.Entity, def.Key))
+		}
+		return fmt.Errorf("failed to create custom field: %w", err)
+	}
+
+	def.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *CustomFieldRepository) FindInBusiness(ctx context.Context, id string) (*Domain.CustomFieldDefinition, error) {
+	var definition Domain.CustomFieldDefinition
+	found, err := findByIDInBusiness(ctx, r.collection, id, &definition)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &definition, nil
+}
+
+func (r *CustomFieldRepository) FindByBusinessID(businessID string, entity Domain.CustomFieldEntity, activeOnly bool) ([]Domain.CustomFieldDefinition, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+	if entity != "" {
+		query["entity"] = entity
+	}
+	if activeOnly {
+		query["active"] = true
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "entity", Value: 1}, {Key: "position", Value: 1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find custom fields: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var defs []Domain.CustomFieldDefinition
+	if err := cursor.All(ctx, &defs); err != nil {
+		return nil, fmt.Errorf("failed to decode custom fields: %w", err)
+	}
+
+	return defs, nil
+}
+
+func (r *CustomFieldRepository) Count(businessID string, entity Domain.CustomFieldEntity) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"business_id": objBusinessID, "entity": entity})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count custom fields: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *CustomFieldRepository) Update(def *Domain.CustomFieldDefinition) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	def.UpdatedAt = time.Now()
+
+	_, err := r.collection.UpdateOne(ctx, bson.M{"_id": def.ID}, bson.M{
+		"$set": bson.M{
+			"label":      def.Label,
+			"required":   def.Required,
+			"options":    def.Options,
+			"max_length": def.MaxLength,
+			"min":        def.Min,
+			"max":        def.Max,
+			"pattern":    def.Pattern,
+			"searchable": def.Searchable,
+			"position":   def.Position,
+			"active":     def.Active,
+			"updated_at": def.UpdatedAt,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update custom field: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,126 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CustomerPriceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCustomerPriceRepository(db *mongo.Database) Domain.CustomerPriceRepository {
+	return &CustomerPriceRepository{
+		collection: db.Collection("customer_prices"),
+	}
+}
+
+func (r *CustomerPriceRepository) Upsert(price *Domain.CustomerPrice) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+
+	// One negotiated price per customer and product
+	filter := bson.M{"customer_id": price.CustomerID, "product_id": price.ProductID}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"business_id": price.BusinessID,
+			"created_by":  price.CreatedBy,
+			"created_at":  now,
+		},
+		"$set": bson.M{
+			"price":      price.Price,
+			"notes":      price.Notes,
+			"updated_at": now,
+		},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(price); err != nil {
+		return fmt.Errorf("failed to save customer price: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CustomerPriceRepository) Find(customerID, productID string) (*Domain.CustomerPrice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objCustomerID, err := primitive.ObjectIDFromHex(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid customer ID: %w", err)
+	}
+
+	objProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	var price Domain.CustomerPrice
+	err = r.collection.FindOne(ctx, bson.M{"customer_id": objCustomerID, "product_id": objProductID}).Decode(&price)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find customer price: %w", err)
+	}
+
+	return &price, nil
+}
+
+func (r *CustomerPriceRepository) FindByCustomerID(customerID string) ([]Domain.CustomerPrice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objCustomerID, err := primitive.ObjectIDFromHex(customerID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid customer ID: %w", err)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"customer_id": objCustomerID}, options.Find().SetSort(bson.M{"updated_at": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer prices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var prices []Domain.CustomerPrice
+	if err := cursor.All(ctx, &prices); err != nil {
+		return nil, fmt.Errorf("failed to decode customer prices: %w", err)
+	}
+
+	return prices, nil
+}
+
+func (r *CustomerPriceRepository) Delete(customerID, productID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objCustomerID, err := primitive.ObjectIDFromHex(customerID)
+	if err != nil {
+		return fmt.Errorf("invalid customer ID: %w", err)
+	}
+
+	objProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	result, err := r.collection.DeleteOne(ctx, bson.M{"customer_id": objCustomerID, "product_id": objProductID})
+	if err != nil {
+		return fmt.Errorf("failed to delete customer price: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return Domain.NotFoundError("customer price not found")
+	}
+
+	return nil
+}
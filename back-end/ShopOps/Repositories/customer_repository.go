@@ -0,0 +1,277 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type CustomerRepository struct {
+	collection *mongo.Collection
+}
+
+func NewCustomerRepository(db *mongo.Database) Domain.CustomerRepository {
+	return &CustomerRepository{
+		collection: db.Collection("customers"),
+	}
+}
+
+func (r *CustomerRepository) Create(customer *Domain.Customer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	customer.Status = Domain.CustomerStatusActive
+	customer.CreatedAt = time.Now()
+	customer.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, customer)
+	if err != nil {
+		return fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	customer.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *CustomerRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Customer, error) {
+	var customer Domain.Customer
+	found, err := findByIDInBusiness(ctx, r.collection, id, &customer)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+func (r *CustomerRepository) FindByPhone(businessID, phone string) (*Domain.Customer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var customer Domain.Customer
+	err = r.collection.FindOne(ctx, bson.M{
+		"business_id": objBusinessID,
+		"phone":       phone,
+		"status":      Domain.CustomerStatusActive,
+	}).Decode(&customer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find customer: %w", err)
+	}
+
+	return &customer, nil
+}
+
+func (r *CustomerRepository) FindByPhones(businessID string, phones []string) ([]Domain.Customer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	if len(phones) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{
+		"business_id": objBusinessID,
+		"phone":       bson.M{"$in": phones},
+		"status":      Domain.CustomerStatusActive,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var customers []Domain.Customer
+	if err := cursor.All(ctx, &customers); err != nil {
+		return nil, fmt.Errorf("failed to decode customers: %w", err)
+	}
+
+	return customers, nil
+}
+
+func (r *CustomerRepository) FindByBusinessID(businessID string, filters Domain.CustomerFilters) ([]Domain.Customer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query, err := r.buildQuery(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find()
+	applyPage(query, opts, filters.Sort, Domain.CustomerSorts["name"], filters.After)
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var customers []Domain.Customer
+	if err := cursor.All(ctx, &customers); err != nil {
+		return nil, fmt.Errorf("failed to decode customers: %w", err)
+	}
+
+	return customers, nil
+}
+
+func (r *CustomerRepository) Count(businessID string, filters Domain.CustomerFilters) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query, err := r.buildQuery(businessID, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count customers: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *CustomerRepository) buildQuery(businessID string, filters Domain.CustomerFilters) (bson.M, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID, "deleted_at": notDeleted}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	} else {
+		query["status"] = Domain.CustomerStatusActive
+	}
+
+	if filters.Tag != nil {
+		query["tags"] = *filters.Tag
+	}
+
+	if filters.Tier != nil {
+		if *filters.Tier == Domain.CustomerTierRetail {
+			// Customers created before tiers existed have no tier and are retail
+			query["tier"] = bson.M{"$in": bson.A{Domain.CustomerTierRetail, nil, ""}}
+		} else {
+			query["tier"] = *filters.Tier
+		}
+	}
+
+	if filters.MarketingOptIn != nil {
+		query["marketing_opt_in"] = *filters.MarketingOptIn
+	}
+
+	if filters.Search != nil && *filters.Search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(*filters.Search), Options: "i"}
+		query["$or"] = []bson.M{
+			{"name": pattern},
+			{"phone": pattern},
+			{"email": pattern},
+		}
+	}
+
+	return query, nil
+}
+
+func (r *CustomerRepository) Update(customer *Domain.Customer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	customer.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       customer.Name,
+			"phone":      customer.Phone,
+			"email":      customer.Email,
+			"address":    customer.Address,
+			"notes":      customer.Notes,
+			"tags":       customer.Tags,
+			"tier":       customer.Tier,
+			"updated_at": customer.UpdatedAt,
+
+			"marketing_opt_in":  customer.MarketingOptIn,
+			"opt_in_updated_at": customer.OptInUpdatedAt,
+			"opt_in_source":     customer.OptInSource,
+
+			"custom_fields": customer.CustomFields,
+		},
+	}
+
+	if err := updateVersioned(ctx, r.collection, customer.ID, customer.Version, update, "customer"); err != nil {
+		return err
+	}
+	customer.Version++
+
+	return nil
+}
+
+func (r *CustomerRepository) MarkMerged(id string, targetID primitive.ObjectID) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid customer ID: %w", err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":      Domain.CustomerStatusMerged,
+			"merged_into": targetID,
+			"updated_at":  time.Now(),
+		},
+	}
+
+	if _, err := r.collection.UpdateByID(ctx, objID, update); err != nil {
+		return fmt.Errorf("failed to mark customer as merged: %w", err)
+	}
+
+	return nil
+}
+
+func (r *CustomerRepository) Delete(id string) error {
+	// Archived too, so lookups by phone and segments that only look at active customers skip them
+	return setDeleted(r.collection, id, true, bson.M{"status": Domain.CustomerStatusArchived})
+}
+
+func (r *CustomerRepository) FindDeleted(businessID string) ([]Domain.Customer, error) {
+	var customers []Domain.Customer
+	if err := findDeleted(r.collection, businessID, &customers); err != nil {
+		return nil, err
+	}
+	return customers, nil
+}
+
+func (r *CustomerRepository) Restore(id string) error {
+	return setDeleted(r.collection, id, false, bson.M{"status": Domain.CustomerStatusActive})
+}
+
+func (r *CustomerRepository) PurgeDeleted(before time.Time, limit int) (map[string]int64, error) {
+	return purgeDeleted(r.collection, before, limit)
+}
@@ -0,0 +1,138 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// demoCollections is everything that can be recorded for a shop while it is tried
+// out: what a backup holds, the aggregates built from it, and settings and logs
+var demoCollections = append(append([]string{}, backupCollections...),
+	"sales_summary_daily", "sales_summary_hourly", "pnl_daily_products", "pnl_daily_expenses",
+	"inventory_snapshots", "sync_logs", "saved_report_runs", "shop_backups",
+	"accounting_settings", "accounts", "journal_entries", "bank_statements", "statement_lines",
+	"currency_settings", "exchange_rates", "custom_field_definitions", "scale_settings", "catalog_settings",
+	"email_settings", "email_messages", "push_devices", "push_subscriptions", "push_messages",
+	"print_bridges", "print_jobs", "webhooks", "webhook_deliveries", "telegram_links",
+	"storefront_connections", "storefront_mappings", "storefront_orders",
+	"subscriptions", "plan_usage", "billing_devices", "billing_events",
+)
+
+type DemoRepository struct {
+	db         *mongo.Database
+	businesses *mongo.Collection
+	users      *mongo.Collection
+}
+
+func NewDemoRepository(db *mongo.Database) Domain.DemoRepository {
+	return &DemoRepository{
+		db:         db,
+		businesses: db.Collection("businesses"),
+		users:      db.Collection("users"),
+	}
+}
+
+func (r *DemoRepository) InsertProducts(products []Domain.Product) error {
+	documents := make([]interface{}, len(products))
+	for i := range products {
+		products[i].ID = primitive.NewObjectID()
+		documents[i] = products[i]
+	}
+	return r.insert("products", documents)
+}
+
+func (r *DemoRepository) InsertCustomers(customers []Domain.Customer) error {
+	documents := make([]interface{}, len(customers))
+	for i := range customers {
+		customers[i].ID = primitive.NewObjectID()
+		documents[i] = customers[i]
+	}
+	return r.insert("customers", documents)
+}
+
+func (r *DemoRepository) InsertSales(sales []Domain.Sale) error {
+	documents := make([]interface{}, len(sales))
+	for i := range sales {
+		sales[i].ID = primitive.NewObjectID()
+		documents[i] = sales[i]
+	}
+	return r.insert("sales", documents)
+}
+
+// insert writes the documents a thousand at a time, so a large demo shop does not
+// go in as one oversized command
+func (r *DemoRepository) insert(collection string, documents []interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	const chunk = 1000
+	for start := 0; start < len(documents); start += chunk {
+		end := min(start+chunk, len(documents))
+		if _, err := r.db.Collection(collection).InsertMany(ctx, documents[start:end]); err != nil {
+			return fmt.Errorf("failed to insert demo %s: %w", collection, err)
+		}
+	}
+	return nil
+}
+
+func (r *DemoRepository) CountLive(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := r.businesses.CountDocuments(ctx, bson.M{"demo_expires_at": bson.M{"$gte": now}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count demo shops: %w", err)
+	}
+	return count, nil
+}
+
+func (r *DemoRepository) FindExpired(now time.Time, limit int) ([]Domain.Business, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"demo_expires_at": 1}).SetLimit(int64(limit))
+	cursor, err := r.businesses.Find(ctx, bson.M{"demo_expires_at": bson.M{"$lt": now}}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expired demo shops: %w", err)
+	}
+
+	var businesses []Domain.Business
+	if err := cursor.All(ctx, &businesses); err != nil {
+		return nil, fmt.Errorf("failed to decode demo shops: %w", err)
+	}
+	return businesses, nil
+}
+
+// DeleteShop removes the shop's records before the shop, so a run cut short leaves
+// the shop to be found and finished next time
+func (r *DemoRepository) DeleteShop(business Domain.Business) error {
+	if business.DemoExpiresAt == nil {
+		return fmt.Errorf("business %s is not a demo shop", business.ID.Hex())
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	for _, collection := range demoCollections {
+		if _, err := r.db.Collection(collection).DeleteMany(ctx, bson.M{"business_id": business.ID}); err != nil {
+			return fmt.Errorf("failed to delete demo %s: %w", collection, err)
+		}
+	}
+
+	// The account was made for the demo alone
+	if _, err := r.users.DeleteOne(ctx, bson.M{"_id": business.UserID}); err != nil {
+		return fmt.Errorf("failed to delete demo account: %w", err)
+	}
+	filter := bson.M{"_id": business.ID, "demo_expires_at": bson.M{"$exists": true}}
+	if _, err := r.businesses.DeleteOne(ctx, filter); err != nil {
+		return fmt.Errorf("failed to delete demo shop: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,264 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type DepositRepository struct {
+	items   *mongo.Collection
+	entries *mongo.Collection
+}
+
+func NewDepositRepository(db *mongo.Database) Domain.DepositRepository {
+	return &DepositRepository{
+		items:   db.Collection("deposit_items"),
+		entries: db.Collection("deposit_entries"),
+	}
+}
+
+func (r *DepositRepository) CreateItem(item *Domain.DepositItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	item.Active = true
+	item.CreatedAt = time.Now()
+	item.UpdatedAt = time.Now()
+
+	result, err := r.items.InsertOne(ctx, item)
+	if err != nil {
+		return fmt.Errorf("failed to create deposit item: %w", err)
+	}
+
+	item.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *DepositRepository) FindItemByID(id string) (*Domain.DepositItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid deposit item ID: %w", err)
+	}
+
+	var item Domain.DepositItem
+	err = r.items.FindOne(ctx, bson.M{"_id": objID}).Decode(&item)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find deposit item: %w", err)
+	}
+
+	return &item, nil
+}
+
+func (r *DepositRepository) FindItems(businessID string) ([]Domain.DepositItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.items.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deposit items: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var items []Domain.DepositItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode deposit items: %w", err)
+	}
+
+	return items, nil
+}
+
+func (r *DepositRepository) UpdateItem(item *Domain.DepositItem) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	item.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":       item.Name,
+			"amount":     item.Amount,
+			"active":     item.Active,
+			"updated_at": item.UpdatedAt,
+		},
+	}
+
+	if _, err := r.items.UpdateByID(ctx, item.ID, update); err != nil {
+		return fmt.Errorf("failed to update deposit item: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DepositRepository) CreateEntries(entries []Domain.DepositEntry) error {
+	if len(entries) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(entries))
+	for i := range entries {
+		if entries[i].ID.IsZero() {
+			entries[i].ID = primitive.NewObjectID()
+		}
+		if entries[i].CreatedAt.IsZero() {
+			entries[i].CreatedAt = time.Now()
+		}
+		docs[i] = entries[i]
+	}
+
+	if _, err := r.entries.InsertMany(ctx, docs); err != nil {
+		return fmt.Errorf("failed to create deposit entries: %w", err)
+	}
+
+	return nil
+}
+
+func (r *DepositRepository) FindEntries(businessID string, filters Domain.DepositEntryFilters) ([]Domain.DepositEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.CustomerPhone != nil {
+		query["customer_phone"] = *filters.CustomerPhone
+	}
+
+	if filters.ItemID != nil {
+		objItemID, err := primitive.ObjectIDFromHex(*filters.ItemID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid deposit item ID: %w", err)
+		}
+		query["item_id"] = objItemID
+	}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.StartDate != nil || filters.EndDate != nil {
+		dateQuery := bson.M{}
+		if filters.StartDate != nil {
+			dateQuery["$gte"] = *filters.StartDate
+		}
+		if filters.EndDate != nil {
+			dateQuery["$lt"] = *filters.EndDate
+		}
+		query["created_at"] = dateQuery
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.entries.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deposit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.DepositEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode deposit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *DepositRepository) FindEntriesBySale(saleID primitive.ObjectID) ([]Domain.DepositEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.entries.Find(ctx, bson.M{"sale_id": saleID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deposit entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.DepositEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode deposit entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *DepositRepository) Holdings(businessID string, phone *string) ([]Domain.DepositHolding, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	match := bson.M{"business_id": objBusinessID}
+	if phone != nil {
+		match["customer_phone"] = *phone
+	}
+
+	// The name is the one last given with the phone
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$sort": bson.M{"created_at": 1}},
+		{"$group": bson.M{
+			"_id":           bson.M{"customer_phone": bson.M{"$ifNull": []interface{}{"$customer_phone", ""}}, "item_id": "$item_id"},
+			"customer_name": bson.M{"$last": "$customer_name"},
+			"quantity":      bson.M{"$sum": "$quantity"},
+			"amount":        bson.M{"$sum": "$amount"},
+		}},
+		{"$match": bson.M{"quantity": bson.M{"$ne": 0}}},
+		{"$project": bson.M{
+			"_id":            0,
+			"customer_phone": "$_id.customer_phone",
+			"item_id":        "$_id.item_id",
+			"customer_name":  bson.M{"$ifNull": []interface{}{"$customer_name", ""}},
+			"quantity":       1,
+			"amount":         1,
+		}},
+		{"$sort": bson.D{{Key: "customer_phone", Value: 1}, {Key: "item_id", Value: 1}}},
+	}
+
+	cursor, err := r.entries.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate deposit holdings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var holdings []Domain.DepositHolding
+	if err := cursor.All(ctx, &holdings); err != nil {
+		return nil, fmt.Errorf("failed to decode deposit holdings: %w", err)
+	}
+
+	return holdings, nil
+}
@@ -0,0 +1,312 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+const outboxCollection = "domain_events"
+
+// outbox writes domain events together with the change they describe. On a
+// replica set both go in one transaction; a standalone server has no
+// transactions, so there the events are written straight after the change.
+type outbox struct {
+	db         *mongo.Database
+	collection *mongo.Collection
+}
+
+func newOutbox(db *mongo.Database) *outbox {
+	return &outbox{db: db, collection: db.Collection(outboxCollection)}
+}
+
+// NewOutbox is the outbox for writers outside the repositories, such as sync
+func NewOutbox(db *mongo.Database) Infrastructure.EventOutbox {
+	return newOutbox(db)
+}
+
+// Write runs change and records the events it returns. change must do all its
+// reads and writes with the context it is given, so they join the transaction.
+func (o *outbox) Write(ctx context.Context, change func(ctx context.Context) ([]*Domain.DomainEvent, error)) error {
+	if !Infrastructure.SupportsTransactions(ctx, o.db) {
+		events, err := change(ctx)
+		if err != nil {
+			return err
+		}
+		return o.Append(ctx, events)
+	}
+
+	session, err := o.db.Client().StartSession()
+	if err != nil {
+		return fmt.Errorf("failed to start session: %w", err)
+	}
+	defer session.EndSession(ctx)
+
+	_, err = session.WithTransaction(ctx, func(sc mongo.SessionContext) (interface{}, error) {
+		events, err := change(sc)
+		if err != nil {
+			return nil, err
+		}
+		return nil, o.Append(sc, events)
+	})
+	return err
+}
+
+// Append records the events of a change already written on its own
+func (o *outbox) Append(ctx context.Context, events []*Domain.DomainEvent) error {
+	for _, event := range events {
+		result, err := o.collection.InsertOne(ctx, event)
+		if err != nil {
+			return fmt.Errorf("failed to record %s event: %w", event.Type, err)
+		}
+		event.ID = result.InsertedID.(primitive.ObjectID)
+	}
+	return nil
+}
+
+type DomainEventRepository struct {
+	collection *mongo.Collection
+}
+
+func NewDomainEventRepository(db *mongo.Database) Domain.DomainEventRepository {
+	return &DomainEventRepository{
+		collection: db.Collection(outboxCollection),
+	}
+}
+
+func (r *DomainEventRepository) Append(event *Domain.DomainEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	result, err := r.collection.InsertOne(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to record %s event: %w", event.Type, err)
+	}
+
+	event.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *DomainEventRepository) FindByID(id string) (*Domain.DomainEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid event ID: %w", err)
+	}
+
+	var event Domain.DomainEvent
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find event: %w", err)
+	}
+
+	return &event, nil
+}
+
+func (r *DomainEventRepository) Find(filters Domain.DomainEventFilters) ([]Domain.DomainEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.BusinessID != nil {
+		objBusinessID, err := primitive.ObjectIDFromHex(*filters.BusinessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		query["business_id"] = objBusinessID
+	}
+
+	opts := options.Find().SetSort(bson.M{"occurred_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []Domain.DomainEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *DomainEventRepository) Claim(workerID string, lease time.Duration) (*Domain.DomainEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"status":          Domain.DomainEventPending,
+		"next_attempt_at": bson.M{"$lte": now},
+		"$or": []bson.M{
+			{"locked_until": bson.M{"$exists": false}},
+			{"locked_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"locked_by":    workerID,
+			"locked_until": now.Add(lease),
+			"updated_at":   now,
+		},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"next_attempt_at": 1}).
+		SetReturnDocument(options.After)
+
+	var event Domain.DomainEvent
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&event)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim event: %w", err)
+	}
+
+	return &event, nil
+}
+
+func (r *DomainEventRepository) Finish(event *Domain.DomainEvent, workerID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	event.UpdatedAt = time.Now()
+	event.LockedBy = ""
+	event.LockedUntil = nil
+
+	filter := bson.M{"_id": event.ID, "locked_by": workerID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          event.Status,
+			"deliveries":      event.Deliveries,
+			"next_attempt_at": event.NextAttemptAt,
+			"finished_at":     event.FinishedAt,
+			"updated_at":      event.UpdatedAt,
+		},
+		"$unset": bson.M{"locked_by": "", "locked_until": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to finish event: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("event lease lost")
+	}
+
+	return nil
+}
+
+func (r *DomainEventRepository) Retry(id string) (*Domain.DomainEvent, error) {
+	event, err := r.FindByID(id)
+	if err != nil || event == nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	for i := range event.Deliveries {
+		delivery := &event.Deliveries[i]
+		if delivery.Status == Domain.DomainEventDead {
+			delivery.Status = Domain.DomainEventPending
+			delivery.Attempts = 0
+			delivery.NextAttemptAt = now
+		}
+	}
+	event.Status = Domain.DomainEventPending
+	event.NextAttemptAt = now
+	event.FinishedAt = nil
+	event.UpdatedAt = now
+
+	filter := bson.M{"_id": event.ID, "status": Domain.DomainEventDead}
+	update := bson.M{
+		"$set": bson.M{
+			"status":          event.Status,
+			"deliveries":      event.Deliveries,
+			"next_attempt_at": event.NextAttemptAt,
+			"updated_at":      event.UpdatedAt,
+		},
+		"$unset": bson.M{"finished_at": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retry event: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return nil, nil
+	}
+
+	return event, nil
+}
+
+func (r *DomainEventRepository) Stats() ([]Domain.DomainEventStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pipeline := []bson.M{
+		{
+			"$group": bson.M{
+				"_id":            bson.M{"type": "$type", "status": "$status"},
+				"count":          bson.M{"$sum": 1},
+				"oldest_attempt": bson.M{"$min": "$next_attempt_at"},
+			},
+		},
+		{
+			"$project": bson.M{
+				"_id":            0,
+				"type":           "$_id.type",
+				"status":         "$_id.status",
+				"count":          1,
+				"oldest_attempt": 1,
+			},
+		},
+		{"$sort": bson.D{{Key: "type", Value: 1}, {Key: "status", Value: 1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate event stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var stats []Domain.DomainEventStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode event stats: %w", err)
+	}
+
+	return stats, nil
+}
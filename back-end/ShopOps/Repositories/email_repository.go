@@ -0,0 +1,253 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EmailRepository struct {
+	messagesCollection     *mongo.Collection
+	settingsCollection     *mongo.Collection
+	suppressionsCollection *mongo.Collection
+}
+
+func NewEmailRepository(db *mongo.Database) Domain.EmailRepository {
+	return &EmailRepository{
+		messagesCollection:     db.Collection("email_messages"),
+		settingsCollection:     db.Collection("email_settings"),
+		suppressionsCollection: db.Collection("email_suppressions"),
+	}
+}
+
+func (r *EmailRepository) LogMessage(message *Domain.EmailMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	message.CreatedAt = time.Now()
+
+	result, err := r.messagesCollection.InsertOne(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to log email message: %w", err)
+	}
+
+	message.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *EmailRepository) FindMessageByID(id string) (*Domain.EmailMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid email message ID: %w", err)
+	}
+
+	var message Domain.EmailMessage
+	err = r.messagesCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find email message: %w", err)
+	}
+
+	return &message, nil
+}
+
+func (r *EmailRepository) FindMessages(businessID string, filters Domain.EmailMessageFilters) ([]Domain.EmailMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Template != nil {
+		query["template"] = *filters.Template
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.To != nil {
+		query["to"] = strings.ToLower(*filters.To)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.messagesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find email messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var messages []Domain.EmailMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode email messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *EmailRepository) UpdateMessage(message *Domain.EmailMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":              message.Status,
+			"provider":            message.Provider,
+			"provider_message_id": message.ProviderMessageID,
+			"error":               message.Error,
+			"sent_at":             message.SentAt,
+		},
+	}
+
+	if _, err := r.messagesCollection.UpdateByID(ctx, message.ID, update); err != nil {
+		return fmt.Errorf("failed to update email message: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailRepository) MarkBounced(provider, providerMessageID string, status Domain.EmailStatus, reason string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.messagesCollection.UpdateOne(ctx,
+		bson.M{"provider": provider, "provider_message_id": providerMessageID},
+		bson.M{"$set": bson.M{"status": status, "error": reason, "bounced_at": time.Now()}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to mark email bounced: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailRepository) FindSettings(businessID string) (*Domain.EmailSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var settings Domain.EmailSettings
+	err = r.settingsCollection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find email settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *EmailRepository) SaveSettings(settings *Domain.EmailSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"from_name":        settings.FromName,
+			"reply_to":         settings.ReplyTo,
+			"recipients":       settings.Recipients,
+			"low_stock_digest": settings.LowStockDigest,
+			"digest_hour":      settings.DigestHour,
+			"updated_at":       settings.UpdatedAt,
+		},
+	}
+
+	_, err := r.settingsCollection.UpdateOne(ctx, bson.M{"business_id": settings.BusinessID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save email settings: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailRepository) FindDigestSettings() ([]Domain.EmailSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.settingsCollection.Find(ctx, bson.M{"low_stock_digest": true})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find email settings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var settings []Domain.EmailSettings
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode email settings: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *EmailRepository) SetLastDigestDate(businessID, date string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	_, err = r.settingsCollection.UpdateOne(ctx, bson.M{"business_id": objBusinessID}, bson.M{"$set": bson.M{"last_digest_date": date}})
+	if err != nil {
+		return fmt.Errorf("failed to update email settings: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailRepository) Suppress(entry *Domain.EmailSuppression) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry.Email = strings.ToLower(entry.Email)
+	entry.CreatedAt = time.Now()
+
+	_, err := r.suppressionsCollection.UpdateOne(ctx,
+		bson.M{"_id": entry.Email},
+		bson.M{"$setOnInsert": bson.M{"reason": entry.Reason, "detail": entry.Detail, "created_at": entry.CreatedAt}},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to suppress email: %w", err)
+	}
+	return nil
+}
+
+func (r *EmailRepository) IsSuppressed(email string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	count, err := r.suppressionsCollection.CountDocuments(ctx, bson.M{"_id": strings.ToLower(email)}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check email suppression: %w", err)
+	}
+	return count > 0, nil
+}
@@ -0,0 +1,275 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type EmployeeRepository struct {
+	employeesCollection   *mongo.Collection
+	timeEntriesCollection *mongo.Collection
+}
+
+func NewEmployeeRepository(db *mongo.Database) Domain.EmployeeRepository {
+	return &EmployeeRepository{
+		employeesCollection:   db.Collection("employees"),
+		timeEntriesCollection: db.Collection("time_entries"),
+	}
+}
+
+func (r *EmployeeRepository) Create(employee *Domain.Employee) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	employee.Status = Domain.EmployeeStatusActive
+	employee.CreatedAt = time.Now()
+	employee.UpdatedAt = time.Now()
+
+	result, err := r.employeesCollection.InsertOne(ctx, employee)
+	if err != nil {
+		return fmt.Errorf("failed to create employee: %w", err)
+	}
+
+	employee.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *EmployeeRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Employee, error) {
+	var employee Domain.Employee
+	found, err := findByIDInBusiness(ctx, r.employeesCollection, id, &employee)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &employee, nil
+}
+
+func (r *EmployeeRepository) FindByBusinessID(businessID string, status *Domain.EmployeeStatus) ([]Domain.Employee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+	if status != nil {
+		query["status"] = *status
+	}
+
+	cursor, err := r.employeesCollection.Find(ctx, query, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employees: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var employees []Domain.Employee
+	if err := cursor.All(ctx, &employees); err != nil {
+		return nil, fmt.Errorf("failed to decode employees: %w", err)
+	}
+
+	return employees, nil
+}
+
+func (r *EmployeeRepository) FindByUserID(businessID, userID string) (*Domain.Employee, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var employee Domain.Employee
+	err = r.employeesCollection.FindOne(ctx, bson.M{
+		"business_id": objBusinessID,
+		"user_id":     objUserID,
+	}).Decode(&employee)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+
+	return &employee, nil
+}
+
+func (r *EmployeeRepository) Update(employee *Domain.Employee) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	employee.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"user_id":     employee.UserID,
+			"name":        employee.Name,
+			"phone":       employee.Phone,
+			"position":    employee.Position,
+			"status":      employee.Status,
+			"permissions": employee.Permissions,
+			"updated_at":  employee.UpdatedAt,
+		},
+	}
+
+	_, err := r.employeesCollection.UpdateByID(ctx, employee.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update employee: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EmployeeRepository) CreateTimeEntry(entry *Domain.TimeEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry.CreatedAt = time.Now()
+	entry.UpdatedAt = time.Now()
+
+	result, err := r.timeEntriesCollection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to create time entry: %w", err)
+	}
+
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *EmployeeRepository) FindOpenTimeEntry(employeeID string) (*Domain.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objEmployeeID, err := primitive.ObjectIDFromHex(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid employee ID: %w", err)
+	}
+
+	return r.findOpenTimeEntry(ctx, bson.M{"employee_id": objEmployeeID})
+}
+
+func (r *EmployeeRepository) FindOpenTimeEntryByDevice(businessID, deviceID string) (*Domain.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return r.findOpenTimeEntry(ctx, bson.M{"business_id": objBusinessID, "device_id": deviceID})
+}
+
+func (r *EmployeeRepository) findOpenTimeEntry(ctx context.Context, query bson.M) (*Domain.TimeEntry, error) {
+	query["clock_out_at"] = bson.M{"$exists": false}
+
+	var entry Domain.TimeEntry
+	opts := options.FindOne().SetSort(bson.M{"clock_in_at": -1})
+	err := r.timeEntriesCollection.FindOne(ctx, query, opts).Decode(&entry)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find time entry: %w", err)
+	}
+
+	return &entry, nil
+}
+
+func (r *EmployeeRepository) CloseTimeEntry(entry *Domain.TimeEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"clock_out_at": entry.ClockOutAt,
+			"minutes":      entry.Minutes,
+			"notes":        entry.Notes,
+			"updated_at":   entry.UpdatedAt,
+		},
+	}
+
+	_, err := r.timeEntriesCollection.UpdateByID(ctx, entry.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to close time entry: %w", err)
+	}
+
+	return nil
+}
+
+func (r *EmployeeRepository) FindTimeEntries(businessID string, filters Domain.TimeEntryFilters) ([]Domain.TimeEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.EmployeeID != nil {
+		objEmployeeID, err := primitive.ObjectIDFromHex(*filters.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID: %w", err)
+		}
+		query["employee_id"] = objEmployeeID
+	}
+
+	if filters.DeviceID != nil {
+		query["device_id"] = *filters.DeviceID
+	}
+
+	if filters.StartDate != nil && filters.EndDate != nil {
+		query["clock_in_at"] = bson.M{
+			"$gte": *filters.StartDate,
+			"$lte": *filters.EndDate,
+		}
+	} else if filters.StartDate != nil {
+		query["clock_in_at"] = bson.M{"$gte": *filters.StartDate}
+	} else if filters.EndDate != nil {
+		query["clock_in_at"] = bson.M{"$lte": *filters.EndDate}
+	}
+
+	if filters.OpenOnly {
+		query["clock_out_at"] = bson.M{"$exists": false}
+	}
+
+	opts := options.Find().SetSort(bson.M{"clock_in_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.timeEntriesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find time entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.TimeEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode time entries: %w", err)
+	}
+
+	return entries, nil
+}
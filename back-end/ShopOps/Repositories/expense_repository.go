@@ -42,24 +42,12 @@ func (r *ExpenseRepository) Create(expense *Domain.Expense) error {
 	return nil
 }
 
-func (r *ExpenseRepository) FindByID(id string) (*Domain.Expense, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
-
-	objID, err := primitive.ObjectIDFromHex(id)
-	if err != nil {
-		return nil, fmt.Errorf("invalid expense ID: %w", err)
-	}
-
+func (r *ExpenseRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Expense, error) {
 	var expense Domain.Expense
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&expense)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to find expense: %w", err)
+	found, err := findByIDInBusiness(ctx, r.collection, id, &expense)
+	if err != nil || !found {
+		return nil, err
 	}
-
 	return &expense, nil
 }
 
@@ -93,6 +81,10 @@ func (r *ExpenseRepository) FindByBusinessID(businessID string, filters Domain.E
 		query["status"] = *filters.Status
 	}
 
+	if filters.UpdatedSince != nil {
+		query["updated_at"] = bson.M{"$gte": *filters.UpdatedSince}
+	}
+
 	opts := options.Find().SetSort(bson.M{"date": -1})
 
 	if filters.Limit > 0 {
@@ -280,7 +272,7 @@ func (r *ExpenseRepository) GetSummaryByCategory(businessID string, startDate, e
 	return summaries, nil
 }
 
-func (r *ExpenseRepository) GetTotal(businessID string, startDate, endDate time.Time) (float64, error) {
+func (r *ExpenseRepository) GetTotal(businessID string, startDate, endDate time.Time) (Domain.Money, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -315,7 +307,7 @@ func (r *ExpenseRepository) GetTotal(businessID string, startDate, endDate time.
 	defer cursor.Close(ctx)
 
 	var result struct {
-		Total float64 `bson:"total"`
+		Total Domain.Money `bson:"total"`
 	}
 
 	if cursor.Next(ctx) {
@@ -0,0 +1,115 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type FeatureFlagRepository struct {
+	collection *mongo.Collection
+}
+
+func NewFeatureFlagRepository(db *mongo.Database) Domain.FeatureFlagRepository {
+	return &FeatureFlagRepository{
+		collection: db.Collection("feature_flags"),
+	}
+}
+
+func (r *FeatureFlagRepository) Create(flag *Domain.FeatureFlag) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	flag.CreatedAt = now
+	flag.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, flag)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("feature flag %s already exists", flag.Key))
+		}
+		return fmt.Errorf("failed to create feature flag: %w", err)
+	}
+
+	flag.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *FeatureFlagRepository) FindByKey(key string) (*Domain.FeatureFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var flag Domain.FeatureFlag
+	err := r.collection.FindOne(ctx, bson.M{"key": key}).Decode(&flag)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find feature flag: %w", err)
+	}
+
+	return &flag, nil
+}
+
+func (r *FeatureFlagRepository) FindAll() ([]Domain.FeatureFlag, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, bson.M{}, options.Find().SetSort(bson.M{"key": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find feature flags: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var flags []Domain.FeatureFlag
+	if err := cursor.All(ctx, &flags); err != nil {
+		return nil, fmt.Errorf("failed to decode feature flags: %w", err)
+	}
+
+	return flags, nil
+}
+
+func (r *FeatureFlagRepository) Update(flag *Domain.FeatureFlag) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	flag.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"description":         flag.Description,
+			"enabled":             flag.Enabled,
+			"rollout_percent":     flag.RolloutPercent,
+			"enabled_businesses":  flag.EnabledBusinesses,
+			"disabled_businesses": flag.DisabledBusinesses,
+			"updated_by":          flag.UpdatedBy,
+			"updated_at":          flag.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, flag.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update feature flag: %w", err)
+	}
+
+	return nil
+}
+
+func (r *FeatureFlagRepository) Delete(key string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.collection.DeleteOne(ctx, bson.M{"key": key})
+	if err != nil {
+		return fmt.Errorf("failed to delete feature flag: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,613 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type GiftCardRepository struct {
+	cardsCollection        *mongo.Collection
+	transactionsCollection *mongo.Collection
+}
+
+func NewGiftCardRepository(db *mongo.Database) Domain.GiftCardRepository {
+	return &GiftCardRepository{
+		cardsCollection:        db.Collection("gift_cards"),
+		transactionsCollection: db.Collection("gift_card_transactions"),
+	}
+}
+
+func (r *GiftCardRepository) Create(card *Domain.GiftCard) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	card.Balance = card.InitialBalance
+	card.Status = Domain.GiftCardStatusActive
+	card.CreatedAt = time.Now()
+	card.UpdatedAt = time.Now()
+
+	result, err := r.cardsCollection.InsertOne(ctx, card)
+	if err != nil {
+		return fmt.Errorf("failed to create gift card: %w", err)
+	}
+
+	card.ID = result.InsertedID.(primitive.ObjectID)
+
+	transaction := Domain.GiftCardTransaction{
+		BusinessID:   card.BusinessID,
+		GiftCardID:   card.ID,
+		Type:         Domain.GiftCardTransactionIssue,
+		Amount:       card.InitialBalance,
+		BalanceAfter: card.Balance,
+		CreatedBy:    card.CreatedBy,
+		CreatedAt:    time.Now(),
+	}
+
+	if _, err := r.transactionsCollection.InsertOne(ctx, transaction); err != nil {
+		// Log error but don't fail issuance
+		Infrastructure.Logger.Error("failed to record gift card issue transaction", "gift_card_id", card.ID.Hex(), "error", err)
+	}
+
+	return nil
+}
+
+func (r *GiftCardRepository) findByID(id string) (*Domain.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gift card ID: %w", err)
+	}
+
+	var card Domain.GiftCard
+	err = r.cardsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find gift card: %w", err)
+	}
+
+	return &card, nil
+}
+
+func (r *GiftCardRepository) FindInBusiness(ctx context.Context, id string) (*Domain.GiftCard, error) {
+	var card Domain.GiftCard
+	found, err := findByIDInBusiness(ctx, r.cardsCollection, id, &card)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &card, nil
+}
+
+func (r *GiftCardRepository) FindByCode(businessID, code string) (*Domain.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var card Domain.GiftCard
+	err = r.cardsCollection.FindOne(ctx, bson.M{
+		"business_id": objBusinessID,
+		"code":        code,
+	}).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find gift card: %w", err)
+	}
+
+	return &card, nil
+}
+
+func (r *GiftCardRepository) FindByBusinessID(businessID string, filters Domain.GiftCardFilters) ([]Domain.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.Phone != nil {
+		query["customer_phone"] = *filters.Phone
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.cardsCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gift cards: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var cards []Domain.GiftCard
+	if err := cursor.All(ctx, &cards); err != nil {
+		return nil, fmt.Errorf("failed to decode gift cards: %w", err)
+	}
+
+	return cards, nil
+}
+
+func (r *GiftCardRepository) Redeem(id string, amount Domain.Money, saleID *string, userID string) (*Domain.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gift card ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+
+	// Only decrement when the card is active, unexpired and has enough balance
+	filter := bson.M{
+		"_id":     objID,
+		"status":  Domain.GiftCardStatusActive,
+		"balance": bson.M{"$gte": amount},
+		"$or": []bson.M{
+			{"expires_at": nil},
+			{"expires_at": bson.M{"$gt": now}},
+		},
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"balance": -amount},
+		"$set": bson.M{"updated_at": now},
+	}
+
+	var card Domain.GiftCard
+	err = r.cardsCollection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("gift card is not active, expired or has insufficient balance")
+		}
+		return nil, fmt.Errorf("failed to redeem gift card: %w", err)
+	}
+
+	// Fully used cards are closed out
+	if card.Balance <= 0 {
+		card.Status = Domain.GiftCardStatusRedeemed
+		_, err = r.cardsCollection.UpdateByID(ctx, objID, bson.M{
+			"$set": bson.M{"status": card.Status, "updated_at": now},
+		})
+		if err != nil {
+			Infrastructure.Logger.Error("failed to mark gift card as redeemed", "gift_card_id", card.ID.Hex(), "error", err)
+		}
+	}
+
+	transaction := Domain.GiftCardTransaction{
+		BusinessID:   card.BusinessID,
+		GiftCardID:   objID,
+		Type:         Domain.GiftCardTransactionRedeem,
+		Amount:       amount,
+		BalanceAfter: card.Balance,
+		CreatedBy:    objUserID,
+		CreatedAt:    now,
+	}
+
+	if saleID != nil {
+		objSaleID, err := primitive.ObjectIDFromHex(*saleID)
+		if err == nil {
+			transaction.SaleID = &objSaleID
+		}
+	}
+
+	if _, err := r.transactionsCollection.InsertOne(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to record gift card transaction: %w", err)
+	}
+
+	return &card, nil
+}
+
+func (r *GiftCardRepository) Reload(id string, amount Domain.Money, note string, userID string) (*Domain.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gift card ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+
+	// Reloading a fully redeemed card makes it usable again
+	filter := bson.M{
+		"_id":    objID,
+		"status": bson.M{"$in": []Domain.GiftCardStatus{Domain.GiftCardStatusActive, Domain.GiftCardStatusRedeemed}},
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"balance": amount},
+		"$set": bson.M{
+			"status":     Domain.GiftCardStatusActive,
+			"updated_at": now,
+		},
+	}
+
+	var card Domain.GiftCard
+	err = r.cardsCollection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("gift card cannot be reloaded")
+		}
+		return nil, fmt.Errorf("failed to reload gift card: %w", err)
+	}
+
+	transaction := Domain.GiftCardTransaction{
+		BusinessID:   card.BusinessID,
+		GiftCardID:   objID,
+		Type:         Domain.GiftCardTransactionReload,
+		Amount:       amount,
+		BalanceAfter: card.Balance,
+		Note:         note,
+		CreatedBy:    objUserID,
+		CreatedAt:    now,
+	}
+
+	if _, err := r.transactionsCollection.InsertOne(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to record gift card transaction: %w", err)
+	}
+
+	return &card, nil
+}
+
+// Refund credits the card even once it is redeemed or expired, since the customer
+// paid with it in good faith: the card becomes active again, and one past its
+// expiry gets the default validity from now so the money can still be spent.
+// Voided cards were written off by the shop and are refused.
+func (r *GiftCardRepository) Refund(id string, amount Domain.Money, saleID *string, note string, userID string) (*Domain.GiftCard, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gift card ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	set := bson.M{
+		"status":     Domain.GiftCardStatusActive,
+		"updated_at": now,
+	}
+
+	current, err := r.findByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, fmt.Errorf("gift card not found")
+	}
+	if current.IsExpired(now) || current.Status == Domain.GiftCardStatusExpired {
+		set["expires_at"] = now.AddDate(0, 0, Domain.DefaultGiftCardExpiryDays)
+	}
+
+	filter := bson.M{
+		"_id":    objID,
+		"status": bson.M{"$ne": Domain.GiftCardStatusVoided},
+	}
+
+	update := bson.M{
+		"$inc": bson.M{"balance": amount},
+		"$set": set,
+	}
+
+	var card Domain.GiftCard
+	err = r.cardsCollection.FindOneAndUpdate(ctx, filter, update,
+		options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&card)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("gift card was voided and cannot be refunded")
+		}
+		return nil, fmt.Errorf("failed to refund gift card: %w", err)
+	}
+
+	transaction := Domain.GiftCardTransaction{
+		BusinessID:   card.BusinessID,
+		GiftCardID:   objID,
+		Type:         Domain.GiftCardTransactionRefund,
+		Amount:       amount,
+		BalanceAfter: card.Balance,
+		Note:         note,
+		CreatedBy:    objUserID,
+		CreatedAt:    now,
+	}
+
+	if saleID != nil {
+		objSaleID, err := primitive.ObjectIDFromHex(*saleID)
+		if err == nil {
+			transaction.SaleID = &objSaleID
+		}
+	}
+
+	if _, err := r.transactionsCollection.InsertOne(ctx, transaction); err != nil {
+		return nil, fmt.Errorf("failed to record gift card transaction: %w", err)
+	}
+
+	return &card, nil
+}
+
+func (r *GiftCardRepository) UpdateStatus(id string, status Domain.GiftCardStatus, txType Domain.GiftCardTransactionType, userID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid gift card ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var card Domain.GiftCard
+	err = r.cardsCollection.FindOneAndUpdate(ctx, bson.M{"_id": objID}, bson.M{
+		"$set": bson.M{
+			"status":     status,
+			"balance":    0,
+			"updated_at": time.Now(),
+		},
+	}).Decode(&card)
+	if err != nil {
+		return fmt.Errorf("failed to update gift card status: %w", err)
+	}
+
+	// Record the written-off balance
+	transaction := Domain.GiftCardTransaction{
+		BusinessID:   card.BusinessID,
+		GiftCardID:   objID,
+		Type:         txType,
+		Amount:       card.Balance,
+		BalanceAfter: 0,
+		CreatedBy:    objUserID,
+		CreatedAt:    time.Now(),
+	}
+
+	_, err = r.transactionsCollection.InsertOne(ctx, transaction)
+	return err
+}
+
+func (r *GiftCardRepository) ExpireOverdue(businessID string, now time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	filter := bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.GiftCardStatusActive,
+		"expires_at":  bson.M{"$ne": nil, "$lte": now},
+	}
+
+	cursor, err := r.cardsCollection.Find(ctx, filter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find overdue gift cards: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var cards []Domain.GiftCard
+	if err := cursor.All(ctx, &cards); err != nil {
+		return 0, fmt.Errorf("failed to decode gift cards: %w", err)
+	}
+
+	expired := 0
+	for _, card := range cards {
+		result, err := r.cardsCollection.UpdateOne(ctx,
+			bson.M{"_id": card.ID, "status": Domain.GiftCardStatusActive},
+			bson.M{"$set": bson.M{
+				"status":     Domain.GiftCardStatusExpired,
+				"balance":    0,
+				"updated_at": now,
+			}})
+		if err != nil || result.ModifiedCount == 0 {
+			continue
+		}
+
+		transaction := Domain.GiftCardTransaction{
+			BusinessID:   card.BusinessID,
+			GiftCardID:   card.ID,
+			Type:         Domain.GiftCardTransactionExpire,
+			Amount:       card.Balance,
+			BalanceAfter: 0,
+			Note:         "Expired by policy",
+			CreatedBy:    card.CreatedBy,
+			CreatedAt:    now,
+		}
+
+		if _, err := r.transactionsCollection.InsertOne(ctx, transaction); err != nil {
+			Infrastructure.Logger.Error("failed to record gift card expiry", "gift_card_id", card.ID.Hex(), "error", err)
+		}
+		expired++
+	}
+
+	return expired, nil
+}
+
+func (r *GiftCardRepository) GetTransactions(giftCardID string, limit int) ([]Domain.GiftCardTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(giftCardID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid gift card ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.transactionsCollection.Find(ctx, bson.M{"gift_card_id": objID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gift card transactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []Domain.GiftCardTransaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, fmt.Errorf("failed to decode transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func (r *GiftCardRepository) GetLiability(businessID string, now time.Time) (*Domain.GiftCardLiability, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	match := bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.GiftCardStatusActive,
+		"$or": []bson.M{
+			{"expires_at": nil},
+			{"expires_at": bson.M{"$gt": now}},
+		},
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":         "$type",
+				"outstanding": bson.M{"$sum": "$balance"},
+				"count":       bson.M{"$sum": 1},
+			},
+		},
+		{"$sort": bson.M{"outstanding": -1}},
+	}
+
+	cursor, err := r.cardsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate gift card liability: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var byType []Domain.GiftCardLiabilityType
+	if err := cursor.All(ctx, &byType); err != nil {
+		return nil, fmt.Errorf("failed to decode liability: %w", err)
+	}
+
+	liability := &Domain.GiftCardLiability{ByType: byType}
+	for _, t := range byType {
+		liability.TotalOutstanding += t.Outstanding
+		liability.ActiveCards += t.Count
+	}
+
+	// Balance that will expire within the next 30 days
+	expiringPipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"status":      Domain.GiftCardStatusActive,
+				"expires_at": bson.M{
+					"$gt":  now,
+					"$lte": now.AddDate(0, 0, 30),
+				},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":   nil,
+				"total": bson.M{"$sum": "$balance"},
+			},
+		},
+	}
+
+	cursor, err = r.cardsCollection.Aggregate(ctx, expiringPipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate expiring balance: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var expiring struct {
+		Total Domain.Money `bson:"total"`
+	}
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&expiring); err == nil {
+			liability.ExpiringSoon = expiring.Total
+		}
+	}
+
+	return liability, nil
+}
+
+func (r *GiftCardRepository) ReassignCustomer(businessID, fromPhone, toPhone, toName string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	result, err := r.cardsCollection.UpdateMany(ctx,
+		bson.M{"business_id": objBusinessID, "customer_phone": fromPhone},
+		bson.M{"$set": bson.M{
+			"customer_phone": toPhone,
+			"customer_name":  toName,
+			"updated_at":     time.Now(),
+		}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reassign gift cards: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
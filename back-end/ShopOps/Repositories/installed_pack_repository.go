@@ -0,0 +1,87 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InstalledPackRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInstalledPackRepository(db *mongo.Database) Domain.InstalledPackRepository {
+	return &InstalledPackRepository{
+		collection: db.Collection("installed_packs"),
+	}
+}
+
+func (r *InstalledPackRepository) Find(businessID, code string) (*Domain.InstalledPack, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var pack Domain.InstalledPack
+	err = r.collection.FindOne(ctx, bson.M{"business_id": objBusinessID, "code": code}).Decode(&pack)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find installed pack: %w", err)
+	}
+	return &pack, nil
+}
+
+func (r *InstalledPackRepository) FindByBusiness(businessID string) ([]Domain.InstalledPack, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"code": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find installed packs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var packs []Domain.InstalledPack
+	if err := cursor.All(ctx, &packs); err != nil {
+		return nil, fmt.Errorf("failed to decode installed packs: %w", err)
+	}
+	return packs, nil
+}
+
+func (r *InstalledPackRepository) Save(pack *Domain.InstalledPack) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	pack.UpdatedAt = time.Now()
+	if pack.ID.IsZero() {
+		pack.InstalledAt = pack.UpdatedAt
+		result, err := r.collection.InsertOne(ctx, pack)
+		if err != nil {
+			return fmt.Errorf("failed to save installed pack: %w", err)
+		}
+		pack.ID = result.InsertedID.(primitive.ObjectID)
+		return nil
+	}
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": pack.ID}, pack)
+	if err != nil {
+		return fmt.Errorf("failed to save installed pack: %w", err)
+	}
+	return nil
+}
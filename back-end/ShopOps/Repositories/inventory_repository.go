@@ -3,9 +3,12 @@ package Repositories
 import (
 	"context"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 
 	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -15,12 +18,14 @@ import (
 type InventoryRepository struct {
 	productsCollection  *mongo.Collection
 	movementsCollection *mongo.Collection
+	outbox              *outbox
 }
 
 func NewInventoryRepository(db *mongo.Database) Domain.ProductRepository {
 	return &InventoryRepository{
 		productsCollection:  db.Collection("products"),
 		movementsCollection: db.Collection("stock_movements"),
+		outbox:              newOutbox(db),
 	}
 }
 
@@ -31,6 +36,7 @@ func (r *InventoryRepository) Create(product *Domain.Product) error {
 	product.Status = Domain.ProductStatusActive
 	product.CreatedAt = time.Now()
 	product.UpdatedAt = time.Now()
+	product.PriceChangedAt = &product.CreatedAt
 
 	result, err := r.productsCollection.InsertOne(ctx, product)
 	if err != nil {
@@ -46,8 +52,10 @@ func (r *InventoryRepository) Create(product *Domain.Product) error {
 			ProductID:  product.ID,
 			Type:       Domain.MovementTypePurchase,
 			Quantity:   product.Stock,
+			Change:     product.Stock,
 			Previous:   0,
 			New:        product.Stock,
+			UnitCost:   product.CostPrice,
 			Reason:     "Initial stock",
 			CreatedBy:  product.CreatedBy,
 			CreatedAt:  time.Now(),
@@ -56,44 +64,116 @@ func (r *InventoryRepository) Create(product *Domain.Product) error {
 		_, err = r.movementsCollection.InsertOne(ctx, movement)
 		if err != nil {
 			// Log error but don't fail product creation
-			fmt.Printf("Failed to create stock movement: %v\n", err)
+			Infrastructure.Logger.Error("failed to create stock movement", "product_id", product.ID.Hex(), "error", err)
 		}
 	}
 
 	return nil
 }
 
-func (r *InventoryRepository) FindByID(id string) (*Domain.Product, error) {
+func (r *InventoryRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Product, error) {
+	var product Domain.Product
+	found, err := findByIDInBusiness(ctx, r.productsCollection, id, &product)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (r *InventoryRepository) FindByIDs(businessID string, ids []string) ([]Domain.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objID, err := primitive.ObjectIDFromHex(id)
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
 	if err != nil {
-		return nil, fmt.Errorf("invalid product ID: %w", err)
+		return nil, fmt.Errorf("invalid business ID: %w", err)
 	}
 
-	var product Domain.Product
-	err = r.productsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&product)
-	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		if objID, err := primitive.ObjectIDFromHex(id); err == nil {
+			objIDs = append(objIDs, objID)
 		}
-		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if len(objIDs) == 0 {
+		return nil, nil
 	}
 
-	return &product, nil
+	cursor, err := r.productsCollection.Find(ctx, bson.M{
+		"_id":         bson.M{"$in": objIDs},
+		"business_id": objBusinessID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var products []Domain.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, fmt.Errorf("failed to decode products: %w", err)
+	}
+
+	return products, nil
 }
 
 func (r *InventoryRepository) FindByBusinessID(businessID string, filters Domain.ProductFilters) ([]Domain.Product, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	query, err := r.buildQuery(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find()
+	applyPage(query, opts, filters.Sort, Domain.ProductSorts["name"], filters.After)
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.productsCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var products []Domain.Product
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, fmt.Errorf("failed to decode products: %w", err)
+	}
+
+	return products, nil
+}
+
+func (r *InventoryRepository) Count(businessID string, filters Domain.ProductFilters) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query, err := r.buildQuery(businessID, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.productsCollection.CountDocuments(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count products: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *InventoryRepository) buildQuery(businessID string, filters Domain.ProductFilters) (bson.M, error) {
 	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid business ID: %w", err)
 	}
 
-	query := bson.M{"business_id": objBusinessID}
+	query := bson.M{"business_id": objBusinessID, "deleted_at": notDeleted}
 
 	if filters.Category != nil {
 		query["category"] = *filters.Category
@@ -111,32 +191,31 @@ func (r *InventoryRepository) FindByBusinessID(businessID string, filters Domain
 		}
 	}
 
-	if filters.LowStock != nil && *filters.LowStock {
-		query["$expr"] = bson.M{"$lt": []interface{}{"$stock", "$min_stock"}}
+	if filters.Barcode != nil {
+		query["barcode"] = *filters.Barcode
 	}
 
-	opts := options.Find().SetSort(bson.M{"name": 1})
+	if filters.SKU != nil {
+		query["sku"] = *filters.SKU
+	}
 
-	if filters.Limit > 0 {
-		opts.SetLimit(int64(filters.Limit))
+	if filters.PLU != nil {
+		query["plu"] = *filters.PLU
 	}
 
-	if filters.Offset > 0 {
-		opts.SetSkip(int64(filters.Offset))
+	if filters.HasPLU != nil && *filters.HasPLU {
+		query["plu"] = bson.M{"$exists": true, "$ne": ""}
 	}
 
-	cursor, err := r.productsCollection.Find(ctx, query, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find products: %w", err)
+	if filters.PriceChangedSince != nil {
+		query["price_changed_at"] = bson.M{"$gte": *filters.PriceChangedSince}
 	}
-	defer cursor.Close(ctx)
 
-	var products []Domain.Product
-	if err := cursor.All(ctx, &products); err != nil {
-		return nil, fmt.Errorf("failed to decode products: %w", err)
+	if filters.LowStock != nil && *filters.LowStock {
+		query["$expr"] = bson.M{"$lt": []interface{}{"$stock", "$min_stock"}}
 	}
 
-	return products, nil
+	return query, nil
 }
 
 func (r *InventoryRepository) Update(product *Domain.Product) error {
@@ -147,32 +226,60 @@ func (r *InventoryRepository) Update(product *Domain.Product) error {
 
 	update := bson.M{
 		"$set": bson.M{
-			"name":          product.Name,
-			"description":   product.Description,
-			"sku":           product.SKU,
-			"barcode":       product.Barcode,
-			"category":      product.Category,
-			"unit":          product.Unit,
-			"cost_price":    product.CostPrice,
-			"selling_price": product.SellingPrice,
-			"stock":         product.Stock,
-			"min_stock":     product.MinStock,
-			"max_stock":     product.MaxStock,
-			"image_url":     product.ImageURL,
-			"status":        product.Status,
-			"updated_at":    product.UpdatedAt,
+			"name":             product.Name,
+			"description":      product.Description,
+			"sku":              product.SKU,
+			"barcode":          product.Barcode,
+			"category":         product.Category,
+			"unit":             product.Unit,
+			"cost_price":       product.CostPrice,
+			"selling_price":    product.SellingPrice,
+			"tier_prices":      product.TierPrices,
+			"min_stock":        product.MinStock,
+			"max_stock":        product.MaxStock,
+			"image_url":        product.ImageURL,
+			"status":           product.Status,
+			"plu":              product.PLU,
+			"weighed":          product.Weighed,
+			"service":          product.Service,
+			"duration_minutes": product.DurationMinutes,
+			"custom_fields":    product.CustomFields,
+			"updated_at":       product.UpdatedAt,
+			"price_changed_at": product.PriceChangedAt,
 		},
 	}
 
-	_, err := r.productsCollection.UpdateByID(ctx, product.ID, update)
-	if err != nil {
-		return fmt.Errorf("failed to update product: %w", err)
+	// Stock is left out: it only changes through AdjustStock, which may have run since the product was read
+	if err := updateVersioned(ctx, r.productsCollection, product.ID, product.Version, update, "product"); err != nil {
+		return err
 	}
+	product.Version++
 
 	return nil
 }
 
 func (r *InventoryRepository) Delete(id string) error {
+	// Discontinued too, so reports and alerts that only look at active products skip it
+	return setDeleted(r.productsCollection, id, true, bson.M{"status": Domain.ProductStatusDiscontinued})
+}
+
+func (r *InventoryRepository) FindDeleted(businessID string) ([]Domain.Product, error) {
+	var products []Domain.Product
+	if err := findDeleted(r.productsCollection, businessID, &products); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+func (r *InventoryRepository) Restore(id string) error {
+	return setDeleted(r.productsCollection, id, false, bson.M{"status": Domain.ProductStatusActive})
+}
+
+func (r *InventoryRepository) PurgeDeleted(before time.Time, limit int) (map[string]int64, error) {
+	return purgeDeleted(r.productsCollection, before, limit)
+}
+
+func (r *InventoryRepository) SetConsignment(id string, consignment *Domain.ProductConsignment) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -181,19 +288,60 @@ func (r *InventoryRepository) Delete(id string) error {
 		return fmt.Errorf("invalid product ID: %w", err)
 	}
 
-	// Soft delete - mark as discontinued
 	update := bson.M{
-		"$set": bson.M{
-			"status":     Domain.ProductStatusDiscontinued,
-			"updated_at": time.Now(),
-		},
+		"$set": bson.M{"updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if consignment != nil {
+		update["$set"].(bson.M)["consignment"] = consignment
+	} else {
+		update["$unset"] = bson.M{"consignment": ""}
 	}
 
-	_, err = r.productsCollection.UpdateByID(ctx, objID, update)
-	return err
+	if _, err := r.productsCollection.UpdateByID(ctx, objID, update); err != nil {
+		return fmt.Errorf("failed to set product consignment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InventoryRepository) SetDeposits(id string, deposits []Domain.ProductDeposit) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{"updated_at": time.Now()},
+		"$inc": bson.M{"version": 1},
+	}
+	if len(deposits) > 0 {
+		update["$set"].(bson.M)["deposits"] = deposits
+	} else {
+		update["$unset"] = bson.M{"deposits": ""}
+	}
+
+	if _, err := r.productsCollection.UpdateByID(ctx, objID, update); err != nil {
+		return fmt.Errorf("failed to set product deposits: %w", err)
+	}
+
+	return nil
 }
 
 func (r *InventoryRepository) AdjustStock(productID string, quantity float64, movementType Domain.MovementType, reason string, referenceID *string, referenceType string, userID string) error {
+	return r.adjustStock(productID, quantity, nil, movementType, reason, referenceID, referenceType, userID)
+}
+
+func (r *InventoryRepository) AddStockAtCost(productID string, quantity float64, unitCost Domain.Money, movementType Domain.MovementType, reason string, referenceID *string, referenceType string, userID string) error {
+	return r.adjustStock(productID, quantity, &unitCost, movementType, reason, referenceID, referenceType, userID)
+}
+
+// adjustStock moves the product's stock, averaging its cost price with unitCost
+// over the stock added when one is given
+func (r *InventoryRepository) adjustStock(productID string, quantity float64, unitCost *Domain.Money, movementType Domain.MovementType, reason string, referenceID *string, referenceType string, userID string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
@@ -207,67 +355,86 @@ func (r *InventoryRepository) AdjustStock(productID string, quantity float64, mo
 		return fmt.Errorf("invalid user ID: %w", err)
 	}
 
-	// Get current product
-	var product Domain.Product
-	err = r.productsCollection.FindOne(ctx, bson.M{"_id": objProductID}).Decode(&product)
-	if err != nil {
-		return fmt.Errorf("failed to find product: %w", err)
-	}
+	// The product, its movement and the stock.adjusted event are written together
+	return r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		var product Domain.Product
+		err := r.productsCollection.FindOne(ctx, bson.M{"_id": objProductID}).Decode(&product)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+
+		// Calculate new stock based on movement type
+		var newStock float64
+		previousStock := product.Stock
+
+		switch movementType {
+		case Domain.MovementTypePurchase, Domain.MovementTypeReturn, Domain.MovementTypeAdjust, Domain.MovementTypeConsignmentIn, Domain.MovementTypeProduction, Domain.MovementTypeTransferIn:
+			newStock = previousStock + quantity
+		case Domain.MovementTypeSale, Domain.MovementTypeDamage, Domain.MovementTypeTheft, Domain.MovementTypeConsignmentReturn, Domain.MovementTypeBackorder, Domain.MovementTypeRepair, Domain.MovementTypeProductionUse, Domain.MovementTypeTransferOut:
+			newStock = previousStock - quantity
+			if newStock < 0 {
+				return nil, fmt.Errorf("insufficient stock. Available: %.2f, Required: %.2f", previousStock, quantity)
+			}
+		}
 
-	// Calculate new stock based on movement type
-	var newStock float64
-	previousStock := product.Stock
+		// Update product stock
+		update := bson.M{
+			"$set": bson.M{
+				"stock":      newStock,
+				"updated_at": time.Now(),
+			},
+		}
 
-	switch movementType {
-	case Domain.MovementTypePurchase, Domain.MovementTypeReturn, Domain.MovementTypeAdjust:
-		newStock = previousStock + quantity
-	case Domain.MovementTypeSale, Domain.MovementTypeDamage, Domain.MovementTypeTheft:
-		newStock = previousStock - quantity
-		if newStock < 0 {
-			return fmt.Errorf("insufficient stock. Available: %.2f, Required: %.2f", previousStock, quantity)
+		if unitCost != nil && newStock > 0 {
+			// Stock already held keeps its cost; none held, or less than none, has no say
+			held := math.Max(previousStock, 0)
+			total := float64(product.CostPrice)*held + float64(*unitCost)*(newStock-held)
+			product.CostPrice = Domain.Money(math.Round(total / newStock))
+			update["$set"].(bson.M)["cost_price"] = product.CostPrice
+			// The cost is the product's, so a client still holding the old one must read it again
+			update["$inc"] = bson.M{"version": 1}
 		}
-	}
 
-	// Update product stock
-	update := bson.M{
-		"$set": bson.M{
-			"stock":      newStock,
-			"updated_at": time.Now(),
-		},
-	}
+		_, err = r.productsCollection.UpdateByID(ctx, objProductID, update)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update product stock: %w", err)
+		}
 
-	_, err = r.productsCollection.UpdateByID(ctx, objProductID, update)
-	if err != nil {
-		return fmt.Errorf("failed to update product stock: %w", err)
-	}
-
-	// Create stock movement record
-	movement := Domain.StockMovement{
-		BusinessID: product.BusinessID,
-		ProductID:  objProductID,
-		Type:       movementType,
-		Quantity:   quantity,
-		Previous:   previousStock,
-		New:        newStock,
-		Reason:     reason,
-		CreatedBy:  objUserID,
-		CreatedAt:  time.Now(),
-	}
-
-	if referenceID != nil {
-		objReferenceID, err := primitive.ObjectIDFromHex(*referenceID)
-		if err == nil {
-			movement.ReferenceID = &objReferenceID
-			movement.ReferenceType = referenceType
+		// Create stock movement record
+		movement := Domain.StockMovement{
+			ID:         primitive.NewObjectID(),
+			BusinessID: product.BusinessID,
+			ProductID:  objProductID,
+			Type:       movementType,
+			Quantity:   quantity,
+			Change:     newStock - previousStock,
+			Previous:   previousStock,
+			New:        newStock,
+			UnitCost:   product.CostPrice,
+			Reason:     reason,
+			CreatedBy:  objUserID,
+			CreatedAt:  time.Now(),
 		}
-	}
 
-	_, err = r.movementsCollection.InsertOne(ctx, movement)
-	if err != nil {
-		return fmt.Errorf("failed to create stock movement: %w", err)
-	}
+		if referenceID != nil {
+			objReferenceID, err := primitive.ObjectIDFromHex(*referenceID)
+			if err == nil {
+				movement.ReferenceID = &objReferenceID
+				movement.ReferenceType = referenceType
+			}
+		}
 
-	return nil
+		_, err = r.movementsCollection.InsertOne(ctx, movement)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create stock movement: %w", err)
+		}
+
+		event, err := Domain.NewDomainEvent(product.BusinessID, Domain.EventStockAdjusted, productID, movement)
+		if err != nil {
+			return nil, err
+		}
+		return []*Domain.DomainEvent{event}, nil
+	})
 }
 
 func (r *InventoryRepository) GetLowStock(businessID string, threshold float64) ([]Domain.Product, error) {
@@ -299,6 +466,35 @@ func (r *InventoryRepository) GetLowStock(businessID string, threshold float64)
 	return products, nil
 }
 
+func (r *InventoryRepository) FindCategories(businessID string) ([]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	values, err := r.productsCollection.Distinct(ctx, "category", bson.M{
+		"business_id": objBusinessID,
+		"deleted_at":  notDeleted,
+		"category":    bson.M{"$nin": []interface{}{nil, ""}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find categories: %w", err)
+	}
+
+	categories := make([]string, 0, len(values))
+	for _, v := range values {
+		if category, ok := v.(string); ok {
+			categories = append(categories, category)
+		}
+	}
+	sort.Strings(categories)
+
+	return categories, nil
+}
+
 func (r *InventoryRepository) GetStockHistory(productID string, limit int) ([]Domain.StockMovement, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
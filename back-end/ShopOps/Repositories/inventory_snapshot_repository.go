@@ -0,0 +1,84 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type InventorySnapshotRepository struct {
+	collection *mongo.Collection
+}
+
+func NewInventorySnapshotRepository(db *mongo.Database) Domain.InventorySnapshotRepository {
+	return &InventorySnapshotRepository{
+		collection: db.Collection("inventory_snapshots"),
+	}
+}
+
+func (r *InventorySnapshotRepository) Save(snapshot *Domain.InventorySnapshot) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"business_id": snapshot.BusinessID, "day": snapshot.Day}
+
+	// Replacing keeps the existing _id, since ID is omitted while unset
+	snapshot.ID = primitive.ObjectID{}
+
+	_, err := r.collection.ReplaceOne(ctx, filter, snapshot, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save inventory snapshot: %w", err)
+	}
+
+	return nil
+}
+
+func (r *InventorySnapshotRepository) Exists(businessID string, day time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return false, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"business_id": objBusinessID, "day": day}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, fmt.Errorf("failed to check inventory snapshot: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *InventorySnapshotRepository) FindTotals(businessID string, fromDay, toDay time.Time) ([]Domain.InventorySnapshot, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := options.Find().
+		SetProjection(bson.M{"lines": 0}).
+		SetSort(bson.M{"day": 1})
+
+	cursor, err := r.collection.Find(ctx, match, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find inventory snapshots: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var snapshots []Domain.InventorySnapshot
+	if err := cursor.All(ctx, &snapshots); err != nil {
+		return nil, fmt.Errorf("failed to decode inventory snapshots: %w", err)
+	}
+
+	return snapshots, nil
+}
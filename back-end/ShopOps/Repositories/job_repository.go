@@ -0,0 +1,278 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type JobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewJobRepository(db *mongo.Database) Domain.JobRepository {
+	return &JobRepository{
+		collection: db.Collection("jobs"),
+	}
+}
+
+func (r *JobRepository) Create(job *Domain.Job) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	job.CreatedAt = now
+	job.UpdatedAt = now
+	if job.RunAt.IsZero() {
+		job.RunAt = now
+	}
+
+	result, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to create job: %w", err)
+	}
+
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *JobRepository) FindByID(id string) (*Domain.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	var job Domain.Job
+	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *JobRepository) Find(filters Domain.JobFilters) ([]Domain.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.BusinessID != nil {
+		objBusinessID, err := primitive.ObjectIDFromHex(*filters.BusinessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		query["business_id"] = objBusinessID
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []Domain.Job
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *JobRepository) Claim(jobType Domain.JobType, workerID string, lease time.Duration) (*Domain.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	filter := bson.M{
+		"type": jobType,
+		"$or": []bson.M{
+			{"status": Domain.JobStatusQueued, "run_at": bson.M{"$lte": now}},
+			{"status": Domain.JobStatusRunning, "locked_until": bson.M{"$lt": now}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       Domain.JobStatusRunning,
+			"locked_by":    workerID,
+			"locked_until": now.Add(lease),
+			"updated_at":   now,
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"run_at": 1}).
+		SetReturnDocument(options.After)
+
+	var job Domain.Job
+	err := r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *JobRepository) Heartbeat(id, workerID string, lease time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{"_id": objID, "locked_by": workerID}
+	update := bson.M{"$set": bson.M{"locked_until": now.Add(lease), "updated_at": now}}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to extend job lease: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("job lease lost")
+	}
+
+	return nil
+}
+
+func (r *JobRepository) Finish(job *Domain.Job, workerID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job.UpdatedAt = time.Now()
+	job.LockedBy = ""
+	job.LockedUntil = nil
+
+	filter := bson.M{"_id": job.ID, "locked_by": workerID}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      job.Status,
+			"attempts":    job.Attempts,
+			"run_at":      job.RunAt,
+			"last_error":  job.LastError,
+			"finished_at": job.FinishedAt,
+			"updated_at":  job.UpdatedAt,
+		},
+		"$unset": bson.M{"locked_by": "", "locked_until": ""},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to finish job: %w", err)
+	}
+	if result.MatchedCount == 0 {
+		return fmt.Errorf("job lease lost")
+	}
+
+	return nil
+}
+
+func (r *JobRepository) Retry(id string) (*Domain.Job, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid job ID: %w", err)
+	}
+
+	now := time.Now()
+	filter := bson.M{
+		"_id":    objID,
+		"status": bson.M{"$in": []Domain.JobStatus{Domain.JobStatusDead, Domain.JobStatusQueued}},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     Domain.JobStatusQueued,
+			"attempts":   0,
+			"run_at":     now,
+			"updated_at": now,
+		},
+		"$unset": bson.M{"finished_at": ""},
+	}
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+
+	var job Domain.Job
+	err = r.collection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to retry job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *JobRepository) Stats() ([]Domain.JobStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	pipeline := []bson.M{
+		{
+			"$group": bson.M{
+				"_id":           bson.M{"type": "$type", "status": "$status"},
+				"count":         bson.M{"$sum": 1},
+				"oldest_run_at": bson.M{"$min": "$run_at"},
+			},
+		},
+		{
+			"$project": bson.M{
+				"_id":           0,
+				"type":          "$_id.type",
+				"status":        "$_id.status",
+				"count":         1,
+				"oldest_run_at": 1,
+			},
+		},
+		{"$sort": bson.D{{Key: "type", Value: 1}, {Key: "status", Value: 1}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate job stats: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var stats []Domain.JobStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode job stats: %w", err)
+	}
+
+	return stats, nil
+}
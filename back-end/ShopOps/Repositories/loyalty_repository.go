@@ -0,0 +1,421 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type LoyaltyRepository struct {
+	programsCollection     *mongo.Collection
+	accountsCollection     *mongo.Collection
+	transactionsCollection *mongo.Collection
+}
+
+func NewLoyaltyRepository(db *mongo.Database) Domain.LoyaltyRepository {
+	return &LoyaltyRepository{
+		programsCollection:     db.Collection("loyalty_programs"),
+		accountsCollection:     db.Collection("loyalty_accounts"),
+		transactionsCollection: db.Collection("loyalty_transactions"),
+	}
+}
+
+func (r *LoyaltyRepository) GetProgram(businessID string) (*Domain.LoyaltyProgram, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var program Domain.LoyaltyProgram
+	err = r.programsCollection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&program)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find loyalty program: %w", err)
+	}
+
+	return &program, nil
+}
+
+func (r *LoyaltyRepository) SaveProgram(program *Domain.LoyaltyProgram) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	program.UpdatedAt = time.Now()
+	if program.CreatedAt.IsZero() {
+		program.CreatedAt = program.UpdatedAt
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":           program.Enabled,
+			"points_per_unit":   program.PointsPerUnit,
+			"point_value":       program.PointValue,
+			"min_purchase":      program.MinPurchase,
+			"min_redeem_points": program.MinRedeemPoints,
+			"rules":             program.Rules,
+			"updated_by":        program.UpdatedBy,
+			"updated_at":        program.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": program.CreatedAt,
+		},
+	}
+
+	var saved Domain.LoyaltyProgram
+	err := r.programsCollection.FindOneAndUpdate(ctx,
+		bson.M{"business_id": program.BusinessID},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&saved)
+	if err != nil {
+		return fmt.Errorf("failed to save loyalty program: %w", err)
+	}
+
+	program.ID = saved.ID
+	return nil
+}
+
+func (r *LoyaltyRepository) FindAccountByID(id string) (*Domain.LoyaltyAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loyalty account ID: %w", err)
+	}
+
+	var account Domain.LoyaltyAccount
+	err = r.accountsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find loyalty account: %w", err)
+	}
+
+	return &account, nil
+}
+
+func (r *LoyaltyRepository) FindAccountByPhone(businessID, phone string) (*Domain.LoyaltyAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var account Domain.LoyaltyAccount
+	err = r.accountsCollection.FindOne(ctx, bson.M{
+		"business_id":    objBusinessID,
+		"customer_phone": phone,
+	}).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find loyalty account: %w", err)
+	}
+
+	return &account, nil
+}
+
+func (r *LoyaltyRepository) FindAccounts(businessID string, limit, offset int) ([]Domain.LoyaltyAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"points": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+	if offset > 0 {
+		opts.SetSkip(int64(offset))
+	}
+
+	cursor, err := r.accountsCollection.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find loyalty accounts: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var accounts []Domain.LoyaltyAccount
+	if err := cursor.All(ctx, &accounts); err != nil {
+		return nil, fmt.Errorf("failed to decode loyalty accounts: %w", err)
+	}
+
+	return accounts, nil
+}
+
+func (r *LoyaltyRepository) AddPoints(businessID, phone, name string, points float64, value Domain.Money, txType Domain.LoyaltyTransactionType, saleID *string, userID, note string) (*Domain.LoyaltyAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+
+	inc := bson.M{"points": points}
+	if txType == Domain.LoyaltyTransactionEarn {
+		inc["lifetime_earned"] = points
+	}
+
+	set := bson.M{"updated_at": now}
+	if name != "" {
+		set["customer_name"] = name
+	}
+
+	// Accounts are created on first earn
+	update := bson.M{
+		"$inc": inc,
+		"$set": set,
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}
+
+	var account Domain.LoyaltyAccount
+	err = r.accountsCollection.FindOneAndUpdate(ctx,
+		bson.M{"business_id": objBusinessID, "customer_phone": phone},
+		update,
+		options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After),
+	).Decode(&account)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add loyalty points: %w", err)
+	}
+
+	if err := r.recordTransaction(ctx, &account, txType, points, value, saleID, objUserID, note, now); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *LoyaltyRepository) DeductPoints(accountID string, points float64, value Domain.Money, txType Domain.LoyaltyTransactionType, saleID *string, userID, note string) (*Domain.LoyaltyAccount, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loyalty account ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+
+	inc := bson.M{"points": -points}
+	if txType == Domain.LoyaltyTransactionRedeem {
+		inc["lifetime_redeemed"] = points
+	}
+
+	var account Domain.LoyaltyAccount
+	err = r.accountsCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": objID, "points": bson.M{"$gte": points}},
+		bson.M{"$inc": inc, "$set": bson.M{"updated_at": now}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&account)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, fmt.Errorf("insufficient loyalty points")
+		}
+		return nil, fmt.Errorf("failed to deduct loyalty points: %w", err)
+	}
+
+	if err := r.recordTransaction(ctx, &account, txType, -points, -value, saleID, objUserID, note, now); err != nil {
+		return nil, err
+	}
+
+	return &account, nil
+}
+
+func (r *LoyaltyRepository) recordTransaction(ctx context.Context, account *Domain.LoyaltyAccount, txType Domain.LoyaltyTransactionType, points float64, value Domain.Money, saleID *string, userID primitive.ObjectID, note string, at time.Time) error {
+	transaction := Domain.LoyaltyTransaction{
+		BusinessID:   account.BusinessID,
+		AccountID:    account.ID,
+		Type:         txType,
+		Points:       points,
+		Value:        value,
+		BalanceAfter: account.Points,
+		Note:         note,
+		CreatedBy:    userID,
+		CreatedAt:    at,
+	}
+
+	if saleID != nil {
+		objSaleID, err := primitive.ObjectIDFromHex(*saleID)
+		if err == nil {
+			transaction.SaleID = &objSaleID
+		}
+	}
+
+	if _, err := r.transactionsCollection.InsertOne(ctx, transaction); err != nil {
+		return fmt.Errorf("failed to record loyalty transaction: %w", err)
+	}
+
+	return nil
+}
+
+func (r *LoyaltyRepository) GetTransactions(accountID string, limit int) ([]Domain.LoyaltyTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loyalty account ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.transactionsCollection.Find(ctx, bson.M{"account_id": objID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find loyalty transactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []Domain.LoyaltyTransaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, fmt.Errorf("failed to decode loyalty transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func (r *LoyaltyRepository) GetSaleTransactions(saleID string) ([]Domain.LoyaltyTransaction, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objSaleID, err := primitive.ObjectIDFromHex(saleID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sale ID: %w", err)
+	}
+
+	cursor, err := r.transactionsCollection.Find(ctx, bson.M{"sale_id": objSaleID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find loyalty transactions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transactions []Domain.LoyaltyTransaction
+	if err := cursor.All(ctx, &transactions); err != nil {
+		return nil, fmt.Errorf("failed to decode loyalty transactions: %w", err)
+	}
+
+	return transactions, nil
+}
+
+func (r *LoyaltyRepository) GetActivity(businessID string, startDate, endDate time.Time) ([]Domain.LoyaltyActivity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"created_at": bson.M{
+					"$gte": startDate,
+					"$lte": endDate,
+				},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":    "$type",
+				"points": bson.M{"$sum": "$points"},
+				"value":  bson.M{"$sum": "$value"},
+			},
+		},
+	}
+
+	cursor, err := r.transactionsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate loyalty activity: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var activity []Domain.LoyaltyActivity
+	if err := cursor.All(ctx, &activity); err != nil {
+		return nil, fmt.Errorf("failed to decode loyalty activity: %w", err)
+	}
+
+	return activity, nil
+}
+
+func (r *LoyaltyRepository) GetOutstanding(businessID string) (float64, int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"points":      bson.M{"$gt": 0},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":     nil,
+				"points":  bson.M{"$sum": "$points"},
+				"members": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	cursor, err := r.accountsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to aggregate outstanding points: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		Points  float64 `bson:"points"`
+		Members int     `bson:"members"`
+	}
+
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return 0, 0, fmt.Errorf("failed to decode result: %w", err)
+		}
+	}
+
+	return result.Points, result.Members, nil
+}
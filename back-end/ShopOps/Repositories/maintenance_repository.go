@@ -0,0 +1,55 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Maintenance state is a single document in system_settings, shared by every instance
+const maintenanceSettingID = "maintenance"
+
+type MaintenanceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMaintenanceRepository(db *mongo.Database) Domain.MaintenanceRepository {
+	return &MaintenanceRepository{
+		collection: db.Collection("system_settings"),
+	}
+}
+
+func (r *MaintenanceRepository) Get() (*Domain.MaintenanceState, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var state Domain.MaintenanceState
+	err := r.collection.FindOne(ctx, bson.M{"_id": maintenanceSettingID}).Decode(&state)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return &Domain.MaintenanceState{}, nil
+		}
+		return nil, fmt.Errorf("failed to find maintenance state: %w", err)
+	}
+
+	return &state, nil
+}
+
+func (r *MaintenanceRepository) Save(state *Domain.MaintenanceState) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	state.UpdatedAt = time.Now()
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": maintenanceSettingID}, state, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save maintenance state: %w", err)
+	}
+
+	return nil
+}
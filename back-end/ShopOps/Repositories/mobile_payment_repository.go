@@ -0,0 +1,155 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type MobilePaymentRepository struct {
+	collection *mongo.Collection
+}
+
+func NewMobilePaymentRepository(db *mongo.Database) Domain.MobilePaymentRepository {
+	return &MobilePaymentRepository{
+		collection: db.Collection("mobile_payments"),
+	}
+}
+
+func (r *MobilePaymentRepository) Create(payment *Domain.MobilePayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	if payment.ID.IsZero() {
+		payment.ID = primitive.NewObjectID()
+	}
+	payment.CreatedAt = now
+	payment.UpdatedAt = now
+
+	if _, err := r.collection.InsertOne(ctx, payment); err != nil {
+		return fmt.Errorf("failed to create mobile payment: %w", err)
+	}
+	return nil
+}
+
+func (r *MobilePaymentRepository) FindByID(id string) (*Domain.MobilePayment, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid mobile payment ID: %w", err)
+	}
+	return r.findOne(bson.M{"_id": objID})
+}
+
+func (r *MobilePaymentRepository) FindInBusiness(ctx context.Context, id string) (*Domain.MobilePayment, error) {
+	var payment Domain.MobilePayment
+	found, err := findByIDInBusiness(ctx, r.collection, id, &payment)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &payment, nil
+}
+
+func (r *MobilePaymentRepository) FindByProviderRef(provider Domain.MobileMoneyProvider, providerRef string) (*Domain.MobilePayment, error) {
+	return r.findOne(bson.M{"provider": provider, "provider_ref": providerRef})
+}
+
+func (r *MobilePaymentRepository) findOne(query bson.M) (*Domain.MobilePayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var payment Domain.MobilePayment
+	err := r.collection.FindOne(ctx, query).Decode(&payment)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find mobile payment: %w", err)
+	}
+
+	return &payment, nil
+}
+
+func (r *MobilePaymentRepository) FindBySale(saleID string) ([]Domain.MobilePayment, error) {
+	objSaleID, err := primitive.ObjectIDFromHex(saleID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid sale ID: %w", err)
+	}
+	return r.find(bson.M{"sale_id": objSaleID}, options.Find().SetSort(bson.M{"created_at": -1}))
+}
+
+func (r *MobilePaymentRepository) FindStale(before time.Time, limit int) ([]Domain.MobilePayment, error) {
+	query := bson.M{
+		"status":     Domain.MobilePaymentPending,
+		"updated_at": bson.M{"$lt": before},
+	}
+	return r.find(query, options.Find().SetSort(bson.M{"updated_at": 1}).SetLimit(int64(limit)))
+}
+
+func (r *MobilePaymentRepository) find(query bson.M, opts *options.FindOptions) ([]Domain.MobilePayment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find mobile payments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var payments []Domain.MobilePayment
+	if err := cursor.All(ctx, &payments); err != nil {
+		return nil, fmt.Errorf("failed to decode mobile payments: %w", err)
+	}
+
+	return payments, nil
+}
+
+func (r *MobilePaymentRepository) Transition(payment *Domain.MobilePayment, from Domain.MobilePaymentStatus) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	payment.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":         payment.Status,
+			"provider_ref":   payment.ProviderRef,
+			"transaction_id": payment.TransactionID,
+			"failure_reason": payment.FailureReason,
+			"completed_at":   payment.CompletedAt,
+			"reversed_at":    payment.ReversedAt,
+			"updated_at":     payment.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, bson.M{"_id": payment.ID, "status": from}, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to update mobile payment: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *MobilePaymentRepository) SetProviderRef(id primitive.ObjectID, providerRef, checkoutURL string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"provider_ref": providerRef,
+			"checkout_url": checkoutURL,
+			"updated_at":   time.Now(),
+		},
+	}
+
+	if _, err := r.collection.UpdateByID(ctx, id, update); err != nil {
+		return fmt.Errorf("failed to update mobile payment: %w", err)
+	}
+	return nil
+}
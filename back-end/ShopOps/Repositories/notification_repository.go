@@ -0,0 +1,192 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type NotificationRepository struct {
+	notificationsCollection *mongo.Collection
+	preferencesCollection   *mongo.Collection
+}
+
+func NewNotificationRepository(db *mongo.Database) Domain.NotificationRepository {
+	return &NotificationRepository{
+		notificationsCollection: db.Collection("notifications"),
+		preferencesCollection:   db.Collection("notification_preferences"),
+	}
+}
+
+func (r *NotificationRepository) Create(notification *Domain.Notification) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	notification.CreatedAt = time.Now()
+
+	result, err := r.notificationsCollection.InsertOne(ctx, notification)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+
+	notification.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *NotificationRepository) Find(userID string, filters Domain.NotificationFilters) ([]Domain.Notification, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	query := bson.M{"user_id": objUserID}
+
+	if filters.BusinessID != nil {
+		objBusinessID, err := primitive.ObjectIDFromHex(*filters.BusinessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		query["business_id"] = objBusinessID
+	}
+
+	if filters.Event != nil {
+		query["event"] = *filters.Event
+	}
+
+	if filters.Unread {
+		query["read_at"] = bson.M{"$exists": false}
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.notificationsCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find notifications: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var notifications []Domain.Notification
+	if err := cursor.All(ctx, &notifications); err != nil {
+		return nil, fmt.Errorf("failed to decode notifications: %w", err)
+	}
+
+	return notifications, nil
+}
+
+func (r *NotificationRepository) MarkRead(userID string, ids []primitive.ObjectID, businessID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	filter := bson.M{"user_id": objUserID, "read_at": bson.M{"$exists": false}}
+	if ids != nil {
+		filter["_id"] = bson.M{"$in": ids}
+	}
+	if businessID != "" {
+		objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+		if err != nil {
+			return 0, fmt.Errorf("invalid business ID: %w", err)
+		}
+		filter["business_id"] = objBusinessID
+	}
+
+	result, err := r.notificationsCollection.UpdateMany(ctx, filter, bson.M{"$set": bson.M{"read_at": time.Now()}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+	return result.ModifiedCount, nil
+}
+
+func (r *NotificationRepository) CountUnread(userID string) (map[string]int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: bson.M{"user_id": objUserID, "read_at": bson.M{"$exists": false}}}},
+		{{Key: "$group", Value: bson.M{"_id": "$business_id", "count": bson.M{"$sum": 1}}}},
+	}
+
+	cursor, err := r.notificationsCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var results []struct {
+		BusinessID *primitive.ObjectID `bson:"_id"`
+		Count      int                 `bson:"count"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, fmt.Errorf("failed to decode unread notification counts: %w", err)
+	}
+
+	counts := make(map[string]int, len(results))
+	for _, result := range results {
+		key := ""
+		if result.BusinessID != nil {
+			key = result.BusinessID.Hex()
+		}
+		counts[key] += result.Count
+	}
+	return counts, nil
+}
+
+func (r *NotificationRepository) FindPreferences(userID string) (*Domain.NotificationPreferences, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var preferences Domain.NotificationPreferences
+	err = r.preferencesCollection.FindOne(ctx, bson.M{"user_id": objUserID}).Decode(&preferences)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find notification preferences: %w", err)
+	}
+
+	return &preferences, nil
+}
+
+func (r *NotificationRepository) SavePreferences(preferences *Domain.NotificationPreferences) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	preferences.UpdatedAt = time.Now()
+
+	_, err := r.preferencesCollection.ReplaceOne(ctx, bson.M{"user_id": preferences.UserID}, preferences, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save notification preferences: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,87 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type OnboardingRepository struct {
+	collection *mongo.Collection
+}
+
+func NewOnboardingRepository(db *mongo.Database) Domain.OnboardingRepository {
+	return &OnboardingRepository{
+		collection: db.Collection("onboardings"),
+	}
+}
+
+func (r *OnboardingRepository) Create(onboarding *Domain.Onboarding) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	onboarding.CreatedAt = time.Now()
+	onboarding.UpdatedAt = onboarding.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, onboarding)
+	if err != nil {
+		return fmt.Errorf("failed to create onboarding: %w", err)
+	}
+
+	onboarding.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *OnboardingRepository) Update(onboarding *Domain.Onboarding) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	onboarding.UpdatedAt = time.Now()
+
+	_, err := r.collection.ReplaceOne(ctx, bson.M{"_id": onboarding.ID}, onboarding)
+	if err != nil {
+		return fmt.Errorf("failed to update onboarding: %w", err)
+	}
+	return nil
+}
+
+func (r *OnboardingRepository) FindByBusiness(businessID string) (*Domain.Onboarding, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	return r.findOne(bson.M{"business_id": objBusinessID}, nil)
+}
+
+func (r *OnboardingRepository) FindUnfinished(userID string) (*Domain.Onboarding, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	filter := bson.M{"user_id": objUserID, "completed_at": bson.M{"$exists": false}}
+	return r.findOne(filter, options.FindOne().SetSort(bson.M{"created_at": -1}))
+}
+
+func (r *OnboardingRepository) findOne(filter bson.M, opts *options.FindOneOptions) (*Domain.Onboarding, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if opts == nil {
+		opts = options.FindOne()
+	}
+	var onboarding Domain.Onboarding
+	err := r.collection.FindOne(ctx, filter, opts).Decode(&onboarding)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find onboarding: %w", err)
+	}
+	return &onboarding, nil
+}
@@ -0,0 +1,30 @@
+package Repositories
+
+import (
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// applyPage sorts a list by sort, or by fallback when no sort was asked for, with _id
+// breaking ties, and narrows query to the items after the cursor
+func applyPage(query bson.M, opts *options.FindOptions, sort, fallback Domain.ListSort, after *Domain.PageCursor) {
+	if sort.Field == "" {
+		sort = fallback
+	}
+
+	direction, op := 1, "$gt"
+	if sort.Desc {
+		direction, op = -1, "$lt"
+	}
+	opts.SetSort(bson.D{{Key: sort.Field, Value: direction}, {Key: "_id", Value: direction}})
+
+	if after != nil {
+		// Kept apart from any $or already in the query, such as a search
+		and, _ := query["$and"].(bson.A)
+		query["$and"] = append(and, bson.M{"$or": bson.A{
+			bson.M{sort.Field: bson.M{op: after.Value}},
+			bson.M{sort.Field: after.Value, "_id": bson.M{op: after.ID}},
+		}})
+	}
+}
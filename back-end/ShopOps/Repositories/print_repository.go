@@ -0,0 +1,319 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PrintRepository struct {
+	bridgesCollection *mongo.Collection
+	jobsCollection    *mongo.Collection
+}
+
+func NewPrintRepository(db *mongo.Database) Domain.PrintRepository {
+	return &PrintRepository{
+		bridgesCollection: db.Collection("print_bridges"),
+		jobsCollection:    db.Collection("print_jobs"),
+	}
+}
+
+func (r *PrintRepository) CreateBridge(bridge *Domain.PrintBridge) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	bridge.CreatedAt = time.Now()
+
+	result, err := r.bridgesCollection.InsertOne(ctx, bridge)
+	if err != nil {
+		return fmt.Errorf("failed to create print bridge: %w", err)
+	}
+
+	bridge.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PrintRepository) FindBridgeByID(id string) (*Domain.PrintBridge, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid print bridge ID: %w", err)
+	}
+	return r.findBridge(bson.M{"_id": objID})
+}
+
+func (r *PrintRepository) FindBridgeByTokenHash(hash string) (*Domain.PrintBridge, error) {
+	return r.findBridge(bson.M{"token_hash": hash})
+}
+
+func (r *PrintRepository) findBridge(query bson.M) (*Domain.PrintBridge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var bridge Domain.PrintBridge
+	err := r.bridgesCollection.FindOne(ctx, query).Decode(&bridge)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find print bridge: %w", err)
+	}
+
+	return &bridge, nil
+}
+
+func (r *PrintRepository) FindBridgesByBusiness(businessID string) ([]Domain.PrintBridge, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.bridgesCollection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find print bridges: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var bridges []Domain.PrintBridge
+	if err := cursor.All(ctx, &bridges); err != nil {
+		return nil, fmt.Errorf("failed to decode print bridges: %w", err)
+	}
+
+	return bridges, nil
+}
+
+func (r *PrintRepository) SetBridgeToken(id primitive.ObjectID, hash string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.bridgesCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"token_hash": hash}})
+	if err != nil {
+		return fmt.Errorf("failed to set print bridge token: %w", err)
+	}
+	return nil
+}
+
+func (r *PrintRepository) DeleteBridge(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid print bridge ID: %w", err)
+	}
+
+	if _, err := r.bridgesCollection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete print bridge: %w", err)
+	}
+
+	// Jobs meant for this bridge alone would otherwise wait out their expiry
+	_, err = r.jobsCollection.UpdateMany(ctx,
+		bson.M{"bridge_id": objID, "status": bson.M{"$in": []Domain.PrintJobStatus{Domain.PrintJobQueued, Domain.PrintJobClaimed}}},
+		bson.M{
+			"$set":   bson.M{"status": Domain.PrintJobFailed, "error": "print bridge removed"},
+			"$unset": bson.M{"lease_until": ""},
+		})
+	if err != nil {
+		return fmt.Errorf("failed to fail print bridge jobs: %w", err)
+	}
+	return nil
+}
+
+func (r *PrintRepository) CreateJob(job *Domain.PrintJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job.CreatedAt = time.Now()
+	if job.ExpiresAt.IsZero() {
+		job.ExpiresAt = job.CreatedAt.Add(Domain.PrintJobTTL)
+	}
+
+	result, err := r.jobsCollection.InsertOne(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to create print job: %w", err)
+	}
+
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PrintRepository) FindJobByID(id string) (*Domain.PrintJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid print job ID: %w", err)
+	}
+
+	var job Domain.PrintJob
+	err = r.jobsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find print job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *PrintRepository) FindJobs(businessID string, filters Domain.PrintJobFilters) ([]Domain.PrintJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.BridgeID != nil {
+		objBridgeID, err := primitive.ObjectIDFromHex(*filters.BridgeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid print bridge ID: %w", err)
+		}
+		query["claimed_by"] = objBridgeID
+	}
+
+	// The list is for following jobs along; the bytes stay with the bridges
+	opts := options.Find().
+		SetSort(bson.M{"created_at": -1}).
+		SetProjection(bson.M{"payload": 0})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.jobsCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find print jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []Domain.PrintJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode print jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *PrintRepository) ClaimJob(bridge *Domain.PrintBridge, now time.Time) (*Domain.PrintJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"business_id": bridge.BusinessID,
+		"expires_at":  bson.M{"$gt": now},
+		"attempts":    bson.M{"$lt": Domain.PrintJobMaxAttempts},
+		"$and": []bson.M{
+			{"$or": []bson.M{
+				{"bridge_id": nil},
+				{"bridge_id": bridge.ID},
+			}},
+			{"$or": []bson.M{
+				{"status": Domain.PrintJobQueued},
+				{"status": Domain.PrintJobClaimed, "lease_until": bson.M{"$lt": now}},
+			}},
+		},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":      Domain.PrintJobClaimed,
+			"claimed_by":  bridge.ID,
+			"lease_until": now.Add(Domain.PrintJobLease),
+		},
+		"$inc": bson.M{"attempts": 1},
+	}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"created_at": 1}).
+		SetReturnDocument(options.After)
+
+	var job Domain.PrintJob
+	err := r.jobsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim print job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *PrintRepository) AckJob(id string, bridgeID primitive.ObjectID, status Domain.PrintJobStatus, errMsg string, at time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid print job ID: %w", err)
+	}
+
+	set := bson.M{"status": status, "error": errMsg}
+	unset := bson.M{"lease_until": ""}
+	switch status {
+	case Domain.PrintJobPrinted:
+		set["printed_at"] = at
+	case Domain.PrintJobQueued:
+		// Back in the queue for any bridge allowed to print it
+		unset["claimed_by"] = ""
+	}
+
+	filter := bson.M{"_id": objID, "claimed_by": bridgeID, "status": Domain.PrintJobClaimed}
+	result, err := r.jobsCollection.UpdateOne(ctx, filter, bson.M{"$set": set, "$unset": unset})
+	if err != nil {
+		return false, fmt.Errorf("failed to update print job: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *PrintRepository) FailExpired(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"$or": []bson.M{
+			{"status": Domain.PrintJobQueued, "expires_at": bson.M{"$lte": now}},
+			{"status": Domain.PrintJobQueued, "attempts": bson.M{"$gte": Domain.PrintJobMaxAttempts}},
+			{"status": Domain.PrintJobClaimed, "lease_until": bson.M{"$lt": now}, "$or": []bson.M{
+				{"expires_at": bson.M{"$lte": now}},
+				{"attempts": bson.M{"$gte": Domain.PrintJobMaxAttempts}},
+			}},
+		},
+	}
+	update := bson.M{
+		"$set":   bson.M{"status": Domain.PrintJobFailed, "error": "not printed in time"},
+		"$unset": bson.M{"lease_until": ""},
+	}
+
+	result, err := r.jobsCollection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire print jobs: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
@@ -0,0 +1,234 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ProductionRepository struct {
+	recipes *mongo.Collection
+	orders  *mongo.Collection
+}
+
+func NewProductionRepository(db *mongo.Database) Domain.ProductionRepository {
+	return &ProductionRepository{
+		recipes: db.Collection("recipes"),
+		orders:  db.Collection("production_orders"),
+	}
+}
+
+func (r *ProductionRepository) SaveRecipe(recipe *Domain.Recipe) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	update := bson.M{
+		"$set": bson.M{
+			"name":       recipe.Name,
+			"yield":      recipe.Yield,
+			"components": recipe.Components,
+			"notes":      recipe.Notes,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"created_by": recipe.CreatedBy,
+			"created_at": now,
+		},
+	}
+
+	filter := bson.M{"business_id": recipe.BusinessID, "product_id": recipe.ProductID}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.recipes.FindOneAndUpdate(ctx, filter, update, opts).Decode(recipe); err != nil {
+		return fmt.Errorf("failed to save recipe: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProductionRepository) FindRecipe(businessID, productID string) (*Domain.Recipe, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	objProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	var recipe Domain.Recipe
+	err = r.recipes.FindOne(ctx, bson.M{"business_id": objBusinessID, "product_id": objProductID}).Decode(&recipe)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find recipe: %w", err)
+	}
+
+	return &recipe, nil
+}
+
+func (r *ProductionRepository) FindRecipes(businessID string) ([]Domain.Recipe, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"name": 1})
+	cursor, err := r.recipes.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recipes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var recipes []Domain.Recipe
+	if err := cursor.All(ctx, &recipes); err != nil {
+		return nil, fmt.Errorf("failed to decode recipes: %w", err)
+	}
+
+	return recipes, nil
+}
+
+func (r *ProductionRepository) DeleteRecipe(businessID, productID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+	objProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	result, err := r.recipes.DeleteOne(ctx, bson.M{"business_id": objBusinessID, "product_id": objProductID})
+	if err != nil {
+		return fmt.Errorf("failed to delete recipe: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return Domain.NotFoundError("recipe not found")
+	}
+
+	return nil
+}
+
+func (r *ProductionRepository) CreateOrder(order *Domain.ProductionOrder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if order.ID.IsZero() {
+		order.ID = primitive.NewObjectID()
+	}
+	order.CreatedAt = time.Now()
+
+	if _, err := r.orders.InsertOne(ctx, order); err != nil {
+		return fmt.Errorf("failed to create production order: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ProductionRepository) FindOrderByID(id string) (*Domain.ProductionOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid production order ID: %w", err)
+	}
+
+	var order Domain.ProductionOrder
+	err = r.orders.FindOne(ctx, bson.M{"_id": objID}).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find production order: %w", err)
+	}
+
+	return &order, nil
+}
+
+func (r *ProductionRepository) FindOrders(businessID string, filters Domain.ProductionOrderFilters) ([]Domain.ProductionOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.ProductID != nil {
+		objProductID, err := primitive.ObjectIDFromHex(*filters.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %w", err)
+		}
+		query["product_id"] = objProductID
+	}
+
+	if filters.StartDate != nil || filters.EndDate != nil {
+		createdAt := bson.M{}
+		if filters.StartDate != nil {
+			createdAt["$gte"] = *filters.StartDate
+		}
+		if filters.EndDate != nil {
+			createdAt["$lt"] = *filters.EndDate
+		}
+		query["created_at"] = createdAt
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.orders.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find production orders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var orders []Domain.ProductionOrder
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, fmt.Errorf("failed to decode production orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (r *ProductionRepository) CountOrders(businessID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.orders.CountDocuments(ctx, bson.M{"business_id": objBusinessID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count production orders: %w", err)
+	}
+
+	return count, nil
+}
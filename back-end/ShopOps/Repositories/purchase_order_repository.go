@@ -0,0 +1,140 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PurchaseOrderRepository struct {
+	collection *mongo.Collection
+}
+
+func NewPurchaseOrderRepository(db *mongo.Database) Domain.PurchaseOrderRepository {
+	return &PurchaseOrderRepository{
+		collection: db.Collection("purchase_orders"),
+	}
+}
+
+func (r *PurchaseOrderRepository) Create(order *Domain.PurchaseOrder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, order)
+	if err != nil {
+		return fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	order.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PurchaseOrderRepository) FindInBusiness(ctx context.Context, id string) (*Domain.PurchaseOrder, error) {
+	var order Domain.PurchaseOrder
+	found, err := findByIDInBusiness(ctx, r.collection, id, &order)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &order, nil
+}
+
+func (r *PurchaseOrderRepository) FindByBusinessID(businessID string, filters Domain.PurchaseOrderFilters) ([]Domain.PurchaseOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.SupplierID != nil {
+		objSupplierID, err := primitive.ObjectIDFromHex(*filters.SupplierID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid supplier ID: %w", err)
+		}
+		query["supplier_id"] = objSupplierID
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find purchase orders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var orders []Domain.PurchaseOrder
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, fmt.Errorf("failed to decode purchase orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (r *PurchaseOrderRepository) UpdateStatus(id string, status Domain.PurchaseOrderStatus, receivedAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid purchase order ID: %w", err)
+	}
+
+	set := bson.M{
+		"status":     status,
+		"updated_at": time.Now(),
+	}
+	if receivedAt != nil {
+		set["received_at"] = *receivedAt
+	}
+	if status == Domain.PurchaseOrderStatusOrdered {
+		set["ordered_at"] = set["updated_at"]
+	}
+
+	_, err = r.collection.UpdateByID(ctx, objID, bson.M{"$set": set})
+	if err != nil {
+		return fmt.Errorf("failed to update purchase order status: %w", err)
+	}
+
+	return nil
+}
+
+func (r *PurchaseOrderRepository) CountByBusinessID(businessID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"business_id": objBusinessID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count purchase orders: %w", err)
+	}
+
+	return count, nil
+}
@@ -0,0 +1,378 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type PushRepository struct {
+	devicesCollection       *mongo.Collection
+	subscriptionsCollection *mongo.Collection
+	messagesCollection      *mongo.Collection
+}
+
+func NewPushRepository(db *mongo.Database) Domain.PushRepository {
+	return &PushRepository{
+		devicesCollection:       db.Collection("push_devices"),
+		subscriptionsCollection: db.Collection("push_subscriptions"),
+		messagesCollection:      db.Collection("push_messages"),
+	}
+}
+
+func (r *PushRepository) SaveDevice(device *Domain.PushDevice) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	device.LastSeenAt = now
+
+	filter := bson.M{"token": device.Token}
+	update := bson.M{
+		"$set": bson.M{
+			"user_id":      device.UserID,
+			"platform":     device.Platform,
+			"name":         device.Name,
+			"app_version":  device.AppVersion,
+			"last_seen_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"created_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	if err := r.devicesCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(device); err != nil {
+		return fmt.Errorf("failed to save push device: %w", err)
+	}
+
+	// A phone that changed hands stops getting the previous user's shops
+	_, err := r.subscriptionsCollection.DeleteMany(ctx, bson.M{"device_id": device.ID, "user_id": bson.M{"$ne": device.UserID}})
+	if err != nil {
+		return fmt.Errorf("failed to clear push subscriptions: %w", err)
+	}
+	return nil
+}
+
+func (r *PushRepository) FindDeviceByID(id string) (*Domain.PushDevice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push device ID: %w", err)
+	}
+
+	var device Domain.PushDevice
+	err = r.devicesCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&device)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find push device: %w", err)
+	}
+
+	return &device, nil
+}
+
+func (r *PushRepository) FindDevicesByUser(userID string) ([]Domain.PushDevice, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	return r.findDevices(bson.M{"user_id": objUserID})
+}
+
+func (r *PushRepository) FindDevicesByIDs(ids []primitive.ObjectID) ([]Domain.PushDevice, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return r.findDevices(bson.M{"_id": bson.M{"$in": ids}})
+}
+
+func (r *PushRepository) FindDevicesAfter(afterID primitive.ObjectID, limit int) ([]Domain.PushDevice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{}
+	if !afterID.IsZero() {
+		query["_id"] = bson.M{"$gt": afterID}
+	}
+
+	opts := options.Find().SetSort(bson.M{"_id": 1}).SetLimit(int64(limit))
+	cursor, err := r.devicesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push devices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var devices []Domain.PushDevice
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, fmt.Errorf("failed to decode push devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+func (r *PushRepository) findDevices(query bson.M) ([]Domain.PushDevice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.devicesCollection.Find(ctx, query, options.Find().SetSort(bson.M{"last_seen_at": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push devices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var devices []Domain.PushDevice
+	if err := cursor.All(ctx, &devices); err != nil {
+		return nil, fmt.Errorf("failed to decode push devices: %w", err)
+	}
+
+	return devices, nil
+}
+
+func (r *PushRepository) DeleteDevice(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid push device ID: %w", err)
+	}
+
+	if _, err := r.devicesCollection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete push device: %w", err)
+	}
+	if _, err := r.subscriptionsCollection.DeleteMany(ctx, bson.M{"device_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete push subscriptions: %w", err)
+	}
+	return nil
+}
+
+func (r *PushRepository) FindSubscription(deviceID, businessID string) (*Domain.PushSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objDeviceID, err := primitive.ObjectIDFromHex(deviceID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push device ID: %w", err)
+	}
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var subscription Domain.PushSubscription
+	err = r.subscriptionsCollection.FindOne(ctx, bson.M{"device_id": objDeviceID, "business_id": objBusinessID}).Decode(&subscription)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find push subscription: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+func (r *PushRepository) SaveSubscription(subscription *Domain.PushSubscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription.UpdatedAt = time.Now()
+
+	filter := bson.M{"device_id": subscription.DeviceID, "business_id": subscription.BusinessID}
+	update := bson.M{
+		"$set": bson.M{
+			"user_id":      subscription.UserID,
+			"topics":       subscription.Topics,
+			"big_sale":     subscription.BigSale,
+			"summary_hour": subscription.SummaryHour,
+			"updated_at":   subscription.UpdatedAt,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	if err := r.subscriptionsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(subscription); err != nil {
+		return fmt.Errorf("failed to save push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PushRepository) FindSubscribed(businessID string, topic Domain.PushTopic) ([]Domain.PushSubscription, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	return r.findSubscriptions(bson.M{"business_id": objBusinessID, "topics": topic})
+}
+
+func (r *PushRepository) FindSummarySubscriptions() ([]Domain.PushSubscription, error) {
+	return r.findSubscriptions(bson.M{"topics": Domain.PushTopicDailySummary})
+}
+
+func (r *PushRepository) findSubscriptions(query bson.M) ([]Domain.PushSubscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.subscriptionsCollection.Find(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []Domain.PushSubscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to decode push subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *PushRepository) SetLastSummaryDate(id primitive.ObjectID, date string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.subscriptionsCollection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"last_summary_date": date}})
+	if err != nil {
+		return fmt.Errorf("failed to update push subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *PushRepository) LogMessage(message *Domain.PushMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	message.CreatedAt = time.Now()
+
+	result, err := r.messagesCollection.InsertOne(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to log push message: %w", err)
+	}
+
+	message.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *PushRepository) FindMessageByID(id string) (*Domain.PushMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid push message ID: %w", err)
+	}
+
+	var message Domain.PushMessage
+	err = r.messagesCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&message)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find push message: %w", err)
+	}
+
+	return &message, nil
+}
+
+func (r *PushRepository) FindMessages(businessID string, filters Domain.PushMessageFilters) ([]Domain.PushMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Topic != nil {
+		query["topic"] = *filters.Topic
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.messagesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find push messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var messages []Domain.PushMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode push messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *PushRepository) UpdateMessage(message *Domain.PushMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":              message.Status,
+			"provider_message_id": message.ProviderMessageID,
+			"error":               message.Error,
+			"sent_at":             message.SentAt,
+		},
+	}
+
+	if _, err := r.messagesCollection.UpdateByID(ctx, message.ID, update); err != nil {
+		return fmt.Errorf("failed to update push message: %w", err)
+	}
+	return nil
+}
+
+func (r *PushRepository) MarkReceipt(id string, status Domain.PushStatus) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid push message ID: %w", err)
+	}
+
+	// Opened implies delivered; a late delivered receipt does not undo opened
+	now := time.Now()
+	set := bson.M{"delivered_at": now}
+	filter := bson.M{"_id": objID, "delivered_at": nil}
+	if status == Domain.PushStatusOpened {
+		set = bson.M{"status": Domain.PushStatusOpened, "opened_at": now}
+		filter = bson.M{"_id": objID, "opened_at": nil}
+	} else {
+		set["status"] = Domain.PushStatusDelivered
+		filter["status"] = bson.M{"$ne": Domain.PushStatusOpened}
+	}
+
+	if _, err := r.messagesCollection.UpdateOne(ctx, filter, bson.M{"$set": set}); err != nil {
+		return fmt.Errorf("failed to record push receipt: %w", err)
+	}
+	if status == Domain.PushStatusOpened {
+		_, err := r.messagesCollection.UpdateOne(ctx, bson.M{"_id": objID, "delivered_at": nil}, bson.M{"$set": bson.M{"delivered_at": now}})
+		if err != nil {
+			return fmt.Errorf("failed to record push receipt: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,161 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReceiptTemplateRepository struct {
+	collection *mongo.Collection
+}
+
+func NewReceiptTemplateRepository(db *mongo.Database) Domain.ReceiptTemplateRepository {
+	return &ReceiptTemplateRepository{
+		collection: db.Collection("receipt_templates"),
+	}
+}
+
+func (r *ReceiptTemplateRepository) Create(template *Domain.ReceiptTemplate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	template.CreatedAt = time.Now()
+	template.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, template)
+	if err != nil {
+		return fmt.Errorf("failed to create receipt template: %w", err)
+	}
+
+	template.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ReceiptTemplateRepository) FindInBusiness(ctx context.Context, id string) (*Domain.ReceiptTemplate, error) {
+	var template Domain.ReceiptTemplate
+	found, err := findByIDInBusiness(ctx, r.collection, id, &template)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &template, nil
+}
+
+func (r *ReceiptTemplateRepository) FindByBusinessID(businessID string) ([]Domain.ReceiptTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "is_default", Value: -1}, {Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find receipt templates: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var templates []Domain.ReceiptTemplate
+	if err := cursor.All(ctx, &templates); err != nil {
+		return nil, fmt.Errorf("failed to decode receipt templates: %w", err)
+	}
+
+	return templates, nil
+}
+
+func (r *ReceiptTemplateRepository) FindDefault(businessID string) (*Domain.ReceiptTemplate, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var template Domain.ReceiptTemplate
+	err = r.collection.FindOne(ctx, bson.M{
+		"business_id": objBusinessID,
+		"is_default":  true,
+	}).Decode(&template)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find default receipt template: %w", err)
+	}
+
+	return &template, nil
+}
+
+func (r *ReceiptTemplateRepository) Update(template *Domain.ReceiptTemplate) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	template.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":              template.Name,
+			"is_default":        template.IsDefault,
+			"header":            template.Header,
+			"footer":            template.Footer,
+			"logo_url":          template.LogoURL,
+			"print_stored_logo": template.PrintStoredLogo,
+			"paper_width":       template.PaperWidth,
+			"fields":            template.Fields,
+			"updated_at":        template.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, template.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update receipt template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReceiptTemplateRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid receipt template ID: %w", err)
+	}
+
+	if _, err := r.collection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete receipt template: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReceiptTemplateRepository) ClearDefault(businessID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	_, err = r.collection.UpdateMany(ctx,
+		bson.M{"business_id": objBusinessID, "is_default": true},
+		bson.M{"$set": bson.M{"is_default": false, "updated_at": time.Now()}},
+	)
+	if err != nil {
+		return fmt.Errorf("failed to clear default receipt template: %w", err)
+	}
+
+	return nil
+}
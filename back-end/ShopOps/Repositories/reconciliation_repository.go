@@ -0,0 +1,311 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ReconciliationRepository struct {
+	statementsCollection *mongo.Collection
+	linesCollection      *mongo.Collection
+}
+
+func NewReconciliationRepository(db *mongo.Database) Domain.ReconciliationRepository {
+	return &ReconciliationRepository{
+		statementsCollection: db.Collection("bank_statements"),
+		linesCollection:      db.Collection("statement_lines"),
+	}
+}
+
+func (r *ReconciliationRepository) CreateStatement(statement *Domain.BankStatement) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	statement.CreatedAt = time.Now()
+
+	result, err := r.statementsCollection.InsertOne(ctx, statement)
+	if err != nil {
+		return fmt.Errorf("failed to create bank statement: %w", err)
+	}
+
+	statement.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ReconciliationRepository) FindStatementByID(id string) (*Domain.BankStatement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid statement ID: %w", err)
+	}
+
+	var statement Domain.BankStatement
+	err = r.statementsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&statement)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find bank statement: %w", err)
+	}
+
+	return &statement, nil
+}
+
+func (r *ReconciliationRepository) FindStatements(businessID string) ([]Domain.BankStatement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.statementsCollection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find bank statements: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var statements []Domain.BankStatement
+	if err := cursor.All(ctx, &statements); err != nil {
+		return nil, fmt.Errorf("failed to decode bank statements: %w", err)
+	}
+
+	return statements, nil
+}
+
+func (r *ReconciliationRepository) UpdateStatement(statement *Domain.BankStatement) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.statementsCollection.UpdateOne(ctx, bson.M{"_id": statement.ID}, bson.M{
+		"$set": bson.M{
+			"period_start": statement.PeriodStart,
+			"period_end":   statement.PeriodEnd,
+			"line_count":   statement.LineCount,
+			"duplicates":   statement.Duplicates,
+			"matched":      statement.Matched,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to update bank statement: %w", err)
+	}
+
+	return nil
+}
+
+func (r *ReconciliationRepository) DeleteStatement(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid statement ID: %w", err)
+	}
+
+	if _, err := r.linesCollection.DeleteMany(ctx, bson.M{"statement_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete statement lines: %w", err)
+	}
+
+	result, err := r.statementsCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete bank statement: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return Domain.NotFoundError("statement not found")
+	}
+
+	return nil
+}
+
+func (r *ReconciliationRepository) CreateLines(lines []Domain.StatementLine) ([]Domain.StatementLine, error) {
+	if len(lines) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	docs := make([]interface{}, len(lines))
+	for i := range lines {
+		lines[i].ID = primitive.NewObjectID()
+		lines[i].CreatedAt = now
+		lines[i].UpdatedAt = now
+		docs[i] = lines[i]
+	}
+
+	// Unordered, so lines already imported are refused by the fingerprint index
+	// one by one and the rest still go in
+	_, err := r.linesCollection.InsertMany(ctx, docs, options.InsertMany().SetOrdered(false))
+	if err == nil {
+		return lines, nil
+	}
+
+	var bulkErr mongo.BulkWriteException
+	if !errors.As(err, &bulkErr) || bulkErr.WriteConcernError != nil {
+		return nil, fmt.Errorf("failed to create statement lines: %w", err)
+	}
+
+	duplicate := make(map[int]bool, len(bulkErr.WriteErrors))
+	for _, writeErr := range bulkErr.WriteErrors {
+		if !mongo.IsDuplicateKeyError(writeErr) {
+			return nil, fmt.Errorf("failed to create statement lines: %w", err)
+		}
+		duplicate[writeErr.Index] = true
+	}
+
+	created := make([]Domain.StatementLine, 0, len(lines)-len(duplicate))
+	for i := range lines {
+		if !duplicate[i] {
+			created = append(created, lines[i])
+		}
+	}
+	return created, nil
+}
+
+func (r *ReconciliationRepository) FindLineByID(id string) (*Domain.StatementLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid statement line ID: %w", err)
+	}
+
+	var line Domain.StatementLine
+	err = r.linesCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&line)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find statement line: %w", err)
+	}
+
+	return &line, nil
+}
+
+func (r *ReconciliationRepository) FindLines(businessID string, filters Domain.StatementLineFilters) ([]Domain.StatementLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.StatementID != nil {
+		objStatementID, err := primitive.ObjectIDFromHex(*filters.StatementID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid statement ID: %w", err)
+		}
+		query["statement_id"] = objStatementID
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.StartDate != nil || filters.EndDate != nil {
+		dateQuery := bson.M{}
+		if filters.StartDate != nil {
+			dateQuery["$gte"] = *filters.StartDate
+		}
+		if filters.EndDate != nil {
+			dateQuery["$lte"] = *filters.EndDate
+		}
+		query["date"] = dateQuery
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "_id", Value: 1}})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.linesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find statement lines: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var lines []Domain.StatementLine
+	if err := cursor.All(ctx, &lines); err != nil {
+		return nil, fmt.Errorf("failed to decode statement lines: %w", err)
+	}
+
+	return lines, nil
+}
+
+func (r *ReconciliationRepository) FindMatchKeys(businessID string) (map[string]bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	values, err := r.linesCollection.Distinct(ctx, "match.key", bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.StatementLineMatched,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find matched payments: %w", err)
+	}
+
+	keys := make(map[string]bool, len(values))
+	for _, v := range values {
+		if key, ok := v.(string); ok {
+			keys[key] = true
+		}
+	}
+
+	return keys, nil
+}
+
+func (r *ReconciliationRepository) SetMatch(line *Domain.StatementLine) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	line.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     line.Status,
+			"note":       line.Note,
+			"updated_at": line.UpdatedAt,
+		},
+	}
+	if line.Match != nil {
+		update["$set"].(bson.M)["match"] = line.Match
+	} else {
+		update["$unset"] = bson.M{"match": ""}
+	}
+
+	_, err := r.linesCollection.UpdateOne(ctx, bson.M{"_id": line.ID}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to update statement line: %w", err)
+	}
+
+	return true, nil
+}
@@ -0,0 +1,144 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RecurringExpenseRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRecurringExpenseRepository(db *mongo.Database) Domain.RecurringExpenseRepository {
+	return &RecurringExpenseRepository{
+		collection: db.Collection("recurring_expenses"),
+	}
+}
+
+func (r *RecurringExpenseRepository) Create(recurring *Domain.RecurringExpense) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	recurring.Status = Domain.RecurringExpenseStatusActive
+	recurring.CreatedAt = time.Now()
+	recurring.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, recurring)
+	if err != nil {
+		return fmt.Errorf("failed to create recurring expense: %w", err)
+	}
+
+	recurring.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *RecurringExpenseRepository) FindInBusiness(ctx context.Context, id string) (*Domain.RecurringExpense, error) {
+	var recurring Domain.RecurringExpense
+	found, err := findByIDInBusiness(ctx, r.collection, id, &recurring)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &recurring, nil
+}
+
+func (r *RecurringExpenseRepository) FindByBusinessID(businessID string) ([]Domain.RecurringExpense, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"next_run_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring expenses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var recurring []Domain.RecurringExpense
+	if err := cursor.All(ctx, &recurring); err != nil {
+		return nil, fmt.Errorf("failed to decode recurring expenses: %w", err)
+	}
+
+	return recurring, nil
+}
+
+func (r *RecurringExpenseRepository) FindDue(asOf time.Time, limit int) ([]Domain.RecurringExpense, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"status":      Domain.RecurringExpenseStatusActive,
+		"next_run_at": bson.M{"$lte": asOf},
+	}
+
+	opts := options.Find().SetSort(bson.M{"next_run_at": 1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find due recurring expenses: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var recurring []Domain.RecurringExpense
+	if err := cursor.All(ctx, &recurring); err != nil {
+		return nil, fmt.Errorf("failed to decode recurring expenses: %w", err)
+	}
+
+	return recurring, nil
+}
+
+func (r *RecurringExpenseRepository) Update(recurring *Domain.RecurringExpense) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	recurring.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"category":    recurring.Category,
+			"amount":      recurring.Amount,
+			"description": recurring.Description,
+			"end_date":    recurring.EndDate,
+			"next_run_at": recurring.NextRunAt,
+			"occurrences": recurring.Occurrences,
+			"status":      recurring.Status,
+			"updated_at":  recurring.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, recurring.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update recurring expense: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RecurringExpenseRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid recurring expense ID: %w", err)
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete recurring expense: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,190 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RepairJobRepository struct {
+	collection *mongo.Collection
+}
+
+func NewRepairJobRepository(db *mongo.Database) Domain.RepairJobRepository {
+	return &RepairJobRepository{
+		collection: db.Collection("repair_jobs"),
+	}
+}
+
+func (r *RepairJobRepository) Create(job *Domain.RepairJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job.CreatedAt = time.Now()
+	job.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, job)
+	if err != nil {
+		return fmt.Errorf("failed to create repair job: %w", err)
+	}
+
+	job.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *RepairJobRepository) FindInBusiness(ctx context.Context, id string) (*Domain.RepairJob, error) {
+	var job Domain.RepairJob
+	found, err := findByIDInBusiness(ctx, r.collection, id, &job)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &job, nil
+}
+
+func (r *RepairJobRepository) FindBySale(saleID primitive.ObjectID) (*Domain.RepairJob, error) {
+	return r.findOne(bson.M{"$or": bson.A{
+		bson.M{"parts.sale_id": saleID},
+		bson.M{"labor.sale_id": saleID},
+	}})
+}
+
+func (r *RepairJobRepository) findOne(query bson.M) (*Domain.RepairJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var job Domain.RepairJob
+	err := r.collection.FindOne(ctx, query).Decode(&job)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find repair job: %w", err)
+	}
+
+	return &job, nil
+}
+
+func (r *RepairJobRepository) FindByBusiness(businessID string, filters Domain.RepairJobFilters) ([]Domain.RepairJob, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	} else if filters.Open != nil {
+		closed := bson.A{Domain.RepairJobCollected, Domain.RepairJobCancelled}
+		if *filters.Open {
+			query["status"] = bson.M{"$nin": closed}
+		} else {
+			query["status"] = bson.M{"$in": closed}
+		}
+	}
+
+	if filters.TechnicianID != nil {
+		objTechnicianID, err := primitive.ObjectIDFromHex(*filters.TechnicianID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid technician ID: %w", err)
+		}
+		query["technician_id"] = objTechnicianID
+	}
+
+	if filters.CustomerPhone != nil {
+		query["customer_phone"] = *filters.CustomerPhone
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find repair jobs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var jobs []Domain.RepairJob
+	if err := cursor.All(ctx, &jobs); err != nil {
+		return nil, fmt.Errorf("failed to decode repair jobs: %w", err)
+	}
+
+	return jobs, nil
+}
+
+func (r *RepairJobRepository) CountByBusinessID(businessID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"business_id": objBusinessID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count repair jobs: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *RepairJobRepository) Update(job *Domain.RepairJob) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	job.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"customer_name":  job.CustomerName,
+			"customer_phone": job.CustomerPhone,
+			"device":         job.Device,
+			"problem":        job.Problem,
+			"diagnosis":      job.Diagnosis,
+			"estimate":       job.Estimate,
+			"status":         job.Status,
+			"technician_id":  job.TechnicianID,
+			"parts":          job.Parts,
+			"labor":          job.Labor,
+			"history":        job.History,
+			"completed_at":   job.CompletedAt,
+			"collected_at":   job.CollectedAt,
+			"updated_at":     job.UpdatedAt,
+		},
+	}
+
+	if err := updateVersioned(ctx, r.collection, job.ID, job.Version, update, "repair job"); err != nil {
+		return err
+	}
+
+	job.Version++
+	return nil
+}
+
+func (r *RepairJobRepository) MarkNotified(id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.collection.UpdateByID(ctx, id, bson.M{"$set": bson.M{"notified_at": at}}); err != nil {
+		return fmt.Errorf("failed to mark repair job notified: %w", err)
+	}
+
+	return nil
+}
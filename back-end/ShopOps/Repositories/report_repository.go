@@ -11,12 +11,14 @@ import (
 	"go.mongodb.org/mongo-driver/mongo"
 )
 
+// ReportRepository only reads, so every query goes to the database reads returns,
+// normally a read replica
 type ReportRepository struct {
-	db *mongo.Database
+	reads func() *mongo.Database
 }
 
-func NewReportRepository(db *mongo.Database) Domain.ReportRepository {
-	return &ReportRepository{db: db}
+func NewReportRepository(reads func() *mongo.Database) Domain.ReportRepository {
+	return &ReportRepository{reads: reads}
 }
 
 func (r *ReportRepository) GenerateSalesReport(businessID string, startDate, endDate time.Time) (*Domain.SalesReport, error) {
@@ -28,7 +30,7 @@ func (r *ReportRepository) GenerateSalesReport(businessID string, startDate, end
 		return nil, fmt.Errorf("invalid business ID: %w", err)
 	}
 
-	salesCollection := r.db.Collection("sales")
+	salesCollection := r.reads().Collection("sales")
 
 	// Get total sales data
 	totalPipeline := []bson.M{
@@ -52,16 +54,16 @@ func (r *ReportRepository) GenerateSalesReport(businessID string, startDate, end
 		},
 	}
 
-	cursor, err := salesCollection.Aggregate(ctx, totalPipeline)
+	cursor, err := salesCollection.Aggregate(ctx, withArchive("sales", totalPipeline))
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate sales: %w", err)
 	}
 	defer cursor.Close(ctx)
 
 	var totalResult struct {
-		TotalSales        float64 `bson:"total_sales"`
-		TotalAmount       float64 `bson:"total_amount"`
-		TotalTransactions int     `bson:"total_transactions"`
+		TotalSales        float64      `bson:"total_sales"`
+		TotalAmount       Domain.Money `bson:"total_amount"`
+		TotalTransactions int          `bson:"total_transactions"`
 	}
 
 	if cursor.Next(ctx) {
@@ -98,7 +100,7 @@ func (r *ReportRepository) GenerateSalesReport(businessID string, startDate, end
 		},
 	}
 
-	cursor, err = salesCollection.Aggregate(ctx, productsPipeline)
+	cursor, err = salesCollection.Aggregate(ctx, withArchive("sales", productsPipeline))
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate top products: %w", err)
 	}
@@ -109,7 +111,7 @@ func (r *ReportRepository) GenerateSalesReport(businessID string, startDate, end
 		var result struct {
 			ProductID   primitive.ObjectID `bson:"_id"`
 			Quantity    float64            `bson:"quantity"`
-			TotalAmount float64            `bson:"total_amount"`
+			TotalAmount Domain.Money       `bson:"total_amount"`
 		}
 
 		if err := cursor.Decode(&result); err != nil {
@@ -135,7 +137,7 @@ func (r *ReportRepository) GenerateSalesReport(businessID string, startDate, end
 	}
 
 	if totalResult.TotalTransactions > 0 {
-		report.AverageSale = totalResult.TotalAmount / float64(totalResult.TotalTransactions)
+		report.AverageSale = Domain.NewMoney(totalResult.TotalAmount.Float64() / float64(totalResult.TotalTransactions))
 	}
 
 	return report, nil
@@ -150,7 +152,7 @@ func (r *ReportRepository) GenerateExpensesReport(businessID string, startDate,
 		return nil, fmt.Errorf("invalid business ID: %w", err)
 	}
 
-	expensesCollection := r.db.Collection("expenses")
+	expensesCollection := r.reads().Collection("expenses")
 
 	// Get category breakdown
 	pipeline := []bson.M{
@@ -183,12 +185,12 @@ func (r *ReportRepository) GenerateExpensesReport(businessID string, startDate,
 	defer cursor.Close(ctx)
 
 	var categoryBreakdown []Domain.CategoryExpense
-	var totalAmount float64
+	var totalAmount Domain.Money
 
 	for cursor.Next(ctx) {
 		var result struct {
 			Category    Domain.ExpenseCategory `bson:"_id"`
-			TotalAmount float64                `bson:"total_amount"`
+			TotalAmount Domain.Money           `bson:"total_amount"`
 			Count       int                    `bson:"count"`
 		}
 
@@ -208,7 +210,7 @@ func (r *ReportRepository) GenerateExpensesReport(businessID string, startDate,
 	// Calculate percentages
 	for i := range categoryBreakdown {
 		if totalAmount > 0 {
-			categoryBreakdown[i].Percentage = (categoryBreakdown[i].TotalAmount / totalAmount) * 100
+			categoryBreakdown[i].Percentage = categoryBreakdown[i].TotalAmount.Float64() / totalAmount.Float64() * 100
 		}
 	}
 
@@ -237,16 +239,17 @@ func (r *ReportRepository) GenerateProfitReport(businessID string, startDate, en
 	grossProfit := salesReport.TotalAmount - expensesReport.TotalExpenses
 	profitMargin := 0.0
 	if salesReport.TotalAmount > 0 {
-		profitMargin = (grossProfit / salesReport.TotalAmount) * 100
+		profitMargin = grossProfit.Float64() / salesReport.TotalAmount.Float64() * 100
 	}
 
 	report := &Domain.ProfitReport{
-		Period:        fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
-		TotalSales:    salesReport.TotalAmount,
-		TotalExpenses: expensesReport.TotalExpenses,
-		GrossProfit:   grossProfit,
-		NetProfit:     grossProfit, // Would deduct taxes, fees, etc.
-		ProfitMargin:  profitMargin,
+		Period:           fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+		TotalSales:       salesReport.TotalAmount,
+		TotalExpenses:    expensesReport.TotalExpenses,
+		GrossProfit:      grossProfit,
+		NetProfit:        grossProfit, // Would deduct taxes, fees, etc.
+		ProfitMargin:     profitMargin,
+		ExpenseBreakdown: expensesReport.CategoryBreakdown,
 	}
 
 	return report, nil
@@ -261,7 +264,7 @@ func (r *ReportRepository) GenerateInventoryReport(businessID string) (*Domain.I
 		return nil, fmt.Errorf("invalid business ID: %w", err)
 	}
 
-	productsCollection := r.db.Collection("products")
+	productsCollection := r.reads().Collection("products")
 
 	// Get all active products
 	cursor, err := productsCollection.Find(ctx, bson.M{
@@ -281,13 +284,13 @@ func (r *ReportRepository) GenerateInventoryReport(businessID string) (*Domain.I
 
 	var totalProducts int
 	var totalStock float64
-	var totalValue float64
+	var totalValue Domain.Money
 	var lowStockItems []Domain.LowStockItem
 
 	for _, product := range products {
 		totalProducts++
 		totalStock += product.Stock
-		totalValue += product.Stock * product.CostPrice
+		totalValue += product.CostPrice.Times(product.Stock)
 
 		if product.MinStock > 0 && product.Stock < product.MinStock {
 			lowStockItems = append(lowStockItems, Domain.LowStockItem{
@@ -310,144 +313,175 @@ func (r *ReportRepository) GenerateInventoryReport(businessID string) (*Domain.I
 	return report, nil
 }
 
-func (r *ReportRepository) GetDashboardData(businessID string) (*Domain.DashboardData, error) {
-	now := time.Now()
-	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-
-	// Today's sales
-	todaySales, _ := r.getSalesTotal(businessID, today, today.Add(24*time.Hour))
-
-	// Today's expenses
-	todayExpenses, _ := r.getExpensesTotal(businessID, today, today.Add(24*time.Hour))
-
-	// Week's data (last 7 days)
-	weekStart := today.AddDate(0, 0, -7)
-	weekSales, _ := r.getSalesTotal(businessID, weekStart, today)
-	weekExpenses, _ := r.getExpensesTotal(businessID, weekStart, today)
-
-	// Month's data (last 30 days)
-	monthStart := today.AddDate(0, 0, -30)
-	monthSales, _ := r.getSalesTotal(businessID, monthStart, today)
-	monthExpenses, _ := r.getExpensesTotal(businessID, monthStart, today)
-
-	// Low stock count
-	lowStockCount, _ := r.getLowStockCount(businessID)
-
-	data := &Domain.DashboardData{
-		TodaySales:      todaySales,
-		TodayExpenses:   todayExpenses,
-		TodayProfit:     todaySales - todayExpenses,
-		WeekSales:       weekSales,
-		WeekExpenses:    weekExpenses,
-		WeekProfit:      weekSales - weekExpenses,
-		MonthSales:      monthSales,
-		MonthExpenses:   monthExpenses,
-		MonthProfit:     monthSales - monthExpenses,
-		LowStockCount:   lowStockCount,
-		PendingPayments: 0, // Would calculate from sales with pending status
-	}
-
-	return data, nil
-}
-
-func (r *ReportRepository) getSalesTotal(businessID string, startDate, endDate time.Time) (float64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+func (r *ReportRepository) GetStockActivity(businessID string, since time.Time) ([]Domain.DeadStockItem, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
 	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
 	if err != nil {
-		return 0, err
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.reads().Collection("products").Find(ctx, bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.ProductStatusActive,
+		"stock":       bson.M{"$gt": 0},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
 	}
 
-	salesCollection := r.db.Collection("sales")
+	var items []Domain.DeadStockItem
+	if err := cursor.All(ctx, &items); err != nil {
+		return nil, fmt.Errorf("failed to decode products: %w", err)
+	}
 
-	pipeline := []bson.M{
+	// One pass over the sales gives every product's last sale and recent quantity; a
+	// product last sold years ago was last sold in the archive
+	cursor, err = r.reads().Collection("sales").Aggregate(ctx, withArchive("sales", []bson.M{
 		{
 			"$match": bson.M{
 				"business_id": objBusinessID,
-				"created_at": bson.M{
-					"$gte": startDate,
-					"$lte": endDate,
-				},
-				"status": Domain.SaleStatusCompleted,
+				"status":      Domain.SaleStatusCompleted,
+				"product_id":  bson.M{"$ne": nil},
 			},
 		},
 		{
 			"$group": bson.M{
-				"_id":   nil,
-				"total": bson.M{"$sum": "$final_amount"},
+				"_id":          "$product_id",
+				"last_sold_at": bson.M{"$max": "$created_at"},
+				"sold_in_window": bson.M{"$sum": bson.M{
+					"$cond": bson.A{bson.M{"$gte": bson.A{"$created_at", since}}, "$quantity", 0},
+				}},
 			},
 		},
+	}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate product sales: %w", err)
 	}
 
-	cursor, err := salesCollection.Aggregate(ctx, pipeline)
-	if err != nil {
-		return 0, err
+	var activity []struct {
+		ProductID    primitive.ObjectID `bson:"_id"`
+		LastSoldAt   time.Time          `bson:"last_sold_at"`
+		SoldInWindow float64            `bson:"sold_in_window"`
+	}
+	if err := cursor.All(ctx, &activity); err != nil {
+		return nil, fmt.Errorf("failed to decode product sales: %w", err)
 	}
-	defer cursor.Close(ctx)
 
-	var result struct {
-		Total float64 `bson:"total"`
+	byProduct := make(map[string]int, len(activity))
+	for i, a := range activity {
+		byProduct[a.ProductID.Hex()] = i
 	}
 
-	if cursor.Next(ctx) {
-		if err := cursor.Decode(&result); err != nil {
-			return 0, err
+	for i := range items {
+		if j, ok := byProduct[items[i].ProductID]; ok {
+			lastSoldAt := activity[j].LastSoldAt
+			items[i].LastSoldAt = &lastSoldAt
+			items[i].SoldInWindow = activity[j].SoldInWindow
 		}
-		return result.Total, nil
 	}
 
-	return 0, nil
+	return items, nil
 }
 
-func (r *ReportRepository) getExpensesTotal(businessID string, startDate, endDate time.Time) (float64, error) {
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
-	defer cancel()
+func (r *ReportRepository) GetDashboardData(businessID string, today time.Time) (*Domain.DashboardData, error) {
+	weekStart := today.AddDate(0, 0, -7)   // Last 7 days
+	monthStart := today.AddDate(0, 0, -30) // Last 30 days
+	tomorrow := today.AddDate(0, 0, 1)
 
+	// Each collection is read once, with the three windows totalled side by side
+	sales, _ := r.getSalesTotals(businessID, monthStart, tomorrow, map[string]bson.M{
+		"today": {"period_start": bson.M{"$gte": today.UTC().Truncate(time.Hour), "$lt": tomorrow}},
+		"week":  {"period_start": bson.M{"$gte": weekStart.UTC().Truncate(time.Hour), "$lt": today}},
+		"month": {"period_start": bson.M{"$gte": monthStart.UTC().Truncate(time.Hour), "$lt": today}},
+	})
+	expenses, _ := r.getExpensesTotals(businessID, monthStart, tomorrow, map[string]bson.M{
+		"today": {"date": bson.M{"$gte": today, "$lte": tomorrow}},
+		"week":  {"date": bson.M{"$gte": weekStart, "$lte": today}},
+		"month": {"date": bson.M{"$gte": monthStart, "$lte": today}},
+	})
+
+	// Low stock count
+	lowStockCount, _ := r.getLowStockCount(businessID)
+
+	data := &Domain.DashboardData{
+		TodaySales:      sales["today"],
+		TodayExpenses:   expenses["today"],
+		TodayProfit:     sales["today"] - expenses["today"],
+		WeekSales:       sales["week"],
+		WeekExpenses:    expenses["week"],
+		WeekProfit:      sales["week"] - expenses["week"],
+		MonthSales:      sales["month"],
+		MonthExpenses:   expenses["month"],
+		MonthProfit:     sales["month"] - expenses["month"],
+		LowStockCount:   lowStockCount,
+		PendingPayments: 0, // Would calculate from sales with pending status
+	}
+
+	return data, nil
+}
+
+// getSalesTotals reads the hourly sales summary maintained by the aggregation job,
+// totalling each window within start and end
+func (r *ReportRepository) getSalesTotals(businessID string, start, end time.Time, windows map[string]bson.M) (map[string]Domain.Money, error) {
+	match, err := hourRangeMatch(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	return windowTotals(r.reads().Collection("sales_summary_hourly"), match, "$amount", windows)
+}
+
+func (r *ReportRepository) getExpensesTotals(businessID string, start, end time.Time, windows map[string]bson.M) (map[string]Domain.Money, error) {
 	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 
-	expensesCollection := r.db.Collection("expenses")
+	match := bson.M{
+		"business_id": objBusinessID,
+		"date":        bson.M{"$gte": start, "$lte": end},
+		"status":      Domain.ExpenseStatusActive,
+	}
+	return windowTotals(r.reads().Collection("expenses"), match, "$amount", windows)
+}
 
-	pipeline := []bson.M{
-		{
-			"$match": bson.M{
-				"business_id": objBusinessID,
-				"date": bson.M{
-					"$gte": startDate,
-					"$lte": endDate,
-				},
-				"status": Domain.ExpenseStatusActive,
-			},
-		},
-		{
-			"$group": bson.M{
-				"_id":   nil,
-				"total": bson.M{"$sum": "$amount"},
-			},
-		},
+// windowTotals sums amount over each window's documents in one aggregation, a
+// $facet per window; windows with nothing in them total 0
+func windowTotals(collection *mongo.Collection, match bson.M, amount string, windows map[string]bson.M) (map[string]Domain.Money, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	facets := bson.M{}
+	for name, window := range windows {
+		facets[name] = []bson.M{
+			{"$match": window},
+			{"$group": bson.M{"_id": nil, "total": bson.M{"$sum": amount}}},
+		}
 	}
 
-	cursor, err := expensesCollection.Aggregate(ctx, pipeline)
+	cursor, err := collection.Aggregate(ctx, []bson.M{{"$match": match}, {"$facet": facets}})
 	if err != nil {
-		return 0, err
+		return nil, err
 	}
 	defer cursor.Close(ctx)
 
-	var result struct {
-		Total float64 `bson:"total"`
+	var result map[string][]struct {
+		Total Domain.Money `bson:"total"`
 	}
-
 	if cursor.Next(ctx) {
 		if err := cursor.Decode(&result); err != nil {
-			return 0, err
+			return nil, err
 		}
-		return result.Total, nil
 	}
 
-	return 0, nil
+	totals := make(map[string]Domain.Money, len(windows))
+	for name := range windows {
+		if groups := result[name]; len(groups) > 0 {
+			totals[name] = groups[0].Total
+		}
+	}
+	return totals, cursor.Err()
 }
 
 func (r *ReportRepository) getLowStockCount(businessID string) (int, error) {
@@ -459,7 +493,7 @@ func (r *ReportRepository) getLowStockCount(businessID string) (int, error) {
 		return 0, err
 	}
 
-	productsCollection := r.db.Collection("products")
+	productsCollection := r.reads().Collection("products")
 
 	count, err := productsCollection.CountDocuments(ctx, bson.M{
 		"business_id": objBusinessID,
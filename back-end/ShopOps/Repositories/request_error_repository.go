@@ -0,0 +1,85 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RequestErrorRepository struct {
+	collection *mongo.Collection
+}
+
+// NewRequestErrorRepository stores failed requests; a TTL index drops them after 14 days
+func NewRequestErrorRepository(db *mongo.Database) Domain.RequestErrorRepository {
+	return &RequestErrorRepository{
+		collection: db.Collection("request_errors"),
+	}
+}
+
+func (r *RequestErrorRepository) Create(requestError *Domain.RequestError) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if requestError.CreatedAt.IsZero() {
+		requestError.CreatedAt = time.Now()
+	}
+
+	result, err := r.collection.InsertOne(ctx, requestError)
+	if err != nil {
+		return fmt.Errorf("failed to create request error: %w", err)
+	}
+
+	requestError.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *RequestErrorRepository) FindByBusiness(businessID string, limit int) ([]Domain.RequestError, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.collection.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find request errors: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var requestErrors []Domain.RequestError
+	if err := cursor.All(ctx, &requestErrors); err != nil {
+		return nil, fmt.Errorf("failed to decode request errors: %w", err)
+	}
+
+	return requestErrors, nil
+}
+
+func (r *RequestErrorRepository) CountByBusinessSince(businessID string, since time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{
+		"business_id": objBusinessID,
+		"created_at":  bson.M{"$gte": since},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count request errors: %w", err)
+	}
+
+	return int(count), nil
+}
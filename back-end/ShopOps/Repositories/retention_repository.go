@@ -0,0 +1,145 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type RetentionRepository struct {
+	db      *mongo.Database
+	reports *mongo.Collection
+}
+
+func NewRetentionRepository(db *mongo.Database) Domain.RetentionRepository {
+	return &RetentionRepository{
+		db:      db,
+		reports: db.Collection("retention_reports"),
+	}
+}
+
+// Anonymize only picks documents that still have one of the fields, so each batch
+// moves on and a run over what is already anonymized finds nothing. updated_at is
+// bumped so the BI feed and the tills' sync send them again without the customer.
+// Records the customer has bought under since before are skipped in the query
+// itself, so a batch never comes back empty while some are left to do.
+func (r *RetentionRepository) Anonymize(businessID primitive.ObjectID, collection Domain.AnonymizedCollection, before time.Time, limit int) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	documents := r.db.Collection(collection.Name)
+	present := make([]bson.M, len(collection.Fields))
+	unset := bson.M{}
+	for i, field := range collection.Fields {
+		present[i] = bson.M{field: bson.M{"$exists": true}}
+		unset[field] = ""
+	}
+	filter := bson.M{
+		"business_id":     businessID,
+		collection.AgedBy: bson.M{"$lt": before},
+		"$or":             present,
+	}
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	if collection.SoldToPhone != "" {
+		pipeline = append(pipeline,
+			bson.D{{Key: "$lookup", Value: bson.M{
+				"from": "sales",
+				"let":  bson.M{"phone": bson.M{"$ifNull": bson.A{"$" + collection.SoldToPhone, ""}}},
+				"pipeline": bson.A{
+					bson.M{"$match": bson.M{"$expr": bson.M{"$and": bson.A{
+						bson.M{"$eq": bson.A{"$business_id", businessID}},
+						bson.M{"$eq": bson.A{"$customer_phone", "$$phone"}},
+						bson.M{"$gte": bson.A{"$created_at", before}},
+					}}}},
+					bson.M{"$limit": 1},
+					bson.M{"$project": bson.M{"_id": 1}},
+				},
+				"as": "recent_sales",
+			}}},
+			bson.D{{Key: "$match", Value: bson.M{"recent_sales": bson.M{"$size": 0}}}},
+		)
+	}
+	pipeline = append(pipeline,
+		bson.D{{Key: "$limit", Value: limit}},
+		bson.D{{Key: "$project", Value: bson.M{"_id": 1}}},
+	)
+	cursor, err := documents.Aggregate(ctx, pipeline)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find %s to anonymize: %w", collection.Name, err)
+	}
+
+	var found []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &found); err != nil {
+		return 0, fmt.Errorf("failed to decode %s to anonymize: %w", collection.Name, err)
+	}
+	if len(found) == 0 {
+		return 0, nil
+	}
+
+	ids := make([]primitive.ObjectID, len(found))
+	for i, document := range found {
+		ids[i] = document.ID
+	}
+
+	now := time.Now()
+	result, err := documents.UpdateMany(ctx, bson.M{"_id": bson.M{"$in": ids}}, bson.M{
+		"$unset": unset,
+		"$set":   bson.M{"anonymized_at": now, "updated_at": now},
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to anonymize %s: %w", collection.Name, err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *RetentionRepository) SaveReports(reports []Domain.RetentionReport) error {
+	if len(reports) == 0 {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	documents := make([]interface{}, len(reports))
+	for i := range reports {
+		documents[i] = reports[i]
+	}
+
+	if _, err := r.reports.InsertMany(ctx, documents); err != nil {
+		return fmt.Errorf("failed to save retention reports: %w", err)
+	}
+
+	return nil
+}
+
+func (r *RetentionRepository) FindReports(businessID string, limit int) ([]Domain.RetentionReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"run_at": -1}).SetLimit(int64(limit))
+	cursor, err := r.reports.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find retention reports: %w", err)
+	}
+
+	var reports []Domain.RetentionReport
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, fmt.Errorf("failed to decode retention reports: %w", err)
+	}
+
+	return reports, nil
+}
@@ -15,12 +15,14 @@ import (
 type SalesRepository struct {
 	collection *mongo.Collection
 	db         *mongo.Database
+	outbox     *outbox
 }
 
 func NewSalesRepository(db *mongo.Database) Domain.SaleRepository {
 	return &SalesRepository{
 		collection: db.Collection("sales"),
 		db:         db,
+		outbox:     newOutbox(db),
 	}
 }
 
@@ -29,48 +31,93 @@ func (r *SalesRepository) Create(sale *Domain.Sale) error {
 	defer cancel()
 
 	// Calculate totals
-	sale.TotalAmount = sale.Quantity * sale.UnitPrice
+	sale.TotalAmount = sale.UnitPrice.Times(sale.Quantity)
 	sale.FinalAmount = sale.TotalAmount - sale.Discount + sale.Tax
 
 	sale.Status = Domain.SaleStatusCompleted
-	sale.PaymentStatus = Domain.PaymentStatusPaid
+	if sale.PaymentStatus == "" {
+		sale.PaymentStatus = Domain.PaymentStatusPaid
+	}
 	sale.CreatedAt = time.Now()
 	sale.UpdatedAt = time.Now()
-
-	result, err := r.collection.InsertOne(ctx, sale)
-	if err != nil {
-		return fmt.Errorf("failed to create sale: %w", err)
+	if sale.ID.IsZero() {
+		sale.ID = primitive.NewObjectID()
 	}
 
-	sale.ID = result.InsertedID.(primitive.ObjectID)
-	return nil
+	// The sale.recorded event goes in with the sale, so subscribers never miss one
+	return r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		if _, err := r.collection.InsertOne(ctx, sale); err != nil {
+			return nil, fmt.Errorf("failed to create sale: %w", err)
+		}
+		event, err := Domain.NewDomainEvent(sale.BusinessID, Domain.EventSaleRecorded, sale.ID.Hex(), sale)
+		if err != nil {
+			return nil, err
+		}
+		return []*Domain.DomainEvent{event}, nil
+	})
+}
+
+func (r *SalesRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Sale, error) {
+	var sale Domain.Sale
+	found, err := findByIDInBusiness(ctx, r.collection, id, &sale)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &sale, nil
 }
 
-func (r *SalesRepository) FindByID(id string) (*Domain.Sale, error) {
+func (r *SalesRepository) FindByBusinessID(businessID string, filters Domain.SaleFilters) ([]Domain.Sale, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
-	objID, err := primitive.ObjectIDFromHex(id)
+	query, err := r.buildQuery(businessID, filters)
 	if err != nil {
-		return nil, fmt.Errorf("invalid sale ID: %w", err)
+		return nil, err
 	}
 
-	var sale Domain.Sale
-	err = r.collection.FindOne(ctx, bson.M{"_id": objID}).Decode(&sale)
+	opts := options.Find()
+	applyPage(query, opts, filters.Sort, Domain.SaleSorts["-created_at"], filters.After)
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
 	if err != nil {
-		if err == mongo.ErrNoDocuments {
-			return nil, nil
-		}
-		return nil, fmt.Errorf("failed to find sale: %w", err)
+		return nil, fmt.Errorf("failed to find sales: %w", err)
 	}
+	defer cursor.Close(ctx)
 
-	return &sale, nil
+	var sales []Domain.Sale
+	if err := cursor.All(ctx, &sales); err != nil {
+		return nil, fmt.Errorf("failed to decode sales: %w", err)
+	}
+
+	return sales, nil
 }
 
-func (r *SalesRepository) FindByBusinessID(businessID string, filters Domain.SaleFilters) ([]Domain.Sale, error) {
+func (r *SalesRepository) Count(businessID string, filters Domain.SaleFilters) (int64, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
 
+	query, err := r.buildQuery(businessID, filters)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := r.collection.CountDocuments(ctx, query)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count sales: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *SalesRepository) buildQuery(businessID string, filters Domain.SaleFilters) (bson.M, error) {
 	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid business ID: %w", err)
@@ -102,28 +149,23 @@ func (r *SalesRepository) FindByBusinessID(businessID string, filters Domain.Sal
 		query["payment_status"] = *filters.PaymentStatus
 	}
 
-	opts := options.Find().SetSort(bson.M{"created_at": -1})
-
-	if filters.Limit > 0 {
-		opts.SetLimit(int64(filters.Limit))
-	}
-
-	if filters.Offset > 0 {
-		opts.SetSkip(int64(filters.Offset))
+	if filters.CustomerPhone != nil {
+		query["customer_phone"] = *filters.CustomerPhone
 	}
 
-	cursor, err := r.collection.Find(ctx, query, opts)
-	if err != nil {
-		return nil, fmt.Errorf("failed to find sales: %w", err)
+	if filters.EmployeeID != nil {
+		objEmployeeID, err := primitive.ObjectIDFromHex(*filters.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID: %w", err)
+		}
+		query["employee_id"] = objEmployeeID
 	}
-	defer cursor.Close(ctx)
 
-	var sales []Domain.Sale
-	if err := cursor.All(ctx, &sales); err != nil {
-		return nil, fmt.Errorf("failed to decode sales: %w", err)
+	if filters.UpdatedSince != nil {
+		query["updated_at"] = bson.M{"$gte": *filters.UpdatedSince}
 	}
 
-	return sales, nil
+	return query, nil
 }
 
 func (r *SalesRepository) FindByLocalID(businessID, localID string) (*Domain.Sale, error) {
@@ -156,7 +198,7 @@ func (r *SalesRepository) Update(sale *Domain.Sale) error {
 	defer cancel()
 
 	sale.UpdatedAt = time.Now()
-	sale.TotalAmount = sale.Quantity * sale.UnitPrice
+	sale.TotalAmount = sale.UnitPrice.Times(sale.Quantity)
 	sale.FinalAmount = sale.TotalAmount - sale.Discount + sale.Tax
 
 	update := bson.M{
@@ -166,24 +208,32 @@ func (r *SalesRepository) Update(sale *Domain.Sale) error {
 			"customer_phone": sale.CustomerPhone,
 			"quantity":       sale.Quantity,
 			"unit_price":     sale.UnitPrice,
+			"unit_cost":      sale.UnitCost,
 			"total_amount":   sale.TotalAmount,
 			"discount":       sale.Discount,
 			"tax":            sale.Tax,
 			"final_amount":   sale.FinalAmount,
 			"payment_method": sale.PaymentMethod,
 			"payment_status": sale.PaymentStatus,
+			"cash_rounding":  sale.CashRounding,
+			"deposits":       sale.Deposits,
+			"deposit_amount": sale.DepositAmount,
 			"notes":          sale.Notes,
 			"status":         sale.Status,
 			"updated_at":     sale.UpdatedAt,
 		},
 	}
 
-	_, err := r.collection.UpdateByID(ctx, sale.ID, update)
-	if err != nil {
-		return fmt.Errorf("failed to update sale: %w", err)
-	}
-
-	return nil
+	return r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		if _, err := r.collection.UpdateByID(ctx, sale.ID, update); err != nil {
+			return nil, fmt.Errorf("failed to update sale: %w", err)
+		}
+		event, err := Domain.NewDomainEvent(sale.BusinessID, Domain.EventSaleUpdated, sale.ID.Hex(), sale)
+		if err != nil {
+			return nil, err
+		}
+		return []*Domain.DomainEvent{event}, nil
+	})
 }
 
 func (r *SalesRepository) UpdateStatus(id string, status Domain.SaleStatus) error {
@@ -210,6 +260,59 @@ func (r *SalesRepository) UpdateStatus(id string, status Domain.SaleStatus) erro
 	return nil
 }
 
+func (r *SalesRepository) SetPayment(id primitive.ObjectID, status Domain.PaymentStatus, payments []Domain.SalePayment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"payment_status": status,
+			"payments":       payments,
+			"updated_at":     time.Now(),
+		},
+	}
+
+	if _, err := r.collection.UpdateByID(ctx, id, update); err != nil {
+		return fmt.Errorf("failed to update sale payment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SalesRepository) Void(id string, voidedBy primitive.ObjectID, voidedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid sale ID: %w", err)
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":     Domain.SaleStatusVoided,
+			"voided_by":  voidedBy,
+			"voided_at":  voidedAt,
+			"updated_at": time.Now(),
+		},
+	}
+
+	return r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		var sale Domain.Sale
+		err := r.collection.FindOneAndUpdate(ctx, bson.M{"_id": objID}, update,
+			options.FindOneAndUpdate().SetReturnDocument(options.After),
+		).Decode(&sale)
+		if err != nil {
+			return nil, fmt.Errorf("failed to void sale: %w", err)
+		}
+		event, err := Domain.NewDomainEvent(sale.BusinessID, Domain.EventSaleVoided, sale.ID.Hex(), sale)
+		if err != nil {
+			return nil, err
+		}
+		return []*Domain.DomainEvent{event}, nil
+	})
+}
+
 func (r *SalesRepository) Delete(id string) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -263,18 +366,18 @@ func (r *SalesRepository) GetSummary(businessID string, startDate, endDate time.
 		},
 	}
 
-	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	cursor, err := r.collection.Aggregate(ctx, withArchive("sales", pipeline))
 	if err != nil {
 		return nil, fmt.Errorf("failed to aggregate sales: %w", err)
 	}
 	defer cursor.Close(ctx)
 
 	var result struct {
-		TotalSales       float64 `bson:"total_sales"`
-		TotalAmount      float64 `bson:"total_amount"`
-		TotalDiscount    float64 `bson:"total_discount"`
-		TotalTax         float64 `bson:"total_tax"`
-		TransactionCount int     `bson:"transaction_count"`
+		TotalSales       float64      `bson:"total_sales"`
+		TotalAmount      Domain.Money `bson:"total_amount"`
+		TotalDiscount    Domain.Money `bson:"total_discount"`
+		TotalTax         Domain.Money `bson:"total_tax"`
+		TransactionCount int          `bson:"transaction_count"`
 	}
 
 	if cursor.Next(ctx) {
@@ -340,3 +443,73 @@ func (r *SalesRepository) GetDailySales(businessID string, date time.Time) ([]Do
 
 	return sales, nil
 }
+
+func (r *SalesRepository) ReassignCustomer(businessID, fromPhone, toPhone, toName string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	result, err := r.collection.UpdateMany(ctx,
+		bson.M{"business_id": objBusinessID, "customer_phone": fromPhone},
+		bson.M{"$set": bson.M{
+			"customer_phone": toPhone,
+			"customer_name":  toName,
+			"updated_at":     time.Now(),
+		}},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("failed to reassign sales: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
+
+func (r *SalesRepository) GetForeignTakings(businessID string, startDate, endDate time.Time) ([]Domain.ForeignCurrencyTakings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id":       objBusinessID,
+				"created_at":        bson.M{"$gte": startDate, "$lte": endDate},
+				"status":            Domain.SaleStatusCompleted,
+				"payments.currency": bson.M{"$exists": true},
+			},
+		},
+		{"$unwind": "$payments"},
+		{"$match": bson.M{"payments.currency": bson.M{"$exists": true}}},
+		{
+			"$group": bson.M{
+				"_id":            "$payments.currency",
+				"foreign_amount": bson.M{"$sum": "$payments.foreign_amount"},
+				"amount":         bson.M{"$sum": "$payments.amount"},
+				"change":         bson.M{"$sum": "$payments.change"},
+				"payments":       bson.M{"$sum": 1},
+			},
+		},
+		{"$sort": bson.M{"_id": 1}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, withArchive("sales", pipeline))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate foreign takings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	takings := []Domain.ForeignCurrencyTakings{}
+	if err := cursor.All(ctx, &takings); err != nil {
+		return nil, fmt.Errorf("failed to decode foreign takings: %w", err)
+	}
+
+	return takings, nil
+}
@@ -0,0 +1,374 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+type SalesSummaryRepository struct {
+	hourly *mongo.Collection
+	daily  *mongo.Collection
+	sales  *mongo.Collection
+	reads  func() *mongo.Database // Report queries; rebuilds read and write the primary
+}
+
+func NewSalesSummaryRepository(db *mongo.Database, reads func() *mongo.Database) Domain.SalesSummaryRepository {
+	return &SalesSummaryRepository{
+		hourly: db.Collection("sales_summary_hourly"),
+		daily:  db.Collection("sales_summary_daily"),
+		sales:  db.Collection("sales"),
+		reads:  reads,
+	}
+}
+
+func (r *SalesSummaryRepository) RebuildDay(businessID string, day, start, end time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	// Truncate created_at to the hour
+	createdMillis := bson.M{"$toLong": "$created_at"}
+	hour := bson.M{"$toDate": bson.M{"$subtract": bson.A{createdMillis, bson.M{"$mod": bson.A{createdMillis, int64(time.Hour / time.Millisecond)}}}}}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"status":      Domain.SaleStatusCompleted,
+				"created_at":  bson.M{"$gte": start, "$lt": end},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "product_id",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"hour":           hour,
+					"device_id":      bson.M{"$ifNull": bson.A{"$device_id", ""}},
+					"category":       bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$product.category", 0}}, ""}},
+					"payment_method": "$payment_method",
+				},
+				"quantity":      bson.M{"$sum": "$quantity"},
+				"amount":        bson.M{"$sum": "$final_amount"},
+				"discount":      bson.M{"$sum": "$discount"},
+				"tax":           bson.M{"$sum": "$tax"},
+				"cash_rounding": bson.M{"$sum": "$cash_rounding"},
+				"transactions":  bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	// Days past the archive age are rebuilt from the archive
+	cursor, err := r.sales.Aggregate(ctx, withArchive("sales", pipeline))
+	if err != nil {
+		return fmt.Errorf("failed to aggregate sales summary: %w", err)
+	}
+
+	var results []struct {
+		Key struct {
+			Hour          time.Time            `bson:"hour"`
+			DeviceID      string               `bson:"device_id"`
+			Category      string               `bson:"category"`
+			PaymentMethod Domain.PaymentMethod `bson:"payment_method"`
+		} `bson:"_id"`
+		Quantity     float64      `bson:"quantity"`
+		Amount       Domain.Money `bson:"amount"`
+		Discount     Domain.Money `bson:"discount"`
+		Tax          Domain.Money `bson:"tax"`
+		CashRounding Domain.Money `bson:"cash_rounding"`
+		Transactions int          `bson:"transactions"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return fmt.Errorf("failed to decode sales summary: %w", err)
+	}
+
+	now := time.Now()
+
+	type dailyKey struct {
+		deviceID      string
+		category      string
+		paymentMethod Domain.PaymentMethod
+	}
+
+	var hourlyRows []interface{}
+	dailyTotals := make(map[dailyKey]*Domain.SalesSummaryRow)
+	var dailyOrder []dailyKey
+
+	for _, result := range results {
+		hourlyRows = append(hourlyRows, Domain.SalesSummaryRow{
+			BusinessID:    objBusinessID,
+			Day:           day,
+			PeriodStart:   result.Key.Hour.UTC(),
+			DeviceID:      result.Key.DeviceID,
+			Category:      result.Key.Category,
+			PaymentMethod: result.Key.PaymentMethod,
+			Quantity:      result.Quantity,
+			Amount:        result.Amount,
+			Discount:      result.Discount,
+			Tax:           result.Tax,
+			CashRounding:  result.CashRounding,
+			Transactions:  result.Transactions,
+			UpdatedAt:     now,
+		})
+
+		key := dailyKey{result.Key.DeviceID, result.Key.Category, result.Key.PaymentMethod}
+		row := dailyTotals[key]
+		if row == nil {
+			row = &Domain.SalesSummaryRow{
+				BusinessID:    objBusinessID,
+				Day:           day,
+				PeriodStart:   day,
+				DeviceID:      key.deviceID,
+				Category:      key.category,
+				PaymentMethod: key.paymentMethod,
+				UpdatedAt:     now,
+			}
+			dailyTotals[key] = row
+			dailyOrder = append(dailyOrder, key)
+		}
+		row.Quantity += result.Quantity
+		row.Amount += result.Amount
+		row.Discount += result.Discount
+		row.Tax += result.Tax
+		row.CashRounding += result.CashRounding
+		row.Transactions += result.Transactions
+	}
+
+	var dailyRows []interface{}
+	for _, key := range dailyOrder {
+		dailyRows = append(dailyRows, *dailyTotals[key])
+	}
+
+	// Replace the day's rows wholesale so rebuilding is idempotent
+	dayFilter := bson.M{"business_id": objBusinessID, "day": day}
+
+	if _, err := r.hourly.DeleteMany(ctx, dayFilter); err != nil {
+		return fmt.Errorf("failed to clear hourly sales summary: %w", err)
+	}
+	if len(hourlyRows) > 0 {
+		if _, err := r.hourly.InsertMany(ctx, hourlyRows); err != nil {
+			return fmt.Errorf("failed to store hourly sales summary: %w", err)
+		}
+	}
+
+	if _, err := r.daily.DeleteMany(ctx, dayFilter); err != nil {
+		return fmt.Errorf("failed to clear daily sales summary: %w", err)
+	}
+	if len(dailyRows) > 0 {
+		if _, err := r.daily.InsertMany(ctx, dailyRows); err != nil {
+			return fmt.Errorf("failed to store daily sales summary: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *SalesSummaryRepository) Summary(businessID string, start, end time.Time) (*Domain.SaleSummary, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := hourRangeMatch(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.reads().Collection("sales_summary_hourly").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":               nil,
+				"total_sales":       bson.M{"$sum": "$quantity"},
+				"total_amount":      bson.M{"$sum": "$amount"},
+				"total_discount":    bson.M{"$sum": "$discount"},
+				"total_tax":         bson.M{"$sum": "$tax"},
+				"transaction_count": bson.M{"$sum": "$transactions"},
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sales summary: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var result struct {
+		TotalSales       float64      `bson:"total_sales"`
+		TotalAmount      Domain.Money `bson:"total_amount"`
+		TotalDiscount    Domain.Money `bson:"total_discount"`
+		TotalTax         Domain.Money `bson:"total_tax"`
+		TransactionCount int          `bson:"transaction_count"`
+	}
+
+	if cursor.Next(ctx) {
+		if err := cursor.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode sales summary: %w", err)
+		}
+	}
+
+	return &Domain.SaleSummary{
+		Date:             start,
+		TotalSales:       result.TotalSales,
+		TotalAmount:      result.TotalAmount,
+		TotalDiscount:    result.TotalDiscount,
+		TotalTax:         result.TotalTax,
+		TransactionCount: result.TransactionCount,
+	}, nil
+}
+
+func (r *SalesSummaryRepository) HourlyBreakdown(businessID string, start, end time.Time) ([]Domain.SalesBreakdownLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := hourRangeMatch(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	cursor, err := r.reads().Collection("sales_summary_hourly").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":          bson.M{"$dateToString": bson.M{"format": "%Y-%m-%dT%H:00:00Z", "date": "$period_start"}},
+				"quantity":     bson.M{"$sum": "$quantity"},
+				"amount":       bson.M{"$sum": "$amount"},
+				"transactions": bson.M{"$sum": "$transactions"},
+			},
+		},
+		{"$sort": bson.M{"_id": 1}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate hourly sales: %w", err)
+	}
+
+	var lines []Domain.SalesBreakdownLine
+	if err := cursor.All(ctx, &lines); err != nil {
+		return nil, fmt.Errorf("failed to decode hourly sales: %w", err)
+	}
+
+	return lines, nil
+}
+
+func (r *SalesSummaryRepository) DailyBreakdown(businessID string, fromDay, toDay time.Time, by Domain.SalesSummaryDimension) ([]Domain.SalesBreakdownLine, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := dayRangeMatch(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	var groupKey interface{}
+	sort := bson.M{"amount": -1}
+	switch by {
+	case Domain.SalesSummaryByDay:
+		groupKey = bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": "$day"}}
+		sort = bson.M{"_id": 1}
+	case Domain.SalesSummaryByDevice:
+		groupKey = "$device_id"
+	case Domain.SalesSummaryByCategory:
+		groupKey = "$category"
+	case Domain.SalesSummaryByPaymentMethod:
+		groupKey = "$payment_method"
+	default:
+		return nil, fmt.Errorf("unsupported sales summary dimension: %s", by)
+	}
+
+	cursor, err := r.reads().Collection("sales_summary_daily").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":           groupKey,
+				"quantity":      bson.M{"$sum": "$quantity"},
+				"amount":        bson.M{"$sum": "$amount"},
+				"cash_rounding": bson.M{"$sum": "$cash_rounding"},
+				"transactions":  bson.M{"$sum": "$transactions"},
+			},
+		},
+		{"$sort": sort},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate daily sales: %w", err)
+	}
+
+	var lines []Domain.SalesBreakdownLine
+	if err := cursor.All(ctx, &lines); err != nil {
+		return nil, fmt.Errorf("failed to decode daily sales: %w", err)
+	}
+
+	return lines, nil
+}
+
+func (r *SalesSummaryRepository) Heatmap(businessID string, start, end time.Time, timezone, deviceID string) ([]Domain.HeatmapCell, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	match, err := hourRangeMatch(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	if deviceID != "" {
+		match["device_id"] = deviceID
+	}
+
+	cursor, err := r.reads().Collection("sales_summary_hourly").Aggregate(ctx, []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"day_of_week": bson.M{"$dayOfWeek": bson.M{"date": "$period_start", "timezone": timezone}},
+					"hour":        bson.M{"$hour": bson.M{"date": "$period_start", "timezone": timezone}},
+				},
+				"transactions": bson.M{"$sum": "$transactions"},
+				"revenue":      bson.M{"$sum": "$amount"},
+			},
+		},
+		{
+			// $dayOfWeek counts from 1 = Sunday
+			"$project": bson.M{
+				"_id":          0,
+				"day_of_week":  bson.M{"$subtract": bson.A{"$_id.day_of_week", 1}},
+				"hour":         "$_id.hour",
+				"transactions": 1,
+				"revenue":      1,
+			},
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate sales heatmap: %w", err)
+	}
+
+	var cells []Domain.HeatmapCell
+	if err := cursor.All(ctx, &cells); err != nil {
+		return nil, fmt.Errorf("failed to decode sales heatmap: %w", err)
+	}
+
+	return cells, nil
+}
+
+// hourRangeMatch selects hourly rows overlapping [start, end)
+func hourRangeMatch(businessID string, start, end time.Time) (bson.M, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return bson.M{
+		"business_id":  objBusinessID,
+		"period_start": bson.M{"$gte": start.UTC().Truncate(time.Hour), "$lt": end},
+	}, nil
+}
@@ -0,0 +1,194 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SavedReportRepository struct {
+	collection     *mongo.Collection
+	runsCollection *mongo.Collection
+}
+
+func NewSavedReportRepository(db *mongo.Database) Domain.SavedReportRepository {
+	return &SavedReportRepository{
+		collection:     db.Collection("saved_reports"),
+		runsCollection: db.Collection("saved_report_runs"),
+	}
+}
+
+func (r *SavedReportRepository) Create(report *Domain.SavedReport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report.CreatedAt = time.Now()
+	report.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, report)
+	if err != nil {
+		return fmt.Errorf("failed to create saved report: %w", err)
+	}
+
+	report.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SavedReportRepository) FindInBusiness(ctx context.Context, id string) (*Domain.SavedReport, error) {
+	var report Domain.SavedReport
+	found, err := findByIDInBusiness(ctx, r.collection, id, &report)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &report, nil
+}
+
+func (r *SavedReportRepository) FindByBusinessID(businessID string) ([]Domain.SavedReport, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return r.find(bson.M{"business_id": objBusinessID})
+}
+
+func (r *SavedReportRepository) FindDue(now time.Time) ([]Domain.SavedReport, error) {
+	return r.find(bson.M{
+		"schedule":    bson.M{"$ne": Domain.ReportScheduleNone},
+		"next_run_at": bson.M{"$lte": now},
+	})
+}
+
+func (r *SavedReportRepository) find(query bson.M) ([]Domain.SavedReport, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find saved reports: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reports []Domain.SavedReport
+	if err := cursor.All(ctx, &reports); err != nil {
+		return nil, fmt.Errorf("failed to decode saved reports: %w", err)
+	}
+
+	return reports, nil
+}
+
+func (r *SavedReportRepository) Update(report *Domain.SavedReport) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":        report.Name,
+			"description": report.Description,
+			"definition":  report.Definition,
+			"schedule":    report.Schedule,
+			"next_run_at": report.NextRunAt,
+			"updated_at":  report.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, report.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update saved report: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SavedReportRepository) UpdateRunTimes(id primitive.ObjectID, lastRunAt time.Time, nextRunAt *time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"last_run_at": lastRunAt,
+			"next_run_at": nextRunAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return fmt.Errorf("failed to update saved report run times: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SavedReportRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid report ID: %w", err)
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete saved report: %w", err)
+	}
+
+	// Stored results go with the report
+	_, err = r.runsCollection.DeleteMany(ctx, bson.M{"saved_report_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete saved report runs: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SavedReportRepository) CreateRun(run *Domain.SavedReportRun) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	run.CreatedAt = time.Now()
+
+	result, err := r.runsCollection.InsertOne(ctx, run)
+	if err != nil {
+		return fmt.Errorf("failed to store report run: %w", err)
+	}
+
+	run.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SavedReportRepository) FindRuns(reportID string, limit int) ([]Domain.SavedReportRun, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(reportID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid report ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.runsCollection.Find(ctx, bson.M{"saved_report_id": objID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find report runs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var runs []Domain.SavedReportRun
+	if err := cursor.All(ctx, &runs); err != nil {
+		return nil, fmt.Errorf("failed to decode report runs: %w", err)
+	}
+
+	return runs, nil
+}
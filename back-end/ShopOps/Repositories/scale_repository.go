@@ -0,0 +1,74 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ScaleRepository struct {
+	settingsCollection *mongo.Collection
+}
+
+func NewScaleRepository(db *mongo.Database) Domain.ScaleRepository {
+	return &ScaleRepository{
+		settingsCollection: db.Collection("scale_settings"),
+	}
+}
+
+func (r *ScaleRepository) FindSettings(businessID string) (*Domain.ScaleSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var settings Domain.ScaleSettings
+	err = r.settingsCollection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find scale settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *ScaleRepository) SaveSettings(settings *Domain.ScaleSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"enabled":           settings.Enabled,
+			"prefixes":          settings.Prefixes,
+			"prefix_digits":     settings.PrefixDigits,
+			"item_digits":       settings.ItemDigits,
+			"value_check_digit": settings.ValueCheckDigit,
+			"value_digits":      settings.ValueDigits,
+			"value_type":        settings.ValueType,
+			"value_decimals":    settings.ValueDecimals,
+			"export_format":     settings.ExportFormat,
+			"name_length":       settings.NameLength,
+			"schemes":           settings.Schemes,
+			"updated_at":        settings.UpdatedAt,
+		},
+	}
+
+	_, err := r.settingsCollection.UpdateOne(ctx, bson.M{"business_id": settings.BusinessID}, update, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save scale settings: %w", err)
+	}
+	return nil
+}
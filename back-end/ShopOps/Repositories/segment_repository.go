@@ -0,0 +1,194 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SegmentRepository struct {
+	collection      *mongo.Collection
+	salesCollection *mongo.Collection
+}
+
+func NewSegmentRepository(db *mongo.Database) Domain.SegmentRepository {
+	return &SegmentRepository{
+		collection:      db.Collection("customer_segments"),
+		salesCollection: db.Collection("sales"),
+	}
+}
+
+func (r *SegmentRepository) Create(segment *Domain.CustomerSegment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	segment.CreatedAt = time.Now()
+	segment.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, segment)
+	if err != nil {
+		return fmt.Errorf("failed to create segment: %w", err)
+	}
+
+	segment.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SegmentRepository) FindInBusiness(ctx context.Context, id string) (*Domain.CustomerSegment, error) {
+	var segment Domain.CustomerSegment
+	found, err := findByIDInBusiness(ctx, r.collection, id, &segment)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &segment, nil
+}
+
+func (r *SegmentRepository) FindByBusinessID(businessID string) ([]Domain.CustomerSegment, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return r.find(bson.M{"business_id": objBusinessID})
+}
+
+func (r *SegmentRepository) FindAll() ([]Domain.CustomerSegment, error) {
+	return r.find(bson.M{})
+}
+
+func (r *SegmentRepository) FindForDashboard(businessID string) ([]Domain.CustomerSegment, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return r.find(bson.M{"business_id": objBusinessID, "show_on_dashboard": true})
+}
+
+func (r *SegmentRepository) find(query bson.M) ([]Domain.CustomerSegment, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find segments: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var segments []Domain.CustomerSegment
+	if err := cursor.All(ctx, &segments); err != nil {
+		return nil, fmt.Errorf("failed to decode segments: %w", err)
+	}
+
+	return segments, nil
+}
+
+func (r *SegmentRepository) Update(segment *Domain.CustomerSegment) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	segment.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"name":              segment.Name,
+			"description":       segment.Description,
+			"rules":             segment.Rules,
+			"show_on_dashboard": segment.ShowOnDashboard,
+			"updated_at":        segment.UpdatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, segment.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update segment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SegmentRepository) UpdateMemberCount(id primitive.ObjectID, count int, evaluatedAt time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"member_count": count,
+			"evaluated_at": evaluatedAt,
+		},
+	}
+
+	_, err := r.collection.UpdateByID(ctx, id, update)
+	if err != nil {
+		return fmt.Errorf("failed to update segment member count: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SegmentRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid segment ID: %w", err)
+	}
+
+	_, err = r.collection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete segment: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SegmentRepository) PurchaseStats(businessID string, since *time.Time) ([]Domain.CustomerPurchaseStats, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	match := bson.M{
+		"business_id":    objBusinessID,
+		"status":         Domain.SaleStatusCompleted,
+		"customer_phone": bson.M{"$nin": bson.A{nil, ""}},
+	}
+	if since != nil {
+		match["created_at"] = bson.M{"$gte": *since}
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{
+			"$group": bson.M{
+				"_id":              "$customer_phone",
+				"purchases":        bson.M{"$sum": 1},
+				"total_spent":      bson.M{"$sum": "$final_amount"},
+				"last_purchase_at": bson.M{"$max": "$created_at"},
+			},
+		},
+	}
+
+	cursor, err := r.salesCollection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate customer purchases: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var stats []Domain.CustomerPurchaseStats
+	if err := cursor.All(ctx, &stats); err != nil {
+		return nil, fmt.Errorf("failed to decode customer purchases: %w", err)
+	}
+
+	return stats, nil
+}
@@ -0,0 +1,126 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ShopSettingsRepository struct {
+	settingsCollection *mongo.Collection
+	changesCollection  *mongo.Collection
+}
+
+func NewShopSettingsRepository(db *mongo.Database) Domain.ShopSettingsRepository {
+	return &ShopSettingsRepository{
+		settingsCollection: db.Collection("shop_settings"),
+		changesCollection:  db.Collection("setting_changes"),
+	}
+}
+
+func (r *ShopSettingsRepository) FindSettings(businessID string) (*Domain.ShopSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	// Settings added since the shop last saved keep their defaults
+	settings := Domain.DefaultShopSettings
+	err = r.settingsCollection.FindOne(ctx, bson.M{"business_id": objBusinessID}).Decode(&settings)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find shop settings: %w", err)
+	}
+
+	return &settings, nil
+}
+
+func (r *ShopSettingsRepository) SaveSettings(settings *Domain.ShopSettings) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	settings.UpdatedAt = time.Now()
+
+	_, err := r.settingsCollection.ReplaceOne(ctx, bson.M{"business_id": settings.BusinessID}, settings, options.Replace().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to save shop settings: %w", err)
+	}
+	return nil
+}
+
+func (r *ShopSettingsRepository) CreateChanges(changes []Domain.SettingChange) error {
+	if len(changes) == 0 {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	docs := make([]interface{}, len(changes))
+	for i := range changes {
+		docs[i] = changes[i]
+	}
+	result, err := r.changesCollection.InsertMany(ctx, docs)
+	if err != nil {
+		return fmt.Errorf("failed to record setting changes: %w", err)
+	}
+	for i, id := range result.InsertedIDs {
+		changes[i].ID = id.(primitive.ObjectID)
+	}
+	return nil
+}
+
+func (r *ShopSettingsRepository) FindChanges(businessID, key string, limit int) ([]Domain.SettingChange, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	filter := bson.M{"business_id": objBusinessID}
+	if key != "" {
+		filter["key"] = key
+	}
+	opts := options.Find().SetSort(bson.D{{Key: "changed_at", Value: -1}, {Key: "_id", Value: -1}}).SetLimit(int64(limit))
+
+	cursor, err := r.changesCollection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find setting changes: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var changes []Domain.SettingChange
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, fmt.Errorf("failed to decode setting changes: %w", err)
+	}
+	return changes, nil
+}
+
+func (r *ShopSettingsRepository) FindAnonymizing() ([]Domain.ShopSettings, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.settingsCollection.Find(ctx, bson.M{"anonymize_after_years": bson.M{"$gt": 0}})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find shop settings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var settings []Domain.ShopSettings
+	if err := cursor.All(ctx, &settings); err != nil {
+		return nil, fmt.Errorf("failed to decode shop settings: %w", err)
+	}
+	return settings, nil
+}
@@ -0,0 +1,261 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type ShrinkageRepository struct {
+	drawerEvents *mongo.Collection
+	sales        *mongo.Collection
+	movements    *mongo.Collection
+}
+
+func NewShrinkageRepository(db *mongo.Database) Domain.ShrinkageRepository {
+	return &ShrinkageRepository{
+		drawerEvents: db.Collection("drawer_events"),
+		sales:        db.Collection("sales"),
+		movements:    db.Collection("stock_movements"),
+	}
+}
+
+func (r *ShrinkageRepository) CreateDrawerEvent(event *Domain.DrawerEvent) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	result, err := r.drawerEvents.InsertOne(ctx, event)
+	if err != nil {
+		return fmt.Errorf("failed to record drawer event: %w", err)
+	}
+
+	event.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *ShrinkageRepository) FindDrawerEvents(businessID string, filters Domain.DrawerEventFilters) ([]Domain.DrawerEvent, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.EmployeeID != nil {
+		objEmployeeID, err := primitive.ObjectIDFromHex(*filters.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID: %w", err)
+		}
+		query["employee_id"] = objEmployeeID
+	}
+
+	if filters.DeviceID != nil {
+		query["device_id"] = *filters.DeviceID
+	}
+
+	if filters.StartDate != nil && filters.EndDate != nil {
+		query["created_at"] = bson.M{
+			"$gte": *filters.StartDate,
+			"$lte": *filters.EndDate,
+		}
+	} else if filters.StartDate != nil {
+		query["created_at"] = bson.M{"$gte": *filters.StartDate}
+	} else if filters.EndDate != nil {
+		query["created_at"] = bson.M{"$lte": *filters.EndDate}
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.drawerEvents.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find drawer events: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var events []Domain.DrawerEvent
+	if err := cursor.All(ctx, &events); err != nil {
+		return nil, fmt.Errorf("failed to decode drawer events: %w", err)
+	}
+
+	return events, nil
+}
+
+func (r *ShrinkageRepository) SaleActivity(businessID string, start, end time.Time, timezone string, cutoffHour int, by Domain.ShrinkageGroupBy) ([]Domain.ShrinkageActivity, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	completed := bson.M{"$eq": bson.A{"$status", Domain.SaleStatusCompleted}}
+	voided := bson.M{"$eq": bson.A{"$status", Domain.SaleStatusVoided}}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"status":      bson.M{"$in": bson.A{Domain.SaleStatusCompleted, Domain.SaleStatusVoided}},
+				"created_at":  bson.M{"$gte": start, "$lte": end},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":          activityKey(by, "$created_at", timezone, cutoffHour),
+				"sales":        bson.M{"$sum": 1},
+				"sales_amount": bson.M{"$sum": bson.M{"$cond": bson.A{completed, "$final_amount", 0}}},
+				"voids":        bson.M{"$sum": bson.M{"$cond": bson.A{voided, 1, 0}}},
+				"void_amount":  bson.M{"$sum": bson.M{"$cond": bson.A{voided, "$final_amount", 0}}},
+			},
+		},
+	}
+
+	return r.aggregateActivity(r.sales, withArchive("sales", pipeline), "sale activity")
+}
+
+func (r *ShrinkageRepository) DrawerActivity(businessID string, start, end time.Time, timezone string, cutoffHour int, by Domain.ShrinkageGroupBy) ([]Domain.ShrinkageActivity, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"type":        Domain.DrawerEventNoSale,
+				"created_at":  bson.M{"$gte": start, "$lte": end},
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id":      activityKey(by, "$created_at", timezone, cutoffHour),
+				"no_sales": bson.M{"$sum": 1},
+			},
+		},
+	}
+
+	return r.aggregateActivity(r.drawerEvents, pipeline, "drawer activity")
+}
+
+func (r *ShrinkageRepository) StockLossActivity(businessID string, start, end time.Time, timezone string, cutoffHour int) ([]Domain.ShrinkageActivity, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	lost := bson.M{"$abs": "$quantity"}
+
+	pipeline := []bson.M{
+		{
+			"$match": bson.M{
+				"business_id": objBusinessID,
+				"created_at":  bson.M{"$gte": start, "$lte": end},
+				"$or": bson.A{
+					bson.M{"type": bson.M{"$in": bson.A{Domain.MovementTypeDamage, Domain.MovementTypeTheft}}},
+					// A negative adjustment is stock counted short
+					bson.M{"type": Domain.MovementTypeAdjust, "quantity": bson.M{"$lt": 0}},
+				},
+			},
+		},
+		{
+			"$lookup": bson.M{
+				"from":         "products",
+				"localField":   "product_id",
+				"foreignField": "_id",
+				"as":           "product",
+			},
+		},
+		{
+			"$group": bson.M{
+				"_id": bson.M{
+					"key": "$created_by",
+					"day": businessDayString("$created_at", timezone, cutoffHour),
+				},
+				"loss_quantity": bson.M{"$sum": lost},
+				// At the cost the ledger recorded, or today's for movements from before it did
+				"loss_value": bson.M{"$sum": bson.M{"$multiply": bson.A{
+					lost,
+					bson.M{"$ifNull": bson.A{"$unit_cost", bson.M{"$ifNull": bson.A{bson.M{"$arrayElemAt": bson.A{"$product.cost_price", 0}}, 0}}}},
+				}}},
+			},
+		},
+	}
+
+	return r.aggregateActivity(r.movements, withArchive("stock_movements", pipeline), "stock losses")
+}
+
+// activityKey groups by actor and local day. Sales and drawer opens without an
+// employee fall back to the user who recorded them.
+func activityKey(by Domain.ShrinkageGroupBy, dateField, timezone string, cutoffHour int) bson.M {
+	key := interface{}(bson.M{"$ifNull": bson.A{"$employee_id", "$created_by"}})
+	if by == Domain.ShrinkageByDevice {
+		key = bson.M{"$ifNull": bson.A{"$device_id", ""}}
+	}
+
+	return bson.M{
+		"key": key,
+		"day": businessDayString(dateField, timezone, cutoffHour),
+	}
+}
+
+// businessDayString is the YYYY-MM-DD business day of dateField: its date in
+// timezone once moved back by the cutoff, so times before it count toward the day before
+func businessDayString(dateField, timezone string, cutoffHour int) bson.M {
+	date := interface{}(dateField)
+	if cutoffHour > 0 {
+		date = bson.M{"$subtract": bson.A{dateField, int64(cutoffHour) * int64(time.Hour/time.Millisecond)}}
+	}
+	return bson.M{"$dateToString": bson.M{"format": "%Y-%m-%d", "date": date, "timezone": timezone}}
+}
+
+func (r *ShrinkageRepository) aggregateActivity(collection *mongo.Collection, pipeline []bson.M, what string) ([]Domain.ShrinkageActivity, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate %s: %w", what, err)
+	}
+	defer cursor.Close(ctx)
+
+	var activity []Domain.ShrinkageActivity
+	for cursor.Next(ctx) {
+		var result struct {
+			ID struct {
+				Key interface{} `bson:"key"`
+				Day string      `bson:"day"`
+			} `bson:"_id"`
+			Domain.ShrinkageActivity `bson:",inline"`
+		}
+		if err := cursor.Decode(&result); err != nil {
+			return nil, fmt.Errorf("failed to decode %s: %w", what, err)
+		}
+
+		row := result.ShrinkageActivity
+		row.Key = pivotKey(result.ID.Key)
+		row.Day = result.ID.Day
+		activity = append(activity, row)
+	}
+
+	return activity, nil
+}
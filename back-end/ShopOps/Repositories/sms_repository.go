@@ -0,0 +1,289 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SMSRepository struct {
+	messagesCollection  *mongo.Collection
+	campaignsCollection *mongo.Collection
+	blacklistCollection *mongo.Collection
+}
+
+func NewSMSRepository(db *mongo.Database) Domain.SMSRepository {
+	return &SMSRepository{
+		messagesCollection:  db.Collection("sms_messages"),
+		campaignsCollection: db.Collection("sms_campaigns"),
+		blacklistCollection: db.Collection("sms_blacklist"),
+	}
+}
+
+func (r *SMSRepository) LogMessage(message *Domain.SMSMessage) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	message.CreatedAt = time.Now()
+
+	result, err := r.messagesCollection.InsertOne(ctx, message)
+	if err != nil {
+		return fmt.Errorf("failed to log sms message: %w", err)
+	}
+
+	message.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SMSRepository) FindMessages(businessID string, filters Domain.SMSMessageFilters) ([]Domain.SMSMessage, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Type != nil {
+		query["type"] = *filters.Type
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.Phone != nil {
+		query["phone"] = *filters.Phone
+	}
+
+	if filters.CampaignID != nil {
+		objCampaignID, err := primitive.ObjectIDFromHex(*filters.CampaignID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid campaign ID: %w", err)
+		}
+		query["campaign_id"] = objCampaignID
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.messagesCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sms messages: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var messages []Domain.SMSMessage
+	if err := cursor.All(ctx, &messages); err != nil {
+		return nil, fmt.Errorf("failed to decode sms messages: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *SMSRepository) CreateCampaign(campaign *Domain.SMSCampaign) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	campaign.CreatedAt = time.Now()
+
+	result, err := r.campaignsCollection.InsertOne(ctx, campaign)
+	if err != nil {
+		return fmt.Errorf("failed to create sms campaign: %w", err)
+	}
+
+	campaign.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SMSRepository) FindCampaignByID(id string) (*Domain.SMSCampaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid campaign ID: %w", err)
+	}
+
+	var campaign Domain.SMSCampaign
+	err = r.campaignsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find sms campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+func (r *SMSRepository) FindCampaigns(businessID string, limit int) ([]Domain.SMSCampaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.campaignsCollection.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sms campaigns: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var campaigns []Domain.SMSCampaign
+	if err := cursor.All(ctx, &campaigns); err != nil {
+		return nil, fmt.Errorf("failed to decode sms campaigns: %w", err)
+	}
+
+	return campaigns, nil
+}
+
+func (r *SMSRepository) FindActiveCampaign(businessID string) (*Domain.SMSCampaign, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var campaign Domain.SMSCampaign
+	err = r.campaignsCollection.FindOne(ctx, bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.SMSCampaignStatusSending,
+	}).Decode(&campaign)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find sms campaign: %w", err)
+	}
+
+	return &campaign, nil
+}
+
+func (r *SMSRepository) UpdateCampaign(campaign *Domain.SMSCampaign) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":       campaign.Status,
+			"total":        campaign.Total,
+			"sent":         campaign.Sent,
+			"failed":       campaign.Failed,
+			"skipped":      campaign.Skipped,
+			"completed_at": campaign.CompletedAt,
+		},
+	}
+
+	_, err := r.campaignsCollection.UpdateByID(ctx, campaign.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update sms campaign: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SMSRepository) AddToBlacklist(entry *Domain.SMSBlacklistEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	entry.CreatedAt = time.Now()
+
+	// Upsert so blacklisting the same number twice keeps a single entry
+	filter := bson.M{"business_id": entry.BusinessID, "phone": entry.Phone}
+	update := bson.M{
+		"$setOnInsert": bson.M{
+			"created_by": entry.CreatedBy,
+			"created_at": entry.CreatedAt,
+		},
+		"$set": bson.M{"reason": entry.Reason},
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	if err := r.blacklistCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(entry); err != nil {
+		return fmt.Errorf("failed to add to sms blacklist: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SMSRepository) RemoveFromBlacklist(businessID, phone string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	_, err = r.blacklistCollection.DeleteOne(ctx, bson.M{"business_id": objBusinessID, "phone": phone})
+	if err != nil {
+		return fmt.Errorf("failed to remove from sms blacklist: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SMSRepository) IsBlacklisted(businessID, phone string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return false, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.blacklistCollection.CountDocuments(ctx, bson.M{"business_id": objBusinessID, "phone": phone})
+	if err != nil {
+		return false, fmt.Errorf("failed to check sms blacklist: %w", err)
+	}
+
+	return count > 0, nil
+}
+
+func (r *SMSRepository) GetBlacklist(businessID string) ([]Domain.SMSBlacklistEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.blacklistCollection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"created_at": -1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sms blacklist: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.SMSBlacklistEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode sms blacklist: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,139 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StockLedgerRepository struct {
+	movements *mongo.Collection
+}
+
+func NewStockLedgerRepository(db *mongo.Database) Domain.StockLedgerRepository {
+	return &StockLedgerRepository{
+		movements: db.Collection("stock_movements"),
+	}
+}
+
+func (r *StockLedgerRepository) FindByBusiness(businessID string, filters Domain.StockLedgerFilters) ([]Domain.StockMovement, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.ProductID != nil {
+		objProductID, err := primitive.ObjectIDFromHex(*filters.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %w", err)
+		}
+		query["product_id"] = objProductID
+	}
+
+	if len(filters.Types) > 0 {
+		query["type"] = bson.M{"$in": filters.Types}
+	}
+
+	if filters.ReferenceType != nil {
+		query["reference_type"] = *filters.ReferenceType
+	}
+
+	if filters.ReferenceID != nil {
+		objReferenceID, err := primitive.ObjectIDFromHex(*filters.ReferenceID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid reference ID: %w", err)
+		}
+		query["reference_id"] = objReferenceID
+	}
+
+	if filters.StartDate != nil || filters.EndDate != nil {
+		dateQuery := bson.M{}
+		if filters.StartDate != nil {
+			dateQuery["$gte"] = *filters.StartDate
+		}
+		if filters.EndDate != nil {
+			dateQuery["$lt"] = *filters.EndDate
+		}
+		query["created_at"] = dateQuery
+	}
+
+	// The ledger reaches back past the archive age, so it is read with the archive
+	// through an aggregation, sorted and paged as a find would be
+	opts := options.Find()
+	applyPage(query, opts, filters.Sort, Domain.StockMovementSorts["-created_at"], filters.After)
+
+	pipeline := withArchive("stock_movements", []bson.M{
+		{"$match": query},
+		{"$sort": opts.Sort},
+	})
+	if filters.Limit > 0 {
+		pipeline = append(pipeline, bson.M{"$limit": filters.Limit})
+	}
+
+	cursor, err := r.movements.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock movements: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var movements []Domain.StockMovement
+	if err := cursor.All(ctx, &movements); err != nil {
+		return nil, fmt.Errorf("failed to decode stock movements: %w", err)
+	}
+
+	// Movements recorded before the change was stored
+	for i := range movements {
+		if movements[i].Change == 0 {
+			movements[i].Change = movements[i].New - movements[i].Previous
+		}
+	}
+
+	return movements, nil
+}
+
+func (r *StockLedgerRepository) BalancesAsOf(businessID string, at time.Time) ([]Domain.StockBalance, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	// Each product's balance is the one its last movement left
+	pipeline := withArchive("stock_movements", []bson.M{
+		{"$match": bson.M{"business_id": objBusinessID, "created_at": bson.M{"$lt": at}}},
+		{"$sort": bson.D{{Key: "product_id", Value: 1}, {Key: "created_at", Value: -1}, {Key: "_id", Value: -1}}},
+		{"$group": bson.M{
+			"_id":       "$product_id",
+			"quantity":  bson.M{"$first": "$new"},
+			"unit_cost": bson.M{"$first": "$unit_cost"},
+			"at":        bson.M{"$first": "$created_at"},
+		}},
+		{"$match": bson.M{"quantity": bson.M{"$ne": 0}}},
+	})
+
+	cursor, err := r.movements.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, fmt.Errorf("failed to aggregate stock balances: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var balances []Domain.StockBalance
+	if err := cursor.All(ctx, &balances); err != nil {
+		return nil, fmt.Errorf("failed to decode stock balances: %w", err)
+	}
+
+	return balances, nil
+}
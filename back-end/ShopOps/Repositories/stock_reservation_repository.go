@@ -0,0 +1,194 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StockReservationRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStockReservationRepository(db *mongo.Database) Domain.StockReservationRepository {
+	return &StockReservationRepository{
+		collection: db.Collection("stock_reservations"),
+	}
+}
+
+func (r *StockReservationRepository) Create(reservation *Domain.StockReservation) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reservation.Status = Domain.ReservationActive
+	reservation.CreatedAt = time.Now()
+	reservation.UpdatedAt = reservation.CreatedAt
+
+	result, err := r.collection.InsertOne(ctx, reservation)
+	if err != nil {
+		return fmt.Errorf("failed to create stock reservation: %w", err)
+	}
+
+	reservation.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *StockReservationRepository) FindInBusiness(ctx context.Context, id string) (*Domain.StockReservation, error) {
+	var reservation Domain.StockReservation
+	found, err := findByIDInBusiness(ctx, r.collection, id, &reservation)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &reservation, nil
+}
+
+func (r *StockReservationRepository) FindByBusiness(businessID string, filters Domain.StockReservationFilters) ([]Domain.StockReservation, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.ProductID != nil {
+		objProductID, err := primitive.ObjectIDFromHex(*filters.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid product ID: %w", err)
+		}
+		query["product_id"] = objProductID
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock reservations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var reservations []Domain.StockReservation
+	if err := cursor.All(ctx, &reservations); err != nil {
+		return nil, fmt.Errorf("failed to decode stock reservations: %w", err)
+	}
+
+	return reservations, nil
+}
+
+func (r *StockReservationRepository) ReservedQuantities(businessID string, productIDs []primitive.ObjectID, except *primitive.ObjectID) (map[primitive.ObjectID]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	// Lapsed reservations stop holding stock at once, before ExpireDue gets to them
+	match := bson.M{
+		"business_id": objBusinessID,
+		"status":      Domain.ReservationActive,
+		"expires_at":  bson.M{"$gt": time.Now()},
+	}
+	if productIDs != nil {
+		match["product_id"] = bson.M{"$in": productIDs}
+	}
+	if except != nil {
+		match["_id"] = bson.M{"$ne": *except}
+	}
+
+	pipeline := []bson.M{
+		{"$match": match},
+		{"$group": bson.M{"_id": "$product_id", "reserved": bson.M{"$sum": "$quantity"}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sum stock reservations: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var rows []struct {
+		ProductID primitive.ObjectID `bson:"_id"`
+		Reserved  float64            `bson:"reserved"`
+	}
+	if err := cursor.All(ctx, &rows); err != nil {
+		return nil, fmt.Errorf("failed to decode stock reservations: %w", err)
+	}
+
+	reserved := make(map[primitive.ObjectID]float64, len(rows))
+	for _, row := range rows {
+		reserved[row.ProductID] = row.Reserved
+	}
+	return reserved, nil
+}
+
+func (r *StockReservationRepository) Close(reservation *Domain.StockReservation) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	reservation.UpdatedAt = time.Now()
+
+	filter := bson.M{"_id": reservation.ID, "status": Domain.ReservationActive}
+	update := bson.M{
+		"$set": bson.M{
+			"status":       reservation.Status,
+			"sale_id":      reservation.SaleID,
+			"closed_by":    reservation.ClosedBy,
+			"closed_at":    reservation.ClosedAt,
+			"close_reason": reservation.CloseReason,
+			"updated_at":   reservation.UpdatedAt,
+		},
+	}
+
+	result, err := r.collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, fmt.Errorf("failed to close stock reservation: %w", err)
+	}
+
+	return result.MatchedCount > 0, nil
+}
+
+func (r *StockReservationRepository) ExpireDue(now time.Time) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"status":     Domain.ReservationActive,
+		"expires_at": bson.M{"$lte": now},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"status":     Domain.ReservationExpired,
+			"closed_at":  now,
+			"updated_at": now,
+		},
+	}
+
+	result, err := r.collection.UpdateMany(ctx, filter, update)
+	if err != nil {
+		return 0, fmt.Errorf("failed to expire stock reservations: %w", err)
+	}
+
+	return result.ModifiedCount, nil
+}
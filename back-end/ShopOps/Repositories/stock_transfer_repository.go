@@ -0,0 +1,165 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StockTransferRepository struct {
+	collection *mongo.Collection
+}
+
+func NewStockTransferRepository(db *mongo.Database) Domain.StockTransferRepository {
+	return &StockTransferRepository{
+		collection: db.Collection("stock_transfers"),
+	}
+}
+
+func (r *StockTransferRepository) Create(transfer *Domain.StockTransfer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	transfer.CreatedAt = time.Now()
+	transfer.UpdatedAt = time.Now()
+
+	result, err := r.collection.InsertOne(ctx, transfer)
+	if err != nil {
+		return fmt.Errorf("failed to create stock transfer: %w", err)
+	}
+
+	transfer.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+// FindInBusiness matches either branch, transfers having no business_id of their own
+func (r *StockTransferRepository) FindInBusiness(ctx context.Context, id string) (*Domain.StockTransfer, error) {
+	businessID, err := scopedBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid transfer ID: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	var transfer Domain.StockTransfer
+	err = r.collection.FindOne(ctx, bson.M{
+		"_id": objID,
+		"$or": []bson.M{{"from_business_id": businessID}, {"to_business_id": businessID}},
+	}).Decode(&transfer)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find transfer: %w", err)
+	}
+	return &transfer, nil
+}
+
+func (r *StockTransferRepository) FindByBusiness(businessID string, filters Domain.StockTransferFilters) ([]Domain.StockTransfer, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"$or": bson.A{
+		bson.M{"to_business_id": objBusinessID},
+		bson.M{"from_business_id": objBusinessID},
+	}}
+
+	if filters.Direction != nil {
+		switch *filters.Direction {
+		case Domain.StockTransferIncoming:
+			query = bson.M{"to_business_id": objBusinessID}
+		case Domain.StockTransferOutgoing:
+			query = bson.M{"from_business_id": objBusinessID}
+		}
+	}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find stock transfers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var transfers []Domain.StockTransfer
+	if err := cursor.All(ctx, &transfers); err != nil {
+		return nil, fmt.Errorf("failed to decode stock transfers: %w", err)
+	}
+
+	return transfers, nil
+}
+
+func (r *StockTransferRepository) CountRequested(businessID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.collection.CountDocuments(ctx, bson.M{"to_business_id": objBusinessID})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count stock transfers: %w", err)
+	}
+
+	return count, nil
+}
+
+func (r *StockTransferRepository) Update(transfer *Domain.StockTransfer) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	transfer.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":      transfer.Status,
+			"lines":       transfer.Lines,
+			"reason":      transfer.Reason,
+			"approved_by": transfer.ApprovedBy,
+			"approved_at": transfer.ApprovedAt,
+			"shipped_by":  transfer.ShippedBy,
+			"shipped_at":  transfer.ShippedAt,
+			"received_by": transfer.ReceivedBy,
+			"received_at": transfer.ReceivedAt,
+			"closed_by":   transfer.ClosedBy,
+			"updated_at":  transfer.UpdatedAt,
+		},
+	}
+
+	if err := updateVersioned(ctx, r.collection, transfer.ID, transfer.Version, update, "stock transfer"); err != nil {
+		return err
+	}
+
+	transfer.Version++
+	return nil
+}
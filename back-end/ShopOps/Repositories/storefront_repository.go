@@ -0,0 +1,371 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type StorefrontRepository struct {
+	connectionsCollection *mongo.Collection
+	mappingsCollection    *mongo.Collection
+	ordersCollection      *mongo.Collection
+}
+
+func NewStorefrontRepository(db *mongo.Database) Domain.StorefrontRepository {
+	return &StorefrontRepository{
+		connectionsCollection: db.Collection("storefront_connections"),
+		mappingsCollection:    db.Collection("storefront_mappings"),
+		ordersCollection:      db.Collection("storefront_orders"),
+	}
+}
+
+func (r *StorefrontRepository) CreateConnection(connection *Domain.StorefrontConnection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	connection.CreatedAt = time.Now()
+	connection.UpdatedAt = connection.CreatedAt
+
+	result, err := r.connectionsCollection.InsertOne(ctx, connection)
+	if err != nil {
+		return fmt.Errorf("failed to create storefront connection: %w", err)
+	}
+
+	connection.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *StorefrontRepository) FindConnectionByID(id string) (*Domain.StorefrontConnection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storefront connection ID: %w", err)
+	}
+
+	var connection Domain.StorefrontConnection
+	err = r.connectionsCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&connection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find storefront connection: %w", err)
+	}
+
+	return &connection, nil
+}
+
+func (r *StorefrontRepository) FindConnections(businessID string) ([]Domain.StorefrontConnection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := r.connectionsCollection.Find(ctx, bson.M{"business_id": objBusinessID}, options.Find().SetSort(bson.M{"created_at": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find storefront connections: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var connections []Domain.StorefrontConnection
+	if err := cursor.All(ctx, &connections); err != nil {
+		return nil, fmt.Errorf("failed to decode storefront connections: %w", err)
+	}
+
+	return connections, nil
+}
+
+func (r *StorefrontRepository) UpdateConnection(connection *Domain.StorefrontConnection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	connection.UpdatedAt = time.Now()
+
+	// Sync progress is left out: it only changes through RecordSync, which a sync
+	// running meanwhile may call
+	update := bson.M{
+		"$set": bson.M{
+			"name":         connection.Name,
+			"api_key":      connection.APIKey,
+			"api_secret":   connection.APISecret,
+			"location_id":  connection.LocationID,
+			"push_catalog": connection.PushCatalog,
+			"push_stock":   connection.PushStock,
+			"pull_orders":  connection.PullOrders,
+			"stock_buffer": connection.StockBuffer,
+			"active":       connection.Active,
+			"updated_at":   connection.UpdatedAt,
+		},
+	}
+
+	if _, err := r.connectionsCollection.UpdateOne(ctx, bson.M{"_id": connection.ID}, update); err != nil {
+		return fmt.Errorf("failed to update storefront connection: %w", err)
+	}
+	return nil
+}
+
+func (r *StorefrontRepository) DeleteConnection(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid storefront connection ID: %w", err)
+	}
+
+	if _, err := r.connectionsCollection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete storefront connection: %w", err)
+	}
+	if _, err := r.mappingsCollection.DeleteMany(ctx, bson.M{"connection_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete storefront mappings: %w", err)
+	}
+	return nil
+}
+
+func (r *StorefrontRepository) ClaimDueConnection(now time.Time) (*Domain.StorefrontConnection, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{"active": true, "next_sync_at": bson.M{"$lte": now}}
+	update := bson.M{"$set": bson.M{"next_sync_at": now.Add(Domain.StorefrontSyncInterval)}}
+	opts := options.FindOneAndUpdate().
+		SetSort(bson.M{"next_sync_at": 1}).
+		SetReturnDocument(options.After)
+
+	var connection Domain.StorefrontConnection
+	err := r.connectionsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(&connection)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to claim storefront connection: %w", err)
+	}
+
+	return &connection, nil
+}
+
+func (r *StorefrontRepository) ScheduleSync(id primitive.ObjectID, at time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.connectionsCollection.UpdateOne(ctx, bson.M{"_id": id}, bson.M{"$set": bson.M{"next_sync_at": at}}); err != nil {
+		return fmt.Errorf("failed to schedule storefront sync: %w", err)
+	}
+	return nil
+}
+
+func (r *StorefrontRepository) RecordSync(id primitive.ObjectID, ordersSince, at time.Time, result Domain.StorefrontSyncStats, lastError string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	update := bson.M{
+		"$set": bson.M{
+			"orders_since": ordersSince,
+			"last_sync_at": at,
+			"last_result":  result,
+			"last_error":   lastError,
+		},
+	}
+
+	if _, err := r.connectionsCollection.UpdateOne(ctx, bson.M{"_id": id}, update); err != nil {
+		return fmt.Errorf("failed to record storefront sync: %w", err)
+	}
+	return nil
+}
+
+func (r *StorefrontRepository) FindMappings(connectionID primitive.ObjectID) ([]Domain.StorefrontMapping, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := r.mappingsCollection.Find(ctx, bson.M{"connection_id": connectionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find storefront mappings: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var mappings []Domain.StorefrontMapping
+	if err := cursor.All(ctx, &mappings); err != nil {
+		return nil, fmt.Errorf("failed to decode storefront mappings: %w", err)
+	}
+
+	return mappings, nil
+}
+
+func (r *StorefrontRepository) FindMappingByRemote(connectionID primitive.ObjectID, remoteProductID, remoteVariantID string) (*Domain.StorefrontMapping, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{"connection_id": connectionID, "remote_product_id": remoteProductID}
+	if remoteVariantID != "" {
+		query["remote_variant_id"] = remoteVariantID
+	}
+
+	var mapping Domain.StorefrontMapping
+	err := r.mappingsCollection.FindOne(ctx, query).Decode(&mapping)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find storefront mapping: %w", err)
+	}
+
+	return &mapping, nil
+}
+
+func (r *StorefrontRepository) SaveMapping(mapping *Domain.StorefrontMapping) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	mapping.UpdatedAt = now
+
+	filter := bson.M{"connection_id": mapping.ConnectionID, "product_id": mapping.ProductID}
+	update := bson.M{
+		"$set": bson.M{
+			"business_id":         mapping.BusinessID,
+			"remote_product_id":   mapping.RemoteProductID,
+			"remote_variant_id":   mapping.RemoteVariantID,
+			"remote_inventory_id": mapping.RemoteInventoryID,
+			"last_pushed_stock":   mapping.LastPushedStock,
+			"last_pushed_price":   mapping.LastPushedPrice,
+			"last_pushed_name":    mapping.LastPushedName,
+			"last_pushed_at":      mapping.LastPushedAt,
+			"updated_at":          mapping.UpdatedAt,
+		},
+		"$setOnInsert": bson.M{"created_at": now},
+	}
+	opts := options.FindOneAndUpdate().
+		SetUpsert(true).
+		SetReturnDocument(options.After)
+
+	if err := r.mappingsCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(mapping); err != nil {
+		return fmt.Errorf("failed to save storefront mapping: %w", err)
+	}
+	return nil
+}
+
+func (r *StorefrontRepository) DeleteMapping(connectionID primitive.ObjectID, productID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	result, err := r.mappingsCollection.DeleteOne(ctx, bson.M{"connection_id": connectionID, "product_id": objProductID})
+	if err != nil {
+		return fmt.Errorf("failed to delete storefront mapping: %w", err)
+	}
+	if result.DeletedCount == 0 {
+		return Domain.NotFoundError("product is not mapped on this store")
+	}
+	return nil
+}
+
+func (r *StorefrontRepository) CreateOrder(order *Domain.StorefrontOrder) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	order.CreatedAt = time.Now()
+	order.UpdatedAt = order.CreatedAt
+
+	result, err := r.ordersCollection.InsertOne(ctx, order)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to create storefront order: %w", err)
+	}
+
+	order.ID = result.InsertedID.(primitive.ObjectID)
+	return true, nil
+}
+
+func (r *StorefrontRepository) FindOrderByID(id string) (*Domain.StorefrontOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid storefront order ID: %w", err)
+	}
+
+	var order Domain.StorefrontOrder
+	err = r.ordersCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&order)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find storefront order: %w", err)
+	}
+
+	return &order, nil
+}
+
+func (r *StorefrontRepository) FindOrders(connectionID primitive.ObjectID, filters Domain.StorefrontOrderFilters) ([]Domain.StorefrontOrder, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{"connection_id": connectionID}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.ordersCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find storefront orders: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var orders []Domain.StorefrontOrder
+	if err := cursor.All(ctx, &orders); err != nil {
+		return nil, fmt.Errorf("failed to decode storefront orders: %w", err)
+	}
+
+	return orders, nil
+}
+
+func (r *StorefrontRepository) UpdateOrder(order *Domain.StorefrontOrder) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	order.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"status":      order.Status,
+			"lines":       order.Lines,
+			"resolved_by": order.ResolvedBy,
+			"resolved_at": order.ResolvedAt,
+			"updated_at":  order.UpdatedAt,
+		},
+	}
+
+	if _, err := r.ordersCollection.UpdateOne(ctx, bson.M{"_id": order.ID}, update); err != nil {
+		return fmt.Errorf("failed to update storefront order: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,243 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SubscriptionRepository struct {
+	collection        *mongo.Collection
+	eventsCollection  *mongo.Collection
+	devicesCollection *mongo.Collection
+	usageCollection   *mongo.Collection
+}
+
+func NewSubscriptionRepository(db *mongo.Database) Domain.SubscriptionRepository {
+	return &SubscriptionRepository{
+		collection:        db.Collection("subscriptions"),
+		eventsCollection:  db.Collection("billing_events"),
+		devicesCollection: db.Collection("billing_devices"),
+		usageCollection:   db.Collection("plan_usage"),
+	}
+}
+
+func (r *SubscriptionRepository) FindByBusiness(businessID string) (*Domain.Subscription, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	return r.findOne(bson.M{"business_id": objBusinessID})
+}
+
+func (r *SubscriptionRepository) FindByProviderSubscription(provider Domain.BillingProvider, subscriptionID string) (*Domain.Subscription, error) {
+	return r.findOne(bson.M{"provider": provider, "provider_subscription_id": subscriptionID})
+}
+
+func (r *SubscriptionRepository) findOne(query bson.M) (*Domain.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	var subscription Domain.Subscription
+	err := r.collection.FindOne(ctx, query).Decode(&subscription)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find subscription: %w", err)
+	}
+
+	return &subscription, nil
+}
+
+func (r *SubscriptionRepository) Create(subscription *Domain.Subscription) (*Domain.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	subscription.CreatedAt = now
+	subscription.UpdatedAt = now
+
+	// Two first requests racing each other both get the one subscription
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var stored Domain.Subscription
+	err := r.collection.FindOneAndUpdate(ctx,
+		bson.M{"business_id": subscription.BusinessID},
+		bson.M{"$setOnInsert": subscription},
+		opts,
+	).Decode(&stored)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create subscription: %w", err)
+	}
+
+	return &stored, nil
+}
+
+func (r *SubscriptionRepository) Update(subscription *Domain.Subscription) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	subscription.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"plan":                     subscription.Plan,
+			"status":                   subscription.Status,
+			"provider":                 subscription.Provider,
+			"provider_customer_id":     subscription.ProviderCustomerID,
+			"provider_subscription_id": subscription.ProviderSubscriptionID,
+			"trial_ends_at":            subscription.TrialEndsAt,
+			"current_period_end":       subscription.CurrentPeriodEnd,
+			"grace_ends_at":            subscription.GraceEndsAt,
+			"cancel_at_period_end":     subscription.CancelAtPeriodEnd,
+			"canceled_at":              subscription.CanceledAt,
+			"updated_at":               subscription.UpdatedAt,
+		},
+	}
+
+	if _, err := r.collection.UpdateByID(ctx, subscription.ID, update); err != nil {
+		return fmt.Errorf("failed to update subscription: %w", err)
+	}
+	return nil
+}
+
+func (r *SubscriptionRepository) FindLapsing(now time.Time, limit int) ([]Domain.Subscription, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	query := bson.M{
+		"$or": []bson.M{
+			{"status": Domain.SubscriptionTrialing, "trial_ends_at": bson.M{"$lt": now}},
+			{"status": bson.M{"$in": []Domain.SubscriptionStatus{Domain.SubscriptionActive, Domain.SubscriptionCanceled}}, "current_period_end": bson.M{"$lt": now}},
+			{"status": Domain.SubscriptionPastDue, "grace_ends_at": bson.M{"$lt": now}},
+		},
+	}
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetLimit(int64(limit)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find lapsing subscriptions: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var subscriptions []Domain.Subscription
+	if err := cursor.All(ctx, &subscriptions); err != nil {
+		return nil, fmt.Errorf("failed to decode subscriptions: %w", err)
+	}
+
+	return subscriptions, nil
+}
+
+func (r *SubscriptionRepository) RecordEvent(provider Domain.BillingProvider, eventID string) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	_, err := r.eventsCollection.InsertOne(ctx, bson.M{
+		"_id":         string(provider) + ":" + eventID,
+		"received_at": time.Now(),
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to record billing event: %w", err)
+	}
+	return true, nil
+}
+
+func (r *SubscriptionRepository) ForgetEvent(provider Domain.BillingProvider, eventID string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.eventsCollection.DeleteOne(ctx, bson.M{"_id": string(provider) + ":" + eventID}); err != nil {
+		return fmt.Errorf("failed to forget billing event: %w", err)
+	}
+	return nil
+}
+
+func (r *SubscriptionRepository) TouchDevice(businessID, deviceID string, since, now time.Time) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return false, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	result, err := r.devicesCollection.UpdateOne(ctx,
+		bson.M{"business_id": objBusinessID, "device_id": deviceID, "last_seen": bson.M{"$gte": since}},
+		bson.M{"$set": bson.M{"last_seen": now}},
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to update device: %w", err)
+	}
+	return result.MatchedCount > 0, nil
+}
+
+func (r *SubscriptionRepository) AddDevice(businessID, deviceID string, now time.Time) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	_, err = r.devicesCollection.UpdateOne(ctx,
+		bson.M{"business_id": objBusinessID, "device_id": deviceID},
+		bson.M{
+			"$set":         bson.M{"last_seen": now},
+			"$setOnInsert": bson.M{"first_seen": now},
+		},
+		options.Update().SetUpsert(true),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to add device: %w", err)
+	}
+	return nil
+}
+
+func (r *SubscriptionRepository) CountDevices(businessID string, since time.Time) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	count, err := r.devicesCollection.CountDocuments(ctx, bson.M{"business_id": objBusinessID, "last_seen": bson.M{"$gte": since}})
+	if err != nil {
+		return 0, fmt.Errorf("failed to count devices: %w", err)
+	}
+	return int(count), nil
+}
+
+func (r *SubscriptionRepository) UseQuota(businessID string, quota Domain.PlanQuota, day string) (int, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	var usage struct {
+		Count int `bson:"count"`
+	}
+	err = r.usageCollection.FindOneAndUpdate(ctx,
+		bson.M{"business_id": objBusinessID, "quota": quota, "day": day},
+		bson.M{"$inc": bson.M{"count": 1}, "$setOnInsert": bson.M{"created_at": time.Now()}},
+		opts,
+	).Decode(&usage)
+	if err != nil {
+		return 0, fmt.Errorf("failed to record plan usage: %w", err)
+	}
+	return usage.Count, nil
+}
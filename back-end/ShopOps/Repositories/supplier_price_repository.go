@@ -0,0 +1,101 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SupplierPriceRepository struct {
+	collection *mongo.Collection
+}
+
+func NewSupplierPriceRepository(db *mongo.Database) Domain.SupplierPriceRepository {
+	return &SupplierPriceRepository{
+		collection: db.Collection("supplier_prices"),
+	}
+}
+
+func (r *SupplierPriceRepository) RecordPurchase(purchase Domain.SupplierPurchase) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	filter := bson.M{
+		"business_id": purchase.BusinessID,
+		"supplier_id": purchase.SupplierID,
+		"product_id":  purchase.ProductID,
+	}
+
+	// One stage, so every expression sees the document as it was before this purchase
+	set := bson.M{
+		"previous_unit_cost": "$last_unit_cost",
+		"last_unit_cost":     purchase.UnitCost,
+		"last_purchased_at":  purchase.PurchasedAt,
+		"last_order_id":      purchase.OrderID,
+		"last_order_number":  purchase.OrderNumber,
+		"purchases":          bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$purchases", 0}}, 1}},
+		"updated_at":         time.Now(),
+	}
+	if purchase.LeadTimeDays != nil {
+		orders := bson.M{"$ifNull": bson.A{"$lead_time_orders", 0}}
+		total := bson.M{"$multiply": bson.A{bson.M{"$ifNull": bson.A{"$lead_time_days", 0}}, orders}}
+		set["lead_time_days"] = bson.M{"$divide": bson.A{
+			bson.M{"$add": bson.A{total, *purchase.LeadTimeDays}},
+			bson.M{"$add": bson.A{orders, 1}},
+		}}
+		set["lead_time_orders"] = bson.M{"$add": bson.A{orders, 1}}
+	}
+
+	_, err := r.collection.UpdateOne(ctx, filter, mongo.Pipeline{{{Key: "$set", Value: set}}}, options.Update().SetUpsert(true))
+	if err != nil {
+		return fmt.Errorf("failed to record supplier price: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SupplierPriceRepository) FindByProduct(businessID, productID string) ([]Domain.SupplierPrice, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	objProductID, err := primitive.ObjectIDFromHex(productID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid product ID: %w", err)
+	}
+
+	return r.find(bson.M{"business_id": objBusinessID, "product_id": objProductID})
+}
+
+func (r *SupplierPriceRepository) FindByBusiness(businessID string) ([]Domain.SupplierPrice, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return r.find(bson.M{"business_id": objBusinessID})
+}
+
+func (r *SupplierPriceRepository) find(query bson.M) ([]Domain.SupplierPrice, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	cursor, err := r.collection.Find(ctx, query, options.Find().SetSort(bson.M{"last_unit_cost": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supplier prices: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var prices []Domain.SupplierPrice
+	if err := cursor.All(ctx, &prices); err != nil {
+		return nil, fmt.Errorf("failed to decode supplier prices: %w", err)
+	}
+
+	return prices, nil
+}
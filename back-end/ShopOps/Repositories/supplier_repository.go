@@ -0,0 +1,230 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SupplierRepository struct {
+	suppliersCollection *mongo.Collection
+	payablesCollection  *mongo.Collection
+}
+
+func NewSupplierRepository(db *mongo.Database) Domain.SupplierRepository {
+	return &SupplierRepository{
+		suppliersCollection: db.Collection("suppliers"),
+		payablesCollection:  db.Collection("supplier_payables"),
+	}
+}
+
+func (r *SupplierRepository) Create(supplier *Domain.Supplier) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	supplier.Status = Domain.SupplierStatusActive
+	supplier.CreatedAt = time.Now()
+	supplier.UpdatedAt = time.Now()
+
+	result, err := r.suppliersCollection.InsertOne(ctx, supplier)
+	if err != nil {
+		return fmt.Errorf("failed to create supplier: %w", err)
+	}
+
+	supplier.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SupplierRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Supplier, error) {
+	var supplier Domain.Supplier
+	found, err := findByIDInBusiness(ctx, r.suppliersCollection, id, &supplier)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &supplier, nil
+}
+
+func (r *SupplierRepository) FindByBusinessID(businessID string, status *Domain.SupplierStatus, search *string) ([]Domain.Supplier, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID, "deleted_at": notDeleted}
+
+	if status != nil {
+		query["status"] = *status
+	}
+
+	if search != nil && *search != "" {
+		pattern := primitive.Regex{Pattern: regexp.QuoteMeta(*search), Options: "i"}
+		query["$or"] = []bson.M{
+			{"name": pattern},
+			{"contact_name": pattern},
+			{"phone": pattern},
+		}
+	}
+
+	cursor, err := r.suppliersCollection.Find(ctx, query, options.Find().SetSort(bson.M{"name": 1}))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find suppliers: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var suppliers []Domain.Supplier
+	if err := cursor.All(ctx, &suppliers); err != nil {
+		return nil, fmt.Errorf("failed to decode suppliers: %w", err)
+	}
+
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) Update(supplier *Domain.Supplier) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	supplier.UpdatedAt = time.Now()
+
+	// Balance is only ever changed through the payables ledger
+	update := bson.M{
+		"$set": bson.M{
+			"name":               supplier.Name,
+			"contact_name":       supplier.ContactName,
+			"phone":              supplier.Phone,
+			"email":              supplier.Email,
+			"address":            supplier.Address,
+			"tin":                supplier.TIN,
+			"payment_terms_days": supplier.PaymentTermsDays,
+			"lead_time_days":     supplier.LeadTimeDays,
+			"notes":              supplier.Notes,
+			"status":             supplier.Status,
+			"custom_fields":      supplier.CustomFields,
+			"updated_at":         supplier.UpdatedAt,
+		},
+	}
+
+	if err := updateVersioned(ctx, r.suppliersCollection, supplier.ID, supplier.Version, update, "supplier"); err != nil {
+		return err
+	}
+	supplier.Version++
+
+	return nil
+}
+
+func (r *SupplierRepository) Delete(id string) error {
+	return setDeleted(r.suppliersCollection, id, true, nil)
+}
+
+func (r *SupplierRepository) FindDeleted(businessID string) ([]Domain.Supplier, error) {
+	var suppliers []Domain.Supplier
+	if err := findDeleted(r.suppliersCollection, businessID, &suppliers); err != nil {
+		return nil, err
+	}
+	return suppliers, nil
+}
+
+func (r *SupplierRepository) Restore(id string) error {
+	return setDeleted(r.suppliersCollection, id, false, nil)
+}
+
+func (r *SupplierRepository) PurgeDeleted(before time.Time, limit int) (map[string]int64, error) {
+	return purgeDeleted(r.suppliersCollection, before, limit)
+}
+
+func (r *SupplierRepository) AddPayableEntry(entry *Domain.PayableEntry) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	delta := entry.Amount
+	if entry.Type != Domain.PayableEntryInvoice {
+		delta = -entry.Amount
+	}
+
+	var supplier Domain.Supplier
+	err := r.suppliersCollection.FindOneAndUpdate(ctx,
+		bson.M{"_id": entry.SupplierID},
+		bson.M{
+			"$inc": bson.M{"balance": delta},
+			"$set": bson.M{"updated_at": time.Now()},
+		},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&supplier)
+	if err != nil {
+		return fmt.Errorf("failed to update supplier balance: %w", err)
+	}
+
+	entry.BalanceAfter = supplier.Balance
+	entry.CreatedAt = time.Now()
+
+	result, err := r.payablesCollection.InsertOne(ctx, entry)
+	if err != nil {
+		return fmt.Errorf("failed to record payable entry: %w", err)
+	}
+
+	entry.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *SupplierRepository) GetPayableEntries(supplierID string, limit int) ([]Domain.PayableEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objSupplierID, err := primitive.ObjectIDFromHex(supplierID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid supplier ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: -1}, {Key: "created_at", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.payablesCollection.Find(ctx, bson.M{"supplier_id": objSupplierID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payable entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.PayableEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode payable entries: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *SupplierRepository) GetBusinessPayableEntries(businessID string) ([]Domain.PayableEntry, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	// Oldest first so payments can be allocated to invoices in order
+	opts := options.Find().SetSort(bson.D{{Key: "date", Value: 1}, {Key: "created_at", Value: 1}})
+
+	cursor, err := r.payablesCollection.Find(ctx, bson.M{"business_id": objBusinessID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find payable entries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var entries []Domain.PayableEntry
+	if err := cursor.All(ctx, &entries); err != nil {
+		return nil, fmt.Errorf("failed to decode payable entries: %w", err)
+	}
+
+	return entries, nil
+}
@@ -0,0 +1,295 @@
+package Repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// errTabUnchanged aborts a tab write whose tab no longer matches, giving back the
+// revision it took
+var errTabUnchanged = errors.New("tab unchanged")
+
+type TabRepository struct {
+	collection *mongo.Collection
+	revisions  *mongo.Collection
+	outbox     *outbox
+}
+
+func NewTabRepository(db *mongo.Database) Domain.TabRepository {
+	return &TabRepository{
+		collection: db.Collection("tabs"),
+		revisions:  db.Collection("tab_revisions"),
+		outbox:     newOutbox(db),
+	}
+}
+
+// nextRevision takes the shop's next tab revision. Within a transaction the
+// counter is locked until commit, so a later revision cannot be seen first.
+func (r *TabRepository) nextRevision(ctx context.Context, businessID primitive.ObjectID) (int64, error) {
+	var counter struct {
+		Revision int64 `bson:"revision"`
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := r.revisions.FindOneAndUpdate(ctx, bson.M{"_id": businessID}, bson.M{"$inc": bson.M{"revision": 1}}, opts).Decode(&counter)
+	if err != nil {
+		return 0, fmt.Errorf("failed to take tab revision: %w", err)
+	}
+	return counter.Revision, nil
+}
+
+// write applies update to the tab if it still matches filter, under the shop's
+// next tab revision. False when it does not match.
+func (r *TabRepository) write(tab *Domain.Tab, filter, update bson.M, opts ...*options.UpdateOptions) (bool, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	set, _ := update["$set"].(bson.M)
+	if set == nil {
+		set = bson.M{}
+		update["$set"] = set
+	}
+	set["updated_at"] = now
+	filter["_id"] = tab.ID
+
+	var revision int64
+	err := r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		var err error
+		if revision, err = r.nextRevision(ctx, tab.BusinessID); err != nil {
+			return nil, err
+		}
+		set["revision"] = revision
+
+		result, err := r.collection.UpdateOne(ctx, filter, update, opts...)
+		if err != nil {
+			return nil, fmt.Errorf("failed to update tab: %w", err)
+		}
+		if result.MatchedCount == 0 {
+			return nil, errTabUnchanged
+		}
+		return nil, nil
+	})
+	if errors.Is(err, errTabUnchanged) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	tab.Revision = revision
+	tab.UpdatedAt = now
+	return true, nil
+}
+
+func (r *TabRepository) Create(tab *Domain.Tab) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	tab.ID = primitive.NewObjectID()
+	tab.CreatedAt = time.Now()
+	tab.UpdatedAt = tab.CreatedAt
+
+	return r.outbox.Write(ctx, func(ctx context.Context) ([]*Domain.DomainEvent, error) {
+		var err error
+		if tab.Revision, err = r.nextRevision(ctx, tab.BusinessID); err != nil {
+			return nil, err
+		}
+		if _, err := r.collection.InsertOne(ctx, tab); err != nil {
+			return nil, fmt.Errorf("failed to create tab: %w", err)
+		}
+		return nil, nil
+	})
+}
+
+func (r *TabRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Tab, error) {
+	var tab Domain.Tab
+	found, err := findByIDInBusiness(ctx, r.collection, id, &tab)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &tab, nil
+}
+
+func (r *TabRepository) FindByBusiness(businessID string, filters Domain.TabFilters) ([]Domain.Tab, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID}
+
+	if filters.Status != nil {
+		query["status"] = *filters.Status
+	}
+
+	if filters.Table != nil {
+		query["table"] = *filters.Table
+	}
+
+	if filters.EmployeeID != nil {
+		objEmployeeID, err := primitive.ObjectIDFromHex(*filters.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid employee ID: %w", err)
+		}
+		query["employee_id"] = objEmployeeID
+	}
+
+	opts := options.Find().SetSort(bson.M{"created_at": -1})
+
+	if filters.Limit > 0 {
+		opts.SetLimit(int64(filters.Limit))
+	}
+
+	if filters.Offset > 0 {
+		opts.SetSkip(int64(filters.Offset))
+	}
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find tabs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tabs []Domain.Tab
+	if err := cursor.All(ctx, &tabs); err != nil {
+		return nil, fmt.Errorf("failed to decode tabs: %w", err)
+	}
+
+	return tabs, nil
+}
+
+func (r *TabRepository) FindChanged(businessID string, revision int64, limit int) ([]Domain.Tab, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	query := bson.M{"business_id": objBusinessID, "revision": bson.M{"$gt": revision}}
+	opts := options.Find().SetSort(bson.M{"revision": 1}).SetLimit(int64(limit))
+
+	cursor, err := r.collection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find changed tabs: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var tabs []Domain.Tab
+	if err := cursor.All(ctx, &tabs); err != nil {
+		return nil, fmt.Errorf("failed to decode tabs: %w", err)
+	}
+
+	return tabs, nil
+}
+
+func (r *TabRepository) Revision(businessID string) (int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return 0, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var counter struct {
+		Revision int64 `bson:"revision"`
+	}
+	err = r.revisions.FindOne(ctx, bson.M{"_id": objBusinessID}).Decode(&counter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return 0, nil
+		}
+		return 0, fmt.Errorf("failed to find tab revision: %w", err)
+	}
+
+	return counter.Revision, nil
+}
+
+func (r *TabRepository) Update(tab *Domain.Tab) (bool, error) {
+	return r.write(tab, bson.M{"status": Domain.TabOpen}, bson.M{
+		"$set": bson.M{
+			"table":       tab.Table,
+			"guests":      tab.Guests,
+			"employee_id": tab.EmployeeID,
+			"notes":       tab.Notes,
+		},
+	})
+}
+
+func (r *TabRepository) AddItems(tab *Domain.Tab, items []Domain.TabItem) (bool, error) {
+	filter := bson.M{"status": Domain.TabOpen, "payments.0": bson.M{"$exists": false}}
+	return r.write(tab, filter, bson.M{
+		"$push": bson.M{"items": bson.M{"$each": items}},
+		"$set":  bson.M{"bills": bson.A{}},
+	})
+}
+
+func (r *TabRepository) RemoveItem(tab *Domain.Tab, itemID primitive.ObjectID) (bool, error) {
+	filter := bson.M{"status": Domain.TabOpen, "payments.0": bson.M{"$exists": false}, "items._id": itemID}
+	return r.write(tab, filter, bson.M{
+		"$pull": bson.M{"items": bson.M{"_id": itemID}},
+		"$set":  bson.M{"bills": bson.A{}},
+	})
+}
+
+func (r *TabRepository) SetBills(tab *Domain.Tab, bills []Domain.TabBill) (bool, error) {
+	filter := bson.M{"status": Domain.TabOpen, "revision": tab.Revision}
+	return r.write(tab, filter, bson.M{"$set": bson.M{"bills": bills}})
+}
+
+func (r *TabRepository) AddPayments(tab *Domain.Tab, payments []Domain.TabPayment) (bool, error) {
+	filter := bson.M{"status": Domain.TabOpen, "revision": tab.Revision}
+	return r.write(tab, filter, bson.M{"$push": bson.M{"payments": bson.M{"$each": payments}}})
+}
+
+func (r *TabRepository) RemovePayment(tab *Domain.Tab, paymentID primitive.ObjectID) (bool, error) {
+	filter := bson.M{
+		"status":        Domain.TabOpen,
+		"payments._id":  paymentID,
+		"items.sale_id": bson.M{"$exists": false},
+	}
+	return r.write(tab, filter, bson.M{"$pull": bson.M{"payments": bson.M{"_id": paymentID}}})
+}
+
+func (r *TabRepository) SetSales(tab *Domain.Tab, sales map[primitive.ObjectID]primitive.ObjectID) error {
+	set := bson.M{}
+	var arrayFilters []interface{}
+	for itemID, saleID := range sales {
+		name := fmt.Sprintf("i%d", len(arrayFilters))
+		set["items.$["+name+"].sale_id"] = saleID
+		arrayFilters = append(arrayFilters, bson.M{name + "._id": itemID})
+	}
+	opts := options.Update().SetArrayFilters(options.ArrayFilters{Filters: arrayFilters})
+
+	updated, err := r.write(tab, bson.M{}, bson.M{"$set": set}, opts)
+	if err != nil {
+		return fmt.Errorf("failed to record tab sales: %w", err)
+	}
+	if !updated {
+		return Domain.NotFoundError("tab not found")
+	}
+	return nil
+}
+
+func (r *TabRepository) Close(tab *Domain.Tab, status Domain.TabStatus, closedBy primitive.ObjectID, at time.Time) (bool, error) {
+	return r.write(tab, bson.M{"status": Domain.TabOpen}, bson.M{
+		"$set": bson.M{
+			"status":    status,
+			"closed_by": closedBy,
+			"closed_at": at,
+		},
+	})
+}
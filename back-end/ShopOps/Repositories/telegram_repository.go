@@ -0,0 +1,185 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TelegramRepository struct {
+	linksCollection *mongo.Collection
+	codesCollection *mongo.Collection
+}
+
+func NewTelegramRepository(db *mongo.Database) Domain.TelegramRepository {
+	return &TelegramRepository{
+		linksCollection: db.Collection("telegram_links"),
+		codesCollection: db.Collection("telegram_link_codes"),
+	}
+}
+
+func (r *TelegramRepository) CreateCode(code *Domain.TelegramLinkCode) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.codesCollection.InsertOne(ctx, code); err != nil {
+		return fmt.Errorf("failed to create telegram link code: %w", err)
+	}
+	return nil
+}
+
+func (r *TelegramRepository) ConsumeCode(code string) (*Domain.TelegramLinkCode, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// Deleted as it is read, so a code links one chat however many times it is sent
+	var linkCode Domain.TelegramLinkCode
+	err := r.codesCollection.FindOneAndDelete(ctx, bson.M{
+		"_id":        code,
+		"expires_at": bson.M{"$gt": time.Now()},
+	}).Decode(&linkCode)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to consume telegram link code: %w", err)
+	}
+
+	return &linkCode, nil
+}
+
+func (r *TelegramRepository) SaveLink(link *Domain.TelegramLink) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	now := time.Now()
+	link.UpdatedAt = now
+
+	filter := bson.M{"business_id": link.BusinessID, "chat_id": link.ChatID}
+	update := bson.M{
+		"$set": bson.M{
+			"chat_name":  link.ChatName,
+			"linked_by":  link.LinkedBy,
+			"updated_at": now,
+		},
+		"$setOnInsert": bson.M{
+			"notify":     link.Notify,
+			"created_at": now,
+		},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	if err := r.linksCollection.FindOneAndUpdate(ctx, filter, update, opts).Decode(link); err != nil {
+		return fmt.Errorf("failed to save telegram link: %w", err)
+	}
+	return nil
+}
+
+func (r *TelegramRepository) FindLinkByID(id string) (*Domain.TelegramLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid telegram link ID: %w", err)
+	}
+
+	var link Domain.TelegramLink
+	err = r.linksCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&link)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find telegram link: %w", err)
+	}
+
+	return &link, nil
+}
+
+func (r *TelegramRepository) FindLinksByBusiness(businessID string) ([]Domain.TelegramLink, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	return r.find(bson.M{"business_id": objBusinessID})
+}
+
+func (r *TelegramRepository) FindLinksByChat(chatID int64) ([]Domain.TelegramLink, error) {
+	return r.find(bson.M{"chat_id": chatID})
+}
+
+func (r *TelegramRepository) FindSummaryLinks() ([]Domain.TelegramLink, error) {
+	return r.find(bson.M{"notify.daily_summary": true})
+}
+
+func (r *TelegramRepository) find(query bson.M) ([]Domain.TelegramLink, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.linksCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find telegram links: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var links []Domain.TelegramLink
+	if err := cursor.All(ctx, &links); err != nil {
+		return nil, fmt.Errorf("failed to decode telegram links: %w", err)
+	}
+
+	return links, nil
+}
+
+func (r *TelegramRepository) UpdateLink(link *Domain.TelegramLink) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	link.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"notify":            link.Notify,
+			"last_summary_date": link.LastSummaryDate,
+			"updated_at":        link.UpdatedAt,
+		},
+	}
+
+	_, err := r.linksCollection.UpdateByID(ctx, link.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update telegram link: %w", err)
+	}
+
+	return nil
+}
+
+func (r *TelegramRepository) DeleteLink(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid telegram link ID: %w", err)
+	}
+
+	if _, err := r.linksCollection.DeleteOne(ctx, bson.M{"_id": objID}); err != nil {
+		return fmt.Errorf("failed to delete telegram link: %w", err)
+	}
+	return nil
+}
+
+func (r *TelegramRepository) DeleteLinksByChat(chatID int64) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	if _, err := r.linksCollection.DeleteMany(ctx, bson.M{"chat_id": chatID}); err != nil {
+		return fmt.Errorf("failed to delete telegram links: %w", err)
+	}
+	return nil
+}
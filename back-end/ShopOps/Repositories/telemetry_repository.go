@@ -0,0 +1,69 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TelemetryRepository struct {
+	bridgesCollection *mongo.Collection
+	devicesCollection *mongo.Collection
+}
+
+func NewTelemetryRepository(db *mongo.Database) Domain.TelemetryRepository {
+	return &TelemetryRepository{
+		bridgesCollection: db.Collection("print_bridges"),
+		devicesCollection: db.Collection("billing_devices"),
+	}
+}
+
+func (r *TelemetryRepository) WriteBatch(writes []Domain.TelemetryWrite) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	var bridges, devices []mongo.WriteModel
+	for _, write := range writes {
+		switch write.Kind {
+		case Domain.TelemetryPrintBridgeSeen:
+			id, err := primitive.ObjectIDFromHex(write.SubjectID)
+			if err != nil {
+				continue
+			}
+			// $max keeps a batch from moving the time back past one written directly
+			update := bson.M{"$max": bson.M{"last_seen_at": write.At}}
+			if len(write.Fields) > 0 {
+				update["$set"] = bson.M(write.Fields)
+			}
+			bridges = append(bridges, mongo.NewUpdateOneModel().SetFilter(bson.M{"_id": id}).SetUpdate(update))
+
+		case Domain.TelemetryBillingDeviceSeen:
+			businessID, err := primitive.ObjectIDFromHex(write.BusinessID)
+			if err != nil {
+				continue
+			}
+			devices = append(devices, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"business_id": businessID, "device_id": write.SubjectID}).
+				SetUpdate(bson.M{"$max": bson.M{"last_seen": write.At}}))
+		}
+	}
+
+	opts := options.BulkWrite().SetOrdered(false)
+	if len(bridges) > 0 {
+		if _, err := r.bridgesCollection.BulkWrite(ctx, bridges, opts); err != nil {
+			return fmt.Errorf("failed to write print bridge telemetry: %w", err)
+		}
+	}
+	if len(devices) > 0 {
+		if _, err := r.devicesCollection.BulkWrite(ctx, devices, opts); err != nil {
+			return fmt.Errorf("failed to write device telemetry: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Lookups of one shop's documents take the shop from the context rather than
+// finding the document first and leaving the caller to compare its business_id,
+// so another shop's ID finds nothing however the caller uses the result.
+
+// scopedBusiness is the shop ctx acts on; an error when it acts on none
+func scopedBusiness(ctx context.Context) (primitive.ObjectID, error) {
+	businessID, ok := Infrastructure.BusinessFromContext(ctx)
+	if !ok {
+		return primitive.NilObjectID, fmt.Errorf("lookup is not scoped to a business")
+	}
+	return businessID, nil
+}
+
+// inBusiness is filter narrowed to the shop ctx acts on
+func inBusiness(ctx context.Context, filter bson.M) (bson.M, error) {
+	businessID, err := scopedBusiness(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	scoped := bson.M{}
+	for key, value := range filter {
+		scoped[key] = value
+	}
+	scoped["business_id"] = businessID
+	return scoped, nil
+}
+
+// findOneInBusiness decodes the shop's document matching filter into result,
+// reporting whether there was one
+func findOneInBusiness(ctx context.Context, collection *mongo.Collection, filter bson.M, result interface{}) (bool, error) {
+	scoped, err := inBusiness(ctx, filter)
+	if err != nil {
+		return false, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err = collection.FindOne(ctx, scoped).Decode(result)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to find %s: %w", collection.Name(), err)
+	}
+
+	return true, nil
+}
+
+// findByIDInBusiness is findOneInBusiness by _id. The repositories' FindInBusiness
+// methods are built on it: they find the document among the shop ctx acts on
+// only, so an ID from another shop finds nothing, as an unknown one does.
+func findByIDInBusiness(ctx context.Context, collection *mongo.Collection, id string, result interface{}) (bool, error) {
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return false, fmt.Errorf("invalid ID: %w", err)
+	}
+	return findOneInBusiness(ctx, collection, bson.M{"_id": objID}, result)
+}
@@ -0,0 +1,118 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// Products, customers and suppliers are soft deleted: deleting sets deleted_at and
+// the document stays, hidden from every listing, until it is restored or purged.
+
+// notDeleted matches documents that are not in the trash
+var notDeleted = bson.M{"$exists": false}
+
+// findDeleted decodes the business's deleted documents into results, most recently
+// deleted first
+func findDeleted(collection *mongo.Collection, businessID string, results interface{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	cursor, err := collection.Find(ctx, bson.M{
+		"business_id": objBusinessID,
+		"deleted_at":  bson.M{"$exists": true},
+	}, options.Find().SetSort(bson.M{"deleted_at": -1}))
+	if err != nil {
+		return fmt.Errorf("failed to find deleted %s: %w", collection.Name(), err)
+	}
+	defer cursor.Close(ctx)
+
+	if err := cursor.All(ctx, results); err != nil {
+		return fmt.Errorf("failed to decode deleted %s: %w", collection.Name(), err)
+	}
+
+	return nil
+}
+
+// setDeleted moves the document to the trash, or out of it when deleted is false,
+// applying set alongside
+func setDeleted(collection *mongo.Collection, id string, deleted bool, set bson.M) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid ID: %w", err)
+	}
+
+	now := time.Now()
+	if set == nil {
+		set = bson.M{}
+	}
+	set["updated_at"] = now
+
+	update := bson.M{"$set": set}
+	if deleted {
+		set["deleted_at"] = now
+	} else {
+		update["$unset"] = bson.M{"deleted_at": ""}
+	}
+
+	if _, err := collection.UpdateByID(ctx, objID, update); err != nil {
+		return fmt.Errorf("failed to update %s: %w", collection.Name(), err)
+	}
+
+	return nil
+}
+
+// purgeDeleted removes up to limit documents that went in the trash before the
+// given time, and returns how many it removed per business
+func purgeDeleted(collection *mongo.Collection, before time.Time, limit int) (map[string]int64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	filter := bson.M{"deleted_at": bson.M{"$lt": before}}
+	opts := options.Find().SetProjection(bson.M{"business_id": 1}).SetLimit(int64(limit))
+	cursor, err := collection.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find deleted %s: %w", collection.Name(), err)
+	}
+
+	var expired []struct {
+		ID         primitive.ObjectID `bson:"_id"`
+		BusinessID primitive.ObjectID `bson:"business_id"`
+	}
+	if err := cursor.All(ctx, &expired); err != nil {
+		return nil, fmt.Errorf("failed to decode deleted %s: %w", collection.Name(), err)
+	}
+
+	ids := make(map[primitive.ObjectID][]primitive.ObjectID)
+	for _, doc := range expired {
+		ids[doc.BusinessID] = append(ids[doc.BusinessID], doc.ID)
+	}
+
+	// Deleted shop by shop, still matching deleted_at so anything restored since is kept
+	purged := make(map[string]int64, len(ids))
+	for businessID, businessIDs := range ids {
+		result, err := collection.DeleteMany(ctx, bson.M{
+			"_id":        bson.M{"$in": businessIDs},
+			"deleted_at": bson.M{"$lt": before},
+		})
+		if err != nil {
+			return purged, fmt.Errorf("failed to purge deleted %s: %w", collection.Name(), err)
+		}
+		purged[businessID.Hex()] = result.DeletedCount
+	}
+
+	return purged, nil
+}
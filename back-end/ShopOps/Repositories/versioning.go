@@ -0,0 +1,36 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// Products, customers and suppliers carry a version that every update increments.
+// An update only applies while the document is still at the version it was read at,
+// so two people editing the same record cannot silently overwrite each other.
+
+// updateVersioned applies update to the document if it is still at version, and
+// increments the version. A document that has moved on is a stale version error.
+func updateVersioned(ctx context.Context, collection *mongo.Collection, id primitive.ObjectID, version int64, update bson.M, entity string) error {
+	filter := bson.M{"_id": id, "version": version}
+	if version == 0 {
+		// Documents from before versioning have no version field
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	}
+	update["$inc"] = bson.M{"version": 1}
+
+	result, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", entity, err)
+	}
+	if result.MatchedCount == 0 {
+		return Domain.StaleVersionError(entity)
+	}
+
+	return nil
+}
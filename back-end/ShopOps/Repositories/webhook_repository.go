@@ -0,0 +1,215 @@
+package Repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type WebhookRepository struct {
+	webhooksCollection   *mongo.Collection
+	deliveriesCollection *mongo.Collection
+}
+
+func NewWebhookRepository(db *mongo.Database) Domain.WebhookRepository {
+	return &WebhookRepository{
+		webhooksCollection:   db.Collection("webhooks"),
+		deliveriesCollection: db.Collection("webhook_deliveries"),
+	}
+}
+
+func (r *WebhookRepository) Create(webhook *Domain.Webhook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhook.CreatedAt = time.Now()
+	webhook.UpdatedAt = time.Now()
+
+	result, err := r.webhooksCollection.InsertOne(ctx, webhook)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook: %w", err)
+	}
+
+	webhook.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *WebhookRepository) FindInBusiness(ctx context.Context, id string) (*Domain.Webhook, error) {
+	var webhook Domain.Webhook
+	found, err := findByIDInBusiness(ctx, r.webhooksCollection, id, &webhook)
+	if err != nil || !found {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+func (r *WebhookRepository) FindByBusinessID(businessID string) ([]Domain.Webhook, error) {
+	return r.find(businessID, bson.M{})
+}
+
+func (r *WebhookRepository) FindSubscribed(businessID string, event Domain.WebhookEvent) ([]Domain.Webhook, error) {
+	return r.find(businessID, bson.M{"active": true, "events": event})
+}
+
+func (r *WebhookRepository) find(businessID string, query bson.M) ([]Domain.Webhook, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	query["business_id"] = objBusinessID
+
+	opts := options.Find().SetSort(bson.M{"created_at": 1})
+	cursor, err := r.webhooksCollection.Find(ctx, query, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhooks: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var webhooks []Domain.Webhook
+	if err := cursor.All(ctx, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to decode webhooks: %w", err)
+	}
+
+	return webhooks, nil
+}
+
+func (r *WebhookRepository) Update(webhook *Domain.Webhook) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	webhook.UpdatedAt = time.Now()
+
+	update := bson.M{
+		"$set": bson.M{
+			"url":         webhook.URL,
+			"events":      webhook.Events,
+			"description": webhook.Description,
+			"secret":      webhook.Secret,
+			"active":      webhook.Active,
+			"updated_at":  webhook.UpdatedAt,
+		},
+	}
+
+	_, err := r.webhooksCollection.UpdateByID(ctx, webhook.ID, update)
+	if err != nil {
+		return fmt.Errorf("failed to update webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) Delete(id string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	_, err = r.webhooksCollection.DeleteOne(ctx, bson.M{"_id": objID})
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	return nil
+}
+
+func (r *WebhookRepository) CreateDelivery(delivery *Domain.WebhookDelivery) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	delivery.CreatedAt = time.Now()
+	delivery.UpdatedAt = time.Now()
+	if delivery.Attempts == nil {
+		delivery.Attempts = []Domain.WebhookAttempt{}
+	}
+
+	result, err := r.deliveriesCollection.InsertOne(ctx, delivery)
+	if err != nil {
+		return fmt.Errorf("failed to create webhook delivery: %w", err)
+	}
+
+	delivery.ID = result.InsertedID.(primitive.ObjectID)
+	return nil
+}
+
+func (r *WebhookRepository) FindDeliveryByID(id string) (*Domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return nil, fmt.Errorf("invalid delivery ID: %w", err)
+	}
+
+	var delivery Domain.WebhookDelivery
+	err = r.deliveriesCollection.FindOne(ctx, bson.M{"_id": objID}).Decode(&delivery)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+
+	return &delivery, nil
+}
+
+func (r *WebhookRepository) FindDeliveries(webhookID string, limit int) ([]Domain.WebhookDelivery, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objWebhookID, err := primitive.ObjectIDFromHex(webhookID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid webhook ID: %w", err)
+	}
+
+	opts := options.Find().SetSort(bson.D{{Key: "created_at", Value: -1}, {Key: "_id", Value: -1}})
+	if limit > 0 {
+		opts.SetLimit(int64(limit))
+	}
+
+	cursor, err := r.deliveriesCollection.Find(ctx, bson.M{"webhook_id": objWebhookID}, opts)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook deliveries: %w", err)
+	}
+	defer cursor.Close(ctx)
+
+	var deliveries []Domain.WebhookDelivery
+	if err := cursor.All(ctx, &deliveries); err != nil {
+		return nil, fmt.Errorf("failed to decode webhook deliveries: %w", err)
+	}
+
+	return deliveries, nil
+}
+
+func (r *WebhookRepository) RecordAttempt(id string, attempt Domain.WebhookAttempt, status Domain.WebhookDeliveryStatus) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	objID, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return fmt.Errorf("invalid delivery ID: %w", err)
+	}
+
+	update := bson.M{
+		"$push": bson.M{"attempts": attempt},
+		"$set":  bson.M{"status": status, "updated_at": time.Now()},
+	}
+
+	_, err = r.deliveriesCollection.UpdateByID(ctx, objID, update)
+	if err != nil {
+		return fmt.Errorf("failed to record webhook attempt: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,640 @@
+package Usecases
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AccountingUseCase interface {
+	GetSettings(businessID string) (*Domain.AccountingSettings, error)
+	// UpdateSettings turns posting on or off; turning it on the first time gives
+	// the shop the default chart of accounts
+	UpdateSettings(businessID string, req Domain.UpdateAccountingSettingsRequest) (*Domain.AccountingSettings, error)
+
+	GetAccounts(businessID string) ([]Domain.Account, error)
+	CreateAccount(businessID string, req Domain.CreateAccountRequest) (*Domain.Account, error)
+	UpdateAccount(id, businessID string, req Domain.UpdateAccountRequest) (*Domain.Account, error)
+
+	GetJournal(businessID string, filters Domain.JournalFilters) ([]Domain.JournalEntry, error)
+	GetTrialBalance(businessID string, asOf time.Time) (*Domain.TrialBalance, error)
+
+	// PostPending posts the sales, expenses and supplier bills changed since the
+	// last run of each shop with accounting on, reversing postings that no
+	// longer match their document
+	PostPending() error
+}
+
+type accountingUseCase struct {
+	accountingRepo Domain.AccountingRepository
+	salesRepo      Domain.SaleRepository
+	expenseRepo    Domain.ExpenseRepository
+	supplierRepo   Domain.SupplierRepository
+	businessRepo   Domain.BusinessRepository
+}
+
+func NewAccountingUseCase(
+	accountingRepo Domain.AccountingRepository,
+	salesRepo Domain.SaleRepository,
+	expenseRepo Domain.ExpenseRepository,
+	supplierRepo Domain.SupplierRepository,
+	businessRepo Domain.BusinessRepository,
+) AccountingUseCase {
+	return &accountingUseCase{
+		accountingRepo: accountingRepo,
+		salesRepo:      salesRepo,
+		expenseRepo:    expenseRepo,
+		supplierRepo:   supplierRepo,
+		businessRepo:   businessRepo,
+	}
+}
+
+func (uc *accountingUseCase) GetSettings(businessID string) (*Domain.AccountingSettings, error) {
+	settings, err := uc.accountingRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return &Domain.AccountingSettings{BusinessID: objBusinessID}, nil
+}
+
+func (uc *accountingUseCase) UpdateSettings(businessID string, req Domain.UpdateAccountingSettingsRequest) (*Domain.AccountingSettings, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.StartDate != nil && !req.StartDate.Equal(settings.StartDate) {
+		settings.StartDate = *req.StartDate
+		// Look at everything again from the new start
+		settings.PostedThrough = nil
+	}
+
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+
+	if settings.Enabled {
+		if settings.StartDate.IsZero() {
+			business, err := uc.businessRepo.FindByID(businessID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find business: %w", err)
+			}
+			now := time.Now().In(businessLocation(business))
+			settings.StartDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		}
+
+		accounts, err := uc.accountingRepo.FindAccounts(businessID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := uc.addDefaultAccounts(settings.BusinessID, accounts); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.accountingRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (uc *accountingUseCase) GetAccounts(businessID string) ([]Domain.Account, error) {
+	return uc.accountingRepo.FindAccounts(businessID)
+}
+
+func (uc *accountingUseCase) CreateAccount(businessID string, req Domain.CreateAccountRequest) (*Domain.Account, error) {
+	if !req.Type.IsValid() {
+		return nil, Domain.ValidationError("type must be asset, liability, equity, income or expense")
+	}
+	if req.Role != "" && !req.Role.IsValid() {
+		return nil, Domain.ValidationError(fmt.Sprintf("unknown account role %q", req.Role))
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	accounts := []Domain.Account{{
+		BusinessID: objBusinessID,
+		Code:       req.Code,
+		Name:       req.Name,
+		Type:       req.Type,
+		Role:       req.Role,
+		Active:     true,
+	}}
+	if err := uc.accountingRepo.CreateAccounts(accounts); err != nil {
+		return nil, err
+	}
+
+	return &accounts[0], nil
+}
+
+func (uc *accountingUseCase) UpdateAccount(id, businessID string, req Domain.UpdateAccountRequest) (*Domain.Account, error) {
+	account, err := uc.accountingRepo.FindAccountByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if account == nil || account.BusinessID.Hex() != businessID {
+		return nil, Domain.NotFoundError("account not found")
+	}
+
+	if req.Code != nil {
+		account.Code = *req.Code
+	}
+	if req.Name != nil {
+		account.Name = *req.Name
+	}
+	if req.Role != nil {
+		if *req.Role != "" && !req.Role.IsValid() {
+			return nil, Domain.ValidationError(fmt.Sprintf("unknown account role %q", *req.Role))
+		}
+		account.Role = *req.Role
+	}
+	if req.Active != nil {
+		account.Active = *req.Active
+	}
+
+	if err := uc.accountingRepo.UpdateAccount(account); err != nil {
+		return nil, err
+	}
+
+	return account, nil
+}
+
+func (uc *accountingUseCase) GetJournal(businessID string, filters Domain.JournalFilters) ([]Domain.JournalEntry, error) {
+	return uc.accountingRepo.FindEntries(businessID, filters)
+}
+
+func (uc *accountingUseCase) GetTrialBalance(businessID string, asOf time.Time) (*Domain.TrialBalance, error) {
+	accounts, err := uc.accountingRepo.FindAccounts(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	totals, err := uc.accountingRepo.GetAccountTotals(businessID, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	byAccount := make(map[primitive.ObjectID]Domain.AccountTotals, len(totals))
+	for _, t := range totals {
+		byAccount[t.AccountID] = t
+	}
+
+	balance := &Domain.TrialBalance{AsOf: asOf, Rows: []Domain.TrialBalanceRow{}}
+	for _, account := range accounts {
+		t, ok := byAccount[account.ID]
+		if !ok && !account.Active {
+			continue
+		}
+
+		row := Domain.TrialBalanceRow{
+			AccountID: account.ID,
+			Code:      account.Code,
+			Name:      account.Name,
+			Type:      account.Type,
+		}
+		if net := t.Debit - t.Credit; net > 0 {
+			row.Debit = net
+		} else if net < 0 {
+			row.Credit = -net
+		}
+
+		balance.Rows = append(balance.Rows, row)
+		balance.TotalDebit += row.Debit
+		balance.TotalCredit += row.Credit
+	}
+
+	balance.Balanced = balance.TotalDebit == balance.TotalCredit
+
+	return balance, nil
+}
+
+func (uc *accountingUseCase) PostPending() error {
+	settings, err := uc.accountingRepo.FindEnabledSettings()
+	if err != nil {
+		return err
+	}
+
+	for i := range settings {
+		passStart := time.Now()
+
+		if err := uc.postBusiness(&settings[i]); err != nil {
+			// The cursor stays put, so the run is retried once the chart is fixed
+			Infrastructure.Logger.Warn("failed to post journal entries for business", "business_id", settings[i].BusinessID.Hex(), "error", err)
+			settings[i].LastError = err.Error()
+		} else {
+			settings[i].PostedThrough = &passStart
+			settings[i].LastError = ""
+		}
+
+		if err := uc.accountingRepo.SaveSettings(&settings[i]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// journalDoc is a document as it should stand in the ledger; an empty posting
+// means it should have nothing posted
+type journalDoc struct {
+	id          primitive.ObjectID
+	date        time.Time
+	description string
+	posting     posting
+	balancing   Domain.AccountRole // Takes the rounding difference
+}
+
+// posting is an amount per account role, debits positive and credits negative
+type posting map[Domain.AccountRole]Domain.Money
+
+func (p posting) add(role Domain.AccountRole, amount Domain.Money) {
+	p[role] += amount
+}
+
+// addDefaultAccounts gives a shop the default chart, or the accounts for roles
+// added to it since the shop's chart was made, and returns the whole chart
+func (uc *accountingUseCase) addDefaultAccounts(businessID primitive.ObjectID, accounts []Domain.Account) ([]Domain.Account, error) {
+	roles := make(map[Domain.AccountRole]bool, len(accounts))
+	codes := make(map[string]bool, len(accounts))
+	for _, account := range accounts {
+		roles[account.Role] = true
+		codes[account.Code] = true
+	}
+
+	var missing []Domain.Account
+	for _, account := range Domain.DefaultChartOfAccounts {
+		if len(accounts) > 0 && (account.Role == "" || roles[account.Role] || codes[account.Code]) {
+			continue
+		}
+		account.BusinessID = businessID
+		account.Active = true
+		missing = append(missing, account)
+	}
+	if len(missing) == 0 {
+		return accounts, nil
+	}
+
+	if err := uc.accountingRepo.CreateAccounts(missing); err != nil {
+		return nil, err
+	}
+	return uc.accountingRepo.FindAccounts(businessID.Hex())
+}
+
+func (uc *accountingUseCase) postBusiness(settings *Domain.AccountingSettings) error {
+	businessID := settings.BusinessID.Hex()
+
+	since := settings.StartDate
+	if settings.PostedThrough != nil {
+		since = settings.PostedThrough.Add(-Domain.AccountingPostingOverlap)
+	}
+
+	accounts, err := uc.accountingRepo.FindAccounts(businessID)
+	if err != nil {
+		return err
+	}
+	if accounts, err = uc.addDefaultAccounts(settings.BusinessID, accounts); err != nil {
+		return err
+	}
+	byRole := make(map[Domain.AccountRole]Domain.Account, len(accounts))
+	for _, account := range accounts {
+		if account.Role != "" {
+			byRole[account.Role] = account
+		}
+	}
+
+	sales, err := uc.salesRepo.FindByBusinessID(businessID, Domain.SaleFilters{UpdatedSince: &since})
+	if err != nil {
+		return err
+	}
+
+	var saleDocs, returnDocs []journalDoc
+	for _, sale := range sales {
+		if sale.CreatedAt.Before(settings.StartDate) {
+			continue
+		}
+
+		doc := journalDoc{
+			id:          sale.ID,
+			date:        sale.CreatedAt,
+			description: "Sale " + sale.ID.Hex(),
+			balancing:   Domain.AccountRoleRevenue,
+		}
+		returned := journalDoc{
+			id:          sale.ID,
+			date:        sale.UpdatedAt,
+			description: "Return of sale " + sale.ID.Hex(),
+			balancing:   Domain.AccountRoleReturns,
+		}
+
+		if sale.Status != Domain.SaleStatusVoided {
+			doc.posting = salePosting(sale)
+		}
+		if sale.Status == Domain.SaleStatusRefunded {
+			returned.posting = posting{}
+			for role, amount := range doc.posting {
+				if role == Domain.AccountRoleRevenue {
+					role = Domain.AccountRoleReturns
+				}
+				returned.posting.add(role, -amount)
+			}
+		}
+
+		saleDocs = append(saleDocs, doc)
+		returnDocs = append(returnDocs, returned)
+	}
+
+	if err := uc.post(settings.BusinessID, Domain.JournalSourceSale, saleDocs, byRole); err != nil {
+		return err
+	}
+	if err := uc.post(settings.BusinessID, Domain.JournalSourceSaleReturn, returnDocs, byRole); err != nil {
+		return err
+	}
+
+	expenses, err := uc.expenseRepo.FindByBusinessID(businessID, Domain.ExpenseFilters{UpdatedSince: &since})
+	if err != nil {
+		return err
+	}
+
+	var expenseDocs []journalDoc
+	for _, expense := range expenses {
+		if expense.Date.Before(settings.StartDate) {
+			continue
+		}
+
+		doc := journalDoc{
+			id:          expense.ID,
+			date:        expense.Date,
+			description: fmt.Sprintf("Expense: %s", expense.Category),
+			balancing:   Domain.AccountRoleCash,
+		}
+		if expense.Description != "" {
+			doc.description += " - " + expense.Description
+		}
+		if expense.Status == Domain.ExpenseStatusActive {
+			doc.posting = posting{}
+			doc.posting.add(Domain.ExpenseAccountRole(expense.Category), expense.Amount)
+			doc.posting.add(Domain.AccountRoleCash, -expense.Amount)
+		}
+
+		expenseDocs = append(expenseDocs, doc)
+	}
+
+	if err := uc.post(settings.BusinessID, Domain.JournalSourceExpense, expenseDocs, byRole); err != nil {
+		return err
+	}
+
+	// Payable entries are never edited, so only new ones need posting
+	entries, err := uc.supplierRepo.GetBusinessPayableEntries(businessID)
+	if err != nil {
+		return err
+	}
+
+	var payableDocs []journalDoc
+	for _, entry := range entries {
+		if entry.CreatedAt.Before(since) || entry.Date.Before(settings.StartDate) {
+			continue
+		}
+
+		doc := journalDoc{
+			id:        entry.ID,
+			date:      entry.Date,
+			posting:   posting{},
+			balancing: Domain.AccountRolePayable,
+		}
+		switch entry.Type {
+		case Domain.PayableEntryInvoice:
+			doc.description = "Supplier invoice"
+			doc.posting.add(Domain.AccountRoleInventory, entry.Amount)
+			doc.posting.add(Domain.AccountRolePayable, -entry.Amount)
+		case Domain.PayableEntryPayment:
+			method := entry.PaymentMethod
+			if method == "" {
+				method = Domain.PaymentMethodCash
+			}
+			doc.description = "Supplier payment"
+			doc.posting.add(Domain.AccountRolePayable, entry.Amount)
+			doc.posting.add(Domain.PaymentAccountRole(method), -entry.Amount)
+		case Domain.PayableEntryCredit:
+			doc.description = "Supplier credit note"
+			doc.posting.add(Domain.AccountRolePayable, entry.Amount)
+			doc.posting.add(Domain.AccountRoleInventory, -entry.Amount)
+		}
+		if entry.Reference != "" {
+			doc.description += " " + entry.Reference
+		}
+
+		payableDocs = append(payableDocs, doc)
+	}
+
+	return uc.post(settings.BusinessID, Domain.JournalSourcePayable, payableDocs, byRole)
+}
+
+// salePosting takes the money in on the accounts it was paid to, with what is
+// still owed on receivables, and moves the goods' cost out of inventory
+func salePosting(sale Domain.Sale) posting {
+	p := posting{}
+
+	switch {
+	case sale.PaymentStatus == Domain.PaymentStatusPending || sale.PaymentStatus == Domain.PaymentStatusFailed:
+		p.add(Domain.AccountRoleReceivable, sale.FinalAmount+sale.DepositAmount)
+	case len(sale.Payments) > 0:
+		var paid Domain.Money
+		for _, payment := range sale.Payments {
+			p.add(Domain.PaymentAccountRole(payment.Method), payment.Amount)
+			paid += payment.Amount
+		}
+		if residual := sale.FinalAmount + sale.DepositAmount + sale.CashRounding - paid; residual != 0 {
+			p.add(Domain.AccountRoleReceivable, residual)
+		}
+	default:
+		p.add(Domain.PaymentAccountRole(sale.PaymentMethod), sale.FinalAmount+sale.DepositAmount+sale.CashRounding)
+	}
+
+	// Rounding is neither revenue nor taxed
+	if sale.CashRounding != 0 {
+		p.add(Domain.AccountRoleCashRounding, -sale.CashRounding)
+	}
+
+	// Deposits are owed back when the containers return
+	if sale.DepositAmount != 0 {
+		p.add(Domain.AccountRoleDeposits, -sale.DepositAmount)
+	}
+
+	p.add(Domain.AccountRoleRevenue, sale.Tax-sale.FinalAmount)
+	if sale.Tax != 0 {
+		p.add(Domain.AccountRoleTaxPayable, -sale.Tax)
+	}
+
+	if sale.ProductID != nil && sale.UnitCost > 0 {
+		cost := sale.UnitCost.Times(sale.Quantity)
+		p.add(Domain.AccountRoleCOGS, cost)
+		p.add(Domain.AccountRoleInventory, -cost)
+	}
+
+	return p
+}
+
+// post brings the ledger in line with the documents: a document whose current
+// posting differs from what it should be has that posting reversed, and its
+// new one posted
+func (uc *accountingUseCase) post(businessID primitive.ObjectID, source Domain.JournalSource, docs []journalDoc, byRole map[Domain.AccountRole]Domain.Account) error {
+	if len(docs) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.id
+	}
+
+	open, err := uc.accountingRepo.FindOpenEntries(businessID, source, ids)
+	if err != nil {
+		return err
+	}
+
+	for _, doc := range docs {
+		lines, err := journalLines(doc, byRole)
+		if err != nil {
+			return fmt.Errorf("%s %s: %w", source, doc.id.Hex(), err)
+		}
+
+		current, posted := open[doc.id]
+		if posted && sameLines(current.Lines, lines) {
+			continue
+		}
+		if !posted && len(lines) == 0 {
+			continue
+		}
+
+		if posted {
+			reversal := &Domain.JournalEntry{
+				BusinessID:  businessID,
+				Date:        time.Now(),
+				Description: "Reversal: " + current.Description,
+				Source:      source,
+				SourceID:    doc.id,
+				Reverses:    &current.ID,
+			}
+			for _, line := range current.Lines {
+				line.Debit, line.Credit = line.Credit, line.Debit
+				reversal.Lines = append(reversal.Lines, line)
+			}
+			// Already there when an earlier run stopped before closing the entry
+			if _, err := uc.accountingRepo.CreateEntry(reversal); err != nil {
+				return err
+			}
+			if err := uc.accountingRepo.CloseEntry(current.ID); err != nil {
+				return err
+			}
+		}
+
+		if len(lines) == 0 {
+			continue
+		}
+
+		entry := &Domain.JournalEntry{
+			BusinessID:  businessID,
+			Date:        doc.date,
+			Description: doc.description,
+			Source:      source,
+			SourceID:    doc.id,
+			Lines:       lines,
+			Open:        true,
+		}
+		if _, err := uc.accountingRepo.CreateEntry(entry); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// journalLines puts the posting on the accounts holding its roles, with any
+// imbalance left on the balancing role
+func journalLines(doc journalDoc, byRole map[Domain.AccountRole]Domain.Account) ([]Domain.JournalLine, error) {
+	amounts := make(map[Domain.AccountRole]Domain.Money, len(doc.posting))
+	var total Domain.Money
+	for role, amount := range doc.posting {
+		amounts[role] = amount
+		total += amount
+	}
+	if total != 0 {
+		amounts[doc.balancing] -= total
+	}
+
+	// Roles landing on one account are netted
+	byAccount := make(map[primitive.ObjectID]Domain.Money)
+	codes := make(map[primitive.ObjectID]string)
+	for role, amount := range amounts {
+		if amount == 0 {
+			continue
+		}
+		account, ok := byRole[role]
+		if !ok || !account.Active {
+			return nil, fmt.Errorf("no active account has the %s role", role)
+		}
+		byAccount[account.ID] += amount
+		codes[account.ID] = account.Code
+	}
+
+	var lines []Domain.JournalLine
+	for id, amount := range byAccount {
+		line := Domain.JournalLine{AccountID: id, AccountCode: codes[id]}
+		if amount > 0 {
+			line.Debit = amount
+		} else if amount < 0 {
+			line.Credit = -amount
+		} else {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		if lines[i].AccountCode != lines[j].AccountCode {
+			return lines[i].AccountCode < lines[j].AccountCode
+		}
+		return lines[i].AccountID.Hex() < lines[j].AccountID.Hex()
+	})
+
+	return lines, nil
+}
+
+func sameLines(a, b []Domain.JournalLine) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	amounts := make(map[primitive.ObjectID]Domain.Money, len(a))
+	for _, line := range a {
+		amounts[line.AccountID] += line.Debit - line.Credit
+	}
+	for _, line := range b {
+		amounts[line.AccountID] -= line.Debit - line.Credit
+	}
+	for _, amount := range amounts {
+		if amount != 0 {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,376 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AlertUseCase interface {
+	// AnalyzeAll checks every active business for anomalies, for the background job
+	AnalyzeAll() error
+	// Analyze checks one business and returns the alerts it newly raised
+	Analyze(businessID string) ([]Domain.Alert, error)
+	GetAlerts(businessID string, filters Domain.AlertFilters) ([]Domain.Alert, error)
+	Acknowledge(ctx context.Context, id, businessID, userID string) (*Domain.Alert, error)
+}
+
+type alertUseCase struct {
+	alertRepo        Domain.AlertRepository
+	salesSummaryRepo Domain.SalesSummaryRepository
+	employeeRepo     Domain.EmployeeRepository
+	businessRepo     Domain.BusinessRepository
+	smsUC            SMSUseCase
+	notificationUC   NotificationUseCase
+}
+
+// Baselines are the same weekday and time of day over the previous weeks, so
+// quiet mornings and busy weekends are judged against their own history
+const alertBaselineWeeks = 4
+
+const (
+	// Revenue is only judged once the day has had time to trade
+	revenueDropMinElapsed = 3 * time.Hour
+	// Baselines thinner than this are too noisy to alert on
+	revenueDropMinTransactions = 10
+	revenueDropWarning         = 0.5  // Below half the usual
+	revenueDropCritical        = 0.75 // Below a quarter of the usual
+
+	refundSpikeMinRefunds = 3
+	refundSpikeRatio      = 3.0 // Times the usual daily refunds
+
+	idleTillAfter = 2 * time.Hour
+)
+
+func NewAlertUseCase(
+	alertRepo Domain.AlertRepository,
+	salesSummaryRepo Domain.SalesSummaryRepository,
+	employeeRepo Domain.EmployeeRepository,
+	businessRepo Domain.BusinessRepository,
+	smsUC SMSUseCase,
+	notificationUC NotificationUseCase,
+) AlertUseCase {
+	return &alertUseCase{
+		alertRepo:        alertRepo,
+		salesSummaryRepo: salesSummaryRepo,
+		employeeRepo:     employeeRepo,
+		businessRepo:     businessRepo,
+		smsUC:            smsUC,
+		notificationUC:   notificationUC,
+	}
+}
+
+func (uc *alertUseCase) AnalyzeAll() error {
+	businesses, err := uc.businessRepo.FindActive()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for i := range businesses {
+		if _, err := uc.analyze(&businesses[i], now); err != nil {
+			Infrastructure.Logger.Warn("failed to analyze business for alerts", "business_id", businesses[i].ID.Hex(), "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *alertUseCase) Analyze(businessID string) ([]Domain.Alert, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	return uc.analyze(business, time.Now())
+}
+
+func (uc *alertUseCase) GetAlerts(businessID string, filters Domain.AlertFilters) ([]Domain.Alert, error) {
+	if filters.Type != nil && !filters.Type.IsValid() {
+		return nil, fmt.Errorf("invalid alert type: %s", *filters.Type)
+	}
+
+	alerts, err := uc.alertRepo.Find(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if alerts == nil {
+		alerts = []Domain.Alert{}
+	}
+	return alerts, nil
+}
+
+func (uc *alertUseCase) Acknowledge(ctx context.Context, id, businessID, userID string) (*Domain.Alert, error) {
+	alert, err := uc.alertRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if alert == nil {
+		return nil, Domain.NotFoundError("alert not found")
+	}
+	if alert.Status == Domain.AlertStatusAcknowledged {
+		return alert, nil
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	if err := uc.alertRepo.Acknowledge(id, objUserID, now); err != nil {
+		return nil, err
+	}
+
+	alert.Status = Domain.AlertStatusAcknowledged
+	alert.AcknowledgedBy = &objUserID
+	alert.AcknowledgedAt = &now
+	return alert, nil
+}
+
+// analyze runs every check; one failing does not stop the others
+func (uc *alertUseCase) analyze(business *Domain.Business, now time.Time) ([]Domain.Alert, error) {
+	clock := clockFor(business)
+	dayStart := clock.Start(clock.Day(now))
+
+	var found []Domain.Alert
+	var firstErr error
+	checks := []func(*Domain.Business, time.Time, time.Time) ([]Domain.Alert, error){
+		uc.checkRevenueDrop,
+		uc.checkRefundSpike,
+		uc.checkIdleTills,
+	}
+	for _, check := range checks {
+		alerts, err := check(business, dayStart, now)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		found = append(found, alerts...)
+	}
+
+	raised := []Domain.Alert{}
+	for i := range found {
+		alert := found[i]
+		alert.BusinessID = business.ID
+		alert.CreatedAt = now
+
+		isNew, err := uc.alertRepo.Raise(&alert)
+		if err != nil {
+			return raised, err
+		}
+		if !isNew {
+			continue
+		}
+
+		raised = append(raised, alert)
+		uc.notify(business, &alert)
+	}
+
+	return raised, firstErr
+}
+
+// notify tells the owner in the app and texts the shop, as the owner chose;
+// shops without a phone still see the alert on the alerts screen
+func (uc *alertUseCase) notify(business *Domain.Business, alert *Domain.Alert) {
+	ownerID := business.UserID.Hex()
+	err := uc.notificationUC.Notify(ownerID, business.ID.Hex(), Domain.NotificationAlert,
+		Infrastructure.Translate(business.Language, "notify.alert", business.Name), alert.Message,
+		map[string]string{"screen": "alert", "alert_id": alert.ID.Hex()})
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to file alert notification", "alert_id", alert.ID.Hex(), "error", err)
+	}
+
+	if business.Phone == "" || !uc.notificationUC.Allows(ownerID, Domain.NotificationAlert, Domain.ChannelSMS) {
+		return
+	}
+	if _, err := uc.smsUC.SendAlert(business.ID.Hex(), alert.Message); err != nil {
+		Infrastructure.Logger.Warn("failed to send alert by sms", "alert_id", alert.ID.Hex(), "error", err)
+	}
+}
+
+// checkRevenueDrop compares sales so far today with the same hours on the same weekday in previous weeks
+func (uc *alertUseCase) checkRevenueDrop(business *Domain.Business, dayStart, now time.Time) ([]Domain.Alert, error) {
+	if now.Sub(dayStart) < revenueDropMinElapsed {
+		return nil, nil
+	}
+
+	businessID := business.ID.Hex()
+	today, err := uc.salesSummaryRepo.Summary(businessID, dayStart, now)
+	if err != nil {
+		return nil, err
+	}
+
+	var baselineAmount float64
+	var baselineTransactions, tradingWeeks int
+	for week := 1; week <= alertBaselineWeeks; week++ {
+		past, err := uc.salesSummaryRepo.Summary(businessID, dayStart.AddDate(0, 0, -7*week), now.AddDate(0, 0, -7*week))
+		if err != nil {
+			return nil, err
+		}
+		// Weeks the shop did not open say nothing about a normal day
+		if past.TransactionCount == 0 {
+			continue
+		}
+		baselineAmount += past.TotalAmount.Float64()
+		baselineTransactions += past.TransactionCount
+		tradingWeeks++
+	}
+
+	if tradingWeeks < alertBaselineWeeks/2 || baselineTransactions/tradingWeeks < revenueDropMinTransactions {
+		return nil, nil
+	}
+
+	expected := baselineAmount / float64(tradingWeeks)
+	if expected <= 0 {
+		return nil, nil
+	}
+
+	drop := 1 - today.TotalAmount.Float64()/expected
+	if drop < revenueDropWarning {
+		return nil, nil
+	}
+
+	severity := Domain.AlertSeverityWarning
+	if drop >= revenueDropCritical {
+		severity = Domain.AlertSeverityCritical
+	}
+
+	local := now.In(businessLocation(business))
+	return []Domain.Alert{{
+		Type:     Domain.AlertTypeRevenueDrop,
+		Severity: severity,
+		Key:      fmt.Sprintf("%s:%s", Domain.AlertTypeRevenueDrop, dayStart.Format("2006-01-02")),
+		Message: Infrastructure.Translate(business.Language, "alert.revenue_drop",
+			drop*100, Infrastructure.Translate(business.Language, fmt.Sprintf("weekday.%d", dayStart.Weekday())),
+			local.Format("15:04"), business.Currency, today.TotalAmount.Float64(), expected),
+		Value:    today.TotalAmount.Float64(),
+		Expected: roundCurrency(expected),
+	}}, nil
+}
+
+// checkRefundSpike compares refunds today with the daily average over the baseline weeks
+func (uc *alertUseCase) checkRefundSpike(business *Domain.Business, dayStart, now time.Time) ([]Domain.Alert, error) {
+	businessID := business.ID.Hex()
+	today, err := uc.alertRepo.RefundActivity(businessID, dayStart, now)
+	if err != nil {
+		return nil, err
+	}
+	if today.Refunds < refundSpikeMinRefunds {
+		return nil, nil
+	}
+
+	baselineDays := 7 * alertBaselineWeeks
+	past, err := uc.alertRepo.RefundActivity(businessID, dayStart.AddDate(0, 0, -baselineDays), dayStart)
+	if err != nil {
+		return nil, err
+	}
+
+	expected := float64(past.Refunds) / float64(baselineDays)
+	threshold := math.Max(float64(refundSpikeMinRefunds), refundSpikeRatio*expected)
+	if float64(today.Refunds) < threshold {
+		return nil, nil
+	}
+
+	severity := Domain.AlertSeverityWarning
+	if float64(today.Refunds) >= 2*threshold {
+		severity = Domain.AlertSeverityCritical
+	}
+
+	return []Domain.Alert{{
+		Type:     Domain.AlertTypeRefundSpike,
+		Severity: severity,
+		Key:      fmt.Sprintf("%s:%s", Domain.AlertTypeRefundSpike, dayStart.Format("2006-01-02")),
+		Message: Infrastructure.Translate(business.Language, "alert.refund_spike",
+			today.Refunds, business.Currency, today.Amount, expected),
+		Value:    float64(today.Refunds),
+		Expected: roundCurrency(expected),
+	}}, nil
+}
+
+// checkIdleTills flags tills with someone clocked in but no sale for hours, when the
+// shop usually trades at this time of day
+func (uc *alertUseCase) checkIdleTills(business *Domain.Business, dayStart, now time.Time) ([]Domain.Alert, error) {
+	businessID := business.ID.Hex()
+	entries, err := uc.employeeRepo.FindTimeEntries(businessID, Domain.TimeEntryFilters{OpenOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	var alerts []Domain.Alert
+	checked := make(map[string]bool)
+	businessHours := false
+	for _, entry := range entries {
+		if entry.DeviceID == "" || checked[entry.DeviceID] {
+			continue
+		}
+		checked[entry.DeviceID] = true
+
+		since := entry.ClockInAt
+		lastSale, err := uc.alertRepo.LastSaleAt(businessID, entry.DeviceID)
+		if err != nil {
+			return nil, err
+		}
+		if lastSale != nil && lastSale.After(since) {
+			since = *lastSale
+		}
+
+		idle := now.Sub(since)
+		if idle < idleTillAfter {
+			continue
+		}
+
+		// Only worth raising when the shop usually sells during these hours
+		if !businessHours {
+			usual, err := uc.usuallyTrading(businessID, now.Add(-idleTillAfter), now)
+			if err != nil {
+				return nil, err
+			}
+			if !usual {
+				return nil, nil
+			}
+			businessHours = true
+		}
+
+		local := since.In(businessLocation(business))
+		alerts = append(alerts, Domain.Alert{
+			Type:     Domain.AlertTypeIdleTill,
+			Severity: Domain.AlertSeverityWarning,
+			Key:      fmt.Sprintf("%s:%s:%d", Domain.AlertTypeIdleTill, entry.DeviceID, since.Unix()),
+			DeviceID: entry.DeviceID,
+			Message: Infrastructure.Translate(business.Language, "alert.idle_till",
+				entry.DeviceID, local.Format("15:04"), idle.Hours()),
+			Value:    math.Round(idle.Hours()*10) / 10,
+			Expected: idleTillAfter.Hours(),
+		})
+	}
+
+	return alerts, nil
+}
+
+// usuallyTrading reports whether the shop sold in the same window on at least half of the
+// same weekdays in the baseline weeks
+func (uc *alertUseCase) usuallyTrading(businessID string, start, end time.Time) (bool, error) {
+	trading := 0
+	for week := 1; week <= alertBaselineWeeks; week++ {
+		past, err := uc.salesSummaryRepo.Summary(businessID, start.AddDate(0, 0, -7*week), end.AddDate(0, 0, -7*week))
+		if err != nil {
+			return false, err
+		}
+		if past.TransactionCount > 0 {
+			trading++
+		}
+	}
+	return trading*2 >= alertBaselineWeeks, nil
+}
@@ -0,0 +1,778 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+type AnalyticsUseCase interface {
+	GetProfitAndLoss(businessID, startDate, endDate string, granularity Domain.PeriodType, calendar Domain.Calendar) (*Domain.PnLReport, error)
+	GetCategoryMargins(businessID, startDate, endDate string) (*Domain.MarginReport, error)
+	GetProductMargins(businessID, startDate, endDate string, limit int) (*Domain.MarginReport, error)
+	GetABCAnalysis(businessID, startDate, endDate string, filter Domain.MarginFilter, aThreshold, bThreshold float64) (*Domain.ABCReport, error)
+	GetTopSellers(businessID, startDate, endDate string, rankBy Domain.TopSellerRank, filter Domain.MarginFilter) (*Domain.TopSellersReport, error)
+	Rebuild(businessID, startDate, endDate string) (*Domain.AnalyticsRebuildResult, error)
+
+	// Comparisons run the report for the range and for the comparable prior range
+	CompareProfitAndLoss(businessID, startDate, endDate string, granularity Domain.PeriodType, calendar Domain.Calendar, opts Domain.CompareOptions) (*Domain.ReportComparison, error)
+	CompareCategoryMargins(businessID, startDate, endDate string, opts Domain.CompareOptions) (*Domain.ReportComparison, error)
+	CompareProductMargins(businessID, startDate, endDate string, limit int, opts Domain.CompareOptions) (*Domain.ReportComparison, error)
+
+	GetSalesSummary(businessID string, start, end time.Time) (*Domain.SaleSummary, error)
+	GetSalesBreakdown(businessID, startDate, endDate string, by Domain.SalesSummaryDimension) (*Domain.SalesBreakdown, error)
+	GetTrafficHeatmap(businessID, startDate, endDate, deviceID string) (*Domain.TrafficHeatmap, error)
+
+	// MarkDirty queues the business days containing the given times for re-aggregation
+	MarkDirty(businessID string, at ...time.Time)
+	// OnSaleRecorded queues the day of a recorded sale, for the event bus
+	OnSaleRecorded(ctx context.Context, event *Domain.DomainEvent) error
+	FlushDirty() error
+	// Refresh re-aggregates the queued days of one business so a read sees its latest writes
+	Refresh(businessID string)
+}
+
+type analyticsUseCase struct {
+	analyticsRepo Domain.AnalyticsRepository
+	summaryRepo   Domain.SalesSummaryRepository
+	businessRepo  Domain.BusinessRepository
+
+	mu    sync.Mutex
+	dirty map[string][]time.Time // Business ID -> changed instants, resolved to days on flush
+}
+
+// Rebuilds cover at most this many days per request
+const maxAnalyticsRebuildDays = 366
+
+// Hourly breakdowns cover at most this many days
+const maxHourlyBreakdownDays = 31
+
+// Default cumulative revenue shares closing the A and B classes
+const (
+	defaultABCThresholdA = 80
+	defaultABCThresholdB = 95
+	defaultTopSellers    = 10
+)
+
+func NewAnalyticsUseCase(
+	analyticsRepo Domain.AnalyticsRepository,
+	summaryRepo Domain.SalesSummaryRepository,
+	businessRepo Domain.BusinessRepository,
+) AnalyticsUseCase {
+	return &analyticsUseCase{
+		analyticsRepo: analyticsRepo,
+		summaryRepo:   summaryRepo,
+		businessRepo:  businessRepo,
+		dirty:         make(map[string][]time.Time),
+	}
+}
+
+func (uc *analyticsUseCase) GetProfitAndLoss(businessID, startDate, endDate string, granularity Domain.PeriodType, calendar Domain.Calendar) (*Domain.PnLReport, error) {
+	fromDay, toDay, err := uc.prepareRange(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	if granularity == "" {
+		granularity = Domain.PeriodTypeDaily
+	}
+	switch granularity {
+	case Domain.PeriodTypeDaily, Domain.PeriodTypeWeekly, Domain.PeriodTypeMonthly, Domain.PeriodTypeYearly, Domain.PeriodTypeFiscalYearly:
+	default:
+		return nil, fmt.Errorf("invalid granularity: %s", granularity)
+	}
+
+	calendar, err = normalizeCalendar(calendar)
+	if err != nil {
+		return nil, err
+	}
+
+	days, err := uc.analyticsRepo.DailyTotals(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Domain.PnLReport{
+		StartDate:   fromDay.Format("2006-01-02"),
+		EndDate:     toDay.Format("2006-01-02"),
+		Granularity: granularity,
+		Totals:      Domain.PnLLine{Period: fmt.Sprintf("%s to %s", Domain.FormatDate(fromDay, calendar), Domain.FormatDate(toDay, calendar))},
+		Periods:     []Domain.PnLLine{},
+		Calendar:    calendar,
+	}
+
+	// Days arrive sorted, so buckets come out in order
+	index := make(map[string]int)
+	for _, day := range days {
+		label := pnlPeriodLabel(day.Day, granularity, calendar)
+		i, ok := index[label]
+		if !ok {
+			i = len(report.Periods)
+			index[label] = i
+			report.Periods = append(report.Periods, Domain.PnLLine{Period: label})
+		}
+		addPnLDay(&report.Periods[i], day)
+		addPnLDay(&report.Totals, day)
+	}
+
+	for i := range report.Periods {
+		finishPnLLine(&report.Periods[i])
+	}
+	finishPnLLine(&report.Totals)
+
+	return report, nil
+}
+
+func (uc *analyticsUseCase) GetCategoryMargins(businessID, startDate, endDate string) (*Domain.MarginReport, error) {
+	fromDay, toDay, err := uc.prepareRange(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := uc.analyticsRepo.CategoryMargins(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		if lines[i].Name == "" {
+			lines[i].Name = "Uncategorized"
+		}
+	}
+
+	return newMarginReport(fromDay, toDay, lines), nil
+}
+
+func (uc *analyticsUseCase) GetProductMargins(businessID, startDate, endDate string, limit int) (*Domain.MarginReport, error) {
+	fromDay, toDay, err := uc.prepareRange(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	lines, err := uc.analyticsRepo.ProductMargins(businessID, fromDay, toDay, Domain.MarginFilter{Limit: limit})
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		if lines[i].ProductID == "" {
+			lines[i].Name = "Items without a product"
+		}
+	}
+
+	return newMarginReport(fromDay, toDay, lines), nil
+}
+
+// GetABCAnalysis classes listed products by their cumulative share of revenue
+func (uc *analyticsUseCase) GetABCAnalysis(businessID, startDate, endDate string, filter Domain.MarginFilter, aThreshold, bThreshold float64) (*Domain.ABCReport, error) {
+	if aThreshold == 0 {
+		aThreshold = defaultABCThresholdA
+	}
+	if bThreshold == 0 {
+		bThreshold = defaultABCThresholdB
+	}
+	if aThreshold <= 0 || aThreshold >= bThreshold || bThreshold >= 100 {
+		return nil, fmt.Errorf("thresholds must satisfy 0 < a_threshold < b_threshold < 100")
+	}
+
+	fromDay, toDay, err := uc.prepareRange(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Every product counts towards the shares, so no limit here
+	filter.Limit = 0
+	lines, err := uc.analyticsRepo.ProductMargins(businessID, fromDay, toDay, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	margins := newMarginReport(fromDay, toDay, listedProducts(lines))
+
+	report := &Domain.ABCReport{
+		StartDate:  margins.StartDate,
+		EndDate:    margins.EndDate,
+		AThreshold: aThreshold,
+		BThreshold: bThreshold,
+		Classes: []Domain.ABCClassSummary{
+			{Class: Domain.ABCClassA},
+			{Class: Domain.ABCClassB},
+			{Class: Domain.ABCClassC},
+		},
+		Lines: make([]Domain.ABCLine, 0, len(margins.Lines)),
+	}
+
+	var cumulative float64
+	for _, line := range margins.Lines {
+		// A product belongs to the class its revenue starts in, so the top
+		// seller is always A even when it alone exceeds the threshold
+		class, summary := Domain.ABCClassC, &report.Classes[2]
+		if cumulative < aThreshold {
+			class, summary = Domain.ABCClassA, &report.Classes[0]
+		} else if cumulative < bThreshold {
+			class, summary = Domain.ABCClassB, &report.Classes[1]
+		}
+		cumulative += line.RevenueShare
+
+		summary.Products++
+		summary.Revenue += line.Revenue
+		summary.RevenueShare += line.RevenueShare
+
+		report.Lines = append(report.Lines, Domain.ABCLine{
+			MarginLine:      line,
+			Class:           class,
+			CumulativeShare: roundCurrency(math.Min(cumulative, 100)),
+		})
+	}
+
+	for i := range report.Classes {
+		report.Classes[i].RevenueShare = roundCurrency(report.Classes[i].RevenueShare)
+	}
+
+	return report, nil
+}
+
+func (uc *analyticsUseCase) GetTopSellers(businessID, startDate, endDate string, rankBy Domain.TopSellerRank, filter Domain.MarginFilter) (*Domain.TopSellersReport, error) {
+	if rankBy == "" {
+		rankBy = Domain.TopSellerRankRevenue
+	}
+	switch rankBy {
+	case Domain.TopSellerRankQuantity, Domain.TopSellerRankRevenue, Domain.TopSellerRankMargin:
+	default:
+		return nil, fmt.Errorf("invalid rank_by: %s", rankBy)
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultTopSellers
+	}
+
+	fromDay, toDay, err := uc.prepareRange(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	// Ranking by anything but revenue needs every product before cutting
+	filter.Limit = 0
+	lines, err := uc.analyticsRepo.ProductMargins(businessID, fromDay, toDay, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	margins := newMarginReport(fromDay, toDay, listedProducts(lines))
+	ranked := margins.Lines
+
+	switch rankBy {
+	case Domain.TopSellerRankQuantity:
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].Quantity > ranked[j].Quantity })
+	case Domain.TopSellerRankMargin:
+		sort.SliceStable(ranked, func(i, j int) bool { return ranked[i].GrossProfit > ranked[j].GrossProfit })
+	}
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return &Domain.TopSellersReport{
+		StartDate: margins.StartDate,
+		EndDate:   margins.EndDate,
+		RankBy:    rankBy,
+		Lines:     ranked,
+	}, nil
+}
+
+// Rebuild recomputes the aggregates for a date range, for history recorded before
+// aggregation existed or after bulk imports
+func (uc *analyticsUseCase) Rebuild(businessID, startDate, endDate string) (*Domain.AnalyticsRebuildResult, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	clock := clockFor(business)
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	if toDay.Sub(fromDay) >= maxAnalyticsRebuildDays*24*time.Hour {
+		return nil, fmt.Errorf("rebuild range cannot exceed %d days", maxAnalyticsRebuildDays)
+	}
+
+	result := &Domain.AnalyticsRebuildResult{
+		StartDate: fromDay.Format("2006-01-02"),
+		EndDate:   toDay.Format("2006-01-02"),
+	}
+
+	for day := fromDay; !day.After(toDay); day = day.AddDate(0, 0, 1) {
+		if err := uc.rebuildDay(businessID, day, clock); err != nil {
+			return nil, err
+		}
+		result.DaysRebuilt++
+	}
+
+	return result, nil
+}
+
+func (uc *analyticsUseCase) GetSalesSummary(businessID string, start, end time.Time) (*Domain.SaleSummary, error) {
+	uc.Refresh(businessID)
+	return uc.summaryRepo.Summary(businessID, start, end)
+}
+
+func (uc *analyticsUseCase) GetSalesBreakdown(businessID, startDate, endDate string, by Domain.SalesSummaryDimension) (*Domain.SalesBreakdown, error) {
+	if by == "" {
+		by = Domain.SalesSummaryByDay
+	}
+	if !by.IsValid() {
+		return nil, fmt.Errorf("invalid breakdown dimension: %s", by)
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	uc.Refresh(businessID)
+
+	clock := clockFor(business)
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clock)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []Domain.SalesBreakdownLine
+	if by == Domain.SalesSummaryByHour {
+		if toDay.Sub(fromDay) >= maxHourlyBreakdownDays*24*time.Hour {
+			return nil, fmt.Errorf("hourly breakdown range cannot exceed %d days", maxHourlyBreakdownDays)
+		}
+		start, end := clock.Range(fromDay, toDay)
+		lines, err = uc.summaryRepo.HourlyBreakdown(businessID, start, end)
+	} else {
+		lines, err = uc.summaryRepo.DailyBreakdown(businessID, fromDay, toDay, by)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if lines == nil {
+		lines = []Domain.SalesBreakdownLine{}
+	}
+
+	return &Domain.SalesBreakdown{
+		StartDate: fromDay.Format("2006-01-02"),
+		EndDate:   toDay.Format("2006-01-02"),
+		By:        by,
+		Lines:     lines,
+	}, nil
+}
+
+// GetTrafficHeatmap totals sales by weekday and hour of day, for planning staffing
+func (uc *analyticsUseCase) GetTrafficHeatmap(businessID, startDate, endDate, deviceID string) (*Domain.TrafficHeatmap, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	uc.Refresh(businessID)
+
+	clock := clockFor(business)
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clock)
+	if err != nil {
+		return nil, err
+	}
+	if toDay.Sub(fromDay) >= maxAnalyticsRebuildDays*24*time.Hour {
+		return nil, fmt.Errorf("heatmap range cannot exceed %d days", maxAnalyticsRebuildDays)
+	}
+
+	start, end := clock.Range(fromDay, toDay)
+
+	counted, err := uc.summaryRepo.Heatmap(businessID, start, end, timezoneName(clock.loc), deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	// How often each weekday occurs in the range, for per-day averages
+	var occurrences [7]int
+	for day := fromDay; !day.After(toDay); day = day.AddDate(0, 0, 1) {
+		occurrences[day.Weekday()]++
+	}
+
+	heatmap := &Domain.TrafficHeatmap{
+		StartDate: fromDay.Format("2006-01-02"),
+		EndDate:   toDay.Format("2006-01-02"),
+		Timezone:  timezoneName(clock.loc),
+		DeviceID:  deviceID,
+		Cells:     make([]Domain.HeatmapCell, 7*24),
+	}
+
+	for i := range heatmap.Cells {
+		heatmap.Cells[i].DayOfWeek = i / 24
+		heatmap.Cells[i].Day = time.Weekday(i / 24).String()
+		heatmap.Cells[i].Hour = i % 24
+	}
+
+	for _, c := range counted {
+		if c.DayOfWeek < 0 || c.DayOfWeek > 6 || c.Hour < 0 || c.Hour > 23 {
+			continue
+		}
+		cell := &heatmap.Cells[c.DayOfWeek*24+c.Hour]
+		cell.Transactions += c.Transactions
+		cell.Revenue += c.Revenue
+	}
+
+	for i := range heatmap.Cells {
+		cell := &heatmap.Cells[i]
+		if n := occurrences[cell.DayOfWeek]; n > 0 {
+			cell.AverageTransactions = roundCurrency(float64(cell.Transactions) / float64(n))
+			cell.AverageRevenue = Domain.NewMoney(cell.Revenue.Float64() / float64(n))
+		}
+		if cell.Transactions > 0 && (heatmap.Peak == nil || cell.Transactions > heatmap.Peak.Transactions) {
+			peak := *cell
+			heatmap.Peak = &peak
+		}
+	}
+
+	return heatmap, nil
+}
+
+func (uc *analyticsUseCase) MarkDirty(businessID string, at ...time.Time) {
+	if businessID == "" || len(at) == 0 {
+		return
+	}
+
+	uc.mu.Lock()
+	defer uc.mu.Unlock()
+
+	for _, t := range at {
+		if !t.IsZero() {
+			uc.dirty[businessID] = append(uc.dirty[businessID], t)
+		}
+	}
+}
+
+func (uc *analyticsUseCase) OnSaleRecorded(ctx context.Context, event *Domain.DomainEvent) error {
+	var sale Domain.Sale
+	if err := event.Decode(&sale); err != nil {
+		return err
+	}
+	uc.MarkDirty(sale.BusinessID.Hex(), sale.CreatedAt)
+	return nil
+}
+
+// FlushDirty re-aggregates every queued business day into the P&L and sales summary tables
+func (uc *analyticsUseCase) FlushDirty() error {
+	uc.mu.Lock()
+	pending := uc.dirty
+	uc.dirty = make(map[string][]time.Time)
+	uc.mu.Unlock()
+
+	var failed int
+	for businessID, times := range pending {
+		if err := uc.flushBusiness(businessID, times); err != nil {
+			Infrastructure.Logger.Warn("failed to refresh analytics for business", "business_id", businessID, "error", err)
+			uc.MarkDirty(businessID, times...)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("analytics refresh failed for %d businesses", failed)
+	}
+	return nil
+}
+
+func (uc *analyticsUseCase) CompareProfitAndLoss(businessID, startDate, endDate string, granularity Domain.PeriodType, calendar Domain.Calendar, opts Domain.CompareOptions) (*Domain.ReportComparison, error) {
+	return uc.compare(businessID, startDate, endDate, opts, func(start, end string) (interface{}, error) {
+		return uc.GetProfitAndLoss(businessID, start, end, granularity, calendar)
+	})
+}
+
+func (uc *analyticsUseCase) CompareCategoryMargins(businessID, startDate, endDate string, opts Domain.CompareOptions) (*Domain.ReportComparison, error) {
+	return uc.compare(businessID, startDate, endDate, opts, func(start, end string) (interface{}, error) {
+		return uc.GetCategoryMargins(businessID, start, end)
+	})
+}
+
+func (uc *analyticsUseCase) CompareProductMargins(businessID, startDate, endDate string, limit int, opts Domain.CompareOptions) (*Domain.ReportComparison, error) {
+	return uc.compare(businessID, startDate, endDate, opts, func(start, end string) (interface{}, error) {
+		return uc.GetProductMargins(businessID, start, end, limit)
+	})
+}
+
+// compare resolves the day range, finds the comparable prior range and runs report over both
+func (uc *analyticsUseCase) compare(businessID, startDate, endDate string, opts Domain.CompareOptions, report func(start, end string) (interface{}, error)) (*Domain.ReportComparison, error) {
+	opts, err := normalizeCompareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	fromDay, toDay, err := uc.prepareRange(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	days := int(toDay.Sub(fromDay).Hours()/24) + 1
+	prevFrom, prevTo := previousRange(fromDay, toDay, days, opts)
+
+	current, err := report(fromDay.Format("2006-01-02"), toDay.Format("2006-01-02"))
+	if err != nil {
+		return nil, err
+	}
+	previous, err := report(prevFrom.Format("2006-01-02"), prevTo.Format("2006-01-02"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate report for the previous period: %w", err)
+	}
+
+	return newReportComparison(opts, fromDay, toDay, prevFrom, prevTo, current, previous), nil
+}
+
+// prepareRange validates the business, brings its aggregates up to date and resolves the day range
+func (uc *analyticsUseCase) prepareRange(businessID, startDate, endDate string) (time.Time, time.Time, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return time.Time{}, time.Time{}, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return time.Time{}, time.Time{}, Domain.NotFoundError("business not found")
+	}
+
+	uc.Refresh(businessID)
+
+	return parseDayRange(startDate, endDate, clockFor(business))
+}
+
+func (uc *analyticsUseCase) Refresh(businessID string) {
+	uc.mu.Lock()
+	times := uc.dirty[businessID]
+	delete(uc.dirty, businessID)
+	uc.mu.Unlock()
+
+	if len(times) == 0 {
+		return
+	}
+
+	if err := uc.flushBusiness(businessID, times); err != nil {
+		uc.MarkDirty(businessID, times...)
+		Infrastructure.Logger.Warn("failed to refresh analytics for business", "business_id", businessID, "error", err)
+	}
+}
+
+func (uc *analyticsUseCase) flushBusiness(businessID string, times []time.Time) error {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil
+	}
+
+	clock := clockFor(business)
+
+	days := make(map[time.Time]bool)
+	for _, t := range times {
+		days[clock.Day(t)] = true
+	}
+
+	for day := range days {
+		if err := uc.rebuildDay(businessID, day, clock); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (uc *analyticsUseCase) rebuildDay(businessID string, day time.Time, clock businessClock) error {
+	start, end := clock.Range(day, day)
+
+	if err := uc.analyticsRepo.RebuildDay(businessID, day, start, end); err != nil {
+		return err
+	}
+	return uc.summaryRepo.RebuildDay(businessID, day, start, end)
+}
+
+// businessClock places times on a shop's business days: days in its timezone that
+// start at its cutoff hour rather than midnight, so a bar's sales at 1 AM count
+// toward the night before. Aggregation, reports and exports all split days with it.
+type businessClock struct {
+	loc    *time.Location
+	cutoff int // Hour of the day days start
+}
+
+func clockFor(business *Domain.Business) businessClock {
+	clock := businessClock{loc: businessLocation(business)}
+	if business != nil && business.DayCutoffHour > 0 && business.DayCutoffHour <= Domain.MaxDayCutoffHour {
+		clock.cutoff = business.DayCutoffHour
+	}
+	return clock
+}
+
+// Day is the business day t falls on, as midnight UTC of its date: the aggregation key
+func (c businessClock) Day(t time.Time) time.Time {
+	local := t.In(c.loc).Add(-time.Duration(c.cutoff) * time.Hour)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// Start is the instant the business day day starts
+func (c businessClock) Start(day time.Time) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), c.cutoff, 0, 0, 0, c.loc)
+}
+
+// Range is the instants from the start of fromDay up to, not including, the start
+// of the day after toDay
+func (c businessClock) Range(fromDay, toDay time.Time) (time.Time, time.Time) {
+	return c.Start(fromDay), c.Start(toDay.AddDate(0, 0, 1))
+}
+
+// parseDayRange reads YYYY-MM-DD bounds, defaulting to the last 30 days including today
+func parseDayRange(startDate, endDate string, clock businessClock) (time.Time, time.Time, error) {
+	toDay := clock.Day(time.Now())
+	if endDate != "" {
+		parsed, err := time.Parse("2006-01-02", endDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid end_date, expected YYYY-MM-DD: %w", err)
+		}
+		toDay = parsed
+	}
+
+	fromDay := toDay.AddDate(0, 0, -29)
+	if startDate != "" {
+		parsed, err := time.Parse("2006-01-02", startDate)
+		if err != nil {
+			return time.Time{}, time.Time{}, fmt.Errorf("invalid start_date, expected YYYY-MM-DD: %w", err)
+		}
+		fromDay = parsed
+	}
+
+	if fromDay.After(toDay) {
+		return time.Time{}, time.Time{}, fmt.Errorf("start_date must not be after end_date")
+	}
+
+	return fromDay, toDay, nil
+}
+
+// timezoneName is loc as the database understands it. The server's local zone has
+// no name, so it is given as its current UTC offset.
+func timezoneName(loc *time.Location) string {
+	if loc != time.Local {
+		return loc.String()
+	}
+	return time.Now().In(loc).Format("-07:00")
+}
+
+// listedProducts drops the line for sales recorded without a catalog product
+func listedProducts(lines []Domain.MarginLine) []Domain.MarginLine {
+	listed := make([]Domain.MarginLine, 0, len(lines))
+	for _, line := range lines {
+		if line.ProductID != "" {
+			listed = append(listed, line)
+		}
+	}
+	return listed
+}
+
+// pnlPeriodLabel names the bucket day falls in. Labels sort in date order within a calendar.
+func pnlPeriodLabel(day time.Time, granularity Domain.PeriodType, calendar Domain.Calendar) string {
+	if calendar == Domain.CalendarEthiopian {
+		date := Domain.ToEthiopian(day)
+		switch granularity {
+		case Domain.PeriodTypeWeekly:
+			// Ethiopian weeks have no numbering; name them by their Monday
+			monday := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7))
+			return Domain.ToEthiopian(monday).String()
+		case Domain.PeriodTypeMonthly:
+			return fmt.Sprintf("%04d-%02d", date.Year, date.Month)
+		case Domain.PeriodTypeYearly:
+			return fmt.Sprintf("%04d", date.Year)
+		case Domain.PeriodTypeFiscalYearly:
+			year, _ := Domain.FiscalYear(day, calendar)
+			return fmt.Sprintf("FY%04d", year)
+		default:
+			return date.String()
+		}
+	}
+
+	switch granularity {
+	case Domain.PeriodTypeWeekly:
+		year, week := day.ISOWeek()
+		return fmt.Sprintf("%d-W%02d", year, week)
+	case Domain.PeriodTypeMonthly:
+		return day.Format("2006-01")
+	case Domain.PeriodTypeYearly:
+		return day.Format("2006")
+	case Domain.PeriodTypeFiscalYearly:
+		year, _ := Domain.FiscalYear(day, calendar)
+		return fmt.Sprintf("FY%04d", year)
+	default:
+		return day.Format("2006-01-02")
+	}
+}
+
+// normalizeCalendar defaults to the Gregorian calendar
+func normalizeCalendar(calendar Domain.Calendar) (Domain.Calendar, error) {
+	if calendar == "" {
+		return Domain.CalendarGregorian, nil
+	}
+	if !calendar.IsValid() {
+		return calendar, fmt.Errorf("invalid calendar: %s (use gregorian or ethiopian)", calendar)
+	}
+	return calendar, nil
+}
+
+func addPnLDay(line *Domain.PnLLine, day Domain.PnLDayTotal) {
+	line.Revenue += day.Revenue
+	line.COGS += day.COGS
+	line.Expenses += day.Expenses
+}
+
+func finishPnLLine(line *Domain.PnLLine) {
+	line.GrossProfit = line.Revenue - line.COGS
+	line.NetProfit = line.GrossProfit - line.Expenses
+	if line.Revenue > 0 {
+		line.GrossMargin = roundCurrency(line.GrossProfit.Float64() / line.Revenue.Float64() * 100)
+		line.NetMargin = roundCurrency(line.NetProfit.Float64() / line.Revenue.Float64() * 100)
+	}
+}
+
+func newMarginReport(fromDay, toDay time.Time, lines []Domain.MarginLine) *Domain.MarginReport {
+	var totalRevenue Domain.Money
+	for _, line := range lines {
+		totalRevenue += line.Revenue
+	}
+
+	for i := range lines {
+		line := &lines[i]
+		line.GrossProfit = line.Revenue - line.COGS
+		if line.Revenue > 0 {
+			line.GrossMargin = roundCurrency(line.GrossProfit.Float64() / line.Revenue.Float64() * 100)
+		}
+		if totalRevenue > 0 {
+			line.RevenueShare = roundCurrency(line.Revenue.Float64() / totalRevenue.Float64() * 100)
+		}
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Revenue > lines[j].Revenue })
+	if lines == nil {
+		lines = []Domain.MarginLine{}
+	}
+
+	return &Domain.MarginReport{
+		StartDate: fromDay.Format("2006-01-02"),
+		EndDate:   toDay.Format("2006-01-02"),
+		Lines:     lines,
+	}
+}
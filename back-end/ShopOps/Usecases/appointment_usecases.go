@@ -0,0 +1,513 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type AppointmentUseCase interface {
+	// GetSlots lists the start times on date (YYYY-MM-DD, in the shop's timezone)
+	// when the service can be booked, with the staff free at each; only the given
+	// staff member's when employeeID is set
+	GetSlots(ctx context.Context, businessID, serviceID, date string, employeeID *string) ([]Domain.BookingSlot, error)
+	BookAppointment(ctx context.Context, businessID, userID string, req Domain.CreateAppointmentRequest) (*Domain.Appointment, error)
+	GetAppointments(businessID string, filters Domain.AppointmentFilters) ([]Domain.Appointment, error)
+	GetAppointment(ctx context.Context, id, businessID string) (*Domain.Appointment, error)
+	RescheduleAppointment(ctx context.Context, id, businessID string, req Domain.RescheduleAppointmentRequest) (*Domain.Appointment, error)
+	CancelAppointment(ctx context.Context, id, businessID string) (*Domain.Appointment, error)
+	MarkNoShow(ctx context.Context, id, businessID string) (*Domain.Appointment, error)
+	// CompleteAppointment records the sale of the service, credited to the staff
+	// member who did it
+	CompleteAppointment(ctx context.Context, id, businessID, userID string, req Domain.CompleteAppointmentRequest) (*Domain.Appointment, error)
+	// SendReminders texts customers whose appointments are within their shop's
+	// reminder time, for the scheduler
+	SendReminders() error
+}
+
+type appointmentUseCase struct {
+	appointmentRepo Domain.AppointmentRepository
+	inventoryRepo   Domain.ProductRepository
+	employeeRepo    Domain.EmployeeRepository
+	businessRepo    Domain.BusinessRepository
+	settingsRepo    Domain.ShopSettingsRepository
+	salesUC         SalesUseCase
+	smsUC           SMSUseCase
+}
+
+func NewAppointmentUseCase(
+	appointmentRepo Domain.AppointmentRepository,
+	inventoryRepo Domain.ProductRepository,
+	employeeRepo Domain.EmployeeRepository,
+	businessRepo Domain.BusinessRepository,
+	settingsRepo Domain.ShopSettingsRepository,
+	salesUC SalesUseCase,
+	smsUC SMSUseCase,
+) AppointmentUseCase {
+	return &appointmentUseCase{
+		appointmentRepo: appointmentRepo,
+		inventoryRepo:   inventoryRepo,
+		employeeRepo:    employeeRepo,
+		businessRepo:    businessRepo,
+		settingsRepo:    settingsRepo,
+		salesUC:         salesUC,
+		smsUC:           smsUC,
+	}
+}
+
+// bookingContext is what a booking for a service is checked against
+type bookingContext struct {
+	business *Domain.Business
+	settings *Domain.ShopSettings
+	service  *Domain.Product
+	location *time.Location
+	duration time.Duration
+	// The staff who may take it; a single nil in a shop without staff
+	staff []*primitive.ObjectID
+}
+
+func (uc *appointmentUseCase) bookingContext(ctx context.Context, businessID, serviceID string, employeeID *string) (*bookingContext, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	settings, err := uc.settingsRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find shop settings: %w", err)
+	}
+	if settings == nil {
+		defaults := Domain.DefaultShopSettings
+		settings = &defaults
+	}
+
+	service, err := uc.inventoryRepo.FindInBusiness(ctx, serviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if service == nil || service.DeletedAt != nil {
+		return nil, Domain.NotFoundError("service not found")
+	}
+	if !service.Service {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "only services can be booked")
+	}
+
+	minutes := service.DurationMinutes
+	if minutes <= 0 {
+		minutes = settings.BookingSlotMinutes
+	}
+
+	bc := &bookingContext{
+		business: business,
+		settings: settings,
+		service:  service,
+		location: businessLocation(business),
+		duration: time.Duration(minutes) * time.Minute,
+	}
+
+	if employeeID != nil && *employeeID != "" {
+		employee, err := uc.employeeRepo.FindInBusiness(ctx, *employeeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find employee: %w", err)
+		}
+		if employee == nil {
+			return nil, Domain.NotFoundError("employee not found")
+		}
+		if employee.Status != Domain.EmployeeStatusActive {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "employee is not active")
+		}
+		bc.staff = []*primitive.ObjectID{&employee.ID}
+		return bc, nil
+	}
+
+	active := Domain.EmployeeStatusActive
+	employees, err := uc.employeeRepo.FindByBusinessID(businessID, &active)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employees: %w", err)
+	}
+	for i := range employees {
+		bc.staff = append(bc.staff, &employees[i].ID)
+	}
+	if len(bc.staff) == 0 {
+		bc.staff = []*primitive.ObjectID{nil}
+	}
+	return bc, nil
+}
+
+// dayHours returns when bookings open and close on the day of t
+func (bc *bookingContext) dayHours(t time.Time) (time.Time, time.Time) {
+	local := t.In(bc.location)
+	day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, bc.location)
+	return day.Add(time.Duration(bc.settings.BookingOpensHour) * time.Hour),
+		day.Add(time.Duration(bc.settings.BookingClosesHour) * time.Hour)
+}
+
+// freeStaff returns the staff with nothing booked from start to end, among
+// booked; except is the appointment being moved
+func (bc *bookingContext) freeStaff(booked []Domain.Appointment, start, end time.Time, except *primitive.ObjectID) []*primitive.ObjectID {
+	var free []*primitive.ObjectID
+	for _, staff := range bc.staff {
+		busy := false
+		for i := range booked {
+			if except != nil && booked[i].ID == *except {
+				continue
+			}
+			// Staff are kept busy by their own appointments; without staff, the shop
+			// takes one at a time
+			if staff != nil && (booked[i].EmployeeID == nil || *booked[i].EmployeeID != *staff) {
+				continue
+			}
+			if booked[i].Overlaps(start, end) {
+				busy = true
+				break
+			}
+		}
+		if !busy {
+			free = append(free, staff)
+		}
+	}
+	return free
+}
+
+func (uc *appointmentUseCase) GetSlots(ctx context.Context, businessID, serviceID, date string, employeeID *string) ([]Domain.BookingSlot, error) {
+	bc, err := uc.bookingContext(ctx, businessID, serviceID, employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	day, err := time.ParseInLocation("2006-01-02", date, bc.location)
+	if err != nil {
+		return nil, Domain.ValidationError("date must be YYYY-MM-DD")
+	}
+	opens, closes := bc.dayHours(day)
+
+	booked, err := uc.appointmentRepo.FindBooked(businessID, opens, closes)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	step := time.Duration(bc.settings.BookingSlotMinutes) * time.Minute
+	slots := []Domain.BookingSlot{}
+	for start := opens; !start.Add(bc.duration).After(closes); start = start.Add(step) {
+		if start.Before(now) {
+			continue
+		}
+		end := start.Add(bc.duration)
+		free := bc.freeStaff(booked, start, end, nil)
+		if len(free) == 0 {
+			continue
+		}
+
+		slot := Domain.BookingSlot{StartsAt: start, EndsAt: end}
+		for _, staff := range free {
+			if staff != nil {
+				slot.EmployeeIDs = append(slot.EmployeeIDs, staff.Hex())
+			}
+		}
+		slots = append(slots, slot)
+	}
+
+	return slots, nil
+}
+
+// checkTime makes sure an appointment from start fits in the shop's booking
+// hours, and returns when it ends
+func (bc *bookingContext) checkTime(start time.Time) (time.Time, error) {
+	if !start.After(time.Now()) {
+		return time.Time{}, Domain.ValidationError("starts_at must be in the future")
+	}
+	end := start.Add(bc.duration)
+	opens, closes := bc.dayHours(start)
+	if start.Before(opens) || end.After(closes) {
+		return time.Time{}, Domain.ValidationError(fmt.Sprintf("appointments must fall between %02d:00 and %02d:00",
+			bc.settings.BookingOpensHour, bc.settings.BookingClosesHour))
+	}
+	return end, nil
+}
+
+func (uc *appointmentUseCase) BookAppointment(ctx context.Context, businessID, userID string, req Domain.CreateAppointmentRequest) (*Domain.Appointment, error) {
+	bc, err := uc.bookingContext(ctx, businessID, req.ServiceID, req.EmployeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := req.StartsAt.Truncate(time.Minute)
+	end, err := bc.checkTime(start)
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := uc.appointmentRepo.FindBooked(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	free := bc.freeStaff(booked, start, end, nil)
+	if len(free) == 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "no one is free at that time; choose another")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	appointment := &Domain.Appointment{
+		BusinessID:    bc.business.ID,
+		ServiceID:     bc.service.ID,
+		ServiceName:   bc.service.Name,
+		EmployeeID:    free[0],
+		CustomerName:  req.CustomerName,
+		CustomerPhone: normalizePhone(req.CustomerPhone, bc.business.Country),
+		StartsAt:      start,
+		EndsAt:        end,
+		Price:         bc.service.SellingPrice,
+		Notes:         req.Notes,
+		Status:        Domain.AppointmentBooked,
+		CreatedBy:     objUserID,
+	}
+	if err := uc.appointmentRepo.Create(appointment); err != nil {
+		return nil, err
+	}
+
+	return appointment, nil
+}
+
+func (uc *appointmentUseCase) GetAppointments(businessID string, filters Domain.AppointmentFilters) ([]Domain.Appointment, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	appointments, err := uc.appointmentRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if appointments == nil {
+		appointments = []Domain.Appointment{}
+	}
+	return appointments, nil
+}
+
+func (uc *appointmentUseCase) GetAppointment(ctx context.Context, id, businessID string) (*Domain.Appointment, error) {
+	appointment, err := uc.appointmentRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if appointment == nil {
+		return nil, Domain.NotFoundError("appointment not found")
+	}
+	return appointment, nil
+}
+
+// bookedAppointment finds the appointment for a change, which only a booked one takes
+func (uc *appointmentUseCase) bookedAppointment(ctx context.Context, id, businessID string) (*Domain.Appointment, error) {
+	appointment, err := uc.GetAppointment(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.Status != Domain.AppointmentBooked {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("appointment is %s", appointment.Status))
+	}
+	return appointment, nil
+}
+
+func (uc *appointmentUseCase) RescheduleAppointment(ctx context.Context, id, businessID string, req Domain.RescheduleAppointmentRequest) (*Domain.Appointment, error) {
+	appointment, err := uc.bookedAppointment(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Stay with the same staff member unless another is asked for
+	employeeID := req.EmployeeID
+	if employeeID == nil && appointment.EmployeeID != nil {
+		current := appointment.EmployeeID.Hex()
+		employeeID = &current
+	}
+	bc, err := uc.bookingContext(ctx, businessID, appointment.ServiceID.Hex(), employeeID)
+	if err != nil {
+		return nil, err
+	}
+
+	start := req.StartsAt.Truncate(time.Minute)
+	end, err := bc.checkTime(start)
+	if err != nil {
+		return nil, err
+	}
+
+	booked, err := uc.appointmentRepo.FindBooked(businessID, start, end)
+	if err != nil {
+		return nil, err
+	}
+	free := bc.freeStaff(booked, start, end, &appointment.ID)
+	if len(free) == 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "no one is free at that time; choose another")
+	}
+
+	appointment.EmployeeID = free[0]
+	appointment.StartsAt = start
+	appointment.EndsAt = end
+	moved, err := uc.appointmentRepo.Reschedule(appointment)
+	if err != nil {
+		return nil, err
+	}
+	if !moved {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "appointment was changed by someone else; reload it")
+	}
+
+	return appointment, nil
+}
+
+func (uc *appointmentUseCase) CancelAppointment(ctx context.Context, id, businessID string) (*Domain.Appointment, error) {
+	appointment, err := uc.bookedAppointment(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.close(appointment, Domain.AppointmentCancelled)
+}
+
+func (uc *appointmentUseCase) MarkNoShow(ctx context.Context, id, businessID string) (*Domain.Appointment, error) {
+	appointment, err := uc.bookedAppointment(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if appointment.StartsAt.After(time.Now()) {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "appointment has not started yet")
+	}
+	return uc.close(appointment, Domain.AppointmentNoShow)
+}
+
+// close ends a booked appointment with status
+func (uc *appointmentUseCase) close(appointment *Domain.Appointment, status Domain.AppointmentStatus) (*Domain.Appointment, error) {
+	now := time.Now()
+	closed, err := uc.appointmentRepo.SetStatus(appointment.ID, Domain.AppointmentBooked, status, now)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "appointment was changed by someone else; reload it")
+	}
+
+	appointment.Status = status
+	appointment.ClosedAt = &now
+	return appointment, nil
+}
+
+func (uc *appointmentUseCase) CompleteAppointment(ctx context.Context, id, businessID, userID string, req Domain.CompleteAppointmentRequest) (*Domain.Appointment, error) {
+	// Gift cards and points need a reference, taken at the till
+	switch req.PaymentMethod {
+	case Domain.PaymentMethodCash, Domain.PaymentMethodCard, Domain.PaymentMethodMobile,
+		Domain.PaymentMethodBank, Domain.PaymentMethodCredit, Domain.PaymentMethodOther:
+	default:
+		return nil, Domain.ValidationError(fmt.Sprintf("invalid payment method: %s", req.PaymentMethod))
+	}
+
+	appointment, err := uc.bookedAppointment(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	// Closed first, so the service is not sold twice
+	if appointment, err = uc.close(appointment, Domain.AppointmentCompleted); err != nil {
+		return nil, err
+	}
+
+	unitPrice := appointment.Price
+	if req.UnitPrice != nil {
+		unitPrice = *req.UnitPrice
+	}
+	serviceID := appointment.ServiceID.Hex()
+	var employeeID *string
+	if appointment.EmployeeID != nil {
+		staff := appointment.EmployeeID.Hex()
+		employeeID = &staff
+	}
+
+	sale, err := uc.salesUC.CreateSale(ctx, businessID, userID, Domain.CreateSaleRequest{
+		ProductID:     &serviceID,
+		CustomerName:  appointment.CustomerName,
+		CustomerPhone: appointment.CustomerPhone,
+		Quantity:      1,
+		UnitPrice:     unitPrice,
+		Discount:      req.Discount,
+		PaymentMethod: req.PaymentMethod,
+		Notes:         "Appointment: " + appointment.ServiceName,
+		LocalID:       "appointment:" + appointment.ID.Hex(),
+		EmployeeID:    employeeID,
+	})
+	if err != nil {
+		// Still booked, so it can be completed again
+		if _, reopenErr := uc.appointmentRepo.SetStatus(appointment.ID, Domain.AppointmentCompleted, Domain.AppointmentBooked, time.Now()); reopenErr != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to reopen appointment after its sale failed", "appointment_id", appointment.ID.Hex(), "error", reopenErr)
+		}
+		return nil, err
+	}
+
+	if err := uc.appointmentRepo.SetSale(appointment.ID, sale.ID); err != nil {
+		Infrastructure.LoggerFrom(ctx).Warn("failed to record sale on appointment", "sale_id", sale.ID.Hex(), "appointment_id", appointment.ID.Hex(), "error", err)
+	}
+	appointment.SaleID = &sale.ID
+
+	return appointment, nil
+}
+
+func (uc *appointmentUseCase) SendReminders() error {
+	now := time.Now()
+	appointments, err := uc.appointmentRepo.FindDueReminders(now, now.Add(Domain.MaxBookingReminderHours*time.Hour))
+	if err != nil {
+		return err
+	}
+
+	businesses := make(map[primitive.ObjectID]*Domain.Business)
+	reminderHours := make(map[primitive.ObjectID]int)
+	for i := range appointments {
+		appointment := &appointments[i]
+
+		business, ok := businesses[appointment.BusinessID]
+		if !ok {
+			if business, err = uc.businessRepo.FindByID(appointment.BusinessID.Hex()); err != nil {
+				return err
+			}
+			businesses[appointment.BusinessID] = business
+
+			settings, err := uc.settingsRepo.FindSettings(appointment.BusinessID.Hex())
+			if err != nil {
+				return fmt.Errorf("failed to find shop settings: %w", err)
+			}
+			reminderHours[appointment.BusinessID] = Domain.DefaultShopSettings.BookingReminderHours
+			if settings != nil {
+				reminderHours[appointment.BusinessID] = settings.BookingReminderHours
+			}
+		}
+		hours := reminderHours[appointment.BusinessID]
+		if business == nil || hours <= 0 || appointment.StartsAt.Sub(now) > time.Duration(hours)*time.Hour {
+			continue
+		}
+
+		at := appointment.StartsAt.In(businessLocation(business)).Format("Mon 2 Jan 15:04")
+		body := Infrastructure.Translate(business.Language, "sms.appointment_reminder",
+			business.Name, appointment.ServiceName, at)
+
+		message, err := uc.smsUC.SendNotice(business.ID.Hex(), appointment.CustomerPhone, nil, body)
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to text reminder for appointment", "appointment_id", appointment.ID.Hex(), "error", err)
+			continue
+		}
+		if message.Status == Domain.SMSStatusFailed {
+			Infrastructure.Logger.Warn("failed to text reminder for appointment", "appointment_id", appointment.ID.Hex(), "error", message.Error)
+		}
+
+		// Marked either way, so a failing number is not texted every run
+		if err := uc.appointmentRepo.MarkReminded(appointment.ID, time.Now()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,174 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+// ArchiveUseCase moves sales and stock movements past the archive age out of the
+// live collections, daily and when an admin asks
+type ArchiveUseCase interface {
+	// ArchiveDue moves every record past the archive age, for the periodic run
+	ArchiveDue() error
+	// TriggerArchive queues a run moving records created before the request's date,
+	// which must be past the archive age, so recent days are never read from the archive
+	TriggerArchive(ctx context.Context, actor string, req Domain.RunArchiveRequest) (*Domain.Job, error)
+	RunArchiveJob(ctx context.Context, job *Domain.Job) error
+	GetRuns(limit int) ([]Domain.ArchiveRun, error)
+}
+
+const defaultArchiveDays = 1095
+
+type archiveUseCase struct {
+	archiveRepo Domain.ArchiveRepository
+	jobQueue    Infrastructure.JobQueue
+	cfg         Infrastructure.ArchiveConfig
+}
+
+func NewArchiveUseCase(
+	archiveRepo Domain.ArchiveRepository,
+	jobQueue Infrastructure.JobQueue,
+	cfg Infrastructure.ArchiveConfig,
+) ArchiveUseCase {
+	return &archiveUseCase{
+		archiveRepo: archiveRepo,
+		jobQueue:    jobQueue,
+		cfg:         cfg,
+	}
+}
+
+func (uc *archiveUseCase) ArchiveDue() error {
+	if uc.cfg.AfterDays == 0 {
+		return nil
+	}
+	return uc.runChecked(uc.cutoff(), false, "")
+}
+
+func (uc *archiveUseCase) TriggerArchive(ctx context.Context, actor string, req Domain.RunArchiveRequest) (*Domain.Job, error) {
+	before := uc.cutoff()
+	if req.Before != "" {
+		parsed, err := time.Parse("2006-01-02", req.Before)
+		if err != nil {
+			return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "before must be a date like 2006-01-02")
+		}
+		if parsed.After(before) {
+			return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument,
+				fmt.Sprintf("before cannot be later than %s, the archive age", before.Format("2006-01-02")))
+		}
+		before = parsed
+	}
+
+	return uc.jobQueue.Enqueue(ctx, Domain.JobTypeArchive, "", map[string]string{
+		"before":       before.Format(time.RFC3339),
+		"compact":      strconv.FormatBool(req.Compact),
+		"requested_by": actor,
+	})
+}
+
+func (uc *archiveUseCase) RunArchiveJob(ctx context.Context, job *Domain.Job) error {
+	before, err := time.Parse(time.RFC3339, job.Payload["before"])
+	if err != nil {
+		return fmt.Errorf("archive job has an invalid date: %w", err)
+	}
+	return uc.runChecked(before, job.Payload["compact"] == "true", job.Payload["requested_by"])
+}
+
+func (uc *archiveUseCase) GetRuns(limit int) ([]Domain.ArchiveRun, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	runs, err := uc.archiveRepo.FindRuns(limit)
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		runs = []Domain.ArchiveRun{}
+	}
+	return runs, nil
+}
+
+// cutoff is the start of the day the archive age reaches back to. With the daily run
+// off an admin can still archive, as far back as the default age.
+func (uc *archiveUseCase) cutoff() time.Time {
+	days := uc.cfg.AfterDays
+	if days == 0 {
+		days = defaultArchiveDays
+	}
+	now := time.Now().UTC()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return today.AddDate(0, 0, -days)
+}
+
+func (uc *archiveUseCase) runChecked(before time.Time, compact bool, actor string) error {
+	run, err := uc.run(before, compact, actor)
+	if err != nil {
+		return err
+	}
+	if run.Error != "" {
+		return fmt.Errorf("archive run stopped: %s", run.Error)
+	}
+	return nil
+}
+
+// run moves the records in batches until none are left, recording how far it got
+// even when a batch fails or the server starts shutting down
+func (uc *archiveUseCase) run(before time.Time, compact bool, actor string) (*Domain.ArchiveRun, error) {
+	run := &Domain.ArchiveRun{
+		Before:      before,
+		Moved:       make(map[string]int64),
+		RequestedBy: actor,
+		StartedAt:   time.Now(),
+	}
+
+	err := uc.move(run)
+	if err == nil && compact {
+		for _, collection := range Domain.ArchivedCollections {
+			if err = uc.archiveRepo.Compact(collection); err != nil {
+				break
+			}
+		}
+		run.Compacted = err == nil
+	}
+	if err != nil {
+		run.Error = err.Error()
+	}
+	run.FinishedAt = time.Now()
+
+	// The daily run has nothing to record most days
+	moved := int64(0)
+	for _, count := range run.Moved {
+		moved += count
+	}
+	if run.Error == "" && actor == "" && moved == 0 {
+		return run, nil
+	}
+	if err := uc.archiveRepo.SaveRun(run); err != nil {
+		return nil, err
+	}
+	return run, nil
+}
+
+func (uc *archiveUseCase) move(run *Domain.ArchiveRun) error {
+	for _, collection := range Domain.ArchivedCollections {
+		for {
+			if Infrastructure.IsShuttingDown() {
+				return fmt.Errorf("server shutting down")
+			}
+			moved, err := uc.archiveRepo.Move(collection, run.Before, uc.cfg.BatchSize)
+			if err != nil {
+				return err
+			}
+			run.Moved[collection] += moved
+			if moved == 0 {
+				break
+			}
+		}
+	}
+	return nil
+}
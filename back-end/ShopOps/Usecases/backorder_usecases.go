@@ -0,0 +1,343 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+type BackorderUseCase interface {
+	GetBackorders(businessID string, filters Domain.BackorderFilters) ([]Domain.Backorder, error)
+	GetBackorder(ctx context.Context, id, businessID string) (*Domain.Backorder, error)
+	// CollectBackorder records the customer taking a ready backorder away
+	CollectBackorder(ctx context.Context, id, businessID string) (*Domain.Backorder, error)
+	// CancelBackorder gives up on the backorder and puts what was set aside for it
+	// back into stock
+	CancelBackorder(ctx context.Context, id, businessID, userID string) (*Domain.Backorder, error)
+	// OnSaleChanged opens a backorder for a sale that sold beyond stock and cancels
+	// it when the sale is voided, for the event bus
+	OnSaleChanged(ctx context.Context, event *Domain.DomainEvent) error
+	// OnStockAdjusted sets stock coming in aside for the product's open
+	// backorders, oldest first, for the event bus
+	OnStockAdjusted(ctx context.Context, event *Domain.DomainEvent) error
+	// NotifyReady texts the customer that their backorder is ready to collect,
+	// for the event bus
+	NotifyReady(ctx context.Context, event *Domain.DomainEvent) error
+}
+
+type backorderUseCase struct {
+	backorderRepo   Domain.BackorderRepository
+	salesRepo       Domain.SaleRepository
+	inventoryRepo   Domain.ProductRepository
+	reservationRepo Domain.StockReservationRepository
+	businessRepo    Domain.BusinessRepository
+	smsUC           SMSUseCase
+}
+
+func NewBackorderUseCase(
+	backorderRepo Domain.BackorderRepository,
+	salesRepo Domain.SaleRepository,
+	inventoryRepo Domain.ProductRepository,
+	reservationRepo Domain.StockReservationRepository,
+	businessRepo Domain.BusinessRepository,
+	smsUC SMSUseCase,
+) BackorderUseCase {
+	return &backorderUseCase{
+		backorderRepo:   backorderRepo,
+		salesRepo:       salesRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		businessRepo:    businessRepo,
+		smsUC:           smsUC,
+	}
+}
+
+func (uc *backorderUseCase) GetBackorders(businessID string, filters Domain.BackorderFilters) ([]Domain.Backorder, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	backorders, err := uc.backorderRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if backorders == nil {
+		backorders = []Domain.Backorder{}
+	}
+	return backorders, nil
+}
+
+func (uc *backorderUseCase) GetBackorder(ctx context.Context, id, businessID string) (*Domain.Backorder, error) {
+	backorder, err := uc.backorderRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if backorder == nil {
+		return nil, Domain.NotFoundError("backorder not found")
+	}
+	return backorder, nil
+}
+
+func (uc *backorderUseCase) CollectBackorder(ctx context.Context, id, businessID string) (*Domain.Backorder, error) {
+	backorder, err := uc.GetBackorder(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if backorder.Status != Domain.BackorderReady {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("backorder is %s, not ready", backorder.Status))
+	}
+
+	now := time.Now()
+	collected, err := uc.backorderRepo.SetStatus(backorder.ID, []Domain.BackorderStatus{Domain.BackorderReady}, Domain.BackorderCollected, now)
+	if err != nil {
+		return nil, err
+	}
+	if !collected {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "backorder was changed by someone else; reload it")
+	}
+
+	backorder.Status = Domain.BackorderCollected
+	backorder.CollectedAt = &now
+	return backorder, nil
+}
+
+func (uc *backorderUseCase) CancelBackorder(ctx context.Context, id, businessID, userID string) (*Domain.Backorder, error) {
+	backorder, err := uc.GetBackorder(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if backorder.Status != Domain.BackorderOpen && backorder.Status != Domain.BackorderReady {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("backorder is already %s", backorder.Status))
+	}
+
+	cancelled, err := uc.cancel(ctx, backorder, userID)
+	if err != nil {
+		return nil, err
+	}
+	if cancelled == nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "backorder was changed by someone else; reload it")
+	}
+	return cancelled, nil
+}
+
+// cancel closes the backorder and returns what it had set aside to stock; nil
+// when it was already collected or cancelled
+func (uc *backorderUseCase) cancel(ctx context.Context, backorder *Domain.Backorder, userID string) (*Domain.Backorder, error) {
+	from := []Domain.BackorderStatus{Domain.BackorderOpen, Domain.BackorderReady}
+	cancelled, err := uc.backorderRepo.SetStatus(backorder.ID, from, Domain.BackorderCancelled, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, nil
+	}
+
+	// Read back, as stock may have been set aside since it was loaded
+	backorder, err = uc.backorderRepo.FindInBusiness(ctx, backorder.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if backorder == nil {
+		return nil, Domain.NotFoundError("backorder not found")
+	}
+
+	if backorder.Allocated > 0 {
+		referenceID := backorder.ID.Hex()
+		if err := uc.inventoryRepo.AdjustStock(
+			backorder.ProductID.Hex(),
+			backorder.Allocated,
+			Domain.MovementTypeReturn,
+			"Backorder cancelled - restoring stock",
+			&referenceID,
+			"backorder",
+			userID,
+		); err != nil {
+			Infrastructure.Logger.Warn("failed to restore stock for cancelled backorder", "backorder_id", referenceID, "error", err)
+		}
+	}
+
+	return backorder, nil
+}
+
+func (uc *backorderUseCase) OnSaleChanged(ctx context.Context, event *Domain.DomainEvent) error {
+	sale, err := uc.salesRepo.FindInBusiness(ctx, event.AggregateID)
+	if err != nil {
+		return err
+	}
+	if sale == nil || sale.ProductID == nil {
+		return nil
+	}
+
+	backorder, err := uc.backorderRepo.FindBySale(sale.ID)
+	if err != nil {
+		return err
+	}
+
+	if sale.Status != Domain.SaleStatusCompleted {
+		if backorder == nil {
+			return nil
+		}
+		userID := sale.CreatedBy.Hex()
+		if sale.VoidedBy != nil {
+			userID = sale.VoidedBy.Hex()
+		}
+		_, err := uc.cancel(ctx, backorder, userID)
+		return err
+	}
+
+	if backorder != nil || sale.Backordered <= 0 {
+		return nil
+	}
+
+	backorder = &Domain.Backorder{
+		BusinessID:    sale.BusinessID,
+		SaleID:        sale.ID,
+		ProductID:     *sale.ProductID,
+		CustomerName:  sale.CustomerName,
+		CustomerPhone: sale.CustomerPhone,
+		Quantity:      sale.Backordered,
+		Status:        Domain.BackorderOpen,
+	}
+	if err := uc.backorderRepo.Create(backorder); err != nil {
+		return err
+	}
+
+	// Stock may have come in since the sale, with no backorder yet to take it
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, backorder.ProductID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil {
+		return nil
+	}
+	_, err = uc.allocate(product, backorder, sale.CreatedBy.Hex())
+	return err
+}
+
+func (uc *backorderUseCase) OnStockAdjusted(ctx context.Context, event *Domain.DomainEvent) error {
+	var movement Domain.StockMovement
+	if err := event.Decode(&movement); err != nil {
+		return err
+	}
+	if movement.New-movement.Previous <= 0 {
+		return nil
+	}
+
+	backorders, err := uc.backorderRepo.FindOpen(movement.ProductID)
+	if err != nil {
+		return err
+	}
+
+	for i := range backorders {
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, movement.ProductID.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to find product: %w", err)
+		}
+		if product == nil {
+			return nil
+		}
+
+		allocated, err := uc.allocate(product, &backorders[i], movement.CreatedBy.Hex())
+		if err != nil {
+			return err
+		}
+		// Older backorders come first, so what is left waits for more stock
+		if allocated < backorders[i].Outstanding() {
+			break
+		}
+	}
+
+	return nil
+}
+
+// allocate takes what it can of the backorder's outstanding quantity out of the
+// product's available stock and sets it aside for the backorder
+func (uc *backorderUseCase) allocate(product *Domain.Product, backorder *Domain.Backorder, userID string) (float64, error) {
+	available, err := availableStock(uc.reservationRepo, product, nil)
+	if err != nil {
+		return 0, err
+	}
+	quantity := math.Min(available, backorder.Outstanding())
+	if quantity <= 0 {
+		return 0, nil
+	}
+
+	referenceID := backorder.ID.Hex()
+	if err := uc.inventoryRepo.AdjustStock(
+		product.ID.Hex(),
+		quantity,
+		Domain.MovementTypeBackorder,
+		"Allocated to backorder",
+		&referenceID,
+		"backorder",
+		userID,
+	); err != nil {
+		return 0, err
+	}
+
+	allocated, err := uc.backorderRepo.Allocate(backorder, quantity, time.Now())
+	if err == nil && allocated {
+		return quantity, nil
+	}
+
+	// The backorder moved on meanwhile, so the stock goes back
+	if err := uc.inventoryRepo.AdjustStock(
+		product.ID.Hex(),
+		quantity,
+		Domain.MovementTypeReturn,
+		"Backorder allocation undone",
+		&referenceID,
+		"backorder",
+		userID,
+	); err != nil {
+		Infrastructure.Logger.Warn("failed to return stock allocated to backorder", "backorder_id", referenceID, "error", err)
+	}
+	return 0, err
+}
+
+func (uc *backorderUseCase) NotifyReady(ctx context.Context, event *Domain.DomainEvent) error {
+	backorder, err := uc.backorderRepo.FindInBusiness(ctx, event.AggregateID)
+	if err != nil {
+		return err
+	}
+	if backorder == nil || backorder.Status != Domain.BackorderReady || backorder.NotifiedAt != nil || backorder.CustomerPhone == "" {
+		return nil
+	}
+
+	business, err := uc.businessRepo.FindByID(backorder.BusinessID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil
+	}
+
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, backorder.ProductID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to find product: %w", err)
+	}
+	name := ""
+	if product != nil {
+		name = product.Name
+	}
+
+	saleID := backorder.SaleID.Hex()
+	ref := strings.ToUpper(saleID[len(saleID)-8:])
+	body := Infrastructure.Translate(business.Language, "sms.backorder_ready",
+		business.Name, backorder.Quantity, name, ref)
+
+	message, err := uc.smsUC.SendNotice(business.ID.Hex(), backorder.CustomerPhone, &backorder.SaleID, body)
+	if err != nil {
+		return err
+	}
+	if message.Status == Domain.SMSStatusFailed {
+		Infrastructure.LoggerFrom(ctx).Warn("failed to text backorder ready", "backorder_id", backorder.ID.Hex(), "error", message.Error)
+	}
+
+	return uc.backorderRepo.MarkNotified(backorder.ID, time.Now())
+}
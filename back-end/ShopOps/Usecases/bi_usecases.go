@@ -0,0 +1,268 @@
+package Usecases
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"slices"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BIUseCase issues the keys BI tools read the shop's data with, and serves the
+// feed they sync from
+type BIUseCase interface {
+	Infrastructure.BIKeyAuthenticator
+	CreateKey(businessID, userID string, req Domain.CreateBIKeyRequest) (*Domain.BIKeyTokenResponse, error)
+	GetKeys(businessID string) ([]Domain.BIKey, error)
+	DeleteKey(id, businessID string) error
+
+	GetDatasets() []Domain.BIDatasetInfo
+	// GetPage returns a page of the dataset's rows changed since req.Since, oldest
+	// change first, up to a point fixed by the first page of the sync
+	GetPage(businessID string, dataset Domain.BIDataset, req Domain.BIPageRequest) (*Domain.BIPage, error)
+}
+
+type biUseCase struct {
+	biRepo Domain.BIRepository
+}
+
+// A key's last use is recorded at most this often, rather than on every page
+const biKeyTouchInterval = time.Minute
+
+// biDatasets are the datasets offered, with the type each row is
+var biDatasets = []struct {
+	info Domain.BIDatasetInfo
+	row  interface{}
+}{
+	{Domain.BIDatasetInfo{Name: Domain.BIDatasetSales, ChangedAt: "updated_at", Description: "Sales, refunds and voids, archived sales included"}, Domain.Sale{}},
+	{Domain.BIDatasetInfo{Name: Domain.BIDatasetProducts, ChangedAt: "updated_at", Description: "Products and services, those in the trash included"}, Domain.Product{}},
+	{Domain.BIDatasetInfo{Name: Domain.BIDatasetCustomers, ChangedAt: "updated_at", Description: "Customers, those in the trash included"}, Domain.Customer{}},
+	{Domain.BIDatasetInfo{Name: Domain.BIDatasetExpenses, ChangedAt: "updated_at", Description: "Expenses"}, Domain.Expense{}},
+	{Domain.BIDatasetInfo{Name: Domain.BIDatasetStockMovements, ChangedAt: "created_at", Description: "Every change to stock; entries are never changed, so each is sent once"}, Domain.StockMovement{}},
+}
+
+func NewBIUseCase(biRepo Domain.BIRepository) BIUseCase {
+	return &biUseCase{biRepo: biRepo}
+}
+
+func (uc *biUseCase) AuthenticateBIKey(token string) (*Domain.BIKey, error) {
+	key, err := uc.biRepo.FindKeyByTokenHash(Infrastructure.HashBIToken(token))
+	if err != nil || key == nil {
+		return key, err
+	}
+
+	now := time.Now()
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) > biKeyTouchInterval {
+		if err := uc.biRepo.TouchKey(key.ID, now); err != nil {
+			Infrastructure.Logger.Warn("failed to record BI key use", "error", err)
+		}
+		key.LastUsedAt = &now
+	}
+	return key, nil
+}
+
+func (uc *biUseCase) CreateKey(businessID, userID string, req Domain.CreateBIKeyRequest) (*Domain.BIKeyTokenResponse, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, Domain.ValidationError("key name is required")
+	}
+
+	token, hash, err := Infrastructure.NewBIToken()
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Domain.BIKey{
+		BusinessID: objBusinessID,
+		Name:       strings.TrimSpace(req.Name),
+		TokenHash:  hash,
+		IncludePII: req.IncludePII,
+		CreatedBy:  objUserID,
+	}
+	if err := uc.biRepo.CreateKey(key); err != nil {
+		return nil, err
+	}
+
+	return &Domain.BIKeyTokenResponse{BIKey: *key, Token: token}, nil
+}
+
+func (uc *biUseCase) GetKeys(businessID string) ([]Domain.BIKey, error) {
+	keys, err := uc.biRepo.FindKeysByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if keys == nil {
+		keys = []Domain.BIKey{}
+	}
+	return keys, nil
+}
+
+func (uc *biUseCase) DeleteKey(id, businessID string) error {
+	key, err := uc.biRepo.FindKeyByID(id)
+	if err != nil {
+		return fmt.Errorf("failed to find BI key: %w", err)
+	}
+	if key == nil {
+		return Domain.NotFoundError("BI key not found")
+	}
+	if key.BusinessID.Hex() != businessID {
+		return Domain.AccessDeniedError("access denied: BI key does not belong to this business")
+	}
+	return uc.biRepo.DeleteKey(id)
+}
+
+func (uc *biUseCase) GetDatasets() []Domain.BIDatasetInfo {
+	datasets := make([]Domain.BIDatasetInfo, len(biDatasets))
+	for i, dataset := range biDatasets {
+		datasets[i] = dataset.info
+		datasets[i].Fields = jsonFields(reflect.TypeOf(dataset.row))
+	}
+	return datasets
+}
+
+func (uc *biUseCase) GetPage(businessID string, dataset Domain.BIDataset, req Domain.BIPageRequest) (*Domain.BIPage, error) {
+	var fields []string
+	for _, d := range biDatasets {
+		if d.info.Name == dataset {
+			fields = jsonFields(reflect.TypeOf(d.row))
+		}
+	}
+	if fields == nil {
+		return nil, Domain.NotFoundError(fmt.Sprintf("unknown dataset %q", dataset))
+	}
+
+	keep := make(map[string]bool, len(req.Fields)+1)
+	for _, field := range req.Fields {
+		if !slices.Contains(fields, field) {
+			return nil, Domain.ValidationError(fmt.Sprintf("%s has no field %q", dataset, field))
+		}
+		keep[field] = true
+	}
+	if len(keep) > 0 {
+		keep["id"] = true
+	}
+
+	query := Domain.BIQuery{Limit: req.Limit}
+	if query.Limit <= 0 {
+		query.Limit = Domain.BIDefaultPageLimit
+	}
+	if query.Limit > Domain.BIMaxPageLimit {
+		query.Limit = Domain.BIMaxPageLimit
+	}
+
+	// The first page fixes the window; the rest continue it from the cursor
+	if req.Cursor != "" {
+		after, err := Domain.DecodeBICursor(req.Cursor, dataset)
+		if err != nil {
+			return nil, err
+		}
+		query.Since, query.AsOf, query.After = after.Since, after.AsOf, after
+	} else {
+		query.Since = req.Since
+		query.AsOf = time.Now().Add(-Domain.BISnapshotLag).Truncate(time.Millisecond)
+		if query.Since != nil && !query.Since.Before(query.AsOf) {
+			// Nothing has settled since the last sync yet
+			return &Domain.BIPage{Dataset: dataset, Since: query.Since, AsOf: *query.Since, Rows: []map[string]interface{}{}}, nil
+		}
+	}
+
+	records, err := uc.biRepo.FindRecords(dataset, businessID, query)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &Domain.BIPage{
+		Dataset: dataset,
+		Since:   query.Since,
+		AsOf:    query.AsOf,
+		Rows:    make([]map[string]interface{}, 0, len(records)),
+	}
+	for _, record := range records {
+		data := record.Data
+		if !req.IncludePII {
+			data = Domain.Redacted(data)
+		}
+		row, err := biRow(data, keep)
+		if err != nil {
+			return nil, err
+		}
+		page.Rows = append(page.Rows, row)
+	}
+
+	if len(records) == query.Limit {
+		last := records[len(records)-1]
+		page.NextCursor = Domain.BICursor{
+			Dataset:   dataset,
+			Since:     query.Since,
+			AsOf:      query.AsOf,
+			ChangedAt: last.ChangedAt,
+			ID:        last.ID,
+		}.Encode()
+	}
+
+	return page, nil
+}
+
+// biRow is the record as the API returns it, cut down to keep when it is not empty
+func biRow(data interface{}, keep map[string]bool) (map[string]interface{}, error) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode row: %w", err)
+	}
+
+	// Numbers are kept as written, so amounts are not rounded through a float
+	decoder := json.NewDecoder(bytes.NewReader(encoded))
+	decoder.UseNumber()
+	var row map[string]interface{}
+	if err := decoder.Decode(&row); err != nil {
+		return nil, fmt.Errorf("failed to encode row: %w", err)
+	}
+
+	if len(keep) > 0 {
+		for field := range row {
+			if !keep[field] {
+				delete(row, field)
+			}
+		}
+	}
+	return row, nil
+}
+
+// jsonFields lists the top-level fields t is encoded with, embedded structs'
+// included
+func jsonFields(t reflect.Type) []string {
+	var fields []string
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" && field.Anonymous && field.Type.Kind() == reflect.Struct {
+			fields = append(fields, jsonFields(field.Type)...)
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields = append(fields, name)
+	}
+	return fields
+}
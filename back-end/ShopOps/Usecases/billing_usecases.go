@@ -0,0 +1,464 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// BillingUseCase runs shops' subscriptions to ShopOps: the trial every new shop
+// starts on, paying through Chapa or Stripe, and falling back to the free plan when
+// payments stop. It also holds shops to their plan's limits.
+type BillingUseCase interface {
+	GetPlans() []Domain.Plan
+	// Providers lists the ways this server can take subscription payments
+	Providers() []Domain.BillingProvider
+	GetSubscription(businessID string) (*Domain.SubscriptionResponse, error)
+	CreateCheckout(ctx context.Context, businessID, userID string, req Domain.CreateCheckoutRequest) (*Domain.CheckoutResponse, error)
+	// CancelSubscription stops renewals; the plan lasts until the paid period ends
+	CancelSubscription(ctx context.Context, businessID string) (*Domain.SubscriptionResponse, error)
+	HandleWebhook(ctx context.Context, provider Domain.BillingProvider, body []byte, header http.Header) error
+	// ExpireLapsed moves subscriptions whose trial, period or grace ran out along
+	ExpireLapsed() error
+
+	Infrastructure.PlanEnforcer
+}
+
+type billingUseCase struct {
+	subscriptionRepo Domain.SubscriptionRepository
+	businessRepo     Domain.BusinessRepository
+	userRepo         Domain.UserRepository
+	gateways         Infrastructure.BillingGateways
+	cfg              Infrastructure.BillingConfig
+	plans            *Infrastructure.TTLCache // business ID to the plan it gets
+	devices          *Infrastructure.TTLCache // business and device IDs recently let in
+	telemetry        *Infrastructure.TelemetryBuffer
+}
+
+const (
+	// How long a shop's plan is cached for the limit checks
+	billingPlanCacheTTL = time.Minute
+	// How long a device that took a slot is let in without asking the database
+	billingDeviceCacheTTL = 10 * time.Minute
+	// Lapsed subscriptions handled per run of ExpireLapsed
+	billingLapseBatch = 200
+)
+
+func NewBillingUseCase(
+	subscriptionRepo Domain.SubscriptionRepository,
+	businessRepo Domain.BusinessRepository,
+	userRepo Domain.UserRepository,
+	gateways Infrastructure.BillingGateways,
+	cfg Infrastructure.BillingConfig,
+	telemetry *Infrastructure.TelemetryBuffer,
+) BillingUseCase {
+	return &billingUseCase{
+		subscriptionRepo: subscriptionRepo,
+		businessRepo:     businessRepo,
+		userRepo:         userRepo,
+		gateways:         gateways,
+		cfg:              cfg,
+		plans:            Infrastructure.NewTTLCache(billingPlanCacheTTL),
+		devices:          Infrastructure.NewTTLCache(billingDeviceCacheTTL),
+		telemetry:        telemetry,
+	}
+}
+
+func (uc *billingUseCase) GetPlans() []Domain.Plan {
+	plans := make([]Domain.Plan, 0, len(Domain.Plans))
+	for _, plan := range Domain.Plans {
+		plans = append(plans, plan)
+	}
+	sort.Slice(plans, func(i, j int) bool { return plans[i].PriceETB < plans[j].PriceETB })
+	return plans
+}
+
+func (uc *billingUseCase) Providers() []Domain.BillingProvider {
+	providers := make([]Domain.BillingProvider, 0, len(uc.gateways))
+	for provider := range uc.gateways {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+func (uc *billingUseCase) GetSubscription(businessID string) (*Domain.SubscriptionResponse, error) {
+	subscription, err := uc.subscription(businessID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.response(subscription)
+}
+
+func (uc *billingUseCase) CreateCheckout(ctx context.Context, businessID, userID string, req Domain.CreateCheckoutRequest) (*Domain.CheckoutResponse, error) {
+	gateway, ok := uc.gateways[req.Provider]
+	if !ok {
+		return nil, Domain.ValidationError(fmt.Sprintf("%s payments are not available", req.Provider))
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	subscription, err := uc.subscription(businessID)
+	if err != nil {
+		return nil, err
+	}
+	// Stripe keeps charging until told to stop, so a second plan would be paid twice
+	if subscription.Provider == Domain.BillingProviderStripe && !subscription.CancelAtPeriodEnd &&
+		(subscription.Status == Domain.SubscriptionActive || subscription.Status == Domain.SubscriptionPastDue) {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "the shop already has a Stripe subscription; cancel it before choosing another plan")
+	}
+
+	email := business.Email
+	if user, err := uc.userRepo.FindByID(userID); err == nil && user != nil && user.Email != "" {
+		email = user.Email
+	}
+	if email == "" && req.Provider == Domain.BillingProviderChapa {
+		return nil, Domain.ValidationError("add an email address to your account or shop to pay with Chapa")
+	}
+
+	checkout := Infrastructure.BillingCheckout{
+		BusinessID:   businessID,
+		BusinessName: business.Name,
+		Email:        email,
+		Plan:         Domain.Plans[req.Plan],
+		ReturnURL:    req.ReturnURL,
+	}
+	if subscription.Provider == req.Provider {
+		checkout.CustomerID = subscription.ProviderCustomerID
+	}
+
+	checkoutURL, err := gateway.Checkout(ctx, checkout)
+	if err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, fmt.Sprintf("could not start the %s checkout: %v", req.Provider, err))
+	}
+
+	return &Domain.CheckoutResponse{CheckoutURL: checkoutURL}, nil
+}
+
+func (uc *billingUseCase) CancelSubscription(ctx context.Context, businessID string) (*Domain.SubscriptionResponse, error) {
+	subscription, err := uc.subscription(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription.Status != Domain.SubscriptionActive && subscription.Status != Domain.SubscriptionPastDue {
+		return nil, Domain.ValidationError("the shop has no paid subscription to cancel")
+	}
+	if subscription.CancelAtPeriodEnd {
+		return uc.response(subscription)
+	}
+
+	if gateway, ok := uc.gateways[subscription.Provider]; ok {
+		if err := gateway.Cancel(ctx, subscription); err != nil {
+			return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, fmt.Sprintf("could not cancel with %s: %v", subscription.Provider, err))
+		}
+	}
+
+	subscription.CancelAtPeriodEnd = true
+	if subscription.Provider != Domain.BillingProviderStripe {
+		// Nothing renews a Chapa month, so it is over as soon as the owner says so;
+		// Stripe reports the end by webhook when the period runs out
+		now := time.Now()
+		subscription.Status = Domain.SubscriptionCanceled
+		subscription.CanceledAt = &now
+		subscription.GraceEndsAt = nil
+	}
+	if err := uc.save(subscription); err != nil {
+		return nil, err
+	}
+
+	return uc.response(subscription)
+}
+
+func (uc *billingUseCase) HandleWebhook(ctx context.Context, provider Domain.BillingProvider, body []byte, header http.Header) error {
+	gateway, ok := uc.gateways[provider]
+	if !ok {
+		return Domain.NotFoundError("unknown billing provider")
+	}
+
+	event, err := gateway.ParseWebhook(ctx, body, header)
+	if err != nil {
+		return err
+	}
+	if event == nil {
+		return nil
+	}
+
+	var subscription *Domain.Subscription
+	if event.BusinessID != "" {
+		if _, err := primitive.ObjectIDFromHex(event.BusinessID); err != nil {
+			return fmt.Errorf("%w: unknown business %q", Infrastructure.ErrBillingBadWebhook, event.BusinessID)
+		}
+		subscription, err = uc.subscription(event.BusinessID)
+	} else {
+		subscription, err = uc.subscriptionRepo.FindByProviderSubscription(provider, event.SubscriptionID)
+	}
+	if err != nil {
+		return err
+	}
+	if subscription == nil {
+		Infrastructure.LoggerFrom(ctx).Warn("billing event is for an unknown subscription", "provider", provider, "event_id", event.ID, "subscription_id", event.SubscriptionID)
+		return nil
+	}
+
+	isNew, err := uc.subscriptionRepo.RecordEvent(provider, event.ID)
+	if err != nil {
+		return err
+	}
+	if !isNew {
+		return nil
+	}
+
+	if !uc.apply(subscription, provider, event, time.Now()) {
+		return nil
+	}
+	if err := uc.save(subscription); err != nil {
+		if forgetErr := uc.subscriptionRepo.ForgetEvent(provider, event.ID); forgetErr != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to forget billing event", "provider", provider, "event_id", event.ID, "error", forgetErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// apply changes the subscription as the event says, reporting whether it changed
+func (uc *billingUseCase) apply(subscription *Domain.Subscription, provider Domain.BillingProvider, event *Infrastructure.BillingEvent, now time.Time) bool {
+	// Renewal events from a provider the shop has since left are stale
+	sameSubscription := event.SubscriptionID != "" && event.SubscriptionID == subscription.ProviderSubscriptionID
+
+	switch event.Type {
+	case Infrastructure.BillingEventPaid:
+		periodEnd := event.PeriodEnd
+		if periodEnd == nil {
+			// A month from now, or from the end of the month already paid for
+			start := now
+			if subscription.CurrentPeriodEnd != nil && subscription.CurrentPeriodEnd.After(now) &&
+				(subscription.Status == Domain.SubscriptionActive || subscription.Status == Domain.SubscriptionCanceled) {
+				start = *subscription.CurrentPeriodEnd
+			}
+			end := start.AddDate(0, 1, 0)
+			periodEnd = &end
+		}
+
+		if event.Plan != "" {
+			subscription.Plan = event.Plan
+		}
+		subscription.Status = Domain.SubscriptionActive
+		subscription.Provider = provider
+		subscription.ProviderSubscriptionID = event.SubscriptionID
+		if event.CustomerID != "" {
+			subscription.ProviderCustomerID = event.CustomerID
+		}
+		subscription.CurrentPeriodEnd = periodEnd
+		subscription.TrialEndsAt = nil
+		subscription.GraceEndsAt = nil
+		subscription.CanceledAt = nil
+		subscription.CancelAtPeriodEnd = false
+
+	case Infrastructure.BillingEventPaymentFailed:
+		if !sameSubscription || subscription.Status != Domain.SubscriptionActive {
+			return false
+		}
+		graceEnds := now.Add(uc.cfg.GracePeriod)
+		subscription.Status = Domain.SubscriptionPastDue
+		subscription.GraceEndsAt = &graceEnds
+
+	case Infrastructure.BillingEventUpdated:
+		if !sameSubscription {
+			return false
+		}
+		if event.Plan != "" {
+			subscription.Plan = event.Plan
+		}
+		if event.PeriodEnd != nil && subscription.Status == Domain.SubscriptionActive {
+			subscription.CurrentPeriodEnd = event.PeriodEnd
+		}
+		subscription.CancelAtPeriodEnd = event.CancelAtPeriodEnd
+
+	case Infrastructure.BillingEventCanceled:
+		if !sameSubscription {
+			return false
+		}
+		subscription.Status = Domain.SubscriptionCanceled
+		subscription.CanceledAt = &now
+		subscription.CurrentPeriodEnd = &now
+		subscription.GraceEndsAt = nil
+		subscription.CancelAtPeriodEnd = false
+
+	default:
+		return false
+	}
+	return true
+}
+
+func (uc *billingUseCase) ExpireLapsed() error {
+	now := time.Now()
+	subscriptions, err := uc.subscriptionRepo.FindLapsing(now, billingLapseBatch)
+	if err != nil {
+		return err
+	}
+
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+		switch subscription.Status {
+		case Domain.SubscriptionActive:
+			// Payment for the next period has not come in; keep the plan for a while
+			graceEnds := subscription.CurrentPeriodEnd.Add(uc.cfg.GracePeriod)
+			if graceEnds.After(now) {
+				subscription.Status = Domain.SubscriptionPastDue
+				subscription.GraceEndsAt = &graceEnds
+			} else {
+				subscription.Status = Domain.SubscriptionExpired
+			}
+		default:
+			subscription.Status = Domain.SubscriptionExpired
+		}
+
+		if err := uc.save(subscription); err != nil {
+			Infrastructure.Logger.Warn("failed to expire subscription for business", "business_id", subscription.BusinessID.Hex(), "error", err)
+		}
+	}
+	return nil
+}
+
+func (uc *billingUseCase) CheckDevice(businessID, deviceID string) error {
+	if !uc.cfg.Enabled {
+		return nil
+	}
+
+	key := businessID + ":" + deviceID
+	if _, _, ok := uc.devices.Get(key); ok {
+		// Already holds a slot; keep its last use current without a write per request
+		uc.telemetry.Record(Domain.TelemetryWrite{
+			Kind:       Domain.TelemetryBillingDeviceSeen,
+			BusinessID: businessID,
+			SubjectID:  deviceID,
+			At:         time.Now(),
+		})
+		return nil
+	}
+
+	now := time.Now()
+	since := now.Add(-Domain.BillingDeviceWindow)
+	seen, err := uc.subscriptionRepo.TouchDevice(businessID, deviceID, since, now)
+	if err != nil {
+		return err
+	}
+	if !seen {
+		plan, err := uc.plan(businessID)
+		if err != nil {
+			return err
+		}
+		if plan.MaxDevices > 0 {
+			count, err := uc.subscriptionRepo.CountDevices(businessID, since)
+			if err != nil {
+				return err
+			}
+			if count >= plan.MaxDevices {
+				return Domain.NewAppError(Domain.ErrCodePaymentRequired,
+					fmt.Sprintf("the %s plan allows %d devices; upgrade to use this one", plan.Name, plan.MaxDevices))
+			}
+		}
+		if err := uc.subscriptionRepo.AddDevice(businessID, deviceID, now); err != nil {
+			return err
+		}
+	}
+
+	uc.devices.Set(key, true)
+	return nil
+}
+
+func (uc *billingUseCase) UseQuota(businessID string, quota Domain.PlanQuota) error {
+	if !uc.cfg.Enabled {
+		return nil
+	}
+
+	plan, err := uc.plan(businessID)
+	if err != nil {
+		return err
+	}
+	limit := plan.Limit(quota)
+	if limit == 0 {
+		return nil
+	}
+
+	used, err := uc.subscriptionRepo.UseQuota(businessID, quota, time.Now().UTC().Format("2006-01-02"))
+	if err != nil {
+		return err
+	}
+	if used > limit {
+		return Domain.NewAppError(Domain.ErrCodePaymentRequired,
+			fmt.Sprintf("the %s plan allows %d %s a day; upgrade for more", plan.Name, limit, quota))
+	}
+	return nil
+}
+
+// subscription returns the shop's subscription, starting its trial on first use
+func (uc *billingUseCase) subscription(businessID string) (*Domain.Subscription, error) {
+	subscription, err := uc.subscriptionRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription != nil {
+		return subscription, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	trialEnds := time.Now().Add(uc.cfg.TrialPeriod)
+	return uc.subscriptionRepo.Create(&Domain.Subscription{
+		BusinessID:  objBusinessID,
+		Plan:        Domain.TrialPlan,
+		Status:      Domain.SubscriptionTrialing,
+		TrialEndsAt: &trialEnds,
+	})
+}
+
+// plan is the plan the shop currently gets, cached for the limit checks
+func (uc *billingUseCase) plan(businessID string) (Domain.Plan, error) {
+	if cached, _, ok := uc.plans.Get(businessID); ok {
+		return cached.(Domain.Plan), nil
+	}
+
+	subscription, err := uc.subscription(businessID)
+	if err != nil {
+		return Domain.Plan{}, err
+	}
+	plan := Domain.Plans[subscription.EffectivePlan(time.Now())]
+	uc.plans.Set(businessID, plan)
+	return plan, nil
+}
+
+func (uc *billingUseCase) save(subscription *Domain.Subscription) error {
+	if err := uc.subscriptionRepo.Update(subscription); err != nil {
+		return err
+	}
+	uc.plans.Delete(subscription.BusinessID.Hex())
+	return nil
+}
+
+func (uc *billingUseCase) response(subscription *Domain.Subscription) (*Domain.SubscriptionResponse, error) {
+	devices, err := uc.subscriptionRepo.CountDevices(subscription.BusinessID.Hex(), time.Now().Add(-Domain.BillingDeviceWindow))
+	if err != nil {
+		return nil, err
+	}
+	return &Domain.SubscriptionResponse{
+		Subscription:  *subscription,
+		EffectivePlan: Domain.Plans[subscription.EffectivePlan(time.Now())],
+		Devices:       devices,
+	}, nil
+}
@@ -0,0 +1,394 @@
+package Usecases
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+type BranchReportUseCase interface {
+	GetConsolidatedPnL(userID string, branchIDs []string, startDate, endDate string, granularity Domain.PeriodType, calendar Domain.Calendar) (*Domain.ConsolidatedPnLReport, error)
+	GetConsolidatedCategoryMargins(userID string, branchIDs []string, startDate, endDate string) (*Domain.ConsolidatedMarginReport, error)
+	// CompareBranches ranks the branches on one metric; opts adds each branch's growth over the prior period
+	CompareBranches(userID string, branchIDs []string, startDate, endDate string, rankBy Domain.BranchRankBy, opts *Domain.CompareOptions) (*Domain.BranchComparison, error)
+}
+
+type branchReportUseCase struct {
+	businessRepo Domain.BusinessRepository
+	analyticsUC  AnalyticsUseCase
+}
+
+func NewBranchReportUseCase(
+	businessRepo Domain.BusinessRepository,
+	analyticsUC AnalyticsUseCase,
+) BranchReportUseCase {
+	return &branchReportUseCase{
+		businessRepo: businessRepo,
+		analyticsUC:  analyticsUC,
+	}
+}
+
+func (uc *branchReportUseCase) GetConsolidatedPnL(userID string, branchIDs []string, startDate, endDate string, granularity Domain.PeriodType, calendar Domain.Calendar) (*Domain.ConsolidatedPnLReport, error) {
+	branches, err := uc.branches(userID, branchIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clockFor(&branches[0]))
+	if err != nil {
+		return nil, err
+	}
+	start, end := fromDay.Format("2006-01-02"), toDay.Format("2006-01-02")
+
+	report := &Domain.ConsolidatedPnLReport{
+		StartDate: start,
+		EndDate:   end,
+		Currency:  branches[0].Currency,
+		Periods:   []Domain.PnLLine{},
+		Branches:  make([]Domain.BranchPnL, 0, len(branches)),
+	}
+
+	periods := make(map[string]*Domain.PnLLine)
+	for _, branch := range branches {
+		pnl, err := uc.analyticsUC.GetProfitAndLoss(branch.ID.Hex(), start, end, granularity, calendar)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get P&L for %s: %w", branch.Name, err)
+		}
+		report.Granularity = pnl.Granularity
+		report.Calendar = pnl.Calendar
+		report.Totals.Period = pnl.Totals.Period
+
+		for _, line := range pnl.Periods {
+			period, ok := periods[line.Period]
+			if !ok {
+				period = &Domain.PnLLine{Period: line.Period}
+				periods[line.Period] = period
+			}
+			addPnLLine(period, line)
+		}
+		addPnLLine(&report.Totals, pnl.Totals)
+
+		report.Branches = append(report.Branches, Domain.BranchPnL{
+			BusinessID: branch.ID.Hex(),
+			Name:       branch.Name,
+			City:       branch.City,
+			Totals:     pnl.Totals,
+			Periods:    pnl.Periods,
+		})
+	}
+
+	// Period labels sort in date order at every granularity
+	for _, period := range periods {
+		finishPnLLine(period)
+		report.Periods = append(report.Periods, *period)
+	}
+	sort.Slice(report.Periods, func(i, j int) bool {
+		return report.Periods[i].Period < report.Periods[j].Period
+	})
+	finishPnLLine(&report.Totals)
+
+	for i := range report.Branches {
+		if report.Totals.Revenue > 0 {
+			report.Branches[i].RevenueShare = roundCurrency(report.Branches[i].Totals.Revenue.Float64() / report.Totals.Revenue.Float64() * 100)
+		}
+	}
+	sort.SliceStable(report.Branches, func(i, j int) bool {
+		return report.Branches[i].Totals.Revenue > report.Branches[j].Totals.Revenue
+	})
+
+	return report, nil
+}
+
+func (uc *branchReportUseCase) GetConsolidatedCategoryMargins(userID string, branchIDs []string, startDate, endDate string) (*Domain.ConsolidatedMarginReport, error) {
+	branches, err := uc.branches(userID, branchIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clockFor(&branches[0]))
+	if err != nil {
+		return nil, err
+	}
+	start, end := fromDay.Format("2006-01-02"), toDay.Format("2006-01-02")
+
+	categories := make(map[string]*Domain.MarginLine)
+	breakdown := make(map[string][]Domain.BranchMarginLine)
+	for _, branch := range branches {
+		margins, err := uc.analyticsUC.GetCategoryMargins(branch.ID.Hex(), start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get margins for %s: %w", branch.Name, err)
+		}
+
+		for _, line := range margins.Lines {
+			category, ok := categories[line.Name]
+			if !ok {
+				category = &Domain.MarginLine{Name: line.Name, Category: line.Category}
+				categories[line.Name] = category
+			}
+			category.Quantity += line.Quantity
+			category.Revenue += line.Revenue
+			category.COGS += line.COGS
+
+			breakdown[line.Name] = append(breakdown[line.Name], Domain.BranchMarginLine{
+				BusinessID:  branch.ID.Hex(),
+				Name:        branch.Name,
+				Quantity:    line.Quantity,
+				Revenue:     line.Revenue,
+				COGS:        line.COGS,
+				GrossProfit: line.GrossProfit,
+				GrossMargin: line.GrossMargin,
+			})
+		}
+	}
+
+	lines := make([]Domain.MarginLine, 0, len(categories))
+	for _, category := range categories {
+		lines = append(lines, *category)
+	}
+	consolidated := newMarginReport(fromDay, toDay, lines)
+
+	report := &Domain.ConsolidatedMarginReport{
+		StartDate: consolidated.StartDate,
+		EndDate:   consolidated.EndDate,
+		Currency:  branches[0].Currency,
+		Lines:     make([]Domain.ConsolidatedMarginLine, 0, len(consolidated.Lines)),
+	}
+	for _, line := range consolidated.Lines {
+		perBranch := breakdown[line.Name]
+		for i := range perBranch {
+			if line.Revenue > 0 {
+				perBranch[i].RevenueShare = roundCurrency(perBranch[i].Revenue.Float64() / line.Revenue.Float64() * 100)
+			}
+		}
+		sort.SliceStable(perBranch, func(i, j int) bool { return perBranch[i].Revenue > perBranch[j].Revenue })
+
+		report.Lines = append(report.Lines, Domain.ConsolidatedMarginLine{MarginLine: line, Branches: perBranch})
+	}
+
+	return report, nil
+}
+
+func (uc *branchReportUseCase) CompareBranches(userID string, branchIDs []string, startDate, endDate string, rankBy Domain.BranchRankBy, opts *Domain.CompareOptions) (*Domain.BranchComparison, error) {
+	if rankBy == "" {
+		rankBy = Domain.BranchRankRevenue
+	}
+	if !rankBy.IsValid() {
+		return nil, fmt.Errorf("invalid rank_by: %s", rankBy)
+	}
+
+	branches, err := uc.branches(userID, branchIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clockFor(&branches[0]))
+	if err != nil {
+		return nil, err
+	}
+
+	comparison := &Domain.BranchComparison{
+		StartDate: fromDay.Format("2006-01-02"),
+		EndDate:   toDay.Format("2006-01-02"),
+		Currency:  branches[0].Currency,
+		RankBy:    rankBy,
+		Branches:  make([]Domain.BranchComparisonLine, 0, len(branches)),
+		Average:   Domain.BranchComparisonLine{Name: "Average"},
+	}
+
+	var prevFrom, prevTo time.Time
+	if opts != nil {
+		normalized, err := normalizeCompareOptions(*opts)
+		if err != nil {
+			return nil, err
+		}
+		days := int(toDay.Sub(fromDay).Hours()/24) + 1
+		prevFrom, prevTo = previousRange(fromDay, toDay, days, normalized)
+
+		comparison.Compare = normalized.Mode
+		comparison.PreviousStart = prevFrom.Format("2006-01-02")
+		comparison.PreviousEnd = prevTo.Format("2006-01-02")
+	}
+
+	var totalRevenue Domain.Money
+	for _, branch := range branches {
+		line, err := uc.branchMetrics(&branch, fromDay, toDay)
+		if err != nil {
+			return nil, err
+		}
+
+		if opts != nil {
+			previous, err := uc.branchMetrics(&branch, prevFrom, prevTo)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get the previous period: %w", err)
+			}
+			line.Growth = map[string]Domain.MetricDelta{
+				"revenue":        metricDelta(line.Revenue.Float64(), previous.Revenue.Float64()),
+				"gross_profit":   metricDelta(line.GrossProfit.Float64(), previous.GrossProfit.Float64()),
+				"net_profit":     metricDelta(line.NetProfit.Float64(), previous.NetProfit.Float64()),
+				"transactions":   metricDelta(float64(line.Transactions), float64(previous.Transactions)),
+				"average_ticket": metricDelta(line.AverageTicket.Float64(), previous.AverageTicket.Float64()),
+			}
+		}
+
+		totalRevenue += line.Revenue
+		comparison.Branches = append(comparison.Branches, *line)
+	}
+
+	average := &comparison.Average
+	count := float64(len(comparison.Branches))
+	for i := range comparison.Branches {
+		line := &comparison.Branches[i]
+		if totalRevenue > 0 {
+			line.RevenueShare = roundCurrency(line.Revenue.Float64() / totalRevenue.Float64() * 100)
+		}
+
+		// Amounts are summed here and divided once below, to the cent
+		average.Revenue += line.Revenue
+		average.GrossProfit += line.GrossProfit
+		average.GrossMargin += line.GrossMargin / count
+		average.Expenses += line.Expenses
+		average.NetProfit += line.NetProfit
+		average.NetMargin += line.NetMargin / count
+		average.AverageTicket += line.AverageTicket
+		average.RevenueShare += line.RevenueShare / count
+		average.Transactions += line.Transactions
+	}
+	average.Revenue = Domain.NewMoney(average.Revenue.Float64() / count)
+	average.GrossProfit = Domain.NewMoney(average.GrossProfit.Float64() / count)
+	average.GrossMargin = roundCurrency(average.GrossMargin)
+	average.Expenses = Domain.NewMoney(average.Expenses.Float64() / count)
+	average.NetProfit = Domain.NewMoney(average.NetProfit.Float64() / count)
+	average.NetMargin = roundCurrency(average.NetMargin)
+	average.AverageTicket = Domain.NewMoney(average.AverageTicket.Float64() / count)
+	average.RevenueShare = roundCurrency(average.RevenueShare)
+	average.Transactions = int(float64(average.Transactions)/count + 0.5)
+	average.VsAverage = 100
+
+	averageValue := branchRankValue(*average, rankBy)
+	for i := range comparison.Branches {
+		if averageValue != 0 {
+			comparison.Branches[i].VsAverage = roundCurrency(branchRankValue(comparison.Branches[i], rankBy) / averageValue * 100)
+		}
+	}
+
+	sort.SliceStable(comparison.Branches, func(i, j int) bool {
+		return branchRankValue(comparison.Branches[i], rankBy) > branchRankValue(comparison.Branches[j], rankBy)
+	})
+	for i := range comparison.Branches {
+		comparison.Branches[i].Rank = i + 1
+	}
+
+	return comparison, nil
+}
+
+// branches resolves the requested branches, all the user's open shops when none are given.
+// Only the owner of a business can include it.
+func (uc *branchReportUseCase) branches(userID string, branchIDs []string) ([]Domain.Business, error) {
+	owned, err := uc.businessRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find businesses: %w", err)
+	}
+
+	var branches []Domain.Business
+	if len(branchIDs) == 0 {
+		for _, business := range owned {
+			if business.Status != Domain.BusinessStatusClosed {
+				branches = append(branches, business)
+			}
+		}
+	} else {
+		byID := make(map[string]Domain.Business, len(owned))
+		for _, business := range owned {
+			byID[business.ID.Hex()] = business
+		}
+
+		seen := make(map[string]bool, len(branchIDs))
+		for _, id := range branchIDs {
+			if seen[id] {
+				continue
+			}
+			seen[id] = true
+
+			business, ok := byID[id]
+			if !ok {
+				return nil, fmt.Errorf("access denied: business %s does not belong to this user", id)
+			}
+			branches = append(branches, business)
+		}
+	}
+
+	if len(branches) == 0 {
+		return nil, fmt.Errorf("no branches to report on")
+	}
+
+	for _, branch := range branches[1:] {
+		if branch.Currency != branches[0].Currency {
+			return nil, fmt.Errorf("cannot consolidate branches in different currencies (%s and %s); choose branches with one currency", branches[0].Currency, branch.Currency)
+		}
+	}
+
+	sort.SliceStable(branches, func(i, j int) bool { return branches[i].Name < branches[j].Name })
+	return branches, nil
+}
+
+// branchMetrics are one branch's headline figures between two business days
+func (uc *branchReportUseCase) branchMetrics(branch *Domain.Business, fromDay, toDay time.Time) (*Domain.BranchComparisonLine, error) {
+	pnl, err := uc.analyticsUC.GetProfitAndLoss(branch.ID.Hex(), fromDay.Format("2006-01-02"), toDay.Format("2006-01-02"), Domain.PeriodTypeYearly, Domain.CalendarGregorian)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get P&L for %s: %w", branch.Name, err)
+	}
+
+	start, end := clockFor(branch).Range(fromDay, toDay)
+	summary, err := uc.analyticsUC.GetSalesSummary(branch.ID.Hex(), start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales summary for %s: %w", branch.Name, err)
+	}
+
+	line := &Domain.BranchComparisonLine{
+		BusinessID:  branch.ID.Hex(),
+		Name:        branch.Name,
+		City:        branch.City,
+		Revenue:     pnl.Totals.Revenue,
+		GrossProfit: pnl.Totals.GrossProfit,
+		GrossMargin: pnl.Totals.GrossMargin,
+		Expenses:    pnl.Totals.Expenses,
+		NetProfit:   pnl.Totals.NetProfit,
+		NetMargin:   pnl.Totals.NetMargin,
+	}
+	if summary != nil {
+		line.Transactions = summary.TransactionCount
+		if summary.TransactionCount > 0 {
+			line.AverageTicket = Domain.NewMoney(summary.TotalAmount.Float64() / float64(summary.TransactionCount))
+		}
+	}
+
+	return line, nil
+}
+
+func branchRankValue(line Domain.BranchComparisonLine, rankBy Domain.BranchRankBy) float64 {
+	switch rankBy {
+	case Domain.BranchRankGrossProfit:
+		return line.GrossProfit.Float64()
+	case Domain.BranchRankGrossMargin:
+		return line.GrossMargin
+	case Domain.BranchRankNetProfit:
+		return line.NetProfit.Float64()
+	case Domain.BranchRankNetMargin:
+		return line.NetMargin
+	case Domain.BranchRankTransactions:
+		return float64(line.Transactions)
+	case Domain.BranchRankAverageTicket:
+		return line.AverageTicket.Float64()
+	default:
+		return line.Revenue.Float64()
+	}
+}
+
+// addPnLLine adds the raw amounts of line to total; finishPnLLine derives the rest
+func addPnLLine(total *Domain.PnLLine, line Domain.PnLLine) {
+	total.Revenue += line.Revenue
+	total.COGS += line.COGS
+	total.Expenses += line.Expenses
+}
@@ -2,6 +2,7 @@ package Usecases
 
 import (
 	"fmt"
+	"time"
 
 	Domain "ShopOps/Domain"
 )
@@ -19,12 +20,18 @@ type BusinessUseCase interface {
 type businessUseCase struct {
 	businessRepo Domain.BusinessRepository
 	userRepo     Domain.UserRepository
+	analyticsUC  AnalyticsUseCase
 }
 
-func NewBusinessUseCase(businessRepo Domain.BusinessRepository, userRepo Domain.UserRepository) BusinessUseCase {
+func NewBusinessUseCase(
+	businessRepo Domain.BusinessRepository,
+	userRepo Domain.UserRepository,
+	analyticsUC AnalyticsUseCase,
+) BusinessUseCase {
 	return &businessUseCase{
 		businessRepo: businessRepo,
 		userRepo:     userRepo,
+		analyticsUC:  analyticsUC,
 	}
 }
 
@@ -35,12 +42,15 @@ func (uc *businessUseCase) CreateBusiness(userID string, req Domain.CreateBusine
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 	if user == nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, Domain.NotFoundError("user not found")
 	}
 
 	// Set default timezone if not provided
 	if req.Timezone == "" {
-		req.Timezone = "UTC"
+		req.Timezone = Domain.DefaultTimezone
+	}
+	if err := validateTimezone(req.Timezone); err != nil {
+		return nil, err
 	}
 
 	// Set default currency if not provided
@@ -60,12 +70,19 @@ func (uc *businessUseCase) CreateBusiness(userID string, req Domain.CreateBusine
 		BusinessType: req.BusinessType,
 		Currency:     req.Currency,
 		Timezone:     req.Timezone,
+		Language:     Domain.Language(req.Language),
 		Address:      req.Address,
 		City:         req.City,
 		Country:      req.Country,
 		Phone:        req.Phone,
 		Email:        req.Email,
 	}
+	if req.DayCutoffHour != nil {
+		if err := validateDayCutoff(*req.DayCutoffHour); err != nil {
+			return nil, err
+		}
+		business.DayCutoffHour = *req.DayCutoffHour
+	}
 
 	if err := uc.businessRepo.Create(business); err != nil {
 		return nil, fmt.Errorf("failed to create business: %w", err)
@@ -89,6 +106,9 @@ func (uc *businessUseCase) UpdateBusiness(id, userID string, req Domain.UpdateBu
 		return nil, err
 	}
 
+	// Daily figures already aggregated were split on the old day boundaries
+	clock := clockFor(business)
+
 	// Update fields
 	if req.Name != "" {
 		business.Name = req.Name
@@ -103,8 +123,20 @@ func (uc *businessUseCase) UpdateBusiness(id, userID string, req Domain.UpdateBu
 		business.Currency = req.Currency
 	}
 	if req.Timezone != "" {
+		if err := validateTimezone(req.Timezone); err != nil {
+			return nil, err
+		}
 		business.Timezone = req.Timezone
 	}
+	if req.DayCutoffHour != nil {
+		if err := validateDayCutoff(*req.DayCutoffHour); err != nil {
+			return nil, err
+		}
+		business.DayCutoffHour = *req.DayCutoffHour
+	}
+	if req.Language != "" {
+		business.Language = Domain.Language(req.Language)
+	}
 	if req.Address != "" {
 		business.Address = req.Address
 	}
@@ -125,9 +157,39 @@ func (uc *businessUseCase) UpdateBusiness(id, userID string, req Domain.UpdateBu
 		return nil, fmt.Errorf("failed to update business: %w", err)
 	}
 
+	if updated := clockFor(business); updated.loc.String() != clock.loc.String() || updated.cutoff != clock.cutoff {
+		uc.resplitDays(business.ID.Hex())
+	}
+
 	return business, nil
 }
 
+// resplitDays queues the days still within the rebuild window for re-aggregation on
+// the new boundaries; older days can be rebuilt on request
+func (uc *businessUseCase) resplitDays(businessID string) {
+	now := time.Now()
+	days := make([]time.Time, 0, maxAnalyticsRebuildDays)
+	for i := 0; i < maxAnalyticsRebuildDays; i++ {
+		days = append(days, now.AddDate(0, 0, -i))
+	}
+	uc.analyticsUC.MarkDirty(businessID, days...)
+}
+
+func validateDayCutoff(hour int) error {
+	if hour < 0 || hour > Domain.MaxDayCutoffHour {
+		return Domain.ValidationError(fmt.Sprintf("day_cutoff_hour must be between 0 and %d", Domain.MaxDayCutoffHour))
+	}
+	return nil
+}
+
+// validateTimezone accepts IANA zone names such as Africa/Addis_Ababa
+func validateTimezone(name string) error {
+	if _, err := time.LoadLocation(name); err != nil {
+		return Domain.ValidationError(fmt.Sprintf("unknown timezone %q", name))
+	}
+	return nil
+}
+
 func (uc *businessUseCase) UpdateBusinessStatus(id, userID string, status Domain.BusinessStatus) error {
 	// Validate business exists and user has access
 	_, err := uc.ValidateBusinessAccess(id, userID)
@@ -154,12 +216,12 @@ func (uc *businessUseCase) ValidateBusinessAccess(businessID, userID string) (*D
 		return nil, fmt.Errorf("failed to find business: %w", err)
 	}
 	if business == nil {
-		return nil, fmt.Errorf("business not found")
+		return nil, Domain.NotFoundError("business not found")
 	}
 
 	// Check if user owns this business
 	if business.UserID.Hex() != userID {
-		return nil, fmt.Errorf("access denied: user does not own this business")
+		return nil, Domain.AccessDeniedError("access denied: user does not own this business")
 	}
 
 	return business, nil
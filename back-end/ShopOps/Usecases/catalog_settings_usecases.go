@@ -0,0 +1,150 @@
+package Usecases
+
+import (
+	"fmt"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CatalogSettingsUseCase interface {
+	GetSettings(businessID string) (*Domain.CatalogSettings, error)
+	UpdateSettings(businessID string, req Domain.UpdateCatalogSettingsRequest) (*Domain.CatalogSettings, error)
+	// ApplyTemplate adds the template's categories, units and tax rates to the shop's,
+	// keeping what the shop added or changed itself
+	ApplyTemplate(businessID string, template Domain.ShopTemplate) (*Domain.CatalogSettings, error)
+	// ApplyPack adds the pack's categories, units and tax rates the same way,
+	// leaving the shop's template as it is
+	ApplyPack(businessID string, pack Domain.VerticalPack) (*Domain.CatalogSettings, error)
+}
+
+type catalogSettingsUseCase struct {
+	settingsRepo Domain.CatalogSettingsRepository
+}
+
+func NewCatalogSettingsUseCase(settingsRepo Domain.CatalogSettingsRepository) CatalogSettingsUseCase {
+	return &catalogSettingsUseCase{settingsRepo: settingsRepo}
+}
+
+func (uc *catalogSettingsUseCase) GetSettings(businessID string) (*Domain.CatalogSettings, error) {
+	settings, err := uc.settingsRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	defaults := Domain.DefaultCatalogSettings
+	defaults.BusinessID = objBusinessID
+	defaults.Categories = append([]string{}, defaults.Categories...)
+	defaults.Units = append([]string{}, defaults.Units...)
+	defaults.TaxRates = append([]Domain.TaxRate{}, defaults.TaxRates...)
+	return &defaults, nil
+}
+
+func (uc *catalogSettingsUseCase) UpdateSettings(businessID string, req Domain.UpdateCatalogSettingsRequest) (*Domain.CatalogSettings, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Categories != nil {
+		settings.Categories = trimNames(*req.Categories)
+	}
+	if req.Units != nil {
+		settings.Units = trimNames(*req.Units)
+	}
+	if req.TaxRates != nil {
+		settings.TaxRates = *req.TaxRates
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.settingsRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (uc *catalogSettingsUseCase) ApplyTemplate(businessID string, template Domain.ShopTemplate) (*Domain.CatalogSettings, error) {
+	return uc.merge(businessID, template.Categories, template.Units, template.TaxRates, template.Code)
+}
+
+func (uc *catalogSettingsUseCase) ApplyPack(businessID string, pack Domain.VerticalPack) (*Domain.CatalogSettings, error) {
+	return uc.merge(businessID, pack.Categories, pack.Units, pack.TaxRates, "")
+}
+
+// merge adds the lists to the shop's settings, recording the template when given
+func (uc *catalogSettingsUseCase) merge(businessID string, categories, units []string, taxRates []Domain.TaxRate, template string) (*Domain.CatalogSettings, error) {
+	settings, err := uc.settingsRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	// A shop that never saved settings takes the lists in place of the defaults
+	if settings == nil {
+		objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		settings = &Domain.CatalogSettings{BusinessID: objBusinessID}
+	}
+
+	settings.Categories = mergeNames(settings.Categories, categories)
+	settings.Units = mergeNames(settings.Units, units)
+
+	// A rate the shop already has keeps its percent; a new default only takes
+	// over when the shop has none of its own
+	hasDefault := false
+	codes := make(map[string]bool, len(settings.TaxRates))
+	for _, rate := range settings.TaxRates {
+		codes[strings.ToLower(rate.Code)] = true
+		hasDefault = hasDefault || rate.Default
+	}
+	for _, rate := range taxRates {
+		if codes[strings.ToLower(rate.Code)] {
+			continue
+		}
+		rate.Default = rate.Default && !hasDefault
+		hasDefault = hasDefault || rate.Default
+		settings.TaxRates = append(settings.TaxRates, rate)
+	}
+	if template != "" {
+		settings.Template = template
+	}
+
+	if err := uc.settingsRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func trimNames(names []string) []string {
+	trimmed := make([]string, len(names))
+	for i, name := range names {
+		trimmed[i] = strings.TrimSpace(name)
+	}
+	return trimmed
+}
+
+// mergeNames appends the names not already in the list, ignoring case
+func mergeNames(list, names []string) []string {
+	seen := make(map[string]bool, len(list))
+	for _, name := range list {
+		seen[strings.ToLower(name)] = true
+	}
+	for _, name := range names {
+		if !seen[strings.ToLower(name)] {
+			list = append(list, name)
+			seen[strings.ToLower(name)] = true
+		}
+	}
+	return list
+}
@@ -0,0 +1,165 @@
+package Usecases
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// normalizeCompareOptions validates the comparison and defaults to weekday alignment
+func normalizeCompareOptions(opts Domain.CompareOptions) (Domain.CompareOptions, error) {
+	if !opts.Mode.IsValid() {
+		return opts, fmt.Errorf("invalid compare: %s (use previous_period, last_week, last_month or last_year)", opts.Mode)
+	}
+	if opts.Align == "" {
+		opts.Align = Domain.CompareAlignWeekday
+	}
+	if !opts.Align.IsValid() {
+		return opts, fmt.Errorf("invalid align: %s (use weekday or date)", opts.Align)
+	}
+	return opts, nil
+}
+
+// previousRange is the range start..end moved back to the comparable prior period.
+// spanDays is how many days the current range covers.
+func previousRange(start, end time.Time, spanDays int, opts Domain.CompareOptions) (time.Time, time.Time) {
+	weekday := opts.Align == Domain.CompareAlignWeekday
+
+	switch opts.Mode {
+	case Domain.CompareModeLastWeek:
+		return start.AddDate(0, 0, -7), end.AddDate(0, 0, -7)
+	case Domain.CompareModeLastMonth:
+		if weekday {
+			return start.AddDate(0, 0, -28), end.AddDate(0, 0, -28)
+		}
+		return addMonthsClamped(start, -1), addMonthsClamped(end, -1)
+	case Domain.CompareModeLastYear:
+		if weekday {
+			return start.AddDate(0, 0, -364), end.AddDate(0, 0, -364)
+		}
+		return addMonthsClamped(start, -12), addMonthsClamped(end, -12)
+	default:
+		// The previous period starts where this one would if it ran back to back,
+		// rounded out to whole weeks when the weekdays have to line up
+		days := spanDays
+		if days < 1 {
+			days = 1
+		}
+		if weekday {
+			days = (days + 6) / 7 * 7
+		}
+		return start.AddDate(0, 0, -days), end.AddDate(0, 0, -days)
+	}
+}
+
+// addMonthsClamped moves t by months, keeping to the last day of a shorter month
+// instead of spilling into the next one (Mar 31 - 1 month is Feb 28, not Mar 3)
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month(), 1, t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), t.Location())
+	target := first.AddDate(0, months, 0)
+	lastDay := target.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+	return target.AddDate(0, 0, day-1)
+}
+
+// spanDays counts the days from start to end, rounding a partial day up
+func spanDays(start, end time.Time) int {
+	return int(math.Ceil(end.Sub(start).Hours() / 24))
+}
+
+func newReportComparison(opts Domain.CompareOptions, currentStart, currentEnd, previousStart, previousEnd time.Time, current, previous interface{}) *Domain.ReportComparison {
+	currentMetrics := reportMetrics(current)
+	previousMetrics := reportMetrics(previous)
+
+	deltas := make(map[string]Domain.MetricDelta, len(currentMetrics))
+	for name, value := range currentMetrics {
+		deltas[name] = metricDelta(value, previousMetrics[name])
+	}
+
+	return &Domain.ReportComparison{
+		Compare:       opts.Mode,
+		Align:         opts.Align,
+		CurrentStart:  currentStart.Format("2006-01-02"),
+		CurrentEnd:    currentEnd.Format("2006-01-02"),
+		PreviousStart: previousStart.Format("2006-01-02"),
+		PreviousEnd:   previousEnd.Format("2006-01-02"),
+		Current:       current,
+		Previous:      previous,
+		Deltas:        deltas,
+	}
+}
+
+func metricDelta(current, previous float64) Domain.MetricDelta {
+	delta := Domain.MetricDelta{
+		Current:  roundCurrency(current),
+		Previous: roundCurrency(previous),
+		Change:   roundCurrency(current - previous),
+	}
+	if previous != 0 {
+		percent := roundCurrency((current - previous) / math.Abs(previous) * 100)
+		delta.ChangePercent = &percent
+	}
+	return delta
+}
+
+// reportMetrics are the headline numbers of a report that a comparison reports deltas for
+func reportMetrics(report interface{}) map[string]float64 {
+	switch r := report.(type) {
+	case *Domain.SalesReport:
+		return map[string]float64{
+			"total_sales":        r.TotalSales,
+			"total_amount":       r.TotalAmount.Float64(),
+			"total_transactions": float64(r.TotalTransactions),
+			"average_sale":       r.AverageSale.Float64(),
+		}
+	case *Domain.ExpensesReport:
+		return map[string]float64{
+			"total_expenses": r.TotalExpenses.Float64(),
+		}
+	case *Domain.ProfitReport:
+		return map[string]float64{
+			"total_sales":    r.TotalSales.Float64(),
+			"total_expenses": r.TotalExpenses.Float64(),
+			"gross_profit":   r.GrossProfit.Float64(),
+			"net_profit":     r.NetProfit.Float64(),
+			"profit_margin":  r.ProfitMargin,
+		}
+	case *Domain.PnLReport:
+		return map[string]float64{
+			"revenue":      r.Totals.Revenue.Float64(),
+			"cogs":         r.Totals.COGS.Float64(),
+			"gross_profit": r.Totals.GrossProfit.Float64(),
+			"gross_margin": r.Totals.GrossMargin,
+			"expenses":     r.Totals.Expenses.Float64(),
+			"net_profit":   r.Totals.NetProfit.Float64(),
+			"net_margin":   r.Totals.NetMargin,
+		}
+	case *Domain.MarginReport:
+		var quantity float64
+		var revenueSum, cogsSum Domain.Money
+		for _, line := range r.Lines {
+			quantity += line.Quantity
+			revenueSum += line.Revenue
+			cogsSum += line.COGS
+		}
+		revenue, cogs := revenueSum.Float64(), cogsSum.Float64()
+		var margin float64
+		if revenue != 0 {
+			margin = (revenue - cogs) / revenue * 100
+		}
+		return map[string]float64{
+			"quantity":     quantity,
+			"revenue":      revenue,
+			"cogs":         cogs,
+			"gross_profit": revenue - cogs,
+			"gross_margin": margin,
+		}
+	}
+	return map[string]float64{}
+}
@@ -0,0 +1,458 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ConsignmentUseCase interface {
+	// SetProductConsignment puts the product's stock on consignment from a supplier
+	SetProductConsignment(ctx context.Context, productID, businessID string, req Domain.SetProductConsignmentRequest) (*Domain.Product, error)
+	// ClearProductConsignment makes the product the shop's own again. Sales already
+	// recorded stay owed to the consignor.
+	ClearProductConsignment(ctx context.Context, productID, businessID string) (*Domain.Product, error)
+	// ReceiveGoods brings goods a consignor leaves with the shop into stock
+	ReceiveGoods(ctx context.Context, businessID, userID string, req Domain.ConsignmentGoodsRequest) ([]Domain.ConsignmentEntry, error)
+	// ReturnGoods takes unsold goods the consignor collects out of stock
+	ReturnGoods(ctx context.Context, businessID, userID string, req Domain.ConsignmentGoodsRequest) ([]Domain.ConsignmentEntry, error)
+	GetEntries(businessID string, filters Domain.ConsignmentEntryFilters) ([]Domain.ConsignmentEntry, error)
+	// GetStatement settles the days from startDate to endDate with the consignor
+	GetStatement(ctx context.Context, consignorID, businessID, startDate, endDate string) (*Domain.ConsignmentStatement, error)
+	// OnSaleChanged brings what a sale owes its consignor in line with the sale, for
+	// the event bus; seeing the same change again records nothing
+	OnSaleChanged(ctx context.Context, event *Domain.DomainEvent) error
+}
+
+type consignmentUseCase struct {
+	consignmentRepo Domain.ConsignmentRepository
+	inventoryRepo   Domain.ProductRepository
+	supplierRepo    Domain.SupplierRepository
+	salesRepo       Domain.SaleRepository
+	businessRepo    Domain.BusinessRepository
+}
+
+func NewConsignmentUseCase(
+	consignmentRepo Domain.ConsignmentRepository,
+	inventoryRepo Domain.ProductRepository,
+	supplierRepo Domain.SupplierRepository,
+	salesRepo Domain.SaleRepository,
+	businessRepo Domain.BusinessRepository,
+) ConsignmentUseCase {
+	return &consignmentUseCase{
+		consignmentRepo: consignmentRepo,
+		inventoryRepo:   inventoryRepo,
+		supplierRepo:    supplierRepo,
+		salesRepo:       salesRepo,
+		businessRepo:    businessRepo,
+	}
+}
+
+func (uc *consignmentUseCase) SetProductConsignment(ctx context.Context, productID, businessID string, req Domain.SetProductConsignmentRequest) (*Domain.Product, error) {
+	if (req.PayoutPerUnit > 0) == (req.CommissionPercent > 0) {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "give either payout_per_unit or commission_percent")
+	}
+
+	product, err := uc.findProduct(ctx, productID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	consignor, err := uc.findConsignor(ctx, req.ConsignorID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	consignment := &Domain.ProductConsignment{
+		ConsignorID:       consignor.ID,
+		PayoutPerUnit:     req.PayoutPerUnit,
+		CommissionPercent: req.CommissionPercent,
+	}
+	if err := uc.inventoryRepo.SetConsignment(productID, consignment); err != nil {
+		return nil, err
+	}
+
+	product.Consignment = consignment
+	product.Version++
+	return product, nil
+}
+
+func (uc *consignmentUseCase) ClearProductConsignment(ctx context.Context, productID, businessID string) (*Domain.Product, error) {
+	product, err := uc.findProduct(ctx, productID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if product.Consignment == nil {
+		return product, nil
+	}
+
+	if err := uc.inventoryRepo.SetConsignment(productID, nil); err != nil {
+		return nil, err
+	}
+
+	product.Consignment = nil
+	product.Version++
+	return product, nil
+}
+
+func (uc *consignmentUseCase) ReceiveGoods(ctx context.Context, businessID, userID string, req Domain.ConsignmentGoodsRequest) ([]Domain.ConsignmentEntry, error) {
+	return uc.moveGoods(ctx, businessID, userID, req, Domain.ConsignmentReceived)
+}
+
+func (uc *consignmentUseCase) ReturnGoods(ctx context.Context, businessID, userID string, req Domain.ConsignmentGoodsRequest) ([]Domain.ConsignmentEntry, error) {
+	return uc.moveGoods(ctx, businessID, userID, req, Domain.ConsignmentReturned)
+}
+
+// moveGoods checks every item is the consignor's before moving any stock, so a
+// bad item does not leave the delivery half recorded
+func (uc *consignmentUseCase) moveGoods(ctx context.Context, businessID, userID string, req Domain.ConsignmentGoodsRequest, entryType Domain.ConsignmentEntryType) ([]Domain.ConsignmentEntry, error) {
+	consignor, err := uc.findConsignor(ctx, req.ConsignorID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	products := make([]*Domain.Product, 0, len(req.Items))
+	for _, item := range req.Items {
+		product, err := uc.findProduct(ctx, item.ProductID, businessID)
+		if err != nil {
+			return nil, err
+		}
+		if product.Consignment == nil || product.Consignment.ConsignorID != consignor.ID {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("%s is not on consignment from %s", product.Name, consignor.Name))
+		}
+		if entryType == Domain.ConsignmentReturned && product.Stock < item.Quantity {
+			return nil, Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("insufficient stock of %s. Available: %.2f, Returning: %.2f", product.Name, product.Stock, item.Quantity))
+		}
+		products = append(products, product)
+	}
+
+	movementType, reason := Domain.MovementTypeConsignmentIn, "Consignment received from "
+	if entryType == Domain.ConsignmentReturned {
+		movementType, reason = Domain.MovementTypeConsignmentReturn, "Consignment returned to "
+	}
+	reason += consignor.Name
+	if req.Reference != "" {
+		reason += " (" + req.Reference + ")"
+	}
+
+	entries := make([]Domain.ConsignmentEntry, 0, len(req.Items))
+	for i, item := range req.Items {
+		product := products[i]
+		if err := uc.inventoryRepo.AdjustStock(item.ProductID, item.Quantity, movementType, reason, nil, "", userID); err != nil {
+			return entries, err
+		}
+
+		entry := Domain.ConsignmentEntry{
+			BusinessID:  product.BusinessID,
+			ConsignorID: consignor.ID,
+			ProductID:   product.ID,
+			Type:        entryType,
+			Quantity:    item.Quantity,
+			Reference:   req.Reference,
+			Notes:       req.Notes,
+			CreatedBy:   &objUserID,
+		}
+		if err := uc.consignmentRepo.Create(&entry); err != nil {
+			return entries, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (uc *consignmentUseCase) GetEntries(businessID string, filters Domain.ConsignmentEntryFilters) ([]Domain.ConsignmentEntry, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	entries, err := uc.consignmentRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []Domain.ConsignmentEntry{}
+	}
+	return entries, nil
+}
+
+func (uc *consignmentUseCase) GetStatement(ctx context.Context, consignorID, businessID, startDate, endDate string) (*Domain.ConsignmentStatement, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	consignor, err := uc.findConsignor(ctx, consignorID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	clock := clockFor(business)
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clock)
+	if err != nil {
+		return nil, err
+	}
+	from, to := clock.Range(fromDay, toDay)
+
+	entries, err := uc.consignmentRepo.FindByBusiness(businessID, Domain.ConsignmentEntryFilters{
+		ConsignorID: &consignorID,
+		StartDate:   &from,
+		EndDate:     &to,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	statement := &Domain.ConsignmentStatement{
+		ConsignorID:   consignor.ID.Hex(),
+		ConsignorName: consignor.Name,
+		StartDate:     fromDay.Format("2006-01-02"),
+		EndDate:       toDay.Format("2006-01-02"),
+		Lines:         []Domain.ConsignmentStatementLine{},
+		Balance:       consignor.Balance,
+	}
+
+	lines := make(map[primitive.ObjectID]*Domain.ConsignmentStatementLine)
+	var productIDs []string
+	for _, entry := range entries {
+		line, ok := lines[entry.ProductID]
+		if !ok {
+			line = &Domain.ConsignmentStatementLine{ProductID: entry.ProductID.Hex()}
+			lines[entry.ProductID] = line
+			productIDs = append(productIDs, entry.ProductID.Hex())
+		}
+
+		switch entry.Type {
+		case Domain.ConsignmentReceived:
+			line.Received += entry.Quantity
+			statement.Received += entry.Quantity
+		case Domain.ConsignmentReturned:
+			line.Returned += entry.Quantity
+			statement.Returned += entry.Quantity
+		case Domain.ConsignmentSold:
+			line.Sold += entry.Quantity
+			line.SalesAmount += entry.SaleAmount
+			line.Payout += entry.Payout
+			statement.Sold += entry.Quantity
+			statement.SalesAmount += entry.SaleAmount
+			statement.Payout += entry.Payout
+		}
+	}
+
+	// Products still on consignment from them with stock are on the statement
+	// even in a quiet period, so the consignor sees everything the shop holds
+	if len(productIDs) > 0 {
+		products, err := uc.inventoryRepo.FindByIDs(businessID, productIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, product := range products {
+			line := lines[product.ID]
+			line.Name = product.Name
+			line.SKU = product.SKU
+			line.OnHand = product.Stock
+		}
+	}
+	consigned, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+	for _, product := range consigned {
+		if product.Consignment == nil || product.Consignment.ConsignorID != consignor.ID || product.Stock == 0 {
+			continue
+		}
+		if _, ok := lines[product.ID]; ok {
+			continue
+		}
+		lines[product.ID] = &Domain.ConsignmentStatementLine{
+			ProductID: product.ID.Hex(),
+			Name:      product.Name,
+			SKU:       product.SKU,
+			OnHand:    product.Stock,
+		}
+	}
+
+	for _, line := range lines {
+		statement.Lines = append(statement.Lines, *line)
+	}
+	sort.Slice(statement.Lines, func(i, j int) bool {
+		return statement.Lines[i].Name < statement.Lines[j].Name
+	})
+
+	payables, err := uc.supplierRepo.GetPayableEntries(consignorID, 0)
+	if err != nil {
+		return nil, err
+	}
+	for _, entry := range payables {
+		if entry.Type == Domain.PayableEntryInvoice || entry.Date.Before(from) || !entry.Date.Before(to) {
+			continue
+		}
+		statement.Paid += entry.Amount
+	}
+
+	return statement, nil
+}
+
+func (uc *consignmentUseCase) OnSaleChanged(ctx context.Context, event *Domain.DomainEvent) error {
+	// The sale as it is now, not as the event saw it, so changes arriving out of
+	// order still settle on the right amount
+	sale, err := uc.salesRepo.FindInBusiness(ctx, event.AggregateID)
+	if err != nil {
+		return err
+	}
+	if sale == nil {
+		return nil
+	}
+
+	recorded, err := uc.consignmentRepo.FindBySale(sale.ID)
+	if err != nil {
+		return err
+	}
+	// A recorded sale seen again has nothing new to tell, even if the product's
+	// terms changed since
+	if event.Type == Domain.EventSaleRecorded && len(recorded) > 0 {
+		return nil
+	}
+
+	type key struct{ consignorID, productID primitive.ObjectID }
+	type owed struct {
+		quantity float64
+		amount   Domain.Money
+		payout   Domain.Money
+	}
+
+	balance := make(map[key]owed)
+	for _, entry := range recorded {
+		k := key{entry.ConsignorID, entry.ProductID}
+		o := balance[k]
+		o.quantity += entry.Quantity
+		o.amount += entry.SaleAmount
+		o.payout += entry.Payout
+		balance[k] = o
+	}
+
+	target := make(map[key]owed)
+	var product *Domain.Product
+	if sale.Status == Domain.SaleStatusCompleted && sale.ProductID != nil {
+		product, err = uc.inventoryRepo.FindInBusiness(ctx, sale.ProductID.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to find product: %w", err)
+		}
+		if product != nil && product.Consignment != nil {
+			target[key{product.Consignment.ConsignorID, product.ID}] = owed{
+				quantity: sale.Quantity,
+				amount:   sale.FinalAmount,
+				payout:   product.Consignment.Payout(sale.Quantity, sale.FinalAmount),
+			}
+		}
+	}
+	for k := range target {
+		if _, ok := balance[k]; !ok {
+			balance[k] = owed{}
+		}
+	}
+
+	for k, current := range balance {
+		want := target[k]
+		delta := owed{
+			quantity: want.quantity - current.quantity,
+			amount:   want.amount - current.amount,
+			payout:   want.payout - current.payout,
+		}
+		if delta.quantity == 0 && delta.amount == 0 && delta.payout == 0 {
+			continue
+		}
+
+		// The first entry is dated with the sale, so it falls in the sale's period
+		entry := &Domain.ConsignmentEntry{
+			BusinessID:  sale.BusinessID,
+			ConsignorID: k.consignorID,
+			ProductID:   k.productID,
+			Type:        Domain.ConsignmentSold,
+			Quantity:    delta.quantity,
+			SaleID:      &sale.ID,
+			SaleAmount:  delta.amount,
+			Payout:      delta.payout,
+		}
+		if len(recorded) == 0 {
+			entry.CreatedAt = sale.CreatedAt
+		}
+		if err := uc.consignmentRepo.Create(entry); err != nil {
+			return err
+		}
+
+		if delta.payout != 0 {
+			if err := uc.recordPayable(ctx, sale, entry); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// recordPayable puts what the sale entry changed on the consignor's payables ledger
+func (uc *consignmentUseCase) recordPayable(ctx context.Context, sale *Domain.Sale, entry *Domain.ConsignmentEntry) error {
+	consignor, err := uc.supplierRepo.FindInBusiness(ctx, entry.ConsignorID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to find consignor: %w", err)
+	}
+	if consignor == nil {
+		return fmt.Errorf("consignor %s not found", entry.ConsignorID.Hex())
+	}
+
+	payable := &Domain.PayableEntry{
+		BusinessID: entry.BusinessID,
+		SupplierID: consignor.ID,
+		Type:       Domain.PayableEntryInvoice,
+		Amount:     entry.Payout,
+		Reference:  "Sale " + sale.ID.Hex(),
+		Date:       entry.CreatedAt,
+		Notes:      "Consignment sale",
+		CreatedBy:  sale.CreatedBy,
+	}
+	if entry.Payout < 0 {
+		payable.Type = Domain.PayableEntryCredit
+		payable.Amount = -entry.Payout
+		payable.Notes = "Consignment sale changed or voided"
+	} else {
+		due := entry.CreatedAt.AddDate(0, 0, consignor.PaymentTermsDays)
+		payable.DueDate = &due
+	}
+
+	return uc.supplierRepo.AddPayableEntry(payable)
+}
+
+func (uc *consignmentUseCase) findProduct(ctx context.Context, productID, businessID string) (*Domain.Product, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+	return product, nil
+}
+
+func (uc *consignmentUseCase) findConsignor(ctx context.Context, consignorID, businessID string) (*Domain.Supplier, error) {
+	consignor, err := uc.supplierRepo.FindInBusiness(ctx, consignorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find consignor: %w", err)
+	}
+	if consignor == nil || consignor.DeletedAt != nil {
+		return nil, Domain.NotFoundError("consignor not found")
+	}
+	return consignor, nil
+}
@@ -0,0 +1,301 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// CurrencyUseCase manages the foreign currencies a shop takes and their rates
+type CurrencyUseCase interface {
+	GetSettings(businessID string) (*Domain.CurrencySettings, error)
+	// UpdateSettings changes the accepted currencies and cash rounding; turning AutoFetch on fetches rates at once
+	UpdateSettings(ctx context.Context, businessID string, req Domain.UpdateCurrencySettingsRequest) (*Domain.CurrencySettings, error)
+	// SetRate records a rate by hand, in force from now on
+	SetRate(businessID, userID string, req Domain.SetExchangeRateRequest) (*Domain.ExchangeRate, error)
+	// GetRates returns the rate in force for each accepted currency that has one
+	GetRates(businessID string) ([]Domain.ExchangeRate, error)
+	GetRateHistory(businessID, currency string, limit int) ([]Domain.ExchangeRate, error)
+	// GetTakings totals the foreign currency taken between two times, by currency
+	GetTakings(businessID string, startDate, endDate time.Time) ([]Domain.ForeignCurrencyTakings, error)
+	// FetchDue fetches market rates for the shops with AutoFetch whose rates are due
+	FetchDue() error
+}
+
+type currencyUseCase struct {
+	currencyRepo Domain.CurrencyRepository
+	businessRepo Domain.BusinessRepository
+	salesRepo    Domain.SaleRepository
+	provider     Infrastructure.ExchangeRateProvider
+}
+
+func NewCurrencyUseCase(
+	currencyRepo Domain.CurrencyRepository,
+	businessRepo Domain.BusinessRepository,
+	salesRepo Domain.SaleRepository,
+	provider Infrastructure.ExchangeRateProvider,
+) CurrencyUseCase {
+	return &currencyUseCase{
+		currencyRepo: currencyRepo,
+		businessRepo: businessRepo,
+		salesRepo:    salesRepo,
+		provider:     provider,
+	}
+}
+
+func (uc *currencyUseCase) GetSettings(businessID string) (*Domain.CurrencySettings, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	settings, err := uc.currencyRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		settings = &Domain.CurrencySettings{BusinessID: business.ID, Accepted: []string{}}
+	}
+	settings.BaseCurrency = business.Currency
+
+	return settings, nil
+}
+
+func (uc *currencyUseCase) UpdateSettings(ctx context.Context, businessID string, req Domain.UpdateCurrencySettingsRequest) (*Domain.CurrencySettings, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Accepted != nil {
+		accepted := []string{}
+		seen := map[string]bool{}
+		for _, code := range req.Accepted {
+			currency, ok := Domain.NormalizeCurrency(code)
+			if !ok {
+				return nil, Domain.ValidationError(fmt.Sprintf("%q is not a currency code", code))
+			}
+			if currency == settings.BaseCurrency {
+				return nil, Domain.ValidationError(currency + " is the shop's base currency")
+			}
+			if !seen[currency] {
+				seen[currency] = true
+				accepted = append(accepted, currency)
+			}
+		}
+		settings.Accepted = accepted
+	}
+
+	if req.CashRounding != nil {
+		rounding, err := normalizeCashRounding(*req.CashRounding)
+		if err != nil {
+			return nil, err
+		}
+		settings.CashRounding = rounding
+	}
+
+	fetchNow := req.AutoFetch != nil && *req.AutoFetch && !settings.AutoFetch
+	if req.AutoFetch != nil {
+		settings.AutoFetch = *req.AutoFetch
+	}
+
+	if fetchNow && len(settings.Accepted) > 0 {
+		uc.fetch(ctx, settings)
+	}
+
+	if err := uc.currencyRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
+func (uc *currencyUseCase) SetRate(businessID, userID string, req Domain.SetExchangeRateRequest) (*Domain.ExchangeRate, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	currency, ok := Domain.NormalizeCurrency(req.Currency)
+	if !ok {
+		return nil, Domain.ValidationError(fmt.Sprintf("%q is not a currency code", req.Currency))
+	}
+	if !settings.Accepts(currency) {
+		return nil, Domain.ValidationError(currency + " is not one of the shop's accepted currencies")
+	}
+	if req.Rate <= 0 {
+		return nil, Domain.ValidationError("rate must be greater than 0")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	rate := &Domain.ExchangeRate{
+		BusinessID:   settings.BusinessID,
+		BaseCurrency: settings.BaseCurrency,
+		Currency:     currency,
+		Rate:         roundRate(req.Rate),
+		Source:       Domain.ExchangeRateManual,
+		CreatedBy:    &objUserID,
+	}
+	if err := uc.currencyRepo.CreateRate(rate); err != nil {
+		return nil, err
+	}
+
+	return rate, nil
+}
+
+func (uc *currencyUseCase) GetRates(businessID string) ([]Domain.ExchangeRate, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	rates := []Domain.ExchangeRate{}
+	for _, currency := range settings.Accepted {
+		rate, err := currentRate(uc.currencyRepo, businessID, settings.BaseCurrency, currency)
+		if err != nil {
+			return nil, err
+		}
+		if rate != nil {
+			rates = append(rates, *rate)
+		}
+	}
+
+	return rates, nil
+}
+
+func (uc *currencyUseCase) GetRateHistory(businessID, currency string, limit int) ([]Domain.ExchangeRate, error) {
+	code, ok := Domain.NormalizeCurrency(currency)
+	if !ok {
+		return nil, Domain.ValidationError(fmt.Sprintf("%q is not a currency code", currency))
+	}
+	if limit <= 0 || limit > 500 {
+		limit = 100
+	}
+	return uc.currencyRepo.FindRates(businessID, code, limit)
+}
+
+func (uc *currencyUseCase) GetTakings(businessID string, startDate, endDate time.Time) ([]Domain.ForeignCurrencyTakings, error) {
+	if endDate.Before(startDate) {
+		return nil, Domain.ValidationError("end_date must not be before start_date")
+	}
+	return uc.salesRepo.GetForeignTakings(businessID, startDate, endDate)
+}
+
+func (uc *currencyUseCase) FetchDue() error {
+	all, err := uc.currencyRepo.FindAutoFetchSettings()
+	if err != nil {
+		return err
+	}
+
+	due := time.Now().Add(-Domain.ExchangeRateHours * time.Hour)
+	for i := range all {
+		settings := &all[i]
+		if settings.LastFetchedAt != nil && settings.LastFetchedAt.After(due) {
+			continue
+		}
+
+		business, err := uc.businessRepo.FindByID(settings.BusinessID.Hex())
+		if err != nil || business == nil {
+			continue
+		}
+		settings.BaseCurrency = business.Currency
+
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		uc.fetch(ctx, settings)
+		cancel()
+
+		if err := uc.currencyRepo.SaveSettings(settings); err != nil {
+			Infrastructure.Logger.Warn("failed to save currency settings for business", "business_id", settings.BusinessID.Hex(), "error", err)
+		}
+	}
+
+	return nil
+}
+
+// fetch records market rates for the shop's accepted currencies, noting on
+// settings when it ran and what went wrong
+func (uc *currencyUseCase) fetch(ctx context.Context, settings *Domain.CurrencySettings) {
+	now := time.Now()
+	settings.LastFetchedAt = &now
+	settings.LastError = ""
+
+	market, err := uc.provider.FetchRates(ctx, settings.BaseCurrency)
+	if err != nil {
+		settings.LastError = err.Error()
+		return
+	}
+
+	var missing []string
+	for _, currency := range settings.Accepted {
+		perBase, ok := market[currency]
+		if !ok || perBase <= 0 {
+			missing = append(missing, currency)
+			continue
+		}
+		rate := &Domain.ExchangeRate{
+			BusinessID:   settings.BusinessID,
+			BaseCurrency: settings.BaseCurrency,
+			Currency:     currency,
+			Rate:         roundRate(1 / perBase),
+			Source:       Domain.ExchangeRateFetched,
+		}
+		if err := uc.currencyRepo.CreateRate(rate); err != nil {
+			settings.LastError = err.Error()
+			return
+		}
+	}
+	if len(missing) > 0 {
+		settings.LastError = fmt.Sprintf("no market rate for %v", missing)
+	}
+}
+
+// currentRate is the rate in force for currency, ignoring rates set against a
+// base currency the shop has since moved away from
+func currentRate(repo Domain.CurrencyRepository, businessID, baseCurrency, currency string) (*Domain.ExchangeRate, error) {
+	rate, err := repo.FindLatestRate(businessID, currency)
+	if err != nil {
+		return nil, err
+	}
+	if rate == nil || rate.BaseCurrency != baseCurrency {
+		return nil, nil
+	}
+	return rate, nil
+}
+
+func roundRate(rate float64) float64 {
+	scale := math.Pow(10, Domain.ExchangeRateDecimals)
+	return math.Round(rate*scale) / scale
+}
+
+// normalizeCashRounding checks a rounding rule, nil when it turns rounding off.
+// Increments must be whole cents so rounded amounts are too.
+func normalizeCashRounding(rounding Domain.CashRounding) (*Domain.CashRounding, error) {
+	if rounding.Increment == 0 {
+		return nil, nil
+	}
+	cents := math.Round(rounding.Increment * 100)
+	if rounding.Increment < 0.01 || rounding.Increment > 100 || math.Abs(cents-rounding.Increment*100) > 1e-6 {
+		return nil, Domain.ValidationError("cash_rounding increment must be whole cents between 0.01 and 100, e.g. 0.25 or 1")
+	}
+	if rounding.Mode == "" {
+		rounding.Mode = Domain.CashRoundingNearest
+	}
+	if !rounding.Mode.IsValid() {
+		return nil, Domain.ValidationError("cash_rounding mode must be nearest, up or down")
+	}
+	rounding.Increment = cents / 100
+	return &rounding, nil
+}
@@ -0,0 +1,139 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CustomFieldUseCase interface {
+	CreateField(businessID string, req Domain.CreateCustomFieldRequest) (*Domain.CustomFieldDefinition, error)
+	// GetFields lists the shop's fields, of one entity when entity is set
+	GetFields(businessID string, entity Domain.CustomFieldEntity) ([]Domain.CustomFieldDefinition, error)
+	// UpdateField changes a field's label and rules; its key and type stay, as
+	// records hold values under them
+	UpdateField(ctx context.Context, id, businessID string, req Domain.UpdateCustomFieldRequest) (*Domain.CustomFieldDefinition, error)
+}
+
+type customFieldUseCase struct {
+	customFieldRepo Domain.CustomFieldRepository
+}
+
+func NewCustomFieldUseCase(customFieldRepo Domain.CustomFieldRepository) CustomFieldUseCase {
+	return &customFieldUseCase{customFieldRepo: customFieldRepo}
+}
+
+func (uc *customFieldUseCase) CreateField(businessID string, req Domain.CreateCustomFieldRequest) (*Domain.CustomFieldDefinition, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	def := &Domain.CustomFieldDefinition{
+		BusinessID: objBusinessID,
+		Entity:     req.Entity,
+		Key:        req.Key,
+		Label:      req.Label,
+		Type:       req.Type,
+		Required:   req.Required,
+		Options:    req.Options,
+		MaxLength:  req.MaxLength,
+		Min:        req.Min,
+		Max:        req.Max,
+		Pattern:    req.Pattern,
+		Searchable: req.Searchable,
+		Position:   req.Position,
+		Active:     true,
+	}
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	count, err := uc.customFieldRepo.Count(businessID, req.Entity)
+	if err != nil {
+		return nil, err
+	}
+	if count >= Domain.MaxCustomFields {
+		return nil, Domain.ValidationError(fmt.Sprintf("a shop can have up to %d custom fields per %s", Domain.MaxCustomFields, req.Entity))
+	}
+
+	if err := uc.customFieldRepo.Create(def); err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+func (uc *customFieldUseCase) GetFields(businessID string, entity Domain.CustomFieldEntity) ([]Domain.CustomFieldDefinition, error) {
+	if entity != "" && !entity.IsValid() {
+		return nil, Domain.ValidationError("entity must be product, customer, supplier or sale")
+	}
+	return uc.customFieldRepo.FindByBusinessID(businessID, entity, false)
+}
+
+func (uc *customFieldUseCase) UpdateField(ctx context.Context, id, businessID string, req Domain.UpdateCustomFieldRequest) (*Domain.CustomFieldDefinition, error) {
+	def, err := uc.customFieldRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if def == nil {
+		return nil, Domain.NotFoundError("custom field not found")
+	}
+
+	if req.Label != nil {
+		def.Label = *req.Label
+	}
+	if req.Required != nil {
+		def.Required = *req.Required
+	}
+	if req.Options != nil {
+		def.Options = req.Options
+	}
+	if req.MaxLength != nil {
+		def.MaxLength = *req.MaxLength
+	}
+	if req.Min != nil {
+		def.Min = req.Min
+	}
+	if req.Max != nil {
+		def.Max = req.Max
+	}
+	if req.Pattern != nil {
+		def.Pattern = *req.Pattern
+	}
+	if req.Searchable != nil {
+		def.Searchable = *req.Searchable
+	}
+	if req.Position != nil {
+		def.Position = *req.Position
+	}
+	if req.Active != nil {
+		def.Active = *req.Active
+	}
+
+	if err := def.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.customFieldRepo.Update(def); err != nil {
+		return nil, err
+	}
+
+	return def, nil
+}
+
+// checkCustomFields validates a record's custom field values against the shop's
+// active fields for entity; see Domain.ValidateCustomFields
+func checkCustomFields(repo Domain.CustomFieldRepository, businessID string, entity Domain.CustomFieldEntity, values, existing Domain.CustomFields, requireAll bool) (Domain.CustomFields, error) {
+	defs, err := repo.FindByBusinessID(businessID, entity, true)
+	if err != nil {
+		return nil, err
+	}
+	if len(defs) == 0 && len(values) == 0 {
+		return existing, nil
+	}
+	return Domain.ValidateCustomFields(defs, values, existing, requireAll)
+}
@@ -0,0 +1,463 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type CustomReportUseCase interface {
+	CreateReport(businessID, userID string, req Domain.CreateSavedReportRequest) (*Domain.SavedReport, error)
+	GetReports(businessID string) ([]Domain.SavedReport, error)
+	GetReportByID(ctx context.Context, id, businessID string) (*Domain.SavedReport, error)
+	UpdateReport(ctx context.Context, id, businessID string, req Domain.UpdateSavedReportRequest) (*Domain.SavedReport, error)
+	DeleteReport(ctx context.Context, id, businessID string) error
+	RunReport(ctx context.Context, id, businessID, startDate, endDate string) (*Domain.CustomReportResult, error)
+	PreviewReport(businessID string, definition Domain.CustomReportDefinition, startDate, endDate string) (*Domain.CustomReportResult, error)
+	GetReportRuns(ctx context.Context, id, businessID string, limit int) ([]Domain.SavedReportRun, error)
+	RunScheduledReports() error
+}
+
+type customReportUseCase struct {
+	savedReportRepo Domain.SavedReportRepository
+	analyticsRepo   Domain.AnalyticsRepository
+	businessRepo    Domain.BusinessRepository
+	analyticsUC     AnalyticsUseCase
+}
+
+const (
+	defaultCustomReportRows      = 100
+	defaultCustomReportRangeDays = 30
+	// Scheduled reports run at this local hour, once offline devices have had time to sync
+	customReportRunHour = 1
+)
+
+func NewCustomReportUseCase(
+	savedReportRepo Domain.SavedReportRepository,
+	analyticsRepo Domain.AnalyticsRepository,
+	businessRepo Domain.BusinessRepository,
+	analyticsUC AnalyticsUseCase,
+) CustomReportUseCase {
+	return &customReportUseCase{
+		savedReportRepo: savedReportRepo,
+		analyticsRepo:   analyticsRepo,
+		businessRepo:    businessRepo,
+		analyticsUC:     analyticsUC,
+	}
+}
+
+func (uc *customReportUseCase) CreateReport(businessID, userID string, req Domain.CreateSavedReportRequest) (*Domain.SavedReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("report name is required")
+	}
+
+	if err := req.Definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	schedule := req.Schedule
+	if schedule == "" {
+		schedule = Domain.ReportScheduleNone
+	}
+	if !schedule.IsValid() {
+		return nil, fmt.Errorf("invalid schedule: %s", schedule)
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	report := &Domain.SavedReport{
+		BusinessID:  objBusinessID,
+		Name:        name,
+		Description: req.Description,
+		Definition:  req.Definition,
+		Schedule:    schedule,
+		NextRunAt:   nextReportRun(schedule, time.Now(), businessLocation(business)),
+		CreatedBy:   objUserID,
+	}
+
+	if err := uc.savedReportRepo.Create(report); err != nil {
+		return nil, fmt.Errorf("failed to create report: %w", err)
+	}
+
+	return report, nil
+}
+
+func (uc *customReportUseCase) GetReports(businessID string) ([]Domain.SavedReport, error) {
+	reports, err := uc.savedReportRepo.FindByBusinessID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []Domain.SavedReport{}
+	}
+	return reports, nil
+}
+
+func (uc *customReportUseCase) GetReportByID(ctx context.Context, id, businessID string) (*Domain.SavedReport, error) {
+	report, err := uc.savedReportRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find report: %w", err)
+	}
+	if report == nil {
+		return nil, Domain.NotFoundError("report not found")
+	}
+
+	// Verify report belongs to business
+
+	return report, nil
+}
+
+func (uc *customReportUseCase) UpdateReport(ctx context.Context, id, businessID string, req Domain.UpdateSavedReportRequest) (*Domain.SavedReport, error) {
+	report, err := uc.GetReportByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("report name is required")
+		}
+		report.Name = name
+	}
+	if req.Description != nil {
+		report.Description = *req.Description
+	}
+	if req.Definition != nil {
+		if err := req.Definition.Validate(); err != nil {
+			return nil, err
+		}
+		report.Definition = *req.Definition
+	}
+	if req.Schedule != nil && *req.Schedule != report.Schedule {
+		if !req.Schedule.IsValid() {
+			return nil, fmt.Errorf("invalid schedule: %s", *req.Schedule)
+		}
+
+		business, err := uc.businessRepo.FindByID(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find business: %w", err)
+		}
+		if business == nil {
+			return nil, Domain.NotFoundError("business not found")
+		}
+
+		report.Schedule = *req.Schedule
+		report.NextRunAt = nextReportRun(report.Schedule, time.Now(), businessLocation(business))
+	}
+
+	if err := uc.savedReportRepo.Update(report); err != nil {
+		return nil, fmt.Errorf("failed to update report: %w", err)
+	}
+
+	return report, nil
+}
+
+func (uc *customReportUseCase) DeleteReport(ctx context.Context, id, businessID string) error {
+	if _, err := uc.GetReportByID(ctx, id, businessID); err != nil {
+		return err
+	}
+
+	return uc.savedReportRepo.Delete(id)
+}
+
+// RunReport executes a saved report on demand over the given dates, or its rolling window
+func (uc *customReportUseCase) RunReport(ctx context.Context, id, businessID, startDate, endDate string) (*Domain.CustomReportResult, error) {
+	report, err := uc.GetReportByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := uc.runOnDemand(businessID, report.Definition, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	result.ReportID = report.ID.Hex()
+	result.Name = report.Name
+	return result, nil
+}
+
+// PreviewReport executes a definition without saving it
+func (uc *customReportUseCase) PreviewReport(businessID string, definition Domain.CustomReportDefinition, startDate, endDate string) (*Domain.CustomReportResult, error) {
+	if err := definition.Validate(); err != nil {
+		return nil, err
+	}
+
+	return uc.runOnDemand(businessID, definition, startDate, endDate)
+}
+
+func (uc *customReportUseCase) GetReportRuns(ctx context.Context, id, businessID string, limit int) ([]Domain.SavedReportRun, error) {
+	if _, err := uc.GetReportByID(ctx, id, businessID); err != nil {
+		return nil, err
+	}
+
+	runs, err := uc.savedReportRepo.FindRuns(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	if runs == nil {
+		runs = []Domain.SavedReportRun{}
+	}
+	return runs, nil
+}
+
+// RunScheduledReports executes every report whose next run has come and stores the result
+func (uc *customReportUseCase) RunScheduledReports() error {
+	now := time.Now()
+
+	reports, err := uc.savedReportRepo.FindDue(now)
+	if err != nil {
+		return err
+	}
+
+	for i := range reports {
+		if err := uc.runScheduled(&reports[i], now); err != nil {
+			// Left due, so the next pass retries it
+			Infrastructure.Logger.Warn("failed to run scheduled report", "report_id", reports[i].ID.Hex(), "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *customReportUseCase) runScheduled(report *Domain.SavedReport, now time.Time) error {
+	businessID := report.BusinessID.Hex()
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return Domain.NotFoundError("business not found")
+	}
+	clock := clockFor(business)
+
+	fromDay, toDay := scheduledReportWindow(report.Definition, report.Schedule, clock.Day(now))
+
+	uc.analyticsUC.Refresh(businessID)
+
+	result, err := uc.execute(businessID, report.Definition, fromDay, toDay)
+	if err != nil {
+		return err
+	}
+	result.ReportID = report.ID.Hex()
+	result.Name = report.Name
+
+	run := &Domain.SavedReportRun{
+		BusinessID: report.BusinessID,
+		ReportID:   report.ID,
+		Result:     *result,
+	}
+	if err := uc.savedReportRepo.CreateRun(run); err != nil {
+		return err
+	}
+
+	return uc.savedReportRepo.UpdateRunTimes(report.ID, now, nextReportRun(report.Schedule, now, clock.loc))
+}
+
+func (uc *customReportUseCase) runOnDemand(businessID string, definition Domain.CustomReportDefinition, startDate, endDate string) (*Domain.CustomReportResult, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+	clock := clockFor(business)
+
+	var fromDay, toDay time.Time
+	if startDate == "" && endDate == "" {
+		days := definition.RangeDays
+		if days == 0 {
+			days = defaultCustomReportRangeDays
+		}
+		toDay = clock.Day(time.Now())
+		fromDay = toDay.AddDate(0, 0, -(days - 1))
+	} else {
+		fromDay, toDay, err = parseDayRange(startDate, endDate, clock)
+		if err != nil {
+			return nil, err
+		}
+		if int(toDay.Sub(fromDay).Hours()/24) >= Domain.MaxCustomReportRangeDays {
+			return nil, fmt.Errorf("a report can cover at most %d days", Domain.MaxCustomReportRangeDays)
+		}
+	}
+
+	uc.analyticsUC.Refresh(businessID)
+
+	return uc.execute(businessID, definition, fromDay, toDay)
+}
+
+// execute groups the daily product aggregates and computes the measures for each group
+func (uc *customReportUseCase) execute(businessID string, definition Domain.CustomReportDefinition, fromDay, toDay time.Time) (*Domain.CustomReportResult, error) {
+	pivot, err := uc.analyticsRepo.Pivot(businessID, fromDay, toDay, definition.Dimensions, definition.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &Domain.CustomReportResult{
+		StartDate:  fromDay.Format("2006-01-02"),
+		EndDate:    toDay.Format("2006-01-02"),
+		Dimensions: definition.Dimensions,
+		Measures:   definition.Measures,
+		Rows:       make([]Domain.CustomReportRow, 0, len(pivot)),
+		TotalRows:  len(pivot),
+		Totals:     make(map[Domain.ReportMeasure]float64, len(definition.Measures)),
+		ComputedAt: time.Now(),
+	}
+	if result.Dimensions == nil {
+		result.Dimensions = []Domain.ReportDimension{}
+	}
+
+	var total Domain.ReportPivotRow
+	for _, p := range pivot {
+		row := Domain.CustomReportRow{
+			Dimensions: p.Keys,
+			Measures:   make(map[Domain.ReportMeasure]float64, len(definition.Measures)),
+		}
+		if len(p.Labels) > 0 {
+			row.Labels = p.Labels
+		}
+		for _, measure := range definition.Measures {
+			row.Measures[measure] = reportMeasureValue(measure, p)
+		}
+		result.Rows = append(result.Rows, row)
+
+		total.Quantity += p.Quantity
+		total.Revenue += p.Revenue
+		total.COGS += p.COGS
+		total.Transactions += p.Transactions
+	}
+
+	for _, measure := range definition.Measures {
+		result.Totals[measure] = reportMeasureValue(measure, total)
+	}
+
+	sortCustomReportRows(result.Rows, definition)
+
+	limit := definition.Limit
+	if limit == 0 {
+		limit = defaultCustomReportRows
+	}
+	if len(result.Rows) > limit {
+		result.Rows = result.Rows[:limit]
+	}
+
+	return result, nil
+}
+
+func reportMeasureValue(measure Domain.ReportMeasure, row Domain.ReportPivotRow) float64 {
+	switch measure {
+	case Domain.ReportMeasureRevenue:
+		return row.Revenue.Float64()
+	case Domain.ReportMeasureQuantity:
+		return roundCurrency(row.Quantity)
+	case Domain.ReportMeasureCOGS:
+		return row.COGS.Float64()
+	case Domain.ReportMeasureGrossProfit:
+		return (row.Revenue - row.COGS).Float64()
+	case Domain.ReportMeasureMargin:
+		if row.Revenue == 0 {
+			return 0
+		}
+		return roundCurrency((row.Revenue - row.COGS).Float64() / row.Revenue.Float64() * 100)
+	case Domain.ReportMeasureTransactions:
+		return float64(row.Transactions)
+	}
+	return 0
+}
+
+// sortCustomReportRows orders rows by the sort measure, largest first unless the order is
+// asc; ties fall back to the dimension values so runs come out in the same order
+func sortCustomReportRows(rows []Domain.CustomReportRow, definition Domain.CustomReportDefinition) {
+	sortBy := definition.SortBy
+	if sortBy == "" {
+		sortBy = definition.Measures[0]
+	}
+	asc := definition.Order == "asc"
+
+	sort.SliceStable(rows, func(i, j int) bool {
+		a, b := rows[i].Measures[sortBy], rows[j].Measures[sortBy]
+		if a != b {
+			if asc {
+				return a < b
+			}
+			return a > b
+		}
+		for _, dim := range definition.Dimensions {
+			if rows[i].Dimensions[dim] != rows[j].Dimensions[dim] {
+				return rows[i].Dimensions[dim] < rows[j].Dimensions[dim]
+			}
+		}
+		return false
+	})
+}
+
+// scheduledReportWindow is the complete days a scheduled run on runDay covers: the
+// rolling window when one is set, otherwise the day, week or month just finished
+func scheduledReportWindow(definition Domain.CustomReportDefinition, schedule Domain.ReportSchedule, runDay time.Time) (time.Time, time.Time) {
+	toDay := runDay.AddDate(0, 0, -1)
+
+	if definition.RangeDays > 0 {
+		return toDay.AddDate(0, 0, -(definition.RangeDays - 1)), toDay
+	}
+
+	switch schedule {
+	case Domain.ReportScheduleDaily:
+		return toDay, toDay
+	case Domain.ReportScheduleWeekly:
+		return toDay.AddDate(0, 0, -6), toDay
+	case Domain.ReportScheduleMonthly:
+		return time.Date(toDay.Year(), toDay.Month(), 1, 0, 0, 0, 0, time.UTC), toDay
+	}
+	return toDay.AddDate(0, 0, -(defaultCustomReportRangeDays - 1)), toDay
+}
+
+// nextReportRun is the first run slot of the schedule after the given time, or nil
+// when the report is not scheduled
+func nextReportRun(schedule Domain.ReportSchedule, after time.Time, loc *time.Location) *time.Time {
+	if schedule == Domain.ReportScheduleNone || schedule == "" {
+		return nil
+	}
+
+	local := after.In(loc)
+	for i := 0; i <= 32; i++ {
+		candidate := time.Date(local.Year(), local.Month(), local.Day()+i, customReportRunHour, 0, 0, 0, loc)
+		if !candidate.After(after) {
+			continue
+		}
+
+		switch {
+		case schedule == Domain.ReportScheduleDaily,
+			schedule == Domain.ReportScheduleWeekly && candidate.Weekday() == time.Monday,
+			schedule == Domain.ReportScheduleMonthly && candidate.Day() == 1:
+			return &candidate
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,593 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	Domain "ShopOps/Domain"
+)
+
+type CustomerUseCase interface {
+	CreateCustomer(businessID, userID string, req Domain.CreateCustomerRequest) (*Domain.Customer, error)
+	GetCustomerByID(ctx context.Context, id, businessID string) (*Domain.Customer, error)
+	// GetCustomersByPhones looks up the shop's customers for phones as typed on
+	// sales, keyed by the phone as given; phones with no customer are left out
+	GetCustomersByPhones(businessID string, phones []string) (map[string]*Domain.Customer, error)
+	// GetCustomers lists customers, with the total across all pages if includeTotal is set
+	GetCustomers(businessID string, filters Domain.CustomerFilters, includeTotal bool) (*Domain.CustomerListResponse, error)
+	CountCustomers(businessID string, filters Domain.CustomerFilters) (int64, error)
+	UpdateCustomer(ctx context.Context, id, businessID string, req Domain.UpdateCustomerRequest) (*Domain.Customer, error)
+	DeleteCustomer(ctx context.Context, id, businessID string) error
+	GetCustomerSales(ctx context.Context, id, businessID string, limit, offset int) ([]Domain.Sale, error)
+	FindDuplicates(businessID string) ([]Domain.DuplicateCustomerGroup, error)
+	MergeCustomers(ctx context.Context, targetID, businessID, userID string, req Domain.MergeCustomersRequest) (*Domain.MergeCustomersResult, error)
+}
+
+type customerUseCase struct {
+	customerRepo    Domain.CustomerRepository
+	businessRepo    Domain.BusinessRepository
+	salesRepo       Domain.SaleRepository
+	giftCardRepo    Domain.GiftCardRepository
+	loyaltyRepo     Domain.LoyaltyRepository
+	customFieldRepo Domain.CustomFieldRepository
+}
+
+func NewCustomerUseCase(
+	customerRepo Domain.CustomerRepository,
+	businessRepo Domain.BusinessRepository,
+	salesRepo Domain.SaleRepository,
+	giftCardRepo Domain.GiftCardRepository,
+	loyaltyRepo Domain.LoyaltyRepository,
+	customFieldRepo Domain.CustomFieldRepository,
+) CustomerUseCase {
+	return &customerUseCase{
+		customerRepo:    customerRepo,
+		businessRepo:    businessRepo,
+		salesRepo:       salesRepo,
+		giftCardRepo:    giftCardRepo,
+		loyaltyRepo:     loyaltyRepo,
+		customFieldRepo: customFieldRepo,
+	}
+}
+
+func (uc *customerUseCase) CreateCustomer(businessID, userID string, req Domain.CreateCustomerRequest) (*Domain.Customer, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("customer name is required")
+	}
+
+	tier := req.Tier
+	if tier == "" {
+		tier = Domain.CustomerTierRetail
+	}
+	if !tier.IsValid() {
+		return nil, fmt.Errorf("invalid customer tier: %s", tier)
+	}
+
+	phone := normalizePhone(req.Phone, business.Country)
+	if phone != "" {
+		existing, err := uc.customerRepo.FindByPhone(businessID, phone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check customer phone: %w", err)
+		}
+		if existing != nil {
+			return nil, fmt.Errorf("customer with phone %s already exists", phone)
+		}
+	}
+
+	customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntityCustomer, req.CustomFields, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	customer := &Domain.Customer{
+		BusinessID: objBusinessID,
+		Name:       name,
+		Phone:      phone,
+		Email:      strings.ToLower(strings.TrimSpace(req.Email)),
+		Address:    req.Address,
+		Notes:      req.Notes,
+		Tags:       req.Tags,
+		Tier:       tier,
+		CreatedBy:  objUserID,
+
+		CustomFields: customFields,
+	}
+
+	if req.MarketingOptIn {
+		now := time.Now()
+		customer.MarketingOptIn = true
+		customer.OptInUpdatedAt = &now
+		customer.OptInSource = "staff"
+	}
+
+	if err := uc.customerRepo.Create(customer); err != nil {
+		return nil, fmt.Errorf("failed to create customer: %w", err)
+	}
+
+	return customer, nil
+}
+
+func (uc *customerUseCase) GetCustomerByID(ctx context.Context, id, businessID string) (*Domain.Customer, error) {
+	customer, err := uc.customerRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer: %w", err)
+	}
+	if customer == nil || customer.DeletedAt != nil {
+		return nil, Domain.NotFoundError("customer not found")
+	}
+
+	return customer, nil
+}
+
+func (uc *customerUseCase) GetCustomersByPhones(businessID string, phones []string) (map[string]*Domain.Customer, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	normalized := make(map[string]string, len(phones))
+	lookup := make([]string, 0, len(phones))
+	for _, phone := range phones {
+		if n := normalizePhone(phone, business.Country); n != "" {
+			normalized[phone] = n
+			lookup = append(lookup, n)
+		}
+	}
+
+	customers, err := uc.customerRepo.FindByPhones(businessID, lookup)
+	if err != nil {
+		return nil, err
+	}
+	byPhone := make(map[string]*Domain.Customer, len(customers))
+	for i := range customers {
+		byPhone[customers[i].Phone] = &customers[i]
+	}
+
+	result := make(map[string]*Domain.Customer, len(phones))
+	for phone, n := range normalized {
+		if customer, ok := byPhone[n]; ok {
+			result[phone] = customer
+		}
+	}
+	return result, nil
+}
+
+func (uc *customerUseCase) GetCustomers(businessID string, filters Domain.CustomerFilters, includeTotal bool) (*Domain.CustomerListResponse, error) {
+	customers, err := uc.customerRepo.FindByBusinessID(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+
+	if customers == nil {
+		customers = []Domain.Customer{}
+	}
+
+	response := &Domain.CustomerListResponse{
+		Customers: customers,
+		Limit:     filters.Limit,
+		Offset:    filters.Offset,
+	}
+
+	if includeTotal {
+		total, err := uc.customerRepo.Count(businessID, filters)
+		if err != nil {
+			return nil, err
+		}
+		response.Total = &total
+	}
+
+	return response, nil
+}
+
+func (uc *customerUseCase) CountCustomers(businessID string, filters Domain.CustomerFilters) (int64, error) {
+	return uc.customerRepo.Count(businessID, filters)
+}
+
+func (uc *customerUseCase) UpdateCustomer(ctx context.Context, id, businessID string, req Domain.UpdateCustomerRequest) (*Domain.Customer, error) {
+	customer, err := uc.GetCustomerByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer.Status != Domain.CustomerStatusActive {
+		return nil, fmt.Errorf("cannot update customer with status: %s", customer.Status)
+	}
+	if req.Version != nil && *req.Version != customer.Version {
+		return nil, Domain.StaleVersionError("customer")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("customer name is required")
+		}
+		customer.Name = name
+	}
+
+	if req.Phone != nil {
+		business, err := uc.businessRepo.FindByID(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find business: %w", err)
+		}
+
+		var country string
+		if business != nil {
+			country = business.Country
+		}
+
+		phone := normalizePhone(*req.Phone, country)
+		if phone != "" && phone != customer.Phone {
+			existing, err := uc.customerRepo.FindByPhone(businessID, phone)
+			if err != nil {
+				return nil, fmt.Errorf("failed to check customer phone: %w", err)
+			}
+			if existing != nil && existing.ID != customer.ID {
+				return nil, fmt.Errorf("customer with phone %s already exists", phone)
+			}
+		}
+		customer.Phone = phone
+	}
+
+	if req.Email != nil {
+		customer.Email = strings.ToLower(strings.TrimSpace(*req.Email))
+	}
+	if req.Address != nil {
+		customer.Address = *req.Address
+	}
+	if req.Notes != nil {
+		customer.Notes = *req.Notes
+	}
+	if req.Tags != nil {
+		customer.Tags = req.Tags
+	}
+	if req.Tier != nil {
+		if !req.Tier.IsValid() {
+			return nil, fmt.Errorf("invalid customer tier: %s", *req.Tier)
+		}
+		customer.Tier = *req.Tier
+	}
+	if req.MarketingOptIn != nil && *req.MarketingOptIn != customer.MarketingOptIn {
+		now := time.Now()
+		customer.MarketingOptIn = *req.MarketingOptIn
+		customer.OptInUpdatedAt = &now
+		customer.OptInSource = "staff"
+	}
+	if req.CustomFields != nil {
+		customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntityCustomer, req.CustomFields, customer.CustomFields, false)
+		if err != nil {
+			return nil, err
+		}
+		customer.CustomFields = customFields
+	}
+
+	if err := uc.customerRepo.Update(customer); err != nil {
+		return nil, fmt.Errorf("failed to update customer: %w", err)
+	}
+
+	return customer, nil
+}
+
+func (uc *customerUseCase) DeleteCustomer(ctx context.Context, id, businessID string) error {
+	if _, err := uc.GetCustomerByID(ctx, id, businessID); err != nil {
+		return err
+	}
+
+	return uc.customerRepo.Delete(id)
+}
+
+func (uc *customerUseCase) GetCustomerSales(ctx context.Context, id, businessID string, limit, offset int) ([]Domain.Sale, error) {
+	customer, err := uc.GetCustomerByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if customer.Phone == "" {
+		return []Domain.Sale{}, nil
+	}
+
+	return uc.salesRepo.FindByBusinessID(businessID, Domain.SaleFilters{
+		CustomerPhone: &customer.Phone,
+		Limit:         limit,
+		Offset:        offset,
+	})
+}
+
+func (uc *customerUseCase) FindDuplicates(businessID string) ([]Domain.DuplicateCustomerGroup, error) {
+	customers, err := uc.customerRepo.FindByBusinessID(businessID, Domain.CustomerFilters{})
+	if err != nil {
+		return nil, err
+	}
+
+	groups := []Domain.DuplicateCustomerGroup{}
+	grouped := make(map[int]bool)
+
+	// Same phone first - those are near-certain duplicates
+	byPhone := make(map[string][]int)
+	for i, c := range customers {
+		if c.Phone != "" {
+			byPhone[c.Phone] = append(byPhone[c.Phone], i)
+		}
+	}
+	for _, idx := range byPhone {
+		if len(idx) < 2 {
+			continue
+		}
+		group := Domain.DuplicateCustomerGroup{Reason: "same_phone"}
+		for _, i := range idx {
+			group.Customers = append(group.Customers, customers[i])
+			grouped[i] = true
+		}
+		groups = append(groups, group)
+	}
+
+	// Then similar names among customers not already grouped
+	for i := range customers {
+		if grouped[i] {
+			continue
+		}
+		group := Domain.DuplicateCustomerGroup{Reason: "similar_name"}
+		for j := i + 1; j < len(customers); j++ {
+			if grouped[j] {
+				continue
+			}
+			if similarNames(customers[i].Name, customers[j].Name) {
+				if len(group.Customers) == 0 {
+					group.Customers = append(group.Customers, customers[i])
+					grouped[i] = true
+				}
+				group.Customers = append(group.Customers, customers[j])
+				grouped[j] = true
+			}
+		}
+		if len(group.Customers) > 1 {
+			groups = append(groups, group)
+		}
+	}
+
+	return groups, nil
+}
+
+func (uc *customerUseCase) MergeCustomers(ctx context.Context, targetID, businessID, userID string, req Domain.MergeCustomersRequest) (*Domain.MergeCustomersResult, error) {
+	target, err := uc.GetCustomerByID(ctx, targetID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if target.Status != Domain.CustomerStatusActive {
+		return nil, fmt.Errorf("cannot merge into customer with status: %s", target.Status)
+	}
+
+	if len(req.SourceIDs) == 0 {
+		return nil, fmt.Errorf("at least one source customer is required")
+	}
+
+	// Load and check every source before changing anything
+	sources := make([]*Domain.Customer, 0, len(req.SourceIDs))
+	for _, sourceID := range req.SourceIDs {
+		if sourceID == targetID {
+			return nil, fmt.Errorf("cannot merge a customer into itself")
+		}
+		source, err := uc.GetCustomerByID(ctx, sourceID, businessID)
+		if err != nil {
+			return nil, err
+		}
+		if source.Status != Domain.CustomerStatusActive {
+			return nil, fmt.Errorf("customer %s is %s and cannot be merged", source.Name, source.Status)
+		}
+		sources = append(sources, source)
+	}
+
+	result := &Domain.MergeCustomersResult{Customer: target}
+
+	for _, source := range sources {
+		// Target inherits any details it doesn't have yet
+		if target.Phone == "" {
+			target.Phone = source.Phone
+		}
+		if target.Email == "" {
+			target.Email = source.Email
+		}
+		if target.Address == "" {
+			target.Address = source.Address
+		}
+		target.Tags = mergeTags(target.Tags, source.Tags)
+
+		if source.Phone != "" && source.Phone != target.Phone {
+			sales, err := uc.salesRepo.ReassignCustomer(businessID, source.Phone, target.Phone, target.Name)
+			if err != nil {
+				return nil, err
+			}
+			result.SalesReassigned += sales
+
+			credits, err := uc.giftCardRepo.ReassignCustomer(businessID, source.Phone, target.Phone, target.Name)
+			if err != nil {
+				return nil, err
+			}
+			result.CreditsReassigned += credits
+
+			points, err := uc.transferLoyaltyPoints(businessID, userID, source, target)
+			if err != nil {
+				return nil, err
+			}
+			result.PointsTransferred += points
+		}
+
+		if err := uc.customerRepo.MarkMerged(source.ID.Hex(), target.ID); err != nil {
+			return nil, err
+		}
+		result.MergedCount++
+	}
+
+	if err := uc.customerRepo.Update(target); err != nil {
+		return nil, fmt.Errorf("failed to update merged customer: %w", err)
+	}
+
+	return result, nil
+}
+
+// transferLoyaltyPoints moves a source customer's point balance onto the target
+func (uc *customerUseCase) transferLoyaltyPoints(businessID, userID string, source, target *Domain.Customer) (float64, error) {
+	account, err := uc.loyaltyRepo.FindAccountByPhone(businessID, source.Phone)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find loyalty account: %w", err)
+	}
+	if account == nil || account.Points <= 0 {
+		return 0, nil
+	}
+
+	program, err := loadLoyaltyProgram(uc.loyaltyRepo, businessID)
+	if err != nil {
+		return 0, err
+	}
+	value := Domain.NewMoney(account.Points * program.PointValue)
+	note := fmt.Sprintf("Customer merge: %s into %s", source.Phone, target.Phone)
+
+	if _, err := uc.loyaltyRepo.DeductPoints(account.ID.Hex(), account.Points, value,
+		Domain.LoyaltyTransactionAdjust, nil, userID, note); err != nil {
+		return 0, fmt.Errorf("failed to transfer loyalty points: %w", err)
+	}
+	if _, err := uc.loyaltyRepo.AddPoints(businessID, target.Phone, target.Name, account.Points, value,
+		Domain.LoyaltyTransactionAdjust, nil, userID, note); err != nil {
+		return 0, fmt.Errorf("failed to transfer loyalty points: %w", err)
+	}
+
+	return account.Points, nil
+}
+
+// Country calling codes for the markets we serve; anything else falls back to Ethiopia
+var countryCallingCodes = map[string]string{
+	"et":       "251",
+	"ethiopia": "251",
+	"ke":       "254",
+	"kenya":    "254",
+	"ug":       "256",
+	"uganda":   "256",
+	"tz":       "255",
+	"tanzania": "255",
+	"rw":       "250",
+	"rwanda":   "250",
+}
+
+// normalizePhone converts local and international formats to +<country><number>
+// so the same customer always matches regardless of how the number was typed
+func normalizePhone(phone, country string) string {
+	var digits strings.Builder
+	for i, r := range strings.TrimSpace(phone) {
+		if unicode.IsDigit(r) {
+			digits.WriteRune(r)
+		} else if r == '+' && i == 0 {
+			digits.WriteRune(r)
+		}
+	}
+
+	number := digits.String()
+	if number == "" || number == "+" {
+		return ""
+	}
+
+	code, ok := countryCallingCodes[strings.ToLower(strings.TrimSpace(country))]
+	if !ok {
+		code = "251"
+	}
+
+	switch {
+	case strings.HasPrefix(number, "+"):
+		return number
+	case strings.HasPrefix(number, "00"):
+		return "+" + number[2:]
+	case strings.HasPrefix(number, code) && len(number) > 10:
+		return "+" + number
+	case strings.HasPrefix(number, "0"):
+		return "+" + code + number[1:]
+	default:
+		return "+" + code + number
+	}
+}
+
+// similarNames treats names as equal when their words match in any order or
+// differ by at most a couple of typos
+func similarNames(a, b string) bool {
+	na, nb := nameKey(a), nameKey(b)
+	if na == "" || nb == "" {
+		return false
+	}
+	if na == nb {
+		return true
+	}
+
+	maxDistance := 1
+	if len(na) > 8 {
+		maxDistance = 2
+	}
+	return levenshtein(na, nb) <= maxDistance
+}
+
+func nameKey(name string) string {
+	words := strings.Fields(strings.ToLower(name))
+	sort.Strings(words)
+	return strings.Join(words, " ")
+}
+
+func levenshtein(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+
+	return prev[len(rb)]
+}
+
+func mergeTags(a, b []string) []string {
+	seen := make(map[string]bool, len(a))
+	out := append([]string{}, a...)
+	for _, t := range a {
+		seen[t] = true
+	}
+	for _, t := range b {
+		if !seen[t] {
+			out = append(out, t)
+			seen[t] = true
+		}
+	}
+	return out
+}
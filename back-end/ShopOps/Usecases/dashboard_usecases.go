@@ -0,0 +1,171 @@
+package Usecases
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+type DashboardUseCase interface {
+	// GetDashboard returns every home screen widget, from the cache unless refresh is set
+	GetDashboard(businessID string, refresh bool) (*Domain.DashboardWidgets, error)
+	// Invalidate drops the cached dashboard so the next read sees new data
+	Invalidate(businessID string)
+}
+
+type dashboardUseCase struct {
+	businessRepo  Domain.BusinessRepository
+	inventoryRepo Domain.ProductRepository
+	reportUC      ReportUseCase
+	analyticsUC   AnalyticsUseCase
+	cache         *Infrastructure.TTLCache
+}
+
+// Dashboards are polled by every open app; a short TTL absorbs the polling
+// while keeping figures close to live
+const dashboardCacheTTL = 30 * time.Second
+
+const (
+	dashboardTopProducts   = 5
+	dashboardLowStockItems = 5
+)
+
+func NewDashboardUseCase(
+	businessRepo Domain.BusinessRepository,
+	inventoryRepo Domain.ProductRepository,
+	reportUC ReportUseCase,
+	analyticsUC AnalyticsUseCase,
+) DashboardUseCase {
+	return &dashboardUseCase{
+		businessRepo:  businessRepo,
+		inventoryRepo: inventoryRepo,
+		reportUC:      reportUC,
+		analyticsUC:   analyticsUC,
+		cache:         Infrastructure.NewTTLCache(dashboardCacheTTL),
+	}
+}
+
+func (uc *dashboardUseCase) GetDashboard(businessID string, refresh bool) (*Domain.DashboardWidgets, error) {
+	if !refresh {
+		if cached, _, ok := uc.cache.Get(businessID); ok {
+			widgets := cached.(Domain.DashboardWidgets)
+			widgets.Cached = true
+			return &widgets, nil
+		}
+	}
+
+	widgets, err := uc.buildDashboard(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	uc.cache.Set(businessID, *widgets)
+	return widgets, nil
+}
+
+func (uc *dashboardUseCase) Invalidate(businessID string) {
+	uc.cache.Delete(businessID)
+}
+
+func (uc *dashboardUseCase) buildDashboard(businessID string) (*Domain.DashboardWidgets, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	// Brings the summary tables up to date for the widgets below
+	overview, err := uc.reportUC.GetDashboardData(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	clock := clockFor(business)
+	today := clock.Day(now)
+	todayLabel := today.Format("2006-01-02")
+	start := clock.Start(today)
+
+	widgets := &Domain.DashboardWidgets{
+		GeneratedAt: now,
+		Currency:    business.Currency,
+		Today: Domain.DashboardToday{
+			Date:     todayLabel,
+			Sales:    overview.TodaySales,
+			Expenses: overview.TodayExpenses,
+			Profit:   overview.TodayProfit,
+		},
+		CashPosition: Domain.DashboardCashPosition{
+			Expenses:        overview.TodayExpenses,
+			ByPaymentMethod: []Domain.SalesBreakdownLine{},
+		},
+		LowStock:    Domain.DashboardLowStock{Items: []Domain.LowStockItem{}},
+		TopProducts: []Domain.MarginLine{},
+		Overview:    *overview,
+	}
+
+	// The remaining widgets are best effort; one failing leaves it empty rather than
+	// failing the whole screen
+	summary, err := uc.analyticsUC.GetSalesSummary(businessID, start, start.AddDate(0, 0, 1))
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to load dashboard sales summary", "error", err)
+	} else if summary != nil {
+		widgets.Today.Transactions = summary.TransactionCount
+		if summary.TransactionCount > 0 {
+			widgets.Today.AverageSale = Domain.NewMoney(summary.TotalAmount.Float64() / float64(summary.TransactionCount))
+		}
+	}
+
+	breakdown, err := uc.analyticsUC.GetSalesBreakdown(businessID, todayLabel, todayLabel, Domain.SalesSummaryByPaymentMethod)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to load dashboard payment methods", "error", err)
+	} else {
+		cash := &widgets.CashPosition
+		for _, line := range breakdown.Lines {
+			if line.Key == string(Domain.PaymentMethodCash) {
+				cash.CashSales += line.Amount
+			} else {
+				cash.OtherSales += line.Amount
+			}
+			cash.CashRounding += line.CashRounding
+			cash.ByPaymentMethod = append(cash.ByPaymentMethod, line)
+		}
+	}
+	widgets.CashPosition.NetCash = widgets.CashPosition.CashSales + widgets.CashPosition.CashRounding - widgets.CashPosition.Expenses
+
+	lowStock, err := uc.inventoryRepo.GetLowStock(businessID, 0)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to load dashboard low stock", "error", err)
+	} else {
+		widgets.LowStock.Count = len(lowStock)
+		for _, product := range lowStock {
+			widgets.LowStock.Items = append(widgets.LowStock.Items, Domain.LowStockItem{
+				ProductID:   product.ID.Hex(),
+				ProductName: product.Name,
+				Current:     product.Stock,
+				Minimum:     product.MinStock,
+				Difference:  product.MinStock - product.Stock,
+			})
+		}
+		sort.SliceStable(widgets.LowStock.Items, func(i, j int) bool {
+			return widgets.LowStock.Items[i].Difference > widgets.LowStock.Items[j].Difference
+		})
+		if len(widgets.LowStock.Items) > dashboardLowStockItems {
+			widgets.LowStock.Items = widgets.LowStock.Items[:dashboardLowStockItems]
+		}
+	}
+
+	topSellers, err := uc.analyticsUC.GetTopSellers(businessID, todayLabel, todayLabel, Domain.TopSellerRankRevenue, Domain.MarginFilter{Limit: dashboardTopProducts})
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to load dashboard top products", "error", err)
+	} else {
+		widgets.TopProducts = topSellers.Lines
+	}
+
+	return widgets, nil
+}
@@ -0,0 +1,273 @@
+package Usecases
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// demoItem is a kind of product an Ethiopian corner shop or mini-market stocks,
+// with its shelf price range in birr and how well it sells against the others
+type demoItem struct {
+	name       string
+	category   string
+	unit       string
+	minPrice   float64
+	maxPrice   float64
+	popularity float64
+	weighed    bool
+}
+
+var demoItems = []demoItem{
+	{"Bottled Water 1L", "Beverages", "pcs", 20, 30, 10, false},
+	{"Coca-Cola 500ml", "Beverages", "pcs", 30, 45, 8, false},
+	{"Ambo Mineral Water", "Beverages", "pcs", 25, 40, 6, false},
+	{"Mango Juice 1L", "Beverages", "pcs", 90, 140, 3, false},
+	{"Buna Coffee Beans", "Coffee & Tea", "kg", 450, 700, 4, true},
+	{"Black Tea Leaves 250g", "Coffee & Tea", "pack", 80, 130, 3, false},
+	{"Teff Flour", "Grains & Flour", "kg", 130, 190, 7, true},
+	{"Wheat Flour 5kg", "Grains & Flour", "pack", 450, 650, 4, false},
+	{"Rice 1kg", "Grains & Flour", "pack", 110, 170, 5, false},
+	{"Pasta 500g", "Grains & Flour", "pack", 55, 85, 6, false},
+	{"Lentils (Misir)", "Grains & Flour", "kg", 140, 210, 4, true},
+	{"Sugar", "Grains & Flour", "kg", 110, 150, 7, true},
+	{"Cooking Oil 1L", "Cooking Oil", "pcs", 180, 260, 6, false},
+	{"Cooking Oil 5L", "Cooking Oil", "pcs", 850, 1200, 2, false},
+	{"Berbere", "Spices", "kg", 350, 550, 3, true},
+	{"Salt 1kg", "Spices", "pack", 25, 40, 4, false},
+	{"Fresh Milk 1L", "Dairy & Eggs", "pcs", 65, 90, 7, false},
+	{"Eggs", "Dairy & Eggs", "pcs", 12, 18, 6, false},
+	{"Butter (Kibe)", "Dairy & Eggs", "kg", 800, 1100, 1, true},
+	{"Yogurt 500ml", "Dairy & Eggs", "pcs", 55, 80, 3, false},
+	{"Bread Loaf", "Bakery", "pcs", 40, 70, 9, false},
+	{"Biscuits", "Snacks", "pack", 20, 45, 6, false},
+	{"Chocolate Bar", "Snacks", "pcs", 35, 80, 3, false},
+	{"Peanuts", "Snacks", "pack", 25, 50, 3, false},
+	{"Laundry Soap Bar", "Household", "pcs", 25, 45, 5, false},
+	{"Detergent Powder 1kg", "Household", "pack", 120, 190, 3, false},
+	{"Toilet Paper 4 Rolls", "Household", "pack", 90, 140, 4, false},
+	{"Candles", "Household", "pack", 40, 70, 2, false},
+	{"Matches", "Household", "pack", 10, 20, 3, false},
+	{"Toothpaste", "Personal Care", "pcs", 60, 110, 3, false},
+	{"Body Lotion", "Personal Care", "pcs", 150, 280, 2, false},
+	{"Shampoo", "Personal Care", "pcs", 120, 220, 2, false},
+	{"Exercise Book", "Stationery", "pcs", 25, 45, 3, false},
+	{"Ballpoint Pen", "Stationery", "pcs", 10, 20, 3, false},
+	{"Airtime Card 100", "Airtime", "pcs", 100, 100, 5, false},
+	{"Phone Charger", "Electronics", "pcs", 250, 450, 1, false},
+}
+
+var (
+	demoVariants      = []string{"", "Family Size", "Small", "Premium", "Value Pack", "Imported", "Local", "Large"}
+	demoFirstNames    = []string{"Abebe", "Almaz", "Biniam", "Chaltu", "Dawit", "Eleni", "Fikru", "Genet", "Hana", "Kebede", "Lulit", "Meron", "Mulugeta", "Selam", "Tesfaye", "Tigist", "Yonas", "Zewdu"}
+	demoLastNames     = []string{"Alemu", "Bekele", "Desta", "Girma", "Haile", "Kassa", "Mekonnen", "Negash", "Tadesse", "Tekle", "Wolde", "Yohannes"}
+	demoCustomerTags  = []string{"regular", "neighbour", "office", "credit"}
+	demoWeekdayWeight = [7]float64{1.3, 0.85, 0.9, 0.95, 1.0, 1.15, 1.4} // Sunday first; weekends are busiest
+)
+
+// demoHourWeight is how much of a day's trade falls in each local hour: a morning
+// rush for bread and milk, a lunch peak, and the biggest peak after work
+var demoHourWeight = [24]float64{
+	0, 0, 0, 0, 0, 0, 0.3, 1.6, 2.2, 1.4, 1.0, 1.2,
+	2.0, 1.8, 1.1, 0.9, 1.1, 1.9, 2.6, 2.4, 1.5, 0.6, 0.1, 0,
+}
+
+// demoGenerator makes a demo shop's records, the same for the same seed
+type demoGenerator struct {
+	rand       *rand.Rand
+	businessID primitive.ObjectID
+	userID     primitive.ObjectID
+	loc        *time.Location
+}
+
+func newDemoGenerator(seed int64, business *Domain.Business) *demoGenerator {
+	return &demoGenerator{
+		rand:       rand.New(rand.NewSource(seed)),
+		businessID: business.ID,
+		userID:     business.UserID,
+		loc:        businessLocation(business),
+	}
+}
+
+// products makes n catalog products, the kinds repeated in variants past the first
+// few dozen, and how often each sells relative to the rest
+func (g *demoGenerator) products(n int, now time.Time) ([]Domain.Product, []float64) {
+	products := make([]Domain.Product, n)
+	popularity := make([]float64, n)
+	for i := range products {
+		item := demoItems[i%len(demoItems)]
+		name := item.name
+		if round := i / len(demoItems); round > 0 {
+			name = fmt.Sprintf("%s %s", item.name, demoVariants[round%len(demoVariants)])
+			if round >= len(demoVariants) {
+				name = fmt.Sprintf("%s #%d", name, round/len(demoVariants)+1)
+			}
+		}
+
+		price := item.minPrice + g.rand.Float64()*(item.maxPrice-item.minPrice)
+		selling := Domain.NewMoney(math.Round(price*4) / 4)
+		cost := Domain.NewMoney(selling.Float64() * (0.65 + g.rand.Float64()*0.2))
+		stock := float64(10 + g.rand.Intn(150))
+		if item.weighed {
+			stock = math.Round((5+g.rand.Float64()*45)*10) / 10
+		}
+
+		products[i] = Domain.Product{
+			BusinessID:   g.businessID,
+			Name:         name,
+			SKU:          fmt.Sprintf("DEMO-%05d", i+1),
+			Barcode:      fmt.Sprintf("2%011d", g.rand.Int63n(1e11)),
+			Category:     item.category,
+			Unit:         item.unit,
+			CostPrice:    cost,
+			SellingPrice: selling,
+			Stock:        stock,
+			MinStock:     math.Ceil(stock / 5),
+			Status:       Domain.ProductStatusActive,
+			Weighed:      item.weighed,
+			CreatedBy:    g.userID,
+			CreatedAt:    now,
+			UpdatedAt:    now,
+			Version:      1,
+		}
+		if item.weighed {
+			products[i].PLU = fmt.Sprintf("%04d", i+1)
+		}
+		// Variants sell less than the original and a few items carry the shop
+		popularity[i] = item.popularity / float64(1+i/len(demoItems)) * (0.5 + g.rand.Float64())
+	}
+	return products, popularity
+}
+
+// customers makes n regulars with phone numbers in the +251 00 range, which no
+// operator issues, so a demo never texts a real person
+func (g *demoGenerator) customers(n int, now time.Time) []Domain.Customer {
+	customers := make([]Domain.Customer, n)
+	for i := range customers {
+		customers[i] = Domain.Customer{
+			BusinessID:     g.businessID,
+			Name:           demoFirstNames[g.rand.Intn(len(demoFirstNames))] + " " + demoLastNames[g.rand.Intn(len(demoLastNames))],
+			Phone:          fmt.Sprintf("+25100%07d", g.rand.Intn(1e7)),
+			Tags:           []string{demoCustomerTags[g.rand.Intn(len(demoCustomerTags))]},
+			Status:         Domain.CustomerStatusActive,
+			MarketingOptIn: g.rand.Intn(3) == 0,
+			CreatedBy:      g.userID,
+			CreatedAt:      now,
+			UpdatedAt:      now,
+			Version:        1,
+		}
+		if g.rand.Intn(8) == 0 {
+			customers[i].Tier = Domain.CustomerTierWholesale
+		}
+	}
+	return customers
+}
+
+// sales makes the sales of the days days up to now: about perDay on an average
+// weekday, busier at weekends, and spread through each day as a shop's trade is
+func (g *demoGenerator) sales(products []Domain.Product, popularity []float64, customers []Domain.Customer, days, perDay int, now time.Time) []Domain.Sale {
+	pickProduct := weightedPicker(g.rand, popularity)
+	pickHour := weightedPicker(g.rand, demoHourWeight[:])
+	today := now.In(g.loc)
+
+	var sales []Domain.Sale
+	for back := days - 1; back >= 0; back-- {
+		day := time.Date(today.Year(), today.Month(), today.Day()-back, 0, 0, 0, 0, g.loc)
+		count := int(float64(perDay) * demoWeekdayWeight[day.Weekday()] * (0.85 + g.rand.Float64()*0.3))
+
+		for i := 0; i < count; i++ {
+			at := day.Add(time.Duration(pickHour())*time.Hour + time.Duration(g.rand.Intn(3600))*time.Second)
+			if at.After(now) {
+				continue
+			}
+			sales = append(sales, g.sale(products[pickProduct()], customers, at))
+		}
+	}
+
+	sort.Slice(sales, func(i, j int) bool { return sales[i].CreatedAt.Before(sales[j].CreatedAt) })
+	return sales
+}
+
+func (g *demoGenerator) sale(product Domain.Product, customers []Domain.Customer, at time.Time) Domain.Sale {
+	quantity := float64(1)
+	switch {
+	case product.Weighed:
+		quantity = math.Round((0.25+g.rand.Float64()*2.75)*100) / 100
+	case g.rand.Intn(4) == 0:
+		quantity = float64(2 + g.rand.Intn(4))
+	}
+
+	total := product.SellingPrice.Times(quantity)
+	final := total
+	var discount Domain.Money
+	if g.rand.Intn(20) == 0 {
+		discount = Domain.NewMoney(math.Floor(total.Float64() * 0.05))
+		final = total - discount
+	}
+
+	productID := product.ID
+	sale := Domain.Sale{
+		BusinessID:    g.businessID,
+		ProductID:     &productID,
+		Quantity:      quantity,
+		UnitPrice:     product.SellingPrice,
+		UnitCost:      product.CostPrice,
+		TotalAmount:   total,
+		Discount:      discount,
+		FinalAmount:   final,
+		PaymentMethod: Domain.PaymentMethodCash,
+		PaymentStatus: Domain.PaymentStatusPaid,
+		Status:        Domain.SaleStatusCompleted,
+		Synced:        true,
+		CreatedBy:     g.userID,
+		CreatedAt:     at.UTC(),
+		UpdatedAt:     at.UTC(),
+	}
+
+	switch roll := g.rand.Intn(100); {
+	case roll < 25:
+		sale.PaymentMethod = Domain.PaymentMethodMobile
+	case roll < 33:
+		sale.PaymentMethod = Domain.PaymentMethodCard
+	case roll < 36 && len(customers) > 0:
+		sale.PaymentMethod = Domain.PaymentMethodCredit
+		sale.PaymentStatus = Domain.PaymentStatusPending
+	}
+
+	// Credit is always on a customer's account; a share of the rest is too
+	if len(customers) > 0 && (sale.PaymentMethod == Domain.PaymentMethodCredit || g.rand.Intn(5) == 0) {
+		customer := customers[g.rand.Intn(len(customers))]
+		sale.CustomerName = customer.Name
+		sale.CustomerPhone = customer.Phone
+	}
+
+	if g.rand.Intn(100) == 0 {
+		voidedAt := sale.CreatedAt.Add(time.Duration(1+g.rand.Intn(10)) * time.Minute)
+		sale.Status = Domain.SaleStatusVoided
+		sale.VoidedBy = &g.userID
+		sale.VoidedAt = &voidedAt
+	}
+	return sale
+}
+
+// weightedPicker returns a function choosing an index with chance proportional to
+// its weight
+func weightedPicker(r *rand.Rand, weights []float64) func() int {
+	cumulative := make([]float64, len(weights))
+	total := 0.0
+	for i, w := range weights {
+		total += w
+		cumulative[i] = total
+	}
+	return func() int {
+		// Never zero, so a weightless first index is never chosen
+		i := sort.SearchFloat64s(cumulative, (1-r.Float64())*total)
+		return min(i, len(weights)-1)
+	}
+}
@@ -0,0 +1,236 @@
+package Usecases
+
+import (
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	mathrand "math/rand"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+// DemoUseCase creates demo shops, each with its own throwaway account, and deletes
+// them once they expire
+type DemoUseCase interface {
+	// TryDemo creates a demo shop of the default size for the app's "try demo" mode
+	TryDemo() (*Domain.DemoSession, error)
+	// CreateDemoShop creates a demo shop sized by the request, for sales demos and
+	// load tests
+	CreateDemoShop(req Domain.CreateDemoRequest) (*Domain.DemoSession, error)
+	// PurgeExpired deletes demo shops past their expiry, for the periodic run
+	PurgeExpired() error
+}
+
+const (
+	defaultDemoProducts    = 120
+	defaultDemoCustomers   = 60
+	defaultDemoDays        = 90
+	defaultDemoSalesPerDay = 80
+
+	maxDemoSales      = 200000 // Days times sales per day, so one request cannot tie up the database
+	demoPurgeBatch    = 50
+	demoPasswordChars = "abcdefghjkmnpqrstuvwxyzABCDEFGHJKLMNPQRSTUVWXYZ23456789"
+)
+
+type demoUseCase struct {
+	demoRepo     Domain.DemoRepository
+	businessRepo Domain.BusinessRepository
+	userUC       UserUseCase
+	analyticsUC  AnalyticsUseCase
+	cfg          Infrastructure.DemoConfig
+}
+
+func NewDemoUseCase(
+	demoRepo Domain.DemoRepository,
+	businessRepo Domain.BusinessRepository,
+	userUC UserUseCase,
+	analyticsUC AnalyticsUseCase,
+	cfg Infrastructure.DemoConfig,
+) DemoUseCase {
+	return &demoUseCase{
+		demoRepo:     demoRepo,
+		businessRepo: businessRepo,
+		userUC:       userUC,
+		analyticsUC:  analyticsUC,
+		cfg:          cfg,
+	}
+}
+
+func (uc *demoUseCase) TryDemo() (*Domain.DemoSession, error) {
+	if !uc.cfg.Enabled {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, "the demo is not available")
+	}
+
+	live, err := uc.demoRepo.CountLive(time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if live >= int64(uc.cfg.MaxShops) {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, "too many people are trying the demo right now; try again later")
+	}
+
+	return uc.create(Domain.CreateDemoRequest{})
+}
+
+func (uc *demoUseCase) CreateDemoShop(req Domain.CreateDemoRequest) (*Domain.DemoSession, error) {
+	return uc.create(req)
+}
+
+func (uc *demoUseCase) create(req Domain.CreateDemoRequest) (*Domain.DemoSession, error) {
+	req = withDemoDefaults(req)
+	if req.Days*req.SalesPerDay > maxDemoSales {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument,
+			fmt.Sprintf("days times sales_per_day cannot be more than %d", maxDemoSales))
+	}
+	ttl := uc.cfg.TTL
+	if req.KeepDays > 0 {
+		ttl = time.Duration(req.KeepDays) * 24 * time.Hour
+	}
+
+	user, password, err := uc.createAccount()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(ttl)
+	business := &Domain.Business{
+		UserID:        user.ID,
+		Name:          "Demo Mini Market",
+		Description:   "A demo shop with generated products, customers and sales",
+		BusinessType:  "mini_market",
+		Currency:      "ETB",
+		Timezone:      Domain.DefaultTimezone,
+		City:          "Addis Ababa",
+		Country:       "Ethiopia",
+		DemoExpiresAt: &expiresAt,
+	}
+	if err := uc.businessRepo.Create(business); err != nil {
+		uc.userUC.DeleteUser(user.ID.Hex())
+		return nil, fmt.Errorf("failed to create demo shop: %w", err)
+	}
+
+	if err := uc.fill(business, req, now); err != nil {
+		uc.demoRepo.DeleteShop(*business)
+		return nil, err
+	}
+
+	login, err := uc.userUC.Login(Domain.LoginRequest{Phone: user.Phone, Password: password})
+	if err != nil {
+		return nil, fmt.Errorf("failed to sign in to demo account: %w", err)
+	}
+	return &Domain.DemoSession{
+		Token:     login.Token,
+		Phone:     user.Phone,
+		Password:  password,
+		User:      login.User,
+		Business:  *business,
+		ExpiresAt: expiresAt,
+	}, nil
+}
+
+func withDemoDefaults(req Domain.CreateDemoRequest) Domain.CreateDemoRequest {
+	if req.Products == 0 {
+		req.Products = defaultDemoProducts
+	}
+	if req.Customers == 0 {
+		req.Customers = defaultDemoCustomers
+	}
+	if req.Days == 0 {
+		req.Days = defaultDemoDays
+	}
+	if req.SalesPerDay == 0 {
+		req.SalesPerDay = defaultDemoSalesPerDay
+	}
+	if req.Seed == 0 {
+		req.Seed = time.Now().UnixNano()
+	}
+	return req
+}
+
+// createAccount registers a demo account under a phone number in the +251 00 range,
+// which no operator issues, trying another on the rare collision
+func (uc *demoUseCase) createAccount() (*Domain.User, string, error) {
+	password, err := demoPassword()
+	if err != nil {
+		return nil, "", err
+	}
+
+	for attempt := 0; ; attempt++ {
+		phone := fmt.Sprintf("+25100%07d", mathrand.Intn(1e7))
+		user, err := uc.userUC.Register(Domain.RegisterRequest{Name: "Demo User", Phone: phone, Password: password})
+		if err == nil {
+			return user, password, nil
+		}
+		if attempt == 4 {
+			return nil, "", fmt.Errorf("failed to create demo account: %w", err)
+		}
+	}
+}
+
+func demoPassword() (string, error) {
+	password := make([]byte, 12)
+	for i := range password {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(demoPasswordChars))))
+		if err != nil {
+			return "", fmt.Errorf("failed to generate demo password: %w", err)
+		}
+		password[i] = demoPasswordChars[n.Int64()]
+	}
+	return string(password), nil
+}
+
+// fill generates and writes the shop's records, then queues its days for the
+// dashboards and reports
+func (uc *demoUseCase) fill(business *Domain.Business, req Domain.CreateDemoRequest, now time.Time) error {
+	gen := newDemoGenerator(req.Seed, business)
+	// The catalog and customers were there before the first sale
+	opened := now.AddDate(0, 0, -req.Days)
+
+	products, popularity := gen.products(req.Products, opened)
+	if err := uc.demoRepo.InsertProducts(products); err != nil {
+		return err
+	}
+	customers := gen.customers(req.Customers, opened)
+	if err := uc.demoRepo.InsertCustomers(customers); err != nil {
+		return err
+	}
+	sales := gen.sales(products, popularity, customers, req.Days, req.SalesPerDay, now)
+	if err := uc.demoRepo.InsertSales(sales); err != nil {
+		return err
+	}
+
+	days := make([]time.Time, 0, req.Days)
+	for back := 0; back < req.Days; back++ {
+		days = append(days, now.AddDate(0, 0, -back))
+	}
+	uc.analyticsUC.MarkDirty(business.ID.Hex(), days...)
+	return nil
+}
+
+func (uc *demoUseCase) PurgeExpired() error {
+	var firstErr error
+	for {
+		expired, err := uc.demoRepo.FindExpired(time.Now(), demoPurgeBatch)
+		if err != nil {
+			return err
+		}
+
+		deleted := 0
+		for _, business := range expired {
+			if err := uc.demoRepo.DeleteShop(business); err != nil {
+				if firstErr == nil {
+					firstErr = err
+				}
+				continue
+			}
+			deleted++
+		}
+		// A short page is the last; a page that all failed would only fail again
+		if len(expired) < demoPurgeBatch || deleted == 0 {
+			return firstErr
+		}
+	}
+}
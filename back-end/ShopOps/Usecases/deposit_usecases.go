@@ -0,0 +1,444 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type DepositUseCase interface {
+	CreateItem(businessID string, req Domain.CreateDepositItemRequest) (*Domain.DepositItem, error)
+	GetItems(businessID string) ([]Domain.DepositItem, error)
+	UpdateItem(id, businessID string, req Domain.UpdateDepositItemRequest) (*Domain.DepositItem, error)
+	// SetProductDeposits sets the containers each unit of the product goes out in,
+	// charged as deposits on its sales from then on
+	SetProductDeposits(ctx context.Context, productID, businessID string, req Domain.SetProductDepositsRequest) (*Domain.Product, error)
+	// ReturnDeposits records empties brought back outside a sale and what was paid out for them
+	ReturnDeposits(businessID, userID string, req Domain.ReturnDepositsRequest) (*Domain.DepositReturn, error)
+	GetEntries(businessID string, filters Domain.DepositEntryFilters) ([]Domain.DepositEntry, error)
+	// GetOutstanding lists each customer's containers still out and the deposit
+	// owed back on them, largest first; only the customer's when phone is given
+	GetOutstanding(businessID, phone string) ([]Domain.DepositBalance, error)
+	// OnSaleChanged brings a sale's deposit entries in line with the sale, for the
+	// event bus; seeing the same change again records nothing
+	OnSaleChanged(ctx context.Context, event *Domain.DomainEvent) error
+}
+
+type depositUseCase struct {
+	depositRepo   Domain.DepositRepository
+	inventoryRepo Domain.ProductRepository
+	salesRepo     Domain.SaleRepository
+	businessRepo  Domain.BusinessRepository
+}
+
+func NewDepositUseCase(
+	depositRepo Domain.DepositRepository,
+	inventoryRepo Domain.ProductRepository,
+	salesRepo Domain.SaleRepository,
+	businessRepo Domain.BusinessRepository,
+) DepositUseCase {
+	return &depositUseCase{
+		depositRepo:   depositRepo,
+		inventoryRepo: inventoryRepo,
+		salesRepo:     salesRepo,
+		businessRepo:  businessRepo,
+	}
+}
+
+func (uc *depositUseCase) CreateItem(businessID string, req Domain.CreateDepositItemRequest) (*Domain.DepositItem, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	item := &Domain.DepositItem{
+		BusinessID: objBusinessID,
+		Name:       strings.TrimSpace(req.Name),
+		Amount:     req.Amount,
+	}
+	if item.Name == "" {
+		return nil, Domain.ValidationError("name is required")
+	}
+	if err := uc.depositRepo.CreateItem(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (uc *depositUseCase) GetItems(businessID string) ([]Domain.DepositItem, error) {
+	items, err := uc.depositRepo.FindItems(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if items == nil {
+		items = []Domain.DepositItem{}
+	}
+	return items, nil
+}
+
+func (uc *depositUseCase) UpdateItem(id, businessID string, req Domain.UpdateDepositItemRequest) (*Domain.DepositItem, error) {
+	item, err := uc.findItem(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		item.Name = strings.TrimSpace(*req.Name)
+		if item.Name == "" {
+			return nil, Domain.ValidationError("name is required")
+		}
+	}
+	if req.Amount != nil {
+		item.Amount = *req.Amount
+	}
+	if req.Active != nil {
+		item.Active = *req.Active
+	}
+
+	if err := uc.depositRepo.UpdateItem(item); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+func (uc *depositUseCase) SetProductDeposits(ctx context.Context, productID, businessID string, req Domain.SetProductDepositsRequest) (*Domain.Product, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+
+	deposits := make([]Domain.ProductDeposit, 0, len(req.Deposits))
+	seen := map[primitive.ObjectID]bool{}
+	for _, link := range req.Deposits {
+		item, err := uc.findItem(link.ItemID, businessID)
+		if err != nil {
+			return nil, err
+		}
+		if seen[item.ID] {
+			return nil, Domain.ValidationError(fmt.Sprintf("%s is listed twice", item.Name))
+		}
+		seen[item.ID] = true
+		deposits = append(deposits, Domain.ProductDeposit{ItemID: item.ID, Quantity: link.Quantity})
+	}
+
+	if err := uc.inventoryRepo.SetDeposits(productID, deposits); err != nil {
+		return nil, err
+	}
+
+	product.Deposits = deposits
+	if len(deposits) == 0 {
+		product.Deposits = nil
+	}
+	product.Version++
+	return product, nil
+}
+
+func (uc *depositUseCase) ReturnDeposits(businessID, userID string, req Domain.ReturnDepositsRequest) (*Domain.DepositReturn, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	method := req.RefundMethod
+	if method == "" {
+		method = Domain.PaymentMethodCash
+	}
+	switch method {
+	case Domain.PaymentMethodCash, Domain.PaymentMethodMobile, Domain.PaymentMethodBank, Domain.PaymentMethodOther:
+	default:
+		return nil, Domain.ValidationError(fmt.Sprintf("deposits cannot be refunded by %s", method))
+	}
+
+	lines, refund, err := saleDeposits(uc.depositRepo, businessID, nil, 0, req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := make([]Domain.DepositEntry, len(lines))
+	for i, line := range lines {
+		entries[i] = Domain.DepositEntry{
+			BusinessID:    business.ID,
+			ItemID:        line.ItemID,
+			Type:          Domain.DepositReturned,
+			Quantity:      line.Quantity,
+			Amount:        line.Total,
+			CustomerPhone: normalizePhone(req.CustomerPhone, business.Country),
+			CustomerName:  strings.TrimSpace(req.CustomerName),
+			RefundMethod:  method,
+			Notes:         req.Notes,
+			CreatedBy:     &objUserID,
+		}
+	}
+	if err := uc.depositRepo.CreateEntries(entries); err != nil {
+		return nil, err
+	}
+
+	return &Domain.DepositReturn{Entries: entries, Refund: -refund}, nil
+}
+
+func (uc *depositUseCase) GetEntries(businessID string, filters Domain.DepositEntryFilters) ([]Domain.DepositEntry, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	if filters.CustomerPhone != nil {
+		business, err := uc.businessRepo.FindByID(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find business: %w", err)
+		}
+		if business != nil {
+			phone := normalizePhone(*filters.CustomerPhone, business.Country)
+			filters.CustomerPhone = &phone
+		}
+	}
+
+	entries, err := uc.depositRepo.FindEntries(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []Domain.DepositEntry{}
+	}
+	return entries, nil
+}
+
+func (uc *depositUseCase) GetOutstanding(businessID, phone string) ([]Domain.DepositBalance, error) {
+	var only *string
+	if phone != "" {
+		business, err := uc.businessRepo.FindByID(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find business: %w", err)
+		}
+		if business == nil {
+			return nil, Domain.NotFoundError("business not found")
+		}
+		phone = normalizePhone(phone, business.Country)
+		only = &phone
+	}
+
+	holdings, err := uc.depositRepo.Holdings(businessID, only)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := uc.depositRepo.FindItems(businessID)
+	if err != nil {
+		return nil, err
+	}
+	names := make(map[primitive.ObjectID]string, len(items))
+	for _, item := range items {
+		names[item.ID] = item.Name
+	}
+
+	// Holdings come sorted by phone, so each customer's are together
+	balances := []Domain.DepositBalance{}
+	for _, holding := range holdings {
+		if n := len(balances); n == 0 || balances[n-1].CustomerPhone != holding.CustomerPhone {
+			balances = append(balances, Domain.DepositBalance{CustomerPhone: holding.CustomerPhone, Items: []Domain.DepositBalanceItem{}})
+		}
+		balance := &balances[len(balances)-1]
+		if holding.CustomerName != "" {
+			balance.CustomerName = holding.CustomerName
+		}
+		balance.Items = append(balance.Items, Domain.DepositBalanceItem{
+			ItemID:   holding.ItemID.Hex(),
+			Name:     names[holding.ItemID],
+			Quantity: holding.Quantity,
+			Amount:   holding.Amount,
+		})
+		balance.Amount += holding.Amount
+	}
+
+	sort.SliceStable(balances, func(i, j int) bool {
+		return balances[i].Amount > balances[j].Amount
+	})
+	return balances, nil
+}
+
+func (uc *depositUseCase) OnSaleChanged(ctx context.Context, event *Domain.DomainEvent) error {
+	// The sale as it is now, not as the event saw it, so changes arriving out of
+	// order still settle on the right deposits
+	sale, err := uc.salesRepo.FindInBusiness(ctx, event.AggregateID)
+	if err != nil {
+		return err
+	}
+	if sale == nil {
+		return nil
+	}
+
+	recorded, err := uc.depositRepo.FindEntriesBySale(sale.ID)
+	if err != nil {
+		return err
+	}
+	if event.Type == Domain.EventSaleRecorded && len(recorded) > 0 {
+		return nil
+	}
+
+	type key struct {
+		phone  string
+		itemID primitive.ObjectID
+		kind   Domain.DepositEntryType
+	}
+	type held struct {
+		quantity float64
+		amount   Domain.Money
+		name     string
+	}
+
+	balance := make(map[key]held)
+	var order []key
+	for _, entry := range recorded {
+		k := key{entry.CustomerPhone, entry.ItemID, entry.Type}
+		if _, ok := balance[k]; !ok {
+			order = append(order, k)
+		}
+		h := balance[k]
+		h.quantity += entry.Quantity
+		h.amount += entry.Amount
+		h.name = entry.CustomerName
+		balance[k] = h
+	}
+
+	target := make(map[key]held)
+	if sale.Status == Domain.SaleStatusCompleted {
+		for _, line := range sale.Deposits {
+			kind := Domain.DepositCharged
+			if line.Quantity < 0 {
+				kind = Domain.DepositReturned
+			}
+			k := key{sale.CustomerPhone, line.ItemID, kind}
+			if _, ok := balance[k]; !ok {
+				balance[k] = held{}
+				order = append(order, k)
+			}
+			h := target[k]
+			h.quantity += line.Quantity
+			h.amount += line.Total
+			h.name = sale.CustomerName
+			target[k] = h
+		}
+	}
+
+	var entries []Domain.DepositEntry
+	for _, k := range order {
+		current, want := balance[k], target[k]
+		quantity, amount := want.quantity-current.quantity, want.amount-current.amount
+		if quantity == 0 && amount == 0 {
+			continue
+		}
+
+		name := want.name
+		if name == "" {
+			name = current.name
+		}
+		entry := Domain.DepositEntry{
+			BusinessID:    sale.BusinessID,
+			ItemID:        k.itemID,
+			Type:          k.kind,
+			Quantity:      quantity,
+			Amount:        amount,
+			CustomerPhone: k.phone,
+			CustomerName:  name,
+			SaleID:        &sale.ID,
+		}
+		// The first entries are dated with the sale, so they fall in the sale's period
+		if len(recorded) == 0 {
+			entry.CreatedAt = sale.CreatedAt
+		}
+		entries = append(entries, entry)
+	}
+
+	return uc.depositRepo.CreateEntries(entries)
+}
+
+func (uc *depositUseCase) findItem(id, businessID string) (*Domain.DepositItem, error) {
+	item, err := uc.depositRepo.FindItemByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if item == nil || item.BusinessID.Hex() != businessID {
+		return nil, Domain.NotFoundError("deposit item not found")
+	}
+	return item, nil
+}
+
+// saleDeposits works out the deposit lines for quantity units of the product and
+// the empties brought back with them, and what they come to. Inactive containers
+// are no longer charged but are still taken back.
+func saleDeposits(depositRepo Domain.DepositRepository, businessID string, product *Domain.Product, quantity float64, returns []Domain.DepositReturnItem) ([]Domain.SaleDeposit, Domain.Money, error) {
+	if (product == nil || len(product.Deposits) == 0) && len(returns) == 0 {
+		return nil, 0, nil
+	}
+
+	items, err := depositRepo.FindItems(businessID)
+	if err != nil {
+		return nil, 0, err
+	}
+	byID := make(map[primitive.ObjectID]*Domain.DepositItem, len(items))
+	for i := range items {
+		byID[items[i].ID] = &items[i]
+	}
+
+	var lines []Domain.SaleDeposit
+	var total Domain.Money
+	add := func(item *Domain.DepositItem, quantity float64) {
+		line := Domain.SaleDeposit{
+			ItemID:   item.ID,
+			Name:     item.Name,
+			Quantity: quantity,
+			Amount:   item.Amount,
+			Total:    item.Amount.Times(quantity),
+		}
+		lines = append(lines, line)
+		total += line.Total
+	}
+
+	if product != nil {
+		for _, link := range product.Deposits {
+			if item := byID[link.ItemID]; item != nil && item.Active {
+				add(item, link.Quantity*quantity)
+			}
+		}
+	}
+
+	for _, returned := range returns {
+		objItemID, err := primitive.ObjectIDFromHex(returned.ItemID)
+		if err != nil {
+			return nil, 0, Domain.ValidationError("invalid deposit item ID")
+		}
+		item := byID[objItemID]
+		if item == nil {
+			return nil, 0, Domain.NotFoundError("deposit item not found")
+		}
+		add(item, -returned.Quantity)
+	}
+
+	return lines, total, nil
+}
+
+// checkDepositCredit turns down a sale whose returned empties are worth more than
+// the rest of it; the balance is paid out as a deposit return instead
+func checkDepositCredit(sale *Domain.Sale) error {
+	if sale.UnitPrice.Times(sale.Quantity)-sale.Discount+sale.Tax+sale.DepositAmount < 0 {
+		return Domain.ValidationError("the empties returned are worth more than the sale; pay their deposit out as a deposit return")
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package Usecases
+
+import (
+	"fmt"
+
+	Domain "ShopOps/Domain"
+)
+
+// DomainEventUseCase is the admin view of the event outbox
+type DomainEventUseCase interface {
+	GetEvents(filters Domain.DomainEventFilters) ([]Domain.DomainEvent, error)
+	GetEvent(id string) (*Domain.DomainEvent, error)
+	// RetryEvent gives the subscribers that ran out of attempts on an event a fresh set
+	RetryEvent(id string) (*Domain.DomainEvent, error)
+	GetStats() ([]Domain.DomainEventStats, error)
+}
+
+type domainEventUseCase struct {
+	eventRepo Domain.DomainEventRepository
+}
+
+func NewDomainEventUseCase(eventRepo Domain.DomainEventRepository) DomainEventUseCase {
+	return &domainEventUseCase{eventRepo: eventRepo}
+}
+
+func (uc *domainEventUseCase) GetEvents(filters Domain.DomainEventFilters) ([]Domain.DomainEvent, error) {
+	events, err := uc.eventRepo.Find(filters)
+	if err != nil {
+		return nil, err
+	}
+	if events == nil {
+		events = []Domain.DomainEvent{}
+	}
+	return events, nil
+}
+
+func (uc *domainEventUseCase) GetEvent(id string) (*Domain.DomainEvent, error) {
+	event, err := uc.eventRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if event == nil {
+		return nil, Domain.NotFoundError("event not found")
+	}
+	return event, nil
+}
+
+func (uc *domainEventUseCase) RetryEvent(id string) (*Domain.DomainEvent, error) {
+	event, err := uc.GetEvent(id)
+	if err != nil {
+		return nil, err
+	}
+	if event.Status != Domain.DomainEventDead {
+		return nil, fmt.Errorf("event is %s; only dead events can be retried", event.Status)
+	}
+
+	retried, err := uc.eventRepo.Retry(id)
+	if err != nil {
+		return nil, err
+	}
+	if retried == nil {
+		return nil, fmt.Errorf("event is no longer dead")
+	}
+	return retried, nil
+}
+
+func (uc *domainEventUseCase) GetStats() ([]Domain.DomainEventStats, error) {
+	stats, err := uc.eventRepo.Stats()
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = []Domain.DomainEventStats{}
+	}
+	return stats, nil
+}
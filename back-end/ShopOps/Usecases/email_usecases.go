@@ -0,0 +1,481 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EmailUseCase sends shops' templated emails. Each send is logged, then delivered
+// from the job queue so a slow or failing provider never holds up the request.
+type EmailUseCase interface {
+	// Publish emails the shop when a backup has failed for good
+	EventPublisher
+
+	// Send queues a templated email to one address on behalf of the shop. The data
+	// is the template's Infrastructure.Email...Data.
+	Send(ctx context.Context, businessID, to string, template Domain.EmailTemplate, data interface{}) error
+	// NotifyShop sends to the shop's notification recipients, or its owner, leaving
+	// out users who turned email off for the template's kind of notification
+	NotifyShop(ctx context.Context, businessID string, template Domain.EmailTemplate, data interface{}) error
+	// SendInvite tells a new employee they were added to the shop, at the address
+	// they were added with or their linked account's
+	SendInvite(ctx context.Context, businessID, inviterID string, employee *Domain.Employee) error
+	// Link is the web app's address for path, or empty when it is not configured
+	Link(path string) string
+	SendMessageJob(ctx context.Context, job *Domain.Job) error
+
+	GetSettings(businessID string) (*Domain.EmailSettings, error)
+	UpdateSettings(businessID string, req Domain.UpdateEmailSettingsRequest) (*Domain.EmailSettings, error)
+	SendTest(ctx context.Context, businessID string, req Domain.SendTestEmailRequest) error
+	GetMessages(businessID string, filters Domain.EmailMessageFilters) ([]Domain.EmailMessage, error)
+
+	// HandleEvents records the bounces and complaints a provider reports, and stops
+	// mail to addresses that will not take it
+	HandleEvents(ctx context.Context, provider string, body []byte, header http.Header) error
+	// SendLowStockDigests emails each opted-in shop its low stock once a day
+	SendLowStockDigests() error
+}
+
+type emailUseCase struct {
+	emailRepo      Domain.EmailRepository
+	businessRepo   Domain.BusinessRepository
+	userRepo       Domain.UserRepository
+	inventoryRepo  Domain.ProductRepository
+	notificationUC NotificationUseCase
+	provider       Infrastructure.EmailProvider
+	renderer       Infrastructure.EmailRenderer
+	jobQueue       Infrastructure.JobQueue
+	cfg            Infrastructure.EmailConfig
+}
+
+// Products listed in the low stock digest; the rest are counted
+const emailDigestItems = 20
+
+func NewEmailUseCase(
+	emailRepo Domain.EmailRepository,
+	businessRepo Domain.BusinessRepository,
+	userRepo Domain.UserRepository,
+	inventoryRepo Domain.ProductRepository,
+	notificationUC NotificationUseCase,
+	provider Infrastructure.EmailProvider,
+	renderer Infrastructure.EmailRenderer,
+	jobQueue Infrastructure.JobQueue,
+	cfg Infrastructure.EmailConfig,
+) EmailUseCase {
+	return &emailUseCase{
+		emailRepo:      emailRepo,
+		businessRepo:   businessRepo,
+		userRepo:       userRepo,
+		inventoryRepo:  inventoryRepo,
+		notificationUC: notificationUC,
+		provider:       provider,
+		renderer:       renderer,
+		jobQueue:       jobQueue,
+		cfg:            cfg,
+	}
+}
+
+func (uc *emailUseCase) Send(ctx context.Context, businessID, to string, template Domain.EmailTemplate, data interface{}) error {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return Domain.NotFoundError("business not found")
+	}
+
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return err
+	}
+
+	subject, text, html, err := uc.renderer.Render(template, data)
+	if err != nil {
+		return err
+	}
+
+	message := &Domain.EmailMessage{
+		BusinessID: &business.ID,
+		Template:   template,
+		To:         strings.ToLower(strings.TrimSpace(to)),
+		FromName:   settings.FromName,
+		ReplyTo:    settings.ReplyTo,
+		Subject:    subject,
+		Text:       text,
+		HTML:       html,
+		Status:     Domain.EmailStatusQueued,
+	}
+	if message.FromName == "" {
+		message.FromName = business.Name
+	}
+	if message.ReplyTo == "" {
+		message.ReplyTo = business.Email
+	}
+
+	suppressed, err := uc.emailRepo.IsSuppressed(message.To)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		message.Status = Domain.EmailStatusSkipped
+		message.Error = "address bounced or reported spam before"
+		return uc.emailRepo.LogMessage(message)
+	}
+
+	if err := uc.emailRepo.LogMessage(message); err != nil {
+		return err
+	}
+
+	_, err = uc.jobQueue.Enqueue(ctx, Domain.JobTypeEmail, businessID, map[string]string{"message_id": message.ID.Hex()})
+	if err != nil {
+		message.Status = Domain.EmailStatusFailed
+		message.Error = err.Error()
+		if updateErr := uc.emailRepo.UpdateMessage(message); updateErr != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to update email message", "message_id", message.ID.Hex(), "error", updateErr)
+		}
+		return fmt.Errorf("failed to queue email: %w", err)
+	}
+	return nil
+}
+
+func (uc *emailUseCase) NotifyShop(ctx context.Context, businessID string, template Domain.EmailTemplate, data interface{}) error {
+	recipients, err := uc.recipients(businessID)
+	if err != nil {
+		return err
+	}
+
+	event, hasEvent := emailNotificationEvents[template]
+	for _, to := range recipients {
+		if hasEvent && !uc.wantsEmail(to, event) {
+			continue
+		}
+		if err := uc.Send(ctx, businessID, to, template, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// emailNotificationEvents are the notifications the shop templates send
+var emailNotificationEvents = map[Domain.EmailTemplate]Domain.NotificationEvent{
+	Domain.EmailTemplateBackupFailed:   Domain.NotificationBackupFailed,
+	Domain.EmailTemplateLowStockDigest: Domain.NotificationLowStock,
+}
+
+// wantsEmail reports whether the address takes the event by email. Addresses
+// without an account always do.
+func (uc *emailUseCase) wantsEmail(address string, event Domain.NotificationEvent) bool {
+	user, err := uc.userRepo.FindByEmail(strings.ToLower(strings.TrimSpace(address)))
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to find user for email", "email", address, "error", err)
+		return true
+	}
+	if user == nil {
+		return true
+	}
+	return uc.notificationUC.Allows(user.ID.Hex(), event, Domain.ChannelEmail)
+}
+
+// recipients are the shop's notification addresses, falling back to its owner's
+func (uc *emailUseCase) recipients(businessID string) ([]string, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(settings.Recipients) > 0 {
+		return settings.Recipients, nil
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	owner, err := uc.userRepo.FindByID(business.UserID.Hex())
+	if err != nil {
+		return nil, fmt.Errorf("failed to find owner: %w", err)
+	}
+	if owner != nil && owner.Email != "" {
+		return []string{owner.Email}, nil
+	}
+	if business.Email != "" {
+		return []string{business.Email}, nil
+	}
+	return nil, nil
+}
+
+func (uc *emailUseCase) SendInvite(ctx context.Context, businessID, inviterID string, employee *Domain.Employee) error {
+	to := employee.Email
+	if to == "" && employee.UserID != nil {
+		user, err := uc.userRepo.FindByID(employee.UserID.Hex())
+		if err != nil {
+			return fmt.Errorf("failed to find user: %w", err)
+		}
+		if user != nil {
+			to = user.Email
+		}
+	}
+	if to == "" {
+		return nil
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return Domain.NotFoundError("business not found")
+	}
+
+	data := Infrastructure.EmailInviteData{
+		ShopName: business.Name,
+		Name:     employee.Name,
+		Position: employee.Position,
+		URL:      uc.Link("/login"),
+	}
+	if inviter, err := uc.userRepo.FindByID(inviterID); err == nil && inviter != nil {
+		data.InviterName = inviter.Name
+	}
+
+	return uc.Send(ctx, businessID, to, Domain.EmailTemplateInvite, data)
+}
+
+func (uc *emailUseCase) Link(path string) string {
+	return Infrastructure.EmailLink(uc.cfg.AppBaseURL, path)
+}
+
+func (uc *emailUseCase) Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error {
+	failure, ok := data.(Domain.BackupFailedEvent)
+	if event != Domain.WebhookEventBackupFailed || !ok {
+		return nil
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil || business == nil {
+		return err
+	}
+
+	err = uc.NotifyShop(ctx, businessID, Domain.EmailTemplateBackupFailed, Infrastructure.EmailBackupFailedData{
+		ShopName: business.Name,
+		Error:    failure.Error,
+		Attempts: failure.Attempts,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to queue backup failure email: %w", err)
+	}
+	return nil
+}
+
+func (uc *emailUseCase) SendMessageJob(ctx context.Context, job *Domain.Job) error {
+	message, err := uc.emailRepo.FindMessageByID(job.Payload["message_id"])
+	if err != nil {
+		return err
+	}
+	if message == nil || message.Status != Domain.EmailStatusQueued {
+		return nil
+	}
+
+	providerMessageID, err := uc.provider.Send(ctx, Infrastructure.Email{
+		From:     uc.cfg.FromAddress,
+		FromName: message.FromName,
+		ReplyTo:  message.ReplyTo,
+		To:       message.To,
+		Subject:  message.Subject,
+		Text:     message.Text,
+		HTML:     message.HTML,
+	})
+	message.Provider = uc.provider.Name()
+	if err != nil {
+		if job.Attempts < job.MaxAttempts {
+			return err
+		}
+		message.Status = Domain.EmailStatusFailed
+		message.Error = err.Error()
+		if updateErr := uc.emailRepo.UpdateMessage(message); updateErr != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to update email message", "message_id", message.ID.Hex(), "error", updateErr)
+		}
+		return err
+	}
+
+	now := time.Now()
+	message.Status = Domain.EmailStatusSent
+	message.ProviderMessageID = providerMessageID
+	message.Error = ""
+	message.SentAt = &now
+	return uc.emailRepo.UpdateMessage(message)
+}
+
+func (uc *emailUseCase) GetSettings(businessID string) (*Domain.EmailSettings, error) {
+	settings, err := uc.emailRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	defaults := Domain.DefaultEmailSettings
+	defaults.BusinessID = objBusinessID
+	return &defaults, nil
+}
+
+func (uc *emailUseCase) UpdateSettings(businessID string, req Domain.UpdateEmailSettingsRequest) (*Domain.EmailSettings, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.FromName != nil {
+		settings.FromName = strings.TrimSpace(*req.FromName)
+	}
+	if req.ReplyTo != nil {
+		settings.ReplyTo = strings.ToLower(strings.TrimSpace(*req.ReplyTo))
+	}
+	if req.Recipients != nil {
+		settings.Recipients = make([]string, 0, len(*req.Recipients))
+		for _, recipient := range *req.Recipients {
+			settings.Recipients = append(settings.Recipients, strings.ToLower(strings.TrimSpace(recipient)))
+		}
+	}
+	if req.LowStockDigest != nil {
+		settings.LowStockDigest = *req.LowStockDigest
+	}
+	if req.DigestHour != nil {
+		settings.DigestHour = *req.DigestHour
+	}
+
+	if err := uc.emailRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (uc *emailUseCase) SendTest(ctx context.Context, businessID string, req Domain.SendTestEmailRequest) error {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return Domain.NotFoundError("business not found")
+	}
+
+	return uc.Send(ctx, businessID, req.To, Domain.EmailTemplateTest, Infrastructure.EmailTestData{ShopName: business.Name})
+}
+
+func (uc *emailUseCase) GetMessages(businessID string, filters Domain.EmailMessageFilters) ([]Domain.EmailMessage, error) {
+	messages, err := uc.emailRepo.FindMessages(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []Domain.EmailMessage{}
+	}
+	return messages, nil
+}
+
+func (uc *emailUseCase) HandleEvents(ctx context.Context, provider string, body []byte, header http.Header) error {
+	if provider != uc.provider.Name() {
+		return Domain.NotFoundError("unknown email provider")
+	}
+
+	events, err := uc.provider.ParseEvents(ctx, body, header)
+	if err != nil {
+		return err
+	}
+
+	for _, event := range events {
+		if event.ProviderMessageID != "" {
+			if err := uc.emailRepo.MarkBounced(provider, event.ProviderMessageID, event.Status, event.Reason); err != nil {
+				return err
+			}
+		}
+		if event.Permanent && event.Email != "" {
+			err := uc.emailRepo.Suppress(&Domain.EmailSuppression{Email: event.Email, Reason: event.Status, Detail: event.Reason})
+			if err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (uc *emailUseCase) SendLowStockDigests() error {
+	settings, err := uc.emailRepo.FindDigestSettings()
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	for i := range settings {
+		businessID := settings[i].BusinessID.Hex()
+
+		business, err := uc.businessRepo.FindByID(businessID)
+		if err != nil {
+			return err
+		}
+		if business == nil {
+			continue
+		}
+
+		now := time.Now().In(businessLocation(business))
+		today := now.Format("2006-01-02")
+		if now.Hour() < settings[i].DigestHour || settings[i].LastDigestDate == today {
+			continue
+		}
+
+		products, err := uc.inventoryRepo.GetLowStock(businessID, 0)
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to load low stock for business", "business_id", businessID, "error", err)
+			continue
+		}
+
+		if len(products) > 0 {
+			data := Infrastructure.EmailLowStockDigestData{
+				ShopName: business.Name,
+				URL:      uc.Link("/businesses/" + businessID + "/inventory?low_stock=true"),
+			}
+			for _, product := range products {
+				data.Items = append(data.Items, Domain.LowStockItem{
+					ProductID:   product.ID.Hex(),
+					ProductName: product.Name,
+					Current:     product.Stock,
+					Minimum:     product.MinStock,
+					Difference:  product.MinStock - product.Stock,
+				})
+			}
+			sort.SliceStable(data.Items, func(i, j int) bool {
+				return data.Items[i].Difference > data.Items[j].Difference
+			})
+			if len(data.Items) > emailDigestItems {
+				data.More = len(data.Items) - emailDigestItems
+				data.Items = data.Items[:emailDigestItems]
+			}
+
+			if err := uc.NotifyShop(ctx, businessID, Domain.EmailTemplateLowStockDigest, data); err != nil {
+				Infrastructure.Logger.Warn("failed to send low stock digest for business", "business_id", businessID, "error", err)
+				continue
+			}
+		}
+
+		if err := uc.emailRepo.SetLastDigestDate(businessID, today); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,641 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type EmployeeUseCase interface {
+	CreateEmployee(businessID, userID string, req Domain.CreateEmployeeRequest) (*Domain.Employee, error)
+	GetEmployeeByID(ctx context.Context, id, businessID string) (*Domain.Employee, error)
+	GetEmployees(businessID string, status *Domain.EmployeeStatus) ([]Domain.Employee, error)
+	UpdateEmployee(ctx context.Context, id, businessID string, req Domain.UpdateEmployeeRequest) (*Domain.Employee, error)
+
+	// Time tracking
+	ClockIn(ctx context.Context, employeeID, businessID, userID string, req Domain.ClockInRequest) (*Domain.TimeEntry, error)
+	ClockOut(ctx context.Context, employeeID, businessID string, req Domain.ClockOutRequest) (*Domain.TimeEntry, error)
+	GetTimeEntries(businessID string, filters Domain.TimeEntryFilters) ([]Domain.TimeEntry, error)
+
+	// Commission
+	CreateCommissionRule(ctx context.Context, businessID string, req Domain.CreateCommissionRuleRequest) (*Domain.CommissionRule, error)
+	GetCommissionRules(businessID string) ([]Domain.CommissionRule, error)
+	UpdateCommissionRule(ctx context.Context, id, businessID string, req Domain.UpdateCommissionRuleRequest) (*Domain.CommissionRule, error)
+	DeleteCommissionRule(ctx context.Context, id, businessID string) error
+	GetCommissionReport(ctx context.Context, businessID, month string) (*Domain.CommissionReport, error)
+}
+
+type employeeUseCase struct {
+	employeeRepo   Domain.EmployeeRepository
+	commissionRepo Domain.CommissionRuleRepository
+	businessRepo   Domain.BusinessRepository
+	salesRepo      Domain.SaleRepository
+	inventoryRepo  Domain.ProductRepository
+	emailUC        EmailUseCase
+}
+
+func NewEmployeeUseCase(
+	employeeRepo Domain.EmployeeRepository,
+	commissionRepo Domain.CommissionRuleRepository,
+	businessRepo Domain.BusinessRepository,
+	salesRepo Domain.SaleRepository,
+	inventoryRepo Domain.ProductRepository,
+	emailUC EmailUseCase,
+) EmployeeUseCase {
+	return &employeeUseCase{
+		employeeRepo:   employeeRepo,
+		commissionRepo: commissionRepo,
+		businessRepo:   businessRepo,
+		salesRepo:      salesRepo,
+		inventoryRepo:  inventoryRepo,
+		emailUC:        emailUC,
+	}
+}
+
+func (uc *employeeUseCase) CreateEmployee(businessID, userID string, req Domain.CreateEmployeeRequest) (*Domain.Employee, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	employee := &Domain.Employee{
+		BusinessID: business.ID,
+		Name:       req.Name,
+		Phone:      normalizePhone(req.Phone, business.Country),
+		Email:      strings.ToLower(strings.TrimSpace(req.Email)),
+		Position:   req.Position,
+		CreatedBy:  objUserID,
+	}
+	if employee.Permissions, err = validPermissions(req.Permissions); err != nil {
+		return nil, err
+	}
+
+	if req.UserID != nil && *req.UserID != "" {
+		linkedUserID, err := uc.validateUserLink(businessID, *req.UserID, nil)
+		if err != nil {
+			return nil, err
+		}
+		employee.UserID = linkedUserID
+	}
+
+	if err := uc.employeeRepo.Create(employee); err != nil {
+		return nil, fmt.Errorf("failed to create employee: %w", err)
+	}
+
+	// The employee is added even when the invite cannot be queued
+	if err := uc.emailUC.SendInvite(context.Background(), businessID, userID, employee); err != nil {
+		Infrastructure.Logger.Warn("failed to send invite to employee", "employee_id", employee.ID.Hex(), "error", err)
+	}
+
+	return employee, nil
+}
+
+func (uc *employeeUseCase) GetEmployeeByID(ctx context.Context, id, businessID string) (*Domain.Employee, error) {
+	employee, err := uc.employeeRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+	if employee == nil {
+		return nil, Domain.NotFoundError("employee not found")
+	}
+
+	return employee, nil
+}
+
+func (uc *employeeUseCase) GetEmployees(businessID string, status *Domain.EmployeeStatus) ([]Domain.Employee, error) {
+	return uc.employeeRepo.FindByBusinessID(businessID, status)
+}
+
+func (uc *employeeUseCase) UpdateEmployee(ctx context.Context, id, businessID string, req Domain.UpdateEmployeeRequest) (*Domain.Employee, error) {
+	employee, err := uc.GetEmployeeByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, fmt.Errorf("name cannot be empty")
+		}
+		employee.Name = *req.Name
+	}
+	if req.Phone != nil {
+		business, err := uc.businessRepo.FindByID(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find business: %w", err)
+		}
+		country := ""
+		if business != nil {
+			country = business.Country
+		}
+		employee.Phone = normalizePhone(*req.Phone, country)
+	}
+	if req.Position != nil {
+		employee.Position = *req.Position
+	}
+	if req.UserID != nil {
+		if *req.UserID == "" {
+			employee.UserID = nil
+		} else {
+			linkedUserID, err := uc.validateUserLink(businessID, *req.UserID, &employee.ID)
+			if err != nil {
+				return nil, err
+			}
+			employee.UserID = linkedUserID
+		}
+	}
+	if req.Permissions != nil {
+		if employee.Permissions, err = validPermissions(*req.Permissions); err != nil {
+			return nil, err
+		}
+	}
+	if req.Status != nil {
+		switch *req.Status {
+		case Domain.EmployeeStatusActive, Domain.EmployeeStatusInactive:
+			employee.Status = *req.Status
+		default:
+			return nil, fmt.Errorf("invalid status: %s", *req.Status)
+		}
+	}
+
+	if err := uc.employeeRepo.Update(employee); err != nil {
+		return nil, fmt.Errorf("failed to update employee: %w", err)
+	}
+
+	// Deactivated employees shouldn't stay clocked in
+	if employee.Status == Domain.EmployeeStatusInactive {
+		if _, err := uc.closeOpenEntry(employee.ID.Hex(), "Clocked out automatically: employee deactivated"); err != nil {
+			Infrastructure.Logger.Warn("failed to clock out employee", "employee_id", employee.ID.Hex(), "error", err)
+		}
+	}
+
+	return employee, nil
+}
+
+// validPermissions checks permissions are known, dropping repeats
+func validPermissions(permissions []Domain.EmployeePermission) ([]Domain.EmployeePermission, error) {
+	var valid []Domain.EmployeePermission
+	for _, permission := range permissions {
+		if !permission.IsValid() {
+			return nil, Domain.ValidationError(fmt.Sprintf("invalid permission: %s", permission))
+		}
+		if !slices.Contains(valid, permission) {
+			valid = append(valid, permission)
+		}
+	}
+	return valid, nil
+}
+
+// validateUserLink makes sure a login account is linked to at most one
+// employee per business
+func (uc *employeeUseCase) validateUserLink(businessID, userID string, employeeID *primitive.ObjectID) (*primitive.ObjectID, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	existing, err := uc.employeeRepo.FindByUserID(businessID, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user link: %w", err)
+	}
+	if existing != nil && (employeeID == nil || existing.ID != *employeeID) {
+		return nil, fmt.Errorf("user is already linked to employee %s", existing.Name)
+	}
+
+	return &objUserID, nil
+}
+
+func (uc *employeeUseCase) ClockIn(ctx context.Context, employeeID, businessID, userID string, req Domain.ClockInRequest) (*Domain.TimeEntry, error) {
+	employee, err := uc.GetEmployeeByID(ctx, employeeID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if employee.Status != Domain.EmployeeStatusActive {
+		return nil, fmt.Errorf("employee is not active")
+	}
+
+	if req.DeviceID == "" {
+		return nil, fmt.Errorf("device ID is required")
+	}
+
+	open, err := uc.employeeRepo.FindOpenTimeEntry(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check open shift: %w", err)
+	}
+	if open != nil {
+		return nil, fmt.Errorf("employee is already clocked in on device %s", open.DeviceID)
+	}
+
+	// A device has one active cashier; clocking in hands it over
+	deviceEntry, err := uc.employeeRepo.FindOpenTimeEntryByDevice(businessID, req.DeviceID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check device shift: %w", err)
+	}
+	if deviceEntry != nil {
+		if err := uc.closeEntry(deviceEntry, time.Now(), "Clocked out automatically: device handed over"); err != nil {
+			return nil, err
+		}
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	entry := &Domain.TimeEntry{
+		BusinessID: employee.BusinessID,
+		EmployeeID: employee.ID,
+		DeviceID:   req.DeviceID,
+		ClockInAt:  time.Now(),
+		Notes:      req.Notes,
+		CreatedBy:  objUserID,
+	}
+
+	if err := uc.employeeRepo.CreateTimeEntry(entry); err != nil {
+		return nil, fmt.Errorf("failed to clock in: %w", err)
+	}
+
+	return entry, nil
+}
+
+func (uc *employeeUseCase) ClockOut(ctx context.Context, employeeID, businessID string, req Domain.ClockOutRequest) (*Domain.TimeEntry, error) {
+	if _, err := uc.GetEmployeeByID(ctx, employeeID, businessID); err != nil {
+		return nil, err
+	}
+
+	entry, err := uc.closeOpenEntry(employeeID, req.Notes)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil {
+		return nil, fmt.Errorf("employee is not clocked in")
+	}
+
+	return entry, nil
+}
+
+func (uc *employeeUseCase) closeOpenEntry(employeeID, notes string) (*Domain.TimeEntry, error) {
+	entry, err := uc.employeeRepo.FindOpenTimeEntry(employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find open shift: %w", err)
+	}
+	if entry == nil {
+		return nil, nil
+	}
+
+	if err := uc.closeEntry(entry, time.Now(), notes); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (uc *employeeUseCase) closeEntry(entry *Domain.TimeEntry, at time.Time, notes string) error {
+	entry.ClockOutAt = &at
+	entry.Minutes = int(at.Sub(entry.ClockInAt).Minutes())
+	if notes != "" {
+		if entry.Notes != "" {
+			entry.Notes += "; "
+		}
+		entry.Notes += notes
+	}
+
+	if err := uc.employeeRepo.CloseTimeEntry(entry); err != nil {
+		return fmt.Errorf("failed to clock out: %w", err)
+	}
+
+	return nil
+}
+
+func (uc *employeeUseCase) GetTimeEntries(businessID string, filters Domain.TimeEntryFilters) ([]Domain.TimeEntry, error) {
+	return uc.employeeRepo.FindTimeEntries(businessID, filters)
+}
+
+func (uc *employeeUseCase) CreateCommissionRule(ctx context.Context, businessID string, req Domain.CreateCommissionRuleRequest) (*Domain.CommissionRule, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf("name is required")
+	}
+	if err := validateCommission(req.Type, req.Rate); err != nil {
+		return nil, err
+	}
+
+	rule := &Domain.CommissionRule{
+		BusinessID: business.ID,
+		Name:       req.Name,
+		Category:   req.Category,
+		Type:       req.Type,
+		Rate:       req.Rate,
+	}
+
+	if req.EmployeeID != nil && *req.EmployeeID != "" {
+		employee, err := uc.GetEmployeeByID(ctx, *req.EmployeeID, businessID)
+		if err != nil {
+			return nil, err
+		}
+		rule.EmployeeID = &employee.ID
+	}
+
+	if err := uc.commissionRepo.Create(rule); err != nil {
+		return nil, fmt.Errorf("failed to create commission rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (uc *employeeUseCase) GetCommissionRules(businessID string) ([]Domain.CommissionRule, error) {
+	return uc.commissionRepo.FindByBusinessID(businessID)
+}
+
+func (uc *employeeUseCase) getCommissionRule(ctx context.Context, id, businessID string) (*Domain.CommissionRule, error) {
+	rule, err := uc.commissionRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find commission rule: %w", err)
+	}
+	if rule == nil {
+		return nil, Domain.NotFoundError("commission rule not found")
+	}
+
+	return rule, nil
+}
+
+func (uc *employeeUseCase) UpdateCommissionRule(ctx context.Context, id, businessID string, req Domain.UpdateCommissionRuleRequest) (*Domain.CommissionRule, error) {
+	rule, err := uc.getCommissionRule(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, fmt.Errorf("name cannot be empty")
+		}
+		rule.Name = *req.Name
+	}
+	if req.Category != nil {
+		rule.Category = *req.Category
+	}
+	if req.Type != nil {
+		rule.Type = *req.Type
+	}
+	if req.Rate != nil {
+		rule.Rate = *req.Rate
+	}
+	if req.Active != nil {
+		rule.Active = *req.Active
+	}
+
+	if err := validateCommission(rule.Type, rule.Rate); err != nil {
+		return nil, err
+	}
+
+	if err := uc.commissionRepo.Update(rule); err != nil {
+		return nil, fmt.Errorf("failed to update commission rule: %w", err)
+	}
+
+	return rule, nil
+}
+
+func (uc *employeeUseCase) DeleteCommissionRule(ctx context.Context, id, businessID string) error {
+	if _, err := uc.getCommissionRule(ctx, id, businessID); err != nil {
+		return err
+	}
+
+	return uc.commissionRepo.Delete(id)
+}
+
+func validateCommission(commissionType Domain.CommissionType, rate float64) error {
+	switch commissionType {
+	case Domain.CommissionTypePercentage:
+		if rate < 0 || rate > 100 {
+			return fmt.Errorf("percentage rate must be between 0 and 100")
+		}
+	case Domain.CommissionTypeFlat:
+		if rate < 0 {
+			return fmt.Errorf("flat rate cannot be negative")
+		}
+	default:
+		return fmt.Errorf("invalid commission type: %s", commissionType)
+	}
+	return nil
+}
+
+// GetCommissionReport totals completed sales and commission per employee for
+// a calendar month (YYYY-MM, defaults to the current month) in the business's
+// timezone. Sales without an explicit employee are credited to whoever was
+// clocked in on the selling device at the time.
+func (uc *employeeUseCase) GetCommissionReport(ctx context.Context, businessID, month string) (*Domain.CommissionReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	loc := businessLocation(business)
+
+	var start time.Time
+	if month == "" {
+		now := time.Now().In(loc)
+		start = time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, loc)
+	} else {
+		start, err = time.ParseInLocation("2006-01", month, loc)
+		if err != nil {
+			return nil, fmt.Errorf("invalid month, expected YYYY-MM: %w", err)
+		}
+	}
+	end := start.AddDate(0, 1, 0).Add(-time.Nanosecond)
+
+	employees, err := uc.employeeRepo.FindByBusinessID(businessID, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get employees: %w", err)
+	}
+
+	rules, err := uc.commissionRepo.FindByBusinessID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commission rules: %w", err)
+	}
+
+	completed := Domain.SaleStatusCompleted
+	sales, err := uc.salesRepo.FindByBusinessID(businessID, Domain.SaleFilters{
+		StartDate: &start,
+		EndDate:   &end,
+		Status:    &completed,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sales: %w", err)
+	}
+
+	// Shifts that started up to a day before the month can still cover sales in it
+	shiftStart := start.AddDate(0, 0, -1)
+	entries, err := uc.employeeRepo.FindTimeEntries(businessID, Domain.TimeEntryFilters{
+		StartDate: &shiftStart,
+		EndDate:   &end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get time entries: %w", err)
+	}
+
+	byEmployee := make(map[primitive.ObjectID]*Domain.EmployeeCommission)
+	row := func(id primitive.ObjectID) *Domain.EmployeeCommission {
+		if r, ok := byEmployee[id]; ok {
+			return r
+		}
+		r := &Domain.EmployeeCommission{EmployeeID: id, EmployeeName: "Unknown employee"}
+		byEmployee[id] = r
+		return r
+	}
+
+	// Active employees are listed even with no sales
+	for _, e := range employees {
+		if e.Status == Domain.EmployeeStatusActive {
+			row(e.ID)
+		}
+	}
+
+	now := time.Now()
+	for _, entry := range entries {
+		if entry.ClockInAt.Before(start) || entry.ClockInAt.After(end) {
+			continue
+		}
+		out := now
+		if entry.ClockOutAt != nil {
+			out = *entry.ClockOutAt
+		}
+		row(entry.EmployeeID).HoursWorked += out.Sub(entry.ClockInAt).Hours()
+	}
+
+	categories := make(map[primitive.ObjectID]string)
+	report := &Domain.CommissionReport{
+		Month:     start.Format("2006-01"),
+		StartDate: start,
+		EndDate:   end,
+	}
+
+	for _, sale := range sales {
+		employeeID := sale.EmployeeID
+		if employeeID == nil && sale.DeviceID != "" {
+			employeeID = shiftEmployeeAt(entries, sale.DeviceID, sale.CreatedAt)
+		}
+		if employeeID == nil {
+			continue
+		}
+
+		category := ""
+		if sale.ProductID != nil {
+			if c, ok := categories[*sale.ProductID]; ok {
+				category = c
+			} else {
+				product, err := uc.inventoryRepo.FindInBusiness(ctx, sale.ProductID.Hex())
+				if err != nil {
+					Infrastructure.Logger.Warn("failed to find product", "product_id", sale.ProductID.Hex(), "error", err)
+				} else if product != nil {
+					category = product.Category
+				}
+				categories[*sale.ProductID] = category
+			}
+		}
+
+		r := row(*employeeID)
+		r.SalesCount++
+		r.SalesTotal += sale.FinalAmount.Float64()
+		report.TotalSales += sale.FinalAmount.Float64()
+
+		if rule := bestCommissionRule(rules, *employeeID, category); rule != nil {
+			commission := rule.Commission(sale.FinalAmount.Float64())
+			r.Commission += commission
+			report.TotalCommission += commission
+		}
+	}
+
+	for _, e := range employees {
+		if r, ok := byEmployee[e.ID]; ok {
+			r.EmployeeName = e.Name
+		}
+	}
+
+	for _, r := range byEmployee {
+		r.SalesTotal = roundCurrency(r.SalesTotal)
+		r.Commission = roundCurrency(r.Commission)
+		r.HoursWorked = math.Round(r.HoursWorked*100) / 100
+		report.Employees = append(report.Employees, *r)
+	}
+	sort.Slice(report.Employees, func(i, j int) bool {
+		if report.Employees[i].SalesTotal != report.Employees[j].SalesTotal {
+			return report.Employees[i].SalesTotal > report.Employees[j].SalesTotal
+		}
+		return report.Employees[i].EmployeeName < report.Employees[j].EmployeeName
+	})
+
+	report.TotalSales = roundCurrency(report.TotalSales)
+	report.TotalCommission = roundCurrency(report.TotalCommission)
+
+	return report, nil
+}
+
+// shiftEmployeeAt finds who was clocked in on deviceID at time at
+func shiftEmployeeAt(entries []Domain.TimeEntry, deviceID string, at time.Time) *primitive.ObjectID {
+	for i := range entries {
+		entry := &entries[i]
+		if entry.DeviceID != deviceID || at.Before(entry.ClockInAt) {
+			continue
+		}
+		if entry.ClockOutAt == nil || !at.After(*entry.ClockOutAt) {
+			return &entry.EmployeeID
+		}
+	}
+	return nil
+}
+
+func bestCommissionRule(rules []Domain.CommissionRule, employeeID primitive.ObjectID, category string) *Domain.CommissionRule {
+	var best *Domain.CommissionRule
+	for i := range rules {
+		if !rules[i].Matches(employeeID, category) {
+			continue
+		}
+		if best == nil || rules[i].Specificity() > best.Specificity() {
+			best = &rules[i]
+		}
+	}
+	return best
+}
+
+// businessLocation returns the business's configured timezone, falling back
+// to the server's local time
+func businessLocation(business *Domain.Business) *time.Location {
+	name := Domain.DefaultTimezone
+	if business != nil && business.Timezone != "" {
+		name = business.Timezone
+	}
+	if loc, err := time.LoadLocation(name); err == nil {
+		return loc
+	}
+	return time.Local
+}
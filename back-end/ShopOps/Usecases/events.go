@@ -0,0 +1,137 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// EventPublisher tells those following a shop, such as its webhooks, about an
+// event. Publishing only queues the notifications, so it is quick; an error means
+// nothing may have been queued and the event should be published again.
+type EventPublisher interface {
+	Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error
+}
+
+// outboxPublisher records events in the outbox; the event bus relays them to the
+// webhook, telegram, email, push and in-app senders, retrying each until it has
+// queued its notifications
+type outboxPublisher struct {
+	eventRepo Domain.DomainEventRepository
+}
+
+func NewOutboxPublisher(eventRepo Domain.DomainEventRepository) EventPublisher {
+	return &outboxPublisher{eventRepo: eventRepo}
+}
+
+func (p *outboxPublisher) Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	var aggregateID string
+	switch payload := data.(type) {
+	case Domain.StockLowEvent:
+		aggregateID = payload.ProductID
+	case Domain.BackupFailedEvent:
+		aggregateID = payload.JobID
+	case Domain.SyncFailedEvent:
+		aggregateID = payload.DeviceID
+	}
+
+	domainEvent, err := Domain.NewDomainEvent(objBusinessID, Domain.DomainEventType(event), aggregateID, data)
+	if err != nil {
+		return err
+	}
+	return p.eventRepo.Append(domainEvent)
+}
+
+// RelayedEventTypes are the domain events the senders relay, each under the
+// webhook event of the same meaning
+var RelayedEventTypes = []Domain.DomainEventType{
+	Domain.EventSaleRecorded,
+	Domain.EventStockLow,
+	Domain.EventBackupCompleted,
+	Domain.EventBackupFailed,
+	Domain.EventSyncFailed,
+}
+
+type eventIDKey struct{}
+
+// eventID is the ID of the domain event being relayed, if any
+func eventID(ctx context.Context) string {
+	id, _ := ctx.Value(eventIDKey{}).(string)
+	return id
+}
+
+// RelayEvents hands the relayed domain events to publisher as webhook events,
+// with the data each webhook event carries
+func RelayEvents(publisher EventPublisher) Infrastructure.EventHandler {
+	return func(ctx context.Context, event *Domain.DomainEvent) error {
+		var webhookEvent Domain.WebhookEvent
+		var data interface{}
+		switch event.Type {
+		case Domain.EventSaleRecorded:
+			var sale Domain.Sale
+			if err := event.Decode(&sale); err != nil {
+				return err
+			}
+			webhookEvent, data = Domain.WebhookEventSaleCreated, &sale
+		case Domain.EventStockLow:
+			var stockLow Domain.StockLowEvent
+			if err := event.Decode(&stockLow); err != nil {
+				return err
+			}
+			webhookEvent, data = Domain.WebhookEventStockLow, stockLow
+		case Domain.EventBackupCompleted:
+			var backup Domain.ShopBackup
+			if err := event.Decode(&backup); err != nil {
+				return err
+			}
+			webhookEvent, data = Domain.WebhookEventBackupCompleted, &backup
+		case Domain.EventBackupFailed:
+			var failure Domain.BackupFailedEvent
+			if err := event.Decode(&failure); err != nil {
+				return err
+			}
+			webhookEvent, data = Domain.WebhookEventBackupFailed, failure
+		case Domain.EventSyncFailed:
+			var failure Domain.SyncFailedEvent
+			if err := event.Decode(&failure); err != nil {
+				return err
+			}
+			webhookEvent, data = Domain.WebhookEventSyncFailed, failure
+		default:
+			return fmt.Errorf("%s events are not relayed", event.Type)
+		}
+
+		ctx = context.WithValue(ctx, eventIDKey{}, event.ID.Hex())
+		return publisher.Publish(ctx, event.BusinessID.Hex(), webhookEvent, data)
+	}
+}
+
+// SecurityAlerter tells a user about activity on their account, such as a new
+// sign-in, on every device they use
+type SecurityAlerter interface {
+	AlertUser(ctx context.Context, userID string, alert Domain.SecurityAlert)
+}
+
+// SecurityAlerters alerts the user through every alerter in turn
+type SecurityAlerters []SecurityAlerter
+
+func (alerters SecurityAlerters) AlertUser(ctx context.Context, userID string, alert Domain.SecurityAlert) {
+	for _, alerter := range alerters {
+		alerter.AlertUser(ctx, userID, alert)
+	}
+}
+
+// MaintenanceAnnouncer tells every device that read-only maintenance has started,
+// changed or ended
+type MaintenanceAnnouncer interface {
+	AnnounceMaintenance(ctx context.Context, state Domain.MaintenanceState) error
+}
@@ -1,35 +1,70 @@
 package Usecases
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"path"
+	"path/filepath"
+	"strings"
 	"time"
 
 	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
 )
 
 type ExpenseUseCase interface {
 	CreateExpense(businessID, userID string, req Domain.CreateExpenseRequest) (*Domain.Expense, error)
-	GetExpenseByID(id, businessID string) (*Domain.Expense, error)
+	GetExpenseByID(ctx context.Context, id, businessID string) (*Domain.Expense, error)
 	GetExpenses(businessID string, filters Domain.ExpenseFilters) ([]Domain.Expense, error)
-	UpdateExpense(id, businessID, userID string, req Domain.CreateExpenseRequest) (*Domain.Expense, error)
-	VoidExpense(id, businessID, userID string) error
+	UpdateExpense(ctx context.Context, id, businessID, userID string, req Domain.CreateExpenseRequest) (*Domain.Expense, error)
+	VoidExpense(ctx context.Context, id, businessID, userID string) error
 	GetExpenseSummary(businessID string, period string) ([]Domain.ExpenseSummary, error)
-	GetExpenseTotal(businessID string, startDate, endDate time.Time) (float64, error)
+	GetExpenseTotal(businessID string, startDate, endDate time.Time) (Domain.Money, error)
 	GetExpenseCategories() []Domain.ExpenseCategory
+	AttachReceipt(ctx context.Context, id, businessID, filename, contentType string, data io.Reader) (*Domain.Expense, error)
+	// OpenReceipt reads back the receipt uploaded for the expense, with its file name
+	OpenReceipt(ctx context.Context, id, businessID string) (io.ReadCloser, string, error)
+
+	// Recurring expenses
+	CreateRecurringExpense(businessID, userID string, req Domain.CreateRecurringExpenseRequest) (*Domain.RecurringExpense, error)
+	GetRecurringExpenses(businessID string) ([]Domain.RecurringExpense, error)
+	UpdateRecurringExpense(ctx context.Context, id, businessID string, req Domain.UpdateRecurringExpenseRequest) (*Domain.RecurringExpense, error)
+	DeleteRecurringExpense(ctx context.Context, id, businessID string) error
+	GenerateDueRecurringExpenses() error
 }
 
 type expenseUseCase struct {
-	expenseRepo  Domain.ExpenseRepository
-	businessRepo Domain.BusinessRepository
+	expenseRepo   Domain.ExpenseRepository
+	recurringRepo Domain.RecurringExpenseRepository
+	businessRepo  Domain.BusinessRepository
+	fileStorage   Infrastructure.FileStorage
+	analyticsUC   AnalyticsUseCase
+}
+
+// Receipt photos larger than this are rejected
+const MaxReceiptSize = 5 << 20
+
+var allowedReceiptTypes = map[string]string{
+	"image/jpeg":      ".jpg",
+	"image/png":       ".png",
+	"image/webp":      ".webp",
+	"application/pdf": ".pdf",
 }
 
 func NewExpenseUseCase(
 	expenseRepo Domain.ExpenseRepository,
+	recurringRepo Domain.RecurringExpenseRepository,
 	businessRepo Domain.BusinessRepository,
+	fileStorage Infrastructure.FileStorage,
+	analyticsUC AnalyticsUseCase,
 ) ExpenseUseCase {
 	return &expenseUseCase{
-		expenseRepo:  expenseRepo,
-		businessRepo: businessRepo,
+		expenseRepo:   expenseRepo,
+		recurringRepo: recurringRepo,
+		businessRepo:  businessRepo,
+		fileStorage:   fileStorage,
+		analyticsUC:   analyticsUC,
 	}
 }
 
@@ -40,7 +75,7 @@ func (uc *expenseUseCase) CreateExpense(businessID, userID string, req Domain.Cr
 		return nil, fmt.Errorf("failed to find business: %w", err)
 	}
 	if business == nil {
-		return nil, fmt.Errorf("business not found")
+		return nil, Domain.NotFoundError("business not found")
 	}
 
 	// Validate category
@@ -64,6 +99,7 @@ func (uc *expenseUseCase) CreateExpense(businessID, userID string, req Domain.Cr
 		Category:    req.Category,
 		Amount:      req.Amount,
 		Description: req.Description,
+		ReceiptURL:  req.ReceiptURL,
 		Date:        req.Date,
 		CreatedBy:   objUserID,
 	}
@@ -76,21 +112,18 @@ func (uc *expenseUseCase) CreateExpense(businessID, userID string, req Domain.Cr
 		return nil, fmt.Errorf("failed to create expense: %w", err)
 	}
 
+	uc.analyticsUC.MarkDirty(businessID, expense.Date)
+
 	return expense, nil
 }
 
-func (uc *expenseUseCase) GetExpenseByID(id, businessID string) (*Domain.Expense, error) {
-	expense, err := uc.expenseRepo.FindByID(id)
+func (uc *expenseUseCase) GetExpenseByID(ctx context.Context, id, businessID string) (*Domain.Expense, error) {
+	expense, err := uc.expenseRepo.FindInBusiness(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find expense: %w", err)
 	}
 	if expense == nil {
-		return nil, fmt.Errorf("expense not found")
-	}
-
-	// Verify expense belongs to business
-	if expense.BusinessID.Hex() != businessID {
-		return nil, fmt.Errorf("access denied: expense does not belong to this business")
+		return nil, Domain.NotFoundError("expense not found")
 	}
 
 	return expense, nil
@@ -100,8 +133,8 @@ func (uc *expenseUseCase) GetExpenses(businessID string, filters Domain.ExpenseF
 	return uc.expenseRepo.FindByBusinessID(businessID, filters)
 }
 
-func (uc *expenseUseCase) UpdateExpense(id, businessID, userID string, req Domain.CreateExpenseRequest) (*Domain.Expense, error) {
-	expense, err := uc.GetExpenseByID(id, businessID)
+func (uc *expenseUseCase) UpdateExpense(ctx context.Context, id, businessID, userID string, req Domain.CreateExpenseRequest) (*Domain.Expense, error) {
+	expense, err := uc.GetExpenseByID(ctx, id, businessID)
 	if err != nil {
 		return nil, err
 	}
@@ -126,6 +159,10 @@ func (uc *expenseUseCase) UpdateExpense(id, businessID, userID string, req Domai
 	if req.Description != "" {
 		expense.Description = req.Description
 	}
+	if req.ReceiptURL != "" {
+		expense.ReceiptURL = req.ReceiptURL
+	}
+	previousDate := expense.Date
 	if !req.Date.IsZero() {
 		expense.Date = req.Date
 	}
@@ -134,11 +171,13 @@ func (uc *expenseUseCase) UpdateExpense(id, businessID, userID string, req Domai
 		return nil, fmt.Errorf("failed to update expense: %w", err)
 	}
 
+	uc.analyticsUC.MarkDirty(businessID, previousDate, expense.Date)
+
 	return expense, nil
 }
 
-func (uc *expenseUseCase) VoidExpense(id, businessID, userID string) error {
-	expense, err := uc.GetExpenseByID(id, businessID)
+func (uc *expenseUseCase) VoidExpense(ctx context.Context, id, businessID, userID string) error {
+	expense, err := uc.GetExpenseByID(ctx, id, businessID)
 	if err != nil {
 		return err
 	}
@@ -149,7 +188,12 @@ func (uc *expenseUseCase) VoidExpense(id, businessID, userID string) error {
 	}
 
 	// Update expense status
-	return uc.expenseRepo.UpdateStatus(id, Domain.ExpenseStatusVoided)
+	if err := uc.expenseRepo.UpdateStatus(id, Domain.ExpenseStatusVoided); err != nil {
+		return err
+	}
+
+	uc.analyticsUC.MarkDirty(businessID, expense.Date)
+	return nil
 }
 
 func (uc *expenseUseCase) GetExpenseSummary(businessID string, period string) ([]Domain.ExpenseSummary, error) {
@@ -174,7 +218,7 @@ func (uc *expenseUseCase) GetExpenseSummary(businessID string, period string) ([
 	return uc.expenseRepo.GetSummaryByCategory(businessID, startDate, endDate)
 }
 
-func (uc *expenseUseCase) GetExpenseTotal(businessID string, startDate, endDate time.Time) (float64, error) {
+func (uc *expenseUseCase) GetExpenseTotal(businessID string, startDate, endDate time.Time) (Domain.Money, error) {
 	return uc.expenseRepo.GetTotal(businessID, startDate, endDate)
 }
 
@@ -200,3 +244,294 @@ func (uc *expenseUseCase) isValidCategory(category Domain.ExpenseCategory) bool
 	}
 	return false
 }
+
+func (uc *expenseUseCase) AttachReceipt(ctx context.Context, id, businessID, filename, contentType string, data io.Reader) (*Domain.Expense, error) {
+	expense, err := uc.GetExpenseByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if expense.Status != Domain.ExpenseStatusActive {
+		return nil, fmt.Errorf("cannot attach receipt to expense with status: %s", expense.Status)
+	}
+
+	ext, ok := allowedReceiptTypes[strings.ToLower(contentType)]
+	if !ok {
+		return nil, fmt.Errorf("unsupported receipt file type: %s", contentType)
+	}
+	if e := strings.ToLower(filepath.Ext(filename)); e == ".jpeg" || e == ext {
+		ext = e
+	}
+
+	// Timestamp keeps re-uploads from being served stale by caches
+	name := fmt.Sprintf("%s-%d%s", expense.ID.Hex(), time.Now().Unix(), ext)
+	url, err := uc.fileStorage.Save(filepath.Join("receipts", businessID), name, data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to store receipt: %w", err)
+	}
+
+	previous := expense.ReceiptURL
+	expense.ReceiptURL = url
+	if err := uc.expenseRepo.Update(expense); err != nil {
+		return nil, fmt.Errorf("failed to update expense: %w", err)
+	}
+
+	if previous != "" {
+		if err := uc.fileStorage.Delete(previous); err != nil {
+			Infrastructure.Logger.Warn("failed to delete old receipt", "file", previous, "error", err)
+		}
+	}
+
+	return expense, nil
+}
+
+func (uc *expenseUseCase) OpenReceipt(ctx context.Context, id, businessID string) (io.ReadCloser, string, error) {
+	expense, err := uc.GetExpenseByID(ctx, id, businessID)
+	if err != nil {
+		return nil, "", err
+	}
+	if expense.ReceiptURL == "" {
+		return nil, "", Domain.NotFoundError("expense has no receipt")
+	}
+
+	// A receipt_url the client set itself points elsewhere and is not ours to serve
+	file, err := uc.fileStorage.Open(expense.ReceiptURL)
+	if err != nil {
+		return nil, "", Domain.NotFoundError("receipt file not found")
+	}
+	return file, path.Base(expense.ReceiptURL), nil
+}
+
+func (uc *expenseUseCase) CreateRecurringExpense(businessID, userID string, req Domain.CreateRecurringExpenseRequest) (*Domain.RecurringExpense, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if !uc.isValidCategory(req.Category) {
+		return nil, fmt.Errorf("invalid expense category: %s", req.Category)
+	}
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than zero")
+	}
+	if !isValidFrequency(req.Frequency) {
+		return nil, fmt.Errorf("invalid frequency: %s", req.Frequency)
+	}
+
+	startDate := req.StartDate
+	if startDate.IsZero() {
+		now := time.Now()
+		startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	}
+	if req.EndDate != nil && req.EndDate.Before(startDate) {
+		return nil, fmt.Errorf("end date cannot be before start date")
+	}
+
+	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	recurring := &Domain.RecurringExpense{
+		BusinessID:  objBusinessID,
+		Category:    req.Category,
+		Amount:      req.Amount,
+		Description: req.Description,
+		Frequency:   req.Frequency,
+		StartDate:   startDate,
+		EndDate:     req.EndDate,
+		NextRunAt:   startDate,
+		CreatedBy:   objUserID,
+	}
+
+	if err := uc.recurringRepo.Create(recurring); err != nil {
+		return nil, fmt.Errorf("failed to create recurring expense: %w", err)
+	}
+
+	// Backfill anything already due (e.g. rent that started on the 1st)
+	if err := uc.generateOccurrences(recurring, time.Now()); err != nil {
+		Infrastructure.Logger.Warn("failed to generate recurring expense", "expense_id", recurring.ID.Hex(), "error", err)
+	}
+
+	return recurring, nil
+}
+
+func (uc *expenseUseCase) GetRecurringExpenses(businessID string) ([]Domain.RecurringExpense, error) {
+	return uc.recurringRepo.FindByBusinessID(businessID)
+}
+
+func (uc *expenseUseCase) getRecurringExpense(ctx context.Context, id, businessID string) (*Domain.RecurringExpense, error) {
+	recurring, err := uc.recurringRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find recurring expense: %w", err)
+	}
+	if recurring == nil {
+		return nil, Domain.NotFoundError("recurring expense not found")
+	}
+
+	return recurring, nil
+}
+
+func (uc *expenseUseCase) UpdateRecurringExpense(ctx context.Context, id, businessID string, req Domain.UpdateRecurringExpenseRequest) (*Domain.RecurringExpense, error) {
+	recurring, err := uc.getRecurringExpense(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if recurring.Status == Domain.RecurringExpenseStatusEnded {
+		return nil, fmt.Errorf("recurring expense has ended")
+	}
+
+	if req.Category != nil {
+		if !uc.isValidCategory(*req.Category) {
+			return nil, fmt.Errorf("invalid expense category: %s", *req.Category)
+		}
+		recurring.Category = *req.Category
+	}
+	if req.Amount != nil {
+		if *req.Amount <= 0 {
+			return nil, fmt.Errorf("amount must be greater than zero")
+		}
+		recurring.Amount = *req.Amount
+	}
+	if req.Description != nil {
+		recurring.Description = *req.Description
+	}
+	if req.EndDate != nil {
+		if req.EndDate.Before(recurring.StartDate) {
+			return nil, fmt.Errorf("end date cannot be before start date")
+		}
+		recurring.EndDate = req.EndDate
+	}
+
+	resumed := false
+	if req.Status != nil {
+		switch *req.Status {
+		case Domain.RecurringExpenseStatusActive:
+			resumed = recurring.Status == Domain.RecurringExpenseStatusPaused
+		case Domain.RecurringExpenseStatusPaused:
+		default:
+			return nil, fmt.Errorf("invalid status: %s", *req.Status)
+		}
+		recurring.Status = *req.Status
+	}
+
+	// Periods skipped while paused are not charged retroactively
+	if resumed {
+		now := time.Now()
+		for !recurring.NextRunAt.After(now) {
+			recurring.Occurrences++
+			recurring.NextRunAt = recurring.Frequency.Occurrence(recurring.StartDate, recurring.Occurrences)
+		}
+	}
+
+	if recurring.EndDate != nil && recurring.NextRunAt.After(*recurring.EndDate) {
+		recurring.Status = Domain.RecurringExpenseStatusEnded
+	}
+
+	if err := uc.recurringRepo.Update(recurring); err != nil {
+		return nil, fmt.Errorf("failed to update recurring expense: %w", err)
+	}
+
+	return recurring, nil
+}
+
+func (uc *expenseUseCase) DeleteRecurringExpense(ctx context.Context, id, businessID string) error {
+	if _, err := uc.getRecurringExpense(ctx, id, businessID); err != nil {
+		return err
+	}
+
+	// Expenses already generated stay in the books
+	return uc.recurringRepo.Delete(id)
+}
+
+// GenerateDueRecurringExpenses creates expenses for every recurring expense
+// that has come due. Runs periodically in the background.
+func (uc *expenseUseCase) GenerateDueRecurringExpenses() error {
+	now := time.Now()
+
+	due, err := uc.recurringRepo.FindDue(now, 500)
+	if err != nil {
+		return err
+	}
+
+	var failed int
+	for i := range due {
+		if err := uc.generateOccurrences(&due[i], now); err != nil {
+			Infrastructure.Logger.Warn("failed to generate recurring expense", "expense_id", due[i].ID.Hex(), "error", err)
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d recurring expenses failed", failed, len(due))
+	}
+
+	return nil
+}
+
+// generateOccurrences creates an expense for each period of recurring that is
+// due by asOf. Each occurrence has a deterministic local ID so a retry after a
+// partial failure doesn't create duplicates.
+func (uc *expenseUseCase) generateOccurrences(recurring *Domain.RecurringExpense, asOf time.Time) error {
+	if recurring.Status != Domain.RecurringExpenseStatusActive {
+		return nil
+	}
+
+	businessID := recurring.BusinessID.Hex()
+	recurringID := recurring.ID
+
+	for !recurring.NextRunAt.After(asOf) {
+		if recurring.EndDate != nil && recurring.NextRunAt.After(*recurring.EndDate) {
+			break
+		}
+
+		localID := fmt.Sprintf("recurring:%s:%d", recurring.ID.Hex(), recurring.Occurrences)
+		existing, err := uc.expenseRepo.FindByLocalID(businessID, localID)
+		if err != nil {
+			return err
+		}
+
+		if existing == nil {
+			expense := &Domain.Expense{
+				BusinessID:  recurring.BusinessID,
+				LocalID:     localID,
+				Category:    recurring.Category,
+				Amount:      recurring.Amount,
+				Description: recurring.Description,
+				RecurringID: &recurringID,
+				Date:        recurring.NextRunAt,
+				CreatedBy:   recurring.CreatedBy,
+			}
+			if err := uc.expenseRepo.Create(expense); err != nil {
+				return err
+			}
+			uc.analyticsUC.MarkDirty(businessID, expense.Date)
+		}
+
+		recurring.Occurrences++
+		recurring.NextRunAt = recurring.Frequency.Occurrence(recurring.StartDate, recurring.Occurrences)
+	}
+
+	if recurring.EndDate != nil && recurring.NextRunAt.After(*recurring.EndDate) {
+		recurring.Status = Domain.RecurringExpenseStatusEnded
+	}
+
+	return uc.recurringRepo.Update(recurring)
+}
+
+func isValidFrequency(frequency Domain.RecurrenceFrequency) bool {
+	switch frequency {
+	case Domain.RecurrenceWeekly, Domain.RecurrenceMonthly, Domain.RecurrenceYearly:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,203 @@
+package Usecases
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// FeatureFlagUseCase manages feature flags and evaluates them for a shop
+type FeatureFlagUseCase interface {
+	GetFlags() ([]Domain.FeatureFlag, error)
+	GetFlag(key string) (*Domain.FeatureFlag, error)
+	CreateFlag(userID string, req Domain.CreateFeatureFlagRequest) (*Domain.FeatureFlag, error)
+	UpdateFlag(key, userID string, req Domain.UpdateFeatureFlagRequest) (*Domain.FeatureFlag, error)
+	DeleteFlag(key string) error
+	// EnabledFlags lists the keys of the flags on for the business
+	EnabledFlags(businessID string) []string
+	IsEnabled(key, businessID string) bool
+}
+
+type featureFlagUseCase struct {
+	flagRepo Domain.FeatureFlagRepository
+	cache    *Infrastructure.TTLCache
+}
+
+// Flags are read on every request, so they are cached; a change made on another
+// instance takes effect here within this long
+const featureFlagCacheTTL = 30 * time.Second
+
+const featureFlagCacheKey = "flags"
+
+var featureFlagKeyFormat = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+
+func NewFeatureFlagUseCase(flagRepo Domain.FeatureFlagRepository) FeatureFlagUseCase {
+	return &featureFlagUseCase{
+		flagRepo: flagRepo,
+		cache:    Infrastructure.NewTTLCache(featureFlagCacheTTL),
+	}
+}
+
+func (uc *featureFlagUseCase) GetFlags() ([]Domain.FeatureFlag, error) {
+	flags, err := uc.flagRepo.FindAll()
+	if err != nil {
+		return nil, err
+	}
+	if flags == nil {
+		flags = []Domain.FeatureFlag{}
+	}
+	return flags, nil
+}
+
+func (uc *featureFlagUseCase) GetFlag(key string) (*Domain.FeatureFlag, error) {
+	flag, err := uc.flagRepo.FindByKey(key)
+	if err != nil {
+		return nil, err
+	}
+	if flag == nil {
+		return nil, Domain.NotFoundError("feature flag not found")
+	}
+	return flag, nil
+}
+
+func (uc *featureFlagUseCase) CreateFlag(userID string, req Domain.CreateFeatureFlagRequest) (*Domain.FeatureFlag, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	key := strings.TrimSpace(req.Key)
+	if !featureFlagKeyFormat.MatchString(key) {
+		return nil, fmt.Errorf("invalid flag key: use lowercase letters, digits and underscores")
+	}
+
+	enabledBusinesses, err := parseBusinessIDs(req.EnabledBusinesses)
+	if err != nil {
+		return nil, err
+	}
+	disabledBusinesses, err := parseBusinessIDs(req.DisabledBusinesses)
+	if err != nil {
+		return nil, err
+	}
+
+	flag := &Domain.FeatureFlag{
+		Key:                key,
+		Description:        req.Description,
+		Enabled:            req.Enabled,
+		RolloutPercent:     req.RolloutPercent,
+		EnabledBusinesses:  enabledBusinesses,
+		DisabledBusinesses: disabledBusinesses,
+		UpdatedBy:          objUserID,
+	}
+	if err := uc.flagRepo.Create(flag); err != nil {
+		return nil, err
+	}
+
+	uc.cache.Delete(featureFlagCacheKey)
+	return flag, nil
+}
+
+func (uc *featureFlagUseCase) UpdateFlag(key, userID string, req Domain.UpdateFeatureFlagRequest) (*Domain.FeatureFlag, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	flag, err := uc.GetFlag(key)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Description != nil {
+		flag.Description = *req.Description
+	}
+	if req.Enabled != nil {
+		flag.Enabled = *req.Enabled
+	}
+	if req.RolloutPercent != nil {
+		flag.RolloutPercent = *req.RolloutPercent
+	}
+	if req.EnabledBusinesses != nil {
+		if flag.EnabledBusinesses, err = parseBusinessIDs(*req.EnabledBusinesses); err != nil {
+			return nil, err
+		}
+	}
+	if req.DisabledBusinesses != nil {
+		if flag.DisabledBusinesses, err = parseBusinessIDs(*req.DisabledBusinesses); err != nil {
+			return nil, err
+		}
+	}
+	flag.UpdatedBy = objUserID
+
+	if err := uc.flagRepo.Update(flag); err != nil {
+		return nil, err
+	}
+
+	uc.cache.Delete(featureFlagCacheKey)
+	return flag, nil
+}
+
+func (uc *featureFlagUseCase) DeleteFlag(key string) error {
+	if _, err := uc.GetFlag(key); err != nil {
+		return err
+	}
+	if err := uc.flagRepo.Delete(key); err != nil {
+		return err
+	}
+
+	uc.cache.Delete(featureFlagCacheKey)
+	return nil
+}
+
+func (uc *featureFlagUseCase) EnabledFlags(businessID string) []string {
+	enabled := []string{}
+	for _, flag := range uc.cachedFlags() {
+		if flag.EnabledFor(businessID) {
+			enabled = append(enabled, flag.Key)
+		}
+	}
+	return enabled
+}
+
+func (uc *featureFlagUseCase) IsEnabled(key, businessID string) bool {
+	for _, flag := range uc.cachedFlags() {
+		if flag.Key == key {
+			return flag.EnabledFor(businessID)
+		}
+	}
+	return false
+}
+
+// cachedFlags returns every flag, reading them again once the cache expires. If
+// they cannot be read, flags evaluate as off rather than failing requests.
+func (uc *featureFlagUseCase) cachedFlags() []Domain.FeatureFlag {
+	if cached, _, ok := uc.cache.Get(featureFlagCacheKey); ok {
+		return cached.([]Domain.FeatureFlag)
+	}
+
+	flags, err := uc.flagRepo.FindAll()
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to load feature flags", "error", err)
+		return nil
+	}
+	uc.cache.Set(featureFlagCacheKey, flags)
+	return flags
+}
+
+func parseBusinessIDs(ids []string) ([]primitive.ObjectID, error) {
+	objIDs := make([]primitive.ObjectID, 0, len(ids))
+	for _, id := range ids {
+		objID, err := primitive.ObjectIDFromHex(id)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID %q", id)
+		}
+		objIDs = append(objIDs, objID)
+	}
+	return objIDs, nil
+}
@@ -0,0 +1,278 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ForecastUseCase interface {
+	GetForecast(ctx context.Context, businessID string, opts Domain.ForecastOptions) (*Domain.ForecastReport, error)
+	GetReorderSuggestions(ctx context.Context, businessID string, leadDays int) (*Domain.ForecastReport, error)
+}
+
+type forecastUseCase struct {
+	analyticsRepo Domain.AnalyticsRepository
+	inventoryRepo Domain.ProductRepository
+	businessRepo  Domain.BusinessRepository
+}
+
+const (
+	defaultForecastHistoryDays = 56
+	minForecastHistoryDays     = 14
+	maxForecastHistoryDays     = 365
+	defaultForecastLeadDays    = 7
+	maxForecastLeadDays        = 90
+
+	// The level is the average of this many most recent days
+	forecastLevelDays = 28
+	// Weekday patterns need at least two of each weekday
+	minSeasonalHistoryDays = 14
+)
+
+func NewForecastUseCase(
+	analyticsRepo Domain.AnalyticsRepository,
+	inventoryRepo Domain.ProductRepository,
+	businessRepo Domain.BusinessRepository,
+) ForecastUseCase {
+	return &forecastUseCase{
+		analyticsRepo: analyticsRepo,
+		inventoryRepo: inventoryRepo,
+		businessRepo:  businessRepo,
+	}
+}
+
+// GetForecast projects the next seven days of demand per product from completed daily
+// sales, and how much to reorder to cover the lead time on top of the minimum stock
+func (uc *forecastUseCase) GetForecast(ctx context.Context, businessID string, opts Domain.ForecastOptions) (*Domain.ForecastReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if opts.HistoryDays == 0 {
+		opts.HistoryDays = defaultForecastHistoryDays
+	}
+	if opts.HistoryDays < minForecastHistoryDays || opts.HistoryDays > maxForecastHistoryDays {
+		return nil, fmt.Errorf("history_days must be between %d and %d", minForecastHistoryDays, maxForecastHistoryDays)
+	}
+	if opts.LeadDays == 0 {
+		opts.LeadDays = defaultForecastLeadDays
+	}
+	if opts.LeadDays < 1 || opts.LeadDays > maxForecastLeadDays {
+		return nil, fmt.Errorf("lead_days must be between 1 and %d", maxForecastLeadDays)
+	}
+
+	var products []Domain.Product
+	if opts.ProductID != "" {
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, opts.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if product == nil {
+			return nil, Domain.NotFoundError("product not found")
+		}
+		products = []Domain.Product{*product}
+	} else {
+		status := Domain.ProductStatusActive
+		filters := Domain.ProductFilters{Status: &status}
+		if opts.Category != "" {
+			filters.Category = &opts.Category
+		}
+		products, err = uc.inventoryRepo.FindByBusinessID(businessID, filters)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find products: %w", err)
+		}
+	}
+
+	// Only whole days count as history, so today is the first forecast day
+	clock := clockFor(business)
+	today := clock.Day(time.Now())
+	fromDay := today.AddDate(0, 0, -opts.HistoryDays)
+	toDay := today.AddDate(0, 0, -1)
+
+	quantities, err := uc.analyticsRepo.ProductDailyQuantities(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	history := make(map[primitive.ObjectID]map[time.Time]float64)
+	for _, q := range quantities {
+		if history[q.ProductID] == nil {
+			history[q.ProductID] = make(map[time.Time]float64)
+		}
+		history[q.ProductID][q.Day] += q.Quantity
+	}
+
+	report := &Domain.ForecastReport{
+		GeneratedAt: time.Now(),
+		StartDate:   today.Format("2006-01-02"),
+		HistoryDays: opts.HistoryDays,
+		LeadDays:    opts.LeadDays,
+		Products:    make([]Domain.ProductForecast, 0, len(products)),
+	}
+
+	for _, product := range products {
+		// A product cannot have sold before it existed
+		start := fromDay
+		if created := clock.Day(product.CreatedAt); !product.CreatedAt.IsZero() && created.After(start) {
+			start = created
+		}
+
+		forecast := forecastProduct(history[product.ID], start, toDay, today, opts.LeadDays)
+		forecast.ProductID = product.ID.Hex()
+		forecast.Name = product.Name
+		forecast.Category = product.Category
+		applyReorder(&forecast, product)
+
+		report.Products = append(report.Products, forecast)
+	}
+
+	return report, nil
+}
+
+// GetReorderSuggestions lists the products whose stock will not cover the lead time,
+// the ones running out soonest first
+func (uc *forecastUseCase) GetReorderSuggestions(ctx context.Context, businessID string, leadDays int) (*Domain.ForecastReport, error) {
+	report, err := uc.GetForecast(ctx, businessID, Domain.ForecastOptions{LeadDays: leadDays})
+	if err != nil {
+		return nil, err
+	}
+
+	suggestions := make([]Domain.ProductForecast, 0)
+	for _, forecast := range report.Products {
+		if forecast.NeedsReorder {
+			suggestions = append(suggestions, forecast)
+		}
+	}
+
+	sort.SliceStable(suggestions, func(i, j int) bool {
+		a, b := suggestions[i].DaysOfCover, suggestions[j].DaysOfCover
+		if a == nil || b == nil {
+			return a != nil
+		}
+		return *a < *b
+	})
+
+	report.Products = suggestions
+	return report, nil
+}
+
+// forecastProduct fits a level and weekday index to the sales in [start, end] and projects
+// them from firstDay for seven days, or for the lead time when that is longer
+func forecastProduct(sales map[time.Time]float64, start, end, firstDay time.Time, leadDays int) Domain.ProductForecast {
+	forecast := Domain.ProductForecast{
+		Method: Domain.ForecastMethodNoHistory,
+		Daily:  []Domain.ForecastDay{},
+	}
+
+	observed := 0
+	if !start.After(end) {
+		observed = int(end.Sub(start).Hours()/24) + 1
+	}
+	forecast.HistoryDays = observed
+
+	var total float64
+	for _, q := range sales {
+		total += q
+	}
+
+	horizon := 7
+	if leadDays > horizon {
+		horizon = leadDays
+	}
+
+	if observed == 0 || total <= 0 {
+		for i := 0; i < 7; i++ {
+			forecast.Daily = append(forecast.Daily, Domain.ForecastDay{Date: firstDay.AddDate(0, 0, i).Format("2006-01-02")})
+		}
+		return forecast
+	}
+
+	// Level: average of the most recent days
+	levelDays := observed
+	if levelDays > forecastLevelDays {
+		levelDays = forecastLevelDays
+	}
+	var recent float64
+	for day := end.AddDate(0, 0, -(levelDays - 1)); !day.After(end); day = day.AddDate(0, 0, 1) {
+		recent += sales[day]
+	}
+	level := recent / float64(levelDays)
+
+	// Weekday index: how each weekday sells relative to the average day
+	index := [7]float64{1, 1, 1, 1, 1, 1, 1}
+	forecast.Method = Domain.ForecastMethodMovingAverage
+	if observed >= minSeasonalHistoryDays {
+		var weekdayTotals [7]float64
+		var weekdayCounts [7]int
+		for day := start; !day.After(end); day = day.AddDate(0, 0, 1) {
+			weekdayTotals[day.Weekday()] += sales[day]
+			weekdayCounts[day.Weekday()]++
+		}
+
+		average := total / float64(observed)
+		for wd := 0; wd < 7; wd++ {
+			if weekdayCounts[wd] > 0 {
+				index[wd] = (weekdayTotals[wd] / float64(weekdayCounts[wd])) / average
+			}
+		}
+		forecast.Method = Domain.ForecastMethodSeasonal
+	}
+
+	forecast.AverageDaily = roundCurrency(level)
+
+	for i := 0; i < horizon; i++ {
+		day := firstDay.AddDate(0, 0, i)
+		quantity := level * index[day.Weekday()]
+
+		if i < 7 {
+			forecast.NextWeekDemand += quantity
+			forecast.Daily = append(forecast.Daily, Domain.ForecastDay{
+				Date:     day.Format("2006-01-02"),
+				Quantity: roundCurrency(quantity),
+			})
+		}
+		if i < leadDays {
+			forecast.LeadDemand += quantity
+		}
+	}
+
+	forecast.NextWeekDemand = roundCurrency(forecast.NextWeekDemand)
+	forecast.LeadDemand = roundCurrency(forecast.LeadDemand)
+
+	return forecast
+}
+
+// applyReorder suggests ordering enough to cover the lead time and keep the minimum stock,
+// capped at the maximum stock when one is set
+func applyReorder(forecast *Domain.ProductForecast, product Domain.Product) {
+	forecast.CurrentStock = product.Stock
+	forecast.MinStock = product.MinStock
+
+	if forecast.AverageDaily > 0 {
+		cover := roundCurrency(product.Stock / forecast.AverageDaily)
+		forecast.DaysOfCover = &cover
+	}
+
+	needed := forecast.LeadDemand + product.MinStock - product.Stock
+	if needed <= 0 {
+		return
+	}
+
+	quantity := math.Ceil(needed)
+	if product.MaxStock > 0 && product.Stock+quantity > product.MaxStock {
+		quantity = math.Max(0, math.Floor(product.MaxStock-product.Stock))
+	}
+
+	forecast.ReorderQuantity = quantity
+	forecast.NeedsReorder = quantity > 0
+}
@@ -0,0 +1,267 @@
+package Usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+type GiftCardUseCase interface {
+	IssueGiftCard(businessID, userID string, req Domain.IssueGiftCardRequest) (*Domain.GiftCard, error)
+	GetGiftCardByID(ctx context.Context, id, businessID string) (*Domain.GiftCard, error)
+	GetGiftCardByCode(businessID, code string) (*Domain.GiftCard, error)
+	GetGiftCards(businessID string, filters Domain.GiftCardFilters) ([]Domain.GiftCard, error)
+	RedeemGiftCard(businessID, userID string, req Domain.RedeemGiftCardRequest) (*Domain.GiftCard, error)
+	ReloadGiftCard(ctx context.Context, id, businessID, userID string, req Domain.ReloadGiftCardRequest) (*Domain.GiftCard, error)
+	VoidGiftCard(ctx context.Context, id, businessID, userID string) error
+	GetTransactions(ctx context.Context, id, businessID string, limit int) ([]Domain.GiftCardTransaction, error)
+	GetLiability(businessID string) (*Domain.GiftCardLiability, error)
+}
+
+type giftCardUseCase struct {
+	giftCardRepo Domain.GiftCardRepository
+	businessRepo Domain.BusinessRepository
+}
+
+func NewGiftCardUseCase(
+	giftCardRepo Domain.GiftCardRepository,
+	businessRepo Domain.BusinessRepository,
+) GiftCardUseCase {
+	return &giftCardUseCase{
+		giftCardRepo: giftCardRepo,
+		businessRepo: businessRepo,
+	}
+}
+
+func (uc *giftCardUseCase) IssueGiftCard(businessID, userID string, req Domain.IssueGiftCardRequest) (*Domain.GiftCard, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	if req.Type == "" {
+		req.Type = Domain.GiftCardTypeGiftCard
+	}
+	if req.Type != Domain.GiftCardTypeGiftCard && req.Type != Domain.GiftCardTypeStoreCredit {
+		return nil, fmt.Errorf("invalid gift card type: %s", req.Type)
+	}
+
+	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	code, err := uc.generateUniqueCode(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	card := &Domain.GiftCard{
+		BusinessID:     objBusinessID,
+		Code:           code,
+		Type:           req.Type,
+		InitialBalance: req.Amount,
+		CustomerName:   req.CustomerName,
+		CustomerPhone:  req.CustomerPhone,
+		Notes:          req.Notes,
+		CreatedBy:      objUserID,
+	}
+
+	// Apply expiry policy: explicit days, 0 for never, default otherwise
+	expiryDays := Domain.DefaultGiftCardExpiryDays
+	if req.ExpiryDays != nil {
+		if *req.ExpiryDays < 0 {
+			return nil, fmt.Errorf("expiry days cannot be negative")
+		}
+		expiryDays = *req.ExpiryDays
+	}
+	if expiryDays > 0 {
+		expiresAt := time.Now().AddDate(0, 0, expiryDays)
+		card.ExpiresAt = &expiresAt
+	}
+
+	if err := uc.giftCardRepo.Create(card); err != nil {
+		return nil, fmt.Errorf("failed to issue gift card: %w", err)
+	}
+
+	return card, nil
+}
+
+func (uc *giftCardUseCase) GetGiftCardByID(ctx context.Context, id, businessID string) (*Domain.GiftCard, error) {
+	card, err := uc.giftCardRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gift card: %w", err)
+	}
+	if card == nil {
+		return nil, Domain.NotFoundError("gift card not found")
+	}
+
+	return card, nil
+}
+
+func (uc *giftCardUseCase) GetGiftCardByCode(businessID, code string) (*Domain.GiftCard, error) {
+	card, err := uc.giftCardRepo.FindByCode(businessID, normalizeGiftCardCode(code))
+	if err != nil {
+		return nil, fmt.Errorf("failed to find gift card: %w", err)
+	}
+	if card == nil {
+		return nil, Domain.NotFoundError("gift card not found")
+	}
+
+	return card, nil
+}
+
+func (uc *giftCardUseCase) GetGiftCards(businessID string, filters Domain.GiftCardFilters) ([]Domain.GiftCard, error) {
+	// Close out cards past their expiry before listing
+	if _, err := uc.giftCardRepo.ExpireOverdue(businessID, time.Now()); err != nil {
+		Infrastructure.Logger.Warn("failed to expire overdue gift cards", "error", err)
+	}
+
+	return uc.giftCardRepo.FindByBusinessID(businessID, filters)
+}
+
+func (uc *giftCardUseCase) RedeemGiftCard(businessID, userID string, req Domain.RedeemGiftCardRequest) (*Domain.GiftCard, error) {
+	card, err := uc.GetGiftCardByCode(businessID, req.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	if err := validateGiftCardForRedemption(card, req.Amount); err != nil {
+		return nil, err
+	}
+
+	return uc.giftCardRepo.Redeem(card.ID.Hex(), req.Amount, req.SaleID, userID)
+}
+
+func (uc *giftCardUseCase) ReloadGiftCard(ctx context.Context, id, businessID, userID string, req Domain.ReloadGiftCardRequest) (*Domain.GiftCard, error) {
+	card, err := uc.GetGiftCardByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	if card.IsExpired(time.Now()) {
+		return nil, fmt.Errorf("gift card has expired")
+	}
+
+	return uc.giftCardRepo.Reload(id, req.Amount, req.Note, userID)
+}
+
+func (uc *giftCardUseCase) VoidGiftCard(ctx context.Context, id, businessID, userID string) error {
+	card, err := uc.GetGiftCardByID(ctx, id, businessID)
+	if err != nil {
+		return err
+	}
+
+	if card.Status == Domain.GiftCardStatusVoided {
+		return fmt.Errorf("gift card is already voided")
+	}
+
+	return uc.giftCardRepo.UpdateStatus(id, Domain.GiftCardStatusVoided, Domain.GiftCardTransactionVoid, userID)
+}
+
+func (uc *giftCardUseCase) GetTransactions(ctx context.Context, id, businessID string, limit int) ([]Domain.GiftCardTransaction, error) {
+	// Verify gift card belongs to business
+	if _, err := uc.GetGiftCardByID(ctx, id, businessID); err != nil {
+		return nil, err
+	}
+
+	return uc.giftCardRepo.GetTransactions(id, limit)
+}
+
+func (uc *giftCardUseCase) GetLiability(businessID string) (*Domain.GiftCardLiability, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	now := time.Now()
+	if _, err := uc.giftCardRepo.ExpireOverdue(businessID, now); err != nil {
+		Infrastructure.Logger.Warn("failed to expire overdue gift cards", "error", err)
+	}
+
+	return uc.giftCardRepo.GetLiability(businessID, now)
+}
+
+func (uc *giftCardUseCase) generateUniqueCode(businessID string) (string, error) {
+	for attempt := 0; attempt < 5; attempt++ {
+		code, err := generateGiftCardCode()
+		if err != nil {
+			return "", fmt.Errorf("failed to generate gift card code: %w", err)
+		}
+
+		existing, err := uc.giftCardRepo.FindByCode(businessID, code)
+		if err != nil {
+			return "", fmt.Errorf("failed to check gift card code: %w", err)
+		}
+		if existing == nil {
+			return code, nil
+		}
+	}
+
+	return "", fmt.Errorf("failed to generate a unique gift card code")
+}
+
+// Alphabet without easily confused characters (0/O, 1/I/L)
+const giftCardCodeAlphabet = "ABCDEFGHJKMNPQRSTUVWXYZ23456789"
+
+func generateGiftCardCode() (string, error) {
+	var sb strings.Builder
+	max := big.NewInt(int64(len(giftCardCodeAlphabet)))
+	for i := 0; i < 12; i++ {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		sb.WriteByte(giftCardCodeAlphabet[n.Int64()])
+	}
+	return sb.String(), nil
+}
+
+func normalizeGiftCardCode(code string) string {
+	code = strings.ToUpper(strings.TrimSpace(code))
+	return strings.ReplaceAll(code, "-", "")
+}
+
+func validateGiftCardForRedemption(card *Domain.GiftCard, amount Domain.Money) error {
+	if card.Status != Domain.GiftCardStatusActive {
+		return fmt.Errorf("gift card is %s", card.Status)
+	}
+	if card.IsExpired(time.Now()) {
+		return fmt.Errorf("gift card has expired")
+	}
+	if card.Balance < amount {
+		return fmt.Errorf("insufficient gift card balance. Available: %s, Requested: %s", card.Balance, amount)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package Usecases
+
+import (
+	"fmt"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+type ImportTemplateUseCase interface {
+	// GetTemplate describes the shop's import file for entity, with the shop's
+	// own values in open-ended columns such as product categories
+	GetTemplate(businessID string, entity Domain.ImportEntity) (*Domain.ImportTemplate, error)
+	// DownloadTemplate returns the template as a file, with its content type and name
+	DownloadTemplate(businessID string, entity Domain.ImportEntity, format Domain.TemplateFormat) ([]byte, string, string, error)
+}
+
+type importTemplateUseCase struct {
+	inventoryRepo   Domain.ProductRepository
+	customFieldRepo Domain.CustomFieldRepository
+}
+
+func NewImportTemplateUseCase(inventoryRepo Domain.ProductRepository, customFieldRepo Domain.CustomFieldRepository) ImportTemplateUseCase {
+	return &importTemplateUseCase{inventoryRepo: inventoryRepo, customFieldRepo: customFieldRepo}
+}
+
+// Record kinds whose import files carry the shop's custom fields
+var importCustomFieldEntities = map[Domain.ImportEntity]Domain.CustomFieldEntity{
+	Domain.ImportEntityProducts:  Domain.CustomFieldEntityProduct,
+	Domain.ImportEntityCustomers: Domain.CustomFieldEntityCustomer,
+	Domain.ImportEntitySuppliers: Domain.CustomFieldEntitySupplier,
+}
+
+func (uc *importTemplateUseCase) GetTemplate(businessID string, entity Domain.ImportEntity) (*Domain.ImportTemplate, error) {
+	base, ok := Domain.ImportTemplates[entity]
+	if !ok {
+		return nil, Domain.ValidationError("entity must be products, customers, suppliers or expenses")
+	}
+
+	// Copied, so the shop's options stay out of the shared template
+	template := base
+	template.Columns = append([]Domain.ImportColumn(nil), base.Columns...)
+
+	if entity == Domain.ImportEntityProducts {
+		categories, err := uc.inventoryRepo.FindCategories(businessID)
+		if err != nil {
+			return nil, err
+		}
+		for i := range template.Columns {
+			if template.Columns[i].Header == "category" {
+				template.Columns[i].Options = categories
+			}
+		}
+	}
+
+	if fieldEntity, ok := importCustomFieldEntities[entity]; ok {
+		fields, err := uc.customFieldRepo.FindByBusinessID(businessID, fieldEntity, true)
+		if err != nil {
+			return nil, err
+		}
+		for _, field := range fields {
+			template.Columns = append(template.Columns, customFieldColumn(field))
+		}
+		if len(fields) > 0 {
+			template.Samples = make([][]string, len(base.Samples))
+			for i, sample := range base.Samples {
+				template.Samples[i] = append(append([]string(nil), sample...), make([]string, len(fields))...)
+			}
+		}
+	}
+
+	return &template, nil
+}
+
+// customFieldColumn is the import column for a custom field, headed by the
+// field's path in the record's JSON
+func customFieldColumn(field Domain.CustomFieldDefinition) Domain.ImportColumn {
+	column := Domain.ImportColumn{
+		Header:   "custom_fields." + field.Key,
+		Type:     Domain.ImportColumnText,
+		Required: field.Required,
+		Note:     field.Label,
+	}
+
+	switch field.Type {
+	case Domain.CustomFieldNumber:
+		// Number columns of a template take no negatives
+		if field.Min != nil && *field.Min >= 0 {
+			column.Type = Domain.ImportColumnNumber
+		}
+	case Domain.CustomFieldDate:
+		column.Type = Domain.ImportColumnDate
+	case Domain.CustomFieldBoolean:
+		column.Options = []string{"yes", "no"}
+	case Domain.CustomFieldSelect:
+		column.Options = field.Options
+	}
+
+	return column
+}
+
+func (uc *importTemplateUseCase) DownloadTemplate(businessID string, entity Domain.ImportEntity, format Domain.TemplateFormat) ([]byte, string, string, error) {
+	if format == "" {
+		format = Domain.TemplateFormatXLSX
+	}
+	if !format.IsValid() {
+		return nil, "", "", Domain.ValidationError("format must be csv or xlsx")
+	}
+
+	template, err := uc.GetTemplate(businessID, entity)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	var data []byte
+	contentType := "text/csv"
+	if format == Domain.TemplateFormatXLSX {
+		data, err = Infrastructure.WriteTemplateXLSX(*template)
+		contentType = Infrastructure.XLSXContentType
+	} else {
+		data, err = Infrastructure.WriteTemplateCSV(*template)
+	}
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	filename := fmt.Sprintf("%s_import_template.%s", entity, format)
+	return data, contentType, filename, nil
+}
@@ -1,34 +1,59 @@
 package Usecases
 
 import (
+	"context"
 	"fmt"
+	"time"
 
 	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type InventoryUseCase interface {
 	CreateProduct(businessID, userID string, req Domain.CreateProductRequest) (*Domain.Product, error)
-	GetProductByID(id, businessID string) (*Domain.Product, error)
+	GetProductByID(ctx context.Context, id, businessID string) (*Domain.Product, error)
 	GetProducts(businessID string, filters Domain.ProductFilters) ([]Domain.Product, error)
-	UpdateProduct(id, businessID, userID string, req Domain.CreateProductRequest) (*Domain.Product, error)
-	DeleteProduct(id, businessID, userID string) error
-	AdjustStock(id, businessID, userID string, req Domain.AdjustStockRequest) error
+	// GetProductsByIDs looks up many of the shop's products in one query, e.g. the
+	// products of a page of sales; trashed products are included, as sales keep them
+	GetProductsByIDs(businessID string, ids []string) (map[string]*Domain.Product, error)
+	CountProducts(businessID string, filters Domain.ProductFilters) (int64, error)
+	UpdateProduct(ctx context.Context, id, businessID, userID string, req Domain.CreateProductRequest) (*Domain.Product, error)
+	DeleteProduct(ctx context.Context, id, businessID, userID string) error
+	AdjustStock(ctx context.Context, id, businessID, userID string, req Domain.AdjustStockRequest) error
 	GetLowStock(businessID string, threshold float64) ([]Domain.Product, error)
-	GetStockHistory(productID, businessID string, limit int) ([]Domain.StockMovement, error)
+	GetStockHistory(ctx context.Context, productID, businessID string, limit int) ([]Domain.StockMovement, error)
+	// GetStockLedger pages through the shop's stock movements, or one product's
+	// when filters.ProductID is set
+	GetStockLedger(ctx context.Context, businessID string, filters Domain.StockLedgerFilters) ([]Domain.StockMovement, error)
+	// OnStockAdjusted raises stock.low when a movement takes a product below its minimum
+	OnStockAdjusted(ctx context.Context, event *Domain.DomainEvent) error
 }
 
 type inventoryUseCase struct {
-	inventoryRepo Domain.ProductRepository
-	businessRepo  Domain.BusinessRepository
+	inventoryRepo   Domain.ProductRepository
+	businessRepo    Domain.BusinessRepository
+	customFieldRepo Domain.CustomFieldRepository
+	reservationRepo Domain.StockReservationRepository
+	ledgerRepo      Domain.StockLedgerRepository
+	events          EventPublisher
 }
 
 func NewInventoryUseCase(
 	inventoryRepo Domain.ProductRepository,
 	businessRepo Domain.BusinessRepository,
+	customFieldRepo Domain.CustomFieldRepository,
+	reservationRepo Domain.StockReservationRepository,
+	ledgerRepo Domain.StockLedgerRepository,
+	events EventPublisher,
 ) InventoryUseCase {
 	return &inventoryUseCase{
-		inventoryRepo: inventoryRepo,
-		businessRepo:  businessRepo,
+		inventoryRepo:   inventoryRepo,
+		businessRepo:    businessRepo,
+		customFieldRepo: customFieldRepo,
+		reservationRepo: reservationRepo,
+		ledgerRepo:      ledgerRepo,
+		events:          events,
 	}
 }
 
@@ -39,7 +64,7 @@ func (uc *inventoryUseCase) CreateProduct(businessID, userID string, req Domain.
 		return nil, fmt.Errorf("failed to find business: %w", err)
 	}
 	if business == nil {
-		return nil, fmt.Errorf("business not found")
+		return nil, Domain.NotFoundError("business not found")
 	}
 
 	// Validate selling price > cost price
@@ -47,11 +72,31 @@ func (uc *inventoryUseCase) CreateProduct(businessID, userID string, req Domain.
 		return nil, fmt.Errorf("selling price must be greater than cost price")
 	}
 
+	// A service has no stock to count or reorder
+	service := req.Service != nil && *req.Service
+	if service {
+		req.Stock, req.MinStock, req.MaxStock = 0, 0, 0
+	}
+
 	// Validate min/max stock if provided
 	if req.MinStock > 0 && req.MaxStock > 0 && req.MinStock >= req.MaxStock {
 		return nil, fmt.Errorf("minimum stock must be less than maximum stock")
 	}
 
+	if err := validateTierPrices(req.TierPrices, req.CostPrice); err != nil {
+		return nil, err
+	}
+
+	req.PLU = Domain.NormalizePLU(req.PLU)
+	if err := uc.checkPLUFree(businessID, req.PLU, ""); err != nil {
+		return nil, err
+	}
+
+	customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntityProduct, req.CustomFields, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
 	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
 	if err != nil {
 		return nil, fmt.Errorf("invalid business ID: %w", err)
@@ -75,8 +120,16 @@ func (uc *inventoryUseCase) CreateProduct(businessID, userID string, req Domain.
 		Stock:        req.Stock,
 		MinStock:     req.MinStock,
 		MaxStock:     req.MaxStock,
+		TierPrices:   req.TierPrices,
+		PLU:          req.PLU,
+		Weighed:      req.Weighed != nil && *req.Weighed,
+		Service:      service,
+		CustomFields: customFields,
 		CreatedBy:    objUserID,
 	}
+	if req.DurationMinutes != nil {
+		product.DurationMinutes = *req.DurationMinutes
+	}
 
 	if err := uc.inventoryRepo.Create(product); err != nil {
 		return nil, fmt.Errorf("failed to create product: %w", err)
@@ -85,33 +138,71 @@ func (uc *inventoryUseCase) CreateProduct(businessID, userID string, req Domain.
 	return product, nil
 }
 
-func (uc *inventoryUseCase) GetProductByID(id, businessID string) (*Domain.Product, error) {
-	product, err := uc.inventoryRepo.FindByID(id)
+func (uc *inventoryUseCase) GetProductByID(ctx context.Context, id, businessID string) (*Domain.Product, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find product: %w", err)
 	}
-	if product == nil {
-		return nil, fmt.Errorf("product not found")
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
 	}
 
-	// Verify product belongs to business
-	if product.BusinessID.Hex() != businessID {
-		return nil, fmt.Errorf("access denied: product does not belong to this business")
+	reserved, err := uc.reservationRepo.ReservedQuantities(businessID, []primitive.ObjectID{product.ID}, nil)
+	if err != nil {
+		return nil, err
 	}
+	product.Reserved = reserved[product.ID]
 
 	return product, nil
 }
 
 func (uc *inventoryUseCase) GetProducts(businessID string, filters Domain.ProductFilters) ([]Domain.Product, error) {
-	return uc.inventoryRepo.FindByBusinessID(businessID, filters)
+	products, err := uc.inventoryRepo.FindByBusinessID(businessID, filters)
+	if err != nil || len(products) == 0 {
+		return products, err
+	}
+
+	ids := make([]primitive.ObjectID, len(products))
+	for i := range products {
+		ids[i] = products[i].ID
+	}
+	reserved, err := uc.reservationRepo.ReservedQuantities(businessID, ids, nil)
+	if err != nil {
+		return nil, err
+	}
+	for i := range products {
+		products[i].Reserved = reserved[products[i].ID]
+	}
+
+	return products, nil
 }
 
-func (uc *inventoryUseCase) UpdateProduct(id, businessID, userID string, req Domain.CreateProductRequest) (*Domain.Product, error) {
-	product, err := uc.GetProductByID(id, businessID)
+func (uc *inventoryUseCase) GetProductsByIDs(businessID string, ids []string) (map[string]*Domain.Product, error) {
+	products, err := uc.inventoryRepo.FindByIDs(businessID, ids)
 	if err != nil {
 		return nil, err
 	}
 
+	byID := make(map[string]*Domain.Product, len(products))
+	for i := range products {
+		byID[products[i].ID.Hex()] = &products[i]
+	}
+	return byID, nil
+}
+
+func (uc *inventoryUseCase) CountProducts(businessID string, filters Domain.ProductFilters) (int64, error) {
+	return uc.inventoryRepo.Count(businessID, filters)
+}
+
+func (uc *inventoryUseCase) UpdateProduct(ctx context.Context, id, businessID, userID string, req Domain.CreateProductRequest) (*Domain.Product, error) {
+	product, err := uc.GetProductByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != nil && *req.Version != product.Version {
+		return nil, Domain.StaleVersionError("product")
+	}
+
 	// Validate selling price > cost price
 	if req.SellingPrice > 0 && req.CostPrice > 0 && req.SellingPrice <= req.CostPrice {
 		return nil, fmt.Errorf("selling price must be greater than cost price")
@@ -145,6 +236,10 @@ func (uc *inventoryUseCase) UpdateProduct(id, businessID, userID string, req Dom
 		product.CostPrice = req.CostPrice
 	}
 	if req.SellingPrice > 0 {
+		if req.SellingPrice != product.SellingPrice {
+			now := time.Now()
+			product.PriceChangedAt = &now
+		}
 		product.SellingPrice = req.SellingPrice
 	}
 	if req.MinStock >= 0 {
@@ -154,6 +249,44 @@ func (uc *inventoryUseCase) UpdateProduct(id, businessID, userID string, req Dom
 		product.MaxStock = req.MaxStock
 	}
 
+	if req.TierPrices != nil {
+		if err := validateTierPrices(req.TierPrices, product.CostPrice); err != nil {
+			return nil, err
+		}
+		product.TierPrices = req.TierPrices
+	}
+
+	req.PLU = Domain.NormalizePLU(req.PLU)
+	if req.PLU != "" && req.PLU != product.PLU {
+		if err := uc.checkPLUFree(businessID, req.PLU, id); err != nil {
+			return nil, err
+		}
+		product.PLU = req.PLU
+	}
+	if req.Weighed != nil {
+		product.Weighed = *req.Weighed
+	}
+	if req.Service != nil && *req.Service != product.Service {
+		if *req.Service && product.Stock != 0 {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "adjust the product's stock to zero before making it a service")
+		}
+		product.Service = *req.Service
+	}
+	if req.DurationMinutes != nil {
+		product.DurationMinutes = *req.DurationMinutes
+	}
+	if product.Service {
+		product.MinStock, product.MaxStock = 0, 0
+	}
+
+	if req.CustomFields != nil {
+		customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntityProduct, req.CustomFields, product.CustomFields, false)
+		if err != nil {
+			return nil, err
+		}
+		product.CustomFields = customFields
+	}
+
 	// Stock should only be updated via AdjustStock method
 	// product.Stock = req.Stock
 
@@ -164,8 +297,8 @@ func (uc *inventoryUseCase) UpdateProduct(id, businessID, userID string, req Dom
 	return product, nil
 }
 
-func (uc *inventoryUseCase) DeleteProduct(id, businessID, userID string) error {
-	product, err := uc.GetProductByID(id, businessID)
+func (uc *inventoryUseCase) DeleteProduct(ctx context.Context, id, businessID, userID string) error {
+	product, err := uc.GetProductByID(ctx, id, businessID)
 	if err != nil {
 		return err
 	}
@@ -178,12 +311,15 @@ func (uc *inventoryUseCase) DeleteProduct(id, businessID, userID string) error {
 	return uc.inventoryRepo.Delete(id)
 }
 
-func (uc *inventoryUseCase) AdjustStock(id, businessID, userID string, req Domain.AdjustStockRequest) error {
+func (uc *inventoryUseCase) AdjustStock(ctx context.Context, id, businessID, userID string, req Domain.AdjustStockRequest) error {
 	// First, get the product to verify it belongs to business
-	_, err := uc.GetProductByID(id, businessID)
+	product, err := uc.GetProductByID(ctx, id, businessID)
 	if err != nil {
 		return err
 	}
+	if product.Service {
+		return Domain.NewAppError(Domain.ErrCodeBadRequest, "services carry no stock")
+	}
 
 	// Validate movement type
 	if !uc.isValidMovementType(req.Type) {
@@ -201,7 +337,7 @@ func (uc *inventoryUseCase) AdjustStock(id, businessID, userID string, req Domai
 	}
 
 	// Call repository method
-	return uc.inventoryRepo.AdjustStock(
+	if err := uc.inventoryRepo.AdjustStock(
 		id,
 		req.Quantity,
 		req.Type,
@@ -209,7 +345,36 @@ func (uc *inventoryUseCase) AdjustStock(id, businessID, userID string, req Domai
 		nil, // referenceID
 		"",  // referenceType
 		userID,
-	)
+	); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// OnStockAdjusted raises stock.low when the movement took the product from at or
+// above its minimum stock to below it, so a product sitting low is reported once
+func (uc *inventoryUseCase) OnStockAdjusted(ctx context.Context, event *Domain.DomainEvent) error {
+	var movement Domain.StockMovement
+	if err := event.Decode(&movement); err != nil {
+		return err
+	}
+
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, movement.ProductID.Hex())
+	if err != nil {
+		return err
+	}
+	if product == nil || product.MinStock <= 0 || movement.Previous < product.MinStock || movement.New >= product.MinStock {
+		return nil
+	}
+
+	return uc.events.Publish(ctx, product.BusinessID.Hex(), Domain.WebhookEventStockLow, Domain.StockLowEvent{
+		ProductID: product.ID.Hex(),
+		Name:      product.Name,
+		SKU:       product.SKU,
+		Stock:     movement.New,
+		MinStock:  product.MinStock,
+	})
 }
 
 func (uc *inventoryUseCase) GetLowStock(businessID string, threshold float64) ([]Domain.Product, error) {
@@ -223,9 +388,9 @@ func (uc *inventoryUseCase) GetLowStock(businessID string, threshold float64) ([
 	return uc.inventoryRepo.GetLowStock(businessID, 0) // 0 means use product's min_stock
 }
 
-func (uc *inventoryUseCase) GetStockHistory(productID, businessID string, limit int) ([]Domain.StockMovement, error) {
+func (uc *inventoryUseCase) GetStockHistory(ctx context.Context, productID, businessID string, limit int) ([]Domain.StockMovement, error) {
 	// Verify product belongs to business
-	_, err := uc.GetProductByID(productID, businessID)
+	_, err := uc.GetProductByID(ctx, productID, businessID)
 	if err != nil {
 		return nil, err
 	}
@@ -233,6 +398,23 @@ func (uc *inventoryUseCase) GetStockHistory(productID, businessID string, limit
 	return uc.inventoryRepo.GetStockHistory(productID, limit)
 }
 
+func (uc *inventoryUseCase) GetStockLedger(ctx context.Context, businessID string, filters Domain.StockLedgerFilters) ([]Domain.StockMovement, error) {
+	if filters.ProductID != nil {
+		if _, err := uc.GetProductByID(ctx, *filters.ProductID, businessID); err != nil {
+			return nil, err
+		}
+	}
+
+	movements, err := uc.ledgerRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if movements == nil {
+		movements = []Domain.StockMovement{}
+	}
+	return movements, nil
+}
+
 func (uc *inventoryUseCase) isValidMovementType(movementType Domain.MovementType) bool {
 	validTypes := []Domain.MovementType{
 		Domain.MovementTypePurchase,
@@ -250,3 +432,32 @@ func (uc *inventoryUseCase) isValidMovementType(movementType Domain.MovementType
 	}
 	return false
 }
+
+// checkPLUFree makes sure no other product of the shop has the PLU, as a scale
+// barcode could only ever ring up one of them
+func (uc *inventoryUseCase) checkPLUFree(businessID, plu, productID string) error {
+	if plu == "" {
+		return nil
+	}
+
+	products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{PLU: &plu, Limit: 1})
+	if err != nil {
+		return fmt.Errorf("failed to check PLU: %w", err)
+	}
+	if len(products) > 0 && products[0].ID.Hex() != productID {
+		return Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("PLU %s is already used by %s", plu, products[0].Name))
+	}
+	return nil
+}
+
+func validateTierPrices(prices map[Domain.CustomerTier]Domain.Money, costPrice Domain.Money) error {
+	for tier, price := range prices {
+		if !tier.IsValid() || tier == Domain.CustomerTierRetail {
+			return fmt.Errorf("invalid price tier: %s (retail uses the selling price)", tier)
+		}
+		if price <= costPrice {
+			return fmt.Errorf("%s price must be greater than cost price", tier)
+		}
+	}
+	return nil
+}
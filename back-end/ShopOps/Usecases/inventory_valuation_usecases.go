@@ -0,0 +1,283 @@
+package Usecases
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type InventoryValuationUseCase interface {
+	GetValuation(businessID, asOf, category string) (*Domain.InventoryValuation, error)
+	GetValuationHistory(businessID, startDate, endDate string) ([]Domain.InventoryValuationPoint, error)
+	TakeSnapshots() error
+}
+
+type inventoryValuationUseCase struct {
+	snapshotRepo  Domain.InventorySnapshotRepository
+	ledgerRepo    Domain.StockLedgerRepository
+	inventoryRepo Domain.ProductRepository
+	businessRepo  Domain.BusinessRepository
+}
+
+func NewInventoryValuationUseCase(
+	snapshotRepo Domain.InventorySnapshotRepository,
+	ledgerRepo Domain.StockLedgerRepository,
+	inventoryRepo Domain.ProductRepository,
+	businessRepo Domain.BusinessRepository,
+) InventoryValuationUseCase {
+	return &inventoryValuationUseCase{
+		snapshotRepo:  snapshotRepo,
+		ledgerRepo:    ledgerRepo,
+		inventoryRepo: inventoryRepo,
+		businessRepo:  businessRepo,
+	}
+}
+
+// GetValuation returns the stock value at the close of asOf from the stock ledger,
+// or the live stock when asOf is today or empty
+func (uc *inventoryValuationUseCase) GetValuation(businessID, asOf, category string) (*Domain.InventoryValuation, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	now := time.Now()
+	today := clockFor(business).Day(now)
+
+	day := today
+	if asOf != "" {
+		day, err = time.Parse("2006-01-02", asOf)
+		if err != nil {
+			return nil, fmt.Errorf("invalid as_of, expected YYYY-MM-DD: %w", err)
+		}
+	}
+
+	if !day.Before(today) {
+		products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find products: %w", err)
+		}
+
+		valuation := newInventoryValuation(snapshotFromProducts(products, today, now), category)
+		valuation.AsOf = day.Format("2006-01-02")
+		valuation.Live = true
+		return valuation, nil
+	}
+
+	snapshot, err := uc.snapshotFromLedger(business, day, now)
+	if err != nil {
+		return nil, err
+	}
+
+	valuation := newInventoryValuation(snapshot, category)
+	valuation.AsOf = day.Format("2006-01-02")
+	return valuation, nil
+}
+
+func (uc *inventoryValuationUseCase) GetValuationHistory(businessID, startDate, endDate string) ([]Domain.InventoryValuationPoint, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clockFor(business))
+	if err != nil {
+		return nil, err
+	}
+
+	snapshots, err := uc.snapshotRepo.FindTotals(businessID, fromDay, toDay)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]Domain.InventoryValuationPoint, 0, len(snapshots))
+	for _, snapshot := range snapshots {
+		points = append(points, Domain.InventoryValuationPoint{
+			Day:           snapshot.Day.Format("2006-01-02"),
+			ProductCount:  snapshot.ProductCount,
+			TotalQuantity: snapshot.TotalQuantity,
+			TotalValue:    snapshot.TotalValue,
+			RetailValue:   snapshot.RetailValue,
+		})
+	}
+
+	return points, nil
+}
+
+// TakeSnapshots records the closing stock of the day that just ended for every active
+// business, for the value history. Runs more often than nightly, so a missed run is
+// caught up on the next one.
+func (uc *inventoryValuationUseCase) TakeSnapshots() error {
+	businesses, err := uc.businessRepo.FindActive()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, business := range businesses {
+		yesterday := clockFor(&business).Day(now).AddDate(0, 0, -1)
+		if err := uc.takeSnapshot(&business, yesterday, now); err != nil {
+			Infrastructure.Logger.Warn("failed to snapshot inventory for business", "business_id", business.ID.Hex(), "error", err)
+		}
+	}
+
+	return nil
+}
+
+func (uc *inventoryValuationUseCase) takeSnapshot(business *Domain.Business, day, now time.Time) error {
+	exists, err := uc.snapshotRepo.Exists(business.ID.Hex(), day)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	snapshot, err := uc.snapshotFromLedger(business, day, now)
+	if err != nil {
+		return err
+	}
+	snapshot.BusinessID = business.ID
+	return uc.snapshotRepo.Save(snapshot)
+}
+
+// snapshotFromLedger values the stock the ledger had at the close of day, at the
+// cost each product last moved at. Selling prices are today's, as their history
+// is not kept.
+func (uc *inventoryValuationUseCase) snapshotFromLedger(business *Domain.Business, day, now time.Time) (*Domain.InventorySnapshot, error) {
+	businessID := business.ID.Hex()
+	_, dayEnd := clockFor(business).Range(day, day)
+	balances, err := uc.ledgerRepo.BalancesAsOf(businessID, dayEnd)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, len(balances))
+	for i, balance := range balances {
+		ids[i] = balance.ProductID.Hex()
+	}
+	products, err := uc.inventoryRepo.FindByIDs(businessID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find products: %w", err)
+	}
+	byID := make(map[primitive.ObjectID]Domain.Product, len(products))
+	for _, product := range products {
+		byID[product.ID] = product
+	}
+
+	// The products as they stood then, as far as the ledger knows
+	held := make([]Domain.Product, 0, len(balances))
+	for _, balance := range balances {
+		product, ok := byID[balance.ProductID]
+		if !ok {
+			continue
+		}
+		product.Stock = balance.Quantity
+		if balance.UnitCost > 0 {
+			product.CostPrice = balance.UnitCost
+		}
+		held = append(held, product)
+	}
+
+	return snapshotFromProducts(held, day, now), nil
+}
+
+// snapshotFromProducts values the stock the shop owns at cost and at selling price
+func snapshotFromProducts(products []Domain.Product, day, takenAt time.Time) *Domain.InventorySnapshot {
+	snapshot := &Domain.InventorySnapshot{
+		Day:     day,
+		TakenAt: takenAt,
+		Lines:   make([]Domain.InventorySnapshotLine, 0, len(products)),
+	}
+
+	for _, product := range products {
+		if product.Stock == 0 {
+			continue
+		}
+		if product.Consignment != nil {
+			snapshot.ConsignedQuantity += product.Stock
+			snapshot.ConsignedRetailValue += product.SellingPrice.Times(product.Stock)
+			continue
+		}
+
+		line := Domain.InventorySnapshotLine{
+			ProductID:   product.ID,
+			Name:        product.Name,
+			Category:    product.Category,
+			Quantity:    product.Stock,
+			UnitCost:    product.CostPrice,
+			UnitPrice:   product.SellingPrice,
+			Value:       product.CostPrice.Times(product.Stock),
+			RetailValue: product.SellingPrice.Times(product.Stock),
+		}
+		snapshot.Lines = append(snapshot.Lines, line)
+
+		snapshot.TotalQuantity += line.Quantity
+		snapshot.TotalValue += line.Value
+		snapshot.RetailValue += line.RetailValue
+	}
+
+	snapshot.ProductCount = len(snapshot.Lines)
+
+	return snapshot
+}
+
+// newInventoryValuation totals the snapshot lines in category (all when empty), most valuable first
+func newInventoryValuation(snapshot *Domain.InventorySnapshot, category string) *Domain.InventoryValuation {
+	valuation := &Domain.InventoryValuation{
+		TakenAt:              snapshot.TakenAt,
+		Categories:           []Domain.CategoryValuation{},
+		Lines:                []Domain.InventorySnapshotLine{},
+		ConsignedQuantity:    snapshot.ConsignedQuantity,
+		ConsignedRetailValue: snapshot.ConsignedRetailValue,
+	}
+
+	categories := make(map[string]*Domain.CategoryValuation)
+	for _, line := range snapshot.Lines {
+		if category != "" && line.Category != category {
+			continue
+		}
+		valuation.Lines = append(valuation.Lines, line)
+
+		valuation.TotalQuantity += line.Quantity
+		valuation.TotalValue += line.Value
+		valuation.RetailValue += line.RetailValue
+
+		name := line.Category
+		if name == "" {
+			name = "Uncategorized"
+		}
+		c, ok := categories[name]
+		if !ok {
+			c = &Domain.CategoryValuation{Category: name}
+			categories[name] = c
+		}
+		c.Quantity += line.Quantity
+		c.Value += line.Value
+		c.RetailValue += line.RetailValue
+	}
+
+	for _, c := range categories {
+		valuation.Categories = append(valuation.Categories, *c)
+	}
+	sort.Slice(valuation.Categories, func(i, j int) bool {
+		return valuation.Categories[i].Value > valuation.Categories[j].Value
+	})
+	sort.SliceStable(valuation.Lines, func(i, j int) bool {
+		return valuation.Lines[i].Value > valuation.Lines[j].Value
+	})
+
+	valuation.ProductCount = len(valuation.Lines)
+
+	return valuation
+}
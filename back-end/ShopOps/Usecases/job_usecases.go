@@ -0,0 +1,81 @@
+package Usecases
+
+import (
+	"fmt"
+
+	Domain "ShopOps/Domain"
+)
+
+// JobUseCase is the admin view of the background job queue
+type JobUseCase interface {
+	GetJobs(filters Domain.JobFilters) ([]Domain.Job, error)
+	GetJob(id string) (*Domain.Job, error)
+	// RetryJob runs a dead-lettered job again, or a job waiting out its backoff right away
+	RetryJob(id string) (*Domain.Job, error)
+	GetStats() ([]Domain.JobStats, error)
+}
+
+type jobUseCase struct {
+	jobRepo Domain.JobRepository
+}
+
+func NewJobUseCase(jobRepo Domain.JobRepository) JobUseCase {
+	return &jobUseCase{jobRepo: jobRepo}
+}
+
+func (uc *jobUseCase) GetJobs(filters Domain.JobFilters) ([]Domain.Job, error) {
+	jobs, err := uc.jobRepo.Find(filters)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []Domain.Job{}
+	}
+	return jobs, nil
+}
+
+func (uc *jobUseCase) GetJob(id string) (*Domain.Job, error) {
+	job, err := uc.jobRepo.FindByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, Domain.NotFoundError("job not found")
+	}
+	return job, nil
+}
+
+func (uc *jobUseCase) RetryJob(id string) (*Domain.Job, error) {
+	job, err := uc.GetJob(id)
+	if err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case Domain.JobStatusRunning:
+		return nil, fmt.Errorf("job is running")
+	case Domain.JobStatusSucceeded:
+		return nil, fmt.Errorf("job already succeeded")
+	}
+
+	retried, err := uc.jobRepo.Retry(id)
+	if err != nil {
+		return nil, err
+	}
+	if retried == nil {
+		// A worker claimed it between the read and the update
+		return nil, fmt.Errorf("job is running")
+	}
+	return retried, nil
+}
+
+func (uc *jobUseCase) GetStats() ([]Domain.JobStats, error) {
+	stats, err := uc.jobRepo.Stats()
+	if err != nil {
+		return nil, err
+	}
+	if stats == nil {
+		stats = []Domain.JobStats{}
+	}
+	return stats, nil
+}
@@ -0,0 +1,304 @@
+package Usecases
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+type LoyaltyUseCase interface {
+	GetProgram(businessID string) (*Domain.LoyaltyProgram, error)
+	UpdateProgram(businessID, userID string, req Domain.UpdateLoyaltyProgramRequest) (*Domain.LoyaltyProgram, error)
+	GetBalance(businessID, phone string) (*Domain.LoyaltyBalance, error)
+	GetAccounts(businessID string, limit, offset int) ([]Domain.LoyaltyAccount, error)
+	GetTransactions(accountID, businessID string, limit int) ([]Domain.LoyaltyTransaction, error)
+	AdjustPoints(accountID, businessID, userID string, req Domain.AdjustLoyaltyPointsRequest) (*Domain.LoyaltyAccount, error)
+	GetReport(businessID string, startDate, endDate time.Time) (*Domain.LoyaltyReport, error)
+}
+
+type loyaltyUseCase struct {
+	loyaltyRepo  Domain.LoyaltyRepository
+	businessRepo Domain.BusinessRepository
+}
+
+func NewLoyaltyUseCase(
+	loyaltyRepo Domain.LoyaltyRepository,
+	businessRepo Domain.BusinessRepository,
+) LoyaltyUseCase {
+	return &loyaltyUseCase{
+		loyaltyRepo:  loyaltyRepo,
+		businessRepo: businessRepo,
+	}
+}
+
+func (uc *loyaltyUseCase) GetProgram(businessID string) (*Domain.LoyaltyProgram, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	return loadLoyaltyProgram(uc.loyaltyRepo, businessID)
+}
+
+func (uc *loyaltyUseCase) UpdateProgram(businessID, userID string, req Domain.UpdateLoyaltyProgramRequest) (*Domain.LoyaltyProgram, error) {
+	program, err := uc.GetProgram(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	if req.Enabled != nil {
+		program.Enabled = *req.Enabled
+	}
+	if req.PointsPerUnit != nil {
+		if *req.PointsPerUnit < 0 {
+			return nil, fmt.Errorf("points per unit cannot be negative")
+		}
+		program.PointsPerUnit = *req.PointsPerUnit
+	}
+	if req.PointValue != nil {
+		if *req.PointValue <= 0 {
+			return nil, fmt.Errorf("point value must be greater than 0")
+		}
+		program.PointValue = *req.PointValue
+	}
+	if req.MinPurchase != nil {
+		if *req.MinPurchase < 0 {
+			return nil, fmt.Errorf("minimum purchase cannot be negative")
+		}
+		program.MinPurchase = *req.MinPurchase
+	}
+	if req.MinRedeemPoints != nil {
+		if *req.MinRedeemPoints < 0 {
+			return nil, fmt.Errorf("minimum redeem points cannot be negative")
+		}
+		program.MinRedeemPoints = *req.MinRedeemPoints
+	}
+	if req.Rules != nil {
+		for _, rule := range req.Rules {
+			if rule.Name == "" {
+				return nil, fmt.Errorf("loyalty rule name is required")
+			}
+			if rule.Multiplier < 0 || rule.BonusPoints < 0 {
+				return nil, fmt.Errorf("loyalty rule %s cannot have negative values", rule.Name)
+			}
+		}
+		program.Rules = req.Rules
+	}
+
+	program.UpdatedBy = objUserID
+
+	if err := uc.loyaltyRepo.SaveProgram(program); err != nil {
+		return nil, fmt.Errorf("failed to update loyalty program: %w", err)
+	}
+
+	return program, nil
+}
+
+func (uc *loyaltyUseCase) GetBalance(businessID, phone string) (*Domain.LoyaltyBalance, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	phone = normalizePhone(phone, business.Country)
+	if phone == "" {
+		return nil, fmt.Errorf("phone is required")
+	}
+
+	program, err := loadLoyaltyProgram(uc.loyaltyRepo, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	account, err := uc.loyaltyRepo.FindAccountByPhone(businessID, phone)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find loyalty account: %w", err)
+	}
+
+	// Customers who haven't earned yet simply have a zero balance
+	balance := &Domain.LoyaltyBalance{CustomerPhone: phone}
+	if account != nil {
+		balance.CustomerName = account.CustomerName
+		balance.Points = account.Points
+		balance.Value = Domain.NewMoney(account.Points * program.PointValue)
+	}
+
+	return balance, nil
+}
+
+func (uc *loyaltyUseCase) GetAccounts(businessID string, limit, offset int) ([]Domain.LoyaltyAccount, error) {
+	return uc.loyaltyRepo.FindAccounts(businessID, limit, offset)
+}
+
+func (uc *loyaltyUseCase) GetTransactions(accountID, businessID string, limit int) ([]Domain.LoyaltyTransaction, error) {
+	// Verify account belongs to business
+	if _, err := uc.getAccount(accountID, businessID); err != nil {
+		return nil, err
+	}
+
+	return uc.loyaltyRepo.GetTransactions(accountID, limit)
+}
+
+func (uc *loyaltyUseCase) AdjustPoints(accountID, businessID, userID string, req Domain.AdjustLoyaltyPointsRequest) (*Domain.LoyaltyAccount, error) {
+	account, err := uc.getAccount(accountID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Points == 0 {
+		return nil, fmt.Errorf("points cannot be zero")
+	}
+	if req.Note == "" {
+		return nil, fmt.Errorf("note is required for manual adjustments")
+	}
+
+	program, err := loadLoyaltyProgram(uc.loyaltyRepo, businessID)
+	if err != nil {
+		return nil, err
+	}
+	value := Domain.NewMoney(math.Abs(req.Points) * program.PointValue)
+
+	if req.Points > 0 {
+		return uc.loyaltyRepo.AddPoints(businessID, account.CustomerPhone, "", req.Points, value,
+			Domain.LoyaltyTransactionAdjust, nil, userID, req.Note)
+	}
+
+	return uc.loyaltyRepo.DeductPoints(accountID, -req.Points, value,
+		Domain.LoyaltyTransactionAdjust, nil, userID, req.Note)
+}
+
+func (uc *loyaltyUseCase) GetReport(businessID string, startDate, endDate time.Time) (*Domain.LoyaltyReport, error) {
+	program, err := uc.GetProgram(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	activity, err := uc.loyaltyRepo.GetActivity(businessID, startDate, endDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loyalty activity: %w", err)
+	}
+
+	report := &Domain.LoyaltyReport{
+		Period: fmt.Sprintf("%s to %s", startDate.Format("2006-01-02"), endDate.Format("2006-01-02")),
+	}
+
+	// Redemptions and reversals are stored as negative movements
+	for _, a := range activity {
+		switch a.Type {
+		case Domain.LoyaltyTransactionEarn:
+			report.PointsAccrued = a.Points
+			report.AccruedValue = a.Value
+		case Domain.LoyaltyTransactionRedeem:
+			report.PointsRedeemed = -a.Points
+			report.RedeemedValue = -a.Value
+		case Domain.LoyaltyTransactionAdjust:
+			report.PointsAdjusted = a.Points
+		case Domain.LoyaltyTransactionReverse:
+			report.PointsReversed = a.Points
+		}
+	}
+
+	outstanding, members, err := uc.loyaltyRepo.GetOutstanding(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get outstanding points: %w", err)
+	}
+	report.OutstandingPoints = outstanding
+	report.OutstandingValue = Domain.NewMoney(outstanding * program.PointValue)
+	report.ActiveMembers = members
+
+	if report.PointsAccrued > 0 {
+		report.RedemptionRate = report.PointsRedeemed / report.PointsAccrued * 100
+	}
+
+	return report, nil
+}
+
+func (uc *loyaltyUseCase) getAccount(accountID, businessID string) (*Domain.LoyaltyAccount, error) {
+	account, err := uc.loyaltyRepo.FindAccountByID(accountID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find loyalty account: %w", err)
+	}
+	if account == nil {
+		return nil, Domain.NotFoundError("loyalty account not found")
+	}
+
+	if account.BusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("access denied: loyalty account does not belong to this business")
+	}
+
+	return account, nil
+}
+
+// loadLoyaltyProgram returns the stored program or a disabled default so
+// callers never have to special-case businesses that haven't configured one
+func loadLoyaltyProgram(repo Domain.LoyaltyRepository, businessID string) (*Domain.LoyaltyProgram, error) {
+	program, err := repo.GetProgram(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find loyalty program: %w", err)
+	}
+	if program != nil {
+		return program, nil
+	}
+
+	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	return &Domain.LoyaltyProgram{
+		BusinessID:    objBusinessID,
+		Enabled:       false,
+		PointsPerUnit: 1,
+		PointValue:    0.01,
+	}, nil
+}
+
+// calculateLoyaltyPoints applies the program's base rate and any matching rules
+// to the amount a customer paid for with money (not points)
+func calculateLoyaltyPoints(program *Domain.LoyaltyProgram, amount Domain.Money, productID, category string) float64 {
+	if !program.Enabled || amount <= 0 || amount < program.MinPurchase {
+		return 0
+	}
+
+	base := amount.Float64() * program.PointsPerUnit
+	points := base
+
+	for _, rule := range program.Rules {
+		if !rule.Active || amount < rule.MinAmount {
+			continue
+		}
+		if rule.ProductID != "" && rule.ProductID != productID {
+			continue
+		}
+		if rule.Category != "" && !strings.EqualFold(rule.Category, category) {
+			continue
+		}
+
+		if rule.Multiplier > 0 {
+			points += base * (rule.Multiplier - 1)
+		}
+		points += rule.BonusPoints
+	}
+
+	return math.Floor(points)
+}
+
+func roundCurrency(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
@@ -0,0 +1,111 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MaintenanceUseCase turns read-only maintenance mode on and off
+type MaintenanceUseCase interface {
+	GetState() (*Domain.MaintenanceState, error)
+	SetState(userID string, req Domain.SetMaintenanceRequest) (*Domain.MaintenanceState, error)
+	// CurrentMaintenance is the state as last read, for checking on every request
+	CurrentMaintenance() Domain.MaintenanceState
+}
+
+type maintenanceUseCase struct {
+	maintenanceRepo Domain.MaintenanceRepository
+	announcer       MaintenanceAnnouncer
+	cache           *Infrastructure.TTLCache
+}
+
+// Every instance reads the switch at most this often, so it takes effect everywhere
+// within this long
+const maintenanceCacheTTL = 5 * time.Second
+
+const maintenanceCacheKey = "state"
+
+func NewMaintenanceUseCase(maintenanceRepo Domain.MaintenanceRepository, announcer MaintenanceAnnouncer) MaintenanceUseCase {
+	return &maintenanceUseCase{
+		maintenanceRepo: maintenanceRepo,
+		announcer:       announcer,
+		cache:           Infrastructure.NewTTLCache(maintenanceCacheTTL),
+	}
+}
+
+func (uc *maintenanceUseCase) GetState() (*Domain.MaintenanceState, error) {
+	return uc.maintenanceRepo.Get()
+}
+
+func (uc *maintenanceUseCase) SetState(userID string, req Domain.SetMaintenanceRequest) (*Domain.MaintenanceState, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	current, err := uc.maintenanceRepo.Get()
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	state := &Domain.MaintenanceState{
+		Enabled:   req.Enabled,
+		Message:   req.Message,
+		UpdatedBy: &objUserID,
+	}
+	if req.Enabled {
+		state.StartedAt = &now
+		// Extending a maintenance window keeps its original start
+		if current.Enabled && current.StartedAt != nil {
+			state.StartedAt = current.StartedAt
+		}
+		if req.ExpectedMinutes > 0 {
+			endsAt := now.Add(time.Duration(req.ExpectedMinutes) * time.Minute)
+			state.EndsAt = &endsAt
+		}
+	}
+
+	if err := uc.maintenanceRepo.Save(state); err != nil {
+		return nil, err
+	}
+
+	uc.cache.Set(maintenanceCacheKey, *state)
+
+	// Devices hear of maintenance starting, its end moving and it being over;
+	// saving the same state again stays quiet
+	if state.Enabled != current.Enabled || (state.Enabled && !sameTime(state.EndsAt, current.EndsAt)) {
+		if err := uc.announcer.AnnounceMaintenance(context.Background(), *state); err != nil {
+			Infrastructure.Logger.Warn("failed to announce maintenance", "error", err)
+		}
+	}
+	return state, nil
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func (uc *maintenanceUseCase) CurrentMaintenance() Domain.MaintenanceState {
+	if cached, _, ok := uc.cache.Get(maintenanceCacheKey); ok {
+		return cached.(Domain.MaintenanceState)
+	}
+
+	state, err := uc.maintenanceRepo.Get()
+	if err != nil {
+		// The database is what maintenance protects; if it cannot be read, writes fail anyway
+		Infrastructure.Logger.Warn("failed to read maintenance state", "error", err)
+		return Domain.MaintenanceState{}
+	}
+	uc.cache.Set(maintenanceCacheKey, *state)
+	return *state
+}
@@ -0,0 +1,444 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// MobilePaymentUseCase collects sale payments from customers' mobile wallets. A
+// payment stays pending until the provider reports back, by callback or when the
+// periodic check asks; the sale is marked paid only once the money has arrived.
+type MobilePaymentUseCase interface {
+	// Providers lists the wallets this server can take payments from
+	Providers() []Domain.MobileMoneyProvider
+	Initiate(ctx context.Context, businessID, userID string, req Domain.InitiateMobilePaymentRequest) (*Domain.MobilePayment, error)
+	GetPayment(ctx context.Context, id, businessID string) (*Domain.MobilePayment, error)
+	GetSalePayments(ctx context.Context, saleID, businessID string) ([]Domain.MobilePayment, error)
+	// Reverse sends a completed payment back to the customer
+	Reverse(ctx context.Context, id, businessID string, req Domain.ReverseMobilePaymentRequest) (*Domain.MobilePayment, error)
+	HandleCallback(ctx context.Context, provider Domain.MobileMoneyProvider, body []byte, header http.Header) error
+	// CheckPending asks providers about payments no callback has settled, and gives
+	// up on those the customer did not approve in time
+	CheckPending() error
+}
+
+type mobilePaymentUseCase struct {
+	paymentRepo     Domain.MobilePaymentRepository
+	salesRepo       Domain.SaleRepository
+	businessRepo    Domain.BusinessRepository
+	gateways        Infrastructure.MobileMoneyGateways
+	callbackBaseURL string
+}
+
+// Pending payments are asked about once callbacks have had this long to arrive
+const mobilePaymentCheckAfter = 30 * time.Second
+
+func NewMobilePaymentUseCase(
+	paymentRepo Domain.MobilePaymentRepository,
+	salesRepo Domain.SaleRepository,
+	businessRepo Domain.BusinessRepository,
+	gateways Infrastructure.MobileMoneyGateways,
+	callbackBaseURL string,
+) MobilePaymentUseCase {
+	return &mobilePaymentUseCase{
+		paymentRepo:     paymentRepo,
+		salesRepo:       salesRepo,
+		businessRepo:    businessRepo,
+		gateways:        gateways,
+		callbackBaseURL: callbackBaseURL,
+	}
+}
+
+func (uc *mobilePaymentUseCase) Providers() []Domain.MobileMoneyProvider {
+	providers := make([]Domain.MobileMoneyProvider, 0, len(uc.gateways))
+	for provider := range uc.gateways {
+		providers = append(providers, provider)
+	}
+	sort.Slice(providers, func(i, j int) bool { return providers[i] < providers[j] })
+	return providers
+}
+
+func (uc *mobilePaymentUseCase) Initiate(ctx context.Context, businessID, userID string, req Domain.InitiateMobilePaymentRequest) (*Domain.MobilePayment, error) {
+	gateway, ok := uc.gateways[req.Provider]
+	if !ok {
+		return nil, Domain.ValidationError(fmt.Sprintf("%s payments are not available", req.Provider))
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	sale, err := uc.salesRepo.FindInBusiness(ctx, req.SaleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sale: %w", err)
+	}
+	if sale == nil {
+		return nil, Domain.NotFoundError("sale not found")
+	}
+	if sale.Status != Domain.SaleStatusCompleted {
+		return nil, Domain.ValidationError(fmt.Sprintf("cannot take payment for a %s sale", sale.Status))
+	}
+	if sale.PaymentStatus == Domain.PaymentStatusPaid {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "sale is already paid")
+	}
+
+	existing, err := uc.paymentRepo.FindBySale(req.SaleID)
+	if err != nil {
+		return nil, err
+	}
+	for _, payment := range existing {
+		if payment.Status == Domain.MobilePaymentPending && time.Now().Before(payment.ExpiresAt) {
+			return nil, Domain.NewAppError(Domain.ErrCodeConflict, "a payment for this sale is already waiting for the customer")
+		}
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	currency := strings.ToUpper(business.Currency)
+	if currency == "" {
+		currency = "ETB"
+	}
+
+	payment := &Domain.MobilePayment{
+		ID:         primitive.NewObjectID(),
+		BusinessID: sale.BusinessID,
+		SaleID:     sale.ID,
+		Provider:   req.Provider,
+		Phone:      normalizePhone(req.Phone, business.Country),
+		Amount:     sale.FinalAmount + sale.DepositAmount,
+		Currency:   currency,
+		Status:     Domain.MobilePaymentPending,
+		ExpiresAt:  time.Now().Add(Domain.MobilePaymentTimeout),
+		CreatedBy:  objUserID,
+	}
+	if err := uc.paymentRepo.Create(payment); err != nil {
+		return nil, err
+	}
+	if sale.PaymentStatus != Domain.PaymentStatusPending {
+		if err := uc.salesRepo.SetPayment(sale.ID, Domain.PaymentStatusPending, sale.Payments); err != nil {
+			return nil, err
+		}
+	}
+
+	result, err := gateway.Initiate(ctx, Infrastructure.MobileMoneyCharge{
+		Reference:   payment.ID.Hex(),
+		Phone:       strings.TrimPrefix(payment.Phone, "+"),
+		Amount:      payment.Amount,
+		Currency:    payment.Currency,
+		Description: fmt.Sprintf("%s sale", business.Name),
+		CallbackURL: Infrastructure.MobileMoneyCallbackURL(uc.callbackBaseURL, req.Provider),
+	})
+	if err != nil {
+		uc.fail(ctx, payment, Domain.MobilePaymentPending, err.Error())
+		return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, fmt.Sprintf("%s could not start the payment: %v", req.Provider, err))
+	}
+
+	payment.ProviderRef = result.ProviderRef
+	payment.CheckoutURL = result.CheckoutURL
+	if err := uc.paymentRepo.SetProviderRef(payment.ID, payment.ProviderRef, payment.CheckoutURL); err != nil {
+		return nil, err
+	}
+
+	return payment, nil
+}
+
+func (uc *mobilePaymentUseCase) GetPayment(ctx context.Context, id, businessID string) (*Domain.MobilePayment, error) {
+	payment, err := uc.paymentRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if payment == nil {
+		return nil, Domain.NotFoundError("payment not found")
+	}
+	return payment, nil
+}
+
+func (uc *mobilePaymentUseCase) GetSalePayments(ctx context.Context, saleID, businessID string) ([]Domain.MobilePayment, error) {
+	sale, err := uc.salesRepo.FindInBusiness(ctx, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sale: %w", err)
+	}
+	if sale == nil {
+		return nil, Domain.NotFoundError("sale not found")
+	}
+	return uc.paymentRepo.FindBySale(saleID)
+}
+
+func (uc *mobilePaymentUseCase) Reverse(ctx context.Context, id, businessID string, req Domain.ReverseMobilePaymentRequest) (*Domain.MobilePayment, error) {
+	payment, err := uc.GetPayment(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if payment.Status != Domain.MobilePaymentSucceeded && payment.Status != Domain.MobilePaymentNeedsReview {
+		return nil, Domain.ValidationError(fmt.Sprintf("cannot reverse a %s payment", payment.Status))
+	}
+	gateway, ok := uc.gateways[payment.Provider]
+	if !ok {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, fmt.Sprintf("%s payments are not available", payment.Provider))
+	}
+
+	reason := req.Reason
+	if reason == "" {
+		reason = "Refund"
+	}
+	from := payment.Status
+	if err := uc.reverse(ctx, gateway, payment, from, reason); err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeUnavailable, fmt.Sprintf("%s could not reverse the payment: %v", payment.Provider, err))
+	}
+
+	// Only money that was applied to the sale comes off it
+	if from == Domain.MobilePaymentSucceeded {
+		sale, err := uc.salesRepo.FindInBusiness(ctx, payment.SaleID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to find sale: %w", err)
+		}
+		if sale != nil {
+			payments := make([]Domain.SalePayment, 0, len(sale.Payments))
+			for _, p := range sale.Payments {
+				if p.Method != Domain.PaymentMethodMobile || p.Reference != payment.TransactionID {
+					payments = append(payments, p)
+				}
+			}
+			if err := uc.salesRepo.SetPayment(sale.ID, Domain.PaymentStatusPending, payments); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return payment, nil
+}
+
+func (uc *mobilePaymentUseCase) HandleCallback(ctx context.Context, provider Domain.MobileMoneyProvider, body []byte, header http.Header) error {
+	gateway, ok := uc.gateways[provider]
+	if !ok {
+		return Domain.NotFoundError("unknown payment provider")
+	}
+
+	result, err := gateway.ParseCallback(body, header)
+	if err != nil {
+		return err
+	}
+	if result == nil {
+		return nil
+	}
+
+	var payment *Domain.MobilePayment
+	if primitive.IsValidObjectID(result.Reference) {
+		payment, err = uc.paymentRepo.FindByID(result.Reference)
+	} else if result.ProviderRef != "" {
+		payment, err = uc.paymentRepo.FindByProviderRef(provider, result.ProviderRef)
+	}
+	if err != nil {
+		return err
+	}
+	if payment == nil || payment.Provider != provider {
+		// Nothing to apply it to; answering with an error would only make the provider retry
+		Infrastructure.LoggerFrom(ctx).Warn("callback for unknown payment", "provider", provider, "reference", result.Reference, "provider_ref", result.ProviderRef)
+		return nil
+	}
+
+	// The provider names no shop; the payment does
+	ctx = Infrastructure.WithBusiness(ctx, payment.BusinessID.Hex())
+	return uc.apply(ctx, gateway, payment, result)
+}
+
+func (uc *mobilePaymentUseCase) CheckPending() error {
+	payments, err := uc.paymentRepo.FindStale(time.Now().Add(-mobilePaymentCheckAfter), 100)
+	if err != nil {
+		return err
+	}
+
+	for i := range payments {
+		payment := &payments[i]
+		ctx := Infrastructure.WithBusiness(context.Background(), payment.BusinessID.Hex())
+
+		if gateway, ok := uc.gateways[payment.Provider]; ok && payment.ProviderRef != "" {
+			result, err := gateway.Query(ctx, payment)
+			if err != nil {
+				Infrastructure.Logger.Warn("failed to check payment", "provider", payment.Provider, "payment_id", payment.ID.Hex(), "error", err)
+			} else if result.Status != Domain.MobilePaymentPending {
+				if err := uc.apply(ctx, gateway, payment, result); err != nil {
+					Infrastructure.Logger.Warn("failed to settle payment", "provider", payment.Provider, "payment_id", payment.ID.Hex(), "error", err)
+				}
+				continue
+			}
+		}
+
+		if time.Now().After(payment.ExpiresAt) {
+			// A late approval is still taken if the sale is unpaid by then, and sent back if not
+			payment.Status = Domain.MobilePaymentExpired
+			payment.FailureReason = "the customer did not approve the payment in time"
+			if ok, err := uc.paymentRepo.Transition(payment, Domain.MobilePaymentPending); err != nil {
+				Infrastructure.Logger.Warn("failed to expire payment", "payment_id", payment.ID.Hex(), "error", err)
+			} else if ok {
+				Infrastructure.MobileMoneyPayments.Inc(string(payment.Provider), string(payment.Status))
+				uc.markSaleUnpaid(ctx, payment)
+			}
+		}
+	}
+
+	return nil
+}
+
+// apply records a provider's outcome for a payment. Unsigned outcomes are checked
+// with the provider first.
+func (uc *mobilePaymentUseCase) apply(ctx context.Context, gateway Infrastructure.MobileMoneyGateway, payment *Domain.MobilePayment, result *Infrastructure.MobileMoneyResult) error {
+	if !result.Verified && result.Status != Domain.MobilePaymentPending {
+		confirmed, err := gateway.Query(ctx, payment)
+		if err != nil {
+			return fmt.Errorf("failed to confirm %s payment: %w", payment.Provider, err)
+		}
+		if result.TransactionID != "" && confirmed.TransactionID == "" {
+			confirmed.TransactionID = result.TransactionID
+		}
+		if result.Amount > 0 && confirmed.Amount == 0 {
+			confirmed.Amount = result.Amount
+		}
+		result = confirmed
+	}
+
+	switch result.Status {
+	case Domain.MobilePaymentSucceeded:
+		// Expired and failed payments can still be approved late
+		if payment.Status != Domain.MobilePaymentPending && payment.Status != Domain.MobilePaymentExpired && payment.Status != Domain.MobilePaymentFailed {
+			return nil
+		}
+		return uc.settle(ctx, gateway, payment, result)
+	case Domain.MobilePaymentFailed:
+		if payment.Status != Domain.MobilePaymentPending {
+			return nil
+		}
+		reason := result.Reason
+		if reason == "" {
+			reason = "declined"
+		}
+		uc.fail(ctx, payment, Domain.MobilePaymentPending, reason)
+	}
+	return nil
+}
+
+// settle applies money that arrived to the sale, or sends it back when the sale
+// cannot take it
+func (uc *mobilePaymentUseCase) settle(ctx context.Context, gateway Infrastructure.MobileMoneyGateway, payment *Domain.MobilePayment, result *Infrastructure.MobileMoneyResult) error {
+	from := payment.Status
+	now := time.Now()
+	payment.CompletedAt = &now
+	if result.TransactionID != "" {
+		payment.TransactionID = result.TransactionID
+	}
+
+	sale, err := uc.salesRepo.FindInBusiness(ctx, payment.SaleID.Hex())
+	if err != nil {
+		return fmt.Errorf("failed to find sale: %w", err)
+	}
+
+	var problem string
+	switch {
+	case sale == nil || sale.Status != Domain.SaleStatusCompleted:
+		problem = "the sale was voided"
+	case result.Amount > 0 && result.Amount != payment.Amount:
+		problem = fmt.Sprintf("the customer paid %s, not %s", result.Amount, payment.Amount)
+	case sale.PaymentStatus == Domain.PaymentStatusPaid:
+		problem = "the sale was already paid"
+	}
+
+	if problem != "" {
+		// Claim the payment before sending it back, so it is returned only once
+		payment.Status = Domain.MobilePaymentNeedsReview
+		payment.FailureReason = problem
+		ok, err := uc.paymentRepo.Transition(payment, from)
+		if err != nil || !ok {
+			return err
+		}
+		Infrastructure.MobileMoneyPayments.Inc(string(payment.Provider), string(payment.Status))
+		if err := uc.reverse(ctx, gateway, payment, Domain.MobilePaymentNeedsReview, problem); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("payment needs review, reversal failed", "payment_id", payment.ID.Hex(), "error", err)
+		}
+		return nil
+	}
+
+	payment.Status = Domain.MobilePaymentSucceeded
+	payment.FailureReason = ""
+	ok, err := uc.paymentRepo.Transition(payment, from)
+	if err != nil || !ok {
+		return err
+	}
+	Infrastructure.MobileMoneyPayments.Inc(string(payment.Provider), string(payment.Status))
+
+	payments := append(sale.Payments, Domain.SalePayment{
+		Method:    Domain.PaymentMethodMobile,
+		Amount:    payment.Amount,
+		Reference: payment.TransactionID,
+	})
+	return uc.salesRepo.SetPayment(sale.ID, Domain.PaymentStatusPaid, payments)
+}
+
+// reverse sends a payment back through its provider and records it. A payment
+// already being reversed by someone else is left alone.
+func (uc *mobilePaymentUseCase) reverse(ctx context.Context, gateway Infrastructure.MobileMoneyGateway, payment *Domain.MobilePayment, from Domain.MobilePaymentStatus, reason string) error {
+	if err := gateway.Reverse(ctx, payment, reason); err != nil {
+		return err
+	}
+
+	now := time.Now()
+	payment.Status = Domain.MobilePaymentReversed
+	payment.ReversedAt = &now
+	if payment.FailureReason == "" {
+		payment.FailureReason = reason
+	}
+	ok, err := uc.paymentRepo.Transition(payment, from)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return errors.New("payment changed while it was being reversed")
+	}
+	Infrastructure.MobileMoneyPayments.Inc(string(payment.Provider), string(payment.Status))
+	return nil
+}
+
+// fail records a payment that did not go through, leaving the sale unpaid
+func (uc *mobilePaymentUseCase) fail(ctx context.Context, payment *Domain.MobilePayment, from Domain.MobilePaymentStatus, reason string) {
+	now := time.Now()
+	payment.Status = Domain.MobilePaymentFailed
+	payment.FailureReason = reason
+	payment.CompletedAt = &now
+
+	ok, err := uc.paymentRepo.Transition(payment, from)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to record failed payment", "payment_id", payment.ID.Hex(), "error", err)
+		return
+	}
+	if ok {
+		Infrastructure.MobileMoneyPayments.Inc(string(payment.Provider), string(payment.Status))
+		uc.markSaleUnpaid(ctx, payment)
+	}
+}
+
+// markSaleUnpaid shows the sale as failed so the cashier can try again or take cash
+func (uc *mobilePaymentUseCase) markSaleUnpaid(ctx context.Context, payment *Domain.MobilePayment) {
+	sale, err := uc.salesRepo.FindInBusiness(ctx, payment.SaleID.Hex())
+	if err != nil || sale == nil || sale.PaymentStatus != Domain.PaymentStatusPending {
+		return
+	}
+	if err := uc.salesRepo.SetPayment(sale.ID, Domain.PaymentStatusFailed, sale.Payments); err != nil {
+		Infrastructure.Logger.Warn("failed to mark sale unpaid", "sale_id", sale.ID.Hex(), "error", err)
+	}
+}
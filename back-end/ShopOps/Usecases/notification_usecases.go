@@ -0,0 +1,261 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultNotificationLimit = 50
+	maxNotificationLimit     = 200
+)
+
+// NotificationUseCase keeps each user's in-app notification center and their
+// choice of channels for every kind of notification. The push, email and SMS
+// senders ask Allows before sending; Notify files an in-app notification.
+type NotificationUseCase interface {
+	// Publish files shop events in the owner's notification center
+	EventPublisher
+	SecurityAlerter
+
+	GetNotifications(userID string, filters Domain.NotificationFilters) ([]Domain.Notification, error)
+	// MarkRead returns how many notifications were marked
+	MarkRead(userID string, req Domain.MarkNotificationsReadRequest) (int64, error)
+	GetBadge(userID string) (*Domain.NotificationBadge, error)
+
+	GetPreferences(userID string) (*Domain.NotificationPreferencesView, error)
+	UpdatePreferences(userID string, req Domain.UpdateNotificationPreferencesRequest) (*Domain.NotificationPreferencesView, error)
+	// Allows reports whether the user wants the event on the channel. When their
+	// preferences cannot be read it falls back to the defaults.
+	Allows(userID string, event Domain.NotificationEvent, channel Domain.NotificationChannel) bool
+
+	// Notify files a notification in the user's center, unless they turned in-app
+	// notifications of the event off. businessID is empty for account notifications.
+	Notify(userID, businessID string, event Domain.NotificationEvent, title, body string, data map[string]string) error
+}
+
+type notificationUseCase struct {
+	notificationRepo Domain.NotificationRepository
+	businessRepo     Domain.BusinessRepository
+}
+
+func NewNotificationUseCase(
+	notificationRepo Domain.NotificationRepository,
+	businessRepo Domain.BusinessRepository,
+) NotificationUseCase {
+	return &notificationUseCase{
+		notificationRepo: notificationRepo,
+		businessRepo:     businessRepo,
+	}
+}
+
+func (uc *notificationUseCase) GetNotifications(userID string, filters Domain.NotificationFilters) ([]Domain.Notification, error) {
+	if filters.Event != nil && Domain.FindNotificationEvent(*filters.Event) == nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("unknown notification event %q", *filters.Event))
+	}
+	if filters.Limit <= 0 {
+		filters.Limit = defaultNotificationLimit
+	}
+	if filters.Limit > maxNotificationLimit {
+		filters.Limit = maxNotificationLimit
+	}
+
+	notifications, err := uc.notificationRepo.Find(userID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if notifications == nil {
+		notifications = []Domain.Notification{}
+	}
+	return notifications, nil
+}
+
+func (uc *notificationUseCase) MarkRead(userID string, req Domain.MarkNotificationsReadRequest) (int64, error) {
+	if !req.All && len(req.IDs) == 0 {
+		return 0, Domain.NewAppError(Domain.ErrCodeBadRequest, "give the notification ids, or all")
+	}
+	if req.BusinessID != "" {
+		if _, err := primitive.ObjectIDFromHex(req.BusinessID); err != nil {
+			return 0, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "invalid business ID")
+		}
+	}
+
+	var ids []primitive.ObjectID
+	if !req.All {
+		ids = make([]primitive.ObjectID, 0, len(req.IDs))
+		for _, id := range req.IDs {
+			objID, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				return 0, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("invalid notification ID %q", id))
+			}
+			ids = append(ids, objID)
+		}
+	}
+	return uc.notificationRepo.MarkRead(userID, ids, req.BusinessID)
+}
+
+func (uc *notificationUseCase) GetBadge(userID string) (*Domain.NotificationBadge, error) {
+	counts, err := uc.notificationRepo.CountUnread(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	badge := &Domain.NotificationBadge{Shops: map[string]int{}}
+	for businessID, count := range counts {
+		badge.Unread += count
+		if businessID != "" {
+			badge.Shops[businessID] = count
+		}
+	}
+	return badge, nil
+}
+
+func (uc *notificationUseCase) GetPreferences(userID string) (*Domain.NotificationPreferencesView, error) {
+	preferences, err := uc.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	return preferencesView(preferences), nil
+}
+
+func (uc *notificationUseCase) UpdatePreferences(userID string, req Domain.UpdateNotificationPreferencesRequest) (*Domain.NotificationPreferencesView, error) {
+	if err := req.Validate(); err != nil {
+		return nil, err
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	preferences, err := uc.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		return nil, err
+	}
+	if preferences == nil {
+		preferences = &Domain.NotificationPreferences{UserID: objUserID}
+	}
+	if preferences.Channels == nil {
+		preferences.Channels = map[Domain.NotificationEvent][]Domain.NotificationChannel{}
+	}
+	for event, channels := range req.Channels {
+		set := []Domain.NotificationChannel{}
+		for _, channel := range channels {
+			if !containsChannel(set, channel) {
+				set = append(set, channel)
+			}
+		}
+		preferences.Channels[event] = set
+	}
+
+	if err := uc.notificationRepo.SavePreferences(preferences); err != nil {
+		return nil, err
+	}
+	return preferencesView(preferences), nil
+}
+
+func preferencesView(preferences *Domain.NotificationPreferences) *Domain.NotificationPreferencesView {
+	return &Domain.NotificationPreferencesView{
+		Events:   Domain.NotificationEvents,
+		Channels: preferences.Resolved(),
+	}
+}
+
+func containsChannel(channels []Domain.NotificationChannel, channel Domain.NotificationChannel) bool {
+	for _, c := range channels {
+		if c == channel {
+			return true
+		}
+	}
+	return false
+}
+
+func (uc *notificationUseCase) Allows(userID string, event Domain.NotificationEvent, channel Domain.NotificationChannel) bool {
+	preferences, err := uc.notificationRepo.FindPreferences(userID)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to find notification preferences for user", "user_id", userID, "error", err)
+		preferences = nil
+	}
+	return preferences.Allows(event, channel)
+}
+
+func (uc *notificationUseCase) Notify(userID, businessID string, event Domain.NotificationEvent, title, body string, data map[string]string) error {
+	if !uc.Allows(userID, event, Domain.ChannelInApp) {
+		return nil
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+	notification := &Domain.Notification{
+		UserID: objUserID,
+		Event:  event,
+		Title:  title,
+		Body:   body,
+		Data:   data,
+	}
+	if businessID != "" {
+		objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+		if err != nil {
+			return fmt.Errorf("invalid business ID: %w", err)
+		}
+		notification.BusinessID = &objBusinessID
+	}
+	return uc.notificationRepo.Create(notification)
+}
+
+// Publish tells the shop's owner about failed syncs and backups and products
+// running low
+func (uc *notificationUseCase) Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error {
+	switch event {
+	case Domain.WebhookEventSyncFailed, Domain.WebhookEventBackupFailed, Domain.WebhookEventStockLow:
+	default:
+		return nil
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil || business == nil {
+		return err
+	}
+	lang := business.Language
+
+	var kind Domain.NotificationEvent
+	var title, body string
+	var screen map[string]string
+	switch payload := data.(type) {
+	case Domain.SyncFailedEvent:
+		kind = Domain.NotificationSyncFailure
+		title = Infrastructure.Translate(lang, "push.sync_problem", business.Name)
+		body = Infrastructure.Translate(lang, "push.sync_problem_body", payload.Failed, payload.Total)
+		screen = map[string]string{"screen": "sync", "device_id": payload.DeviceID}
+	case Domain.BackupFailedEvent:
+		kind = Domain.NotificationBackupFailed
+		title = Infrastructure.Translate(lang, "notify.backup_failed", business.Name)
+		body = Infrastructure.Translate(lang, "notify.backup_failed_body", payload.Attempts, payload.Error)
+		screen = map[string]string{"screen": "backups"}
+	case Domain.StockLowEvent:
+		kind = Domain.NotificationLowStock
+		title = Infrastructure.Translate(lang, "notify.low_stock", business.Name)
+		body = Infrastructure.Translate(lang, "notify.low_stock_body", payload.Name, payload.Stock, payload.MinStock)
+		screen = map[string]string{"screen": "product", "product_id": payload.ProductID}
+	default:
+		return nil
+	}
+
+	if err := uc.Notify(business.UserID.Hex(), businessID, kind, title, body, screen); err != nil {
+		return fmt.Errorf("failed to file %s notification: %w", kind, err)
+	}
+	return nil
+}
+
+func (uc *notificationUseCase) AlertUser(ctx context.Context, userID string, alert Domain.SecurityAlert) {
+	err := uc.Notify(userID, "", Domain.NotificationSecurity, alert.Title, alert.Body, map[string]string{"screen": "security"})
+	if err != nil {
+		Infrastructure.LoggerFrom(ctx).Warn("failed to file security notification for user", "user_id", userID, "error", err)
+	}
+}
@@ -0,0 +1,253 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// OnboardingUseCase walks a new owner through setting up a shop, one step at a
+// time, saving progress after each so the wizard can be resumed
+type OnboardingUseCase interface {
+	GetTemplates() []Domain.ShopTemplate
+	// GetUnfinished returns the user's onboarding still in progress, to resume
+	GetUnfinished(userID string) (*Domain.OnboardingState, error)
+	GetOnboarding(businessID string) (*Domain.OnboardingState, error)
+	// SaveBusiness creates the shop and starts the onboarding, or changes the shop
+	// when the user already has one in progress
+	SaveBusiness(userID string, req Domain.CreateBusinessRequest) (*Domain.OnboardingState, error)
+	// ChooseTemplate applies the template and installs its pack and any others chosen
+	ChooseTemplate(ctx context.Context, businessID, userID string, req Domain.ChooseTemplateRequest) (*Domain.OnboardingState, error)
+	ImportProducts(businessID, userID string, req Domain.ImportProductsRequest) (*Domain.ImportProductsResult, error)
+	RegisterDevice(businessID string, req Domain.RegisterDeviceRequest) (*Domain.OnboardingState, error)
+	SkipStep(businessID string, req Domain.SkipOnboardingStepRequest) (*Domain.OnboardingState, error)
+}
+
+type onboardingUseCase struct {
+	onboardingRepo    Domain.OnboardingRepository
+	businessUC        BusinessUseCase
+	catalogSettingsUC CatalogSettingsUseCase
+	packUC            VerticalPackUseCase
+	inventoryUC       InventoryUseCase
+	billingUC         BillingUseCase
+}
+
+func NewOnboardingUseCase(
+	onboardingRepo Domain.OnboardingRepository,
+	businessUC BusinessUseCase,
+	catalogSettingsUC CatalogSettingsUseCase,
+	packUC VerticalPackUseCase,
+	inventoryUC InventoryUseCase,
+	billingUC BillingUseCase,
+) OnboardingUseCase {
+	return &onboardingUseCase{
+		onboardingRepo:    onboardingRepo,
+		businessUC:        businessUC,
+		catalogSettingsUC: catalogSettingsUC,
+		packUC:            packUC,
+		inventoryUC:       inventoryUC,
+		billingUC:         billingUC,
+	}
+}
+
+func (uc *onboardingUseCase) GetTemplates() []Domain.ShopTemplate {
+	return Domain.ShopTemplates
+}
+
+func (uc *onboardingUseCase) GetUnfinished(userID string) (*Domain.OnboardingState, error) {
+	onboarding, err := uc.onboardingRepo.FindUnfinished(userID)
+	if err != nil {
+		return nil, err
+	}
+	if onboarding == nil {
+		return nil, Domain.NotFoundError("no onboarding in progress")
+	}
+	return uc.state(onboarding)
+}
+
+func (uc *onboardingUseCase) GetOnboarding(businessID string) (*Domain.OnboardingState, error) {
+	onboarding, err := uc.onboardingRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if onboarding == nil {
+		return nil, Domain.NotFoundError("this shop was not set up through onboarding")
+	}
+	return uc.state(onboarding)
+}
+
+func (uc *onboardingUseCase) SaveBusiness(userID string, req Domain.CreateBusinessRequest) (*Domain.OnboardingState, error) {
+	onboarding, err := uc.onboardingRepo.FindUnfinished(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Going back to the first step edits the shop already created
+	if onboarding != nil {
+		business, err := uc.businessUC.UpdateBusiness(onboarding.BusinessID.Hex(), userID, Domain.UpdateBusinessRequest{
+			Name:          req.Name,
+			Description:   req.Description,
+			BusinessType:  req.BusinessType,
+			Currency:      req.Currency,
+			Timezone:      req.Timezone,
+			DayCutoffHour: req.DayCutoffHour,
+			Language:      req.Language,
+			Address:       req.Address,
+			City:          req.City,
+			Country:       req.Country,
+			Phone:         req.Phone,
+			Email:         req.Email,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &Domain.OnboardingState{Onboarding: *onboarding, Business: business}, nil
+	}
+
+	business, err := uc.businessUC.CreateBusiness(userID, req)
+	if err != nil {
+		return nil, err
+	}
+	onboarding = &Domain.Onboarding{
+		UserID:     business.UserID,
+		BusinessID: business.ID,
+		Done:       []Domain.OnboardingStep{},
+		Skipped:    []Domain.OnboardingStep{},
+	}
+	onboarding.MarkDone(Domain.OnboardingStepBusiness, false)
+	if err := uc.onboardingRepo.Create(onboarding); err != nil {
+		return nil, err
+	}
+	return &Domain.OnboardingState{Onboarding: *onboarding, Business: business}, nil
+}
+
+func (uc *onboardingUseCase) ChooseTemplate(ctx context.Context, businessID, userID string, req Domain.ChooseTemplateRequest) (*Domain.OnboardingState, error) {
+	template := Domain.FindShopTemplate(req.Template)
+	if template == nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("unknown template %q", req.Template))
+	}
+	packs := req.Packs
+	if template.Pack != "" {
+		packs = mergeNames([]string{template.Pack}, packs)
+	}
+	for _, code := range packs {
+		if Domain.FindVerticalPack(code) == nil {
+			return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("unknown pack %q", code))
+		}
+	}
+
+	onboarding, err := uc.inProgress(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := uc.catalogSettingsUC.ApplyTemplate(businessID, *template); err != nil {
+		return nil, err
+	}
+	// Installing again is safe, so choosing another template can repeat a pack
+	for _, code := range packs {
+		if _, err := uc.packUC.InstallPack(ctx, businessID, userID, code); err != nil {
+			return nil, err
+		}
+	}
+
+	onboarding.Template = template.Code
+	onboarding.Packs = mergeNames(onboarding.Packs, packs)
+	return uc.advance(onboarding, Domain.OnboardingStepTemplate, false)
+}
+
+func (uc *onboardingUseCase) ImportProducts(businessID, userID string, req Domain.ImportProductsRequest) (*Domain.ImportProductsResult, error) {
+	onboarding, err := uc.inProgress(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Each product stands alone, so one bad row does not hold back the rest
+	result := &Domain.ImportProductsResult{Failed: []Domain.ImportProductError{}}
+	for i, product := range req.Products {
+		if _, err := uc.inventoryUC.CreateProduct(businessID, userID, product); err != nil {
+			result.Failed = append(result.Failed, Domain.ImportProductError{Row: i, Name: product.Name, Error: err.Error()})
+			continue
+		}
+		result.Imported++
+	}
+
+	onboarding.ProductsImported += result.Imported
+	if result.Imported == 0 {
+		// Nothing went in; stay on the step so the rows can be fixed
+		result.Onboarding = *onboarding
+		return result, nil
+	}
+	state, err := uc.advance(onboarding, Domain.OnboardingStepProducts, false)
+	if err != nil {
+		return nil, err
+	}
+	result.Onboarding = state.Onboarding
+	return result, nil
+}
+
+func (uc *onboardingUseCase) RegisterDevice(businessID string, req Domain.RegisterDeviceRequest) (*Domain.OnboardingState, error) {
+	onboarding, err := uc.inProgress(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.billingUC.CheckDevice(businessID, req.DeviceID); err != nil {
+		return nil, err
+	}
+
+	onboarding.DeviceID = req.DeviceID
+	return uc.advance(onboarding, Domain.OnboardingStepDevice, false)
+}
+
+func (uc *onboardingUseCase) SkipStep(businessID string, req Domain.SkipOnboardingStepRequest) (*Domain.OnboardingState, error) {
+	if !req.Step.Skippable() {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("the %s step cannot be skipped", req.Step))
+	}
+
+	onboarding, err := uc.inProgress(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if onboarding.IsDone(req.Step) {
+		return uc.state(onboarding)
+	}
+	return uc.advance(onboarding, req.Step, true)
+}
+
+// inProgress returns the shop's onboarding, refusing steps once it is finished;
+// from then on the shop's settings are changed in the usual places
+func (uc *onboardingUseCase) inProgress(businessID string) (*Domain.Onboarding, error) {
+	onboarding, err := uc.onboardingRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if onboarding == nil {
+		return nil, Domain.NotFoundError("this shop was not set up through onboarding")
+	}
+	if onboarding.CompletedAt != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "onboarding is already finished")
+	}
+	return onboarding, nil
+}
+
+// advance records the step and saves the onboarding, finishing it after the last
+func (uc *onboardingUseCase) advance(onboarding *Domain.Onboarding, step Domain.OnboardingStep, skipped bool) (*Domain.OnboardingState, error) {
+	onboarding.MarkDone(step, skipped)
+	if onboarding.NextStep == Domain.OnboardingStepFinished {
+		now := time.Now()
+		onboarding.CompletedAt = &now
+	}
+	if err := uc.onboardingRepo.Update(onboarding); err != nil {
+		return nil, err
+	}
+	return uc.state(onboarding)
+}
+
+func (uc *onboardingUseCase) state(onboarding *Domain.Onboarding) (*Domain.OnboardingState, error) {
+	business, err := uc.businessUC.GetBusinessByID(onboarding.BusinessID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	return &Domain.OnboardingState{Onboarding: *onboarding, Business: business}, nil
+}
@@ -0,0 +1,212 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type PricingUseCase interface {
+	SetCustomerPrice(ctx context.Context, customerID, businessID, userID string, req Domain.SetCustomerPriceRequest) (*Domain.CustomerPrice, error)
+	GetCustomerPrices(ctx context.Context, customerID, businessID string) ([]Domain.CustomerPrice, error)
+	DeleteCustomerPrice(ctx context.Context, customerID, productID, businessID string) error
+	QuotePrice(ctx context.Context, businessID, productID, customerID, customerPhone string, quantity float64) (*Domain.PriceQuote, error)
+}
+
+type pricingUseCase struct {
+	priceRepo     Domain.CustomerPriceRepository
+	customerRepo  Domain.CustomerRepository
+	inventoryRepo Domain.ProductRepository
+	businessRepo  Domain.BusinessRepository
+	catalog       *Infrastructure.CatalogCache
+}
+
+func NewPricingUseCase(
+	priceRepo Domain.CustomerPriceRepository,
+	customerRepo Domain.CustomerRepository,
+	inventoryRepo Domain.ProductRepository,
+	businessRepo Domain.BusinessRepository,
+	catalog *Infrastructure.CatalogCache,
+) PricingUseCase {
+	return &pricingUseCase{
+		priceRepo:     priceRepo,
+		customerRepo:  customerRepo,
+		inventoryRepo: inventoryRepo,
+		businessRepo:  businessRepo,
+		catalog:       catalog,
+	}
+}
+
+func (uc *pricingUseCase) SetCustomerPrice(ctx context.Context, customerID, businessID, userID string, req Domain.SetCustomerPriceRequest) (*Domain.CustomerPrice, error) {
+	customer, err := uc.findCustomer(ctx, customerID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if customer.Status != Domain.CustomerStatusActive {
+		return nil, fmt.Errorf("cannot set prices for customer with status: %s", customer.Status)
+	}
+
+	product, err := uc.findProduct(ctx, req.ProductID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Price <= 0 {
+		return nil, fmt.Errorf("price must be greater than zero")
+	}
+	if req.Price <= product.CostPrice {
+		return nil, fmt.Errorf("negotiated price must be greater than cost price")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	price := &Domain.CustomerPrice{
+		BusinessID: customer.BusinessID,
+		CustomerID: customer.ID,
+		ProductID:  product.ID,
+		Price:      req.Price,
+		Notes:      strings.TrimSpace(req.Notes),
+		CreatedBy:  objUserID,
+	}
+
+	if err := uc.priceRepo.Upsert(price); err != nil {
+		return nil, err
+	}
+
+	return price, nil
+}
+
+func (uc *pricingUseCase) GetCustomerPrices(ctx context.Context, customerID, businessID string) ([]Domain.CustomerPrice, error) {
+	if _, err := uc.findCustomer(ctx, customerID, businessID); err != nil {
+		return nil, err
+	}
+
+	prices, err := uc.priceRepo.FindByCustomerID(customerID)
+	if err != nil {
+		return nil, err
+	}
+	if prices == nil {
+		prices = []Domain.CustomerPrice{}
+	}
+	return prices, nil
+}
+
+func (uc *pricingUseCase) DeleteCustomerPrice(ctx context.Context, customerID, productID, businessID string) error {
+	if _, err := uc.findCustomer(ctx, customerID, businessID); err != nil {
+		return err
+	}
+
+	return uc.priceRepo.Delete(customerID, productID)
+}
+
+// QuotePrice resolves the unit price for a product: the customer's negotiated price,
+// then the product price for the customer's tier, then the retail selling price.
+// The customer can be given by ID or by phone; walk-in sales pass neither.
+func (uc *pricingUseCase) QuotePrice(ctx context.Context, businessID, productID, customerID, customerPhone string, quantity float64) (*Domain.PriceQuote, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	// Quotes are asked for every item rung up, so the product comes from memory
+	product, err := uc.catalog.Product(businessID, productID, uc.loadCatalog)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		// Not in the shop's catalog: in the trash, another shop's or unknown
+		if product, err = uc.findProduct(ctx, productID, businessID); err != nil {
+			return nil, err
+		}
+	}
+
+	if quantity <= 0 {
+		quantity = 1
+	}
+
+	var customer *Domain.Customer
+	if customerID != "" {
+		customer, err = uc.findCustomer(ctx, customerID, businessID)
+		if err != nil {
+			return nil, err
+		}
+	} else if phone := normalizePhone(customerPhone, business.Country); phone != "" {
+		// Unknown numbers are simply walk-in customers
+		customer, err = uc.customerRepo.FindByPhone(businessID, phone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find customer: %w", err)
+		}
+	}
+
+	quote := &Domain.PriceQuote{
+		ProductID:    product.ID.Hex(),
+		ProductName:  product.Name,
+		CustomerTier: Domain.CustomerTierRetail,
+		Quantity:     quantity,
+		RetailPrice:  product.SellingPrice,
+		UnitPrice:    product.SellingPrice,
+		Source:       Domain.PriceSourceRetail,
+	}
+
+	if customer != nil && customer.Status == Domain.CustomerStatusActive {
+		quote.CustomerID = customer.ID.Hex()
+		quote.CustomerTier = customer.PricingTier()
+
+		negotiated, err := uc.priceRepo.Find(customer.ID.Hex(), productID)
+		if err != nil {
+			return nil, err
+		}
+
+		if negotiated != nil {
+			quote.UnitPrice = negotiated.Price
+			quote.Source = Domain.PriceSourceCustomer
+		} else if tierPrice, ok := product.TierPrices[quote.CustomerTier]; ok && tierPrice > 0 {
+			quote.UnitPrice = tierPrice
+			quote.Source = Domain.PriceSourceTier
+		}
+	}
+
+	quote.Total = quote.UnitPrice.Times(quantity)
+
+	return quote, nil
+}
+
+func (uc *pricingUseCase) loadCatalog(businessID string) ([]Domain.Product, error) {
+	products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	return products, nil
+}
+
+func (uc *pricingUseCase) findCustomer(ctx context.Context, customerID, businessID string) (*Domain.Customer, error) {
+	customer, err := uc.customerRepo.FindInBusiness(ctx, customerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer: %w", err)
+	}
+	if customer == nil {
+		return nil, Domain.NotFoundError("customer not found")
+	}
+	return customer, nil
+}
+
+func (uc *pricingUseCase) findProduct(ctx context.Context, productID, businessID string) (*Domain.Product, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+	return product, nil
+}
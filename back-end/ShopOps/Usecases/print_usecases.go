@@ -0,0 +1,643 @@
+package Usecases
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PrintUseCase queues receipts, labels and Z-reports for a shop's print bridges
+// and hands them to the bridges as they poll
+type PrintUseCase interface {
+	Infrastructure.PrintBridgeAuthenticator
+	RegisterBridge(businessID, userID string, req Domain.RegisterPrintBridgeRequest) (*Domain.PrintBridgeTokenResponse, error)
+	GetBridges(businessID string) ([]Domain.PrintBridge, error)
+	RotateBridgeToken(id, businessID string) (*Domain.PrintBridgeTokenResponse, error)
+	DeleteBridge(id, businessID string) error
+
+	CreateJob(ctx context.Context, businessID, userID string, req Domain.CreatePrintJobRequest) (*Domain.PrintJob, error)
+	GetJobs(businessID string, filters Domain.PrintJobFilters) ([]Domain.PrintJob, error)
+	GetJob(id, businessID string) (*Domain.PrintJob, error)
+
+	// RenderShelfLabels lays out shelf labels for the products req picks, as a PDF
+	// for label sheets or a label printer, or as ESC/POS
+	RenderShelfLabels(businessID string, req Domain.ShelfLabelRequest) (data []byte, contentType, filename string, err error)
+	// PrintShelfLabels queues the shelf labels in ESC/POS for the print bridges
+	PrintShelfLabels(businessID, userID string, req Domain.ShelfLabelRequest) (*Domain.PrintJob, error)
+
+	// ClaimJobs hands the bridge up to req.Max jobs, waiting up to req.Wait seconds
+	// for the first when there are none
+	ClaimJobs(ctx context.Context, bridge *Domain.PrintBridge, req Domain.PrintBridgeClaimRequest) ([]Domain.PrintJob, error)
+	AckJob(bridge *Domain.PrintBridge, jobID string, req Domain.PrintJobAckRequest) (*Domain.PrintJob, error)
+	FailExpiredJobs() error
+}
+
+type printUseCase struct {
+	printRepo     Domain.PrintRepository
+	businessRepo  Domain.BusinessRepository
+	inventoryRepo Domain.ProductRepository
+	receiptUC     ReceiptUseCase
+	analyticsUC   AnalyticsUseCase
+	salesRepo     Domain.SaleRepository
+	renderer      Infrastructure.ReceiptRenderer
+	labels        Infrastructure.LabelRenderer
+	signal        *Infrastructure.PrintSignal
+	telemetry     *Infrastructure.TelemetryBuffer
+}
+
+const (
+	defaultPrintClaims = 5
+	// A bridge waiting on one instance also checks for jobs queued on the others this often
+	printPollInterval = 5 * time.Second
+)
+
+func NewPrintUseCase(
+	printRepo Domain.PrintRepository,
+	businessRepo Domain.BusinessRepository,
+	inventoryRepo Domain.ProductRepository,
+	receiptUC ReceiptUseCase,
+	analyticsUC AnalyticsUseCase,
+	salesRepo Domain.SaleRepository,
+	renderer Infrastructure.ReceiptRenderer,
+	labels Infrastructure.LabelRenderer,
+	signal *Infrastructure.PrintSignal,
+	telemetry *Infrastructure.TelemetryBuffer,
+) PrintUseCase {
+	return &printUseCase{
+		printRepo:     printRepo,
+		businessRepo:  businessRepo,
+		inventoryRepo: inventoryRepo,
+		receiptUC:     receiptUC,
+		analyticsUC:   analyticsUC,
+		salesRepo:     salesRepo,
+		renderer:      renderer,
+		labels:        labels,
+		signal:        signal,
+		telemetry:     telemetry,
+	}
+}
+
+func (uc *printUseCase) AuthenticateBridge(token string) (*Domain.PrintBridge, error) {
+	return uc.printRepo.FindBridgeByTokenHash(Infrastructure.HashPrintBridgeToken(token))
+}
+
+func (uc *printUseCase) RegisterBridge(businessID, userID string, req Domain.RegisterPrintBridgeRequest) (*Domain.PrintBridgeTokenResponse, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+	if req.Name == "" {
+		return nil, Domain.ValidationError("bridge name is required")
+	}
+
+	token, hash, err := Infrastructure.NewPrintBridgeToken()
+	if err != nil {
+		return nil, err
+	}
+
+	bridge := &Domain.PrintBridge{
+		BusinessID: objBusinessID,
+		Name:       req.Name,
+		TokenHash:  hash,
+		CreatedBy:  objUserID,
+	}
+	if err := uc.printRepo.CreateBridge(bridge); err != nil {
+		return nil, err
+	}
+
+	return &Domain.PrintBridgeTokenResponse{PrintBridge: *bridge, Token: token}, nil
+}
+
+func (uc *printUseCase) GetBridges(businessID string) ([]Domain.PrintBridge, error) {
+	bridges, err := uc.printRepo.FindBridgesByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	for i := range bridges {
+		bridges[i].Online = bridges[i].LastSeenAt != nil && now.Sub(*bridges[i].LastSeenAt) < Domain.PrintBridgeOnlineWindow
+	}
+	if bridges == nil {
+		bridges = []Domain.PrintBridge{}
+	}
+	return bridges, nil
+}
+
+func (uc *printUseCase) getBridge(id, businessID string) (*Domain.PrintBridge, error) {
+	bridge, err := uc.printRepo.FindBridgeByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find print bridge: %w", err)
+	}
+	if bridge == nil {
+		return nil, Domain.NotFoundError("print bridge not found")
+	}
+	if bridge.BusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("access denied: print bridge does not belong to this business")
+	}
+	return bridge, nil
+}
+
+// RotateBridgeToken replaces the bridge's token, e.g. when the PC it ran on was
+// lost; the old token stops working at once
+func (uc *printUseCase) RotateBridgeToken(id, businessID string) (*Domain.PrintBridgeTokenResponse, error) {
+	bridge, err := uc.getBridge(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	token, hash, err := Infrastructure.NewPrintBridgeToken()
+	if err != nil {
+		return nil, err
+	}
+	if err := uc.printRepo.SetBridgeToken(bridge.ID, hash); err != nil {
+		return nil, err
+	}
+	bridge.TokenHash = hash
+
+	return &Domain.PrintBridgeTokenResponse{PrintBridge: *bridge, Token: token}, nil
+}
+
+func (uc *printUseCase) DeleteBridge(id, businessID string) error {
+	if _, err := uc.getBridge(id, businessID); err != nil {
+		return err
+	}
+	return uc.printRepo.DeleteBridge(id)
+}
+
+func (uc *printUseCase) CreateJob(ctx context.Context, businessID, userID string, req Domain.CreatePrintJobRequest) (*Domain.PrintJob, error) {
+	if !req.Type.IsValid() {
+		return nil, Domain.ValidationError(fmt.Sprintf("invalid print job type: %s", req.Type))
+	}
+
+	business, err := uc.findBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	job := &Domain.PrintJob{
+		BusinessID: business.ID,
+		Type:       req.Type,
+		Printer:    req.Printer,
+		Status:     Domain.PrintJobQueued,
+		CreatedBy:  objUserID,
+	}
+
+	if err := uc.setJobBridge(job, req.BridgeID); err != nil {
+		return nil, err
+	}
+
+	copies := max(req.Copies, 1)
+
+	switch req.Type {
+	case Domain.PrintJobReceipt:
+		if req.SaleID == "" {
+			return nil, Domain.ValidationError("sale_id is required for receipts")
+		}
+		saleID, err := primitive.ObjectIDFromHex(req.SaleID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sale ID: %w", err)
+		}
+		receipt, _, err := uc.receiptUC.RenderReceipt(ctx, req.SaleID, businessID, req.TemplateID, Domain.ReceiptFormatESCPOS, business.Language)
+		if err != nil {
+			return nil, err
+		}
+		job.SaleID = &saleID
+		job.Payload = bytes.Repeat(receipt, copies)
+
+	case Domain.PrintJobBarcode:
+		if req.ProductID == "" {
+			return nil, Domain.ValidationError("product_id is required for barcode labels")
+		}
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, req.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if product == nil || product.DeletedAt != nil {
+			return nil, Domain.NotFoundError("product not found")
+		}
+		template, err := uc.receiptUC.ResolveTemplate(ctx, businessID, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		job.ProductID = &product.ID
+		job.Payload, err = uc.renderer.RenderBarcodeLabels(Domain.BarcodeLabelData{
+			Template: template,
+			Business: business,
+			Product:  product,
+			Copies:   copies,
+		})
+		if err != nil {
+			return nil, Domain.ValidationError(err.Error())
+		}
+
+	case Domain.PrintJobZReport:
+		payload, date, err := uc.renderZReport(ctx, business, req.Date, req.TemplateID)
+		if err != nil {
+			return nil, err
+		}
+		job.ReportDate = date
+		job.Payload = bytes.Repeat(payload, copies)
+	}
+
+	return uc.queueJob(job)
+}
+
+// setJobBridge sends the job to bridgeID only, or to any of the shop's bridges
+// when empty
+func (uc *printUseCase) setJobBridge(job *Domain.PrintJob, bridgeID string) error {
+	businessID := job.BusinessID.Hex()
+
+	// A job nobody can collect would only sit until it expires
+	if bridgeID != "" {
+		bridge, err := uc.getBridge(bridgeID, businessID)
+		if err != nil {
+			return err
+		}
+		job.BridgeID = &bridge.ID
+		return nil
+	}
+
+	bridges, err := uc.printRepo.FindBridgesByBusiness(businessID)
+	if err != nil {
+		return err
+	}
+	if len(bridges) == 0 {
+		return Domain.ValidationError("no print bridge is registered for this business")
+	}
+	return nil
+}
+
+func (uc *printUseCase) queueJob(job *Domain.PrintJob) (*Domain.PrintJob, error) {
+	if err := uc.printRepo.CreateJob(job); err != nil {
+		return nil, err
+	}
+	uc.signal.Notify(job.BusinessID.Hex())
+
+	job.Payload = nil
+	return job, nil
+}
+
+func (uc *printUseCase) RenderShelfLabels(businessID string, req Domain.ShelfLabelRequest) ([]byte, string, string, error) {
+	business, err := uc.findBusiness(businessID)
+	if err != nil {
+		return nil, "", "", err
+	}
+	data, _, err := uc.shelfLabels(business, req)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	stamp := time.Now().In(clockFor(business).loc).Format("20060102_150405")
+	if req.Format == Domain.ShelfLabelESCPOS {
+		payload, err := uc.labels.RenderESCPOS(data)
+		if err != nil {
+			return nil, "", "", Domain.ValidationError(err.Error())
+		}
+		return payload, "application/octet-stream", "shelf_labels_" + stamp + ".bin", nil
+	}
+
+	pdf, err := uc.labels.RenderPDF(data)
+	if err != nil {
+		return nil, "", "", Domain.ValidationError(err.Error())
+	}
+	return pdf, "application/pdf", "shelf_labels_" + stamp + ".pdf", nil
+}
+
+func (uc *printUseCase) PrintShelfLabels(businessID, userID string, req Domain.ShelfLabelRequest) (*Domain.PrintJob, error) {
+	business, err := uc.findBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	job := &Domain.PrintJob{
+		BusinessID: business.ID,
+		Type:       Domain.PrintJobBarcode,
+		Printer:    req.Printer,
+		Status:     Domain.PrintJobQueued,
+		CreatedBy:  objUserID,
+	}
+	if err := uc.setJobBridge(job, req.BridgeID); err != nil {
+		return nil, err
+	}
+
+	data, products, err := uc.shelfLabels(business, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(products) == 1 {
+		job.ProductID = &products[0].ID
+	}
+	job.Payload, err = uc.labels.RenderESCPOS(data)
+	if err != nil {
+		return nil, Domain.ValidationError(err.Error())
+	}
+
+	return uc.queueJob(job)
+}
+
+func (uc *printUseCase) findBusiness(businessID string) (*Domain.Business, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+	return business, nil
+}
+
+// shelfLabels looks up the products req picks, those listed first in their order
+// and then the rest by name, and lays out their labels
+func (uc *printUseCase) shelfLabels(business *Domain.Business, req Domain.ShelfLabelRequest) (Domain.ShelfLabelData, []Domain.Product, error) {
+	businessID := business.ID.Hex()
+	if len(req.ProductIDs) == 0 && req.Category == "" && req.PriceChangedSince == nil {
+		return Domain.ShelfLabelData{}, nil, Domain.ValidationError("product_ids, category or price_changed_since is required")
+	}
+
+	var products []Domain.Product
+	seen := make(map[primitive.ObjectID]bool)
+	if len(req.ProductIDs) > 0 {
+		found, err := uc.inventoryRepo.FindByIDs(businessID, req.ProductIDs)
+		if err != nil {
+			return Domain.ShelfLabelData{}, nil, err
+		}
+		byID := make(map[string]Domain.Product, len(found))
+		for _, product := range found {
+			if product.DeletedAt == nil {
+				byID[product.ID.Hex()] = product
+			}
+		}
+		for _, id := range req.ProductIDs {
+			product, ok := byID[id]
+			if !ok {
+				return Domain.ShelfLabelData{}, nil, Domain.NotFoundError(fmt.Sprintf("product %s not found", id))
+			}
+			if !seen[product.ID] {
+				seen[product.ID] = true
+				products = append(products, product)
+			}
+		}
+	}
+
+	if req.Category != "" || req.PriceChangedSince != nil {
+		active := Domain.ProductStatusActive
+		filters := Domain.ProductFilters{Status: &active, PriceChangedSince: req.PriceChangedSince}
+		if req.Category != "" {
+			filters.Category = &req.Category
+		}
+		found, err := uc.inventoryRepo.FindByBusinessID(businessID, filters)
+		if err != nil {
+			return Domain.ShelfLabelData{}, nil, err
+		}
+		for _, product := range found {
+			if !seen[product.ID] {
+				seen[product.ID] = true
+				products = append(products, product)
+			}
+		}
+	}
+
+	if len(products) == 0 {
+		return Domain.ShelfLabelData{}, nil, Domain.NotFoundError("no products to print labels for")
+	}
+	copies := max(req.Copies, 1)
+	if len(products)*copies > Domain.MaxShelfLabels {
+		return Domain.ShelfLabelData{}, nil, Domain.ValidationError(fmt.Sprintf("at most %d labels can be printed at once", Domain.MaxShelfLabels))
+	}
+
+	data := Domain.ShelfLabelData{
+		WidthMM:  req.WidthMM,
+		HeightMM: req.HeightMM,
+		Sheet:    req.Sheet,
+		Border:   req.Border,
+	}
+	for _, product := range products {
+		label := Domain.ShelfLabel{
+			Name:  product.Name,
+			Price: strings.TrimSpace(business.Currency + " " + product.SellingPrice.String()),
+			Code:  product.Barcode,
+		}
+		if label.Code == "" {
+			label.Code = product.SKU
+		}
+		if product.Weighed {
+			label.Price += " / kg"
+		}
+		for i := 0; i < copies; i++ {
+			data.Labels = append(data.Labels, label)
+		}
+	}
+	return data, products, nil
+}
+
+// renderZReport prints the takings of the business day date, today when empty
+func (uc *printUseCase) renderZReport(ctx context.Context, business *Domain.Business, date, templateID string) ([]byte, string, error) {
+	clock := clockFor(business)
+	if date == "" {
+		date = clock.Day(time.Now()).Format("2006-01-02")
+	} else if _, err := time.Parse("2006-01-02", date); err != nil {
+		return nil, "", Domain.ValidationError("invalid date, expected YYYY-MM-DD")
+	}
+
+	businessID := business.ID.Hex()
+	payments, err := uc.analyticsUC.GetSalesBreakdown(businessID, date, date, Domain.SalesSummaryByPaymentMethod)
+	if err != nil {
+		return nil, "", err
+	}
+	devices, err := uc.analyticsUC.GetSalesBreakdown(businessID, date, date, Domain.SalesSummaryByDevice)
+	if err != nil {
+		return nil, "", err
+	}
+	day, _ := time.Parse("2006-01-02", date)
+	dayStart, dayEnd := clock.Range(day, day)
+	currencies, err := uc.salesRepo.GetForeignTakings(businessID, dayStart, dayEnd.Add(-time.Nanosecond))
+	if err != nil {
+		return nil, "", err
+	}
+	template, err := uc.receiptUC.ResolveTemplate(ctx, businessID, templateID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	payload, err := uc.renderer.RenderZReport(Domain.ZReportData{
+		Template:     template,
+		Business:     business,
+		Date:         date,
+		Payments:     payments.Lines,
+		Devices:      devices.Lines,
+		Currencies:   currencies,
+		CashRounding: cashRounding(payments.Lines),
+		Printed:      time.Now().In(clock.loc),
+		Language:     business.Language,
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return payload, date, nil
+}
+
+func (uc *printUseCase) GetJobs(businessID string, filters Domain.PrintJobFilters) ([]Domain.PrintJob, error) {
+	jobs, err := uc.printRepo.FindJobs(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []Domain.PrintJob{}
+	}
+	return jobs, nil
+}
+
+func (uc *printUseCase) GetJob(id, businessID string) (*Domain.PrintJob, error) {
+	job, err := uc.printRepo.FindJobByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find print job: %w", err)
+	}
+	if job == nil {
+		return nil, Domain.NotFoundError("print job not found")
+	}
+	if job.BusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("access denied: print job does not belong to this business")
+	}
+
+	job.Payload = nil
+	return job, nil
+}
+
+func (uc *printUseCase) ClaimJobs(ctx context.Context, bridge *Domain.PrintBridge, req Domain.PrintBridgeClaimRequest) ([]Domain.PrintJob, error) {
+	// Bridges poll every few seconds; when they were last seen only needs to be close
+	seen := Domain.TelemetryWrite{
+		Kind:       Domain.TelemetryPrintBridgeSeen,
+		BusinessID: bridge.BusinessID.Hex(),
+		SubjectID:  bridge.ID.Hex(),
+		At:         time.Now(),
+		Fields:     make(map[string]interface{}),
+	}
+	if req.Printers != nil {
+		seen.Fields["printers"] = req.Printers
+	}
+	if req.Version != "" {
+		seen.Fields["version"] = req.Version
+	}
+	uc.telemetry.Record(seen)
+
+	limit := req.Max
+	if limit <= 0 {
+		limit = defaultPrintClaims
+	}
+	businessID := bridge.BusinessID.Hex()
+	deadline := time.Now().Add(time.Duration(req.Wait) * time.Second)
+
+	jobs := []Domain.PrintJob{}
+	for {
+		wake := uc.signal.Wait(businessID)
+
+		for len(jobs) < limit {
+			job, err := uc.printRepo.ClaimJob(bridge, time.Now())
+			if err != nil {
+				return nil, err
+			}
+			if job == nil {
+				break
+			}
+			jobs = append(jobs, *job)
+		}
+
+		wait := time.Until(deadline)
+		if len(jobs) > 0 || wait <= 0 {
+			return jobs, nil
+		}
+
+		timer := time.NewTimer(min(wait, printPollInterval))
+		select {
+		case <-ctx.Done():
+			// The bridge hung up; it claimed nothing
+			timer.Stop()
+			return jobs, nil
+		case <-wake:
+		case <-timer.C:
+		}
+		timer.Stop()
+	}
+}
+
+func (uc *printUseCase) AckJob(bridge *Domain.PrintBridge, jobID string, req Domain.PrintJobAckRequest) (*Domain.PrintJob, error) {
+	job, err := uc.printRepo.FindJobByID(jobID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find print job: %w", err)
+	}
+	if job == nil || job.BusinessID != bridge.BusinessID {
+		return nil, Domain.NotFoundError("print job not found")
+	}
+
+	status := req.Status
+	switch status {
+	case Domain.PrintJobPrinted:
+		req.Error = ""
+	case Domain.PrintJobFailed:
+		if req.Retry && job.Attempts < Domain.PrintJobMaxAttempts {
+			status = Domain.PrintJobQueued
+		}
+	default:
+		return nil, Domain.ValidationError("status must be printed or failed")
+	}
+
+	now := time.Now()
+	held, err := uc.printRepo.AckJob(jobID, bridge.ID, status, req.Error, now)
+	if err != nil {
+		return nil, err
+	}
+	if !held {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "print job is not held by this bridge; its lease may have run out")
+	}
+
+	if status == Domain.PrintJobQueued {
+		uc.signal.Notify(bridge.BusinessID.Hex())
+	}
+
+	job.Status = status
+	job.Error = req.Error
+	job.LeaseUntil = nil
+	job.Payload = nil
+	if status == Domain.PrintJobPrinted {
+		job.PrintedAt = &now
+	}
+	if status == Domain.PrintJobQueued {
+		job.ClaimedBy = nil
+	}
+	return job, nil
+}
+
+func (uc *printUseCase) FailExpiredJobs() error {
+	_, err := uc.printRepo.FailExpired(time.Now())
+	return err
+}
+
+// cashRounding totals what rounding at the till added to the sales in lines
+func cashRounding(lines []Domain.SalesBreakdownLine) Domain.Money {
+	var total Domain.Money
+	for _, line := range lines {
+		total += line.CashRounding
+	}
+	return total
+}
@@ -0,0 +1,364 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ProductionUseCase interface {
+	// SetRecipe replaces the product's recipe
+	SetRecipe(ctx context.Context, businessID, productID, userID string, req Domain.SetRecipeRequest) (*Domain.Recipe, error)
+	GetRecipe(businessID, productID string) (*Domain.Recipe, error)
+	GetRecipes(businessID string) ([]Domain.Recipe, error)
+	DeleteRecipe(businessID, productID string) error
+	// Produce makes units of a product from its recipe, taking the components out
+	// of stock and adding the units at what they cost
+	Produce(ctx context.Context, businessID, userID string, req Domain.CreateProductionOrderRequest) (*Domain.ProductionOrder, error)
+	GetOrders(businessID string, filters Domain.ProductionOrderFilters) ([]Domain.ProductionOrder, error)
+	GetOrder(id, businessID string) (*Domain.ProductionOrder, error)
+	// GetReport sums what was made between the days, by product and by component
+	GetReport(businessID, startDate, endDate string) (*Domain.ProductionReport, error)
+}
+
+type productionUseCase struct {
+	productionRepo  Domain.ProductionRepository
+	inventoryRepo   Domain.ProductRepository
+	reservationRepo Domain.StockReservationRepository
+	businessRepo    Domain.BusinessRepository
+}
+
+func NewProductionUseCase(
+	productionRepo Domain.ProductionRepository,
+	inventoryRepo Domain.ProductRepository,
+	reservationRepo Domain.StockReservationRepository,
+	businessRepo Domain.BusinessRepository,
+) ProductionUseCase {
+	return &productionUseCase{
+		productionRepo:  productionRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		businessRepo:    businessRepo,
+	}
+}
+
+// stocked finds one of the shop's products that keeps stock of its own, for a
+// recipe or production order
+func (uc *productionUseCase) stocked(ctx context.Context, businessID, productID string) (*Domain.Product, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError(fmt.Sprintf("product %s not found", productID))
+	}
+	if product.Service {
+		return nil, Domain.ValidationError(fmt.Sprintf("%s is a service and keeps no stock", product.Name))
+	}
+	if product.Consignment != nil {
+		return nil, Domain.ValidationError(fmt.Sprintf("%s is the consignor's stock, not the shop's to make or use", product.Name))
+	}
+	return product, nil
+}
+
+func (uc *productionUseCase) SetRecipe(ctx context.Context, businessID, productID, userID string, req Domain.SetRecipeRequest) (*Domain.Recipe, error) {
+	product, err := uc.stocked(ctx, businessID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	recipe := &Domain.Recipe{
+		BusinessID: product.BusinessID,
+		ProductID:  product.ID,
+		Name:       product.Name,
+		Yield:      req.Yield,
+		Components: make([]Domain.RecipeComponent, 0, len(req.Components)),
+		Notes:      req.Notes,
+		CreatedBy:  objUserID,
+	}
+
+	seen := make(map[string]bool)
+	for _, input := range req.Components {
+		if input.ProductID == productID {
+			return nil, Domain.ValidationError("a product cannot be made from itself")
+		}
+		if seen[input.ProductID] {
+			return nil, Domain.ValidationError(fmt.Sprintf("component %s is listed twice", input.ProductID))
+		}
+		seen[input.ProductID] = true
+
+		component, err := uc.stocked(ctx, businessID, input.ProductID)
+		if err != nil {
+			return nil, err
+		}
+		recipe.Components = append(recipe.Components, Domain.RecipeComponent{
+			ProductID: component.ID,
+			Name:      component.Name,
+			Quantity:  input.Quantity,
+		})
+	}
+
+	if err := uc.productionRepo.SaveRecipe(recipe); err != nil {
+		return nil, err
+	}
+
+	return recipe, nil
+}
+
+func (uc *productionUseCase) GetRecipe(businessID, productID string) (*Domain.Recipe, error) {
+	recipe, err := uc.productionRepo.FindRecipe(businessID, productID)
+	if err != nil {
+		return nil, err
+	}
+	if recipe == nil {
+		return nil, Domain.NotFoundError("recipe not found")
+	}
+	return recipe, nil
+}
+
+func (uc *productionUseCase) GetRecipes(businessID string) ([]Domain.Recipe, error) {
+	recipes, err := uc.productionRepo.FindRecipes(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if recipes == nil {
+		recipes = []Domain.Recipe{}
+	}
+	return recipes, nil
+}
+
+func (uc *productionUseCase) DeleteRecipe(businessID, productID string) error {
+	return uc.productionRepo.DeleteRecipe(businessID, productID)
+}
+
+func (uc *productionUseCase) Produce(ctx context.Context, businessID, userID string, req Domain.CreateProductionOrderRequest) (*Domain.ProductionOrder, error) {
+	product, err := uc.stocked(ctx, businessID, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	recipe, err := uc.GetRecipe(businessID, req.ProductID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	count, err := uc.productionRepo.CountOrders(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	order := &Domain.ProductionOrder{
+		ID:         primitive.NewObjectID(),
+		BusinessID: product.BusinessID,
+		Number:     fmt.Sprintf("PR-%05d", count+1),
+		ProductID:  product.ID,
+		Name:       product.Name,
+		Quantity:   req.Quantity,
+		Components: make([]Domain.ProductionComponent, 0, len(recipe.Components)),
+		Notes:      req.Notes,
+		ProducedBy: objUserID,
+	}
+
+	// Check every component is there before taking any, leaving what orders hold
+	batches := req.Quantity / recipe.Yield
+	for _, ingredient := range recipe.Components {
+		component, err := uc.stocked(ctx, businessID, ingredient.ProductID.Hex())
+		if err != nil {
+			return nil, err
+		}
+
+		quantity := math.Round(ingredient.Quantity*batches*1e6) / 1e6
+		available, err := availableStock(uc.reservationRepo, component, nil)
+		if err != nil {
+			return nil, err
+		}
+		if available < quantity {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("insufficient %s. Available: %.2f, Required: %.2f",
+				component.Name, available, quantity))
+		}
+
+		cost := component.CostPrice.Times(quantity)
+		order.Components = append(order.Components, Domain.ProductionComponent{
+			ProductID: component.ID,
+			Name:      component.Name,
+			Quantity:  quantity,
+			UnitCost:  component.CostPrice,
+			Cost:      cost,
+		})
+		order.TotalCost += cost
+	}
+	order.UnitCost = Domain.Money(math.Round(float64(order.TotalCost) / order.Quantity))
+
+	referenceID := order.ID.Hex()
+	for i, component := range order.Components {
+		if err := uc.inventoryRepo.AdjustStock(
+			component.ProductID.Hex(),
+			component.Quantity,
+			Domain.MovementTypeProductionUse,
+			"Used in production order "+order.Number,
+			&referenceID,
+			"production_order",
+			userID,
+		); err != nil {
+			uc.returnComponents(order, order.Components[:i], userID)
+			return nil, err
+		}
+	}
+
+	if err := uc.inventoryRepo.AddStockAtCost(
+		product.ID.Hex(),
+		order.Quantity,
+		order.UnitCost,
+		Domain.MovementTypeProduction,
+		"Made by production order "+order.Number,
+		&referenceID,
+		"production_order",
+		userID,
+	); err != nil {
+		uc.returnComponents(order, order.Components, userID)
+		return nil, err
+	}
+
+	if err := uc.productionRepo.CreateOrder(order); err != nil {
+		// The stock has moved and the ledger references the order; keep it moved
+		Infrastructure.Logger.Warn("failed to save production order", "order_number", order.Number, "reference_id", referenceID, "error", err)
+		return nil, err
+	}
+
+	return order, nil
+}
+
+// returnComponents puts components taken for an order that could not be made
+// back into stock
+func (uc *productionUseCase) returnComponents(order *Domain.ProductionOrder, components []Domain.ProductionComponent, userID string) {
+	referenceID := order.ID.Hex()
+	for _, component := range components {
+		if err := uc.inventoryRepo.AdjustStock(
+			component.ProductID.Hex(),
+			component.Quantity,
+			Domain.MovementTypeReturn,
+			"Production order "+order.Number+" failed - restoring stock",
+			&referenceID,
+			"production_order",
+			userID,
+		); err != nil {
+			Infrastructure.Logger.Warn("failed to restore stock for production order", "product_id", component.ProductID.Hex(), "order_number", order.Number, "error", err)
+		}
+	}
+}
+
+func (uc *productionUseCase) GetOrders(businessID string, filters Domain.ProductionOrderFilters) ([]Domain.ProductionOrder, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	orders, err := uc.productionRepo.FindOrders(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if orders == nil {
+		orders = []Domain.ProductionOrder{}
+	}
+	return orders, nil
+}
+
+func (uc *productionUseCase) GetOrder(id, businessID string) (*Domain.ProductionOrder, error) {
+	order, err := uc.productionRepo.FindOrderByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.BusinessID.Hex() != businessID {
+		return nil, Domain.NotFoundError("production order not found")
+	}
+	return order, nil
+}
+
+func (uc *productionUseCase) GetReport(businessID, startDate, endDate string) (*Domain.ProductionReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	clock := clockFor(business)
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clock)
+	if err != nil {
+		return nil, err
+	}
+	start, end := clock.Range(fromDay, toDay)
+
+	orders, err := uc.productionRepo.FindOrders(businessID, Domain.ProductionOrderFilters{StartDate: &start, EndDate: &end})
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Domain.ProductionReport{
+		StartDate:  fromDay.Format("2006-01-02"),
+		EndDate:    toDay.Format("2006-01-02"),
+		Orders:     len(orders),
+		Products:   []Domain.ProductionReportProduct{},
+		Components: []Domain.ProductionReportComponent{},
+	}
+
+	products := make(map[primitive.ObjectID]*Domain.ProductionReportProduct)
+	components := make(map[primitive.ObjectID]*Domain.ProductionReportComponent)
+	for _, order := range orders {
+		report.TotalCost += order.TotalCost
+
+		line, ok := products[order.ProductID]
+		if !ok {
+			line = &Domain.ProductionReportProduct{ProductID: order.ProductID.Hex(), Name: order.Name}
+			products[order.ProductID] = line
+		}
+		line.Orders++
+		line.Quantity += order.Quantity
+		line.TotalCost += order.TotalCost
+
+		for _, used := range order.Components {
+			component, ok := components[used.ProductID]
+			if !ok {
+				component = &Domain.ProductionReportComponent{ProductID: used.ProductID.Hex(), Name: used.Name}
+				components[used.ProductID] = component
+			}
+			component.Quantity += used.Quantity
+			component.Cost += used.Cost
+		}
+	}
+
+	for _, line := range products {
+		line.Quantity = math.Round(line.Quantity*1e6) / 1e6
+		line.UnitCost = Domain.Money(math.Round(float64(line.TotalCost) / line.Quantity))
+		report.Products = append(report.Products, *line)
+	}
+	for _, component := range components {
+		component.Quantity = math.Round(component.Quantity*1e6) / 1e6
+		report.Components = append(report.Components, *component)
+	}
+
+	sort.Slice(report.Products, func(i, j int) bool {
+		return report.Products[i].TotalCost > report.Products[j].TotalCost
+	})
+	sort.Slice(report.Components, func(i, j int) bool {
+		return report.Components[i].Cost > report.Components[j].Cost
+	})
+
+	return report, nil
+}
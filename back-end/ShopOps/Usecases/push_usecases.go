@@ -0,0 +1,506 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// PushUseCase registers owners' phones and pushes them notifications about the
+// shops they follow: big sales, the end-of-day summary and failed syncs, plus
+// security alerts about their own account and maintenance windows. Each
+// notification to each device is logged and sent from the job queue.
+type PushUseCase interface {
+	EventPublisher
+	SecurityAlerter
+	MaintenanceAnnouncer
+
+	RegisterDevice(userID string, req Domain.RegisterPushDeviceRequest) (*Domain.PushDevice, error)
+	GetDevices(userID string) ([]Domain.PushDevice, error)
+	DeleteDevice(userID, deviceID string) error
+	// GetSubscription is what the device is sent about the shop; the defaults until saved
+	GetSubscription(userID, deviceID, businessID string) (*Domain.PushSubscription, error)
+	UpdateSubscription(userID, deviceID, businessID string, req Domain.UpdatePushSubscriptionRequest) (*Domain.PushSubscription, error)
+	// RecordReceipt records the app showing or opening a notification
+	RecordReceipt(userID, messageID string, req Domain.PushReceiptRequest) error
+	GetMessages(businessID string, filters Domain.PushMessageFilters) ([]Domain.PushMessage, error)
+
+	SendDailySummaries() error
+	SendPushJob(ctx context.Context, job *Domain.Job) error
+	// SendMaintenanceJob pushes a queued maintenance announcement to every device
+	SendMaintenanceJob(ctx context.Context, job *Domain.Job) error
+}
+
+type pushUseCase struct {
+	pushRepo       Domain.PushRepository
+	businessRepo   Domain.BusinessRepository
+	dashboardUC    DashboardUseCase
+	notificationUC NotificationUseCase
+	sender         Infrastructure.PushSender
+	jobQueue       Infrastructure.JobQueue
+}
+
+func NewPushUseCase(
+	pushRepo Domain.PushRepository,
+	businessRepo Domain.BusinessRepository,
+	dashboardUC DashboardUseCase,
+	notificationUC NotificationUseCase,
+	sender Infrastructure.PushSender,
+	jobQueue Infrastructure.JobQueue,
+) PushUseCase {
+	return &pushUseCase{
+		pushRepo:       pushRepo,
+		businessRepo:   businessRepo,
+		dashboardUC:    dashboardUC,
+		notificationUC: notificationUC,
+		sender:         sender,
+		jobQueue:       jobQueue,
+	}
+}
+
+func (uc *pushUseCase) RegisterDevice(userID string, req Domain.RegisterPushDeviceRequest) (*Domain.PushDevice, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	device := &Domain.PushDevice{
+		UserID:     objUserID,
+		Platform:   req.Platform,
+		Token:      strings.TrimSpace(req.Token),
+		Name:       req.Name,
+		AppVersion: req.AppVersion,
+	}
+	if err := uc.pushRepo.SaveDevice(device); err != nil {
+		return nil, err
+	}
+	return device, nil
+}
+
+func (uc *pushUseCase) GetDevices(userID string) ([]Domain.PushDevice, error) {
+	devices, err := uc.pushRepo.FindDevicesByUser(userID)
+	if err != nil {
+		return nil, err
+	}
+	if devices == nil {
+		devices = []Domain.PushDevice{}
+	}
+	return devices, nil
+}
+
+func (uc *pushUseCase) getDevice(userID, deviceID string) (*Domain.PushDevice, error) {
+	device, err := uc.pushRepo.FindDeviceByID(deviceID)
+	if err != nil {
+		return nil, err
+	}
+	if device == nil || device.UserID.Hex() != userID {
+		return nil, Domain.NotFoundError("push device not found")
+	}
+	return device, nil
+}
+
+func (uc *pushUseCase) DeleteDevice(userID, deviceID string) error {
+	if _, err := uc.getDevice(userID, deviceID); err != nil {
+		return err
+	}
+	return uc.pushRepo.DeleteDevice(deviceID)
+}
+
+func (uc *pushUseCase) GetSubscription(userID, deviceID, businessID string) (*Domain.PushSubscription, error) {
+	device, err := uc.getDevice(userID, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	subscription, err := uc.pushRepo.FindSubscription(deviceID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if subscription != nil {
+		return subscription, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	defaults := Domain.DefaultPushSubscription
+	defaults.Topics = append([]Domain.PushTopic(nil), defaults.Topics...)
+	defaults.DeviceID = device.ID
+	defaults.UserID = device.UserID
+	defaults.BusinessID = objBusinessID
+	return &defaults, nil
+}
+
+func (uc *pushUseCase) UpdateSubscription(userID, deviceID, businessID string, req Domain.UpdatePushSubscriptionRequest) (*Domain.PushSubscription, error) {
+	subscription, err := uc.GetSubscription(userID, deviceID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Topics != nil {
+		subscription.Topics = []Domain.PushTopic{}
+		for _, topic := range *req.Topics {
+			if !topic.IsValid() {
+				return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("unknown push topic %q", topic))
+			}
+			if !subscription.HasTopic(topic) {
+				subscription.Topics = append(subscription.Topics, topic)
+			}
+		}
+	}
+	if req.BigSale != nil {
+		subscription.BigSale = *req.BigSale
+	}
+	if req.SummaryHour != nil {
+		subscription.SummaryHour = *req.SummaryHour
+	}
+
+	if err := uc.pushRepo.SaveSubscription(subscription); err != nil {
+		return nil, err
+	}
+	return subscription, nil
+}
+
+func (uc *pushUseCase) RecordReceipt(userID, messageID string, req Domain.PushReceiptRequest) error {
+	message, err := uc.pushRepo.FindMessageByID(messageID)
+	if err != nil {
+		return err
+	}
+	if message == nil || message.UserID.Hex() != userID {
+		return Domain.NotFoundError("push message not found")
+	}
+	return uc.pushRepo.MarkReceipt(messageID, req.Status)
+}
+
+func (uc *pushUseCase) GetMessages(businessID string, filters Domain.PushMessageFilters) ([]Domain.PushMessage, error) {
+	messages, err := uc.pushRepo.FindMessages(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []Domain.PushMessage{}
+	}
+	return messages, nil
+}
+
+// Publish pushes big sales and failed syncs to the devices following them
+func (uc *pushUseCase) Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error {
+	var topic Domain.PushTopic
+	var kind Domain.NotificationEvent
+	switch event {
+	case Domain.WebhookEventSaleCreated:
+		topic, kind = Domain.PushTopicBigSale, Domain.NotificationBigSale
+	case Domain.WebhookEventSyncFailed:
+		topic, kind = Domain.PushTopicSyncFailure, Domain.NotificationSyncFailure
+	default:
+		return nil
+	}
+
+	subscriptions, err := uc.pushRepo.FindSubscribed(businessID, topic)
+	if err != nil {
+		return fmt.Errorf("failed to find push subscriptions: %w", err)
+	}
+	if len(subscriptions) == 0 {
+		return nil
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil || business == nil {
+		return err
+	}
+
+	var deviceIDs []primitive.ObjectID
+	var notification Infrastructure.PushNotification
+	switch payload := data.(type) {
+	case *Domain.Sale:
+		for _, subscription := range subscriptions {
+			if subscription.BigSale > 0 && payload.FinalAmount.Float64() >= subscription.BigSale {
+				deviceIDs = append(deviceIDs, subscription.DeviceID)
+			}
+		}
+		notification = Infrastructure.PushNotification{
+			Title: Infrastructure.Translate(business.Language, "push.big_sale", business.Name),
+			Body:  formatPushMoney(payload.FinalAmount.Float64(), business.Currency),
+			Data:  map[string]string{"screen": "sale", "sale_id": payload.ID.Hex()},
+		}
+		if payload.CustomerName != "" {
+			notification.Body = Infrastructure.Translate(business.Language, "push.big_sale_customer", notification.Body, payload.CustomerName)
+		}
+	case Domain.SyncFailedEvent:
+		for _, subscription := range subscriptions {
+			deviceIDs = append(deviceIDs, subscription.DeviceID)
+		}
+		notification = Infrastructure.PushNotification{
+			Title: Infrastructure.Translate(business.Language, "push.sync_problem", business.Name),
+			Body:  Infrastructure.Translate(business.Language, "push.sync_problem_body", payload.Failed, payload.Total),
+			Data:  map[string]string{"screen": "sync", "device_id": payload.DeviceID},
+		}
+	default:
+		return nil
+	}
+
+	devices, err := uc.pushRepo.FindDevicesByIDs(deviceIDs)
+	if err != nil {
+		return fmt.Errorf("failed to find push devices: %w", err)
+	}
+	allowed := make(map[primitive.ObjectID]bool)
+	var failed error
+	for i := range devices {
+		if !uc.allows(allowed, devices[i].UserID, kind) {
+			continue
+		}
+		if err := uc.push(ctx, &business.ID, &devices[i], topic, notification); err != nil {
+			failed = fmt.Errorf("failed to queue push %s for device %s: %w", topic, devices[i].ID.Hex(), err)
+		}
+	}
+	return failed
+}
+
+func (uc *pushUseCase) AlertUser(ctx context.Context, userID string, alert Domain.SecurityAlert) {
+	devices, err := uc.pushRepo.FindDevicesByUser(userID)
+	if err != nil {
+		Infrastructure.LoggerFrom(ctx).Warn("failed to find push devices for user", "user_id", userID, "error", err)
+		return
+	}
+
+	notification := Infrastructure.PushNotification{
+		Title: alert.Title,
+		Body:  alert.Body,
+		Data:  map[string]string{"screen": "security"},
+	}
+	for i := range devices {
+		if err := uc.push(ctx, nil, &devices[i], Domain.PushTopicSecurity, notification); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to queue security push for device", "device_id", devices[i].ID.Hex(), "error", err)
+		}
+	}
+}
+
+// AnnounceMaintenance queues the announcement, since it goes to every device
+func (uc *pushUseCase) AnnounceMaintenance(ctx context.Context, state Domain.MaintenanceState) error {
+	payload := map[string]string{"enabled": strconv.FormatBool(state.Enabled)}
+	if state.EndsAt != nil {
+		payload["ends_at"] = state.EndsAt.UTC().Format(time.RFC3339)
+	}
+	_, err := uc.jobQueue.Enqueue(ctx, Domain.JobTypeMaintenanceAnnouncement, "", payload)
+	return err
+}
+
+// Devices are read this many at a time when announcing maintenance
+const maintenanceAnnouncementBatch = 500
+
+func (uc *pushUseCase) SendMaintenanceJob(ctx context.Context, job *Domain.Job) error {
+	language := Domain.DefaultLanguage
+	notification := Infrastructure.PushNotification{
+		Title: Infrastructure.Translate(language, "push.maintenance_over"),
+		Body:  Infrastructure.Translate(language, "push.maintenance_over_body"),
+		Data:  map[string]string{"screen": "maintenance", "enabled": job.Payload["enabled"]},
+	}
+	if job.Payload["enabled"] == "true" {
+		notification.Title = Infrastructure.Translate(language, "push.maintenance")
+		notification.Body = Infrastructure.Translate(language, "push.maintenance_open")
+		if endsAt, err := time.Parse(time.RFC3339, job.Payload["ends_at"]); err == nil {
+			notification.Body = Infrastructure.Translate(language, "push.maintenance_until", pushAlertTime(endsAt))
+			notification.Data["ends_at"] = job.Payload["ends_at"]
+		}
+	}
+
+	var afterID primitive.ObjectID
+	for {
+		devices, err := uc.pushRepo.FindDevicesAfter(afterID, maintenanceAnnouncementBatch)
+		if err != nil {
+			return err
+		}
+		for i := range devices {
+			if err := uc.push(ctx, nil, &devices[i], Domain.PushTopicMaintenance, notification); err != nil {
+				Infrastructure.LoggerFrom(ctx).Warn("failed to queue maintenance push for device", "device_id", devices[i].ID.Hex(), "error", err)
+			}
+		}
+		if len(devices) < maintenanceAnnouncementBatch {
+			return nil
+		}
+		afterID = devices[len(devices)-1].ID
+	}
+}
+
+// SendDailySummaries pushes each subscribed device its shop's day once the
+// shop-local summary hour has passed. Run it at least hourly.
+func (uc *pushUseCase) SendDailySummaries() error {
+	subscriptions, err := uc.pushRepo.FindSummarySubscriptions()
+	if err != nil {
+		return err
+	}
+
+	businesses := make(map[primitive.ObjectID]*Domain.Business)
+	allowed := make(map[primitive.ObjectID]bool)
+	dashboards := make(map[primitive.ObjectID]*Domain.DashboardWidgets)
+	ctx := context.Background()
+	for i := range subscriptions {
+		subscription := &subscriptions[i]
+
+		business, ok := businesses[subscription.BusinessID]
+		if !ok {
+			if business, err = uc.businessRepo.FindByID(subscription.BusinessID.Hex()); err != nil {
+				return err
+			}
+			businesses[subscription.BusinessID] = business
+		}
+		if business == nil {
+			continue
+		}
+
+		now := time.Now().In(businessLocation(business))
+		today := now.Format("2006-01-02")
+		if now.Hour() < subscription.SummaryHour || subscription.LastSummaryDate == today {
+			continue
+		}
+
+		dashboard, ok := dashboards[business.ID]
+		if !ok {
+			if dashboard, err = uc.dashboardUC.GetDashboard(business.ID.Hex(), false); err != nil {
+				Infrastructure.Logger.Warn("failed to build push summary for business", "business_id", business.ID.Hex(), "error", err)
+				continue
+			}
+			dashboards[business.ID] = dashboard
+		}
+
+		device, err := uc.pushRepo.FindDeviceByID(subscription.DeviceID.Hex())
+		if err != nil {
+			return err
+		}
+		if device != nil && uc.allows(allowed, device.UserID, Domain.NotificationDailySummary) {
+			notification := Infrastructure.PushNotification{
+				Title: Infrastructure.Translate(business.Language, "push.daily_summary", business.Name),
+				Body: Infrastructure.Translate(business.Language, "push.daily_summary_body",
+					formatPushMoney(dashboard.Today.Sales.Float64(), business.Currency), dashboard.Today.Transactions,
+					formatPushMoney(dashboard.Today.Expenses.Float64(), business.Currency),
+					formatPushMoney(dashboard.Today.Profit.Float64(), business.Currency)),
+				Data: map[string]string{"screen": "dashboard", "date": dashboard.Today.Date},
+			}
+			if err := uc.push(ctx, &business.ID, device, Domain.PushTopicDailySummary, notification); err != nil {
+				return err
+			}
+		}
+
+		if err := uc.pushRepo.SetLastSummaryDate(subscription.ID, today); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// allows reports whether the user takes the event by push, remembering the
+// answer for the rest of the run
+func (uc *pushUseCase) allows(allowed map[primitive.ObjectID]bool, userID primitive.ObjectID, event Domain.NotificationEvent) bool {
+	ok, seen := allowed[userID]
+	if !seen {
+		ok = uc.notificationUC.Allows(userID.Hex(), event, Domain.ChannelPush)
+		allowed[userID] = ok
+	}
+	return ok
+}
+
+// push logs the notification for the device and queues it, so a slow push service
+// does not hold up the caller and failed sends are retried
+func (uc *pushUseCase) push(ctx context.Context, businessID *primitive.ObjectID, device *Domain.PushDevice, topic Domain.PushTopic, notification Infrastructure.PushNotification) error {
+	message := &Domain.PushMessage{
+		BusinessID: businessID,
+		UserID:     device.UserID,
+		DeviceID:   device.ID,
+		Topic:      topic,
+		Title:      notification.Title,
+		Body:       notification.Body,
+		Data:       notification.Data,
+		Status:     Domain.PushStatusQueued,
+	}
+	if err := uc.pushRepo.LogMessage(message); err != nil {
+		return err
+	}
+
+	var jobBusinessID string
+	if businessID != nil {
+		jobBusinessID = businessID.Hex()
+	}
+	_, err := uc.jobQueue.Enqueue(ctx, Domain.JobTypePush, jobBusinessID, map[string]string{"message_id": message.ID.Hex()})
+	return err
+}
+
+func (uc *pushUseCase) SendPushJob(ctx context.Context, job *Domain.Job) error {
+	message, err := uc.pushRepo.FindMessageByID(job.Payload["message_id"])
+	if err != nil {
+		return err
+	}
+	if message == nil || message.Status != Domain.PushStatusQueued {
+		return nil
+	}
+
+	device, err := uc.pushRepo.FindDeviceByID(message.DeviceID.Hex())
+	if err != nil {
+		return err
+	}
+	if device == nil {
+		message.Status = Domain.PushStatusFailed
+		message.Error = "device unregistered"
+		return uc.pushRepo.UpdateMessage(message)
+	}
+
+	// The message ID lets the app send back delivery and open receipts
+	data := map[string]string{"message_id": message.ID.Hex(), "topic": string(message.Topic)}
+	for key, value := range message.Data {
+		data[key] = value
+	}
+	if message.BusinessID != nil {
+		data["business_id"] = message.BusinessID.Hex()
+	}
+
+	providerMessageID, err := uc.sender.Send(ctx, device.Platform, device.Token, Infrastructure.PushNotification{
+		Title: message.Title,
+		Body:  message.Body,
+		Data:  data,
+	})
+	if errors.Is(err, Infrastructure.ErrPushTokenGone) {
+		// Uninstalled or a replaced token: forget the device rather than retrying
+		Infrastructure.LoggerFrom(ctx).Warn("unregistering push device", "device_id", device.ID.Hex(), "error", err)
+		message.Status = Domain.PushStatusFailed
+		message.Error = err.Error()
+		if updateErr := uc.pushRepo.UpdateMessage(message); updateErr != nil {
+			return updateErr
+		}
+		return uc.pushRepo.DeleteDevice(device.ID.Hex())
+	}
+	if err != nil {
+		if job.Attempts < job.MaxAttempts {
+			return err
+		}
+		message.Status = Domain.PushStatusFailed
+		message.Error = err.Error()
+		if updateErr := uc.pushRepo.UpdateMessage(message); updateErr != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to update push message", "message_id", message.ID.Hex(), "error", updateErr)
+		}
+		return err
+	}
+
+	now := time.Now()
+	message.Status = Domain.PushStatusSent
+	message.ProviderMessageID = providerMessageID
+	message.Error = ""
+	message.SentAt = &now
+	return uc.pushRepo.UpdateMessage(message)
+}
+
+func formatPushMoney(amount float64, currency string) string {
+	return strings.TrimSpace(formatThousands(amount) + " " + currency)
+}
+
+// pushAlertTime is when a security alert happened, as the alert text shows it
+func pushAlertTime(t time.Time) string {
+	return t.UTC().Format("2 Jan 2006 15:04") + " UTC"
+}
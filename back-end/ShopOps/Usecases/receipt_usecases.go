@@ -0,0 +1,279 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+type ReceiptUseCase interface {
+	CreateTemplate(businessID, userID string, req Domain.CreateReceiptTemplateRequest) (*Domain.ReceiptTemplate, error)
+	GetTemplates(businessID string) ([]Domain.ReceiptTemplate, error)
+	GetTemplateByID(ctx context.Context, id, businessID string) (*Domain.ReceiptTemplate, error)
+	UpdateTemplate(ctx context.Context, id, businessID string, req Domain.UpdateReceiptTemplateRequest) (*Domain.ReceiptTemplate, error)
+	DeleteTemplate(ctx context.Context, id, businessID string) error
+	// RenderReceipt labels the receipt in language, the shop's when empty
+	RenderReceipt(ctx context.Context, saleID, businessID, templateID string, format Domain.ReceiptFormat, language Domain.Language) ([]byte, string, error)
+	ResolveTemplate(ctx context.Context, businessID, templateID string) (*Domain.ReceiptTemplate, error)
+}
+
+type receiptUseCase struct {
+	templateRepo  Domain.ReceiptTemplateRepository
+	salesRepo     Domain.SaleRepository
+	businessRepo  Domain.BusinessRepository
+	inventoryRepo Domain.ProductRepository
+	renderer      Infrastructure.ReceiptRenderer
+}
+
+func NewReceiptUseCase(
+	templateRepo Domain.ReceiptTemplateRepository,
+	salesRepo Domain.SaleRepository,
+	businessRepo Domain.BusinessRepository,
+	inventoryRepo Domain.ProductRepository,
+	renderer Infrastructure.ReceiptRenderer,
+) ReceiptUseCase {
+	return &receiptUseCase{
+		templateRepo:  templateRepo,
+		salesRepo:     salesRepo,
+		businessRepo:  businessRepo,
+		inventoryRepo: inventoryRepo,
+		renderer:      renderer,
+	}
+}
+
+func (uc *receiptUseCase) CreateTemplate(businessID, userID string, req Domain.CreateReceiptTemplateRequest) (*Domain.ReceiptTemplate, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if req.Name == "" {
+		return nil, fmt.Errorf("template name is required")
+	}
+
+	if req.PaperWidth == 0 {
+		req.PaperWidth = Domain.ReceiptPaperWidth80
+	}
+	if err := validatePaperWidth(req.PaperWidth); err != nil {
+		return nil, err
+	}
+
+	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	fields := Domain.DefaultReceiptFields()
+	if req.Fields != nil {
+		fields = *req.Fields
+	}
+
+	// The first template a business creates becomes its default
+	existing, err := uc.templateRepo.FindDefault(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check default template: %w", err)
+	}
+	isDefault := req.IsDefault || existing == nil
+
+	if isDefault && existing != nil {
+		if err := uc.templateRepo.ClearDefault(businessID); err != nil {
+			return nil, err
+		}
+	}
+
+	template := &Domain.ReceiptTemplate{
+		BusinessID:      objBusinessID,
+		Name:            req.Name,
+		IsDefault:       isDefault,
+		Header:          req.Header,
+		Footer:          req.Footer,
+		LogoURL:         req.LogoURL,
+		PrintStoredLogo: req.PrintStoredLogo,
+		PaperWidth:      req.PaperWidth,
+		Fields:          fields,
+		CreatedBy:       objUserID,
+	}
+
+	if err := uc.templateRepo.Create(template); err != nil {
+		return nil, fmt.Errorf("failed to create receipt template: %w", err)
+	}
+
+	return template, nil
+}
+
+func (uc *receiptUseCase) GetTemplates(businessID string) ([]Domain.ReceiptTemplate, error) {
+	return uc.templateRepo.FindByBusinessID(businessID)
+}
+
+func (uc *receiptUseCase) GetTemplateByID(ctx context.Context, id, businessID string) (*Domain.ReceiptTemplate, error) {
+	template, err := uc.templateRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find receipt template: %w", err)
+	}
+	if template == nil {
+		return nil, Domain.NotFoundError("receipt template not found")
+	}
+
+	// Verify template belongs to business
+
+	return template, nil
+}
+
+func (uc *receiptUseCase) UpdateTemplate(ctx context.Context, id, businessID string, req Domain.UpdateReceiptTemplateRequest) (*Domain.ReceiptTemplate, error) {
+	template, err := uc.GetTemplateByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		if *req.Name == "" {
+			return nil, fmt.Errorf("template name is required")
+		}
+		template.Name = *req.Name
+	}
+	if req.Header != nil {
+		template.Header = req.Header
+	}
+	if req.Footer != nil {
+		template.Footer = req.Footer
+	}
+	if req.LogoURL != nil {
+		template.LogoURL = *req.LogoURL
+	}
+	if req.PrintStoredLogo != nil {
+		template.PrintStoredLogo = *req.PrintStoredLogo
+	}
+	if req.PaperWidth != nil {
+		if err := validatePaperWidth(*req.PaperWidth); err != nil {
+			return nil, err
+		}
+		template.PaperWidth = *req.PaperWidth
+	}
+	if req.Fields != nil {
+		template.Fields = *req.Fields
+	}
+
+	if req.IsDefault != nil {
+		if *req.IsDefault && !template.IsDefault {
+			if err := uc.templateRepo.ClearDefault(businessID); err != nil {
+				return nil, err
+			}
+		}
+		// A business always keeps a default; unset it by promoting another template
+		if !*req.IsDefault && template.IsDefault {
+			return nil, fmt.Errorf("cannot unset the default template; make another template default instead")
+		}
+		template.IsDefault = *req.IsDefault
+	}
+
+	if err := uc.templateRepo.Update(template); err != nil {
+		return nil, fmt.Errorf("failed to update receipt template: %w", err)
+	}
+
+	return template, nil
+}
+
+func (uc *receiptUseCase) DeleteTemplate(ctx context.Context, id, businessID string) error {
+	template, err := uc.GetTemplateByID(ctx, id, businessID)
+	if err != nil {
+		return err
+	}
+
+	if template.IsDefault {
+		return fmt.Errorf("cannot delete the default template")
+	}
+
+	return uc.templateRepo.Delete(id)
+}
+
+func (uc *receiptUseCase) RenderReceipt(ctx context.Context, saleID, businessID, templateID string, format Domain.ReceiptFormat, language Domain.Language) ([]byte, string, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, "", Domain.NotFoundError("business not found")
+	}
+
+	sale, err := uc.salesRepo.FindInBusiness(ctx, saleID)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to find sale: %w", err)
+	}
+	if sale == nil {
+		return nil, "", Domain.NotFoundError("sale not found")
+	}
+
+	template, err := uc.ResolveTemplate(ctx, businessID, templateID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	data := Domain.ReceiptData{
+		Template: template,
+		Business: business,
+		Sale:     sale,
+		Language: Domain.LanguageOr(language, business.Language),
+	}
+
+	if sale.ProductID != nil {
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, sale.ProductID.Hex())
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to load product for receipt", "error", err)
+		} else if product != nil {
+			data.ProductName = product.Name
+		}
+	}
+
+	switch format {
+	case Domain.ReceiptFormatESCPOS:
+		out, err := uc.renderer.RenderESCPOS(data)
+		return out, "application/octet-stream", err
+	case Domain.ReceiptFormatHTML, "":
+		out, err := uc.renderer.RenderHTML(data)
+		return out, "text/html; charset=utf-8", err
+	default:
+		return nil, "", fmt.Errorf("unsupported receipt format: %s", format)
+	}
+}
+
+// ResolveTemplate picks the requested template, then the business default,
+// then a built-in layout so receipts print before anything is configured
+func (uc *receiptUseCase) ResolveTemplate(ctx context.Context, businessID, templateID string) (*Domain.ReceiptTemplate, error) {
+	if templateID != "" {
+		return uc.GetTemplateByID(ctx, templateID, businessID)
+	}
+
+	template, err := uc.templateRepo.FindDefault(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find default receipt template: %w", err)
+	}
+	if template != nil {
+		return template, nil
+	}
+
+	return &Domain.ReceiptTemplate{
+		Name:       "Default",
+		IsDefault:  true,
+		PaperWidth: Domain.ReceiptPaperWidth80,
+		Fields:     Domain.DefaultReceiptFields(),
+		Footer:     []string{"Thank you!"},
+	}, nil
+}
+
+func validatePaperWidth(width Domain.ReceiptPaperWidth) error {
+	if width != Domain.ReceiptPaperWidth58 && width != Domain.ReceiptPaperWidth80 {
+		return fmt.Errorf("paper width must be 58 or 80")
+	}
+	return nil
+}
@@ -0,0 +1,560 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ReconciliationUseCase interface {
+	// ImportStatement reads a bank or wallet statement export and matches its
+	// new lines to the payments and expenses recorded in the shop
+	ImportStatement(businessID, userID string, source Domain.StatementSource, fileName string, file io.Reader) (*Domain.BankStatement, error)
+	GetStatements(businessID string) ([]Domain.BankStatement, error)
+	GetStatement(id, businessID string) (*Domain.BankStatement, error)
+	DeleteStatement(id, businessID string) error
+
+	GetLines(businessID string, filters Domain.StatementLineFilters) ([]Domain.StatementLine, error)
+	MatchLine(ctx context.Context, lineID, businessID, userID string, req Domain.MatchStatementLineRequest) (*Domain.StatementLine, error)
+	UnmatchLine(lineID, businessID string) (*Domain.StatementLine, error)
+	IgnoreLine(lineID, businessID string, req Domain.IgnoreStatementLineRequest) (*Domain.StatementLine, error)
+	// AutoMatch tries the unmatched lines again, e.g. after payments recorded late;
+	// returns how many were matched
+	AutoMatch(businessID string) (int, error)
+
+	GetReport(businessID string, startDate, endDate time.Time) (*Domain.ReconciliationReport, error)
+}
+
+type reconciliationUseCase struct {
+	reconciliationRepo Domain.ReconciliationRepository
+	salesRepo          Domain.SaleRepository
+	expenseRepo        Domain.ExpenseRepository
+	businessRepo       Domain.BusinessRepository
+}
+
+func NewReconciliationUseCase(
+	reconciliationRepo Domain.ReconciliationRepository,
+	salesRepo Domain.SaleRepository,
+	expenseRepo Domain.ExpenseRepository,
+	businessRepo Domain.BusinessRepository,
+) ReconciliationUseCase {
+	return &reconciliationUseCase{
+		reconciliationRepo: reconciliationRepo,
+		salesRepo:          salesRepo,
+		expenseRepo:        expenseRepo,
+		businessRepo:       businessRepo,
+	}
+}
+
+// Statement files larger than this are rejected; a year of a busy account is
+// well under it
+const MaxStatementSize = 10 << 20
+
+// minMatchReference is the shortest payment reference looked for on a line;
+// shorter ones turn up in unrelated narratives
+const minMatchReference = 6
+
+func (uc *reconciliationUseCase) ImportStatement(businessID, userID string, source Domain.StatementSource, fileName string, file io.Reader) (*Domain.BankStatement, error) {
+	if !source.IsValid() {
+		return nil, Domain.ValidationError("source must be cbe, awash or telebirr")
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+
+	lines, err := Infrastructure.ParseStatement(source, file, businessLocation(business))
+	if err != nil {
+		return nil, err
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	statement := &Domain.BankStatement{
+		BusinessID:  objBusinessID,
+		Source:      source,
+		FileName:    fileName,
+		PeriodStart: lines[0].Date,
+		PeriodEnd:   lines[0].Date,
+		CreatedBy:   objUserID,
+	}
+	for _, line := range lines {
+		if line.Date.Before(statement.PeriodStart) {
+			statement.PeriodStart = line.Date
+		}
+		if line.Date.After(statement.PeriodEnd) {
+			statement.PeriodEnd = line.Date
+		}
+	}
+
+	if err := uc.reconciliationRepo.CreateStatement(statement); err != nil {
+		return nil, err
+	}
+
+	for i := range lines {
+		lines[i].BusinessID = objBusinessID
+		lines[i].StatementID = statement.ID
+		lines[i].Status = Domain.StatementLineUnmatched
+	}
+
+	created, err := uc.reconciliationRepo.CreateLines(lines)
+	if err != nil {
+		return nil, err
+	}
+	if len(created) == 0 {
+		if err := uc.reconciliationRepo.DeleteStatement(statement.ID.Hex()); err != nil {
+			return nil, err
+		}
+		return nil, Domain.ValidationError("every line on this statement was imported before")
+	}
+
+	statement.LineCount = len(created)
+	statement.Duplicates = len(lines) - len(created)
+	statement.Matched, err = uc.match(businessID, created)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.reconciliationRepo.UpdateStatement(statement); err != nil {
+		return nil, err
+	}
+	return statement, nil
+}
+
+func (uc *reconciliationUseCase) GetStatements(businessID string) ([]Domain.BankStatement, error) {
+	return uc.reconciliationRepo.FindStatements(businessID)
+}
+
+func (uc *reconciliationUseCase) GetStatement(id, businessID string) (*Domain.BankStatement, error) {
+	statement, err := uc.reconciliationRepo.FindStatementByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if statement == nil || statement.BusinessID.Hex() != businessID {
+		return nil, Domain.NotFoundError("statement not found")
+	}
+	return statement, nil
+}
+
+func (uc *reconciliationUseCase) DeleteStatement(id, businessID string) error {
+	if _, err := uc.GetStatement(id, businessID); err != nil {
+		return err
+	}
+	return uc.reconciliationRepo.DeleteStatement(id)
+}
+
+func (uc *reconciliationUseCase) GetLines(businessID string, filters Domain.StatementLineFilters) ([]Domain.StatementLine, error) {
+	return uc.reconciliationRepo.FindLines(businessID, filters)
+}
+
+func (uc *reconciliationUseCase) getLine(lineID, businessID string) (*Domain.StatementLine, error) {
+	line, err := uc.reconciliationRepo.FindLineByID(lineID)
+	if err != nil {
+		return nil, err
+	}
+	if line == nil || line.BusinessID.Hex() != businessID {
+		return nil, Domain.NotFoundError("statement line not found")
+	}
+	return line, nil
+}
+
+func (uc *reconciliationUseCase) MatchLine(ctx context.Context, lineID, businessID, userID string, req Domain.MatchStatementLineRequest) (*Domain.StatementLine, error) {
+	line, err := uc.getLine(lineID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := uc.findEntry(ctx, businessID, req)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Amount != line.Amount {
+		return nil, Domain.ValidationError(fmt.Sprintf("the line is for %s but the %s is for %s", line.Amount, entry.Type, entry.Amount))
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	line.Status = Domain.StatementLineMatched
+	line.Note = ""
+	line.Match = &Domain.StatementMatch{
+		Type:      entry.Type,
+		ID:        entry.ID,
+		Payment:   entry.Payment,
+		Key:       entry.Key(),
+		Method:    Domain.MatchManual,
+		MatchedBy: &objUserID,
+		MatchedAt: time.Now(),
+	}
+
+	ok, err := uc.reconciliationRepo.SetMatch(line)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("this %s is already matched to another statement line", entry.Type))
+	}
+	return line, nil
+}
+
+// findEntry looks up the recorded payment a manual match names
+func (uc *reconciliationUseCase) findEntry(ctx context.Context, businessID string, req Domain.MatchStatementLineRequest) (*Domain.ReconciliationEntry, error) {
+	switch req.Type {
+	case Domain.ReconciliationSale:
+		sale, err := uc.salesRepo.FindInBusiness(ctx, req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find sale: %w", err)
+		}
+		if sale == nil || sale.Status != Domain.SaleStatusCompleted {
+			return nil, Domain.NotFoundError("sale not found")
+		}
+
+		entries := saleEntries(sale, nil)
+		if len(sale.Payments) == 0 {
+			if req.Payment != nil && *req.Payment != -1 {
+				return nil, Domain.ValidationError("the sale was not split; leave payment out")
+			}
+			return &entries[0], nil
+		}
+		if req.Payment == nil {
+			return nil, Domain.ValidationError("the sale was split; give the payment the line is for")
+		}
+		for i := range entries {
+			if entries[i].Payment == *req.Payment {
+				return &entries[i], nil
+			}
+		}
+		return nil, Domain.ValidationError("the sale has no such payment")
+
+	case Domain.ReconciliationExpense:
+		expense, err := uc.expenseRepo.FindInBusiness(ctx, req.ID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find expense: %w", err)
+		}
+		if expense == nil || expense.Status != Domain.ExpenseStatusActive {
+			return nil, Domain.NotFoundError("expense not found")
+		}
+		entry := expenseEntry(expense)
+		return &entry, nil
+	}
+
+	return nil, Domain.ValidationError("type must be sale or expense")
+}
+
+func (uc *reconciliationUseCase) UnmatchLine(lineID, businessID string) (*Domain.StatementLine, error) {
+	line, err := uc.getLine(lineID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	line.Status = Domain.StatementLineUnmatched
+	line.Match = nil
+	line.Note = ""
+	if _, err := uc.reconciliationRepo.SetMatch(line); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (uc *reconciliationUseCase) IgnoreLine(lineID, businessID string, req Domain.IgnoreStatementLineRequest) (*Domain.StatementLine, error) {
+	line, err := uc.getLine(lineID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	line.Status = Domain.StatementLineIgnored
+	line.Match = nil
+	line.Note = req.Note
+	if _, err := uc.reconciliationRepo.SetMatch(line); err != nil {
+		return nil, err
+	}
+	return line, nil
+}
+
+func (uc *reconciliationUseCase) AutoMatch(businessID string) (int, error) {
+	status := Domain.StatementLineUnmatched
+	lines, err := uc.reconciliationRepo.FindLines(businessID, Domain.StatementLineFilters{Status: &status})
+	if err != nil {
+		return 0, err
+	}
+	return uc.match(businessID, lines)
+}
+
+// match pairs unmatched lines with recorded payments of the same amount within
+// the match window: one whose transaction reference is on the line if there is
+// one, else the closest in time. Each recorded payment is matched to one line.
+func (uc *reconciliationUseCase) match(businessID string, lines []Domain.StatementLine) (int, error) {
+	if len(lines) == 0 {
+		return 0, nil
+	}
+
+	start, end := lines[0].Date, lines[0].Date
+	for _, line := range lines {
+		if line.Date.Before(start) {
+			start = line.Date
+		}
+		if line.Date.After(end) {
+			end = line.Date
+		}
+	}
+
+	entries, err := uc.entries(businessID, start.Add(-Domain.ReconciliationMatchWindow), end.Add(Domain.ReconciliationMatchWindow))
+	if err != nil {
+		return 0, err
+	}
+	used, err := uc.reconciliationRepo.FindMatchKeys(businessID)
+	if err != nil {
+		return 0, err
+	}
+
+	sort.SliceStable(lines, func(i, j int) bool { return lines[i].Date.Before(lines[j].Date) })
+
+	matched := 0
+	for i := range lines {
+		line := &lines[i]
+		if line.Status != Domain.StatementLineUnmatched {
+			continue
+		}
+
+		entry, method := bestEntry(line, entries, used)
+		if entry == nil {
+			continue
+		}
+
+		line.Status = Domain.StatementLineMatched
+		line.Match = &Domain.StatementMatch{
+			Type:      entry.Type,
+			ID:        entry.ID,
+			Payment:   entry.Payment,
+			Key:       entry.Key(),
+			Method:    method,
+			MatchedAt: time.Now(),
+		}
+		ok, err := uc.reconciliationRepo.SetMatch(line)
+		if err != nil {
+			return matched, err
+		}
+		used[line.Match.Key] = true
+		if !ok {
+			line.Status, line.Match = Domain.StatementLineUnmatched, nil
+			continue
+		}
+		matched++
+	}
+
+	return matched, nil
+}
+
+func bestEntry(line *Domain.StatementLine, entries []Domain.ReconciliationEntry, used map[string]bool) (*Domain.ReconciliationEntry, Domain.MatchMethod) {
+	methods := line.Source.Methods()
+	text := strings.ToLower(line.Reference + " " + line.Description)
+
+	var best *Domain.ReconciliationEntry
+	var bestGap time.Duration
+	for i := range entries {
+		entry := &entries[i]
+		if used[entry.Key()] || entry.Amount != line.Amount {
+			continue
+		}
+		if entry.Type == Domain.ReconciliationSale && !containsMethod(methods, entry.Method) {
+			continue
+		}
+		gap := entry.Date.Sub(line.Date)
+		if gap < 0 {
+			gap = -gap
+		}
+		if gap > Domain.ReconciliationMatchWindow {
+			continue
+		}
+
+		if len(entry.Reference) >= minMatchReference && strings.Contains(text, strings.ToLower(entry.Reference)) {
+			return entry, Domain.MatchByReference
+		}
+		if best == nil || gap < bestGap {
+			best, bestGap = entry, gap
+		}
+	}
+	return best, Domain.MatchByAmountDate
+}
+
+func containsMethod(methods []Domain.PaymentMethod, method Domain.PaymentMethod) bool {
+	for _, m := range methods {
+		if m == method {
+			return true
+		}
+	}
+	return false
+}
+
+// entries returns the payments recorded between start and end that go through
+// a bank or wallet: bank, card and mobile sale payments, and expenses
+func (uc *reconciliationUseCase) entries(businessID string, start, end time.Time) ([]Domain.ReconciliationEntry, error) {
+	completed := Domain.SaleStatusCompleted
+	paid := Domain.PaymentStatusPaid
+	sales, err := uc.salesRepo.FindByBusinessID(businessID, Domain.SaleFilters{
+		StartDate:     &start,
+		EndDate:       &end,
+		Status:        &completed,
+		PaymentStatus: &paid,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sales: %w", err)
+	}
+
+	active := Domain.ExpenseStatusActive
+	expenses, err := uc.expenseRepo.FindByBusinessID(businessID, Domain.ExpenseFilters{
+		StartDate: &start,
+		EndDate:   &end,
+		Status:    &active,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find expenses: %w", err)
+	}
+
+	reconciled := []Domain.PaymentMethod{Domain.PaymentMethodBank, Domain.PaymentMethodCard, Domain.PaymentMethodMobile}
+	var entries []Domain.ReconciliationEntry
+	for i := range sales {
+		entries = append(entries, saleEntries(&sales[i], reconciled)...)
+	}
+	for i := range expenses {
+		entries = append(entries, expenseEntry(&expenses[i]))
+	}
+	return entries, nil
+}
+
+// saleEntries splits a sale into its payments, keeping those paid by one of
+// methods, or all when methods is nil
+func saleEntries(sale *Domain.Sale, methods []Domain.PaymentMethod) []Domain.ReconciliationEntry {
+	if len(sale.Payments) == 0 {
+		if methods != nil && !containsMethod(methods, sale.PaymentMethod) {
+			return nil
+		}
+		return []Domain.ReconciliationEntry{{
+			Type:    Domain.ReconciliationSale,
+			ID:      sale.ID,
+			Payment: -1,
+			Date:    sale.CreatedAt,
+			Amount:  sale.FinalAmount,
+			Method:  sale.PaymentMethod,
+			Label:   sale.CustomerName,
+		}}
+	}
+
+	var entries []Domain.ReconciliationEntry
+	for i, p := range sale.Payments {
+		if methods != nil && !containsMethod(methods, p.Method) {
+			continue
+		}
+		entries = append(entries, Domain.ReconciliationEntry{
+			Type:      Domain.ReconciliationSale,
+			ID:        sale.ID,
+			Payment:   i,
+			Date:      sale.CreatedAt,
+			Amount:    p.Amount,
+			Method:    p.Method,
+			Reference: p.Reference,
+			Label:     sale.CustomerName,
+		})
+	}
+	return entries
+}
+
+func expenseEntry(expense *Domain.Expense) Domain.ReconciliationEntry {
+	return Domain.ReconciliationEntry{
+		Type:   Domain.ReconciliationExpense,
+		ID:     expense.ID,
+		Date:   expense.Date,
+		Amount: -expense.Amount,
+		Label:  expense.Description,
+	}
+}
+
+// GetReport sets the period's statement lines against its recorded payments.
+// Expenses left unmatched are not listed as unrecorded, as most are paid in cash.
+func (uc *reconciliationUseCase) GetReport(businessID string, startDate, endDate time.Time) (*Domain.ReconciliationReport, error) {
+	if endDate.Before(startDate) {
+		return nil, Domain.ValidationError("end_date must not be before start_date")
+	}
+
+	lines, err := uc.reconciliationRepo.FindLines(businessID, Domain.StatementLineFilters{StartDate: &startDate, EndDate: &endDate})
+	if err != nil {
+		return nil, err
+	}
+	entries, err := uc.entries(businessID, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+	used, err := uc.reconciliationRepo.FindMatchKeys(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Domain.ReconciliationReport{
+		StartDate:      startDate,
+		EndDate:        endDate,
+		Lines:          len(lines),
+		UnmatchedLines: []Domain.StatementLine{},
+		Unrecorded:     []Domain.ReconciliationEntry{},
+	}
+
+	for _, line := range lines {
+		if line.Amount > 0 {
+			report.MoneyIn += line.Amount
+		} else {
+			report.MoneyOut -= line.Amount
+		}
+
+		switch line.Status {
+		case Domain.StatementLineMatched:
+			report.MatchedLines++
+			if line.Amount > 0 {
+				report.MatchedIn += line.Amount
+			} else {
+				report.MatchedOut -= line.Amount
+			}
+		case Domain.StatementLineIgnored:
+			report.IgnoredLines++
+		default:
+			if line.Amount > 0 {
+				report.UnmatchedIn += line.Amount
+			} else {
+				report.UnmatchedOut -= line.Amount
+			}
+			report.UnmatchedLines = append(report.UnmatchedLines, line)
+		}
+	}
+
+	for _, entry := range entries {
+		if entry.Type != Domain.ReconciliationSale {
+			continue
+		}
+		report.RecordedIn += entry.Amount
+		if !used[entry.Key()] {
+			report.UnrecordedIn += entry.Amount
+			report.Unrecorded = append(report.Unrecorded, entry)
+		}
+	}
+	sort.SliceStable(report.Unrecorded, func(i, j int) bool { return report.Unrecorded[i].Date.Before(report.Unrecorded[j].Date) })
+
+	report.Reconciled = len(report.UnmatchedLines) == 0 && len(report.Unrecorded) == 0
+	return report, nil
+}
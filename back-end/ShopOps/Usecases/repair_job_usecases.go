@@ -0,0 +1,663 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type RepairJobUseCase interface {
+	CreateJob(ctx context.Context, businessID, userID string, req Domain.CreateRepairJobRequest) (*Domain.RepairJob, error)
+	GetJobs(businessID string, filters Domain.RepairJobFilters) ([]Domain.RepairJob, error)
+	GetJob(ctx context.Context, id, businessID string) (*Domain.RepairJob, error)
+	UpdateJob(ctx context.Context, id, businessID string, req Domain.UpdateRepairJobRequest) (*Domain.RepairJob, error)
+	// SetStatus moves the job along. A job made ready is texted to the customer,
+	// one is collected only once all of it is billed, and cancelling one puts its
+	// parts back into stock.
+	SetStatus(ctx context.Context, id, businessID, userID string, req Domain.SetRepairJobStatusRequest) (*Domain.RepairJob, error)
+	// AddPart fits a product on the job, taking it out of stock
+	AddPart(ctx context.Context, id, businessID, userID string, req Domain.AddRepairPartRequest) (*Domain.RepairJob, error)
+	// RemovePart takes an unbilled part off the job and puts it back into stock
+	RemovePart(ctx context.Context, id, businessID, userID, partID string) (*Domain.RepairJob, error)
+	AddLabor(ctx context.Context, id, businessID, userID string, req Domain.AddRepairLaborRequest) (*Domain.RepairJob, error)
+	RemoveLabor(ctx context.Context, id, businessID, laborID string) (*Domain.RepairJob, error)
+	// BillJob records a sale for each part and labor line not yet billed
+	BillJob(ctx context.Context, id, businessID, userID string, req Domain.BillRepairJobRequest) (*Domain.RepairJob, error)
+	// OnSaleVoided puts a line billed on a voided sale back to be billed again,
+	// for the event bus
+	OnSaleVoided(ctx context.Context, event *Domain.DomainEvent) error
+}
+
+type repairJobUseCase struct {
+	repairRepo      Domain.RepairJobRepository
+	inventoryRepo   Domain.ProductRepository
+	reservationRepo Domain.StockReservationRepository
+	employeeRepo    Domain.EmployeeRepository
+	businessRepo    Domain.BusinessRepository
+	salesUC         SalesUseCase
+	smsUC           SMSUseCase
+}
+
+func NewRepairJobUseCase(
+	repairRepo Domain.RepairJobRepository,
+	inventoryRepo Domain.ProductRepository,
+	reservationRepo Domain.StockReservationRepository,
+	employeeRepo Domain.EmployeeRepository,
+	businessRepo Domain.BusinessRepository,
+	salesUC SalesUseCase,
+	smsUC SMSUseCase,
+) RepairJobUseCase {
+	return &repairJobUseCase{
+		repairRepo:      repairRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		employeeRepo:    employeeRepo,
+		businessRepo:    businessRepo,
+		salesUC:         salesUC,
+		smsUC:           smsUC,
+	}
+}
+
+func (uc *repairJobUseCase) CreateJob(ctx context.Context, businessID, userID string, req Domain.CreateRepairJobRequest) (*Domain.RepairJob, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var technicianID *primitive.ObjectID
+	if req.TechnicianID != nil && *req.TechnicianID != "" {
+		technicianID, err = uc.technician(ctx, businessID, *req.TechnicianID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	count, err := uc.repairRepo.CountByBusinessID(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	job := &Domain.RepairJob{
+		BusinessID:    business.ID,
+		Number:        fmt.Sprintf("RJ-%05d", count+1),
+		CustomerName:  req.CustomerName,
+		CustomerPhone: normalizePhone(req.CustomerPhone, business.Country),
+		Device:        req.Device,
+		Problem:       req.Problem,
+		Estimate:      req.Estimate,
+		Status:        Domain.RepairJobReceived,
+		TechnicianID:  technicianID,
+		Parts:         []Domain.RepairPart{},
+		Labor:         []Domain.RepairLabor{},
+		History:       []Domain.RepairJobEvent{{Status: Domain.RepairJobReceived, By: objUserID, At: now}},
+		CreatedBy:     objUserID,
+	}
+	if err := uc.repairRepo.Create(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (uc *repairJobUseCase) GetJobs(businessID string, filters Domain.RepairJobFilters) ([]Domain.RepairJob, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	jobs, err := uc.repairRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if jobs == nil {
+		jobs = []Domain.RepairJob{}
+	}
+	return jobs, nil
+}
+
+func (uc *repairJobUseCase) GetJob(ctx context.Context, id, businessID string) (*Domain.RepairJob, error) {
+	job, err := uc.repairRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if job == nil {
+		return nil, Domain.NotFoundError("repair job not found")
+	}
+	return job, nil
+}
+
+// openJob finds the job for a change, which a collected or cancelled one takes no more
+func (uc *repairJobUseCase) openJob(ctx context.Context, id, businessID string) (*Domain.RepairJob, error) {
+	job, err := uc.GetJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Closed() {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("repair job is %s", job.Status))
+	}
+	return job, nil
+}
+
+func (uc *repairJobUseCase) UpdateJob(ctx context.Context, id, businessID string, req Domain.UpdateRepairJobRequest) (*Domain.RepairJob, error) {
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != nil && *req.Version != job.Version {
+		return nil, Domain.StaleVersionError("repair job")
+	}
+
+	if req.CustomerName != nil {
+		job.CustomerName = *req.CustomerName
+	}
+	if req.CustomerPhone != nil {
+		job.CustomerPhone = *req.CustomerPhone
+		if business, err := uc.businessRepo.FindByID(businessID); err == nil && business != nil {
+			job.CustomerPhone = normalizePhone(*req.CustomerPhone, business.Country)
+		}
+	}
+	if req.Device != nil {
+		job.Device = *req.Device
+	}
+	if req.Problem != nil {
+		job.Problem = *req.Problem
+	}
+	if req.Diagnosis != nil {
+		job.Diagnosis = *req.Diagnosis
+	}
+	if req.Estimate != nil {
+		job.Estimate = *req.Estimate
+	}
+	if req.TechnicianID != nil {
+		job.TechnicianID = nil
+		if *req.TechnicianID != "" {
+			if job.TechnicianID, err = uc.technician(ctx, businessID, *req.TechnicianID); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if err := uc.repairRepo.Update(job); err != nil {
+		return nil, err
+	}
+	return job, nil
+}
+
+// technician checks the employee is one of the shop's active staff
+func (uc *repairJobUseCase) technician(ctx context.Context, businessID, employeeID string) (*primitive.ObjectID, error) {
+	employee, err := uc.employeeRepo.FindInBusiness(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+	if employee == nil {
+		return nil, Domain.NotFoundError("technician not found")
+	}
+	if employee.Status != Domain.EmployeeStatusActive {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "technician is not active")
+	}
+	return &employee.ID, nil
+}
+
+func (uc *repairJobUseCase) SetStatus(ctx context.Context, id, businessID, userID string, req Domain.SetRepairJobStatusRequest) (*Domain.RepairJob, error) {
+	if !req.Status.IsValid() {
+		return nil, Domain.ValidationError(fmt.Sprintf("invalid status: %s", req.Status))
+	}
+
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if job.Status == req.Status {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("repair job is already %s", job.Status))
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	switch req.Status {
+	case Domain.RepairJobReady:
+		job.CompletedAt = &now
+	case Domain.RepairJobCollected:
+		if job.Unbilled() {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "bill the repair job before it is collected")
+		}
+		job.CollectedAt = &now
+	case Domain.RepairJobCancelled:
+		// What was billed stays billed until its sale is voided
+		if job.Billed() {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "void the repair job's sales before cancelling it")
+		}
+	}
+
+	job.Status = req.Status
+	job.History = append(job.History, Domain.RepairJobEvent{Status: req.Status, Note: req.Note, By: objUserID, At: now})
+	if err := uc.repairRepo.Update(job); err != nil {
+		return nil, err
+	}
+
+	switch job.Status {
+	case Domain.RepairJobReady:
+		uc.notifyReady(job)
+	case Domain.RepairJobCancelled:
+		for _, part := range job.Parts {
+			uc.returnPart(job, part, userID, "Repair job cancelled - restoring stock")
+		}
+	}
+
+	return job, nil
+}
+
+// notifyReady texts the customer that their device is ready to collect
+func (uc *repairJobUseCase) notifyReady(job *Domain.RepairJob) {
+	if job.CustomerPhone == "" {
+		return
+	}
+
+	business, err := uc.businessRepo.FindByID(job.BusinessID.Hex())
+	if err != nil || business == nil {
+		Infrastructure.Logger.Warn("failed to find business for repair job", "repair_job_id", job.ID.Hex(), "error", err)
+		return
+	}
+
+	device := job.Device.Model
+	if device == "" {
+		device = job.Device.Type
+	}
+	body := Infrastructure.Translate(business.Language, "sms.repair_ready",
+		business.Name, device, job.Number, job.Total().String())
+
+	message, err := uc.smsUC.SendNotice(business.ID.Hex(), job.CustomerPhone, nil, body)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to text repair job ready", "repair_job_id", job.ID.Hex(), "error", err)
+		return
+	}
+	if message.Status == Domain.SMSStatusFailed {
+		Infrastructure.Logger.Warn("failed to text repair job ready", "repair_job_id", job.ID.Hex(), "error", message.Error)
+		return
+	}
+
+	now := time.Now()
+	if err := uc.repairRepo.MarkNotified(job.ID, now); err != nil {
+		Infrastructure.Logger.Warn("failed to mark repair job notified", "repair_job_id", job.ID.Hex(), "error", err)
+		return
+	}
+	job.NotifiedAt = &now
+}
+
+func (uc *repairJobUseCase) AddPart(ctx context.Context, id, businessID, userID string, req Domain.AddRepairPartRequest) (*Domain.RepairJob, error) {
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+	if product.Service {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "services are added to a repair job as labor")
+	}
+
+	// Leave what other orders hold
+	available, err := availableStock(uc.reservationRepo, product, nil)
+	if err != nil {
+		return nil, err
+	}
+	if available < req.Quantity {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("insufficient stock. Available: %.2f, Requested: %.2f",
+			available, req.Quantity))
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	part := Domain.RepairPart{
+		ID:        primitive.NewObjectID(),
+		ProductID: product.ID,
+		Name:      product.Name,
+		Quantity:  req.Quantity,
+		UnitPrice: product.SellingPrice,
+		AddedBy:   objUserID,
+		AddedAt:   time.Now(),
+	}
+	if req.UnitPrice != nil {
+		part.UnitPrice = *req.UnitPrice
+	}
+
+	referenceID := job.ID.Hex()
+	if err := uc.inventoryRepo.AdjustStock(
+		product.ID.Hex(),
+		part.Quantity,
+		Domain.MovementTypeRepair,
+		"Fitted on repair job "+job.Number,
+		&referenceID,
+		"repair_job",
+		userID,
+	); err != nil {
+		return nil, err
+	}
+
+	job.Parts = append(job.Parts, part)
+	if err := uc.repairRepo.Update(job); err != nil {
+		uc.returnPart(job, part, userID, "Repair part not saved - restoring stock")
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (uc *repairJobUseCase) RemovePart(ctx context.Context, id, businessID, userID, partID string) (*Domain.RepairJob, error) {
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i := range job.Parts {
+		if job.Parts[i].ID.Hex() == partID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, Domain.NotFoundError("part not found")
+	}
+	part := job.Parts[index]
+	if part.SaleID != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "part is billed; void its sale first")
+	}
+
+	job.Parts = append(job.Parts[:index], job.Parts[index+1:]...)
+	if err := uc.repairRepo.Update(job); err != nil {
+		return nil, err
+	}
+
+	uc.returnPart(job, part, userID, "Removed from repair job - restoring stock")
+	return job, nil
+}
+
+// returnPart puts a part taken off the job back into stock
+func (uc *repairJobUseCase) returnPart(job *Domain.RepairJob, part Domain.RepairPart, userID, reason string) {
+	referenceID := job.ID.Hex()
+	if err := uc.inventoryRepo.AdjustStock(
+		part.ProductID.Hex(),
+		part.Quantity,
+		Domain.MovementTypeReturn,
+		reason,
+		&referenceID,
+		"repair_job",
+		userID,
+	); err != nil {
+		Infrastructure.Logger.Warn("failed to restore stock for part of repair job", "part_id", part.ID.Hex(), "repair_job_id", referenceID, "error", err)
+	}
+}
+
+func (uc *repairJobUseCase) AddLabor(ctx context.Context, id, businessID, userID string, req Domain.AddRepairLaborRequest) (*Domain.RepairJob, error) {
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	labor := Domain.RepairLabor{
+		ID:          primitive.NewObjectID(),
+		Description: req.Description,
+		Quantity:    req.Quantity,
+		AddedBy:     objUserID,
+		AddedAt:     time.Now(),
+	}
+	if labor.Quantity == 0 {
+		labor.Quantity = 1
+	}
+
+	if req.ServiceID != nil && *req.ServiceID != "" {
+		service, err := uc.inventoryRepo.FindInBusiness(ctx, *req.ServiceID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if service == nil || service.DeletedAt != nil {
+			return nil, Domain.NotFoundError("service not found")
+		}
+		if !service.Service {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "product is not a service; add it as a part")
+		}
+		labor.ServiceID = &service.ID
+		labor.UnitPrice = service.SellingPrice
+		if labor.Description == "" {
+			labor.Description = service.Name
+		}
+	}
+	if req.UnitPrice != nil {
+		labor.UnitPrice = *req.UnitPrice
+	}
+
+	if labor.Description == "" {
+		return nil, Domain.ValidationError("description is required without a service")
+	}
+	if labor.UnitPrice <= 0 {
+		return nil, Domain.ValidationError("unit_price is required without a service")
+	}
+
+	job.Labor = append(job.Labor, labor)
+	if err := uc.repairRepo.Update(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (uc *repairJobUseCase) RemoveLabor(ctx context.Context, id, businessID, laborID string) (*Domain.RepairJob, error) {
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	index := -1
+	for i := range job.Labor {
+		if job.Labor[i].ID.Hex() == laborID {
+			index = i
+			break
+		}
+	}
+	if index < 0 {
+		return nil, Domain.NotFoundError("labor not found")
+	}
+	if job.Labor[index].SaleID != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "labor is billed; void its sale first")
+	}
+
+	job.Labor = append(job.Labor[:index], job.Labor[index+1:]...)
+	if err := uc.repairRepo.Update(job); err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (uc *repairJobUseCase) BillJob(ctx context.Context, id, businessID, userID string, req Domain.BillRepairJobRequest) (*Domain.RepairJob, error) {
+	// Gift cards and points need a reference, taken at the till
+	switch req.PaymentMethod {
+	case Domain.PaymentMethodCash, Domain.PaymentMethodCard, Domain.PaymentMethodMobile,
+		Domain.PaymentMethodBank, Domain.PaymentMethodCredit, Domain.PaymentMethodOther:
+	default:
+		return nil, Domain.ValidationError(fmt.Sprintf("invalid payment method: %s", req.PaymentMethod))
+	}
+
+	job, err := uc.openJob(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if !job.Unbilled() {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "nothing left to bill on the repair job")
+	}
+
+	// Sales are credited to the technician
+	var employeeID *string
+	if job.TechnicianID != nil {
+		technicianID := job.TechnicianID.Hex()
+		employeeID = &technicianID
+	}
+
+	bill := func(lineID primitive.ObjectID, productID *primitive.ObjectID, description string, quantity float64, unitPrice Domain.Money, stockTaken bool) (*primitive.ObjectID, error) {
+		var saleProductID *string
+		if productID != nil {
+			id := productID.Hex()
+			saleProductID = &id
+		}
+		sale, err := uc.salesUC.CreateSale(ctx, businessID, userID, Domain.CreateSaleRequest{
+			ProductID:     saleProductID,
+			CustomerName:  job.CustomerName,
+			CustomerPhone: job.CustomerPhone,
+			Quantity:      quantity,
+			UnitPrice:     unitPrice,
+			PaymentMethod: req.PaymentMethod,
+			Notes:         fmt.Sprintf("Repair job %s: %s", job.Number, description),
+			LocalID:       "repair:" + job.ID.Hex() + ":" + lineID.Hex(),
+			EmployeeID:    employeeID,
+			StockTaken:    stockTaken,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return &sale.ID, nil
+	}
+
+	// Billed lines are saved even when a later one fails, so billing again picks up the rest
+	billed := map[primitive.ObjectID]primitive.ObjectID{}
+	var billErr error
+	for _, part := range job.Parts {
+		if part.SaleID != nil {
+			continue
+		}
+		saleID, err := bill(part.ID, &part.ProductID, part.Name, part.Quantity, part.UnitPrice, true)
+		if err != nil {
+			billErr = err
+			break
+		}
+		billed[part.ID] = *saleID
+	}
+	for _, labor := range job.Labor {
+		if billErr != nil {
+			break
+		}
+		if labor.SaleID != nil {
+			continue
+		}
+		saleID, err := bill(labor.ID, labor.ServiceID, labor.Description, labor.Quantity, labor.UnitPrice, false)
+		if err != nil {
+			billErr = err
+			break
+		}
+		billed[labor.ID] = *saleID
+	}
+
+	if len(billed) > 0 {
+		if job, err = uc.recordSales(ctx, job, billed); err != nil {
+			return nil, err
+		}
+	}
+	if billErr != nil {
+		return nil, billErr
+	}
+
+	return job, nil
+}
+
+// recordSales sets the sales billed on the job's lines, reading the job again if
+// it changed meanwhile
+func (uc *repairJobUseCase) recordSales(ctx context.Context, job *Domain.RepairJob, billed map[primitive.ObjectID]primitive.ObjectID) (*Domain.RepairJob, error) {
+	for attempt := 0; ; attempt++ {
+		for i := range job.Parts {
+			if saleID, ok := billed[job.Parts[i].ID]; ok {
+				job.Parts[i].SaleID = &saleID
+			}
+		}
+		for i := range job.Labor {
+			if saleID, ok := billed[job.Labor[i].ID]; ok {
+				job.Labor[i].SaleID = &saleID
+			}
+		}
+
+		err := uc.repairRepo.Update(job)
+		if err == nil {
+			return job, nil
+		}
+		if !isConflict(err) || attempt >= 2 {
+			Infrastructure.Logger.Warn("failed to record sales billed on repair job", "repair_job_id", job.ID.Hex(), "error", err)
+			return nil, err
+		}
+
+		if job, err = uc.repairRepo.FindInBusiness(ctx, job.ID.Hex()); err != nil {
+			return nil, err
+		}
+		if job == nil {
+			return nil, Domain.NotFoundError("repair job not found")
+		}
+	}
+}
+
+func (uc *repairJobUseCase) OnSaleVoided(ctx context.Context, event *Domain.DomainEvent) error {
+	saleID, err := primitive.ObjectIDFromHex(event.AggregateID)
+	if err != nil {
+		return nil
+	}
+
+	for attempt := 0; attempt < 3; attempt++ {
+		job, err := uc.repairRepo.FindBySale(saleID)
+		if err != nil {
+			return err
+		}
+		if job == nil {
+			return nil
+		}
+
+		for i := range job.Parts {
+			if job.Parts[i].SaleID != nil && *job.Parts[i].SaleID == saleID {
+				job.Parts[i].SaleID = nil
+			}
+		}
+		for i := range job.Labor {
+			if job.Labor[i].SaleID != nil && *job.Labor[i].SaleID == saleID {
+				job.Labor[i].SaleID = nil
+			}
+		}
+
+		if err := uc.repairRepo.Update(job); !isConflict(err) {
+			return err
+		}
+	}
+
+	return fmt.Errorf("repair job billed on sale %s kept changing", saleID.Hex())
+}
+
+// isConflict reports whether err is a record changed by someone else since it was read
+func isConflict(err error) bool {
+	var appErr *Domain.AppError
+	return errors.As(err, &appErr) && appErr.Code == Domain.ErrCodeConflict
+}
@@ -1,7 +1,12 @@
 package Usecases
 
 import (
+	"bytes"
+	"context"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	Domain "ShopOps/Domain"
@@ -12,39 +17,78 @@ type ReportUseCase interface {
 	GenerateReport(req Domain.ReportRequest) (interface{}, error)
 	GetDashboardData(businessID string) (*Domain.DashboardData, error)
 	ExportReport(req Domain.ReportRequest) ([]byte, string, error)
-	GetProfitSummary(businessID string, period Domain.PeriodType, startDate, endDate *time.Time) (*Domain.ProfitReport, error)
-	GetProfitTrends(businessID string, period Domain.PeriodType, weeks int) ([]Domain.ProfitTrend, error)
+	// QueueExportEmail exports the report in the background and emails the user a
+	// download link when it is ready
+	QueueExportEmail(ctx context.Context, req Domain.ReportRequest, userID string) (*Domain.Job, error)
+	RunExportEmailJob(ctx context.Context, job *Domain.Job) error
+	GetProfitSummary(businessID string, period Domain.PeriodType, startDate, endDate *time.Time, calendar Domain.Calendar) (*Domain.ProfitReport, error)
+	GetProfitTrends(businessID string, period Domain.PeriodType, weeks int, calendar Domain.Calendar) ([]Domain.ProfitTrend, error)
 	ComparePeriods(businessID string, period1, period2 Domain.ReportRequest) (interface{}, error)
+	CompareReport(req Domain.ReportRequest, opts Domain.CompareOptions) (*Domain.ReportComparison, error)
+	GetDeadStockReport(businessID string, opts Domain.DeadStockOptions) (*Domain.DeadStockReport, error)
 }
 
 type reportUseCase struct {
-	reportRepo    Domain.ReportRepository
-	businessRepo  Domain.BusinessRepository
-	exportService Infrastructure.ExportService
+	reportRepo      Domain.ReportRepository
+	businessRepo    Domain.BusinessRepository
+	exportService   Infrastructure.ExportService
+	segmentRepo     Domain.SegmentRepository
+	analyticsUC     AnalyticsUseCase
+	userRepo        Domain.UserRepository
+	files           Infrastructure.FileStorage
+	jobQueue        Infrastructure.JobQueue
+	emailUC         EmailUseCase
+	customFieldRepo Domain.CustomFieldRepository
 }
 
+// Emailed exports can be downloaded from their link for this long
+const exportLinkTTL = 7 * 24 * time.Hour
+
 func NewReportUseCase(
 	reportRepo Domain.ReportRepository,
 	businessRepo Domain.BusinessRepository,
 	exportService Infrastructure.ExportService,
+	segmentRepo Domain.SegmentRepository,
+	analyticsUC AnalyticsUseCase,
+	userRepo Domain.UserRepository,
+	files Infrastructure.FileStorage,
+	jobQueue Infrastructure.JobQueue,
+	emailUC EmailUseCase,
+	customFieldRepo Domain.CustomFieldRepository,
 ) ReportUseCase {
 	return &reportUseCase{
-		reportRepo:    reportRepo,
-		businessRepo:  businessRepo,
-		exportService: exportService,
+		reportRepo:      reportRepo,
+		businessRepo:    businessRepo,
+		exportService:   exportService,
+		segmentRepo:     segmentRepo,
+		analyticsUC:     analyticsUC,
+		userRepo:        userRepo,
+		files:           files,
+		jobQueue:        jobQueue,
+		emailUC:         emailUC,
+		customFieldRepo: customFieldRepo,
 	}
 }
 
 func (uc *reportUseCase) GenerateReport(req Domain.ReportRequest) (interface{}, error) {
 	// Validate business exists
-	_, err := uc.businessRepo.FindByID(req.BusinessID)
+	business, err := uc.businessRepo.FindByID(req.BusinessID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find business: %w", err)
 	}
 
+	if _, err := normalizeCalendar(req.Calendar); err != nil {
+		return nil, err
+	}
+
 	// Set default dates based on period
-	startDate, endDate := uc.getDateRange(req.Period, req.StartDate, req.EndDate)
+	startDate, endDate := uc.getDateRange(clockFor(business), req.Period, req.StartDate, req.EndDate, req.Calendar)
+
+	return uc.generateReport(req, startDate, endDate)
+}
 
+// generateReport builds the report between two instants already resolved from the request
+func (uc *reportUseCase) generateReport(req Domain.ReportRequest, startDate, endDate time.Time) (interface{}, error) {
 	switch req.Type {
 	case Domain.ReportTypeSales:
 		return uc.reportRepo.GenerateSalesReport(req.BusinessID, startDate, endDate)
@@ -54,6 +98,13 @@ func (uc *reportUseCase) GenerateReport(req Domain.ReportRequest) (interface{},
 		return uc.reportRepo.GenerateProfitReport(req.BusinessID, startDate, endDate)
 	case Domain.ReportTypeInventory:
 		return uc.reportRepo.GenerateInventoryReport(req.BusinessID)
+	case Domain.ReportTypeDeadStock:
+		return uc.GetDeadStockReport(req.BusinessID, Domain.DeadStockOptions{
+			Days:        req.Days,
+			IncludeSlow: true,
+			SortBy:      req.SortBy,
+			Order:       req.Order,
+		})
 	default:
 		return nil, fmt.Errorf("invalid report type: %s", req.Type)
 	}
@@ -61,27 +112,72 @@ func (uc *reportUseCase) GenerateReport(req Domain.ReportRequest) (interface{},
 
 func (uc *reportUseCase) GetDashboardData(businessID string) (*Domain.DashboardData, error) {
 	// Validate business exists
-	_, err := uc.businessRepo.FindByID(businessID)
+	business, err := uc.businessRepo.FindByID(businessID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find business: %w", err)
 	}
 
-	return uc.reportRepo.GetDashboardData(businessID)
+	// Sales totals come from the summary tables, so bring them up to date first
+	uc.analyticsUC.Refresh(businessID)
+
+	clock := clockFor(business)
+	data, err := uc.reportRepo.GetDashboardData(businessID, clock.Start(clock.Day(time.Now())))
+	if err != nil {
+		return nil, err
+	}
+
+	// Segment counts are cached on the segment and refreshed in the background
+	segments, err := uc.segmentRepo.FindForDashboard(businessID)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to load dashboard segments", "error", err)
+	}
+	for _, segment := range segments {
+		data.Segments = append(data.Segments, Domain.SegmentCount{
+			SegmentID:   segment.ID.Hex(),
+			Name:        segment.Name,
+			MemberCount: segment.MemberCount,
+			EvaluatedAt: segment.EvaluatedAt,
+		})
+	}
+
+	return data, nil
 }
 
-func (uc *reportUseCase) ExportReport(req Domain.ReportRequest) ([]byte, string, error) {
+func (uc *reportUseCase) ExportReport(req Domain.ReportRequest) (data []byte, filename string, err error) {
+	format := "json"
+	if req.Format != nil && *req.Format == "csv" {
+		format = "csv"
+	}
+	start := time.Now()
+	defer func() {
+		outcome := "success"
+		if err != nil {
+			outcome = "failure"
+		}
+		Infrastructure.ExportDuration.ObserveSince(start, string(req.Type), format, outcome)
+	}()
+
 	// Generate report
 	report, err := uc.GenerateReport(req)
 	if err != nil {
 		return nil, "", fmt.Errorf("failed to generate report: %w", err)
 	}
+	if !req.IncludePII {
+		report = Domain.Redacted(report)
+	}
 
-	var data []byte
-	var filename string
-
-	if req.Format != nil && *req.Format == "csv" {
-		// Export to CSV
-		data, err = uc.exportService.ExportToCSV(report, req.Type)
+	if format == "csv" {
+		// Export to CSV, with a column for each of the shop's custom fields and
+		// times in the shop's timezone
+		fields, err := uc.customFieldRepo.FindByBusinessID(req.BusinessID, "", true)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to load custom fields: %w", err)
+		}
+		business, err := uc.businessRepo.FindByID(req.BusinessID)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to find business: %w", err)
+		}
+		data, err = uc.exportService.ExportToCSV(report, req.Type, req.Calendar, req.Language, businessLocation(business), fields)
 		if err != nil {
 			return nil, "", fmt.Errorf("failed to export to CSV: %w", err)
 		}
@@ -100,21 +196,126 @@ func (uc *reportUseCase) ExportReport(req Domain.ReportRequest) ([]byte, string,
 	return data, filename, nil
 }
 
-func (uc *reportUseCase) GetProfitSummary(businessID string, period Domain.PeriodType, startDate, endDate *time.Time) (*Domain.ProfitReport, error) {
-	if startDate == nil || endDate == nil {
-		startDateVal, endDateVal := uc.getDateRange(period, nil, nil)
-		startDate = &startDateVal
-		endDate = &endDateVal
+func (uc *reportUseCase) QueueExportEmail(ctx context.Context, req Domain.ReportRequest, userID string) (*Domain.Job, error) {
+	user, err := uc.userRepo.FindByID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find user: %w", err)
+	}
+	if user == nil || user.Email == "" {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "add an email address to your account to have exports emailed")
+	}
+
+	payload := map[string]string{
+		"user_id":  userID,
+		"to":       user.Email,
+		"type":     string(req.Type),
+		"period":   string(req.Period),
+		"calendar": string(req.Calendar),
+		"language": string(req.Language),
+		"days":     strconv.Itoa(req.Days),
+		"sort_by":  req.SortBy,
+		"order":    req.Order,
+	}
+	if req.IncludePII {
+		payload["include_pii"] = "true"
+	}
+	if req.StartDate != nil {
+		payload["start_date"] = req.StartDate.Format("2006-01-02")
+	}
+	if req.EndDate != nil {
+		payload["end_date"] = req.EndDate.Format("2006-01-02")
+	}
+
+	return uc.jobQueue.Enqueue(ctx, Domain.JobTypeReportExport, req.BusinessID, payload)
+}
+
+func (uc *reportUseCase) RunExportEmailJob(ctx context.Context, job *Domain.Job) error {
+	if job.BusinessID == nil {
+		return fmt.Errorf("export job has no business")
+	}
+	businessID := job.BusinessID.Hex()
+
+	format := "csv"
+	req := Domain.ReportRequest{
+		BusinessID: businessID,
+		Type:       Domain.ReportType(job.Payload["type"]),
+		Period:     Domain.PeriodType(job.Payload["period"]),
+		Format:     &format,
+		SortBy:     job.Payload["sort_by"],
+		Order:      job.Payload["order"],
+		Calendar:   Domain.Calendar(job.Payload["calendar"]),
+		Language:   Domain.Language(job.Payload["language"]),
+		IncludePII: job.Payload["include_pii"] == "true",
+	}
+	req.Days, _ = strconv.Atoi(job.Payload["days"])
+	if date, err := time.Parse("2006-01-02", job.Payload["start_date"]); err == nil {
+		req.StartDate = &date
+	}
+	if date, err := time.Parse("2006-01-02", job.Payload["end_date"]); err == nil {
+		req.EndDate = &date
+	}
+
+	data, filename, err := uc.ExportReport(req)
+	if err != nil {
+		return err
+	}
+
+	// The job ID keeps the link from being guessed from the report type and time
+	url, err := uc.files.Save(businessID+"/exports", job.ID.Hex()+"-"+filename, bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to save export: %w", err)
 	}
 
-	return uc.reportRepo.GenerateProfitReport(businessID, *startDate, *endDate)
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil
+	}
+
+	// Inventory and dead stock are as of now, whatever the period
+	var period string
+	switch {
+	case req.Type == Domain.ReportTypeInventory || req.Type == Domain.ReportTypeDeadStock:
+	default:
+		clock := clockFor(business)
+		start, end := uc.getDateRange(clock, req.Period, req.StartDate, req.EndDate, req.Calendar)
+		period = clock.Day(start).Format("2006-01-02") + " to " + clock.Day(end).Format("2006-01-02")
+	}
+
+	return uc.emailUC.Send(ctx, businessID, job.Payload["to"], Domain.EmailTemplateExportReady, Infrastructure.EmailExportReadyData{
+		ShopName:   business.Name,
+		ReportType: strings.ReplaceAll(string(req.Type), "_", " "),
+		Period:     period,
+		URL:        uc.emailUC.Link(uc.files.SignURL(url, exportLinkTTL)),
+	})
+}
+
+func (uc *reportUseCase) GetProfitSummary(businessID string, period Domain.PeriodType, startDate, endDate *time.Time, calendar Domain.Calendar) (*Domain.ProfitReport, error) {
+	if _, err := normalizeCalendar(calendar); err != nil {
+		return nil, err
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+
+	start, end := uc.getDateRange(clockFor(business), period, startDate, endDate, calendar)
+	return uc.reportRepo.GenerateProfitReport(businessID, start, end)
 }
 
-func (uc *reportUseCase) GetProfitTrends(businessID string, period Domain.PeriodType, weeks int) ([]Domain.ProfitTrend, error) {
+func (uc *reportUseCase) GetProfitTrends(businessID string, period Domain.PeriodType, weeks int, calendar Domain.Calendar) ([]Domain.ProfitTrend, error) {
 	if weeks <= 0 {
 		weeks = 12 // Default to 12 weeks
 	}
 
+	calendar, err := normalizeCalendar(calendar)
+	if err != nil {
+		return nil, err
+	}
+
 	var trends []Domain.ProfitTrend
 	now := time.Now()
 
@@ -137,7 +338,7 @@ func (uc *reportUseCase) GetProfitTrends(businessID string, period Domain.Period
 			continue
 		}
 
-		periodLabel := uc.getPeriodLabel(startDate, endDate, period)
+		periodLabel := uc.getPeriodLabel(startDate, endDate, period, calendar)
 
 		trends = append(trends, Domain.ProfitTrend{
 			Period:   periodLabel,
@@ -171,20 +372,196 @@ func (uc *reportUseCase) ComparePeriods(businessID string, period1, period2 Doma
 	return comparison, nil
 }
 
-func (uc *reportUseCase) getDateRange(period Domain.PeriodType, customStart, customEnd *time.Time) (time.Time, time.Time) {
+const (
+	defaultDeadStockDays     = 90
+	defaultSlowCoverDays     = 180
+	maxDeadStockDays         = 730
+	deadStockSortCapital     = "capital"
+	deadStockSortSinceSale   = "days_since_sale"
+	deadStockSortStock       = "stock"
+	deadStockSortProductName = "name"
+)
+
+// GetDeadStockReport finds stock that has not sold in opts.Days days, and optionally stock
+// selling too slowly to clear within opts.SlowCoverDays, with the capital tied up in each
+// CompareReport generates a sales, expenses or profit report for the requested period and
+// for the comparable prior period, with the change in each headline metric
+func (uc *reportUseCase) CompareReport(req Domain.ReportRequest, opts Domain.CompareOptions) (*Domain.ReportComparison, error) {
+	switch req.Type {
+	case Domain.ReportTypeSales, Domain.ReportTypeExpenses, Domain.ReportTypeProfit:
+	default:
+		return nil, fmt.Errorf("comparison is not available for %s reports", req.Type)
+	}
+
+	opts, err := normalizeCompareOptions(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	business, err := uc.businessRepo.FindByID(req.BusinessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if _, err := normalizeCalendar(req.Calendar); err != nil {
+		return nil, err
+	}
+
+	clock := clockFor(business)
+	startDate, endDate := uc.getDateRange(clock, req.Period, req.StartDate, req.EndDate, req.Calendar)
+	prevStart, prevEnd := previousRange(startDate, endDate, spanDays(startDate, endDate), opts)
+
+	current, err := uc.generateReport(req, startDate, endDate)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := uc.generateReport(req, prevStart, prevEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate report for the previous period: %w", err)
+	}
+
+	return newReportComparison(opts, clock.Day(startDate), clock.Day(endDate), clock.Day(prevStart), clock.Day(prevEnd), current, previous), nil
+}
+
+func (uc *reportUseCase) GetDeadStockReport(businessID string, opts Domain.DeadStockOptions) (*Domain.DeadStockReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if opts.Days == 0 {
+		opts.Days = defaultDeadStockDays
+	}
+	if opts.Days < 1 || opts.Days > maxDeadStockDays {
+		return nil, fmt.Errorf("days must be between 1 and %d", maxDeadStockDays)
+	}
+	if opts.SlowCoverDays == 0 {
+		opts.SlowCoverDays = defaultSlowCoverDays
+	}
+	if opts.SlowCoverDays < 1 {
+		return nil, fmt.Errorf("slow_cover_days must be positive")
+	}
+	if opts.SortBy == "" {
+		opts.SortBy = deadStockSortCapital
+	}
+	switch opts.SortBy {
+	case deadStockSortCapital, deadStockSortSinceSale, deadStockSortStock, deadStockSortProductName:
+	default:
+		return nil, fmt.Errorf("invalid sort_by: %s", opts.SortBy)
+	}
+	if opts.Order != "" && opts.Order != "asc" && opts.Order != "desc" {
+		return nil, fmt.Errorf("invalid order: %s", opts.Order)
+	}
+
+	now := time.Now()
+	since := now.AddDate(0, 0, -opts.Days)
+
+	items, err := uc.reportRepo.GetStockActivity(businessID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &Domain.DeadStockReport{
+		Days:          opts.Days,
+		SlowCoverDays: opts.SlowCoverDays,
+		SortBy:        opts.SortBy,
+		Items:         []Domain.DeadStockItem{},
+	}
+
+	for _, item := range items {
+		// Products newer than the window have not had the chance to sell yet
+		if item.LastSoldAt == nil && item.CreatedAt.After(since) {
+			continue
+		}
+
+		if item.LastSoldAt != nil {
+			days := int(now.Sub(*item.LastSoldAt).Hours() / 24)
+			item.DaysSinceSale = &days
+		}
+
+		if item.SoldInWindow <= 0 {
+			item.Status = Domain.DeadStockStatusDead
+		} else {
+			cover := roundCurrency(item.Stock / (item.SoldInWindow / float64(opts.Days)))
+			item.DaysOfCover = &cover
+			if !opts.IncludeSlow || cover <= float64(opts.SlowCoverDays) {
+				continue
+			}
+			item.Status = Domain.DeadStockStatusSlow
+		}
+
+		item.TiedUpCapital = item.CostPrice.Times(item.Stock)
+		item.RetailValue = item.SellingPrice.Times(item.Stock)
+
+		if item.Status == Domain.DeadStockStatusDead {
+			report.DeadCount++
+			report.DeadCapital += item.TiedUpCapital
+		} else {
+			report.SlowCount++
+			report.SlowCapital += item.TiedUpCapital
+		}
+
+		report.Items = append(report.Items, item)
+	}
+
+	report.TotalTiedUpCapital = report.DeadCapital + report.SlowCapital
+
+	sortDeadStock(report.Items, opts.SortBy, opts.Order)
+
+	return report, nil
+}
+
+// sortDeadStock orders items by the given key. Names default to A-Z, everything else
+// to largest first; never-sold items count as the longest since a sale.
+func sortDeadStock(items []Domain.DeadStockItem, sortBy, order string) {
+	less := func(a, b Domain.DeadStockItem) bool {
+		switch sortBy {
+		case deadStockSortSinceSale:
+			if a.DaysSinceSale == nil || b.DaysSinceSale == nil {
+				return a.DaysSinceSale != nil && b.DaysSinceSale == nil
+			}
+			return *a.DaysSinceSale < *b.DaysSinceSale
+		case deadStockSortStock:
+			return a.Stock < b.Stock
+		case deadStockSortProductName:
+			return strings.ToLower(a.ProductName) < strings.ToLower(b.ProductName)
+		default:
+			return a.TiedUpCapital < b.TiedUpCapital
+		}
+	}
+
+	ascending := order == "asc" || (order == "" && sortBy == deadStockSortProductName)
+
+	sort.SliceStable(items, func(i, j int) bool {
+		if ascending {
+			return less(items[i], items[j])
+		}
+		return less(items[j], items[i])
+	})
+}
+
+// getDateRange resolves a period, or custom start and end days, to the instants it
+// covers. Days are the shop's business days, so the end is the last instant before
+// the day after the end day starts.
+func (uc *reportUseCase) getDateRange(clock businessClock, period Domain.PeriodType, customStart, customEnd *time.Time, calendar Domain.Calendar) (time.Time, time.Time) {
 	now := time.Now()
 
 	// Use custom dates if provided
 	if customStart != nil && customEnd != nil {
-		return *customStart, *customEnd
+		start, end := clock.Range(*customStart, *customEnd)
+		return start, end.Add(-time.Nanosecond)
 	}
 
 	var startDate, endDate time.Time
 
 	switch period {
 	case Domain.PeriodTypeDaily:
-		startDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
-		endDate = startDate.Add(24 * time.Hour)
+		today := clock.Day(now)
+		startDate, endDate = clock.Range(today, today)
+		endDate = endDate.Add(-time.Nanosecond)
 	case Domain.PeriodTypeWeekly:
 		// Last 7 days
 		endDate = now
@@ -197,6 +574,11 @@ func (uc *reportUseCase) getDateRange(period Domain.PeriodType, customStart, cus
 		// Last 365 days
 		endDate = now
 		startDate = now.AddDate(0, 0, -365)
+	case Domain.PeriodTypeFiscalYearly:
+		// Fiscal year to date; the Ethiopian fiscal year starts on Hamle 1
+		_, yearStart := Domain.FiscalYear(clock.Day(now), calendar)
+		endDate = now
+		startDate = clock.Start(yearStart)
 	case Domain.PeriodTypeCustom:
 		// Default to last 30 days
 		endDate = now
@@ -210,7 +592,26 @@ func (uc *reportUseCase) getDateRange(period Domain.PeriodType, customStart, cus
 	return startDate, endDate
 }
 
-func (uc *reportUseCase) getPeriodLabel(startDate, endDate time.Time, period Domain.PeriodType) string {
+func (uc *reportUseCase) getPeriodLabel(startDate, endDate time.Time, period Domain.PeriodType, calendar Domain.Calendar) string {
+	if calendar == Domain.CalendarEthiopian {
+		start, end := Domain.ToEthiopian(startDate), Domain.ToEthiopian(endDate)
+		switch period {
+		case Domain.PeriodTypeDaily:
+			return fmt.Sprintf("%s %02d", start.MonthName(), start.Day)
+		case Domain.PeriodTypeMonthly:
+			return fmt.Sprintf("%s %d", start.MonthName(), start.Year)
+		case Domain.PeriodTypeYearly:
+			return fmt.Sprintf("%d", start.Year)
+		case Domain.PeriodTypeFiscalYearly:
+			year, _ := Domain.FiscalYear(startDate, calendar)
+			return fmt.Sprintf("FY%d", year)
+		default:
+			return fmt.Sprintf("%s %02d to %s %02d",
+				start.MonthName(), start.Day,
+				end.MonthName(), end.Day)
+		}
+	}
+
 	switch period {
 	case Domain.PeriodTypeDaily:
 		return startDate.Format("Jan 02")
@@ -221,6 +622,9 @@ func (uc *reportUseCase) getPeriodLabel(startDate, endDate time.Time, period Dom
 		return startDate.Format("Jan 2006")
 	case Domain.PeriodTypeYearly:
 		return startDate.Format("2006")
+	case Domain.PeriodTypeFiscalYearly:
+		year, _ := Domain.FiscalYear(startDate, calendar)
+		return fmt.Sprintf("FY%d", year)
 	default:
 		return fmt.Sprintf("%s to %s",
 			startDate.Format("Jan 02"),
@@ -0,0 +1,207 @@
+package Usecases
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// RetentionUseCase removes customers' details from what is past each shop's
+// retention and empties the trash of what has expired, daily, reporting to each
+// shop what it touched
+type RetentionUseCase interface {
+	// ApplyDue anonymizes and purges everything due, for the periodic run
+	ApplyDue() error
+	GetReports(businessID string, limit int) ([]Domain.RetentionReport, error)
+}
+
+type retentionUseCase struct {
+	retentionRepo Domain.RetentionRepository
+	settingsRepo  Domain.ShopSettingsRepository
+	inventoryRepo Domain.ProductRepository
+	customerRepo  Domain.CustomerRepository
+	supplierRepo  Domain.SupplierRepository
+	cfg           Infrastructure.RetentionConfig
+}
+
+func NewRetentionUseCase(
+	retentionRepo Domain.RetentionRepository,
+	settingsRepo Domain.ShopSettingsRepository,
+	inventoryRepo Domain.ProductRepository,
+	customerRepo Domain.CustomerRepository,
+	supplierRepo Domain.SupplierRepository,
+	cfg Infrastructure.RetentionConfig,
+) RetentionUseCase {
+	return &retentionUseCase{
+		retentionRepo: retentionRepo,
+		settingsRepo:  settingsRepo,
+		inventoryRepo: inventoryRepo,
+		customerRepo:  customerRepo,
+		supplierRepo:  supplierRepo,
+		cfg:           cfg,
+	}
+}
+
+// retentionRun collects each shop's report as a run goes
+type retentionRun struct {
+	trashBefore time.Time
+	startedAt   time.Time
+	reports     map[string]*Domain.RetentionReport
+}
+
+func (run *retentionRun) anonymized(collection string, before time.Time, counts map[string]int64) {
+	for businessID, count := range counts {
+		if report := run.report(businessID, count); report != nil {
+			report.AnonymizedBefore = &before
+			report.Anonymized[collection] += count
+		}
+	}
+}
+
+func (run *retentionRun) purged(collection string, counts map[string]int64) {
+	for businessID, count := range counts {
+		if report := run.report(businessID, count); report != nil {
+			report.Purged[collection] += count
+		}
+	}
+}
+
+// report is the shop's report, started on its first touch; nil when count is zero
+func (run *retentionRun) report(businessID string, count int64) *Domain.RetentionReport {
+	if count == 0 {
+		return nil
+	}
+	if report, ok := run.reports[businessID]; ok {
+		return report
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil
+	}
+	report := &Domain.RetentionReport{
+		BusinessID:  objBusinessID,
+		TrashBefore: run.trashBefore,
+		Anonymized:  make(map[string]int64),
+		Purged:      make(map[string]int64),
+		RunAt:       run.startedAt,
+	}
+	run.reports[businessID] = report
+	return report
+}
+
+// ApplyDue saves the shops' reports even when the run stops early, so what was
+// touched is always on record; the next run carries on from there
+func (uc *retentionUseCase) ApplyDue() error {
+	now := time.Now()
+	run := &retentionRun{
+		trashBefore: now.Add(-Domain.TrashRetention),
+		startedAt:   now,
+		reports:     make(map[string]*Domain.RetentionReport),
+	}
+	err := uc.apply(run)
+
+	reports := make([]Domain.RetentionReport, 0, len(run.reports))
+	for _, report := range run.reports {
+		reports = append(reports, *report)
+	}
+	sort.Slice(reports, func(i, j int) bool {
+		return reports[i].BusinessID.Hex() < reports[j].BusinessID.Hex()
+	})
+	if saveErr := uc.retentionRepo.SaveReports(reports); saveErr != nil {
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to save retention reports", "error", saveErr)
+			return err
+		}
+		return saveErr
+	}
+
+	if err != nil {
+		return fmt.Errorf("retention run stopped: %w", err)
+	}
+	return nil
+}
+
+func (uc *retentionUseCase) apply(run *retentionRun) error {
+	shops, err := uc.settingsRepo.FindAnonymizing()
+	if err != nil {
+		return err
+	}
+	today := time.Date(run.startedAt.Year(), run.startedAt.Month(), run.startedAt.Day(), 0, 0, 0, 0, time.UTC)
+	for _, shop := range shops {
+		before := today.AddDate(-shop.AnonymizeAfterYears, 0, 0)
+		for _, collection := range Domain.AnonymizedCollections {
+			err := uc.drain(func() (map[string]int64, error) {
+				count, err := uc.retentionRepo.Anonymize(shop.BusinessID, collection, before, uc.cfg.BatchSize)
+				if count == 0 {
+					return nil, err
+				}
+				return map[string]int64{shop.BusinessID.Hex(): count}, err
+			}, func(counts map[string]int64) {
+				run.anonymized(collection.Name, before, counts)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	trash := []struct {
+		collection string
+		purge      func(before time.Time, limit int) (map[string]int64, error)
+	}{
+		{"products", uc.inventoryRepo.PurgeDeleted},
+		{"customers", uc.customerRepo.PurgeDeleted},
+		{"suppliers", uc.supplierRepo.PurgeDeleted},
+	}
+	for _, t := range trash {
+		err := uc.drain(func() (map[string]int64, error) {
+			return t.purge(run.trashBefore, uc.cfg.BatchSize)
+		}, func(counts map[string]int64) {
+			run.purged(t.collection, counts)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// drain runs batch until it finds nothing left, passing on what each batch touched,
+// a failed batch's partial counts included
+func (uc *retentionUseCase) drain(batch func() (map[string]int64, error), touched func(map[string]int64)) error {
+	for {
+		if Infrastructure.IsShuttingDown() {
+			return fmt.Errorf("server shutting down")
+		}
+		counts, err := batch()
+		touched(counts)
+		if err != nil {
+			return err
+		}
+		if len(counts) == 0 {
+			return nil
+		}
+	}
+}
+
+func (uc *retentionUseCase) GetReports(businessID string, limit int) ([]Domain.RetentionReport, error) {
+	if limit <= 0 || limit > 100 {
+		limit = 20
+	}
+
+	reports, err := uc.retentionRepo.FindReports(businessID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if reports == nil {
+		reports = []Domain.RetentionReport{}
+	}
+	return reports, nil
+}
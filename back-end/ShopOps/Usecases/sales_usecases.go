@@ -1,75 +1,173 @@
 package Usecases
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"time"
 
 	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type SalesUseCase interface {
-	CreateSale(businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error)
-	GetSaleByID(id, businessID string) (*Domain.Sale, error)
+	// CreateSale records a sale; ctx carries the request's log fields into follow-up work
+	CreateSale(ctx context.Context, businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error)
+	GetSaleByID(ctx context.Context, id, businessID string) (*Domain.Sale, error)
 	GetSales(businessID string, filters Domain.SaleFilters) ([]Domain.Sale, error)
-	UpdateSale(id, businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error)
-	VoidSale(id, businessID, userID string) error
+	// ExpandSales embeds the related records named in expand (see Domain.SaleExpansions),
+	// with one query per kind of record for the whole list
+	ExpandSales(businessID string, sales []Domain.Sale, expand []string) ([]Domain.SaleListItem, error)
+	CountSales(businessID string, filters Domain.SaleFilters) (int64, error)
+	UpdateSale(ctx context.Context, id, businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error)
+	VoidSale(ctx context.Context, id, businessID, userID string) error
 	GetSalesSummary(businessID string, period string) (*Domain.SaleSummary, error)
 	GetSalesStats(businessID string, period string) (*Domain.SaleStats, error)
 	GetDailySales(businessID string, date time.Time) ([]Domain.Sale, error)
 }
 
 type salesUseCase struct {
-	salesRepo     Domain.SaleRepository
-	businessRepo  Domain.BusinessRepository
-	inventoryRepo Domain.ProductRepository
+	salesRepo       Domain.SaleRepository
+	businessRepo    Domain.BusinessRepository
+	inventoryRepo   Domain.ProductRepository
+	giftCardRepo    Domain.GiftCardRepository
+	loyaltyRepo     Domain.LoyaltyRepository
+	employeeRepo    Domain.EmployeeRepository
+	customerRepo    Domain.CustomerRepository
+	customFieldRepo Domain.CustomFieldRepository
+	currencyRepo    Domain.CurrencyRepository
+	reservationRepo Domain.StockReservationRepository
+	settingsRepo    Domain.ShopSettingsRepository
+	depositRepo     Domain.DepositRepository
+	smsUC           SMSUseCase
+	analyticsUC     AnalyticsUseCase
 }
 
 func NewSalesUseCase(
 	salesRepo Domain.SaleRepository,
 	businessRepo Domain.BusinessRepository,
 	inventoryRepo Domain.ProductRepository,
+	giftCardRepo Domain.GiftCardRepository,
+	loyaltyRepo Domain.LoyaltyRepository,
+	employeeRepo Domain.EmployeeRepository,
+	customerRepo Domain.CustomerRepository,
+	customFieldRepo Domain.CustomFieldRepository,
+	currencyRepo Domain.CurrencyRepository,
+	reservationRepo Domain.StockReservationRepository,
+	settingsRepo Domain.ShopSettingsRepository,
+	depositRepo Domain.DepositRepository,
+	smsUC SMSUseCase,
+	analyticsUC AnalyticsUseCase,
 ) SalesUseCase {
 	return &salesUseCase{
-		salesRepo:     salesRepo,
-		businessRepo:  businessRepo,
-		inventoryRepo: inventoryRepo,
+		salesRepo:       salesRepo,
+		businessRepo:    businessRepo,
+		inventoryRepo:   inventoryRepo,
+		giftCardRepo:    giftCardRepo,
+		loyaltyRepo:     loyaltyRepo,
+		employeeRepo:    employeeRepo,
+		customerRepo:    customerRepo,
+		customFieldRepo: customFieldRepo,
+		currencyRepo:    currencyRepo,
+		reservationRepo: reservationRepo,
+		settingsRepo:    settingsRepo,
+		depositRepo:     depositRepo,
+		smsUC:           smsUC,
+		analyticsUC:     analyticsUC,
 	}
 }
 
-func (uc *salesUseCase) CreateSale(businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error) {
+func (uc *salesUseCase) CreateSale(ctx context.Context, businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error) {
 	// Validate business exists
 	business, err := uc.businessRepo.FindByID(businessID)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find business: %w", err)
 	}
 	if business == nil {
-		return nil, fmt.Errorf("business not found")
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	// The order's reservation, if the sale fulfils one
+	var reservation *Domain.StockReservation
+	if req.ReservationID != nil {
+		reservation, err = uc.reservationRepo.FindInBusiness(ctx, *req.ReservationID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find reservation: %w", err)
+		}
+		if reservation == nil {
+			return nil, Domain.NotFoundError("reservation not found")
+		}
+		if !reservation.Holds(time.Now()) {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "reservation is no longer active")
+		}
+		reservedProductID := reservation.ProductID.Hex()
+		if req.ProductID == nil {
+			req.ProductID = &reservedProductID
+		} else if *req.ProductID != reservedProductID {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "reservation is for a different product")
+		}
 	}
 
 	// Validate product if specified
 	var productID *primitive.ObjectID
+	var productCategory string
+	var unitCost Domain.Money
+	var backordered float64
+	var product *Domain.Product
 	if req.ProductID != nil {
 		objProductID, err := primitive.ObjectIDFromHex(*req.ProductID)
 		if err != nil {
 			return nil, fmt.Errorf("invalid product ID: %w", err)
 		}
 
-		product, err := uc.inventoryRepo.FindByID(*req.ProductID)
+		product, err = uc.inventoryRepo.FindInBusiness(ctx, *req.ProductID)
 		if err != nil {
 			return nil, fmt.Errorf("failed to find product: %w", err)
 		}
 		if product == nil {
-			return nil, fmt.Errorf("product not found")
+			return nil, Domain.NotFoundError("product not found")
 		}
 
-		// Check if sufficient stock
-		if product.Stock < req.Quantity {
-			return nil, fmt.Errorf("insufficient stock. Available: %.2f, Requested: %.2f",
-				product.Stock, req.Quantity)
+		// Check if sufficient stock, leaving what other orders hold
+		var except *primitive.ObjectID
+		if reservation != nil {
+			except = &reservation.ID
+		}
+		available := req.Quantity
+		if takesStock(product, req.StockTaken) {
+			available, err = availableStock(uc.reservationRepo, product, except)
+			if err != nil {
+				return nil, err
+			}
+		}
+		if available < req.Quantity {
+			// A shop taking backorders sells what it has and owes the customer the rest
+			allow, err := uc.allowsBackorders(businessID)
+			if err != nil {
+				return nil, err
+			}
+			if !allow || reservation != nil {
+				return nil, fmt.Errorf("insufficient stock. Available: %.2f, Requested: %.2f",
+					available, req.Quantity)
+			}
+			backordered = req.Quantity - math.Max(available, 0)
 		}
 
 		productID = &objProductID
+		productCategory = product.Category
+		unitCost = product.CostPrice
+	}
+
+	deposits, depositAmount, err := saleDeposits(uc.depositRepo, businessID, product, req.Quantity, req.DepositReturns)
+	if err != nil {
+		return nil, err
+	}
+
+	customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntitySale, req.CustomFields, nil, true)
+	if err != nil {
+		return nil, err
 	}
 
 	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
@@ -82,31 +180,84 @@ func (uc *salesUseCase) CreateSale(businessID, userID string, req Domain.CreateS
 		return nil, fmt.Errorf("invalid user ID: %w", err)
 	}
 
+	employeeID, err := uc.resolveEmployee(ctx, businessID, userID, req)
+	if err != nil {
+		return nil, err
+	}
+
 	sale := &Domain.Sale{
+		ID:            primitive.NewObjectID(),
 		BusinessID:    objBusinessID,
 		LocalID:       req.LocalID,
 		ProductID:     productID,
 		CustomerName:  req.CustomerName,
-		CustomerPhone: req.CustomerPhone,
+		CustomerPhone: normalizePhone(req.CustomerPhone, business.Country),
 		Quantity:      req.Quantity,
 		UnitPrice:     req.UnitPrice,
 		Discount:      req.Discount,
 		Tax:           req.Tax,
 		PaymentMethod: req.PaymentMethod,
 		Notes:         req.Notes,
+		EmployeeID:    employeeID,
+		DeviceID:      req.DeviceID,
 		CreatedBy:     objUserID,
+		UnitCost:      unitCost,
+		CustomFields:  customFields,
+		Backordered:   backordered,
+		StockTaken:    req.StockTaken,
+		Deposits:      deposits,
+		DepositAmount: depositAmount,
+	}
+	if err := checkDepositCredit(sale); err != nil {
+		return nil, err
+	}
+	if req.AwaitPayment {
+		if req.PaymentMethod != Domain.PaymentMethodMobile || len(req.Payments) > 0 {
+			return nil, Domain.ValidationError("await_payment is only for sales paid in full by mobile money")
+		}
+		sale.PaymentStatus = Domain.PaymentStatusPending
+	}
+
+	// Validate split tender and redeem gift cards and points before the sale is recorded
+	if len(req.Payments) > 0 {
+		for i := range req.Payments {
+			if req.Payments[i].Method == Domain.PaymentMethodLoyalty {
+				req.Payments[i].Reference = normalizePhone(req.Payments[i].Reference, business.Country)
+			}
+		}
+		if err := uc.convertPayments(sale, business, req.Payments); err != nil {
+			return nil, err
+		}
+		if err := uc.roundCashPayments(sale, req.Payments); err != nil {
+			return nil, err
+		}
+		if err := uc.applyPayments(sale, businessID, userID, req.Payments); err != nil {
+			return nil, err
+		}
+	} else if sale.PaymentStatus != Domain.PaymentStatusPending {
+		if err := uc.roundCashSale(sale); err != nil {
+			return nil, err
+		}
 	}
 
 	if err := uc.salesRepo.Create(sale); err != nil {
+		if refundErr := uc.refundGiftCardPayments(sale, businessID, userID, "Sale creation failed - refunding"); refundErr != nil {
+			err = errors.Join(err, refundErr)
+		}
+		uc.reverseLoyaltyTransactions(sale.ID.Hex(), businessID, userID, "Sale creation failed - refunding")
 		return nil, fmt.Errorf("failed to create sale: %w", err)
 	}
 
-	// Update inventory if product was specified
-	if productID != nil {
+	if sale.CustomerPhone != "" {
+		uc.earnLoyaltyPoints(sale, businessID, userID, productCategory)
+	}
+
+	// Update inventory if product was specified; a backorder takes its stock as it comes in
+	if productID != nil && takesStock(product, req.StockTaken) && req.Quantity > backordered {
 		referenceID := sale.ID.Hex()
 		if err := uc.inventoryRepo.AdjustStock(
 			productID.Hex(), // This should work now
-			req.Quantity,
+			req.Quantity-backordered,
 			Domain.MovementTypeSale,
 			"Sale transaction",
 			&referenceID,
@@ -114,24 +265,37 @@ func (uc *salesUseCase) CreateSale(businessID, userID string, req Domain.CreateS
 			userID,
 		); err != nil {
 			// Rollback sale creation? For now, just log error
-			fmt.Printf("Failed to update inventory for sale: %v\n", err)
+			Infrastructure.LoggerFrom(ctx).Error("failed to update inventory for sale", "sale_id", sale.ID.Hex(), "error", err)
+		}
+	}
+
+	if reservation != nil {
+		now := time.Now()
+		reservation.Status = Domain.ReservationFulfilled
+		reservation.SaleID = &sale.ID
+		reservation.ClosedBy = &objUserID
+		reservation.ClosedAt = &now
+		if closed, err := uc.reservationRepo.Close(reservation); err != nil || !closed {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to mark reservation fulfilled by sale", "reservation_id", reservation.ID.Hex(), "sale_id", sale.ID.Hex(), "error", err)
+		}
+	}
+
+	// Texting the receipt must not hold up the checkout
+	if req.SendReceiptSMS && sale.CustomerPhone != "" {
+		if err := uc.smsUC.QueueSaleReceipt(ctx, sale.ID.Hex(), businessID); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to queue sms receipt for sale", "sale_id", sale.ID.Hex(), "error", err)
 		}
 	}
 
 	return sale, nil
 }
-func (uc *salesUseCase) GetSaleByID(id, businessID string) (*Domain.Sale, error) {
-	sale, err := uc.salesRepo.FindByID(id)
+func (uc *salesUseCase) GetSaleByID(ctx context.Context, id, businessID string) (*Domain.Sale, error) {
+	sale, err := uc.salesRepo.FindInBusiness(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to find sale: %w", err)
 	}
 	if sale == nil {
-		return nil, fmt.Errorf("sale not found")
-	}
-
-	// Verify sale belongs to business
-	if sale.BusinessID.Hex() != businessID {
-		return nil, fmt.Errorf("access denied: sale does not belong to this business")
+		return nil, Domain.NotFoundError("sale not found")
 	}
 
 	return sale, nil
@@ -141,8 +305,147 @@ func (uc *salesUseCase) GetSales(businessID string, filters Domain.SaleFilters)
 	return uc.salesRepo.FindByBusinessID(businessID, filters)
 }
 
-func (uc *salesUseCase) UpdateSale(id, businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error) {
-	sale, err := uc.GetSaleByID(id, businessID)
+func (uc *salesUseCase) ExpandSales(businessID string, sales []Domain.Sale, expand []string) ([]Domain.SaleListItem, error) {
+	wanted := map[string]bool{}
+	for _, name := range expand {
+		if !Domain.SaleExpansions[name] {
+			return nil, Domain.ValidationError(fmt.Sprintf("cannot expand %q; use product, customer or employee", name))
+		}
+		wanted[name] = true
+	}
+
+	items := make([]Domain.SaleListItem, len(sales))
+	for i := range sales {
+		items[i].Sale = sales[i]
+	}
+	if len(sales) == 0 {
+		return items, nil
+	}
+
+	if wanted["product"] {
+		if err := uc.expandProducts(businessID, items); err != nil {
+			return nil, err
+		}
+	}
+	if wanted["customer"] {
+		if err := uc.expandCustomers(businessID, items); err != nil {
+			return nil, err
+		}
+	}
+	if wanted["employee"] {
+		if err := uc.expandEmployees(businessID, items); err != nil {
+			return nil, err
+		}
+	}
+
+	return items, nil
+}
+
+func (uc *salesUseCase) expandProducts(businessID string, items []Domain.SaleListItem) error {
+	var ids []string
+	seen := map[primitive.ObjectID]bool{}
+	for _, item := range items {
+		if item.ProductID != nil && !seen[*item.ProductID] {
+			seen[*item.ProductID] = true
+			ids = append(ids, item.ProductID.Hex())
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	products, err := uc.inventoryRepo.FindByIDs(businessID, ids)
+	if err != nil {
+		return err
+	}
+	byID := make(map[primitive.ObjectID]*Domain.SaleProduct, len(products))
+	for _, p := range products {
+		byID[p.ID] = &Domain.SaleProduct{ID: p.ID, Name: p.Name, SKU: p.SKU, Barcode: p.Barcode, Category: p.Category, Unit: p.Unit}
+	}
+	for i := range items {
+		if items[i].ProductID != nil {
+			items[i].Product = byID[*items[i].ProductID]
+		}
+	}
+	return nil
+}
+
+// expandCustomers matches sales to customers by phone, as sales record the phone
+// typed at the till rather than a customer ID
+func (uc *salesUseCase) expandCustomers(businessID string, items []Domain.SaleListItem) error {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return Domain.NotFoundError("business not found")
+	}
+
+	phones := make([]string, len(items))
+	var lookup []string
+	seen := map[string]bool{}
+	for i, item := range items {
+		phones[i] = normalizePhone(item.CustomerPhone, business.Country)
+		if phones[i] != "" && !seen[phones[i]] {
+			seen[phones[i]] = true
+			lookup = append(lookup, phones[i])
+		}
+	}
+	if len(lookup) == 0 {
+		return nil
+	}
+
+	customers, err := uc.customerRepo.FindByPhones(businessID, lookup)
+	if err != nil {
+		return err
+	}
+	byPhone := make(map[string]*Domain.SaleCustomer, len(customers))
+	for _, c := range customers {
+		byPhone[c.Phone] = &Domain.SaleCustomer{ID: c.ID, Name: c.Name, Phone: c.Phone, Tier: c.Tier}
+	}
+	for i := range items {
+		if phones[i] != "" {
+			items[i].Customer = byPhone[phones[i]]
+		}
+	}
+	return nil
+}
+
+// expandEmployees reads the shop's whole staff list, which is short, once
+func (uc *salesUseCase) expandEmployees(businessID string, items []Domain.SaleListItem) error {
+	credited := false
+	for _, item := range items {
+		if item.EmployeeID != nil {
+			credited = true
+			break
+		}
+	}
+	if !credited {
+		return nil
+	}
+
+	employees, err := uc.employeeRepo.FindByBusinessID(businessID, nil)
+	if err != nil {
+		return err
+	}
+	byID := make(map[primitive.ObjectID]*Domain.SaleEmployee, len(employees))
+	for _, e := range employees {
+		byID[e.ID] = &Domain.SaleEmployee{ID: e.ID, Name: e.Name, Position: e.Position}
+	}
+	for i := range items {
+		if items[i].EmployeeID != nil {
+			items[i].Employee = byID[*items[i].EmployeeID]
+		}
+	}
+	return nil
+}
+
+func (uc *salesUseCase) CountSales(businessID string, filters Domain.SaleFilters) (int64, error) {
+	return uc.salesRepo.Count(businessID, filters)
+}
+
+func (uc *salesUseCase) UpdateSale(ctx context.Context, id, businessID, userID string, req Domain.CreateSaleRequest) (*Domain.Sale, error) {
+	sale, err := uc.GetSaleByID(ctx, id, businessID)
 	if err != nil {
 		return nil, err
 	}
@@ -152,10 +455,24 @@ func (uc *salesUseCase) UpdateSale(id, businessID, userID string, req Domain.Cre
 		return nil, fmt.Errorf("cannot update sale with status: %s", sale.Status)
 	}
 
+	// A backordered sale's stock is owed through its backorder, and one whose stock
+	// was taken elsewhere is changed there, so what it sold is fixed
+	if sale.StockTaken && (req.Quantity != sale.Quantity || (req.ProductID != nil && (sale.ProductID == nil || *req.ProductID != sale.ProductID.Hex()))) {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "cannot change what was sold on a sale whose stock was taken by another record")
+	}
+	if sale.Backordered > 0 {
+		if req.ProductID != nil && (sale.ProductID == nil || *req.ProductID != sale.ProductID.Hex()) {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "cannot change the product of a backordered sale")
+		}
+		if req.Quantity != sale.Quantity {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "cannot change the quantity of a backordered sale")
+		}
+	}
+
 	// Get previous product and quantity for inventory adjustment
 	var previousProductID *string
 	var previousQuantity float64
-	if sale.ProductID != nil {
+	if sale.ProductID != nil && sale.Backordered == 0 && !sale.StockTaken && !uc.isService(ctx, *sale.ProductID) {
 		// FIX: Dereference the pointer before calling Hex()
 		productIDStr := sale.ProductID.Hex()
 		previousProductID = &productIDStr
@@ -168,11 +485,25 @@ func (uc *salesUseCase) UpdateSale(id, businessID, userID string, req Domain.Cre
 		if err != nil {
 			return nil, fmt.Errorf("invalid product ID: %w", err)
 		}
+		if sale.ProductID == nil || *sale.ProductID != objProductID {
+			// Snapshot the cost of the product now being sold
+			product, err := uc.inventoryRepo.FindInBusiness(ctx, *req.ProductID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find product: %w", err)
+			}
+			if product == nil {
+				return nil, Domain.NotFoundError("product not found")
+			}
+			sale.UnitCost = product.CostPrice
+		}
 		sale.ProductID = &objProductID
 	}
 
 	sale.CustomerName = req.CustomerName
 	sale.CustomerPhone = req.CustomerPhone
+	if business, err := uc.businessRepo.FindByID(businessID); err == nil && business != nil {
+		sale.CustomerPhone = normalizePhone(req.CustomerPhone, business.Country)
+	}
 	sale.Quantity = req.Quantity
 	sale.UnitPrice = req.UnitPrice
 	sale.Discount = req.Discount
@@ -180,6 +511,30 @@ func (uc *salesUseCase) UpdateSale(id, businessID, userID string, req Domain.Cre
 	sale.PaymentMethod = req.PaymentMethod
 	sale.Notes = req.Notes
 
+	// Deposits follow the quantity; empties brought back are given again
+	var product *Domain.Product
+	if sale.ProductID != nil {
+		product, err = uc.inventoryRepo.FindInBusiness(ctx, sale.ProductID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+	}
+	sale.Deposits, sale.DepositAmount, err = saleDeposits(uc.depositRepo, businessID, product, sale.Quantity, req.DepositReturns)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkDepositCredit(sale); err != nil {
+		return nil, err
+	}
+
+	// Split tenders keep what was taken; a single tender is taken again in full
+	if len(sale.Payments) == 0 {
+		sale.CashRounding = 0
+		if err := uc.roundCashSale(sale); err != nil {
+			return nil, err
+		}
+	}
+
 	if err := uc.salesRepo.Update(sale); err != nil {
 		return nil, fmt.Errorf("failed to update sale: %w", err)
 	}
@@ -199,7 +554,7 @@ func (uc *salesUseCase) UpdateSale(id, businessID, userID string, req Domain.Cre
 		)
 	}
 
-	if sale.ProductID != nil {
+	if sale.ProductID != nil && sale.Backordered == 0 && (product == nil || takesStock(product, sale.StockTaken)) {
 		// Deduct new product stock
 		referenceID := sale.ID.Hex()
 		if err := uc.inventoryRepo.AdjustStock(
@@ -211,14 +566,16 @@ func (uc *salesUseCase) UpdateSale(id, businessID, userID string, req Domain.Cre
 			"sale",
 			userID,
 		); err != nil {
-			fmt.Printf("Failed to update inventory for sale update: %v\n", err)
+			Infrastructure.Logger.Error("failed to update inventory for sale update", "sale_id", referenceID, "error", err)
 		}
 	}
 
+	uc.analyticsUC.MarkDirty(businessID, sale.CreatedAt)
+
 	return sale, nil
 }
-func (uc *salesUseCase) VoidSale(id, businessID, userID string) error {
-	sale, err := uc.GetSaleByID(id, businessID)
+func (uc *salesUseCase) VoidSale(ctx context.Context, id, businessID, userID string) error {
+	sale, err := uc.GetSaleByID(ctx, id, businessID)
 	if err != nil {
 		return err
 	}
@@ -228,30 +585,76 @@ func (uc *salesUseCase) VoidSale(id, businessID, userID string) error {
 		return fmt.Errorf("sale cannot be voided with status: %s", sale.Status)
 	}
 
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	if err := uc.checkGiftCardRefunds(sale, businessID); err != nil {
+		return err
+	}
+
 	// Update sale status
-	if err := uc.salesRepo.UpdateStatus(id, Domain.SaleStatusVoided); err != nil {
+	if err := uc.salesRepo.Void(id, objUserID, time.Now()); err != nil {
 		return fmt.Errorf("failed to void sale: %w", err)
 	}
 
-	// Restore inventory if product was sold
-	if sale.ProductID != nil {
+	// Return any gift card tender to the cards it came from; the sale stays voided,
+	// so a card that could not take it back is reported for the shop to settle
+	refundErr := uc.refundGiftCardPayments(sale, businessID, userID, "Sale voided - refunding")
+	uc.reverseLoyaltyTransactions(id, businessID, userID, "Sale voided")
+	uc.analyticsUC.MarkDirty(businessID, sale.CreatedAt)
+
+	// Restore inventory if product was sold; the backorder returns what it set aside,
+	// and a record that took the stock itself sees to it
+	if sale.ProductID != nil && !sale.StockTaken && sale.Quantity > sale.Backordered && !uc.isService(ctx, *sale.ProductID) {
 		referenceID := sale.ID.Hex()
 		if err := uc.inventoryRepo.AdjustStock(
 			sale.ProductID.Hex(),
-			sale.Quantity,
+			sale.Quantity-sale.Backordered,
 			Domain.MovementTypeReturn,
 			"Sale voided - restoring stock",
 			&referenceID,
 			"sale",
 			userID,
 		); err != nil {
-			fmt.Printf("Failed to restore inventory for voided sale: %v\n", err)
+			Infrastructure.Logger.Error("failed to restore inventory for voided sale", "sale_id", referenceID, "error", err)
 		}
 	}
 
+	if refundErr != nil {
+		return fmt.Errorf("sale voided, but its gift card tender was not all refunded: %w", refundErr)
+	}
 	return nil
 }
 
+// isService reports whether the product is a service, which has no stock to move
+func (uc *salesUseCase) isService(ctx context.Context, productID primitive.ObjectID) bool {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID.Hex())
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to find product", "product_id", productID.Hex(), "error", err)
+		return false
+	}
+	return product != nil && product.Service
+}
+
+// takesStock reports whether selling the product moves its stock: not for a
+// service, nor for goods already taken out by another record
+func takesStock(product *Domain.Product, stockTaken bool) bool {
+	return !product.Service && !stockTaken
+}
+
+func (uc *salesUseCase) allowsBackorders(businessID string) (bool, error) {
+	settings, err := uc.settingsRepo.FindSettings(businessID)
+	if err != nil {
+		return false, fmt.Errorf("failed to find shop settings: %w", err)
+	}
+	if settings == nil {
+		return Domain.DefaultShopSettings.AllowBackorders, nil
+	}
+	return settings.AllowBackorders, nil
+}
+
 func (uc *salesUseCase) GetSalesSummary(businessID string, period string) (*Domain.SaleSummary, error) {
 	now := time.Now()
 	var startDate, endDate time.Time
@@ -271,7 +674,8 @@ func (uc *salesUseCase) GetSalesSummary(businessID string, period string) (*Doma
 		endDate = now
 	}
 
-	return uc.salesRepo.GetSummary(businessID, startDate, endDate)
+	// Served from the hourly summary table rather than scanning raw sales
+	return uc.analyticsUC.GetSalesSummary(businessID, startDate, endDate)
 }
 
 func (uc *salesUseCase) GetSalesStats(businessID string, period string) (*Domain.SaleStats, error) {
@@ -281,3 +685,434 @@ func (uc *salesUseCase) GetSalesStats(businessID string, period string) (*Domain
 func (uc *salesUseCase) GetDailySales(businessID string, date time.Time) ([]Domain.Sale, error) {
 	return uc.salesRepo.GetDailySales(businessID, date)
 }
+
+// applyPayments checks that the split tender covers the sale exactly and redeems
+// gift card and loyalty portions against the sale ID
+func (uc *salesUseCase) applyPayments(sale *Domain.Sale, businessID, userID string, payments []Domain.SalePayment) error {
+	finalAmount := sale.UnitPrice.Times(sale.Quantity) - sale.Discount + sale.Tax + sale.DepositAmount + sale.CashRounding
+
+	var paid Domain.Money
+	for _, payment := range payments {
+		if payment.Amount <= 0 {
+			return fmt.Errorf("payment amount must be greater than 0")
+		}
+		if payment.Method == Domain.PaymentMethodSplit {
+			return fmt.Errorf("invalid payment method: %s", payment.Method)
+		}
+		if payment.Method == Domain.PaymentMethodGiftCard && payment.Reference == "" {
+			return fmt.Errorf("gift card code is required for gift card payments")
+		}
+		if payment.Method == Domain.PaymentMethodLoyalty && payment.Reference == "" && sale.CustomerPhone == "" {
+			return fmt.Errorf("customer phone is required for loyalty point payments")
+		}
+		paid += payment.Amount
+	}
+
+	if paid != finalAmount {
+		return fmt.Errorf("payments total %s does not match sale amount %s", paid, finalAmount)
+	}
+
+	// Check every gift card up front so a bad card doesn't leave others half-redeemed
+	cards := make([]*Domain.GiftCard, len(payments))
+	for i, payment := range payments {
+		if payment.Method != Domain.PaymentMethodGiftCard {
+			continue
+		}
+		card, err := uc.giftCardRepo.FindByCode(businessID, normalizeGiftCardCode(payment.Reference))
+		if err != nil {
+			return fmt.Errorf("failed to find gift card: %w", err)
+		}
+		if card == nil {
+			return fmt.Errorf("gift card %s not found", payment.Reference)
+		}
+		if err := validateGiftCardForRedemption(card, payment.Amount); err != nil {
+			return err
+		}
+		cards[i] = card
+	}
+
+	accounts, points, err := uc.prepareLoyaltyPayments(sale, businessID, payments)
+	if err != nil {
+		return err
+	}
+
+	saleID := sale.ID.Hex()
+	redeemed := []Domain.SalePayment{}
+	for i, payment := range payments {
+		if cards[i] == nil {
+			continue
+		}
+		if _, err := uc.giftCardRepo.Redeem(cards[i].ID.Hex(), payment.Amount, &saleID, userID); err != nil {
+			err = fmt.Errorf("failed to redeem gift card %s: %w", payment.Reference, err)
+			return errors.Join(err, uc.refundGiftCardPayments(&Domain.Sale{ID: sale.ID, Payments: redeemed}, businessID, userID, "Split payment failed - refunding"))
+		}
+		payments[i].Reference = cards[i].Code
+		redeemed = append(redeemed, payments[i])
+	}
+
+	for i, payment := range payments {
+		if accounts[i] == nil {
+			continue
+		}
+		if _, err := uc.loyaltyRepo.DeductPoints(accounts[i].ID.Hex(), points[i], payment.Amount,
+			Domain.LoyaltyTransactionRedeem, &saleID, userID, "Redeemed at sale"); err != nil {
+			refundErr := uc.refundGiftCardPayments(&Domain.Sale{ID: sale.ID, Payments: redeemed}, businessID, userID, "Split payment failed - refunding")
+			uc.reverseLoyaltyTransactions(saleID, businessID, userID, "Split payment failed - refunding")
+			return errors.Join(fmt.Errorf("failed to redeem loyalty points: %w", err), refundErr)
+		}
+		payments[i].Reference = accounts[i].CustomerPhone
+	}
+
+	sale.Payments = payments
+	if len(payments) == 1 {
+		sale.PaymentMethod = payments[0].Method
+	} else {
+		sale.PaymentMethod = Domain.PaymentMethodSplit
+	}
+
+	return nil
+}
+
+// convertPayments works out the base currency amount of payments made in a
+// foreign currency, at the rate in force now. Foreign cash rarely comes to the
+// exact amount, so what the last foreign payment covers beyond the sale is
+// recorded as change given back in the base currency.
+func (uc *salesUseCase) convertPayments(sale *Domain.Sale, business *Domain.Business, payments []Domain.SalePayment) error {
+	var settings *Domain.CurrencySettings
+	last := -1
+	for i := range payments {
+		payment := &payments[i]
+		payment.ExchangeRate, payment.Change = 0, 0
+		if payment.Currency == "" {
+			if payment.ForeignAmount > 0 {
+				return Domain.ValidationError("currency is required with foreign_amount")
+			}
+			continue
+		}
+
+		currency, ok := Domain.NormalizeCurrency(payment.Currency)
+		if !ok {
+			return Domain.ValidationError(fmt.Sprintf("%q is not a currency code", payment.Currency))
+		}
+		if currency == business.Currency {
+			if payment.Amount == 0 {
+				payment.Amount = payment.ForeignAmount
+			}
+			payment.Currency, payment.ForeignAmount = "", 0
+			continue
+		}
+		if payment.Method == Domain.PaymentMethodGiftCard || payment.Method == Domain.PaymentMethodLoyalty {
+			return Domain.ValidationError("gift cards and loyalty points are only redeemed in " + business.Currency)
+		}
+		if payment.ForeignAmount <= 0 {
+			return Domain.ValidationError("foreign_amount is required for payments in " + currency)
+		}
+
+		if settings == nil {
+			found, err := uc.currencyRepo.FindSettings(business.ID.Hex())
+			if err != nil {
+				return err
+			}
+			settings = found
+		}
+		if !settings.Accepts(currency) {
+			return Domain.ValidationError(currency + " is not one of the shop's accepted currencies")
+		}
+		rate, err := currentRate(uc.currencyRepo, business.ID.Hex(), business.Currency, currency)
+		if err != nil {
+			return err
+		}
+		if rate == nil {
+			return Domain.ValidationError("no exchange rate is set for " + currency)
+		}
+
+		payment.Currency = currency
+		payment.ExchangeRate = rate.Rate
+		payment.Amount = Domain.NewMoney(payment.ForeignAmount.Float64() * rate.Rate)
+		last = i
+	}
+	if last < 0 {
+		return nil
+	}
+
+	finalAmount := sale.UnitPrice.Times(sale.Quantity) - sale.Discount + sale.Tax + sale.DepositAmount
+	var paid Domain.Money
+	for _, payment := range payments {
+		paid += payment.Amount
+	}
+	if excess := paid - finalAmount; excess > 0 {
+		payment := &payments[last]
+		if excess >= payment.Amount {
+			return fmt.Errorf("payments total %s is more than the sale amount %s", paid, finalAmount)
+		}
+		payment.Change = excess
+		payment.Amount -= excess
+	}
+
+	return nil
+}
+
+// roundCashSale rounds a sale paid in full in cash by the shop's rule
+func (uc *salesUseCase) roundCashSale(sale *Domain.Sale) error {
+	if sale.PaymentMethod != Domain.PaymentMethodCash {
+		return nil
+	}
+	rounding, err := uc.cashRounding(sale.BusinessID.Hex())
+	if err != nil || rounding == nil {
+		return err
+	}
+
+	finalAmount := sale.UnitPrice.Times(sale.Quantity) - sale.Discount + sale.Tax + sale.DepositAmount
+	sale.CashRounding = rounding.Apply(finalAmount) - finalAmount
+	return nil
+}
+
+// roundCashPayments rounds the part of a split tender left to pay in cash in the
+// base currency. The till may enter the cash at what was due or already rounded;
+// either way the last cash payment ends up at the rounded amount.
+func (uc *salesUseCase) roundCashPayments(sale *Domain.Sale, payments []Domain.SalePayment) error {
+	last := -1
+	var cashPaid, otherPaid Domain.Money
+	for i, payment := range payments {
+		if payment.Method == Domain.PaymentMethodCash && payment.Currency == "" {
+			cashPaid += payment.Amount
+			last = i
+		} else {
+			otherPaid += payment.Amount
+		}
+	}
+	if last < 0 {
+		return nil
+	}
+
+	rounding, err := uc.cashRounding(sale.BusinessID.Hex())
+	if err != nil || rounding == nil {
+		return err
+	}
+
+	due := sale.UnitPrice.Times(sale.Quantity) - sale.Discount + sale.Tax + sale.DepositAmount - otherPaid
+	rounded := rounding.Apply(due)
+	switch cashPaid {
+	case rounded:
+	case due:
+		payments[last].Amount += rounded - cashPaid
+	default:
+		// Left for applyPayments to turn down
+		return nil
+	}
+	if payments[last].Amount <= 0 {
+		return Domain.ValidationError("the cash due rounds to nothing; leave the cash payment out")
+	}
+
+	sale.CashRounding = rounded - due
+	return nil
+}
+
+func (uc *salesUseCase) cashRounding(businessID string) (*Domain.CashRounding, error) {
+	settings, err := uc.currencyRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings == nil {
+		return nil, nil
+	}
+	return settings.CashRounding, nil
+}
+
+// refundGiftCardPayments gives the sale's gift card tender back to the cards it
+// came from. Every card is tried; the error names each one that was not refunded.
+func (uc *salesUseCase) refundGiftCardPayments(sale *Domain.Sale, businessID, userID, note string) error {
+	saleID := sale.ID.Hex()
+	var errs []error
+	for _, payment := range sale.Payments {
+		if payment.Method != Domain.PaymentMethodGiftCard {
+			continue
+		}
+		card, err := uc.giftCardRepo.FindByCode(businessID, payment.Reference)
+		if err == nil && card == nil {
+			err = fmt.Errorf("card not found")
+		}
+		if err == nil {
+			_, err = uc.giftCardRepo.Refund(card.ID.Hex(), payment.Amount, &saleID, note, userID)
+		}
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to refund %s to gift card %s: %w", payment.Amount, payment.Reference, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// checkGiftCardRefunds makes sure the sale's gift card tender can go back before the
+// sale is undone: a card the shop has voided since cannot take it
+func (uc *salesUseCase) checkGiftCardRefunds(sale *Domain.Sale, businessID string) error {
+	for _, payment := range sale.Payments {
+		if payment.Method != Domain.PaymentMethodGiftCard {
+			continue
+		}
+		card, err := uc.giftCardRepo.FindByCode(businessID, payment.Reference)
+		if err != nil {
+			return fmt.Errorf("failed to find gift card: %w", err)
+		}
+		if card == nil {
+			return Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("gift card %s paid for this sale and no longer exists; refund the customer another way", payment.Reference))
+		}
+		if card.Status == Domain.GiftCardStatusVoided {
+			return Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("gift card %s paid for this sale and has been voided; refund the customer another way", payment.Reference))
+		}
+	}
+	return nil
+}
+
+// prepareLoyaltyPayments converts loyalty tender into points and checks each
+// customer can cover it, returning per-payment accounts and point amounts
+func (uc *salesUseCase) prepareLoyaltyPayments(sale *Domain.Sale, businessID string, payments []Domain.SalePayment) ([]*Domain.LoyaltyAccount, []float64, error) {
+	accounts := make([]*Domain.LoyaltyAccount, len(payments))
+	points := make([]float64, len(payments))
+
+	var program *Domain.LoyaltyProgram
+	required := map[string]float64{}
+	for i, payment := range payments {
+		if payment.Method != Domain.PaymentMethodLoyalty {
+			continue
+		}
+
+		if program == nil {
+			p, err := loadLoyaltyProgram(uc.loyaltyRepo, businessID)
+			if err != nil {
+				return nil, nil, err
+			}
+			if !p.Enabled {
+				return nil, nil, fmt.Errorf("loyalty program is not enabled")
+			}
+			program = p
+		}
+
+		phone := payment.Reference
+		if phone == "" {
+			phone = sale.CustomerPhone
+		}
+
+		account, err := uc.loyaltyRepo.FindAccountByPhone(businessID, phone)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to find loyalty account: %w", err)
+		}
+		if account == nil {
+			return nil, nil, fmt.Errorf("no loyalty account for %s", phone)
+		}
+
+		points[i] = math.Ceil(payment.Amount.Float64() / program.PointValue)
+		if points[i] < program.MinRedeemPoints {
+			return nil, nil, fmt.Errorf("at least %.0f points must be redeemed", program.MinRedeemPoints)
+		}
+
+		required[account.ID.Hex()] += points[i]
+		if account.Points < required[account.ID.Hex()] {
+			return nil, nil, fmt.Errorf("insufficient loyalty points. Available: %.0f, Requested: %.0f",
+				account.Points, required[account.ID.Hex()])
+		}
+		accounts[i] = account
+	}
+
+	return accounts, points, nil
+}
+
+// earnLoyaltyPoints credits the customer for the part of the sale not paid with points
+func (uc *salesUseCase) earnLoyaltyPoints(sale *Domain.Sale, businessID, userID, category string) {
+	program, err := loadLoyaltyProgram(uc.loyaltyRepo, businessID)
+	if err != nil {
+		Infrastructure.Logger.Error("failed to load loyalty program", "business_id", businessID, "error", err)
+		return
+	}
+
+	eligible := sale.FinalAmount
+	for _, payment := range sale.Payments {
+		if payment.Method == Domain.PaymentMethodLoyalty {
+			eligible -= payment.Amount
+		}
+	}
+
+	var productID string
+	if sale.ProductID != nil {
+		productID = sale.ProductID.Hex()
+	}
+
+	points := calculateLoyaltyPoints(program, eligible, productID, category)
+	if points <= 0 {
+		return
+	}
+
+	saleID := sale.ID.Hex()
+	if _, err := uc.loyaltyRepo.AddPoints(businessID, sale.CustomerPhone, sale.CustomerName, points,
+		Domain.NewMoney(points*program.PointValue), Domain.LoyaltyTransactionEarn, &saleID, userID, "Earned on sale"); err != nil {
+		Infrastructure.Logger.Error("failed to award loyalty points for sale", "sale_id", saleID, "error", err)
+	}
+}
+
+// reverseLoyaltyTransactions undoes points earned and redeemed against a sale
+func (uc *salesUseCase) reverseLoyaltyTransactions(saleID, businessID, userID, note string) {
+	transactions, err := uc.loyaltyRepo.GetSaleTransactions(saleID)
+	if err != nil {
+		Infrastructure.Logger.Error("failed to find loyalty transactions for sale", "sale_id", saleID, "error", err)
+		return
+	}
+
+	for _, tx := range transactions {
+		switch tx.Type {
+		case Domain.LoyaltyTransactionEarn:
+			if _, err := uc.loyaltyRepo.DeductPoints(tx.AccountID.Hex(), tx.Points, tx.Value,
+				Domain.LoyaltyTransactionReverse, &saleID, userID, note); err != nil {
+				Infrastructure.Logger.Error("failed to reverse earned loyalty points", "sale_id", saleID, "account_id", tx.AccountID.Hex(), "error", err)
+			}
+		case Domain.LoyaltyTransactionRedeem:
+			account, err := uc.loyaltyRepo.FindAccountByID(tx.AccountID.Hex())
+			if err != nil || account == nil {
+				Infrastructure.Logger.Error("failed to find loyalty account for refund", "sale_id", saleID, "account_id", tx.AccountID.Hex(), "error", err)
+				continue
+			}
+			if _, err := uc.loyaltyRepo.AddPoints(businessID, account.CustomerPhone, "", -tx.Points, -tx.Value,
+				Domain.LoyaltyTransactionReverse, &saleID, userID, note); err != nil {
+				Infrastructure.Logger.Error("failed to refund redeemed loyalty points", "sale_id", saleID, "account_id", tx.AccountID.Hex(), "error", err)
+			}
+		}
+	}
+}
+
+// resolveEmployee works out which employee a sale is credited to: the one
+// given explicitly, else whoever is clocked in on the device, else the
+// employee linked to the logged-in user. Lookup failures for the implicit
+// cases only leave the sale unattributed.
+func (uc *salesUseCase) resolveEmployee(ctx context.Context, businessID, userID string, req Domain.CreateSaleRequest) (*primitive.ObjectID, error) {
+	if req.EmployeeID != nil && *req.EmployeeID != "" {
+		employee, err := uc.employeeRepo.FindInBusiness(ctx, *req.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find employee: %w", err)
+		}
+		if employee == nil {
+			return nil, Domain.NotFoundError("employee not found")
+		}
+		if employee.Status != Domain.EmployeeStatusActive {
+			return nil, fmt.Errorf("employee is not active")
+		}
+		return &employee.ID, nil
+	}
+
+	if req.DeviceID != "" {
+		entry, err := uc.employeeRepo.FindOpenTimeEntryByDevice(businessID, req.DeviceID)
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to find shift for device", "device_id", req.DeviceID, "error", err)
+		} else if entry != nil {
+			return &entry.EmployeeID, nil
+		}
+	}
+
+	employee, err := uc.employeeRepo.FindByUserID(businessID, userID)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to find employee for user", "user_id", userID, "error", err)
+		return nil, nil
+	}
+	if employee != nil && employee.Status == Domain.EmployeeStatusActive {
+		return &employee.ID, nil
+	}
+
+	return nil, nil
+}
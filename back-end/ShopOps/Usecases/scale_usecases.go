@@ -0,0 +1,292 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ScaleUseCase interface {
+	GetSettings(businessID string) (*Domain.ScaleSettings, error)
+	UpdateSettings(businessID string, req Domain.UpdateScaleSettingsRequest) (*Domain.ScaleSettings, error)
+	// ExportPLUs is the file of PLU products to load into the shop's scales
+	ExportPLUs(businessID string) (data []byte, contentType, filename string, err error)
+	// Scan finds the product a barcode read at the POS is for
+	Scan(businessID, barcode string) (*Domain.ScanResult, error)
+	// QuickSaleLine prices a scanned barcode as a sale line, reading the weight
+	// or price out of barcodes in one of the shop's schemes
+	QuickSaleLine(businessID, barcode string) (*Domain.QuickSaleLine, error)
+	// QuickSale rings up a scanned barcode as a sale on its own
+	QuickSale(ctx context.Context, businessID, userID string, req Domain.QuickSaleRequest) (*Domain.Sale, error)
+}
+
+type scaleUseCase struct {
+	scaleRepo     Domain.ScaleRepository
+	inventoryRepo Domain.ProductRepository
+	catalog       *Infrastructure.CatalogCache
+	salesUC       SalesUseCase
+}
+
+func NewScaleUseCase(
+	scaleRepo Domain.ScaleRepository,
+	inventoryRepo Domain.ProductRepository,
+	catalog *Infrastructure.CatalogCache,
+	salesUC SalesUseCase,
+) ScaleUseCase {
+	return &scaleUseCase{
+		scaleRepo:     scaleRepo,
+		inventoryRepo: inventoryRepo,
+		catalog:       catalog,
+		salesUC:       salesUC,
+	}
+}
+
+func (uc *scaleUseCase) GetSettings(businessID string) (*Domain.ScaleSettings, error) {
+	settings, err := uc.scaleRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	defaults := Domain.DefaultScaleSettings
+	defaults.BusinessID = objBusinessID
+	return &defaults, nil
+}
+
+func (uc *scaleUseCase) UpdateSettings(businessID string, req Domain.UpdateScaleSettingsRequest) (*Domain.ScaleSettings, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Enabled != nil {
+		settings.Enabled = *req.Enabled
+	}
+	if req.Prefixes != nil {
+		settings.Prefixes = *req.Prefixes
+	}
+	if req.PrefixDigits != nil {
+		settings.PrefixDigits = *req.PrefixDigits
+	}
+	if req.ItemDigits != nil {
+		settings.ItemDigits = *req.ItemDigits
+	}
+	if req.ValueCheckDigit != nil {
+		settings.ValueCheckDigit = *req.ValueCheckDigit
+	}
+	if req.ValueDigits != nil {
+		settings.ValueDigits = *req.ValueDigits
+	}
+	if req.ValueType != nil {
+		settings.ValueType = *req.ValueType
+	}
+	if req.ValueDecimals != nil {
+		settings.ValueDecimals = *req.ValueDecimals
+	}
+	if req.ExportFormat != nil {
+		settings.ExportFormat = *req.ExportFormat
+	}
+	if req.NameLength != nil {
+		settings.NameLength = *req.NameLength
+	}
+	if req.Schemes != nil {
+		settings.Schemes = *req.Schemes
+	}
+
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	if err := uc.scaleRepo.SaveSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+func (uc *scaleUseCase) ExportPLUs(businessID string) ([]byte, string, string, error) {
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	hasPLU := true
+	status := Domain.ProductStatusActive
+	products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{HasPLU: &hasPLU, Status: &status})
+	if err != nil {
+		return nil, "", "", fmt.Errorf("failed to find products: %w", err)
+	}
+
+	data, err := Infrastructure.WritePLUFile(*settings, products)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	contentType := "text/csv"
+	if settings.ExportFormat == Domain.ScaleExportTXT {
+		contentType = "text/plain"
+	}
+	filename := fmt.Sprintf("plu_%s.%s", time.Now().Format("20060102_150405"), settings.ExportFormat)
+	return data, contentType, filename, nil
+}
+
+// resolve finds the product a barcode is for, with what the barcode says when
+// one of the shop's price-embedded schemes reads it
+func (uc *scaleUseCase) resolve(businessID, barcode string) (*Domain.Product, *Domain.ScaleBarcode, error) {
+	if barcode == "" {
+		return nil, nil, Domain.ValidationError("barcode is required")
+	}
+
+	settings, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	scaled, ok, err := settings.Parse(barcode)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if ok {
+		product, err := uc.catalog.ProductByPLU(businessID, scaled.PLU, uc.loadCatalog)
+		if err != nil {
+			return nil, nil, err
+		}
+		if product == nil {
+			return nil, nil, Domain.NotFoundError(fmt.Sprintf("no product has PLU %s", scaled.PLU))
+		}
+		return product, scaled, nil
+	}
+
+	product, err := uc.catalog.ProductByBarcode(businessID, barcode, uc.loadCatalog)
+	if err != nil {
+		return nil, nil, err
+	}
+	if product == nil {
+		return nil, nil, Domain.NotFoundError("no product has this barcode")
+	}
+	return product, nil, nil
+}
+
+func (uc *scaleUseCase) Scan(businessID, barcode string) (*Domain.ScanResult, error) {
+	product, scaled, err := uc.resolve(businessID, barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	if scaled != nil {
+		result := &Domain.ScanResult{
+			Barcode:   barcode,
+			Product:   product,
+			FromScale: true,
+			UnitPrice: product.SellingPrice.Float64(),
+		}
+		if scaled.Weight {
+			result.Quantity = scaled.Value
+			result.Amount = product.SellingPrice.Times(scaled.Value).Float64()
+		} else {
+			// The scale priced it; the weight is only for stock, so the customer
+			// pays what the label says even if the price has changed since
+			result.Amount = scaled.Value
+			if product.SellingPrice > 0 {
+				result.Quantity = math.Round(scaled.Value/product.SellingPrice.Float64()*1000) / 1000
+			}
+		}
+		return result, nil
+	}
+
+	return &Domain.ScanResult{
+		Barcode:   barcode,
+		Product:   product,
+		Quantity:  1,
+		UnitPrice: product.SellingPrice.Float64(),
+		Amount:    product.SellingPrice.Float64(),
+	}, nil
+}
+
+func (uc *scaleUseCase) QuickSaleLine(businessID, barcode string) (*Domain.QuickSaleLine, error) {
+	product, scaled, err := uc.resolve(businessID, barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	line := &Domain.QuickSaleLine{
+		Barcode:   barcode,
+		ProductID: product.ID.Hex(),
+		Name:      product.Name,
+		Unit:      product.Unit,
+		Quantity:  1,
+		UnitPrice: product.SellingPrice,
+		Amount:    product.SellingPrice,
+	}
+
+	if scaled != nil {
+		line.FromScale = true
+		line.Scheme = scaled.Scheme
+		if scaled.Weight {
+			line.Quantity = scaled.Value
+			line.Amount = product.SellingPrice.Times(scaled.Value)
+		} else {
+			price := scaled.Price()
+			if price <= 0 {
+				return nil, Domain.ValidationError("the label shows no price; weigh it again")
+			}
+			if product.SellingPrice > 0 {
+				// Whole grams, rounded up so the line never comes to less than the label
+				grams := (int64(price)*1000 + int64(product.SellingPrice) - 1) / int64(product.SellingPrice)
+				line.Quantity = float64(grams) / 1000
+				line.Discount = product.SellingPrice.Times(line.Quantity) - price
+			} else {
+				line.UnitPrice = price
+			}
+			line.Amount = price
+		}
+	}
+
+	if line.UnitPrice <= 0 {
+		return nil, Domain.ValidationError(fmt.Sprintf("%s has no selling price", product.Name))
+	}
+	return line, nil
+}
+
+func (uc *scaleUseCase) QuickSale(ctx context.Context, businessID, userID string, req Domain.QuickSaleRequest) (*Domain.Sale, error) {
+	line, err := uc.QuickSaleLine(businessID, req.Barcode)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.salesUC.CreateSale(ctx, businessID, userID, Domain.CreateSaleRequest{
+		ProductID:     &line.ProductID,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: req.CustomerPhone,
+		Quantity:      line.Quantity,
+		UnitPrice:     line.UnitPrice,
+		Discount:      line.Discount,
+		PaymentMethod: req.PaymentMethod,
+		Payments:      req.Payments,
+		LocalID:       req.LocalID,
+		EmployeeID:    req.EmployeeID,
+		DeviceID:      req.DeviceID,
+	})
+}
+
+// loadCatalog reads every product a scan can find, by name so that of two with the
+// same barcode the first by name is found, as it always was
+func (uc *scaleUseCase) loadCatalog(businessID string) ([]Domain.Product, error) {
+	products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	return products, nil
+}
@@ -0,0 +1,98 @@
+package Usecases
+
+import (
+	"fmt"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+)
+
+// SearchUseCase finds a shop's active products and customers as the cashier types
+type SearchUseCase interface {
+	Search(businessID, query string, kinds []Domain.SearchKind, limit int) ([]Domain.SearchResult, error)
+}
+
+type searchUseCase struct {
+	index           *Infrastructure.SearchIndex
+	inventoryRepo   Domain.ProductRepository
+	customerRepo    Domain.CustomerRepository
+	customFieldRepo Domain.CustomFieldRepository
+}
+
+func NewSearchUseCase(
+	index *Infrastructure.SearchIndex,
+	inventoryRepo Domain.ProductRepository,
+	customerRepo Domain.CustomerRepository,
+	customFieldRepo Domain.CustomFieldRepository,
+) SearchUseCase {
+	return &searchUseCase{
+		index:           index,
+		inventoryRepo:   inventoryRepo,
+		customerRepo:    customerRepo,
+		customFieldRepo: customFieldRepo,
+	}
+}
+
+func (uc *searchUseCase) Search(businessID, query string, kinds []Domain.SearchKind, limit int) ([]Domain.SearchResult, error) {
+	return uc.index.Search(businessID, query, kinds, limit, uc.loadDocuments)
+}
+
+// loadDocuments reads everything a shop's index is built from. Only active products
+// can be sold and only active customers picked at the till, so nothing else is indexed.
+func (uc *searchUseCase) loadDocuments(businessID string) ([]Domain.SearchDocument, error) {
+	productStatus := Domain.ProductStatusActive
+	products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{Status: &productStatus})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load products for search: %w", err)
+	}
+
+	customerStatus := Domain.CustomerStatusActive
+	customers, err := uc.customerRepo.FindByBusinessID(businessID, Domain.CustomerFilters{Status: &customerStatus})
+	if err != nil {
+		return nil, fmt.Errorf("failed to load customers for search: %w", err)
+	}
+
+	fields, err := uc.customFieldRepo.FindByBusinessID(businessID, "", true)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load custom fields for search: %w", err)
+	}
+	searchable := map[Domain.CustomFieldEntity][]string{}
+	for _, field := range fields {
+		if field.Searchable {
+			searchable[field.Entity] = append(searchable[field.Entity], field.Key)
+		}
+	}
+
+	docs := make([]Domain.SearchDocument, 0, len(products)+len(customers))
+	for _, product := range products {
+		docs = append(docs, Domain.SearchDocument{
+			Kind:     Domain.SearchKindProduct,
+			ID:       product.ID.Hex(),
+			Title:    product.Name,
+			Subtitle: product.SKU,
+			Terms:    append([]string{product.SKU, product.Barcode}, customFieldTerms(product.CustomFields, searchable[Domain.CustomFieldEntityProduct])...),
+		})
+	}
+	for _, customer := range customers {
+		docs = append(docs, Domain.SearchDocument{
+			Kind:     Domain.SearchKindCustomer,
+			ID:       customer.ID.Hex(),
+			Title:    customer.Name,
+			Subtitle: customer.Phone,
+			Terms:    append([]string{customer.Phone, customer.Email}, customFieldTerms(customer.CustomFields, searchable[Domain.CustomFieldEntityCustomer])...),
+		})
+	}
+
+	return docs, nil
+}
+
+// customFieldTerms are a record's values of the shop's searchable custom fields
+func customFieldTerms(values Domain.CustomFields, keys []string) []string {
+	var terms []string
+	for _, key := range keys {
+		if term := Domain.FormatCustomField(values[key]); term != "" {
+			terms = append(terms, term)
+		}
+	}
+	return terms
+}
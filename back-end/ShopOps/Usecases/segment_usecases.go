@@ -0,0 +1,372 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SegmentUseCase interface {
+	CreateSegment(businessID, userID string, req Domain.CreateSegmentRequest) (*Domain.CustomerSegment, error)
+	GetSegments(businessID string) ([]Domain.CustomerSegment, error)
+	GetSegmentByID(ctx context.Context, id, businessID string) (*Domain.CustomerSegment, error)
+	UpdateSegment(ctx context.Context, id, businessID string, req Domain.UpdateSegmentRequest) (*Domain.CustomerSegment, error)
+	DeleteSegment(ctx context.Context, id, businessID string) error
+	GetSegmentMembers(ctx context.Context, id, businessID string, limit, offset int) (*Domain.SegmentMembersResponse, error)
+	PreviewSegment(businessID string, req Domain.PreviewSegmentRequest, limit int) (*Domain.SegmentMembersResponse, error)
+	MatchCustomers(ctx context.Context, id, businessID string) ([]Domain.Customer, error)
+	RefreshSegmentCounts() error
+}
+
+type segmentUseCase struct {
+	segmentRepo  Domain.SegmentRepository
+	customerRepo Domain.CustomerRepository
+	businessRepo Domain.BusinessRepository
+}
+
+func NewSegmentUseCase(
+	segmentRepo Domain.SegmentRepository,
+	customerRepo Domain.CustomerRepository,
+	businessRepo Domain.BusinessRepository,
+) SegmentUseCase {
+	return &segmentUseCase{
+		segmentRepo:  segmentRepo,
+		customerRepo: customerRepo,
+		businessRepo: businessRepo,
+	}
+}
+
+func (uc *segmentUseCase) CreateSegment(businessID, userID string, req Domain.CreateSegmentRequest) (*Domain.CustomerSegment, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("segment name is required")
+	}
+
+	if err := validateSegmentRules(req.Rules); err != nil {
+		return nil, err
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	segment := &Domain.CustomerSegment{
+		BusinessID:      objBusinessID,
+		Name:            name,
+		Description:     req.Description,
+		Rules:           req.Rules,
+		ShowOnDashboard: req.ShowOnDashboard,
+		CreatedBy:       objUserID,
+	}
+
+	if err := uc.segmentRepo.Create(segment); err != nil {
+		return nil, fmt.Errorf("failed to create segment: %w", err)
+	}
+
+	uc.refreshCount(segment)
+
+	return segment, nil
+}
+
+func (uc *segmentUseCase) GetSegments(businessID string) ([]Domain.CustomerSegment, error) {
+	segments, err := uc.segmentRepo.FindByBusinessID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if segments == nil {
+		segments = []Domain.CustomerSegment{}
+	}
+	return segments, nil
+}
+
+func (uc *segmentUseCase) GetSegmentByID(ctx context.Context, id, businessID string) (*Domain.CustomerSegment, error) {
+	segment, err := uc.segmentRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find segment: %w", err)
+	}
+	if segment == nil {
+		return nil, Domain.NotFoundError("segment not found")
+	}
+
+	// Verify segment belongs to business
+
+	return segment, nil
+}
+
+func (uc *segmentUseCase) UpdateSegment(ctx context.Context, id, businessID string, req Domain.UpdateSegmentRequest) (*Domain.CustomerSegment, error) {
+	segment, err := uc.GetSegmentByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("segment name is required")
+		}
+		segment.Name = name
+	}
+	if req.Description != nil {
+		segment.Description = *req.Description
+	}
+	if req.ShowOnDashboard != nil {
+		segment.ShowOnDashboard = *req.ShowOnDashboard
+	}
+
+	rulesChanged := req.Rules != nil
+	if rulesChanged {
+		if err := validateSegmentRules(req.Rules); err != nil {
+			return nil, err
+		}
+		segment.Rules = req.Rules
+	}
+
+	if err := uc.segmentRepo.Update(segment); err != nil {
+		return nil, fmt.Errorf("failed to update segment: %w", err)
+	}
+
+	if rulesChanged {
+		uc.refreshCount(segment)
+	}
+
+	return segment, nil
+}
+
+func (uc *segmentUseCase) DeleteSegment(ctx context.Context, id, businessID string) error {
+	if _, err := uc.GetSegmentByID(ctx, id, businessID); err != nil {
+		return err
+	}
+
+	return uc.segmentRepo.Delete(id)
+}
+
+func (uc *segmentUseCase) GetSegmentMembers(ctx context.Context, id, businessID string, limit, offset int) (*Domain.SegmentMembersResponse, error) {
+	segment, err := uc.GetSegmentByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	members, err := uc.evaluate(businessID, segment.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	// Listing members is a full evaluation, so the cached count comes for free
+	now := time.Now()
+	if err := uc.segmentRepo.UpdateMemberCount(segment.ID, len(members), now); err != nil {
+		Infrastructure.Logger.Warn("failed to update member count for segment", "segment_id", id, "error", err)
+	} else {
+		segment.MemberCount = len(members)
+		segment.EvaluatedAt = &now
+	}
+
+	response := pageSegmentMembers(members, limit, offset)
+	response.Segment = segment
+	return response, nil
+}
+
+func (uc *segmentUseCase) PreviewSegment(businessID string, req Domain.PreviewSegmentRequest, limit int) (*Domain.SegmentMembersResponse, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if err := validateSegmentRules(req.Rules); err != nil {
+		return nil, err
+	}
+
+	members, err := uc.evaluate(businessID, req.Rules)
+	if err != nil {
+		return nil, err
+	}
+
+	return pageSegmentMembers(members, limit, 0), nil
+}
+
+func (uc *segmentUseCase) MatchCustomers(ctx context.Context, id, businessID string) ([]Domain.Customer, error) {
+	segment, err := uc.GetSegmentByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	return uc.evaluate(businessID, segment.Rules)
+}
+
+// RefreshSegmentCounts re-evaluates every segment so dashboard counts stay current
+func (uc *segmentUseCase) RefreshSegmentCounts() error {
+	segments, err := uc.segmentRepo.FindAll()
+	if err != nil {
+		return err
+	}
+
+	for i := range segments {
+		uc.refreshCount(&segments[i])
+	}
+
+	return nil
+}
+
+func (uc *segmentUseCase) refreshCount(segment *Domain.CustomerSegment) {
+	members, err := uc.evaluate(segment.BusinessID.Hex(), segment.Rules)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to evaluate segment", "segment_id", segment.ID.Hex(), "error", err)
+		return
+	}
+
+	now := time.Now()
+	if err := uc.segmentRepo.UpdateMemberCount(segment.ID, len(members), now); err != nil {
+		Infrastructure.Logger.Warn("failed to update member count for segment", "segment_id", segment.ID.Hex(), "error", err)
+		return
+	}
+
+	segment.MemberCount = len(members)
+	segment.EvaluatedAt = &now
+}
+
+// evaluate returns the active customers matching every rule. Sales are linked to
+// customers through the normalized phone number.
+func (uc *segmentUseCase) evaluate(businessID string, rules []Domain.SegmentRule) ([]Domain.Customer, error) {
+	customers, err := uc.customerRepo.FindByBusinessID(businessID, Domain.CustomerFilters{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customers: %w", err)
+	}
+
+	// One aggregation per distinct window; window 0 is all time
+	now := time.Now()
+	statsByWindow := make(map[int]map[string]Domain.CustomerPurchaseStats)
+	for _, rule := range rules {
+		if rule.Field == Domain.SegmentFieldTag {
+			continue
+		}
+		if _, ok := statsByWindow[rule.WindowDays]; ok {
+			continue
+		}
+
+		var since *time.Time
+		if rule.WindowDays > 0 {
+			start := now.AddDate(0, 0, -rule.WindowDays)
+			since = &start
+		}
+
+		stats, err := uc.segmentRepo.PurchaseStats(businessID, since)
+		if err != nil {
+			return nil, err
+		}
+
+		byPhone := make(map[string]Domain.CustomerPurchaseStats, len(stats))
+		for _, s := range stats {
+			byPhone[s.Phone] = s
+		}
+		statsByWindow[rule.WindowDays] = byPhone
+	}
+
+	var members []Domain.Customer
+	for _, customer := range customers {
+		matched := true
+		for _, rule := range rules {
+			if !matchSegmentRule(rule, customer, statsByWindow[rule.WindowDays], now) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			members = append(members, customer)
+		}
+	}
+
+	return members, nil
+}
+
+func matchSegmentRule(rule Domain.SegmentRule, customer Domain.Customer, stats map[string]Domain.CustomerPurchaseStats, now time.Time) bool {
+	if rule.Field == Domain.SegmentFieldTag {
+		hasTag := false
+		for _, tag := range customer.Tags {
+			if strings.EqualFold(tag, rule.Tag) {
+				hasTag = true
+				break
+			}
+		}
+		return hasTag == (rule.Op == Domain.SegmentOpEq)
+	}
+
+	var purchases Domain.CustomerPurchaseStats
+	var bought bool
+	if customer.Phone != "" {
+		purchases, bought = stats[customer.Phone]
+	}
+
+	switch rule.Field {
+	case Domain.SegmentFieldPurchaseCount:
+		return rule.Compare(float64(purchases.Purchases))
+	case Domain.SegmentFieldTotalSpent:
+		return rule.Compare(purchases.TotalSpent)
+	case Domain.SegmentFieldDaysSinceLastPurchase:
+		days := math.Inf(1)
+		if bought {
+			days = math.Floor(now.Sub(purchases.LastPurchaseAt).Hours() / 24)
+		}
+		return rule.Compare(days)
+	}
+
+	return false
+}
+
+func validateSegmentRules(rules []Domain.SegmentRule) error {
+	if len(rules) == 0 {
+		return fmt.Errorf("segment needs at least one rule")
+	}
+	for i, rule := range rules {
+		if err := rule.Validate(); err != nil {
+			return fmt.Errorf("rule %d: %w", i+1, err)
+		}
+	}
+	return nil
+}
+
+func pageSegmentMembers(members []Domain.Customer, limit, offset int) *Domain.SegmentMembersResponse {
+	total := len(members)
+
+	if offset > total {
+		offset = total
+	}
+	end := total
+	if limit > 0 && offset+limit < total {
+		end = offset + limit
+	}
+
+	page := members[offset:end]
+	if page == nil {
+		page = []Domain.Customer{}
+	}
+
+	return &Domain.SegmentMembersResponse{
+		Customers: page,
+		Total:     total,
+		Limit:     limit,
+		Offset:    offset,
+	}
+}
@@ -0,0 +1,237 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	defaultSettingHistory = 50
+	maxSettingHistory     = 500
+)
+
+type ShopSettingsUseCase interface {
+	GetDefinitions() []Domain.SettingDefinition
+	GetSettings(businessID string) (*Domain.ShopSettings, error)
+	// UpdateSettings changes the settings given and records each one that changed
+	UpdateSettings(businessID, userID string, req Domain.UpdateShopSettingsRequest) (*Domain.ShopSettings, error)
+	// GetHistory lists changes newest first, of one setting when key is set
+	GetHistory(businessID, key string, limit int) ([]Domain.SettingChange, error)
+	// GetBundle gathers every setting a till needs into one download
+	GetBundle(ctx context.Context, businessID string) (*Domain.SettingsBundle, error)
+}
+
+type shopSettingsUseCase struct {
+	settingsRepo      Domain.ShopSettingsRepository
+	businessRepo      Domain.BusinessRepository
+	catalogSettingsUC CatalogSettingsUseCase
+	scaleUC           ScaleUseCase
+	currencyUC        CurrencyUseCase
+	receiptUC         ReceiptUseCase
+}
+
+func NewShopSettingsUseCase(
+	settingsRepo Domain.ShopSettingsRepository,
+	businessRepo Domain.BusinessRepository,
+	catalogSettingsUC CatalogSettingsUseCase,
+	scaleUC ScaleUseCase,
+	currencyUC CurrencyUseCase,
+	receiptUC ReceiptUseCase,
+) ShopSettingsUseCase {
+	return &shopSettingsUseCase{
+		settingsRepo:      settingsRepo,
+		businessRepo:      businessRepo,
+		catalogSettingsUC: catalogSettingsUC,
+		scaleUC:           scaleUC,
+		currencyUC:        currencyUC,
+		receiptUC:         receiptUC,
+	}
+}
+
+func (uc *shopSettingsUseCase) GetDefinitions() []Domain.SettingDefinition {
+	return Domain.ShopSettingDefinitions
+}
+
+func (uc *shopSettingsUseCase) GetSettings(businessID string) (*Domain.ShopSettings, error) {
+	settings, err := uc.settingsRepo.FindSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if settings != nil {
+		return settings, nil
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	defaults := Domain.DefaultShopSettings
+	defaults.BusinessID = objBusinessID
+	return &defaults, nil
+}
+
+func (uc *shopSettingsUseCase) UpdateSettings(businessID, userID string, req Domain.UpdateShopSettingsRequest) (*Domain.ShopSettings, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	before, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	settings := *before
+
+	if req.AllowNegativeStock != nil {
+		settings.AllowNegativeStock = *req.AllowNegativeStock
+	}
+	if req.AllowBackorders != nil {
+		settings.AllowBackorders = *req.AllowBackorders
+	}
+	if req.MaxDiscountPercent != nil {
+		settings.MaxDiscountPercent = *req.MaxDiscountPercent
+	}
+	if req.RequireCustomerForCredit != nil {
+		settings.RequireCustomerForCredit = *req.RequireCustomerForCredit
+	}
+	if req.DefaultPaymentMethod != nil {
+		settings.DefaultPaymentMethod = *req.DefaultPaymentMethod
+	}
+	if req.AutoPrintReceipt != nil {
+		settings.AutoPrintReceipt = *req.AutoPrintReceipt
+	}
+	if req.ReceiptCopies != nil {
+		settings.ReceiptCopies = *req.ReceiptCopies
+	}
+	if req.OfferSMSReceipt != nil {
+		settings.OfferSMSReceipt = *req.OfferSMSReceipt
+	}
+	if req.LockAfterMinutes != nil {
+		settings.LockAfterMinutes = *req.LockAfterMinutes
+	}
+	if req.SyncIntervalMinutes != nil {
+		settings.SyncIntervalMinutes = *req.SyncIntervalMinutes
+	}
+	if req.OfflineSalesLimit != nil {
+		settings.OfflineSalesLimit = *req.OfflineSalesLimit
+	}
+	if req.DefaultMinStock != nil {
+		settings.DefaultMinStock = *req.DefaultMinStock
+	}
+	if req.BookingOpensHour != nil {
+		settings.BookingOpensHour = *req.BookingOpensHour
+	}
+	if req.BookingClosesHour != nil {
+		settings.BookingClosesHour = *req.BookingClosesHour
+	}
+	if req.BookingSlotMinutes != nil {
+		settings.BookingSlotMinutes = *req.BookingSlotMinutes
+	}
+	if req.BookingReminderHours != nil {
+		settings.BookingReminderHours = *req.BookingReminderHours
+	}
+	if req.AnonymizeAfterYears != nil {
+		settings.AnonymizeAfterYears = *req.AnonymizeAfterYears
+	}
+	if err := settings.Validate(); err != nil {
+		return nil, err
+	}
+
+	changes := settings.Changes(before)
+	if len(changes) == 0 {
+		return before, nil
+	}
+	if err := uc.settingsRepo.SaveSettings(&settings); err != nil {
+		return nil, err
+	}
+	for i := range changes {
+		changes[i].ChangedBy = objUserID
+		changes[i].ChangedAt = settings.UpdatedAt
+	}
+	if err := uc.settingsRepo.CreateChanges(changes); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+func (uc *shopSettingsUseCase) GetHistory(businessID, key string, limit int) ([]Domain.SettingChange, error) {
+	if key != "" && !knownSetting(key) {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, fmt.Sprintf("unknown setting %q", key))
+	}
+	if limit <= 0 {
+		limit = defaultSettingHistory
+	}
+	if limit > maxSettingHistory {
+		limit = maxSettingHistory
+	}
+
+	changes, err := uc.settingsRepo.FindChanges(businessID, key, limit)
+	if err != nil {
+		return nil, err
+	}
+	if changes == nil {
+		changes = []Domain.SettingChange{}
+	}
+	return changes, nil
+}
+
+func (uc *shopSettingsUseCase) GetBundle(ctx context.Context, businessID string) (*Domain.SettingsBundle, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	shop, err := uc.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	catalog, err := uc.catalogSettingsUC.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	scale, err := uc.scaleUC.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	currencies, err := uc.currencyUC.GetSettings(businessID)
+	if err != nil {
+		return nil, err
+	}
+	receipt, err := uc.receiptUC.ResolveTemplate(ctx, businessID, "")
+	if err != nil {
+		return nil, err
+	}
+
+	timezone := business.Timezone
+	if timezone == "" {
+		timezone = Domain.DefaultTimezone
+	}
+	return &Domain.SettingsBundle{
+		BusinessID:    business.ID,
+		Currency:      business.Currency,
+		Timezone:      timezone,
+		DayCutoffHour: business.DayCutoffHour,
+		Language:      business.Language,
+		Shop:          *shop,
+		Catalog:       *catalog,
+		Scale:         *scale,
+		Currencies:    *currencies,
+		Receipt:       *receipt,
+	}, nil
+}
+
+func knownSetting(key string) bool {
+	for _, def := range Domain.ShopSettingDefinitions {
+		if def.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,376 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type ShrinkageUseCase interface {
+	RecordNoSale(ctx context.Context, businessID, userID string, req Domain.RecordDrawerEventRequest) (*Domain.DrawerEvent, error)
+	GetDrawerEvents(businessID string, filters Domain.DrawerEventFilters) ([]Domain.DrawerEvent, error)
+	GetShrinkageReport(businessID, startDate, endDate string, groupBy Domain.ShrinkageGroupBy, threshold float64) (*Domain.ShrinkageReport, error)
+}
+
+type shrinkageUseCase struct {
+	shrinkageRepo Domain.ShrinkageRepository
+	employeeRepo  Domain.EmployeeRepository
+	businessRepo  Domain.BusinessRepository
+}
+
+const (
+	defaultShrinkageThreshold = 3.0
+	// Rates over fewer sales than this are too noisy to flag
+	shrinkageMinSales = 20
+)
+
+func NewShrinkageUseCase(
+	shrinkageRepo Domain.ShrinkageRepository,
+	employeeRepo Domain.EmployeeRepository,
+	businessRepo Domain.BusinessRepository,
+) ShrinkageUseCase {
+	return &shrinkageUseCase{
+		shrinkageRepo: shrinkageRepo,
+		employeeRepo:  employeeRepo,
+		businessRepo:  businessRepo,
+	}
+}
+
+// RecordNoSale logs a drawer opened without a sale. The employee defaults to whoever is
+// clocked in on the device, then to the employee linked to the user.
+func (uc *shrinkageUseCase) RecordNoSale(ctx context.Context, businessID, userID string, req Domain.RecordDrawerEventRequest) (*Domain.DrawerEvent, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	event := &Domain.DrawerEvent{
+		BusinessID: business.ID,
+		Type:       Domain.DrawerEventNoSale,
+		DeviceID:   req.DeviceID,
+		Reason:     req.Reason,
+		CreatedBy:  objUserID,
+		CreatedAt:  time.Now(),
+	}
+
+	if req.OpenedAt != nil {
+		if req.OpenedAt.After(event.CreatedAt) {
+			return nil, fmt.Errorf("opened_at cannot be in the future")
+		}
+		event.CreatedAt = *req.OpenedAt
+	}
+
+	if req.EmployeeID != nil && *req.EmployeeID != "" {
+		employee, err := uc.employeeRepo.FindInBusiness(ctx, *req.EmployeeID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find employee: %w", err)
+		}
+		if employee == nil {
+			return nil, Domain.NotFoundError("employee not found")
+		}
+		if employee.BusinessID != business.ID {
+			return nil, Domain.AccessDeniedError("access denied: employee does not belong to this business")
+		}
+		event.EmployeeID = &employee.ID
+	} else {
+		event.EmployeeID = uc.defaultEmployee(businessID, userID, req.DeviceID)
+	}
+
+	if err := uc.shrinkageRepo.CreateDrawerEvent(event); err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+func (uc *shrinkageUseCase) defaultEmployee(businessID, userID, deviceID string) *primitive.ObjectID {
+	if deviceID != "" {
+		entry, err := uc.employeeRepo.FindOpenTimeEntryByDevice(businessID, deviceID)
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to find shift on device", "device_id", deviceID, "error", err)
+		} else if entry != nil {
+			return &entry.EmployeeID
+		}
+	}
+
+	employee, err := uc.employeeRepo.FindByUserID(businessID, userID)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to find employee for user", "user_id", userID, "error", err)
+		return nil
+	}
+	if employee == nil {
+		return nil
+	}
+	return &employee.ID
+}
+
+func (uc *shrinkageUseCase) GetDrawerEvents(businessID string, filters Domain.DrawerEventFilters) ([]Domain.DrawerEvent, error) {
+	return uc.shrinkageRepo.FindDrawerEvents(businessID, filters)
+}
+
+// GetShrinkageReport lines up voids, no-sale drawer opens and stock losses per employee
+// or device, and flags lines where a metric is threshold times the average of the others
+func (uc *shrinkageUseCase) GetShrinkageReport(businessID, startDate, endDate string, groupBy Domain.ShrinkageGroupBy, threshold float64) (*Domain.ShrinkageReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	if groupBy == "" {
+		groupBy = Domain.ShrinkageByEmployee
+	}
+	if groupBy != Domain.ShrinkageByEmployee && groupBy != Domain.ShrinkageByDevice {
+		return nil, fmt.Errorf("invalid group_by, expected employee or device")
+	}
+	if threshold == 0 {
+		threshold = defaultShrinkageThreshold
+	}
+	if threshold <= 1 {
+		return nil, fmt.Errorf("threshold must be greater than 1")
+	}
+
+	clock := clockFor(business)
+	fromDay, toDay, err := parseDayRange(startDate, endDate, clock)
+	if err != nil {
+		return nil, err
+	}
+	start, end := clock.Range(fromDay, toDay)
+	timezone := timezoneName(clock.loc)
+
+	sales, err := uc.shrinkageRepo.SaleActivity(businessID, start, end, timezone, clock.cutoff, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	drawer, err := uc.shrinkageRepo.DrawerActivity(businessID, start, end, timezone, clock.cutoff, groupBy)
+	if err != nil {
+		return nil, err
+	}
+	losses, err := uc.shrinkageRepo.StockLossActivity(businessID, start, end, timezone, clock.cutoff)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[string]string)
+	if groupBy == Domain.ShrinkageByEmployee {
+		employees, err := uc.employeeRepo.FindByBusinessID(businessID, nil)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find employees: %w", err)
+		}
+
+		// Losses are recorded by users; credit them to the user's employee
+		byUser := make(map[string]string)
+		for _, employee := range employees {
+			names[employee.ID.Hex()] = employee.Name
+			if employee.UserID != nil {
+				byUser[employee.UserID.Hex()] = employee.ID.Hex()
+			}
+		}
+		for i := range losses {
+			if employeeID, ok := byUser[losses[i].Key]; ok {
+				losses[i].Key = employeeID
+			}
+		}
+		// Sales and drawer opens without an employee are keyed by user too
+		for i := range sales {
+			if employeeID, ok := byUser[sales[i].Key]; ok {
+				sales[i].Key = employeeID
+			}
+		}
+		for i := range drawer {
+			if employeeID, ok := byUser[drawer[i].Key]; ok {
+				drawer[i].Key = employeeID
+			}
+		}
+	}
+
+	activity := append(append(sales, drawer...), losses...)
+	report := &Domain.ShrinkageReport{
+		StartDate: fromDay.Format("2006-01-02"),
+		EndDate:   toDay.Format("2006-01-02"),
+		GroupBy:   groupBy,
+		Threshold: threshold,
+		Lines:     []Domain.ShrinkageLine{},
+		Anomalies: []Domain.ShrinkageAnomaly{},
+		Trend:     []Domain.ShrinkageTrendPoint{},
+	}
+
+	lines := make(map[string]*Domain.ShrinkageLine)
+	weeks := make(map[string]*Domain.ShrinkageTrendPoint)
+	for i, row := range activity {
+		addShrinkage(&report.Totals, row)
+
+		// Stock movements carry no device, so losses only count towards the totals there
+		if groupBy == Domain.ShrinkageByEmployee || i < len(sales)+len(drawer) {
+			line, ok := lines[row.Key]
+			if !ok {
+				line = &Domain.ShrinkageLine{Key: row.Key, Name: shrinkageName(row.Key, groupBy, names)}
+				lines[row.Key] = line
+			}
+			addShrinkage(line, row)
+		}
+
+		day, err := time.Parse("2006-01-02", row.Day)
+		if err != nil {
+			continue
+		}
+		period := day.AddDate(0, 0, -((int(day.Weekday()) + 6) % 7)).Format("2006-01-02")
+		point, ok := weeks[period]
+		if !ok {
+			point = &Domain.ShrinkageTrendPoint{Period: period}
+			weeks[period] = point
+		}
+		point.Sales += row.Sales
+		point.Voids += row.Voids
+		point.VoidAmount += row.VoidAmount
+		point.NoSales += row.NoSales
+		point.LossValue += row.LossValue
+	}
+
+	report.Totals.Key = "total"
+	report.Totals.Name = "Total"
+	finishShrinkageLine(&report.Totals)
+
+	for _, line := range lines {
+		finishShrinkageLine(line)
+		report.Lines = append(report.Lines, *line)
+	}
+	sort.Slice(report.Lines, func(i, j int) bool {
+		if report.Lines[i].VoidAmount != report.Lines[j].VoidAmount {
+			return report.Lines[i].VoidAmount > report.Lines[j].VoidAmount
+		}
+		return report.Lines[i].Key < report.Lines[j].Key
+	})
+
+	report.Anomalies = flagShrinkageAnomalies(report.Lines, threshold)
+
+	for _, point := range weeks {
+		point.VoidAmount = roundCurrency(point.VoidAmount)
+		point.LossValue = roundCurrency(point.LossValue)
+		if point.Sales > 0 {
+			point.VoidRate = roundCurrency(float64(point.Voids) / float64(point.Sales) * 100)
+		}
+		report.Trend = append(report.Trend, *point)
+	}
+	sort.Slice(report.Trend, func(i, j int) bool {
+		return report.Trend[i].Period < report.Trend[j].Period
+	})
+
+	return report, nil
+}
+
+func shrinkageName(key string, groupBy Domain.ShrinkageGroupBy, names map[string]string) string {
+	if name, ok := names[key]; ok {
+		return name
+	}
+	if key == "" {
+		if groupBy == Domain.ShrinkageByDevice {
+			return "No device"
+		}
+		return "Unknown"
+	}
+	if groupBy == Domain.ShrinkageByEmployee {
+		return "User " + key
+	}
+	return key
+}
+
+func addShrinkage(line *Domain.ShrinkageLine, row Domain.ShrinkageActivity) {
+	line.Sales += row.Sales
+	line.SalesAmount += row.SalesAmount
+	line.Voids += row.Voids
+	line.VoidAmount += row.VoidAmount
+	line.NoSales += row.NoSales
+	line.LossQuantity += row.LossQuantity
+	line.LossValue += row.LossValue
+}
+
+func finishShrinkageLine(line *Domain.ShrinkageLine) {
+	line.SalesAmount = roundCurrency(line.SalesAmount)
+	line.VoidAmount = roundCurrency(line.VoidAmount)
+	line.LossValue = roundCurrency(line.LossValue)
+	if line.Sales > 0 {
+		line.VoidRate = roundCurrency(float64(line.Voids) / float64(line.Sales) * 100)
+		line.NoSaleRate = roundCurrency(float64(line.NoSales) / float64(line.Sales) * 100)
+	}
+}
+
+// flagShrinkageAnomalies compares each line's void rate, no-sale rate and loss value with
+// the average of the other lines. Rates are only compared between lines with enough sales.
+func flagShrinkageAnomalies(lines []Domain.ShrinkageLine, threshold float64) []Domain.ShrinkageAnomaly {
+	metrics := []struct {
+		name     string
+		value    func(Domain.ShrinkageLine) float64
+		eligible func(Domain.ShrinkageLine) bool
+	}{
+		{
+			name:     "void_rate",
+			value:    func(l Domain.ShrinkageLine) float64 { return l.VoidRate },
+			eligible: func(l Domain.ShrinkageLine) bool { return l.Sales >= shrinkageMinSales },
+		},
+		{
+			name:     "no_sale_rate",
+			value:    func(l Domain.ShrinkageLine) float64 { return l.NoSaleRate },
+			eligible: func(l Domain.ShrinkageLine) bool { return l.Sales >= shrinkageMinSales },
+		},
+		{
+			name:     "loss_value",
+			value:    func(l Domain.ShrinkageLine) float64 { return l.LossValue },
+			eligible: func(l Domain.ShrinkageLine) bool { return true },
+		},
+	}
+
+	anomalies := []Domain.ShrinkageAnomaly{}
+	for _, metric := range metrics {
+		var eligible []int
+		total := 0.0
+		for i, line := range lines {
+			if metric.eligible(line) {
+				eligible = append(eligible, i)
+				total += metric.value(line)
+			}
+		}
+		if len(eligible) < 2 {
+			continue
+		}
+
+		for _, i := range eligible {
+			value := metric.value(lines[i])
+			average := (total - value) / float64(len(eligible)-1)
+			if value <= 0 || average <= 0 || value < threshold*average {
+				continue
+			}
+
+			lines[i].Anomalies = append(lines[i].Anomalies, metric.name)
+			anomalies = append(anomalies, Domain.ShrinkageAnomaly{
+				Key:     lines[i].Key,
+				Name:    lines[i].Name,
+				Metric:  metric.name,
+				Value:   value,
+				Average: roundCurrency(average),
+				Ratio:   roundCurrency(value / average),
+			})
+		}
+	}
+
+	sort.SliceStable(anomalies, func(i, j int) bool {
+		return anomalies[i].Ratio > anomalies[j].Ratio
+	})
+
+	return anomalies
+}
@@ -0,0 +1,610 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SMSUseCase interface {
+	SendSaleReceipt(ctx context.Context, saleID, businessID string) (*Domain.SMSMessage, error)
+	// QueueSaleReceipt sends the receipt from the job queue so checkout does not wait on the gateway
+	QueueSaleReceipt(ctx context.Context, saleID, businessID string) error
+	// SendAlert texts an operational alert to the business's own phone
+	SendAlert(businessID, body string) (*Domain.SMSMessage, error)
+	// SendNotice texts a customer news of their order, such as a backorder ready to collect
+	SendNotice(businessID, phone string, saleID *primitive.ObjectID, body string) (*Domain.SMSMessage, error)
+	GetMessages(businessID string, filters Domain.SMSMessageFilters) ([]Domain.SMSMessage, error)
+
+	// Promotional campaigns
+	SendBulk(ctx context.Context, businessID, userID string, req Domain.BulkSMSRequest) (*Domain.SMSCampaign, error)
+	GetCampaigns(businessID string, limit int) ([]Domain.SMSCampaign, error)
+	GetCampaignByID(id, businessID string) (*Domain.SMSCampaign, error)
+
+	// Blacklist
+	AddToBlacklist(businessID, userID string, req Domain.AddSMSBlacklistRequest) (*Domain.SMSBlacklistEntry, error)
+	RemoveFromBlacklist(businessID, phone string) error
+	GetBlacklist(businessID string) ([]Domain.SMSBlacklistEntry, error)
+
+	// Job queue handlers
+	RunCampaignJob(ctx context.Context, job *Domain.Job) error
+	SendReceiptJob(ctx context.Context, job *Domain.Job) error
+}
+
+type smsUseCase struct {
+	smsRepo      Domain.SMSRepository
+	customerRepo Domain.CustomerRepository
+	salesRepo    Domain.SaleRepository
+	businessRepo Domain.BusinessRepository
+	provider     Infrastructure.SMSProvider
+	segmentUC    SegmentUseCase
+	jobQueue     Infrastructure.JobQueue
+	interval     time.Duration // Gap between campaign messages
+}
+
+// Promotional messages longer than four SMS segments are rejected
+const MaxBulkSMSLength = 612
+
+// A campaign still marked as sending after this long is stuck, its job dead or lost
+const staleCampaignAfter = 6 * time.Hour
+
+func NewSMSUseCase(
+	smsRepo Domain.SMSRepository,
+	customerRepo Domain.CustomerRepository,
+	salesRepo Domain.SaleRepository,
+	businessRepo Domain.BusinessRepository,
+	provider Infrastructure.SMSProvider,
+	segmentUC SegmentUseCase,
+	jobQueue Infrastructure.JobQueue,
+	perSecond int,
+) SMSUseCase {
+	if perSecond <= 0 {
+		perSecond = 5
+	}
+
+	return &smsUseCase{
+		smsRepo:      smsRepo,
+		customerRepo: customerRepo,
+		salesRepo:    salesRepo,
+		businessRepo: businessRepo,
+		provider:     provider,
+		segmentUC:    segmentUC,
+		jobQueue:     jobQueue,
+		interval:     time.Second / time.Duration(perSecond),
+	}
+}
+
+func (uc *smsUseCase) SendSaleReceipt(ctx context.Context, saleID, businessID string) (*Domain.SMSMessage, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	sale, err := uc.salesRepo.FindInBusiness(ctx, saleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sale: %w", err)
+	}
+	if sale == nil {
+		return nil, Domain.NotFoundError("sale not found")
+	}
+
+	phone := normalizePhone(sale.CustomerPhone, business.Country)
+	if phone == "" {
+		return nil, fmt.Errorf("sale has no customer phone")
+	}
+
+	saleObjID := sale.ID
+	message := &Domain.SMSMessage{
+		BusinessID: sale.BusinessID,
+		SaleID:     &saleObjID,
+		Phone:      phone,
+		Body:       receiptSMSBody(business, sale),
+		Type:       Domain.SMSTypeReceipt,
+	}
+
+	if uc.screen(message) {
+		uc.deliver(context.Background(), message)
+	}
+
+	if err := uc.smsRepo.LogMessage(message); err != nil {
+		Infrastructure.Logger.Warn("failed to log sms receipt for sale", "sale_id", saleID, "error", err)
+	}
+
+	return message, nil
+}
+
+func (uc *smsUseCase) SendAlert(businessID, body string) (*Domain.SMSMessage, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	phone := normalizePhone(business.Phone, business.Country)
+	if phone == "" {
+		return nil, fmt.Errorf("business has no phone")
+	}
+
+	message := &Domain.SMSMessage{
+		BusinessID: business.ID,
+		Phone:      phone,
+		Body:       fmt.Sprintf("%s: %s", business.Name, body),
+		Type:       Domain.SMSTypeAlert,
+	}
+
+	if uc.screen(message) {
+		uc.deliver(context.Background(), message)
+	}
+
+	if err := uc.smsRepo.LogMessage(message); err != nil {
+		Infrastructure.Logger.Warn("failed to log sms alert for business", "business_id", businessID, "error", err)
+	}
+
+	return message, nil
+}
+
+func (uc *smsUseCase) SendNotice(businessID, phone string, saleID *primitive.ObjectID, body string) (*Domain.SMSMessage, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	phone = normalizePhone(phone, business.Country)
+	if phone == "" {
+		return nil, fmt.Errorf("no customer phone")
+	}
+
+	message := &Domain.SMSMessage{
+		BusinessID: business.ID,
+		SaleID:     saleID,
+		Phone:      phone,
+		Body:       body,
+		Type:       Domain.SMSTypeNotice,
+	}
+
+	if uc.screen(message) {
+		uc.deliver(context.Background(), message)
+	}
+
+	if err := uc.smsRepo.LogMessage(message); err != nil {
+		Infrastructure.Logger.Warn("failed to log sms notice for business", "business_id", businessID, "error", err)
+	}
+
+	return message, nil
+}
+
+func (uc *smsUseCase) GetMessages(businessID string, filters Domain.SMSMessageFilters) ([]Domain.SMSMessage, error) {
+	messages, err := uc.smsRepo.FindMessages(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if messages == nil {
+		messages = []Domain.SMSMessage{}
+	}
+	return messages, nil
+}
+
+func (uc *smsUseCase) SendBulk(ctx context.Context, businessID, userID string, req Domain.BulkSMSRequest) (*Domain.SMSCampaign, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	text := strings.TrimSpace(req.Message)
+	if text == "" {
+		return nil, fmt.Errorf("message is required")
+	}
+	if utf8.RuneCountInString(text) > MaxBulkSMSLength {
+		return nil, fmt.Errorf("message exceeds %d characters", MaxBulkSMSLength)
+	}
+
+	// One campaign at a time per business keeps the gateway throttle meaningful
+	active, err := uc.smsRepo.FindActiveCampaign(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		if time.Since(active.CreatedAt) < staleCampaignAfter {
+			return nil, fmt.Errorf("another sms campaign is still sending")
+		}
+		uc.finishCampaign(active)
+	}
+
+	recipients, err := uc.campaignRecipients(ctx, businessID, business.Country, req)
+	if err != nil {
+		return nil, err
+	}
+	if len(recipients) == 0 {
+		return nil, fmt.Errorf("no opted-in customers match this audience")
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	campaign := &Domain.SMSCampaign{
+		BusinessID: objBusinessID,
+		Message:    text,
+		Status:     Domain.SMSCampaignStatusSending,
+		Total:      len(recipients),
+		CreatedBy:  objUserID,
+		Recipients: recipients,
+	}
+	if req.Tag != nil {
+		campaign.Tag = *req.Tag
+	}
+	if req.SegmentID != nil {
+		objSegmentID, err := primitive.ObjectIDFromHex(*req.SegmentID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid segment ID: %w", err)
+		}
+		campaign.SegmentID = &objSegmentID
+	}
+
+	if err := uc.smsRepo.CreateCampaign(campaign); err != nil {
+		return nil, fmt.Errorf("failed to create sms campaign: %w", err)
+	}
+
+	// Sending is throttled, so the request returns while the campaign runs from the queue
+	if _, err := uc.jobQueue.Enqueue(ctx, Domain.JobTypeSMSCampaign, businessID, map[string]string{"campaign_id": campaign.ID.Hex()}); err != nil {
+		uc.finishCampaign(campaign)
+		return nil, fmt.Errorf("failed to queue sms campaign: %w", err)
+	}
+
+	return campaign, nil
+}
+
+func (uc *smsUseCase) QueueSaleReceipt(ctx context.Context, saleID, businessID string) error {
+	_, err := uc.jobQueue.Enqueue(ctx, Domain.JobTypeSMSReceipt, businessID, map[string]string{"sale_id": saleID})
+	return err
+}
+
+// SendReceiptJob retries a receipt the gateway failed to take; each attempt is logged
+func (uc *smsUseCase) SendReceiptJob(ctx context.Context, job *Domain.Job) error {
+	if job.BusinessID == nil {
+		return fmt.Errorf("receipt job has no business")
+	}
+	message, err := uc.SendSaleReceipt(ctx, job.Payload["sale_id"], job.BusinessID.Hex())
+	if err != nil {
+		return err
+	}
+	if message.Status == Domain.SMSStatusFailed {
+		return fmt.Errorf("sms gateway: %s", message.Error)
+	}
+	return nil
+}
+
+// RunCampaignJob sends a queued campaign. Numbers already logged for the campaign are
+// skipped, so a campaign stopped by a deploy or crash resumes without double texting.
+func (uc *smsUseCase) RunCampaignJob(ctx context.Context, job *Domain.Job) error {
+	campaign, err := uc.smsRepo.FindCampaignByID(job.Payload["campaign_id"])
+	if err != nil {
+		return fmt.Errorf("failed to find sms campaign: %w", err)
+	}
+	if campaign == nil || campaign.Status != Domain.SMSCampaignStatusSending {
+		return nil
+	}
+	return uc.runCampaign(ctx, campaign)
+}
+
+func (uc *smsUseCase) GetCampaigns(businessID string, limit int) ([]Domain.SMSCampaign, error) {
+	campaigns, err := uc.smsRepo.FindCampaigns(businessID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if campaigns == nil {
+		campaigns = []Domain.SMSCampaign{}
+	}
+	return campaigns, nil
+}
+
+func (uc *smsUseCase) GetCampaignByID(id, businessID string) (*Domain.SMSCampaign, error) {
+	campaign, err := uc.smsRepo.FindCampaignByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find sms campaign: %w", err)
+	}
+	if campaign == nil {
+		return nil, Domain.NotFoundError("sms campaign not found")
+	}
+	if campaign.BusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("access denied: sms campaign does not belong to this business")
+	}
+	return campaign, nil
+}
+
+func (uc *smsUseCase) AddToBlacklist(businessID, userID string, req Domain.AddSMSBlacklistRequest) (*Domain.SMSBlacklistEntry, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	phone := normalizePhone(req.Phone, business.Country)
+	if phone == "" {
+		return nil, fmt.Errorf("phone is required")
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	entry := &Domain.SMSBlacklistEntry{
+		BusinessID: objBusinessID,
+		Phone:      phone,
+		Reason:     strings.TrimSpace(req.Reason),
+		CreatedBy:  objUserID,
+	}
+
+	if err := uc.smsRepo.AddToBlacklist(entry); err != nil {
+		return nil, err
+	}
+
+	// A blacklisted number has withdrawn consent, so the customer record follows
+	customer, err := uc.customerRepo.FindByPhone(businessID, phone)
+	if err != nil {
+		Infrastructure.Logger.Warn("failed to find customer for blacklisted phone", "phone", phone, "error", err)
+	} else if customer != nil && customer.MarketingOptIn {
+		now := time.Now()
+		customer.MarketingOptIn = false
+		customer.OptInUpdatedAt = &now
+		customer.OptInSource = "blacklist"
+		if err := uc.customerRepo.Update(customer); err != nil {
+			Infrastructure.Logger.Warn("failed to opt out customer", "customer_id", customer.ID.Hex(), "error", err)
+		}
+	}
+
+	return entry, nil
+}
+
+func (uc *smsUseCase) RemoveFromBlacklist(businessID, phone string) error {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return Domain.NotFoundError("business not found")
+	}
+
+	normalized := normalizePhone(phone, business.Country)
+	if normalized == "" {
+		return fmt.Errorf("phone is required")
+	}
+
+	return uc.smsRepo.RemoveFromBlacklist(businessID, normalized)
+}
+
+func (uc *smsUseCase) GetBlacklist(businessID string) ([]Domain.SMSBlacklistEntry, error) {
+	entries, err := uc.smsRepo.GetBlacklist(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []Domain.SMSBlacklistEntry{}
+	}
+	return entries, nil
+}
+
+// campaignRecipients returns the deduplicated phones of opted-in active customers,
+// narrowed by tag, segment and the explicit phone list when given
+func (uc *smsUseCase) campaignRecipients(ctx context.Context, businessID, country string, req Domain.BulkSMSRequest) ([]string, error) {
+	optedIn := true
+	customers, err := uc.customerRepo.FindByBusinessID(businessID, Domain.CustomerFilters{
+		Tag:            req.Tag,
+		MarketingOptIn: &optedIn,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customers: %w", err)
+	}
+
+	var inSegment map[primitive.ObjectID]bool
+	if req.SegmentID != nil {
+		members, err := uc.segmentUC.MatchCustomers(ctx, *req.SegmentID, businessID)
+		if err != nil {
+			return nil, err
+		}
+		inSegment = make(map[primitive.ObjectID]bool, len(members))
+		for _, member := range members {
+			inSegment[member.ID] = true
+		}
+	}
+
+	var requested map[string]bool
+	if len(req.Phones) > 0 {
+		requested = make(map[string]bool, len(req.Phones))
+		for _, phone := range req.Phones {
+			if normalized := normalizePhone(phone, country); normalized != "" {
+				requested[normalized] = true
+			}
+		}
+	}
+
+	seen := make(map[string]bool)
+	var recipients []string
+	for _, customer := range customers {
+		phone := customer.Phone
+		if phone == "" || seen[phone] {
+			continue
+		}
+		if inSegment != nil && !inSegment[customer.ID] {
+			continue
+		}
+		if requested != nil && !requested[phone] {
+			continue
+		}
+		seen[phone] = true
+		recipients = append(recipients, phone)
+	}
+
+	return recipients, nil
+}
+
+func (uc *smsUseCase) runCampaign(ctx context.Context, campaign *Domain.SMSCampaign) error {
+	campaignID := campaign.ID
+	hexID := campaignID.Hex()
+
+	logged, err := uc.smsRepo.FindMessages(campaign.BusinessID.Hex(), Domain.SMSMessageFilters{CampaignID: &hexID})
+	if err != nil {
+		return err
+	}
+	done := make(map[string]bool, len(logged))
+	campaign.Sent, campaign.Failed, campaign.Skipped = 0, 0, 0
+	for _, message := range logged {
+		done[message.Phone] = true
+		countCampaignMessage(campaign, message.Status)
+	}
+
+	// On shutdown, stop between messages and save the counts; the queue resumes the campaign
+	stop := func() error {
+		if err := uc.smsRepo.UpdateCampaign(campaign); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to update sms campaign", "campaign_id", hexID, "error", err)
+		}
+		return ctx.Err()
+	}
+
+	ticker := time.NewTicker(uc.interval)
+	defer ticker.Stop()
+
+	processed := 0
+	for _, phone := range campaign.Recipients {
+		if done[phone] {
+			continue
+		}
+		if ctx.Err() != nil {
+			return stop()
+		}
+
+		message := &Domain.SMSMessage{
+			BusinessID: campaign.BusinessID,
+			CampaignID: &campaignID,
+			Phone:      phone,
+			Body:       campaign.Message,
+			Type:       Domain.SMSTypePromotional,
+		}
+
+		// Checked per message so numbers blacklisted mid-campaign are honoured
+		if uc.screen(message) {
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return stop()
+			}
+			uc.deliver(ctx, message)
+		}
+
+		countCampaignMessage(campaign, message.Status)
+
+		if err := uc.smsRepo.LogMessage(message); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to log sms", "phone", phone, "error", err)
+		}
+
+		// Persist progress periodically so clients can poll the campaign
+		if processed++; processed%25 == 0 {
+			if err := uc.smsRepo.UpdateCampaign(campaign); err != nil {
+				Infrastructure.LoggerFrom(ctx).Warn("failed to update sms campaign", "campaign_id", hexID, "error", err)
+			}
+		}
+	}
+
+	uc.finishCampaign(campaign)
+	return nil
+}
+
+func countCampaignMessage(campaign *Domain.SMSCampaign, status Domain.SMSStatus) {
+	switch status {
+	case Domain.SMSStatusSent:
+		campaign.Sent++
+	case Domain.SMSStatusSkipped:
+		campaign.Skipped++
+	default:
+		campaign.Failed++
+	}
+}
+
+func (uc *smsUseCase) finishCampaign(campaign *Domain.SMSCampaign) {
+	now := time.Now()
+	campaign.Status = Domain.SMSCampaignStatusCompleted
+	campaign.CompletedAt = &now
+	if err := uc.smsRepo.UpdateCampaign(campaign); err != nil {
+		Infrastructure.Logger.Warn("failed to complete sms campaign", "campaign_id", campaign.ID.Hex(), "error", err)
+	}
+}
+
+// screen reports whether the message may be sent, marking it skipped for blacklisted numbers
+func (uc *smsUseCase) screen(message *Domain.SMSMessage) bool {
+	blacklisted, err := uc.smsRepo.IsBlacklisted(message.BusinessID.Hex(), message.Phone)
+	if err != nil {
+		message.Status = Domain.SMSStatusFailed
+		message.Error = err.Error()
+		return false
+	}
+	if blacklisted {
+		message.Status = Domain.SMSStatusSkipped
+		message.Error = "blacklisted"
+		return false
+	}
+	return true
+}
+
+// deliver hands the message to the provider and records the outcome on it
+func (uc *smsUseCase) deliver(ctx context.Context, message *Domain.SMSMessage) {
+	message.Provider = uc.provider.Name()
+
+	_, span := Infrastructure.StartSpan(ctx, "sms.send", Infrastructure.SpanKindClient)
+	span.SetAttribute("sms.provider", message.Provider)
+	span.SetAttribute("sms.type", string(message.Type))
+	providerID, err := uc.provider.Send(message.Phone, message.Body)
+	span.RecordError(err)
+	span.End()
+	if err != nil {
+		message.Status = Domain.SMSStatusFailed
+		message.Error = err.Error()
+		return
+	}
+
+	message.Status = Domain.SMSStatusSent
+	message.ProviderMessageID = providerID
+}
+
+func receiptSMSBody(business *Domain.Business, sale *Domain.Sale) string {
+	createdAt := sale.CreatedAt.In(businessLocation(business))
+	// Month names are English, so other languages get the date in digits
+	dateFormat := "02 Jan 2006 15:04"
+	if Domain.LanguageOr(business.Language, Domain.DefaultLanguage) != Domain.LanguageEnglish {
+		dateFormat = "02/01/2006 15:04"
+	}
+	ref := strings.ToUpper(sale.ID.Hex()[len(sale.ID.Hex())-8:])
+
+	return Infrastructure.Translate(business.Language, "sms.receipt",
+		business.Name, ref, business.Currency, sale.FinalAmount.Float64(), createdAt.Format(dateFormat))
+}
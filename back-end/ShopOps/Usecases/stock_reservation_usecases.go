@@ -0,0 +1,244 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StockReservationUseCase interface {
+	// CreateReservation holds stock for an accepted order, if enough is available
+	CreateReservation(ctx context.Context, businessID, userID string, req Domain.CreateStockReservationRequest) (*Domain.StockReservation, error)
+	GetReservations(businessID string, filters Domain.StockReservationFilters) ([]Domain.StockReservation, error)
+	GetReservation(ctx context.Context, id, businessID string) (*Domain.StockReservation, error)
+	// ReleaseReservation gives a cancelled order's stock back to the shop
+	ReleaseReservation(ctx context.Context, id, businessID, userID string, req Domain.ReleaseStockReservationRequest) (*Domain.StockReservation, error)
+	GetAvailability(ctx context.Context, productID, businessID string) (*Domain.ProductAvailability, error)
+	// GetBranchAvailability finds the product with the SKU in each of the user's
+	// branches, with what each has available
+	GetBranchAvailability(userID, sku string) ([]Domain.BranchAvailability, error)
+	// ExpireDue closes reservations that lapsed. Lapsed ones stop holding stock
+	// on their own; this only tidies their status. Run it every few minutes.
+	ExpireDue() error
+}
+
+type stockReservationUseCase struct {
+	reservationRepo Domain.StockReservationRepository
+	inventoryRepo   Domain.ProductRepository
+	businessRepo    Domain.BusinessRepository
+}
+
+func NewStockReservationUseCase(
+	reservationRepo Domain.StockReservationRepository,
+	inventoryRepo Domain.ProductRepository,
+	businessRepo Domain.BusinessRepository,
+) StockReservationUseCase {
+	return &stockReservationUseCase{
+		reservationRepo: reservationRepo,
+		inventoryRepo:   inventoryRepo,
+		businessRepo:    businessRepo,
+	}
+}
+
+func (uc *stockReservationUseCase) CreateReservation(ctx context.Context, businessID, userID string, req Domain.CreateStockReservationRequest) (*Domain.StockReservation, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+	if product.Status != Domain.ProductStatusActive {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "only active products can be reserved")
+	}
+
+	available, err := availableStock(uc.reservationRepo, product, nil)
+	if err != nil {
+		return nil, err
+	}
+	if available < req.Quantity {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("insufficient stock. Available: %.2f, Requested: %.2f", available, req.Quantity))
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	ttl := Domain.DefaultReservationTTL
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	if ttl > Domain.MaxReservationTTL {
+		ttl = Domain.MaxReservationTTL
+	}
+
+	reservation := &Domain.StockReservation{
+		BusinessID:    product.BusinessID,
+		ProductID:     product.ID,
+		Quantity:      req.Quantity,
+		Channel:       req.Channel,
+		Reference:     req.Reference,
+		CustomerName:  req.CustomerName,
+		CustomerPhone: normalizePhone(req.CustomerPhone, business.Country),
+		Note:          req.Note,
+		ExpiresAt:     time.Now().Add(ttl),
+		CreatedBy:     objUserID,
+	}
+	if err := uc.reservationRepo.Create(reservation); err != nil {
+		return nil, err
+	}
+
+	return reservation, nil
+}
+
+func (uc *stockReservationUseCase) GetReservations(businessID string, filters Domain.StockReservationFilters) ([]Domain.StockReservation, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	reservations, err := uc.reservationRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if reservations == nil {
+		reservations = []Domain.StockReservation{}
+	}
+	return reservations, nil
+}
+
+func (uc *stockReservationUseCase) GetReservation(ctx context.Context, id, businessID string) (*Domain.StockReservation, error) {
+	reservation, err := uc.reservationRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if reservation == nil {
+		return nil, Domain.NotFoundError("reservation not found")
+	}
+	return reservation, nil
+}
+
+func (uc *stockReservationUseCase) ReleaseReservation(ctx context.Context, id, businessID, userID string, req Domain.ReleaseStockReservationRequest) (*Domain.StockReservation, error) {
+	reservation, err := uc.GetReservation(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if reservation.Status != Domain.ReservationActive {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("reservation is already %s", reservation.Status))
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	reservation.Status = Domain.ReservationReleased
+	reservation.ClosedBy = &objUserID
+	reservation.ClosedAt = &now
+	reservation.CloseReason = req.Reason
+
+	closed, err := uc.reservationRepo.Close(reservation)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "reservation was closed by someone else; reload it")
+	}
+
+	return reservation, nil
+}
+
+func (uc *stockReservationUseCase) GetAvailability(ctx context.Context, productID, businessID string) (*Domain.ProductAvailability, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+
+	reserved, err := uc.reservationRepo.ReservedQuantities(businessID, []primitive.ObjectID{product.ID}, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := productAvailability(product, reserved[product.ID])
+	return &availability, nil
+}
+
+func (uc *stockReservationUseCase) GetBranchAvailability(userID, sku string) ([]Domain.BranchAvailability, error) {
+	if sku == "" {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "sku is required")
+	}
+
+	businesses, err := uc.businessRepo.FindByUserID(userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find businesses: %w", err)
+	}
+
+	branches := []Domain.BranchAvailability{}
+	for _, business := range businesses {
+		businessID := business.ID.Hex()
+		products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{SKU: &sku, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(products) == 0 {
+			continue
+		}
+		product := &products[0]
+
+		reserved, err := uc.reservationRepo.ReservedQuantities(businessID, []primitive.ObjectID{product.ID}, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		branches = append(branches, Domain.BranchAvailability{
+			BusinessID:          businessID,
+			Name:                business.Name,
+			City:                business.City,
+			ProductAvailability: productAvailability(product, reserved[product.ID]),
+		})
+	}
+
+	return branches, nil
+}
+
+func (uc *stockReservationUseCase) ExpireDue() error {
+	_, err := uc.reservationRepo.ExpireDue(time.Now())
+	return err
+}
+
+// availableStock is the product's stock less what its holding reservations, but
+// for except, keep for orders
+func availableStock(reservationRepo Domain.StockReservationRepository, product *Domain.Product, except *primitive.ObjectID) (float64, error) {
+	reserved, err := reservationRepo.ReservedQuantities(product.BusinessID.Hex(), []primitive.ObjectID{product.ID}, except)
+	if err != nil {
+		return 0, err
+	}
+	return product.Stock - reserved[product.ID], nil
+}
+
+func productAvailability(product *Domain.Product, reserved float64) Domain.ProductAvailability {
+	return Domain.ProductAvailability{
+		ProductID: product.ID.Hex(),
+		Name:      product.Name,
+		SKU:       product.SKU,
+		Stock:     product.Stock,
+		Reserved:  reserved,
+		Available: product.Stock - reserved,
+	}
+}
@@ -0,0 +1,499 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StockTransferUseCase interface {
+	// RequestTransfer asks another of the owner's branches for stock
+	RequestTransfer(ctx context.Context, businessID, userID string, req Domain.CreateStockTransferRequest) (*Domain.StockTransfer, error)
+	GetTransfers(businessID string, filters Domain.StockTransferFilters) ([]Domain.StockTransfer, error)
+	// GetTransfer finds a transfer the branch asked for or was asked for
+	GetTransfer(ctx context.Context, id, businessID string) (*Domain.StockTransfer, error)
+	// ApproveTransfer approves what the supplying branch can spare, matching each
+	// line to its product by SKU
+	ApproveTransfer(ctx context.Context, id, businessID, userID string, req Domain.ApproveStockTransferRequest) (*Domain.StockTransfer, error)
+	RejectTransfer(ctx context.Context, id, businessID, userID string, req Domain.CloseStockTransferRequest) (*Domain.StockTransfer, error)
+	// ShipTransfer takes the approved stock out of the supplying branch; it is in
+	// transit until received
+	ShipTransfer(ctx context.Context, id, businessID, userID string) (*Domain.StockTransfer, error)
+	// ReceiveTransfer adds what arrived to the requesting branch's stock, recording
+	// any difference from what was shipped
+	ReceiveTransfer(ctx context.Context, id, businessID, userID string, req Domain.ReceiveStockTransferRequest) (*Domain.StockTransfer, error)
+	// CancelTransfer withdraws a request not yet shipped
+	CancelTransfer(ctx context.Context, id, businessID, userID string, req Domain.CloseStockTransferRequest) (*Domain.StockTransfer, error)
+}
+
+type stockTransferUseCase struct {
+	transferRepo    Domain.StockTransferRepository
+	inventoryRepo   Domain.ProductRepository
+	reservationRepo Domain.StockReservationRepository
+	businessRepo    Domain.BusinessRepository
+}
+
+func NewStockTransferUseCase(
+	transferRepo Domain.StockTransferRepository,
+	inventoryRepo Domain.ProductRepository,
+	reservationRepo Domain.StockReservationRepository,
+	businessRepo Domain.BusinessRepository,
+) StockTransferUseCase {
+	return &stockTransferUseCase{
+		transferRepo:    transferRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		businessRepo:    businessRepo,
+	}
+}
+
+// branch finds one of the businesses a transfer is between
+func (uc *stockTransferUseCase) branch(id string) (*Domain.Business, error) {
+	business, err := uc.businessRepo.FindByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+	return business, nil
+}
+
+// transferable reports why stock of the product cannot move between branches, if it cannot
+func transferable(product *Domain.Product) error {
+	if product.Service {
+		return Domain.ValidationError(fmt.Sprintf("%s is a service and keeps no stock", product.Name))
+	}
+	if product.Consignment != nil {
+		return Domain.ValidationError(fmt.Sprintf("%s is the consignor's stock, not the shop's to transfer", product.Name))
+	}
+	return nil
+}
+
+func (uc *stockTransferUseCase) RequestTransfer(ctx context.Context, businessID, userID string, req Domain.CreateStockTransferRequest) (*Domain.StockTransfer, error) {
+	if req.FromBusinessID == businessID {
+		return nil, Domain.ValidationError("a branch cannot transfer stock to itself")
+	}
+
+	to, err := uc.branch(businessID)
+	if err != nil {
+		return nil, err
+	}
+	from, err := uc.branch(req.FromBusinessID)
+	if err != nil {
+		return nil, err
+	}
+	if from.UserID != to.UserID {
+		return nil, Domain.AccessDeniedError("stock can only be transferred between branches of one owner")
+	}
+	if from.Status == Domain.BusinessStatusClosed {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("%s is closed", from.Name))
+	}
+	if from.Currency != to.Currency {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("cannot transfer between branches in different currencies (%s and %s)", from.Currency, to.Currency))
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	lines := make([]Domain.StockTransferLine, 0, len(req.Lines))
+	seen := make(map[string]bool)
+	for _, input := range req.Lines {
+		if seen[input.ProductID] {
+			return nil, Domain.ValidationError(fmt.Sprintf("product %s is listed twice", input.ProductID))
+		}
+		seen[input.ProductID] = true
+
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, input.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if product == nil || product.BusinessID != to.ID || product.DeletedAt != nil {
+			return nil, Domain.NotFoundError(fmt.Sprintf("product %s not found", input.ProductID))
+		}
+		if err := transferable(product); err != nil {
+			return nil, err
+		}
+		if product.SKU == "" {
+			return nil, Domain.ValidationError(fmt.Sprintf("%s has no SKU; branches match products by SKU", product.Name))
+		}
+
+		lines = append(lines, Domain.StockTransferLine{
+			ID:        primitive.NewObjectID(),
+			ProductID: product.ID,
+			SKU:       product.SKU,
+			Name:      product.Name,
+			Requested: input.Quantity,
+		})
+	}
+
+	count, err := uc.transferRepo.CountRequested(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	transfer := &Domain.StockTransfer{
+		Number:         fmt.Sprintf("TR-%05d", count+1),
+		FromBusinessID: from.ID,
+		ToBusinessID:   to.ID,
+		Status:         Domain.StockTransferRequested,
+		Lines:          lines,
+		Notes:          req.Notes,
+		RequestedBy:    objUserID,
+	}
+	if err := uc.transferRepo.Create(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (uc *stockTransferUseCase) GetTransfers(businessID string, filters Domain.StockTransferFilters) ([]Domain.StockTransfer, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	transfers, err := uc.transferRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if transfers == nil {
+		transfers = []Domain.StockTransfer{}
+	}
+	return transfers, nil
+}
+
+func (uc *stockTransferUseCase) GetTransfer(ctx context.Context, id, businessID string) (*Domain.StockTransfer, error) {
+	transfer, err := uc.transferRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if transfer == nil {
+		return nil, Domain.NotFoundError("stock transfer not found")
+	}
+	return transfer, nil
+}
+
+// transferAt finds the transfer for a step only one side takes, at the status it is taken from
+func (uc *stockTransferUseCase) transferAt(ctx context.Context, id, businessID string, supplying bool, statuses ...Domain.StockTransferStatus) (*Domain.StockTransfer, error) {
+	transfer, err := uc.GetTransfer(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if supplying && transfer.FromBusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("only the supplying branch can do this")
+	}
+	if !supplying && transfer.ToBusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("only the requesting branch can do this")
+	}
+
+	for _, status := range statuses {
+		if transfer.Status == status {
+			return transfer, nil
+		}
+	}
+	return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("stock transfer is %s", transfer.Status))
+}
+
+// lineQuantities indexes the quantities given by line, checking each is for a line of the transfer
+func lineQuantities(transfer *Domain.StockTransfer, lines []Domain.StockTransferLineQuantity) (map[primitive.ObjectID]Domain.StockTransferLineQuantity, error) {
+	byLine := make(map[primitive.ObjectID]Domain.StockTransferLineQuantity, len(lines))
+	for _, line := range lines {
+		found := false
+		for _, existing := range transfer.Lines {
+			if existing.ID.Hex() == line.LineID {
+				byLine[existing.ID] = line
+				found = true
+				break
+			}
+		}
+		if !found {
+			return nil, Domain.NotFoundError(fmt.Sprintf("line %s not found", line.LineID))
+		}
+	}
+	return byLine, nil
+}
+
+func (uc *stockTransferUseCase) ApproveTransfer(ctx context.Context, id, businessID, userID string, req Domain.ApproveStockTransferRequest) (*Domain.StockTransfer, error) {
+	transfer, err := uc.transferAt(ctx, id, businessID, true, Domain.StockTransferRequested)
+	if err != nil {
+		return nil, err
+	}
+
+	byLine, err := lineQuantities(transfer, req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var approved float64
+	for i := range transfer.Lines {
+		line := &transfer.Lines[i]
+		line.Approved = line.Requested
+		if given, ok := byLine[line.ID]; ok {
+			if given.Quantity > line.Requested {
+				return nil, Domain.ValidationError(fmt.Sprintf("%s: cannot approve more than the %.2f requested", line.Name, line.Requested))
+			}
+			line.Approved = given.Quantity
+		}
+		if line.Approved == 0 {
+			continue
+		}
+
+		sku := line.SKU
+		products, err := uc.inventoryRepo.FindByBusinessID(businessID, Domain.ProductFilters{SKU: &sku, Limit: 1})
+		if err != nil {
+			return nil, err
+		}
+		if len(products) == 0 {
+			return nil, Domain.NotFoundError(fmt.Sprintf("no product here has SKU %s (%s); approve none of it", line.SKU, line.Name))
+		}
+		product := &products[0]
+		if err := transferable(product); err != nil {
+			return nil, err
+		}
+
+		// Leave what orders hold
+		available, err := availableStock(uc.reservationRepo, product, nil)
+		if err != nil {
+			return nil, err
+		}
+		if available < line.Approved {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("insufficient %s. Available: %.2f, Approved: %.2f",
+				product.Name, available, line.Approved))
+		}
+
+		line.FromProductID = &product.ID
+		approved += line.Approved
+	}
+	if approved == 0 {
+		return nil, Domain.ValidationError("nothing approved; reject the transfer instead")
+	}
+
+	now := time.Now()
+	transfer.Status = Domain.StockTransferApproved
+	transfer.ApprovedBy = &objUserID
+	transfer.ApprovedAt = &now
+	if err := uc.transferRepo.Update(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (uc *stockTransferUseCase) RejectTransfer(ctx context.Context, id, businessID, userID string, req Domain.CloseStockTransferRequest) (*Domain.StockTransfer, error) {
+	transfer, err := uc.transferAt(ctx, id, businessID, true, Domain.StockTransferRequested, Domain.StockTransferApproved)
+	if err != nil {
+		return nil, err
+	}
+	return uc.close(transfer, Domain.StockTransferRejected, userID, req.Reason)
+}
+
+func (uc *stockTransferUseCase) CancelTransfer(ctx context.Context, id, businessID, userID string, req Domain.CloseStockTransferRequest) (*Domain.StockTransfer, error) {
+	transfer, err := uc.transferAt(ctx, id, businessID, false, Domain.StockTransferRequested, Domain.StockTransferApproved)
+	if err != nil {
+		return nil, err
+	}
+	return uc.close(transfer, Domain.StockTransferCancelled, userID, req.Reason)
+}
+
+func (uc *stockTransferUseCase) close(transfer *Domain.StockTransfer, status Domain.StockTransferStatus, userID, reason string) (*Domain.StockTransfer, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	transfer.Status = status
+	transfer.Reason = reason
+	transfer.ClosedBy = &objUserID
+	if err := uc.transferRepo.Update(transfer); err != nil {
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+func (uc *stockTransferUseCase) ShipTransfer(ctx context.Context, id, businessID, userID string) (*Domain.StockTransfer, error) {
+	transfer, err := uc.transferAt(ctx, id, businessID, true, Domain.StockTransferApproved)
+	if err != nil {
+		return nil, err
+	}
+
+	to, err := uc.branch(transfer.ToBusinessID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	referenceID := transfer.ID.Hex()
+	var shipped []Domain.StockTransferLine
+	for i := range transfer.Lines {
+		line := &transfer.Lines[i]
+		if line.Approved == 0 || line.FromProductID == nil {
+			continue
+		}
+
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, line.FromProductID.Hex())
+		if err != nil {
+			uc.unship(transfer, shipped, userID)
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if product == nil {
+			uc.unship(transfer, shipped, userID)
+			return nil, Domain.NotFoundError(fmt.Sprintf("%s is no longer in stock here", line.Name))
+		}
+
+		if err := uc.inventoryRepo.AdjustStock(
+			product.ID.Hex(),
+			line.Approved,
+			Domain.MovementTypeTransferOut,
+			fmt.Sprintf("Shipped to %s on transfer %s", to.Name, transfer.Number),
+			&referenceID,
+			"stock_transfer",
+			userID,
+		); err != nil {
+			uc.unship(transfer, shipped, userID)
+			return nil, err
+		}
+
+		line.Shipped = line.Approved
+		line.UnitCost = product.CostPrice
+		shipped = append(shipped, *line)
+	}
+
+	now := time.Now()
+	transfer.Status = Domain.StockTransferInTransit
+	transfer.ShippedBy = &objUserID
+	transfer.ShippedAt = &now
+	if err := uc.transferRepo.Update(transfer); err != nil {
+		uc.unship(transfer, shipped, userID)
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// unship puts stock taken out for a shipment that did not go back into stock
+func (uc *stockTransferUseCase) unship(transfer *Domain.StockTransfer, lines []Domain.StockTransferLine, userID string) {
+	referenceID := transfer.ID.Hex()
+	for _, line := range lines {
+		if err := uc.inventoryRepo.AdjustStock(
+			line.FromProductID.Hex(),
+			line.Shipped,
+			Domain.MovementTypeReturn,
+			"Transfer "+transfer.Number+" not shipped - restoring stock",
+			&referenceID,
+			"stock_transfer",
+			userID,
+		); err != nil {
+			Infrastructure.Logger.Warn("failed to restore stock for line of transfer", "line_id", line.ID.Hex(), "transfer_id", referenceID, "error", err)
+		}
+	}
+}
+
+func (uc *stockTransferUseCase) ReceiveTransfer(ctx context.Context, id, businessID, userID string, req Domain.ReceiveStockTransferRequest) (*Domain.StockTransfer, error) {
+	transfer, err := uc.transferAt(ctx, id, businessID, false, Domain.StockTransferInTransit)
+	if err != nil {
+		return nil, err
+	}
+
+	byLine, err := lineQuantities(transfer, req.Lines)
+	if err != nil {
+		return nil, err
+	}
+
+	from, err := uc.branch(transfer.FromBusinessID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	for i := range transfer.Lines {
+		line := &transfer.Lines[i]
+		line.Received = line.Shipped
+		if given, ok := byLine[line.ID]; ok {
+			if line.Shipped == 0 && given.Quantity > 0 {
+				return nil, Domain.ValidationError(fmt.Sprintf("%s: none was shipped", line.Name))
+			}
+			line.Received = given.Quantity
+			line.Note = given.Note
+		}
+		line.Discrepancy = line.Received - line.Shipped
+	}
+
+	referenceID := transfer.ID.Hex()
+	var received []Domain.StockTransferLine
+	for _, line := range transfer.Lines {
+		if line.Received == 0 {
+			continue
+		}
+
+		reason := fmt.Sprintf("Received from %s on transfer %s", from.Name, transfer.Number)
+		if line.Discrepancy != 0 {
+			reason += fmt.Sprintf(" (%.2f shipped)", line.Shipped)
+		}
+		if err := uc.inventoryRepo.AddStockAtCost(
+			line.ProductID.Hex(),
+			line.Received,
+			line.UnitCost,
+			Domain.MovementTypeTransferIn,
+			reason,
+			&referenceID,
+			"stock_transfer",
+			userID,
+		); err != nil {
+			uc.unreceive(transfer, received, userID)
+			return nil, err
+		}
+		received = append(received, line)
+	}
+
+	now := time.Now()
+	transfer.Status = Domain.StockTransferReceived
+	transfer.ReceivedBy = &objUserID
+	transfer.ReceivedAt = &now
+	if err := uc.transferRepo.Update(transfer); err != nil {
+		uc.unreceive(transfer, received, userID)
+		return nil, err
+	}
+
+	return transfer, nil
+}
+
+// unreceive takes stock added for a receipt that was not recorded back out
+func (uc *stockTransferUseCase) unreceive(transfer *Domain.StockTransfer, lines []Domain.StockTransferLine, userID string) {
+	referenceID := transfer.ID.Hex()
+	for _, line := range lines {
+		if err := uc.inventoryRepo.AdjustStock(
+			line.ProductID.Hex(),
+			-line.Received,
+			Domain.MovementTypeAdjust,
+			"Transfer "+transfer.Number+" not received - reversing stock",
+			&referenceID,
+			"stock_transfer",
+			userID,
+		); err != nil {
+			Infrastructure.Logger.Warn("failed to reverse stock for line of transfer", "line_id", line.ID.Hex(), "transfer_id", referenceID, "error", err)
+		}
+	}
+}
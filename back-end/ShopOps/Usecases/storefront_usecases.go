@@ -0,0 +1,690 @@
+package Usecases
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type StorefrontUseCase interface {
+	// Connect links an online store after checking its credentials work
+	Connect(businessID, userID string, req Domain.ConnectStorefrontRequest) (*Domain.StorefrontConnection, error)
+	GetConnections(businessID string) ([]Domain.StorefrontConnection, error)
+	GetConnection(id, businessID string) (*Domain.StorefrontConnection, error)
+	UpdateConnection(id, businessID string, req Domain.UpdateStorefrontRequest) (*Domain.StorefrontConnection, error)
+	DeleteConnection(id, businessID string) error
+	// SyncNow has the store synced within a minute rather than at its next turn
+	SyncNow(id, businessID string) (*Domain.StorefrontConnection, error)
+
+	GetMappings(id, businessID string) ([]Domain.StorefrontMapping, error)
+	// SetMapping ties a product to a listing the store already has, for listings
+	// whose SKU does not match
+	SetMapping(ctx context.Context, id, businessID string, req Domain.SetStorefrontMappingRequest) (*Domain.StorefrontMapping, error)
+	DeleteMapping(id, businessID, productID string) error
+
+	GetOrders(id, businessID string, filters Domain.StorefrontOrderFilters) ([]Domain.StorefrontOrder, error)
+	// ResolveOrder retries an order's waiting lines or dismisses them
+	ResolveOrder(id, orderID, businessID, userID string, req Domain.ResolveStorefrontOrderRequest) (*Domain.StorefrontOrder, error)
+
+	// SyncDue syncs every store whose turn has come
+	SyncDue() error
+}
+
+type storefrontUseCase struct {
+	storefrontRepo  Domain.StorefrontRepository
+	inventoryRepo   Domain.ProductRepository
+	reservationRepo Domain.StockReservationRepository
+	salesUC         SalesUseCase
+	connectors      Infrastructure.StorefrontConnectors
+}
+
+func NewStorefrontUseCase(
+	storefrontRepo Domain.StorefrontRepository,
+	inventoryRepo Domain.ProductRepository,
+	reservationRepo Domain.StockReservationRepository,
+	salesUC SalesUseCase,
+	connectors Infrastructure.StorefrontConnectors,
+) StorefrontUseCase {
+	return &storefrontUseCase{
+		storefrontRepo:  storefrontRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		salesUC:         salesUC,
+		connectors:      connectors,
+	}
+}
+
+// storefrontSyncTimeout bounds one store's sync, so a slow store does not hold
+// up the others
+const storefrontSyncTimeout = 5 * time.Minute
+
+func (uc *storefrontUseCase) Connect(businessID, userID string, req Domain.ConnectStorefrontRequest) (*Domain.StorefrontConnection, error) {
+	connector, ok := uc.connectors[req.Platform]
+	if !ok {
+		return nil, Domain.ValidationError("platform must be woocommerce or shopify")
+	}
+
+	storeURL, err := connector.NormalizeStoreURL(req.StoreURL)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.storefrontRepo.FindConnections(businessID)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range existing {
+		if c.StoreURL == storeURL {
+			return nil, Domain.NewAppError(Domain.ErrCodeConflict, "this store is already connected")
+		}
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	now := time.Now()
+	connection := &Domain.StorefrontConnection{
+		BusinessID:  objBusinessID,
+		Platform:    req.Platform,
+		Name:        req.Name,
+		StoreURL:    storeURL,
+		APIKey:      req.APIKey,
+		APISecret:   req.APISecret,
+		LocationID:  req.LocationID,
+		PushCatalog: req.PushCatalog,
+		PushStock:   req.PushStock == nil || *req.PushStock,
+		PullOrders:  req.PullOrders == nil || *req.PullOrders,
+		StockBuffer: req.StockBuffer,
+		Active:      true,
+		NextSyncAt:  now,
+		// Orders placed before the store was connected were dealt with already
+		OrdersSince: now,
+		CreatedBy:   objUserID,
+	}
+
+	if err := uc.verify(connector, connection); err != nil {
+		return nil, err
+	}
+
+	if err := uc.storefrontRepo.CreateConnection(connection); err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
+
+func (uc *storefrontUseCase) verify(connector Infrastructure.StorefrontConnector, connection *Domain.StorefrontConnection) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	if err := connector.Verify(ctx, connection); err != nil {
+		var appErr *Domain.AppError
+		if errors.As(err, &appErr) {
+			return err
+		}
+		return Domain.ValidationError(fmt.Sprintf("could not reach the store with these details: %v", err))
+	}
+	return nil
+}
+
+func (uc *storefrontUseCase) GetConnections(businessID string) ([]Domain.StorefrontConnection, error) {
+	return uc.storefrontRepo.FindConnections(businessID)
+}
+
+func (uc *storefrontUseCase) GetConnection(id, businessID string) (*Domain.StorefrontConnection, error) {
+	connection, err := uc.storefrontRepo.FindConnectionByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if connection == nil || connection.BusinessID.Hex() != businessID {
+		return nil, Domain.NotFoundError("storefront connection not found")
+	}
+	return connection, nil
+}
+
+func (uc *storefrontUseCase) UpdateConnection(id, businessID string, req Domain.UpdateStorefrontRequest) (*Domain.StorefrontConnection, error) {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	reverify := false
+	if req.Name != nil {
+		connection.Name = *req.Name
+	}
+	if req.APIKey != nil {
+		connection.APIKey = *req.APIKey
+		reverify = true
+	}
+	if req.APISecret != nil {
+		connection.APISecret = *req.APISecret
+		reverify = true
+	}
+	if req.LocationID != nil {
+		connection.LocationID = *req.LocationID
+		reverify = true
+	}
+	if req.PushCatalog != nil {
+		connection.PushCatalog = *req.PushCatalog
+	}
+	if req.PushStock != nil {
+		connection.PushStock = *req.PushStock
+	}
+	if req.PullOrders != nil {
+		connection.PullOrders = *req.PullOrders
+	}
+	if req.StockBuffer != nil {
+		connection.StockBuffer = *req.StockBuffer
+	}
+	resumed := false
+	if req.Active != nil {
+		resumed = *req.Active && !connection.Active
+		connection.Active = *req.Active
+	}
+
+	if reverify {
+		connector, ok := uc.connectors[connection.Platform]
+		if !ok {
+			return nil, fmt.Errorf("no connector for %s", connection.Platform)
+		}
+		if err := uc.verify(connector, connection); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := uc.storefrontRepo.UpdateConnection(connection); err != nil {
+		return nil, err
+	}
+
+	if resumed || reverify {
+		connection.NextSyncAt = time.Now()
+		if err := uc.storefrontRepo.ScheduleSync(connection.ID, connection.NextSyncAt); err != nil {
+			return nil, err
+		}
+	}
+	return connection, nil
+}
+
+func (uc *storefrontUseCase) DeleteConnection(id, businessID string) error {
+	if _, err := uc.GetConnection(id, businessID); err != nil {
+		return err
+	}
+	return uc.storefrontRepo.DeleteConnection(id)
+}
+
+func (uc *storefrontUseCase) SyncNow(id, businessID string) (*Domain.StorefrontConnection, error) {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if !connection.Active {
+		return nil, Domain.ValidationError("the store connection is paused; set it active first")
+	}
+
+	connection.NextSyncAt = time.Now()
+	if err := uc.storefrontRepo.ScheduleSync(connection.ID, connection.NextSyncAt); err != nil {
+		return nil, err
+	}
+	return connection, nil
+}
+
+func (uc *storefrontUseCase) GetMappings(id, businessID string) ([]Domain.StorefrontMapping, error) {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.storefrontRepo.FindMappings(connection.ID)
+}
+
+func (uc *storefrontUseCase) SetMapping(ctx context.Context, id, businessID string, req Domain.SetStorefrontMappingRequest) (*Domain.StorefrontMapping, error) {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, req.ProductID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil || product.BusinessID != connection.BusinessID {
+		return nil, Domain.NotFoundError("product not found")
+	}
+
+	connector, ok := uc.connectors[connection.Platform]
+	if !ok {
+		return nil, fmt.Errorf("no connector for %s", connection.Platform)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	listing, err := connector.GetListing(ctx, connection, req.RemoteProductID, req.RemoteVariantID)
+	if err != nil {
+		return nil, err
+	}
+	if listing == nil {
+		return nil, Domain.NotFoundError("the store has no such listing")
+	}
+
+	taken, err := uc.storefrontRepo.FindMappingByRemote(connection.ID, listing.ProductID, listing.VariantID)
+	if err != nil {
+		return nil, err
+	}
+	if taken != nil && taken.ProductID != product.ID {
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, "this listing is already mapped to another product")
+	}
+
+	mapping := &Domain.StorefrontMapping{
+		BusinessID:        connection.BusinessID,
+		ConnectionID:      connection.ID,
+		ProductID:         product.ID,
+		RemoteProductID:   listing.ProductID,
+		RemoteVariantID:   listing.VariantID,
+		RemoteInventoryID: listing.InventoryID,
+		LastPushedStock:   -1,
+	}
+	if err := uc.storefrontRepo.SaveMapping(mapping); err != nil {
+		return nil, err
+	}
+	return mapping, nil
+}
+
+func (uc *storefrontUseCase) DeleteMapping(id, businessID, productID string) error {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return err
+	}
+	return uc.storefrontRepo.DeleteMapping(connection.ID, productID)
+}
+
+func (uc *storefrontUseCase) GetOrders(id, businessID string, filters Domain.StorefrontOrderFilters) ([]Domain.StorefrontOrder, error) {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	return uc.storefrontRepo.FindOrders(connection.ID, filters)
+}
+
+func (uc *storefrontUseCase) ResolveOrder(id, orderID, businessID, userID string, req Domain.ResolveStorefrontOrderRequest) (*Domain.StorefrontOrder, error) {
+	connection, err := uc.GetConnection(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := uc.storefrontRepo.FindOrderByID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if order == nil || order.ConnectionID != connection.ID {
+		return nil, Domain.NotFoundError("storefront order not found")
+	}
+	// An order left importing was cut off mid-way, e.g. by a restart
+	if order.Status != Domain.StorefrontOrderConflict && order.Status != Domain.StorefrontOrderImporting {
+		return nil, Domain.ValidationError("the order has no lines waiting")
+	}
+
+	switch req.Action {
+	case Domain.StorefrontResolutionRetry:
+		uc.importLines(context.Background(), connection, order)
+	case Domain.StorefrontResolutionDismiss:
+		order.Status = Domain.StorefrontOrderDismissed
+	default:
+		return nil, Domain.ValidationError("action must be retry or dismiss")
+	}
+
+	if order.Status != Domain.StorefrontOrderConflict {
+		objUserID, err := primitive.ObjectIDFromHex(userID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid user ID: %w", err)
+		}
+		now := time.Now()
+		order.ResolvedBy = &objUserID
+		order.ResolvedAt = &now
+	}
+
+	if err := uc.storefrontRepo.UpdateOrder(order); err != nil {
+		return nil, err
+	}
+	return order, nil
+}
+
+func (uc *storefrontUseCase) SyncDue() error {
+	for {
+		// Claiming moves the store's next sync on, so each is synced once however
+		// many instances are running and this loop always ends
+		connection, err := uc.storefrontRepo.ClaimDueConnection(time.Now())
+		if err != nil {
+			return err
+		}
+		if connection == nil {
+			return nil
+		}
+		if err := uc.sync(connection); err != nil {
+			return err
+		}
+	}
+}
+
+// sync pulls the store's new orders first, so the stock pushed after counts them
+func (uc *storefrontUseCase) sync(connection *Domain.StorefrontConnection) error {
+	started := time.Now()
+	ordersSince := connection.OrdersSince
+	var stats Domain.StorefrontSyncStats
+	var syncErr error
+
+	connector, ok := uc.connectors[connection.Platform]
+	if !ok {
+		syncErr = fmt.Errorf("no connector for %s", connection.Platform)
+	} else {
+		ctx, cancel := context.WithTimeout(context.Background(), storefrontSyncTimeout)
+		defer cancel()
+
+		if connection.PullOrders {
+			if err := uc.pullOrders(ctx, connector, connection, &stats); err != nil {
+				syncErr = err
+			} else {
+				ordersSince = started
+			}
+		}
+		if connection.PushStock || connection.PushCatalog {
+			if err := uc.pushCatalog(ctx, connector, connection, &stats); err != nil && syncErr == nil {
+				syncErr = err
+			}
+		}
+	}
+
+	status, lastError := "success", ""
+	if syncErr != nil {
+		status, lastError = "failure", syncErr.Error()
+	}
+	Infrastructure.StorefrontSyncs.Inc(string(connection.Platform), status)
+
+	return uc.storefrontRepo.RecordSync(connection.ID, ordersSince, started, stats, lastError)
+}
+
+func (uc *storefrontUseCase) pullOrders(ctx context.Context, connector Infrastructure.StorefrontConnector, connection *Domain.StorefrontConnection, stats *Domain.StorefrontSyncStats) error {
+	orders, err := connector.ListOrders(ctx, connection, connection.OrdersSince.Add(-Domain.StorefrontOrderOverlap))
+	if err != nil {
+		return err
+	}
+
+	// First ordered, first served when stock runs short
+	sort.SliceStable(orders, func(i, j int) bool { return orders[i].OrderedAt.Before(orders[j].OrderedAt) })
+
+	for i := range orders {
+		order := &orders[i]
+		order.BusinessID = connection.BusinessID
+		order.ConnectionID = connection.ID
+		order.Status = Domain.StorefrontOrderImporting
+
+		created, err := uc.storefrontRepo.CreateOrder(order)
+		if err != nil {
+			return err
+		}
+		if !created {
+			continue
+		}
+
+		uc.importLines(ctx, connection, order)
+		if err := uc.storefrontRepo.UpdateOrder(order); err != nil {
+			return err
+		}
+
+		if order.Status == Domain.StorefrontOrderConflict {
+			stats.OrderConflicts++
+		} else {
+			stats.OrdersImported++
+		}
+	}
+	return nil
+}
+
+// importLines records a sale for each of the order's lines without one. Lines
+// whose product is unknown, or sold out in the shop since the store last heard
+// its stock, are left with the reason for the owner.
+func (uc *storefrontUseCase) importLines(ctx context.Context, connection *Domain.StorefrontConnection, order *Domain.StorefrontOrder) {
+	businessID := connection.BusinessID.Hex()
+	ctx = Infrastructure.WithBusiness(ctx, businessID)
+	order.Status = Domain.StorefrontOrderImported
+
+	for i := range order.Lines {
+		line := &order.Lines[i]
+		if line.SaleID != nil {
+			continue
+		}
+
+		line.Conflict = ""
+		product, err := uc.lineProduct(ctx, connection, line)
+		switch {
+		case err != nil:
+			line.Conflict = err.Error()
+		case product == nil:
+			line.Conflict = "no product is mapped to this listing or has its SKU"
+		case product.Stock < line.Quantity:
+			line.Conflict = fmt.Sprintf("only %s left in the shop; it sold in store after the online stock was last updated",
+				strconv.FormatFloat(product.Stock, 'f', -1, 64))
+		}
+		if line.Conflict != "" {
+			order.Status = Domain.StorefrontOrderConflict
+			continue
+		}
+
+		productID := product.ID.Hex()
+		sale, err := uc.salesUC.CreateSale(ctx, businessID, connection.CreatedBy.Hex(), Domain.CreateSaleRequest{
+			ProductID:     &productID,
+			CustomerName:  order.CustomerName,
+			CustomerPhone: order.CustomerPhone,
+			Quantity:      line.Quantity,
+			UnitPrice:     Domain.NewMoney(line.UnitPrice),
+			PaymentMethod: order.PaymentMethod,
+			Notes:         fmt.Sprintf("Online order %s (%s)", order.Number, connection.Name),
+			LocalID:       "storefront:" + order.ID.Hex() + ":" + line.RemoteLineID,
+			DeviceID:      "storefront:" + connection.ID.Hex(),
+		})
+		if err != nil {
+			line.Conflict = err.Error()
+			order.Status = Domain.StorefrontOrderConflict
+			continue
+		}
+
+		line.ProductID = &product.ID
+		line.SaleID = &sale.ID
+	}
+}
+
+// lineProduct finds the product an order line is for: by its mapping, or else by SKU
+func (uc *storefrontUseCase) lineProduct(ctx context.Context, connection *Domain.StorefrontConnection, line *Domain.StorefrontOrderLine) (*Domain.Product, error) {
+	mapping, err := uc.storefrontRepo.FindMappingByRemote(connection.ID, line.RemoteProductID, line.RemoteVariantID)
+	if err != nil {
+		return nil, err
+	}
+	if mapping != nil {
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, mapping.ProductID.Hex())
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if product != nil && product.DeletedAt == nil {
+			return product, nil
+		}
+	}
+
+	if line.SKU == "" {
+		return nil, nil
+	}
+	products, err := uc.inventoryRepo.FindByBusinessID(connection.BusinessID.Hex(), Domain.ProductFilters{SKU: &line.SKU, Limit: 1})
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if len(products) == 0 {
+		return nil, nil
+	}
+	return &products[0], nil
+}
+
+// pushCatalog brings mapped listings in line with the shop and maps, or lists,
+// products changed since the last sync. Failures for one product are counted
+// and the rest carry on; the first is returned.
+func (uc *storefrontUseCase) pushCatalog(ctx context.Context, connector Infrastructure.StorefrontConnector, connection *Domain.StorefrontConnection, stats *Domain.StorefrontSyncStats) error {
+	products, err := uc.inventoryRepo.FindByBusinessID(connection.BusinessID.Hex(), Domain.ProductFilters{})
+	if err != nil {
+		return fmt.Errorf("failed to find products: %w", err)
+	}
+	reserved, err := uc.reservationRepo.ReservedQuantities(connection.BusinessID.Hex(), nil, nil)
+	if err != nil {
+		return err
+	}
+	byID := make(map[primitive.ObjectID]*Domain.Product, len(products))
+	for i := range products {
+		products[i].Reserved = reserved[products[i].ID]
+		byID[products[i].ID] = &products[i]
+	}
+
+	mappings, err := uc.storefrontRepo.FindMappings(connection.ID)
+	if err != nil {
+		return err
+	}
+
+	var firstErr error
+	fail := func(product string, err error) {
+		stats.Errors++
+		if firstErr == nil {
+			firstErr = fmt.Errorf("%s: %w", product, err)
+		}
+	}
+	now := time.Now()
+
+	mapped := make(map[primitive.ObjectID]bool, len(mappings))
+	for i := range mappings {
+		m := &mappings[i]
+		mapped[m.ProductID] = true
+		product := byID[m.ProductID]
+		listing := Infrastructure.StorefrontListing{ProductID: m.RemoteProductID, VariantID: m.RemoteVariantID, InventoryID: m.RemoteInventoryID}
+		changed := false
+
+		if connection.PushCatalog && product != nil && (product.SellingPrice.Float64() != m.LastPushedPrice || product.Name != m.LastPushedName) {
+			if err := connector.UpdateListing(ctx, connection, listing, storefrontItem(product, 0)); err != nil {
+				fail(product.Name, err)
+			} else {
+				m.LastPushedPrice, m.LastPushedName = product.SellingPrice.Float64(), product.Name
+				stats.ProductsUpdated++
+				changed = true
+			}
+		}
+
+		// Products gone from the shop, paused or trashed, are sold out online
+		if available := storefrontStock(product, connection.StockBuffer); connection.PushStock && available != m.LastPushedStock {
+			if err := connector.SetStock(ctx, connection, listing, available); err != nil {
+				fail(m.RemoteProductID, err)
+			} else {
+				m.LastPushedStock = available
+				stats.StockUpdates++
+				changed = true
+			}
+		}
+
+		if changed {
+			m.LastPushedAt = &now
+			if err := uc.storefrontRepo.SaveMapping(m); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i := range products {
+		product := &products[i]
+		if mapped[product.ID] || product.SKU == "" || product.Status != Domain.ProductStatusActive {
+			continue
+		}
+		// Looking every product up on every sync would soon use up the store's rate
+		// limit; unchanged ones were looked up before
+		if connection.LastSyncAt != nil && !product.UpdatedAt.After(*connection.LastSyncAt) {
+			continue
+		}
+
+		listing, err := connector.FindBySKU(ctx, connection, product.SKU)
+		if err != nil {
+			fail(product.Name, err)
+			continue
+		}
+
+		m := &Domain.StorefrontMapping{
+			BusinessID:      connection.BusinessID,
+			ConnectionID:    connection.ID,
+			ProductID:       product.ID,
+			LastPushedStock: -1,
+		}
+		switch {
+		case listing != nil:
+			stats.ProductsMapped++
+		case connection.PushCatalog:
+			item := storefrontItem(product, storefrontStock(product, connection.StockBuffer))
+			listing, err = connector.CreateListing(ctx, connection, item)
+			if listing == nil {
+				fail(product.Name, err)
+				continue
+			}
+			if err != nil {
+				fail(product.Name, err)
+			} else {
+				m.LastPushedStock = item.Stock
+			}
+			m.LastPushedPrice, m.LastPushedName = product.SellingPrice.Float64(), product.Name
+			stats.ProductsListed++
+		default:
+			continue
+		}
+
+		m.RemoteProductID, m.RemoteVariantID, m.RemoteInventoryID = listing.ProductID, listing.VariantID, listing.InventoryID
+		if available := storefrontStock(product, connection.StockBuffer); connection.PushStock && m.LastPushedStock != available {
+			if err := connector.SetStock(ctx, connection, *listing, available); err != nil {
+				fail(product.Name, err)
+			} else {
+				m.LastPushedStock = available
+				stats.StockUpdates++
+			}
+		}
+		m.LastPushedAt = &now
+		if err := uc.storefrontRepo.SaveMapping(m); err != nil {
+			return err
+		}
+	}
+
+	return firstErr
+}
+
+func storefrontItem(product *Domain.Product, stock int) Infrastructure.StorefrontItem {
+	return Infrastructure.StorefrontItem{
+		Name:        product.Name,
+		Description: product.Description,
+		SKU:         product.SKU,
+		Barcode:     product.Barcode,
+		Price:       product.SellingPrice.Float64(),
+		Stock:       stock,
+	}
+}
+
+// storefrontStock is what the store may sell: whole units not held for orders,
+// less the buffer
+func storefrontStock(product *Domain.Product, buffer float64) int {
+	if product == nil || product.DeletedAt != nil || product.Status != Domain.ProductStatusActive {
+		return 0
+	}
+	available := math.Floor(product.Stock - product.Reserved - buffer)
+	if available < 0 {
+		return 0
+	}
+	return int(available)
+}
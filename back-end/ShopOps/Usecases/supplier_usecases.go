@@ -0,0 +1,920 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SupplierUseCase interface {
+	CreateSupplier(businessID, userID string, req Domain.CreateSupplierRequest) (*Domain.Supplier, error)
+	GetSupplierByID(ctx context.Context, id, businessID string) (*Domain.Supplier, error)
+	GetSuppliers(businessID string, status *Domain.SupplierStatus, search *string) ([]Domain.Supplier, error)
+	UpdateSupplier(ctx context.Context, id, businessID string, req Domain.UpdateSupplierRequest) (*Domain.Supplier, error)
+	DeleteSupplier(ctx context.Context, id, businessID string) error
+
+	CreatePurchaseOrder(ctx context.Context, businessID, userID string, req Domain.CreatePurchaseOrderRequest) (*Domain.PurchaseOrder, error)
+	GetPurchaseOrderByID(ctx context.Context, id, businessID string) (*Domain.PurchaseOrder, error)
+	GetPurchaseOrders(businessID string, filters Domain.PurchaseOrderFilters) ([]Domain.PurchaseOrder, error)
+	SubmitPurchaseOrder(ctx context.Context, id, businessID string) error
+	ReceivePurchaseOrder(ctx context.Context, id, businessID, userID string, req Domain.ReceivePurchaseOrderRequest) (*Domain.PurchaseOrder, error)
+	CancelPurchaseOrder(ctx context.Context, id, businessID string) error
+
+	RecordInvoice(ctx context.Context, supplierID, businessID, userID string, req Domain.RecordSupplierInvoiceRequest) (*Domain.PayableEntry, error)
+	RecordPayment(ctx context.Context, supplierID, businessID, userID string, req Domain.RecordSupplierPaymentRequest) (*Domain.PayableEntry, error)
+	GetLedger(ctx context.Context, supplierID, businessID string, limit int) ([]Domain.PayableEntry, error)
+	GetAgingReport(businessID string) (*Domain.PayablesAgingReport, error)
+
+	// GetProductSupplierPrices compares what each supplier last charged for the product
+	GetProductSupplierPrices(ctx context.Context, productID, businessID string) (*Domain.ProductSupplierPrices, error)
+	// GetPurchaseSuggestions recommends who to buy each product from, how much and
+	// by when, from the suppliers' last prices and lead times and the sales forecast
+	GetPurchaseSuggestions(ctx context.Context, businessID string, opts Domain.PurchaseSuggestionOptions) (*Domain.PurchaseSuggestionReport, error)
+}
+
+type supplierUseCase struct {
+	supplierRepo      Domain.SupplierRepository
+	purchaseOrderRepo Domain.PurchaseOrderRepository
+	businessRepo      Domain.BusinessRepository
+	inventoryRepo     Domain.ProductRepository
+	customFieldRepo   Domain.CustomFieldRepository
+	supplierPriceRepo Domain.SupplierPriceRepository
+	forecastUC        ForecastUseCase
+}
+
+const (
+	// Without product IDs, suggestions cover products to order within this many days
+	purchaseSuggestionHorizonDays = 14
+	// A suggested order covers the lead time and this many days after delivery
+	purchaseCycleDays = 14
+)
+
+func NewSupplierUseCase(
+	supplierRepo Domain.SupplierRepository,
+	purchaseOrderRepo Domain.PurchaseOrderRepository,
+	businessRepo Domain.BusinessRepository,
+	inventoryRepo Domain.ProductRepository,
+	customFieldRepo Domain.CustomFieldRepository,
+	supplierPriceRepo Domain.SupplierPriceRepository,
+	forecastUC ForecastUseCase,
+) SupplierUseCase {
+	return &supplierUseCase{
+		supplierRepo:      supplierRepo,
+		purchaseOrderRepo: purchaseOrderRepo,
+		businessRepo:      businessRepo,
+		inventoryRepo:     inventoryRepo,
+		customFieldRepo:   customFieldRepo,
+		supplierPriceRepo: supplierPriceRepo,
+		forecastUC:        forecastUC,
+	}
+}
+
+func (uc *supplierUseCase) CreateSupplier(businessID, userID string, req Domain.CreateSupplierRequest) (*Domain.Supplier, error) {
+	// Validate business exists
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, fmt.Errorf("supplier name is required")
+	}
+
+	if req.PaymentTermsDays < 0 {
+		return nil, fmt.Errorf("payment terms cannot be negative")
+	}
+	if req.PaymentTermsDays == 0 {
+		req.PaymentTermsDays = Domain.DefaultSupplierPaymentTermsDays
+	}
+	if req.LeadTimeDays < 0 {
+		return nil, fmt.Errorf("lead time cannot be negative")
+	}
+
+	customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntitySupplier, req.CustomFields, nil, true)
+	if err != nil {
+		return nil, err
+	}
+
+	objBusinessID, err := Domain.PrimitiveObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	supplier := &Domain.Supplier{
+		BusinessID:       objBusinessID,
+		Name:             name,
+		ContactName:      req.ContactName,
+		Phone:            normalizePhone(req.Phone, business.Country),
+		Email:            strings.ToLower(strings.TrimSpace(req.Email)),
+		Address:          req.Address,
+		TIN:              req.TIN,
+		PaymentTermsDays: req.PaymentTermsDays,
+		LeadTimeDays:     req.LeadTimeDays,
+		Notes:            req.Notes,
+		CustomFields:     customFields,
+		CreatedBy:        objUserID,
+	}
+
+	if err := uc.supplierRepo.Create(supplier); err != nil {
+		return nil, fmt.Errorf("failed to create supplier: %w", err)
+	}
+
+	return supplier, nil
+}
+
+func (uc *supplierUseCase) GetSupplierByID(ctx context.Context, id, businessID string) (*Domain.Supplier, error) {
+	supplier, err := uc.supplierRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supplier: %w", err)
+	}
+	if supplier == nil || supplier.DeletedAt != nil {
+		return nil, Domain.NotFoundError("supplier not found")
+	}
+
+	return supplier, nil
+}
+
+func (uc *supplierUseCase) GetSuppliers(businessID string, status *Domain.SupplierStatus, search *string) ([]Domain.Supplier, error) {
+	return uc.supplierRepo.FindByBusinessID(businessID, status, search)
+}
+
+func (uc *supplierUseCase) UpdateSupplier(ctx context.Context, id, businessID string, req Domain.UpdateSupplierRequest) (*Domain.Supplier, error) {
+	supplier, err := uc.GetSupplierByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Version != nil && *req.Version != supplier.Version {
+		return nil, Domain.StaleVersionError("supplier")
+	}
+
+	if req.Name != nil {
+		name := strings.TrimSpace(*req.Name)
+		if name == "" {
+			return nil, fmt.Errorf("supplier name is required")
+		}
+		supplier.Name = name
+	}
+	if req.ContactName != nil {
+		supplier.ContactName = *req.ContactName
+	}
+	if req.Phone != nil {
+		var country string
+		if business, err := uc.businessRepo.FindByID(businessID); err == nil && business != nil {
+			country = business.Country
+		}
+		supplier.Phone = normalizePhone(*req.Phone, country)
+	}
+	if req.Email != nil {
+		supplier.Email = strings.ToLower(strings.TrimSpace(*req.Email))
+	}
+	if req.Address != nil {
+		supplier.Address = *req.Address
+	}
+	if req.TIN != nil {
+		supplier.TIN = *req.TIN
+	}
+	if req.PaymentTermsDays != nil {
+		if *req.PaymentTermsDays < 0 {
+			return nil, fmt.Errorf("payment terms cannot be negative")
+		}
+		supplier.PaymentTermsDays = *req.PaymentTermsDays
+	}
+	if req.LeadTimeDays != nil {
+		if *req.LeadTimeDays < 0 {
+			return nil, fmt.Errorf("lead time cannot be negative")
+		}
+		supplier.LeadTimeDays = *req.LeadTimeDays
+	}
+	if req.Notes != nil {
+		supplier.Notes = *req.Notes
+	}
+	if req.Status != nil {
+		if *req.Status != Domain.SupplierStatusActive && *req.Status != Domain.SupplierStatusInactive {
+			return nil, fmt.Errorf("invalid supplier status: %s", *req.Status)
+		}
+		supplier.Status = *req.Status
+	}
+	if req.CustomFields != nil {
+		customFields, err := checkCustomFields(uc.customFieldRepo, businessID, Domain.CustomFieldEntitySupplier, req.CustomFields, supplier.CustomFields, false)
+		if err != nil {
+			return nil, err
+		}
+		supplier.CustomFields = customFields
+	}
+
+	if err := uc.supplierRepo.Update(supplier); err != nil {
+		return nil, fmt.Errorf("failed to update supplier: %w", err)
+	}
+
+	return supplier, nil
+}
+
+func (uc *supplierUseCase) DeleteSupplier(ctx context.Context, id, businessID string) error {
+	supplier, err := uc.GetSupplierByID(ctx, id, businessID)
+	if err != nil {
+		return err
+	}
+
+	// The payables ledger must be settled first, or the debt would vanish from the aging report
+	if supplier.Balance != 0 {
+		return fmt.Errorf("cannot delete supplier with an outstanding balance. Current balance: %s", supplier.Balance)
+	}
+
+	return uc.supplierRepo.Delete(id)
+}
+
+func (uc *supplierUseCase) CreatePurchaseOrder(ctx context.Context, businessID, userID string, req Domain.CreatePurchaseOrderRequest) (*Domain.PurchaseOrder, error) {
+	supplier, err := uc.GetSupplierByID(ctx, req.SupplierID, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if supplier.Status != Domain.SupplierStatusActive {
+		return nil, fmt.Errorf("supplier is inactive")
+	}
+
+	if len(req.Items) == 0 {
+		return nil, fmt.Errorf("purchase order must have at least one item")
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	order := &Domain.PurchaseOrder{
+		BusinessID: supplier.BusinessID,
+		SupplierID: supplier.ID,
+		Status:     Domain.PurchaseOrderStatusDraft,
+		ExpectedAt: req.ExpectedAt,
+		Notes:      req.Notes,
+		CreatedBy:  objUserID,
+	}
+	if req.Submit {
+		now := time.Now()
+		order.Status = Domain.PurchaseOrderStatusOrdered
+		order.OrderedAt = &now
+	}
+
+	for _, item := range req.Items {
+		if item.Quantity <= 0 {
+			return nil, fmt.Errorf("item quantity must be greater than 0")
+		}
+		if item.UnitCost < 0 {
+			return nil, fmt.Errorf("item unit cost cannot be negative")
+		}
+
+		poItem := Domain.PurchaseOrderItem{
+			Description: item.Description,
+			Quantity:    item.Quantity,
+			UnitCost:    item.UnitCost,
+			Total:       item.UnitCost.Times(item.Quantity),
+		}
+
+		if item.ProductID != nil {
+			product, err := uc.inventoryRepo.FindInBusiness(ctx, *item.ProductID)
+			if err != nil {
+				return nil, fmt.Errorf("failed to find product: %w", err)
+			}
+			if product == nil {
+				return nil, fmt.Errorf("product %s not found", *item.ProductID)
+			}
+			poItem.ProductID = &product.ID
+			if poItem.Description == "" {
+				poItem.Description = product.Name
+			}
+		}
+
+		if poItem.Description == "" {
+			return nil, fmt.Errorf("item description is required when no product is given")
+		}
+
+		order.Items = append(order.Items, poItem)
+		order.Total += poItem.Total
+	}
+
+	count, err := uc.purchaseOrderRepo.CountByBusinessID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	order.Number = fmt.Sprintf("PO-%05d", count+1)
+
+	if err := uc.purchaseOrderRepo.Create(order); err != nil {
+		return nil, fmt.Errorf("failed to create purchase order: %w", err)
+	}
+
+	return order, nil
+}
+
+func (uc *supplierUseCase) GetPurchaseOrderByID(ctx context.Context, id, businessID string) (*Domain.PurchaseOrder, error) {
+	order, err := uc.purchaseOrderRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find purchase order: %w", err)
+	}
+	if order == nil {
+		return nil, Domain.NotFoundError("purchase order not found")
+	}
+
+	return order, nil
+}
+
+func (uc *supplierUseCase) GetPurchaseOrders(businessID string, filters Domain.PurchaseOrderFilters) ([]Domain.PurchaseOrder, error) {
+	return uc.purchaseOrderRepo.FindByBusinessID(businessID, filters)
+}
+
+func (uc *supplierUseCase) SubmitPurchaseOrder(ctx context.Context, id, businessID string) error {
+	order, err := uc.GetPurchaseOrderByID(ctx, id, businessID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status != Domain.PurchaseOrderStatusDraft {
+		return fmt.Errorf("only draft purchase orders can be submitted")
+	}
+
+	return uc.purchaseOrderRepo.UpdateStatus(id, Domain.PurchaseOrderStatusOrdered, nil)
+}
+
+func (uc *supplierUseCase) ReceivePurchaseOrder(ctx context.Context, id, businessID, userID string, req Domain.ReceivePurchaseOrderRequest) (*Domain.PurchaseOrder, error) {
+	order, err := uc.GetPurchaseOrderByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != Domain.PurchaseOrderStatusOrdered && order.Status != Domain.PurchaseOrderStatusDraft {
+		return nil, fmt.Errorf("cannot receive purchase order with status: %s", order.Status)
+	}
+
+	now := time.Now()
+	if err := uc.purchaseOrderRepo.UpdateStatus(id, Domain.PurchaseOrderStatusReceived, &now); err != nil {
+		return nil, err
+	}
+	order.Status = Domain.PurchaseOrderStatusReceived
+	order.ReceivedAt = &now
+
+	// Bring received goods into stock
+	referenceID := order.ID.Hex()
+	for _, item := range order.Items {
+		if item.ProductID == nil {
+			continue
+		}
+		if err := uc.inventoryRepo.AdjustStock(
+			item.ProductID.Hex(),
+			item.Quantity,
+			Domain.MovementTypePurchase,
+			"Purchase order "+order.Number,
+			&referenceID,
+			"purchase_order",
+			userID,
+		); err != nil {
+			Infrastructure.Logger.Error("failed to update inventory for purchase order", "order_number", order.Number, "product_id", item.ProductID.Hex(), "error", err)
+		}
+	}
+
+	uc.recordPrices(order)
+
+	// The goods are now owed for
+	invoice := Domain.RecordSupplierInvoiceRequest{
+		Amount:          order.Total,
+		InvoiceNumber:   req.InvoiceNumber,
+		InvoiceDate:     req.InvoiceDate,
+		DueDate:         req.DueDate,
+		PurchaseOrderID: &referenceID,
+		Notes:           "Received purchase order " + order.Number,
+	}
+	if order.Total > 0 {
+		if _, err := uc.RecordInvoice(ctx, order.SupplierID.Hex(), businessID, userID, invoice); err != nil {
+			return nil, fmt.Errorf("purchase order received but invoice could not be recorded: %w", err)
+		}
+	}
+
+	return order, nil
+}
+
+func (uc *supplierUseCase) CancelPurchaseOrder(ctx context.Context, id, businessID string) error {
+	order, err := uc.GetPurchaseOrderByID(ctx, id, businessID)
+	if err != nil {
+		return err
+	}
+
+	if order.Status == Domain.PurchaseOrderStatusReceived || order.Status == Domain.PurchaseOrderStatusCancelled {
+		return fmt.Errorf("cannot cancel purchase order with status: %s", order.Status)
+	}
+
+	return uc.purchaseOrderRepo.UpdateStatus(id, Domain.PurchaseOrderStatusCancelled, nil)
+}
+
+func (uc *supplierUseCase) RecordInvoice(ctx context.Context, supplierID, businessID, userID string, req Domain.RecordSupplierInvoiceRequest) (*Domain.PayableEntry, error) {
+	supplier, err := uc.GetSupplierByID(ctx, supplierID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	invoiceDate := time.Now()
+	if req.InvoiceDate != nil {
+		invoiceDate = *req.InvoiceDate
+	}
+
+	dueDate := invoiceDate.AddDate(0, 0, supplier.PaymentTermsDays)
+	if req.DueDate != nil {
+		if req.DueDate.Before(invoiceDate) {
+			return nil, fmt.Errorf("due date cannot be before invoice date")
+		}
+		dueDate = *req.DueDate
+	}
+
+	entry := &Domain.PayableEntry{
+		BusinessID: supplier.BusinessID,
+		SupplierID: supplier.ID,
+		Type:       Domain.PayableEntryInvoice,
+		Amount:     req.Amount,
+		Reference:  req.InvoiceNumber,
+		Date:       invoiceDate,
+		DueDate:    &dueDate,
+		Notes:      req.Notes,
+		CreatedBy:  objUserID,
+	}
+
+	if req.PurchaseOrderID != nil {
+		objOrderID, err := primitive.ObjectIDFromHex(*req.PurchaseOrderID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid purchase order ID: %w", err)
+		}
+		entry.PurchaseOrderID = &objOrderID
+	}
+
+	if err := uc.supplierRepo.AddPayableEntry(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (uc *supplierUseCase) RecordPayment(ctx context.Context, supplierID, businessID, userID string, req Domain.RecordSupplierPaymentRequest) (*Domain.PayableEntry, error) {
+	supplier, err := uc.GetSupplierByID(ctx, supplierID, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("amount must be greater than 0")
+	}
+
+	objUserID, err := Domain.PrimitiveObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	date := time.Now()
+	if req.Date != nil {
+		date = *req.Date
+	}
+
+	entry := &Domain.PayableEntry{
+		BusinessID:    supplier.BusinessID,
+		SupplierID:    supplier.ID,
+		Type:          Domain.PayableEntryPayment,
+		Amount:        req.Amount,
+		Reference:     req.Reference,
+		Date:          date,
+		PaymentMethod: req.PaymentMethod,
+		Notes:         req.Notes,
+		CreatedBy:     objUserID,
+	}
+
+	if err := uc.supplierRepo.AddPayableEntry(entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+func (uc *supplierUseCase) GetLedger(ctx context.Context, supplierID, businessID string, limit int) ([]Domain.PayableEntry, error) {
+	// Verify supplier belongs to business
+	if _, err := uc.GetSupplierByID(ctx, supplierID, businessID); err != nil {
+		return nil, err
+	}
+
+	return uc.supplierRepo.GetPayableEntries(supplierID, limit)
+}
+
+func (uc *supplierUseCase) GetAgingReport(businessID string) (*Domain.PayablesAgingReport, error) {
+	suppliers, err := uc.supplierRepo.FindByBusinessID(businessID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := uc.supplierRepo.GetBusinessPayableEntries(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	bySupplier := make(map[primitive.ObjectID][]Domain.PayableEntry)
+	for _, e := range entries {
+		bySupplier[e.SupplierID] = append(bySupplier[e.SupplierID], e)
+	}
+
+	now := time.Now()
+	report := &Domain.PayablesAgingReport{
+		AsOf:      now,
+		Suppliers: []Domain.SupplierAgingDetail{},
+	}
+
+	for _, supplier := range suppliers {
+		buckets := agePayables(bySupplier[supplier.ID], now)
+		if buckets.Outstanding == 0 {
+			continue
+		}
+
+		report.Suppliers = append(report.Suppliers, Domain.SupplierAgingDetail{
+			SupplierID:   supplier.ID,
+			SupplierName: supplier.Name,
+			Buckets:      buckets,
+		})
+
+		report.Totals.Current += buckets.Current
+		report.Totals.Days1To30 += buckets.Days1To30
+		report.Totals.Days31To60 += buckets.Days31To60
+		report.Totals.Days61To90 += buckets.Days61To90
+		report.Totals.Over90 += buckets.Over90
+		report.Totals.Outstanding += buckets.Outstanding
+	}
+
+	// Largest debts first
+	sort.Slice(report.Suppliers, func(i, j int) bool {
+		return report.Suppliers[i].Buckets.Outstanding > report.Suppliers[j].Buckets.Outstanding
+	})
+
+	return report, nil
+}
+
+// agePayables allocates payments and credits to the oldest invoices first and
+// buckets whatever remains unpaid by days past due
+func agePayables(entries []Domain.PayableEntry, now time.Time) Domain.AgingBuckets {
+	var invoices []Domain.PayableEntry
+	var paid Domain.Money
+	for _, e := range entries {
+		if e.Type == Domain.PayableEntryInvoice {
+			invoices = append(invoices, e)
+		} else {
+			paid += e.Amount
+		}
+	}
+
+	var buckets Domain.AgingBuckets
+	for _, inv := range invoices {
+		remaining := inv.Amount
+		if paid >= remaining {
+			paid -= remaining
+			continue
+		}
+		remaining -= paid
+		paid = 0
+
+		due := inv.Date
+		if inv.DueDate != nil {
+			due = *inv.DueDate
+		}
+		daysOverdue := int(now.Sub(due).Hours() / 24)
+		buckets.Add(remaining, daysOverdue)
+	}
+
+	// Overpayment is a credit with the supplier
+	if paid > 0 {
+		buckets.Add(-paid, 0)
+	}
+
+	return buckets
+}
+
+// recordPrices makes the received order's lines the supplier's last prices, and
+// learns how long the supplier took to deliver
+func (uc *supplierUseCase) recordPrices(order *Domain.PurchaseOrder) {
+	var leadTime *float64
+	if order.OrderedAt != nil && order.ReceivedAt != nil {
+		days := math.Max(0, order.ReceivedAt.Sub(*order.OrderedAt).Hours()/24)
+		days = math.Round(days*10) / 10
+		leadTime = &days
+	}
+
+	for _, item := range order.Items {
+		if item.ProductID == nil {
+			continue
+		}
+		purchase := Domain.SupplierPurchase{
+			BusinessID:   order.BusinessID,
+			SupplierID:   order.SupplierID,
+			ProductID:    *item.ProductID,
+			UnitCost:     item.UnitCost,
+			OrderID:      order.ID,
+			OrderNumber:  order.Number,
+			PurchasedAt:  *order.ReceivedAt,
+			LeadTimeDays: leadTime,
+		}
+		if err := uc.supplierPriceRepo.RecordPurchase(purchase); err != nil {
+			Infrastructure.Logger.Warn("failed to record supplier price for purchase order", "order_number", order.Number, "error", err)
+		}
+	}
+}
+
+func (uc *supplierUseCase) GetProductSupplierPrices(ctx context.Context, productID, businessID string) (*Domain.ProductSupplierPrices, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, productID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt != nil {
+		return nil, Domain.NotFoundError("product not found")
+	}
+
+	prices, err := uc.supplierPriceRepo.FindByProduct(businessID, productID)
+	if err != nil {
+		return nil, err
+	}
+
+	suppliers, err := uc.supplierRepo.FindByBusinessID(businessID, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Domain.ProductSupplierPrices{
+		ProductID: product.ID.Hex(),
+		Name:      product.Name,
+		SKU:       product.SKU,
+		CostPrice: product.CostPrice,
+		Quotes:    supplierQuotes(prices, suppliersByID(suppliers), time.Now()),
+	}, nil
+}
+
+func (uc *supplierUseCase) GetPurchaseSuggestions(ctx context.Context, businessID string, opts Domain.PurchaseSuggestionOptions) (*Domain.PurchaseSuggestionReport, error) {
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find business: %w", err)
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+
+	// Only active suppliers are worth suggesting
+	active := Domain.SupplierStatusActive
+	suppliers, err := uc.supplierRepo.FindByBusinessID(businessID, &active, nil)
+	if err != nil {
+		return nil, err
+	}
+	byID := suppliersByID(suppliers)
+	if opts.SupplierID != "" {
+		supplier, err := uc.GetSupplierByID(ctx, opts.SupplierID, businessID)
+		if err != nil {
+			return nil, err
+		}
+		byID = map[primitive.ObjectID]Domain.Supplier{supplier.ID: *supplier}
+	}
+
+	prices, err := uc.supplierPriceRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	pricesByProduct := make(map[primitive.ObjectID][]Domain.SupplierPrice)
+	for _, price := range prices {
+		pricesByProduct[price.ProductID] = append(pricesByProduct[price.ProductID], price)
+	}
+
+	// Asked-for products are suggested even without a supplier price, so the
+	// caller can see none is known
+	explicit := len(opts.ProductIDs) > 0
+	productIDs := opts.ProductIDs
+	if !explicit {
+		for id := range pricesByProduct {
+			productIDs = append(productIDs, id.Hex())
+		}
+	}
+
+	forecastOpts := Domain.ForecastOptions{}
+	if len(productIDs) == 1 {
+		forecastOpts.ProductID = productIDs[0]
+	}
+	forecast, err := uc.forecastUC.GetForecast(ctx, businessID, forecastOpts)
+	if err != nil {
+		return nil, err
+	}
+	demand := make(map[string]float64, len(forecast.Products))
+	for _, f := range forecast.Products {
+		demand[f.ProductID] = f.AverageDaily
+	}
+
+	products, err := uc.inventoryRepo.FindByIDs(businessID, productIDs)
+	if err != nil {
+		return nil, err
+	}
+	if explicit && len(products) < len(productIDs) {
+		return nil, Domain.NotFoundError("product not found")
+	}
+
+	now := time.Now()
+	today := clockFor(business).Day(now)
+	report := &Domain.PurchaseSuggestionReport{
+		GeneratedAt: now,
+		Suggestions: []Domain.PurchaseSuggestion{},
+	}
+
+	for _, product := range products {
+		if product.DeletedAt != nil || (!explicit && product.Status != Domain.ProductStatusActive) {
+			continue
+		}
+
+		suggestion := suggestPurchase(product, demand[product.ID.Hex()], supplierQuotes(pricesByProduct[product.ID], byID, now), today)
+		if !explicit {
+			if suggestion.Recommended == nil || suggestion.ReorderBy == "" {
+				continue
+			}
+			if by, _ := time.Parse("2006-01-02", suggestion.ReorderBy); by.After(today.AddDate(0, 0, purchaseSuggestionHorizonDays)) {
+				continue
+			}
+		}
+
+		report.Suggestions = append(report.Suggestions, suggestion)
+	}
+
+	// Most urgent first; products that are not selling last
+	sort.SliceStable(report.Suggestions, func(i, j int) bool {
+		a, b := report.Suggestions[i].ReorderBy, report.Suggestions[j].ReorderBy
+		if a == "" || b == "" {
+			return a != ""
+		}
+		return a < b
+	})
+
+	return report, nil
+}
+
+// suggestPurchase picks the supplier for the product and works out when to order
+// from them, and how much, so the delivery lands before stock falls below the minimum
+func suggestPurchase(product Domain.Product, averageDaily float64, quotes []Domain.SupplierQuote, today time.Time) Domain.PurchaseSuggestion {
+	suggestion := Domain.PurchaseSuggestion{
+		ProductID:    product.ID.Hex(),
+		Name:         product.Name,
+		SKU:          product.SKU,
+		Stock:        product.Stock,
+		MinStock:     product.MinStock,
+		AverageDaily: averageDaily,
+		Quotes:       quotes,
+	}
+
+	// Days until stock is down to the minimum; nil when nothing is selling
+	var daysToMin *float64
+	if averageDaily > 0 {
+		cover := roundCurrency(product.Stock / averageDaily)
+		suggestion.DaysOfCover = &cover
+		days := math.Max(0, (product.Stock-product.MinStock)/averageDaily)
+		daysToMin = &days
+	} else if product.MinStock > 0 && product.Stock < product.MinStock {
+		days := 0.0
+		daysToMin = &days
+	}
+
+	recommended, reason := recommendSupplier(quotes, daysToMin)
+	if recommended == nil {
+		return suggestion
+	}
+	suggestion.Recommended = recommended
+	suggestion.Reason = reason
+
+	if daysToMin == nil {
+		return suggestion
+	}
+
+	slack := math.Max(0, math.Floor(*daysToMin-recommended.LeadTimeDays))
+	suggestion.ReorderBy = today.AddDate(0, 0, int(slack)).Format("2006-01-02")
+	suggestion.OrderNow = slack == 0
+
+	// Order enough, as of the reorder day, for the lead time and a cycle after it
+	atOrder := product
+	atOrder.Stock = math.Max(0, product.Stock-averageDaily*slack)
+	forecast := Domain.ProductForecast{
+		AverageDaily: averageDaily,
+		LeadDemand:   roundCurrency(averageDaily * (recommended.LeadTimeDays + purchaseCycleDays)),
+	}
+	applyReorder(&forecast, atOrder)
+
+	suggestion.SuggestedQuantity = forecast.ReorderQuantity
+	suggestion.EstimatedCost = recommended.LastUnitCost.Times(forecast.ReorderQuantity)
+
+	return suggestion
+}
+
+// recommendSupplier picks the cheapest supplier with a fresh price, unless it
+// cannot deliver before stock runs down to the minimum and another can. With only
+// stale prices it falls back to whoever was bought from last.
+func recommendSupplier(quotes []Domain.SupplierQuote, daysToMin *float64) (*Domain.SupplierQuote, Domain.SuggestionReason) {
+	if len(quotes) == 0 {
+		return nil, ""
+	}
+	if len(quotes) == 1 {
+		quote := quotes[0]
+		return &quote, Domain.SuggestionOnlySupplier
+	}
+
+	var fresh []Domain.SupplierQuote
+	for _, quote := range quotes {
+		if !quote.Stale {
+			fresh = append(fresh, quote)
+		}
+	}
+	if len(fresh) == 0 {
+		latest := quotes[0]
+		for _, quote := range quotes[1:] {
+			if quote.LastPurchasedAt.After(latest.LastPurchasedAt) {
+				latest = quote
+			}
+		}
+		return &latest, Domain.SuggestionMostRecent
+	}
+
+	// Quotes are cheapest first, so the first in time is the cheapest in time
+	cheapest := fresh[0]
+	if daysToMin != nil && cheapest.LeadTimeDays > *daysToMin {
+		for _, quote := range fresh[1:] {
+			if quote.LeadTimeDays <= *daysToMin {
+				return &quote, Domain.SuggestionFasterDelivery
+			}
+		}
+	}
+	return &cheapest, Domain.SuggestionCheapest
+}
+
+// supplierQuotes turns the suppliers' prices into quotes, cheapest first, leaving
+// out suppliers not in suppliers
+func supplierQuotes(prices []Domain.SupplierPrice, suppliers map[primitive.ObjectID]Domain.Supplier, now time.Time) []Domain.SupplierQuote {
+	staleBefore := now.AddDate(0, 0, -Domain.StaleSupplierPriceDays)
+
+	quotes := []Domain.SupplierQuote{}
+	for _, price := range prices {
+		supplier, ok := suppliers[price.SupplierID]
+		if !ok {
+			continue
+		}
+
+		quote := Domain.SupplierQuote{
+			SupplierID:       supplier.ID.Hex(),
+			SupplierName:     supplier.Name,
+			LastUnitCost:     price.LastUnitCost,
+			PreviousUnitCost: price.PreviousUnitCost,
+			LastPurchasedAt:  price.LastPurchasedAt,
+			LastOrderNumber:  price.LastOrderNumber,
+			Purchases:        price.Purchases,
+			LeadTimeDays:     Domain.DefaultSupplierLeadTimeDays,
+			LeadTimeSource:   Domain.LeadTimeDefault,
+			Stale:            price.LastPurchasedAt.Before(staleBefore),
+		}
+		switch {
+		case price.LeadTimeDays != nil:
+			quote.LeadTimeDays = math.Round(*price.LeadTimeDays*10) / 10
+			quote.LeadTimeSource = Domain.LeadTimeFromHistory
+		case supplier.LeadTimeDays > 0:
+			quote.LeadTimeDays = float64(supplier.LeadTimeDays)
+			quote.LeadTimeSource = Domain.LeadTimeFromSupplier
+		}
+
+		quotes = append(quotes, quote)
+	}
+
+	sort.SliceStable(quotes, func(i, j int) bool {
+		if quotes[i].LastUnitCost != quotes[j].LastUnitCost {
+			return quotes[i].LastUnitCost < quotes[j].LastUnitCost
+		}
+		return quotes[i].LeadTimeDays < quotes[j].LeadTimeDays
+	})
+
+	return quotes
+}
+
+func suppliersByID(suppliers []Domain.Supplier) map[primitive.ObjectID]Domain.Supplier {
+	byID := make(map[primitive.ObjectID]Domain.Supplier, len(suppliers))
+	for _, supplier := range suppliers {
+		byID[supplier.ID] = supplier
+	}
+	return byID
+}
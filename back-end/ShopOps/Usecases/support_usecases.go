@@ -0,0 +1,374 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// SupportUseCase backs the internal support API: finding shops, unblocking them and
+// seeing what they ran into
+type SupportUseCase interface {
+	// SearchShops finds shops by ID, name, phone or email, or by their owner's phone
+	SearchShops(query string) ([]Domain.Business, error)
+	GetShop(businessID string) (*Domain.ShopOverview, error)
+	ResetRateLimits(ctx context.Context, businessID string) error
+	// TriggerBackup queues a full export of the shop's data
+	TriggerBackup(ctx context.Context, businessID, actor string) (*Domain.Job, error)
+	RunBackupJob(ctx context.Context, job *Domain.Job) error
+	GetBackups(businessID string) ([]Domain.ShopBackup, error)
+	OpenBackup(businessID, backupID string) (*Domain.ShopBackup, io.ReadCloser, error)
+	// Impersonate issues a short-lived, read-only token for the shop's owner
+	Impersonate(businessID, actor string) (*Domain.ImpersonationResponse, error)
+	GetRecentErrors(businessID string, limit int) ([]Domain.RequestError, error)
+	GetAuditLog(filters Domain.AuditFilters) ([]Domain.AuditEntry, error)
+	CountAuditLog(filters Domain.AuditFilters) (int64, error)
+}
+
+type supportUseCase struct {
+	businessRepo     Domain.BusinessRepository
+	userRepo         Domain.UserRepository
+	employeeRepo     Domain.EmployeeRepository
+	requestErrorRepo Domain.RequestErrorRepository
+	backupRepo       Domain.BackupRepository
+	auditRepo        Domain.AuditLogRepository
+	flags            Infrastructure.FlagEvaluator
+	maintenance      Infrastructure.MaintenanceSource
+	rateLimiter      Infrastructure.RateLimitService
+	jobQueue         Infrastructure.JobQueue
+	backupStorage    Infrastructure.FileStorage
+	jwtService       Infrastructure.JWTService
+	events           EventPublisher
+	alerter          SecurityAlerter
+	impersonationTTL time.Duration
+}
+
+const maxShopSearchResults = 20
+
+func NewSupportUseCase(
+	businessRepo Domain.BusinessRepository,
+	userRepo Domain.UserRepository,
+	employeeRepo Domain.EmployeeRepository,
+	requestErrorRepo Domain.RequestErrorRepository,
+	backupRepo Domain.BackupRepository,
+	auditRepo Domain.AuditLogRepository,
+	flags Infrastructure.FlagEvaluator,
+	maintenance Infrastructure.MaintenanceSource,
+	rateLimiter Infrastructure.RateLimitService,
+	jobQueue Infrastructure.JobQueue,
+	backupStorage Infrastructure.FileStorage,
+	jwtService Infrastructure.JWTService,
+	events EventPublisher,
+	alerter SecurityAlerter,
+	impersonationTTL time.Duration,
+) SupportUseCase {
+	return &supportUseCase{
+		businessRepo:     businessRepo,
+		userRepo:         userRepo,
+		employeeRepo:     employeeRepo,
+		requestErrorRepo: requestErrorRepo,
+		backupRepo:       backupRepo,
+		auditRepo:        auditRepo,
+		flags:            flags,
+		maintenance:      maintenance,
+		rateLimiter:      rateLimiter,
+		jobQueue:         jobQueue,
+		backupStorage:    backupStorage,
+		jwtService:       jwtService,
+		events:           events,
+		alerter:          alerter,
+		impersonationTTL: impersonationTTL,
+	}
+}
+
+func (uc *supportUseCase) SearchShops(query string) ([]Domain.Business, error) {
+	if query == "" {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "search query is required")
+	}
+
+	if _, err := primitive.ObjectIDFromHex(query); err == nil {
+		business, err := uc.businessRepo.FindByID(query)
+		if err != nil {
+			return nil, err
+		}
+		if business == nil {
+			return []Domain.Business{}, nil
+		}
+		return []Domain.Business{*business}, nil
+	}
+
+	businesses, err := uc.businessRepo.Search(query, maxShopSearchResults)
+	if err != nil {
+		return nil, err
+	}
+
+	// Shops are often reported by the owner's number rather than the shop's
+	owner, err := uc.userRepo.FindByPhone(query)
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		owned, err := uc.businessRepo.FindByUserID(owner.ID.Hex())
+		if err != nil {
+			return nil, err
+		}
+		seen := make(map[primitive.ObjectID]bool, len(businesses))
+		for _, business := range businesses {
+			seen[business.ID] = true
+		}
+		for _, business := range owned {
+			if !seen[business.ID] {
+				businesses = append(businesses, business)
+			}
+		}
+	}
+
+	if businesses == nil {
+		businesses = []Domain.Business{}
+	}
+	return businesses, nil
+}
+
+func (uc *supportUseCase) GetShop(businessID string) (*Domain.ShopOverview, error) {
+	business, err := uc.getBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	overview := &Domain.ShopOverview{
+		Business:    *business,
+		Features:    uc.flags.EnabledFlags(businessID),
+		Maintenance: uc.maintenance.CurrentMaintenance().Enabled,
+	}
+
+	owner, err := uc.userRepo.FindByID(business.UserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if owner != nil {
+		overview.Owner = userSummary(owner)
+	}
+
+	if overview.RecentErrors, err = uc.requestErrorRepo.CountByBusinessSince(businessID, time.Now().Add(-24*time.Hour)); err != nil {
+		return nil, err
+	}
+
+	backups, err := uc.backupRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) > 0 {
+		overview.LastBackup = &backups[0]
+	}
+
+	return overview, nil
+}
+
+func (uc *supportUseCase) ResetRateLimits(ctx context.Context, businessID string) error {
+	business, err := uc.getBusiness(businessID)
+	if err != nil {
+		return err
+	}
+
+	userIDs := []string{business.UserID.Hex()}
+	employees, err := uc.employeeRepo.FindByBusinessID(businessID, nil)
+	if err != nil {
+		return err
+	}
+	for _, employee := range employees {
+		if employee.UserID != nil {
+			userIDs = append(userIDs, employee.UserID.Hex())
+		}
+	}
+
+	return uc.rateLimiter.ResetBusiness(ctx, businessID, userIDs)
+}
+
+func (uc *supportUseCase) TriggerBackup(ctx context.Context, businessID, actor string) (*Domain.Job, error) {
+	if _, err := uc.getBusiness(businessID); err != nil {
+		return nil, err
+	}
+
+	return uc.jobQueue.Enqueue(ctx, Domain.JobTypeShopBackup, businessID, map[string]string{"requested_by": actor})
+}
+
+// RunBackupJob streams the shop's documents into a file of JSON lines, each
+// {"collection": ..., "document": ...} in MongoDB extended JSON. The shop hears
+// of a failure once the job is out of attempts.
+func (uc *supportUseCase) RunBackupJob(ctx context.Context, job *Domain.Job) error {
+	if job.BusinessID == nil {
+		return fmt.Errorf("backup job has no business")
+	}
+	businessID := job.BusinessID.Hex()
+
+	err := uc.runBackup(ctx, job, businessID)
+	if err != nil && job.Attempts >= job.MaxAttempts {
+		failure := Domain.BackupFailedEvent{
+			JobID:    job.ID.Hex(),
+			Error:    err.Error(),
+			Attempts: job.Attempts,
+		}
+		if publishErr := uc.events.Publish(ctx, businessID, Domain.WebhookEventBackupFailed, failure); publishErr != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to publish backup failure for business", "business_id", businessID, "error", publishErr)
+		}
+	}
+	return err
+}
+
+func (uc *supportUseCase) runBackup(ctx context.Context, job *Domain.Job, businessID string) error {
+
+	reader, writer := io.Pipe()
+	exported := make(chan map[string]int, 1)
+	go func() {
+		counts, err := uc.backupRepo.Export(businessID, func(collection string, document []byte) error {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			_, err := fmt.Fprintf(writer, "{\"collection\":%q,\"document\":%s}\n", collection, document)
+			return err
+		})
+		exported <- counts
+		writer.CloseWithError(err)
+	}()
+
+	filename := fmt.Sprintf("%s-%s.jsonl", businessID, time.Now().UTC().Format("20060102T150405Z"))
+	url, err := uc.backupStorage.Save(businessID, filename, reader)
+	reader.CloseWithError(err) // Stops the export if saving failed first
+	counts := <-exported
+	if err != nil {
+		return fmt.Errorf("failed to save backup: %w", err)
+	}
+
+	backup := &Domain.ShopBackup{
+		BusinessID:  *job.BusinessID,
+		JobID:       job.ID,
+		URL:         url,
+		Documents:   counts,
+		RequestedBy: job.Payload["requested_by"],
+	}
+	// Records backup.completed along with the backup
+	return uc.backupRepo.Create(backup)
+}
+
+func (uc *supportUseCase) GetBackups(businessID string) ([]Domain.ShopBackup, error) {
+	backups, err := uc.backupRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if backups == nil {
+		backups = []Domain.ShopBackup{}
+	}
+	return backups, nil
+}
+
+func (uc *supportUseCase) OpenBackup(businessID, backupID string) (*Domain.ShopBackup, io.ReadCloser, error) {
+	backups, err := uc.backupRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, nil, err
+	}
+	for i := range backups {
+		if backups[i].ID.Hex() == backupID {
+			file, err := uc.backupStorage.Open(backups[i].URL)
+			if err != nil {
+				return nil, nil, err
+			}
+			return &backups[i], file, nil
+		}
+	}
+	return nil, nil, Domain.NotFoundError("backup not found")
+}
+
+func (uc *supportUseCase) Impersonate(businessID, actor string) (*Domain.ImpersonationResponse, error) {
+	business, err := uc.getBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := uc.userRepo.FindByID(business.UserID.Hex())
+	if err != nil {
+		return nil, err
+	}
+	if owner == nil {
+		return nil, Domain.NotFoundError("shop owner not found")
+	}
+
+	token, expiresAt, err := uc.jwtService.GenerateImpersonationToken(owner.ID.Hex(), owner.Phone, string(owner.Role), actor, uc.impersonationTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	uc.alerter.AlertUser(context.Background(), owner.ID.Hex(), Domain.SecurityAlert{
+		Title: "ShopOps support is viewing " + business.Name,
+		Body:  "Our support team opened a read-only view of your account at " + pushAlertTime(time.Now()) + " to help with your request.",
+	})
+
+	return &Domain.ImpersonationResponse{
+		AccessToken: token,
+		ExpiresAt:   expiresAt,
+		UserID:      owner.ID.Hex(),
+		ReadOnly:    true,
+	}, nil
+}
+
+func (uc *supportUseCase) GetRecentErrors(businessID string, limit int) ([]Domain.RequestError, error) {
+	if _, err := uc.getBusiness(businessID); err != nil {
+		return nil, err
+	}
+
+	requestErrors, err := uc.requestErrorRepo.FindByBusiness(businessID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if requestErrors == nil {
+		requestErrors = []Domain.RequestError{}
+	}
+	return requestErrors, nil
+}
+
+func (uc *supportUseCase) GetAuditLog(filters Domain.AuditFilters) ([]Domain.AuditEntry, error) {
+	entries, err := uc.auditRepo.Find(filters)
+	if err != nil {
+		return nil, err
+	}
+	if entries == nil {
+		entries = []Domain.AuditEntry{}
+	}
+	return entries, nil
+}
+
+func (uc *supportUseCase) CountAuditLog(filters Domain.AuditFilters) (int64, error) {
+	return uc.auditRepo.Count(filters)
+}
+
+func (uc *supportUseCase) getBusiness(businessID string) (*Domain.Business, error) {
+	if _, err := primitive.ObjectIDFromHex(businessID); err != nil {
+		return nil, Domain.NewAppError(Domain.ErrCodeInvalidArgument, "invalid business ID")
+	}
+
+	business, err := uc.businessRepo.FindByID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if business == nil {
+		return nil, Domain.NotFoundError("business not found")
+	}
+	return business, nil
+}
+
+func userSummary(user *Domain.User) *Domain.UserSummary {
+	return &Domain.UserSummary{
+		ID:        user.ID,
+		Name:      user.Name,
+		Phone:     user.Phone,
+		Email:     user.Email,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+	}
+}
@@ -1,6 +1,7 @@
 package Usecases
 
 import (
+	"context"
 	"fmt"
 	"time"
 
@@ -9,21 +10,28 @@ import (
 )
 
 type SyncUseCase interface {
-	ProcessBatch(batch Domain.SyncBatch) (*Domain.SyncResponse, error)
+	ProcessBatch(ctx context.Context, batch Domain.SyncBatch) (*Domain.SyncResponse, error)
 	GetSyncStatus(businessID string) (*Domain.SyncStatus, error)
 	ValidateBatch(batch Domain.SyncBatch) error
 	GetLastSync(businessID, deviceID string) (*time.Time, error)
 }
 
 type syncUseCase struct {
-	syncService   Infrastructure.SyncService
-	businessRepo  Domain.BusinessRepository
-	salesRepo     Domain.SaleRepository
-	expenseRepo   Domain.ExpenseRepository
-	inventoryRepo Domain.ProductRepository
-	syncRepo      Domain.SyncRepository
+	syncService     Infrastructure.SyncService
+	businessRepo    Domain.BusinessRepository
+	salesRepo       Domain.SaleRepository
+	expenseRepo     Domain.ExpenseRepository
+	inventoryRepo   Domain.ProductRepository
+	syncRepo        Domain.SyncRepository
+	customFieldRepo Domain.CustomFieldRepository
+	analyticsUC     AnalyticsUseCase
+	dashboardUC     DashboardUseCase
+	events          EventPublisher
 }
 
+// Errors listed in a sync.failed event; the device has them all
+const syncFailedEventErrors = 5
+
 func NewSyncUseCase(
 	syncService Infrastructure.SyncService,
 	businessRepo Domain.BusinessRepository,
@@ -31,32 +39,132 @@ func NewSyncUseCase(
 	expenseRepo Domain.ExpenseRepository,
 	inventoryRepo Domain.ProductRepository,
 	syncRepo Domain.SyncRepository,
+	customFieldRepo Domain.CustomFieldRepository,
+	analyticsUC AnalyticsUseCase,
+	dashboardUC DashboardUseCase,
+	events EventPublisher,
 ) SyncUseCase {
 	return &syncUseCase{
-		syncService:   syncService,
-		businessRepo:  businessRepo,
-		salesRepo:     salesRepo,
-		expenseRepo:   expenseRepo,
-		inventoryRepo: inventoryRepo,
-		syncRepo:      syncRepo,
+		syncService:     syncService,
+		businessRepo:    businessRepo,
+		salesRepo:       salesRepo,
+		expenseRepo:     expenseRepo,
+		inventoryRepo:   inventoryRepo,
+		syncRepo:        syncRepo,
+		customFieldRepo: customFieldRepo,
+		analyticsUC:     analyticsUC,
+		dashboardUC:     dashboardUC,
+		events:          events,
 	}
 }
 
-func (uc *syncUseCase) ProcessBatch(batch Domain.SyncBatch) (*Domain.SyncResponse, error) {
+func (uc *syncUseCase) ProcessBatch(ctx context.Context, batch Domain.SyncBatch) (*Domain.SyncResponse, error) {
 	// Validate batch
 	if err := uc.ValidateBatch(batch); err != nil {
 		return nil, fmt.Errorf("batch validation failed: %w", err)
 	}
 
 	// Process batch using sync service
-	response, err := uc.syncService.ProcessBatch(batch)
+	Infrastructure.SyncBatchItems.Observe(float64(len(batch.Items)))
+
+	valid, rejected, err := uc.checkCustomFields(batch)
+	if err != nil {
+		return nil, err
+	}
+
+	response, err := uc.syncService.ProcessBatch(ctx, valid)
 	if err != nil {
 		return nil, fmt.Errorf("failed to process batch: %w", err)
 	}
+	response.Failed = append(response.Failed, rejected...)
+	Infrastructure.SyncItems.Add(float64(len(response.Success)), "success")
+	Infrastructure.SyncItems.Add(float64(len(response.Failed)), "failed")
+
+	// Synced records land on the days they were recorded offline as well as today
+	touched := []time.Time{time.Now()}
+	for _, item := range batch.Items {
+		touched = append(touched, item.CreatedAt, item.UpdatedAt)
+	}
+	uc.analyticsUC.MarkDirty(batch.BusinessID, touched...)
+	uc.dashboardUC.Invalidate(batch.BusinessID)
+
+	if len(response.Failed) > 0 {
+		event := Domain.SyncFailedEvent{
+			DeviceID: batch.DeviceID,
+			Failed:   len(response.Failed),
+			Total:    len(batch.Items),
+		}
+		for i, result := range response.Failed {
+			if i == syncFailedEventErrors {
+				break
+			}
+			event.Errors = append(event.Errors, result.Error)
+		}
+		if err := uc.events.Publish(ctx, batch.BusinessID, Domain.WebhookEventSyncFailed, event); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to publish sync failure for device", "device_id", batch.DeviceID, "error", err)
+		}
+	}
 
 	return response, nil
 }
 
+// checkCustomFields validates the custom fields of synced products and sales the
+// way the API does, and stores them normalized. Items with bad values are failed
+// on their own so the rest of the batch still goes through. Fields the shop
+// made required after the item was recorded offline are not insisted on.
+func (uc *syncUseCase) checkCustomFields(batch Domain.SyncBatch) (Domain.SyncBatch, []Domain.SyncResult, error) {
+	var defs map[Domain.CustomFieldEntity][]Domain.CustomFieldDefinition
+	var rejected []Domain.SyncResult
+
+	items := make([]Domain.SyncItem, 0, len(batch.Items))
+	for _, item := range batch.Items {
+		entity := Domain.CustomFieldEntity(item.EntityType)
+		data, ok := item.Data.(map[string]interface{})
+		if !ok || (entity != Domain.CustomFieldEntityProduct && entity != Domain.CustomFieldEntitySale) {
+			items = append(items, item)
+			continue
+		}
+		raw, ok := data["custom_fields"]
+		if !ok {
+			items = append(items, item)
+			continue
+		}
+
+		if defs == nil {
+			all, err := uc.customFieldRepo.FindByBusinessID(batch.BusinessID, "", true)
+			if err != nil {
+				return batch, nil, err
+			}
+			defs = make(map[Domain.CustomFieldEntity][]Domain.CustomFieldDefinition)
+			for _, def := range all {
+				defs[def.Entity] = append(defs[def.Entity], def)
+			}
+		}
+
+		values, _ := raw.(map[string]interface{})
+		if raw != nil && values == nil {
+			rejected = append(rejected, Domain.SyncResult{LocalID: item.LocalID, Error: "custom_fields must be an object", Timestamp: time.Now()})
+			continue
+		}
+		customFields, err := Domain.ValidateCustomFields(defs[entity], values, nil, false)
+		if err != nil {
+			rejected = append(rejected, Domain.SyncResult{LocalID: item.LocalID, Error: err.Error(), Timestamp: time.Now()})
+			continue
+		}
+
+		normalized := make(map[string]interface{}, len(data))
+		for key, value := range data {
+			normalized[key] = value
+		}
+		normalized["custom_fields"] = customFields
+		item.Data = normalized
+		items = append(items, item)
+	}
+
+	batch.Items = items
+	return batch, rejected, nil
+}
+
 func (uc *syncUseCase) GetSyncStatus(businessID string) (*Domain.SyncStatus, error) {
 	// Validate business exists
 	_, err := uc.businessRepo.FindByID(businessID)
@@ -78,7 +186,7 @@ func (uc *syncUseCase) ValidateBatch(batch Domain.SyncBatch) error {
 		return fmt.Errorf("invalid business ID: %w", err)
 	}
 	if business == nil {
-		return fmt.Errorf("business not found")
+		return Domain.NotFoundError("business not found")
 	}
 
 	// Validate device ID
@@ -0,0 +1,746 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	// tabChangesLimit caps the tabs sent per poll; a device behind by more asks again
+	tabChangesLimit = 200
+	// openTabsLimit caps the open tabs a device starts from
+	openTabsLimit = 500
+	// tabMaxWait is the longest a poll for changes is held open
+	tabMaxWait      = 30 * time.Second
+	tabPollInterval = time.Second
+)
+
+const errTabChangedText = "the tab changed on another device; reload it and try again"
+
+type TabUseCase interface {
+	// OpenTab starts a tab for a table, crediting it to the waiter given or
+	// whoever is clocked in on the device
+	OpenTab(ctx context.Context, businessID, userID string, req Domain.OpenTabRequest) (*Domain.Tab, error)
+	GetTabs(businessID string, filters Domain.TabFilters) ([]Domain.Tab, error)
+	GetTab(ctx context.Context, id, businessID string) (*Domain.Tab, error)
+	// GetChanges returns the shop's tabs changed since revision, holding the
+	// request for up to wait until there are any. Without a revision it returns
+	// the open tabs, with the revision to follow them from.
+	GetChanges(ctx context.Context, businessID string, revision *int64, wait time.Duration) (*Domain.TabChanges, error)
+	UpdateTab(ctx context.Context, id, businessID string, req Domain.UpdateTabRequest) (*Domain.Tab, error)
+	AddItems(ctx context.Context, id, businessID, userID string, req Domain.AddTabItemsRequest) (*Domain.Tab, error)
+	RemoveItem(ctx context.Context, id, itemID, businessID string) (*Domain.Tab, error)
+	// SplitTab splits the tab evenly or by items, or undoes the split when
+	// given neither
+	SplitTab(ctx context.Context, id, businessID string, req Domain.SplitTabRequest) (*Domain.Tab, error)
+	// PayTab takes payments towards the tab or one of its bills. Once the whole
+	// tab is paid it closes, recording a sale for each item.
+	PayTab(ctx context.Context, id, businessID, userID string, req Domain.PayTabRequest) (*Domain.Tab, error)
+	// RemovePayment takes back a payment taken in error, before the tab closes
+	RemovePayment(ctx context.Context, id, paymentID, businessID string) (*Domain.Tab, error)
+	// CloseTab records the sales of a fully paid tab whose closing was cut short
+	CloseTab(ctx context.Context, id, businessID, userID string) (*Domain.Tab, error)
+	// CancelTab closes a tab with nothing paid on it, without any sales
+	CancelTab(ctx context.Context, id, businessID, userID string) (*Domain.Tab, error)
+}
+
+type tabUseCase struct {
+	tabRepo         Domain.TabRepository
+	inventoryRepo   Domain.ProductRepository
+	reservationRepo Domain.StockReservationRepository
+	employeeRepo    Domain.EmployeeRepository
+	salesUC         SalesUseCase
+}
+
+func NewTabUseCase(
+	tabRepo Domain.TabRepository,
+	inventoryRepo Domain.ProductRepository,
+	reservationRepo Domain.StockReservationRepository,
+	employeeRepo Domain.EmployeeRepository,
+	salesUC SalesUseCase,
+) TabUseCase {
+	return &tabUseCase{
+		tabRepo:         tabRepo,
+		inventoryRepo:   inventoryRepo,
+		reservationRepo: reservationRepo,
+		employeeRepo:    employeeRepo,
+		salesUC:         salesUC,
+	}
+}
+
+func (uc *tabUseCase) OpenTab(ctx context.Context, businessID, userID string, req Domain.OpenTabRequest) (*Domain.Tab, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	var employeeID *primitive.ObjectID
+	if req.EmployeeID != nil && *req.EmployeeID != "" {
+		if employeeID, err = uc.waiter(ctx, businessID, *req.EmployeeID); err != nil {
+			return nil, err
+		}
+	} else if req.DeviceID != "" {
+		entry, err := uc.employeeRepo.FindOpenTimeEntryByDevice(businessID, req.DeviceID)
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to find shift for device", "device_id", req.DeviceID, "error", err)
+		} else if entry != nil {
+			employeeID = &entry.EmployeeID
+		}
+	}
+
+	items, err := uc.newItems(ctx, businessID, objUserID, req.Items, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	tab := &Domain.Tab{
+		BusinessID: objBusinessID,
+		Table:      req.Table,
+		Guests:     req.Guests,
+		EmployeeID: employeeID,
+		Status:     Domain.TabOpen,
+		Items:      items,
+		Bills:      []Domain.TabBill{},
+		Payments:   []Domain.TabPayment{},
+		Notes:      req.Notes,
+		OpenedBy:   objUserID,
+	}
+	if err := uc.tabRepo.Create(tab); err != nil {
+		return nil, err
+	}
+
+	return tab, nil
+}
+
+// waiter checks the employee is one of the shop's active staff
+func (uc *tabUseCase) waiter(ctx context.Context, businessID, employeeID string) (*primitive.ObjectID, error) {
+	employee, err := uc.employeeRepo.FindInBusiness(ctx, employeeID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find employee: %w", err)
+	}
+	if employee == nil {
+		return nil, Domain.NotFoundError("employee not found")
+	}
+	if employee.Status != Domain.EmployeeStatusActive {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "employee is not active")
+	}
+	return &employee.ID, nil
+}
+
+// newItems prices the items ordered from the shop's products. Stock is only
+// checked here; it is taken by the sales when the tab closes.
+func (uc *tabUseCase) newItems(ctx context.Context, businessID string, userID primitive.ObjectID, reqs []Domain.TabItemRequest, deviceID string) ([]Domain.TabItem, error) {
+	now := time.Now()
+	items := []Domain.TabItem{}
+	for _, req := range reqs {
+		product, err := uc.inventoryRepo.FindInBusiness(ctx, req.ProductID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to find product: %w", err)
+		}
+		if product == nil || product.DeletedAt != nil {
+			return nil, Domain.NotFoundError(fmt.Sprintf("product %s not found", req.ProductID))
+		}
+
+		if !product.Service {
+			available, err := availableStock(uc.reservationRepo, product, nil)
+			if err != nil {
+				return nil, err
+			}
+			if available < req.Quantity {
+				return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("insufficient stock of %s. Available: %.2f, Requested: %.2f",
+					product.Name, available, req.Quantity))
+			}
+		}
+
+		item := Domain.TabItem{
+			ID:        primitive.NewObjectID(),
+			ProductID: product.ID,
+			Name:      product.Name,
+			Quantity:  req.Quantity,
+			UnitPrice: product.SellingPrice,
+			Notes:     req.Notes,
+			DeviceID:  deviceID,
+			AddedBy:   userID,
+			AddedAt:   now,
+		}
+		if req.UnitPrice != nil {
+			item.UnitPrice = *req.UnitPrice
+		}
+		if item.UnitPrice <= 0 {
+			return nil, Domain.ValidationError(fmt.Sprintf("%s has no selling price; give a unit_price", product.Name))
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+func (uc *tabUseCase) GetTabs(businessID string, filters Domain.TabFilters) ([]Domain.Tab, error) {
+	if filters.Limit <= 0 || filters.Limit > 200 {
+		filters.Limit = 50
+	}
+
+	tabs, err := uc.tabRepo.FindByBusiness(businessID, filters)
+	if err != nil {
+		return nil, err
+	}
+	if tabs == nil {
+		tabs = []Domain.Tab{}
+	}
+	return tabs, nil
+}
+
+func (uc *tabUseCase) GetTab(ctx context.Context, id, businessID string) (*Domain.Tab, error) {
+	tab, err := uc.tabRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if tab == nil {
+		return nil, Domain.NotFoundError("tab not found")
+	}
+	return tab, nil
+}
+
+func (uc *tabUseCase) GetChanges(ctx context.Context, businessID string, revision *int64, wait time.Duration) (*Domain.TabChanges, error) {
+	if revision == nil {
+		// Read before the tabs, so a change made in between is sent again rather than missed
+		latest, err := uc.tabRepo.Revision(businessID)
+		if err != nil {
+			return nil, err
+		}
+		open := Domain.TabOpen
+		tabs, err := uc.tabRepo.FindByBusiness(businessID, Domain.TabFilters{Status: &open, Limit: openTabsLimit})
+		if err != nil {
+			return nil, err
+		}
+		if tabs == nil {
+			tabs = []Domain.Tab{}
+		}
+		return &Domain.TabChanges{Tabs: tabs, Revision: latest}, nil
+	}
+
+	if wait > tabMaxWait {
+		wait = tabMaxWait
+	}
+	deadline := time.Now().Add(wait)
+	for {
+		tabs, err := uc.tabRepo.FindChanged(businessID, *revision, tabChangesLimit)
+		if err != nil {
+			return nil, err
+		}
+		if len(tabs) > 0 {
+			return &Domain.TabChanges{Tabs: tabs, Revision: tabs[len(tabs)-1].Revision}, nil
+		}
+		if !time.Now().Add(tabPollInterval).Before(deadline) {
+			return &Domain.TabChanges{Tabs: []Domain.Tab{}, Revision: *revision}, nil
+		}
+
+		select {
+		case <-time.After(tabPollInterval):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-Infrastructure.ShuttingDown():
+			return &Domain.TabChanges{Tabs: []Domain.Tab{}, Revision: *revision}, nil
+		}
+	}
+}
+
+// openTab is the tab if it is still open
+func (uc *tabUseCase) openTab(ctx context.Context, id, businessID string) (*Domain.Tab, error) {
+	tab, err := uc.GetTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if tab.Status != Domain.TabOpen {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("tab is %s", tab.Status))
+	}
+	return tab, nil
+}
+
+// unpaidTab is the tab if it is open with no payments taken, so its items can change
+func (uc *tabUseCase) unpaidTab(ctx context.Context, id, businessID string) (*Domain.Tab, error) {
+	tab, err := uc.openTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tab.Payments) > 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "the tab is being paid; take back its payments to change it")
+	}
+	return tab, nil
+}
+
+// changed explains why a write to the tab no longer applied
+func (uc *tabUseCase) changed(ctx context.Context, tab *Domain.Tab) error {
+	if _, err := uc.openTab(ctx, tab.ID.Hex(), tab.BusinessID.Hex()); err != nil {
+		return err
+	}
+	return Domain.NewAppError(Domain.ErrCodeConflict, errTabChangedText)
+}
+
+// reload reads the tab back after a write, with what other devices added since
+func (uc *tabUseCase) reload(ctx context.Context, tab *Domain.Tab) (*Domain.Tab, error) {
+	return uc.GetTab(ctx, tab.ID.Hex(), tab.BusinessID.Hex())
+}
+
+func (uc *tabUseCase) UpdateTab(ctx context.Context, id, businessID string, req Domain.UpdateTabRequest) (*Domain.Tab, error) {
+	tab, err := uc.openTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Table != nil {
+		tab.Table = *req.Table
+	}
+	if req.Guests != nil {
+		tab.Guests = *req.Guests
+	}
+	if req.EmployeeID != nil {
+		tab.EmployeeID = nil
+		if *req.EmployeeID != "" {
+			if tab.EmployeeID, err = uc.waiter(ctx, businessID, *req.EmployeeID); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if req.Notes != nil {
+		tab.Notes = *req.Notes
+	}
+
+	updated, err := uc.tabRepo.Update(tab)
+	if err != nil {
+		return nil, err
+	}
+	if !updated {
+		return nil, uc.changed(ctx, tab)
+	}
+	return uc.reload(ctx, tab)
+}
+
+func (uc *tabUseCase) AddItems(ctx context.Context, id, businessID, userID string, req Domain.AddTabItemsRequest) (*Domain.Tab, error) {
+	tab, err := uc.unpaidTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	items, err := uc.newItems(ctx, businessID, objUserID, req.Items, req.DeviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := uc.tabRepo.AddItems(tab, items)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		// Only payments starting or the tab closing stop items being added
+		if _, err := uc.unpaidTab(ctx, id, businessID); err != nil {
+			return nil, err
+		}
+		return nil, Domain.NewAppError(Domain.ErrCodeConflict, errTabChangedText)
+	}
+	return uc.reload(ctx, tab)
+}
+
+func (uc *tabUseCase) RemoveItem(ctx context.Context, id, itemID, businessID string) (*Domain.Tab, error) {
+	tab, err := uc.unpaidTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objItemID, err := primitive.ObjectIDFromHex(itemID)
+	if err != nil {
+		return nil, Domain.ValidationError("invalid item ID")
+	}
+
+	removed, err := uc.tabRepo.RemoveItem(tab, objItemID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		if tab, err = uc.unpaidTab(ctx, id, businessID); err != nil {
+			return nil, err
+		}
+		return nil, Domain.NotFoundError("item not found on the tab")
+	}
+	return uc.reload(ctx, tab)
+}
+
+func (uc *tabUseCase) SplitTab(ctx context.Context, id, businessID string, req Domain.SplitTabRequest) (*Domain.Tab, error) {
+	if req.Evenly > 0 && len(req.Items) > 0 {
+		return nil, Domain.ValidationError("split evenly or by items, not both")
+	}
+
+	tab, err := uc.unpaidTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tab.Items) == 0 && (req.Evenly > 0 || len(req.Items) > 0) {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "nothing on the tab to split")
+	}
+
+	bills := []Domain.TabBill{}
+	switch {
+	case req.Evenly > 0:
+		// The cents that do not share out evenly go on the first bill
+		total := tab.Total()
+		share := total / Domain.Money(req.Evenly)
+		for i := 0; i < req.Evenly; i++ {
+			bills = append(bills, Domain.TabBill{ID: primitive.NewObjectID(), Amount: share})
+		}
+		bills[0].Amount += total - share*Domain.Money(req.Evenly)
+
+	case len(req.Items) > 0:
+		amounts := make(map[primitive.ObjectID]Domain.Money)
+		for _, item := range tab.Items {
+			amounts[item.ID] = item.UnitPrice.Times(item.Quantity)
+		}
+
+		billed := make(map[primitive.ObjectID]bool)
+		for _, group := range req.Items {
+			if len(group) == 0 {
+				return nil, Domain.ValidationError("each bill needs at least one item")
+			}
+			bill := Domain.TabBill{ID: primitive.NewObjectID()}
+			for _, itemID := range group {
+				objItemID, err := primitive.ObjectIDFromHex(itemID)
+				if err != nil {
+					return nil, Domain.ValidationError(fmt.Sprintf("invalid item ID: %s", itemID))
+				}
+				amount, ok := amounts[objItemID]
+				if !ok {
+					return nil, Domain.NotFoundError(fmt.Sprintf("item %s not found on the tab", itemID))
+				}
+				if billed[objItemID] {
+					return nil, Domain.ValidationError(fmt.Sprintf("item %s is on more than one bill", itemID))
+				}
+				billed[objItemID] = true
+				bill.ItemIDs = append(bill.ItemIDs, objItemID)
+				bill.Amount += amount
+			}
+			bills = append(bills, bill)
+		}
+
+		// Items left out share a bill of their own
+		rest := Domain.TabBill{ID: primitive.NewObjectID()}
+		for _, item := range tab.Items {
+			if !billed[item.ID] {
+				rest.ItemIDs = append(rest.ItemIDs, item.ID)
+				rest.Amount += amounts[item.ID]
+			}
+		}
+		if len(rest.ItemIDs) > 0 {
+			bills = append(bills, rest)
+		}
+	}
+
+	split, err := uc.tabRepo.SetBills(tab, bills)
+	if err != nil {
+		return nil, err
+	}
+	if !split {
+		return nil, uc.changed(ctx, tab)
+	}
+
+	tab.Bills = bills
+	return tab, nil
+}
+
+func (uc *tabUseCase) PayTab(ctx context.Context, id, businessID, userID string, req Domain.PayTabRequest) (*Domain.Tab, error) {
+	for _, payment := range req.Payments {
+		// Gift cards, points and credit are taken at the till
+		switch payment.Method {
+		case Domain.PaymentMethodCash, Domain.PaymentMethodCard, Domain.PaymentMethodMobile,
+			Domain.PaymentMethodBank, Domain.PaymentMethodOther:
+		default:
+			return nil, Domain.ValidationError(fmt.Sprintf("invalid payment method: %s", payment.Method))
+		}
+	}
+
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	tab, err := uc.openTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tab.Items) == 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "nothing on the tab to pay")
+	}
+
+	due := tab.Due()
+	var billID *primitive.ObjectID
+	if req.BillID != nil {
+		objBillID, err := primitive.ObjectIDFromHex(*req.BillID)
+		if err != nil {
+			return nil, Domain.ValidationError("invalid bill ID")
+		}
+		bill := tab.Bill(objBillID)
+		if bill == nil {
+			return nil, Domain.NotFoundError("bill not found on the tab")
+		}
+		if billDue := tab.BillDue(bill); billDue < due {
+			due = billDue
+		}
+		billID = &bill.ID
+	}
+	if due <= 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "already paid")
+	}
+
+	now := time.Now()
+	payments := []Domain.TabPayment{}
+	var paid Domain.Money
+	for _, p := range req.Payments {
+		payment := Domain.TabPayment{
+			ID:        primitive.NewObjectID(),
+			BillID:    billID,
+			Method:    p.Method,
+			Reference: p.Reference,
+			TakenBy:   objUserID,
+			TakenAt:   now,
+		}
+		if p.Amount != nil {
+			payment.Amount = *p.Amount
+		} else if len(req.Payments) == 1 {
+			payment.Amount = due
+		}
+		if payment.Amount <= 0 {
+			return nil, Domain.ValidationError("an amount is required for each of several payments")
+		}
+		paid += payment.Amount
+		payments = append(payments, payment)
+	}
+	if paid > due {
+		return nil, Domain.ValidationError(fmt.Sprintf("payments total %s is more than the %s due", paid, due))
+	}
+
+	added, err := uc.tabRepo.AddPayments(tab, payments)
+	if err != nil {
+		return nil, err
+	}
+	if !added {
+		return nil, uc.changed(ctx, tab)
+	}
+	tab.Payments = append(tab.Payments, payments...)
+
+	if tab.Due() > 0 {
+		return tab, nil
+	}
+	return uc.settle(ctx, tab, objUserID)
+}
+
+func (uc *tabUseCase) RemovePayment(ctx context.Context, id, paymentID, businessID string) (*Domain.Tab, error) {
+	tab, err := uc.openTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	objPaymentID, err := primitive.ObjectIDFromHex(paymentID)
+	if err != nil {
+		return nil, Domain.ValidationError("invalid payment ID")
+	}
+
+	removed, err := uc.tabRepo.RemovePayment(tab, objPaymentID)
+	if err != nil {
+		return nil, err
+	}
+	if !removed {
+		if tab, err = uc.openTab(ctx, id, businessID); err != nil {
+			return nil, err
+		}
+		if tab.Billed() {
+			return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "the tab's sales are already being recorded; close it instead")
+		}
+		return nil, Domain.NotFoundError("payment not found on the tab")
+	}
+	return uc.reload(ctx, tab)
+}
+
+func (uc *tabUseCase) CloseTab(ctx context.Context, id, businessID, userID string) (*Domain.Tab, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	tab, err := uc.openTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(tab.Items) == 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, "nothing on the tab; cancel it instead")
+	}
+	if due := tab.Due(); due > 0 {
+		return nil, Domain.NewAppError(Domain.ErrCodeBadRequest, fmt.Sprintf("%s is still due on the tab", due))
+	}
+
+	return uc.settle(ctx, tab, objUserID)
+}
+
+func (uc *tabUseCase) CancelTab(ctx context.Context, id, businessID, userID string) (*Domain.Tab, error) {
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	tab, err := uc.unpaidTab(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	cancelled, err := uc.tabRepo.Close(tab, Domain.TabCancelled, objUserID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !cancelled {
+		return nil, uc.changed(ctx, tab)
+	}
+
+	tab.Status = Domain.TabCancelled
+	tab.ClosedBy = &objUserID
+	tab.ClosedAt = &now
+	return tab, nil
+}
+
+// tenders are payments waiting to be spread over sales, first taken first
+type tenders []Domain.SalePayment
+
+// take spreads up to due of the payments onto parts, returning what is still due
+func (t *tenders) take(due Domain.Money, parts []Domain.SalePayment) ([]Domain.SalePayment, Domain.Money) {
+	for due > 0 && len(*t) > 0 {
+		part := (*t)[0]
+		if part.Amount > due {
+			(*t)[0].Amount -= due
+			part.Amount = due
+		} else {
+			*t = (*t)[1:]
+		}
+		parts = append(parts, part)
+		due -= part.Amount
+	}
+	return parts, due
+}
+
+// settle records a sale for each item still without one and closes the tab. Each
+// item is paid for out of its bill's payments first, then out of the rest; the
+// spread is worked out the same way every time, so settling again after a
+// failure gives the remaining items the payments left for them.
+func (uc *tabUseCase) settle(ctx context.Context, tab *Domain.Tab, userID primitive.ObjectID) (*Domain.Tab, error) {
+	billOf := make(map[primitive.ObjectID]primitive.ObjectID)
+	for _, bill := range tab.Bills {
+		for _, itemID := range bill.ItemIDs {
+			billOf[itemID] = bill.ID
+		}
+	}
+
+	byBill := make(map[primitive.ObjectID]*tenders)
+	shared := &tenders{}
+	for _, payment := range tab.Payments {
+		tender := Domain.SalePayment{Method: payment.Method, Amount: payment.Amount, Reference: payment.Reference}
+		// An even share pays for the tab as a whole
+		queue := shared
+		if payment.BillID != nil {
+			if bill := tab.Bill(*payment.BillID); bill != nil && len(bill.ItemIDs) > 0 {
+				if byBill[bill.ID] == nil {
+					byBill[bill.ID] = &tenders{}
+				}
+				queue = byBill[bill.ID]
+			}
+		}
+		*queue = append(*queue, tender)
+	}
+
+	// Sales are credited to the waiter
+	var employeeID *string
+	if tab.EmployeeID != nil {
+		waiterID := tab.EmployeeID.Hex()
+		employeeID = &waiterID
+	}
+
+	businessID := tab.BusinessID.Hex()
+	sold := make(map[primitive.ObjectID]primitive.ObjectID)
+	var saleErr error
+	for _, item := range tab.Items {
+		var parts []Domain.SalePayment
+		due := item.UnitPrice.Times(item.Quantity)
+		if queue, ok := byBill[billOf[item.ID]]; ok {
+			parts, due = queue.take(due, parts)
+		}
+		parts, _ = shared.take(due, parts)
+
+		if item.SaleID != nil || saleErr != nil {
+			continue
+		}
+
+		productID := item.ProductID.Hex()
+		req := Domain.CreateSaleRequest{
+			ProductID:     &productID,
+			Quantity:      item.Quantity,
+			UnitPrice:     item.UnitPrice,
+			PaymentMethod: Domain.PaymentMethodSplit,
+			Payments:      parts,
+			Notes:         "Table " + tab.Table,
+			LocalID:       "tab:" + tab.ID.Hex() + ":" + item.ID.Hex(),
+			EmployeeID:    employeeID,
+			DeviceID:      item.DeviceID,
+		}
+		if len(parts) == 1 {
+			req.PaymentMethod = parts[0].Method
+			req.Payments = nil
+		}
+
+		sale, err := uc.salesUC.CreateSale(ctx, businessID, userID.Hex(), req)
+		if err != nil {
+			saleErr = fmt.Errorf("failed to record the sale of %s: %w", item.Name, err)
+			continue
+		}
+		sold[item.ID] = sale.ID
+	}
+
+	// Sales recorded are saved even when a later one failed, so closing again picks up the rest
+	if len(sold) > 0 {
+		if err := uc.tabRepo.SetSales(tab, sold); err != nil {
+			Infrastructure.LoggerFrom(ctx).Warn("failed to record sales of tab", "tab_id", tab.ID.Hex(), "error", err)
+			return nil, err
+		}
+		for i := range tab.Items {
+			if saleID, ok := sold[tab.Items[i].ID]; ok {
+				tab.Items[i].SaleID = &saleID
+			}
+		}
+	}
+	if saleErr != nil {
+		return nil, saleErr
+	}
+
+	now := time.Now()
+	closed, err := uc.tabRepo.Close(tab, Domain.TabClosed, userID, now)
+	if err != nil {
+		return nil, err
+	}
+	if !closed {
+		return nil, uc.changed(ctx, tab)
+	}
+
+	tab.Status = Domain.TabClosed
+	tab.ClosedBy = &userID
+	tab.ClosedAt = &now
+	return tab, nil
+}
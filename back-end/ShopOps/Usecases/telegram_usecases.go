@@ -0,0 +1,482 @@
+package Usecases
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// TelegramUseCase links owners' Telegram chats to their shops, sends them
+// notifications and answers their questions through the bot
+type TelegramUseCase interface {
+	EventPublisher
+	CreateLinkCode(businessID, userID string) (*Domain.TelegramLinkCodeResponse, error)
+	GetLinks(businessID string) ([]Domain.TelegramLink, error)
+	UpdateLink(id, businessID string, req Domain.UpdateTelegramLinkRequest) (*Domain.TelegramLink, error)
+	DeleteLink(id, businessID string) error
+	// HandleUpdate answers a message sent to the bot
+	HandleUpdate(ctx context.Context, update Domain.TelegramUpdate) error
+	SendDailySummaries() error
+	SendMessageJob(ctx context.Context, job *Domain.Job) error
+}
+
+type telegramUseCase struct {
+	telegramRepo  Domain.TelegramRepository
+	businessRepo  Domain.BusinessRepository
+	inventoryRepo Domain.ProductRepository
+	dashboardUC   DashboardUseCase
+	bot           Infrastructure.TelegramBot
+	jobQueue      Infrastructure.JobQueue
+}
+
+const (
+	telegramCodeAlphabet = "ABCDEFGHJKLMNPQRSTUVWXYZ23456789" // No 0/O or 1/I to misread
+	telegramCodeLength   = 8
+	telegramLowStockList = 10
+)
+
+func NewTelegramUseCase(
+	telegramRepo Domain.TelegramRepository,
+	businessRepo Domain.BusinessRepository,
+	inventoryRepo Domain.ProductRepository,
+	dashboardUC DashboardUseCase,
+	bot Infrastructure.TelegramBot,
+	jobQueue Infrastructure.JobQueue,
+) TelegramUseCase {
+	return &telegramUseCase{
+		telegramRepo:  telegramRepo,
+		businessRepo:  businessRepo,
+		inventoryRepo: inventoryRepo,
+		dashboardUC:   dashboardUC,
+		bot:           bot,
+		jobQueue:      jobQueue,
+	}
+}
+
+func (uc *telegramUseCase) CreateLinkCode(businessID, userID string) (*Domain.TelegramLinkCodeResponse, error) {
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	code := make([]byte, telegramCodeLength)
+	for i := range code {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(telegramCodeAlphabet))))
+		if err != nil {
+			return nil, fmt.Errorf("failed to generate link code: %w", err)
+		}
+		code[i] = telegramCodeAlphabet[n.Int64()]
+	}
+
+	linkCode := &Domain.TelegramLinkCode{
+		Code:       string(code),
+		BusinessID: objBusinessID,
+		UserID:     objUserID,
+		ExpiresAt:  time.Now().Add(Domain.TelegramLinkCodeTTL),
+	}
+	if err := uc.telegramRepo.CreateCode(linkCode); err != nil {
+		return nil, err
+	}
+
+	response := &Domain.TelegramLinkCodeResponse{Code: linkCode.Code, ExpiresAt: linkCode.ExpiresAt}
+	if username := uc.bot.Username(); username != "" {
+		response.BotURL = fmt.Sprintf("https://t.me/%s?start=%s", username, linkCode.Code)
+	}
+	return response, nil
+}
+
+func (uc *telegramUseCase) GetLinks(businessID string) ([]Domain.TelegramLink, error) {
+	links, err := uc.telegramRepo.FindLinksByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if links == nil {
+		links = []Domain.TelegramLink{}
+	}
+	return links, nil
+}
+
+func (uc *telegramUseCase) getLink(id, businessID string) (*Domain.TelegramLink, error) {
+	link, err := uc.telegramRepo.FindLinkByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find telegram link: %w", err)
+	}
+	if link == nil {
+		return nil, Domain.NotFoundError("telegram link not found")
+	}
+	if link.BusinessID.Hex() != businessID {
+		return nil, Domain.AccessDeniedError("access denied: telegram link does not belong to this business")
+	}
+	return link, nil
+}
+
+func (uc *telegramUseCase) UpdateLink(id, businessID string, req Domain.UpdateTelegramLinkRequest) (*Domain.TelegramLink, error) {
+	link, err := uc.getLink(id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.DailySummary != nil {
+		link.Notify.DailySummary = *req.DailySummary
+	}
+	if req.SummaryHour != nil {
+		if *req.SummaryHour < 0 || *req.SummaryHour > 23 {
+			return nil, Domain.ValidationError("summary_hour must be between 0 and 23")
+		}
+		link.Notify.SummaryHour = *req.SummaryHour
+	}
+	if req.LowStock != nil {
+		link.Notify.LowStock = *req.LowStock
+	}
+	if req.BigSale != nil {
+		if *req.BigSale < 0 {
+			return nil, Domain.ValidationError("big_sale cannot be negative")
+		}
+		link.Notify.BigSale = *req.BigSale
+	}
+
+	if err := uc.telegramRepo.UpdateLink(link); err != nil {
+		return nil, err
+	}
+	return link, nil
+}
+
+func (uc *telegramUseCase) DeleteLink(id, businessID string) error {
+	if _, err := uc.getLink(id, businessID); err != nil {
+		return err
+	}
+	return uc.telegramRepo.DeleteLink(id)
+}
+
+func (uc *telegramUseCase) HandleUpdate(ctx context.Context, update Domain.TelegramUpdate) error {
+	message := update.Message
+	if message == nil || strings.TrimSpace(message.Text) == "" {
+		return nil
+	}
+	chatID := message.Chat.ID
+
+	command, argument := parseTelegramCommand(message.Text)
+	switch command {
+	case "start", "link":
+		if argument == "" {
+			return uc.send(ctx, chatID, telegramHelp)
+		}
+		return uc.linkChat(ctx, message.Chat, argument)
+	case "unlink", "stop":
+		if err := uc.telegramRepo.DeleteLinksByChat(chatID); err != nil {
+			return err
+		}
+		return uc.send(ctx, chatID, "This chat is unlinked and will get no more notifications.")
+	}
+
+	links, err := uc.telegramRepo.FindLinksByChat(chatID)
+	if err != nil {
+		return err
+	}
+	if len(links) == 0 {
+		return uc.send(ctx, chatID, telegramHelp)
+	}
+
+	switch command {
+	case "today", "sales":
+		return uc.replyForEachShop(ctx, chatID, links, uc.todayText)
+	case "lowstock", "stock":
+		return uc.replyForEachShop(ctx, chatID, links, uc.lowStockText)
+	default:
+		return uc.send(ctx, chatID, telegramHelp)
+	}
+}
+
+const telegramHelp = `ShopOps bot
+
+To link this chat to your shop, open Settings > Telegram in the ShopOps app, tap "Link Telegram" and send the code here, e.g. /link ABCD2345.
+
+Once linked, ask:
+/today - today's sales, expenses and profit
+/lowstock - products below their minimum stock
+/unlink - stop notifications to this chat`
+
+// parseTelegramCommand reads "/command@bot argument" or a plain question. Questions
+// are matched loosely, in English and Amharic, so "today's sales?" or "ዛሬ ሽያጭ" work.
+func parseTelegramCommand(text string) (string, string) {
+	text = strings.TrimSpace(text)
+	if strings.HasPrefix(text, "/") {
+		fields := strings.Fields(text[1:])
+		if len(fields) == 0 {
+			return "", ""
+		}
+		command, _, _ := strings.Cut(fields[0], "@")
+		argument := ""
+		if len(fields) > 1 {
+			argument = strings.ToUpper(strings.TrimSpace(fields[1]))
+		}
+		return strings.ToLower(command), argument
+	}
+
+	lower := strings.ToLower(text)
+	switch {
+	case strings.Contains(lower, "stock") || strings.Contains(lower, "ክምችት") || strings.Contains(lower, "እቃ"):
+		return "lowstock", ""
+	case strings.Contains(lower, "today") || strings.Contains(lower, "sales") ||
+		strings.Contains(lower, "ዛሬ") || strings.Contains(lower, "ሽያጭ"):
+		return "today", ""
+	}
+	return "", ""
+}
+
+func (uc *telegramUseCase) linkChat(ctx context.Context, chat Domain.TelegramChat, code string) error {
+	linkCode, err := uc.telegramRepo.ConsumeCode(code)
+	if err != nil {
+		return err
+	}
+	if linkCode == nil {
+		return uc.send(ctx, chat.ID, "That code is wrong or has expired. Get a new one from the ShopOps app; codes last 10 minutes.")
+	}
+
+	business, err := uc.businessRepo.FindByID(linkCode.BusinessID.Hex())
+	if err != nil {
+		return err
+	}
+	if business == nil {
+		return uc.send(ctx, chat.ID, "That shop no longer exists.")
+	}
+
+	chatName := chat.Title
+	if chatName == "" {
+		chatName = chat.Username
+	}
+	if chatName == "" {
+		chatName = chat.FirstName
+	}
+
+	link := &Domain.TelegramLink{
+		BusinessID: linkCode.BusinessID,
+		ChatID:     chat.ID,
+		ChatName:   chatName,
+		LinkedBy:   linkCode.UserID,
+		Notify:     Domain.DefaultTelegramNotifications,
+	}
+	if err := uc.telegramRepo.SaveLink(link); err != nil {
+		return err
+	}
+
+	return uc.send(ctx, chat.ID, fmt.Sprintf(
+		"Linked to %s. You will get a daily summary at %02d:00, low-stock alerts and, if you set an amount in the app, big-sale alerts.\n\nAsk /today for today's sales at any time.",
+		business.Name, link.Notify.SummaryHour))
+}
+
+func (uc *telegramUseCase) replyForEachShop(ctx context.Context, chatID int64, links []Domain.TelegramLink, text func(business *Domain.Business) (string, error)) error {
+	for _, link := range links {
+		business, err := uc.businessRepo.FindByID(link.BusinessID.Hex())
+		if err != nil {
+			return err
+		}
+		if business == nil {
+			continue
+		}
+		reply, err := text(business)
+		if err != nil {
+			return err
+		}
+		if err := uc.send(ctx, chatID, reply); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *telegramUseCase) todayText(business *Domain.Business) (string, error) {
+	dashboard, err := uc.dashboardUC.GetDashboard(business.ID.Hex(), false)
+	if err != nil {
+		return "", err
+	}
+
+	today := dashboard.Today
+	text := fmt.Sprintf("%s, %s\nSales: %s (%d)\nExpenses: %s\nProfit: %s",
+		business.Name, today.Date,
+		formatTelegramMoney(today.Sales.Float64(), business.Currency), today.Transactions,
+		formatTelegramMoney(today.Expenses.Float64(), business.Currency),
+		formatTelegramMoney(today.Profit.Float64(), business.Currency))
+	if dashboard.LowStock.Count > 0 {
+		text += fmt.Sprintf("\nLow stock: %d products (/lowstock)", dashboard.LowStock.Count)
+	}
+	return text, nil
+}
+
+func (uc *telegramUseCase) lowStockText(business *Domain.Business) (string, error) {
+	products, err := uc.inventoryRepo.GetLowStock(business.ID.Hex(), 0)
+	if err != nil {
+		return "", err
+	}
+	if len(products) == 0 {
+		return fmt.Sprintf("%s: nothing is below its minimum stock.", business.Name), nil
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d products below minimum stock", business.Name, len(products))
+	for i, product := range products {
+		if i == telegramLowStockList {
+			fmt.Fprintf(&b, "\n...and %d more in the app", len(products)-telegramLowStockList)
+			break
+		}
+		fmt.Fprintf(&b, "\n- %s: %s left (min %s)", product.Name, formatTelegramQuantity(product.Stock), formatTelegramQuantity(product.MinStock))
+	}
+	return b.String(), nil
+}
+
+// Publish notifies linked chats of big sales and of products going below minimum stock
+func (uc *telegramUseCase) Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error {
+	if event != Domain.WebhookEventSaleCreated && event != Domain.WebhookEventStockLow {
+		return nil
+	}
+
+	links, err := uc.telegramRepo.FindLinksByBusiness(businessID)
+	if err != nil {
+		return fmt.Errorf("failed to find telegram links: %w", err)
+	}
+	if len(links) == 0 {
+		return nil
+	}
+
+	var business *Domain.Business
+	var failed error
+	for _, link := range links {
+		var text string
+		switch payload := data.(type) {
+		case *Domain.Sale:
+			if link.Notify.BigSale <= 0 || payload.FinalAmount.Float64() < link.Notify.BigSale {
+				continue
+			}
+			if business == nil {
+				if business, err = uc.businessRepo.FindByID(businessID); err != nil || business == nil {
+					return err
+				}
+			}
+			text = fmt.Sprintf("Big sale at %s: %s", business.Name, formatTelegramMoney(payload.FinalAmount.Float64(), business.Currency))
+			if payload.CustomerName != "" {
+				text += " to " + payload.CustomerName
+			}
+		case Domain.StockLowEvent:
+			if !link.Notify.LowStock {
+				continue
+			}
+			text = fmt.Sprintf("Low stock: %s has %s left (minimum %s)",
+				payload.Name, formatTelegramQuantity(payload.Stock), formatTelegramQuantity(payload.MinStock))
+		default:
+			continue
+		}
+
+		if err := uc.send(ctx, link.ChatID, text); err != nil {
+			failed = fmt.Errorf("failed to queue telegram %s for chat %d: %w", event, link.ChatID, err)
+		}
+	}
+	return failed
+}
+
+// SendDailySummaries sends each linked chat its shop's day once the shop-local
+// summary hour has passed. Run it at least hourly.
+func (uc *telegramUseCase) SendDailySummaries() error {
+	links, err := uc.telegramRepo.FindSummaryLinks()
+	if err != nil {
+		return err
+	}
+
+	businesses := make(map[primitive.ObjectID]*Domain.Business)
+	ctx := context.Background()
+	for i := range links {
+		link := &links[i]
+
+		business, ok := businesses[link.BusinessID]
+		if !ok {
+			if business, err = uc.businessRepo.FindByID(link.BusinessID.Hex()); err != nil {
+				return err
+			}
+			businesses[link.BusinessID] = business
+		}
+		if business == nil {
+			continue
+		}
+
+		now := time.Now().In(businessLocation(business))
+		today := now.Format("2006-01-02")
+		if now.Hour() < link.Notify.SummaryHour || link.LastSummaryDate == today {
+			continue
+		}
+
+		text, err := uc.todayText(business)
+		if err != nil {
+			Infrastructure.Logger.Warn("failed to build telegram summary for business", "business_id", business.ID.Hex(), "error", err)
+			continue
+		}
+		if err := uc.send(ctx, link.ChatID, "Daily summary\n"+text); err != nil {
+			return err
+		}
+
+		link.LastSummaryDate = today
+		if err := uc.telegramRepo.UpdateLink(link); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// send queues a message, so a slow or failing Telegram does not hold up the caller
+// and failed sends are retried
+func (uc *telegramUseCase) send(ctx context.Context, chatID int64, text string) error {
+	_, err := uc.jobQueue.Enqueue(ctx, Domain.JobTypeTelegramMessage, "", map[string]string{
+		"chat_id": strconv.FormatInt(chatID, 10),
+		"text":    text,
+	})
+	return err
+}
+
+func (uc *telegramUseCase) SendMessageJob(ctx context.Context, job *Domain.Job) error {
+	chatID, err := strconv.ParseInt(job.Payload["chat_id"], 10, 64)
+	if err != nil {
+		return fmt.Errorf("invalid telegram chat ID: %w", err)
+	}
+
+	err = uc.bot.SendMessage(ctx, chatID, job.Payload["text"])
+	if errors.Is(err, Infrastructure.ErrTelegramChatGone) {
+		// Blocked or removed from the chat: unlink it rather than retrying
+		Infrastructure.LoggerFrom(ctx).Warn("unlinking telegram chat", "chat_id", chatID, "error", err)
+		return uc.telegramRepo.DeleteLinksByChat(chatID)
+	}
+	return err
+}
+
+func formatTelegramMoney(amount float64, currency string) string {
+	return strings.TrimSpace(formatThousands(amount) + " " + currency)
+}
+
+// formatThousands writes amount with two decimals and comma thousands separators
+func formatThousands(amount float64) string {
+	text := strconv.FormatFloat(amount, 'f', 2, 64)
+	sign := ""
+	if strings.HasPrefix(text, "-") {
+		sign, text = "-", text[1:]
+	}
+	whole, fraction, _ := strings.Cut(text, ".")
+	for i := len(whole) - 3; i > 0; i -= 3 {
+		whole = whole[:i] + "," + whole[i:]
+	}
+	return sign + whole + "." + fraction
+}
+
+func formatTelegramQuantity(quantity float64) string {
+	return strconv.FormatFloat(quantity, 'f', -1, 64)
+}
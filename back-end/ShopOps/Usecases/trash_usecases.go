@@ -0,0 +1,132 @@
+package Usecases
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	Domain "ShopOps/Domain"
+)
+
+// TrashUseCase lists and restores deleted products, customers and suppliers; the
+// retention run purges them once they have been in the trash for Domain.TrashRetention
+type TrashUseCase interface {
+	GetTrash(businessID string) (*Domain.Trash, error)
+	RestoreProduct(ctx context.Context, id, businessID string) (*Domain.Product, error)
+	RestoreCustomer(ctx context.Context, id, businessID string) (*Domain.Customer, error)
+	RestoreSupplier(ctx context.Context, id, businessID string) (*Domain.Supplier, error)
+}
+
+type trashUseCase struct {
+	inventoryRepo Domain.ProductRepository
+	customerRepo  Domain.CustomerRepository
+	supplierRepo  Domain.SupplierRepository
+}
+
+func NewTrashUseCase(
+	inventoryRepo Domain.ProductRepository,
+	customerRepo Domain.CustomerRepository,
+	supplierRepo Domain.SupplierRepository,
+) TrashUseCase {
+	return &trashUseCase{
+		inventoryRepo: inventoryRepo,
+		customerRepo:  customerRepo,
+		supplierRepo:  supplierRepo,
+	}
+}
+
+func (uc *trashUseCase) GetTrash(businessID string) (*Domain.Trash, error) {
+	products, err := uc.inventoryRepo.FindDeleted(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	customers, err := uc.customerRepo.FindDeleted(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	suppliers, err := uc.supplierRepo.FindDeleted(businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	trash := &Domain.Trash{Products: products, Customers: customers, Suppliers: suppliers}
+	if trash.Products == nil {
+		trash.Products = []Domain.Product{}
+	}
+	if trash.Customers == nil {
+		trash.Customers = []Domain.Customer{}
+	}
+	if trash.Suppliers == nil {
+		trash.Suppliers = []Domain.Supplier{}
+	}
+	return trash, nil
+}
+
+func (uc *trashUseCase) RestoreProduct(ctx context.Context, id, businessID string) (*Domain.Product, error) {
+	product, err := uc.inventoryRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find product: %w", err)
+	}
+	if product == nil || product.DeletedAt == nil {
+		return nil, Domain.NotFoundError("product not found in trash")
+	}
+
+	if err := uc.inventoryRepo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	product.DeletedAt = nil
+	product.Status = Domain.ProductStatusActive
+	product.UpdatedAt = time.Now()
+	return product, nil
+}
+
+func (uc *trashUseCase) RestoreCustomer(ctx context.Context, id, businessID string) (*Domain.Customer, error) {
+	customer, err := uc.customerRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find customer: %w", err)
+	}
+	if customer == nil || customer.DeletedAt == nil {
+		return nil, Domain.NotFoundError("customer not found in trash")
+	}
+
+	// The phone may have been given to a new customer since
+	if customer.Phone != "" {
+		existing, err := uc.customerRepo.FindByPhone(businessID, customer.Phone)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check customer phone: %w", err)
+		}
+		if existing != nil {
+			return nil, Domain.NewAppError(Domain.ErrCodeConflict, fmt.Sprintf("customer with phone %s already exists", customer.Phone))
+		}
+	}
+
+	if err := uc.customerRepo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	customer.DeletedAt = nil
+	customer.Status = Domain.CustomerStatusActive
+	customer.UpdatedAt = time.Now()
+	return customer, nil
+}
+
+func (uc *trashUseCase) RestoreSupplier(ctx context.Context, id, businessID string) (*Domain.Supplier, error) {
+	supplier, err := uc.supplierRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find supplier: %w", err)
+	}
+	if supplier == nil || supplier.DeletedAt == nil {
+		return nil, Domain.NotFoundError("supplier not found in trash")
+	}
+
+	if err := uc.supplierRepo.Restore(id); err != nil {
+		return nil, err
+	}
+
+	supplier.DeletedAt = nil
+	supplier.UpdatedAt = time.Now()
+	return supplier, nil
+}
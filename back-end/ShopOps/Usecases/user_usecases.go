@@ -1,7 +1,10 @@
 package Usecases
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"time"
 
 	Domain "ShopOps/Domain"
 	Infrastructure "ShopOps/Infrastructure"
@@ -21,12 +24,14 @@ type UserUseCase interface {
 type userUseCase struct {
 	userRepo   Domain.UserRepository
 	jwtService Infrastructure.JWTService
+	alerter    SecurityAlerter
 }
 
-func NewUserUseCase(userRepo Domain.UserRepository, jwtService Infrastructure.JWTService) UserUseCase {
+func NewUserUseCase(userRepo Domain.UserRepository, jwtService Infrastructure.JWTService, alerter SecurityAlerter) UserUseCase {
 	return &userUseCase{
 		userRepo:   userRepo,
 		jwtService: jwtService,
+		alerter:    alerter,
 	}
 }
 
@@ -98,6 +103,11 @@ func (uc *userUseCase) Login(req Domain.LoginRequest) (*Domain.LoginResponse, er
 		return nil, fmt.Errorf("failed to generate token: %w", err)
 	}
 
+	uc.alerter.AlertUser(context.Background(), user.ID.Hex(), Domain.SecurityAlert{
+		Title: "New sign-in to ShopOps",
+		Body:  "Your account was signed in to at " + pushAlertTime(time.Now()) + ". If this was not you, change your password.",
+	})
+
 	return &Domain.LoginResponse{
 		Token: token,
 		User:  *user,
@@ -122,9 +132,11 @@ func (uc *userUseCase) UpdateUser(id string, req Domain.UpdateUserRequest) (*Dom
 		return nil, fmt.Errorf("failed to find user: %w", err)
 	}
 	if user == nil {
-		return nil, fmt.Errorf("user not found")
+		return nil, Domain.NotFoundError("user not found")
 	}
 
+	previousPhone, previousEmail := user.Phone, user.Email
+
 	// Update fields
 	if req.Name != "" {
 		user.Name = req.Name
@@ -160,6 +172,20 @@ func (uc *userUseCase) UpdateUser(id string, req Domain.UpdateUserRequest) (*Dom
 		return nil, fmt.Errorf("failed to update user: %w", err)
 	}
 
+	var changed []string
+	if user.Phone != previousPhone {
+		changed = append(changed, "phone number")
+	}
+	if user.Email != previousEmail {
+		changed = append(changed, "email address")
+	}
+	if len(changed) > 0 {
+		uc.alerter.AlertUser(context.Background(), user.ID.Hex(), Domain.SecurityAlert{
+			Title: "Account details changed",
+			Body:  "The " + strings.Join(changed, " and ") + " on your ShopOps account changed at " + pushAlertTime(time.Now()) + ".",
+		})
+	}
+
 	return user, nil
 }
 
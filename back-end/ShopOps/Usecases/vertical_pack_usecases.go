@@ -0,0 +1,398 @@
+package Usecases
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	Domain "ShopOps/Domain"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type VerticalPackUseCase interface {
+	// GetPacks lists the packs with the version the shop has installed, if any
+	GetPacks(businessID string) ([]Domain.PackStatus, error)
+	GetPack(code string) (*Domain.VerticalPack, error)
+	// InstallPack installs the pack, or brings an installed one up to its latest
+	// version. Records the shop changed, deleted or already had are left alone.
+	InstallPack(ctx context.Context, businessID, userID, code string) (*Domain.PackInstallResult, error)
+}
+
+type verticalPackUseCase struct {
+	installedPackRepo Domain.InstalledPackRepository
+	catalogSettingsUC CatalogSettingsUseCase
+	customFieldUC     CustomFieldUseCase
+	receiptUC         ReceiptUseCase
+	customReportUC    CustomReportUseCase
+}
+
+func NewVerticalPackUseCase(
+	installedPackRepo Domain.InstalledPackRepository,
+	catalogSettingsUC CatalogSettingsUseCase,
+	customFieldUC CustomFieldUseCase,
+	receiptUC ReceiptUseCase,
+	customReportUC CustomReportUseCase,
+) VerticalPackUseCase {
+	return &verticalPackUseCase{
+		installedPackRepo: installedPackRepo,
+		catalogSettingsUC: catalogSettingsUC,
+		customFieldUC:     customFieldUC,
+		receiptUC:         receiptUC,
+		customReportUC:    customReportUC,
+	}
+}
+
+func (uc *verticalPackUseCase) GetPacks(businessID string) ([]Domain.PackStatus, error) {
+	installed, err := uc.installedPackRepo.FindByBusiness(businessID)
+	if err != nil {
+		return nil, err
+	}
+	versions := make(map[string]int, len(installed))
+	for _, pack := range installed {
+		versions[pack.Code] = pack.Version
+	}
+
+	statuses := make([]Domain.PackStatus, len(Domain.VerticalPacks))
+	for i, pack := range Domain.VerticalPacks {
+		version := versions[pack.Code]
+		statuses[i] = Domain.PackStatus{
+			Code:             pack.Code,
+			Name:             pack.Name,
+			Description:      pack.Description,
+			Version:          pack.Version,
+			InstalledVersion: version,
+			UpdateAvailable:  version > 0 && version < pack.Version,
+		}
+	}
+	return statuses, nil
+}
+
+func (uc *verticalPackUseCase) GetPack(code string) (*Domain.VerticalPack, error) {
+	pack := Domain.FindVerticalPack(code)
+	if pack == nil {
+		return nil, Domain.NotFoundError(fmt.Sprintf("unknown pack %q", code))
+	}
+	return pack, nil
+}
+
+func (uc *verticalPackUseCase) InstallPack(ctx context.Context, businessID, userID, code string) (*Domain.PackInstallResult, error) {
+	pack, err := uc.GetPack(code)
+	if err != nil {
+		return nil, err
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	installed, err := uc.installedPackRepo.Find(businessID, code)
+	if err != nil {
+		return nil, err
+	}
+	if installed == nil {
+		objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+		if err != nil {
+			return nil, fmt.Errorf("invalid business ID: %w", err)
+		}
+		installed = &Domain.InstalledPack{BusinessID: objBusinessID, Code: code}
+	}
+
+	if _, err := uc.catalogSettingsUC.ApplyPack(businessID, *pack); err != nil {
+		return nil, err
+	}
+
+	result := &Domain.PackInstallResult{Code: code, Version: pack.Version, PreviousVersion: installed.Version, Items: []Domain.PackItemResult{}}
+	var items []Domain.PackItem
+	keep := func(item *Domain.PackItem, res Domain.PackItemResult) {
+		if item != nil {
+			items = append(items, *item)
+		}
+		result.Items = append(result.Items, res)
+	}
+
+	if len(pack.CustomFields) > 0 {
+		fields, err := uc.customFieldUC.GetFields(businessID, "")
+		if err != nil {
+			return nil, err
+		}
+		for _, spec := range pack.CustomFields {
+			keep(uc.installField(ctx, businessID, installed, fields, spec))
+		}
+	}
+	if len(pack.ReceiptTemplates) > 0 {
+		templates, err := uc.receiptUC.GetTemplates(businessID)
+		if err != nil {
+			return nil, err
+		}
+		for _, spec := range pack.ReceiptTemplates {
+			keep(uc.installReceiptTemplate(ctx, businessID, userID, installed, templates, spec))
+		}
+	}
+	if len(pack.ReportPresets) > 0 {
+		reports, err := uc.customReportUC.GetReports(businessID)
+		if err != nil {
+			return nil, err
+		}
+		for _, spec := range pack.ReportPresets {
+			keep(uc.installReport(ctx, businessID, userID, installed, reports, spec))
+		}
+	}
+
+	// Items a newer version dropped stay tracked, and their records stay in the shop
+	for _, item := range installed.Items {
+		if packItemIndex(items, item.Kind, item.Key) < 0 {
+			items = append(items, item)
+		}
+	}
+
+	installed.Items = items
+	installed.Version = pack.Version
+	installed.InstalledBy = objUserID
+	if err := uc.installedPackRepo.Save(installed); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+func (uc *verticalPackUseCase) installField(ctx context.Context, businessID string, installed *Domain.InstalledPack, fields []Domain.CustomFieldDefinition, spec Domain.CreateCustomFieldRequest) (*Domain.PackItem, Domain.PackItemResult) {
+	key := string(spec.Entity) + "." + spec.Key
+	res := Domain.PackItemResult{Kind: Domain.PackItemCustomField, Key: key}
+	prior := installed.Item(Domain.PackItemCustomField, key)
+
+	var current *Domain.CustomFieldDefinition
+	for i := range fields {
+		f := &fields[i]
+		if (prior != nil && f.ID == prior.RecordID) || (prior == nil && f.Entity == spec.Entity && f.Key == spec.Key) {
+			current = f
+			break
+		}
+	}
+
+	specHash := packHash(spec)
+	var currentHash string
+	if current != nil {
+		currentHash = fieldFingerprint(current)
+	}
+	res.Outcome = packOutcome(prior, current != nil, currentHash, specHash)
+
+	switch res.Outcome {
+	case Domain.PackOutcomeCreated:
+		field, err := uc.customFieldUC.CreateField(businessID, spec)
+		if err != nil {
+			return nil, packFailed(res, err)
+		}
+		return &Domain.PackItem{Kind: res.Kind, Key: key, RecordID: field.ID, SpecHash: specHash, Written: fieldFingerprint(field)}, res
+	case Domain.PackOutcomeUpdated:
+		// A field's type is fixed once records hold values for it
+		if current.Type != spec.Type {
+			res.Outcome = Domain.PackOutcomeKept
+			res.Note = fmt.Sprintf("the pack now makes this a %s field; an existing field keeps its type", spec.Type)
+			return prior, res
+		}
+		field, err := uc.customFieldUC.UpdateField(ctx, current.ID.Hex(), businessID, Domain.UpdateCustomFieldRequest{
+			Label:      &spec.Label,
+			Required:   &spec.Required,
+			Options:    spec.Options,
+			MaxLength:  &spec.MaxLength,
+			Min:        spec.Min,
+			Max:        spec.Max,
+			Pattern:    &spec.Pattern,
+			Searchable: &spec.Searchable,
+			Position:   &spec.Position,
+		})
+		if err != nil {
+			return prior, packFailed(res, err)
+		}
+		return &Domain.PackItem{Kind: res.Kind, Key: key, RecordID: field.ID, SpecHash: specHash, Written: fieldFingerprint(field)}, res
+	case Domain.PackOutcomeKept:
+		if prior == nil {
+			res.Note = "the shop already has a field with this key"
+		}
+	}
+	return prior, res
+}
+
+func (uc *verticalPackUseCase) installReceiptTemplate(ctx context.Context, businessID, userID string, installed *Domain.InstalledPack, templates []Domain.ReceiptTemplate, spec Domain.CreateReceiptTemplateRequest) (*Domain.PackItem, Domain.PackItemResult) {
+	res := Domain.PackItemResult{Kind: Domain.PackItemReceiptTemplate, Key: spec.Name}
+	prior := installed.Item(Domain.PackItemReceiptTemplate, spec.Name)
+
+	var current *Domain.ReceiptTemplate
+	for i := range templates {
+		t := &templates[i]
+		if (prior != nil && t.ID == prior.RecordID) || (prior == nil && strings.EqualFold(t.Name, spec.Name)) {
+			current = t
+			break
+		}
+	}
+
+	specHash := packHash(spec)
+	var currentHash string
+	if current != nil {
+		currentHash = receiptFingerprint(current)
+	}
+	res.Outcome = packOutcome(prior, current != nil, currentHash, specHash)
+
+	switch res.Outcome {
+	case Domain.PackOutcomeCreated:
+		template, err := uc.receiptUC.CreateTemplate(businessID, userID, spec)
+		if err != nil {
+			return nil, packFailed(res, err)
+		}
+		return &Domain.PackItem{Kind: res.Kind, Key: spec.Name, RecordID: template.ID, SpecHash: specHash, Written: receiptFingerprint(template)}, res
+	case Domain.PackOutcomeUpdated:
+		width := spec.PaperWidth
+		if width == 0 {
+			width = Domain.ReceiptPaperWidth80
+		}
+		fields := Domain.DefaultReceiptFields()
+		if spec.Fields != nil {
+			fields = *spec.Fields
+		}
+		template, err := uc.receiptUC.UpdateTemplate(ctx, current.ID.Hex(), businessID, Domain.UpdateReceiptTemplateRequest{
+			Name:       &spec.Name,
+			Header:     append([]string{}, spec.Header...),
+			Footer:     append([]string{}, spec.Footer...),
+			PaperWidth: &width,
+			Fields:     &fields,
+		})
+		if err != nil {
+			return prior, packFailed(res, err)
+		}
+		return &Domain.PackItem{Kind: res.Kind, Key: spec.Name, RecordID: template.ID, SpecHash: specHash, Written: receiptFingerprint(template)}, res
+	case Domain.PackOutcomeKept:
+		if prior == nil {
+			res.Note = "the shop already has a receipt template with this name"
+		}
+	}
+	return prior, res
+}
+
+func (uc *verticalPackUseCase) installReport(ctx context.Context, businessID, userID string, installed *Domain.InstalledPack, reports []Domain.SavedReport, spec Domain.CreateSavedReportRequest) (*Domain.PackItem, Domain.PackItemResult) {
+	res := Domain.PackItemResult{Kind: Domain.PackItemReportPreset, Key: spec.Name}
+	prior := installed.Item(Domain.PackItemReportPreset, spec.Name)
+
+	var current *Domain.SavedReport
+	for i := range reports {
+		r := &reports[i]
+		if (prior != nil && r.ID == prior.RecordID) || (prior == nil && strings.EqualFold(r.Name, spec.Name)) {
+			current = r
+			break
+		}
+	}
+
+	specHash := packHash(spec)
+	var currentHash string
+	if current != nil {
+		currentHash = reportFingerprint(current)
+	}
+	res.Outcome = packOutcome(prior, current != nil, currentHash, specHash)
+
+	switch res.Outcome {
+	case Domain.PackOutcomeCreated:
+		report, err := uc.customReportUC.CreateReport(businessID, userID, spec)
+		if err != nil {
+			return nil, packFailed(res, err)
+		}
+		return &Domain.PackItem{Kind: res.Kind, Key: spec.Name, RecordID: report.ID, SpecHash: specHash, Written: reportFingerprint(report)}, res
+	case Domain.PackOutcomeUpdated:
+		schedule := spec.Schedule
+		if schedule == "" {
+			schedule = Domain.ReportScheduleNone
+		}
+		report, err := uc.customReportUC.UpdateReport(ctx, current.ID.Hex(), businessID, Domain.UpdateSavedReportRequest{
+			Name:        &spec.Name,
+			Description: &spec.Description,
+			Definition:  &spec.Definition,
+			Schedule:    &schedule,
+		})
+		if err != nil {
+			return prior, packFailed(res, err)
+		}
+		return &Domain.PackItem{Kind: res.Kind, Key: spec.Name, RecordID: report.ID, SpecHash: specHash, Written: reportFingerprint(report)}, res
+	case Domain.PackOutcomeKept:
+		if prior == nil {
+			res.Note = "the shop already has a report with this name"
+		}
+	}
+	return prior, res
+}
+
+// packOutcome decides what an install does with one item. prior is what the last
+// install wrote, if it wrote the item; found reports whether the record is still
+// there (or, on a first install, whether the shop has its own by the same name).
+func packOutcome(prior *Domain.PackItem, found bool, currentHash, specHash string) Domain.PackOutcome {
+	switch {
+	case prior == nil && found:
+		return Domain.PackOutcomeKept
+	case prior == nil:
+		return Domain.PackOutcomeCreated
+	case !found:
+		return Domain.PackOutcomeSkipped
+	case currentHash != prior.Written:
+		return Domain.PackOutcomeKept
+	case specHash == prior.SpecHash:
+		return Domain.PackOutcomeUnchanged
+	}
+	return Domain.PackOutcomeUpdated
+}
+
+func packFailed(res Domain.PackItemResult, err error) Domain.PackItemResult {
+	res.Outcome = Domain.PackOutcomeFailed
+	res.Note = err.Error()
+	return res
+}
+
+func packItemIndex(items []Domain.PackItem, kind Domain.PackItemKind, key string) int {
+	for i, item := range items {
+		if item.Kind == kind && item.Key == key {
+			return i
+		}
+	}
+	return -1
+}
+
+// packHash fingerprints the parts of a record a pack sets, so a later install can
+// tell whether the shop has changed it since
+func packHash(v interface{}) string {
+	data, _ := json.Marshal(v)
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func fieldFingerprint(f *Domain.CustomFieldDefinition) string {
+	return packHash(struct {
+		Label      string   `json:"label"`
+		Required   bool     `json:"required"`
+		Options    []string `json:"options,omitempty"`
+		MaxLength  int      `json:"max_length"`
+		Min        *float64 `json:"min,omitempty"`
+		Max        *float64 `json:"max,omitempty"`
+		Pattern    string   `json:"pattern"`
+		Searchable bool     `json:"searchable"`
+		Position   int      `json:"position"`
+		Active     bool     `json:"active"`
+	}{f.Label, f.Required, f.Options, f.MaxLength, f.Min, f.Max, f.Pattern, f.Searchable, f.Position, f.Active})
+}
+
+func receiptFingerprint(t *Domain.ReceiptTemplate) string {
+	return packHash(struct {
+		Name       string                        `json:"name"`
+		Header     []string                      `json:"header,omitempty"`
+		Footer     []string                      `json:"footer,omitempty"`
+		PaperWidth Domain.ReceiptPaperWidth      `json:"paper_width"`
+		Fields     Domain.ReceiptFieldVisibility `json:"fields"`
+	}{t.Name, t.Header, t.Footer, t.PaperWidth, t.Fields})
+}
+
+func reportFingerprint(r *Domain.SavedReport) string {
+	return packHash(struct {
+		Name        string                        `json:"name"`
+		Description string                        `json:"description"`
+		Definition  Domain.CustomReportDefinition `json:"definition"`
+		Schedule    Domain.ReportSchedule         `json:"schedule"`
+	}{r.Name, r.Description, r.Definition, r.Schedule})
+}
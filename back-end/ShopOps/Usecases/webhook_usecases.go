@@ -0,0 +1,365 @@
+package Usecases
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	Domain "ShopOps/Domain"
+	Infrastructure "ShopOps/Infrastructure"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WebhookUseCase manages a shop's webhooks and delivers events to them through the
+// job queue, which retries failed deliveries with backoff
+type WebhookUseCase interface {
+	EventPublisher
+	CreateWebhook(businessID, userID string, req Domain.CreateWebhookRequest) (*Domain.WebhookSecretResponse, error)
+	GetWebhooks(businessID string) ([]Domain.Webhook, error)
+	GetWebhookByID(ctx context.Context, id, businessID string) (*Domain.Webhook, error)
+	UpdateWebhook(ctx context.Context, id, businessID string, req Domain.UpdateWebhookRequest) (*Domain.Webhook, error)
+	DeleteWebhook(ctx context.Context, id, businessID string) error
+	RotateSecret(ctx context.Context, id, businessID string) (*Domain.WebhookSecretResponse, error)
+	GetDeliveries(ctx context.Context, id, businessID string, limit int) ([]Domain.WebhookDelivery, error)
+	// ReplayDelivery sends a past delivery's payload again, as a new delivery
+	ReplayDelivery(ctx context.Context, id, deliveryID, businessID string) (*Domain.WebhookDelivery, error)
+	DeliverJob(ctx context.Context, job *Domain.Job) error
+}
+
+type webhookUseCase struct {
+	webhookRepo Domain.WebhookRepository
+	sender      Infrastructure.WebhookSender
+	jobQueue    Infrastructure.JobQueue
+}
+
+const maxWebhooksPerBusiness = 10
+
+func NewWebhookUseCase(
+	webhookRepo Domain.WebhookRepository,
+	sender Infrastructure.WebhookSender,
+	jobQueue Infrastructure.JobQueue,
+) WebhookUseCase {
+	return &webhookUseCase{
+		webhookRepo: webhookRepo,
+		sender:      sender,
+		jobQueue:    jobQueue,
+	}
+}
+
+func (uc *webhookUseCase) CreateWebhook(businessID, userID string, req Domain.CreateWebhookRequest) (*Domain.WebhookSecretResponse, error) {
+	if err := validateWebhookURL(req.URL); err != nil {
+		return nil, err
+	}
+	events, err := validateWebhookEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := uc.webhookRepo.FindByBusinessID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if len(existing) >= maxWebhooksPerBusiness {
+		return nil, Domain.ValidationError(fmt.Sprintf("a business can have at most %d webhooks", maxWebhooksPerBusiness))
+	}
+
+	objBusinessID, err := primitive.ObjectIDFromHex(businessID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid business ID: %w", err)
+	}
+	objUserID, err := primitive.ObjectIDFromHex(userID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid user ID: %w", err)
+	}
+
+	secret, err := Infrastructure.NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	webhook := &Domain.Webhook{
+		BusinessID:  objBusinessID,
+		URL:         req.URL,
+		Events:      events,
+		Description: req.Description,
+		Secret:      secret,
+		Active:      true,
+		CreatedBy:   objUserID,
+	}
+	if err := uc.webhookRepo.Create(webhook); err != nil {
+		return nil, err
+	}
+
+	return &Domain.WebhookSecretResponse{Webhook: *webhook, Secret: secret}, nil
+}
+
+func (uc *webhookUseCase) GetWebhooks(businessID string) ([]Domain.Webhook, error) {
+	webhooks, err := uc.webhookRepo.FindByBusinessID(businessID)
+	if err != nil {
+		return nil, err
+	}
+	if webhooks == nil {
+		webhooks = []Domain.Webhook{}
+	}
+	return webhooks, nil
+}
+
+func (uc *webhookUseCase) GetWebhookByID(ctx context.Context, id, businessID string) (*Domain.Webhook, error) {
+	webhook, err := uc.webhookRepo.FindInBusiness(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook: %w", err)
+	}
+	if webhook == nil {
+		return nil, Domain.NotFoundError("webhook not found")
+	}
+	return webhook, nil
+}
+
+func (uc *webhookUseCase) UpdateWebhook(ctx context.Context, id, businessID string, req Domain.UpdateWebhookRequest) (*Domain.Webhook, error) {
+	webhook, err := uc.GetWebhookByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.URL != nil {
+		if err := validateWebhookURL(*req.URL); err != nil {
+			return nil, err
+		}
+		webhook.URL = *req.URL
+	}
+	if req.Events != nil {
+		events, err := validateWebhookEvents(req.Events)
+		if err != nil {
+			return nil, err
+		}
+		webhook.Events = events
+	}
+	if req.Description != nil {
+		webhook.Description = *req.Description
+	}
+	if req.Active != nil {
+		webhook.Active = *req.Active
+	}
+
+	if err := uc.webhookRepo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return webhook, nil
+}
+
+func (uc *webhookUseCase) DeleteWebhook(ctx context.Context, id, businessID string) error {
+	if _, err := uc.GetWebhookByID(ctx, id, businessID); err != nil {
+		return err
+	}
+	return uc.webhookRepo.Delete(id)
+}
+
+// RotateSecret replaces the signing secret. Deliveries already queued are signed
+// with the new one when they are sent.
+func (uc *webhookUseCase) RotateSecret(ctx context.Context, id, businessID string) (*Domain.WebhookSecretResponse, error) {
+	webhook, err := uc.GetWebhookByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := Infrastructure.NewWebhookSecret()
+	if err != nil {
+		return nil, err
+	}
+	webhook.Secret = secret
+
+	if err := uc.webhookRepo.Update(webhook); err != nil {
+		return nil, err
+	}
+	return &Domain.WebhookSecretResponse{Webhook: *webhook, Secret: secret}, nil
+}
+
+func (uc *webhookUseCase) GetDeliveries(ctx context.Context, id, businessID string, limit int) ([]Domain.WebhookDelivery, error) {
+	if _, err := uc.GetWebhookByID(ctx, id, businessID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := uc.webhookRepo.FindDeliveries(id, limit)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []Domain.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+func (uc *webhookUseCase) ReplayDelivery(ctx context.Context, id, deliveryID, businessID string) (*Domain.WebhookDelivery, error) {
+	webhook, err := uc.GetWebhookByID(ctx, id, businessID)
+	if err != nil {
+		return nil, err
+	}
+
+	original, err := uc.webhookRepo.FindDeliveryByID(deliveryID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to find webhook delivery: %w", err)
+	}
+	if original == nil || original.WebhookID != webhook.ID {
+		return nil, Domain.NotFoundError("webhook delivery not found")
+	}
+
+	var envelope Domain.WebhookEnvelope
+	if err := json.Unmarshal([]byte(original.Payload), &envelope); err != nil {
+		return nil, fmt.Errorf("failed to read webhook delivery payload: %w", err)
+	}
+
+	delivery, err := uc.queue(ctx, webhook, original.Event, envelope, &original.ID)
+	if err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// Publish queues a delivery to every webhook subscribed to the event. If any
+// fails to queue, the error asks for the event again; webhooks already queued
+// then get it twice, under the same event ID.
+func (uc *webhookUseCase) Publish(ctx context.Context, businessID string, event Domain.WebhookEvent, data interface{}) error {
+	webhooks, err := uc.webhookRepo.FindSubscribed(businessID, event)
+	if err != nil {
+		return fmt.Errorf("failed to find webhooks for %s: %w", event, err)
+	}
+
+	envelope := Domain.WebhookEnvelope{EventID: eventID(ctx), CreatedAt: time.Now(), Data: data}
+	var failed error
+	for i := range webhooks {
+		if _, err := uc.queue(ctx, &webhooks[i], event, envelope, nil); err != nil {
+			failed = fmt.Errorf("failed to queue %s webhook %s: %w", event, webhooks[i].ID.Hex(), err)
+		}
+	}
+	return failed
+}
+
+// queue logs a delivery to webhook of the envelope's event ID, time and data and
+// puts it on the job queue
+func (uc *webhookUseCase) queue(ctx context.Context, webhook *Domain.Webhook, event Domain.WebhookEvent, envelope Domain.WebhookEnvelope, replayOf *primitive.ObjectID) (*Domain.WebhookDelivery, error) {
+	delivery := &Domain.WebhookDelivery{
+		ID:         primitive.NewObjectID(), // Known before insert, as the envelope carries it
+		BusinessID: webhook.BusinessID,
+		WebhookID:  webhook.ID,
+		Event:      event,
+		ReplayOf:   replayOf,
+		Status:     Domain.WebhookDeliveryPending,
+	}
+
+	envelope.ID = delivery.ID.Hex()
+	envelope.Event = event
+	envelope.BusinessID = webhook.BusinessID.Hex()
+	payload, err := json.Marshal(envelope)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+	delivery.Payload = string(payload)
+
+	if err := uc.webhookRepo.CreateDelivery(delivery); err != nil {
+		return nil, err
+	}
+
+	if _, err := uc.jobQueue.Enqueue(ctx, Domain.JobTypeWebhookDelivery, webhook.BusinessID.Hex(),
+		map[string]string{"delivery_id": delivery.ID.Hex()}); err != nil {
+		return nil, err
+	}
+	return delivery, nil
+}
+
+// DeliverJob POSTs one delivery. Any answer but a 2xx is a failure, so the queue
+// retries it; the last failure marks the delivery failed, leaving it to be replayed.
+func (uc *webhookUseCase) DeliverJob(ctx context.Context, job *Domain.Job) error {
+	delivery, err := uc.webhookRepo.FindDeliveryByID(job.Payload["delivery_id"])
+	if err != nil {
+		return err
+	}
+	if delivery == nil || delivery.Status != Domain.WebhookDeliveryPending {
+		return nil
+	}
+
+	webhook, err := uc.webhookRepo.FindInBusiness(ctx, delivery.WebhookID.Hex())
+	if err != nil {
+		return err
+	}
+	if webhook == nil || !webhook.Active {
+		// Deleted or paused since the event; nothing to retry
+		return uc.webhookRepo.RecordAttempt(delivery.ID.Hex(), Domain.WebhookAttempt{
+			At:    time.Now(),
+			Error: "webhook deleted or inactive",
+		}, Domain.WebhookDeliveryFailed)
+	}
+
+	start := time.Now()
+	resp, sendErr := uc.sender.Send(ctx, webhook.URL, webhook.Secret, string(delivery.Event), delivery.ID.Hex(), []byte(delivery.Payload))
+	attempt := Domain.WebhookAttempt{At: start, DurationMs: time.Since(start).Milliseconds()}
+	switch {
+	case sendErr != nil:
+		attempt.Error = sendErr.Error()
+	case resp.StatusCode < 200 || resp.StatusCode > 299:
+		attempt.StatusCode = resp.StatusCode
+		attempt.ResponseBody = resp.Body
+		sendErr = fmt.Errorf("webhook answered %d", resp.StatusCode)
+	default:
+		attempt.StatusCode = resp.StatusCode
+		attempt.ResponseBody = resp.Body
+	}
+	Infrastructure.WebhookDeliveries.Inc(string(delivery.Event), webhookOutcome(sendErr))
+
+	status := Domain.WebhookDeliverySucceeded
+	if sendErr != nil {
+		status = Domain.WebhookDeliveryPending
+		if job.Attempts >= job.MaxAttempts {
+			status = Domain.WebhookDeliveryFailed
+		}
+	}
+	if err := uc.webhookRepo.RecordAttempt(delivery.ID.Hex(), attempt, status); err != nil {
+		return err
+	}
+	return sendErr
+}
+
+func webhookOutcome(err error) string {
+	if err != nil {
+		return "failed"
+	}
+	return "succeeded"
+}
+
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Host == "" {
+		return Domain.ValidationError("webhook URL is invalid")
+	}
+	if parsed.Scheme != "https" && parsed.Scheme != "http" {
+		return Domain.ValidationError("webhook URL must be http or https")
+	}
+	if parsed.User != nil {
+		return Domain.ValidationError("webhook URL must not contain credentials; verify deliveries by their signature")
+	}
+	return nil
+}
+
+// validateWebhookEvents checks the events and drops duplicates
+func validateWebhookEvents(events []Domain.WebhookEvent) ([]Domain.WebhookEvent, error) {
+	if len(events) == 0 {
+		return nil, Domain.ValidationError("at least one event is required")
+	}
+
+	seen := make(map[Domain.WebhookEvent]bool)
+	var result []Domain.WebhookEvent
+	for _, event := range events {
+		event = Domain.WebhookEvent(strings.TrimSpace(string(event)))
+		if !event.IsValid() {
+			return nil, Domain.ValidationError(fmt.Sprintf("unknown webhook event %q", event))
+		}
+		if !seen[event] {
+			seen[event] = true
+			result = append(result, event)
+		}
+	}
+	return result, nil
+}